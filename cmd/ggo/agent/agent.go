@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"strconv"
@@ -20,6 +21,7 @@ import (
 	"github.com/NexusGPU/gpu-go/internal/config"
 	"github.com/NexusGPU/gpu-go/internal/deps"
 	"github.com/NexusGPU/gpu-go/internal/hypervisor"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 	"github.com/NexusGPU/gpu-go/internal/platform"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	tfv1 "github.com/NexusGPU/tensor-fusion/api/v1"
@@ -39,6 +41,7 @@ var (
 	outputFormat   string
 	acceleratorLib string
 	isolationMode  string
+	transportName  string
 	paths          = platform.DefaultPaths()
 
 	// Hypervisor singleton
@@ -52,7 +55,21 @@ func NewAgentCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "agent",
 		Short: "Manage GPU agent on the server side",
-		Long:  `The agent command manages the GPU agent that runs on GPU servers to sync with the cloud platform.`,
+		Long: `The agent command manages the GPU agent that runs on GPU servers to sync with the cloud platform.
+
+A host split between teams can run several isolated agents side by side. Each
+--instance has its own config and state directories (by default below the
+host's, in instances/<name>), PID file and worker connections, and can be
+pinned to GPUs by UUID with --gpus on register or start. Later commands find
+an instance by name alone; 'ggo agent instances' lists them.
+
+Examples:
+  ggo agent register --instance team-a --gpus GPU-1a2b...,GPU-3c4d... -t <token>
+  ggo agent start --instance team-a
+  ggo agent status --instance team-a`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return resolveInstance(cmd)
+		},
 	}
 
 	cmd.PersistentFlags().StringVar(&configDir, "config-dir", paths.ConfigDir(), "Configuration directory")
@@ -61,13 +78,28 @@ func NewAgentCmd() *cobra.Command {
 	cmdutil.AddOutputFlag(cmd, &outputFormat)
 	cmd.PersistentFlags().StringVar(&acceleratorLib, "accelerator-lib", "", "Path to accelerator library (auto-detected if not specified)")
 	cmd.PersistentFlags().StringVar(&isolationMode, "isolation-mode", "shared", "Worker isolation mode (shared, soft, partitioned)")
+	cmd.PersistentFlags().StringVar(&instanceName, "instance", os.Getenv("GGO_AGENT_INSTANCE"), "Agent instance on this host to act on (or set GGO_AGENT_INSTANCE)")
+	cmd.PersistentFlags().StringVar(&transportName, "transport", os.Getenv("GGO_AGENT_TRANSPORT"),
+		"Transport of calls to the platform: auto (gRPC when the server offers it) or rest (default auto; or set GGO_AGENT_TRANSPORT)")
 
-	cmd.AddCommand(newRegisterCmd())
-	cmd.AddCommand(newUnregisterCmd())
+	cmd.AddCommand(cmdutil.Audited(newRegisterCmd()))
+	cmd.AddCommand(cmdutil.Audited(newUnregisterCmd()))
+	cmd.AddCommand(cmdutil.Audited(newRotateSecretCmd()))
 	cmd.AddCommand(newStartCmd())
+	cmd.AddCommand(cmdutil.Audited(newRestartCmd()))
 	cmd.AddCommand(newStatusCmd())
 	cmd.AddCommand(newListCmd())
 	cmd.AddCommand(newGetCmd())
+	cmd.AddCommand(newGPUsCmd())
+	cmd.AddCommand(newInstancesCmd())
+	cmd.AddCommand(newExportCmd())
+	cmd.AddCommand(cmdutil.Audited(newLabelCmd()))
+	cmd.AddCommand(cmdutil.Audited(newExecCmd()))
+	cmd.AddCommand(cmdutil.Audited(newDeleteCmd()))
+	cmd.AddCommand(newNetTestCmd())
+	cmd.AddCommand(newHistoryCmd())
+	cmd.AddCommand(newLicenseCmd())
+	cmd.AddCommand(newWorkerExecCmd())
 
 	return cmd
 }
@@ -125,6 +157,7 @@ func getHypervisorManager() (*hypervisor.Manager, error) {
 				Vendor:        agent.DetectVendorFromLibPath(libPath),
 				IsolationMode: getIsolationMode(),
 				StateDir:      stateDir,
+				Devices:       instanceGPUs,
 			})
 			if hypervisorErr != nil {
 				return
@@ -135,6 +168,26 @@ func getHypervisorManager() (*hypervisor.Manager, error) {
 	return hypervisorManager, hypervisorErr
 }
 
+// restartStopTimeout bounds stopping the agent for a restart. A subsystem
+// stuck badly enough for the watchdog to restart the agent may never stop.
+const restartStopTimeout = 30 * time.Second
+
+// stopWithin runs stop and reports whether it returned within timeout; past
+// it stop is left running
+func stopWithin(timeout time.Duration, stop func()) bool {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stop()
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
 // stopHypervisorManager stops the singleton hypervisor manager if running
 func stopHypervisorManager() {
 	if hypervisorManager != nil {
@@ -152,7 +205,12 @@ func newRegisterCmd() *cobra.Command {
 		Long:  `Register this GPU server as an agent with the GPU Go platform.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out := getOutput()
-			client := api.NewClient(api.WithBaseURL(serverURL))
+			transport, err := api.ParseAgentTransport(transportName)
+			if err != nil {
+				return cmdutil.UsageError(err)
+			}
+			client := api.NewClient(api.WithBaseURL(serverURL), api.WithAgentTransport(transport))
+			defer func() { _ = client.Close() }()
 
 			if token == "" {
 				token = os.Getenv("GPU_GO_TOKEN")
@@ -161,7 +219,12 @@ func newRegisterCmd() *cobra.Command {
 				if !out.IsJSON() {
 					out.Error("Token is required. Use --token flag or GPU_GO_TOKEN environment variable")
 				}
-				return fmt.Errorf("token is required")
+				return cmdutil.UsageErrorf("token is required")
+			}
+
+			if err := recordInstance(); err != nil {
+				cmd.SilenceUsage = true
+				return err
 			}
 
 			configMgr := config.NewManager(configDir, stateDir)
@@ -189,12 +252,12 @@ func newRegisterCmd() *cobra.Command {
 
 				if isStale {
 					if !out.IsJSON() {
-						out.Warning(fmt.Sprintf("Stale local registration found (agent %s no longer on server). Clearing and re-registering...", cfg.AgentID))
+						out.Warningf("Stale local registration found (agent %s no longer on server). Clearing and re-registering...", cfg.AgentID)
 					}
 				} else if force {
 					// --force: skip confirmation, auto-unregister.
 					if !out.IsJSON() {
-						out.Info(fmt.Sprintf("Force replacing existing registration (agent %s)...", cfg.AgentID))
+						out.Infof("Force replacing existing registration (agent %s)...", cfg.AgentID)
 					}
 					if deleteErr := agentClient.SelfDeleteAgent(context.Background(), cfg.AgentID); deleteErr != nil {
 						klog.Warningf("Failed to delete old agent from server (continuing): agent_id=%s error=%v", cfg.AgentID, deleteErr)
@@ -205,7 +268,7 @@ func newRegisterCmd() *cobra.Command {
 					return agent.ErrAlreadyRegistered
 				} else {
 					// Interactive: show current registration and ask for confirmation.
-					out.Warning(fmt.Sprintf("This machine is already registered as agent %s", cfg.AgentID))
+					out.Warningf("This machine is already registered as agent %s", cfg.AgentID)
 					confirmed, promptErr := tui.ConfirmPrompt("Unregister the existing agent and re-register with the new token?")
 					if promptErr != nil || !confirmed {
 						out.Info("Registration cancelled. Existing registration unchanged.")
@@ -214,10 +277,10 @@ func newRegisterCmd() *cobra.Command {
 					if deleteErr := agentClient.SelfDeleteAgent(context.Background(), cfg.AgentID); deleteErr != nil {
 						klog.Warningf("Failed to delete old agent from server (continuing): agent_id=%s error=%v", cfg.AgentID, deleteErr)
 						if !out.IsJSON() {
-							out.Warning(fmt.Sprintf("Could not remove old agent from server: %v", deleteErr))
+							out.Warningf("Could not remove old agent from server: %v", deleteErr)
 						}
 					} else if !out.IsJSON() {
-						out.Info(fmt.Sprintf("Old agent %s unregistered from server.", cfg.AgentID))
+						out.Infof("Old agent %s unregistered from server.", cfg.AgentID)
 					}
 				}
 
@@ -238,11 +301,15 @@ func newRegisterCmd() *cobra.Command {
 			gpus, gpuErr := discoverGPUs()
 			if gpuErr != nil {
 				if !out.IsJSON() {
-					out.Warning(fmt.Sprintf("Failed to discover GPUs: %v", gpuErr))
+					out.Warningf("Failed to discover GPUs: %v", gpuErr)
 				}
 				klog.Warningf("Failed to discover GPUs: error=%v", gpuErr)
 				gpus = []api.GPUInfo{}
 			}
+			if len(gpus) < len(instanceGPUs) {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d of the %d GPUs pinned to instance %s; check the UUIDs with nvidia-smi -L", len(gpus), len(instanceGPUs), instanceName)
+			}
 			if len(gpus) == 0 {
 				if !out.IsJSON() {
 					out.Info("No GPUs detected. Registering as client-only machine.")
@@ -266,6 +333,7 @@ func newRegisterCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&token, "token", "t", "", "Temporary installation token")
 	cmd.Flags().BoolVar(&force, "force", false, "Force re-registration, replacing any existing registration on this machine")
+	cmd.Flags().StringSliceVar(&instanceGPUs, "gpus", nil, "UUIDs of the GPUs this --instance may use (default all)")
 
 	return cmd
 }
@@ -317,7 +385,7 @@ Use --force to clean up local config even when the server is unreachable.`,
 			if err := client.SelfDeleteAgent(ctx, agentID); err != nil {
 				if force {
 					if !out.IsJSON() {
-						out.Warning(fmt.Sprintf("Server unregistration failed (continuing due to --force): %v", err))
+						out.Warningf("Server unregistration failed (continuing due to --force): %v", err)
 					}
 					klog.Warningf("Failed to unregister agent from server: agent_id=%s error=%v", agentID, err)
 				} else {
@@ -331,13 +399,15 @@ Use --force to clean up local config even when the server is unreachable.`,
 			if err := configMgr.RemoveConfig(); err != nil {
 				klog.Warningf("Failed to remove local config files: %v", err)
 				if !out.IsJSON() {
-					out.Warning(fmt.Sprintf("Failed to remove local config: %v", err))
+					out.Warningf("Failed to remove local config: %v", err)
 				}
 			}
+			forgetInstance()
 
 			return out.Render(&cmdutil.ActionData{
 				Success: true,
-				Message: fmt.Sprintf("Agent '%s' unregistered successfully", agentID),
+				Message: "Agent '%s' unregistered successfully",
+				Args:    []any{agentID},
 			})
 		},
 	}
@@ -347,13 +417,210 @@ Use --force to clean up local config even when the server is unreachable.`,
 	return cmd
 }
 
+func newRotateSecretCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate-secret",
+		Short: "Replace the agent secret with a new one",
+		Long: `Request a new agent secret from the server, save it to the agent config
+and verify it. If the server rejects the new secret, the previous one is kept.
+
+A running agent rotates its secret automatically when the server reports the
+current one as expiring or compromised. After a manual rotation, restart the
+running agent so it picks up the new secret.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			configMgr := config.NewManager(configDir, stateDir)
+
+			cfg, err := configMgr.LoadConfig()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if cfg == nil || cfg.AgentID == "" {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("agent not registered, run 'ggo agent register' first")
+			}
+
+			resolvedServerURL := serverURL
+			if resolvedServerURL == api.GetDefaultBaseURL() && cfg.ServerURL != "" {
+				resolvedServerURL = cfg.ServerURL
+			}
+			client := api.NewClient(
+				api.WithBaseURL(resolvedServerURL),
+				api.WithAgentSecret(cfg.AgentSecret),
+			)
+
+			if err := agent.RotateSecret(context.Background(), client, configMgr); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to rotate agent secret: agent_id=%s error=%v", cfg.AgentID, err)
+				return err
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Agent secret rotated for '%s'. Restart the running agent to use it.",
+				Args:    []any{cfg.AgentID},
+				ID:      cfg.AgentID,
+			})
+		},
+	}
+}
+
 func newStartCmd() *cobra.Command {
+	var proxy bool
+	var tlsMode string
+	var relayProxy string
+	var localAPI string
+	var drainGrace time.Duration
+	var hooksDir string
+	var hookTimeout time.Duration
+	var execAllowlist string
+	var healthInterval time.Duration
+	var healthProbes []string
+	var healthFailures int
+	var healthMaxRestarts int
+	var healthPingCmd string
+	var reportInterval time.Duration
+	var forceRefreshInterval time.Duration
+	var keepaliveInterval time.Duration
+	var changesOnly bool
+	var workerUpgrades bool
+	var upgradeWindow string
+	var upgradeCheckInterval time.Duration
+	var upgradeHealthTimeout time.Duration
+	var stateStore string
+	var workerLogMaxSize int
+	var workerLogMaxFiles int
+	var diskSettings agent.DiskSettings
+	var repairDeps bool
+
 	cmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start the agent daemon",
-		Long:  `Start the GPU agent daemon to sync with the cloud platform.`,
+		Long: `Start the GPU agent daemon to sync with the cloud platform.
+
+With --tls the agent terminates TLS on worker ports (this implies --proxy).
+Certificates are self-signed per worker and pinned by clients through the
+fingerprint published with each share, or signed by the platform.
+
+Workers shared with --relay are served through the platform relay over
+outbound tunnels, so they work behind NAT and firewalls that block inbound
+ports. Tunnels honor HTTPS_PROXY, NO_PROXY and ALL_PROXY; --relay-proxy
+overrides them with an http://, https:// or socks5:// proxy URL.
+
+With --proxy, a worker that is disabled or deleted stops accepting new
+connections but keeps serving connected clients for up to --drain-grace before
+it is stopped. Deleting a worker with 'ggo worker delete --force' skips this.
+Without --proxy the worker listens on its port itself, so it is stopped at
+once.
+
+Executables in the hooks directory run on lifecycle events: pre-worker-start,
+post-worker-stop, on-gpu-change and on-license-renewal. Each event runs the
+file named after it and then the files in <event>.d in lexical order, with a
+JSON description of the event on stdin. See docs/agent-hooks.md.
+
+'ggo agent exec' runs only the commands listed in --exec-allowlist; without
+the file every remote command is refused. Requests are recorded in
+exec-audit.log in the state directory.
+
+Running workers are probed every --health-interval: a TCP connect to the
+worker port and, on NVIDIA GPUs, a scan of the kernel log for critical Xid
+errors on the worker's GPUs. --health-ping-cmd adds an app-level probe: the
+command runs with GGO_WORKER_ID and GGO_WORKER_ADDR set and must exit 0. A
+worker is unhealthy after --health-failures failed rounds in a row, or at once
+on a critical Xid, and is restarted at most --health-max-restarts times an
+hour. Probe results are reported with the worker status.
+
+Status is reported every 30 seconds, and every 6 hours all GPUs and workers
+are reported in full. The platform can change both per agent; the
+--report-interval and --force-refresh-interval flags override it. With
+--changes-only (for metered or cellular links) reports in which nothing
+changed are skipped and only a small keepalive is sent every
+--keepalive-interval.
+
+When the server offers it, registration, config pulls, status and metrics
+reports and the command channel use gRPC instead of REST and SSE; while gRPC
+fails the agent falls back to them. --transport rest disables gRPC.
+
+With --worker-upgrades the agent checks for a newer remote-gpu-worker release
+every --upgrade-check-interval and downloads it. Within --upgrade-window
+(HH:MM-HH:MM in local time, e.g. 02:00-05:00; any time if empty) workers are
+restarted with it one at a time: each takes no new clients and, with --proxy,
+drains for up to --drain-grace first. A worker that is not reported healthy
+within --upgrade-health-timeout (or, without health probes, does not stay up
+that long) puts all workers back on the previous release, and that release is
+not tried again.
+
+The stdout and stderr of workers are captured in output-<worker>.log in the
+logs directory of the state directory ('ggo worker logs --stdio'). The file is
+rotated at --worker-log-max-size MB and --worker-log-max-files rotated files
+are kept, as are that many of the logs each worker writes itself. The
+platform can change both per agent; the flags override it. While a worker is
+in a crash loop, its status carries the last error lines it printed.
+
+The agent keeps its disk use in check every minute. Staged worker releases
+that no worker runs are removed. Once the logs directory exceeds
+--max-logs-size MB, or free space on the filesystems of the cache or state
+directories drops below --disk-min-free-percent or --disk-min-free MB, rotated
+output and the logs of earlier worker starts and of deleted workers are
+removed, oldest first. The sizes are reported with the agent status; when
+pruning cannot free enough space, or the cache exceeds --max-cache-size MB, a
+disk_pressure event is raised. The platform can change the limits per agent;
+the flags override it.
+
+GPUs and workers are kept in gpus.json and workers.json. With --state-store
+sqlite they move to state.db in the state directory, an SQLite database that
+is updated in transactions and also records the history of worker restarts
+and GPU changes ('ggo agent history'). The store stays in use on later starts;
+--state-store json moves the state back to the JSON files.
+
+Before workers start, the remote-gpu-worker binary and the downloaded vGPU
+libraries are checked against the SHA-256 in the deps manifest, for the
+executable bit and for the host's architecture, so a binary built for another
+machine (e.g. amd64 on an arm64 host) fails the start instead of crashing
+every worker in a loop. With --repair-deps the agent syncs releases and
+downloads the builds for this host again instead.
+
+A watchdog checks that status reports keep completing, that the hypervisor
+answers and that reports are accepted while the platform is reachable. A
+stuck subsystem gets a dump of the goroutine stacks and timings in the
+watchdog directory of the state directory and is restarted; when that does
+not help, or the hypervisor hangs, the agent restarts itself with its workers
+running and records a watchdog event. It does so at most once an hour.
+
+With --local-api the agent serves its GPUs, workers and client sessions to
+tooling on this host, lets it trigger a reconcile and streams agent events
+(see docs/agent-local-api.md). It listens on a Unix socket (unix:<path>) or a
+loopback address only, and requests must present the token in
+<config-dir>/` + agent.LocalAPITokenFile + `.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out := getOutput()
+			if _, err := agent.ParseTLSMode(tlsMode); err != nil {
+				return cmdutil.UsageError(err)
+			}
+			transport, err := api.ParseAgentTransport(transportName)
+			if err != nil {
+				return cmdutil.UsageError(err)
+			}
+			healthCfg, xid, err := newHealthConfig(healthProbes, healthPingCmd)
+			if err != nil {
+				return err
+			}
+			reportSettings, err := newReportSettings(cmd, reportInterval, forceRefreshInterval, keepaliveInterval, changesOnly)
+			if err != nil {
+				return err
+			}
+			window, err := agent.ParseMaintenanceWindow(upgradeWindow)
+			if err != nil {
+				return cmdutil.UsageError(err)
+			}
+			if tlsMode != "" {
+				proxy = true
+			}
+			if err := recordInstance(); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
 			configMgr := config.NewManager(configDir, stateDir)
 
 			if !configMgr.ConfigExists() {
@@ -363,6 +630,18 @@ func newStartCmd() *cobra.Command {
 				}
 				return agent.ErrNotRegistered
 			}
+			if stateStore != "" {
+				backend, err := config.ParseStoreBackend(stateStore)
+				if err != nil {
+					return cmdutil.UsageError(err)
+				}
+				if err := configMgr.UseStore(backend); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to switch state store: backend=%s error=%v", backend, err)
+					return err
+				}
+			}
+			defer func() { _ = configMgr.Close() }()
 
 			cfg, err := configMgr.LoadConfig()
 			if err != nil {
@@ -397,7 +676,9 @@ func newStartCmd() *cobra.Command {
 			client := api.NewClient(
 				api.WithBaseURL(effectiveServerURL),
 				api.WithAgentSecret(cfg.AgentSecret),
+				api.WithAgentTransport(transport),
 			)
+			defer func() { _ = client.Close() }()
 
 			// Check if this machine was registered with GPUs
 			localGPUs, _ := configMgr.LoadGPUs()
@@ -409,7 +690,7 @@ func newStartCmd() *cobra.Command {
 					// GPU machine: hypervisor is required for worker management
 					cmd.SilenceUsage = true
 					if !out.IsJSON() {
-						out.Error(fmt.Sprintf("Failed to initialize GPU management: %v", hvErr))
+						out.Errorf("Failed to initialize GPU management: %v", hvErr)
 						out.Println(tui.Muted("This machine has registered GPUs but the hypervisor could not start."))
 						out.Println(tui.Muted("Please check that GPU drivers are installed and accessible."))
 					}
@@ -421,8 +702,9 @@ func newStartCmd() *cobra.Command {
 			}
 
 			var workerBinaryPath string
+			var depsMgr *deps.Manager
 			if hvMgr != nil {
-				depsMgr := deps.NewManager(
+				depsMgr = deps.NewManager(
 					deps.WithPaths(paths),
 					deps.WithAPIClient(client),
 				)
@@ -430,11 +712,16 @@ func newStartCmd() *cobra.Command {
 				if err != nil {
 					cmd.SilenceUsage = true
 					if !out.IsJSON() {
-						out.Error(fmt.Sprintf("Failed to get remote-gpu-worker binary: %v", err))
+						out.Errorf("Failed to get remote-gpu-worker binary: %v", err)
 						out.Println(tui.Muted("The agent requires remote-gpu-worker to manage workers. Please ensure the binary is available for your platform."))
 					}
 					return err
 				}
+				workerBinaryPath, err = verifyWorkerDeps(context.Background(), out, depsMgr, workerBinaryPath, repairDeps)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
 				klog.V(4).Infof("Using remote-gpu-worker binary: path=%s", workerBinaryPath)
 			}
 
@@ -444,6 +731,98 @@ func newStartCmd() *cobra.Command {
 			} else {
 				agentInstance = agent.NewAgent(client, configMgr)
 			}
+			agentInstance.SetDrainGrace(drainGrace)
+			agentInstance.SetReportSettings(reportSettings)
+			agentInstance.SetWorkerLogSettings(agent.WorkerLogSettings{MaxSizeMB: workerLogMaxSize, MaxFiles: workerLogMaxFiles})
+			agentInstance.SetDiskSettings(diskSettings)
+			if hvMgr != nil {
+				if exe, err := os.Executable(); err == nil {
+					agentInstance.EnableWorkerOutputCapture(exe)
+				} else {
+					klog.Warningf("Failed to locate ggo binary, worker output is not captured: error=%v", err)
+				}
+			}
+			if hooksDir == "" {
+				hooksDir = filepath.Join(configDir, "hooks")
+			}
+			agentInstance.EnableHooks(hooksDir, hookTimeout)
+			if execAllowlist == "" {
+				execAllowlist = filepath.Join(configDir, agent.ExecAllowlistFile)
+			}
+			execPolicy, err := agent.LoadExecPolicy(execAllowlist)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load exec allowlist: error=%v", err)
+				return err
+			}
+			if execPolicy != nil {
+				agentInstance.EnableRemoteExec(execPolicy)
+				klog.Infof("Remote exec enabled: allowlist=%s commands=%d", execAllowlist, len(execPolicy.Allow))
+			}
+			if healthInterval > 0 {
+				healthCfg.Interval = healthInterval
+				healthCfg.FailureThreshold = healthFailures
+				healthCfg.MaxRestarts = healthMaxRestarts
+				agentInstance.EnableHealthProbes(healthCfg, xid)
+			}
+			switch {
+			case workerUpgrades && depsMgr == nil:
+				out.Warning("--worker-upgrades ignored: workers are only managed with hypervisor integration")
+			case workerUpgrades:
+				current, err := depsMgr.SelectedRemoteGPUWorker()
+				if err != nil || current == nil {
+					klog.Warningf("Failed to read the installed remote-gpu-worker release, worker upgrades disabled: error=%v", err)
+					out.Warning("--worker-upgrades ignored: the installed remote-gpu-worker release is unknown")
+					break
+				}
+				if err := agentInstance.EnableWorkerUpgrades(agent.WorkerUpgradeConfig{
+					Releases:       depsMgr,
+					CurrentVersion: current.Version,
+					Window:         window,
+					CheckInterval:  upgradeCheckInterval,
+					HealthTimeout:  upgradeHealthTimeout,
+				}); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				klog.Infof("Worker upgrades enabled: version=%s window=%s", current.Version, window)
+			}
+			if relayProxy != "" {
+				if err := agentInstance.SetRelayProxy(relayProxy); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to configure relay proxy: error=%v", err)
+					return err
+				}
+			}
+			switch {
+			case proxy && hvMgr == nil:
+				// Without the hypervisor the agent does not launch workers, so
+				// it cannot move them off their listen ports
+				klog.Warningf("Connection proxy requested but hypervisor integration is disabled, proxy not started")
+				out.Warning("--proxy ignored: connection proxy requires hypervisor integration")
+			case proxy:
+				agentInstance.EnableConnectionProxy()
+				klog.Infof("Connection proxy enabled: worker ports are served by the agent for usage accounting")
+				if tlsMode != "" {
+					if err := agentInstance.EnableWorkerTLS(tlsMode); err != nil {
+						cmd.SilenceUsage = true
+						klog.Errorf("Failed to enable worker TLS: mode=%s error=%v", tlsMode, err)
+						return err
+					}
+					klog.Infof("Worker TLS enabled: mode=%s", tlsMode)
+				}
+			}
+			if !proxy && cmd.Flags().Changed("drain-grace") {
+				out.Warning("--drain-grace ignored: workers are only drained with --proxy")
+			}
+
+			if localAPI != "" {
+				if err := agentInstance.EnableLocalAPI(localAPI); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to enable local API: addr=%s error=%v", localAPI, err)
+					return err
+				}
+			}
 
 			if err := agentInstance.Start(); err != nil {
 				cmd.SilenceUsage = true
@@ -466,7 +845,28 @@ func newStartCmd() *cobra.Command {
 
 			sigCh := make(chan os.Signal, 1)
 			signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-			<-sigCh
+			select {
+			case <-sigCh:
+			case req := <-agentInstance.RestartRequests():
+				klog.Infof("Restart requested: preserve_workers=%v", req.PreserveWorkers)
+				if !out.IsJSON() {
+					out.Info("Restarting...")
+				}
+				stopped := stopWithin(restartStopTimeout, func() {
+					agentInstance.StopForRestart(req.PreserveWorkers)
+					stopHypervisorManager()
+				})
+				if !stopped {
+					klog.Warningf("Agent did not stop for the restart in time, restarting anyway: timeout=%s", restartStopTimeout)
+				}
+				klog.Flush()
+				if err := reexecAgent(); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to restart agent: error=%v", err)
+					return err
+				}
+				return nil
+			}
 
 			if !out.IsJSON() {
 				out.Info("Shutting down...")
@@ -478,15 +878,193 @@ func newStartCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&proxy, "proxy", os.Getenv("GGO_AGENT_PROXY") == "1",
+		"Proxy worker ports through the agent to account traffic and session time per client and share (or set GGO_AGENT_PROXY=1)")
+	cmd.Flags().StringVar(&tlsMode, "tls", os.Getenv("GGO_AGENT_TLS"),
+		"Terminate TLS on worker ports: self-signed or platform (or set GGO_AGENT_TLS)")
+	cmd.Flags().StringVar(&relayProxy, "relay-proxy", os.Getenv(agent.RelayProxyEnv),
+		"Proxy URL for relay tunnels (http, https or socks5; or set "+agent.RelayProxyEnv+")")
+	cmd.Flags().StringVar(&localAPI, "local-api", os.Getenv("GGO_AGENT_LOCAL_API"),
+		"Serve the local REST API on unix:<path> or a loopback host:port (or set GGO_AGENT_LOCAL_API)")
+	cmd.Flags().DurationVar(&drainGrace, "drain-grace", agent.DefaultDrainGrace,
+		"How long disabled or deleted workers keep serving connected clients before they are stopped, with --proxy (0 stops them at once)")
+	cmd.Flags().StringVar(&hooksDir, "hooks-dir", "", "Directory of lifecycle hook scripts (default <config-dir>/hooks)")
+	cmd.Flags().DurationVar(&hookTimeout, "hook-timeout", agent.DefaultHookTimeout, "Time limit for each hook script")
+	cmd.Flags().StringVar(&execAllowlist, "exec-allowlist", "", "Commands 'ggo agent exec' may run on this server (default <config-dir>/"+agent.ExecAllowlistFile+")")
+	cmd.Flags().DurationVar(&healthInterval, "health-interval", hypervisor.DefaultHealthInterval, "How often running workers are probed (0 disables health probes)")
+	cmd.Flags().StringSliceVar(&healthProbes, "health-probes", []string{hypervisor.ProbeTCP, hypervisor.ProbeXID}, "Health probes to run: tcp, xid")
+	cmd.Flags().IntVar(&healthFailures, "health-failures", hypervisor.DefaultFailureThreshold, "Failed probe rounds in a row before a worker is unhealthy")
+	cmd.Flags().IntVar(&healthMaxRestarts, "health-max-restarts", hypervisor.DefaultMaxHealthRestarts, "Restarts of an unhealthy worker per hour (0 only reports it)")
+	cmd.Flags().StringVar(&healthPingCmd, "health-ping-cmd", "", "Executable that checks a worker answers requests (exit 0 when healthy)")
+	cmd.Flags().DurationVar(&reportInterval, "report-interval", 0, "How often status is reported (default from the platform, or 30s)")
+	cmd.Flags().DurationVar(&forceRefreshInterval, "force-refresh-interval", 0, "How often all GPUs and workers are reported in full (default from the platform, or 6h)")
+	cmd.Flags().BoolVar(&changesOnly, "changes-only", false, "Skip status reports in which nothing changed and send a keepalive instead (default from the platform)")
+	cmd.Flags().DurationVar(&keepaliveInterval, "keepalive-interval", 0, "How often a keepalive is sent with --changes-only (default from the platform, or 5m)")
+	cmd.Flags().BoolVar(&workerUpgrades, "worker-upgrades", os.Getenv("GGO_AGENT_WORKER_UPGRADES") == "1",
+		"Upgrade workers to new remote-gpu-worker releases with rolling restarts (or set GGO_AGENT_WORKER_UPGRADES=1)")
+	cmd.Flags().StringSliceVar(&instanceGPUs, "gpus", nil, "UUIDs of the GPUs this --instance may use (default those it was registered with, or all)")
+	cmd.Flags().StringVar(&upgradeWindow, "upgrade-window", "", "Daily maintenance window for worker upgrades, HH:MM-HH:MM in local time (default any time)")
+	cmd.Flags().DurationVar(&upgradeCheckInterval, "upgrade-check-interval", agent.DefaultUpgradeCheckInterval, "How often to check for a new remote-gpu-worker release")
+	cmd.Flags().BoolVar(&repairDeps, "repair-deps", os.Getenv("GGO_AGENT_REPAIR_DEPS") == "1",
+		"Download the remote-gpu-worker and vGPU libraries for this host again when they fail verification (or set GGO_AGENT_REPAIR_DEPS=1)")
+	cmd.Flags().StringVar(&stateStore, "state-store", os.Getenv("GGO_AGENT_STATE_STORE"),
+		"Where GPUs, workers and their history are kept: json or sqlite (default the store in use, initially json)")
+	cmd.Flags().DurationVar(&upgradeHealthTimeout, "upgrade-health-timeout", agent.DefaultUpgradeHealthTimeout, "How long an upgraded worker has to prove healthy before the release is rolled back")
+	cmd.Flags().IntVar(&workerLogMaxSize, "worker-log-max-size", 0,
+		fmt.Sprintf("Size in MB at which captured worker output is rotated (default from the platform, or %d)", agent.DefaultWorkerLogMaxSizeMB))
+	cmd.Flags().IntVar(&workerLogMaxFiles, "worker-log-max-files", 0,
+		fmt.Sprintf("Rotated output files and worker logs kept per worker (default from the platform, or %d)", agent.DefaultWorkerLogMaxFiles))
+	cmd.Flags().IntVar(&diskSettings.MinFreePercent, "disk-min-free-percent", 0,
+		fmt.Sprintf("Free disk space in percent below which logs are pruned and disk pressure is reported (default from the platform, or %d)", agent.DefaultDiskMinFreePercent))
+	cmd.Flags().IntVar(&diskSettings.MinFreeMB, "disk-min-free", 0,
+		fmt.Sprintf("Free disk space in MB below which logs are pruned and disk pressure is reported (default from the platform, or %d)", agent.DefaultDiskMinFreeMB))
+	cmd.Flags().IntVar(&diskSettings.MaxLogsMB, "max-logs-size", 0,
+		fmt.Sprintf("Size in MB of the logs directory beyond which rotated logs are pruned (default from the platform, or %d)", agent.DefaultMaxLogsMB))
+	cmd.Flags().IntVar(&diskSettings.MaxCacheMB, "max-cache-size", 0,
+		fmt.Sprintf("Size in MB of downloaded libraries and worker releases beyond which disk pressure is reported (default from the platform, or %d)", agent.DefaultMaxCacheMB))
+
 	return cmd
 }
 
+func newRestartCmd() *cobra.Command {
+	var preserveWorkers bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "restart",
+		Short: "Restart the agent running on this machine",
+		Long: `Restart the agent running on this machine, for example after 'ggo self-update'
+replaced the ggo binary. The agent restarts in place from the binary on disk
+with its original flags, so a service manager such as systemd sees no exit.
+
+By default workers are stopped and started again. With --preserve-workers the
+worker processes keep running and serving their clients across the restart,
+and the new agent adopts them. Clients connected through the connection proxy
+(agent start --proxy) are disconnected and reconnect to the same port.
+
+Examples:
+  ggo agent restart
+  ggo agent restart --preserve-workers`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			status := agent.GetLocalStatus(agentPaths())
+			if !status.Running {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("the agent is not running on this machine")
+			}
+
+			agentStateDir := config.NewManager(configDir, stateDir).StateDir()
+			requestedAt := time.Now()
+			if err := agent.RequestRestart(agentStateDir, agent.RestartRequest{
+				RequestedAt:     requestedAt,
+				PreserveWorkers: preserveWorkers,
+			}); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to request agent restart: error=%v", err)
+				return err
+			}
+
+			deadline := time.Now().Add(timeout)
+			for agent.RestartPending(agentStateDir) {
+				if time.Now().After(deadline) {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("the agent (PID %d) did not pick up the restart request; it may use another --state-dir or predate 'ggo agent restart'", status.PID)
+				}
+				time.Sleep(200 * time.Millisecond)
+			}
+			for {
+				restarted := agent.GetLocalStatus(agentPaths())
+				if info, err := os.Stat(agentPaths().AgentPIDFile()); err == nil && restarted.Running && !info.ModTime().Before(requestedAt) {
+					message := fmt.Sprintf("Agent restarted (PID %d)", restarted.PID)
+					if preserveWorkers {
+						message += ", workers kept running"
+					}
+					return out.Render(&cmdutil.ActionData{
+						Success: true,
+						Message: message,
+						ID:      strconv.Itoa(restarted.PID),
+					})
+				}
+				if time.Now().After(deadline) {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("the agent stopped but did not start again within %s; check the agent logs", timeout)
+				}
+				time.Sleep(200 * time.Millisecond)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&preserveWorkers, "preserve-workers", false, "Keep worker processes and their client connections alive across the restart")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "How long to wait for the agent to restart")
+
+	return cmd
+}
+
+// newReportSettings builds the local status report settings. Flags that were
+// not set are left to the platform's agent config.
+func newReportSettings(cmd *cobra.Command, interval, forceRefresh, keepalive time.Duration, changesOnly bool) (agent.ReportSettings, error) {
+	if interval != 0 && interval < agent.MinReportInterval {
+		return agent.ReportSettings{}, cmdutil.UsageErrorf("--report-interval must be at least %s", agent.MinReportInterval)
+	}
+	if forceRefresh < 0 || keepalive < 0 {
+		return agent.ReportSettings{}, fmt.Errorf("--force-refresh-interval and --keepalive-interval must not be negative")
+	}
+	settings := agent.ReportSettings{
+		Interval:     interval,
+		ForceRefresh: forceRefresh,
+		Keepalive:    keepalive,
+	}
+	if cmd.Flags().Changed("changes-only") {
+		settings.ChangesOnly = &changesOnly
+	}
+	return settings, nil
+}
+
+// newHealthConfig builds the health probe settings from --health-probes and
+// --health-ping-cmd, and reports whether Xid errors are monitored
+func newHealthConfig(probes []string, pingCmd string) (hypervisor.HealthConfig, bool, error) {
+	var cfg hypervisor.HealthConfig
+	var xid bool
+	for _, probe := range probes {
+		switch probe {
+		case hypervisor.ProbeTCP:
+			cfg.TCP = true
+		case hypervisor.ProbeXID:
+			xid = true
+		default:
+			return cfg, false, cmdutil.UsageErrorf("invalid --health-probes value %q: expected tcp or xid", probe)
+		}
+	}
+	if pingCmd != "" {
+		path, err := exec.LookPath(pingCmd)
+		if err != nil {
+			return cfg, false, cmdutil.UsageErrorf("invalid --health-ping-cmd: %w", err)
+		}
+		cfg.Ping = hypervisor.CommandPing(path)
+	}
+	return cfg, xid, nil
+}
+
 func newListCmd() *cobra.Command {
+	var status string
+	var selectors []string
+	var offlineFor string
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all agents",
 		Long:  `List all registered GPU agents (machines) for the current user.`,
+		Example: `  # List offline agents labeled team=ml
+  ggo agent list --status offline -l team=ml
+
+  # List agents that have not reported for a week
+  ggo agent list --offline-for 7d`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := newAgentFilter(status, selectors, offlineFor)
+			if err != nil {
+				return err
+			}
+
 			client := getUserClient()
 			ctx := context.Background()
 			out := getOutput()
@@ -498,10 +1076,14 @@ func newListCmd() *cobra.Command {
 				return err
 			}
 
-			return out.Render(&agentListResult{agents: resp.Agents})
+			return out.Render(&agentListResult{agents: filter.apply(resp.Agents)})
 		},
 	}
 
+	cmd.Flags().StringVar(&status, "status", "", "Only list agents with this status (e.g. online, offline)")
+	cmd.Flags().StringSliceVarP(&selectors, "label", "l", nil, "Only list agents with this label (key or key=value, repeatable)")
+	cmd.Flags().StringVar(&offlineFor, "offline-for", "", "Only list agents offline for at least this long (e.g. 30d, 12h)")
+
 	return cmd
 }
 
@@ -511,7 +1093,9 @@ func getUserClient() *api.Client {
 	if token == "" {
 		token = os.Getenv("GPU_GO_USER_TOKEN")
 	}
-	if token == "" {
+	// The agent is registered with the default endpoint; never send its
+	// secret to the control plane of a CLI profile
+	if token == "" && platform.DefaultPaths().ProfileDir() == "" {
 		cfgMgr := config.NewManager(configDir, stateDir)
 		if agentCfg, err := cfgMgr.LoadConfig(); err == nil && agentCfg != nil && agentCfg.AgentSecret != "" {
 			token = agentCfg.AgentSecret
@@ -557,6 +1141,11 @@ func (r *agentListResult) RenderTUI(out *tui.Output) {
 			}
 		}
 
+		lastSeen := styles.Muted.Render("-")
+		if !a.LastSeenAt.IsZero() {
+			lastSeen = a.LastSeenAt.Local().Format("2006-01-02 15:04")
+		}
+
 		rows = append(rows, []string{
 			a.AgentID,
 			a.Hostname,
@@ -564,11 +1153,13 @@ func (r *agentListResult) RenderTUI(out *tui.Output) {
 			fmt.Sprintf("%s/%s", a.OS, a.Arch),
 			gpuSummary,
 			fmt.Sprintf("%d", len(a.Workers)),
+			lastSeen,
+			formatLabels(a.Labels),
 		})
 	}
 
 	table := tui.NewTable().
-		Headers("AGENT ID", "HOSTNAME", "STATUS", "OS/ARCH", "GPUS", "WORKERS").
+		Headers("AGENT ID", "HOSTNAME", "STATUS", "OS/ARCH", "GPUS", "WORKERS", "LAST SEEN", "LABELS").
 		Rows(rows)
 
 	out.Println(table.String())
@@ -614,7 +1205,7 @@ func (r *agentDetailResult) RenderTUI(out *tui.Output) {
 	a := r.agent
 
 	out.Println()
-	out.Println(styles.Title.Render("Agent Details"))
+	out.Println(styles.Title.Render(i18n.T("Agent Details")))
 	out.Println()
 
 	statusIcon := tui.StatusIcon(a.Status)
@@ -629,12 +1220,18 @@ func (r *agentDetailResult) RenderTUI(out *tui.Output) {
 	if len(a.NetworkIPs) > 0 {
 		status.Add("Network IPs", fmt.Sprintf("%v", a.NetworkIPs))
 	}
+	if len(a.Labels) > 0 {
+		status.Add("Labels", formatLabels(a.Labels))
+	}
+	if !a.LastSeenAt.IsZero() {
+		status.Add("Last Seen", a.LastSeenAt.Local().Format(time.RFC3339))
+	}
 
 	out.Println(status.String())
 
 	if len(a.GPUs) > 0 {
 		out.Println()
-		out.Println(styles.Subtitle.Render(fmt.Sprintf("GPUs (%d)", len(a.GPUs))))
+		out.Println(styles.Subtitle.Render(i18n.Tf("GPUs (%d)", len(a.GPUs))))
 		out.Println()
 
 		var rows [][]string
@@ -654,11 +1251,30 @@ func (r *agentDetailResult) RenderTUI(out *tui.Output) {
 			Rows(rows)
 
 		out.Println(table.String())
+
+		var partRows [][]string
+		for _, g := range a.GPUs {
+			for _, p := range g.Partitions {
+				worker := p.WorkerID
+				if worker == "" {
+					worker = "-"
+				}
+				partRows = append(partRows, []string{g.GPUID, p.Profile, p.UUID, worker})
+			}
+		}
+		if len(partRows) > 0 {
+			out.Println()
+			out.Println(styles.Subtitle.Render(i18n.Tf("MIG Instances (%d)", len(partRows))))
+			out.Println()
+			out.Println(tui.NewTable().
+				Headers("GPU ID", "PROFILE", "UUID", "WORKER").
+				Rows(partRows).String())
+		}
 	}
 
 	if len(a.Workers) > 0 {
 		out.Println()
-		out.Println(styles.Subtitle.Render(fmt.Sprintf("Workers (%d)", len(a.Workers))))
+		out.Println(styles.Subtitle.Render(i18n.Tf("Workers (%d)", len(a.Workers))))
 		out.Println()
 
 		var rows [][]string
@@ -682,10 +1298,28 @@ func (r *agentDetailResult) RenderTUI(out *tui.Output) {
 }
 
 func newStatusCmd() *cobra.Command {
+	var (
+		watch    bool
+		interval time.Duration
+	)
+
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show agent status",
-		Long:  `Show the current status of the GPU agent (server-side and local).`,
+		Long: `Show the current status of the GPU agent (server-side and local).
+The license row warns from a week before the license expires; renew it with
+'ggo agent license' on hosts that cannot reach the platform.
+
+With --watch, show a live dashboard of GPUs, workers and client connections
+on this machine, refreshed until interrupted.`,
+		Example: `  # Show agent status
+  ggo agent status
+
+  # Live dashboard, refreshed every 2 seconds
+  ggo agent status --watch
+
+  # Refresh every 5 seconds
+  ggo agent status --watch --interval 5s`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out := getOutput()
 			configMgr := config.NewManager(configDir, stateDir)
@@ -701,8 +1335,13 @@ func newStatusCmd() *cobra.Command {
 				return out.Render(&agentStatusResult{registered: false})
 			}
 
+			if watch {
+				cmd.SilenceUsage = true
+				return runStatusDashboard(out, cfg, interval)
+			}
+
 			// Get local status by checking PID file
-			localStatus := agent.GetLocalStatus(paths)
+			localStatus := agent.GetLocalStatus(agentPaths())
 
 			// Get server-side status
 			client := api.NewClient(
@@ -710,25 +1349,40 @@ func newStatusCmd() *cobra.Command {
 				api.WithAgentSecret(cfg.AgentSecret),
 			)
 
+			// Hosts without access to the platform still see their local
+			// status and license expiry
 			ctx := context.Background()
-			agentConfig, err := client.GetAgentConfig(ctx, cfg.AgentID)
-			if err != nil {
+			agentConfig, fetchErr := client.GetAgentConfig(ctx, cfg.AgentID)
+			if fetchErr != nil {
 				cmd.SilenceUsage = true
 				if !out.IsJSON() {
-					out.Error(fmt.Sprintf("Failed to fetch config from server: %v", err))
+					out.Errorf("Failed to fetch config from server: %v", fetchErr)
 				}
-				return err
 			}
 
-			return out.Render(&agentStatusResult{
+			// The running agent's transport health, from its live snapshot
+			var live *agent.LiveStatus
+			if snapshot, err := agent.ReadLiveStatus(agentPaths()); err == nil && snapshot != nil && localStatus.Running &&
+				snapshot.PID == localStatus.PID && time.Since(snapshot.UpdatedAt) < liveStaleAfter {
+				live = snapshot
+			}
+
+			if err := out.Render(&agentStatusResult{
 				registered:  true,
 				cfg:         cfg,
 				agentConfig: agentConfig,
 				localStatus: localStatus,
-			})
+				live:        live,
+			}); err != nil {
+				return err
+			}
+			return fetchErr
 		},
 	}
 
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Show a live dashboard of local GPUs, workers and connections")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Dashboard refresh interval (with --watch)")
+
 	return cmd
 }
 
@@ -738,6 +1392,8 @@ type agentStatusResult struct {
 	cfg         *config.Config
 	agentConfig *api.AgentConfigResponse
 	localStatus agent.LocalStatus
+	// live is the running agent's live snapshot, nil if unavailable
+	live *agent.LiveStatus
 }
 
 func (r *agentStatusResult) RenderJSON() any {
@@ -764,6 +1420,28 @@ func (r *agentStatusResult) RenderJSON() any {
 		},
 	}
 
+	if exp := agent.LicenseExpiration(r.cfg.License); exp != nil {
+		result["license_expires_at"] = time.UnixMilli(*exp).UTC()
+		if status := agent.LicenseStatus(exp, time.Now()); status != "" {
+			result["license_status"] = status
+		}
+	}
+
+	if r.live != nil {
+		result["heartbeat_mode"] = r.live.HeartbeatMode
+		result["transport"] = r.live.Transport
+		result["last_report_at"] = r.live.LastReportAt
+		if r.live.Disk != nil {
+			result["disk"] = r.live.Disk
+		}
+		if r.live.OfflineSince != nil {
+			result["offline_since"] = r.live.OfflineSince
+		}
+		if r.live.QueuedReports > 0 {
+			result["queued_reports"] = r.live.QueuedReports
+		}
+	}
+
 	if r.agentConfig != nil {
 		result["config_version"] = r.agentConfig.ConfigVersion
 
@@ -791,7 +1469,7 @@ func (r *agentStatusResult) RenderTUI(out *tui.Output) {
 	}
 
 	out.Println()
-	out.Println(styles.Title.Render("Agent Status"))
+	out.Println(styles.Title.Render(i18n.T("Agent Status")))
 	out.Println()
 
 	configVersion := r.cfg.ConfigVersion
@@ -816,13 +1494,32 @@ func (r *agentStatusResult) RenderTUI(out *tui.Output) {
 		Add("Config Version", fmt.Sprintf("%d", configVersion)).
 		Add("Server URL", r.cfg.ServerURL).
 		Add("Local Status", localStateStyled).
-		Add("Local PID", localPID)
+		Add("Local PID", localPID).
+		Add("License", formatLicenseExpiry(agent.LicenseExpiration(r.cfg.License), time.Now(), styles))
+
+	if r.live != nil {
+		now := time.Now()
+		status.Add("Heartbeat", formatHeartbeat(r.live, styles)).
+			Add("Last Report", formatLastSuccess(r.live.LastReportAt, now, styles))
+		if platform := formatPlatformReachability(r.live, now, styles); platform != "" {
+			status.Add("Platform", platform)
+		}
+		if t := r.live.Transport; t != nil {
+			status.Add("Last SSE", formatLastSuccess(t.LastSSEAt, now, styles))
+			if !t.LastPollAt.IsZero() {
+				status.Add("Last Long Poll", formatLastSuccess(t.LastPollAt, now, styles))
+			}
+		}
+		if r.live.Disk != nil {
+			status.Add("Disk", formatDiskUsage(r.live.Disk, styles))
+		}
+	}
 
 	out.Println(status.String())
 
 	if r.agentConfig != nil && len(r.agentConfig.Workers) > 0 {
 		out.Println()
-		out.Println(styles.Subtitle.Render(fmt.Sprintf("Workers (%d)", len(r.agentConfig.Workers))))
+		out.Println(styles.Subtitle.Render(i18n.Tf("Workers (%d)", len(r.agentConfig.Workers))))
 		out.Println()
 
 		var rows [][]string
@@ -854,7 +1551,7 @@ func discoverGPUs() ([]api.GPUInfo, error) {
 			count = 1
 		}
 		klog.Infof("Using mock GPUs for testing: count=%d", count)
-		return agent.CreateMockGPUs(count), nil
+		return filterInstanceGPUs(agent.CreateMockGPUs(count)), nil
 	}
 
 	hvMgr, err := getHypervisorManager()
@@ -867,7 +1564,7 @@ func discoverGPUs() ([]api.GPUInfo, error) {
 		return nil, fmt.Errorf("failed to list devices: %w", err)
 	}
 
-	return agent.ConvertDevicesToGPUInfo(devices), nil
+	return filterInstanceGPUs(agent.ConvertDevicesToGPUInfo(devices)), nil
 }
 
 func boolToYesNo(b bool) string {
@@ -0,0 +1,337 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/agent"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/charmbracelet/lipgloss"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// liveStaleAfter is how old the agent's live snapshot may get before the
+	// dashboard stops trusting it (the agent rewrites it every few seconds)
+	liveStaleAfter = 10 * time.Second
+	utilBarWidth   = 20
+
+	// ANSI sequences used to redraw the dashboard in place
+	ansiClearScreen = "\033[H\033[2J"
+	ansiHideCursor  = "\033[?25l"
+	ansiShowCursor  = "\033[?25h"
+)
+
+// runStatusDashboard redraws the agent status every interval until interrupted
+func runStatusDashboard(out *tui.Output, cfg *config.Config, interval time.Duration) error {
+	if interval <= 0 {
+		return cmdutil.UsageErrorf("--interval must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if !out.IsJSON() {
+		fmt.Print(ansiHideCursor)
+		defer fmt.Print(ansiShowCursor)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		live, err := agent.ReadLiveStatus(agentPaths())
+		if err != nil {
+			klog.V(4).Infof("Failed to read live status: error=%v", err)
+		}
+		dash := &agentDashboard{
+			cfg:         cfg,
+			localStatus: agent.GetLocalStatus(agentPaths()),
+			live:        live,
+			connections: agent.ReadConnections(agentPaths()),
+			interval:    interval,
+			now:         time.Now(),
+		}
+		if !out.IsJSON() {
+			fmt.Print(ansiClearScreen)
+		}
+		if err := out.Render(dash); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// agentDashboard implements Renderable for one frame of `agent status --watch`
+type agentDashboard struct {
+	cfg         *config.Config
+	localStatus agent.LocalStatus
+	live        *agent.LiveStatus
+	connections map[string][]api.ConnectionInfo
+	interval    time.Duration
+	now         time.Time
+}
+
+// fresh reports whether the live snapshot belongs to the running agent
+func (d *agentDashboard) fresh() bool {
+	return d.live != nil && d.localStatus.Running &&
+		d.live.PID == d.localStatus.PID &&
+		d.now.Sub(d.live.UpdatedAt) < liveStaleAfter
+}
+
+func (d *agentDashboard) RenderJSON() any {
+	localState := stateStopped
+	if d.localStatus.Running {
+		localState = stateRunning
+	}
+	result := map[string]any{
+		"agent_id":    d.cfg.AgentID,
+		"timestamp":   d.now,
+		"state":       localState,
+		"pid":         d.localStatus.PID,
+		"connections": d.connections,
+	}
+	if d.fresh() {
+		result["heartbeat_mode"] = d.live.HeartbeatMode
+		if d.live.Transport != nil {
+			result["transport"] = d.live.Transport
+		}
+		result["gpus"] = d.live.GPUs
+		result["workers"] = d.live.Workers
+		if !d.live.LastReportAt.IsZero() {
+			result["last_report_at"] = d.live.LastReportAt
+		}
+		if d.live.OfflineSince != nil {
+			result["offline_since"] = d.live.OfflineSince
+		}
+		if d.live.QueuedReports > 0 {
+			result["queued_reports"] = d.live.QueuedReports
+		}
+	}
+	return result
+}
+
+func (d *agentDashboard) RenderTUI(out *tui.Output) {
+	styles := tui.DefaultStyles()
+
+	out.Println()
+	out.Println(styles.Title.Render(i18n.T("Agent Dashboard")) + "  " +
+		tui.Muted(fmt.Sprintf("%s · refreshed %s · every %s · Ctrl+C to exit",
+			d.cfg.AgentID, d.now.Format("15:04:05"), d.interval)))
+	out.Println()
+
+	localState := stateStopped
+	localPID := "-"
+	if d.localStatus.Running {
+		localState = stateRunning
+		localPID = strconv.Itoa(d.localStatus.PID)
+	}
+	status := tui.NewStatusTable().
+		Add("Local Status", styles.StatusStyle(localState).Render(tui.StatusIcon(localState)+" "+localState)).
+		Add("Local PID", localPID)
+
+	if !d.fresh() {
+		out.Println(status.String())
+		out.Println()
+		if d.localStatus.Running {
+			out.Warning("No live data from the agent yet; restart it with this ggo version if this persists")
+		} else {
+			out.Warning("Agent is not running; start it with 'ggo agent start'")
+		}
+		return
+	}
+
+	heartbeat := formatHeartbeat(d.live, styles)
+	status.Add("Heartbeat", heartbeat).Add("Last Report", formatLastSuccess(d.live.LastReportAt, d.now, styles))
+	if platform := formatPlatformReachability(d.live, d.now, styles); platform != "" {
+		status.Add("Platform", platform)
+	}
+	out.Println(status.String())
+
+	d.renderGPUs(out, styles)
+	d.renderWorkers(out, styles)
+	d.renderConnections(out, styles)
+}
+
+// formatHeartbeat describes how the agent receives pushes from the platform
+func formatHeartbeat(live *agent.LiveStatus, styles *tui.Styles) string {
+	var heartbeat string
+	switch live.HeartbeatMode {
+	case agent.HeartbeatModeGRPC:
+		heartbeat = styles.Success.Render(tui.StatusIcon("connected") + " push (grpc)")
+	case agent.HeartbeatModeSSE:
+		heartbeat = styles.Success.Render(tui.StatusIcon("connected") + " push (sse)")
+	case agent.HeartbeatModeLongPoll:
+		heartbeat = styles.Warning.Render(tui.StatusIcon("pending") + " long polling (sse unavailable)")
+	default:
+		heartbeat = styles.Warning.Render(tui.StatusIcon("pending") + " polling")
+	}
+	if t := live.Transport; t != nil && (t.Reconnects > 0 || t.Fallbacks > 0) {
+		heartbeat += tui.Muted(fmt.Sprintf(" · %d reconnects, %d fallbacks", t.Reconnects, t.Fallbacks))
+	}
+	return heartbeat
+}
+
+// formatPlatformReachability describes a platform outage and the status
+// reports queued for delivery; empty while reports are delivered as usual
+func formatPlatformReachability(live *agent.LiveStatus, now time.Time, styles *tui.Styles) string {
+	queued := ""
+	if live.QueuedReports > 0 {
+		queued = i18n.Tf("%d reports queued", live.QueuedReports)
+	}
+	if live.OfflineSince == nil {
+		if queued == "" {
+			return ""
+		}
+		return styles.Warning.Render(tui.StatusIcon("pending") + " " + i18n.T("reachable, backfilling") + " · " + queued)
+	}
+	offline := i18n.Tf("unreachable since %s (%s)", live.OfflineSince.Local().Format("15:04:05"), now.Sub(*live.OfflineSince).Truncate(time.Second))
+	if queued != "" {
+		offline += " · " + queued
+	}
+	return styles.Error.Render(tui.StatusIcon("disconnected") + " " + offline)
+}
+
+// formatDiskUsage describes the space the agent's cache and logs take and
+// the free space left, flagged while the agent reports disk pressure
+func formatDiskUsage(disk *api.AgentDiskUsage, styles *tui.Styles) string {
+	usage := fmt.Sprintf("cache %d MiB, logs %d MiB", disk.CacheBytes>>20, disk.LogsBytes>>20)
+	if disk.TotalBytes > 0 {
+		usage += fmt.Sprintf(", %d of %d MiB free", disk.FreeBytes>>20, disk.TotalBytes>>20)
+	}
+	if disk.Pressure {
+		return styles.Warning.Render(tui.StatusIcon("pending") + " " + usage + " (" + i18n.T("disk pressure") + ")")
+	}
+	return usage
+}
+
+// formatLastSuccess formats when something last succeeded, or "never"
+func formatLastSuccess(t, now time.Time, styles *tui.Styles) string {
+	if t.IsZero() {
+		return styles.Muted.Render(i18n.T("never"))
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Local().Format("15:04:05"), now.Sub(t).Truncate(time.Second))
+}
+
+func (d *agentDashboard) renderGPUs(out *tui.Output, styles *tui.Styles) {
+	out.Println()
+	out.Println(styles.Subtitle.Render(i18n.Tf("GPUs (%d)", len(d.live.GPUs))))
+	out.Println()
+	if len(d.live.GPUs) == 0 {
+		out.Println(tui.Muted("  No GPUs reported by the hypervisor"))
+		return
+	}
+
+	rows := make([][]string, 0, len(d.live.GPUs))
+	for _, g := range d.live.GPUs {
+		vram := "-"
+		if g.VRAMTotalMb > 0 {
+			vram = fmt.Sprintf("%s %d/%d MiB", utilBar(float64(g.VRAMUsedMb)*100/float64(g.VRAMTotalMb), styles),
+				g.VRAMUsedMb, g.VRAMTotalMb)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(g.GPUIndex),
+			g.Model,
+			fmt.Sprintf("%s %5.1f%%", utilBar(g.Utilization, styles), g.Utilization),
+			vram,
+		})
+	}
+	out.Println(tui.NewTable().Headers("IDX", "MODEL", "UTILIZATION", "VRAM").Rows(rows).String())
+}
+
+func (d *agentDashboard) renderWorkers(out *tui.Output, styles *tui.Styles) {
+	out.Println()
+	out.Println(styles.Subtitle.Render(i18n.Tf("Workers (%d)", len(d.live.Workers))))
+	out.Println()
+	if len(d.live.Workers) == 0 {
+		out.Println(tui.Muted("  No workers"))
+		return
+	}
+
+	rows := make([][]string, 0, len(d.live.Workers))
+	for _, w := range d.live.Workers {
+		pid := "-"
+		if w.PID > 0 {
+			pid = strconv.Itoa(w.PID)
+		}
+		restarts := strconv.Itoa(w.Restarts)
+		if w.Restarts > 0 {
+			restarts = styles.Warning.Render(restarts)
+		}
+		state := styles.StatusStyle(w.Status).Render(tui.StatusIcon(w.Status) + " " + w.Status)
+		if w.Warm {
+			state += tui.Muted(" (" + i18n.T("warm") + ")")
+		}
+		rows = append(rows, []string{
+			w.WorkerID,
+			state,
+			pid,
+			restarts,
+			strings.Join(w.GPUIDs, ","),
+			strconv.Itoa(len(d.connections[w.WorkerID])),
+		})
+	}
+	out.Println(tui.NewTable().Headers("ID", "STATE", "PID", "RESTARTS", "GPUS", "CLIENTS").Rows(rows).String())
+}
+
+func (d *agentDashboard) renderConnections(out *tui.Output, styles *tui.Styles) {
+	workerIDs := make([]string, 0, len(d.connections))
+	for id := range d.connections {
+		workerIDs = append(workerIDs, id)
+	}
+	sort.Strings(workerIDs)
+
+	var rows [][]string
+	for _, id := range workerIDs {
+		for _, c := range d.connections[id] {
+			port, pid := "-", "-"
+			if c.ClientPort > 0 {
+				port = strconv.Itoa(c.ClientPort)
+			}
+			if c.ClientPID > 0 {
+				pid = strconv.Itoa(c.ClientPID)
+			}
+			rows = append(rows, []string{id, c.ClientIP, port, pid})
+		}
+	}
+
+	out.Println()
+	out.Println(styles.Subtitle.Render(i18n.Tf("Client Connections (%d)", len(rows))))
+	out.Println()
+	if len(rows) == 0 {
+		out.Println(tui.Muted("  No active connections"))
+		return
+	}
+	out.Println(tui.NewTable().Headers("WORKER", "CLIENT IP", "PORT", "CLIENT PID").Rows(rows).String())
+}
+
+// utilBar draws a fixed-width percentage bar colored by load
+func utilBar(pct float64, styles *tui.Styles) string {
+	pct = max(0, min(100, pct))
+	filled := int(pct/100*utilBarWidth + 0.5)
+
+	style := styles.Success
+	switch {
+	case pct >= 90:
+		style = styles.Error
+	case pct >= 70:
+		style = styles.Warning
+	}
+	return style.Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(tui.DefaultTheme().TextDim).Render(strings.Repeat("░", utilBarWidth-filled))
+}
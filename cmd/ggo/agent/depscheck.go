@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"k8s.io/klog/v2"
+)
+
+// verifyWorkerDeps checks the remote-gpu-worker binary and vGPU libraries
+// before workers are started with them. With repair, broken artifacts are
+// downloaded again for this host and the worker binary is resolved anew.
+func verifyWorkerDeps(ctx context.Context, out *tui.Output, depsMgr *deps.Manager, workerBinaryPath string, repair bool) (string, error) {
+	issues, err := depsMgr.VerifyInstalled(ctx, deps.AgentLibraryTypes)
+	if err != nil {
+		klog.Errorf("Failed to verify worker dependencies: error=%v", err)
+		return "", err
+	}
+	if len(issues) == 0 {
+		return workerBinaryPath, nil
+	}
+	for _, issue := range issues {
+		klog.Warningf("Worker dependency failed verification: library=%s kind=%s detail=%s", issue.Library, issue.Kind, issue.Detail)
+	}
+
+	if repair {
+		klog.Infof("Downloading worker dependencies for this host again: issues=%d", len(issues))
+		issues, err = depsMgr.RepairInstalled(ctx, deps.AgentLibraryTypes)
+		if err != nil {
+			klog.Errorf("Failed to repair worker dependencies: error=%v", err)
+			if !out.IsJSON() {
+				out.Errorf("Failed to repair worker dependencies: %v", err)
+			}
+			return "", err
+		}
+		if len(issues) == 0 {
+			if !out.IsJSON() {
+				out.Success("Worker dependencies downloaded again for this host")
+			}
+			return depsMgr.GetRemoteGPUWorkerPath(ctx)
+		}
+	}
+
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = "  - " + issue.String()
+	}
+	report := strings.Join(lines, "\n")
+	if !out.IsJSON() {
+		out.Errorf("Worker dependencies failed verification:\n%s", report)
+		if repair {
+			out.Println(tui.Muted("The published release for this platform is broken; report it or pin another version with 'ggo deps pin'."))
+		} else {
+			out.Println(tui.Muted("Run 'ggo agent start --repair-deps' to download them for this host again."))
+		}
+	}
+	return "", cmdutil.DependencyError(fmt.Errorf("worker dependencies are not usable on this host:\n%s", report))
+}
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/agent"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newExecCmd() *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "exec <agent-id> -- <command> [args...]",
+		Short: "Run a diagnostic command on an agent",
+		Long: `Run a diagnostic command on a GPU server through its agent, for debugging
+a fleet without logging in to each machine. Output is streamed back and ggo
+exits with the command's exit status.
+
+Agents only run commands on the allowlist in their config directory
+(` + agent.ExecAllowlistFile + `), which the operator of the server maintains:
+
+  {"allow": ["nvidia-smi", "nvidia-smi -q *", "dmesg -T"], "timeout_seconds": 60}
+
+An entry matches the command word by word; a trailing * accepts any further
+arguments. Commands run without a shell, are stopped after the timeout, and
+their output is cut off after 1 MiB (max_output_bytes). Agents without an
+allowlist refuse every command. Every request is recorded in the agent's
+exec-audit.log and on its event timeline.`,
+		Example: `  # Show GPUs on a server
+  ggo agent exec agent_xxx -- nvidia-smi
+
+  # Detailed ECC state, allowed by "nvidia-smi -q *"
+  ggo agent exec agent_xxx --timeout 1m -- nvidia-smi -q -d ECC`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+				return fmt.Errorf("usage: ggo agent exec <agent-id> -- <command> [args...]")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentID, command := args[0], args[1:]
+			out := getOutput()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			result := &execResult{AgentID: agentID, Command: command}
+			stdout, stderr := cmd.OutOrStdout(), cmd.ErrOrStderr()
+			if out.IsJSON() {
+				stdout, stderr = io.Discard, io.Discard
+			}
+			req := &api.AgentExecStartRequest{Command: command, TimeoutSeconds: int(timeout.Seconds())}
+			err := getUserClient().ExecAgentCommand(ctx, agentID, req, func(chunk *api.AgentExecChunk) error {
+				result.add(chunk)
+				_, _ = io.WriteString(stdout, chunk.Stdout)
+				_, _ = io.WriteString(stderr, chunk.Stderr)
+				return nil
+			})
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to run remote command: agent_id=%s command=%q error=%v", agentID, command, err)
+				return err
+			}
+			if out.IsJSON() {
+				if err := out.Render(result); err != nil {
+					return err
+				}
+			} else if result.Truncated {
+				out.Warning("Output was truncated by the agent's output limit")
+			}
+
+			cmd.SilenceUsage = true
+			if result.Error != "" {
+				klog.Errorf("Remote command failed: agent_id=%s command=%q error=%s", agentID, command, result.Error)
+				return fmt.Errorf("remote command failed: %s", result.Error)
+			}
+			if result.ExitCode != nil && *result.ExitCode != 0 {
+				return &remoteExitError{code: *result.ExitCode}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", agent.DefaultExecTimeout, "Time limit for the command (capped by the agent's allowlist)")
+
+	return cmd
+}
+
+// remoteExitError passes a remote command's exit status through to ggo's
+type remoteExitError struct {
+	code int
+}
+
+func (e *remoteExitError) Error() string {
+	return fmt.Sprintf("remote command exited with status %d", e.code)
+}
+
+// ExitCode returns the remote exit status for the process to exit with
+func (e *remoteExitError) ExitCode() int {
+	return e.code
+}
+
+// execResult implements Renderable for a remote command
+type execResult struct {
+	AgentID   string   `json:"agent_id"`
+	Command   []string `json:"command"`
+	Stdout    string   `json:"stdout"`
+	Stderr    string   `json:"stderr"`
+	ExitCode  *int     `json:"exit_code,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func (r *execResult) add(chunk *api.AgentExecChunk) {
+	r.Stdout += chunk.Stdout
+	r.Stderr += chunk.Stderr
+	if chunk.EOF {
+		r.ExitCode, r.Truncated, r.Error = chunk.ExitCode, chunk.Truncated, chunk.Error
+	}
+}
+
+func (r *execResult) RenderJSON() any {
+	return tui.NewDetailResult(r)
+}
+
+// RenderTUI does nothing; the output was streamed as it arrived
+func (r *execResult) RenderTUI(_ *tui.Output) {}
@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/inventory"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newExportCmd() *cobra.Command {
+	var format string
+	var columns []string
+	var since string
+	var status string
+	var selectors []string
+
+	cmd := &cobra.Command{
+		Use:       "export [agents|gpus|workers|shares]",
+		Short:     "Export the fleet inventory as CSV, JSON or NDJSON",
+		ValidArgs: []string{"agents", "gpus", "workers", "shares"},
+		Long: `Export agents, GPUs, workers or shares from the platform for CMDBs and
+spreadsheets. Rows are written to stdout as they are encoded; redirect to a
+file to save them.
+
+Columns:
+  agents:  ` + strings.Join(inventory.ColumnNames(inventory.ResourceAgents), ", ") + `
+  gpus:    ` + strings.Join(inventory.ColumnNames(inventory.ResourceGPUs), ", ") + `
+  workers: ` + strings.Join(inventory.ColumnNames(inventory.ResourceWorkers), ", ") + `
+  shares:  ` + strings.Join(inventory.ColumnNames(inventory.ResourceShares), ", ") + `
+
+In CSV, lists are joined with ';' and labels are written as key=value pairs.
+--since keeps rows created, started or seen within the window; GPUs follow
+their agent. --status and --label select agents, and the GPUs, workers and
+shares on them.`,
+		Example: `  # All agents as CSV
+  ggo agent export > agents.csv
+
+  # GPU UUIDs, models and driver versions as NDJSON
+  ggo agent export gpus --format ndjson --columns gpu_id,hostname,model,vram_mb,driver_version,cuda_version
+
+  # Workers of agents labeled team=ml that started in the last day
+  ggo agent export workers -l team=ml --since 24h --format json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resource := inventory.ResourceAgents
+			if len(args) > 0 {
+				r, err := inventory.ParseResource(args[0])
+				if err != nil {
+					return err
+				}
+				resource = r
+			}
+			exportFormat, err := inventory.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+			from, err := parseSince(since)
+			if err != nil {
+				return err
+			}
+			filter, err := newAgentFilter(status, selectors, "")
+			if err != nil {
+				return err
+			}
+
+			client := getUserClient()
+			ctx := context.Background()
+
+			n, err := exportInventory(ctx, client, resource, exportFormat, columns, from, filter)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to export inventory: resource=%s error=%v", resource, err)
+				return err
+			}
+			klog.V(2).Infof("Exported inventory: resource=%s rows=%d", resource, n)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", string(inventory.FormatCSV), "Output format (csv, json, ndjson)")
+	cmd.Flags().StringSliceVar(&columns, "columns", nil, "Columns to export, in order (default: all)")
+	cmd.Flags().StringVar(&since, "since", "", "Only export rows changed within this window (e.g. 7d, 12h) or after an RFC 3339 time")
+	cmd.Flags().StringVar(&status, "status", "", "Only export agents with this status (e.g. online, offline)")
+	cmd.Flags().StringSliceVarP(&selectors, "label", "l", nil, "Only export agents with this label (key or key=value, repeatable)")
+
+	return cmd
+}
+
+// parseSince accepts an age such as 7d or an absolute RFC 3339 time
+func parseSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	d, err := cmdutil.ParseAge(since)
+	if err != nil {
+		return time.Time{}, cmdutil.UsageErrorf("invalid --since %q: expected an age (e.g. 7d, 12h) or an RFC 3339 time", since)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// exportInventory fetches one resource and streams it to stdout. Workers and
+// shares are fetched only when exported or needed to apply an agent filter.
+func exportInventory(ctx context.Context, client *api.Client, resource inventory.Resource,
+	format inventory.Format, columns []string, since time.Time, filter *agentFilter) (int, error) {
+	var agents []api.AgentInfo
+	if resource == inventory.ResourceAgents || resource == inventory.ResourceGPUs || !filter.empty() {
+		resp, err := client.ListAgents(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list agents: %w", err)
+		}
+		agents = filter.apply(resp.Agents)
+	}
+
+	switch resource {
+	case inventory.ResourceAgents:
+		return inventory.Export(os.Stdout, format, inventory.AgentTable, columns, agents, since)
+	case inventory.ResourceGPUs:
+		return inventory.Export(os.Stdout, format, inventory.GPUTable, columns, inventory.GPURows(agents), since)
+	}
+
+	resp, err := client.ListWorkers(ctx, "", "")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list workers: %w", err)
+	}
+	workers := resp.Workers
+	if !filter.empty() {
+		ids := make([]string, len(agents))
+		for i := range agents {
+			ids[i] = agents[i].AgentID
+		}
+		workers = slices.DeleteFunc(workers, func(w api.WorkerInfo) bool { return !slices.Contains(ids, w.AgentID) })
+	}
+	if resource == inventory.ResourceWorkers {
+		return inventory.Export(os.Stdout, format, inventory.WorkerTable, columns, workers, since)
+	}
+
+	shares, err := client.ListShares(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list shares: %w", err)
+	}
+	rows := inventory.ShareRows(shares.Shares, workers)
+	if !filter.empty() {
+		rows = slices.DeleteFunc(rows, func(s inventory.Share) bool { return s.AgentID == "" })
+	}
+	return inventory.Export(os.Stdout, format, inventory.ShareTable, columns, rows, since)
+}
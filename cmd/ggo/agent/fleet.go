@@ -0,0 +1,371 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+)
+
+const agentStatusOnline = "online"
+
+// labelKeyPattern restricts label keys to a portable, shell-friendly charset
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+// agentFilter selects agents for list and bulk delete
+type agentFilter struct {
+	status     string
+	labels     map[string]string
+	offlineFor time.Duration
+}
+
+func (f *agentFilter) empty() bool {
+	return f.status == "" && len(f.labels) == 0 && f.offlineFor == 0
+}
+
+func (f *agentFilter) match(a *api.AgentInfo, now time.Time) bool {
+	if f.status != "" && !strings.EqualFold(a.Status, f.status) {
+		return false
+	}
+	for k, v := range f.labels {
+		if got, ok := a.Labels[k]; !ok || (v != "" && got != v) {
+			return false
+		}
+	}
+	if f.offlineFor > 0 {
+		if a.Status == agentStatusOnline || now.Sub(a.LastSeenAt) < f.offlineFor {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *agentFilter) apply(agents []api.AgentInfo) []api.AgentInfo {
+	if f.empty() {
+		return agents
+	}
+	now := time.Now()
+	var matched []api.AgentInfo
+	for i := range agents {
+		if f.match(&agents[i], now) {
+			matched = append(matched, agents[i])
+		}
+	}
+	return matched
+}
+
+// newAgentFilter builds a filter from the shared --status/--label/--offline-for flags
+func newAgentFilter(status string, selectors []string, offlineFor string) (*agentFilter, error) {
+	f := &agentFilter{status: status, labels: map[string]string{}}
+	for _, sel := range selectors {
+		key, value, _ := strings.Cut(sel, "=")
+		if !labelKeyPattern.MatchString(key) {
+			return nil, cmdutil.UsageErrorf("invalid label selector %q", sel)
+		}
+		f.labels[key] = value
+	}
+	if offlineFor != "" {
+		d, err := cmdutil.ParseAge(offlineFor)
+		if err != nil {
+			return nil, cmdutil.UsageErrorf("invalid --offline-for %q: %w", offlineFor, err)
+		}
+		f.offlineFor = d
+	}
+	return f, nil
+}
+
+// parseLabelArgs parses "key=value" (set) and "key-" (remove) arguments
+func parseLabelArgs(args []string) (map[string]string, []string, error) {
+	set := map[string]string{}
+	var remove []string
+	for _, arg := range args {
+		if key, ok := strings.CutSuffix(arg, "-"); ok && !strings.Contains(arg, "=") {
+			if !labelKeyPattern.MatchString(key) {
+				return nil, nil, cmdutil.UsageErrorf("invalid label key %q", key)
+			}
+			remove = append(remove, key)
+			continue
+		}
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, nil, cmdutil.UsageErrorf("invalid label %q: expected key=value or key-", arg)
+		}
+		if !labelKeyPattern.MatchString(key) {
+			return nil, nil, cmdutil.UsageErrorf("invalid label key %q", key)
+		}
+		set[key] = value
+	}
+	return set, remove, nil
+}
+
+// formatLabels renders labels as sorted "k=v" pairs
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func newLabelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "label <agent-id> [key=value ...] [key- ...]",
+		Short: "Set or remove agent labels",
+		Long: `Attach arbitrary key=value metadata to an agent. A trailing dash removes
+a label. Without label arguments the agent's current labels are shown.
+
+Labels can be used to filter 'ggo agent list' and 'ggo agent delete'.`,
+		Example: `  # Label an agent
+  ggo agent label agent_xxx rack=a1 team=ml
+
+  # Remove a label
+  ggo agent label agent_xxx team-`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentID := args[0]
+			set, remove, err := parseLabelArgs(args[1:])
+			if err != nil {
+				return err
+			}
+
+			client := getUserClient()
+			ctx := context.Background()
+			out := getOutput()
+
+			agent, err := client.GetAgent(ctx, agentID)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to get agent: agent_id=%s error=%v", agentID, err)
+				return err
+			}
+
+			if len(set) == 0 && len(remove) == 0 {
+				return out.Render(&agentLabelsResult{agentID: agentID, labels: agent.Labels})
+			}
+
+			labels := make(map[string]string, len(agent.Labels)+len(set))
+			for k, v := range agent.Labels {
+				labels[k] = v
+			}
+			for k, v := range set {
+				labels[k] = v
+			}
+			for _, k := range remove {
+				delete(labels, k)
+			}
+
+			updated, err := client.UpdateAgent(ctx, agentID, &api.AgentUpdateRequest{Labels: labels})
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to update agent labels: agent_id=%s error=%v", agentID, err)
+				return err
+			}
+
+			return out.Render(&agentLabelsResult{agentID: agentID, labels: updated.Labels})
+		},
+	}
+
+	return cmd
+}
+
+// agentLabelsResult implements Renderable for agent labels
+type agentLabelsResult struct {
+	agentID string
+	labels  map[string]string
+}
+
+func (r *agentLabelsResult) RenderJSON() any {
+	labels := r.labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return tui.NewDetailResult(map[string]any{"agent_id": r.agentID, "labels": labels})
+}
+
+func (r *agentLabelsResult) RenderTUI(out *tui.Output) {
+	if len(r.labels) == 0 {
+		out.Infof("Agent %s has no labels", r.agentID)
+		return
+	}
+
+	keys := make([]string, 0, len(r.labels))
+	for k := range r.labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	status := tui.NewStatusTable()
+	for _, k := range keys {
+		status.Add(k, r.labels[k])
+	}
+	out.Println(status.String())
+}
+
+func newDeleteCmd() *cobra.Command {
+	var status string
+	var selectors []string
+	var offlineFor string
+	var dryRun bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "delete [agent-id ...]",
+		Short: "Delete agents",
+		Long: `Delete one or more agents from your account.
+
+Instead of listing agent IDs, select agents with filters to clean up in bulk,
+e.g. machines that have not reported in a while. Deleting an agent does not
+stop it; a running agent will fail to authenticate until re-registered.`,
+		Example: `  # Delete a single agent
+  ggo agent delete agent_xxx
+
+  # Preview agents offline for more than 30 days
+  ggo agent delete --offline-for 30d --dry-run
+
+  # Delete offline agents labeled env=ci without confirmation (required
+  # for filtered deletes in scripts and with -o json)
+  ggo agent delete --offline-for 7d --label env=ci -f`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := newAgentFilter(status, selectors, offlineFor)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 && filter.empty() {
+				return fmt.Errorf("specify agent IDs or at least one of --offline-for, --label, --status")
+			}
+			if len(args) > 0 && !filter.empty() {
+				return cmdutil.UsageErrorf("agent IDs cannot be combined with filters")
+			}
+
+			client := getUserClient()
+			ctx := context.Background()
+			out := getOutput()
+
+			var targets []api.AgentInfo
+			if len(args) > 0 {
+				for _, id := range args {
+					targets = append(targets, api.AgentInfo{AgentID: id})
+				}
+			} else {
+				resp, err := client.ListAgents(ctx)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to list agents: error=%v", err)
+					return err
+				}
+				targets = filter.apply(resp.Agents)
+			}
+
+			if len(targets) == 0 {
+				out.Info("No agents match")
+				return nil
+			}
+
+			if dryRun {
+				return out.Render(&agentDeleteResult{candidates: targets, dryRun: true})
+			}
+
+			// A filter can match more agents than expected; without a prompt
+			// the caller has to confirm with --force
+			interactive := !out.IsJSON() && term.IsTerminal(int(os.Stdin.Fd()))
+			if !force && len(args) == 0 && !interactive {
+				return cmdutil.UsageErrorf("--force is required to delete agents selected by filters without a confirmation prompt")
+			}
+			if !force && !out.IsJSON() {
+				if len(args) == 0 {
+					(&agentListResult{agents: targets}).RenderTUI(out)
+				}
+				styles := tui.DefaultStyles()
+				fmt.Printf(i18n.T("%s Are you sure you want to delete %s? [y/N]: "),
+					styles.Warning.Render("!"),
+					styles.Bold.Render(i18n.Tf("%d agent(s)", len(targets))))
+				var confirm string
+				fmt.Scanln(&confirm)
+				if confirm != "y" && confirm != "Y" {
+					out.Info("Cancelled")
+					return nil
+				}
+			}
+
+			result := &agentDeleteResult{candidates: targets, failed: map[string]string{}}
+			for _, a := range targets {
+				if err := client.DeleteAgent(ctx, a.AgentID); err != nil {
+					klog.Errorf("Failed to delete agent: agent_id=%s error=%v", a.AgentID, err)
+					result.failed[a.AgentID] = err.Error()
+					continue
+				}
+				result.deleted = append(result.deleted, a.AgentID)
+			}
+
+			if err := out.Render(result); err != nil {
+				return err
+			}
+			if len(result.failed) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to delete %d of %d agent(s)", len(result.failed), len(targets))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&status, "status", "", "Only delete agents with this status (e.g. offline)")
+	cmd.Flags().StringSliceVarP(&selectors, "label", "l", nil, "Only delete agents with this label (key or key=value, repeatable)")
+	cmd.Flags().StringVar(&offlineFor, "offline-for", "", "Only delete agents offline for at least this long (e.g. 30d, 12h)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the agents that would be deleted")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation")
+
+	return cmd
+}
+
+// agentDeleteResult implements Renderable for agent delete
+type agentDeleteResult struct {
+	candidates []api.AgentInfo
+	deleted    []string
+	failed     map[string]string
+	dryRun     bool
+}
+
+func (r *agentDeleteResult) RenderJSON() any {
+	if r.dryRun {
+		return tui.NewListResult(r.candidates)
+	}
+	deleted := r.deleted
+	if deleted == nil {
+		deleted = []string{}
+	}
+	return map[string]any{
+		"success": len(r.failed) == 0,
+		"deleted": deleted,
+		"failed":  r.failed,
+	}
+}
+
+func (r *agentDeleteResult) RenderTUI(out *tui.Output) {
+	if r.dryRun {
+		(&agentListResult{agents: r.candidates}).RenderTUI(out)
+		out.Infof("%d agent(s) would be deleted (dry run)", len(r.candidates))
+		return
+	}
+
+	for _, id := range r.deleted {
+		out.Successf("Agent %s deleted", id)
+	}
+	for id, msg := range r.failed {
+		out.Errorf("Agent %s: %s", id, msg)
+	}
+}
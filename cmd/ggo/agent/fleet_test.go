@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentFilter_Match(t *testing.T) {
+	now := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	agents := []api.AgentInfo{
+		{AgentID: "a1", Status: "online", LastSeenAt: now, Labels: map[string]string{"env": "ci", "rack": "a1"}},
+		{AgentID: "a2", Status: "offline", LastSeenAt: now.Add(-10 * 24 * time.Hour), Labels: map[string]string{"env": "ci"}},
+		{AgentID: "a3", Status: "offline", LastSeenAt: now.Add(-time.Hour), Labels: map[string]string{"env": "prod"}},
+		{AgentID: "a4", Status: "offline"},
+	}
+	matching := func(f *agentFilter) []string {
+		var ids []string
+		for i := range agents {
+			if f.match(&agents[i], now) {
+				ids = append(ids, agents[i].AgentID)
+			}
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name       string
+		status     string
+		selectors  []string
+		offlineFor string
+		want       []string
+	}{
+		{name: "status is case-insensitive", status: "OFFLINE", want: []string{"a2", "a3", "a4"}},
+		{name: "key=value selector", selectors: []string{"env=ci"}, want: []string{"a1", "a2"}},
+		{name: "key-only selector needs the label with any value", selectors: []string{"env"}, want: []string{"a1", "a2", "a3"}},
+		{name: "selectors are ANDed", selectors: []string{"env=ci", "rack"}, want: []string{"a1"}},
+		{name: "offline-for skips online and recently seen agents", offlineFor: "7d", want: []string{"a2", "a4"}},
+		{name: "filters combine", selectors: []string{"env"}, offlineFor: "30m", want: []string{"a2", "a3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newAgentFilter(tt.status, tt.selectors, tt.offlineFor)
+			require.NoError(t, err)
+			assert.False(t, f.empty())
+			assert.Equal(t, tt.want, matching(f))
+		})
+	}
+
+	f, err := newAgentFilter("", nil, "")
+	require.NoError(t, err)
+	assert.True(t, f.empty())
+	assert.Len(t, f.apply(agents), len(agents))
+}
+
+func TestNewAgentFilter_Invalid(t *testing.T) {
+	_, err := newAgentFilter("", []string{"bad key=x"}, "")
+	assert.ErrorContains(t, err, "invalid label selector")
+	_, err = newAgentFilter("", nil, "soon")
+	assert.ErrorContains(t, err, "invalid --offline-for")
+}
+
+func TestParseLabelArgs(t *testing.T) {
+	set, remove, err := parseLabelArgs([]string{"rack=a1", "team-", "note=", "range=1-"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"rack": "a1", "note": "", "range": "1-"}, set)
+	assert.Equal(t, []string{"team"}, remove)
+
+	for _, arg := range []string{"rack", "-", "bad key=x", "bad key-"} {
+		_, _, err := parseLabelArgs([]string{arg})
+		assert.Error(t, err, arg)
+	}
+}
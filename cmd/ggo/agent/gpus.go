@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/agent"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newGPUsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "gpus",
+		Short: "Show the GPUs of the agent on this machine",
+		Long: `Show the GPUs the running agent discovered on this machine, with their model,
+UUID, VRAM, driver and CUDA version, ECC mode, temperature, utilization,
+health and the workers they are allocated to.
+
+The data comes from the agent's local hypervisor snapshot rather than the
+platform, so this works while the host is offline. The agent must be running.`,
+		Example: `  # Show local GPUs
+  ggo agent gpus
+
+  # As JSON, for scripts
+  ggo agent gpus -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+
+			localStatus := agent.GetLocalStatus(agentPaths())
+			if !localStatus.Running {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("the agent is not running on this machine; start it with 'ggo agent start'")
+			}
+			live, err := agent.ReadLiveStatus(agentPaths())
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to read the agent's live status: %w", err)
+			}
+			if live == nil || live.PID != localStatus.PID || time.Since(live.UpdatedAt) >= liveStaleAfter {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("no live data from the agent yet; restart it with this ggo version if this persists")
+			}
+			return out.Render(&gpusResult{gpus: live.GPUs})
+		},
+	}
+}
+
+// gpusResult implements Renderable for agent gpus
+type gpusResult struct {
+	gpus []agent.LiveGPU
+}
+
+func (r *gpusResult) RenderJSON() any {
+	return map[string]any{"gpus": r.gpus}
+}
+
+func (r *gpusResult) RenderTUI(out *tui.Output) {
+	if len(r.gpus) == 0 {
+		out.Info("No GPUs reported by the hypervisor")
+		return
+	}
+	styles := tui.DefaultStyles()
+
+	rows := make([][]string, 0, len(r.gpus))
+	for _, g := range r.gpus {
+		vram := "-"
+		if g.VRAMTotalMb > 0 {
+			vram = fmt.Sprintf("%d/%d MiB", g.VRAMUsedMb, g.VRAMTotalMb)
+		}
+		temp := "-"
+		if g.Temperature > 0 {
+			temp = fmt.Sprintf("%.0f°C", g.Temperature)
+		}
+		health := styles.Success.Render("ok")
+		if len(g.Health) > 0 {
+			health = styles.Warning.Render(strings.Join(g.Health, ","))
+		}
+		workers := "-"
+		if len(g.Workers) > 0 {
+			workers = strings.Join(g.Workers, ",")
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(g.GPUIndex),
+			g.Model,
+			g.GPUID,
+			vram,
+			fmt.Sprintf("%.1f%%", g.Utilization),
+			temp,
+			cmdutil.ValueOrDash(g.DriverVersion),
+			cmdutil.ValueOrDash(g.CUDAVersion),
+			cmdutil.ValueOrDash(g.ECCMode),
+			health,
+			workers,
+		})
+	}
+	out.Println(tui.NewTable().
+		Headers("IDX", "MODEL", "UUID", "VRAM", "UTIL", "TEMP", "DRIVER", "CUDA", "ECC", "HEALTH", "WORKERS").
+		Rows(rows).String())
+}
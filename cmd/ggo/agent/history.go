@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newHistoryCmd() *cobra.Command {
+	var (
+		workerID string
+		gpuID    string
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the history of worker restarts and GPU changes",
+		Long: `Show the worker restarts and GPU changes recorded by the agent, newest first.
+
+History is kept by the SQLite state store only; start the agent with
+--state-store sqlite to record it.`,
+		Example: `  # Recent worker restarts and GPU changes
+  ggo agent history
+
+  # Restarts of one worker
+  ggo agent history --worker worker-1 --limit 50`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			configMgr := config.NewManager(configDir, stateDir)
+			defer func() { _ = configMgr.Close() }()
+			store, err := configMgr.Store()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to open state store: error=%v", err)
+				return err
+			}
+
+			result := &historyResult{}
+			if gpuID == "" {
+				if result.WorkerRestarts, err = store.WorkerRestarts(workerID, limit); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+			if workerID == "" {
+				if result.GPUChanges, err = store.GPUChanges(gpuID, limit); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+			result.showWorkers, result.showGPUs = gpuID == "", workerID == ""
+			return out.Render(result)
+		},
+	}
+
+	cmd.Flags().StringVar(&workerID, "worker", "", "Only show restarts of this worker")
+	cmd.Flags().StringVar(&gpuID, "gpu", "", "Only show changes of this GPU")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of entries of each kind (0 for all)")
+	cmd.MarkFlagsMutuallyExclusive("worker", "gpu")
+
+	return cmd
+}
+
+// historyResult implements Renderable for the history command
+type historyResult struct {
+	WorkerRestarts []config.WorkerRestart `json:"worker_restarts,omitempty"`
+	GPUChanges     []config.GPUChange     `json:"gpu_changes,omitempty"`
+
+	showWorkers, showGPUs bool
+}
+
+func (r *historyResult) RenderJSON() any {
+	return r
+}
+
+func (r *historyResult) RenderTUI(out *tui.Output) {
+	styles := tui.DefaultStyles()
+
+	if r.showWorkers {
+		out.Println(styles.Subtitle.Render(i18n.T("Worker Restarts")))
+		if len(r.WorkerRestarts) == 0 {
+			out.Println(tui.Muted(i18n.T("No worker restarts recorded")))
+		} else {
+			var rows [][]string
+			for _, restart := range r.WorkerRestarts {
+				cause := styles.StatusStyle("unknown").Render(i18n.T("restarted"))
+				if restart.Crashed {
+					cause = styles.StatusStyle("error").Render(i18n.T("crashed"))
+				}
+				rows = append(rows, []string{
+					restart.At.Local().Format(time.DateTime),
+					restart.WorkerID,
+					strconv.Itoa(restart.PreviousPID) + " → " + strconv.Itoa(restart.PID),
+					strconv.Itoa(restart.Restarts),
+					cause,
+				})
+			}
+			out.Println(tui.NewTable().Headers("TIME", "WORKER", "PID", "RESTARTS", "CAUSE").Rows(rows).String())
+		}
+	}
+
+	if r.showGPUs {
+		if r.showWorkers {
+			out.Println()
+		}
+		out.Println(styles.Subtitle.Render(i18n.T("GPU Changes")))
+		if len(r.GPUChanges) == 0 {
+			out.Println(tui.Muted(i18n.T("No GPU changes recorded")))
+			return
+		}
+		var rows [][]string
+		for _, change := range r.GPUChanges {
+			status := "pending"
+			switch change.Change {
+			case config.GPUAdded:
+				status = "active"
+			case config.GPURemoved:
+				status = "error"
+			}
+			rows = append(rows, []string{
+				change.At.Local().Format(time.DateTime),
+				change.GPUID,
+				styles.StatusStyle(status).Render(change.Change),
+				strconv.Itoa(change.GPUIndex),
+				change.Model,
+				cmdutil.ValueOrDash(change.DriverVersion),
+			})
+		}
+		out.Println(tui.NewTable().Headers("TIME", "GPU", "CHANGE", "INDEX", "MODEL", "DRIVER").Rows(rows).String())
+	}
+}
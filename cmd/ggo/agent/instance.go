@@ -0,0 +1,209 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/agent"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// instanceName selects one of several agents on this host; empty is the
+	// host's default agent
+	instanceName string
+
+	// instanceGPUs are the UUIDs of the GPUs the selected instance may use;
+	// empty allows all GPUs
+	instanceGPUs []string
+)
+
+// instanceRegistry is the host-wide record of agent instances
+func instanceRegistry() *config.Manager {
+	return config.NewManagerWithPaths(paths)
+}
+
+// resolveInstance points configDir and stateDir at the instance selected with
+// --instance: the directories given on the command line, else the ones the
+// instance was started with, else its default directories
+func resolveInstance(cmd *cobra.Command) error {
+	if instanceName == "" {
+		if cmd.Flags().Changed("gpus") {
+			return cmdutil.UsageErrorf("--gpus requires --instance")
+		}
+		return nil
+	}
+	if platform.NormalizeName(instanceName) != instanceName {
+		return cmdutil.UsageErrorf("invalid instance name %q: use lowercase letters, digits, '-' or '_'", instanceName)
+	}
+
+	registry := instanceRegistry()
+	instances, err := registry.LoadInstances()
+	if err != nil {
+		return fmt.Errorf("failed to load agent instances: %w", err)
+	}
+	inst := instances.Get(instanceName)
+	defaultConfigDir, defaultStateDir := registry.InstanceDirs(instanceName)
+
+	if !cmd.Flag("config-dir").Changed {
+		configDir = defaultConfigDir
+		if inst != nil {
+			configDir = inst.ConfigDir
+		}
+	}
+	if !cmd.Flag("state-dir").Changed {
+		stateDir = defaultStateDir
+		if inst != nil {
+			stateDir = inst.StateDir
+		}
+	}
+	if inst != nil && !cmd.Flags().Changed("gpus") {
+		instanceGPUs = inst.GPUs
+	}
+	klog.V(2).Infof("Using agent instance: name=%s config_dir=%s state_dir=%s gpus=%v", instanceName, configDir, stateDir, instanceGPUs)
+	return nil
+}
+
+// recordInstance saves the selected instance's directories and GPUs so later
+// commands given only --instance find them. It refuses directories or GPUs
+// another instance already uses.
+func recordInstance() error {
+	if instanceName == "" {
+		return nil
+	}
+	absConfigDir, err := filepath.Abs(configDir)
+	if err != nil {
+		return err
+	}
+	absStateDir, err := filepath.Abs(stateDir)
+	if err != nil {
+		return err
+	}
+	inst := config.Instance{Name: instanceName, ConfigDir: absConfigDir, StateDir: absStateDir, GPUs: instanceGPUs}
+
+	registry := instanceRegistry()
+	instances, err := registry.LoadInstances()
+	if err != nil {
+		return fmt.Errorf("failed to load agent instances: %w", err)
+	}
+	if err := instances.Conflict(inst); err != nil {
+		return fmt.Errorf("cannot use agent instance %s: %w", instanceName, err)
+	}
+	if samePathAs(absConfigDir, paths.ConfigDir()) || samePathAs(absStateDir, paths.StateDir()) {
+		return fmt.Errorf("cannot use agent instance %s: its directories must differ from the default agent's", instanceName)
+	}
+	instances.Set(inst)
+	return registry.SaveInstances(instances)
+}
+
+// forgetInstance removes the selected instance from the registry
+func forgetInstance() {
+	if instanceName == "" {
+		return
+	}
+	registry := instanceRegistry()
+	instances, err := registry.LoadInstances()
+	if err == nil && instances.Remove(instanceName) {
+		err = registry.SaveInstances(instances)
+	}
+	if err != nil {
+		klog.Warningf("Failed to remove agent instance: name=%s error=%v", instanceName, err)
+	}
+}
+
+func samePathAs(a, b string) bool {
+	absB, err := filepath.Abs(b)
+	return err == nil && filepath.Clean(a) == absB
+}
+
+// agentPaths returns the paths of the selected agent's local files (PID,
+// live status, connections). Downloads stay in the host's shared cache.
+func agentPaths() *platform.Paths {
+	return paths.WithConfigDir(configDir).WithStateDir(stateDir)
+}
+
+// filterInstanceGPUs keeps the GPUs pinned to the selected instance
+func filterInstanceGPUs(gpus []api.GPUInfo) []api.GPUInfo {
+	if len(instanceGPUs) == 0 {
+		return gpus
+	}
+	return slices.DeleteFunc(gpus, func(g api.GPUInfo) bool {
+		return !slices.ContainsFunc(instanceGPUs, func(uuid string) bool { return strings.EqualFold(uuid, g.GPUID) })
+	})
+}
+
+func newInstancesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "instances",
+		Short: "List the agent instances on this host",
+		Long: `List the agent instances on this host, with their directories, pinned GPUs
+and whether they are running. Instances are created by 'ggo agent register' or
+'ggo agent start' with --instance.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			instances, err := instanceRegistry().LoadInstances()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load agent instances: error=%v", err)
+				return err
+			}
+			result := &instanceListResult{}
+			for _, inst := range instances.Instances {
+				status := agent.GetLocalStatus(paths.WithConfigDir(inst.ConfigDir).WithStateDir(inst.StateDir))
+				result.instances = append(result.instances, instanceListItem{
+					Instance: inst,
+					Running:  status.Running,
+					PID:      status.PID,
+				})
+			}
+			return out.Render(result)
+		},
+	}
+}
+
+type instanceListItem struct {
+	config.Instance
+	Running bool `json:"running"`
+	PID     int  `json:"pid,omitempty"`
+}
+
+// instanceListResult implements Renderable for agent instances
+type instanceListResult struct {
+	instances []instanceListItem
+}
+
+func (r *instanceListResult) RenderJSON() any {
+	return tui.NewListResult(r.instances)
+}
+
+func (r *instanceListResult) RenderTUI(out *tui.Output) {
+	if len(r.instances) == 0 {
+		out.Info("No agent instances found")
+		return
+	}
+	styles := tui.DefaultStyles()
+	var rows [][]string
+	for _, inst := range r.instances {
+		state := styles.StatusStyle(stateStopped).Render(tui.StatusIcon(stateStopped) + " " + stateStopped)
+		if inst.Running {
+			state = styles.StatusStyle(stateRunning).Render(fmt.Sprintf("%s %s (PID %d)", tui.StatusIcon(stateRunning), stateRunning, inst.PID))
+		}
+		gpus := "all"
+		if len(inst.GPUs) > 0 {
+			gpus = strings.Join(inst.GPUs, ", ")
+		}
+		rows = append(rows, []string{inst.Name, state, gpus, inst.ConfigDir, inst.StateDir})
+	}
+	table := tui.NewTable().
+		Headers("NAME", "STATUS", "GPUS", "CONFIG DIR", "STATE DIR").
+		Rows(rows)
+	out.Println(table.String())
+}
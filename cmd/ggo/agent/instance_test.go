@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useInstanceTestPaths points the host paths and agent flags at temp dirs
+func useInstanceTestPaths(t *testing.T) {
+	t.Helper()
+	origPaths, origConfig, origState, origName, origGPUs := paths, configDir, stateDir, instanceName, instanceGPUs
+	t.Cleanup(func() {
+		paths, configDir, stateDir, instanceName, instanceGPUs = origPaths, origConfig, origState, origName, origGPUs
+	})
+	paths = platform.DefaultPaths().WithConfigDir(t.TempDir()).WithStateDir(t.TempDir())
+	configDir, stateDir, instanceGPUs = paths.ConfigDir(), paths.StateDir(), nil
+}
+
+// instanceTestCmd parses args with the agent's persistent flags
+func instanceTestCmd(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	root := NewAgentCmd()
+	cmd, _, err := root.Find([]string{"status"})
+	require.NoError(t, err)
+	require.NoError(t, cmd.ParseFlags(args))
+	return cmd
+}
+
+func TestResolveInstance(t *testing.T) {
+	useInstanceTestPaths(t)
+	hostConfig, hostState := paths.ConfigDir(), paths.StateDir()
+
+	// The default agent keeps the host directories
+	require.NoError(t, resolveInstance(instanceTestCmd(t)))
+	assert.Equal(t, hostConfig, configDir)
+
+	// A new instance defaults to directories below the host's
+	cmd := instanceTestCmd(t, "--instance", "team-a")
+	require.NoError(t, resolveInstance(cmd))
+	assert.Equal(t, filepath.Join(hostConfig, "instances", "team-a"), configDir)
+	assert.Equal(t, filepath.Join(hostState, "instances", "team-a"), stateDir)
+
+	// Directories and GPUs given once are found by name later
+	customState := t.TempDir()
+	cmd = instanceTestCmd(t, "--instance", "team-a", "--state-dir", customState)
+	require.NoError(t, resolveInstance(cmd))
+	instanceGPUs = []string{"GPU-aaa"}
+	require.NoError(t, recordInstance())
+
+	instanceGPUs = nil
+	require.NoError(t, resolveInstance(instanceTestCmd(t, "--instance", "team-a")))
+	assert.Equal(t, customState, stateDir)
+	assert.Equal(t, []string{"GPU-aaa"}, instanceGPUs)
+	assert.Equal(t, filepath.Join(customState, "agent.pid"), agentPaths().AgentPIDFile())
+
+	// Another instance cannot take the same GPU
+	instanceName = "team-b"
+	configDir, stateDir = t.TempDir(), t.TempDir()
+	assert.ErrorContains(t, recordInstance(), "GPU GPU-aaa is already assigned to instance team-a")
+
+	assert.ErrorContains(t, resolveInstance(instanceTestCmd(t, "--instance", "Team A")), "invalid instance name")
+}
+
+func TestFilterInstanceGPUs(t *testing.T) {
+	useInstanceTestPaths(t)
+	gpus := []api.GPUInfo{{GPUID: "GPU-aaa"}, {GPUID: "GPU-bbb"}}
+
+	assert.Len(t, filterInstanceGPUs(gpus), 2)
+
+	instanceGPUs = []string{"gpu-BBB"}
+	assert.Equal(t, []api.GPUInfo{{GPUID: "GPU-bbb"}}, filterInstanceGPUs(gpus))
+}
@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/agent"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newLicenseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "license",
+		Short: "Renew the agent license offline",
+		Long: `Renew the license of an agent that cannot reach the platform, for hosts in
+air-gapped networks. A connected agent renews its license automatically.
+
+  1. On the agent host, 'ggo agent license export-request' writes a renewal
+     request signed with the agent secret.
+  2. Carry the request to a machine with access to the platform, logged in
+     with 'ggo login', and run 'ggo agent license fulfill <request>'. The
+     platform answers with a license bundle.
+  3. Carry the bundle back and run 'ggo agent license import <bundle>'.
+
+Only a bundle answering the latest exported request is accepted; exporting a
+new request invalidates bundles for earlier ones.`,
+		Example: `  # On the air-gapped agent host
+  ggo agent license export-request -f license-request.json
+
+  # On a connected machine
+  ggo agent license fulfill license-request.json -f license-bundle.json
+
+  # Back on the agent host
+  ggo agent license import license-bundle.json`,
+	}
+
+	cmd.AddCommand(cmdutil.Audited(newLicenseExportRequestCmd()))
+	cmd.AddCommand(cmdutil.Audited(newLicenseFulfillCmd()))
+	cmd.AddCommand(cmdutil.Audited(newLicenseImportCmd()))
+	return cmd
+}
+
+func newLicenseExportRequestCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export-request",
+		Short: "Write a signed offline license renewal request",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configMgr := config.NewManager(configDir, stateDir)
+			req, err := agent.NewLicenseRenewalRequest(configMgr, time.Now())
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to create license renewal request: error=%v", err)
+				return err
+			}
+			if err := writeJSONFile(file, req); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to write license renewal request: file=%s error=%v", file, err)
+				return err
+			}
+			return getOutput().Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "License renewal request written to %s. Fulfill it with 'ggo agent license fulfill' on a connected machine.",
+				Args:    []any{file},
+				ID:      req.AgentID,
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "license-request.json", "File to write the request to")
+	return cmd
+}
+
+func newLicenseFulfillCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "fulfill <request-file>",
+		Short: "Obtain a license bundle for an offline renewal request",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req api.LicenseRenewalRequest
+			if err := readJSONFile(args[0], &req); err != nil {
+				return fmt.Errorf("failed to read license renewal request: %w", err)
+			}
+			if req.AgentID == "" || req.Nonce == "" || req.Signature == "" {
+				return fmt.Errorf("%s is not a license renewal request", args[0])
+			}
+
+			bundle, err := getUserClient().RenewLicenseOffline(context.Background(), &req)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to renew license: agent_id=%s error=%v", req.AgentID, err)
+				return err
+			}
+			if err := writeJSONFile(file, bundle); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to write license bundle: file=%s error=%v", file, err)
+				return err
+			}
+			return getOutput().Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "License bundle for agent '%s' written to %s. Import it on the agent host with 'ggo agent license import'.",
+				Args:    []any{req.AgentID, file},
+				ID:      req.AgentID,
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "license-bundle.json", "File to write the license bundle to")
+	return cmd
+}
+
+func newLicenseImportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <bundle-file>",
+		Short: "Validate and install a license bundle",
+		Long: `Validate a license bundle obtained with 'ggo agent license fulfill' and
+install its license. The bundle must be signed for this agent, answer the
+latest exported request and extend the current license.
+
+A running agent picks up the license with its next status report.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var bundle api.LicenseRenewalBundle
+			if err := readJSONFile(args[0], &bundle); err != nil {
+				return fmt.Errorf("failed to read license bundle: %w", err)
+			}
+
+			configMgr := config.NewManager(configDir, stateDir)
+			expiresAt, err := agent.ImportLicenseBundle(configMgr, &bundle, time.Now())
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to import license bundle: file=%s error=%v", args[0], err)
+				return err
+			}
+			return getOutput().Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "License installed, expires at %s",
+				Args:    []any{expiresAt.Local().Format(time.DateTime)},
+				ID:      bundle.AgentID,
+			})
+		},
+	}
+}
+
+// writeJSONFile writes v as indented JSON, readable by the owner only
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.AtomicWriteFile(path, append(data, '\n'), 0600)
+}
+
+// readJSONFile decodes the JSON file at path into v
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// formatLicenseExpiry describes when the license expires, styled as a
+// warning within a week of the expiration
+func formatLicenseExpiry(expiration *int64, now time.Time, styles *tui.Styles) string {
+	if expiration == nil {
+		return "-"
+	}
+	expiresAt := time.UnixMilli(*expiration)
+	switch agent.LicenseStatus(expiration, now) {
+	case api.LicenseStatusExpired:
+		return styles.Error.Render("✗ " + i18n.Tf("expired %s, renew with 'ggo agent license'", expiresAt.Local().Format(time.DateTime)))
+	case api.LicenseStatusExpiring:
+		return styles.Warning.Render("! " + i18n.Tf("expires %s (in %s)", expiresAt.Local().Format(time.DateTime), expiresAt.Sub(now).Round(time.Hour)))
+	}
+	return i18n.Tf("expires %s", expiresAt.Local().Format(time.DateTime))
+}
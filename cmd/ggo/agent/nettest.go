@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/agent"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newNetTestCmd() *cobra.Command {
+	var (
+		samples     int
+		sizeMB      int
+		cdnURL      string
+		ports       []int
+		workerPorts bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "nettest",
+		Short: "Measure this agent's network to the platform and CDN",
+		Long: `Measure the network of this agent to help diagnose slow remote GPUs:
+
+  API        HTTPS request latency and download throughput
+  WebSocket  connect time and message round-trip latency
+  CDN        download throughput of a test object
+  Ports      whether the platform can connect to worker ports, as clients do
+             (with --ports or --worker-ports)
+
+The result is saved in the agent's state directory and its summary is attached
+to the next status report of the running agent, so it is visible on the
+platform next to the agent.`,
+		Example: `  # Latency and throughput to the API and CDN
+  ggo agent nettest
+
+  # Also check that clients can reach the ports of this agent's workers
+  ggo agent nettest --worker-ports
+
+  # Check specific ports, as JSON
+  ggo agent nettest --ports 9001,9002 -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			configMgr := config.NewManager(configDir, stateDir)
+			cfg, err := configMgr.LoadConfig()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load config: error=%v", err)
+				return err
+			}
+			if cfg == nil {
+				cmd.SilenceUsage = true
+				return agent.ErrNotRegistered
+			}
+
+			if workerPorts {
+				workers, err := configMgr.LoadWorkers()
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to load workers: %w", err)
+				}
+				for _, w := range workers {
+					if w.ListenPort > 0 && !slices.Contains(ports, w.ListenPort) {
+						ports = append(ports, w.ListenPort)
+					}
+				}
+			}
+
+			effectiveServerURL := serverURL
+			if cfg.ServerURL != "" {
+				effectiveServerURL = cfg.ServerURL
+			}
+			client := api.NewClient(
+				api.WithBaseURL(effectiveServerURL),
+				api.WithAgentSecret(cfg.AgentSecret),
+			)
+
+			if !out.IsJSON() {
+				out.Info("Running network self-test...")
+			}
+			result := agent.RunNetTest(context.Background(), agent.NetTestConfig{
+				Client:  client,
+				AgentID: cfg.AgentID,
+				CDNURL:  cdnURL,
+				Samples: samples,
+				Bytes:   int64(sizeMB) << 20,
+				Ports:   ports,
+			})
+			if err := agent.SaveNetTest(agentPaths(), result); err != nil {
+				klog.Warningf("Failed to save network self-test: error=%v", err)
+			}
+			return out.Render(&netTestResult{result: result})
+		},
+	}
+
+	cmd.Flags().IntVar(&samples, "samples", agent.DefaultNetTestSamples, "Number of timed requests and WebSocket round trips")
+	cmd.Flags().IntVar(&sizeMB, "size", agent.DefaultNetTestBytes>>20, "MiB to download from the API and the CDN")
+	cmd.Flags().StringVar(&cdnURL, "cdn-url", deps.DefaultCDNBaseURL+agent.DefaultNetTestCDNPath, "CDN object to download")
+	cmd.Flags().IntSliceVar(&ports, "ports", nil, "Ports the platform should try to connect to (comma-separated)")
+	cmd.Flags().BoolVar(&workerPorts, "worker-ports", false, "Also check the listen ports of this agent's workers")
+
+	return cmd
+}
+
+// netTestResult implements Renderable for the nettest command
+type netTestResult struct {
+	result *agent.NetTestResult
+}
+
+func (r *netTestResult) RenderJSON() any {
+	return r.result
+}
+
+func (r *netTestResult) RenderTUI(out *tui.Output) {
+	res := r.result
+	styles := tui.DefaultStyles()
+
+	apiTable := tui.NewStatusTable().Add("Target", res.API.Target)
+	addProbe(apiTable, res.API)
+	ws := tui.NewStatusTable().Add("Target", res.WebSocket.Target)
+	if res.WebSocket.ConnectMs > 0 {
+		ws.Add("Connect", formatMs(res.WebSocket.ConnectMs))
+	}
+	addProbe(ws, res.WebSocket)
+	cdn := tui.NewStatusTable().Add("Target", res.CDN.Target)
+	addProbe(cdn, res.CDN)
+
+	for _, section := range []struct {
+		title string
+		table *tui.StatusTable
+	}{{"API", apiTable}, {"WebSocket", ws}, {"CDN", cdn}} {
+		out.Println()
+		out.Println(styles.Subtitle.Render(section.title))
+		out.Println(section.table.String())
+	}
+
+	if res.PortsError != "" || len(res.Ports) > 0 {
+		out.Println(styles.Subtitle.Render(i18n.T("Port Reachability")))
+		if res.PortsError != "" {
+			out.Errorf("Port check failed: %s", res.PortsError)
+		} else {
+			if res.Address != "" {
+				out.Printf("Connecting to %s\n", res.Address)
+			}
+			var rows [][]string
+			for _, p := range res.Ports {
+				state, status := i18n.T("reachable"), "active"
+				if !p.Reachable {
+					state, status = i18n.T("unreachable"), "error"
+				}
+				latency := "-"
+				if p.LatencyMs > 0 {
+					latency = formatMs(p.LatencyMs)
+				}
+				rows = append(rows, []string{strconv.Itoa(p.Port), styles.StatusStyle(status).Render(state), latency, p.Error})
+			}
+			out.Println(tui.NewTable().Headers("PORT", "STATUS", "LATENCY", "ERROR").Rows(rows).String())
+		}
+	}
+
+	summary := res.Summary()
+	if len(summary.Failed) > 0 || len(summary.UnreachablePorts) > 0 {
+		out.Warningf("Network self-test found problems: %s", netTestProblems(summary))
+	} else {
+		out.Successf("Network self-test finished in %s", formatMs(res.DurationMs))
+	}
+	out.Println(tui.Muted(i18n.T("The summary will be sent with the agent's next status report.")))
+}
+
+// addProbe adds the latency, throughput and error of a probe
+func addProbe(table *tui.StatusTable, probe agent.NetProbe) {
+	if l := probe.Latency; l != nil {
+		table.Add("Latency", i18n.Tf("%s median (min %s, max %s, %d samples)", formatMs(l.MedianMs), formatMs(l.MinMs), formatMs(l.MaxMs), l.Samples))
+	}
+	if probe.Bytes > 0 {
+		table.Add("Throughput", fmt.Sprintf("%.1f Mbit/s (%.1f MiB in %s)", probe.ThroughputMbps, float64(probe.Bytes)/(1<<20), formatMs(probe.DownloadMs)))
+	}
+	if probe.Error != "" {
+		table.AddWithStatus("Error", probe.Error, "error")
+	}
+}
+
+func netTestProblems(summary *api.NetTestSummary) string {
+	var problems []string
+	if len(summary.Failed) > 0 {
+		problems = append(problems, i18n.Tf("failed: %s", strings.Join(summary.Failed, ", ")))
+	}
+	if len(summary.UnreachablePorts) > 0 {
+		ports := make([]string, len(summary.UnreachablePorts))
+		for i, p := range summary.UnreachablePorts {
+			ports[i] = strconv.Itoa(p)
+		}
+		problems = append(problems, i18n.Tf("unreachable ports: %s", strings.Join(ports, ", ")))
+	}
+	return strings.Join(problems, "; ")
+}
+
+func formatMs(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+	return fmt.Sprintf("%.1fms", ms)
+}
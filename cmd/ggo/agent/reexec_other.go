@@ -0,0 +1,25 @@
+//go:build !unix
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// reexecAgent starts the agent again from the ggo binary now on disk. The
+// caller exits afterwards; worker processes outlive it on Windows.
+func reexecAgent() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate ggo binary: %w", err)
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+	return cmd.Process.Release()
+}
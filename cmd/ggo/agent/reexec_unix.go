@@ -0,0 +1,20 @@
+//go:build unix
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// reexecAgent replaces this process with a fresh start of the agent from the
+// ggo binary now on disk. The PID is kept, so service managers see no exit
+// and preserved workers stay children of the agent.
+func reexecAgent() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate ggo binary: %w", err)
+	}
+	return syscall.Exec(exe, os.Args, os.Environ())
+}
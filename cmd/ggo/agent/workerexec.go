@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"os"
+
+	"github.com/NexusGPU/gpu-go/internal/worker"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// newWorkerExecCmd runs a worker with its output captured. The agent starts
+// workers through it; it is not meant to be run by hand.
+func newWorkerExecCmd() *cobra.Command {
+	var logPath string
+	var maxSizeMB int
+	var maxFiles int
+
+	cmd := &cobra.Command{
+		Use:    "worker-exec --log <file> -- <worker-binary> [args...]",
+		Short:  "Run a worker with its stdout and stderr captured in a rotated log",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var log *worker.RotatingLog
+			if logPath != "" {
+				var err error
+				log, err = worker.OpenRotatingLog(logPath, int64(maxSizeMB)<<20, maxFiles)
+				if err != nil {
+					// Run the worker anyway; its output goes where ours does
+					klog.Errorf("Failed to open worker output log: path=%s error=%v", logPath, err)
+				}
+			}
+			code := worker.RunCaptured(log, args[0], args[1:])
+			if log != nil {
+				_ = log.Close()
+			}
+			klog.Flush()
+			os.Exit(code)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&logPath, "log", "", "File to append the worker's stdout and stderr to")
+	cmd.Flags().IntVar(&maxSizeMB, "max-size-mb", 0, "Size at which the log is rotated (0 never rotates)")
+	cmd.Flags().IntVar(&maxFiles, "max-files", 0, "Rotated log files to keep")
+	return cmd
+}
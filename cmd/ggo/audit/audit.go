@@ -0,0 +1,222 @@
+// Package audit implements the ggo audit command for the local log of CLI mutations
+package audit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/auth"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/audit"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// uploadBatchSize caps the entries sent per upload request
+const uploadBatchSize = 500
+
+var outputFormat string
+
+// NewAuditCmd creates the audit command
+func NewAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit log of CLI changes",
+		Long: `Inspect the local audit log of mutating CLI actions.
+
+Every run of a command that changes state (worker create/update/delete,
+share create/delete, studio create/resize/rm, agent register and similar)
+is appended to ` + "`~/.gpugo/state/audit.jsonl`" + ` with its timestamp, local user,
+arguments and result. Credential flags such as --token are redacted.`,
+	}
+
+	cmdutil.AddOutputFlag(cmd, &outputFormat)
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newUploadCmd())
+
+	return cmd
+}
+
+func getOutput() *tui.Output {
+	return cmdutil.NewOutput(outputFormat)
+}
+
+func getLog() *audit.Log {
+	return audit.NewLog(platform.DefaultPaths().AuditLogFile())
+}
+
+func newListCmd() *cobra.Command {
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded CLI actions",
+		Long: `List recorded CLI actions, oldest first.
+
+Examples:
+  # Actions of the last week
+  ggo audit list --since 7d
+
+  # Everything, as JSON
+  ggo audit list -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var from time.Time
+			if since != "" {
+				d, err := cmdutil.ParseAge(since)
+				if err != nil {
+					return cmdutil.UsageErrorf("invalid --since %q: %w", since, err)
+				}
+				from = time.Now().Add(-d)
+			}
+
+			entries, err := getLog().List(from)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to read audit log: error=%v", err)
+				return err
+			}
+			return getOutput().Render(&auditListResult{entries: entries})
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only list actions newer than this (e.g. 7d, 12h)")
+
+	return cmd
+}
+
+// auditListResult implements Renderable for audit list
+type auditListResult struct {
+	entries []audit.Entry
+}
+
+func (r *auditListResult) RenderJSON() any {
+	return tui.NewListResult(r.entries)
+}
+
+func (r *auditListResult) RenderTUI(out *tui.Output) {
+	if len(r.entries) == 0 {
+		out.Info("No audit log entries found")
+		return
+	}
+
+	styles := tui.DefaultStyles()
+	var rows [][]string
+	for _, e := range r.entries {
+		result := styles.Success.Render(e.Result)
+		if e.Result != audit.ResultSuccess {
+			result = styles.Error.Render(e.Result)
+		}
+		rows = append(rows, []string{
+			e.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			e.User,
+			e.Command,
+			strings.Join(e.Args, " "),
+			result,
+		})
+	}
+
+	table := tui.NewTable().
+		Headers("TIME", "USER", "COMMAND", "ARGS", "RESULT").
+		Rows(rows)
+
+	out.Println(table.String())
+}
+
+func newUploadCmd() *cobra.Command {
+	var serverURL string
+	var userToken string
+
+	cmd := &cobra.Command{
+		Use:   "upload",
+		Short: "Upload new audit log entries to the platform",
+		Long: `Upload audit log entries recorded since the last upload to the platform,
+where they are retained centrally for compliance. Uploading is optional; the
+local log is kept either way.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			log := getLog()
+
+			token := userToken
+			if token == "" {
+				token = os.Getenv("GPU_GO_TOKEN")
+			}
+			if token == "" {
+				token, _ = auth.GetToken()
+			}
+			if token == "" {
+				cmd.SilenceUsage = true
+				return cmdutil.AuthErrorf("not logged in, run 'ggo login' first")
+			}
+			client := api.NewClient(api.WithBaseURL(serverURL), api.WithUserToken(token))
+
+			uploaded, err := uploadEntries(cmd.Context(), client, log)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to upload audit log: uploaded=%d error=%v", uploaded, err)
+				return err
+			}
+
+			message := fmt.Sprintf("Uploaded %d audit log entries", uploaded)
+			if uploaded == 0 {
+				message = "Audit log is already up to date"
+			}
+			return out.Render(&cmdutil.ActionData{Success: true, Message: message})
+		},
+	}
+
+	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL (or set GPU_GO_ENDPOINT env var)")
+	cmd.Flags().StringVar(&userToken, "token", "", "User authentication token")
+
+	return cmd
+}
+
+// uploadEntries sends entries newer than the upload cursor in batches,
+// advancing the cursor after each accepted batch so an interrupted upload
+// resumes where it stopped
+func uploadEntries(ctx context.Context, client *api.Client, log *audit.Log) (int, error) {
+	cursor, err := log.Uploaded()
+	if err != nil {
+		return 0, err
+	}
+	entries, err := log.List(cursor)
+	if err != nil {
+		return 0, err
+	}
+	host, _ := os.Hostname()
+
+	var pending []api.AuditEntry
+	for _, e := range entries {
+		if !e.Timestamp.After(cursor) {
+			continue
+		}
+		pending = append(pending, api.AuditEntry{
+			Timestamp: e.Timestamp,
+			User:      e.User,
+			Host:      host,
+			Command:   e.Command,
+			Args:      e.Args,
+			Result:    e.Result,
+			Error:     e.Error,
+		})
+	}
+
+	uploaded := 0
+	for len(pending) > 0 {
+		batch := pending[:min(uploadBatchSize, len(pending))]
+		if err := client.UploadAuditEntries(ctx, &api.AuditUploadRequest{Entries: batch}); err != nil {
+			return uploaded, err
+		}
+		if err := log.MarkUploaded(batch[len(batch)-1].Timestamp); err != nil {
+			return uploaded, fmt.Errorf("failed to save upload progress: %w", err)
+		}
+		uploaded += len(batch)
+		pending = pending[len(batch):]
+	}
+	return uploaded, nil
+}
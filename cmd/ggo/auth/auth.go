@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/credentials"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 	"github.com/NexusGPU/gpu-go/internal/platform"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/spf13/cobra"
@@ -41,6 +43,7 @@ type AuthStatusResponse struct {
 	CreatedAt string `json:"created_at,omitempty"`
 	ExpiresAt string `json:"expires_at,omitempty"`
 	Expired   bool   `json:"expired,omitempty"`
+	Storage   string `json:"storage,omitempty"`
 }
 
 // NewAuthCmd creates the auth command
@@ -52,11 +55,13 @@ func NewAuthCmd() *cobra.Command {
 
 Use 'ggo login' to authenticate with a Personal Access Token (PAT).
 Use 'ggo logout' to remove stored credentials.
-Use 'ggo auth status' to check your current authentication status.`,
+Use 'ggo auth status' to check your current authentication status.
+Use 'ggo auth tokens' to manage tokens limited to scopes, e.g. for automation.`,
 	}
 
 	cmdutil.AddOutputFlag(cmd, &outputFormat)
 	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newTokensCmd())
 
 	return cmd
 }
@@ -127,7 +132,7 @@ This will sign you out of the GPU Go CLI and IDE extensions.`,
 
 			if !force && !out.IsJSON() {
 				styles := tui.DefaultStyles()
-				fmt.Printf("%s Are you sure you want to logout? [y/N]: ",
+				fmt.Printf(i18n.T("%s Are you sure you want to logout? [y/N]: "),
 					styles.Warning.Render("!"))
 				reader := bufio.NewReader(os.Stdin)
 				confirm, _ := reader.ReadString('\n')
@@ -138,6 +143,11 @@ This will sign you out of the GPU Go CLI and IDE extensions.`,
 				}
 			}
 
+			if stored, err := loadTokenFile(tokenPath); err == nil && stored != nil {
+				if err := credentials.Forget(stored.Token); err != nil {
+					klog.Warningf("Failed to remove token from OS keyring: error=%v", err)
+				}
+			}
 			if err := os.Remove(tokenPath); err != nil {
 				cmd.SilenceUsage = true
 				klog.Errorf("Failed to remove token: error=%v", err)
@@ -174,7 +184,11 @@ func newStatusCmd() *cobra.Command {
 				return out.Render(&authStatusResult{tokenConfig: nil})
 			}
 
-			return out.Render(&authStatusResult{tokenConfig: tokenConfig})
+			storage := ""
+			if stored, err := loadTokenFile(getTokenPath()); err == nil && stored != nil {
+				storage = credentials.Location(stored.Token)
+			}
+			return out.Render(&authStatusResult{tokenConfig: tokenConfig, storage: storage})
 		},
 	}
 }
@@ -182,6 +196,7 @@ func newStatusCmd() *cobra.Command {
 // authStatusResult implements Renderable for auth status
 type authStatusResult struct {
 	tokenConfig *TokenConfig
+	storage     string
 }
 
 func (r *authStatusResult) RenderJSON() any {
@@ -190,14 +205,11 @@ func (r *authStatusResult) RenderJSON() any {
 	}
 
 	expired := !r.tokenConfig.ExpiresAt.IsZero() && time.Now().After(r.tokenConfig.ExpiresAt)
-	maskedToken := ""
-	if len(r.tokenConfig.Token) > 12 {
-		maskedToken = r.tokenConfig.Token[:8] + "..." + r.tokenConfig.Token[len(r.tokenConfig.Token)-4:]
-	}
 
 	return AuthStatusResponse{
 		LoggedIn:  true,
-		Token:     maskedToken,
+		Token:     credentials.Redact(r.tokenConfig.Token),
+		Storage:   r.storage,
 		CreatedAt: r.tokenConfig.CreatedAt.Format(time.RFC3339),
 		ExpiresAt: r.tokenConfig.ExpiresAt.Format(time.RFC3339),
 		Expired:   expired,
@@ -210,19 +222,19 @@ func (r *authStatusResult) RenderTUI(out *tui.Output) {
 	if r.tokenConfig == nil {
 		out.Warning("Not logged in.")
 		out.Println()
-		out.Println("Run " + tui.Code("ggo login") + " to authenticate.")
+		out.Println(i18n.Tf("Run %s to authenticate.", tui.Code("ggo login")))
 		return
 	}
 
 	expired := !r.tokenConfig.ExpiresAt.IsZero() && time.Now().After(r.tokenConfig.ExpiresAt)
-	maskedToken := r.tokenConfig.Token[:8] + "..." + r.tokenConfig.Token[len(r.tokenConfig.Token)-4:]
 
 	out.Println()
 	out.Success("Logged in")
 	out.Println()
 
 	status := tui.NewStatusTable().
-		Add("Token", maskedToken).
+		Add("Token", credentials.Redact(r.tokenConfig.Token)).
+		Add("Stored in", r.storage).
 		Add("Created", r.tokenConfig.CreatedAt.Format("2006-01-02 15:04:05"))
 
 	if !r.tokenConfig.ExpiresAt.IsZero() {
@@ -237,7 +249,7 @@ func (r *authStatusResult) RenderTUI(out *tui.Output) {
 
 	if expired {
 		out.Println()
-		out.Println(styles.Warning.Render("! Your token has expired. Please run ") + tui.Code("ggo login") + styles.Warning.Render(" to re-authenticate."))
+		out.Println(styles.Warning.Render(i18n.T("! Your token has expired. Please run ")) + tui.Code("ggo login") + styles.Warning.Render(i18n.T(" to re-authenticate.")))
 	}
 }
 
@@ -246,11 +258,11 @@ func interactiveLogin(noBrowser bool, out *tui.Output) error {
 
 	if !out.IsJSON() {
 		fmt.Println()
-		fmt.Println(styles.Title.Render("GPU Go Login"))
+		fmt.Println(styles.Title.Render(i18n.T("GPU Go Login")))
 		fmt.Println()
 
 		if !noBrowser {
-			fmt.Println("Opening browser to generate a Personal Access Token (PAT)...")
+			fmt.Println(i18n.T("Opening browser to generate a Personal Access Token (PAT)..."))
 			fmt.Println()
 			fmt.Println("  " + tui.URL(dashboardURL))
 			fmt.Println()
@@ -260,15 +272,15 @@ func interactiveLogin(noBrowser bool, out *tui.Output) error {
 				fmt.Println(tui.WarningMessage("Could not open browser automatically."))
 			}
 		} else {
-			fmt.Println("Please visit the following URL to generate a Personal Access Token (PAT):")
+			fmt.Println(i18n.T("Please visit the following URL to generate a Personal Access Token (PAT):"))
 			fmt.Println()
 			fmt.Println("  " + tui.URL(dashboardURL))
 			fmt.Println()
 		}
 
-		fmt.Println("After generating your PAT, paste it below.")
+		fmt.Println(i18n.T("After generating your PAT, paste it below."))
 		fmt.Println()
-		fmt.Print(styles.Bold.Render("Enter PAT: "))
+		fmt.Print(styles.Bold.Render(i18n.T("Enter PAT: ")))
 	}
 
 	token, err := readSecureInput()
@@ -311,33 +323,46 @@ func saveToken(token string, out *tui.Output) error {
 	}
 
 	tokenPath := getTokenPath()
+	stored, err := writeTokenFile(tokenPath, tokenConfig)
+	if err != nil {
+		return err
+	}
 
+	return out.Render(&loginResult{tokenPath: tokenPath, inKeyring: credentials.IsRef(stored)})
+}
+
+// writeTokenFile saves the token, keeping the PAT itself in the OS keyring
+// when one is available, and returns the value written to the file
+func writeTokenFile(tokenPath string, tokenConfig *TokenConfig) (string, error) {
 	tokenDir := filepath.Dir(tokenPath)
 	if err := os.MkdirAll(tokenDir, 0700); err != nil {
-		return fmt.Errorf("failed to create token directory: %w", err)
+		return "", fmt.Errorf("failed to create token directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(tokenConfig, "", "  ")
+	stored := *tokenConfig
+	stored.Token = credentials.Seal(tokenAccount(tokenPath), tokenConfig.Token)
+
+	data, err := json.MarshalIndent(&stored, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal token: %w", err)
+		return "", fmt.Errorf("failed to marshal token: %w", err)
 	}
 
 	tmpPath := tokenPath + ".tmp"
 	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write token file: %w", err)
+		return "", fmt.Errorf("failed to write token file: %w", err)
 	}
 
 	if err := os.Rename(tmpPath, tokenPath); err != nil {
 		os.Remove(tmpPath)
-		return fmt.Errorf("failed to save token file: %w", err)
+		return "", fmt.Errorf("failed to save token file: %w", err)
 	}
-
-	return out.Render(&loginResult{tokenPath: tokenPath})
+	return stored.Token, nil
 }
 
 // loginResult implements Renderable for login result
 type loginResult struct {
 	tokenPath string
+	inKeyring bool
 }
 
 func (r *loginResult) RenderJSON() any {
@@ -348,13 +373,39 @@ func (r *loginResult) RenderTUI(out *tui.Output) {
 	out.Println()
 	out.Success("Successfully logged in!")
 	out.Println()
+	if r.inKeyring {
+		out.Println(tui.KeyValue("Token saved to", "OS keyring"))
+		return
+	}
 	out.Println(tui.KeyValue("Token saved to", r.tokenPath))
 }
 
-// LoadToken loads the stored PAT token
+// LoadToken loads the stored PAT token, resolving it from the OS keyring. A
+// token still kept in the file is moved to the keyring when one is available.
 func LoadToken() (*TokenConfig, error) {
 	tokenPath := getTokenPath()
 
+	tokenConfig, err := loadTokenFile(tokenPath)
+	if err != nil || tokenConfig == nil {
+		return nil, err
+	}
+
+	if credentials.NeedsMigration(tokenConfig.Token) {
+		if _, err := writeTokenFile(tokenPath, tokenConfig); err != nil {
+			klog.Warningf("Failed to move token to OS keyring: error=%v", err)
+		}
+	}
+
+	token, err := credentials.Open(tokenConfig.Token)
+	if err != nil {
+		return nil, err
+	}
+	tokenConfig.Token = token
+	return tokenConfig, nil
+}
+
+// loadTokenFile reads the token file as stored, without resolving the token
+func loadTokenFile(tokenPath string) (*TokenConfig, error) {
 	data, err := os.ReadFile(tokenPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -371,6 +422,11 @@ func LoadToken() (*TokenConfig, error) {
 	return &tokenConfig, nil
 }
 
+// tokenAccount names the keyring entry of the PAT stored at tokenPath
+func tokenAccount(tokenPath string) string {
+	return "token:" + tokenPath
+}
+
 // GetToken returns the stored PAT token string
 func GetToken() (string, error) {
 	tokenConfig, err := LoadToken()
@@ -383,8 +439,13 @@ func GetToken() (string, error) {
 	return tokenConfig.Token, nil
 }
 
+// getTokenPath returns where the PAT is stored. Each CLI profile keeps its
+// own token so that a login never leaks to another endpoint.
 func getTokenPath() string {
 	paths := platform.DefaultPaths()
+	if dir := paths.ProfileDir(); dir != "" {
+		return filepath.Join(dir, tokenFileName)
+	}
 	return filepath.Join(paths.UserDir(), tokenFileName)
 }
 
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+var (
+	tokensServerURL string
+	tokensUserToken string
+)
+
+// ScopeHint tells how to get a token with scope; commands pass it to their
+// API client with api.WithScopeHint
+func ScopeHint(scope string) string {
+	return fmt.Sprintf("create a token with it: ggo auth tokens create --scope %s", scope)
+}
+
+func newTokensCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tokens",
+		Aliases: []string{"token"},
+		Short:   "Manage Personal Access Tokens and their scopes",
+		Long: `Manage the Personal Access Tokens (PATs) of your account. Give automation a
+token limited to what it does, so a leaked token cannot do more:
+
+  read-only     read agents, workers and shares
+  worker-admin  create, update and delete workers
+  share-only    manage shares and nothing else
+
+A token created without --scope has full access. When a command is refused
+for a missing scope, the error names the scope the operation needs.`,
+		Example: `  # A token for CI that can only read, valid for 30 days
+  ggo auth tokens create ci --scope read-only --expires-in 30d
+
+  # List tokens and revoke one
+  ggo auth tokens list
+  ggo auth tokens revoke pat_abc123`,
+	}
+	cmd.PersistentFlags().StringVar(&tokensServerURL, "server", api.GetDefaultBaseURL(), "Server URL (or set GPU_GO_ENDPOINT env var)")
+	cmd.PersistentFlags().StringVar(&tokensUserToken, "token", "", "User authentication token")
+
+	cmd.AddCommand(newTokensListCmd())
+	cmd.AddCommand(cmdutil.Audited(newTokensCreateCmd()))
+	cmd.AddCommand(cmdutil.Audited(newTokensRevokeCmd()))
+	return cmd
+}
+
+// getTokensClient returns a client authenticated as the user: with --token,
+// the token environment variables or the token saved by 'ggo login'
+func getTokensClient() *api.Client {
+	token := tokensUserToken
+	if token == "" {
+		token = os.Getenv("GPU_GO_TOKEN")
+	}
+	if token == "" {
+		token = os.Getenv("GPU_GO_USER_TOKEN")
+	}
+	if token == "" {
+		if savedToken, err := GetToken(); err == nil {
+			token = savedToken
+		}
+	}
+	return api.NewClient(
+		api.WithBaseURL(tokensServerURL),
+		api.WithUserToken(token),
+		api.WithScopeHint(ScopeHint),
+	)
+}
+
+// validateScopes checks scopes are known token scopes
+func validateScopes(scopes []string) error {
+	for _, s := range scopes {
+		if !slices.Contains(api.TokenScopes, s) {
+			return cmdutil.UsageErrorf("unknown scope %q (use %s)", s, strings.Join(api.TokenScopes, ", "))
+		}
+	}
+	return nil
+}
+
+// parseExpiresIn parses --expires-in into whole days, nil for "never"
+func parseExpiresIn(s string) (*int, error) {
+	if s == "never" {
+		return nil, nil
+	}
+	d, err := cmdutil.ParseAge(s)
+	if err != nil {
+		return nil, cmdutil.UsageErrorf("invalid --expires-in %q: %w", s, err)
+	}
+	// Tokens expire on day boundaries; round up so they last at least d
+	days := int((d + 24*time.Hour - 1) / (24 * time.Hour))
+	return &days, nil
+}
+
+func newTokensListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List your Personal Access Tokens",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			resp, err := getTokensClient().ListPersonalAccessTokens(ctx)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to list tokens: error=%v", err)
+				return err
+			}
+			return getOutput().Render(&tokenListResult{tokens: resp.Tokens})
+		},
+	}
+}
+
+func newTokensCreateCmd() *cobra.Command {
+	var (
+		scopes    []string
+		expiresIn string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a Personal Access Token, optionally limited to scopes",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateScopes(scopes); err != nil {
+				return err
+			}
+			expiresInDays, err := parseExpiresIn(expiresIn)
+			if err != nil {
+				return err
+			}
+			req := &api.PATCreateRequest{Scopes: scopes, ExpiresInDays: expiresInDays}
+			if len(args) > 0 {
+				req.Name = args[0]
+			}
+			cmd.SilenceUsage = true
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			resp, err := getTokensClient().CreatePersonalAccessToken(ctx, req)
+			if err != nil {
+				klog.Errorf("Failed to create token: name=%s error=%v", req.Name, err)
+				return err
+			}
+			return getOutput().Render(&tokenCreateResult{token: resp})
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&scopes, "scope", nil, "Limit the token to a scope: read-only, worker-admin or share-only (repeatable; default: full access)")
+	cmd.Flags().StringVar(&expiresIn, "expires-in", "90d", "Lifetime of the token, e.g. 30d or 720h, or never")
+	return cmd
+}
+
+func newTokensRevokeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <token-id>",
+		Short: "Revoke a Personal Access Token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokenID := args[0]
+			cmd.SilenceUsage = true
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			if err := getTokensClient().RevokePersonalAccessToken(ctx, tokenID); err != nil {
+				klog.Errorf("Failed to revoke token: token_id=%s error=%v", tokenID, err)
+				return err
+			}
+			return getOutput().Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Token %s revoked",
+				Args:    []any{tokenID},
+				ID:      tokenID,
+			})
+		},
+	}
+}
+
+// scopesString renders token scopes; a token without any has full access
+func scopesString(scopes []string) string {
+	if len(scopes) == 0 {
+		return "full access"
+	}
+	return strings.Join(scopes, ",")
+}
+
+func formatOptionalTime(t *time.Time, none string) string {
+	if t == nil || t.IsZero() {
+		return none
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// tokenListResult implements Renderable for tokens list
+type tokenListResult struct {
+	tokens []api.PersonalAccessToken
+}
+
+func (r *tokenListResult) RenderJSON() any {
+	return tui.NewListResult(r.tokens)
+}
+
+func (r *tokenListResult) RenderTUI(out *tui.Output) {
+	if len(r.tokens) == 0 {
+		out.Info("No tokens found (see 'ggo auth tokens create')")
+		return
+	}
+	styles := tui.DefaultStyles()
+	now := time.Now()
+	rows := make([][]string, 0, len(r.tokens))
+	for _, t := range r.tokens {
+		expires := formatOptionalTime(t.ExpiresAt, "never")
+		if t.ExpiresAt != nil && now.After(*t.ExpiresAt) {
+			expires += " (EXPIRED)"
+		}
+		rows = append(rows, []string{
+			styles.Bold.Render(t.ID),
+			t.Name,
+			scopesString(t.Scopes),
+			t.CreatedAt.Local().Format("2006-01-02 15:04"),
+			expires,
+			formatOptionalTime(t.LastUsedAt, "-"),
+		})
+	}
+	out.Println(tui.NewTable().Headers("ID", "NAME", "SCOPES", "CREATED", "EXPIRES", "LAST USED").Rows(rows).String())
+}
+
+// tokenCreateResult implements Renderable for tokens create
+type tokenCreateResult struct {
+	token *api.PATCreateResponse
+}
+
+func (r *tokenCreateResult) RenderJSON() any {
+	return r.token
+}
+
+func (r *tokenCreateResult) RenderTUI(out *tui.Output) {
+	t := r.token
+	out.Success("Token created")
+	out.Println()
+
+	status := tui.NewStatusTable().
+		Add("ID", t.ID).
+		Add("Scopes", scopesString(t.Scopes)).
+		Add("Expires", formatOptionalTime(t.ExpiresAt, "never"))
+	if t.Name != "" {
+		status.Add("Name", t.Name)
+	}
+	out.Println(status.String())
+	out.Println()
+	out.Println("  " + tui.Code(t.Token))
+	out.Println()
+	out.Warning(i18n.T("Copy the token now; it is not shown again."))
+}
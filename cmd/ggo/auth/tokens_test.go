@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiresIn(t *testing.T) {
+	days, err := parseExpiresIn("30d")
+	require.NoError(t, err)
+	assert.Equal(t, 30, *days)
+
+	days, err = parseExpiresIn("36h")
+	require.NoError(t, err)
+	assert.Equal(t, 2, *days, "rounds up to whole days")
+
+	days, err = parseExpiresIn("never")
+	require.NoError(t, err)
+	assert.Nil(t, days)
+
+	_, err = parseExpiresIn("-1d")
+	assert.Error(t, err)
+}
+
+func TestValidateScopes(t *testing.T) {
+	assert.NoError(t, validateScopes(nil))
+	assert.NoError(t, validateScopes([]string{"read-only", "share-only"}))
+	assert.ErrorContains(t, validateScopes([]string{"admin"}), `unknown scope "admin"`)
+}
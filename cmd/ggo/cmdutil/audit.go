@@ -0,0 +1,50 @@
+package cmdutil
+
+import (
+	"github.com/NexusGPU/gpu-go/internal/audit"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+)
+
+// auditAnnotation marks commands whose runs are recorded in the audit log
+const auditAnnotation = "ggo.audit"
+
+// Audited marks cmd as a mutating command so that each run is recorded in
+// the local audit log. It returns cmd for use inside AddCommand.
+func Audited(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[auditAnnotation] = "true"
+	return cmd
+}
+
+// RecordAudit appends the run of an audited command to the audit log. It
+// is called once after the command tree has executed; runs of commands not
+// marked with Audited are ignored. Failing to write the log never fails the
+// command itself.
+func RecordAudit(cmd *cobra.Command, runErr error) {
+	if cmd == nil || cmd.Annotations[auditAnnotation] != "true" {
+		return
+	}
+
+	args := append([]string{}, cmd.Flags().Args()...)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		args = append(args, audit.FormatFlag(f.Name, f.Value.String()))
+	})
+	entry := audit.Entry{
+		Command: cmd.CommandPath(),
+		Args:    args,
+		Result:  audit.ResultSuccess,
+	}
+	if runErr != nil {
+		entry.Result = audit.ResultFailure
+		entry.Error = runErr.Error()
+	}
+
+	if err := audit.NewLog(platform.DefaultPaths().AuditLogFile()).Append(entry); err != nil {
+		klog.Warningf("Failed to record audit log entry: command=%s error=%v", entry.Command, err)
+	}
+}
@@ -0,0 +1,39 @@
+package cmdutil
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/history"
+	"k8s.io/klog/v2"
+)
+
+// StartBackgroundSync syncs the release manifest in a detached
+// 'ggo deps sync' when the command just run used an outdated manifest under
+// the background auto-sync policy. It is called once after the command tree
+// has executed, so the sync adds no latency to the command itself. At most
+// one sync is started every few minutes, and failing to start one never
+// fails the command.
+func StartBackgroundSync() {
+	pending := deps.TakeBackgroundSync()
+	if pending == nil || !deps.NewManager().ClaimBackgroundSync() {
+		return
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		klog.Warningf("Failed to start background release sync: error=%v", err)
+		return
+	}
+
+	cmd := exec.Command(exe, "deps", "sync", "--os", pending.OS, "--arch", pending.Arch, "--output", "json")
+	// The sync is housekeeping, not something the user ran
+	cmd.Env = append(os.Environ(), history.DisableEnv+"=1")
+	setDetachedProcAttr(cmd)
+	if err := cmd.Start(); err != nil {
+		klog.Warningf("Failed to start background release sync: error=%v", err)
+		return
+	}
+	klog.V(4).Infof("Started background release sync: pid=%d platform=%s/%s", cmd.Process.Pid, pending.OS, pending.Arch)
+	_ = cmd.Process.Release()
+}
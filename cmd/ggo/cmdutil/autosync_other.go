@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cmdutil
+
+import "os/exec"
+
+// setDetachedProcAttr keeps the defaults; background processes outlive the
+// console they were started from on Windows
+func setDetachedProcAttr(_ *exec.Cmd) {}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package cmdutil
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setDetachedProcAttr starts a background process in its own session, so
+// closing the terminal ggo ran in does not hang it up
+func setDetachedProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,53 @@
+package cmdutil
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// ApplyDefaults presets the flags of cmd that were not given on the command
+// line from the environment, the project's ggo.yaml and the user's
+// config.yaml, in that order. Values are set without marking the flags as
+// changed, so they behave like built-in defaults.
+func ApplyDefaults(cmd *cobra.Command) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	defaults, err := config.LoadLayeredDefaults(wd)
+	if err != nil {
+		return err
+	}
+
+	for _, setting := range config.Settings {
+		flag := cmd.Flags().Lookup(setting.Key)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		value, source, ok := defaults.Lookup(setting.Key)
+		if !ok {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid %s %q from %s: %w", setting.Key, value, defaultsOrigin(defaults, setting, source), err)
+		}
+		klog.V(4).Infof("Applied default: flag=%s source=%s", setting.Key, source)
+	}
+	return nil
+}
+
+// defaultsOrigin names where a default was read from for error messages
+func defaultsOrigin(d *config.Defaults, setting config.Setting, source string) string {
+	switch source {
+	case config.SourceProject:
+		return d.ProjectPath
+	case config.SourceUser:
+		return d.UserPath
+	default:
+		return setting.Env
+	}
+}
@@ -0,0 +1,160 @@
+package cmdutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	ggoerrors "github.com/NexusGPU/gpu-go/internal/errors"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of ggo, part of its interface to scripts; see
+// 'ggo help exit-codes'. Commands running another program, such as 'ggo run'
+// and 'ggo studio ssh', exit with that program's status instead.
+const (
+	ExitOK         = 0
+	ExitError      = 1
+	ExitUsage      = 2
+	ExitAuth       = 3
+	ExitNotFound   = 4
+	ExitConflict   = 5
+	ExitNetwork    = 6
+	ExitDependency = 7
+)
+
+// UsageErrorf returns an error for invalid arguments or flags, exiting with
+// ExitUsage
+func UsageErrorf(format string, args ...any) error {
+	return ggoerrors.WithKind(fmt.Errorf(format, args...), ggoerrors.ErrBadRequest)
+}
+
+// UsageError marks err, such as one parsing a flag, as invalid usage
+// exiting with ExitUsage; nil stays nil
+func UsageError(err error) error {
+	return ggoerrors.WithKind(err, ggoerrors.ErrBadRequest)
+}
+
+// NotFoundErrorf returns an error for a missing resource, exiting with
+// ExitNotFound
+func NotFoundErrorf(format string, args ...any) error {
+	return ggoerrors.WithKind(fmt.Errorf(format, args...), ggoerrors.ErrNotFound)
+}
+
+// AuthErrorf returns an error for a missing or rejected sign-in, exiting
+// with ExitAuth
+func AuthErrorf(format string, args ...any) error {
+	return ggoerrors.WithKind(fmt.Errorf(format, args...), ggoerrors.ErrUnauthorized)
+}
+
+// ConflictError marks err as a resource being in use or busy, exiting with
+// ExitConflict; nil stays nil
+func ConflictError(err error) error {
+	return ggoerrors.WithKind(err, ggoerrors.ErrConflict)
+}
+
+// DependencyError marks err as a GPU library, worker binary or runtime the
+// command needs being unavailable, exiting with ExitDependency; nil stays nil
+func DependencyError(err error) error {
+	return ggoerrors.WithKind(err, ggoerrors.ErrUnavailable)
+}
+
+// ExitCode returns the code ggo exits with after cmd, the command cobra ran
+// or tried to run, failed with err
+func ExitCode(cmd *cobra.Command, err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	// Commands such as studio ssh pass through a child's exit status
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	// Unknown commands fail before any command runs
+	if cmd != nil && !cmd.Runnable() {
+		return ExitUsage
+	}
+
+	var statusErr *api.StatusError
+	status := 0
+	if errors.As(err, &statusErr) {
+		status = statusErr.StatusCode
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ggoerrors.ErrBadRequest):
+		return ExitUsage
+	case errors.Is(err, ggoerrors.ErrUnauthorized), status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return ExitAuth
+	case errors.Is(err, ggoerrors.ErrNotFound), status == http.StatusNotFound:
+		return ExitNotFound
+	case errors.Is(err, ggoerrors.ErrConflict), status == http.StatusConflict:
+		return ExitConflict
+	case errors.Is(err, ggoerrors.ErrUnavailable), errors.Is(err, deps.ErrSignature):
+		return ExitDependency
+	case status >= http.StatusInternalServerError, status == http.StatusRequestTimeout, status == http.StatusTooManyRequests,
+		errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded), errors.Is(err, api.ErrGRPCUnavailable):
+		return ExitNetwork
+	}
+	return ExitError
+}
+
+// MarkUsageErrors makes cobra's own errors for bad flags and arguments of
+// root and its subcommands exit with ExitUsage. Call it once the command
+// tree is complete.
+func MarkUsageErrors(root *cobra.Command) {
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return UsageError(err)
+	})
+	var walk func(*cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if validate := cmd.Args; validate != nil {
+			cmd.Args = func(cmd *cobra.Command, args []string) error {
+				return UsageError(validate(cmd, args))
+			}
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+// ValidateFlags checks required and mutually exclusive flags as cobra does
+// before running cmd, failing with ExitUsage. It is run from the root's
+// persistent pre-run hook, since cobra's own check comes after it and its
+// errors cannot be told apart from the command's.
+func ValidateFlags(cmd *cobra.Command) error {
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return UsageError(err)
+	}
+	return UsageError(cmd.ValidateFlagGroups())
+}
+
+// NewExitCodesHelpCmd returns the 'ggo help exit-codes' help topic
+func NewExitCodesHelpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exit-codes",
+		Short: "Exit codes of ggo commands",
+		Long: fmt.Sprintf(`Exit codes of ggo commands, for scripts to tell failures apart:
+
+  %d  success
+  %d  any other error
+  %d  usage: unknown command, invalid flags or arguments
+  %d  authentication: not signed in, expired or revoked token, missing scope
+  %d  not found: worker, share, environment, profile or other resource
+  %d  conflict: the resource already exists, is in use or is busy
+  %d  network: the platform, a worker or a mirror could not be reached
+  %d  dependency: a GPU library, the worker binary or a container runtime
+      is missing, broken or could not be downloaded
+
+Commands that run another program, such as 'ggo run', 'ggo studio ssh',
+'ggo agent exec' and 'ggo history replay', exit with that program's exit
+status instead.`,
+			ExitOK, ExitError, ExitUsage, ExitAuth, ExitNotFound, ExitConflict, ExitNetwork, ExitDependency),
+	}
+}
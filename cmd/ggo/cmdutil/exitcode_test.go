@@ -0,0 +1,92 @@
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	ggoerrors "github.com/NexusGPU/gpu-go/internal/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type childExit int
+
+func (e childExit) Error() string { return "child failed" }
+func (e childExit) ExitCode() int { return int(e) }
+
+func TestExitCode(t *testing.T) {
+	run := &cobra.Command{Use: "run", RunE: func(*cobra.Command, []string) error { return nil }}
+	wrapped := func(err error) error { return fmt.Errorf("failed to list workers: %w", err) }
+	for name, tc := range map[string]struct {
+		err  error
+		want int
+	}{
+		"success":          {nil, ExitOK},
+		"plain":            {errors.New("boom"), ExitError},
+		"child":            {wrapped(childExit(42)), 42},
+		"usage":            {UsageErrorf("--a cannot be combined with --b"), ExitUsage},
+		"bad request":      {ggoerrors.BadRequest("invalid volume name"), ExitUsage},
+		"unauthorized":     {wrapped(&api.StatusError{StatusCode: 401}), ExitAuth},
+		"missing scope":    {wrapped(&api.ScopeError{StatusError: &api.StatusError{StatusCode: 403}, Scope: "workers:write"}), ExitAuth},
+		"not logged in":    {AuthErrorf("not logged in"), ExitAuth},
+		"api not found":    {wrapped(&api.StatusError{StatusCode: 404}), ExitNotFound},
+		"not found":        {ggoerrors.NotFound("environment", "dev"), ExitNotFound},
+		"api conflict":     {wrapped(&api.StatusError{StatusCode: 409}), ExitConflict},
+		"conflict":         {ggoerrors.Conflict("volume", "in use"), ExitConflict},
+		"server error":     {wrapped(&api.StatusError{StatusCode: 503}), ExitNetwork},
+		"rate limited":     {wrapped(&api.StatusError{StatusCode: 429}), ExitNetwork},
+		"connect":          {wrapped(&net.OpError{Op: "dial", Err: errors.New("connection refused")}), ExitNetwork},
+		"signature":        {fmt.Errorf("%w: invalid signature", deps.ErrSignature), ExitDependency},
+		"no backend":       {ggoerrors.Unavailable("no backend available"), ExitDependency},
+		"download failed":  {DependencyError(wrapped(&net.OpError{Op: "dial", Err: errors.New("timeout")})), ExitDependency},
+		"worker busy":      {ConflictError(errors.New("worker is at capacity")), ExitConflict},
+		"missing resource": {NotFoundErrorf("worker %q not found", "w1"), ExitNotFound},
+	} {
+		assert.Equal(t, tc.want, ExitCode(run, tc.err), name)
+	}
+
+	// The kind does not change the message
+	assert.Equal(t, "worker \"w1\" not found", NotFoundErrorf("worker %q not found", "w1").Error())
+	assert.Nil(t, DependencyError(nil))
+}
+
+func TestMarkUsageErrors(t *testing.T) {
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "ggo", SilenceErrors: true, SilenceUsage: true}
+		sub := &cobra.Command{
+			Use:  "get <name>",
+			Args: cobra.ExactArgs(1),
+			RunE: func(*cobra.Command, []string) error { return ggoerrors.NotFound("worker", "w1") },
+		}
+		sub.Flags().Int("count", 0, "")
+		sub.Flags().String("from", "", "")
+		sub.Flags().String("to", "", "")
+		sub.MarkFlagsMutuallyExclusive("from", "to")
+		root.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error { return ValidateFlags(cmd) }
+		root.AddCommand(sub)
+		MarkUsageErrors(root)
+		return root
+	}
+	for name, tc := range map[string]struct {
+		args []string
+		want int
+	}{
+		"unknown command": {[]string{"nope"}, ExitUsage},
+		"bad flag value":  {[]string{"get", "w1", "--count", "x"}, ExitUsage},
+		"unknown flag":    {[]string{"get", "w1", "--bogus"}, ExitUsage},
+		"missing arg":     {[]string{"get"}, ExitUsage},
+		"exclusive flags": {[]string{"get", "w1", "--from", "a", "--to", "b"}, ExitUsage},
+		"command error":   {[]string{"get", "w1"}, ExitNotFound},
+	} {
+		root := newRoot()
+		root.SetArgs(tc.args)
+		cmd, err := root.ExecuteC()
+		require.Error(t, err, name)
+		assert.Equal(t, tc.want, ExitCode(cmd, err), name)
+	}
+}
@@ -0,0 +1,25 @@
+package cmdutil
+
+import "fmt"
+
+// FormatBytes prints a byte count in binary units, e.g. 512 B or 1.5 MB
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// ValueOrDash returns s, or "-" for an empty value in a table cell
+func ValueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
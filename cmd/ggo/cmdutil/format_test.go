@@ -0,0 +1,15 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0 B", FormatBytes(0))
+	assert.Equal(t, "1023 B", FormatBytes(1023))
+	assert.Equal(t, "1.0 KB", FormatBytes(1024))
+	assert.Equal(t, "1.5 MB", FormatBytes(3<<19))
+	assert.Equal(t, "2.0 GB", FormatBytes(2<<30))
+}
@@ -0,0 +1,71 @@
+package cmdutil
+
+import (
+	"os"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/audit"
+	"github.com/NexusGPU/gpu-go/internal/history"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+)
+
+// RecordHistory appends the run of a command to the command history. It is
+// called once after the command tree has executed with the arguments ggo
+// was run with. The history commands themselves, help, shell completion and
+// runs with GGO_NO_HISTORY set are not recorded. Failing to write the history
+// never fails the command itself.
+func RecordHistory(cmd *cobra.Command, argv []string, started time.Time, runErr error) {
+	if cmd == nil || os.Getenv(history.DisableEnv) != "" || !recordsHistory(cmd) {
+		return
+	}
+
+	flags := map[string]string{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		// Defaults from the config files are set without marking the flag
+		// changed
+		if !f.Changed && f.Value.String() == f.DefValue {
+			return
+		}
+		value := f.Value.String()
+		if audit.IsSensitiveFlag(f.Name) {
+			value = audit.Redacted
+		}
+		flags[f.Name] = value
+	})
+	dir, _ := os.Getwd()
+	entry := history.Entry{
+		Timestamp:  started,
+		Command:    cmd.CommandPath(),
+		Argv:       history.RedactArgs(argv),
+		Flags:      flags,
+		Dir:        dir,
+		Result:     history.ResultSuccess,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if runErr != nil {
+		entry.Result = history.ResultFailure
+		entry.Error = runErr.Error()
+	}
+
+	if err := history.NewLog(platform.DefaultPaths().HistoryFile()).Append(entry); err != nil {
+		klog.Warningf("Failed to record command history: command=%s error=%v", entry.Command, err)
+	}
+}
+
+// recordsHistory reports whether runs of cmd belong in the history
+func recordsHistory(cmd *cobra.Command) bool {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if !c.Parent().HasParent() {
+			switch c.Name() {
+			case "history", "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+				return false
+			}
+			return true
+		}
+	}
+	// The bare root only prints its help
+	return false
+}
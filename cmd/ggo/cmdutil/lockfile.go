@@ -0,0 +1,22 @@
+package cmdutil
+
+import (
+	"os"
+
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"k8s.io/klog/v2"
+)
+
+// ProjectLockfile loads the ggo.lock of the project in the working directory.
+// It returns nil without an error when the project has no lockfile.
+func ProjectLockfile() (*deps.Lockfile, string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, "", err
+	}
+	lock, path, err := deps.FindLockfile(wd)
+	if lock != nil {
+		klog.Infof("Using project lockfile: path=%s libraries=%d", path, len(lock.Libraries))
+	}
+	return lock, path, err
+}
@@ -2,6 +2,9 @@
 package cmdutil
 
 import (
+	"fmt"
+
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/spf13/cobra"
 )
@@ -105,20 +108,37 @@ func (s *StatusData) RenderTUI(out *tui.Output) {
 // ActionData represents the result of an action (create, update, delete, etc.)
 type ActionData struct {
 	Success bool
-	Message string
+	Message string // printf format when Args is set
+	Args    []any
 	ID      string
 }
 
 func (a *ActionData) RenderJSON() any {
-	return tui.NewActionResult(a.Success, a.Message, a.ID)
+	return tui.NewActionResult(a.Success, formatMessage(a.Message, a.Args), a.ID)
 }
 
 func (a *ActionData) RenderTUI(out *tui.Output) {
 	if a.Success {
-		out.Success(a.Message)
+		out.Success(translateMessage(a.Message, a.Args))
 	} else {
-		out.Error(a.Message)
+		out.Error(translateMessage(a.Message, a.Args))
+	}
+}
+
+// formatMessage formats an untranslated message for JSON output
+func formatMessage(msg string, args []any) string {
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// translateMessage formats a translated message for TUI output
+func translateMessage(msg string, args []any) string {
+	if len(args) == 0 {
+		return i18n.T(msg)
 	}
+	return i18n.Tf(msg, args...)
 }
 
 // ListData represents a list of items with table rendering
@@ -166,7 +186,7 @@ func (d *DetailData[T]) RenderTUI(out *tui.Output) {
 	styles := tui.DefaultStyles()
 	if d.Title != "" {
 		out.Println()
-		out.Println(styles.Title.Render(d.Title))
+		out.Println(styles.Title.Render(i18n.T(d.Title)))
 		out.Println()
 	}
 	if d.ItemFunc != nil {
@@ -195,9 +215,9 @@ func (p *ProgressData) RenderJSON() any {
 
 func (p *ProgressData) RenderTUI(out *tui.Output) {
 	if p.Total > 0 {
-		out.Printf("\r  %s: %.1f%% (%d/%d bytes)", p.Operation, p.Progress, p.Current, p.Total)
+		out.Printf("\r  %s: %.1f%% (%d/%d bytes)", i18n.T(p.Operation), p.Progress, p.Current, p.Total)
 	} else {
-		out.Printf("%s %s...\n", p.Operation, p.Target)
+		out.Printf("%s %s...\n", i18n.T(p.Operation), p.Target)
 	}
 }
 
@@ -220,7 +240,8 @@ func (c *CompositeData) RenderTUI(out *tui.Output) {
 // MessageData represents a simple message (success, error, info, warning)
 type MessageData struct {
 	Type    string // "success", "error", "info", "warning"
-	Message string
+	Message string // printf format when Args is set
+	Args    []any
 	JSON    any // Optional custom JSON
 }
 
@@ -228,18 +249,19 @@ func (m *MessageData) RenderJSON() any {
 	if m.JSON != nil {
 		return m.JSON
 	}
-	return map[string]string{"message": m.Message}
+	return map[string]string{"message": formatMessage(m.Message, m.Args)}
 }
 
 func (m *MessageData) RenderTUI(out *tui.Output) {
+	msg := translateMessage(m.Message, m.Args)
 	switch m.Type {
 	case "success":
-		out.Success(m.Message)
+		out.Success(msg)
 	case "error":
-		out.Error(m.Message)
+		out.Error(msg)
 	case "warning":
-		out.Warning(m.Message)
+		out.Warning(msg)
 	default:
-		out.Info(m.Message)
+		out.Info(msg)
 	}
 }
@@ -0,0 +1,29 @@
+package cmdutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionDataTranslation(t *testing.T) {
+	i18n.SetLanguage("zh-CN")
+	t.Cleanup(func() { i18n.SetLanguage(i18n.DefaultLanguage) })
+
+	data := &ActionData{Success: true, Message: "Profile %s saved", Args: []any{"prod"}, ID: "prod"}
+	assert.Equal(t, tui.NewActionResult(true, "Profile prod saved", "prod"), data.RenderJSON(), "JSON stays in English")
+
+	var buf bytes.Buffer
+	out := NewOutput("table")
+	out.SetWriter(&buf)
+	require.NoError(t, out.Render(data))
+	assert.Contains(t, buf.String(), "Profile prod 已保存")
+
+	buf.Reset()
+	require.NoError(t, out.Render(&ActionData{Success: true, Message: "100% done"}))
+	assert.Contains(t, buf.String(), "100% done", "messages without Args are not formatted")
+}
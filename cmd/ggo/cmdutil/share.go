@@ -0,0 +1,364 @@
+package cmdutil
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+// shareTLSVerifyTimeout bounds the TLS handshake used to check a worker's pin
+const shareTLSVerifyTimeout = 10 * time.Second
+
+// shareLatencyTimeout bounds the connect used to measure a share's latency
+const shareLatencyTimeout = 5 * time.Second
+
+// happyEyeballsDelay is how long a connect to one share address gets before
+// the next address is tried alongside it
+const happyEyeballsDelay = 250 * time.Millisecond
+
+// shareProbeAttempts is the number of connects per share when ranking
+// shares; the fastest counts, so one slow handshake doesn't decide
+const shareProbeAttempts = 3
+
+// shareWaitInterval is how often a saturated share is polled for a free
+// slot; a variable so tests can shorten it
+var shareWaitInterval = 10 * time.Second
+
+// shareConsumerTimeout bounds consumer registration so an unreachable audit
+// endpoint never delays connecting to the GPU
+const shareConsumerTimeout = 5 * time.Second
+
+// RegisterShareConsumer records this machine (hostname, OS, client version)
+// in the share's audit trail. It is best-effort: failures are only logged.
+// via names the command that resolved the share, e.g. "use" or "studio".
+func RegisterShareConsumer(ctx context.Context, client *api.Client, shortCode, via, clientVersion string) {
+	hostname, _ := os.Hostname()
+
+	ctx, cancel := context.WithTimeout(ctx, shareConsumerTimeout)
+	defer cancel()
+
+	err := client.RegisterShareConsumer(ctx, shortCode, &api.ShareConsumerRequest{
+		Hostname:      hostname,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		ClientVersion: clientVersion,
+		Via:           via,
+	})
+	if err != nil {
+		klog.V(2).Infof("Failed to register share consumer: short_code=%s error=%v", shortCode, err)
+	}
+}
+
+// ExtractShortCode returns the short code of a share link, or the input if
+// it is a code already. Supports "abc123", "https://gpu.tf/s/abc123" and
+// "gpu.tf/s/abc123".
+func ExtractShortCode(input string) string {
+	input = strings.TrimSuffix(strings.TrimSpace(input), "/")
+	if i := strings.LastIndex(input, "/"); i >= 0 {
+		return input[i+1:]
+	}
+	return input
+}
+
+// shareAliasPattern is what the platform accepts as a share alias: lowercase
+// letters, digits and inner hyphens, 3 to 32 characters
+var shareAliasPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,30}[a-z0-9]$`)
+
+// ValidateShareAlias checks an alias given to a new share
+func ValidateShareAlias(alias string) error {
+	if !shareAliasPattern.MatchString(alias) {
+		return UsageErrorf("invalid alias %q: use 3 to 32 lowercase letters, digits and hyphens, starting and ending with a letter or digit", alias)
+	}
+	return nil
+}
+
+// ShareAliasError explains a share creation the platform refused because
+// another share of the account already has the alias. Other errors are
+// returned as they are.
+func ShareAliasError(ctx context.Context, client *api.Client, alias string, err error) error {
+	if alias == "" || !api.IsConflict(err) {
+		return err
+	}
+	if resp, listErr := client.ListShares(ctx); listErr == nil {
+		for _, s := range resp.Shares {
+			if s.Alias == alias {
+				return fmt.Errorf("alias %q is already used by share %s of worker %s in your account; delete it with 'ggo share delete %s' or choose another alias",
+					alias, s.ShortCode, s.WorkerID, s.ShareID)
+			}
+		}
+	}
+	return fmt.Errorf("alias %q is already used by another share in your account; choose another alias", alias)
+}
+
+// ResolveShareAlias returns the short code of the share named alias in the
+// account of the client's user. It reports false when ref is not one of
+// their aliases, so that it is used as a short code: aliases are unique per
+// account only, and a consumer's own aliases win over other short codes.
+func ResolveShareAlias(ctx context.Context, client *api.Client, ref string) (string, bool) {
+	if !shareAliasPattern.MatchString(ref) {
+		return "", false
+	}
+	share, err := client.ResolveShareAlias(ctx, ref)
+	switch {
+	case api.IsNotFound(err):
+		return "", false
+	case err != nil:
+		klog.V(2).Infof("Failed to resolve share alias, using it as a short code: alias=%s error=%v", ref, err)
+		return "", false
+	}
+	klog.V(2).Infof("Resolved share alias: alias=%s short_code=%s", ref, share.ShortCode)
+	return share.ShortCode, true
+}
+
+// VerifyShareTLS checks that the worker behind a share presents the
+// certificate pinned in the share info. Shares without a fingerprint are
+// served over plain TCP and are not checked.
+func VerifyShareTLS(ctx context.Context, info *api.SharePublicInfo) error {
+	if info.TLSFingerprint == "" {
+		return nil
+	}
+	addr, err := shareAddr(info)
+	if err != nil {
+		return fmt.Errorf("cannot verify worker certificate: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shareTLSVerifyTimeout)
+	defer cancel()
+	if err := utils.VerifyPinnedCert(ctx, addr, info.TLSFingerprint); err != nil {
+		return fmt.Errorf("worker %s failed TLS verification: %w", info.WorkerID, err)
+	}
+	return nil
+}
+
+// ShareLatency measures the TCP connect time to the share's connection
+// address: the GPU host for direct shares, the relay for relayed ones
+func ShareLatency(ctx context.Context, info *api.SharePublicInfo) (time.Duration, error) {
+	addr, err := shareAddr(info)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, shareLatencyTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	_ = conn.Close()
+	return latency, nil
+}
+
+// ShareProbe is the outcome of probing a share for ranking
+type ShareProbe struct {
+	ShortCode string
+	Info      *api.SharePublicInfo
+	Latency   time.Duration
+	Err       error
+}
+
+// ProbeShares resolves and probes shares concurrently and returns them
+// fastest first, with failed ones last in the order given. A share is healthy
+// when the platform resolves it, its worker presents the pinned certificate
+// (for TLS shares) and its connection address accepts TCP connections; the
+// latency is the fastest of a few connects.
+func ProbeShares(ctx context.Context, client *api.Client, codes []string) []ShareProbe {
+	probes := make([]ShareProbe, len(codes))
+	var wg sync.WaitGroup
+	for i, code := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probes[i] = probeShare(ctx, client, code)
+		}()
+	}
+	wg.Wait()
+
+	slices.SortStableFunc(probes, func(a, b ShareProbe) int {
+		switch {
+		case a.Err != nil && b.Err != nil:
+			return 0
+		case a.Err != nil:
+			return 1
+		case b.Err != nil:
+			return -1
+		}
+		// A busy worker makes clients wait, so it ranks after free ones
+		if aBusy, bBusy := a.Info.Queue.Saturated(), b.Info.Queue.Saturated(); aBusy != bBusy {
+			if aBusy {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a.Latency, b.Latency)
+	})
+	return probes
+}
+
+func probeShare(ctx context.Context, client *api.Client, code string) ShareProbe {
+	probe := ShareProbe{ShortCode: code}
+	probe.Info, probe.Err = client.GetSharePublic(ctx, code)
+	if probe.Err != nil {
+		return probe
+	}
+	SelectShareAddress(ctx, probe.Info)
+	if probe.Err = VerifyShareTLS(ctx, probe.Info); probe.Err != nil {
+		return probe
+	}
+	for range shareProbeAttempts {
+		latency, err := ShareLatency(ctx, probe.Info)
+		if err != nil {
+			probe.Err = err
+			return probe
+		}
+		if probe.Latency == 0 || latency < probe.Latency {
+			probe.Latency = latency
+		}
+	}
+	return probe
+}
+
+// FormatShareRoute describes how a share is reached and its measured
+// latency, e.g. "via relay, 85ms" or "direct, unreachable"
+func FormatShareRoute(info *api.SharePublicInfo, latency time.Duration, err error) string {
+	route := "direct"
+	if info.Relay {
+		route = "via relay"
+	}
+	if err != nil {
+		return route + ", unreachable"
+	}
+	return fmt.Sprintf("%s, %dms", route, latency.Milliseconds())
+}
+
+// FormatShareQueue describes a worker's client load, e.g. "4/4 clients
+// connected, position 2 of 3 in queue, about 5m wait"
+func FormatShareQueue(q *api.ShareQueueStatus) string {
+	if q == nil {
+		return "unknown"
+	}
+	if q.MaxConnections == 0 {
+		return fmt.Sprintf("%d clients connected", q.Connections)
+	}
+	s := fmt.Sprintf("%d/%d clients connected", q.Connections, q.MaxConnections)
+	switch {
+	case q.Position > 0:
+		s += fmt.Sprintf(", position %d of %d in queue", q.Position, max(q.Waiting, q.Position))
+	case q.Waiting > 0:
+		s += fmt.Sprintf(", %d waiting", q.Waiting)
+	}
+	if q.EstimatedWaitSeconds > 0 {
+		wait := time.Duration(q.EstimatedWaitSeconds) * time.Second
+		if wait < time.Minute {
+			s += fmt.Sprintf(", about %ds wait", q.EstimatedWaitSeconds)
+		} else {
+			s += fmt.Sprintf(", about %dm wait", int(wait.Round(time.Minute).Minutes()))
+		}
+	}
+	return s
+}
+
+// FormatFairness describes how a worker divides its GPU time between
+// clients, with the share each client is guaranteed when maxClients is known
+func FormatFairness(f *api.WorkerFairness, maxClients int) string {
+	if f.IsZero() {
+		return "none"
+	}
+	var parts []string
+	if f.PerClientComputePercent > 0 {
+		parts = append(parts, fmt.Sprintf("up to %d%% compute per client", f.PerClientComputePercent))
+	}
+	if f.Scheduling != "" {
+		parts = append(parts, f.Scheduling)
+	}
+	if guaranteed := f.GuaranteedComputePercent(maxClients); guaranteed > 0 {
+		parts = append(parts, fmt.Sprintf("%d%% guaranteed", guaranteed))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ShareBusyError is returned when a share's worker serves its maximum number
+// of clients
+type ShareBusyError struct {
+	WorkerID string
+	Queue    *api.ShareQueueStatus
+}
+
+func (e *ShareBusyError) Error() string {
+	return fmt.Sprintf("GPU worker %s is busy: %s (pass --wait to queue for a free slot)", e.WorkerID, FormatShareQueue(e.Queue))
+}
+
+// WaitForShareSlot polls a saturated share until its worker has a free client
+// slot or ctx is done. progress is called with each status while waiting;
+// failed polls are retried.
+func WaitForShareSlot(ctx context.Context, queue *api.ShareQueueStatus, poll func(context.Context) (*api.ShareQueueStatus, error), progress func(*api.ShareQueueStatus)) error {
+	ticker := time.NewTicker(shareWaitInterval)
+	defer ticker.Stop()
+	for queue.Saturated() {
+		progress(queue)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no free slot while waiting (%s): %w", FormatShareQueue(queue), ctx.Err())
+		case <-ticker.C:
+		}
+		next, err := poll(ctx)
+		if err != nil {
+			klog.Warningf("Failed to poll share queue, retrying: error=%v", err)
+			continue
+		}
+		queue = next
+	}
+	return nil
+}
+
+// shareAddr returns the host:port of a share's connection URL
+func shareAddr(info *api.SharePublicInfo) (string, error) {
+	return utils.ConnectionAddr(info.ConnectionURL)
+}
+
+// SelectShareAddress points a dual-stack share at the first of its
+// connection URLs that accepts a connection, so clients whose network lacks
+// the primary address family fall back to the other one. The share is left
+// as is when it has no fallback or none of its addresses is reachable.
+func SelectShareAddress(ctx context.Context, info *api.SharePublicInfo) {
+	if len(info.FallbackConnectionURLs) == 0 {
+		return
+	}
+	urls := append([]string{info.ConnectionURL}, info.FallbackConnectionURLs...)
+	var addrs, candidates []string
+	for _, u := range urls {
+		addr, err := utils.ConnectionAddr(u)
+		if err != nil {
+			klog.Warningf("Ignoring share address: connection_url=%s error=%v", u, err)
+			continue
+		}
+		addrs = append(addrs, addr)
+		candidates = append(candidates, u)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shareLatencyTimeout)
+	defer cancel()
+	idx, err := utils.DialFirst(ctx, addrs, happyEyeballsDelay)
+	if err != nil {
+		klog.V(2).Infof("No share address reachable: worker_id=%s error=%v", info.WorkerID, err)
+		return
+	}
+	if candidates[idx] != info.ConnectionURL {
+		klog.Infof("Primary share address unreachable, using fallback: worker_id=%s connection_url=%s", info.WorkerID, candidates[idx])
+		info.ConnectionURL = candidates[idx]
+	}
+}
@@ -0,0 +1,123 @@
+package cmdutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractShortCode(t *testing.T) {
+	assert.Equal(t, "abc123", ExtractShortCode(" abc123 "))
+	assert.Equal(t, "abc123", ExtractShortCode("https://gpu.tf/s/abc123/"))
+	assert.Equal(t, "abc123", ExtractShortCode("gpu.tf/s/abc123"))
+}
+
+func TestFormatShareQueue(t *testing.T) {
+	assert.Equal(t, "unknown", FormatShareQueue(nil))
+	assert.Equal(t, "3 clients connected", FormatShareQueue(&api.ShareQueueStatus{Connections: 3}))
+	assert.Equal(t, "4/4 clients connected, 2 waiting, about 45s wait",
+		FormatShareQueue(&api.ShareQueueStatus{Connections: 4, MaxConnections: 4, Waiting: 2, EstimatedWaitSeconds: 45}))
+	assert.Equal(t, "4/4 clients connected, position 2 of 3 in queue, about 5m wait",
+		FormatShareQueue(&api.ShareQueueStatus{Connections: 4, MaxConnections: 4, Waiting: 3, Position: 2, EstimatedWaitSeconds: 290}))
+}
+
+func TestFormatFairness(t *testing.T) {
+	assert.Equal(t, "none", FormatFairness(nil, 4))
+	capped := &api.WorkerFairness{PerClientComputePercent: 25, Scheduling: api.SchedulingRoundRobin}
+	assert.Equal(t, "up to 25% compute per client, round-robin", FormatFairness(capped, 0))
+	assert.Equal(t, "up to 25% compute per client, round-robin, 25% guaranteed", FormatFairness(capped, 4))
+	// More clients than caps fit: round-robin still splits the GPU evenly
+	assert.Equal(t, "up to 25% compute per client, round-robin, 16% guaranteed", FormatFairness(capped, 6))
+	// First come, first served guarantees nothing once the caps overlap
+	assert.Equal(t, "up to 40% compute per client", FormatFairness(&api.WorkerFairness{PerClientComputePercent: 40}, 3))
+}
+
+func TestWaitForShareSlot(t *testing.T) {
+	orig := shareWaitInterval
+	shareWaitInterval = time.Millisecond
+	t.Cleanup(func() { shareWaitInterval = orig })
+
+	busy := &api.ShareQueueStatus{Connections: 2, MaxConnections: 2, Position: 1}
+	polls := []*api.ShareQueueStatus{busy, nil, {Connections: 1, MaxConnections: 2}}
+	var errs int
+	poll := func(context.Context) (*api.ShareQueueStatus, error) {
+		next := polls[0]
+		polls = polls[1:]
+		if next == nil {
+			errs++
+			return nil, errors.New("temporary failure")
+		}
+		return next, nil
+	}
+	var seen int
+	require.NoError(t, WaitForShareSlot(context.Background(), busy, poll, func(*api.ShareQueueStatus) { seen++ }))
+	assert.Empty(t, polls)
+	assert.Equal(t, 1, errs, "failed polls are retried")
+	assert.Equal(t, 3, seen)
+
+	// Free workers return at once
+	require.NoError(t, WaitForShareSlot(context.Background(), nil, nil, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := WaitForShareSlot(ctx, busy, poll, func(*api.ShareQueueStatus) {})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "2/2 clients connected")
+}
+
+func TestValidateShareAlias(t *testing.T) {
+	for _, alias := range []string{"lab-3090", "a40", "gpu-box-2"} {
+		assert.NoError(t, ValidateShareAlias(alias), alias)
+	}
+	for _, alias := range []string{"", "ab", "Lab-3090", "-lab", "lab-", "lab_3090", "https://gpu.tf/s/abc", "a123456789012345678901234567890123"} {
+		assert.Error(t, ValidateShareAlias(alias), alias)
+	}
+}
+
+func TestShareAliases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/shares/aliases/lab-3090":
+			_ = json.NewEncoder(w).Encode(api.ShareInfo{ShareID: "share-1", ShortCode: "x7k2p9", Alias: "lab-3090"})
+		case "/api/v1/shares/aliases/flaky":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/api/v1/shares":
+			_ = json.NewEncoder(w).Encode(api.ShareListResponse{Shares: []api.ShareInfo{
+				{ShareID: "share-1", ShortCode: "x7k2p9", WorkerID: "worker-1", Alias: "lab-3090"},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client := api.NewClient(api.WithBaseURL(server.URL), api.WithUserToken("token"))
+	ctx := context.Background()
+
+	code, ok := ResolveShareAlias(ctx, client, "lab-3090")
+	assert.True(t, ok)
+	assert.Equal(t, "x7k2p9", code)
+	// Not an alias of the user's, or not resolvable now: used as a short code
+	for _, ref := range []string{"abc123", "flaky", "Not-An-Alias"} {
+		_, ok = ResolveShareAlias(ctx, client, ref)
+		assert.False(t, ok, ref)
+	}
+
+	conflict := &api.StatusError{StatusCode: http.StatusConflict, Body: "alias taken"}
+	err := ShareAliasError(ctx, client, "lab-3090", conflict)
+	assert.ErrorContains(t, err, `alias "lab-3090" is already used by share x7k2p9 of worker worker-1`)
+	assert.ErrorContains(t, err, "ggo share delete share-1")
+	assert.ErrorContains(t, ShareAliasError(ctx, client, "other", conflict), "already used by another share")
+
+	other := errors.New("boom")
+	assert.Equal(t, other, ShareAliasError(ctx, client, "lab-3090", other))
+	assert.Equal(t, error(conflict), ShareAliasError(ctx, client, "", conflict), "without an alias the conflict is something else")
+}
@@ -0,0 +1,276 @@
+// Package config implements the ggo config command for managing CLI defaults and profiles
+package config
+
+import (
+	"fmt"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/credentials"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ProfileEnv selects the active profile when --profile is not given
+	ProfileEnv = config.ProfileEnv
+
+	// defaultProfileName is reserved by 'profile use' to select the default endpoint
+	defaultProfileName = "default"
+)
+
+var outputFormat string
+
+// ApplyProfile activates the named profile (or GGO_PROFILE / the saved current
+// profile when empty) for the rest of the process
+func ApplyProfile(name string) (*config.Profile, error) {
+	return profileManager().ApplyProfile(name)
+}
+
+func profileManager() *config.Manager {
+	return config.NewManager(platform.DefaultPaths().ConfigDir(), "")
+}
+
+// NewConfigCmd creates the config command
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage CLI configuration",
+		Long: `Manage GPU Go CLI configuration such as flag defaults and named profiles
+for multiple control planes.
+
+Flag defaults are read from ~/.config/ggo/config.yaml and from ./ggo.yaml in
+the working directory. A flag given on the command line wins over its
+environment variable, which wins over ./ggo.yaml, which wins over the user
+file. A profile selected with --profile, GGO_PROFILE or 'profile use' sets
+GPU_GO_ENDPOINT and therefore counts as the environment.
+
+Example ggo.yaml:
+  server: https://gpu.example.com
+  mode: colima
+  colima-profile: gpu
+  platform: linux/amd64`,
+	}
+
+	cmdutil.AddOutputFlag(cmd, &outputFormat)
+	cmd.AddCommand(newProfileCmd())
+	cmd.AddCommand(newSetCmd())
+	cmd.AddCommand(newGetCmd())
+	cmd.AddCommand(newListCmd())
+
+	return cmd
+}
+
+func getOutput() *tui.Output {
+	return cmdutil.NewOutput(outputFormat)
+}
+
+func newProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named endpoint profiles",
+		Long: `Manage named profiles, each with its own endpoint, token and cached manifests.
+
+Select a profile per command with --profile or GGO_PROFILE, or persistently
+with 'ggo config profile use'.
+
+Examples:
+  # Add a self-hosted control plane
+  ggo config profile add staging --endpoint https://gpu.example.com --token <pat>
+
+  # Run a single command against it
+  ggo --profile staging worker list
+
+  # Make it the default
+  ggo config profile use staging`,
+	}
+
+	cmd.AddCommand(newProfileAddCmd())
+	cmd.AddCommand(newProfileListCmd())
+	cmd.AddCommand(newProfileUseCmd())
+	cmd.AddCommand(newProfileRemoveCmd())
+
+	return cmd
+}
+
+func newProfileAddCmd() *cobra.Command {
+	var endpoint, token string
+	var use bool
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add or update a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			name := args[0]
+			if platform.NormalizeName(name) != name {
+				return cmdutil.UsageErrorf("invalid profile name %q: use lowercase letters, digits, '-' or '_'", name)
+			}
+			if name == defaultProfileName {
+				return fmt.Errorf("profile name %q is reserved for the default endpoint", name)
+			}
+			if endpoint == "" {
+				return cmdutil.UsageErrorf("--endpoint is required")
+			}
+
+			mgr := profileManager()
+			profiles, err := mgr.LoadProfiles()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load profiles: error=%v", err)
+				return err
+			}
+
+			profiles.Set(config.Profile{Name: name, Endpoint: endpoint, Token: token})
+			if use {
+				profiles.Current = name
+			}
+			if err := mgr.SaveProfiles(profiles); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to save profiles: error=%v", err)
+				return err
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Profile %s saved",
+				Args:    []any{name},
+				ID:      name,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "Control plane endpoint URL")
+	cmd.Flags().StringVar(&token, "token", "", "Personal Access Token (PAT) for this endpoint")
+	cmd.Flags().BoolVar(&use, "use", false, "Make this the current profile")
+
+	return cmd
+}
+
+func newProfileListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			profiles, err := profileManager().LoadProfiles()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load profiles: error=%v", err)
+				return err
+			}
+
+			items := make([]profileItem, 0, len(profiles.Profiles))
+			for _, p := range profiles.Profiles {
+				items = append(items, profileItem{
+					Name:     p.Name,
+					Endpoint: p.Endpoint,
+					Token:    credentials.Redact(p.Token),
+					Current:  p.Name == profiles.Current,
+				})
+			}
+
+			return out.Render(&cmdutil.ListData[profileItem]{
+				Items:   items,
+				Headers: []string{"", "NAME", "ENDPOINT", "TOKEN"},
+				RowFunc: func(p profileItem, styles *tui.Styles) []string {
+					marker := ""
+					if p.Current {
+						marker = styles.Success.Render("*")
+					}
+					return []string{marker, p.Name, p.Endpoint, p.Token}
+				},
+				Empty: "No profiles configured. Add one with 'ggo config profile add'.",
+			})
+		},
+	}
+}
+
+func newProfileUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Set the current profile (use \"default\" to clear)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			name := args[0]
+
+			mgr := profileManager()
+			profiles, err := mgr.LoadProfiles()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load profiles: error=%v", err)
+				return err
+			}
+
+			if name == defaultProfileName {
+				profiles.Current = ""
+			} else {
+				if profiles.Get(name) == nil {
+					cmd.SilenceUsage = true
+					return cmdutil.NotFoundErrorf("profile not found: %s", name)
+				}
+				profiles.Current = name
+			}
+			if err := mgr.SaveProfiles(profiles); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to save profiles: error=%v", err)
+				return err
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Switched to profile %s",
+				Args:    []any{name},
+				ID:      name,
+			})
+		},
+	}
+}
+
+func newProfileRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a profile",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			name := args[0]
+
+			mgr := profileManager()
+			profiles, err := mgr.LoadProfiles()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load profiles: error=%v", err)
+				return err
+			}
+			if !profiles.Remove(name) {
+				cmd.SilenceUsage = true
+				return cmdutil.NotFoundErrorf("profile not found: %s", name)
+			}
+			if err := mgr.SaveProfiles(profiles); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to save profiles: error=%v", err)
+				return err
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Profile %s removed",
+				Args:    []any{name},
+				ID:      name,
+			})
+		},
+	}
+}
+
+type profileItem struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	Token    string `json:"token,omitempty"`
+	Current  bool   `json:"current"`
+}
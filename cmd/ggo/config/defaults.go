@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// settingKeys lists the accepted keys for help texts and errors
+func settingKeys() string {
+	keys := make([]string, 0, len(config.Settings))
+	for _, s := range config.Settings {
+		keys = append(keys, s.Key)
+	}
+	return strings.Join(keys, ", ")
+}
+
+func lookupSetting(key string) (config.Setting, error) {
+	setting, ok := config.LookupSetting(key)
+	if !ok {
+		return setting, fmt.Errorf("unknown setting %q (valid settings: %s)", key, settingKeys())
+	}
+	return setting, nil
+}
+
+// loadDefaults reads the user defaults and the ggo.yaml of the working directory
+func loadDefaults() (*config.Defaults, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadLayeredDefaults(wd)
+}
+
+func newSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a default in the user config file",
+		Long: `Set a flag default in the user config file (~/.config/ggo/config.yaml).
+
+Defaults apply to every command with a flag of the same name. Flags given on
+the command line take precedence, then environment variables, then the
+project's ./ggo.yaml, then the user config file. An empty value removes the
+setting.
+
+Settings: ` + settingKeys() + `
+
+Examples:
+  ggo config set server https://gpu.example.com
+  ggo config set docker-host unix:///var/run/docker.sock
+  ggo config set platform ""`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			key, value := args[0], args[1]
+			if _, err := lookupSetting(key); err != nil {
+				return err
+			}
+
+			path := config.UserDefaultsPath()
+			defaults, err := config.LoadDefaults(path)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load user config: path=%s error=%v", path, err)
+				return err
+			}
+			message := fmt.Sprintf("Set %s in %s", key, path)
+			if value == "" {
+				delete(defaults, key)
+				message = fmt.Sprintf("Removed %s from %s", key, path)
+			} else {
+				defaults[key] = value
+			}
+			if err := config.SaveDefaults(path, defaults); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to save user config: path=%s error=%v", path, err)
+				return err
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: message,
+				ID:      key,
+			})
+		},
+	}
+}
+
+func newGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Show the effective default of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			key := args[0]
+			if _, err := lookupSetting(key); err != nil {
+				return err
+			}
+			defaults, err := loadDefaults()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load config: error=%v", err)
+				return err
+			}
+			value, source, _ := defaults.Lookup(key)
+			return out.Render(&settingResult{Key: key, Value: value, Source: source})
+		},
+	}
+}
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List settings with their effective defaults",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			defaults, err := loadDefaults()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to load config: error=%v", err)
+				return err
+			}
+
+			items := make([]settingResult, 0, len(config.Settings))
+			for _, s := range config.Settings {
+				value, source, _ := defaults.Lookup(s.Key)
+				items = append(items, settingResult{Key: s.Key, Value: value, Source: source, Env: s.Env, Usage: s.Usage})
+			}
+			return out.Render(&cmdutil.ListData[settingResult]{
+				Items:   items,
+				Headers: []string{"KEY", "VALUE", "SOURCE", "ENV", "DESCRIPTION"},
+				RowFunc: func(s settingResult, styles *tui.Styles) []string {
+					value, source := s.Value, s.Source
+					if source == "" {
+						value, source = styles.Muted.Render("-"), styles.Muted.Render("-")
+					}
+					return []string{s.Key, value, source, s.Env, s.Usage}
+				},
+			})
+		},
+	}
+}
+
+// settingResult is a setting with its effective default
+type settingResult struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+	Env    string `json:"env,omitempty"`
+	Usage  string `json:"description,omitempty"`
+}
+
+func (s *settingResult) RenderJSON() any {
+	return s
+}
+
+func (s *settingResult) RenderTUI(out *tui.Output) {
+	out.Println(s.Value)
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"runtime"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
 	"github.com/NexusGPU/gpu-go/internal/api"
 	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/progress"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -31,6 +35,15 @@ var (
 	downloadOS      string
 	downloadArch    string
 	outputFormat    string
+	channel         string
+	mirrorURL       string
+	locked          bool
+	lockOutput      string
+	insecure        bool
+	whichType       string
+	whichVendor     string
+	whichOS         string
+	whichArch       string
 )
 
 // NewDepsCmd creates the deps command
@@ -43,6 +56,8 @@ func NewDepsCmd() *cobra.Command {
 
 	cmd.PersistentFlags().StringVar(&cdnURL, "cdn", deps.DefaultCDNBaseURL, "CDN base URL")
 	cmd.PersistentFlags().StringVar(&apiURL, "api", api.GetDefaultBaseURL(), "API base URL (or set GPU_GO_ENDPOINT env var)")
+	cmd.PersistentFlags().StringVar(&mirrorURL, "mirror", "", "Download artifacts from this mirror base URL instead of the CDN (overrides 'ggo deps mirror use')")
+	cmd.PersistentFlags().BoolVar(&insecure, "insecure-skip-signature", false, "Skip verifying the publisher signature of downloaded artifacts, for development (or set GGO_INSECURE_SKIP_SIGNATURE=1)")
 	cmdutil.AddOutputFlag(cmd, &outputFormat)
 
 	cmd.AddCommand(newSyncCmd())
@@ -51,15 +66,25 @@ func NewDepsCmd() *cobra.Command {
 	cmd.AddCommand(newInstallCmd())
 	cmd.AddCommand(newUpdateCmd())
 	cmd.AddCommand(newCleanCmd())
+	cmd.AddCommand(newChannelCmd())
+	cmd.AddCommand(newAutoSyncCmd())
+	cmd.AddCommand(newPinCmd())
+	cmd.AddCommand(newUnpinCmd())
+	cmd.AddCommand(newMirrorCmd())
+	cmd.AddCommand(newLockCmd())
+	cmd.AddCommand(newWhichCmd())
 
 	return cmd
 }
 
-func getManager() *deps.Manager {
-	return deps.NewManager(
+func getManager(opts ...deps.ManagerOption) *deps.Manager {
+	return deps.NewManager(append([]deps.ManagerOption{
 		deps.WithCDNBaseURL(cdnURL),
 		deps.WithAPIBaseURL(apiURL),
-	)
+		deps.WithChannel(channel),
+		deps.WithMirrorURL(mirrorURL),
+		deps.WithInsecureSkipSignature(insecure),
+	}, opts...)...)
 }
 
 func getOutput() *tui.Output {
@@ -80,9 +105,9 @@ func newSyncCmd() *cobra.Command {
 			targetArch := syncArch
 			if !out.IsJSON() {
 				if targetOS != "" || targetArch != "" {
-					fmt.Printf("Syncing releases from API for platform %s/%s...\n", targetOS, targetArch)
+					fmt.Printf(i18n.T("Syncing releases from API for platform %s/%s...\n"), targetOS, targetArch)
 				} else {
-					fmt.Println("Syncing releases from API...")
+					fmt.Println(i18n.T("Syncing releases from API..."))
 				}
 			}
 
@@ -114,29 +139,29 @@ func (r *syncResult) RenderJSON() any {
 
 func (r *syncResult) RenderTUI(out *tui.Output) {
 	if r.manifest != nil {
-		out.Success(fmt.Sprintf("Synced %d libraries (manifest version: %s)", len(r.manifest.Libraries), r.manifest.Version))
+		out.Successf("Synced %d libraries (manifest version: %s)", len(r.manifest.Libraries), r.manifest.Version)
 
 		if len(r.manifest.Libraries) > 0 {
 			if r.verbose {
-				fmt.Println("\nSynced libraries:")
+				fmt.Println(i18n.T("\nSynced libraries:"))
 				for _, lib := range r.manifest.Libraries {
-					fmt.Printf("  Name: %s\n", lib.Name)
-					fmt.Printf("    Version: %s\n", lib.Version)
-					fmt.Printf("    Type: %s\n", lib.Type)
-					fmt.Printf("    Platform: %s/%s\n", lib.Platform, lib.Arch)
-					fmt.Printf("    Size: %d bytes\n", lib.Size)
-					fmt.Printf("    SHA256: %s\n", lib.SHA256)
-					fmt.Printf("    URL: %s\n", lib.URL)
+					fmt.Printf(i18n.T("  Name: %s\n"), lib.Name)
+					fmt.Printf(i18n.T("    Version: %s\n"), lib.Version)
+					fmt.Printf(i18n.T("    Type: %s\n"), lib.Type)
+					fmt.Printf(i18n.T("    Platform: %s/%s\n"), lib.Platform, lib.Arch)
+					fmt.Printf(i18n.T("    Size: %d bytes\n"), lib.Size)
+					fmt.Printf(i18n.T("    SHA256: %s\n"), lib.SHA256)
+					fmt.Printf(i18n.T("    URL: %s\n"), lib.URL)
 					fmt.Println()
 				}
 			} else {
-				fmt.Println("\nSynced libraries:")
+				fmt.Println(i18n.T("\nSynced libraries:"))
 				for _, lib := range r.manifest.Libraries {
 					typeStr := ""
 					if lib.Type != "" {
 						typeStr = fmt.Sprintf(" [%s]", lib.Type)
 					}
-					fmt.Printf("  %s (version: %s, platform: %s/%s)%s\n", lib.Name, lib.Version, lib.Platform, lib.Arch, typeStr)
+					fmt.Printf(i18n.T("  %s (version: %s, platform: %s/%s)%s\n"), lib.Name, lib.Version, lib.Platform, lib.Arch, typeStr)
 				}
 			}
 		}
@@ -173,7 +198,7 @@ func newListCmd() *cobra.Command {
 			if synced && !out.IsJSON() {
 				diff, _ := mgr.ComputeUpdateDiff()
 				if diff != nil && len(diff.ToDownload) > 0 {
-					out.Info(fmt.Sprintf("Manifest updated. %d updates available. Run 'ggo deps update' to upgrade.", len(diff.ToDownload)))
+					out.Infof("Manifest updated. %d updates available. Run 'ggo deps update' to upgrade.", len(diff.ToDownload))
 				}
 			}
 
@@ -196,7 +221,11 @@ func newListCmd() *cobra.Command {
 			// Build merged display libraries (group by name+platform+arch, show latest version)
 			displayLibs := buildMergedDisplayLibraries(libs, depsManifest, downloaded)
 
-			return out.Render(&listResult{libs: displayLibs, filterDesc: filterDesc})
+			status, err := mgr.ManifestStatus()
+			if err != nil {
+				klog.Warningf("Failed to check release manifest age: error=%v", err)
+			}
+			return out.Render(&listResult{libs: displayLibs, filterDesc: filterDesc, manifest: status})
 		},
 	}
 
@@ -348,18 +377,30 @@ func determineStatus(dLib DisplayLibrary) string {
 type listResult struct {
 	libs       []DisplayLibrary
 	filterDesc string
+	manifest   *deps.ManifestStatus
 }
 
 func (r *listResult) RenderJSON() any {
-	return tui.NewListResult(r.libs)
+	return struct {
+		tui.ListResult[DisplayLibrary]
+		Manifest *deps.ManifestStatus `json:"manifest,omitempty"`
+	}{tui.NewListResult(r.libs), r.manifest}
 }
 
 func (r *listResult) RenderTUI(out *tui.Output) {
+	if r.manifest != nil && r.manifest.Synced {
+		age := formatAge(time.Since(r.manifest.SyncedAt))
+		if r.manifest.Stale {
+			out.Warningf("Release manifest is outdated: last synced %s ago (auto-sync: %s). Run 'ggo deps sync' to refresh.", age, r.manifest.Policy)
+		} else {
+			fmt.Println(tui.Muted(fmt.Sprintf("Release manifest last synced %s ago (auto-sync: %s)", age, r.manifest.Policy)))
+		}
+	}
 	if len(r.libs) == 0 {
 		if r.filterDesc != "all platforms" {
-			fmt.Printf("No libraries available for platform %s\n", r.filterDesc)
+			fmt.Printf(i18n.T("No libraries available for platform %s\n"), r.filterDesc)
 		} else {
-			fmt.Println("No libraries available")
+			fmt.Println(i18n.T("No libraries available"))
 		}
 		return
 	}
@@ -387,7 +428,7 @@ func (r *listResult) RenderTUI(out *tui.Output) {
 		}
 
 		// Show "N/A" for size when file doesn't exist and size is 0
-		sizeStr := formatSize(dLib.Size)
+		sizeStr := cmdutil.FormatBytes(dLib.Size)
 		if !dLib.FileExists && dLib.Size == 0 {
 			sizeStr = "N/A"
 		}
@@ -405,6 +446,20 @@ func (r *listResult) RenderTUI(out *tui.Output) {
 	out.PrintTable([]string{"Name", "Version", "Type", "Platform", "Size", "Status"}, rows)
 }
 
+// formatAge renders a duration in its largest whole unit, e.g. 3d or 5h
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return "<1m"
+	}
+}
+
 func compareStrings(a, b string) int {
 	if a < b {
 		return -1
@@ -415,19 +470,6 @@ func compareStrings(a, b string) int {
 	return 0
 }
 
-func formatSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
-
 func newDownloadCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "download [library...]",
@@ -459,21 +501,25 @@ Downloads all libraries in deps-manifest that aren't already downloaded or have
 
 			// Download all required libraries
 			if !out.IsJSON() {
-				fmt.Println("Downloading dependencies...")
+				fmt.Println(i18n.T("Downloading dependencies..."))
 			}
 
 			progressFn := func(lib deps.Library, downloaded, total int64) {
+				progress.Update(progress.StepLibraries, lib.Name, downloaded, total, progress.UnitBytes)
 				if !out.IsJSON() && total > 0 {
 					pct := float64(downloaded) / float64(total) * 100
-					fmt.Printf("\r  %s: %.1f%% (%d/%d bytes)", lib.Name, pct, downloaded, total)
+					fmt.Printf(i18n.T("\r  %s: %.1f%% (%d/%d bytes)"), lib.Name, pct, downloaded, total)
 				}
 			}
 
+			progress.StepStarted(progress.StepLibraries, "Downloading dependencies")
 			results, err := mgr.DownloadAllRequired(ctx, progressFn)
 			if err != nil {
 				cmd.SilenceUsage = true
-				return err
+				progress.Failed(progress.StepLibraries, err)
+				return cmdutil.DependencyError(err)
 			}
+			progress.StepFinished(progress.StepLibraries, fmt.Sprintf("%d library(ies) processed", len(results)))
 
 			return out.Render(&downloadResult{results: results})
 		},
@@ -539,7 +585,7 @@ func (r *downloadResult) RenderTUI(out *tui.Output) {
 			res.Library.Name,
 			res.Library.Version,
 			typeStr,
-			formatSize(res.Library.Size),
+			cmdutil.FormatBytes(res.Library.Size),
 			style.Render(statusStr),
 		})
 	}
@@ -564,7 +610,7 @@ func (r *downloadResult) RenderTUI(out *tui.Output) {
 	}
 
 	if len(summary) > 0 {
-		out.Println(fmt.Sprintf("Download complete: %s", strings.Join(summary, ", ")))
+		out.Printf("Download complete: %s\n", strings.Join(summary, ", "))
 	}
 }
 
@@ -572,7 +618,10 @@ func newInstallCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "install [library...]",
 		Short: "Download and install dependencies",
-		Long:  `Download and install vGPU library dependencies to the system.`,
+		Long: `Download and install vGPU library dependencies to the system.
+
+With --locked, exactly the libraries recorded in ./ggo.lock (see 'ggo deps lock')
+are installed, and any library or version missing from the lock is refused.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mgr := getManager()
 			out := getOutput()
@@ -586,6 +635,13 @@ func newInstallCmd() *cobra.Command {
 			}
 
 			libs := mgr.GetLibrariesForPlatform(manifest, "", "", "")
+			if locked {
+				libs, err = lockedInstallLibraries(libs, args)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
 			if len(libs) == 0 {
 				return out.Render(&cmdutil.ActionData{
 					Success: false,
@@ -609,35 +665,40 @@ func newInstallCmd() *cobra.Command {
 			installedLibs := []deps.Library{}
 			for _, lib := range libs {
 				if !out.IsJSON() {
-					fmt.Printf("Installing %s (version: %s)...\n", lib.Name, lib.Version)
+					fmt.Printf(i18n.T("Installing %s (version: %s)...\n"), lib.Name, lib.Version)
 				}
 
 				progressFn := func(downloaded, total int64) {
+					progress.Update(progress.StepLibraries, lib.Name, downloaded, total, progress.UnitBytes)
 					if !out.IsJSON() && total > 0 {
 						pct := float64(downloaded) / float64(total) * 100
-						fmt.Printf("\r  Downloading: %.1f%%", pct)
+						fmt.Printf(i18n.T("\r  Downloading: %.1f%%"), pct)
 					}
 				}
 
+				progress.StepStarted(progress.StepLibraries, "Installing "+lib.Name)
 				if err := mgr.DownloadLibrary(ctx, lib, progressFn); err != nil {
 					cmd.SilenceUsage = true
 					klog.Errorf("Failed to download: library=%s error=%v", lib.Name, err)
-					return err
+					progress.Failed(progress.StepLibraries, err)
+					return cmdutil.DependencyError(err)
 				}
 				if !out.IsJSON() {
 					fmt.Println()
 				}
 
 				if !out.IsJSON() {
-					fmt.Printf("  Installing to %s...\n", mgr.GetLibraryPath(lib.Name))
+					fmt.Printf(i18n.T("  Installing to %s...\n"), mgr.GetLibraryPath(lib.Name))
 				}
 				if err := mgr.InstallLibrary(lib); err != nil {
 					cmd.SilenceUsage = true
 					klog.Errorf("Failed to install: library=%s error=%v", lib.Name, err)
+					progress.Failed(progress.StepLibraries, err)
 					return err
 				}
+				progress.StepFinished(progress.StepLibraries, "Installed "+lib.Name)
 				if !out.IsJSON() {
-					fmt.Println("  Done!")
+					fmt.Println(i18n.T("  Done!"))
 				}
 				installedLibs = append(installedLibs, lib)
 			}
@@ -647,9 +708,45 @@ func newInstallCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force reinstall even if already installed")
+	cmd.Flags().BoolVar(&locked, "locked", false, "Install only the library versions recorded in ./"+deps.LockfileName)
 	return cmd
 }
 
+// lockedInstallLibraries replaces the released libraries with the entries of
+// the project lockfile for this platform. Libraries named in args must be
+// locked, and locked libraries must still match their release.
+func lockedInstallLibraries(released []deps.Library, args []string) ([]deps.Library, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	lock, path, err := deps.FindLockfile(wd)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		return nil, fmt.Errorf("--locked requires a %s in the current directory; run 'ggo deps lock' first", deps.LockfileName)
+	}
+	klog.Infof("Installing from lockfile: path=%s", path)
+
+	libs := lock.ForPlatform(runtime.GOOS, runtime.GOARCH)
+	for _, name := range args {
+		if !slices.ContainsFunc(libs, func(lib deps.Library) bool { return lib.Name == name }) {
+			return nil, fmt.Errorf("%s is not in %s for %s/%s", name, deps.LockfileName, runtime.GOOS, runtime.GOARCH)
+		}
+	}
+	for _, rel := range released {
+		if !slices.ContainsFunc(libs, func(lib deps.Library) bool { return lib.Key() == rel.Key() && lib.Version == rel.Version }) {
+			continue
+		}
+		// A republished artifact must not silently replace the locked one
+		if err := lock.Check(rel); err != nil {
+			return nil, err
+		}
+	}
+	return libs, nil
+}
+
 // installResult implements Renderable for install command
 type installResult struct {
 	libs []deps.Library
@@ -669,14 +766,32 @@ func newUpdateCmd() *cobra.Command {
 		Use:   "update",
 		Short: "Check for and install updates",
 		Long: `Sync releases from API, update deps manifest, and optionally download updates.
-Use -y flag to automatically download without confirmation.`,
+Use -y flag to automatically download without confirmation.
+Use --channel to switch this machine to another release channel (stable, beta, nightly).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mgr := getManager()
 			out := getOutput()
 			ctx := context.Background()
 
+			// Switching channel is persisted: otherwise the next automatic
+			// deps refresh would silently move the machine back to its old channel
+			if channel != "" {
+				parsed, err := deps.ParseChannel(channel)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return cmdutil.UsageError(err)
+				}
+				if err := mgr.SetChannel(parsed); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				if !out.IsJSON() {
+					fmt.Printf(i18n.T("Release channel set to %s\n"), parsed)
+				}
+			}
+
 			if !out.IsJSON() {
-				fmt.Println("Syncing releases and checking for updates...")
+				fmt.Println(i18n.T("Syncing releases and checking for updates..."))
 			}
 
 			// Update deps manifest (syncs releases internally)
@@ -703,7 +818,7 @@ Use -y flag to automatically download without confirmation.`,
 
 			// Show what needs to be downloaded
 			if !out.IsJSON() {
-				fmt.Printf("\nFound %d dependencies to update:\n\n", len(diff.ToDownload))
+				fmt.Printf(i18n.T("\nFound %d dependencies to update:\n\n"), len(diff.ToDownload))
 
 				styles := tui.DefaultStyles()
 				var rows [][]string
@@ -717,8 +832,8 @@ Use -y flag to automatically download without confirmation.`,
 						lib.Version,
 						typeStr,
 						fmt.Sprintf("%s/%s", lib.Platform, lib.Arch),
-						formatSize(lib.Size),
-						styles.Warning.Render("Pending"),
+						cmdutil.FormatBytes(lib.Size),
+						styles.Warning.Render(i18n.T("Pending")),
 					})
 				}
 				out.PrintTable([]string{"Name", "Version", "Type", "Platform", "Size", "Status"}, rows)
@@ -727,7 +842,7 @@ Use -y flag to automatically download without confirmation.`,
 
 			// If not auto-confirm, prompt user
 			if !autoConfirm && !out.IsJSON() {
-				fmt.Print("Do you want to download these updates? [y/N]: ")
+				fmt.Print(i18n.T("Do you want to download these updates? [y/N]: "))
 				var response string
 				_, _ = fmt.Scanln(&response)
 				response = strings.ToLower(strings.TrimSpace(response))
@@ -739,21 +854,25 @@ Use -y flag to automatically download without confirmation.`,
 
 			// Download updates
 			if !out.IsJSON() {
-				fmt.Println("\nDownloading updates...")
+				fmt.Println(i18n.T("\nDownloading updates..."))
 			}
 
 			progressFn := func(lib deps.Library, downloaded, total int64) {
+				progress.Update(progress.StepLibraries, lib.Name, downloaded, total, progress.UnitBytes)
 				if !out.IsJSON() && total > 0 {
 					pct := float64(downloaded) / float64(total) * 100
 					fmt.Printf("\r  %s: %.1f%%", lib.Name, pct)
 				}
 			}
 
+			progress.StepStarted(progress.StepLibraries, "Downloading updates")
 			results, err := mgr.DownloadAllRequired(ctx, progressFn)
 			if err != nil {
 				cmd.SilenceUsage = true
-				return err
+				progress.Failed(progress.StepLibraries, err)
+				return cmdutil.DependencyError(err)
 			}
+			progress.StepFinished(progress.StepLibraries, fmt.Sprintf("%d library(ies) processed", len(results)))
 
 			return out.Render(&updateResult{
 				deps:    newDeps,
@@ -764,6 +883,7 @@ Use -y flag to automatically download without confirmation.`,
 	}
 
 	cmd.Flags().BoolVarP(&autoConfirm, "yes", "y", false, "Automatically confirm and download updates")
+	cmd.Flags().StringVar(&channel, "channel", "", "Switch to release channel (stable, beta, nightly)")
 	return cmd
 }
 
@@ -820,9 +940,9 @@ func (r *updateResult) RenderTUI(out *tui.Output) {
 	fmt.Println()
 
 	if failedCount > 0 {
-		out.Warning(fmt.Sprintf("%d updates failed", failedCount))
+		out.Warningf("%d updates failed", failedCount)
 	} else {
-		out.Success(fmt.Sprintf("All %d updates installed!", successCount))
+		out.Successf("All %d updates installed!", successCount)
 	}
 }
 
@@ -830,12 +950,17 @@ func newCleanCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "clean",
 		Short: "Clean the dependency cache",
+		Long: `Remove this user's downloaded dependencies.
+
+Artifacts in the host's shared cache (GGO_SHARED_CACHE_DIR, or
+/var/cache/gpu-go on Linux) are kept for other users; only this user's links
+to them are removed.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mgr := getManager()
 			out := getOutput()
 
 			if !out.IsJSON() {
-				fmt.Println("Cleaning dependency cache...")
+				fmt.Println(i18n.T("Cleaning dependency cache..."))
 			}
 			if err := mgr.CleanCache(); err != nil {
 				cmd.SilenceUsage = true
@@ -851,3 +976,534 @@ func newCleanCmd() *cobra.Command {
 	}
 	return cmd
 }
+
+func newChannelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "channel [stable|beta|nightly]",
+		Short: "Show or set the release channel for this machine",
+		Long: `Show or set the release channel used when selecting dependency versions.
+
+  stable   - only stable releases (default)
+  beta     - stable and beta releases
+  nightly  - all releases, including nightly builds
+
+Pinned library types (see 'ggo deps pin') ignore the channel.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := getManager()
+			out := getOutput()
+
+			if len(args) == 1 {
+				channel, err := deps.ParseChannel(args[0])
+				if err != nil {
+					cmd.SilenceUsage = true
+					return cmdutil.UsageError(err)
+				}
+				if err := mgr.SetChannel(channel); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				return out.Render(&cmdutil.ActionData{
+					Success: true,
+					Message: "Release channel set to %s. Run 'ggo deps update' to apply.",
+					Args:    []any{channel},
+				})
+			}
+
+			settings, err := mgr.LoadSettings()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			return out.Render(&settingsResult{settings: settings, sync: mgr.SyncPolicy()})
+		},
+	}
+}
+
+func newAutoSyncCmd() *cobra.Command {
+	var background bool
+	cmd := &cobra.Command{
+		Use:   "auto-sync [interval|never|on-demand]",
+		Short: "Show or set when the release manifest syncs on its own",
+		Long: `Show or set when commands that need dependencies sync the release manifest.
+
+  <interval>  - sync when the manifest is older than this, e.g. 24h or 7d (default: 7d)
+  on-demand   - sync only when the manifest is missing or lacks the platform needed
+  never       - never sync on its own; run 'ggo deps sync' or 'ggo deps update'
+
+With --background, an outdated manifest is used as is and synced in the
+background after the command completes, instead of delaying the command.
+
+GGO_DEPS_AUTO_SYNC and GGO_DEPS_BACKGROUND_SYNC=1|0 override the settings.
+
+Examples:
+  ggo deps auto-sync 1d
+  ggo deps auto-sync 7d --background
+  ggo deps auto-sync --background=false
+  ggo deps auto-sync never`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := getManager()
+			out := getOutput()
+
+			if len(args) == 0 && !cmd.Flags().Changed("background") {
+				settings, err := mgr.LoadSettings()
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				return out.Render(&settingsResult{settings: settings, sync: mgr.SyncPolicy()})
+			}
+
+			var policy string
+			if len(args) == 1 {
+				var err error
+				if policy, err = deps.ParseAutoSync(args[0]); err != nil {
+					cmd.SilenceUsage = true
+					return cmdutil.UsageError(err)
+				}
+			}
+			var bg *bool
+			if cmd.Flags().Changed("background") {
+				bg = &background
+			}
+			if err := mgr.SetAutoSync(policy, bg); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to save auto-sync settings: policy=%s error=%v", policy, err)
+				return err
+			}
+			settings, err := mgr.LoadSettings()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			policy = settings.AutoSync
+			if policy == "" {
+				policy = deps.DefaultAutoSync
+			}
+			if settings.BackgroundSync {
+				return out.Render(&cmdutil.ActionData{
+					Success: true,
+					Message: "Release manifest auto-sync set to %s; outdated manifests sync in the background after commands.",
+					Args:    []any{policy},
+				})
+			}
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Release manifest auto-sync set to %s.",
+				Args:    []any{policy},
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&background, "background", false, "Sync an outdated manifest in the background after the command instead of before it")
+	return cmd
+}
+
+func newPinCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pin <type> <version>",
+		Short: "Pin a library type to a specific version",
+		Long: `Pin a library type to a specific version. Pins survive deps manifest
+refreshes and take precedence over the release channel.
+
+Examples:
+  ggo deps pin vgpu-library 1.42.0`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := getManager()
+			out := getOutput()
+
+			libType, err := deps.ParseLibraryType(args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				return cmdutil.UsageError(err)
+			}
+			version := strings.TrimSpace(args[1])
+			if err := mgr.Pin(libType, version); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			// A pin to an unknown version is kept (the release may not be
+			// synced yet) but falls back to the channel until it shows up
+			found, cached, err := mgr.HasRelease(libType, version)
+			if err != nil {
+				klog.Warningf("Failed to check pinned version against release manifest: error=%v", err)
+			} else if cached && !found {
+				out.Warningf("%s %s is not in the cached release manifest; the channel version is used until it is released. Run 'ggo deps sync' to refresh.", libType, version)
+			}
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Pinned %s to %s. Run 'ggo deps update' to apply.",
+				Args:    []any{libType, version},
+			})
+		},
+	}
+}
+
+func newUnpinCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unpin <type>",
+		Short: "Remove a version pin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := getManager()
+			out := getOutput()
+
+			removed, err := mgr.Unpin(args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if !removed {
+				return out.Render(&cmdutil.ActionData{
+					Success: false,
+					Message: "%s is not pinned",
+					Args:    []any{args[0]},
+				})
+			}
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Unpinned %s",
+				Args:    []any{args[0]},
+			})
+		},
+	}
+}
+
+// stepMirror is the progress event step of deps mirror
+const stepMirror = "mirror"
+
+func newMirrorCmd() *cobra.Command {
+	var target, baseURL string
+
+	cmd := &cobra.Command{
+		Use:   "mirror --target <s3://bucket/prefix|dir|https://root>",
+		Short: "Mirror all dependency artifacts to a self-hosted location",
+		Long: `Download every released artifact (all platforms) and GPU tool binary,
+verify their checksums, and publish them to a self-hosted location with the
+CDN's path layout. A releases-manifest.json with URLs rewritten to the mirror
+is written at the mirror root.
+
+Targets:
+  s3://bucket[/prefix]   uploaded with the aws CLI
+  https://host/root      uploaded with HTTP PUT (WebDAV, artifact repositories)
+  /path/to/dir           written locally, for any static web server
+
+Point client machines at the mirror with 'ggo deps mirror use <base-url>'.
+
+Examples:
+  # Mirror to S3, served through an internal endpoint
+  ggo deps mirror --target s3://gpu-artifacts/ggo --base-url https://artifacts.corp.example.com/ggo
+
+  # Mirror to a directory served by nginx
+  ggo deps mirror --target /srv/www/ggo --base-url https://mirror.corp.example.com/ggo
+
+  # On client machines
+  ggo deps mirror use https://mirror.corp.example.com/ggo`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			if target == "" {
+				return cmdutil.UsageErrorf("--target is required")
+			}
+			mirrorTarget, err := deps.ParseMirrorTarget(target)
+			if err != nil {
+				return cmdutil.UsageError(err)
+			}
+
+			progressFn := func(artifact string, done, total int) {
+				progress.Update(stepMirror, artifact, int64(done), int64(total), progress.UnitArtifacts)
+				if out.IsJSON() {
+					return
+				}
+				if artifact == "" {
+					fmt.Printf(i18n.T("\r\033[K  [%d/%d] done\n"), done, total)
+					return
+				}
+				fmt.Printf("\r\033[K  [%d/%d] %s", done+1, total, artifact)
+			}
+
+			progress.StepStarted(stepMirror, "Mirroring dependencies to "+target)
+			result, err := getManager().Mirror(context.Background(), mirrorTarget, baseURL, progressFn)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to mirror dependencies: target=%s error=%v", target, err)
+				progress.Failed(stepMirror, err)
+				return err
+			}
+			progress.StepFinished(stepMirror, "Mirror complete")
+			return out.Render(&mirrorResult{result: result})
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "target", "", "Mirror destination: s3://bucket[/prefix], an http(s) root, or a directory")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "URL clients reach the mirror root at (default: derived from an S3 or HTTP target)")
+
+	cmd.AddCommand(newMirrorUseCmd())
+	return cmd
+}
+
+func newMirrorUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <base-url|none>",
+		Short: "Download dependencies from a mirror on this machine",
+		Long: `Download dependencies from a self-hosted mirror instead of the public CDN.
+Checksums from the release manifest are still verified, and redirects back to
+the public CDN are refused. Use "none" to go back to the CDN.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			url := args[0]
+			if url == "none" {
+				url = ""
+			}
+			if err := getManager().SetMirror(url); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			message := "Mirror cleared, downloading from the CDN"
+			if url != "" {
+				message = fmt.Sprintf("Downloading dependencies from mirror %s", strings.TrimSuffix(url, "/"))
+			}
+			return out.Render(&cmdutil.ActionData{Success: true, Message: message})
+		},
+	}
+}
+
+// mirrorResult implements Renderable for the mirror command
+type mirrorResult struct {
+	result *deps.MirrorResult
+}
+
+func (r *mirrorResult) RenderJSON() any {
+	return r.result
+}
+
+func (r *mirrorResult) RenderTUI(out *tui.Output) {
+	out.Successf("Mirrored %d artifacts (%s) to %s", r.result.Artifacts, cmdutil.FormatBytes(r.result.Bytes), r.result.Target)
+	out.Println(tui.NewStatusTable().
+		Add("Base URL", r.result.BaseURL).
+		Add("Manifest", r.result.Manifest).
+		Add("Libraries", fmt.Sprintf("%d", len(r.result.Libraries))).
+		String())
+	out.Printf("\nOn client machines run: ggo deps mirror use %s\n", r.result.BaseURL)
+}
+
+// settingsResult implements Renderable for the channel command
+type settingsResult struct {
+	settings *deps.Settings
+	// sync is the effective auto-sync policy, which the environment may
+	// override
+	sync deps.SyncPolicy
+}
+
+func (r *settingsResult) RenderJSON() any {
+	return struct {
+		*deps.Settings
+		Effective deps.SyncPolicy `json:"effective_sync"`
+	}{r.settings, r.sync}
+}
+
+func (r *settingsResult) RenderTUI(out *tui.Output) {
+	status := tui.NewStatusTable().Add("Channel", r.settings.Channel)
+	if r.settings.Mirror != "" {
+		status.Add("Mirror", r.settings.Mirror)
+	}
+	status.Add("Auto-sync", r.sync.Policy)
+	if r.sync.Background {
+		status.Add("Background sync", "on")
+	} else {
+		status.Add("Background sync", "off")
+	}
+	types := make([]string, 0, len(r.settings.Pins))
+	for t := range r.settings.Pins {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		status.Add("Pin "+t, r.settings.Pins[t])
+	}
+	out.Println(status.String())
+}
+
+func newLockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Write a ggo.lock pinning the libraries in use",
+		Long: `Write a ggo.lock recording the exact names, versions and hashes of the
+libraries downloaded on this machine. Commit it to the project so the whole team
+runs against the same client libraries:
+
+  ggo use and ggo studio create pick up ./ggo.lock automatically
+  ggo deps install --locked installs exactly the locked libraries
+
+Examples:
+  ggo deps lock
+  ggo deps lock -f ../ggo.lock`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := getManager()
+			out := getOutput()
+
+			lock, err := mgr.Lock()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if err := lock.Save(lockOutput); err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to write %s: %w", lockOutput, err)
+			}
+			return out.Render(&lockResult{lock: lock, path: lockOutput})
+		},
+	}
+	cmd.Flags().StringVarP(&lockOutput, "file", "f", deps.LockfileName, "Lockfile to write")
+	return cmd
+}
+
+// lockResult implements Renderable for the lock command
+type lockResult struct {
+	lock *deps.Lockfile
+	path string
+}
+
+func (r *lockResult) RenderJSON() any {
+	return r.lock
+}
+
+func (r *lockResult) RenderTUI(out *tui.Output) {
+	out.Successf("Locked %d libraries in %s", len(r.lock.Libraries), r.path)
+	var rows [][]string
+	for _, lib := range r.lock.Libraries {
+		rows = append(rows, []string{lib.Name, lib.Version, lib.Type, fmt.Sprintf("%s/%s", lib.Platform, lib.Arch)})
+	}
+	out.PrintTable([]string{"Name", "Version", "Type", "Platform"}, rows)
+}
+
+func newWhichCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "which",
+		Short: "Show which library file a type and vendor resolve to",
+		Long: `Show which library files a library type and vendor resolve to, and why:
+the selected version, whether ./ggo.lock, a pin or the release channel chose it,
+the cached file and its hash check, and the matching entries of the release,
+deps and downloaded manifests. Nothing is downloaded.
+
+Without --os and --arch the libraries used by 'ggo use' and the agent on this
+machine are resolved; with them, those downloaded for studio environments.
+
+Examples:
+  ggo deps which --type vgpu-library --vendor nvidia
+  ggo deps which --type remote-gpu-client --os linux --arch arm64 -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			libType, err := deps.ParseLibraryType(whichType)
+			if err != nil {
+				return cmdutil.UsageError(err)
+			}
+			if whichArch != "" && whichOS == "" {
+				whichOS = runtime.GOOS
+			}
+
+			lock, lockPath, err := cmdutil.ProjectLockfile()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			res, err := getManager(deps.WithLockfile(lock)).Which(libType, whichVendor, whichOS, whichArch)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to resolve library: type=%s vendor=%s error=%v", libType, whichVendor, err)
+				return err
+			}
+			if res.Source == deps.SelectedByLockfile {
+				res.Lockfile = lockPath
+			}
+			return out.Render(&whichResult{res: res})
+		},
+	}
+	cmd.Flags().StringVar(&whichType, "type", "", "Library type (vgpu-library, remote-gpu-worker, remote-gpu-client)")
+	cmd.Flags().StringVar(&whichVendor, "vendor", "", "Vendor slug (e.g., nvidia, amd). Omit to match every vendor")
+	cmd.Flags().StringVar(&whichOS, "os", "", "Target OS (linux, darwin, windows). Defaults to current OS")
+	cmd.Flags().StringVar(&whichArch, "arch", "", "Target architecture (amd64, arm64). Defaults to current architecture")
+	_ = cmd.MarkFlagRequired("type")
+	return cmd
+}
+
+// whichResult implements Renderable for the which command
+type whichResult struct {
+	res *deps.Resolution
+}
+
+func (r *whichResult) RenderJSON() any {
+	return r.res
+}
+
+func (r *whichResult) RenderTUI(out *tui.Output) {
+	res := r.res
+	var source string
+	switch res.Source {
+	case deps.SelectedByLockfile:
+		source = i18n.Tf("locked in %s", res.Lockfile)
+	case deps.SelectedByPin:
+		source = i18n.Tf("pinned in %s", deps.DepsSettingsFile)
+	default:
+		source = i18n.Tf("latest release on the %s channel", res.Channel)
+	}
+	vendor := res.Vendor
+	if vendor == "" {
+		vendor = i18n.T("any")
+	}
+	out.Println(tui.NewStatusTable().
+		Add("Type", res.Type).
+		Add("Vendor", vendor).
+		Add("Platform", fmt.Sprintf("%s/%s", res.Platform, res.Arch)).
+		Add("Version", res.Version).
+		Add("Selected by", source).
+		Add("Manifests", res.ManifestDir).
+		String())
+	if res.Pin != "" && res.Source == deps.SelectedByChannel {
+		out.Warningf("Pinned version %s is not released; using the %s channel instead", res.Pin, res.Channel)
+	}
+
+	for _, lib := range res.Libraries {
+		out.Println("")
+		hash, status := hashStatus(lib)
+		out.Println(tui.NewStatusTable().
+			Add("Library", lib.Name).
+			Add("Path", lib.Path).
+			Add("SHA256", cmdutil.ValueOrDash(lib.SHA256)).
+			AddWithStatus("Hash check", hash, status).
+			String())
+
+		var rows [][]string
+		for _, e := range lib.Entries {
+			match := i18n.T("yes")
+			if !e.Matches {
+				match = i18n.T("no")
+			}
+			hash := "-"
+			if e.SHA256 != "" {
+				hash = deps.ShortHash(e.SHA256)
+			}
+			rows = append(rows, []string{e.Manifest, e.Version, cmdutil.ValueOrDash(e.Channel), hash, match})
+		}
+		out.Println(tui.NewTable().Headers("MANIFEST", "VERSION", "CHANNEL", "SHA256", "MATCHES").Rows(rows).String())
+	}
+}
+
+// hashStatus describes the hash check of lib and returns the status that
+// styles it
+func hashStatus(lib deps.ResolvedLibrary) (string, string) {
+	switch lib.Hash {
+	case deps.HashVerified:
+		return i18n.T("verified"), "active"
+	case deps.HashMismatch:
+		return i18n.Tf("mismatch (file has %s)", deps.ShortHash(lib.ActualHash)), "error"
+	case deps.HashMissing:
+		return i18n.T("not downloaded"), "pending"
+	default:
+		return i18n.T("no published hash"), "unknown"
+	}
+}
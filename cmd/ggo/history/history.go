@@ -0,0 +1,292 @@
+// Package history implements the ggo history command for the local history
+// of executed ggo commands
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/version"
+	"github.com/NexusGPU/gpu-go/internal/history"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+var outputFormat string
+
+// NewHistoryCmd creates the history command
+func NewHistoryCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List, replay and export executed ggo commands",
+		Long: `List the ggo commands run on this machine, newest last.
+
+Every ggo command is recorded in ` + "`~/.gpugo/state/history.jsonl`" + ` with its
+arguments, the flag values it resolved (including defaults from the config
+files), working directory, duration and outcome, whichever shell it was run
+from. Credentials such as --token are redacted. The oldest entries are
+dropped once the file reaches 1 MB; set GGO_NO_HISTORY=1 to stop recording.
+
+Examples:
+  # The last 20 commands
+  ggo history
+
+  # Rerun command number 42
+  ggo history replay 42
+
+  # Attach the history of the last day to a support ticket
+  ggo history export --since 1d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := getLog().List()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to read command history: error=%v", err)
+				return err
+			}
+			first := 0
+			if limit > 0 && len(entries) > limit {
+				first = len(entries) - limit
+			}
+			return getOutput().Render(&historyListResult{entries: entries[first:], first: first + 1})
+		},
+	}
+
+	cmdutil.AddOutputFlag(cmd, &outputFormat)
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Number of most recent commands to list (0 lists all)")
+	cmd.AddCommand(newReplayCmd())
+	cmd.AddCommand(newExportCmd())
+
+	return cmd
+}
+
+func getOutput() *tui.Output {
+	return cmdutil.NewOutput(outputFormat)
+}
+
+func getLog() *history.Log {
+	return history.NewLog(platform.DefaultPaths().HistoryFile())
+}
+
+// numberedEntry is a history entry with the number replay takes
+type numberedEntry struct {
+	Number int `json:"number"`
+	history.Entry
+}
+
+// historyListResult implements Renderable for history
+type historyListResult struct {
+	entries []history.Entry
+	first   int
+}
+
+func (r *historyListResult) RenderJSON() any {
+	items := make([]numberedEntry, 0, len(r.entries))
+	for i, e := range r.entries {
+		items = append(items, numberedEntry{Number: r.first + i, Entry: e})
+	}
+	return tui.NewListResult(items)
+}
+
+func (r *historyListResult) RenderTUI(out *tui.Output) {
+	if len(r.entries) == 0 {
+		out.Info("No commands recorded yet")
+		return
+	}
+
+	styles := tui.DefaultStyles()
+	var rows [][]string
+	for i, e := range r.entries {
+		result := styles.Success.Render(e.Result)
+		if e.Result != history.ResultSuccess {
+			result = styles.Error.Render(e.Result)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(r.first + i),
+			e.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			"ggo " + strings.Join(e.Argv, " "),
+			(time.Duration(e.DurationMs) * time.Millisecond).String(),
+			result,
+		})
+	}
+
+	table := tui.NewTable().
+		Headers("#", "TIME", "COMMAND", "DURATION", "RESULT").
+		Rows(rows)
+
+	out.Println(table.String())
+}
+
+func newReplayCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "replay <number>",
+		Short: "Run a recorded command again",
+		Long: `Run the command with the given number in 'ggo history' again, with the
+same arguments, in the current directory. ggo runs it directly rather than
+through a shell, so a replay behaves the same in PowerShell, cmd and bash.
+
+Commands whose credentials were redacted cannot be replayed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := strconv.Atoi(args[0])
+			if err != nil || number < 1 {
+				return cmdutil.UsageErrorf("invalid history number %q", args[0])
+			}
+			entries, err := getLog().List()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to read command history: error=%v", err)
+				return err
+			}
+			if number > len(entries) {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("no command number %d in the history (%d recorded)", number, len(entries))
+			}
+			entry := entries[number-1]
+			cmd.SilenceUsage = true
+			if !entry.Replayable() {
+				return fmt.Errorf("command %d has redacted credentials and cannot be replayed; run it again by hand", number)
+			}
+
+			commandLine := "ggo " + strings.Join(entry.Argv, " ")
+			if !yes && !getOutput().IsJSON() {
+				confirmed, err := tui.ConfirmPrompt(fmt.Sprintf("Run '%s' again?", commandLine))
+				if err != nil {
+					return fmt.Errorf("failed to confirm: %w", err)
+				}
+				if !confirmed {
+					getOutput().Info("Cancelled")
+					return nil
+				}
+			}
+
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to locate ggo: %w", err)
+			}
+			klog.V(2).Infof("Replaying command: number=%d command=%q", number, commandLine)
+			child := exec.CommandContext(cmd.Context(), exe, entry.Argv...)
+			child.Stdin, child.Stdout, child.Stderr = os.Stdin, os.Stdout, os.Stderr
+			if err := child.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					return &replayExitError{code: exitErr.ExitCode()}
+				}
+				return fmt.Errorf("failed to replay command: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+
+	return cmd
+}
+
+// replayExitError passes a replayed command's exit status through to ggo's
+type replayExitError struct {
+	code int
+}
+
+func (e *replayExitError) Error() string {
+	return fmt.Sprintf("replayed command exited with status %d", e.code)
+}
+
+// ExitCode returns the replayed command's exit status for the process to
+// exit with
+func (e *replayExitError) ExitCode() int {
+	return e.code
+}
+
+// historyExport is the document ggo history export writes
+type historyExport struct {
+	GGOVersion string          `json:"ggo_version"`
+	OS         string          `json:"os"`
+	Arch       string          `json:"arch"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Entries    []history.Entry `json:"entries"`
+}
+
+func newExportCmd() *cobra.Command {
+	var file string
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the command history for a support ticket",
+		Long: `Write the command history, with the ggo version and platform, to a JSON
+file to attach to a support ticket. Credentials are already redacted when
+commands are recorded.
+
+Examples:
+  # Everything recorded
+  ggo history export
+
+  # The last day, to a file of your choice
+  ggo history export --since 1d -f ticket-1234.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var from time.Time
+			if since != "" {
+				d, err := cmdutil.ParseAge(since)
+				if err != nil {
+					return cmdutil.UsageErrorf("invalid --since %q: %w", since, err)
+				}
+				from = time.Now().Add(-d)
+			}
+
+			entries, err := getLog().List()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to read command history: error=%v", err)
+				return err
+			}
+			export := historyExport{
+				GGOVersion: version.Version,
+				OS:         runtime.GOOS,
+				Arch:       runtime.GOARCH,
+				ExportedAt: time.Now().UTC(),
+				Entries:    []history.Entry{},
+			}
+			for _, e := range entries {
+				if from.IsZero() || !e.Timestamp.Before(from) {
+					export.Entries = append(export.Entries, e)
+				}
+			}
+
+			data, err := json.MarshalIndent(export, "", "  ")
+			if err == nil {
+				err = os.WriteFile(file, append(data, '\n'), 0600)
+			}
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to export command history: file=%s error=%v", file, err)
+				return err
+			}
+			return getOutput().Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Exported %d commands to %s",
+				Args:    []any{len(export.Entries), file},
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "ggo-history.json", "File to write the history to")
+	cmd.Flags().StringVar(&since, "since", "", "Only export commands newer than this (e.g. 7d, 12h)")
+
+	return cmd
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"syscall"
 	"unsafe"
@@ -75,7 +76,7 @@ Examples:
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if shareLink == "" {
-				return fmt.Errorf("share link is required, use -s <share-link>")
+				return cmdutil.UsageErrorf("share link is required, use -s <share-link>")
 			}
 			return runLaunch(args, shareLink, serverURL, verbose)
 		},
@@ -89,22 +90,6 @@ Examples:
 	return cmd
 }
 
-// extractShortCode extracts the short code from a short link URL or returns the input as-is if it's already a code.
-// Supports formats: "abc123", "https://gpu.tf/s/abc123", "gpu.tf/s/abc123"
-func extractShortCode(input string) string {
-	input = strings.TrimSpace(input)
-
-	// If it looks like a URL, extract the last path segment
-	if strings.Contains(input, "/") {
-		parts := strings.Split(strings.TrimSuffix(input, "/"), "/")
-		if len(parts) > 0 {
-			return parts[len(parts)-1]
-		}
-	}
-
-	return input
-}
-
 // ensureRemoteGPUClientLibs downloads remote-gpu-client libraries if not already present
 // vendorSlug filters by vendor (e.g., "nvidia", "amd") to avoid downloading unnecessary libraries
 func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug string, verbose bool) error {
@@ -126,7 +111,7 @@ func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug
 
 	libs, err := depsMgr.EnsureLibrariesByTypes(ctx, targetTypes, vendorSlug, progressFn)
 	if err != nil {
-		return fmt.Errorf("failed to ensure GPU client libraries: %w", err)
+		return cmdutil.DependencyError(fmt.Errorf("failed to ensure GPU client libraries: %w", err))
 	}
 
 	if verbose {
@@ -148,7 +133,7 @@ func runLaunch(args []string, shareLink, serverURL string, verbose bool) error {
 	ctx := context.Background()
 
 	// Get share info from API
-	shortCode := extractShortCode(shareLink)
+	shortCode := cmdutil.ExtractShortCode(shareLink)
 	client := api.NewClient(api.WithBaseURL(serverURL))
 	shareInfo, err := client.GetSharePublic(ctx, shortCode)
 	if err != nil {
@@ -171,7 +156,7 @@ func runLaunch(args []string, shareLink, serverURL string, verbose bool) error {
 	// Ensure required GPU client libraries exist
 	// Filter by vendor from share info to avoid downloading unnecessary libraries
 	if err := ensureRemoteGPUClientLibs(ctx, out, shareInfo.HardwareVendor, verbose); err != nil {
-		return fmt.Errorf("failed to ensure GPU client libraries: %w", err)
+		return cmdutil.DependencyError(fmt.Errorf("failed to ensure GPU client libraries: %w", err))
 	}
 
 	// Get cache directory
@@ -251,7 +236,7 @@ func runLaunch(args []string, shareLink, serverURL string, verbose bool) error {
 	// Look up the program in PATH if not an absolute path
 	execPath, err := exec.LookPath(program)
 	if err != nil {
-		return fmt.Errorf("program not found: %s", program)
+		return cmdutil.NotFoundErrorf("program not found: %s", program)
 	}
 
 	// Create the command
@@ -267,8 +252,8 @@ func runLaunch(args []string, shareLink, serverURL string, verbose bool) error {
 	env = setEnvVar(env, "TF_LOG_LEVEL", getEnvDefault("TF_LOG_LEVEL", "info"))
 	env = setEnvVar(env, "TF_ENABLE_LOG", getEnvDefault("TF_ENABLE_LOG", "1"))
 
-	// Add GPU bin directory to PATH (for nvidia-smi, etc.)
-	binDir := filepath.Join(paths.CacheDir(), "bin")
+	// Add the vendor's GPU tools directory to PATH (for nvidia-smi, xpu-smi, etc.)
+	binDir := deps.GPUBinDir(paths, shareInfo.HardwareVendor, runtime.GOOS, runtime.GOARCH)
 	existingPath := os.Getenv("PATH")
 	if existingPath != "" {
 		env = setEnvVar(env, "PATH", binDir+";"+existingPath)
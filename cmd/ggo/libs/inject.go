@@ -0,0 +1,194 @@
+package libs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	ggoversion "github.com/NexusGPU/gpu-go/cmd/ggo/version"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newInjectCmd() *cobra.Command {
+	var (
+		target    studio.InjectTarget
+		shareLink string
+		endpoint  string
+		vendor    string
+		serverURL string
+		name      string
+		restart   bool
+		anonymous bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "inject",
+		Short: "Install the GPU client libraries into an existing container",
+		Long: `Give a container that was not created by ggo remote GPU access: the GPU client
+libraries for the share's vendor and the container's architecture are copied to
+/opt/gpugo/libs, registered in /etc/ld.so.conf.d and /etc/ld.so.preload, and the
+connection settings are written to /etc/environment and /etc/profile.d.
+
+The container must be running. Processes started afterwards load the libraries;
+pass --restart so the container's main process does too. Login shells get the
+connection settings; a main process not started from one needs
+TENSOR_FUSION_OPERATOR_CONNECTION_INFO in the container's own environment.
+
+Docker containers are reached with the docker CLI, containerd containers with
+nerdctl. Running inject again replaces what an earlier run wrote, e.g. to
+switch the container to another share.`,
+		Example: `  # Inject into a Docker container and restart it
+  ggo libs inject --container my-app -s abc123 --restart
+
+  # A containerd container, e.g. in the k8s.io namespace
+  ggo libs inject --container 3f2a9c --runtime containerd --namespace k8s.io -s abc123
+
+  # Without a share link, for a worker reached directly
+  ggo libs inject --container my-app --endpoint native+10.0.0.5+9001 --vendor nvidia`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := target.Validate(); err != nil {
+				return err
+			}
+			if (shareLink == "") == (endpoint == "") {
+				return fmt.Errorf("give either a share link (-s) or --endpoint")
+			}
+			cmd.SilenceUsage = true
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			defer cancel()
+			out := getOutput()
+
+			arch, err := target.Arch(ctx)
+			if err != nil {
+				klog.Errorf("Failed to inspect container: container=%s error=%v", target.Container, err)
+				return err
+			}
+
+			connectionURL := endpoint
+			if shareLink != "" {
+				code := cmdutil.ExtractShortCode(shareLink)
+				client := api.NewClient(api.WithBaseURL(serverURL))
+				info, err := client.GetSharePublic(ctx, code)
+				if err != nil {
+					klog.Errorf("Failed to resolve share link: error=%v", err)
+					return fmt.Errorf("failed to resolve share link '%s': %w", shareLink, err)
+				}
+				cmdutil.SelectShareAddress(ctx, info)
+				connectionURL = info.ConnectionURL + "+" + code
+				if vendor == "" {
+					vendor = info.HardwareVendor
+				}
+				if !anonymous {
+					cmdutil.RegisterShareConsumer(ctx, client, code, "inject", ggoversion.Version)
+				}
+			}
+
+			_, lockPath, err := cmdutil.ProjectLockfile()
+			if err != nil {
+				return err
+			}
+			gpuVendor := studio.ParseVendor(vendor)
+			if !out.IsJSON() {
+				out.Infof("Downloading GPU client libraries for %s (linux/%s)...", gpuVendor, arch)
+			}
+			libsDir, err := studio.EnsureContainerLibraries(ctx, gpuVendor, arch, lockPath)
+			if err != nil {
+				klog.Errorf("Failed to download GPU client libraries: vendor=%s arch=%s error=%v", gpuVendor, arch, err)
+				return err
+			}
+
+			if !out.IsJSON() {
+				out.Infof("Installing into container %s...", target.Container)
+			}
+			result, err := target.Inject(ctx, &studio.InjectOptions{
+				LibsDir:       libsDir,
+				Vendor:        gpuVendor,
+				ConnectionURL: connectionURL,
+				Name:          name,
+				Tools:         gpuTools(ctx, vendor, arch),
+				Restart:       restart,
+			})
+			if err != nil {
+				klog.Errorf("Failed to inject GPU client: container=%s error=%v", target.Container, err)
+				return err
+			}
+			return out.Render(&injectResult{result: result})
+		},
+	}
+
+	cmd.Flags().StringVar(&target.Container, "container", "", "ID or name of the container")
+	cmd.Flags().StringVar(&target.Runtime, "runtime", studio.InjectRuntimeDocker, "Container runtime: docker or containerd (through nerdctl)")
+	cmd.Flags().StringVar(&target.Namespace, "namespace", studio.DefaultContainerdNamespace, "containerd namespace")
+	cmd.Flags().StringVar(&target.DockerHost, "docker-host", "", "Custom Docker socket path (e.g., unix:///path/to/docker.sock)")
+	cmd.Flags().StringVarP(&shareLink, "share-link", "s", "", "Share link or share code of the remote GPU worker")
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "GPU worker connection URL, instead of a share link")
+	cmd.Flags().StringVar(&vendor, "vendor", "", "GPU vendor (nvidia, amd, hygon, mthreads, cambricon); taken from the share by default")
+	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL for resolving share links")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the connection file the GPU client writes (default: the container)")
+	cmd.Flags().BoolVar(&restart, "restart", false, "Restart the container so its main process loads the libraries")
+	cmd.Flags().BoolVar(&anonymous, "anonymous", false, "Don't register this machine with the share owner")
+	_ = cmd.MarkFlagRequired("container")
+
+	return cmd
+}
+
+// gpuTools downloads the vendor's GPU tools, like nvidia-smi, for the
+// container; they are optional, so failures only leave them out
+func gpuTools(ctx context.Context, vendor, arch string) map[string]string {
+	if vendor == "" {
+		return nil
+	}
+	bundle, err := deps.EnsureGPUBinaryForPlatform(ctx, platform.DefaultPaths(), vendor, "linux", arch)
+	if err != nil {
+		klog.Warningf("Failed to download GPU tools, continuing without them: vendor=%s arch=%s error=%v", vendor, arch, err)
+		return nil
+	}
+	if bundle == nil {
+		return nil
+	}
+	tools := make(map[string]string, len(bundle.Tools))
+	for _, tool := range bundle.Tools {
+		tools[tool] = bundle.ToolPath(tool)
+	}
+	return tools
+}
+
+// injectResult implements Renderable for libs inject
+type injectResult struct {
+	result *studio.InjectResult
+}
+
+func (r *injectResult) RenderJSON() any {
+	return r.result
+}
+
+func (r *injectResult) RenderTUI(out *tui.Output) {
+	res := r.result
+	out.Successf("GPU client installed into container %s", res.Container)
+	out.Println()
+
+	status := tui.NewStatusTable().
+		Add("Runtime", res.Runtime).
+		Add("Libraries", fmt.Sprintf("%d in /opt/gpugo/libs", len(res.Libraries))).
+		Add("Preload", strings.Join(res.Preload, " ")).
+		Add("GPU Worker", res.EnvVars["TENSOR_FUSION_OPERATOR_CONNECTION_INFO"])
+	if len(res.Tools) > 0 {
+		status.Add("Tools", strings.Join(res.Tools, ", "))
+	}
+	out.Println(status.String())
+	out.Println()
+
+	if res.Restarted {
+		out.Info(i18n.T("The container was restarted; its processes now use the remote GPU."))
+	} else {
+		out.Info(i18n.T("New processes in the container use the remote GPU; pass --restart for its main process."))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"runtime"
 
 	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 	"github.com/NexusGPU/gpu-go/internal/studio"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/spf13/cobra"
@@ -35,12 +36,16 @@ Examples:
   ggo libs info
 
   # Clear library cache
-  ggo libs clean`,
+  ggo libs clean
+
+  # Give an existing container remote GPU access
+  ggo libs inject --container my-app -s abc123 --restart`,
 	}
 
 	cmd.AddCommand(newDownloadCmd())
 	cmd.AddCommand(newInfoCmd())
 	cmd.AddCommand(newCleanCmd())
+	cmd.AddCommand(cmdutil.Audited(newInjectCmd()))
 
 	cmdutil.AddOutputFlag(cmd, &outputFormat)
 
@@ -69,18 +74,18 @@ The libraries are cached locally and used by studio environments for remote GPU
 			arch := studio.DetectArchitecture()
 
 			if !out.IsJSON() {
-				fmt.Printf("Detected architecture: %s\n", arch.String())
-				fmt.Printf("CDN URL: %s\n", cdnURL)
-				fmt.Printf("Version: %s\n", version)
-				fmt.Printf("Cache directory: %s\n", downloader.GetCacheDir())
+				fmt.Printf(i18n.T("Detected architecture: %s\n"), arch.String())
+				fmt.Printf(i18n.T("CDN URL: %s\n"), cdnURL)
+				fmt.Printf(i18n.T("Version: %s\n"), version)
+				fmt.Printf(i18n.T("Cache directory: %s\n"), downloader.GetCacheDir())
 				fmt.Println()
-				fmt.Println("Downloading libraries...")
+				fmt.Println(i18n.T("Downloading libraries..."))
 			}
 
 			paths, err := downloader.DownloadDefaultLibraries()
 			if err != nil {
 				cmd.SilenceUsage = true
-				return fmt.Errorf("failed to download libraries: %w", err)
+				return cmdutil.DependencyError(fmt.Errorf("failed to download libraries: %w", err))
 			}
 
 			return out.Render(&downloadResult{paths: paths})
@@ -212,7 +217,7 @@ func newCleanCmd() *cobra.Command {
 			}
 
 			if !out.IsJSON() {
-				fmt.Printf("Cleaning cache directory: %s\n", downloader.GetCacheDir())
+				fmt.Printf(i18n.T("Cleaning cache directory: %s\n"), downloader.GetCacheDir())
 			}
 
 			// In production, implement actual cache cleaning
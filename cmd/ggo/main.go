@@ -1,30 +1,55 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/NexusGPU/gpu-go/cmd/ggo/agent"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/audit"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/auth"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/config"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/deps"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/history"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/launch"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/libs"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/protocol"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/quickstart"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/share"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/studio"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/system"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/use"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/version"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/worker"
+	"github.com/NexusGPU/gpu-go/internal/credentials"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/progress"
 	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	profileFlag   = "profile"
+	noKeyringFlag = "no-keyring"
 )
 
 var (
-	verbose bool
-	rootCmd = &cobra.Command{
+	verbose        bool
+	profile        string
+	noKeyring      bool
+	progressEvents bool
+)
+
+func newRootCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
 		Use:   "ggo",
 		Short: "GPU Go - Remote GPU environment management CLI",
 		Long: `GPU Go (ggo) is a command-line tool for managing remote GPU environments.
 
 It provides commands to:
+  - Get started with a guided setup ('ggo quickstart')
   - Run an agent on GPU servers to sync with the cloud platform
   - Set up temporary or long-term remote GPU environments
   - Manage workers on GPU servers
@@ -34,16 +59,32 @@ Troubleshooting & Support:
   - Troubleshooting Handbook: https://tensor-fusion.ai/docs/gpu-go/troubleshooting/handbook
   - Discord Community: https://discord.com/invite/2bybv9yQNk
   - GitHub Issues: https://github.com/NexusGPU/gpu-go/issues`,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// klog verbosity is controlled by -v flag, no need to configure here
+			if progressEvents {
+				progress.Enable(os.Stderr)
+			}
+			// The config subtree edits the defaults and must work when they are broken
+			if !isConfigSubcommand(cmd) {
+				if err := cmdutil.ApplyDefaults(cmd); err != nil {
+					return err
+				}
+			}
+			return cmdutil.ValidateFlags(cmd)
 		},
 	}
-)
+	// Subcommands set their own persistent hooks; run the root's first
+	cobra.EnableTraverseRunHooks = true
 
-func init() {
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&profile, profileFlag, "", "Configuration profile to use (or set GGO_PROFILE env var)")
+	rootCmd.PersistentFlags().BoolVar(&noKeyring, noKeyringFlag, false,
+		"Keep tokens and secrets in config files instead of the OS keyring (or set "+credentials.NoKeyringEnv+"=1)")
+	rootCmd.PersistentFlags().BoolVar(&progressEvents, "progress-events", false,
+		"Write progress of long operations to stderr as NDJSON events, for tools driving ggo (use with --output json)")
 
 	// Add subcommands
+	rootCmd.AddCommand(quickstart.NewQuickstartCmd())
 	rootCmd.AddCommand(agent.NewAgentCmd())
 	rootCmd.AddCommand(worker.NewWorkerCmd())
 	rootCmd.AddCommand(share.NewShareCmd())
@@ -55,11 +96,20 @@ func init() {
 	if cleanCmd := use.NewCleanCmd(); cleanCmd != nil {
 		rootCmd.AddCommand(cleanCmd)
 	}
+	// Run command (disabled on macOS - returns nil)
+	if runCmd := use.NewRunCmd(); runCmd != nil {
+		rootCmd.AddCommand(runCmd)
+	}
 	rootCmd.AddCommand(deps.NewDepsCmd())
 	rootCmd.AddCommand(studio.NewStudioCmd())
 	rootCmd.AddCommand(libs.NewLibsCmd())
 	rootCmd.AddCommand(system.NewUpdateCmd())
+	rootCmd.AddCommand(system.NewSelfUpdateCmd())
 	rootCmd.AddCommand(system.NewUninstallCmd())
+	rootCmd.AddCommand(config.NewConfigCmd())
+	rootCmd.AddCommand(audit.NewAuditCmd())
+	rootCmd.AddCommand(history.NewHistoryCmd())
+	rootCmd.AddCommand(protocol.NewProtocolCmd())
 
 	// Auth commands (login/logout at root level for convenience)
 	rootCmd.AddCommand(auth.NewLoginCmd())
@@ -73,10 +123,102 @@ func init() {
 	if launchCmd := launch.NewLaunchCmd(); launchCmd != nil {
 		rootCmd.AddCommand(launchCmd)
 	}
+
+	rootCmd.AddCommand(cmdutil.NewExitCodesHelpCmd())
+	cmdutil.MarkUsageErrors(rootCmd)
+	return rootCmd
+}
+
+// profileFromArgs extracts --profile before cobra parses flags. Subcommands
+// read GPU_GO_ENDPOINT into their flag defaults while the tree is built, so
+// the profile has to be applied before newRootCmd runs.
+func profileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--"+profileFlag+"="); ok {
+			return value
+		}
+		if arg == "--"+profileFlag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// noKeyringFromArgs reports whether --no-keyring is given. Like --profile it
+// is needed before cobra parses flags, since applying a profile already loads
+// and may migrate stored tokens.
+func noKeyringFromArgs(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--"+noKeyringFlag || arg == "--"+noKeyringFlag+"=true" {
+			return true
+		}
+	}
+	return false
+}
+
+// isConfigCommand reports whether args run the config subtree, which manages
+// the profiles themselves and must keep working when a profile is broken
+func isConfigCommand(args []string) bool {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return false
+		}
+		if arg == "--"+profileFlag {
+			i++ // skip the flag value
+			continue
+		}
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		return arg == "config"
+	}
+	return false
+}
+
+// isConfigSubcommand reports whether cmd belongs to the config subtree
+func isConfigSubcommand(cmd *cobra.Command) bool {
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		if !cmd.Parent().HasParent() {
+			return cmd.Name() == "config"
+		}
+	}
+	return false
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	args := os.Args[1:]
+	if noKeyringFromArgs(args) {
+		credentials.Disable()
+	}
+	if !isConfigCommand(args) {
+		name := profileFromArgs(args)
+		explicit := name != "" || os.Getenv(config.ProfileEnv) != ""
+		if _, err := config.ApplyProfile(name); err != nil {
+			// A broken saved profile must not lock the user out of every
+			// command; only a profile asked for by name is fatal
+			if explicit {
+				fmt.Fprintf(os.Stderr, i18n.T("Error: %v\n"), err)
+				os.Exit(cmdutil.ExitCode(nil, err))
+			}
+			fmt.Fprintf(os.Stderr, i18n.T("Warning: ignoring current profile: %v\n"), err)
+		}
+	}
+
+	started := time.Now()
+	cmd, err := newRootCmd().ExecuteC()
+	cmdutil.RecordAudit(cmd, err)
+	cmdutil.RecordHistory(cmd, args, started, err)
+	cmdutil.StartBackgroundSync()
+	if err != nil {
+		progress.Failed("", err)
+		klog.Flush()
+		os.Exit(cmdutil.ExitCode(cmd, err))
 	}
 }
@@ -0,0 +1,229 @@
+// Package protocol implements the ggo protocol command, which registers ggo
+// as the handler of ggo:// share links
+package protocol
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/protocol"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+)
+
+var outputFormat string
+
+// NewProtocolCmd creates the protocol command
+func NewProtocolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "protocol",
+		Short: "Handle ggo:// share links from the browser",
+		Long: `Register ggo as the handler of ggo:// links, so that clicking a link such as
+ggo://use/abc123 in a browser opens a terminal that runs 'ggo use abc123'.
+
+Links are registered for the current user only:
+  - Windows: HKCU\Software\Classes\ggo
+  - Linux:   a desktop entry for x-scheme-handler/ggo, set as default with xdg-mime
+  - macOS:   a small handler app in ~/Applications registered with LaunchServices
+
+A clicked link never runs anything without confirmation: the terminal shows
+the share code and the command it would run and asks first. Only
+ggo://use/<code> and ggo://studio/<code> links with a plain share code are
+accepted. On macOS, where 'ggo use' is not available, use links create a
+studio instead.`,
+	}
+
+	cmdutil.AddOutputFlag(cmd, &outputFormat)
+	cmd.AddCommand(cmdutil.Audited(newInstallCmd()))
+	cmd.AddCommand(cmdutil.Audited(newUninstallCmd()))
+	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newOpenCmd())
+
+	return cmd
+}
+
+func getOutput() *tui.Output {
+	return cmdutil.NewOutput(outputFormat)
+}
+
+func newInstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install",
+		Short: "Register ggo as the handler of ggo:// links",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			exe, err := executable()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			location, err := protocol.Install(exe)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to register ggo:// links: error=%v", err)
+				return fmt.Errorf("failed to register %s:// links: %w", protocol.Scheme, err)
+			}
+			return out.Render(&protocolResult{Installed: true, Location: location, Handler: exe})
+		},
+	}
+}
+
+func newUninstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the ggo:// link handler",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			location, err := protocol.Uninstall()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to unregister ggo:// links: error=%v", err)
+				return fmt.Errorf("failed to unregister %s:// links: %w", protocol.Scheme, err)
+			}
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Removed the ggo:// link handler (%s)",
+				Args:    []any{location},
+			})
+		},
+	}
+}
+
+func newStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether ggo handles ggo:// links",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			location, installed := protocol.Installed()
+			return getOutput().Render(&protocolResult{Installed: installed, Location: location})
+		},
+	}
+}
+
+// protocolResult implements Renderable for the install and status commands
+type protocolResult struct {
+	Installed bool   `json:"installed"`
+	Location  string `json:"location,omitempty"`
+	Handler   string `json:"handler,omitempty"`
+}
+
+func (r *protocolResult) RenderJSON() any {
+	return r
+}
+
+func (r *protocolResult) RenderTUI(out *tui.Output) {
+	if !r.Installed {
+		out.Info("ggo:// links are not handled by ggo")
+		out.Println(tui.Muted(i18n.T("Register the handler with: ggo protocol install")))
+		return
+	}
+	if r.Handler != "" {
+		out.Success("ggo:// links now open in ggo")
+	}
+	status := tui.NewStatusTable().Add("Registered", r.Location)
+	if r.Handler != "" {
+		status.Add("Handler", r.Handler)
+	}
+	out.Println(status.String())
+	out.Println(tui.Muted(i18n.T("Try it: open ggo://use/<share-code> in your browser")))
+}
+
+func newOpenCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "open <link>",
+		Short: "Open a ggo:// link",
+		Long: `Open a ggo:// link: show the share code and the command it runs and, once
+confirmed, run it. This is what the registered handler runs for a clicked link.`,
+		Example: `  ggo protocol open ggo://use/abc123`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			err := openLink(args[0], yes)
+			if err != nil {
+				klog.Errorf("Failed to open link: link=%q error=%v", args[0], err)
+			}
+			// The handler's terminal closes when ggo exits; keep it open
+			// until the user has read the outcome
+			if !yes {
+				waitForEnter()
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Run the link's command without asking")
+
+	return cmd
+}
+
+// openLink validates a link, asks for confirmation and runs its command with
+// this ggo binary. The share code is passed as a single argument, never
+// through a shell.
+func openLink(raw string, yes bool) error {
+	link, err := protocol.Parse(raw)
+	if err != nil {
+		return err
+	}
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	args := link.Args(runtime.GOOS)
+
+	styles := tui.DefaultStyles()
+	fmt.Println()
+	fmt.Println(styles.Title.Render(i18n.T("GPU Go Share Link")))
+	fmt.Println(tui.NewStatusTable().
+		Add("Share code", styles.Bold.Render(link.Code)).
+		Add("Command", tui.Code("ggo "+strings.Join(args, " "))).
+		String())
+	if !yes {
+		confirmed, err := tui.ConfirmPrompt("Run this command?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println(i18n.T("Cancelled."))
+			return nil
+		}
+	}
+
+	run := exec.Command(exe, args...)
+	run.Stdin = os.Stdin
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	return run.Run()
+}
+
+// waitForEnter waits for Enter when ggo runs in a terminal
+func waitForEnter() {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	fmt.Print(i18n.T("\nPress Enter to close..."))
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// executable returns the path of the running ggo, which the registered
+// handlers run. Symlinks are kept, so that a link such as a package manager's
+// bin entry keeps working across upgrades.
+func executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the ggo executable: %w", err)
+	}
+	return exe, nil
+}
@@ -0,0 +1,510 @@
+package quickstart
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/auth"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+)
+
+// Step IDs, as saved in the checkpoints
+const (
+	stepRole        = "role"
+	stepLogin       = "login"
+	stepRegister    = "register-agent"
+	stepAgentOnline = "agent-online"
+	stepWorker      = "create-worker"
+	stepShare       = "share-worker"
+	stepShareCode   = "share-code"
+	stepConnect     = "connect"
+)
+
+const (
+	agentStatusOnline = "online"
+	agentPollInterval = 5 * time.Second
+	agentOnlineWait   = 10 * time.Minute
+)
+
+// gpuTools are vendor tools whose presence suggests this machine has GPUs
+var gpuTools = []string{"nvidia-smi", "rocm-smi", "hy-smi", "mthreads-gmi", "cnmon"}
+
+var serverURL string
+
+// platformAPI is the part of the platform API the quickstart uses
+type platformAPI interface {
+	GenerateToken(ctx context.Context, tokenType string) (*api.TokenResponse, error)
+	GetAgent(ctx context.Context, agentID string) (*api.AgentInfo, error)
+	ListWorkers(ctx context.Context, agentID, hostname string) (*api.WorkerListResponse, error)
+	ListShares(ctx context.Context) (*api.ShareListResponse, error)
+	GetSharePublic(ctx context.Context, shortCode string) (*api.SharePublicInfo, error)
+}
+
+// step is one checkpoint of the quickstart
+type step struct {
+	id    string
+	title string
+	run   func(f *flow) error
+}
+
+// flow runs the quickstart steps. Everything that talks to the user, the
+// platform or other ggo commands goes through its fields.
+type flow struct {
+	ctx   context.Context
+	out   *tui.Output
+	state *state
+	path  string
+
+	client func() platformAPI
+	// ggo runs a ggo command attached to the terminal
+	ggo func(args ...string) error
+	// signedIn reports whether a platform token is available
+	signedIn func() bool
+	// agentID returns the ID this machine is registered as, if it is
+	agentID func() string
+	// hasGPU reports whether this machine appears to have GPUs
+	hasGPU   func() bool
+	selectFn func(title string, options []tui.SelectOption, defaultIdx int) (string, error)
+	inputFn  func(prompt, defaultValue string) (string, error)
+	// waitOnline polls until the agent is online
+	waitOnline func(f *flow) error
+}
+
+// NewQuickstartCmd creates the quickstart command
+func NewQuickstartCmd() *cobra.Command {
+	var (
+		restart bool
+		role    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "quickstart",
+		Short: "Guided setup for sharing or using a remote GPU",
+		Long: `Walk through the first steps with GPU Go interactively.
+
+The quickstart signs you in and asks whether this machine shares its GPUs
+(GPU owner) or uses a shared GPU (consumer), suggesting one from the GPUs it
+finds. GPU owners then register this machine as an agent, start it, create a
+worker and share it. Consumers paste the share link or code they received and
+either activate the GPU in a shell ('ggo use') or create a studio container.
+
+Each step runs the regular ggo command for it, so everything the quickstart
+sets up can be managed with those commands later. Progress is saved after
+every step: run 'ggo quickstart' again to continue an interrupted quickstart,
+or pass --restart to start over.
+
+Examples:
+  # Start or continue the guided setup
+  ggo quickstart
+
+  # Skip the role question
+  ggo quickstart --role consumer
+
+  # Start over
+  ggo quickstart --restart`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if role != "" && role != roleOwner && role != roleConsumer {
+				return cmdutil.UsageErrorf("invalid --role %q: must be %s or %s", role, roleOwner, roleConsumer)
+			}
+			cmd.SilenceUsage = true
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return fmt.Errorf("quickstart is interactive and needs a terminal; see 'ggo --help' for the commands it runs")
+			}
+
+			paths := platform.DefaultPaths()
+			f := newFlow(statePath(paths))
+			if restart {
+				if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to reset quickstart progress: %w", err)
+				}
+			}
+			if err := f.load(); err != nil {
+				klog.Errorf("Failed to load quickstart progress: path=%s error=%v", f.path, err)
+				return err
+			}
+			if role != "" && f.state.Role == "" {
+				f.state.Role = role
+				f.state.complete(stepRole)
+			}
+			if err := f.run(); err != nil {
+				klog.Errorf("Quickstart stopped: error=%v", err)
+				f.out.Println()
+				f.out.Println(tui.Code("ggo quickstart") + " " + tui.DefaultStyles().Muted.Render(i18n.T("continues where you left off")))
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&restart, "restart", false, "Discard saved progress and start over")
+	cmd.Flags().StringVar(&role, "role", "", "Skip the role question: owner (share this machine's GPUs) or consumer (use a shared GPU)")
+	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL (or set GPU_GO_ENDPOINT env var)")
+
+	return cmd
+}
+
+// newFlow returns a flow talking to the terminal and the platform
+func newFlow(path string) *flow {
+	return &flow{
+		ctx:      context.Background(),
+		out:      cmdutil.NewOutput("table"),
+		path:     path,
+		client:   func() platformAPI { return userClient() },
+		ggo:      runGGO,
+		signedIn: func() bool { return userToken() != "" },
+		agentID: func() string {
+			cfg, err := config.NewManager("", "").LoadConfig()
+			if err != nil || cfg == nil || cfg.AgentSecret == "" {
+				return ""
+			}
+			return cfg.AgentID
+		},
+		hasGPU: func() bool {
+			return slices.ContainsFunc(gpuTools, func(tool string) bool {
+				_, err := exec.LookPath(tool)
+				return err == nil
+			})
+		},
+		selectFn: func(title string, options []tui.SelectOption, defaultIdx int) (string, error) {
+			return tui.SelectPromptWithDefault(title, options, defaultIdx, false)
+		},
+		inputFn:    tui.InputPromptWithDefault,
+		waitOnline: pollAgentOnline,
+	}
+}
+
+func (f *flow) load() error {
+	s, err := loadState(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to read quickstart progress: %w", err)
+	}
+	f.state = s
+	return nil
+}
+
+// steps returns the steps for the chosen role; before a role is chosen, only
+// the common ones
+func (f *flow) steps() []step {
+	steps := []step{
+		{stepRole, i18n.T("Choose what to do"), (*flow).chooseRole},
+		{stepLogin, i18n.T("Sign in to GPU Go"), (*flow).login},
+	}
+	switch f.state.Role {
+	case roleOwner:
+		steps = append(steps,
+			step{stepRegister, i18n.T("Register this machine as an agent"), (*flow).registerAgent},
+			step{stepAgentOnline, i18n.T("Start the agent"), (*flow).agentOnline},
+			step{stepWorker, i18n.T("Create a worker"), (*flow).createWorker},
+			step{stepShare, i18n.T("Share the worker"), (*flow).shareWorker},
+		)
+	case roleConsumer:
+		steps = append(steps,
+			step{stepShareCode, i18n.T("Enter the share link"), (*flow).enterShareCode},
+			step{stepConnect, i18n.T("Connect to the GPU"), (*flow).connect},
+		)
+	}
+	return steps
+}
+
+// run executes the steps not completed yet, saving progress after each
+func (f *flow) run() error {
+	if f.state.started() {
+		if f.finished() {
+			f.out.Info("Quickstart already completed; pass --restart to run it again")
+			f.summary()
+			return nil
+		}
+		f.out.Infof("Resuming quickstart: %d step(s) already done", len(f.state.Completed))
+	}
+
+	for {
+		steps := f.steps()
+		idx := slices.IndexFunc(steps, func(s step) bool { return !f.state.done(s.id) })
+		if idx < 0 {
+			break
+		}
+		s := steps[idx]
+		tui.StepHeader(idx+1, f.totalSteps(), s.title)
+		if err := s.run(f); err != nil {
+			return err
+		}
+		f.state.complete(s.id)
+		if err := saveState(f.path, f.state); err != nil {
+			// Only resuming suffers; the step itself succeeded
+			klog.Warningf("Failed to save quickstart progress: path=%s error=%v", f.path, err)
+		}
+	}
+	f.summary()
+	return nil
+}
+
+// totalSteps is the number of steps of the role, or of the longer path while
+// no role is chosen
+func (f *flow) totalSteps() int {
+	if f.state.Role == "" {
+		return 6
+	}
+	return len(f.steps())
+}
+
+// finished reports whether all steps of the chosen role are done
+func (f *flow) finished() bool {
+	return f.state.Role != "" && !slices.ContainsFunc(f.steps(), func(s step) bool { return !f.state.done(s.id) })
+}
+
+func (f *flow) chooseRole() error {
+	owner := tui.SelectOption{Label: i18n.T("Share the GPUs of this machine (GPU owner)"), Value: roleOwner}
+	consumer := tui.SelectOption{Label: i18n.T("Use a GPU someone shared with me (consumer)"), Value: roleConsumer}
+	defaultIdx := 1
+	if f.agentID() != "" || f.hasGPU() {
+		defaultIdx = 0
+	}
+	role, err := f.selectFn(i18n.T("What do you want to do?"), []tui.SelectOption{owner, consumer}, defaultIdx)
+	if err != nil {
+		return err
+	}
+	f.state.Role = role
+	return nil
+}
+
+func (f *flow) login() error {
+	if f.signedIn() {
+		f.out.Success("Already signed in")
+		return nil
+	}
+	if err := f.ggo("login"); err != nil {
+		return fmt.Errorf("sign-in failed: %w", err)
+	}
+	if !f.signedIn() {
+		return cmdutil.AuthErrorf("not signed in; run 'ggo login' to sign in")
+	}
+	return nil
+}
+
+func (f *flow) registerAgent() error {
+	if id := f.agentID(); id != "" {
+		f.state.AgentID = id
+		f.out.Successf("This machine is already registered as agent %s", id)
+		return nil
+	}
+	token, err := f.client().GenerateToken(f.ctx, "agent_install")
+	if err != nil {
+		return fmt.Errorf("failed to create an installation token: %w", err)
+	}
+	if err := f.ggo("agent", "register", "--token", token.Token); err != nil {
+		return fmt.Errorf("agent registration failed: %w", err)
+	}
+	f.state.AgentID = f.agentID()
+	if f.state.AgentID == "" {
+		return fmt.Errorf("agent registration did not complete")
+	}
+	return nil
+}
+
+func (f *flow) agentOnline() error {
+	info, err := f.client().GetAgent(f.ctx, f.state.AgentID)
+	if err == nil && info.Status == agentStatusOnline {
+		f.out.Successf("Agent %s is online", f.state.AgentID)
+		return nil
+	}
+
+	f.out.Println(i18n.T("Start the agent in another terminal, or as a service:"))
+	f.out.Println()
+	f.out.Println("  " + tui.Code("ggo agent start"))
+	if platform.IsLinux() {
+		f.out.Println("  " + tui.Code("sudo systemctl start ggo-agent") + " " + tui.DefaultStyles().Muted.Render(i18n.T("(when installed as a service)")))
+	}
+	f.out.Println()
+	if err := f.waitOnline(f); err != nil {
+		return err
+	}
+	f.out.Successf("Agent %s is online", f.state.AgentID)
+	return nil
+}
+
+// pollAgentOnline waits until the platform reports the agent online
+func pollAgentOnline(f *flow) error {
+	f.out.Infof("Waiting for agent %s to come online...", f.state.AgentID)
+	ctx, cancel := context.WithTimeout(f.ctx, agentOnlineWait)
+	defer cancel()
+	ticker := time.NewTicker(agentPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("agent %s did not come online within %s", f.state.AgentID, agentOnlineWait)
+		case <-ticker.C:
+			info, err := f.client().GetAgent(ctx, f.state.AgentID)
+			if err != nil {
+				klog.V(4).Infof("Failed to get agent status: agent_id=%s error=%v", f.state.AgentID, err)
+				continue
+			}
+			if info.Status == agentStatusOnline {
+				return nil
+			}
+		}
+	}
+}
+
+func (f *flow) createWorker() error {
+	before, err := f.client().ListWorkers(f.ctx, f.state.AgentID, "")
+	if err != nil {
+		return fmt.Errorf("failed to list workers: %w", err)
+	}
+	if err := f.ggo("worker", "create", "--agent-id", f.state.AgentID); err != nil {
+		return fmt.Errorf("worker creation failed: %w", err)
+	}
+	after, err := f.client().ListWorkers(f.ctx, f.state.AgentID, "")
+	if err != nil {
+		return fmt.Errorf("failed to list workers: %w", err)
+	}
+	for _, w := range after.Workers {
+		if !slices.ContainsFunc(before.Workers, func(b api.WorkerInfo) bool { return b.WorkerID == w.WorkerID }) {
+			f.state.WorkerID, f.state.WorkerName = w.WorkerID, w.Name
+			return nil
+		}
+	}
+	return fmt.Errorf("no worker was created")
+}
+
+func (f *flow) shareWorker() error {
+	if err := f.ggo("worker", "share", f.state.WorkerName); err != nil {
+		return fmt.Errorf("sharing the worker failed: %w", err)
+	}
+	shares, err := f.client().ListShares(f.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list shares: %w", err)
+	}
+	var latest *api.ShareInfo
+	for i, s := range shares.Shares {
+		if s.WorkerID == f.state.WorkerID && (latest == nil || s.CreatedAt.After(latest.CreatedAt)) {
+			latest = &shares.Shares[i]
+		}
+	}
+	if latest == nil {
+		return fmt.Errorf("no share was created for worker %s", f.state.WorkerName)
+	}
+	f.state.ShareCode = latest.ShortCode
+	return nil
+}
+
+func (f *flow) enterShareCode() error {
+	input, err := f.inputFn(i18n.T("Paste the share link or code you received"), "")
+	if err != nil {
+		return err
+	}
+	code := cmdutil.ExtractShortCode(input)
+	info, err := f.client().GetSharePublic(f.ctx, code)
+	if err != nil {
+		return fmt.Errorf("share %s not found: %w", code, err)
+	}
+	f.state.ShareCode = code
+	f.out.Successf("Found GPU worker %s (%s)", info.WorkerID, info.HardwareVendor)
+	return nil
+}
+
+func (f *flow) connect() error {
+	options := []tui.SelectOption{
+		{Label: i18n.T("Activate the GPU in a shell ('ggo use')"), Value: connectUse},
+		{Label: i18n.T("Create a studio container ('ggo studio create')"), Value: connectStudio},
+	}
+	if platform.IsDarwin() {
+		// 'ggo use' is not available on macOS
+		options = options[1:]
+	}
+	choice, err := f.selectFn(i18n.T("How do you want to use the GPU?"), options, 0)
+	if err != nil {
+		return err
+	}
+	f.state.Connect = choice
+
+	if choice == connectUse {
+		if err := f.ggo("use", f.state.ShareCode); err != nil {
+			return fmt.Errorf("setting up the GPU environment failed: %w", err)
+		}
+		return nil
+	}
+	name, err := f.inputFn(i18n.T("Studio name"), "quickstart")
+	if err != nil {
+		return err
+	}
+	if err := f.ggo("studio", "create", name, "--share-link", f.state.ShareCode); err != nil {
+		return fmt.Errorf("creating the studio failed: %w", err)
+	}
+	f.state.StudioName = name
+	return nil
+}
+
+// summary shows what the quickstart set up and what to do next
+func (f *flow) summary() {
+	f.out.Println()
+	switch f.state.Role {
+	case roleOwner:
+		f.out.Success("Your GPU is shared!")
+		table := tui.NewStatusTable().
+			Add("Agent", f.state.AgentID).
+			Add("Worker", fmt.Sprintf("%s (%s)", f.state.WorkerName, f.state.WorkerID)).
+			Add("Share Code", f.state.ShareCode)
+		f.out.Println(table.String())
+		f.out.Println()
+		f.out.Println(i18n.T("On the machine that should use the GPU, run:"))
+		f.out.Println("  " + tui.Code("ggo use "+f.state.ShareCode))
+	case roleConsumer:
+		f.out.Success("You are set up to use the remote GPU!")
+		f.out.Println()
+		f.out.Println(i18n.T("Next steps:"))
+		if f.state.Connect == connectStudio {
+			f.out.Println("  " + tui.Code("ggo studio ssh "+f.state.StudioName))
+		} else {
+			f.out.Println("  " + tui.Code(`eval "$(ggo use `+f.state.ShareCode+` -y)"`))
+		}
+		if !platform.IsDarwin() {
+			f.out.Println("  " + tui.Code("ggo run --share "+f.state.ShareCode+" -- python train.py"))
+		}
+	}
+}
+
+// runGGO runs a ggo command with the terminal attached
+func runGGO(args ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the ggo binary: %w", err)
+	}
+	klog.Infof("Quickstart running: ggo %s", strings.Join(args, " "))
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// userToken returns the platform token of the signed-in user
+func userToken() string {
+	for _, env := range []string{"GPU_GO_TOKEN", "GPU_GO_USER_TOKEN"} {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+	if token, err := auth.GetToken(); err == nil {
+		return token
+	}
+	return ""
+}
+
+func userClient() *api.Client {
+	return api.NewClient(api.WithBaseURL(serverURL), api.WithUserToken(userToken()))
+}
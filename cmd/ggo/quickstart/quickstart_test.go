@@ -0,0 +1,152 @@
+package quickstart
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlatform serves the quickstart's API calls from memory
+type fakePlatform struct {
+	agentStatus string
+	workers     []api.WorkerInfo
+	shares      []api.ShareInfo
+}
+
+func (p *fakePlatform) GenerateToken(ctx context.Context, tokenType string) (*api.TokenResponse, error) {
+	return &api.TokenResponse{Token: "tmp-" + tokenType}, nil
+}
+
+func (p *fakePlatform) GetAgent(ctx context.Context, agentID string) (*api.AgentInfo, error) {
+	return &api.AgentInfo{AgentID: agentID, Status: p.agentStatus}, nil
+}
+
+func (p *fakePlatform) ListWorkers(ctx context.Context, agentID, hostname string) (*api.WorkerListResponse, error) {
+	return &api.WorkerListResponse{Workers: append([]api.WorkerInfo(nil), p.workers...)}, nil
+}
+
+func (p *fakePlatform) ListShares(ctx context.Context) (*api.ShareListResponse, error) {
+	return &api.ShareListResponse{Shares: p.shares}, nil
+}
+
+func (p *fakePlatform) GetSharePublic(ctx context.Context, shortCode string) (*api.SharePublicInfo, error) {
+	if shortCode != "abc123" {
+		return nil, errors.New("not found")
+	}
+	return &api.SharePublicInfo{WorkerID: "wk-1", HardwareVendor: "nvidia"}, nil
+}
+
+// testFlow returns a flow whose ggo commands act on p and are recorded in ran
+func testFlow(t *testing.T, p *fakePlatform, ran *[]string) *flow {
+	t.Helper()
+	signedIn, agentID := false, ""
+	f := &flow{
+		ctx:      context.Background(),
+		out:      cmdutil.NewOutput("table"),
+		path:     filepath.Join(t.TempDir(), StateFile),
+		client:   func() platformAPI { return p },
+		signedIn: func() bool { return signedIn },
+		agentID:  func() string { return agentID },
+		hasGPU:   func() bool { return true },
+		selectFn: func(title string, options []tui.SelectOption, defaultIdx int) (string, error) {
+			return options[defaultIdx].Value, nil
+		},
+		inputFn: func(prompt, defaultValue string) (string, error) {
+			if defaultValue != "" {
+				return defaultValue, nil
+			}
+			return "https://gpu.tf/s/abc123", nil
+		},
+		waitOnline: func(f *flow) error {
+			p.agentStatus = agentStatusOnline
+			return nil
+		},
+	}
+	f.ggo = func(args ...string) error {
+		*ran = append(*ran, strings.Join(args, " "))
+		switch args[0] {
+		case "login":
+			signedIn = true
+		case "agent":
+			agentID = "agent-1"
+		case "worker":
+			if args[1] == "create" {
+				p.workers = append(p.workers, api.WorkerInfo{WorkerID: "wk-new", Name: "trainer"})
+			} else {
+				p.shares = append(p.shares,
+					api.ShareInfo{ShortCode: "old111", WorkerID: "wk-new", CreatedAt: time.Now().Add(-time.Hour)},
+					api.ShareInfo{ShortCode: "new222", WorkerID: "wk-new", CreatedAt: time.Now()})
+			}
+		}
+		return nil
+	}
+	require.NoError(t, f.load())
+	return f
+}
+
+func TestFlow_Owner(t *testing.T) {
+	p := &fakePlatform{workers: []api.WorkerInfo{{WorkerID: "wk-old", Name: "old"}}}
+	var ran []string
+	f := testFlow(t, p, &ran)
+
+	require.NoError(t, f.run())
+	assert.Equal(t, []string{
+		"login",
+		"agent register --token tmp-agent_install",
+		"worker create --agent-id agent-1",
+		"worker share trainer",
+	}, ran)
+	assert.Equal(t, roleOwner, f.state.Role, "GPUs on the machine suggest the owner role")
+	assert.Equal(t, "wk-new", f.state.WorkerID)
+	assert.Equal(t, "new222", f.state.ShareCode, "the newest share of the worker is shown")
+
+	saved, err := loadState(f.path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{stepRole, stepLogin, stepRegister, stepAgentOnline, stepWorker, stepShare}, saved.Completed)
+}
+
+func TestFlow_ResumesAfterFailure(t *testing.T) {
+	p := &fakePlatform{}
+	var ran []string
+	f := testFlow(t, p, &ran)
+	ggo := f.ggo
+	f.ggo = func(args ...string) error {
+		if args[0] == "worker" {
+			return errors.New("exit status 1")
+		}
+		return ggo(args...)
+	}
+	assert.ErrorContains(t, f.run(), "worker creation failed")
+
+	// A new run picks up at the failed step
+	ran = nil
+	f.ggo = ggo
+	require.NoError(t, f.load())
+	assert.True(t, f.state.done(stepAgentOnline))
+	require.NoError(t, f.run())
+	assert.Equal(t, []string{"worker create --agent-id agent-1", "worker share trainer"}, ran)
+	assert.True(t, f.finished())
+}
+
+func TestFlow_Consumer(t *testing.T) {
+	p := &fakePlatform{}
+	var ran []string
+	f := testFlow(t, p, &ran)
+	f.hasGPU = func() bool { return false }
+
+	require.NoError(t, f.run())
+	assert.Equal(t, roleConsumer, f.state.Role)
+	assert.Equal(t, "abc123", f.state.ShareCode)
+	require.NotEmpty(t, ran)
+	assert.Equal(t, "login", ran[0])
+	assert.Contains(t, []string{"use abc123", "studio create quickstart --share-link abc123"}, ran[len(ran)-1])
+}
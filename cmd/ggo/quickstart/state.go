@@ -0,0 +1,76 @@
+package quickstart
+
+import (
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+)
+
+// StateFile holds the quickstart checkpoints in the config dir
+const StateFile = "quickstart.json"
+
+// Roles the quickstart guides through
+const (
+	roleOwner    = "owner"
+	roleConsumer = "consumer"
+)
+
+// How a consumer connects to the shared GPU
+const (
+	connectUse    = "use"
+	connectStudio = "studio"
+)
+
+// state is the progress of a quickstart, saved after every step so an
+// interrupted quickstart resumes where it stopped
+type state struct {
+	Role string `json:"role,omitempty"`
+	// Completed lists the IDs of the finished steps
+	Completed  []string  `json:"completed,omitempty"`
+	AgentID    string    `json:"agentId,omitempty"`
+	WorkerID   string    `json:"workerId,omitempty"`
+	WorkerName string    `json:"workerName,omitempty"`
+	ShareCode  string    `json:"shareCode,omitempty"`
+	Connect    string    `json:"connect,omitempty"`
+	StudioName string    `json:"studioName,omitempty"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func statePath(paths *platform.Paths) string {
+	return filepath.Join(paths.ConfigDir(), StateFile)
+}
+
+// loadState reads the saved progress, or returns an empty state
+func loadState(path string) (*state, error) {
+	s, err := utils.LoadJSON[state](path)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &state{}
+	}
+	return s, nil
+}
+
+func saveState(path string, s *state) error {
+	s.UpdatedAt = time.Now()
+	return utils.SaveJSON(path, s, 0600)
+}
+
+func (s *state) done(id string) bool {
+	return slices.Contains(s.Completed, id)
+}
+
+func (s *state) complete(id string) {
+	if !s.done(id) {
+		s.Completed = append(s.Completed, id)
+	}
+}
+
+// started reports whether any step was completed
+func (s *state) started() bool {
+	return len(s.Completed) > 0
+}
@@ -0,0 +1,190 @@
+package share
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// quotaFlags holds the usage quota flags shared by share create and share quota
+type quotaFlags struct {
+	gpuHoursPerWeek       float64
+	maxSession            time.Duration
+	maxConcurrentSessions int
+}
+
+func (f *quotaFlags) register(cmd *cobra.Command) {
+	cmd.Flags().Float64Var(&f.gpuHoursPerWeek, "gpu-hours-per-week", 0, "GPU-hours all consumers may use per week, from Monday 00:00 UTC (0 = unlimited)")
+	cmd.Flags().DurationVar(&f.maxSession, "max-session", 0, "Maximum duration of one session, e.g. 2h (0 = unlimited)")
+	cmd.Flags().IntVar(&f.maxConcurrentSessions, "max-concurrent-sessions", 0, "Maximum open sessions per consumer (0 = unlimited)")
+}
+
+// build validates the quota flags. It returns nil when no limit is set.
+func (f *quotaFlags) build() (*api.ShareQuota, error) {
+	if f.gpuHoursPerWeek < 0 || f.maxSession < 0 || f.maxConcurrentSessions < 0 {
+		return nil, fmt.Errorf("quota limits cannot be negative")
+	}
+	if f.maxSession > 0 && f.maxSession < time.Minute {
+		return nil, cmdutil.UsageErrorf("--max-session must be at least 1m")
+	}
+	quota := &api.ShareQuota{
+		GPUHoursPerWeek:       f.gpuHoursPerWeek,
+		MaxSessionMinutes:     int(f.maxSession.Round(time.Minute) / time.Minute),
+		MaxConcurrentSessions: f.maxConcurrentSessions,
+	}
+	if quota.IsZero() {
+		return nil, nil
+	}
+	return quota, nil
+}
+
+func newShareQuotaCmd() *cobra.Command {
+	var flags quotaFlags
+	var off bool
+
+	cmd := &cobra.Command{
+		Use:   "quota <share-id|short-link|alias>",
+		Short: "Limit how much a share link may be used",
+		Long: `Set usage quotas on a share link. The agent serving the worker enforces them
+on the connections of the share's consumers:
+
+  --gpu-hours-per-week       new sessions are refused and open ones are ended
+                             once all consumers together used up the GPU-hours
+                             of the week (session hours times the worker's GPUs)
+  --max-session              sessions lasting longer are ended
+  --max-concurrent-sessions  further sessions of a consumer (client IP) are
+                             refused while it has this many open
+
+The flags replace the share's whole quota; limits left out are removed. Use
+--off to remove the quota. 'ggo share inspect' shows consumption against the
+quota.
+
+Sessions can only be attributed to a share when it is the worker's only
+share; clients of workers with several shares are not limited.`,
+		Example: `  # 20 GPU-hours a week, sessions of at most 4 hours
+  ggo share quota abc123 --gpu-hours-per-week 20 --max-session 4h
+
+  # One session at a time per consumer
+  ggo share quota abc123 --max-concurrent-sessions 1
+
+  # Remove the quota
+  ggo share quota abc123 --off`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := getClient()
+			ctx := context.Background()
+			out := getOutput()
+
+			quota := &api.ShareQuota{}
+			if !off {
+				q, err := flags.build()
+				if err != nil {
+					return err
+				}
+				if q == nil {
+					return fmt.Errorf("specify --gpu-hours-per-week, --max-session and/or --max-concurrent-sessions, or --off")
+				}
+				quota = q
+			} else if cmd.Flags().Changed("gpu-hours-per-week") || cmd.Flags().Changed("max-session") || cmd.Flags().Changed("max-concurrent-sessions") {
+				return cmdutil.UsageErrorf("--off cannot be combined with quota limits")
+			}
+
+			share, err := findShare(ctx, client, args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to find share: error=%v", err)
+				return err
+			}
+
+			if _, err := client.UpdateShare(ctx, share.ShareID, &api.ShareUpdateRequest{Quota: quota}); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to update share quota: share_id=%s error=%v", share.ShareID, err)
+				return err
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Quota for share %s: %s",
+				Args:    []any{share.ShortCode, formatQuota(quota)},
+				ID:      share.ShareID,
+			})
+		},
+	}
+
+	flags.register(cmd)
+	cmd.Flags().BoolVar(&off, "off", false, "Remove the quota")
+
+	return cmd
+}
+
+// formatQuota describes a share quota in one line
+func formatQuota(q *api.ShareQuota) string {
+	if q.IsZero() {
+		return i18n.T("unlimited")
+	}
+	var limits []string
+	if q.GPUHoursPerWeek > 0 {
+		limits = append(limits, i18n.Tf("%s GPU-hours/week", formatGPUHours(q.GPUHoursPerWeek)))
+	}
+	if q.MaxSessionMinutes > 0 {
+		limits = append(limits, i18n.Tf("sessions up to %s", formatMinutes(q.MaxSessionMinutes)))
+	}
+	if q.MaxConcurrentSessions > 0 {
+		limits = append(limits, i18n.Tf("%d concurrent session(s) per consumer", q.MaxConcurrentSessions))
+	}
+	return strings.Join(limits, ", ")
+}
+
+// formatGPUHours prints GPU-hours with at most one decimal
+func formatGPUHours(hours float64) string {
+	return strconv.FormatFloat(math.Round(hours*10)/10, 'f', -1, 64)
+}
+
+// formatMinutes prints a duration in minutes as e.g. 45m, 2h or 1h30m
+func formatMinutes(minutes int) string {
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh%dm", minutes/60, minutes%60)
+}
+
+// addQuotaUsage adds the share's consumption against its quota to status
+func addQuotaUsage(status *tui.StatusTable, share *api.ShareInfo) {
+	styles := tui.DefaultStyles()
+	status.Add("Quota", formatQuota(share.Quota))
+	usage := share.QuotaUsage
+	if usage == nil {
+		return
+	}
+	hours := formatGPUHours(usage.GPUHours)
+	var limit float64
+	if share.Quota != nil {
+		limit = share.Quota.GPUHoursPerWeek
+	}
+	if limit > 0 {
+		hours = fmt.Sprintf("%s / %s (%.0f%%)", hours, formatGPUHours(limit), usage.GPUHours/limit*100)
+		if usage.GPUHours >= limit {
+			hours = styles.Error.Render(hours)
+		} else if usage.GPUHours >= limit*0.8 {
+			hours = styles.Warning.Render(hours)
+		}
+	}
+	status.Add("GPU-Hours This Week", hours)
+	status.Add("Active Sessions", fmt.Sprintf("%d", usage.ActiveSessions))
+	if usage.QuotaDenied > 0 {
+		status.Add("Denied By Quota", styles.Warning.Render(i18n.Tf("%d session(s) this week", usage.QuotaDenied)))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/mail"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -12,6 +14,10 @@ import (
 	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
 	"github.com/NexusGPU/gpu-go/internal/api"
 	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -23,22 +29,6 @@ var (
 	outputFormat string
 )
 
-// extractShortCode extracts the short code from a short link URL or returns the input as-is if it's already a code.
-// Supports formats: "abc123", "https://gpu.tf/s/abc123", "gpu.tf/s/abc123"
-func extractShortCode(input string) string {
-	input = strings.TrimSpace(input)
-
-	// If it looks like a URL, extract the last path segment
-	if strings.Contains(input, "/") {
-		parts := strings.Split(strings.TrimSuffix(input, "/"), "/")
-		if len(parts) > 0 {
-			return parts[len(parts)-1]
-		}
-	}
-
-	return input
-}
-
 // NewShareCmd creates the share command
 func NewShareCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -60,10 +50,14 @@ func NewShareCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&userToken, "token", "", "User authentication token")
 	cmdutil.AddOutputFlag(cmd, &outputFormat)
 
-	cmd.AddCommand(newShareCreateCmd())
+	cmd.AddCommand(cmdutil.Audited(newShareCreateCmd()))
 	cmd.AddCommand(newShareListCmd())
-	cmd.AddCommand(newShareDeleteCmd())
+	cmd.AddCommand(cmdutil.Audited(newShareDeleteCmd()))
 	cmd.AddCommand(newShareGetCmd())
+	cmd.AddCommand(newShareInspectCmd())
+	cmd.AddCommand(newShareStatsCmd())
+	cmd.AddCommand(cmdutil.Audited(newShareNotifyCmd()))
+	cmd.AddCommand(cmdutil.Audited(newShareQuotaCmd()))
 
 	return cmd
 }
@@ -78,12 +72,15 @@ func getClient() *api.Client {
 		token = os.Getenv("GPU_GO_USER_TOKEN")
 	}
 
-	// Try agent config secret before PAT token
-	if token == "" {
+	// Try agent config secret before PAT token. The agent is registered with
+	// the default endpoint, so its secret is never sent to a profile's one.
+	agentSecret := false
+	if token == "" && platform.DefaultPaths().ProfileDir() == "" {
 		cfgMgr := config.NewManager("", "")
 		if agentCfg, err := cfgMgr.LoadConfig(); err == nil && agentCfg != nil && agentCfg.AgentSecret != "" {
 			klog.V(2).Infof("Using agent secret for authentication")
 			token = agentCfg.AgentSecret
+			agentSecret = true
 		}
 	}
 
@@ -93,10 +90,16 @@ func getClient() *api.Client {
 			token = savedToken
 		}
 	}
-	return api.NewClient(
+	opts := []api.ClientOption{
 		api.WithBaseURL(serverURL),
 		api.WithUserToken(token),
-	)
+	}
+	// Scope errors name the missing scope; user tokens can be recreated
+	// with it, the agent secret cannot
+	if !agentSecret {
+		opts = append(opts, api.WithScopeHint(auth.ScopeHint))
+	}
+	return api.NewClient(opts...)
 }
 
 func getOutput() *tui.Output {
@@ -108,17 +111,55 @@ func newShareCreateCmd() *cobra.Command {
 	var connectionIP string
 	var expiresIn string
 	var maxUses int
+	var notifyWebhook string
+	var notifyEmail string
+	var notifyOn []string
+	var relay bool
+	var forStudio string
+	var snippetImage string
+	var snippetArch string
+	var quota quotaFlags
+	var alias string
 
 	cmd := &cobra.Command{
 		Use:   "create <worker-name>",
 		Short: "Create a share link for a worker",
-		Long:  `Create a shareable link that allows others to connect to your GPU worker.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Create a shareable link that allows others to connect to your GPU worker.
+
+With --for-studio, a docker run command (or, with --for-studio=compose, a
+compose file) is printed along with the share. It downloads the GPU client
+libraries from the CDN into a volume and runs a container using the share,
+so users can connect without installing ggo.`,
+		Example: `  # Share a worker
+  ggo share create my-worker
+
+  # Share a worker with users who only have Docker
+  ggo share create my-worker --for-studio
+
+  # Emit a compose file for an ARM machine
+  ggo share create my-worker --for-studio=compose --arch arm64
+
+  # Limit consumers to 10 GPU-hours a week and 2-hour sessions
+  ggo share create my-worker --gpu-hours-per-week 10 --max-session 2h
+
+  # Name the share, so that you can use it by name when signed in
+  ggo share create my-worker --alias lab-3090
+  ggo use lab-3090`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := getClient()
 			ctx := context.Background()
 			out := getOutput()
 
+			if forStudio != "" && forStudio != studio.SnippetDocker && forStudio != studio.SnippetCompose {
+				return cmdutil.UsageErrorf("invalid --for-studio format %q (use %s or %s)", forStudio, studio.SnippetDocker, studio.SnippetCompose)
+			}
+			if alias != "" {
+				if err := cmdutil.ValidateShareAlias(alias); err != nil {
+					return err
+				}
+			}
+
 			if len(args) > 0 && workerID == "" {
 				resp, err := client.ListWorkers(ctx, "", "")
 				if err != nil {
@@ -138,23 +179,25 @@ func newShareCreateCmd() *cobra.Command {
 				if workerID == "" {
 					cmd.SilenceUsage = true
 					klog.Errorf("Worker not found: name=%s", workerName)
-					return fmt.Errorf("worker '%s' not found", workerName)
+					return cmdutil.NotFoundErrorf("worker '%s' not found", workerName)
 				}
 			}
 
 			if workerID == "" {
-				return fmt.Errorf("worker ID or name is required")
+				return cmdutil.UsageErrorf("worker ID or name is required")
 			}
 
 			req := &api.ShareCreateRequest{
 				WorkerID:     workerID,
 				ConnectionIP: connectionIP,
+				Relay:        relay,
+				Alias:        alias,
 			}
 
 			if expiresIn != "" {
 				duration, err := time.ParseDuration(expiresIn)
 				if err != nil {
-					return fmt.Errorf("invalid expiration duration: %w", err)
+					return cmdutil.UsageErrorf("invalid expiration duration: %w", err)
 				}
 				expiresAt := time.Now().Add(duration)
 				req.ExpiresAt = &expiresAt
@@ -164,14 +207,37 @@ func newShareCreateCmd() *cobra.Command {
 				req.MaxUses = &maxUses
 			}
 
+			notifications, err := buildNotifications(notifyWebhook, notifyEmail, notifyOn)
+			if err != nil {
+				return err
+			}
+			req.Notifications = notifications
+
+			if req.Quota, err = quota.build(); err != nil {
+				return err
+			}
+
 			resp, err := client.CreateShare(ctx, req)
 			if err != nil {
 				cmd.SilenceUsage = true
-				klog.Errorf("Failed to create share: error=%v", err)
-				return err
+				klog.Errorf("Failed to create share: alias=%s error=%v", alias, err)
+				return cmdutil.ShareAliasError(ctx, client, alias, err)
 			}
 
-			return out.Render(&shareCreateResult{share: resp})
+			result := &shareCreateResult{share: resp}
+			if forStudio != "" {
+				snippet, err := buildShareSnippet(ctx, client, resp, snippetImage, snippetArch)
+				if err == nil {
+					result.snippet, err = snippet.Render(forStudio)
+				}
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to generate container snippet: share=%s error=%v", resp.ShortCode, err)
+					return fmt.Errorf("share %s was created, but its container snippet could not be generated: %w", resp.ShortCode, err)
+				}
+			}
+
+			return out.Render(result)
 		},
 	}
 
@@ -179,17 +245,58 @@ func newShareCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&connectionIP, "connection-ip", "", "Connection IP address")
 	cmd.Flags().StringVar(&expiresIn, "expires-in", "", "Expiration duration (e.g., 24h, 7d)")
 	cmd.Flags().IntVar(&maxUses, "max-uses", 0, "Maximum number of uses (0 = unlimited)")
+	cmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "Webhook URL notified when the share is first used or exhausted")
+	cmd.Flags().StringVar(&notifyEmail, "notify-email", "", "Email address notified when the share is first used or exhausted")
+	cmd.Flags().StringSliceVar(&notifyOn, "notify-on", nil, "Events to notify about (first-use, exhausted; default: both)")
+	cmd.Flags().BoolVar(&relay, "relay", false, "Serve the share through the platform relay (works behind NAT and firewalls)")
+	cmd.Flags().StringVar(&forStudio, "for-studio", "", "Also print a container snippet using the share without ggo (docker, compose)")
+	cmd.Flags().Lookup("for-studio").NoOptDefVal = studio.SnippetDocker
+	cmd.Flags().StringVar(&snippetImage, "image", studio.DefaultImageStudioTorch, "Container image used in the --for-studio snippet")
+	cmd.Flags().StringVar(&snippetArch, "arch", "amd64", "CPU architecture of the machines running the --for-studio snippet (amd64, arm64)")
+	cmd.Flags().StringVar(&alias, "alias", "", "Name for the share, unique in your account, that 'ggo use <alias>' accepts when signed in")
+	quota.register(cmd)
 
 	return cmd
 }
 
+// buildShareSnippet resolves the client libraries a container needs to use
+// the share on Linux/arch
+func buildShareSnippet(ctx context.Context, client *api.Client, share *api.ShareInfo, image, arch string) (*studio.ShareSnippet, error) {
+	vendor := strings.ToLower(share.HardwareVendor)
+	depsMgr := deps.NewManager(deps.WithAPIClient(client))
+	libs, err := depsMgr.ResolveLibrariesForPlatform(ctx,
+		[]string{deps.LibraryTypeRemoteGPUClient, deps.LibraryTypeVGPULibrary}, vendor, "linux", arch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve client libraries: %w", err)
+	}
+
+	snippet := &studio.ShareSnippet{
+		ConnectionInfo: share.ConnectionURL + "+" + share.ShortCode,
+		Vendor:         studio.ParseVendor(vendor),
+		Image:          image,
+		Volume:         "ggo-libs-" + vendor,
+	}
+	for _, lib := range libs {
+		snippet.Libraries = append(snippet.Libraries, studio.SnippetLibrary{Name: lib.Name, URL: lib.URL, SHA256: lib.SHA256})
+	}
+	return snippet, nil
+}
+
 // shareCreateResult implements Renderable for share create
 type shareCreateResult struct {
 	share *api.ShareInfo
+	// snippet is the --for-studio container snippet, if requested
+	snippet string
 }
 
 func (r *shareCreateResult) RenderJSON() any {
-	return r.share
+	if r.snippet == "" {
+		return r.share
+	}
+	return struct {
+		*api.ShareInfo
+		Snippet string `json:"snippet"`
+	}{r.share, r.snippet}
 }
 
 func (r *shareCreateResult) RenderTUI(out *tui.Output) {
@@ -205,27 +312,49 @@ func (r *shareCreateResult) RenderTUI(out *tui.Output) {
 		Add("Worker ID", r.share.WorkerID).
 		Add("Connection URL", r.share.ConnectionURL)
 
+	if r.share.Alias != "" {
+		status.Add("Alias", styles.Bold.Render(r.share.Alias))
+	}
+	if r.share.Relay {
+		status.Add("Route", "via relay")
+	}
 	if r.share.ExpiresAt != nil {
 		status.Add("Expires At", r.share.ExpiresAt.Format("2006-01-02 15:04:05"))
 	}
 	if r.share.MaxUses != nil {
 		status.Add("Max Uses", fmt.Sprintf("%d", *r.share.MaxUses))
 	}
+	if n := r.share.Notifications; n != nil {
+		status.Add("Notifications", formatNotifications(n))
+	}
+	if !r.share.Quota.IsZero() {
+		status.Add("Quota", formatQuota(r.share.Quota))
+	}
 
 	out.Println(status.String())
 
 	out.Println()
-	out.Println(styles.Subtitle.Render("Share this with others:"))
+	out.Println(styles.Subtitle.Render(i18n.T("Share this with others:")))
 	out.Println()
 	out.Println("  " + tui.Code(fmt.Sprintf("ggo use %s", r.share.ShortCode)))
 	out.Println()
+	if r.share.Alias != "" {
+		out.Println(styles.Muted.Render(i18n.Tf("  Signed in to your account, 'ggo use %s' works too.", r.share.Alias)))
+		out.Println()
+	}
+
+	if r.snippet != "" {
+		out.Println(styles.Subtitle.Render(i18n.T("Or, for users without ggo:")))
+		out.Println()
+		out.Println(r.snippet)
+	}
 }
 
 func newShareListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all share links",
-		Long:  `List all share links for the current user.`,
+		Long:  `List all share links for the current user, with the aliases given to them.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := getClient()
 			ctx := context.Background()
@@ -267,16 +396,21 @@ func (r *shareListResult) RenderTUI(out *tui.Output) {
 		if s.MaxUses != nil {
 			maxStr = fmt.Sprintf("%d", *s.MaxUses)
 		}
-		expiresStr := styles.Muted.Render("never")
+		expiresStr := styles.Muted.Render(i18n.T("never"))
 		if s.ExpiresAt != nil {
 			if s.ExpiresAt.Before(time.Now()) {
-				expiresStr = styles.Error.Render("expired")
+				expiresStr = styles.Error.Render(i18n.T("expired"))
 			} else {
 				expiresStr = s.ExpiresAt.Format("2006-01-02")
 			}
 		}
+		alias := styles.Muted.Render("-")
+		if s.Alias != "" {
+			alias = s.Alias
+		}
 		rows = append(rows, []string{
 			styles.Bold.Render(s.ShortCode),
+			alias,
 			tui.URL(s.ShortLink),
 			fmt.Sprintf("%d", s.UsedCount),
 			maxStr,
@@ -285,7 +419,7 @@ func (r *shareListResult) RenderTUI(out *tui.Output) {
 	}
 
 	table := tui.NewTable().
-		Headers("SHORT CODE", "SHORT LINK", "USED", "MAX", "EXPIRES").
+		Headers("SHORT CODE", "ALIAS", "SHORT LINK", "USED", "MAX", "EXPIRES").
 		Rows(rows)
 
 	out.Println(table.String())
@@ -298,7 +432,7 @@ func newShareGetCmd() *cobra.Command {
 		Long:  `Get public information about a share link using its short code or full link.`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			shortCode := extractShortCode(args[0])
+			shortCode := cmdutil.ExtractShortCode(args[0])
 			client := getClient()
 			ctx := context.Background()
 			out := getOutput()
@@ -310,7 +444,11 @@ func newShareGetCmd() *cobra.Command {
 				return err
 			}
 
-			return out.Render(&shareDetailResult{share: resp})
+			latency, latencyErr := cmdutil.ShareLatency(ctx, resp)
+			if latencyErr != nil {
+				klog.V(2).Infof("Failed to measure share latency: short_code=%s error=%v", shortCode, latencyErr)
+			}
+			return out.Render(&shareDetailResult{share: resp, latency: latency, latencyErr: latencyErr})
 		},
 	}
 
@@ -319,24 +457,48 @@ func newShareGetCmd() *cobra.Command {
 
 // shareDetailResult implements Renderable for share detail
 type shareDetailResult struct {
-	share *api.SharePublicInfo
+	share      *api.SharePublicInfo
+	latency    time.Duration
+	latencyErr error
+}
+
+// shareDetail is the JSON form of share details with the measured latency
+type shareDetail struct {
+	*api.SharePublicInfo
+	LatencyMs *int64 `json:"latency_ms,omitempty"` // unset when unreachable
 }
 
 func (r *shareDetailResult) RenderJSON() any {
-	return tui.NewDetailResult(r.share)
+	detail := shareDetail{SharePublicInfo: r.share}
+	if r.latencyErr == nil {
+		ms := r.latency.Milliseconds()
+		detail.LatencyMs = &ms
+	}
+	return tui.NewDetailResult(detail)
 }
 
 func (r *shareDetailResult) RenderTUI(out *tui.Output) {
 	styles := tui.DefaultStyles()
 
 	out.Println()
-	out.Println(styles.Title.Render("Share Details"))
+	out.Println(styles.Title.Render(i18n.T("Share Details")))
 	out.Println()
 
 	status := tui.NewStatusTable().
 		Add("Worker ID", r.share.WorkerID).
 		Add("Hardware Vendor", r.share.HardwareVendor).
-		Add("Connection URL", tui.URL(r.share.ConnectionURL))
+		Add("Connection URL", tui.URL(r.share.ConnectionURL)).
+		Add("Latency", cmdutil.FormatShareRoute(r.share, r.latency, r.latencyErr))
+	if r.share.Queue != nil {
+		status.Add("Clients", cmdutil.FormatShareQueue(r.share.Queue))
+	}
+	if !r.share.Fairness.IsZero() {
+		maxClients := 0
+		if r.share.Queue != nil {
+			maxClients = r.share.Queue.MaxConnections
+		}
+		status.Add("Client Slice", cmdutil.FormatFairness(r.share.Fairness, maxClients))
+	}
 
 	out.Println(status.String())
 }
@@ -357,7 +519,7 @@ func newShareDeleteCmd() *cobra.Command {
 
 			if !force && !out.IsJSON() {
 				styles := tui.DefaultStyles()
-				fmt.Printf("%s Are you sure you want to delete share %s? [y/N]: ",
+				fmt.Printf(i18n.T("%s Are you sure you want to delete share %s? [y/N]: "),
 					styles.Warning.Render("!"),
 					styles.Bold.Render(shareID))
 				var confirm string
@@ -376,7 +538,8 @@ func newShareDeleteCmd() *cobra.Command {
 
 			return out.Render(&cmdutil.ActionData{
 				Success: true,
-				Message: fmt.Sprintf("Share %s deleted successfully!", shareID),
+				Message: "Share %s deleted successfully!",
+				Args:    []any{shareID},
 				ID:      shareID,
 			})
 		},
@@ -386,3 +549,245 @@ func newShareDeleteCmd() *cobra.Command {
 
 	return cmd
 }
+
+// buildNotifications validates the notification flags. It returns nil when
+// neither a webhook nor an email is given.
+func buildNotifications(webhook, email string, events []string) (*api.ShareNotifications, error) {
+	if webhook == "" && email == "" {
+		if len(events) > 0 {
+			return nil, fmt.Errorf("--notify-on requires a webhook or email target")
+		}
+		return nil, nil
+	}
+
+	if webhook != "" {
+		u, err := url.Parse(webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, cmdutil.UsageErrorf("invalid webhook URL %q: must be an http(s) URL", webhook)
+		}
+	}
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, cmdutil.UsageErrorf("invalid email address %q", email)
+		}
+	}
+
+	if len(events) == 0 {
+		events = []string{api.ShareEventFirstUse, api.ShareEventExhausted}
+	}
+	normalized := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(e)), "-", "_")
+		if e != api.ShareEventFirstUse && e != api.ShareEventExhausted {
+			return nil, fmt.Errorf("unknown notification event %q (valid: first-use, exhausted)", e)
+		}
+		normalized = append(normalized, e)
+	}
+
+	return &api.ShareNotifications{WebhookURL: webhook, Email: email, Events: normalized}, nil
+}
+
+// formatNotifications describes notification settings in one line
+func formatNotifications(n *api.ShareNotifications) string {
+	var targets []string
+	if n.WebhookURL != "" {
+		targets = append(targets, n.WebhookURL)
+	}
+	if n.Email != "" {
+		targets = append(targets, n.Email)
+	}
+	if len(targets) == 0 {
+		return tui.Muted("off")
+	}
+	events := make([]string, len(n.Events))
+	for i, e := range n.Events {
+		events[i] = strings.ReplaceAll(e, "_", "-")
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(targets, ", "), strings.Join(events, ", "))
+}
+
+// findShare resolves a share ID, short code, short link or alias among the
+// user's shares
+func findShare(ctx context.Context, client *api.Client, ref string) (*api.ShareInfo, error) {
+	code := cmdutil.ExtractShortCode(ref)
+	resp, err := client.ListShares(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Shares {
+		if resp.Shares[i].ShareID == ref || resp.Shares[i].ShortCode == code || (resp.Shares[i].Alias != "" && resp.Shares[i].Alias == ref) {
+			return &resp.Shares[i], nil
+		}
+	}
+	return nil, cmdutil.NotFoundErrorf("share %q not found among your shares", ref)
+}
+
+func newShareInspectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inspect <share-id|short-link|alias>",
+		Short: "Show a share link and who has used it",
+		Long: `Show details of one of your share links, including its notification settings,
+its consumption against its quota and the machines that have resolved it with
+'ggo use' or 'ggo studio create -s'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := getClient()
+			ctx := context.Background()
+			out := getOutput()
+
+			share, err := findShare(ctx, client, args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to find share: error=%v", err)
+				return err
+			}
+
+			resp, err := client.ListShareConsumers(ctx, share.ShareID)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to list share consumers: share_id=%s error=%v", share.ShareID, err)
+				return err
+			}
+
+			return out.Render(&shareInspectResult{share: share, consumers: resp.Consumers})
+		},
+	}
+
+	return cmd
+}
+
+// shareInspectResult implements Renderable for share inspect
+type shareInspectResult struct {
+	share     *api.ShareInfo
+	consumers []api.ShareConsumer
+}
+
+func (r *shareInspectResult) RenderJSON() any {
+	return tui.NewDetailResult(struct {
+		*api.ShareInfo
+		Consumers []api.ShareConsumer `json:"consumers"`
+	}{r.share, r.consumers})
+}
+
+func (r *shareInspectResult) RenderTUI(out *tui.Output) {
+	styles := tui.DefaultStyles()
+
+	out.Println()
+	out.Println(styles.Title.Render(i18n.Tf("Share %s", r.share.ShortCode)))
+	out.Println()
+
+	maxStr := "∞"
+	if r.share.MaxUses != nil {
+		maxStr = fmt.Sprintf("%d", *r.share.MaxUses)
+	}
+	notifications := tui.Muted("off")
+	if r.share.Notifications != nil {
+		notifications = formatNotifications(r.share.Notifications)
+	}
+	status := tui.NewStatusTable().
+		Add("Share ID", r.share.ShareID).
+		Add("Short Link", tui.URL(r.share.ShortLink)).
+		Add("Worker ID", r.share.WorkerID).
+		Add("Uses", fmt.Sprintf("%d / %s", r.share.UsedCount, maxStr)).
+		Add("Notifications", notifications)
+	addQuotaUsage(status, r.share)
+	out.Println(status.String())
+	out.Println()
+
+	if len(r.consumers) == 0 {
+		out.Info("No consumers registered yet")
+		return
+	}
+
+	var rows [][]string
+	for _, c := range r.consumers {
+		osArch := c.OS
+		if c.Arch != "" {
+			osArch += "/" + c.Arch
+		}
+		rows = append(rows, []string{
+			styles.Bold.Render(c.Hostname),
+			osArch,
+			c.ClientVersion,
+			c.Via,
+			fmt.Sprintf("%d", c.Uses),
+			c.FirstSeenAt.Format("2006-01-02 15:04"),
+			c.LastSeenAt.Format("2006-01-02 15:04"),
+		})
+	}
+
+	out.Println(styles.Subtitle.Render(i18n.T("Consumers")))
+	table := tui.NewTable().
+		Headers("HOSTNAME", "PLATFORM", "VERSION", "VIA", "USES", "FIRST SEEN", "LAST SEEN").
+		Rows(rows)
+	out.Println(table.String())
+}
+
+func newShareNotifyCmd() *cobra.Command {
+	var webhook string
+	var email string
+	var on []string
+	var off bool
+
+	cmd := &cobra.Command{
+		Use:   "notify <share-id|short-link|alias>",
+		Short: "Configure notifications for a share link",
+		Long: `Get notified by webhook or email when a share link is first used or
+reaches its max uses. Use --off to turn notifications off.`,
+		Example: `  # Post to a webhook on first use and when exhausted
+  ggo share notify abc123 --webhook https://hooks.example.com/gpu
+
+  # Email only when the share is exhausted
+  ggo share notify abc123 --email me@example.com --on exhausted
+
+  # Turn notifications off
+  ggo share notify abc123 --off`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := getClient()
+			ctx := context.Background()
+			out := getOutput()
+
+			notifications := &api.ShareNotifications{}
+			if !off {
+				n, err := buildNotifications(webhook, email, on)
+				if err != nil {
+					return err
+				}
+				if n == nil {
+					return fmt.Errorf("specify --webhook and/or --email, or --off")
+				}
+				notifications = n
+			} else if webhook != "" || email != "" || len(on) > 0 {
+				return cmdutil.UsageErrorf("--off cannot be combined with --webhook, --email or --on")
+			}
+
+			share, err := findShare(ctx, client, args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to find share: error=%v", err)
+				return err
+			}
+
+			if _, err := client.UpdateShare(ctx, share.ShareID, &api.ShareUpdateRequest{Notifications: notifications}); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to update share notifications: share_id=%s error=%v", share.ShareID, err)
+				return err
+			}
+
+			message := fmt.Sprintf("Notifications for share %s: %s", share.ShortCode, formatNotifications(notifications))
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: message,
+				ID:      share.ShareID,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&webhook, "webhook", "", "Webhook URL to notify")
+	cmd.Flags().StringVar(&email, "email", "", "Email address to notify")
+	cmd.Flags().StringSliceVar(&on, "on", nil, "Events to notify about (first-use, exhausted; default: both)")
+	cmd.Flags().BoolVar(&off, "off", false, "Turn notifications off")
+
+	return cmd
+}
@@ -0,0 +1,189 @@
+package share
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// maxSparklineWidth is the widest trend drawn; adjacent buckets are merged
+// beyond it
+const maxSparklineWidth = 60
+
+// sparkLevels are the bar heights of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+func newShareStatsCmd() *cobra.Command {
+	var since string
+
+	cmd := &cobra.Command{
+		Use:   "stats <share-id|short-link|alias>",
+		Short: "Show how much a share link has been used",
+		Long: `Show the consumption of one of your share links as aggregated by the platform:
+sessions, unique consumers, GPU-hours, data transferred and the share of
+sessions that failed, in total and as a trend over the period, to decide
+which shares to revoke ('ggo share delete') or to give more room
+('ggo share quota').
+
+Data transferred is only counted for agents running the connection proxy.`,
+		Example: `  # The last 7 days
+  ggo share stats abc123
+
+  # The last 30 days, as JSON
+  ggo share stats abc123 --since 30d -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := getClient()
+			ctx := context.Background()
+			out := getOutput()
+
+			age, err := cmdutil.ParseAge(since)
+			if err != nil {
+				return cmdutil.UsageErrorf("invalid --since %q: %w", since, err)
+			}
+
+			share, err := findShare(ctx, client, args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to find share: error=%v", err)
+				return err
+			}
+
+			usage, err := client.GetShareConsumption(ctx, share.ShareID, time.Now().Add(-age))
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to get share consumption: share_id=%s error=%v", share.ShareID, err)
+				return err
+			}
+
+			return out.Render(&shareStatsResult{share: share, usage: usage})
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "7d", "Period to show, back from now (e.g. 24h, 7d, 30d)")
+
+	return cmd
+}
+
+// shareStatsResult implements Renderable for share stats
+type shareStatsResult struct {
+	share *api.ShareInfo
+	usage *api.ShareConsumption
+}
+
+func (r *shareStatsResult) RenderJSON() any {
+	return tui.NewDetailResult(struct {
+		ShortCode string `json:"short_code"`
+		*api.ShareConsumption
+		ErrorRate float64 `json:"error_rate"`
+	}{r.share.ShortCode, r.usage, r.usage.Totals.ErrorRate()})
+}
+
+func (r *shareStatsResult) RenderTUI(out *tui.Output) {
+	styles := tui.DefaultStyles()
+	totals := r.usage.Totals
+
+	out.Println()
+	out.Println(styles.Title.Render(i18n.Tf("Share %s", r.share.ShortCode)))
+	out.Println()
+
+	if totals.Sessions == 0 {
+		out.Info("No sessions in this period")
+		return
+	}
+
+	buckets, width := mergeBuckets(r.usage.Buckets, time.Duration(r.usage.BucketSeconds)*time.Second, maxSparklineWidth)
+	trend := func(value func(c *api.ShareConsumptionCounts) float64) string {
+		values := make([]float64, len(buckets))
+		for i := range buckets {
+			values[i] = value(&buckets[i].ShareConsumptionCounts)
+		}
+		return styles.Info.Render(sparkline(values))
+	}
+
+	errorRate := fmt.Sprintf("%.1f%%", totals.ErrorRate()*100)
+	if totals.ErrorRate() >= 0.1 {
+		errorRate = styles.Warning.Render(errorRate)
+	}
+	rows := [][]string{
+		{i18n.T("Sessions"), fmt.Sprintf("%d", totals.Sessions),
+			trend(func(c *api.ShareConsumptionCounts) float64 { return float64(c.Sessions) })},
+		{i18n.T("Unique consumers"), fmt.Sprintf("%d", totals.UniqueConsumers),
+			trend(func(c *api.ShareConsumptionCounts) float64 { return float64(c.UniqueConsumers) })},
+		{i18n.T("GPU-hours"), formatGPUHours(totals.GPUHours),
+			trend(func(c *api.ShareConsumptionCounts) float64 { return c.GPUHours })},
+		{i18n.T("Data transferred"), cmdutil.FormatBytes(totals.BytesTransferred),
+			trend(func(c *api.ShareConsumptionCounts) float64 { return float64(c.BytesTransferred) })},
+		{i18n.T("Error rate"), errorRate,
+			trend(func(c *api.ShareConsumptionCounts) float64 { return c.ErrorRate() })},
+	}
+	table := tui.NewTable().
+		Headers("METRIC", "TOTAL", "TREND").
+		Rows(rows)
+	out.Println(table.String())
+
+	period := i18n.Tf("%s to %s", r.usage.Since.Local().Format("2006-01-02 15:04"), r.usage.Until.Local().Format("2006-01-02 15:04"))
+	if width > 0 {
+		period += " · " + i18n.Tf("one bar per %s", formatBucket(width))
+	}
+	out.Println(styles.Muted.Render(period))
+}
+
+// mergeBuckets merges adjacent buckets so that at most maxBuckets remain,
+// returning them with their width. Unique consumers of merged buckets are
+// the largest of them, as consumers cannot be told apart across buckets.
+func mergeBuckets(buckets []api.ShareConsumptionBucket, width time.Duration, maxBuckets int) ([]api.ShareConsumptionBucket, time.Duration) {
+	if len(buckets) <= maxBuckets {
+		return buckets, width
+	}
+	n := (len(buckets) + maxBuckets - 1) / maxBuckets
+	merged := make([]api.ShareConsumptionBucket, 0, maxBuckets)
+	for i := 0; i < len(buckets); i += n {
+		m := api.ShareConsumptionBucket{Start: buckets[i].Start}
+		for _, b := range buckets[i:min(i+n, len(buckets))] {
+			m.Sessions += b.Sessions
+			m.UniqueConsumers = max(m.UniqueConsumers, b.UniqueConsumers)
+			m.GPUHours += b.GPUHours
+			m.BytesTransferred += b.BytesTransferred
+			m.FailedSessions += b.FailedSessions
+		}
+		merged = append(merged, m)
+	}
+	return merged, width * time.Duration(n)
+}
+
+// sparkline draws values as a row of bars scaled to the largest. Zero is
+// the lowest bar and any other value is drawn above it.
+func sparkline(values []float64) string {
+	var peak float64
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if v > 0 && peak > 0 {
+			level = 1 + int(math.Round(v/peak*float64(len(sparkLevels)-2)))
+		}
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
+
+// formatBucket prints a bucket width as e.g. 15m, 6h or 1d
+func formatBucket(width time.Duration) string {
+	const day = 24 * time.Hour
+	if width >= day && width%day == 0 {
+		return fmt.Sprintf("%dd", width/day)
+	}
+	return formatMinutes(int(width / time.Minute))
+}
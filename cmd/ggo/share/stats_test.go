@@ -0,0 +1,47 @@
+package share
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▂▅█", sparkline([]float64{0, 0.1, 5, 10}))
+	assert.Equal(t, "▁▁▁", sparkline([]float64{0, 0, 0}), "no activity stays flat")
+	assert.Equal(t, "██", sparkline([]float64{3, 3}))
+	assert.Empty(t, sparkline(nil))
+}
+
+func TestMergeBuckets(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	var buckets []api.ShareConsumptionBucket
+	for i := range 5 {
+		buckets = append(buckets, api.ShareConsumptionBucket{
+			Start:                  start.Add(time.Duration(i) * time.Hour),
+			ShareConsumptionCounts: api.ShareConsumptionCounts{Sessions: 2, UniqueConsumers: i + 1, GPUHours: 1, BytesTransferred: 10, FailedSessions: 1},
+		})
+	}
+
+	same, width := mergeBuckets(buckets, time.Hour, 5)
+	assert.Len(t, same, 5)
+	assert.Equal(t, time.Hour, width)
+
+	merged, width := mergeBuckets(buckets, time.Hour, 2)
+	assert.Equal(t, 3*time.Hour, width)
+	require.Len(t, merged, 2)
+	assert.Equal(t, start, merged[0].Start)
+	assert.Equal(t, api.ShareConsumptionCounts{Sessions: 6, UniqueConsumers: 3, GPUHours: 3, BytesTransferred: 30, FailedSessions: 3}, merged[0].ShareConsumptionCounts)
+	assert.Equal(t, start.Add(3*time.Hour), merged[1].Start)
+	assert.Equal(t, 5, merged[1].UniqueConsumers, "consumers are not summed across buckets")
+}
+
+func TestFormatBucket(t *testing.T) {
+	assert.Equal(t, "15m", formatBucket(15*time.Minute))
+	assert.Equal(t, "6h", formatBucket(6*time.Hour))
+	assert.Equal(t, "1d", formatBucket(24*time.Hour))
+	assert.Equal(t, "36h", formatBucket(36*time.Hour))
+}
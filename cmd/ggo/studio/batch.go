@@ -0,0 +1,35 @@
+package studio
+
+import (
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+)
+
+// batchOptions returns options for working on several studios, parallel at
+// once, that print a line as each studio starts and finishes. started is the
+// format of the start line, given the icon and the studio name.
+func batchOptions(out *tui.Output, parallel int, started string) *studio.BatchOptions {
+	styles := tui.DefaultStyles()
+	return &studio.BatchOptions{
+		Concurrency: parallel,
+		OnProgress: func(p studio.BatchProgress) {
+			switch {
+			case !p.Done:
+				out.Printf(started, styles.Info.Render("◐"), styles.Bold.Render(p.Name))
+			case p.Err != nil:
+				out.Printf("%s [%d/%d] %s: %v\n", styles.Error.Render("✗"), p.Finished, p.Total, p.Name, p.Err)
+			default:
+				out.Printf("%s [%d/%d] %s\n", styles.Success.Render("✓"), p.Finished, p.Total, p.Name)
+			}
+		},
+	}
+}
+
+// validateParallel checks the value of a --parallel flag
+func validateParallel(parallel int) error {
+	if parallel < 1 {
+		return cmdutil.UsageErrorf("--parallel must be at least 1, got %d", parallel)
+	}
+	return nil
+}
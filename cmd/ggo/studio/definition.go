@@ -0,0 +1,248 @@
+package studio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+func newExportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export <name>",
+		Short: "Export the definition of a studio environment",
+		Long: `Write the definition of a studio environment as YAML to stdout: its image,
+mode, platform, resources, ports, volumes, environment variables and command,
+the share its remote GPU is used through and the template it was created from.
+'ggo studio import' creates the environment again from it, on this machine or
+another, so definitions can be shared and checked into repositories.
+
+A definition describes how the environment is created, not what it holds:
+files changed inside the container are not part of it, only those on its
+volumes stay where they are. Environment values given as templates, such as
+{{secret "hf"}}, are exported as templates and resolved again on import; other
+values are exported as given. Bind mounts keep their host paths, which may
+need editing for another machine. The studio's SSH key and the GPU
+connection are not exported: the importing machine uses its own key and
+resolves the share again.`,
+		Example: `  # Export a studio to a file
+  ggo studio export my-env > my-env.yaml
+
+  # As JSON
+  ggo studio export my-env -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			def, err := getManager().Definition(args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			data, err := studio.MarshalDefinition(def)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to encode definition: %w", err)
+			}
+			return out.Render(&exportResult{def: def, yaml: data})
+		},
+	}
+}
+
+// exportResult implements Renderable for studio export
+type exportResult struct {
+	def  *studio.Definition
+	yaml []byte
+}
+
+func (r *exportResult) RenderJSON() any {
+	return r.def
+}
+
+func (r *exportResult) RenderTUI(out *tui.Output) {
+	out.Printf("%s", r.yaml)
+}
+
+func newImportCmd() *cobra.Command {
+	var name string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Create a studio environment from an exported definition",
+		Long: `Create a studio environment from a definition written by 'ggo studio export',
+in YAML or JSON; '-' reads it from stdin. The studio is created as with
+'ggo studio create' and the options of the definition: the share is resolved
+again, the GPU client libraries are downloaded, a ggo.lock in the current
+directory fixes their versions, and a new SSH key pair is generated.
+
+The studio takes the definition's name unless --name is given. --mode,
+--share-link, --server and --pull override the definition, e.g. to create a
+studio exported from a Windows machine with Colima on macOS, or with a share
+of your own.
+
+A definition may come from anyone. When it bind mounts host paths, reads
+secrets of your secret store or resolves its share with a server other than
+the default, these are listed and must be confirmed; --yes skips the
+confirmation, which is required when it cannot be asked for, such as when
+reading from stdin.`,
+		Example: `  # Create the studio defined in a file
+  ggo studio import my-env.yaml
+
+  # Under another name, through another share
+  ggo studio import my-env.yaml --name my-env-2 -s def456
+
+  # Copy a studio to another machine
+  ggo studio export my-env | ssh gpu-box ggo studio import - --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			def, err := readDefinition(args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				return cmdutil.UsageError(err)
+			}
+			if name == "" {
+				name = def.Name
+			}
+			if name == "" {
+				return cmdutil.UsageErrorf("the definition has no name; pass --name")
+			}
+			applyDefinition(cmd, def)
+
+			access := def.Access(api.GetDefaultBaseURL())
+			if cmd.Flags().Changed("server") {
+				access.Server = ""
+			}
+			if !yes {
+				out := getOutput()
+				var confirm func(*studio.DefinitionAccess) (bool, error)
+				if !out.IsJSON() && args[0] != "-" && term.IsTerminal(int(os.Stdin.Fd())) {
+					confirm = confirmAccess
+				}
+				if err := studio.ConfirmImport(access, confirm); err != nil {
+					if !errors.Is(err, studio.ErrImportNotConfirmed) {
+						return err
+					}
+					if confirm == nil {
+						return cmdutil.UsageErrorf("the definition %s; review it and pass --yes to import it", describeAccess(access))
+					}
+					out.Info("Cancelled")
+					return nil
+				}
+			}
+			return createStudios(cmd, []string{name}, def)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Name of the new studio (default the definition's)")
+	cmd.Flags().StringVarP(&mode, "mode", "m", "", "Container/VM mode, instead of the definition's")
+	cmd.Flags().StringVarP(&shareLink, "share-link", "s", "", "Share link or share code to use instead of the definition's")
+	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL for resolving share links, instead of the definition's")
+	cmd.Flags().StringVar(&pullPolicy, "pull", "", "Image pull policy, instead of the definition's: never, missing, always")
+	cmd.Flags().BoolVar(&anonymous, "anonymous", false, "Don't register this machine with the share owner")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip the GPU readiness probe after creating (see 'ggo studio verify')")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Import without confirming the host paths, secrets and server the definition uses")
+
+	return cmd
+}
+
+// confirmAccess lists what a definition reaches on this machine and asks
+// whether to create the studio
+func confirmAccess(access *studio.DefinitionAccess) (bool, error) {
+	styles := tui.DefaultStyles()
+	fmt.Println()
+	fmt.Println(styles.Warning.Render(i18n.T("The definition gives the studio access to this machine:")))
+	status := tui.NewStatusTable()
+	if len(access.HostPaths) > 0 {
+		status.Add("Host paths", strings.Join(access.HostPaths, ", "))
+	}
+	if len(access.Secrets) > 0 {
+		status.Add("Secrets", strings.Join(access.Secrets, ", "))
+	}
+	if access.Server != "" {
+		status.Add("Share server", access.Server)
+	}
+	fmt.Println(status.String())
+	return tui.ConfirmPrompt("Create the studio?")
+}
+
+// describeAccess describes what a definition reaches on this machine in one
+// line
+func describeAccess(access *studio.DefinitionAccess) string {
+	var parts []string
+	if len(access.HostPaths) > 0 {
+		parts = append(parts, fmt.Sprintf("bind mounts host paths %s", strings.Join(access.HostPaths, ", ")))
+	}
+	if len(access.Secrets) > 0 {
+		parts = append(parts, fmt.Sprintf("reads secrets %s", strings.Join(access.Secrets, ", ")))
+	}
+	if access.Server != "" {
+		parts = append(parts, fmt.Sprintf("resolves its share with %s", access.Server))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// readDefinition reads a studio definition from path, or from stdin for "-"
+func readDefinition(path string) (*studio.Definition, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read definition: %w", err)
+	}
+	def, err := studio.ParseDefinition(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return def, nil
+}
+
+// applyDefinition sets the create flags from def; the import flags given
+// take precedence
+func applyDefinition(cmd *cobra.Command, def *studio.Definition) {
+	flags := cmd.Flags()
+	if !flags.Changed("mode") {
+		mode = string(def.Mode)
+	}
+	if !flags.Changed("share-link") {
+		shareLink = ""
+		if def.Share != nil {
+			shareLink = def.Share.Code
+		}
+	}
+	if !flags.Changed("server") && def.Share != nil && def.Share.Server != "" {
+		serverURL = def.Share.Server
+	}
+	if !flags.Changed("pull") {
+		pullPolicy = string(def.PullPolicy)
+	}
+
+	image = def.Image
+	platform = def.Platform
+	endpoint = def.Endpoint
+	cpus, memory = def.CPUs, def.Memory
+	ports, volumes, command = def.Ports, def.Volumes, def.Command
+
+	keys := make([]string, 0, len(def.Env))
+	for k := range def.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	envVars = make([]string, 0, len(keys))
+	for _, k := range keys {
+		envVars = append(envVars, k+"="+def.Env[k])
+	}
+}
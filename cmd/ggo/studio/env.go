@@ -0,0 +1,127 @@
+package studio
+
+import (
+	"context"
+	"sort"
+
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newEnvCmd() *cobra.Command {
+	var set []string
+	var unset []string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "env <name>",
+		Short: "Show or change the GPU environment of a studio",
+		Long: `Show the GPU-related environment of a studio as SSH and VS Code sessions
+see it: the GPU worker connection, LD_PRELOAD and LD_LIBRARY_PATH, TF_* limiter
+and logging settings and device visibility. The SOURCE column tells whether a
+variable was set on the container when it was created or in /etc/environment,
+which sessions read and which overrides the container.
+
+--set and --unset persist changes in /etc/environment and restart the studio
+so they take effect. A stopped studio is started for the change and stopped
+again. Variables set on the container itself cannot be removed; --unset
+blanks them for sessions instead.`,
+		Example: `  # Show the effective GPU environment
+  ggo studio env my-env
+
+  # Raise the log level and drop a preload library
+  ggo studio env my-env --set TF_LOG_LEVEL=debug --unset LD_PRELOAD
+
+  # Point the studio at another worker
+  ggo studio env my-env --set TENSOR_FUSION_OPERATOR_CONNECTION_INFO=native+10.0.0.5+9001+abc`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			mgr := getManager()
+			if jsonOutput {
+				outputFormat = "json"
+			}
+			out := getOutput()
+
+			setMap, err := parseEnvVars(set)
+			if err != nil {
+				return err
+			}
+			changes := &studio.EnvChanges{Set: setMap, Unset: unset}
+			if err := changes.Validate(); err != nil {
+				return err
+			}
+
+			if changes.IsEmpty() {
+				vars, err := mgr.Env(ctx, args[0])
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to read studio environment: name=%s error=%v", args[0], err)
+					return err
+				}
+				return out.Render(&envVarsResult{name: args[0], vars: vars})
+			}
+
+			vars, err := mgr.UpdateEnv(ctx, args[0], changes)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to update studio environment: name=%s error=%v", args[0], err)
+				return err
+			}
+			return out.Render(&envVarsResult{name: args[0], vars: vars, changes: changes})
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&set, "set", nil, "Set a variable (KEY=VALUE, repeatable)")
+	cmd.Flags().StringArrayVar(&unset, "unset", nil, "Remove a variable (repeatable); a variable set on the container is set to empty instead, as it cannot be removed")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output JSON (same as -o json)")
+	return cmd
+}
+
+// envVarsResult implements Renderable for the env command
+type envVarsResult struct {
+	name    string
+	vars    []studio.EnvVar
+	changes *studio.EnvChanges
+}
+
+type envVarsJSON struct {
+	Name    string             `json:"name"`
+	Env     []studio.EnvVar    `json:"env"`
+	Changes *studio.EnvChanges `json:"changes,omitempty"`
+}
+
+func (r *envVarsResult) RenderJSON() any {
+	return envVarsJSON{Name: r.name, Env: r.vars, Changes: r.changes}
+}
+
+func (r *envVarsResult) RenderTUI(out *tui.Output) {
+	styles := tui.DefaultStyles()
+	if r.changes != nil {
+		keys := make([]string, 0, len(r.changes.Set))
+		for k := range r.changes.Set {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out.Printf("%s Set %s\n", styles.Success.Render("●"), k)
+		}
+		for _, k := range r.changes.Unset {
+			out.Printf("%s Unset %s\n", styles.Success.Render("●"), k)
+		}
+		out.Successf("Environment '%s' updated and restarted", r.name)
+		out.Println()
+	}
+
+	if len(r.vars) == 0 {
+		out.Infof("No GPU environment variables set in '%s'", r.name)
+		return
+	}
+	var rows [][]string
+	for _, v := range r.vars {
+		rows = append(rows, []string{styles.Bold.Render(v.Key), v.Value, styles.Muted.Render(string(v.Source))})
+	}
+	out.Println(tui.NewTable().Headers("KEY", "VALUE", "SOURCE").Rows(rows).String())
+}
@@ -0,0 +1,333 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/agent"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	ggoplatform "github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// envConnectionInfo holds the GPU worker URL inside a studio
+	envConnectionInfo = "TENSOR_FUSION_OPERATOR_CONNECTION_INFO"
+	loadBarWidth      = 20
+)
+
+func newGPUTopCmd() *cobra.Command {
+	var watch bool
+	var interval time.Duration
+	var stateDir string
+
+	cmd := &cobra.Command{
+		Use:   "gpu-top [name]",
+		Short: "Show the load of a studio's remote GPU and of the link to it",
+		Long: `Show the utilization and memory of the remote GPU worker a studio uses, the
+sessions the studio opened on it and the throughput of the link, to tell
+whether slow GPU work is held back by the GPU or by the network.
+
+The GPU load comes from the agent on this machine when it runs the worker,
+and otherwise from the platform. Link throughput needs the worker's agent to
+run the connection proxy (ggo agent start --proxy) and is measured between
+two samples, so it shows up with --watch. Inside a studio the name can be
+left out.`,
+		Example: `  # Keep refreshing the GPU load of a studio
+  ggo studio gpu-top my-env --watch
+
+  # From inside the studio
+  ggo studio gpu-top -w
+
+  # One sample as JSON for scripts
+  ggo studio gpu-top my-env -o json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			sampler, err := newGPUTopSampler(ctx, args, stateDir)
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+
+			if !watch {
+				return out.Render(&gpuTopResult{sample: sampler.sample(ctx, nil)})
+			}
+			if interval <= 0 {
+				return cmdutil.UsageErrorf("--interval must be positive")
+			}
+			if !out.IsJSON() {
+				fmt.Print(ansiHideCursor)
+				defer fmt.Print(ansiShowCursor)
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			var prev *studio.GPUTopSample
+			for {
+				sample := sampler.sample(ctx, prev)
+				if ctx.Err() != nil {
+					return nil
+				}
+				if !out.IsJSON() {
+					fmt.Print(ansiClearScreen)
+				}
+				if err := out.Render(&gpuTopResult{sample: sample, watching: true}); err != nil {
+					return err
+				}
+				prev = sample
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Keep refreshing until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Refresh interval for --watch")
+	cmd.Flags().StringVar(&stateDir, "state-dir", config.NewManager("", "").StateDir(), "State directory of the agent on this machine")
+	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL for the GPU load of shared workers")
+
+	return cmd
+}
+
+// gpuTopSampler samples the GPU worker of one studio
+type gpuTopSampler struct {
+	client    *api.Client
+	paths     *ggoplatform.Paths
+	name      string
+	workerURL string
+	shareCode string
+	// workerID is resolved from the share once, to find the worker in the
+	// live status of the agent on this machine
+	workerID string
+}
+
+// newGPUTopSampler finds the GPU worker of the named studio or, without a
+// name, of the studio ggo runs in
+func newGPUTopSampler(ctx context.Context, args []string, stateDir string) (*gpuTopSampler, error) {
+	s := &gpuTopSampler{
+		client: api.NewClient(api.WithBaseURL(serverURL)),
+		paths:  ggoplatform.DefaultPaths().WithStateDir(stateDir),
+	}
+	if len(args) == 0 {
+		s.workerURL = os.Getenv(envConnectionInfo)
+		if s.workerURL == "" {
+			return nil, fmt.Errorf("give the name of a studio, or run gpu-top inside one")
+		}
+	} else {
+		env, err := getManager().Get(ctx, args[0])
+		if err != nil {
+			klog.Errorf("Failed to get studio: name=%s error=%v", args[0], err)
+			return nil, err
+		}
+		if env.GPUWorkerURL == "" {
+			return nil, fmt.Errorf("studio %s does not use a remote GPU worker", env.Name)
+		}
+		s.name, s.workerURL = env.Name, env.GPUWorkerURL
+	}
+
+	s.shareCode = studio.ShareCodeFromWorkerURL(s.workerURL)
+	if s.shareCode != "" {
+		if info, err := s.client.GetSharePublic(ctx, s.shareCode); err != nil {
+			klog.Warningf("Failed to resolve share, the agent on this machine is not asked: share=%s error=%v", s.shareCode, err)
+		} else {
+			s.workerID = info.WorkerID
+		}
+	}
+	return s, nil
+}
+
+// sample measures the link and the GPU load once; prev is the previous
+// sample, from which the link throughput is measured
+func (s *gpuTopSampler) sample(ctx context.Context, prev *studio.GPUTopSample) *studio.GPUTopSample {
+	now := time.Now()
+	sample := &studio.GPUTopSample{Studio: s.name, WorkerID: s.workerID, SampledAt: now}
+	sample.Link.GPUConnection = *studio.ProbeGPUWorker(ctx, s.workerURL)
+
+	switch {
+	case s.shareCode == "":
+		sample.Error = "the studio was not created from a share link; only the link is measured"
+	case s.workerID != "" && s.sampleLocal(sample, now):
+	default:
+		s.samplePlatform(ctx, sample)
+	}
+	sample.Update(prev)
+	return sample
+}
+
+// sampleLocal takes the GPU load from the agent on this machine, if it runs
+// the worker
+func (s *gpuTopSampler) sampleLocal(sample *studio.GPUTopSample, now time.Time) bool {
+	gpus, sessions, ok := agent.LiveWorkerLoad(s.paths, s.workerID, now)
+	if !ok {
+		return false
+	}
+	sample.Source = studio.GPUTopSourceAgent
+	sample.GPUs = gpus
+	sample.SetSessions(sessions, s.shareCode)
+	return true
+}
+
+// samplePlatform takes the GPU load from the platform's share stats
+func (s *gpuTopSampler) samplePlatform(ctx context.Context, sample *studio.GPUTopSample) {
+	stats, err := s.client.GetShareStats(ctx, s.shareCode)
+	switch {
+	case api.IsNotFound(err):
+		sample.Error = "the server does not report the load of shared workers; only the link is measured"
+		return
+	case err != nil:
+		klog.Warningf("Failed to get share stats: share=%s error=%v", s.shareCode, err)
+		sample.Error = err.Error()
+		return
+	}
+	sample.Source = studio.GPUTopSourcePlatform
+	sample.WorkerID = stats.WorkerID
+	sample.GPUs = stats.GPUs
+	sample.SessionVRAMUsedMb = stats.SessionVRAMUsedMb
+	sample.SetSessions(stats.Sessions, s.shareCode)
+}
+
+// gpuTopResult implements Renderable for gpu-top
+type gpuTopResult struct {
+	sample   *studio.GPUTopSample
+	watching bool
+}
+
+func (r *gpuTopResult) RenderJSON() any {
+	return r.sample
+}
+
+func (r *gpuTopResult) RenderTUI(out *tui.Output) {
+	styles := tui.DefaultStyles()
+	s := r.sample
+
+	title := i18n.T("Remote GPU")
+	if s.Studio != "" {
+		title += " · " + s.Studio
+	}
+	if s.WorkerID != "" {
+		title += " → " + s.WorkerID
+	}
+	out.Println(styles.Title.Render(title))
+	if s.Source != "" {
+		out.Println(tui.Muted(i18n.Tf("via %s", s.Source)))
+	}
+	out.Println()
+
+	if len(s.GPUs) > 0 {
+		rows := make([][]string, 0, len(s.GPUs))
+		for _, g := range s.GPUs {
+			vram := "-"
+			if g.VRAMTotalMb > 0 {
+				vram = fmt.Sprintf("%s %d/%d MiB", loadBar(float64(g.VRAMUsedMb)*100/float64(g.VRAMTotalMb), styles),
+					g.VRAMUsedMb, g.VRAMTotalMb)
+			}
+			temp := "-"
+			if g.Temperature > 0 {
+				temp = fmt.Sprintf("%.0f°C", g.Temperature)
+			}
+			rows = append(rows, []string{g.GPUID, fmt.Sprintf("%s %5.1f%%", loadBar(g.Utilization, styles), g.Utilization), vram, temp})
+		}
+		out.Println(tui.NewTable().Headers("GPU", "UTIL", "VRAM", "TEMP").Rows(rows).String())
+		out.Println()
+	}
+
+	status := tui.NewStatusTable().
+		Add("Link", formatGPULink(&s.Link, styles)).
+		Add("Sessions", formatGPUSessions(s)).
+		Add("Bottleneck", formatBottleneck(s, styles))
+	out.Println(status.String())
+
+	if s.Error != "" {
+		out.Println()
+		out.Warning(s.Error)
+	}
+	if r.watching {
+		out.Println()
+		out.Println(styles.Muted.Render(i18n.Tf("Updated %s · Ctrl+C to exit", s.SampledAt.Format(time.TimeOnly))))
+	}
+}
+
+// formatGPULink describes the reachability and throughput of the link
+func formatGPULink(link *studio.GPULink, styles *tui.Styles) string {
+	if !link.Reachable {
+		if link.Addr == "" {
+			return styles.Error.Render(link.Error)
+		}
+		return styles.Error.Render(link.Addr + " unreachable")
+	}
+	parts := []string{fmt.Sprintf("%s %.1fms", link.Addr, link.LatencyMs)}
+	switch {
+	case link.Rates != nil:
+		parts = append(parts, fmt.Sprintf("↑ %s/s ↓ %s/s",
+			cmdutil.FormatBytes(int64(link.Rates.SendBytesPerSec)), cmdutil.FormatBytes(int64(link.Rates.ReceiveBytesPerSec))))
+	case link.Accounted:
+		parts = append(parts, fmt.Sprintf("↑ %s ↓ %s", cmdutil.FormatBytes(link.SentBytes), cmdutil.FormatBytes(link.ReceivedBytes)))
+	default:
+		parts = append(parts, i18n.T("traffic not accounted"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func formatGPUSessions(s *studio.GPUTopSample) string {
+	sessions := fmt.Sprintf("%d", s.Sessions)
+	if s.SessionVRAMUsedMb > 0 {
+		sessions += fmt.Sprintf(" · %d MiB VRAM", s.SessionVRAMUsedMb)
+	}
+	return sessions
+}
+
+// formatBottleneck explains what holds back the studio's GPU work
+func formatBottleneck(s *studio.GPUTopSample, styles *tui.Styles) string {
+	switch s.Bottleneck {
+	case studio.BottleneckNetwork:
+		if !s.Link.Reachable {
+			return styles.Error.Render(i18n.T("Network: the GPU worker is unreachable"))
+		}
+		return styles.Warning.Render(i18n.T("Network bound: the GPU waits on the link to it"))
+	case studio.BottleneckGPU:
+		return styles.Warning.Render(i18n.T("GPU bound: the remote GPU is saturated"))
+	case studio.BottleneckIdle:
+		return styles.Muted.Render(i18n.T("Idle: the studio is not using the GPU"))
+	case studio.BottleneckNone:
+		return styles.Success.Render(i18n.T("Neither the GPU nor the link is saturated"))
+	default:
+		return styles.Muted.Render(i18n.T("Not enough data yet"))
+	}
+}
+
+// loadBar draws a percentage as a bar, colored by how close it is to full
+func loadBar(pct float64, styles *tui.Styles) string {
+	pct = max(0, min(100, pct))
+	filled := int(pct/100*loadBarWidth + 0.5)
+
+	style := styles.Success
+	switch {
+	case pct >= 90:
+		style = styles.Error
+	case pct >= 70:
+		style = styles.Warning
+	}
+	return style.Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(tui.DefaultTheme().TextDim).Render(strings.Repeat("░", loadBarWidth-filled))
+}
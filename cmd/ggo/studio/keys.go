@@ -0,0 +1,62 @@
+package studio
+
+import (
+	"context"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	ggoplatform "github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newKeysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "keys",
+		Aliases: []string{"key"},
+		Short:   "Manage the SSH keys of studios",
+		Long: `Every studio created without --ssh-key gets an SSH key pair of its own,
+stored in ~/.gpugo/studio/<name>/keys. Its public key is authorized in the
+studio at create and its ~/.ssh/config entry (ggo-<name>) uses the private
+key, so 'ssh ggo-<name>', 'ggo studio ssh' and VS Code connect without any
+key setup. The key pair is removed with the studio.`,
+	}
+	cmd.AddCommand(cmdutil.Audited(newKeysRotateCmd()))
+	return cmd
+}
+
+func newKeysRotateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rotate <name>",
+		Short: "Replace the SSH key pair of a studio",
+		Long: `Generate a new SSH key pair for a studio, authorize it in the studio in
+place of the old key and point the studio's ~/.ssh/config entry at it. The
+old key no longer gets in. A studio created with --ssh-key moves to a key
+pair of its own. A stopped studio is started for the change and stopped
+again.`,
+		Example: `  ggo studio keys rotate my-env`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			cmd.SilenceUsage = true
+			mgr := getManager()
+
+			env, err := mgr.RotateSSHKey(context.Background(), name)
+			if err != nil {
+				klog.Errorf("Failed to rotate studio SSH key: name=%s error=%v", name, err)
+				return err
+			}
+			if env.SSHPort > 0 {
+				if err := mgr.AddSSHConfig(env); err != nil {
+					klog.Warningf("Failed to update SSH config: name=%s error=%v", env.Name, err)
+				}
+			}
+			return getOutput().Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "SSH key of studio %s rotated: %s",
+				Args:    []any{env.Name, studio.StudioKeyPath(ggoplatform.DefaultPaths(), env.Name)},
+				ID:      env.Name,
+			})
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package studio
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/credentials"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	ggoplatform "github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+)
+
+func newSecretCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "secret",
+		Aliases: []string{"secrets"},
+		Short:   "Manage the local secrets studio env templates refer to",
+		Long: `Manage named secrets for templated environment variables. A value given with
+'ggo studio create -e' or by a template can refer to them as {{secret "name"}};
+they are resolved when the studio is created, so studio definitions can be
+shared without the credentials in them.
+
+Secrets are kept in the OS keyring, or in an owner-only file in the ggo config
+directory on hosts without one. Besides secrets, env templates can use
+{{host_ip}}, the IPv4 address of this machine, and {{share_code}}, the share
+code of the studio's GPU worker.`,
+		Example: `  # Store a token, typed without echo or piped in
+  ggo studio secret set hf
+  echo "$HF_TOKEN" | ggo studio secret set hf
+
+  # Use it in a studio
+  ggo studio create my-env -s abc123 -e 'HF_TOKEN={{secret "hf"}}'
+
+  # List and remove secrets
+  ggo studio secret ls
+  ggo studio secret rm hf`,
+	}
+	cmd.AddCommand(cmdutil.Audited(newSecretSetCmd()))
+	cmd.AddCommand(newSecretListCmd())
+	cmd.AddCommand(cmdutil.Audited(newSecretRemoveCmd()))
+	return cmd
+}
+
+func getSecretStore() *studio.SecretStore {
+	return studio.NewSecretStore(ggoplatform.DefaultPaths())
+}
+
+func newSecretSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <name>",
+		Short: "Store a secret, read from the terminal or standard input",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if err := studio.ValidateSecretName(name); err != nil {
+				return cmdutil.UsageError(err)
+			}
+			out := getOutput()
+			cmd.SilenceUsage = true
+
+			if term.IsTerminal(int(os.Stdin.Fd())) {
+				fmt.Fprint(os.Stderr, i18n.Tf("Value of secret %s: ", name))
+			}
+			value, err := readSecretValue()
+			if err != nil {
+				return fmt.Errorf("failed to read secret: %w", err)
+			}
+
+			sec, err := getSecretStore().Set(name, value)
+			if err != nil {
+				klog.Errorf("Failed to store secret: name=%s error=%v", name, err)
+				return err
+			}
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Secret '%s' stored in %s",
+				Args:    []any{name, credentials.Location(sec.Value)},
+				ID:      name,
+			})
+		},
+	}
+}
+
+// readSecretValue reads a secret without echo from a terminal, or the first
+// line of piped input
+func readSecretValue() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(value)), nil
+	}
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && value == "" {
+		return "", err
+	}
+	return strings.TrimSpace(value), nil
+}
+
+func newSecretListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List stored secrets, without their values",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			secrets, err := getSecretStore().List()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to list secrets: error=%v", err)
+				return err
+			}
+			return getOutput().Render(&secretListResult{secrets: secrets})
+		},
+	}
+}
+
+// secretInfo describes a stored secret without its value
+type secretInfo struct {
+	Name      string    `json:"name"`
+	Storage   string    `json:"storage"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// secretListResult implements Renderable for secret list
+type secretListResult struct {
+	secrets []studio.StoredSecret
+}
+
+func (r *secretListResult) RenderJSON() any {
+	infos := make([]secretInfo, 0, len(r.secrets))
+	for _, s := range r.secrets {
+		infos = append(infos, secretInfo{Name: s.Name, Storage: credentials.Location(s.Value), UpdatedAt: s.UpdatedAt})
+	}
+	return tui.NewListResult(infos)
+}
+
+func (r *secretListResult) RenderTUI(out *tui.Output) {
+	if len(r.secrets) == 0 {
+		out.Info("No secrets stored (see 'ggo studio secret set')")
+		return
+	}
+	styles := tui.DefaultStyles()
+	rows := make([][]string, 0, len(r.secrets))
+	for _, s := range r.secrets {
+		rows = append(rows, []string{
+			styles.Bold.Render(s.Name),
+			credentials.Location(s.Value),
+			s.UpdatedAt.Local().Format(time.DateTime),
+		})
+	}
+	out.Println(tui.NewTable().Headers("NAME", "STORAGE", "UPDATED").Rows(rows).String())
+}
+
+func newSecretRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"remove"},
+		Short:   "Remove a stored secret",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := getSecretStore().Remove(args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to remove secret: name=%s error=%v", args[0], err)
+				return err
+			}
+			if !removed {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("secret %q is not set", args[0])
+			}
+			return getOutput().Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Secret '%s' removed",
+				Args:    []any{args[0]},
+				ID:      args[0],
+			})
+		},
+	}
+}
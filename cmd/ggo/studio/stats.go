@@ -0,0 +1,168 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// ANSI sequences used to redraw `stats --watch` in place
+const (
+	ansiClearScreen = "\033[H\033[2J"
+	ansiHideCursor  = "\033[?25l"
+	ansiShowCursor  = "\033[?25h"
+)
+
+func newStatsCmd() *cobra.Command {
+	var watch bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "stats [name...]",
+		Short: "Show live resource usage of studio environments",
+		Long: `Show CPU, memory, network and process usage of running studio environments,
+and whether the remote GPU worker each one uses is reachable.
+
+Usage comes from the container runtime (docker stats for docker, podman,
+colima and wsl) or from the cgroup counters inside the container (apple). CPU % is
+relative to one CPU, so busy studios on several CPUs exceed 100%.`,
+		Example: `  # Usage of all studios
+  ggo studio stats
+
+  # Keep refreshing one studio's usage
+  ggo studio stats my-env --watch
+
+  # One sample as JSON for scripts
+  ggo studio stats -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mgr := getManager()
+			out := getOutput()
+
+			if !watch {
+				stats, err := mgr.Stats(context.Background(), args)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to get studio stats: names=%v error=%v", args, err)
+					return err
+				}
+				return out.Render(&statsResult{stats: stats})
+			}
+
+			if interval <= 0 {
+				return cmdutil.UsageErrorf("--interval must be positive")
+			}
+			cmd.SilenceUsage = true
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			if !out.IsJSON() {
+				fmt.Print(ansiHideCursor)
+				defer fmt.Print(ansiShowCursor)
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				stats, err := mgr.Stats(ctx, args)
+				if ctx.Err() != nil {
+					return nil
+				}
+				if err != nil {
+					klog.Errorf("Failed to get studio stats: names=%v error=%v", args, err)
+					return err
+				}
+				if !out.IsJSON() {
+					fmt.Print(ansiClearScreen)
+				}
+				if err := out.Render(&statsResult{stats: stats, sampledAt: time.Now()}); err != nil {
+					return err
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Keep refreshing the usage until interrupted")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Refresh interval for --watch")
+
+	return cmd
+}
+
+// statsResult implements Renderable for stats command
+type statsResult struct {
+	stats []*studio.EnvironmentStats
+	// sampledAt is set when watching
+	sampledAt time.Time
+}
+
+func (r *statsResult) RenderJSON() any {
+	return tui.NewListResult(r.stats)
+}
+
+func (r *statsResult) RenderTUI(out *tui.Output) {
+	if len(r.stats) == 0 {
+		out.Info("No studio environments found")
+		return
+	}
+
+	styles := tui.DefaultStyles()
+	var rows [][]string
+	for _, s := range r.stats {
+		cpu, mem, netIO, pids := "-", "-", "-", "-"
+		switch {
+		case s.Usage != nil:
+			cpu = fmt.Sprintf("%.1f%%", s.Usage.CPUPercent)
+			mem = cmdutil.FormatBytes(s.Usage.MemoryUsageBytes)
+			if s.Usage.MemoryLimitBytes > 0 {
+				mem += " / " + cmdutil.FormatBytes(s.Usage.MemoryLimitBytes)
+			}
+			netIO = cmdutil.FormatBytes(s.Usage.NetRxBytes) + " / " + cmdutil.FormatBytes(s.Usage.NetTxBytes)
+			if s.Usage.PIDs > 0 {
+				pids = fmt.Sprintf("%d", s.Usage.PIDs)
+			}
+		case s.Error != "":
+			cpu = styles.Error.Render(i18n.T("error"))
+		case s.Status != studio.StatusRunning:
+			cpu = styles.Muted.Render(string(s.Status))
+		}
+
+		gpu := styles.Muted.Render("-")
+		if s.GPU != nil {
+			if s.GPU.Reachable {
+				gpu = styles.Success.Render(fmt.Sprintf("%s %.1fms", s.GPU.Addr, s.GPU.LatencyMs))
+			} else {
+				gpu = styles.Error.Render(s.GPU.Addr + " unreachable")
+			}
+		}
+
+		rows = append(rows, []string{styles.Bold.Render(s.Name), string(s.Mode), cpu, mem, netIO, pids, gpu})
+	}
+
+	out.Println(tui.NewTable().
+		Headers("NAME", "MODE", "CPU %", "MEM USAGE / LIMIT", "NET I/O (RX / TX)", "PIDS", "GPU WORKER").
+		Rows(rows).String())
+
+	for _, s := range r.stats {
+		if s.Error != "" {
+			out.Warningf("%s: %s", s.Name, s.Error)
+		}
+	}
+	if !r.sampledAt.IsZero() {
+		out.Println(styles.Muted.Render(i18n.Tf("Updated %s · Ctrl+C to exit", r.sampledAt.Format(time.TimeOnly))))
+	}
+}
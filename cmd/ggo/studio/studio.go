@@ -2,15 +2,23 @@ package studio
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/version"
 	"github.com/NexusGPU/gpu-go/internal/api"
 	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	ggoplatform "github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/progress"
 	"github.com/NexusGPU/gpu-go/internal/studio"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/spf13/cobra"
@@ -36,6 +44,11 @@ var (
 	command       []string
 	endpoint      string
 	platform      string // container platform (e.g., linux/amd64, linux/arm64)
+	pullPolicy    string // never, missing, always
+	anonymous     bool   // skip registering with the share owner
+	templateName  string // studio template to create from
+	noVerify      bool   // skip the GPU readiness probe after create
+	parallel      int    // studios created at once by a multi-name create
 
 	// lastPrivateKeyPath stores the private key path from the most recent buildCreateOptions call
 	lastPrivateKeyPath string
@@ -52,7 +65,8 @@ Supported platforms:
   - wsl:    Windows Subsystem for Linux (Windows only)
   - colima: Colima container runtime (macOS/Linux)
   - apple-container: Apple Container (macOS 26+)
-  - docker: Native Docker
+  - docker: Native Docker, or rootless Podman's Docker-compatible socket
+  - podman: Podman, rootful or rootless (Linux)
   - k8s:    Kubernetes (kind, minikube, etc.)
   - auto:   Auto-detect best available platform
 
@@ -102,14 +116,28 @@ Examples:
 
 	cmdutil.AddOutputFlag(cmd, &outputFormat)
 
-	cmd.AddCommand(newCreateCmd())
+	cmd.AddCommand(cmdutil.Audited(newCreateCmd()))
+	cmd.AddCommand(newPullCmd())
 	cmd.AddCommand(newListCmd())
 	cmd.AddCommand(newStartCmd())
 	cmd.AddCommand(newStopCmd())
-	cmd.AddCommand(newRemoveCmd())
+	cmd.AddCommand(cmdutil.Audited(newResizeCmd()))
+	cmd.AddCommand(cmdutil.Audited(newRebuildCmd()))
+	cmd.AddCommand(cmdutil.Audited(newRemoveCmd()))
+	cmd.AddCommand(newExportCmd())
+	cmd.AddCommand(cmdutil.Audited(newImportCmd()))
+	cmd.AddCommand(newVolumeCmd())
+	cmd.AddCommand(newSecretCmd())
+	cmd.AddCommand(newKeysCmd())
 	cmd.AddCommand(newSSHCmd())
+	cmd.AddCommand(newCodeCmd())
+	cmd.AddCommand(cmdutil.Audited(newEnvCmd()))
+	cmd.AddCommand(newVerifyCmd())
 	cmd.AddCommand(newLogsCmd())
+	cmd.AddCommand(newStatsCmd())
+	cmd.AddCommand(newGPUTopCmd())
 	cmd.AddCommand(newImagesCmd())
+	cmd.AddCommand(newTemplatesCmd())
 	cmd.AddCommand(newTagsCmd())
 	cmd.AddCommand(newBackendsCmd())
 
@@ -125,6 +153,8 @@ func getManager() *studio.Manager {
 	}
 	mgr.RegisterBackend(dockerBackend)
 
+	mgr.RegisterBackend(studio.NewPodmanBackend())
+
 	colimaBackend := studio.NewColimaBackend()
 	if colimaProfile != "" {
 		colimaBackend = studio.NewColimaBackendWithProfile(colimaProfile)
@@ -157,7 +187,7 @@ func printBackendAndSocket(ctx context.Context, out *tui.Output, mgr *studio.Man
 	}
 	styles := tui.DefaultStyles()
 	out.Println()
-	out.Println(styles.Subtitle.Render("Backend"))
+	out.Println(styles.Subtitle.Render(i18n.T("Backend")))
 	sock := "—"
 	if sp, ok := backend.(studio.BackendSocketPath); ok {
 		if p := sp.SocketPath(ctx); p != "" {
@@ -171,8 +201,8 @@ func printBackendAndSocket(ctx context.Context, out *tui.Output, mgr *studio.Man
 
 func newCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "create <name>",
-		Short: "Create a new studio environment",
+		Use:   "create <name>...",
+		Short: "Create new studio environment(s)",
 		Long: `Create a new AI development studio environment.
 
 The environment will be configured with:
@@ -187,6 +217,10 @@ Examples:
   # Create with specific image
   ggo studio create my-env -s abc123 --image tensorfusion/studio-torch:latest
 
+  # Create from a template: image, resources, ports and notes in one go
+  # (see 'ggo studio templates --remote'); flags override the template
+  ggo studio create my-env --template sd-webui -s abc123
+
   # Create with WSL on Windows (specific distro)
   ggo studio create my-env --mode wsl --wsl-distro Ubuntu-22.04 -s abc123
 
@@ -202,23 +236,51 @@ Examples:
   # Best practice: mount user data directory to prevent data loss on studio rebuild
   ggo studio create my-env -s abc123 -v ~/data:/data
 
+  # Keep data on a named volume, created if missing (see 'ggo studio volume')
+  ggo studio create my-env -s abc123 -v my-env-data:/data
+
+  # Pass a token from the local secret store (see 'ggo studio secret')
+  ggo studio create my-env -s abc123 -e 'HF_TOKEN={{secret "hf"}}'
+
   # Create with custom startup command (supplements ENTRYPOINT args)
   ggo studio create my-env -s abc123 -c /bin/bash -c "echo hello"
 
   # Create with endpoint override (override GPU worker endpoint)
-  ggo studio create my-env -s abc123 --endpoint "https://custom-worker.example.com:9001"`,
-		Args: cobra.ExactArgs(1),
+  ggo studio create my-env -s abc123 --endpoint "https://custom-worker.example.com:9001"
+
+  # Create offline from an image loaded with 'docker load'
+  ggo studio create my-env -s abc123 --pull=never
+
+  # Create a studio per student of a class, four at a time
+  ggo studio create lab-01 lab-02 lab-03 lab-04 lab-05 -s abc123 -j 4
+
+With a remote GPU, the new studio is probed once it runs: the GPU client
+libraries must load, the GPU worker must be reachable from the container and
+nvidia-smi must list the GPUs. The outcome and hints for failed checks are
+shown with the result; rerun the probe with 'ggo studio verify <name>'.
+
+Given several names, the studios are created with the same options, --parallel
+at a time, and failures are reported per studio. Fixed host ports (--port) can
+only be used by one studio, and the GPU readiness probe is left to
+'ggo studio verify'.
+
+Environment values, from -e or a template, may be templates resolved on this
+machine at creation: {{secret "name"}} from the local secret store (see
+'ggo studio secret'), {{host_ip}} and {{share_code}}. Templates and shared
+commands then carry no credentials or host specifics.`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: runCreate,
 	}
 
-	cmd.Flags().StringVarP(&mode, "mode", "m", "", "Container/VM mode (wsl, colima, apple-container, docker, k8s, auto)")
+	cmd.Flags().StringVarP(&mode, "mode", "m", "", "Container/VM mode (wsl, colima, apple-container, docker, podman, k8s, auto)")
 	cmd.Flags().StringVarP(&image, "image", "i", "tensorfusion/studio-torch:latest", "Container image")
 	cmd.Flags().StringVarP(&shareLink, "share-link", "s", "", "Share link or share code to remote vGPU worker (recommended for GPU access)")
 	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL for resolving share links")
-	cmd.Flags().StringVar(&sshKey, "ssh-key", "", "SSH public key to authorize (auto-generates dedicated key pair if not provided)")
+	cmd.Flags().BoolVar(&anonymous, "anonymous", false, "Don't register this machine with the share owner")
+	cmd.Flags().StringVar(&sshKey, "ssh-key", "", "SSH public key to authorize (default a key pair of the studio's own, generated and used by its ~/.ssh/config entry)")
 	cmd.Flags().StringArrayVarP(&ports, "port", "p", nil, "Port mappings (host:container)")
-	cmd.Flags().StringArrayVarP(&volumes, "volume", "v", nil, "Volume mounts (host:container[:ro])")
-	cmd.Flags().StringArrayVarP(&envVars, "env", "e", nil, "Environment variables (KEY=VALUE)")
+	cmd.Flags().StringArrayVarP(&volumes, "volume", "v", nil, "Volume mounts (host-path-or-volume:container[:ro])")
+	cmd.Flags().StringArrayVarP(&envVars, "env", "e", nil, "Environment variables (KEY=VALUE); values may use {{secret \"name\"}}, {{host_ip}} and {{share_code}}")
 	cmd.Flags().Float64Var(&cpus, "cpus", 0, "CPU limit")
 	cmd.Flags().StringVar(&memory, "memory", "", "Memory limit (e.g., 8Gi)")
 	cmd.Flags().BoolVar(&noSSH, "no-ssh", false, "Don't configure SSH")
@@ -228,11 +290,22 @@ Examples:
 	cmd.Flags().StringArrayVarP(&command, "command", "c", nil, "Container startup command or ENTRYPOINT args (can be specified multiple times)")
 	cmd.Flags().StringVar(&endpoint, "endpoint", "", "Override GPU worker endpoint URL")
 	cmd.Flags().StringVar(&platform, "platform", "", "Container image platform (e.g., linux/amd64, linux/arm64). Default: linux/amd64")
+	cmd.Flags().StringVar(&pullPolicy, "pull", string(studio.PullPolicyMissing), "Image pull policy: never, missing, always")
+	cmd.Flags().StringVarP(&templateName, "template", "t", "", "Studio template to create from, built in or from the platform registry (see 'ggo studio templates')")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip the GPU readiness probe after creating (see 'ggo studio verify')")
+	cmd.Flags().IntVarP(&parallel, "parallel", "j", studio.DefaultBatchConcurrency, "Studios created at once when several names are given")
 
 	return cmd
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
+	return createStudios(cmd, args, nil)
+}
+
+// createStudios creates the studios named in args from the create flags. def
+// is the definition they were set from by 'ggo studio import', for the
+// options that have no flag.
+func createStudios(cmd *cobra.Command, args []string, def *studio.Definition) error {
 	name := args[0]
 	// Use a longer timeout for docker pull operations (10 minutes)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
@@ -240,25 +313,65 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	mgr := getManager()
 	out := getOutput()
 
+	if len(args) > 1 {
+		if err := validateParallel(parallel); err != nil {
+			return err
+		}
+		if len(ports) > 0 {
+			return fmt.Errorf("--port binds fixed host ports and cannot be used when creating several studios")
+		}
+	}
+
+	// A ggo.lock in the project directory fixes the client library versions
+	lock, lockPath, err := cmdutil.ProjectLockfile()
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+
+	var tmpl *api.StudioTemplate
+	if def != nil && def.Template != nil {
+		// The definition's options already include the template's
+		tmpl = &api.StudioTemplate{Name: def.Template.Name, Title: def.Template.Title, Notes: def.Template.Notes}
+	} else if templateName != "" {
+		tmpl, err = studio.ResolveTemplate(ctx, api.NewClient(api.WithBaseURL(serverURL)), templateName)
+		if err != nil {
+			cmd.SilenceUsage = true
+			klog.Errorf("Failed to resolve studio template: template=%s error=%v", templateName, err)
+			return err
+		}
+		applyTemplate(cmd, tmpl)
+		klog.Infof("Using studio template: template=%s image=%s", tmpl.Name, image)
+	}
+
 	// Resolve share link if provided
 	var shareInfo *api.SharePublicInfo
 	if shareLink != "" {
-		shortCode := extractShortCode(shareLink)
+		shortCode := cmdutil.ExtractShortCode(shareLink)
 		client := api.NewClient(api.WithBaseURL(serverURL))
 
-		var err error
 		shareInfo, err = client.GetSharePublic(ctx, shortCode)
 		if err != nil {
 			cmd.SilenceUsage = true
 			klog.Errorf("Failed to resolve share link: error=%v", err)
 			return fmt.Errorf("failed to resolve share link '%s': %w", shareLink, err)
 		}
+		cmdutil.SelectShareAddress(ctx, shareInfo)
+		if shareInfo.Queue.Saturated() {
+			// The studio connects on its first GPU call, which waits for a slot
+			klog.Warningf("GPU worker is busy: worker_id=%s status=%q", shareInfo.WorkerID, cmdutil.FormatShareQueue(shareInfo.Queue))
+			out.Warningf("GPU worker %s is busy (%s); GPU calls in the studio wait until a slot frees up", shareInfo.WorkerID, cmdutil.FormatShareQueue(shareInfo.Queue))
+		}
 
 		// Append share code to connection URL for authentication
 		shareInfo.ConnectionURL = shareInfo.ConnectionURL + "+" + shortCode
 		klog.Infof("Resolved share link: worker_id=%s vendor=%s arch=%s connection_url=%s",
 			shareInfo.WorkerID, shareInfo.HardwareVendor, shareInfo.AgentArch, shareInfo.ConnectionURL)
 
+		if !anonymous {
+			cmdutil.RegisterShareConsumer(ctx, client, shortCode, "studio", version.Version)
+		}
+
 		// Determine target arch from share info (preferred) or platform flag (fallback)
 		targetArch := "amd64"
 		if shareInfo.AgentArch != "" {
@@ -271,10 +384,13 @@ func runCreate(cmd *cobra.Command, args []string) error {
 
 		// Download required GPU client libraries before creating studio
 		// Filter by vendor from share info to avoid downloading unnecessary libraries
-		if err := ensureRemoteGPUClientLibs(ctx, out, shareInfo.HardwareVendor, targetArch); err != nil {
+		if lock != nil && !out.IsJSON() {
+			out.Infof("Using library versions locked in %s", lockPath)
+		}
+		if err := ensureRemoteGPUClientLibs(ctx, out, shareInfo.HardwareVendor, targetArch, lock); err != nil {
 			cmd.SilenceUsage = true
 			klog.Errorf("Failed to ensure GPU client libraries: error=%v", err)
-			return fmt.Errorf("failed to download GPU client libraries: %w", err)
+			return cmdutil.DependencyError(fmt.Errorf("failed to download GPU client libraries: %w", err))
 		}
 	} else if !out.IsJSON() {
 		styles := tui.DefaultStyles()
@@ -284,10 +400,19 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		out.Println()
 	}
 
+	if len(args) > 1 {
+		return createMany(ctx, cmd, mgr, out, args, shareInfo, lockPath, tmpl)
+	}
+
 	opts, err := buildCreateOptions(name, shareInfo)
 	if err != nil {
 		return err
 	}
+	opts.Lockfile = lockPath
+	opts.Template = templateRef(tmpl)
+	if def != nil {
+		opts.WorkDir, opts.Labels, opts.NoUserVolume = def.WorkDir, def.Labels, def.NoUserVolume
+	}
 
 	if !out.IsJSON() {
 		styles := tui.DefaultStyles()
@@ -343,6 +468,21 @@ Original error: %w`, err)
 		}
 	}
 
+	// Check the remote GPU works inside the container before handing it over
+	var probe *studio.ProbeResult
+	if opts.GPUWorkerURL != "" && env.Status == studio.StatusRunning && !noVerify {
+		if !out.IsJSON() {
+			out.Printf("%s Verifying GPU environment...\n", tui.DefaultStyles().Info.Render("◐"))
+		}
+		progress.StepStarted(progress.StepVerify, "Verifying GPU environment")
+		probe = mgr.Probe(ctx, env)
+		if probe.Error != "" || !probe.Passed {
+			progress.Failed(progress.StepVerify, fmt.Errorf("GPU readiness probe failed for studio %s", env.Name))
+		} else {
+			progress.StepFinished(progress.StepVerify, "GPU environment ready")
+		}
+	}
+
 	backendName, socketPath := "", ""
 	if backend, err := mgr.GetBackend(env.Mode); err == nil {
 		backendName = backend.Name()
@@ -357,15 +497,112 @@ Original error: %w`, err)
 		backendName:    backendName,
 		socketPath:     socketPath,
 		privateKeyPath: lastPrivateKeyPath,
+		template:       tmpl,
+		probe:          probe,
 	})
 }
 
+// createMany creates a studio for each of names concurrently, with the same
+// options
+func createMany(ctx context.Context, cmd *cobra.Command, mgr *studio.Manager, out *tui.Output, names []string, shareInfo *api.SharePublicInfo, lockPath string, tmpl *api.StudioTemplate) error {
+	allOpts := make([]*studio.CreateOptions, 0, len(names))
+	for _, name := range names {
+		opts, err := buildCreateOptions(name, shareInfo)
+		if err != nil {
+			return err
+		}
+		opts.Lockfile = lockPath
+		opts.Template = templateRef(tmpl)
+		allOpts = append(allOpts, opts)
+	}
+
+	if !out.IsJSON() {
+		out.Printf("%s Creating %d studio environments, %d at a time...\n",
+			tui.DefaultStyles().Info.Render("◐"), len(names), parallel)
+	}
+	envs, err := mgr.CreateMany(ctx, allOpts, batchOptions(out, parallel, i18n.T("%s Creating %s...\n")))
+	if !noSSH {
+		for _, env := range envs {
+			if env.SSHPort == 0 {
+				continue
+			}
+			if err := mgr.AddSSHConfig(env); err != nil {
+				klog.Warningf("Failed to add SSH config: name=%s error=%v", env.Name, err)
+			}
+		}
+	}
+	if err != nil {
+		cmd.SilenceUsage = true
+		klog.Errorf("Failed to create studios: created=%d total=%d error=%v", len(envs), len(names), err)
+		if len(envs) > 0 && !out.IsJSON() {
+			out.Println()
+			_ = out.Render(&envListResult{envs: envs})
+		}
+		return err
+	}
+	return out.Render(&envListResult{envs: envs})
+}
+
+func newPullCmd() *cobra.Command {
+	var pullPlatform string
+
+	cmd := &cobra.Command{
+		Use:   "pull <image>",
+		Short: "Pull a studio image ahead of time",
+		Long: `Pull a studio image into the backend's image cache so that a later
+'ggo studio create' starts without waiting for the download.
+
+Examples:
+  # Pre-pull the default image for amd64
+  ggo studio pull tensorfusion/studio-torch:latest --platform linux/amd64
+
+  # Pull into a specific Colima profile
+  ggo studio pull tensorfusion/studio-torch:latest --mode colima --colima-profile myprofile`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+			defer cancel()
+			out := getOutput()
+
+			studioMode := studio.ModeAuto
+			if mode != "" {
+				studioMode = studio.Mode(mode)
+			}
+			err := getManager().Pull(ctx, studioMode, args[0], studio.PullOptions{
+				Platform: pullPlatform,
+				Policy:   studio.PullPolicyAlways,
+				Progress: os.Stderr,
+			})
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to pull image: image=%s error=%v", args[0], err)
+				return err
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Image %s is ready",
+				Args:    []any{args[0]},
+				ID:      args[0],
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&mode, "mode", "m", "", "Container/VM mode (wsl, colima, docker, podman, auto)")
+	cmd.Flags().StringVar(&pullPlatform, "platform", "", "Image platform (e.g., linux/amd64, linux/arm64). Default: backend architecture")
+	cmd.Flags().StringVar(&colimaProfile, "colima-profile", "", "Colima profile name (default: 'default')")
+	cmd.Flags().StringVar(&wslDistro, "wsl-distro", "", "WSL distribution name (default: use default distro)")
+	cmd.Flags().StringVar(&dockerHost, "docker-host", "", "Custom Docker socket path (e.g., unix:///path/to/docker.sock)")
+
+	return cmd
+}
+
 // ensureRemoteGPUClientLibs downloads remote-gpu-client libraries if not already present
 // vendorSlug filters by vendor (e.g., "nvidia", "amd") to avoid downloading unnecessary libraries
 // targetArch specifies the CPU architecture (e.g., "amd64", "arm64") for the target container platform
 // Note: Studio environments run in Linux containers, so we always download Linux libraries
-func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug, targetArch string) error {
-	depsMgr := deps.NewManager()
+func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug, targetArch string, lock *deps.Lockfile) error {
+	depsMgr := deps.NewManager(deps.WithLockfile(lock))
 
 	// Target library types that are needed for GPU client functionality
 	targetTypes := []string{deps.LibraryTypeRemoteGPUClient, deps.LibraryTypeVGPULibrary}
@@ -375,6 +612,7 @@ func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug,
 	}
 
 	progressFn := func(lib deps.Library, downloaded, total int64) {
+		progress.Update(progress.StepLibraries, lib.Name, downloaded, total, progress.UnitBytes)
 		if !out.IsJSON() && total > 0 {
 			pct := float64(downloaded) / float64(total) * 100
 			fmt.Printf("\r  %s: %.1f%%", lib.Name, pct)
@@ -383,10 +621,14 @@ func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug,
 
 	// Studio environments always run in Linux containers
 	// Download libraries for the specified target architecture
+	progress.StepStarted(progress.StepLibraries, fmt.Sprintf("Downloading GPU client libraries for %s (linux/%s)", vendorSlug, targetArch))
 	libs, err := depsMgr.EnsureLibrariesByTypesForPlatform(ctx, targetTypes, vendorSlug, "linux", targetArch, progressFn)
 	if err != nil {
-		return fmt.Errorf("failed to ensure GPU client libraries: %w", err)
+		err = cmdutil.DependencyError(fmt.Errorf("failed to ensure GPU client libraries: %w", err))
+		progress.Failed(progress.StepLibraries, err)
+		return err
 	}
+	progress.StepFinished(progress.StepLibraries, fmt.Sprintf("%d library(ies) downloaded", len(libs)))
 
 	if !out.IsJSON() {
 		if len(libs) > 0 {
@@ -400,24 +642,17 @@ func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug,
 	return nil
 }
 
-// extractShortCode extracts the short code from a share link URL
-func extractShortCode(input string) string {
-	input = strings.TrimSpace(input)
-	if strings.Contains(input, "/") {
-		parts := strings.Split(strings.TrimSuffix(input, "/"), "/")
-		if len(parts) > 0 {
-			return parts[len(parts)-1]
-		}
-	}
-	return input
-}
-
 func buildCreateOptions(name string, shareInfo *api.SharePublicInfo) (*studio.CreateOptions, error) {
 	studioMode := studio.ModeAuto
 	if mode != "" {
 		studioMode = studio.Mode(mode)
 	}
 
+	policy, err := studio.ParsePullPolicy(pullPolicy)
+	if err != nil {
+		return nil, cmdutil.UsageError(err)
+	}
+
 	portMappings, err := parsePorts(ports)
 	if err != nil {
 		return nil, err
@@ -433,17 +668,17 @@ func buildCreateOptions(name string, shareInfo *api.SharePublicInfo) (*studio.Cr
 		return nil, err
 	}
 
-	// Get or create dedicated SSH key pair for TF studio containers
+	// Get or create the studio's own SSH key pair
 	effectiveSSHKey := sshKey
 	privateKeyPath := ""
 	if effectiveSSHKey == "" {
-		pubKey, privPath, err := studio.GetOrCreateStudioSSHKey()
+		pubKey, privPath, err := studio.GetOrCreateStudioSSHKey(ggoplatform.DefaultPaths(), name)
 		if err != nil {
 			klog.Warningf("Failed to get/create studio SSH key: %v", err)
 		} else {
 			effectiveSSHKey = pubKey
 			privateKeyPath = privPath
-			klog.V(2).Infof("Using TF studio SSH key: %s", privPath)
+			klog.V(2).Infof("Using studio SSH key: %s", privPath)
 		}
 	}
 
@@ -458,9 +693,14 @@ func buildCreateOptions(name string, shareInfo *api.SharePublicInfo) (*studio.Cr
 	// Set GPU connection info from share link
 	gpuWorkerURL := ""
 	hardwareVendor := ""
+	var share *studio.ShareRef
 	if shareInfo != nil {
 		gpuWorkerURL = shareInfo.ConnectionURL
 		hardwareVendor = shareInfo.HardwareVendor
+		share = &studio.ShareRef{Code: cmdutil.ExtractShortCode(shareLink)}
+		if serverURL != api.GetDefaultBaseURL() {
+			share.Server = serverURL
+		}
 	}
 
 	// Allow --endpoint to override the connection URL
@@ -473,6 +713,24 @@ func buildCreateOptions(name string, shareInfo *api.SharePublicInfo) (*studio.Cr
 		}
 	}
 
+	// Templated values are resolved now so shared studio definitions carry
+	// no secrets or host specifics; the templates are kept for export
+	var envTemplates map[string]string
+	for k, v := range envMap {
+		if studio.IsEnvTemplate(v) {
+			if envTemplates == nil {
+				envTemplates = make(map[string]string)
+			}
+			envTemplates[k] = v
+		}
+	}
+	if err := studio.ExpandEnvTemplates(envMap, &studio.EnvTemplateContext{
+		Secret:    getSecretStore().Get,
+		ShareCode: studio.ShareCodeFromWorkerURL(gpuWorkerURL),
+	}); err != nil {
+		return nil, err
+	}
+
 	// Default platform to linux/amd64 for studio containers
 	// If share info provides agent architecture, use it to set the correct platform
 	effectivePlatform := platform
@@ -501,10 +759,13 @@ func buildCreateOptions(name string, shareInfo *api.SharePublicInfo) (*studio.Cr
 			CPUs:   cpus,
 			Memory: memory,
 		},
-		Command:     command,
-		Endpoint:    endpointOverride,
-		Platform:    effectivePlatform,
-		UseLocalGPU: gpuWorkerURL == "" && (studioMode == studio.ModeDocker || studioMode == studio.ModeWSL || studioMode == studio.ModeAuto),
+		Command:      command,
+		Endpoint:     endpointOverride,
+		Platform:     effectivePlatform,
+		PullPolicy:   policy,
+		UseLocalGPU:  gpuWorkerURL == "" && (studioMode == studio.ModeDocker || studioMode == studio.ModePodman || studioMode == studio.ModeWSL || studioMode == studio.ModeAuto),
+		Share:        share,
+		EnvTemplates: envTemplates,
 	}, nil
 }
 
@@ -513,14 +774,14 @@ func parsePorts(ports []string) ([]studio.PortMapping, error) {
 	for _, p := range ports {
 		parts := strings.Split(p, ":")
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid port format: %s (expected host:container)", p)
+			return nil, cmdutil.UsageErrorf("invalid port format: %s (expected host:container)", p)
 		}
 		var hostPort, containerPort int
 		if _, err := fmt.Sscanf(parts[0], "%d", &hostPort); err != nil {
-			return nil, fmt.Errorf("invalid host port: %s", parts[0])
+			return nil, cmdutil.UsageErrorf("invalid host port: %s", parts[0])
 		}
 		if _, err := fmt.Sscanf(parts[1], "%d", &containerPort); err != nil {
-			return nil, fmt.Errorf("invalid container port: %s", parts[1])
+			return nil, cmdutil.UsageErrorf("invalid container port: %s", parts[1])
 		}
 		mappings = append(mappings, studio.PortMapping{
 			HostPort:      hostPort,
@@ -535,7 +796,7 @@ func parseVolumes(volumes []string) ([]studio.VolumeMount, error) {
 	for _, v := range volumes {
 		parts := strings.Split(v, ":")
 		if len(parts) < 2 {
-			return nil, fmt.Errorf("invalid volume format: %s (expected host:container[:ro])", v)
+			return nil, cmdutil.UsageErrorf("invalid volume format: %s (expected host:container[:ro])", v)
 		}
 		mount := studio.VolumeMount{
 			HostPath:      parts[0],
@@ -554,7 +815,7 @@ func parseEnvVars(envVars []string) (map[string]string, error) {
 	for _, e := range envVars {
 		parts := strings.SplitN(e, "=", 2)
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid env var format: %s (expected KEY=VALUE)", e)
+			return nil, cmdutil.UsageErrorf("invalid env var format: %s (expected KEY=VALUE)", e)
 		}
 		envMap[parts[0]] = parts[1]
 	}
@@ -569,10 +830,19 @@ type createResult struct {
 	backendName    string
 	socketPath     string
 	privateKeyPath string
+	template       *api.StudioTemplate
+	// probe is the GPU readiness probe, nil if it was not run
+	probe *studio.ProbeResult
 }
 
 func (r *createResult) RenderJSON() any {
-	return r.env
+	if r.probe == nil {
+		return r.env
+	}
+	return struct {
+		*studio.Environment
+		Probe *studio.ProbeResult `json:"probe"`
+	}{r.env, r.probe}
 }
 
 func (r *createResult) RenderTUI(out *tui.Output) {
@@ -599,9 +869,13 @@ func (r *createResult) RenderTUI(out *tui.Output) {
 	}
 	out.Println(status.String())
 
+	if r.probe != nil {
+		renderProbe(out, r.probe)
+	}
+
 	if env.SSHPort > 0 && !r.noSSH {
 		out.Println()
-		out.Println(styles.Subtitle.Render("SSH Configuration"))
+		out.Println(styles.Subtitle.Render(i18n.T("SSH Configuration")))
 		out.Println()
 
 		sshStatus := tui.NewStatusTable().
@@ -616,15 +890,27 @@ func (r *createResult) RenderTUI(out *tui.Output) {
 		out.Println(sshStatus.String())
 
 		out.Println()
-		out.Println(styles.Subtitle.Render("Connect with:"))
+		out.Println(styles.Subtitle.Render(i18n.T("Connect with:")))
 		out.Println()
 		out.Println("  " + tui.Code(fmt.Sprintf("ssh ggo-%s", env.Name)))
 		out.Println()
-		out.Println(styles.Subtitle.Render("Or in VS Code:"))
+		out.Println(styles.Subtitle.Render(i18n.T("Or in VS Code:")))
 		out.Println()
-		out.Println("  1. Install 'Remote - SSH' extension")
-		out.Println("  2. Press F1 → 'Remote-SSH: Connect to Host...'")
-		out.Printf("  3. Select '%s'\n", styles.Bold.Render(fmt.Sprintf("ggo-%s", env.Name)))
+		out.Println("  " + tui.Code(fmt.Sprintf("ggo studio code %s", env.Name)))
+	}
+
+	if tmpl := r.template; tmpl != nil && (tmpl.Notes != "" || tmpl.MinVRAMMb > 0) {
+		out.Println()
+		out.Println(styles.Subtitle.Render(i18n.Tf("Template %s", tmpl.Name)))
+		out.Println()
+		if tmpl.MinVRAMMb > 0 {
+			out.Printf("  Recommended GPU memory: %d GB or more\n", tmpl.MinVRAMMb/1024)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(tmpl.Notes), "\n") {
+			if line != "" {
+				out.Println("  " + line)
+			}
+		}
 	}
 	out.Println()
 }
@@ -677,7 +963,7 @@ func (r *envListResult) RenderTUI(out *tui.Output) {
 			offlineModes[string(env.Mode)] = struct{}{}
 		}
 		if env.Status != studio.StatusUnknown && env.Status != studio.StatusDeleted && env.SSHPort > 0 && env.SSHHost != "" {
-			sshInfo = fmt.Sprintf("%s:%d", env.SSHHost, env.SSHPort)
+			sshInfo = net.JoinHostPort(env.SSHHost, strconv.Itoa(env.SSHPort))
 		}
 
 		rows = append(rows, []string{
@@ -703,7 +989,7 @@ func (r *envListResult) RenderTUI(out *tui.Output) {
 		}
 		sort.Strings(modes)
 		out.Println()
-		out.Warning(fmt.Sprintf("Container runtime offline: %s", strings.Join(modes, ", ")))
+		out.Warningf("Container runtime offline: %s", strings.Join(modes, ", "))
 	}
 }
 
@@ -732,7 +1018,8 @@ func newStartCmd() *cobra.Command {
 
 			return out.Render(&cmdutil.ActionData{
 				Success: true,
-				Message: fmt.Sprintf("Environment '%s' started", args[0]),
+				Message: "Environment '%s' started",
+				Args:    []any{args[0]},
 				ID:      args[0],
 			})
 		},
@@ -764,21 +1051,239 @@ func newStopCmd() *cobra.Command {
 
 			return out.Render(&cmdutil.ActionData{
 				Success: true,
-				Message: fmt.Sprintf("Environment '%s' stopped", args[0]),
+				Message: "Environment '%s' stopped",
+				Args:    []any{args[0]},
 				ID:      args[0],
 			})
 		},
 	}
 }
 
+func newResizeCmd() *cobra.Command {
+	var cpus float64
+	var memory string
+	var ports []string
+	var removePorts []int
+	var volumes []string
+	var removeVolumes []string
+
+	cmd := &cobra.Command{
+		Use:   "resize <name>",
+		Short: "Change resources, ports or volumes of a studio environment",
+		Long: `Update an existing studio environment instead of recreating it.
+
+CPU and memory limits are applied in place. Adding or removing ports and
+volumes requires a new container: the environment is stopped, its filesystem
+is committed to a ggo-snapshot/<name> image and a container with the same
+name, mounts and settings is created from it. Volumes and bind mounts are
+reattached, so no data is lost, and the original container is restored if
+anything fails.
+
+Supported on docker, podman, colima and wsl. Not supported on apple-container: the
+container CLI has no update or commit command, so CPU, memory, ports and
+volumes are fixed when the container is created and changing them would
+discard its filesystem. Keep data on a mounted volume and recreate the
+environment with the new settings instead.`,
+		Example: `  # Raise limits
+  ggo studio resize my-env --cpus 8 --memory 16Gi
+
+  # Publish Jupyter and mount a dataset
+  ggo studio resize my-env -p 8888:8888 -v ~/datasets:/data:ro
+
+  # Unpublish a port and drop a mount
+  ggo studio resize my-env --remove-port 8888 --remove-volume /data`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			mgr := getManager()
+			out := getOutput()
+
+			addPorts, err := parsePorts(ports)
+			if err != nil {
+				return err
+			}
+			addVolumes, err := parseVolumes(volumes)
+			if err != nil {
+				return err
+			}
+			opts := &studio.ResizeOptions{
+				Resources:     studio.ResourceSpec{CPUs: cpus, Memory: memory},
+				AddPorts:      addPorts,
+				RemovePorts:   removePorts,
+				AddVolumes:    addVolumes,
+				RemoveVolumes: removeVolumes,
+			}
+			if opts.IsEmpty() {
+				return fmt.Errorf("nothing to change: specify --cpus, --memory, --port, --volume, --remove-port or --remove-volume")
+			}
+
+			env, err := mgr.Get(ctx, args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if !out.IsJSON() {
+				printBackendAndSocket(ctx, out, mgr, env.Mode)
+			}
+
+			resized, err := mgr.Resize(ctx, args[0], opts)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to resize studio: name=%s error=%v", args[0], err)
+				return err
+			}
+
+			if resized.SSHPort > 0 && resized.SSHPort != env.SSHPort {
+				if err := mgr.AddSSHConfig(resized); err != nil {
+					klog.Warningf("Failed to update SSH config: error=%v", err)
+				}
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Environment '%s' resized",
+				Args:    []any{args[0]},
+				ID:      resized.ID,
+			})
+		},
+	}
+
+	cmd.Flags().Float64Var(&cpus, "cpus", 0, "New CPU limit")
+	cmd.Flags().StringVar(&memory, "memory", "", "New memory limit (e.g., 16Gi)")
+	cmd.Flags().StringArrayVarP(&ports, "port", "p", nil, "Add a port mapping (host:container), replacing any for the same container port")
+	cmd.Flags().IntSliceVar(&removePorts, "remove-port", nil, "Remove the mapping of a container port")
+	cmd.Flags().StringArrayVarP(&volumes, "volume", "v", nil, "Add a volume mount (host-path-or-volume:container[:ro])")
+	cmd.Flags().StringArrayVar(&removeVolumes, "remove-volume", nil, "Remove the mount at a container path")
+	return cmd
+}
+
+func newRebuildCmd() *cobra.Command {
+	var image string
+	var pull string
+	var parallel int
+
+	cmd := &cobra.Command{
+		Use:   "rebuild <name>...",
+		Short: "Recreate studio environment(s), keeping their volumes",
+		Long: `Replace the container of a broken or outdated studio environment with a
+fresh one, without losing the data on its volumes.
+
+The environment is created again with the options it was created with
+(ports, env vars, mounts, resources, GPU share), optionally from a new image.
+Named and anonymous volumes are reattached at the same paths and bind mounts
+are mounted again. Anything else written to the container filesystem is
+discarded; use 'ggo studio resize' to change ports or mounts while keeping it.
+
+The original container is stopped and only removed once the new one is
+running; if the rebuild fails it is started again.
+
+Given several names, the environments are rebuilt --parallel at a time and
+failures are reported per environment.
+
+Supported on docker, podman, colima and wsl, for environments created by this
+version of ggo or later.`,
+		Example: `  # Start over from the same image
+  ggo studio rebuild my-env
+
+  # Move to a new image, pulling it first
+  ggo studio rebuild my-env --image tensorfusion/studio-torch:2.5 --pull always
+
+  # Rebuild a class's studios on a new image, eight at a time
+  ggo studio rebuild lab-01 lab-02 lab-03 --image tensorfusion/studio-torch:2.5 -j 8`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			mgr := getManager()
+			out := getOutput()
+
+			policy, err := studio.ParsePullPolicy(pull)
+			if err != nil {
+				return cmdutil.UsageError(err)
+			}
+			if len(args) > 1 {
+				if err := validateParallel(parallel); err != nil {
+					return err
+				}
+				rebuilt, err := mgr.RebuildMany(ctx, args, &studio.RebuildOptions{Image: image, PullPolicy: policy},
+					batchOptions(out, parallel, i18n.T("%s Rebuilding %s...\n")))
+				for _, env := range rebuilt {
+					if env.SSHPort == 0 {
+						continue
+					}
+					if err := mgr.AddSSHConfig(env); err != nil {
+						klog.Warningf("Failed to update SSH config: name=%s error=%v", env.Name, err)
+					}
+				}
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to rebuild studios: rebuilt=%d total=%d error=%v", len(rebuilt), len(args), err)
+					return err
+				}
+				return out.Render(&cmdutil.ActionData{
+					Success: true,
+					Message: "Rebuilt %d studio environment(s)",
+					Args:    []any{len(rebuilt)},
+					ID:      strings.Join(args, ","),
+				})
+			}
+
+			env, err := mgr.Get(ctx, args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if !out.IsJSON() {
+				printBackendAndSocket(ctx, out, mgr, env.Mode)
+			}
+
+			rebuilt, err := mgr.Rebuild(ctx, args[0], &studio.RebuildOptions{Image: image, PullPolicy: policy})
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to rebuild studio: name=%s error=%v", args[0], err)
+				return err
+			}
+
+			if rebuilt.SSHPort > 0 {
+				if err := mgr.AddSSHConfig(rebuilt); err != nil {
+					klog.Warningf("Failed to update SSH config: error=%v", err)
+				}
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Environment '%s' rebuilt from %s",
+				Args:    []any{args[0], rebuilt.Image},
+				ID:      rebuilt.ID,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&image, "image", "", "New container image (default: the environment's current image)")
+	cmd.Flags().StringVar(&pull, "pull", string(studio.PullPolicyMissing), "Image pull policy: never, missing, always")
+	cmd.Flags().IntVarP(&parallel, "parallel", "j", studio.DefaultBatchConcurrency, "Environments rebuilt at once when several names are given")
+	return cmd
+}
+
 func newRemoveCmd() *cobra.Command {
 	var force bool
 	var all bool
+	var keepVolumes bool
+	var purge bool
+	var parallel int
 
 	cmd := &cobra.Command{
-		Use:     "rm <name>",
+		Use:     "rm <name>...",
 		Short:   "Remove studio environment(s)",
 		Aliases: []string{"remove", "delete"},
+		Long: `Remove studio environment(s).
+
+Several studios, given by name or with --all, are removed --parallel at a
+time; a failure to remove one does not stop the others and is reported per
+studio.
+
+Named volumes are kept. When a removed studio leaves volumes that no other
+studio mounts, rm warns before removing it; --purge-volumes deletes those
+volumes along with the studio and --keep-volumes keeps them without a warning.`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if all {
 				if len(args) > 0 {
@@ -786,17 +1291,33 @@ func newRemoveCmd() *cobra.Command {
 				}
 				return nil
 			}
-			return cobra.ExactArgs(1)(cmd, args)
+			return cobra.MinimumNArgs(1)(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 			mgr := getManager()
 			out := getOutput()
 
-			if all {
+			if all || len(args) > 1 {
+				if err := validateParallel(parallel); err != nil {
+					return err
+				}
+				names := args
+				if all {
+					if envs, err := mgr.List(ctx); err == nil {
+						for _, env := range envs {
+							names = append(names, env.Name)
+						}
+					}
+				}
+				orphans := orphanedVolumes(ctx, out, mgr, names, keepVolumes, purge)
 				if !force && !out.IsJSON() {
 					styles := tui.DefaultStyles()
-					fmt.Printf("%s Are you sure you want to remove ALL studio environments? [y/N]: ", styles.Warning.Render("!"))
+					if all {
+						fmt.Printf(i18n.T("%s Are you sure you want to remove ALL studio environments%s? [y/N]: "), styles.Warning.Render("!"), purgeSuffix(orphans, purge))
+					} else {
+						fmt.Printf(i18n.T("%s Are you sure you want to remove %d studio environments%s? [y/N]: "), styles.Warning.Render("!"), len(names), purgeSuffix(orphans, purge))
+					}
 					var confirm string
 					fmt.Scanln(&confirm)
 					if confirm != "y" && confirm != "Y" {
@@ -805,34 +1326,53 @@ func newRemoveCmd() *cobra.Command {
 					}
 				}
 
-				removedNames, err := mgr.RemoveAll(ctx)
+				batch := batchOptions(out, parallel, i18n.T("%s Removing %s...\n"))
+				var removedNames []string
+				var err error
+				if all {
+					removedNames, err = mgr.RemoveAll(ctx, batch)
+				} else {
+					removedNames, err = mgr.RemoveMany(ctx, names, batch)
+				}
+				for _, removedName := range removedNames {
+					if err := mgr.RemoveSSHConfig(removedName); err != nil {
+						klog.Warningf("Failed to remove SSH config for %s: error=%v", removedName, err)
+					}
+				}
 				if err != nil {
+					// Volumes of studios that failed to go are still in use
 					cmd.SilenceUsage = true
+					klog.Errorf("Failed to remove studios: removed=%d error=%v", len(removedNames), err)
 					return err
 				}
-				if len(removedNames) == 0 {
+				if all && len(removedNames) == 0 {
 					out.Info("No studio environments found")
 					return nil
 				}
-				for _, removedName := range removedNames {
-					if err := mgr.RemoveSSHConfig(removedName); err != nil {
-						klog.Warningf("Failed to remove SSH config for %s: error=%v", removedName, err)
-					}
+				if purge {
+					purgeVolumes(ctx, out, mgr, orphans)
 				}
 
+				id := "all"
+				if !all {
+					id = strings.Join(removedNames, ",")
+				}
 				return out.Render(&cmdutil.ActionData{
 					Success: true,
-					Message: fmt.Sprintf("Removed %d studio environment(s)", len(removedNames)),
-					ID:      "all",
+					Message: "Removed %d studio environment(s)",
+					Args:    []any{len(removedNames)},
+					ID:      id,
 				})
 			}
 
 			name := args[0]
+			orphans := orphanedVolumes(ctx, out, mgr, []string{name}, keepVolumes, purge)
 			if !force && !out.IsJSON() {
 				styles := tui.DefaultStyles()
-				fmt.Printf("%s Are you sure you want to remove environment %s? [y/N]: ",
+				fmt.Printf(i18n.T("%s Are you sure you want to remove environment %s%s? [y/N]: "),
 					styles.Warning.Render("!"),
-					styles.Bold.Render(name))
+					styles.Bold.Render(name),
+					purgeSuffix(orphans, purge))
 				var confirm string
 				fmt.Scanln(&confirm)
 				if confirm != "y" && confirm != "Y" {
@@ -849,10 +1389,14 @@ func newRemoveCmd() *cobra.Command {
 			if err := mgr.RemoveSSHConfig(name); err != nil {
 				klog.Warningf("Failed to remove SSH config: error=%v", err)
 			}
+			if purge {
+				purgeVolumes(ctx, out, mgr, orphans)
+			}
 
 			return out.Render(&cmdutil.ActionData{
 				Success: true,
-				Message: fmt.Sprintf("Environment '%s' removed", name),
+				Message: "Environment '%s' removed",
+				Args:    []any{name},
 				ID:      name,
 			})
 		},
@@ -860,14 +1404,62 @@ func newRemoveCmd() *cobra.Command {
 
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force remove")
 	cmd.Flags().BoolVar(&all, "all", false, "Remove all studio environments in one batch")
+	cmd.Flags().IntVarP(&parallel, "parallel", "j", studio.DefaultBatchConcurrency, "Studios removed at once when several are removed")
+	cmd.Flags().BoolVar(&keepVolumes, "keep-volumes", false, "Keep named volumes no other studio uses, without warning")
+	cmd.Flags().BoolVar(&purge, "purge-volumes", false, "Also delete named volumes no other studio uses")
+	cmd.MarkFlagsMutuallyExclusive("keep-volumes", "purge-volumes")
 	return cmd
 }
 
+// orphanedVolumes returns the named volumes that removing the studios leaves
+// unused, warning that they will be kept unless keep or purge is set
+func orphanedVolumes(ctx context.Context, out *tui.Output, mgr *studio.Manager, names []string, keep, purge bool) []*studio.NamedVolume {
+	if keep {
+		return nil
+	}
+	orphans, err := mgr.OrphanedVolumes(ctx, names)
+	if err != nil {
+		klog.Warningf("Failed to check for orphaned volumes: error=%v", err)
+		return nil
+	}
+	if len(orphans) > 0 && !purge {
+		out.Warningf("No other studio uses volume(s) %s; they will be kept. Pass --purge-volumes to delete them or --keep-volumes to silence this warning.", volumeNames(orphans))
+	}
+	return orphans
+}
+
+// purgeSuffix completes the removal prompt with the volumes deleted too
+func purgeSuffix(orphans []*studio.NamedVolume, purge bool) string {
+	if !purge || len(orphans) == 0 {
+		return ""
+	}
+	return " and delete volume(s) " + volumeNames(orphans)
+}
+
 func newSSHCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "ssh <name>",
+	var forwardAgent bool
+	var printOnly bool
+	var embedded bool
+
+	cmd := &cobra.Command{
+		Use:   "ssh <name> [-- command...]",
 		Short: "SSH into a studio environment",
-		Args:  cobra.ExactArgs(1),
+		Long: `Open an interactive SSH session into a studio environment.
+
+Uses the local ssh client when available and falls back to a built-in client
+otherwise (e.g. minimal Windows installs). Arguments after -- are run as a
+remote command and the remote exit status is returned.
+
+Examples:
+  # Open a shell
+  ggo studio ssh my-studio
+
+  # Run a command and exit
+  ggo studio ssh my-studio -- nvidia-smi
+
+  # Only print connection details
+  ggo studio ssh my-studio --print`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 			mgr := getManager()
@@ -884,9 +1476,37 @@ func newSSHCmd() *cobra.Command {
 				return fmt.Errorf("SSH not configured for this environment")
 			}
 
-			return out.Render(&sshResult{env: env})
+			if printOnly || out.IsJSON() {
+				return out.Render(&sshResult{env: env})
+			}
+
+			var remoteCmd []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				remoteCmd = args[dash:]
+			} else if len(args) > 1 {
+				remoteCmd = args[1:]
+			}
+
+			cmd.SilenceUsage = true
+			err = studio.RunSSHSession(ctx, env, studio.SSHSessionOptions{
+				ForwardAgent:  forwardAgent,
+				Command:       remoteCmd,
+				ForceEmbedded: embedded,
+			})
+			var exitErr *studio.SSHExitError
+			if errors.As(err, &exitErr) {
+				// main propagates the remote exit status like ssh does; the
+				// status itself is not an error worth printing
+				cmd.SilenceErrors = true
+			}
+			return err
 		},
 	}
+
+	cmd.Flags().BoolVarP(&forwardAgent, "forward-agent", "A", false, "Forward the local SSH agent")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the ssh command instead of connecting")
+	cmd.Flags().BoolVar(&embedded, "embedded", false, "Use the built-in SSH client even if ssh is installed")
+	return cmd
 }
 
 // sshResult implements Renderable for ssh command
@@ -914,7 +1534,7 @@ func (r *sshResult) RenderTUI(out *tui.Output) {
 	out.Println()
 	out.Println("  " + tui.Code(sshCmd))
 	out.Println()
-	out.Println(styles.Muted.Render(fmt.Sprintf("Please run: ssh -p %d %s@%s", r.env.SSHPort, r.env.SSHUser, r.env.SSHHost)))
+	out.Println(styles.Muted.Render(i18n.Tf("Please run: ssh -p %d %s@%s", r.env.SSHPort, r.env.SSHUser, r.env.SSHHost)))
 }
 
 func newLogsCmd() *cobra.Command {
@@ -1068,13 +1688,13 @@ func (r *tagsResult) RenderJSON() any {
 
 func (r *tagsResult) RenderTUI(out *tui.Output) {
 	if len(r.tags) == 0 {
-		out.Info(fmt.Sprintf("No tags found for '%s'", r.image))
+		out.Infof("No tags found for '%s'", r.image)
 		return
 	}
 
 	styles := tui.DefaultStyles()
 	out.Println()
-	out.Println(styles.Subtitle.Render(fmt.Sprintf("Tags for %s (%d)", r.image, len(r.tags))))
+	out.Println(styles.Subtitle.Render(i18n.Tf("Tags for %s (%d)", r.image, len(r.tags))))
 	out.Println()
 	for _, tag := range r.tags {
 		out.Printf("  %s\n", tag)
@@ -1084,45 +1704,73 @@ func (r *tagsResult) RenderTUI(out *tui.Output) {
 
 func newBackendsCmd() *cobra.Command {
 	var showAll bool
+	var showCapabilities bool
 
 	cmd := &cobra.Command{
 		Use:   "backends",
 		Short: "List available container/VM backends",
+		Long: `List available container/VM backends.
+
+With --capabilities, each available backend is probed for GPU passthrough,
+nested virtualization, cgroup v2, memory and how foreign-architecture images
+are emulated (QEMU or Rosetta).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 			mgr := getManager()
 			out := getOutput()
 
+			var capabilities map[string]*studio.CapabilityReport
+			probe := func(b studio.Backend) {
+				if showCapabilities {
+					if capabilities == nil {
+						capabilities = make(map[string]*studio.CapabilityReport)
+					}
+					capabilities[b.Name()] = studio.ProbeCapabilities(ctx, b)
+				}
+			}
+
 			if showAll {
 				statuses := mgr.ListAllBackends(ctx)
-				return out.Render(&allBackendsResult{statuses: statuses})
+				for _, s := range statuses {
+					if s.Available {
+						probe(s.Backend)
+					}
+				}
+				return out.Render(&allBackendsResult{statuses: statuses, capabilities: capabilities})
 			}
 
 			backends := mgr.ListAvailableBackends(ctx)
-			return out.Render(&backendsResult{backends: backends})
+			for _, b := range backends {
+				probe(b)
+			}
+			return out.Render(&backendsResult{backends: backends, capabilities: capabilities})
 		},
 	}
 
 	cmd.Flags().BoolVar(&showAll, "all", false, "Show all registered backends including unavailable ones")
+	cmd.Flags().BoolVar(&showCapabilities, "capabilities", false, "Probe available backends for GPU, virtualization and emulation support")
 
 	return cmd
 }
 
 // backendsResult implements Renderable for backends command
 type backendsResult struct {
-	backends []studio.Backend
+	backends     []studio.Backend
+	capabilities map[string]*studio.CapabilityReport
 }
 
 func (r *backendsResult) RenderJSON() any {
 	type backendInfo struct {
-		Name string `json:"name"`
-		Mode string `json:"mode"`
+		Name         string                   `json:"name"`
+		Mode         string                   `json:"mode"`
+		Capabilities *studio.CapabilityReport `json:"capabilities,omitempty"`
 	}
 	var result []backendInfo
 	for _, b := range r.backends {
 		result = append(result, backendInfo{
-			Name: b.Name(),
-			Mode: string(b.Mode()),
+			Name:         b.Name(),
+			Mode:         string(b.Mode()),
+			Capabilities: r.capabilities[b.Name()],
 		})
 	}
 	return tui.NewListResult(result)
@@ -1134,19 +1782,20 @@ func (r *backendsResult) RenderTUI(out *tui.Output) {
 	if len(r.backends) == 0 {
 		out.Warning("No backends available")
 		out.Println()
-		out.Println(styles.Subtitle.Render("Install one of the following:"))
+		out.Println(styles.Subtitle.Render(i18n.T("Install one of the following:")))
 		out.Println()
-		out.Println("  • " + styles.Bold.Render("Apple Container (macOS 26+):") + " " + tui.URL("https://github.com/apple/container/releases"))
-		out.Println("  • " + styles.Bold.Render("Docker:") + " " + tui.URL("https://docs.docker.com/get-docker/"))
-		out.Println("  • " + styles.Bold.Render("Colima (macOS):") + " " + tui.Code("brew install colima"))
-		out.Println("  • " + styles.Bold.Render("OrbStack (macOS):") + " " + tui.Code("brew install orbstack"))
-		out.Println("  • " + styles.Bold.Render("WSL (Windows):") + " " + tui.URL("https://docs.microsoft.com/en-us/windows/wsl/install"))
+		out.Println("  • " + styles.Bold.Render(i18n.T("Apple Container (macOS 26+):")) + " " + tui.URL("https://github.com/apple/container/releases"))
+		out.Println("  • " + styles.Bold.Render(i18n.T("Docker:")) + " " + tui.URL("https://docs.docker.com/get-docker/"))
+		out.Println("  • " + styles.Bold.Render(i18n.T("Podman (Linux):")) + " " + tui.URL("https://podman.io/docs/installation"))
+		out.Println("  • " + styles.Bold.Render(i18n.T("Colima (macOS):")) + " " + tui.Code("brew install colima"))
+		out.Println("  • " + styles.Bold.Render(i18n.T("OrbStack (macOS):")) + " " + tui.Code("brew install orbstack"))
+		out.Println("  • " + styles.Bold.Render(i18n.T("WSL (Windows):")) + " " + tui.URL("https://docs.microsoft.com/en-us/windows/wsl/install"))
 		out.Println()
 		return
 	}
 
 	out.Println()
-	out.Println(styles.Title.Render("Available Backends"))
+	out.Println(styles.Title.Render(i18n.T("Available Backends")))
 	out.Println()
 
 	var rows [][]string
@@ -1154,7 +1803,7 @@ func (r *backendsResult) RenderTUI(out *tui.Output) {
 		rows = append(rows, []string{
 			styles.Bold.Render(b.Name()),
 			string(b.Mode()),
-			styles.Success.Render("● available"),
+			styles.Success.Render(i18n.T("● available")),
 		})
 	}
 
@@ -1163,27 +1812,31 @@ func (r *backendsResult) RenderTUI(out *tui.Output) {
 		Rows(rows)
 
 	out.Println(table.String())
+	renderCapabilities(out, r.capabilities)
 }
 
 // allBackendsResult implements Renderable for backends --all command
 type allBackendsResult struct {
-	statuses []studio.BackendStatus
+	statuses     []studio.BackendStatus
+	capabilities map[string]*studio.CapabilityReport
 }
 
 func (r *allBackendsResult) RenderJSON() any {
 	type backendInfo struct {
-		Name      string `json:"name"`
-		Mode      string `json:"mode"`
-		Available bool   `json:"available"`
-		Installed bool   `json:"installed"`
+		Name         string                   `json:"name"`
+		Mode         string                   `json:"mode"`
+		Available    bool                     `json:"available"`
+		Installed    bool                     `json:"installed"`
+		Capabilities *studio.CapabilityReport `json:"capabilities,omitempty"`
 	}
 	var result []backendInfo
 	for _, s := range r.statuses {
 		result = append(result, backendInfo{
-			Name:      s.Backend.Name(),
-			Mode:      string(s.Backend.Mode()),
-			Available: s.Available,
-			Installed: s.Installed,
+			Name:         s.Backend.Name(),
+			Mode:         string(s.Backend.Mode()),
+			Available:    s.Available,
+			Installed:    s.Installed,
+			Capabilities: r.capabilities[s.Backend.Name()],
 		})
 	}
 	return tui.NewListResult(result)
@@ -1193,16 +1846,16 @@ func (r *allBackendsResult) RenderTUI(out *tui.Output) {
 	styles := tui.DefaultStyles()
 
 	out.Println()
-	out.Println(styles.Title.Render("All Backends"))
+	out.Println(styles.Title.Render(i18n.T("All Backends")))
 	out.Println()
 
 	var rows [][]string
 	for _, s := range r.statuses {
-		status := styles.Error.Render("○ not installed")
+		status := styles.Error.Render(i18n.T("○ not installed"))
 		if s.Available {
-			status = styles.Success.Render("● available")
+			status = styles.Success.Render(i18n.T("● available"))
 		} else if s.Installed {
-			status = styles.Warning.Render("◐ not running")
+			status = styles.Warning.Render(i18n.T("◐ not running"))
 		}
 		rows = append(rows, []string{
 			styles.Bold.Render(s.Backend.Name()),
@@ -1216,6 +1869,73 @@ func (r *allBackendsResult) RenderTUI(out *tui.Output) {
 		Rows(rows)
 
 	out.Println(table.String())
+	renderCapabilities(out, r.capabilities)
+}
+
+// renderCapabilities prints the capability table for probed backends
+func renderCapabilities(out *tui.Output, capabilities map[string]*studio.CapabilityReport) {
+	if len(capabilities) == 0 {
+		return
+	}
+	styles := tui.DefaultStyles()
+
+	names := make([]string, 0, len(capabilities))
+	for name := range capabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	yesNo := func(b bool) string {
+		if b {
+			return styles.Success.Render(i18n.T("yes"))
+		}
+		return styles.Muted.Render(i18n.T("no"))
+	}
+
+	var rows [][]string
+	var errs []string
+	for _, name := range names {
+		c := capabilities[name]
+		if c.Error != "" {
+			errs = append(errs, fmt.Sprintf("%s: %s", name, c.Error))
+			continue
+		}
+		memory := styles.Muted.Render("-")
+		if c.MaxMemoryBytes > 0 {
+			memory = fmt.Sprintf("%.1f GiB", float64(c.MaxMemoryBytes)/(1<<30))
+		}
+		var emulation []string
+		for p, e := range c.Emulation {
+			emulation = append(emulation, fmt.Sprintf("%s: %s", p, e))
+		}
+		sort.Strings(emulation)
+		emulationStr := styles.Muted.Render(i18n.T("unknown"))
+		if len(emulation) > 0 {
+			emulationStr = strings.Join(emulation, ", ")
+		}
+		rows = append(rows, []string{
+			styles.Bold.Render(name),
+			c.NativeArch,
+			yesNo(c.GPUPassthrough),
+			yesNo(c.NestedVirtualization),
+			yesNo(c.CgroupV2),
+			memory,
+			emulationStr,
+		})
+	}
+
+	out.Println()
+	out.Println(styles.Subtitle.Render(i18n.T("Capabilities")))
+	out.Println()
+	if len(rows) > 0 {
+		table := tui.NewTable().
+			Headers("NAME", "ARCH", "GPU", "NESTED VIRT", "CGROUP V2", "MEMORY", "EMULATION").
+			Rows(rows)
+		out.Println(table.String())
+	}
+	for _, e := range errs {
+		out.Warningf("Could not probe %s", e)
+	}
 }
 
 // Helper functions
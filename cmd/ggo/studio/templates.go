@@ -0,0 +1,147 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newTemplatesCmd() *cobra.Command {
+	var remote bool
+
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "List studio templates",
+		Long: `List the templates 'ggo studio create --template' accepts.
+
+A template bundles an image with recommended resources, ports, volumes and
+notes. The built-in templates cover the default images; --remote lists the
+curated templates of the platform registry, such as ready-made Stable
+Diffusion or fine-tuning setups.
+
+Examples:
+  # Built-in templates
+  ggo studio templates
+
+  # Templates from the platform registry
+  ggo studio templates --remote
+
+  # Create a studio from a registry template
+  ggo studio create my-env --template sd-webui -s abc123`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			templates := studio.DefaultTemplates()
+			if remote {
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+				resp, err := api.NewClient(api.WithBaseURL(serverURL)).ListStudioTemplates(ctx)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to list studio templates: server=%s error=%v", serverURL, err)
+					return fmt.Errorf("failed to list studio templates: %w", err)
+				}
+				templates = resp.Templates
+			}
+			return out.Render(&templatesResult{templates: templates, remote: remote})
+		},
+	}
+
+	cmd.Flags().BoolVar(&remote, "remote", false, "List the templates of the platform registry")
+	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL of the template registry")
+
+	return cmd
+}
+
+// templatesResult implements Renderable for the templates command
+type templatesResult struct {
+	templates []api.StudioTemplate
+	remote    bool
+}
+
+func (r *templatesResult) RenderJSON() any {
+	return tui.NewListResult(r.templates)
+}
+
+func (r *templatesResult) RenderTUI(out *tui.Output) {
+	if len(r.templates) == 0 {
+		out.Info("No studio templates found")
+		return
+	}
+
+	styles := tui.DefaultStyles()
+	var rows [][]string
+	for _, tmpl := range r.templates {
+		description := tmpl.Description
+		if tmpl.Title != "" {
+			description = tmpl.Title + ": " + description
+		}
+		rows = append(rows, []string{
+			styles.Bold.Render(tmpl.Name),
+			tmpl.Image,
+			templateResources(&tmpl),
+			description,
+		})
+	}
+	out.Println(tui.NewTable().Headers("NAME", "IMAGE", "RESOURCES", "DESCRIPTION").Rows(rows).String())
+	if !r.remote {
+		out.Println(tui.Muted(i18n.T("More templates: ggo studio templates --remote")))
+	}
+}
+
+// templateResources summarizes the recommended resources of a template
+func templateResources(tmpl *api.StudioTemplate) string {
+	var parts []string
+	if tmpl.CPUs > 0 {
+		parts = append(parts, i18n.Tf("%g CPUs", tmpl.CPUs))
+	}
+	if tmpl.Memory != "" {
+		parts = append(parts, i18n.Tf("%s memory", tmpl.Memory))
+	}
+	if tmpl.MinVRAMMb > 0 {
+		parts = append(parts, i18n.Tf("%d GB VRAM", tmpl.MinVRAMMb/1024))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// templateRef records the template a studio is created from, nil for none
+func templateRef(tmpl *api.StudioTemplate) *studio.TemplateRef {
+	if tmpl == nil {
+		return nil
+	}
+	return &studio.TemplateRef{Name: tmpl.Name, Title: tmpl.Title, Notes: tmpl.Notes}
+}
+
+// applyTemplate sets the create flags the user did not give from a template.
+// Ports and volumes are merged, with the user's replacing the template's for
+// the same container port or path, and so are environment variables.
+func applyTemplate(cmd *cobra.Command, tmpl *api.StudioTemplate) {
+	flags := cmd.Flags()
+	if !flags.Changed("image") && tmpl.Image != "" {
+		image = tmpl.Image
+	}
+	if !flags.Changed("cpus") && tmpl.CPUs > 0 {
+		cpus = tmpl.CPUs
+	}
+	if !flags.Changed("memory") && tmpl.Memory != "" {
+		memory = tmpl.Memory
+	}
+	if !flags.Changed("command") && len(tmpl.Command) > 0 {
+		command = tmpl.Command
+	}
+	ports = studio.MergePortArgs(tmpl.Ports, ports)
+	volumes = studio.MergeVolumeArgs(tmpl.Volumes, volumes)
+	// Later entries win in parseEnvVars
+	envVars = append(append([]string(nil), tmpl.Env...), envVars...)
+}
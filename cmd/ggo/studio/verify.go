@@ -0,0 +1,113 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify <name>",
+		Short: "Check that the remote GPU works inside a studio",
+		Long: `Run the GPU readiness probe that 'ggo studio create' runs after creating a
+studio. Inside the container, with the environment SSH sessions get, it checks
+that:
+
+  - the GPU client libraries are present and load
+  - the GPU worker accepts connections from the container
+  - nvidia-smi lists the remote GPUs (NVIDIA only)
+
+Failed checks come with a hint on how to fix them. The command exits with an
+error if any check fails.`,
+		Example: `  # Verify a studio after changing its network or share link
+  ggo studio verify my-env
+
+  # Machine-readable result
+  ggo studio verify my-env -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			probe, err := getManager().Verify(context.Background(), args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to verify studio: name=%s error=%v", args[0], err)
+				return err
+			}
+			if err := out.Render(&verifyResult{name: args[0], probe: probe}); err != nil {
+				return err
+			}
+			if probe.Error != "" || !probe.Passed {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("GPU readiness probe failed for studio %s", args[0])
+			}
+			return nil
+		},
+	}
+}
+
+// verifyResult implements Renderable for the verify command
+type verifyResult struct {
+	name  string
+	probe *studio.ProbeResult
+}
+
+func (r *verifyResult) RenderJSON() any {
+	return r.probe
+}
+
+func (r *verifyResult) RenderTUI(out *tui.Output) {
+	renderProbe(out, r.probe)
+	out.Println()
+}
+
+// probeCheckLabel names a readiness check in the TUI
+func probeCheckLabel(name string) string {
+	switch name {
+	case studio.ProbeCheckLibraries:
+		return i18n.T("GPU client libraries")
+	case studio.ProbeCheckConnection:
+		return i18n.T("GPU worker connection")
+	case studio.ProbeCheckDevices:
+		return i18n.T("Device list")
+	}
+	return name
+}
+
+// renderProbe prints the outcome of a GPU readiness probe, with hints below
+// failed checks
+func renderProbe(out *tui.Output, probe *studio.ProbeResult) {
+	styles := tui.DefaultStyles()
+	out.Println()
+	out.Println(styles.Subtitle.Render(i18n.T("GPU Readiness")))
+	out.Println()
+
+	if probe.Error != "" {
+		out.Println("  " + styles.Error.Render("✗ "+probe.Error))
+		return
+	}
+	for _, c := range probe.Checks {
+		var icon string
+		switch c.Status {
+		case studio.ProbePass:
+			icon = styles.Success.Render("✓")
+		case studio.ProbeFail:
+			icon = styles.Error.Render("✗")
+		default:
+			icon = styles.Muted.Render("-")
+		}
+		line := fmt.Sprintf("  %s %s", icon, probeCheckLabel(c.Name))
+		if c.Detail != "" {
+			line += tui.Muted(" · " + c.Detail)
+		}
+		out.Println(line)
+		if c.Hint != "" {
+			out.Println("      " + styles.Warning.Render(c.Hint))
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package studio
+
+import (
+	"context"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+func newVolumeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "volume",
+		Aliases: []string{"volumes"},
+		Short:   "Manage named volumes of studio environments",
+		Long: `Manage named volumes, which keep data independently of the studios mounting
+them. Mount one with 'ggo studio create -v <volume>:<path>'; volumes that do
+not exist yet are created and labelled with the studio.
+
+'ggo studio rm' keeps the named volumes of a removed studio and warns when no
+other studio uses them; pass --purge-volumes to delete them along with it.`,
+		Example: `  # Create a volume and mount it in a studio
+  ggo studio volume create datasets
+  ggo studio create my-env -v datasets:/data
+
+  # List volumes and the studios using them
+  ggo studio volume ls
+
+  # Remove an unused volume
+  ggo studio volume rm datasets`,
+	}
+	cmd.PersistentFlags().StringVarP(&mode, "mode", "m", "", "Container/VM mode (wsl, colima, docker, podman, auto)")
+	cmd.PersistentFlags().StringVar(&colimaProfile, "colima-profile", "", "Colima profile name (default: 'default')")
+	cmd.PersistentFlags().StringVar(&wslDistro, "wsl-distro", "", "WSL distribution name (default: use default distro)")
+	cmd.PersistentFlags().StringVar(&dockerHost, "docker-host", "", "Custom Docker socket path (e.g., unix:///path/to/docker.sock)")
+
+	cmd.AddCommand(cmdutil.Audited(newVolumeCreateCmd()))
+	cmd.AddCommand(newVolumeListCmd())
+	cmd.AddCommand(cmdutil.Audited(newVolumeRemoveCmd()))
+	return cmd
+}
+
+// volumeMode returns the mode selected with --mode
+func volumeMode() studio.Mode {
+	if mode == "" {
+		return studio.ModeAuto
+	}
+	return studio.Mode(mode)
+}
+
+func newVolumeCreateCmd() *cobra.Command {
+	var forStudio string
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a named volume",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			volume, err := getManager().CreateVolume(context.Background(), volumeMode(), args[0], forStudio)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to create volume: name=%s error=%v", args[0], err)
+				return err
+			}
+			return getOutput().Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Volume '%s' created (%s)",
+				Args:    []any{volume.Name, volume.Mode},
+				ID:      volume.Name,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&forStudio, "studio", "", "Label the volume as belonging to this studio")
+	return cmd
+}
+
+func newVolumeListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List named volumes created by ggo",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			volumes, err := getManager().ListVolumes(context.Background(), volumeMode())
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to list volumes: error=%v", err)
+				return err
+			}
+			return getOutput().Render(&volumeListResult{volumes: volumes})
+		},
+	}
+}
+
+// volumeListResult implements Renderable for volume list
+type volumeListResult struct {
+	volumes []*studio.NamedVolume
+}
+
+func (r *volumeListResult) RenderJSON() any {
+	return tui.NewListResult(r.volumes)
+}
+
+func (r *volumeListResult) RenderTUI(out *tui.Output) {
+	if len(r.volumes) == 0 {
+		out.Info("No volumes found")
+		return
+	}
+
+	styles := tui.DefaultStyles()
+	var rows [][]string
+	for _, v := range r.volumes {
+		owner := v.Studio
+		if owner == "" {
+			owner = "-"
+		}
+		usedBy := styles.Warning.Render(i18n.T("unused"))
+		if len(v.UsedBy) > 0 {
+			usedBy = strings.Join(v.UsedBy, ", ")
+		}
+		rows = append(rows, []string{styles.Bold.Render(v.Name), string(v.Mode), v.Driver, owner, usedBy})
+	}
+
+	out.Println(tui.NewTable().
+		Headers("NAME", "MODE", "DRIVER", "STUDIO", "USED BY").
+		Rows(rows).String())
+}
+
+func newVolumeRemoveCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:     "rm <name>...",
+		Aliases: []string{"remove", "delete"},
+		Short:   "Remove named volumes and their data",
+		Long: `Remove named volumes and the data on them. Volumes mounted by a studio,
+running or not, are refused; remove the studio first or pass --force to let the
+container runtime decide.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			mgr := getManager()
+			out := getOutput()
+
+			var removed []string
+			for _, name := range args {
+				if err := mgr.RemoveVolume(ctx, volumeMode(), name, force); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to remove volume: name=%s error=%v", name, err)
+					return err
+				}
+				removed = append(removed, name)
+			}
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: "Removed volume(s) %s",
+				Args:    []any{strings.Join(removed, ", ")},
+				ID:      strings.Join(removed, ","),
+			})
+		},
+	}
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Remove volumes even if studios still mount them")
+	return cmd
+}
+
+// volumeNames returns the names of volumes
+func volumeNames(volumes []*studio.NamedVolume) string {
+	names := make([]string, len(volumes))
+	for i, v := range volumes {
+		names[i] = v.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// purgeVolumes removes the named volumes left unused by removed studios
+func purgeVolumes(ctx context.Context, out *tui.Output, mgr *studio.Manager, orphans []*studio.NamedVolume) {
+	for _, v := range orphans {
+		if err := mgr.RemoveVolume(ctx, v.Mode, v.Name, false); err != nil {
+			klog.Warningf("Failed to remove volume: name=%s error=%v", v.Name, err)
+			out.Warningf("Failed to remove volume %s: %v", v.Name, err)
+		}
+	}
+}
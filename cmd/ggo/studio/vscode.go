@@ -0,0 +1,232 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// remoteSSHExtension is the VS Code extension that opens ssh-remote folders
+	remoteSSHExtension = "ms-vscode-remote.remote-ssh"
+
+	// defaultRemoteFolder is opened when neither the command nor the
+	// environment names a folder
+	defaultRemoteFolder = "/workspace"
+
+	// vscodeCLITimeout bounds calls to the code CLI, which returns as soon as
+	// the window was handed to VS Code
+	vscodeCLITimeout = 30 * time.Second
+)
+
+func newCodeCmd() *cobra.Command {
+	var codeBin string
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:     "code <name> [path]",
+		Aliases: []string{"vscode"},
+		Short:   "Open a studio environment in VS Code",
+		Long: `Open a folder of a studio environment in VS Code over Remote-SSH.
+
+Writes the environment's 'ggo-<name>' entry to the SSH config, checks that
+the Remote-SSH extension is installed and runs
+'code --remote ssh-remote+ggo-<name> <path>'. The path defaults to the
+environment's working directory, or /workspace.
+
+Without the code command on PATH (or with --print) the vscode:// link is
+printed instead; open it in a browser or with the OS URL handler.
+
+Examples:
+  # Open /workspace of my-studio
+  ggo studio code my-studio
+
+  # Open another folder
+  ggo studio code my-studio /root/project
+
+  # Use VS Code Insiders
+  ggo studio code my-studio --code-bin code-insiders`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			mgr := getManager()
+			out := getOutput()
+
+			env, err := mgr.Get(ctx, args[0])
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if env.SSHPort == 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("SSH not configured for this environment")
+			}
+			if env.Status != studio.StatusRunning {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("environment '%s' is %s; start it with 'ggo studio start %s'", env.Name, env.Status, env.Name)
+			}
+
+			if err := mgr.AddSSHConfig(env); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to add SSH config: name=%s error=%v", env.Name, err)
+				return err
+			}
+
+			folder := defaultRemoteFolder
+			if env.WorkDir != "" {
+				folder = env.WorkDir
+			}
+			if len(args) > 1 {
+				folder = args[1]
+			}
+			result := &codeResult{
+				host:   fmt.Sprintf("ggo-%s", env.Name),
+				folder: folder,
+			}
+
+			if !printOnly {
+				bin := findVSCode(codeBin)
+				if bin == "" {
+					klog.Infof("VS Code CLI not found, printing link instead")
+					result.note = "The 'code' command was not found. Add it to PATH from VS Code (Command Palette: \"Shell Command: Install 'code' command in PATH\") or open the link below."
+				} else if err := launchVSCode(ctx, bin, result); err != nil {
+					cmd.SilenceUsage = true
+					if help := result.installHelp(bin); help != "" && !out.IsJSON() {
+						out.Println(help)
+					}
+					klog.Errorf("Failed to open VS Code: name=%s error=%v", env.Name, err)
+					return err
+				}
+			}
+
+			return out.Render(result)
+		},
+	}
+
+	cmd.Flags().StringVar(&codeBin, "code-bin", "", "VS Code CLI to use (default: code, then code-insiders)")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the vscode:// link instead of launching VS Code")
+	return cmd
+}
+
+// findVSCode returns the VS Code CLI to use, or "" when none is installed.
+// On macOS the CLI inside the app bundle is used when it was never added to
+// PATH.
+func findVSCode(preferred string) string {
+	candidates := []string{"code", "code-insiders"}
+	if preferred != "" {
+		candidates = []string{preferred}
+	}
+	for _, name := range candidates {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	if preferred == "" && runtime.GOOS == "darwin" {
+		for _, app := range []string{"Visual Studio Code.app", "Visual Studio Code - Insiders.app"} {
+			for _, root := range []string{"/Applications", filepath.Join(os.Getenv("HOME"), "Applications")} {
+				name := "code"
+				if strings.Contains(app, "Insiders") {
+					name = "code-insiders"
+				}
+				path := filepath.Join(root, app, "Contents", "Resources", "app", "bin", name)
+				if _, err := os.Stat(path); err == nil {
+					return path
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// hasVSCodeExtension reports whether the VS Code behind bin has the extension
+func hasVSCodeExtension(ctx context.Context, bin, extension string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, vscodeCLITimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, bin, "--list-extensions").Output()
+	if err != nil {
+		return false, err
+	}
+	installed := strings.Fields(strings.ToLower(string(output)))
+	return slices.Contains(installed, strings.ToLower(extension)), nil
+}
+
+// launchVSCode opens the remote folder after checking for Remote-SSH. A
+// failed extension check is only logged, since VS Code offers the install
+// itself when it can.
+func launchVSCode(ctx context.Context, bin string, r *codeResult) error {
+	ok, err := hasVSCodeExtension(ctx, bin, remoteSSHExtension)
+	switch {
+	case err != nil:
+		klog.Warningf("Failed to list VS Code extensions: bin=%s error=%v", bin, err)
+	case !ok:
+		r.missingExtension = true
+		return fmt.Errorf("VS Code extension %s is not installed", remoteSSHExtension)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, vscodeCLITimeout)
+	defer cancel()
+	command := exec.CommandContext(ctx, bin, "--remote", "ssh-remote+"+r.host, r.folder)
+	if output, err := command.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s --remote failed: %w: %s", filepath.Base(bin), err, strings.TrimSpace(string(output)))
+	}
+	r.launched = true
+	return nil
+}
+
+// codeResult implements Renderable for the code command
+type codeResult struct {
+	host             string
+	folder           string
+	launched         bool
+	missingExtension bool
+	note             string
+}
+
+// uri is the link that opens the folder from a browser or the OS; the
+// folder is escaped so paths with spaces stay one link
+func (r *codeResult) uri() string {
+	folder := &url.URL{Path: "/" + strings.TrimPrefix(r.folder, "/")}
+	return "vscode://vscode-remote/ssh-remote+" + r.host + folder.EscapedPath()
+}
+
+func (r *codeResult) installHelp(bin string) string {
+	if !r.missingExtension {
+		return ""
+	}
+	return fmt.Sprintf("The Remote-SSH extension is required. Install it with:\n\n  %s\n\nor from the Extensions view (search 'Remote - SSH'), then run this command again.",
+		tui.Code(filepath.Base(bin)+" --install-extension "+remoteSSHExtension))
+}
+
+func (r *codeResult) RenderJSON() any {
+	return map[string]any{
+		"host":     r.host,
+		"folder":   r.folder,
+		"uri":      r.uri(),
+		"launched": r.launched,
+	}
+}
+
+func (r *codeResult) RenderTUI(out *tui.Output) {
+	if r.launched {
+		out.Successf("Opening %s on %s in VS Code", r.folder, r.host)
+		return
+	}
+	if r.note != "" {
+		out.Warning(r.note)
+		out.Println()
+	}
+	out.Println("  " + tui.URL(r.uri()))
+}
@@ -0,0 +1,63 @@
+package studio
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeResultURI(t *testing.T) {
+	r := &codeResult{host: "ggo-dev", folder: "/workspace"}
+	assert.Equal(t, "vscode://vscode-remote/ssh-remote+ggo-dev/workspace", r.uri())
+
+	r.folder = "workspace/src"
+	assert.Equal(t, "vscode://vscode-remote/ssh-remote+ggo-dev/workspace/src", r.uri(), "relative folders get a leading slash")
+
+	r.folder = "/root/my project"
+	assert.Equal(t, "vscode://vscode-remote/ssh-remote+ggo-dev/root/my%20project", r.uri())
+}
+
+// fakeVSCodePath puts executables with the given names on an otherwise
+// empty PATH
+func fakeVSCodePath(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755))
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("HOME", t.TempDir())
+	return dir
+}
+
+func TestFindVSCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake executables need PATHEXT on Windows")
+	}
+	dir := fakeVSCodePath(t, "code", "code-insiders", "codium")
+	assert.Equal(t, filepath.Join(dir, "code"), findVSCode(""), "code comes first")
+	assert.Equal(t, filepath.Join(dir, "codium"), findVSCode("codium"), "the preferred binary wins")
+
+	dir = fakeVSCodePath(t, "code-insiders")
+	assert.Equal(t, filepath.Join(dir, "code-insiders"), findVSCode(""), "falls back to code-insiders")
+	assert.Empty(t, findVSCode("codium"), "a preferred binary has no fallback")
+
+	if runtime.GOOS != "darwin" {
+		fakeVSCodePath(t)
+		assert.Empty(t, findVSCode(""))
+	}
+}
+
+func TestCodeResultInstallHelp(t *testing.T) {
+	r := &codeResult{host: "ggo-dev", folder: "/workspace"}
+	assert.Empty(t, r.installHelp("/usr/bin/code"), "no help unless the extension is missing")
+
+	r.missingExtension = true
+	help := r.installHelp("/usr/bin/code-insiders")
+	assert.Contains(t, help, "Remote-SSH extension is required")
+	assert.Contains(t, help, "code-insiders --install-extension "+remoteSSHExtension)
+}
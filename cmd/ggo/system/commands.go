@@ -9,9 +9,12 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
 	"github.com/NexusGPU/gpu-go/internal/api"
 	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/credentials"
 	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 	"github.com/NexusGPU/gpu-go/internal/platform"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -40,11 +43,11 @@ func NewUpdateCmd() *cobra.Command {
 			}
 
 			// Step 2: Update dependencies
-			fmt.Println("\nUpdating dependencies...")
+			fmt.Println(i18n.T("\nUpdating dependencies..."))
 			if err := updateDeps(); err != nil {
 				klog.Warningf("Failed to update dependencies: %v", err)
-				fmt.Printf("Warning: dependency update failed: %v\n", err)
-				fmt.Println("You can update dependencies manually with: ggo deps update -y")
+				fmt.Printf(i18n.T("Warning: dependency update failed: %v\n"), err)
+				fmt.Println(i18n.T("You can update dependencies manually with: ggo deps update -y"))
 			}
 
 			return nil
@@ -73,11 +76,11 @@ func updateDeps() error {
 	}
 
 	if len(diff.ToDownload) == 0 {
-		fmt.Println("All dependencies are up to date!")
+		fmt.Println(i18n.T("All dependencies are up to date!"))
 		return nil
 	}
 
-	fmt.Printf("Downloading %d dependency update(s)...\n", len(diff.ToDownload))
+	fmt.Printf(i18n.T("Downloading %d dependency update(s)...\n"), len(diff.ToDownload))
 	progressFn := func(lib deps.Library, downloaded, total int64) {
 		if total > 0 {
 			pct := float64(downloaded) / float64(total) * 100
@@ -86,7 +89,7 @@ func updateDeps() error {
 	}
 	results, err := mgr.DownloadAllRequired(ctx, progressFn)
 	if err != nil {
-		return fmt.Errorf("failed to download dependencies: %w", err)
+		return cmdutil.DependencyError(fmt.Errorf("failed to download dependencies: %w", err))
 	}
 
 	fmt.Println()
@@ -96,7 +99,7 @@ func updateDeps() error {
 			successCount++
 		}
 	}
-	fmt.Printf("Dependencies updated: %d/%d successful\n", successCount, len(results))
+	fmt.Printf(i18n.T("Dependencies updated: %d/%d successful\n"), successCount, len(results))
 	return nil
 }
 
@@ -148,7 +151,7 @@ func cleanupDataDirs() {
 	userDir := paths.UserDir()
 	if userDir != "" {
 		if _, err := os.Stat(userDir); err == nil {
-			fmt.Printf("Removing %s...\n", userDir)
+			fmt.Printf(i18n.T("Removing %s...\n"), userDir)
 			if err := os.RemoveAll(userDir); err != nil {
 				klog.Warningf("Failed to remove %s: %v", userDir, err)
 			}
@@ -178,14 +181,14 @@ func cleanupDataDirs() {
 		}
 	}
 
-	fmt.Printf("Removing %s (requires sudo)...\n", rootGpugoDir)
+	fmt.Printf(i18n.T("Removing %s (requires sudo)...\n"), rootGpugoDir)
 	rmCmd := exec.Command("sudo", "rm", "-rf", rootGpugoDir)
 	rmCmd.Stdout = os.Stdout
 	rmCmd.Stderr = os.Stderr
 	rmCmd.Stdin = os.Stdin
 	if err := rmCmd.Run(); err != nil {
 		klog.Warningf("Failed to remove %s: %v", rootGpugoDir, err)
-		fmt.Printf("Warning: failed to remove %s, you may need to run: sudo rm -rf %s\n", rootGpugoDir, rootGpugoDir)
+		fmt.Printf(i18n.T("Warning: failed to remove %s, you may need to run: sudo rm -rf %s\n"), rootGpugoDir, rootGpugoDir)
 	}
 }
 
@@ -261,7 +264,7 @@ func unregisterAgentFromServer(cfg *config.Config) {
 		srvURL = api.GetDefaultBaseURL()
 	}
 
-	fmt.Printf("Unregistering agent %s...\n", cfg.AgentID)
+	fmt.Printf(i18n.T("Unregistering agent %s...\n"), cfg.AgentID)
 	client := api.NewClient(
 		api.WithBaseURL(srvURL),
 		api.WithAgentSecret(cfg.AgentSecret),
@@ -269,9 +272,9 @@ func unregisterAgentFromServer(cfg *config.Config) {
 
 	if err := client.SelfDeleteAgent(context.Background(), cfg.AgentID); err != nil {
 		klog.Warningf("Failed to unregister agent from server: agent_id=%s error=%v", cfg.AgentID, err)
-		fmt.Printf("Warning: could not unregister agent from server: %v\n", err)
+		fmt.Printf(i18n.T("Warning: could not unregister agent from server: %v\n"), err)
 	} else {
-		fmt.Printf("Agent %s unregistered from server\n", cfg.AgentID)
+		fmt.Printf(i18n.T("Agent %s unregistered from server\n"), cfg.AgentID)
 	}
 }
 
@@ -295,6 +298,11 @@ func tryUnregisterRootAgent() {
 	if cfg.AgentID == "" || cfg.AgentSecret == "" {
 		return
 	}
+	// The secret lives in root's OS keyring, which this user cannot read
+	if credentials.IsRef(cfg.AgentSecret) {
+		fmt.Printf(i18n.T("Warning: root agent %s keeps its secret in the OS keyring; run uninstall with sudo to unregister it\n"), cfg.AgentID)
+		return
+	}
 
 	unregisterAgentFromServer(&cfg)
 }
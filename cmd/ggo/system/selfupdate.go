@@ -0,0 +1,142 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/version"
+	"github.com/NexusGPU/gpu-go/internal/agent"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/progress"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// stepSelfUpdate is the progress event step of self-update
+const stepSelfUpdate = "self-update"
+
+// NewSelfUpdateCmd creates the self-update command.
+func NewSelfUpdateCmd() *cobra.Command {
+	var channel string
+	var force bool
+	var restartAgent bool
+	var insecure bool
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Replace this ggo binary with the latest release",
+		Long: `Download the latest ggo release for this platform from the releases API,
+verify its publisher signature and SHA256 checksum, and atomically replace
+the running binary. The previous binary is restored if the replacement fails.
+
+Unlike 'ggo update', no install script or package manager is involved and
+dependencies are left untouched. The machine's deps release channel is used
+unless --channel is given.
+
+An agent running on this machine is restarted on the new binary with its
+workers kept running (see 'ggo agent restart --preserve-workers'), unless
+--restart-agent=false is given.`,
+		Example: `  # Update to the latest stable release
+  ggo self-update
+
+  # Follow the beta channel
+  ggo self-update --channel beta`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmdutil.NewOutput(outputFormat)
+			ctx := context.Background()
+
+			if channel != "" {
+				parsed, err := deps.ParseChannel(channel)
+				if err != nil {
+					return cmdutil.UsageError(err)
+				}
+				channel = parsed
+			}
+
+			exePath, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to locate ggo binary: %w", err)
+			}
+			deps.CleanSelfUpdateBackup(exePath)
+
+			mgr := deps.NewManager(deps.WithChannel(channel), deps.WithInsecureSkipSignature(insecure))
+			latest, err := mgr.LatestCLIRelease(ctx, runtime.GOOS, runtime.GOARCH)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to check for updates: error=%v", err)
+				return err
+			}
+			if latest == nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("no ggo release found for %s/%s", runtime.GOOS, runtime.GOARCH)
+			}
+
+			if !force && !version.UpdateAvailable(latest.Version) {
+				return out.Render(&cmdutil.ActionData{
+					Success: true,
+					Message: "ggo %s is already up to date",
+					Args:    []any{version.Version},
+				})
+			}
+
+			if !out.IsJSON() {
+				fmt.Printf(i18n.T("Updating ggo %s -> %s...\n"), version.Version, latest.Version)
+			}
+			progressFn := func(downloaded, total int64) {
+				progress.Update(stepSelfUpdate, latest.Version, downloaded, total, progress.UnitBytes)
+				if !out.IsJSON() && total > 0 {
+					pct := float64(downloaded) / float64(total) * 100
+					fmt.Printf(i18n.T("\r  Downloading: %.1f%%"), pct)
+				}
+			}
+			progress.StepStarted(stepSelfUpdate, "Updating ggo to "+latest.Version)
+			err = mgr.SelfUpdate(ctx, *latest, exePath, progressFn)
+			if !out.IsJSON() && latest.Size > 0 {
+				fmt.Println()
+			}
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to update ggo: version=%s error=%v", latest.Version, err)
+				progress.Failed(stepSelfUpdate, err)
+				return err
+			}
+			progress.StepFinished(stepSelfUpdate, "ggo updated to "+latest.Version)
+
+			message := fmt.Sprintf("ggo updated to %s", latest.Version)
+			if restartAgent && agent.GetLocalStatus(platform.DefaultPaths()).Running {
+				err := agent.RequestRestart(platform.DefaultPaths().StateDir(), agent.RestartRequest{
+					RequestedAt:     time.Now(),
+					PreserveWorkers: true,
+				})
+				if err != nil {
+					klog.Warningf("Failed to restart agent on the new binary: error=%v", err)
+					message += "; restart the agent with 'ggo agent restart --preserve-workers'"
+				} else {
+					message += "; the agent is restarting with its workers kept running"
+				}
+			}
+
+			return out.Render(&cmdutil.ActionData{
+				Success: true,
+				Message: message,
+				ID:      latest.Version,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&channel, "channel", "", "Release channel to update from (stable, beta, nightly)")
+	cmd.Flags().BoolVar(&force, "force", false, "Reinstall even if already up to date")
+	cmd.Flags().BoolVar(&restartAgent, "restart-agent", true, "Restart a running agent on the new binary, keeping its workers running")
+	cmd.Flags().BoolVar(&insecure, "insecure-skip-signature", false, "Skip verifying the publisher signature of downloaded artifacts, for development (or set GGO_INSECURE_SKIP_SIGNATURE=1)")
+	cmdutil.AddOutputFlag(cmd, &outputFormat)
+
+	return cmd
+}
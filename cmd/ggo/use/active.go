@@ -0,0 +1,372 @@
+package use
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"golang.org/x/term"
+)
+
+// restoreFileEnv names the script a stacked activation writes to return the
+// shell to the environment it was put on
+const restoreFileEnv = "_GGO_RESTORE_FILE"
+
+// activationMode is what 'ggo use' does about a GPU Go environment the
+// calling shell is already activated for
+type activationMode int
+
+const (
+	// activateNew is for a shell without a GPU Go environment
+	activateNew activationMode = iota
+	// activateReplace deactivates the current environment first
+	activateReplace
+	// activateStack deactivates it too, but clean returns to it
+	activateStack
+	// activateAbort leaves the shell alone
+	activateAbort
+)
+
+// takeover says how an activation treats the GPU Go environment the shell
+// already has. The zero value is for a shell without one.
+type takeover struct {
+	// unwind deactivates the current environment, with every layer stacked
+	// under it, before activating
+	unwind bool
+	// restoreFile is sourced by clean to return to the current environment
+	restoreFile string
+}
+
+// activeEnv describes the GPU Go environment the calling shell is activated for
+type activeEnv struct {
+	// Name is the environment's connection name; environments activated by
+	// an older ggo have none
+	Name      string
+	ShortCode string
+	WorkerID  string
+}
+
+// currentActivation returns the GPU Go environment the calling shell is
+// activated for, or nil
+func currentActivation() *activeEnv {
+	if os.Getenv("_GGO_ACTIVE") == "" {
+		return nil
+	}
+	active := &activeEnv{Name: os.Getenv(studio.ConnectionEnv)}
+	if active.Name == "" {
+		return active
+	}
+	if conn, err := studio.NewUseRegistry(paths).Get(active.Name); err == nil && conn != nil {
+		active.ShortCode, active.WorkerID = conn.ShortCode, conn.WorkerID
+	}
+	return active
+}
+
+// String returns the environment's name, with its share and worker when known
+func (a *activeEnv) String() string {
+	if a.Name == "" {
+		return i18n.T("an unnamed GPU environment")
+	}
+	var details []string
+	if a.ShortCode != "" && a.ShortCode != a.Name {
+		details = append(details, "share "+a.ShortCode)
+	}
+	if a.WorkerID != "" {
+		details = append(details, "worker "+a.WorkerID)
+	}
+	if len(details) == 0 {
+		return a.Name
+	}
+	return fmt.Sprintf("%s (%s)", a.Name, strings.Join(details, ", "))
+}
+
+// resolveActivationMode decides what happens to active, the environment the
+// shell already has, when a new one is activated. A new shell started by
+// 'ggo use' leaves it behind and returns to it on exit. Activating the
+// calling shell with -y takes --replace or --stack, or asks on the terminal;
+// without one it fails rather than stack environments blindly. Everything is
+// printed to stderr, since stdout is evaluated by the shell.
+func resolveActivationMode(active *activeEnv, replace, stack, yes bool, out *tui.Output) (activationMode, error) {
+	if active == nil {
+		return activateNew, nil
+	}
+	if !yes {
+		if !out.IsJSON() {
+			fmt.Fprintf(os.Stderr, i18n.T("This shell uses %s; the new shell starts without it, and it is back when you exit.\n"), active)
+		}
+		return activateReplace, nil
+	}
+	switch {
+	case replace:
+		return activateReplace, nil
+	case stack:
+		return activateStack, nil
+	}
+	if out.IsJSON() || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return activateAbort, cmdutil.ConflictError(fmt.Errorf(
+			"this shell already uses %s; pass --replace to switch to the new environment, --stack to return to it on 'ggo clean', or run 'ggo clean' first", active))
+	}
+
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, i18n.T("This shell already uses %s.\n"), active)
+	fmt.Fprintln(os.Stderr, i18n.T("  r  replace it with the new environment"))
+	fmt.Fprintln(os.Stderr, i18n.T("  s  stack the new environment on it; 'ggo clean' returns to it"))
+	fmt.Fprintln(os.Stderr, i18n.T("  a  abort and keep it"))
+	fmt.Fprint(os.Stderr, i18n.T("Replace, stack or abort? [R/s/a]: "))
+
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	mode := parseActivationChoice(response)
+	if mode == activateAbort {
+		fmt.Fprintln(os.Stderr, i18n.T("Cancelled, the shell keeps its GPU environment"))
+	}
+	return mode, nil
+}
+
+// parseActivationChoice reads the answer to the replace / stack / abort
+// prompt; an empty answer replaces and anything unknown aborts
+func parseActivationChoice(response string) activationMode {
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "", "r", "replace":
+		return activateReplace
+	case "s", "stack":
+		return activateStack
+	default:
+		return activateAbort
+	}
+}
+
+// envSnapshot holds the variables of an activation at one point in time
+type envSnapshot struct {
+	// names lists every variable saved, sorted
+	names []string
+	// vars holds the values of those that were set; the others were unset
+	vars map[string]string
+	// pathAdded and wslenvAdded are the PATH entries and WSLENV names the
+	// activation added on Windows, which are restored in place rather than
+	// by overwriting PATH and WSLENV
+	pathAdded   string
+	wslenvAdded string
+}
+
+// activationVars returns the variables an activation sets on this platform,
+// besides those an activation for WSL passes on. PATH and WSLENV are left out
+// on Windows, where activation only adds entries to them.
+func activationVars() []string {
+	vars := []string{
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO", "TF_CONNECTION_INFO_PATH",
+		"TF_LOG_PATH", "TF_LOG_LEVEL", "TF_ENABLE_LOG", "TF_GPU_VENDOR",
+		studio.ConnectionEnv, restoreFileEnv,
+		"_GGO_ACTIVE", "_GGO_LIBS_PATH", "_GGO_BIN_PATH", "_GGO_CLEAN_FILE",
+	}
+	if platform.IsWindows() {
+		return append(vars, "CUDA_PATH", "CUDA_HOME", "_GGO_ORIG_PATH", "_GGO_PATH_ADDED", "_GGO_WSLENV_ADDED")
+	}
+	return append(vars, "PATH", "LD_LIBRARY_PATH", "LD_PRELOAD",
+		"_GGO_ORIG_LD_LIBRARY_PATH", "_GGO_ORIG_LD_PRELOAD", "_GGO_ORIG_PATH")
+}
+
+// snapshotActivation saves the current activation's variables, to restore
+// them when an activation stacked on it is cleaned
+func snapshotActivation() *envSnapshot {
+	snap := &envSnapshot{
+		vars:        make(map[string]string),
+		pathAdded:   os.Getenv("_GGO_PATH_ADDED"),
+		wslenvAdded: os.Getenv("_GGO_WSLENV_ADDED"),
+	}
+	names := activationVars()
+	for _, name := range strings.Split(snap.wslenvAdded, ":") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			snap.vars[name] = value
+		}
+	}
+	sort.Strings(names)
+	snap.names = names
+	return snap
+}
+
+// label returns the name of the environment snap was taken of
+func (s *envSnapshot) label() string {
+	if name := s.vars[studio.ConnectionEnv]; name != "" {
+		return name
+	}
+	return "the previous GPU environment"
+}
+
+// unwindActivation deactivates the GPU Go environment in the environment of
+// this process the way clean does in the shell, so that the new activation
+// builds on the shell's original PATH and library paths
+func unwindActivation() {
+	if platform.IsWindows() {
+		if added := os.Getenv("_GGO_PATH_ADDED"); added != "" {
+			_ = os.Setenv("PATH", removePathEntries(os.Getenv("PATH"), strings.Split(added, ";")))
+		} else if orig := os.Getenv("_GGO_ORIG_PATH"); orig != "" {
+			_ = os.Setenv("PATH", orig)
+		}
+		if added := os.Getenv("_GGO_WSLENV_ADDED"); added != "" {
+			names := strings.Split(added, ":")
+			var kept []string
+			for _, name := range strings.Split(os.Getenv("WSLENV"), ":") {
+				if name != "" && !slices.Contains(names, name) {
+					kept = append(kept, name)
+				}
+			}
+			for _, name := range names {
+				if name != "" {
+					_ = os.Unsetenv(name)
+				}
+			}
+			_ = os.Setenv("WSLENV", strings.Join(kept, ":"))
+		}
+	} else {
+		for _, name := range []string{"LD_LIBRARY_PATH", "LD_PRELOAD"} {
+			if orig := os.Getenv("_GGO_ORIG_" + name); orig != "" {
+				_ = os.Setenv(name, orig)
+			} else {
+				_ = os.Unsetenv(name)
+			}
+		}
+		if orig := os.Getenv("_GGO_ORIG_PATH"); orig != "" {
+			_ = os.Setenv("PATH", orig)
+		}
+	}
+	for _, name := range activationVars() {
+		if name != "PATH" && name != "LD_LIBRARY_PATH" && name != "LD_PRELOAD" {
+			_ = os.Unsetenv(name)
+		}
+	}
+}
+
+// writeRestoreScript writes the script that returns the shell to snap, in the
+// language of the shell being activated, to a new file in dir
+func writeRestoreScript(dir string, snap *envSnapshot) (string, error) {
+	pattern, content := "restore-*.sh", restoreScriptUnix(snap)
+	if platform.IsWindows() {
+		if detectWindowsShell() == shellPowerShell {
+			pattern, content = "restore-*.ps1", restoreScriptPowerShell(snap)
+		} else {
+			pattern, content = "restore-*.bat", restoreScriptCMD(snap)
+		}
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create restore script: %w", err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write restore script: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write restore script: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// restoreScriptUnix returns the shell commands that return to snap
+func restoreScriptUnix(snap *envSnapshot) string {
+	var script strings.Builder
+	script.WriteString("# GPU Go environment restore script\n")
+	script.WriteString("# Generated by ggo use for a stacked activation; clean sources it\n\n")
+	for _, name := range snap.names {
+		if value, ok := snap.vars[name]; ok {
+			fmt.Fprintf(&script, "export %s=%s\n", name, studio.ShellQuote(value))
+		} else {
+			fmt.Fprintf(&script, "unset %s\n", name)
+		}
+	}
+	fmt.Fprintf(&script, "\necho %s >&2\n", studio.ShellQuote("GPU Go environment returned to "+snap.label()))
+	return script.String()
+}
+
+// restoreScriptPowerShell returns the PowerShell commands that return to
+// snap. The stacked activation's PATH entries are removed and the previous
+// ones put back, keeping other changes made to PATH meanwhile.
+func restoreScriptPowerShell(snap *envSnapshot) string {
+	var script strings.Builder
+	script.WriteString("# GPU Go environment restore script (PowerShell)\n")
+	script.WriteString("# Generated by ggo use for a stacked activation; clean dot-sources it\n\n")
+	script.WriteString(powerShellPathCleanup(""))
+	script.WriteString(powerShellWSLENVCleanup(""))
+	script.WriteString("\n")
+	for _, name := range snap.names {
+		if value, ok := snap.vars[name]; ok {
+			fmt.Fprintf(&script, "$env:%s = \"%s\"\n", name, escapeForPowerShell(value))
+		} else {
+			fmt.Fprintf(&script, "Remove-Item Env:%s -ErrorAction SilentlyContinue\n", name)
+		}
+	}
+	if snap.pathAdded != "" {
+		fmt.Fprintf(&script, "$env:PATH = \"%s\" + $env:PATH\n", escapeForPowerShell(snap.pathAdded))
+	}
+	if snap.wslenvAdded != "" {
+		fmt.Fprintf(&script, "$env:WSLENV = \"%s\" + $env:WSLENV\n", escapeForPowerShell(snap.wslenvAdded))
+	}
+	fmt.Fprintf(&script, "\n[Console]::Error.WriteLine(\"GPU Go environment returned to %s\")\n", escapeForPowerShell(snap.label()))
+	return script.String()
+}
+
+// restoreScriptCMD returns the batch commands doing what
+// restoreScriptPowerShell does, and points the ggo macro back at the previous
+// environment's clean script
+func restoreScriptCMD(snap *envSnapshot) string {
+	var script strings.Builder
+	script.WriteString("@echo off\n")
+	script.WriteString("REM GPU Go environment restore script (CMD)\n")
+	script.WriteString("REM Generated by ggo use for a stacked activation; clean calls it\n\n")
+	script.WriteString(cmdPathCleanup())
+	script.WriteString(cmdWSLENVCleanup())
+	script.WriteString("\n")
+	for _, name := range snap.names {
+		fmt.Fprintf(&script, "set \"%s=%s\"\n", name, escapeForCMD(snap.vars[name]))
+	}
+	if snap.pathAdded != "" {
+		fmt.Fprintf(&script, "set \"PATH=%s%%PATH%%\"\n", escapeForCMD(snap.pathAdded))
+	}
+	if snap.wslenvAdded != "" {
+		fmt.Fprintf(&script, "set \"WSLENV=%s%%WSLENV%%\"\n", escapeForCMD(snap.wslenvAdded))
+	}
+	if cleanBat := snap.vars["_GGO_CLEAN_FILE"]; cleanBat != "" {
+		script.WriteString(cmdWrapperMacro(cleanBat) + "\n")
+	}
+	fmt.Fprintf(&script, "\necho GPU Go environment returned to %s 1>&2\n", escapeForCMDEcho(snap.label()))
+	return script.String()
+}
+
+// stackedRestoreFile returns the restore script of the shell's activation
+// when it was stacked on another one that clean should return to
+func stackedRestoreFile() string {
+	restoreFile := os.Getenv(restoreFileEnv)
+	if restoreFile == "" || !pathExists(restoreFile) {
+		return ""
+	}
+	return restoreFile
+}
+
+// restoreEnvEval outputs the command that runs restoreFile in the calling
+// shell, for eval mode
+func restoreEnvEval(restoreFile string) error {
+	switch {
+	case !platform.IsWindows():
+		fmt.Printf(". %s\n", studio.ShellQuote(restoreFile))
+	case detectWindowsShell() == shellPowerShell:
+		fmt.Printf(". \"%s\"\n", escapeForPowerShell(restoreFile))
+	default:
+		fmt.Printf("call \"%s\"\n", restoreFile)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package use
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseActivationChoice(t *testing.T) {
+	assert.Equal(t, activateReplace, parseActivationChoice("\n"))
+	assert.Equal(t, activateReplace, parseActivationChoice("R\n"))
+	assert.Equal(t, activateStack, parseActivationChoice(" stack\n"))
+	assert.Equal(t, activateAbort, parseActivationChoice("a\n"))
+	assert.Equal(t, activateAbort, parseActivationChoice("maybe\n"))
+}
+
+func TestActiveEnvString(t *testing.T) {
+	assert.Equal(t, "abc123 (worker w-1)", (&activeEnv{Name: "abc123", ShortCode: "abc123", WorkerID: "w-1"}).String())
+	assert.Equal(t, "training (share abc123, worker w-1)", (&activeEnv{Name: "training", ShortCode: "abc123", WorkerID: "w-1"}).String())
+	assert.Equal(t, "abc123", (&activeEnv{Name: "abc123"}).String())
+}
+
+func TestResolveActivationMode(t *testing.T) {
+	out := tui.NewOutputWithFormat(tui.FormatJSON)
+	active := &activeEnv{Name: "abc123"}
+
+	mode, err := resolveActivationMode(nil, false, false, true, out)
+	require.NoError(t, err)
+	assert.Equal(t, activateNew, mode)
+
+	mode, err = resolveActivationMode(active, true, false, true, out)
+	require.NoError(t, err)
+	assert.Equal(t, activateReplace, mode)
+
+	mode, err = resolveActivationMode(active, false, true, true, out)
+	require.NoError(t, err)
+	assert.Equal(t, activateStack, mode)
+
+	// A new shell always starts without the current environment
+	mode, err = resolveActivationMode(active, false, true, false, out)
+	require.NoError(t, err)
+	assert.Equal(t, activateReplace, mode)
+
+	// Without a way to ask, scripts must choose
+	mode, err = resolveActivationMode(active, false, false, true, out)
+	require.Error(t, err)
+	assert.Equal(t, activateAbort, mode)
+	assert.Equal(t, cmdutil.ExitConflict, cmdutil.ExitCode(nil, err))
+	assert.Contains(t, err.Error(), "--replace")
+}
+
+// setActivation puts the environment of a shell activated for name into the
+// environment of the test
+func setActivation(t *testing.T, name string, vars map[string]string) {
+	t.Helper()
+	for _, k := range activationVars() {
+		t.Setenv(k, "")
+		require.NoError(t, os.Unsetenv(k))
+	}
+	t.Setenv(studio.ConnectionEnv, name)
+	t.Setenv("_GGO_ACTIVE", "1")
+	for k, v := range vars {
+		t.Setenv(k, v)
+	}
+}
+
+func TestUnwindActivation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix activation")
+	}
+	setActivation(t, "abc123", map[string]string{
+		"PATH":                      "/gpugo/bin:/usr/bin",
+		"LD_LIBRARY_PATH":           "/gpugo/libs:/usr/lib",
+		"LD_PRELOAD":                "/gpugo/libs/libcuda.so",
+		"TF_LOG_PATH":               "/gpugo/logs/abc123.txt",
+		"_GGO_ORIG_PATH":            "/usr/bin",
+		"_GGO_ORIG_LD_LIBRARY_PATH": "/usr/lib",
+	})
+
+	unwindActivation()
+
+	assert.Equal(t, "/usr/bin", os.Getenv("PATH"))
+	assert.Equal(t, "/usr/lib", os.Getenv("LD_LIBRARY_PATH"))
+	for _, k := range []string{"LD_PRELOAD", "TF_LOG_PATH", "_GGO_ACTIVE", "_GGO_ORIG_PATH", studio.ConnectionEnv} {
+		_, ok := os.LookupEnv(k)
+		assert.False(t, ok, k)
+	}
+}
+
+// TestStackedActivationReturnsOnClean stacks an activation on another in
+// bash and checks that each clean steps back one environment
+func TestStackedActivationReturnsOnClean(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil || runtime.GOOS == "windows" {
+		t.Skip("bash is not available")
+	}
+
+	dir := t.TempDir()
+	cleanFile := filepath.Join(dir, "clean.sh")
+	require.NoError(t, os.WriteFile(cleanFile, []byte(generateCleanScriptUnix()), 0755))
+
+	// The shell is activated for first
+	setActivation(t, "first", map[string]string{
+		"PATH":                                   "/first/bin:/usr/bin:/bin",
+		"LD_LIBRARY_PATH":                        "/first/libs:/usr/lib",
+		"LD_PRELOAD":                             "/first/libs/libcuda.so",
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO": "native+10.0.0.1+9000+first",
+		"_GGO_ORIG_PATH":                         "/usr/bin:/bin",
+		"_GGO_ORIG_LD_LIBRARY_PATH":              "/usr/lib",
+		"_GGO_ORIG_LD_PRELOAD":                   "",
+		"_GGO_CLEAN_FILE":                        cleanFile,
+	})
+	restoreFile, err := writeRestoreScript(dir, snapshotActivation())
+	require.NoError(t, err)
+
+	config := &studio.GPUEnvConfig{
+		Vendor:        studio.VendorNvidia,
+		LibsPath:      "/second/libs",
+		BinPath:       "/second/bin",
+		ConnectionURL: "native+10.0.0.2+9000+second",
+	}
+	envResult := &studio.GPUEnvResult{EnvVars: map[string]string{
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO": config.ConnectionURL,
+		studio.ConnectionEnv:                     "second",
+	}}
+	stacked := unixActivationScript(config, envResult, cleanFile, takeover{unwind: true, restoreFile: restoreFile})
+	replaced := unixActivationScript(config, envResult, cleanFile, takeover{unwind: true})
+
+	script := `
+state() { echo "state=$_GGO_CONNECTION|$LD_LIBRARY_PATH|${LD_PRELOAD-unset}|$TENSOR_FUSION_OPERATOR_CONNECTION_INFO|${_GGO_ACTIVE-unset}"; }
+eval "$1"
+state
+. "$3" >/dev/null
+state
+. "$3" >/dev/null
+state
+export LD_LIBRARY_PATH=/first/libs:/usr/lib _GGO_ORIG_LD_LIBRARY_PATH=/usr/lib _GGO_ACTIVE=1 _GGO_CONNECTION=first
+eval "$2"
+state
+. "$3" >/dev/null
+state
+`
+	output, err := exec.Command(bash, "-c", script, "bash", stacked, replaced, cleanFile).Output()
+	require.NoError(t, err, string(output))
+
+	secondPreload := "/second/libs/libcuda.so:/second/libs/libnvidia-ml.so"
+	assert.Equal(t, []string{
+		// Stacked: only the second environment is active
+		"state=second|/second/libs:/usr/lib|" + secondPreload + "|native+10.0.0.2+9000+second|1",
+		// Clean returns to the first one, then to the original environment
+		"state=first|/first/libs:/usr/lib|/first/libs/libcuda.so|native+10.0.0.1+9000+first|1",
+		"state=|/usr/lib|unset||unset",
+		// Replaced: a single clean leaves no environment behind
+		"state=second|/second/libs:/usr/lib|" + secondPreload + "|native+10.0.0.2+9000+second|1",
+		"state=|/usr/lib|unset||unset",
+	}, strings.Split(strings.TrimSpace(string(output)), "\n"))
+}
+
+func TestRestoreScriptCMD(t *testing.T) {
+	snap := &envSnapshot{
+		names:     []string{"_GGO_CLEAN_FILE", "_GGO_PATH_ADDED", studio.ConnectionEnv, "TF_GPU_VENDOR"},
+		vars:      map[string]string{"_GGO_CLEAN_FILE": `C:\gpugo\first\clean.bat`, "_GGO_PATH_ADDED": `C:\gpugo\bin;C:\gpugo\libs;`, studio.ConnectionEnv: "first"},
+		pathAdded: `C:\gpugo\bin;C:\gpugo\libs;`,
+	}
+	script := restoreScriptCMD(snap)
+	lines := strings.Split(script, "\n")
+
+	// The stacked activation's PATH entries are removed before the previous
+	// ones are put back
+	assert.Less(t, strings.Index(script, cmdPathCleanup()), strings.Index(script, `set "PATH=C:\gpugo\bin;C:\gpugo\libs;%PATH%"`))
+	assert.Contains(t, lines, `set "`+studio.ConnectionEnv+`=first"`)
+	assert.Contains(t, lines, `set "TF_GPU_VENDOR="`, "variables the previous environment did not set are cleared")
+	assert.Contains(t, lines, cmdWrapperMacro(`C:\gpugo\first\clean.bat`))
+	assert.Contains(t, lines, "echo GPU Go environment returned to first 1>&2")
+}
@@ -0,0 +1,346 @@
+package use
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// ciEnvFileName is the dotenv file 'ggo use --ci' writes unless --env-file
+// names another one
+const ciEnvFileName = "ci.env"
+
+// Error codes reported by 'ggo use --ci' and 'ggo clean --ci'
+const (
+	ciCodeInvalidArguments = "INVALID_ARGUMENTS"
+	ciCodeShareUnavailable = "SHARE_UNAVAILABLE"
+	ciCodeWorkerUntrusted  = "WORKER_VERIFICATION_FAILED"
+	ciCodeWorkerBusy       = "WORKER_BUSY"
+	ciCodeLibraryDownload  = "LIBRARY_DOWNLOAD_FAILED"
+	ciCodeLibraryABI       = "LIBRARY_INCOMPATIBLE"
+	ciCodeSetupFailed      = "ENVIRONMENT_SETUP_FAILED"
+	ciCodeCleanFailed      = "CLEAN_FAILED"
+	ciCodeUnknown          = "ERROR"
+)
+
+// ciError tags an error with the code CI mode reports for it. It reads like
+// the wrapped error, so interactive runs are unaffected.
+type ciError struct {
+	code string
+	err  error
+}
+
+func (e *ciError) Error() string { return e.err.Error() }
+func (e *ciError) Unwrap() error { return e.err }
+
+// ciFail tags err with a CI error code, and the codes with a matching exit
+// code with its kind; nil stays nil
+func ciFail(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch code {
+	case ciCodeInvalidArguments:
+		err = cmdutil.UsageError(err)
+	case ciCodeWorkerBusy:
+		err = cmdutil.ConflictError(err)
+	case ciCodeLibraryDownload, ciCodeLibraryABI:
+		err = cmdutil.DependencyError(err)
+	}
+	return &ciError{code: code, err: err}
+}
+
+// ciErrorResult is printed to stdout when CI mode fails
+type ciErrorResult struct {
+	Success bool        `json:"success"`
+	Error   ciErrorBody `json:"error"`
+}
+
+type ciErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// reportCIError prints err as JSON on stdout and, on GitHub Actions, as an
+// error annotation on stderr
+func reportCIError(err error, title string) {
+	body := ciErrorBody{Code: ciCodeUnknown, Message: err.Error()}
+	var tagged *ciError
+	if errors.As(err, &tagged) {
+		body.Code = tagged.code
+	}
+	if jsonErr := cmdutil.NewOutput("json").PrintJSON(ciErrorResult{Error: body}); jsonErr != nil {
+		klog.Errorf("Failed to print CI error: error=%v", jsonErr)
+	}
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		fmt.Fprintf(os.Stderr, "::error title=%s::%s\n", escapeGitHubProperty(title), escapeGitHubData(body.Code+": "+body.Message))
+	}
+}
+
+// reportCIFailure reports err when running in CI mode and passes it through
+func reportCIFailure(cmd *cobra.Command, ci bool, err error) error {
+	if ci && err != nil {
+		cmd.SilenceUsage = true
+		reportCIError(err, "ggo "+cmd.Name())
+	}
+	return err
+}
+
+// escapeGitHubData escapes the message of a workflow command
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// ciUseResult is printed to stdout when 'ggo use --ci' succeeds
+type ciUseResult struct {
+	Success    bool              `json:"success"`
+	Connection string            `json:"connection"`
+	ShortCode  string            `json:"shortCode"`
+	WorkerID   string            `json:"workerId"`
+	Vendor     string            `json:"vendor"`
+	EnvFile    string            `json:"envFile"`
+	GitHubEnv  bool              `json:"githubEnv"`
+	Env        map[string]string `json:"env"`
+	Path       []string          `json:"path"`
+}
+
+func (r *ciUseResult) RenderJSON() any {
+	return r
+}
+
+func (r *ciUseResult) RenderTUI(out *tui.Output) {
+	out.Successf("GPU environment written to %s", r.EnvFile)
+}
+
+// setupCIEnv sets up a temporary environment without prompting and writes
+// its variables to envFile in dotenv format, and to $GITHUB_ENV and
+// $GITHUB_PATH when running on GitHub Actions
+func setupCIEnv(shareInfo *api.SharePublicInfo, rec *studio.UseConnection, envFile string, out *tui.Output) error {
+	klog.Info("Setting up GPU environment for CI...")
+
+	config := temporaryEnvConfig(shareInfo, rec)
+	envResult, err := studio.SetupGPUEnv(paths, config)
+	if err != nil {
+		return ciFail(ciCodeSetupFailed, fmt.Errorf("failed to setup GPU environment: %w", err))
+	}
+	rec.CI = true
+	rec.AddDirs(paths.StudioConfigDir(config.StudioName))
+
+	if envFile == "" {
+		envFile = filepath.Join(paths.StudioConfigDir(config.StudioName), ciEnvFileName)
+	}
+	envFile, err = filepath.Abs(envFile)
+	if err != nil {
+		return ciFail(ciCodeSetupFailed, fmt.Errorf("failed to resolve env file: %w", err))
+	}
+
+	vars, pathDirs := ciEnvironment(config, envResult)
+	if err := writeDotenv(envFile, vars, pathDirs, os.Getenv("PATH")); err != nil {
+		return ciFail(ciCodeSetupFailed, err)
+	}
+	rec.AddFiles(envFile)
+	recordUseConnection(rec)
+
+	result := &ciUseResult{
+		Success:    true,
+		Connection: rec.ID(),
+		ShortCode:  rec.ShortCode,
+		WorkerID:   shareInfo.WorkerID,
+		Vendor:     string(config.Vendor),
+		EnvFile:    envFile,
+		Env:        vars,
+		Path:       pathDirs,
+	}
+	if githubEnv := os.Getenv("GITHUB_ENV"); githubEnv != "" {
+		if err := writeGitHubEnv(githubEnv, os.Getenv("GITHUB_PATH"), vars, pathDirs, os.Getenv("PATH")); err != nil {
+			return ciFail(ciCodeSetupFailed, err)
+		}
+		result.GitHubEnv = true
+	}
+	return out.Render(result)
+}
+
+// ciEnvironment returns the variables that activate the environment, with
+// the values they take in this process, and the directories to put in
+// front of PATH
+func ciEnvironment(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult) (map[string]string, []string) {
+	libsPath := config.LibsPath
+	if libsPath == "" {
+		libsPath = paths.LibsDir()
+	}
+	binDir := getGPUBinDir(config)
+
+	vars := make(map[string]string, len(envResult.EnvVars)+8)
+	for k, v := range envResult.EnvVars {
+		vars[k] = v
+	}
+	vars["_GGO_ACTIVE"] = "1"
+	vars["_GGO_LIBS_PATH"] = libsPath
+	vars["_GGO_BIN_PATH"] = binDir
+
+	if platform.IsWindows() {
+		vars["TF_GPU_VENDOR"] = string(config.Vendor)
+		vars["CUDA_PATH"] = libsPath
+		vars["CUDA_HOME"] = libsPath
+		return vars, []string{binDir, libsPath}
+	}
+
+	vars["LD_LIBRARY_PATH"] = prependList(libsPath, os.Getenv("LD_LIBRARY_PATH"), ":")
+	var preload []string
+	for _, lib := range studio.GetLibraryNames(config.Vendor) {
+		preload = append(preload, filepath.Join(libsPath, lib))
+	}
+	if len(preload) > 0 {
+		vars["LD_PRELOAD"] = prependList(strings.Join(preload, ":"), os.Getenv("LD_PRELOAD"), ":")
+	}
+	return vars, []string{binDir}
+}
+
+// prependList puts head in front of a separator-delimited list
+func prependList(head, list, sep string) string {
+	if list == "" {
+		return head
+	}
+	return head + sep + list
+}
+
+// writeDotenv writes vars and PATH with pathDirs prepended to path
+func writeDotenv(path string, vars map[string]string, pathDirs []string, currentPath string) error {
+	var b strings.Builder
+	b.WriteString("# GPU Go environment (generated by ggo use --ci)\n")
+	for _, k := range studio.SortedKeys(vars) {
+		fmt.Fprintf(&b, "%s=%s\n", k, dotenvValue(vars[k]))
+	}
+	pathList := strings.Join(pathDirs, string(os.PathListSeparator))
+	fmt.Fprintf(&b, "PATH=%s\n", dotenvValue(prependList(pathList, currentPath, string(os.PathListSeparator))))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create env file directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+	return nil
+}
+
+// dotenvValue quotes a value when dotenv parsers would otherwise change it
+func dotenvValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, " \t\r\n\"'#$\\`") {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`, "`", "\\`").Replace(v) + `"`
+}
+
+// writeGitHubEnv appends vars to the $GITHUB_ENV file and pathDirs to the
+// $GITHUB_PATH file, so later steps of the job run in the environment.
+// Without a $GITHUB_PATH file PATH is set through $GITHUB_ENV.
+func writeGitHubEnv(envPath, pathPath string, vars map[string]string, pathDirs []string, currentPath string) error {
+	var b strings.Builder
+	for _, k := range studio.SortedKeys(vars) {
+		writeGitHubEnvVar(&b, k, vars[k])
+	}
+	if pathPath == "" {
+		pathList := strings.Join(pathDirs, string(os.PathListSeparator))
+		writeGitHubEnvVar(&b, "PATH", prependList(pathList, currentPath, string(os.PathListSeparator)))
+	}
+	if err := appendFile(envPath, b.String()); err != nil {
+		return fmt.Errorf("failed to write $GITHUB_ENV: %w", err)
+	}
+
+	if pathPath != "" {
+		// Entries added later take precedence, so the first dir goes last
+		var p strings.Builder
+		for i := len(pathDirs) - 1; i >= 0; i-- {
+			p.WriteString(pathDirs[i] + "\n")
+		}
+		if err := appendFile(pathPath, p.String()); err != nil {
+			return fmt.Errorf("failed to write $GITHUB_PATH: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeGitHubEnvVar writes one variable in $GITHUB_ENV syntax, using a
+// random heredoc delimiter for multi-line values
+func writeGitHubEnvVar(b *strings.Builder, key, value string) {
+	if !strings.ContainsAny(value, "\r\n") {
+		fmt.Fprintf(b, "%s=%s\n", key, value)
+		return
+	}
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	delimiter := "ggo_EOF_" + hex.EncodeToString(buf)
+	fmt.Fprintf(b, "%s<<%s\n%s\n%s\n", key, delimiter, value, delimiter)
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ciCleanResult is printed to stdout by 'ggo clean --ci'
+type ciCleanResult struct {
+	Success bool     `json:"success"`
+	Cleaned []string `json:"cleaned"`
+}
+
+func (r *ciCleanResult) RenderJSON() any {
+	return r
+}
+
+func (r *ciCleanResult) RenderTUI(out *tui.Output) {
+	out.Successf("Cleaned up %d CI GPU environment(s)", len(r.Cleaned))
+}
+
+// cleanCIEnv tears down the environments 'ggo use --ci' set up, or only the
+// one named by args. Nothing to clean up is not an error, so it can run
+// unconditionally in a post step.
+func cleanCIEnv(args []string, out *tui.Output) error {
+	registry := studio.NewUseRegistry(paths)
+	conns, err := registry.List()
+	if err != nil {
+		return ciFail(ciCodeCleanFailed, err)
+	}
+
+	result := &ciCleanResult{Success: true, Cleaned: []string{}}
+	for _, c := range conns {
+		if !c.CI || (len(args) > 0 && c.ID() != args[0] && c.ShortCode != cmdutil.ExtractShortCode(args[0])) {
+			continue
+		}
+		released, err := registry.Release(c.ID())
+		if err != nil {
+			return ciFail(ciCodeCleanFailed, err)
+		}
+		if released == nil {
+			continue
+		}
+		removeUseArtifacts(released, nil)
+		result.Cleaned = append(result.Cleaned, c.ID())
+	}
+	klog.Infof("Cleaned up CI GPU environments: connections=%v", result.Cleaned)
+	return out.Render(result)
+}
@@ -0,0 +1,105 @@
+package use
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDotenvValue(t *testing.T) {
+	assert.Equal(t, "/opt/gpugo/libs", dotenvValue("/opt/gpugo/libs"))
+	assert.Equal(t, `""`, dotenvValue(""))
+	assert.Equal(t, `"a b"`, dotenvValue("a b"))
+	assert.Equal(t, `"native+\$abc#1"`, dotenvValue("native+$abc#1"))
+	assert.Equal(t, `"C:\\gpugo\\libs"`, dotenvValue(`C:\gpugo\libs`))
+	assert.Equal(t, `"line1\nline2"`, dotenvValue("line1\nline2"))
+}
+
+func TestWriteDotenv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "ci.env")
+	vars := map[string]string{"TF_LOG_LEVEL": "info", "LD_PRELOAD": "/libs/libcuda.so"}
+	require.NoError(t, writeDotenv(path, vars, []string{"/bin/gpu"}, "/usr/bin"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	sep := string(os.PathListSeparator)
+	assert.Equal(t, "# GPU Go environment (generated by ggo use --ci)\n"+
+		"LD_PRELOAD=/libs/libcuda.so\n"+
+		"TF_LOG_LEVEL=info\n"+
+		"PATH="+dotenvValue("/bin/gpu"+sep+"/usr/bin")+"\n", string(data))
+}
+
+func TestWriteGitHubEnv(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, "env")
+	pathPath := filepath.Join(dir, "path")
+	require.NoError(t, os.WriteFile(envPath, []byte("EXISTING=1\n"), 0644))
+
+	vars := map[string]string{"A": "1", "MULTI": "x\ny"}
+	require.NoError(t, writeGitHubEnv(envPath, pathPath, vars, []string{"/first", "/second"}, "/usr/bin"))
+
+	env, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "EXISTING=1\nA=1\nMULTI<<ggo_EOF_")
+	assert.Contains(t, string(env), "\nx\ny\nggo_EOF_")
+	assert.NotContains(t, string(env), "PATH=")
+
+	// $GITHUB_PATH entries added later win, so the first dir is written last
+	path, err := os.ReadFile(pathPath)
+	require.NoError(t, err)
+	assert.Equal(t, "/second\n/first\n", string(path))
+
+	// Without $GITHUB_PATH, PATH goes to $GITHUB_ENV
+	noPathEnv := filepath.Join(dir, "env2")
+	require.NoError(t, writeGitHubEnv(noPathEnv, "", map[string]string{}, []string{"/first"}, "/usr/bin"))
+	env, err = os.ReadFile(noPathEnv)
+	require.NoError(t, err)
+	assert.Equal(t, "PATH=/first"+string(os.PathListSeparator)+"/usr/bin\n", string(env))
+}
+
+func TestCIFailCodes(t *testing.T) {
+	assert.NoError(t, ciFail(ciCodeShareUnavailable, nil))
+
+	err := fmt.Errorf("use: %w", ciFail(ciCodeLibraryABI, fmt.Errorf("glibc too old")))
+	assert.Equal(t, "use: glibc too old", err.Error(), "CI codes do not change the message")
+	var tagged *ciError
+	require.ErrorAs(t, err, &tagged)
+	assert.Equal(t, ciCodeLibraryABI, tagged.code)
+
+	assert.Equal(t, "50%25 done%0Anext: a,b", escapeGitHubData("50% done\nnext: a,b"))
+	assert.Equal(t, "ggo use%3A ci%2C x", escapeGitHubProperty("ggo use: ci, x"))
+}
+
+func TestCleanCIEnv(t *testing.T) {
+	orig := paths
+	paths = platform.DefaultPaths().WithConfigDir(t.TempDir())
+	t.Cleanup(func() { paths = orig })
+
+	envFile := filepath.Join(t.TempDir(), "ci.env")
+	require.NoError(t, os.WriteFile(envFile, []byte("A=1\n"), 0644))
+
+	ciConn := &studio.UseConnection{ShortCode: "abc123", WorkerID: "w1", CI: true}
+	ciConn.AddFiles(envFile)
+	recordUseConnection(ciConn)
+	recordUseConnection(&studio.UseConnection{ShortCode: "def456", WorkerID: "w2"})
+
+	out := cmdutil.NewOutput("json")
+	require.NoError(t, cleanCIEnv([]string{"def456"}, out), "interactive connections are left alone")
+	require.NoError(t, cleanCIEnv(nil, out))
+
+	conns, err := studio.NewUseRegistry(paths).List()
+	require.NoError(t, err)
+	require.Len(t, conns, 1)
+	assert.Equal(t, "def456", conns[0].ShortCode)
+	assert.NoFileExists(t, envFile)
+
+	// Nothing left to clean is not an error
+	require.NoError(t, cleanCIEnv(nil, out))
+}
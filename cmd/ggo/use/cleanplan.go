@@ -0,0 +1,319 @@
+package use
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"k8s.io/klog/v2"
+)
+
+// Kinds of host changes 'ggo clean' makes
+const (
+	cleanKindProfileLine = "profile-line"
+	cleanKindFile        = "file"
+	cleanKindDir         = "directory"
+	cleanKindEnvVar      = "env-var"
+	cleanKindPathEntry   = "path-entry"
+)
+
+// cleanKinds orders the kinds in the summary
+var cleanKinds = []string{cleanKindProfileLine, cleanKindFile, cleanKindDir, cleanKindEnvVar, cleanKindPathEntry}
+
+// Outcomes of a clean action
+const (
+	cleanStatusPlanned = "would-remove"
+	cleanStatusRemoved = "removed"
+	cleanStatusAbsent  = "absent"
+	cleanStatusFailed  = "failed"
+)
+
+// permanentWinEnvVars are the user variables setenv.bat persists on Windows
+var permanentWinEnvVars = []string{
+	"TENSOR_FUSION_OPERATOR_CONNECTION_INFO",
+	studio.ConnectionEnv,
+	"TF_LOG_PATH",
+	"TF_LOG_LEVEL",
+	"TF_ENABLE_LOG",
+	"TF_GPU_VENDOR",
+	"CUDA_PATH",
+	"CUDA_HOME",
+}
+
+// cleanAction is one artifact 'ggo clean' removes from the host
+type cleanAction struct {
+	Connection string `json:"connection"`
+	Kind       string `json:"kind"`
+	// Target is the path, profile line, variable name or PATH entry
+	Target string `json:"target"`
+	// Location is the profile of a profile line, or the registry value
+	// holding a variable or PATH entry
+	Location string `json:"location,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// planUseArtifacts lists what removing a released connection changes on
+// the host, in the order it is done. Artifacts already gone are marked
+// absent; registry values are not checked and always planned.
+func planUseArtifacts(conn *studio.UseConnection) []cleanAction {
+	var actions []cleanAction
+	add := func(kind, target, location string, exists bool) {
+		status := cleanStatusPlanned
+		if !exists {
+			status = cleanStatusAbsent
+		}
+		actions = append(actions, cleanAction{Connection: conn.ID(), Kind: kind, Target: target, Location: location, Status: status})
+	}
+
+	for _, pl := range conn.ProfileLines {
+		add(cleanKindProfileLine, pl.Line, pl.File, profileHasLine(pl.File, pl.Line))
+	}
+	for _, file := range conn.Files {
+		add(cleanKindFile, file, "", pathExists(file))
+	}
+	for _, dir := range conn.Dirs {
+		add(cleanKindDir, dir, "", pathExists(dir))
+	}
+	if !platform.IsWindows() {
+		return actions
+	}
+	// Variables setx made permanent, and the PATH entries activation added
+	// should PATH have been persisted from an activated session
+	if conn.WindowsEnv {
+		for _, name := range permanentWinEnvVars {
+			add(cleanKindEnvVar, name, userEnvKey, true)
+		}
+	}
+	for _, entry := range conn.PathEntries {
+		add(cleanKindPathEntry, entry, userEnvKey+`\Path`, true)
+	}
+	return actions
+}
+
+// applyCleanAction removes the artifact of a planned action and records the
+// outcome
+func applyCleanAction(a *cleanAction) {
+	if a.Status != cleanStatusPlanned {
+		return
+	}
+	var err error
+	removed := true
+	switch a.Kind {
+	case cleanKindProfileLine:
+		removed, err = removeProfileLine(a.Location, a.Target)
+	case cleanKindFile:
+		if err = os.Remove(a.Target); os.IsNotExist(err) {
+			removed, err = false, nil
+		}
+	case cleanKindDir:
+		removed = pathExists(a.Target)
+		err = os.RemoveAll(a.Target)
+	case cleanKindEnvVar:
+		removed = removePermanentWinEnvVar(a.Target)
+	case cleanKindPathEntry:
+		removed, err = removePersistedPathEntries([]string{a.Target})
+	}
+
+	switch {
+	case err != nil:
+		a.Status, a.Error = cleanStatusFailed, err.Error()
+		klog.Warningf("Failed to remove %s: target=%s error=%v", a.Kind, a.Target, err)
+	case removed:
+		a.Status = cleanStatusRemoved
+		klog.V(4).Infof("Removed %s: target=%s", a.Kind, a.Target)
+	default:
+		a.Status = cleanStatusAbsent
+	}
+}
+
+// removeUseArtifacts deletes what a recorded connection created on the host.
+// onAction, if set, is called after each removal.
+func removeUseArtifacts(conn *studio.UseConnection, onAction func(*cleanAction)) []cleanAction {
+	actions := planUseArtifacts(conn)
+	for i := range actions {
+		applyCleanAction(&actions[i])
+		if onAction != nil {
+			onAction(&actions[i])
+		}
+	}
+	return actions
+}
+
+// cleanActionPrinter prints each removal with --verbose; nil otherwise
+func cleanActionPrinter(verbose bool, out *tui.Output) func(*cleanAction) {
+	if !verbose || out.IsJSON() {
+		return nil
+	}
+	return func(a *cleanAction) {
+		out.Printf("  %-12s %-12s %s\n", a.Status, a.Kind, describeCleanTarget(a))
+	}
+}
+
+// appendUniqueActions appends the actions not already in actions, as
+// connections sharing an artifact each list it
+func appendUniqueActions(actions, more []cleanAction) []cleanAction {
+	for _, a := range more {
+		if !slices.ContainsFunc(actions, func(b cleanAction) bool {
+			return a.Kind == b.Kind && a.Target == b.Target && a.Location == b.Location
+		}) {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// describeCleanTarget names the artifact of an action in one line
+func describeCleanTarget(a *cleanAction) string {
+	switch a.Kind {
+	case cleanKindProfileLine:
+		return fmt.Sprintf("%s: %s", a.Location, a.Target)
+	case cleanKindEnvVar, cleanKindPathEntry:
+		return fmt.Sprintf("%s (%s)", a.Target, a.Location)
+	}
+	return a.Target
+}
+
+func profileHasLine(filePath, line string) bool {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(strings.Split(string(data), "\n"), func(l string) bool {
+		return strings.TrimSpace(l) == line
+	})
+}
+
+func pathExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// cleanResult reports what 'ggo clean' removed, or with --dry-run would
+// remove
+type cleanResult struct {
+	DryRun      bool
+	All         bool
+	Connections []string
+	Actions     []cleanAction
+}
+
+// cleanResultJSON is the JSON form of cleanResult
+type cleanResultJSON struct {
+	Success     bool                      `json:"success"`
+	DryRun      bool                      `json:"dry_run"`
+	Message     string                    `json:"message"`
+	Connections []string                  `json:"connections"`
+	Actions     []cleanAction             `json:"actions"`
+	Summary     map[string]map[string]int `json:"summary"`
+}
+
+func (r *cleanResult) message() string {
+	switch {
+	case r.DryRun && len(r.Connections) == 0:
+		return "No GPU environments recorded; nothing to clean"
+	case r.DryRun:
+		return "Dry run: nothing was changed"
+	case r.All:
+		return "All GPU environments cleaned up successfully!"
+	}
+	return "GPU environment cleaned up successfully"
+}
+
+// summary counts the actions per kind and status
+func (r *cleanResult) summary() map[string]map[string]int {
+	summary := make(map[string]map[string]int)
+	for _, a := range r.Actions {
+		if summary[a.Kind] == nil {
+			summary[a.Kind] = make(map[string]int)
+		}
+		summary[a.Kind][a.Status]++
+	}
+	return summary
+}
+
+func (r *cleanResult) failed() bool {
+	return slices.ContainsFunc(r.Actions, func(a cleanAction) bool { return a.Status == cleanStatusFailed })
+}
+
+func (r *cleanResult) RenderJSON() any {
+	actions := r.Actions
+	if actions == nil {
+		actions = []cleanAction{}
+	}
+	connections := r.Connections
+	if connections == nil {
+		connections = []string{}
+	}
+	return &cleanResultJSON{
+		Success:     !r.failed(),
+		DryRun:      r.DryRun,
+		Message:     r.message(),
+		Connections: connections,
+		Actions:     actions,
+		Summary:     r.summary(),
+	}
+}
+
+func (r *cleanResult) RenderTUI(out *tui.Output) {
+	styles := tui.DefaultStyles()
+	switch {
+	case r.DryRun && len(r.Connections) == 0:
+		out.Info("No GPU environments recorded; nothing to clean")
+	case r.DryRun:
+		out.Info("Dry run: nothing was changed")
+	case r.failed():
+		out.Warning("Some artifacts could not be removed")
+	case r.All:
+		out.Success("All GPU environments cleaned up successfully!")
+	default:
+		out.Success("GPU environment cleaned up successfully")
+	}
+
+	if r.DryRun {
+		if len(r.Actions) > 0 {
+			out.Println()
+			table := tui.NewTable().Headers("Connection", "Kind", "Target", "Status")
+			for _, a := range r.Actions {
+				status := a.Status
+				if status == cleanStatusAbsent {
+					status = styles.Muted.Render(status)
+				}
+				table.Row(a.Connection, a.Kind, describeCleanTarget(&a), status)
+			}
+			out.Println(table.String())
+		}
+	}
+
+	if len(r.Actions) > 0 {
+		out.Println()
+		summary := r.summary()
+		table := tui.NewTable().Headers("Kind", "Would Remove", "Removed", "Absent", "Failed")
+		for _, kind := range cleanKinds {
+			counts, ok := summary[kind]
+			if !ok {
+				continue
+			}
+			table.Row(kind,
+				fmt.Sprint(counts[cleanStatusPlanned]),
+				fmt.Sprint(counts[cleanStatusRemoved]),
+				fmt.Sprint(counts[cleanStatusAbsent]),
+				fmt.Sprint(counts[cleanStatusFailed]))
+		}
+		out.Println(table.String())
+	}
+	for _, a := range r.Actions {
+		if a.Status == cleanStatusFailed {
+			out.Println(styles.Error.Render(fmt.Sprintf("  %s %s: %s", a.Kind, describeCleanTarget(&a), a.Error)))
+		}
+	}
+
+	if r.All && !r.DryRun {
+		out.Println()
+		renderShellNote(out)
+	}
+}
@@ -0,0 +1,62 @@
+package use
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanAndRemoveUseArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "env.sh")
+	gone := filepath.Join(dir, "gone.sh")
+	libs := filepath.Join(dir, "libs")
+	profile := filepath.Join(dir, ".bashrc")
+	line := ". " + file
+	require.NoError(t, os.WriteFile(file, []byte("export A=1\n"), 0644))
+	require.NoError(t, os.MkdirAll(libs, 0755))
+	require.NoError(t, os.WriteFile(profile, []byte("alias ll='ls -l'\n"+line+"\n"), 0644))
+
+	conn := &studio.UseConnection{ShortCode: "abc123"}
+	conn.AddProfileLine(profile, line)
+	conn.AddFiles(file, gone)
+	conn.AddDirs(libs)
+
+	plan := planUseArtifacts(conn)
+	require.Len(t, plan, 4)
+	assert.Equal(t, cleanAction{Connection: "abc123", Kind: cleanKindProfileLine, Target: line, Location: profile, Status: cleanStatusPlanned}, plan[0])
+	assert.Equal(t, cleanStatusPlanned, plan[1].Status)
+	assert.Equal(t, cleanStatusAbsent, plan[2].Status, "missing files are not planned")
+	assert.Equal(t, cleanKindDir, plan[3].Kind)
+	assert.FileExists(t, file, "planning changes nothing")
+
+	var printed []string
+	actions := removeUseArtifacts(conn, func(a *cleanAction) { printed = append(printed, a.Target) })
+	assert.Len(t, printed, 4)
+	for i, status := range []string{cleanStatusRemoved, cleanStatusRemoved, cleanStatusAbsent, cleanStatusRemoved} {
+		assert.Equal(t, status, actions[i].Status, actions[i].Target)
+	}
+	assert.NoFileExists(t, file)
+	assert.NoDirExists(t, libs)
+	data, err := os.ReadFile(profile)
+	require.NoError(t, err)
+	assert.Equal(t, "alias ll='ls -l'\n", string(data))
+
+	result := &cleanResult{Actions: actions}
+	assert.Equal(t, map[string]map[string]int{
+		cleanKindProfileLine: {cleanStatusRemoved: 1},
+		cleanKindFile:        {cleanStatusRemoved: 1, cleanStatusAbsent: 1},
+		cleanKindDir:         {cleanStatusRemoved: 1},
+	}, result.summary())
+}
+
+func TestAppendUniqueActions(t *testing.T) {
+	a := cleanAction{Connection: "a", Kind: cleanKindDir, Target: "/libs"}
+	b := cleanAction{Connection: "b", Kind: cleanKindDir, Target: "/libs"}
+	c := cleanAction{Connection: "b", Kind: cleanKindFile, Target: "/libs"}
+	assert.Equal(t, []cleanAction{a, c}, appendUniqueActions([]cleanAction{a}, []cleanAction{b, c}))
+}
@@ -0,0 +1,134 @@
+package use
+
+import (
+	"bufio"
+	"context"
+	"debug/elf"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// maxInterpreterDepth bounds scripts whose interpreter is a script again
+	maxInterpreterDepth = 4
+	loaderProbeTimeout  = 5 * time.Second
+)
+
+// isolatedCommand returns the command line that runs exe through its dynamic
+// loader with preload loaded and libraryPath searched first. Unlike
+// LD_PRELOAD and LD_LIBRARY_PATH, loader options are not inherited: processes
+// exe starts do not load the GPU libraries.
+func isolatedCommand(exe string, args []string, preload []string, libraryPath string) ([]string, error) {
+	exe, args, err := resolveInterpreter(exe, args)
+	if err != nil {
+		return nil, err
+	}
+	loader, err := programLoader(exe)
+	if err != nil {
+		return nil, err
+	}
+	if !loaderSupportsPreload(loader) {
+		return nil, fmt.Errorf("dynamic loader %s does not support --preload (glibc 2.30 or newer is needed); run without --isolate", loader)
+	}
+
+	cmdline := []string{loader}
+	if libraryPath != "" {
+		cmdline = append(cmdline, "--library-path", libraryPath)
+	}
+	if len(preload) > 0 {
+		cmdline = append(cmdline, "--preload", strings.Join(preload, ":"))
+	}
+	cmdline = append(cmdline, exe)
+	return append(cmdline, args...), nil
+}
+
+// resolveInterpreter follows the #! lines of scripts to the binary that
+// actually runs, as the loader only runs ELF programs. An interpreter given
+// as '/usr/bin/env prog' is looked up in PATH, since env would start it
+// without the loader options.
+func resolveInterpreter(exe string, args []string) (string, []string, error) {
+	for range maxInterpreterDepth {
+		interp, arg, err := readShebang(exe)
+		if err != nil {
+			return "", nil, err
+		}
+		if interp == "" {
+			return exe, args, nil
+		}
+
+		script := exe
+		if filepath.Base(interp) == "env" {
+			if arg == "" || strings.HasPrefix(arg, "-") || strings.ContainsAny(arg, " \t") {
+				return "", nil, fmt.Errorf("cannot isolate %s: unsupported interpreter line '#!%s %s'; run its interpreter directly", script, interp, arg)
+			}
+			if interp, err = exec.LookPath(arg); err != nil {
+				return "", nil, fmt.Errorf("interpreter of %s not found: %s", script, arg)
+			}
+			arg = ""
+		}
+
+		prefix := []string{}
+		if arg != "" {
+			prefix = append(prefix, arg)
+		}
+		exe, args = interp, append(append(prefix, script), args...)
+	}
+	return "", nil, fmt.Errorf("cannot isolate %s: too many nested interpreters", exe)
+}
+
+// readShebang returns the interpreter and its optional argument of a script,
+// or an empty interpreter for other files
+func readShebang(path string) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	r := bufio.NewReader(f)
+	if magic, _ := r.Peek(2); string(magic) != "#!" {
+		return "", "", nil
+	}
+	line, _ := r.ReadString('\n')
+	// Like the kernel, pass everything after the interpreter as one argument
+	fields := strings.TrimSpace(strings.TrimPrefix(line, "#!"))
+	interp, arg, _ := strings.Cut(fields, " ")
+	if interp == "" {
+		return "", "", fmt.Errorf("empty interpreter line in %s", path)
+	}
+	return interp, strings.TrimSpace(arg), nil
+}
+
+// programLoader returns the dynamic loader an ELF program asks for
+func programLoader(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot isolate %s: not an ELF program: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			return "", fmt.Errorf("failed to read loader of %s: %w", path, err)
+		}
+		return strings.TrimRight(string(data), "\x00"), nil
+	}
+	return "", fmt.Errorf("cannot isolate %s: it is statically linked and loads no shared libraries", path)
+}
+
+// loaderSupportsPreload reports whether the loader has the --preload option
+func loaderSupportsPreload(loader string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), loaderProbeTimeout)
+	defer cancel()
+	// --help exits non-zero on some glibc versions; only its text matters
+	output, _ := exec.CommandContext(ctx, loader, "--help").CombinedOutput()
+	return strings.Contains(string(output), "--preload")
+}
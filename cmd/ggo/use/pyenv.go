@@ -0,0 +1,301 @@
+package use
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"k8s.io/klog/v2"
+)
+
+// Kinds of Python environments 'ggo use' can hook into
+const (
+	pyEnvConda      = "conda"
+	pyEnvVirtualenv = "virtualenv"
+)
+
+// pyEnvScriptName is the file name of the hook scripts, unique per connection
+// so that several connections can hook into different environments
+func pyEnvScriptName(rec *studio.UseConnection) string {
+	return "ggo-" + rec.ID() + ".sh"
+}
+
+// pyEnvTarget is a Python environment the GPU environment is hooked into
+type pyEnvTarget struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+// condaInfo is the part of `conda info --json` used to find environments
+type condaInfo struct {
+	RootPrefix string   `json:"root_prefix"`
+	Envs       []string `json:"envs"`
+}
+
+// resolveCondaEnv finds the prefix of a conda environment given by name or
+// path. Names are looked up with `conda info --json`, so environments outside
+// the base installation's envs directory are found too.
+func resolveCondaEnv(nameOrPath string) (*pyEnvTarget, error) {
+	if strings.ContainsRune(nameOrPath, filepath.Separator) || strings.HasPrefix(nameOrPath, ".") {
+		prefix, err := filepath.Abs(nameOrPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve conda environment %s: %w", nameOrPath, err)
+		}
+		if _, err := os.Stat(filepath.Join(prefix, "conda-meta")); err != nil {
+			return nil, fmt.Errorf("%s is not a conda environment (no conda-meta directory)", prefix)
+		}
+		return &pyEnvTarget{Kind: pyEnvConda, Name: filepath.Base(prefix), Prefix: prefix}, nil
+	}
+
+	conda := os.Getenv("CONDA_EXE")
+	if conda == "" {
+		conda = "conda"
+	}
+	output, err := exec.Command(conda, "info", "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run conda to look up environment %s (is conda installed and initialized?): %w", nameOrPath, err)
+	}
+	var info condaInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse conda info: %w", err)
+	}
+	prefix, ok := matchCondaEnv(&info, nameOrPath)
+	if !ok {
+		return nil, cmdutil.NotFoundErrorf("conda environment %s not found (see 'conda env list')", nameOrPath)
+	}
+	return &pyEnvTarget{Kind: pyEnvConda, Name: nameOrPath, Prefix: prefix}, nil
+}
+
+// matchCondaEnv returns the prefix of the environment called name; "base"
+// is the root installation
+func matchCondaEnv(info *condaInfo, name string) (string, bool) {
+	if name == "base" && info.RootPrefix != "" {
+		return info.RootPrefix, true
+	}
+	for _, env := range info.Envs {
+		if env != info.RootPrefix && filepath.Base(env) == name {
+			return env, true
+		}
+	}
+	return "", false
+}
+
+// resolveVirtualenv checks that dir is a virtualenv with an activate script
+func resolveVirtualenv(dir string) (*pyEnvTarget, error) {
+	prefix, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve virtualenv %s: %w", dir, err)
+	}
+	if _, err := os.Stat(filepath.Join(prefix, "bin", "activate")); err != nil {
+		return nil, fmt.Errorf("%s is not a virtualenv (no bin/activate script)", prefix)
+	}
+	return &pyEnvTarget{Kind: pyEnvVirtualenv, Name: filepath.Base(prefix), Prefix: prefix}, nil
+}
+
+// setupPythonEnv hooks the GPU environment into a conda environment or a
+// virtualenv, so that activating it configures the GPU libraries and
+// deactivating it tears them down again
+func setupPythonEnv(shareInfo *api.SharePublicInfo, rec *studio.UseConnection, target *pyEnvTarget, out *tui.Output) error {
+	klog.Infof("Hooking GPU environment into Python environment: kind=%s prefix=%s", target.Kind, target.Prefix)
+
+	config := temporaryEnvConfig(shareInfo, rec)
+	envResult, err := studio.SetupGPUEnv(paths, config)
+	if err != nil {
+		return fmt.Errorf("failed to setup GPU environment: %w", err)
+	}
+	rec.AddDirs(paths.StudioConfigDir(config.StudioName))
+
+	activate := pyEnvActivateScript(config, envResult, target)
+	deactivate := pyEnvDeactivateScript(envResult)
+	result := &pyEnvResult{Connection: rec.ID(), Target: target}
+
+	switch target.Kind {
+	case pyEnvConda:
+		// conda sources every script in these directories on activate and
+		// deactivate
+		activateFile := filepath.Join(target.Prefix, "etc", "conda", "activate.d", pyEnvScriptName(rec))
+		deactivateFile := filepath.Join(target.Prefix, "etc", "conda", "deactivate.d", pyEnvScriptName(rec))
+		if err := writeHookScript(activateFile, activate); err != nil {
+			return err
+		}
+		rec.AddFiles(activateFile)
+		if err := writeHookScript(deactivateFile, deactivate); err != nil {
+			recordUseConnection(rec)
+			return err
+		}
+		rec.AddFiles(deactivateFile)
+		result.Files = []string{activateFile, deactivateFile}
+	case pyEnvVirtualenv:
+		// The activate script of a virtualenv has no hook directories, so a
+		// line sourcing the hook is appended to it; the hook wraps the
+		// deactivate function it defines
+		configDir := paths.StudioConfigDir(config.StudioName)
+		activateFile := filepath.Join(configDir, "venv-activate.sh")
+		deactivateFile := filepath.Join(configDir, "venv-deactivate.sh")
+		if err := writeHookScript(deactivateFile, deactivate); err != nil {
+			return err
+		}
+		if err := writeHookScript(activateFile, venvDeactivateWrapper(deactivateFile)+activate); err != nil {
+			return err
+		}
+		rec.AddFiles(activateFile, deactivateFile)
+
+		venvActivate := filepath.Join(target.Prefix, "bin", "activate")
+		sourceLine := ". " + studio.ShellQuote(activateFile)
+		if err := appendToFile(venvActivate, fmt.Sprintf("\n%s\n%s\n", profileMarker, sourceLine), activateFile); err != nil {
+			recordUseConnection(rec)
+			return fmt.Errorf("failed to update %s: %w", venvActivate, err)
+		}
+		rec.AddProfileLine(venvActivate, sourceLine)
+		result.Files = []string{venvActivate, activateFile, deactivateFile}
+	}
+	recordUseConnection(rec)
+
+	return out.Render(result)
+}
+
+func writeHookScript(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// pyEnvActivateScript returns the script sourced when the Python environment
+// is activated. It saves the library and binary search paths it changes under
+// _GGO_PYENV_ORIG_*, apart from those of 'eval "$(ggo use -y)"', and does
+// nothing when already active.
+func pyEnvActivateScript(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, target *pyEnvTarget) string {
+	libsPath := config.LibsPath
+	if libsPath == "" {
+		libsPath = paths.LibsDir()
+	}
+	binDir := getGPUBinDir(config)
+
+	var script strings.Builder
+	fmt.Fprintf(&script, "# GPU Go environment for %s %s\n", target.Kind, target.Name)
+	fmt.Fprintf(&script, "# Generated by ggo use; removed by 'ggo clean %s'\n\n", config.ConnectionName)
+	script.WriteString("if [ -z \"$_GGO_PYENV_ACTIVE\" ]; then\n")
+	script.WriteString("  export _GGO_PYENV_ORIG_LD_LIBRARY_PATH=\"$LD_LIBRARY_PATH\"\n")
+	script.WriteString("  export _GGO_PYENV_ORIG_LD_PRELOAD=\"$LD_PRELOAD\"\n")
+	script.WriteString("  export _GGO_PYENV_ORIG_PATH=\"$PATH\"\n")
+	for _, k := range studio.SortedKeys(envResult.EnvVars) {
+		fmt.Fprintf(&script, "  export %s=%s\n", k, studio.ShellQuote(envResult.EnvVars[k]))
+	}
+	fmt.Fprintf(&script, "  export LD_LIBRARY_PATH=%s\"${LD_LIBRARY_PATH:+:$LD_LIBRARY_PATH}\"\n", studio.ShellQuote(libsPath))
+	fmt.Fprintf(&script, "  export PATH=%s\"${PATH:+:$PATH}\"\n", studio.ShellQuote(binDir))
+	var preload []string
+	for _, lib := range studio.GetLibraryNames(config.Vendor) {
+		preload = append(preload, filepath.Join(libsPath, lib))
+	}
+	if len(preload) > 0 {
+		fmt.Fprintf(&script, "  export LD_PRELOAD=%s\"${LD_PRELOAD:+:$LD_PRELOAD}\"\n", studio.ShellQuote(strings.Join(preload, ":")))
+	}
+	script.WriteString("  export _GGO_PYENV_ACTIVE=1\n")
+	script.WriteString("fi\n")
+	return script.String()
+}
+
+// pyEnvDeactivateScript returns the script sourced when the Python
+// environment is deactivated, restoring what the activate script changed
+func pyEnvDeactivateScript(envResult *studio.GPUEnvResult) string {
+	var script strings.Builder
+	script.WriteString("# GPU Go environment teardown\n")
+	script.WriteString("# Generated by ggo use\n\n")
+	script.WriteString("if [ -n \"$_GGO_PYENV_ACTIVE\" ]; then\n")
+	for _, v := range []string{"LD_LIBRARY_PATH", "LD_PRELOAD"} {
+		fmt.Fprintf(&script, "  if [ -n \"$_GGO_PYENV_ORIG_%s\" ]; then\n", v)
+		fmt.Fprintf(&script, "    export %s=\"$_GGO_PYENV_ORIG_%s\"\n", v, v)
+		script.WriteString("  else\n")
+		fmt.Fprintf(&script, "    unset %s\n", v)
+		script.WriteString("  fi\n")
+	}
+	script.WriteString("  if [ -n \"$_GGO_PYENV_ORIG_PATH\" ]; then\n")
+	script.WriteString("    export PATH=\"$_GGO_PYENV_ORIG_PATH\"\n")
+	script.WriteString("  fi\n")
+	for _, k := range studio.SortedKeys(envResult.EnvVars) {
+		fmt.Fprintf(&script, "  unset %s\n", k)
+	}
+	script.WriteString("  unset _GGO_PYENV_ORIG_LD_LIBRARY_PATH _GGO_PYENV_ORIG_LD_PRELOAD _GGO_PYENV_ORIG_PATH _GGO_PYENV_ACTIVE\n")
+	script.WriteString("fi\n")
+	return script.String()
+}
+
+// venvDeactivateWrapper returns the shell code that makes the deactivate
+// function of a virtualenv source deactivateFile first. The original function
+// is copied under another name; the first line of `typeset -f` output reads
+// "deactivate ()" in both bash and zsh. It goes before the activate script, so
+// that sed does not run with the GPU libraries preloaded.
+func venvDeactivateWrapper(deactivateFile string) string {
+	var script strings.Builder
+	script.WriteString("# Tear the GPU environment down with the virtualenv\n")
+	script.WriteString("if typeset -f deactivate >/dev/null 2>&1; then\n")
+	script.WriteString("  eval \"$(typeset -f deactivate | sed '1s/^deactivate/_ggo_venv_deactivate/')\"\n")
+	script.WriteString("  deactivate() {\n")
+	fmt.Fprintf(&script, "    . %s\n", studio.ShellQuote(deactivateFile))
+	script.WriteString("    _ggo_venv_deactivate \"$@\"\n")
+	script.WriteString("  }\n")
+	script.WriteString("fi\n\n")
+	return script.String()
+}
+
+// pyEnvResult implements Renderable for 'ggo use --conda-env' and '--venv'
+type pyEnvResult struct {
+	Connection string       `json:"connection"`
+	Target     *pyEnvTarget `json:"target"`
+	Files      []string     `json:"files"`
+}
+
+func (r *pyEnvResult) RenderJSON() any {
+	return r
+}
+
+func (r *pyEnvResult) RenderTUI(out *tui.Output) {
+	if r.Target.Kind == pyEnvConda {
+		out.Successf("GPU environment hooked into conda environment %s", r.Target.Name)
+	} else {
+		out.Successf("GPU environment hooked into virtualenv %s", r.Target.Prefix)
+	}
+	out.Println(tui.NewStatusTable().
+		Add("Environment", r.Target.Prefix).
+		Add("Hook", r.Files[0]).
+		String())
+	out.Println()
+	out.Println("Activating it now sets up the GPU libraries, and deactivating it removes them:")
+	if r.Target.Kind == pyEnvConda {
+		out.Printf("\n   conda activate %s\n\n", r.Target.Prefix)
+	} else {
+		out.Printf("\n   source %s\n\n", filepath.Join(r.Target.Prefix, "bin", "activate"))
+	}
+	out.Println("To remove the hook:")
+	out.Printf("\n   ggo clean %s\n\n", r.Connection)
+}
+
+// validatePythonEnvFlags rejects --conda-env and --venv in combinations they
+// do not support. The hooks are shell scripts, and they replace activating
+// the environment in the current shell.
+func validatePythonEnvFlags(condaEnv, venv string, ci, longTerm, yes bool) error {
+	switch {
+	case condaEnv != "" && venv != "":
+		return cmdutil.UsageErrorf("--conda-env and --venv cannot be combined")
+	case platform.IsWindows():
+		return fmt.Errorf("--conda-env and --venv are not supported on Windows")
+	case ci || longTerm:
+		return cmdutil.UsageErrorf("--conda-env and --venv cannot be combined with --ci or --long-term")
+	case yes:
+		return cmdutil.UsageErrorf("--conda-env and --venv cannot be combined with -y; activate the Python environment instead")
+	}
+	return nil
+}
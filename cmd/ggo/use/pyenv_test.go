@@ -0,0 +1,120 @@
+package use
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchCondaEnv(t *testing.T) {
+	info := &condaInfo{
+		RootPrefix: "/opt/conda",
+		Envs:       []string{"/opt/conda", "/opt/conda/envs/train", "/home/u/.conda/envs/infer"},
+	}
+
+	prefix, ok := matchCondaEnv(info, "base")
+	assert.True(t, ok)
+	assert.Equal(t, "/opt/conda", prefix)
+
+	prefix, ok = matchCondaEnv(info, "infer")
+	assert.True(t, ok)
+	assert.Equal(t, "/home/u/.conda/envs/infer", prefix, "environments outside the base envs directory are found")
+
+	_, ok = matchCondaEnv(info, "conda")
+	assert.False(t, ok, "the root prefix only matches as base")
+	_, ok = matchCondaEnv(info, "missing")
+	assert.False(t, ok)
+}
+
+func TestResolvePythonEnvPaths(t *testing.T) {
+	dir := t.TempDir()
+	_, err := resolveCondaEnv(dir)
+	assert.Error(t, err, "a directory without conda-meta is not a conda environment")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "conda-meta"), 0755))
+	target, err := resolveCondaEnv(dir)
+	require.NoError(t, err)
+	assert.Equal(t, &pyEnvTarget{Kind: pyEnvConda, Name: filepath.Base(dir), Prefix: dir}, target)
+
+	venv := t.TempDir()
+	_, err = resolveVirtualenv(venv)
+	assert.Error(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(venv, "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(venv, "bin", "activate"), nil, 0644))
+	target, err = resolveVirtualenv(venv)
+	require.NoError(t, err)
+	assert.Equal(t, pyEnvVirtualenv, target.Kind)
+}
+
+func TestValidatePythonEnvFlags(t *testing.T) {
+	assert.Error(t, validatePythonEnvFlags("ml", ".venv", false, false, false))
+	assert.Error(t, validatePythonEnvFlags("ml", "", true, false, false))
+	assert.Error(t, validatePythonEnvFlags("", ".venv", false, true, false))
+	assert.Error(t, validatePythonEnvFlags("ml", "", false, false, true))
+	if runtime.GOOS != "windows" {
+		assert.NoError(t, validatePythonEnvFlags("ml", "", false, false, false))
+	}
+}
+
+// TestVirtualenvHook activates and deactivates a minimal virtualenv with the
+// hook appended, and checks that the environment is restored
+func TestVirtualenvHook(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil || runtime.GOOS == "windows" {
+		t.Skip("bash is not available")
+	}
+
+	dir := t.TempDir()
+	config := &studio.GPUEnvConfig{
+		Vendor:         studio.VendorNvidia,
+		LibsPath:       filepath.Join(dir, "it's-libs"),
+		BinPath:        filepath.Join(dir, "bin"),
+		ConnectionName: "abc123",
+	}
+	envResult := &studio.GPUEnvResult{EnvVars: map[string]string{
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO": "native+10.0.0.1+8000+abc123",
+		studio.ConnectionEnv:                     "abc123",
+	}}
+	target := &pyEnvTarget{Kind: pyEnvVirtualenv, Name: "venv", Prefix: dir}
+
+	deactivateFile := filepath.Join(dir, "venv-deactivate.sh")
+	activateFile := filepath.Join(dir, "venv-activate.sh")
+	require.NoError(t, writeHookScript(deactivateFile, pyEnvDeactivateScript(envResult)))
+	require.NoError(t, writeHookScript(activateFile, venvDeactivateWrapper(deactivateFile)+pyEnvActivateScript(config, envResult, target)))
+
+	venvActivate := filepath.Join(dir, "activate")
+	venvScript := "deactivate () {\n  unset VIRTUAL_ENV\n  [ \"$1\" = nondestructive ] || unset -f deactivate\n}\nexport VIRTUAL_ENV=venv\n"
+	require.NoError(t, os.WriteFile(venvActivate, []byte(venvScript), 0644))
+	sourceLine := ". " + studio.ShellQuote(activateFile)
+	require.NoError(t, appendToFile(venvActivate, "\n"+profileMarker+"\n"+sourceLine+"\n", activateFile))
+
+	script := `
+export LD_LIBRARY_PATH=/usr/lib
+unset LD_PRELOAD
+. "$1"
+echo "active=$LD_LIBRARY_PATH|$LD_PRELOAD|$_GGO_CONNECTION|$VIRTUAL_ENV"
+deactivate
+echo "inactive=$LD_LIBRARY_PATH|${LD_PRELOAD-unset}|${_GGO_CONNECTION-unset}|${VIRTUAL_ENV-unset}"
+`
+	output, err := exec.Command(bash, "-c", script, "bash", venvActivate).CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	libs := config.LibsPath
+	preload := filepath.Join(libs, "libcuda.so") + ":" + filepath.Join(libs, "libnvidia-ml.so")
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	require.Len(t, lines, 2, string(output))
+	assert.Equal(t, "active="+libs+":/usr/lib|"+preload+"|abc123|venv", lines[0])
+	assert.Equal(t, "inactive=/usr/lib|unset|unset|unset", lines[1])
+
+	// ggo clean removes the hook from the activate script again
+	removeProfileLine(venvActivate, sourceLine)
+	data, err := os.ReadFile(venvActivate)
+	require.NoError(t, err)
+	assert.Equal(t, venvScript+"\n", string(data))
+}
@@ -0,0 +1,353 @@
+package use
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/version"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+// NewRunCmd creates the run command, which runs one program in a GPU
+// environment without activating it in the shell.
+// Returns nil on macOS, like 'ggo use'.
+func NewRunCmd() *cobra.Command {
+	if platform.IsDarwin() {
+		return nil
+	}
+
+	var (
+		share     string
+		isolate   bool
+		anonymous bool
+		force     bool
+		verbose   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run [--share <share-link>|<name>] -- <program> [args...]",
+		Short: "Run a program on a remote GPU without activating the shell",
+		Long: `Run a single program with the remote GPU environment applied to it alone.
+
+'ggo use' activates the GPU environment in the whole shell: every process
+started from it loads the GPU client libraries (LD_PRELOAD on Linux), which
+can break tools such as strace or the system package manager. 'ggo run'
+leaves the shell untouched and only sets the environment of the program it
+starts; on Windows the variables and PATH entries are injected into that
+process.
+
+The GPU comes from --share, a share link, short code or the name of an
+environment set up with 'ggo use'. Without --share, the environment of the
+current shell is used, or the only environment 'ggo use' set up.
+
+By default, processes the program starts inherit the environment. With
+--isolate (Linux), the program is started through its dynamic loader with
+the libraries passed as loader options instead, so they are loaded into the
+program itself but not into anything it runs. Child processes that need the
+GPU, such as Python multiprocessing workers started with 'spawn', do not get
+it then. --isolate needs glibc 2.30 or newer.
+
+The exit code is the program's.
+
+Examples:
+  # Run a training script on the environment set up with 'ggo use'
+  ggo run -- python train.py
+
+  # Pick the share directly, no 'ggo use' needed
+  ggo run --share abc123 -- python train.py
+
+  # Use a named environment ('ggo use abc123 --name training')
+  ggo run --share training -- jupyter notebook
+
+  # Keep the GPU libraries out of the processes the program starts
+  ggo run --isolate -- python train.py`,
+		Args: cobra.MinimumNArgs(1),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			klog.InitFlags(nil)
+			// Keep informational logs off the program's terminal
+			flag.Set("logtostderr", "false")
+			flag.Set("stderrthreshold", "WARNING")
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isolate && !platform.IsLinux() {
+				return fmt.Errorf("--isolate is only supported on Linux")
+			}
+			cmd.SilenceUsage = true
+			out := getOutput()
+			ctx := context.Background()
+
+			rec, err := resolveRunConnection(share)
+			if err != nil {
+				klog.Errorf("Failed to resolve GPU environment: share=%s error=%v", share, err)
+				return err
+			}
+
+			client := api.NewClient(api.WithBaseURL(serverURL))
+			shareInfo, err := client.GetSharePublic(ctx, rec.ShortCode)
+			if err != nil {
+				klog.Errorf("Failed to get share info: short_link=%s error=%v", rec.ShortCode, err)
+				return fmt.Errorf("failed to get share info: %w", err)
+			}
+			cmdutil.SelectShareAddress(ctx, shareInfo)
+			if err := cmdutil.VerifyShareTLS(ctx, shareInfo); err != nil {
+				klog.Errorf("Failed to verify GPU worker: worker_id=%s error=%v", shareInfo.WorkerID, err)
+				return err
+			}
+			shareInfo.ConnectionURL = shareInfo.ConnectionURL + "+" + rec.ShortCode
+			klog.Infof("Found GPU worker: worker_id=%s vendor=%s connection_url=%s", shareInfo.WorkerID, shareInfo.HardwareVendor, shareInfo.ConnectionURL)
+			if !anonymous {
+				cmdutil.RegisterShareConsumer(ctx, client, rec.ShortCode, "run", version.Version)
+			}
+
+			libs, err := ensureRemoteGPUClientLibs(ctx, out, shareInfo.HardwareVendor, !verbose, false)
+			if err != nil {
+				klog.Errorf("Failed to ensure GPU client libraries: error=%v", err)
+				return cmdutil.DependencyError(fmt.Errorf("failed to download GPU client libraries: %w", err))
+			}
+			if err := checkClientLibsABI(ctx, out, libs, force); err != nil {
+				klog.Errorf("GPU client libraries are incompatible with this host: error=%v", err)
+				return err
+			}
+			if err := ensureGPUBinary(ctx, out, shareInfo.HardwareVendor, !verbose); err != nil {
+				klog.Warningf("Failed to ensure GPU binary: %v (continuing without it)", err)
+			}
+
+			config := temporaryEnvConfig(shareInfo, rec)
+			envResult, err := studio.SetupGPUEnv(paths, config)
+			if err != nil {
+				return fmt.Errorf("failed to setup GPU environment: %w", err)
+			}
+			rec.WorkerID = shareInfo.WorkerID
+			rec.AddDirs(paths.StudioConfigDir(config.StudioName))
+			recordUseConnection(rec)
+
+			execCmd, err := runCommand(config, envResult, args, isolate)
+			if err != nil {
+				klog.Errorf("Failed to prepare program: program=%s error=%v", args[0], err)
+				return err
+			}
+			if verbose {
+				out.Infof("Running %s on GPU worker %s (%s)", args[0], shareInfo.WorkerID, rec.ID())
+			}
+			klog.Infof("Running program: connection=%s isolate=%t args=%v", rec.ID(), isolate, execCmd.Args)
+
+			code, err := runForwardingSignals(execCmd)
+			if err != nil {
+				klog.Errorf("Failed to run program: program=%s error=%v", args[0], err)
+				return err
+			}
+			if code != 0 {
+				os.Exit(code)
+			}
+			return nil
+		},
+	}
+
+	// Flags after the program name are the program's
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL (or set GPU_GO_ENDPOINT env var)")
+	cmd.Flags().StringVarP(&share, "share", "s", "", "Share link, short code or name of a 'ggo use' environment (default: the current one)")
+	cmd.Flags().BoolVar(&isolate, "isolate", false, "Load the GPU libraries into the program only, not into processes it starts (Linux)")
+	cmd.Flags().BoolVar(&anonymous, "anonymous", false, "Don't register this machine with the share owner")
+	cmd.Flags().BoolVar(&force, "force", false, "Run even if the client libraries fail the compatibility check")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show download progress and the GPU worker used")
+
+	return cmd
+}
+
+// resolveRunConnection finds the environment 'ggo run' uses: the one named by
+// share, a new one for a share link, or the one the shell is in or the only
+// one set up
+func resolveRunConnection(share string) (*studio.UseConnection, error) {
+	registry := studio.NewUseRegistry(paths)
+	if share != "" {
+		rec, err := registry.Get(share)
+		if err != nil {
+			return nil, err
+		}
+		if rec == nil {
+			rec, err = registry.Get(cmdutil.ExtractShortCode(share))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if rec == nil {
+			rec = &studio.UseConnection{ShortCode: cmdutil.ExtractShortCode(share)}
+		}
+		return rec, nil
+	}
+
+	if current := os.Getenv(studio.ConnectionEnv); current != "" {
+		rec, err := registry.Get(current)
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil {
+			return rec, nil
+		}
+	}
+	conns, err := registry.List()
+	if err != nil {
+		return nil, err
+	}
+	switch len(conns) {
+	case 0:
+		return nil, fmt.Errorf("no GPU environment set up; pass --share <share-link> or run 'ggo use <share-link>' first")
+	case 1:
+		return &conns[0], nil
+	}
+	names := make([]string, len(conns))
+	for i := range conns {
+		names[i] = conns[i].ID()
+	}
+	return nil, fmt.Errorf("%d GPU environments are set up (%s); pick one with --share", len(conns), strings.Join(names, ", "))
+}
+
+// runCommand builds the command running args in the GPU environment. With
+// isolate, the preloaded libraries and library path are passed to the
+// program's loader instead of being set in its environment.
+func runCommand(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, args []string, isolate bool) (*exec.Cmd, error) {
+	vars, pathDirs := ciEnvironment(config, envResult)
+	// The program's shell, if it starts one, is not an activated shell
+	delete(vars, "_GGO_ACTIVE")
+	// SetupGPUEnv's PATH is rebuilt below with the platform's separator
+	delete(vars, "PATH")
+	if config.CachePath != "" {
+		pathDirs = append(pathDirs, config.CachePath)
+	}
+
+	env := os.Environ()
+	pathList := strings.Join(pathDirs, string(os.PathListSeparator))
+	env = setEnvVar(env, "PATH", prependList(pathList, os.Getenv("PATH"), string(os.PathListSeparator)))
+
+	var preload []string
+	var libraryPath string
+	if isolate {
+		// The variables keep the values they have in this process
+		libraryPath = vars["_GGO_LIBS_PATH"]
+		for _, lib := range studio.GetLibraryNames(config.Vendor) {
+			preload = append(preload, filepath.Join(libraryPath, lib))
+		}
+		delete(vars, "LD_PRELOAD")
+		delete(vars, "LD_LIBRARY_PATH")
+	}
+	for _, k := range studio.SortedKeys(vars) {
+		env = setEnvVar(env, k, vars[k])
+	}
+
+	// Look the program up in the PATH it runs with, which has the GPU tools
+	exe, err := lookPathIn(args[0], envValue(env, "PATH"))
+	if err != nil {
+		return nil, cmdutil.NotFoundErrorf("program not found: %s", args[0])
+	}
+	cmdline := append([]string{exe}, args[1:]...)
+	if isolate {
+		if cmdline, err = isolatedCommand(exe, args[1:], preload, libraryPath); err != nil {
+			return nil, err
+		}
+	}
+
+	execCmd := exec.Command(cmdline[0], cmdline[1:]...)
+	execCmd.Env = env
+	execCmd.Stdin = os.Stdin
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+	return execCmd, nil
+}
+
+// lookPathIn is exec.LookPath searching pathList instead of $PATH
+func lookPathIn(program, pathList string) (string, error) {
+	if strings.ContainsAny(program, `/\`) {
+		return exec.LookPath(program)
+	}
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		if exe, err := exec.LookPath(filepath.Join(dir, program)); err == nil {
+			return exe, nil
+		}
+	}
+	return "", exec.ErrNotFound
+}
+
+// runForwardingSignals runs cmd, passing termination signals on to it, and
+// returns its exit code. A program killed by a signal exits with 128 plus
+// the signal number, like in a shell.
+func runForwardingSignals(cmd *exec.Cmd) (int, error) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to run program: %w", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				// Ctrl+C reaches the program through the terminal as well;
+				// failing to pass it on is harmless
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	err := cmd.Wait()
+	close(done)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return 0, fmt.Errorf("failed to run program: %w", err)
+	}
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal()), nil
+	}
+	return cmd.ProcessState.ExitCode(), nil
+}
+
+// envValue returns the value of key in env, the last one if set repeatedly
+func envValue(env []string, key string) string {
+	value := ""
+	for _, e := range env {
+		if k, v, ok := strings.Cut(e, "="); ok && envKeyEqual(k, key) {
+			value = v
+		}
+	}
+	return value
+}
+
+// setEnvVar sets key in env, replacing every existing entry of it
+func setEnvVar(env []string, key, value string) []string {
+	kept := env[:0]
+	for _, e := range env {
+		if k, _, ok := strings.Cut(e, "="); ok && envKeyEqual(k, key) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return append(kept, key+"="+value)
+}
+
+// envKeyEqual compares variable names, which are case-insensitive on Windows
+func envKeyEqual(a, b string) bool {
+	if platform.IsWindows() {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
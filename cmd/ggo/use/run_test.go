@@ -0,0 +1,102 @@
+package use
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRunConnection(t *testing.T) {
+	orig := paths
+	paths = platform.DefaultPaths().WithConfigDir(t.TempDir())
+	t.Cleanup(func() { paths = orig })
+	t.Setenv(studio.ConnectionEnv, "")
+
+	_, err := resolveRunConnection("")
+	assert.ErrorContains(t, err, "no GPU environment set up")
+
+	rec, err := resolveRunConnection("https://gpu.tf/s/xyz789")
+	require.NoError(t, err)
+	assert.Equal(t, "xyz789", rec.ShortCode, "share links need no 'ggo use'")
+
+	registry := studio.NewUseRegistry(paths)
+	require.NoError(t, registry.Record(&studio.UseConnection{ShortCode: "abc123"}))
+	rec, err = resolveRunConnection("")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", rec.ShortCode, "the only environment is used")
+
+	require.NoError(t, registry.Record(&studio.UseConnection{Name: "training", ShortCode: "def456"}))
+	_, err = resolveRunConnection("")
+	assert.ErrorContains(t, err, "pick one with --share")
+
+	rec, err = resolveRunConnection("training")
+	require.NoError(t, err)
+	assert.Equal(t, "def456", rec.ShortCode)
+
+	t.Setenv(studio.ConnectionEnv, "training")
+	rec, err = resolveRunConnection("")
+	require.NoError(t, err)
+	assert.Equal(t, "def456", rec.ShortCode, "the shell's environment wins")
+}
+
+func TestSetEnvVar(t *testing.T) {
+	env := []string{"A=1", "PATH=/usr/bin", "B=2", "PATH=/bin"}
+	env = setEnvVar(env, "PATH", "/gpu:/usr/bin")
+	assert.Equal(t, []string{"A=1", "B=2", "PATH=/gpu:/usr/bin"}, env)
+	assert.Equal(t, "/gpu:/usr/bin", envValue(env, "PATH"))
+	assert.Empty(t, envValue(env, "MISSING"))
+}
+
+func TestResolveInterpreter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("scripts with #! lines run on Unix only")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "train")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh -e\necho hi\n"), 0755))
+	exe, args, err := resolveInterpreter(script, []string{"--epochs", "3"})
+	require.NoError(t, err)
+	assert.Equal(t, "/bin/sh", exe)
+	assert.Equal(t, []string{"-e", script, "--epochs", "3"}, args)
+
+	envScript := filepath.Join(dir, "serve")
+	require.NoError(t, os.WriteFile(envScript, []byte("#!/usr/bin/env sh\necho hi\n"), 0755))
+	exe, args, err = resolveInterpreter(envScript, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "sh", filepath.Base(exe), "env is skipped so the loader starts the interpreter")
+	assert.Equal(t, []string{envScript}, args)
+
+	flagged := filepath.Join(dir, "flagged")
+	require.NoError(t, os.WriteFile(flagged, []byte("#!/usr/bin/env -S python3 -u\n"), 0755))
+	_, _, err = resolveInterpreter(flagged, nil)
+	assert.ErrorContains(t, err, "unsupported interpreter line")
+
+	binary := filepath.Join(dir, "binary")
+	require.NoError(t, os.WriteFile(binary, []byte{0x7f, 'E', 'L', 'F'}, 0755))
+	exe, args, err = resolveInterpreter(binary, []string{"x"})
+	require.NoError(t, err)
+	assert.Equal(t, binary, exe)
+	assert.Equal(t, []string{"x"}, args)
+}
+
+func TestProgramLoader(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("ELF programs only")
+	}
+	sh, err := filepath.EvalSymlinks("/bin/sh")
+	require.NoError(t, err)
+	loader, err := programLoader(sh)
+	require.NoError(t, err)
+	assert.Contains(t, filepath.Base(loader), "ld-")
+
+	text := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(text, []byte("hello\n"), 0644))
+	_, err = programLoader(text)
+	assert.ErrorContains(t, err, "not an ELF program")
+}
@@ -10,12 +10,19 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/NexusGPU/gpu-go/cmd/ggo/auth"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/cmd/ggo/version"
 	"github.com/NexusGPU/gpu-go/internal/api"
 	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/progress"
 	"github.com/NexusGPU/gpu-go/internal/studio"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/spf13/cobra"
@@ -30,27 +37,14 @@ const (
 	shellCMD        = "cmd"
 )
 
+// profileMarker precedes every line 'ggo use' appends to a shell profile
+const profileMarker = "# GPU Go environment"
+
 var (
 	serverURL    string
 	outputFormat string
 )
 
-// extractShortCode extracts the short code from a short link URL or returns the input as-is if it's already a code.
-// Supports formats: "abc123", "https://gpu.tf/s/abc123", "gpu.tf/s/abc123"
-func extractShortCode(input string) string {
-	input = strings.TrimSpace(input)
-
-	// If it looks like a URL, extract the last path segment
-	if strings.Contains(input, "/") {
-		parts := strings.Split(strings.TrimSuffix(input, "/"), "/")
-		if len(parts) > 0 {
-			return parts[len(parts)-1]
-		}
-	}
-
-	return input
-}
-
 // NewUseCmd creates the use command
 // Returns nil on macOS (use command is disabled on macOS)
 func NewUseCmd() *cobra.Command {
@@ -60,18 +54,38 @@ func NewUseCmd() *cobra.Command {
 	}
 
 	var (
-		longTerm  bool
-		outputDir string
-		yes       bool
+		longTerm   bool
+		outputDir  string
+		name       string
+		yes        bool
+		anonymous  bool
+		fastest    bool
+		rankingTTL time.Duration
+		team       string
+		worker     string
+		force      bool
+		insecure   bool
+		ci         bool
+		envFile    string
+		wait       bool
+		waitFor    time.Duration
+		condaEnv   string
+		venv       string
+		wsl        bool
+		wslDistro  string
+		replace    bool
+		stack      bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "use <share-link>",
+		Use:   "use <share-link>[,<share-link>...] | --team <team> --worker <worker>",
 		Short: "Set up a remote GPU environment",
 		Long: `Set up a temporary or long-term connection to a remote GPU worker.
 
 This command connects to a shared GPU worker and sets up the environment
-so you can use the remote GPU as if it were local.
+so you can use the remote GPU as if it were local. When the worker is served
+over TLS, its certificate is checked against the fingerprint published with
+the share before anything is set up.
 
 Examples:
   # Connect using short code (will prompt to activate)
@@ -80,12 +94,109 @@ Examples:
   # Connect using full short link
   ggo use https://gpu.tf/s/abc123
 
+  # Connect using the alias of one of your own shares, signed in with
+  # 'ggo login' ('ggo worker share my-worker --alias lab-3090')
+  ggo use lab-3090
+
   # Activate in current shell (recommended)
   eval "$(ggo use abc123 -y)"
 
+  # Switch a shell that is already activated to another worker without
+  # asking, or stack it so that 'ggo clean' returns to the first one
+  eval "$(ggo use def456 -y --replace)"
+  eval "$(ggo use def456 -y --stack)"
+
   # Set up a long-term GPU connection (persists across shell sessions)
-  ggo use abc123 --long-term`,
-		Args: cobra.ExactArgs(1),
+  ggo use abc123 --long-term
+
+  # Keep a second environment for the same share side by side
+  ggo use abc123 --name training
+
+  # Use whichever of several shared workers answers fastest; the choice is
+  # reused until --ranking-ttl expires, so repeated activations stay quick
+  eval "$(ggo use abc123,def456,ghi789 --fastest -y)"
+
+  # Use a worker shared with your team, signed in with 'ggo login'
+  # ('ggo worker list --team ml-infra' lists them)
+  ggo use --team ml-infra --worker my-worker
+
+  # On a CI runner: no prompts, JSON output, and the environment written to
+  # a dotenv file (and $GITHUB_ENV / $GITHUB_PATH on GitHub Actions)
+  ggo use abc123 --ci
+  ggo clean --ci    # in a post step
+
+  # Queue for a busy worker for up to 30 minutes
+  eval "$(ggo use abc123 --wait --wait-timeout 30m -y)"
+
+  # Set up the GPU whenever a conda environment or a virtualenv is
+  # activated, and tear it down again on deactivate
+  ggo use abc123 --conda-env myenv
+  ggo use abc123 --venv .venv
+
+  # On Windows, set up the Linux libraries inside a WSL distribution and
+  # pass the environment to the wsl.exe commands of this PowerShell session
+  ggo use abc123 --wsl --wsl-distro Ubuntu
+  ggo use abc123 --wsl -y | Out-String | Invoke-Expression
+
+  # List configured environments
+  ggo use list
+
+A worker can limit how many clients it serves at once. When it is full, ggo
+use reports the connected clients, the queue and the estimated wait, and
+fails; with --wait it queues and polls until a slot frees up.
+
+The share owner sees this machine's hostname, OS and ggo version in
+'ggo share inspect'; pass --anonymous to connect without registering.
+
+Before activation the downloaded client libraries are checked against this
+machine: their architecture, the glibc version they need, and libraries of the
+same name a local driver already installs (DLLs in System32 on Windows). Any
+problem is reported and the environment is not activated; pass --force to
+activate anyway.
+
+When the shell is already activated for a GPU environment, ggo use -y shows
+it and asks whether to replace it, stack the new environment on it so that
+'ggo clean' returns to it, or abort. --replace and --stack decide without
+asking; with no terminal to ask on, ggo use fails with exit code 5. A new
+shell started by ggo use always starts without the current environment and
+returns to it on exit.
+
+With --ci, failures are printed to stdout as {"success":false,"error":
+{"code":...,"message":...}} and, on GitHub Actions, as an error annotation.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if ci && longTerm {
+				return ciFail(ciCodeInvalidArguments, cmdutil.UsageErrorf("--ci cannot be combined with --long-term"))
+			}
+			if envFile != "" && !ci {
+				return cmdutil.UsageErrorf("--env-file requires --ci")
+			}
+			if condaEnv != "" || venv != "" {
+				if err := validatePythonEnvFlags(condaEnv, venv, ci, longTerm, yes); err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("wsl-distro") && !wsl {
+				return cmdutil.UsageErrorf("--wsl-distro requires --wsl")
+			}
+			if wsl {
+				if err := validateWSLFlags(ci, longTerm, condaEnv != "" || venv != ""); err != nil {
+					return err
+				}
+			}
+			if (replace || stack) && (longTerm || ci || condaEnv != "" || venv != "" || wsl) {
+				return cmdutil.UsageErrorf("--replace and --stack only apply to activating a shell")
+			}
+			if team != "" || worker != "" {
+				if team == "" || worker == "" {
+					return cmdutil.UsageErrorf("--team and --worker must be given together")
+				}
+				if fastest {
+					return cmdutil.UsageErrorf("--fastest cannot be combined with --team")
+				}
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			// Initialize klog flags if not already initialized
 			klog.InitFlags(nil)
@@ -96,16 +207,143 @@ Examples:
 			flag.Set("stderrthreshold", "WARNING")
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			shortCode := extractShortCode(args[0])
 			client := api.NewClient(api.WithBaseURL(serverURL))
 			ctx := context.Background()
+			var codes []string
+			if team == "" {
+				codes = parseShareCodes(args[0])
+				// Aliases name shares of the signed-in user's account
+				if signedInToken() != "" {
+					for i, code := range codes {
+						if shortCode, ok := cmdutil.ResolveShareAlias(ctx, userClient(), code); ok {
+							codes[i] = shortCode
+						}
+					}
+				}
+			}
 			out := getOutput()
+			if ci {
+				// Machine mode: never prompt, only JSON on stdout
+				yes = true
+				out = cmdutil.NewOutput("json")
+			}
 
-			shareInfo, err := client.GetSharePublic(ctx, shortCode)
-			if err != nil {
+			if len(codes) > 1 && !fastest {
 				cmd.SilenceUsage = true
-				klog.Errorf("Failed to get share info: error=%v", err)
-				return err
+				return ciFail(ciCodeInvalidArguments, fmt.Errorf("%d share codes given; pass --fastest to pick one of them", len(codes)))
+			}
+
+			// Find the Python environment before anything is downloaded
+			var pyEnv *pyEnvTarget
+			if condaEnv != "" || venv != "" {
+				var err error
+				if condaEnv != "" {
+					pyEnv, err = resolveCondaEnv(condaEnv)
+				} else {
+					pyEnv, err = resolveVirtualenv(venv)
+				}
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to find Python environment: conda_env=%s venv=%s error=%v", condaEnv, venv, err)
+					return err
+				}
+			}
+
+			// Settle what happens to a GPU environment the shell already has
+			// before anything is downloaded
+			mode := activateNew
+			if !longTerm && !ci && pyEnv == nil && !wsl {
+				var err error
+				mode, err = resolveActivationMode(currentActivation(), replace, stack, yes, out)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				if mode == activateAbort {
+					return nil
+				}
+			}
+
+			var (
+				shortCode string
+				shareInfo *api.SharePublicInfo
+				route     string
+				err       error
+			)
+			if team != "" {
+				var teamShare *api.TeamShareInfo
+				teamShare, err = userClient().GetTeamWorkerShare(ctx, team, worker)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to get team share: team=%s worker=%s error=%v", team, worker, err)
+					return ciFail(ciCodeShareUnavailable, fmt.Errorf("failed to resolve worker %s of team %s (are you signed in with 'ggo login'?): %w", worker, team, err))
+				}
+				shortCode, shareInfo = teamShare.ShortCode, &teamShare.SharePublicInfo
+				cmdutil.SelectShareAddress(ctx, shareInfo)
+				if err := cmdutil.VerifyShareTLS(ctx, shareInfo); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to verify GPU worker: worker_id=%s error=%v", shareInfo.WorkerID, err)
+					return ciFail(ciCodeWorkerUntrusted, err)
+				}
+				latency, latencyErr := cmdutil.ShareLatency(ctx, shareInfo)
+				route = cmdutil.FormatShareRoute(shareInfo, latency, latencyErr)
+			} else if fastest {
+				var probe *cmdutil.ShareProbe
+				probe, err = selectFastestShare(ctx, client, codes, rankingTTL, yes, out)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to select share: codes=%s error=%v", strings.Join(codes, ","), err)
+					return ciFail(ciCodeShareUnavailable, err)
+				}
+				shortCode, shareInfo = probe.ShortCode, probe.Info
+				route = cmdutil.FormatShareRoute(shareInfo, probe.Latency, probe.Err)
+			} else {
+				shortCode = codes[0]
+				shareInfo, err = client.GetSharePublic(ctx, shortCode)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to get share info: error=%v", err)
+					return ciFail(ciCodeShareUnavailable, err)
+				}
+
+				// Fall back to the other IP family if the primary one is unreachable
+				cmdutil.SelectShareAddress(ctx, shareInfo)
+
+				// Check the pinned certificate before any traffic reaches the worker
+				if err := cmdutil.VerifyShareTLS(ctx, shareInfo); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to verify GPU worker: worker_id=%s error=%v", shareInfo.WorkerID, err)
+					return ciFail(ciCodeWorkerUntrusted, err)
+				}
+
+				latency, latencyErr := cmdutil.ShareLatency(ctx, shareInfo)
+				route = cmdutil.FormatShareRoute(shareInfo, latency, latencyErr)
+			}
+
+			// A worker serving its maximum number of clients cannot take this one yet
+			waiterID := newWaiterID()
+			pollQueue := func(ctx context.Context) (*api.ShareQueueStatus, error) {
+				if team != "" {
+					teamShare, err := userClient().GetTeamWorkerShare(ctx, team, worker)
+					if err != nil {
+						return nil, err
+					}
+					return teamShare.Queue, nil
+				}
+				info, err := client.WaitSharePublic(ctx, shortCode, waiterID)
+				if err != nil {
+					return nil, err
+				}
+				return info.Queue, nil
+			}
+			if err := waitForFreeSlot(ctx, shareInfo, pollQueue, wait, waitFor, yes, out); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("GPU worker has no free slot: worker_id=%s error=%v", shareInfo.WorkerID, err)
+				return ciFail(ciCodeWorkerBusy, err)
+			}
+			klog.Infof("GPU worker route: worker_id=%s route=%q", shareInfo.WorkerID, route)
+			if !yes && !out.IsJSON() {
+				out.Infof("Connecting to GPU worker %s (%s)", shareInfo.WorkerID, route)
 			}
 
 			// Append share code to connection URL for authentication
@@ -113,12 +351,32 @@ Examples:
 
 			klog.Infof("Found GPU worker: worker_id=%s vendor=%s connection_url=%s", shareInfo.WorkerID, shareInfo.HardwareVendor, shareInfo.ConnectionURL)
 
+			if !anonymous && team == "" {
+				// Team members are known to the platform by their login
+				cmdutil.RegisterShareConsumer(ctx, client, shortCode, "use", version.Version)
+			}
+
+			if wsl {
+				// The distro gets the Linux libraries, checked by its own loader
+				cmd.SilenceUsage = true
+				rec := &studio.UseConnection{Name: name, ShortCode: shortCode, WorkerID: shareInfo.WorkerID}
+				return setupWSLEnv(ctx, shareInfo, rec, wslDistro, yes, insecure, out)
+			}
+
 			// Download required libraries first (silent when -y is used for eval)
 			// Filter by vendor from share info to avoid downloading unnecessary libraries
-			if err := ensureRemoteGPUClientLibs(ctx, out, shareInfo.HardwareVendor, yes); err != nil {
+			libs, err := ensureRemoteGPUClientLibs(ctx, out, shareInfo.HardwareVendor, yes, insecure)
+			if err != nil {
 				cmd.SilenceUsage = true
 				klog.Errorf("Failed to ensure GPU client libraries: error=%v", err)
-				return fmt.Errorf("failed to download GPU client libraries: %w", err)
+				return ciFail(ciCodeLibraryDownload, fmt.Errorf("failed to download GPU client libraries: %w", err))
+			}
+
+			// Refuse libraries the local loader would reject before touching the environment
+			if err := checkClientLibsABI(ctx, out, libs, force); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("GPU client libraries are incompatible with this host: error=%v", err)
+				return ciFail(ciCodeLibraryABI, err)
 			}
 
 			// Download GPU binary (like nvidia-smi) if available for this vendor
@@ -127,10 +385,19 @@ Examples:
 				klog.Warningf("Failed to ensure GPU binary: %v (continuing without it)", err)
 			}
 
+			rec := &studio.UseConnection{Name: name, ShortCode: shortCode, WorkerID: shareInfo.WorkerID, LongTerm: longTerm}
 			if longTerm {
-				return setupLongTermEnv(shareInfo, outputDir, yes, out)
+				return setupLongTermEnv(shareInfo, rec, outputDir, yes, out)
+			}
+			if ci {
+				cmd.SilenceUsage = true
+				return setupCIEnv(shareInfo, rec, envFile, out)
 			}
-			return setupTemporaryEnv(shareInfo, yes, out)
+			if pyEnv != nil {
+				cmd.SilenceUsage = true
+				return setupPythonEnv(shareInfo, rec, pyEnv, out)
+			}
+			return setupTemporaryEnv(shareInfo, rec, yes, mode, out)
 		},
 	}
 
@@ -138,11 +405,221 @@ Examples:
 	cmd.Flags().StringVar(&serverURL, "server", api.GetDefaultBaseURL(), "Server URL (or set GPU_GO_ENDPOINT env var)")
 	cmd.Flags().BoolVar(&longTerm, "long-term", false, "Set up a long-term connection")
 	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Output directory for configuration files")
+	cmd.Flags().StringVar(&name, "name", "", "Environment name (defaults to the share code)")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Auto-activate environment (use with eval: eval \"$(ggo use ... -y)\")")
+	cmd.Flags().BoolVar(&anonymous, "anonymous", false, "Don't register this machine with the share owner")
+	cmd.Flags().BoolVar(&fastest, "fastest", false, "Probe comma-separated share codes and use the lowest-latency healthy one")
+	cmd.Flags().DurationVar(&rankingTTL, "ranking-ttl", defaultRankingTTL, "How long --fastest reuses its last ranking (0 always probes)")
+	cmd.Flags().StringVar(&team, "team", "", "Use a worker shared with this team (requires 'ggo login' and --worker)")
+	cmd.Flags().StringVar(&worker, "worker", "", "Name or ID of the team worker to use (with --team)")
+	cmd.Flags().BoolVar(&force, "force", false, "Activate even if the client libraries fail the compatibility check")
+	cmd.Flags().BoolVar(&wait, "wait", false, "When the worker serves its maximum number of clients, queue until a slot frees up instead of failing")
+	cmd.Flags().DurationVar(&waitFor, "wait-timeout", 0, "Give up waiting with --wait after this long (0 waits indefinitely)")
+	cmd.Flags().BoolVar(&ci, "ci", false, "Machine mode for CI runners: no prompts, JSON output, environment written to --env-file and $GITHUB_ENV")
+	cmd.Flags().StringVar(&envFile, "env-file", "", "Dotenv file written by --ci (default: ci.env in the environment's config directory)")
+	cmd.Flags().StringVar(&condaEnv, "conda-env", "", "Hook the GPU environment into this conda environment (name or path), set up on activate and removed on deactivate")
+	cmd.Flags().StringVar(&venv, "venv", "", "Hook the GPU environment into the activate script of this virtualenv directory")
+	cmd.Flags().BoolVar(&wsl, "wsl", false, "Set up the Linux GPU environment inside a WSL distribution (Windows only)")
+	cmd.Flags().StringVar(&wslDistro, "wsl-distro", "", "WSL distribution for --wsl (default: use default distro)")
+	cmd.Flags().BoolVar(&insecure, "insecure-skip-signature", false, "Skip verifying the publisher signature of downloaded artifacts, for development (or set GGO_INSECURE_SKIP_SIGNATURE=1)")
+	cmd.Flags().BoolVar(&replace, "replace", false, "Deactivate the GPU environment the shell already has instead of asking")
+	cmd.Flags().BoolVar(&stack, "stack", false, "Stack on the GPU environment the shell already has, returning to it on 'ggo clean', instead of asking")
+	cmd.MarkFlagsMutuallyExclusive("replace", "stack")
+
+	cmd.AddCommand(newUseListCmd())
+
+	// In CI mode every failure, including bad arguments, is reported in
+	// machine-readable form
+	validateArgs, run := cmd.Args, cmd.RunE
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		return reportCIFailure(cmd, ci, validateArgs(cmd, args))
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return reportCIFailure(cmd, ci, run(cmd, args))
+	}
 
 	return cmd
 }
 
+// userClient returns a client authenticated as the signed-in user, for team
+// shares (the user must be a member of the team) and the user's share aliases
+func userClient() *api.Client {
+	return api.NewClient(api.WithBaseURL(serverURL), api.WithUserToken(signedInToken()))
+}
+
+// signedInToken returns the user's token from the environment or 'ggo login',
+// or "" when the user is not signed in
+func signedInToken() string {
+	token := os.Getenv("GPU_GO_TOKEN")
+	if token == "" {
+		token = os.Getenv("GPU_GO_USER_TOKEN")
+	}
+	if token == "" {
+		if saved, err := auth.GetToken(); err == nil {
+			token = saved
+		}
+	}
+	return token
+}
+
+// defaultRankingTTL is how long a --fastest ranking is reused
+const defaultRankingTTL = 10 * time.Minute
+
+// parseShareCodes splits a comma-separated list of share codes or links,
+// dropping empty entries and duplicates
+func parseShareCodes(arg string) []string {
+	var codes []string
+	for part := range strings.SplitSeq(arg, ",") {
+		if code := cmdutil.ExtractShortCode(part); code != "" && !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// selectFastestShare picks the lowest-latency healthy share among codes.
+// A ranking younger than ttl is reused as long as its pick still resolves
+// and passes the certificate check; otherwise all shares are probed again and
+// the new ranking recorded.
+func selectFastestShare(ctx context.Context, client *api.Client, codes []string, ttl time.Duration, quiet bool, out *tui.Output) (*cmdutil.ShareProbe, error) {
+	store := studio.NewUseRankingStore(paths)
+
+	if ttl > 0 {
+		ranking, err := store.Get(codes, ttl)
+		if err != nil {
+			klog.Warningf("Failed to read share ranking: error=%v", err)
+		}
+		if code := rankingPick(ranking); code != "" {
+			info, err := client.GetSharePublic(ctx, code)
+			if err == nil {
+				err = cmdutil.VerifyShareTLS(ctx, info)
+			}
+			if err == nil {
+				klog.Infof("Reusing share ranking: short_code=%s probed_at=%s", code, ranking.ProbedAt.Format(time.RFC3339))
+				latency, latencyErr := cmdutil.ShareLatency(ctx, info)
+				return &cmdutil.ShareProbe{ShortCode: code, Info: info, Latency: latency, Err: latencyErr}, nil
+			}
+			klog.Warningf("Ranked share failed, probing again: short_code=%s error=%v", code, err)
+			_ = store.Forget(codes)
+		}
+	}
+
+	probes := cmdutil.ProbeShares(ctx, client, codes)
+	ranking := &studio.ShareRanking{Codes: codes}
+	for _, p := range probes {
+		res := studio.ShareProbeResult{ShortCode: p.ShortCode, Healthy: p.Err == nil}
+		if p.Info != nil {
+			res.WorkerID = p.Info.WorkerID
+		}
+		if p.Err != nil {
+			res.Error = p.Err.Error()
+			klog.Warningf("Share probe failed: short_code=%s error=%v", p.ShortCode, p.Err)
+		} else {
+			res.LatencyMs = p.Latency.Milliseconds()
+		}
+		ranking.Results = append(ranking.Results, res)
+	}
+	if ttl > 0 {
+		if err := store.Record(ranking, ttl); err != nil {
+			klog.Warningf("Failed to record share ranking: error=%v", err)
+		}
+	}
+	if !quiet && !out.IsJSON() {
+		renderShareRanking(ranking, out)
+	}
+
+	if probes[0].Err != nil {
+		return nil, fmt.Errorf("none of the %d shares is reachable: %s: %w", len(probes), probes[0].ShortCode, probes[0].Err)
+	}
+	return &probes[0], nil
+}
+
+// rankingPick returns the share a recorded ranking chose, or "" without one
+func rankingPick(ranking *studio.ShareRanking) string {
+	if ranking == nil {
+		return ""
+	}
+	return ranking.Fastest()
+}
+
+// renderShareRanking prints probe results fastest first
+func renderShareRanking(ranking *studio.ShareRanking, out *tui.Output) {
+	table := tui.NewTable().Headers("SHARE", "WORKER", "LATENCY", "STATUS")
+	for _, res := range ranking.Results {
+		latency, status := fmt.Sprintf("%dms", res.LatencyMs), "healthy"
+		if !res.Healthy {
+			latency, status = "-", res.Error
+		}
+		table.Row(res.ShortCode, res.WorkerID, latency, status)
+	}
+	out.Println(table.String())
+}
+
+// useStudioName returns the studio name holding an environment's generated
+// files. Each connection gets its own, so environments never overwrite each
+// other; the prefix keeps them apart from real studios.
+func useStudioName(rec *studio.UseConnection) string {
+	return "use-" + platform.NormalizeName(rec.ID())
+}
+
+// useListItem is a recorded environment as shown by 'ggo use list'
+type useListItem struct {
+	Name      string    `json:"name"`
+	ShortCode string    `json:"shortCode"`
+	WorkerID  string    `json:"workerId,omitempty"`
+	LongTerm  bool      `json:"longTerm"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newUseListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List configured remote GPU environments",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := getOutput()
+			conns, err := studio.NewUseRegistry(paths).List()
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to list GPU environments: error=%v", err)
+				return err
+			}
+
+			current := os.Getenv(studio.ConnectionEnv)
+			items := make([]useListItem, 0, len(conns))
+			for _, c := range conns {
+				items = append(items, useListItem{
+					Name:      c.ID(),
+					ShortCode: c.ShortCode,
+					WorkerID:  c.WorkerID,
+					LongTerm:  c.LongTerm,
+					Active:    current != "" && c.ID() == current,
+					CreatedAt: c.CreatedAt,
+				})
+			}
+
+			return out.Render(&cmdutil.ListData[useListItem]{
+				Items:   items,
+				Headers: []string{"", "NAME", "SHARE CODE", "WORKER", "MODE", "CREATED"},
+				RowFunc: func(item useListItem, styles *tui.Styles) []string {
+					marker := ""
+					if item.Active {
+						marker = styles.Success.Render("*")
+					}
+					mode := "temporary"
+					if item.LongTerm {
+						mode = "long-term"
+					}
+					return []string{marker, item.Name, item.ShortCode, item.WorkerID, mode, item.CreatedAt.Format("2006-01-02 15:04")}
+				},
+				Empty: "No GPU environments configured. Set one up with 'ggo use <share-link>'.",
+			})
+		},
+	}
+	return cmd
+}
+
 func getOutput() *tui.Output {
 	return cmdutil.NewOutput(outputFormat)
 }
@@ -155,8 +632,24 @@ func isYesResponse(response string) bool {
 
 // ensureRemoteGPUClientLibs downloads remote-gpu-client libraries if not already present
 // vendorSlug filters by vendor (e.g., "nvidia", "amd") to avoid downloading unnecessary libraries
-func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug string, silent bool) error {
-	depsMgr := deps.NewManager()
+// A ggo.lock in the working directory fixes the library versions.
+// Returns the libraries of the vendor, downloaded or already present.
+func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug string, silent, skipSignature bool) ([]deps.Library, error) {
+	return ensureRemoteGPUClientLibsFor(ctx, out, vendorSlug, "", "", silent, skipSignature)
+}
+
+// ensureRemoteGPUClientLibsFor downloads the GPU client libraries for
+// targetOS/targetArch into their per-platform libs directory, or for this
+// host when both are empty
+func ensureRemoteGPUClientLibsFor(ctx context.Context, out *tui.Output, vendorSlug, targetOS, targetArch string, silent, skipSignature bool) ([]deps.Library, error) {
+	lock, lockPath, err := cmdutil.ProjectLockfile()
+	if err != nil {
+		return nil, err
+	}
+	depsMgr := deps.NewManager(deps.WithLockfile(lock), deps.WithInsecureSkipSignature(skipSignature))
+	if lock != nil && !silent && !out.IsJSON() {
+		out.Infof("Using library versions locked in %s", lockPath)
+	}
 
 	// Target library types that are needed for GPU client functionality
 	targetTypes := []string{deps.LibraryTypeRemoteGPUClient, deps.LibraryTypeVGPULibrary}
@@ -166,16 +659,21 @@ func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug
 	}
 
 	progressFn := func(lib deps.Library, downloaded, total int64) {
+		progress.Update(progress.StepLibraries, lib.Name, downloaded, total, progress.UnitBytes)
 		if !silent && !out.IsJSON() && total > 0 {
 			pct := float64(downloaded) / float64(total) * 100
 			fmt.Printf("\r  %s: %.1f%%", lib.Name, pct)
 		}
 	}
 
-	libs, err := depsMgr.EnsureLibrariesByTypes(ctx, targetTypes, vendorSlug, progressFn)
+	progress.StepStarted(progress.StepLibraries, "Downloading GPU client libraries for "+vendorSlug)
+	libs, err := depsMgr.EnsureLibrariesByTypesForPlatform(ctx, targetTypes, vendorSlug, targetOS, targetArch, progressFn)
 	if err != nil {
-		return fmt.Errorf("failed to ensure GPU client libraries: %w", err)
+		err = cmdutil.DependencyError(fmt.Errorf("failed to ensure GPU client libraries: %w", err))
+		progress.Failed(progress.StepLibraries, err)
+		return nil, err
 	}
+	progress.StepFinished(progress.StepLibraries, fmt.Sprintf("%d library(ies) downloaded", len(libs)))
 
 	if !silent && !out.IsJSON() {
 		if len(libs) > 0 {
@@ -186,31 +684,57 @@ func ensureRemoteGPUClientLibs(ctx context.Context, out *tui.Output, vendorSlug
 		}
 	}
 
-	return nil
+	return libs, nil
+}
+
+// checkClientLibsABI refuses client libraries the host's loader would reject,
+// unless force is set, in which case the problems are only reported
+func checkClientLibsABI(ctx context.Context, out *tui.Output, libs []deps.Library, force bool) error {
+	issues := deps.NewManager().CheckLibrariesABI(ctx, libs)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = "  - " + issue.String()
+		klog.Warningf("GPU client library compatibility issue: library=%s kind=%s detail=%s", issue.Library, issue.Kind, issue.Detail)
+	}
+	report := strings.Join(lines, "\n")
+	if force {
+		// stderr keeps the warning out of the commands eval'd with -y
+		if !out.IsJSON() {
+			fmt.Fprintln(os.Stderr, tui.WarningMessage("Activating despite GPU client library compatibility issues (--force):\n"+report))
+		}
+		return nil
+	}
+	return fmt.Errorf("GPU client libraries are not compatible with this machine:\n%s\nupdate the host (or remove the conflicting driver libraries) and retry, or pass --force to activate anyway", report)
 }
 
 // ensureGPUBinary downloads GPU binary tools (like nvidia-smi) if available for the vendor
 func ensureGPUBinary(ctx context.Context, out *tui.Output, vendorSlug string, silent bool) error {
-	binPath, err := deps.EnsureGPUBinary(ctx, paths, vendorSlug)
+	bundle, err := deps.EnsureGPUBinary(ctx, paths, vendorSlug)
 	if err != nil {
 		return err
 	}
-	if binPath == "" {
+	if bundle == nil {
 		klog.V(2).Infof("No GPU binary available for vendor %s", vendorSlug)
 		return nil
 	}
 
 	if !silent && !out.IsJSON() {
-		binaryName := deps.GetGPUBinaryName(vendorSlug)
-		out.Printf("GPU binary %s is available at %s\n", binaryName, binPath)
+		out.Printf("GPU tools %s are available in %s\n", strings.Join(bundle.Tools, ", "), bundle.Dir)
 	}
 
 	return nil
 }
 
-// getGPUBinDir returns the directory containing GPU binaries
-func getGPUBinDir() string {
-	return filepath.Join(paths.CacheDir(), "bin")
+// getGPUBinDir returns the directory containing the vendor's GPU tools
+func getGPUBinDir(config *studio.GPUEnvConfig) string {
+	if config.BinPath != "" {
+		return config.BinPath
+	}
+	return deps.GPUBinDir(paths, string(config.Vendor), runtime.GOOS, runtime.GOARCH)
 }
 
 // NewCleanCmd creates the clean command
@@ -223,9 +747,12 @@ func NewCleanCmd() *cobra.Command {
 
 	var all bool
 	var yes bool
+	var ci bool
+	var dryRun bool
+	var verbose bool
 
 	cmd := &cobra.Command{
-		Use:   "clean [short-link]",
+		Use:   "clean [short-link|name]",
 		Short: "Clean up remote GPU environment",
 		Long: `Clean up temporary or long-term remote GPU environment setup.
 
@@ -233,23 +760,61 @@ Examples:
   # Clean up current shell environment (if activated with ggo use)
   ggo clean
 
-  # Clean up a specific connection (using code or link)
+  # Clean up a specific connection (using code, link or --name given to ggo use)
   ggo clean abc123
   ggo clean https://gpu.tf/s/abc123
+  ggo clean training
 
   # Clean up all GPU Go connections
-  ggo clean --all`,
+  ggo clean --all
+
+  # Show what would be removed without changing anything
+  ggo clean --all --dry-run
+  ggo clean abc123 --dry-run -o json
+
+  # Tear down what 'ggo use --ci' set up, in a CI post step
+  ggo clean --ci
+
+Files, directories and shell profile lines are tracked per share code when
+'ggo use' creates them, so only that connection's artifacts are removed.
+--dry-run lists every file, directory, profile line, registry variable and
+PATH entry that would be removed; --verbose prints each one as it is removed.
+Either way a summary of the changes per kind is shown.
+
+With -y and a connection (or --all), the artifacts are removed and the
+commands that deactivate the shell are printed when the shell was activated
+for that connection, e.g. eval "$(ggo clean -y abc123)". In CMD the ggo
+macro defined by 'ggo use' does this for 'ggo clean <code>'.`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out := getOutput()
 
+			if ci {
+				return reportCIFailure(cmd, ci, cleanCIEnv(args, cmdutil.NewOutput("json")))
+			}
+
 			// If -y flag, output shell commands to restore environment (for eval)
 			if yes {
+				if all || len(args) > 0 {
+					return cleanEnvEvalReleased(args, all, out)
+				}
 				return cleanEnvEval(out)
 			}
 
+			if dryRun {
+				if !all && len(args) == 0 {
+					return fmt.Errorf("--dry-run needs a connection or --all")
+				}
+				shortCode := ""
+				if len(args) > 0 {
+					shortCode = cmdutil.ExtractShortCode(args[0])
+				}
+				cmd.SilenceUsage = true
+				return planClean(shortCode, all, out)
+			}
+
 			if all {
-				return cleanAllEnv(out)
+				return cleanAllEnv(verbose, out)
 			}
 
 			if len(args) == 0 {
@@ -257,50 +822,89 @@ Examples:
 				return cleanCurrentEnv(out)
 			}
 
-			shortCode := extractShortCode(args[0])
-			return cleanEnv(shortCode, out)
+			shortCode := cmdutil.ExtractShortCode(args[0])
+			if err := cleanEnv(shortCode, verbose, out); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&all, "all", false, "Clean up all GPU Go connections")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Deactivate environment non-interactively (use with eval: eval \"$(ggo clean -y)\")")
+	cmd.Flags().BoolVar(&ci, "ci", false, "Clean up connections made by 'ggo use --ci' without prompting, with JSON output")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be removed without changing anything")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Print each file, profile line and variable as it is removed")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "ci")
 
 	return cmd
 }
 
 // setupTemporaryEnv sets up a temporary GPU environment
 // When yes=true, outputs shell commands for eval (user runs: eval "$(ggo use xxx -y)")
-func setupTemporaryEnv(shareInfo *api.SharePublicInfo, yes bool, out *tui.Output) error {
+// mode says what happens to a GPU environment the shell already has
+func setupTemporaryEnv(shareInfo *api.SharePublicInfo, rec *studio.UseConnection, yes bool, mode activationMode, out *tui.Output) error {
 	klog.Info("Setting up temporary GPU environment...")
 
-	vendor := studio.ParseVendor(shareInfo.HardwareVendor)
-	studioName := "current-os"
-
-	// Create GPU environment config
-	config := &studio.GPUEnvConfig{
-		Vendor:        vendor,
-		ConnectionURL: shareInfo.ConnectionURL,
-		CachePath:     paths.CacheDir(),
-		LogPath:       paths.StudioLogsDir(studioName),
-		StudioName:    studioName,
-		IsContainer:   false,
+	// Build on the shell's environment without the current activation,
+	// saving it first when the new one is stacked on it
+	var (
+		tk   takeover
+		snap *envSnapshot
+	)
+	if mode != activateNew {
+		tk.unwind = yes
+		if yes && mode == activateStack {
+			snap = snapshotActivation()
+		}
+		unwindActivation()
 	}
 
+	config := temporaryEnvConfig(shareInfo, rec)
+
 	// Setup GPU environment (creates config files and directories)
 	envResult, err := studio.SetupGPUEnv(paths, config)
 	if err != nil {
 		return fmt.Errorf("failed to setup GPU environment: %w", err)
 	}
+	rec.AddDirs(paths.StudioConfigDir(config.StudioName))
+
+	if snap != nil {
+		restoreFile, err := writeRestoreScript(paths.StudioConfigDir(config.StudioName), snap)
+		if err != nil {
+			return err
+		}
+		rec.AddFiles(restoreFile)
+		tk.restoreFile = restoreFile
+		klog.Infof("Stacking GPU environment: previous=%s restore_file=%s", snap.label(), restoreFile)
+	}
 
 	if platform.IsWindows() {
-		return renderWindowsEnv(shareInfo, config, envResult, yes, out)
+		return renderWindowsEnv(shareInfo, rec, config, envResult, yes, tk, out)
+	}
+	return renderUnixEnv(shareInfo, rec, config, envResult, yes, tk, out)
+}
+
+// temporaryEnvConfig returns the GPU environment config of a temporary connection
+func temporaryEnvConfig(shareInfo *api.SharePublicInfo, rec *studio.UseConnection) *studio.GPUEnvConfig {
+	studioName := useStudioName(rec)
+	return &studio.GPUEnvConfig{
+		Vendor:         studio.ParseVendor(shareInfo.HardwareVendor),
+		ConnectionURL:  shareInfo.ConnectionURL,
+		CachePath:      paths.CacheDir(),
+		BinPath:        deps.GPUBinDir(paths, shareInfo.HardwareVendor, runtime.GOOS, runtime.GOARCH),
+		LogPath:        paths.StudioLogsDir(studioName),
+		StudioName:     studioName,
+		IsContainer:    false,
+		ConnectionName: rec.ID(),
 	}
-	return renderUnixEnv(shareInfo, config, envResult, yes, out)
 }
 
 // renderUnixEnv renders and optionally activates the Unix environment
 // When yes=true, outputs shell commands for eval (designed to be run via: eval "$(ggo use xxx -y)")
-func renderUnixEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, yes bool, out *tui.Output) error {
+func renderUnixEnv(shareInfo *api.SharePublicInfo, rec *studio.UseConnection, config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, yes bool, tk takeover, out *tui.Output) error {
 	// Generate environment script
 	envScript, err := studio.GenerateEnvScript(config, paths)
 	if err != nil {
@@ -319,10 +923,12 @@ func renderUnixEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfig,
 	if err := os.WriteFile(cleanFile, []byte(cleanScript), 0755); err != nil {
 		klog.Warningf("Failed to write clean script: %v", err)
 	}
+	rec.AddFiles(envFile, cleanFile)
+	recordUseConnection(rec)
 
 	// If -y flag, output shell commands for eval
 	if yes {
-		return outputEvalCommands(config, envResult, envFile, cleanFile, out)
+		return outputEvalCommands(config, envResult, envFile, cleanFile, tk, out)
 	}
 
 	styles := tui.DefaultStyles()
@@ -339,7 +945,7 @@ func renderUnixEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfig,
 
 	// Prompt user
 	if !out.IsJSON() {
-		out.Println(styles.Subtitle.Render("Activate Environment"))
+		out.Println(styles.Subtitle.Render(i18n.T("Activate Environment")))
 		out.Println()
 		out.Printf("Would you like to activate the GPU environment in a new shell? [Y/n]: ")
 
@@ -349,7 +955,7 @@ func renderUnixEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfig,
 
 		if shouldActivate {
 			out.Println()
-			out.Println(styles.Info.Render("Launching new shell with GPU environment..."))
+			out.Println(styles.Info.Render(i18n.T("Launching new shell with GPU environment...")))
 			out.Println()
 
 			// Launch a new interactive shell with the environment set
@@ -364,11 +970,11 @@ func renderUnixEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfig,
 
 			// After shell exits, show message
 			out.Println()
-			out.Println(styles.Muted.Render("GPU shell session ended. Environment deactivated."))
+			out.Println(styles.Muted.Render(i18n.T("GPU shell session ended. Environment deactivated.")))
 			out.Println()
 		} else {
 			out.Println()
-			out.Println(styles.Subtitle.Render("Manual Activation"))
+			out.Println(styles.Subtitle.Render(i18n.T("Manual Activation")))
 			out.Println()
 			out.Println("You can activate later by running:")
 			out.Printf("\n   source %s\n\n", envFile)
@@ -393,7 +999,7 @@ func launchGPUShell(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult,
 	}
 
 	// BinDir is for GPU binaries like nvidia-smi
-	binDir := getGPUBinDir()
+	binDir := getGPUBinDir(config)
 
 	// Create the command
 	cmd := exec.Command(shell, "-i")
@@ -448,8 +1054,8 @@ func launchGPUShell(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult,
 	cmd.Env = env
 
 	// Print GPU environment banner
-	fmt.Printf("\n%s GPU environment activated %s\n", styles().Success.Render("✓"), styles().Muted.Render("(type 'exit' to deactivate)"))
-	fmt.Printf("%s\n\n", styles().Muted.Render("Ctrl+C interrupts the current command, not the GPU environment."))
+	fmt.Printf(i18n.T("\n%s GPU environment activated %s\n"), styles().Success.Render("✓"), styles().Muted.Render(i18n.T("(type 'exit' to deactivate)")))
+	fmt.Printf("%s\n\n", styles().Muted.Render(i18n.T("Ctrl+C interrupts the current command, not the GPU environment.")))
 
 	// Ignore SIGINT in parent process - let the child shell handle Ctrl+C naturally.
 	// Without this, Ctrl+C kills both the parent ggo process and the child shell,
@@ -477,15 +1083,23 @@ func styles() *tui.Styles {
 }
 
 // outputEvalCommands outputs shell commands for eval mode
-func outputEvalCommands(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile string, out *tui.Output) error {
+func outputEvalCommands(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile string, tk takeover, out *tui.Output) error {
 	if platform.IsWindows() {
-		return outputEvalCommandsWindows(config, envResult, envFile, cleanFile, out)
+		return outputEvalCommandsWindows(config, envResult, envFile, cleanFile, tk, out)
 	}
-	return outputEvalCommandsUnix(config, envResult, envFile, cleanFile, out)
+	return outputEvalCommandsUnix(config, envResult, envFile, cleanFile, tk, out)
 }
 
 // outputEvalCommandsUnix outputs shell commands for eval mode (Unix/Linux)
-func outputEvalCommandsUnix(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile string, out *tui.Output) error {
+func outputEvalCommandsUnix(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile string, tk takeover, out *tui.Output) error {
+	// Output to stdout for eval
+	fmt.Print(unixActivationScript(config, envResult, cleanFile, tk))
+	return nil
+}
+
+// unixActivationScript returns the shell commands that activate the
+// environment in the calling shell
+func unixActivationScript(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, cleanFile string, tk takeover) string {
 	// LibsPath is for .so files (used for LD_LIBRARY_PATH, LD_PRELOAD)
 	libsPath := config.LibsPath
 	if libsPath == "" {
@@ -493,16 +1107,26 @@ func outputEvalCommandsUnix(config *studio.GPUEnvConfig, envResult *studio.GPUEn
 	}
 
 	// BinDir is for GPU binaries like nvidia-smi
-	binDir := getGPUBinDir()
+	binDir := getGPUBinDir(config)
 
 	var script strings.Builder
 
+	// Take the shell's current environment off first
+	if tk.unwind {
+		script.WriteString("# Deactivate the GPU Go environment the shell already has\n")
+		script.WriteString(unixDeactivateCommands(""))
+		script.WriteString("\n")
+	}
+
 	// Save original values for later restoration
 	script.WriteString("# Save original environment for cleanup\n")
 	script.WriteString("export _GGO_ORIG_LD_LIBRARY_PATH=\"$LD_LIBRARY_PATH\"\n")
 	script.WriteString("export _GGO_ORIG_LD_PRELOAD=\"$LD_PRELOAD\"\n")
 	script.WriteString("export _GGO_ORIG_PATH=\"$PATH\"\n")
 	fmt.Fprintf(&script, "export _GGO_CLEAN_FILE=\"%s\"\n", cleanFile)
+	if tk.restoreFile != "" {
+		fmt.Fprintf(&script, "export %s=\"%s\"\n", restoreFileEnv, tk.restoreFile)
+	}
 	script.WriteString("\n")
 
 	// Export TensorFusion environment variables
@@ -550,15 +1174,12 @@ func outputEvalCommandsUnix(config *studio.GPUEnvConfig, envResult *studio.GPUEn
 	script.WriteString("echo \"\" >&2\n")
 	script.WriteString("echo \"To deactivate and restore your environment, run:\" >&2\n")
 	script.WriteString("echo '  ggo clean' >&2\n")
-
-	// Output to stdout for eval
-	fmt.Print(script.String())
-	return nil
+	return script.String()
 }
 
 // outputEvalCommandsWindows outputs shell commands for eval mode (Windows)
 // Detects PowerShell vs CMD and outputs appropriate commands
-func outputEvalCommandsWindows(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile string, out *tui.Output) error {
+func outputEvalCommandsWindows(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile string, tk takeover, out *tui.Output) error {
 	// LibsPath is for .dll files (used for PATH on Windows)
 	libsPath := config.LibsPath
 	if libsPath == "" {
@@ -570,9 +1191,9 @@ func outputEvalCommandsWindows(config *studio.GPUEnvConfig, envResult *studio.GP
 	shell := detectWindowsShell()
 
 	if shell == shellPowerShell {
-		return outputEvalCommandsPowerShell(config, envResult, envFile, cleanFile, libsPath, out)
+		return outputEvalCommandsPowerShell(config, envResult, envFile, cleanFile, libsPath, tk, out)
 	}
-	return outputEvalCommandsCMD(config, envResult, envFile, cleanFile, libsPath, out)
+	return outputEvalCommandsCMD(config, envResult, envFile, cleanFile, libsPath, tk, out)
 }
 
 // detectWindowsShell detects whether we're in PowerShell or CMD
@@ -612,16 +1233,31 @@ func escapeForPowerShell(s string) string {
 }
 
 // outputEvalCommandsPowerShell outputs PowerShell commands for eval mode
-func outputEvalCommandsPowerShell(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile, libsPath string, out *tui.Output) error {
+func outputEvalCommandsPowerShell(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile, libsPath string, tk takeover, out *tui.Output) error {
+	// Output to stdout for eval
+	fmt.Print(powerShellActivationScript(config, envResult, cleanFile, libsPath, tk))
+	return nil
+}
+
+// powerShellActivationScript returns the PowerShell commands that activate
+// the environment in the calling session
+func powerShellActivationScript(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, cleanFile, libsPath string, tk takeover) string {
 	// BinDir is for GPU binaries like nvidia-smi
-	binDir := getGPUBinDir()
+	binDir := getGPUBinDir(config)
 
 	var script strings.Builder
 
-	// Save original values for later restoration
-	script.WriteString("# Save original environment for cleanup\n")
-	script.WriteString("$env:_GGO_ORIG_PATH = $env:PATH\n")
+	// Take the session's current environment off first
+	if tk.unwind {
+		script.WriteString("# Deactivate the GPU Go environment the session already has\n")
+		script.WriteString(powerShellDeactivateCommands(""))
+		script.WriteString("\n")
+	}
+
 	fmt.Fprintf(&script, "$env:_GGO_CLEAN_FILE = \"%s\"\n", escapeForPowerShell(cleanFile))
+	if tk.restoreFile != "" {
+		fmt.Fprintf(&script, "$env:%s = \"%s\"\n", restoreFileEnv, escapeForPowerShell(tk.restoreFile))
+	}
 	script.WriteString("\n")
 
 	// Export TensorFusion environment variables
@@ -632,8 +1268,11 @@ func outputEvalCommandsPowerShell(config *studio.GPUEnvConfig, envResult *studio
 	// Set GPU vendor
 	fmt.Fprintf(&script, "$env:TF_GPU_VENDOR = \"%s\"\n", config.Vendor)
 
-	// Add libs path and bin path to PATH at the front
-	fmt.Fprintf(&script, "$env:PATH = \"%s;%s;\" + $env:PATH\n", escapeForPowerShell(binDir), escapeForPowerShell(libsPath))
+	// Add libs path and bin path to PATH at the front, recording the entries
+	// so that clean removes just them and keeps PATH changes made meanwhile
+	added := escapeForPowerShell(binDir + ";" + libsPath)
+	fmt.Fprintf(&script, "$env:PATH = \"%s;\" + $env:PATH\n", added)
+	fmt.Fprintf(&script, "$env:_GGO_PATH_ADDED = \"%s;\" + $env:_GGO_PATH_ADDED\n", added)
 
 	// Set CUDA_PATH - point to libs directory
 	fmt.Fprintf(&script, "$env:CUDA_PATH = \"%s\"\n", escapeForPowerShell(libsPath))
@@ -646,7 +1285,22 @@ func outputEvalCommandsPowerShell(config *studio.GPUEnvConfig, envResult *studio
 	script.WriteString("\n")
 
 	// Define ggo wrapper function for automatic clean handling
-	// Note: We use $MyArgs instead of $args since $args is an automatic variable
+	script.WriteString(powerShellWrapperFunction())
+	script.WriteString("\n")
+
+	// Print activation message to stderr using [Console]::Error
+	script.WriteString("[Console]::Error.WriteLine(\"GPU Go environment activated for vendor: " + string(config.Vendor) + "\")\n")
+	script.WriteString("[Console]::Error.WriteLine(\"Connection URL: " + escapeForPowerShell(config.ConnectionURL) + "\")\n")
+	script.WriteString("[Console]::Error.WriteLine(\"\")\n")
+	script.WriteString("[Console]::Error.WriteLine(\"To deactivate and restore your environment, run:\")\n")
+	script.WriteString("[Console]::Error.WriteLine(\"  ggo clean\")\n")
+	return script.String()
+}
+
+// powerShellWrapperFunction returns the PowerShell commands that define the
+// ggo wrapper, which deactivates the session on a bare `ggo clean`
+func powerShellWrapperFunction() string {
+	var script strings.Builder
 	script.WriteString("# Define ggo wrapper function for automatic clean handling\n")
 	script.WriteString("$Global:_ggo_real = (Get-Command ggo -CommandType Application -ErrorAction SilentlyContinue | Select-Object -First 1).Source\n")
 	script.WriteString("if (-not $Global:_ggo_real) { $Global:_ggo_real = \"ggo\" }\n")
@@ -657,39 +1311,137 @@ func outputEvalCommandsPowerShell(config *studio.GPUEnvConfig, envResult *studio
 	script.WriteString("    & $Global:_ggo_real @args\n")
 	script.WriteString("  }\n")
 	script.WriteString("}\n")
+	return script.String()
+}
+
+// outputEvalCommandsCMD activates the environment in CMD. CMD has no eval, but
+// `for /f "delims=" %i in ('ggo use <code> -y') do @%i` runs each stdout line
+// in the current session, so the commands go into a uniquely named temporary
+// .cmd file and only a single `call` line is printed. The file mutates the
+// session environment directly (no setlocal) and defines a doskey ggo macro
+// so that a later `ggo clean` deactivates in place.
+func outputEvalCommandsCMD(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile, libsPath string, tk takeover, out *tui.Output) error {
+	cleanBat := filepath.Join(filepath.Dir(cleanFile), "clean.bat")
+	callFile, err := writeCMDEvalScript("ggo-use-*.cmd", cmdActivationScript(config, envResult, cleanBat, libsPath, tk))
+	if err != nil {
+		// Fall back to the persistent batch file, which sets the same variables
+		klog.Warningf("Failed to write CMD activation script: error=%v", err)
+		fmt.Printf("call \"%s\"\n", envFile)
+		return nil
+	}
+	fmt.Printf("call \"%s\"\n", callFile)
+	return nil
+}
+
+// cmdActivationScript returns the batch commands that activate the
+// environment in the calling CMD session
+func cmdActivationScript(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, cleanBat, libsPath string, tk takeover) string {
+	binDir := getGPUBinDir(config)
+
+	var script strings.Builder
+	script.WriteString("@echo off\n")
+	script.WriteString("REM GPU Go environment activation (generated by ggo use, deletes itself)\n\n")
+
+	// Take the session's current environment off first
+	if tk.unwind {
+		script.WriteString(cmdDeactivateCommands())
+		script.WriteString("\n")
+	}
+
+	fmt.Fprintf(&script, "set \"_GGO_CLEAN_FILE=%s\"\n", escapeForCMD(cleanBat))
+	if tk.restoreFile != "" {
+		fmt.Fprintf(&script, "set \"%s=%s\"\n", restoreFileEnv, escapeForCMD(tk.restoreFile))
+	}
 	script.WriteString("\n")
 
-	// Print activation message to stderr using [Console]::Error
-	script.WriteString("[Console]::Error.WriteLine(\"GPU Go environment activated for vendor: " + string(config.Vendor) + "\")\n")
-	script.WriteString("[Console]::Error.WriteLine(\"Connection URL: " + escapeForPowerShell(config.ConnectionURL) + "\")\n")
-	script.WriteString("[Console]::Error.WriteLine(\"\")\n")
-	script.WriteString("[Console]::Error.WriteLine(\"To deactivate and restore your environment, run:\")\n")
-	script.WriteString("[Console]::Error.WriteLine(\"  ggo clean\")\n")
+	// Export TensorFusion environment variables
+	keys := make([]string, 0, len(envResult.EnvVars))
+	for k := range envResult.EnvVars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&script, "set \"%s=%s\"\n", k, escapeForCMD(envResult.EnvVars[k]))
+	}
+	fmt.Fprintf(&script, "set \"TF_GPU_VENDOR=%s\"\n", config.Vendor)
 
-	// Output to stdout for eval
-	fmt.Print(script.String())
-	return nil
+	// Add libs path and bin path to PATH at the front, recording the entries
+	// so that clean removes just them and keeps PATH changes made meanwhile
+	added := escapeForCMD(binDir + ";" + libsPath)
+	fmt.Fprintf(&script, "set \"PATH=%s;%%PATH%%\"\n", added)
+	fmt.Fprintf(&script, "set \"_GGO_PATH_ADDED=%s;%%_GGO_PATH_ADDED%%\"\n", added)
+	fmt.Fprintf(&script, "set \"CUDA_PATH=%s\"\n", escapeForCMD(libsPath))
+	fmt.Fprintf(&script, "set \"CUDA_HOME=%s\"\n\n", escapeForCMD(libsPath))
+
+	// Mark as activated
+	script.WriteString("set \"_GGO_ACTIVE=1\"\n")
+	fmt.Fprintf(&script, "set \"_GGO_LIBS_PATH=%s\"\n", escapeForCMD(libsPath))
+	fmt.Fprintf(&script, "set \"_GGO_BIN_PATH=%s\"\n\n", escapeForCMD(binDir))
+
+	// Define ggo wrapper macro for automatic clean handling
+	script.WriteString("REM Define ggo wrapper macro for automatic clean handling\n")
+	script.WriteString(cmdWrapperMacro(cleanBat) + "\n\n")
+
+	// Print activation message to stderr so it doesn't interfere with for /f
+	fmt.Fprintf(&script, "echo GPU Go environment activated for vendor: %s 1>&2\n", config.Vendor)
+	fmt.Fprintf(&script, "echo Connection URL: %s 1>&2\n", escapeForCMDEcho(config.ConnectionURL))
+	script.WriteString("echo. 1>&2\n")
+	script.WriteString("echo To deactivate and restore your environment, run: 1>&2\n")
+	script.WriteString("echo   ggo clean 1>&2\n")
+	return script.String()
 }
 
-// outputEvalCommandsCMD outputs CMD batch commands for eval mode
-// Note: CMD doesn't support true eval like bash/PowerShell. Instead, we output
-// a message guiding users to use the generated batch file directly.
-func outputEvalCommandsCMD(config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, envFile, cleanFile, libsPath string, out *tui.Output) error {
-	// CMD doesn't support eval-style command execution like bash or PowerShell
-	// The best we can do is tell users to call the batch file directly
-	// Output message to stderr
-	fmt.Fprintf(os.Stderr, "CMD does not support eval-style activation.\n")
-	fmt.Fprintf(os.Stderr, "Please run the following command to activate GPU environment:\n")
-	fmt.Fprintf(os.Stderr, "\n")
-	fmt.Fprintf(os.Stderr, "  call \"%s\"\n", envFile)
-	fmt.Fprintf(os.Stderr, "\n")
-	fmt.Fprintf(os.Stderr, "Or use PowerShell for better experience:\n")
-	fmt.Fprintf(os.Stderr, "  powershell -Command \"ggo use %s -y | Out-String | Invoke-Expression\"\n", extractShortCode(config.ConnectionURL))
-	fmt.Fprintf(os.Stderr, "\n")
-
-	// Output the call command to stdout so user can copy-paste
-	fmt.Printf("call \"%s\"\n", envFile)
-	return nil
+// cmdWrapperMacro returns the doskey command that defines the ggo wrapper. A
+// bare `ggo clean` calls the clean script. `ggo clean <args>` runs
+// `ggo clean -y <args>` through for /f, so the session is deactivated too
+// when its own environment is cleaned. Everything else runs ggo itself.
+func cmdWrapperMacro(cleanBat string) string {
+	ggoReal, err := os.Executable()
+	if err != nil {
+		ggoReal = "ggo.exe"
+	}
+	ggoReal = escapeForCMD(ggoReal)
+	// "call" keeps CMD from stripping the quotes around the executable
+	return fmt.Sprintf("doskey ggo=if /i \"$*\"==\"clean\" (call \"%s\") "+
+		"else if /i \"$1\"==\"clean\" (for /f \"delims=\" %%i in ('call \"%s\" clean -y $2 $3 $4') do @%%i) "+
+		"else (\"%s\" $*)",
+		escapeForCMD(cleanBat), ggoReal, ggoReal)
+}
+
+// writeCMDEvalScript writes a uniquely named temporary .cmd that deletes
+// itself once called and returns its path
+func writeCMDEvalScript(pattern, content string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	// "(goto) 2>nul & del" returns from the script before deleting it, so CMD
+	// doesn't try to read the rest of a file that no longer exists
+	content += "(goto) 2>nul & del \"%~f0\"\n"
+	if _, err := f.WriteString(strings.ReplaceAll(content, "\n", "\r\n")); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// escapeForCMD escapes a value for a quoted set "K=V" line in a batch file
+func escapeForCMD(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// escapeForCMDEcho escapes a value for an unquoted echo in a batch file
+func escapeForCMDEcho(s string) string {
+	s = escapeForCMD(s)
+	for _, c := range []string{"^", "&", "|", "<", ">"} {
+		s = strings.ReplaceAll(s, c, "^"+c)
+	}
+	return s
 }
 
 // generateCleanScript generates a shell script to clean up the GPU environment
@@ -707,6 +1459,13 @@ func generateCleanScriptUnix() string {
 	script.WriteString("# GPU Go environment cleanup script\n")
 	script.WriteString("# Generated by ggo use\n\n")
 
+	// Return to the environment a stacked activation was put on
+	script.WriteString("# Return to the previous environment of a stacked activation\n")
+	script.WriteString("if [ -n \"$" + restoreFileEnv + "\" ] && [ -f \"$" + restoreFileEnv + "\" ]; then\n")
+	script.WriteString("  . \"$" + restoreFileEnv + "\"\n")
+	script.WriteString("  return 0 2>/dev/null || exit 0\n")
+	script.WriteString("fi\n\n")
+
 	// Restore original LD_LIBRARY_PATH (remove only TF entries)
 	script.WriteString("# Restore LD_LIBRARY_PATH\n")
 	script.WriteString("if [ -n \"$_GGO_ORIG_LD_LIBRARY_PATH\" ]; then\n")
@@ -744,7 +1503,9 @@ func generateCleanScriptUnix() string {
 	script.WriteString("unset _GGO_ORIG_PATH\n")
 	script.WriteString("unset _GGO_ACTIVE\n")
 	script.WriteString("unset _GGO_LIBS_PATH\n")
-	script.WriteString("unset _GGO_CLEAN_FILE\n\n")
+	script.WriteString("unset _GGO_CLEAN_FILE\n")
+	script.WriteString("unset " + restoreFileEnv + "\n")
+	script.WriteString("unset " + studio.ConnectionEnv + "\n\n")
 
 	// Remove ggo wrapper function
 	script.WriteString("# Remove ggo wrapper function\n")
@@ -769,12 +1530,20 @@ func generateCleanScriptWindows() string {
 	script.WriteString("  return\n")
 	script.WriteString("}\n\n")
 
-	// Restore original PATH
-	script.WriteString("# Restore PATH\n")
-	script.WriteString("if ($env:_GGO_ORIG_PATH) {\n")
-	script.WriteString("  $env:PATH = $env:_GGO_ORIG_PATH\n")
+	// Return to the environment a stacked activation was put on
+	script.WriteString("# Return to the previous environment of a stacked activation\n")
+	script.WriteString("if ($env:" + restoreFileEnv + " -and (Test-Path $env:" + restoreFileEnv + ")) {\n")
+	script.WriteString("  . $env:" + restoreFileEnv + "\n")
+	script.WriteString("  return\n")
 	script.WriteString("}\n\n")
 
+	// Remove the PATH entries added on activation
+	script.WriteString("# Remove the entries GPU Go added to PATH\n")
+	script.WriteString(powerShellPathCleanup(""))
+	script.WriteString("\n")
+	script.WriteString(powerShellWSLENVCleanup(""))
+	script.WriteString("\n")
+
 	// Unset TensorFusion environment variables
 	script.WriteString("# Unset TensorFusion environment variables\n")
 	script.WriteString("Remove-Item Env:TENSOR_FUSION_OPERATOR_CONNECTION_INFO -ErrorAction SilentlyContinue\n")
@@ -787,10 +1556,14 @@ func generateCleanScriptWindows() string {
 
 	// Unset internal tracking variables
 	script.WriteString("# Unset internal tracking variables\n")
+	script.WriteString("Remove-Item Env:_GGO_PATH_ADDED -ErrorAction SilentlyContinue\n")
 	script.WriteString("Remove-Item Env:_GGO_ORIG_PATH -ErrorAction SilentlyContinue\n")
 	script.WriteString("Remove-Item Env:_GGO_ACTIVE -ErrorAction SilentlyContinue\n")
 	script.WriteString("Remove-Item Env:_GGO_LIBS_PATH -ErrorAction SilentlyContinue\n")
-	script.WriteString("Remove-Item Env:_GGO_CLEAN_FILE -ErrorAction SilentlyContinue\n\n")
+	script.WriteString("Remove-Item Env:_GGO_BIN_PATH -ErrorAction SilentlyContinue\n")
+	script.WriteString("Remove-Item Env:_GGO_CLEAN_FILE -ErrorAction SilentlyContinue\n")
+	script.WriteString("Remove-Item Env:" + restoreFileEnv + " -ErrorAction SilentlyContinue\n")
+	script.WriteString("Remove-Item Env:" + studio.ConnectionEnv + " -ErrorAction SilentlyContinue\n\n")
 
 	// Remove ggo wrapper function (Global scope)
 	script.WriteString("# Remove ggo wrapper function\n")
@@ -802,9 +1575,30 @@ func generateCleanScriptWindows() string {
 	return script.String()
 }
 
+// powerShellPathCleanup returns PowerShell commands that remove the entries
+// recorded in _GGO_PATH_ADDED from PATH, leaving any other change made to PATH
+// during the session in place. Sessions activated by an older ggo only saved
+// the whole PATH, which is restored instead.
+func powerShellPathCleanup(indent string) string {
+	lines := []string{
+		"if ($env:_GGO_PATH_ADDED) {",
+		"  $ggoAdded = $env:_GGO_PATH_ADDED -split ';' | Where-Object { $_ }",
+		"  $env:PATH = ($env:PATH -split ';' | Where-Object { -not $_ -or $ggoAdded -notcontains $_ }) -join ';'",
+		"  Remove-Variable ggoAdded -ErrorAction SilentlyContinue",
+		"} elseif ($env:_GGO_ORIG_PATH) {",
+		"  $env:PATH = $env:_GGO_ORIG_PATH",
+		"}",
+	}
+	var script strings.Builder
+	for _, line := range lines {
+		script.WriteString(indent + line + "\n")
+	}
+	return script.String()
+}
+
 // renderWindowsEnv renders and optionally activates the Windows environment
 // When yes=true, outputs shell commands for eval (designed to be run via: eval "$(ggo use xxx -y)" in PowerShell or CMD)
-func renderWindowsEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, yes bool, out *tui.Output) error {
+func renderWindowsEnv(shareInfo *api.SharePublicInfo, rec *studio.UseConnection, config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, yes bool, tk takeover, out *tui.Output) error {
 	// Generate PowerShell script
 	psScript, err := studio.GeneratePowerShellScript(config, paths)
 	if err != nil {
@@ -840,10 +1634,13 @@ func renderWindowsEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfi
 	if err := os.WriteFile(cleanBatFile, []byte(cleanBatScript), 0644); err != nil {
 		klog.Warningf("Failed to write CMD clean script: %v", err)
 	}
+	rec.AddFiles(psFile, batFile, cleanPSFile, cleanBatFile)
+	rec.AddPathEntries(windowsPathEntries(config)...)
+	recordUseConnection(rec)
 
 	// If -y flag, output shell commands for eval
 	if yes {
-		return outputEvalCommands(config, envResult, psFile, cleanPSFile, out)
+		return outputEvalCommands(config, envResult, psFile, cleanPSFile, tk, out)
 	}
 
 	styles := tui.DefaultStyles()
@@ -860,7 +1657,7 @@ func renderWindowsEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfi
 
 	// Prompt user
 	if !out.IsJSON() {
-		out.Println(styles.Subtitle.Render("Activate Environment"))
+		out.Println(styles.Subtitle.Render(i18n.T("Activate Environment")))
 		out.Println()
 		out.Printf("Would you like to activate the GPU environment in a new shell? [Y/n]: ")
 
@@ -870,7 +1667,7 @@ func renderWindowsEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfi
 
 		if shouldActivate {
 			out.Println()
-			out.Println(styles.Info.Render("Launching new shell with GPU environment..."))
+			out.Println(styles.Info.Render(i18n.T("Launching new shell with GPU environment...")))
 			out.Println()
 
 			// Launch a new interactive shell with the environment set
@@ -890,11 +1687,11 @@ func renderWindowsEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfi
 
 			// After shell exits, show message
 			out.Println()
-			out.Println(styles.Muted.Render("GPU shell session ended. Environment deactivated."))
+			out.Println(styles.Muted.Render(i18n.T("GPU shell session ended. Environment deactivated.")))
 			out.Println()
 		} else {
 			out.Println()
-			out.Println(styles.Subtitle.Render("Manual Activation"))
+			out.Println(styles.Subtitle.Render(i18n.T("Manual Activation")))
 			out.Println()
 			out.Println("You can activate later by running:")
 			shell := detectWindowsShell()
@@ -904,8 +1701,8 @@ func renderWindowsEnv(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfi
 				out.Printf("\n   %s\n\n", batFile)
 			}
 			out.Println("Or use eval mode (recommended):")
-			out.Println("\n   PowerShell: ggo use " + extractShortCode(shareInfo.WorkerID) + " -y | Out-String | Invoke-Expression")
-			out.Println("   CMD:        for /f \"delims=\" %i in ('ggo use " + extractShortCode(shareInfo.WorkerID) + " -y') do @%i")
+			out.Println("\n   PowerShell: ggo use " + cmdutil.ExtractShortCode(shareInfo.WorkerID) + " -y | Out-String | Invoke-Expression")
+			out.Println("   CMD:        for /f \"delims=\" %i in ('ggo use " + cmdutil.ExtractShortCode(shareInfo.WorkerID) + " -y') do @%i")
 			out.Println()
 		}
 	}
@@ -927,12 +1724,28 @@ func generateCleanScriptCMD() string {
 	script.WriteString("  goto :eof\n")
 	script.WriteString(")\n\n")
 
-	// Restore original PATH
-	script.WriteString("REM Restore PATH\n")
-	script.WriteString("if defined _GGO_ORIG_PATH (\n")
-	script.WriteString("  set \"PATH=%_GGO_ORIG_PATH%\"\n")
+	// Return to the environment a stacked activation was put on
+	script.WriteString("REM Return to the previous environment of a stacked activation\n")
+	script.WriteString("if defined " + restoreFileEnv + " if exist \"%" + restoreFileEnv + "%\" (\n")
+	script.WriteString("  call \"%" + restoreFileEnv + "%\"\n")
+	script.WriteString("  goto :eof\n")
 	script.WriteString(")\n\n")
 
+	script.WriteString(cmdDeactivateCommands())
+	script.WriteString("\n")
+	script.WriteString("echo GPU Go environment deactivated 1>&2\n")
+
+	return script.String()
+}
+
+// cmdDeactivateCommands returns the batch commands that deactivate the
+// environment in the calling CMD session, without checking it is active
+func cmdDeactivateCommands() string {
+	var script strings.Builder
+	script.WriteString(cmdPathCleanup())
+	script.WriteString(cmdWSLENVCleanup())
+	script.WriteString("\n")
+
 	// Unset TensorFusion environment variables
 	script.WriteString("REM Unset TensorFusion environment variables\n")
 	script.WriteString("set \"TENSOR_FUSION_OPERATOR_CONNECTION_INFO=\"\n")
@@ -945,13 +1758,34 @@ func generateCleanScriptCMD() string {
 
 	// Unset internal tracking variables
 	script.WriteString("REM Unset internal tracking variables\n")
+	script.WriteString("set \"_GGO_PATH_ADDED=\"\n")
 	script.WriteString("set \"_GGO_ORIG_PATH=\"\n")
 	script.WriteString("set \"_GGO_ACTIVE=\"\n")
 	script.WriteString("set \"_GGO_LIBS_PATH=\"\n")
-	script.WriteString("set \"_GGO_CLEAN_FILE=\"\n\n")
-
-	script.WriteString("echo GPU Go environment deactivated\n")
+	script.WriteString("set \"_GGO_BIN_PATH=\"\n")
+	script.WriteString("set \"_GGO_CLEAN_FILE=\"\n")
+	script.WriteString("set \"" + restoreFileEnv + "=\"\n")
+	script.WriteString("set \"" + studio.ConnectionEnv + "=\"\n\n")
+
+	// Remove the ggo wrapper macro defined by eval activation
+	script.WriteString("REM Remove ggo wrapper macro\n")
+	script.WriteString("doskey ggo=\n")
+	return script.String()
+}
 
+// cmdPathCleanup returns the batch commands that remove the entries recorded
+// in _GGO_PATH_ADDED from PATH. Activation prepends them followed by ";", so
+// removing each "entry;" keeps the rest of PATH, including changes made
+// during the session. Sessions activated by an older ggo only saved the
+// whole PATH, which is restored instead.
+func cmdPathCleanup() string {
+	var script strings.Builder
+	script.WriteString("REM Remove the entries GPU Go added to PATH\n")
+	script.WriteString("if defined _GGO_PATH_ADDED (\n")
+	script.WriteString("  for %%e in (\"%_GGO_PATH_ADDED:;=\" \"%\") do if not \"%%~e\"==\"\" call set \"PATH=%%PATH:%%~e;=%%\"\n")
+	script.WriteString(") else if defined _GGO_ORIG_PATH (\n")
+	script.WriteString("  set \"PATH=%_GGO_ORIG_PATH%\"\n")
+	script.WriteString(")\n\n")
 	return script.String()
 }
 
@@ -1007,14 +1841,12 @@ func launchGPUShellWindows(config *studio.GPUEnvConfig, envResult *studio.GPUEnv
 		env = append(env, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	// Add libs path and GPU bin directory to PATH
-	binDir := getGPUBinDir()
-	existingPath := os.Getenv("PATH")
-	if existingPath != "" {
-		env = append(env, fmt.Sprintf("PATH=%s;%s;%s", binDir, libsPath, existingPath))
-	} else {
-		env = append(env, fmt.Sprintf("PATH=%s;%s", binDir, libsPath))
-	}
+	// Add GPU bin directory and libs path to PATH, recording the entries for
+	// clean. Each is followed by ";", which the CMD clean script relies on.
+	binDir := getGPUBinDir(config)
+	added := strings.Join(windowsPathEntries(config), ";")
+	env = append(env, fmt.Sprintf("PATH=%s;%s", added, os.Getenv("PATH")))
+	env = append(env, fmt.Sprintf("_GGO_PATH_ADDED=%s;%s", added, os.Getenv("_GGO_PATH_ADDED")))
 
 	// Set CUDA_PATH - point to libs directory
 	env = append(env, fmt.Sprintf("CUDA_PATH=%s", libsPath))
@@ -1032,8 +1864,8 @@ func launchGPUShellWindows(config *studio.GPUEnvConfig, envResult *studio.GPUEnv
 
 	// Print GPU environment banner
 	styles := tui.DefaultStyles()
-	fmt.Printf("\n%s GPU environment activated %s\n", styles.Success.Render("✓"), styles.Muted.Render("(type 'exit' to deactivate)"))
-	fmt.Printf("%s\n\n", styles.Muted.Render("Ctrl+C interrupts the current command, not the GPU environment."))
+	fmt.Printf(i18n.T("\n%s GPU environment activated %s\n"), styles.Success.Render("✓"), styles.Muted.Render(i18n.T("(type 'exit' to deactivate)")))
+	fmt.Printf("%s\n\n", styles.Muted.Render(i18n.T("Ctrl+C interrupts the current command, not the GPU environment.")))
 
 	// Ignore SIGINT (Ctrl+C) in parent process - let the child shell handle it.
 	// On Windows, CTRL_C_EVENT is sent to all processes in the console.
@@ -1082,28 +1914,34 @@ func (r *tempEnvResultWindows) RenderTUI(out *tui.Output) {
 
 // setupLongTermEnv sets up a long-term GPU environment
 // When yes=true, outputs shell commands for eval (user runs: eval "$(ggo use xxx -y --long-term)")
-func setupLongTermEnv(shareInfo *api.SharePublicInfo, outputDir string, yes bool, out *tui.Output) error {
+func setupLongTermEnv(shareInfo *api.SharePublicInfo, rec *studio.UseConnection, outputDir string, yes bool, out *tui.Output) error {
 	klog.Info("Setting up long-term GPU environment...")
 
 	if outputDir == "" {
-		outputDir = paths.UserDir()
+		outputDir = paths.StudioConfigDir(useStudioName(rec))
 	}
 
+	// Only a directory created here belongs to the connection; an existing
+	// --output-dir is left in place on clean
+	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+		rec.AddDirs(outputDir)
+	}
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	vendor := studio.ParseVendor(shareInfo.HardwareVendor)
-	studioName := "current-os"
+	studioName := useStudioName(rec)
 
 	// Create GPU environment config
 	config := &studio.GPUEnvConfig{
-		Vendor:        vendor,
-		ConnectionURL: shareInfo.ConnectionURL,
-		CachePath:     paths.CacheDir(),
-		LogPath:       paths.StudioLogsDir(studioName),
-		StudioName:    studioName,
-		IsContainer:   false,
+		Vendor:         vendor,
+		ConnectionURL:  shareInfo.ConnectionURL,
+		CachePath:      paths.CacheDir(),
+		LogPath:        paths.StudioLogsDir(studioName),
+		StudioName:     studioName,
+		IsContainer:    false,
+		ConnectionName: rec.ID(),
 	}
 
 	// Setup GPU environment
@@ -1111,6 +1949,7 @@ func setupLongTermEnv(shareInfo *api.SharePublicInfo, outputDir string, yes bool
 	if err != nil {
 		return fmt.Errorf("failed to setup GPU environment: %w", err)
 	}
+	rec.AddDirs(paths.StudioConfigDir(studioName))
 
 	// Write config file
 	configFile := filepath.Join(outputDir, "config.json")
@@ -1125,16 +1964,17 @@ func setupLongTermEnv(shareInfo *api.SharePublicInfo, outputDir string, yes bool
 	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
+	rec.AddFiles(configFile)
 
 	if platform.IsWindows() {
-		return setupLongTermWindows(shareInfo, config, envResult, outputDir, yes, out)
+		return setupLongTermWindows(shareInfo, rec, config, envResult, outputDir, yes, out)
 	}
-	return setupLongTermUnix(shareInfo, config, envResult, outputDir, yes, out)
+	return setupLongTermUnix(shareInfo, rec, config, envResult, outputDir, yes, out)
 }
 
 // setupLongTermUnix sets up long-term environment for Unix
 // When yes=true, outputs shell commands for eval
-func setupLongTermUnix(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, outputDir string, yes bool, out *tui.Output) error {
+func setupLongTermUnix(shareInfo *api.SharePublicInfo, rec *studio.UseConnection, config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, outputDir string, yes bool, out *tui.Output) error {
 	// Generate the profile script
 	profileScript, err := studio.GenerateEnvScript(config, paths)
 	if err != nil {
@@ -1152,10 +1992,12 @@ func setupLongTermUnix(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConf
 	if err := os.WriteFile(cleanFile, []byte(cleanScript), 0755); err != nil {
 		klog.Warningf("Failed to write clean script: %v", err)
 	}
+	rec.AddFiles(profileSnippet, cleanFile)
+	recordUseConnection(rec)
 
 	// If -y flag, output shell commands for eval
 	if yes {
-		return outputEvalCommands(config, envResult, profileSnippet, cleanFile, out)
+		return outputEvalCommands(config, envResult, profileSnippet, cleanFile, takeover{}, out)
 	}
 
 	styles := tui.DefaultStyles()
@@ -1182,7 +2024,7 @@ func setupLongTermUnix(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConf
 	}
 
 	if shellRC != "" && !out.IsJSON() {
-		out.Println(styles.Subtitle.Render("Permanent Activation"))
+		out.Println(styles.Subtitle.Render(i18n.T("Permanent Activation")))
 		out.Println()
 		out.Printf("Add GPU environment to %s for all new shells? [Y/n]: ", filepath.Base(shellRC))
 
@@ -1191,11 +2033,13 @@ func setupLongTermUnix(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConf
 		shouldAdd := isYesResponse(response)
 
 		if shouldAdd {
-			sourceLine := fmt.Sprintf("\n# GPU Go environment\nsource %s\n", profileSnippet)
-			if err := appendToFile(shellRC, sourceLine, profileSnippet); err != nil {
-				out.Warning(fmt.Sprintf("Failed to update %s: %v", shellRC, err))
+			sourceLine := fmt.Sprintf("source %s", profileSnippet)
+			if err := appendToFile(shellRC, fmt.Sprintf("\n%s\n%s\n", profileMarker, sourceLine), profileSnippet); err != nil {
+				out.Warningf("Failed to update %s: %v", shellRC, err)
 			} else {
-				out.Success(fmt.Sprintf("Added to %s", shellRC))
+				rec.AddProfileLine(shellRC, sourceLine)
+				recordUseConnection(rec)
+				out.Successf("Added to %s", shellRC)
 				out.Println()
 				out.Println("Restart your terminal or run:")
 				out.Printf("\n   source %s\n\n", shellRC)
@@ -1203,10 +2047,10 @@ func setupLongTermUnix(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConf
 		}
 
 		out.Println()
-		out.Println(styles.Subtitle.Render("Current Shell Activation"))
+		out.Println(styles.Subtitle.Render(i18n.T("Current Shell Activation")))
 		out.Println()
 		out.Println("To activate in your current shell now:")
-		out.Printf("\n   eval \"$(ggo use %s -y)\"\n\n", extractShortCode(shareInfo.WorkerID))
+		out.Printf("\n   eval \"$(ggo use %s -y)\"\n\n", cmdutil.ExtractShortCode(shareInfo.WorkerID))
 		out.Println("To deactivate later:")
 		out.Println("\n   ggo clean")
 		out.Println()
@@ -1241,7 +2085,7 @@ func appendToFile(filePath, content, marker string) error {
 
 // setupLongTermWindows sets up long-term environment for Windows
 // When yes=true, outputs shell commands for eval
-func setupLongTermWindows(shareInfo *api.SharePublicInfo, config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, outputDir string, yes bool, out *tui.Output) error {
+func setupLongTermWindows(shareInfo *api.SharePublicInfo, rec *studio.UseConnection, config *studio.GPUEnvConfig, envResult *studio.GPUEnvResult, outputDir string, yes bool, out *tui.Output) error {
 	// Generate PowerShell profile
 	psProfile, err := studio.GeneratePowerShellScript(config, paths)
 	if err != nil {
@@ -1285,10 +2129,15 @@ func setupLongTermWindows(shareInfo *api.SharePublicInfo, config *studio.GPUEnvC
 	if err := os.WriteFile(cleanBatFile, []byte(cleanBatScript), 0644); err != nil {
 		klog.Warningf("Failed to write CMD clean script: %v", err)
 	}
+	rec.AddFiles(psProfilePath, batFile, cleanPSFile, cleanBatFile)
+	rec.AddPathEntries(windowsPathEntries(config)...)
+	// setenv.bat is run by the user; assume its variables may be set
+	rec.WindowsEnv = true
+	recordUseConnection(rec)
 
 	// If -y flag, output shell commands for eval
 	if yes {
-		return outputEvalCommands(config, envResult, psProfilePath, cleanPSFile, out)
+		return outputEvalCommands(config, envResult, psProfilePath, cleanPSFile, takeover{}, out)
 	}
 
 	styles := tui.DefaultStyles()
@@ -1307,7 +2156,7 @@ func setupLongTermWindows(shareInfo *api.SharePublicInfo, config *studio.GPUEnvC
 	// Detect shell and offer to add to profile
 	shell := detectWindowsShell()
 	if shell == shellPowerShell && !out.IsJSON() {
-		out.Println(styles.Subtitle.Render("Permanent Activation"))
+		out.Println(styles.Subtitle.Render(i18n.T("Permanent Activation")))
 		out.Println()
 		out.Printf("Add GPU environment to PowerShell profile for all new shells? [Y/n]: ")
 
@@ -1322,11 +2171,13 @@ func setupLongTermWindows(shareInfo *api.SharePublicInfo, config *studio.GPUEnvC
 				profilePath = filepath.Join(os.Getenv("USERPROFILE"), "Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1")
 			}
 
-			sourceLine := fmt.Sprintf("\n# GPU Go environment\n. \"%s\"\n", psProfilePath)
-			if err := appendToFile(profilePath, sourceLine, psProfilePath); err != nil {
-				out.Warning(fmt.Sprintf("Failed to update PowerShell profile: %v", err))
+			sourceLine := fmt.Sprintf(". \"%s\"", psProfilePath)
+			if err := appendToFile(profilePath, fmt.Sprintf("\n%s\n%s\n", profileMarker, sourceLine), psProfilePath); err != nil {
+				out.Warningf("Failed to update PowerShell profile: %v", err)
 			} else {
-				out.Success(fmt.Sprintf("Added to PowerShell profile: %s", profilePath))
+				rec.AddProfileLine(profilePath, sourceLine)
+				recordUseConnection(rec)
+				out.Successf("Added to PowerShell profile: %s", profilePath)
 				out.Println()
 				out.Println("Restart your terminal or run:")
 				out.Printf("\n   . $PROFILE\n\n")
@@ -1334,15 +2185,15 @@ func setupLongTermWindows(shareInfo *api.SharePublicInfo, config *studio.GPUEnvC
 		}
 
 		out.Println()
-		out.Println(styles.Subtitle.Render("Current Shell Activation"))
+		out.Println(styles.Subtitle.Render(i18n.T("Current Shell Activation")))
 		out.Println()
 		out.Println("To activate in your current shell now:")
-		out.Printf("\n   ggo use %s -y | Out-String | Invoke-Expression\n\n", extractShortCode(shareInfo.WorkerID))
+		out.Printf("\n   ggo use %s -y | Out-String | Invoke-Expression\n\n", cmdutil.ExtractShortCode(shareInfo.WorkerID))
 		out.Println("To deactivate later:")
 		out.Println("\n   ggo clean")
 		out.Println()
 	} else if !out.IsJSON() {
-		out.Println(styles.Subtitle.Render("Permanent Activation"))
+		out.Println(styles.Subtitle.Render(i18n.T("Permanent Activation")))
 		out.Println()
 		out.Println("To activate in all new PowerShell sessions, add to your profile:")
 		out.Println("  Run: notepad $PROFILE")
@@ -1350,7 +2201,7 @@ func setupLongTermWindows(shareInfo *api.SharePublicInfo, config *studio.GPUEnvC
 		out.Println("Or set permanent environment variables:")
 		out.Printf("  %s\n\n", batFile)
 		out.Println("To activate in current CMD session:")
-		out.Printf("\n   for /f \"delims=\" %%i in ('ggo use %s -y') do @%%i\n\n", extractShortCode(shareInfo.WorkerID))
+		out.Printf("\n   for /f \"delims=\" %%i in ('ggo use %s -y') do @%%i\n\n", cmdutil.ExtractShortCode(shareInfo.WorkerID))
 		out.Println("To clean up, run:")
 		out.Println("\n   ggo clean --all")
 		out.Println()
@@ -1401,12 +2252,60 @@ func (r *longTermEnvResultWindows) RenderTUI(out *tui.Output) {
 
 // cleanEnvEval outputs shell commands to restore environment for eval mode
 func cleanEnvEval(out *tui.Output) error {
+	// A stacked activation returns to the environment it was put on
+	if restoreFile := stackedRestoreFile(); restoreFile != "" {
+		return restoreEnvEval(restoreFile)
+	}
 	if platform.IsWindows() {
 		return cleanEnvEvalWindows(out)
 	}
 	return cleanEnvEvalUnix(out)
 }
 
+// cleanEnvEvalReleased removes the artifacts of the named environment, or of
+// all of them, and outputs the commands that deactivate the current shell
+// when its own environment was among them. Messages go to stderr so the
+// output can still be evaluated.
+func cleanEnvEvalReleased(args []string, all bool, out *tui.Output) error {
+	registry := studio.NewUseRegistry(paths)
+	var released []studio.UseConnection
+	if all {
+		conns, err := registry.ReleaseAll()
+		if err != nil {
+			return err
+		}
+		released = conns
+	} else {
+		shortCode := cmdutil.ExtractShortCode(args[0])
+		conn, err := registry.Release(shortCode)
+		if err != nil {
+			return err
+		}
+		if conn == nil {
+			return fmt.Errorf("no GPU environment recorded for %s (run 'ggo clean --all' to remove every recorded environment)", shortCode)
+		}
+		released = []studio.UseConnection{*conn}
+	}
+
+	active := false
+	for i := range released {
+		removeUseArtifacts(&released[i], nil)
+		fmt.Fprintf(os.Stderr, i18n.T("GPU environment %s cleaned up\n"), released[i].ID())
+		active = active || sessionUsesConnection(&released[i])
+	}
+	if !active {
+		return nil
+	}
+	return cleanEnvEval(out)
+}
+
+// sessionUsesConnection reports whether the calling shell was activated for
+// conn by 'ggo use'
+func sessionUsesConnection(conn *studio.UseConnection) bool {
+	name := os.Getenv(studio.ConnectionEnv)
+	return name != "" && name == conn.ID()
+}
+
 // cleanEnvEvalUnix outputs shell commands to restore environment for eval mode (Unix/Linux)
 func cleanEnvEvalUnix(out *tui.Output) error {
 	var script strings.Builder
@@ -1415,45 +2314,7 @@ func cleanEnvEvalUnix(out *tui.Output) error {
 	script.WriteString("if [ -z \"$_GGO_ACTIVE\" ]; then\n")
 	script.WriteString("  echo 'GPU Go environment is not active' >&2\n")
 	script.WriteString("else\n")
-
-	// Restore original LD_LIBRARY_PATH
-	script.WriteString("  if [ -n \"$_GGO_ORIG_LD_LIBRARY_PATH\" ]; then\n")
-	script.WriteString("    export LD_LIBRARY_PATH=\"$_GGO_ORIG_LD_LIBRARY_PATH\"\n")
-	script.WriteString("  else\n")
-	script.WriteString("    unset LD_LIBRARY_PATH\n")
-	script.WriteString("  fi\n")
-
-	// Restore original LD_PRELOAD
-	script.WriteString("  if [ -n \"$_GGO_ORIG_LD_PRELOAD\" ]; then\n")
-	script.WriteString("    export LD_PRELOAD=\"$_GGO_ORIG_LD_PRELOAD\"\n")
-	script.WriteString("  else\n")
-	script.WriteString("    unset LD_PRELOAD\n")
-	script.WriteString("  fi\n")
-
-	// Restore original PATH
-	script.WriteString("  if [ -n \"$_GGO_ORIG_PATH\" ]; then\n")
-	script.WriteString("    export PATH=\"$_GGO_ORIG_PATH\"\n")
-	script.WriteString("  fi\n")
-
-	// Unset TensorFusion environment variables
-	script.WriteString("  unset TENSOR_FUSION_OPERATOR_CONNECTION_INFO\n")
-	script.WriteString("  unset TF_LOG_PATH\n")
-	script.WriteString("  unset TF_LOG_LEVEL\n")
-	script.WriteString("  unset TF_ENABLE_LOG\n")
-	script.WriteString("  unset TF_GPU_VENDOR\n")
-
-	// Unset internal tracking variables
-	script.WriteString("  unset _GGO_ORIG_LD_LIBRARY_PATH\n")
-	script.WriteString("  unset _GGO_ORIG_LD_PRELOAD\n")
-	script.WriteString("  unset _GGO_ORIG_PATH\n")
-	script.WriteString("  unset _GGO_ACTIVE\n")
-	script.WriteString("  unset _GGO_LIBS_PATH\n")
-	script.WriteString("  unset _GGO_CLEAN_FILE\n")
-
-	// Remove ggo wrapper function
-	script.WriteString("  unset -f ggo 2>/dev/null\n")
-	script.WriteString("  unset _ggo_real\n")
-
+	script.WriteString(unixDeactivateCommands("  "))
 	script.WriteString("  echo 'GPU Go environment deactivated' >&2\n")
 	script.WriteString("fi\n")
 
@@ -1462,6 +2323,52 @@ func cleanEnvEvalUnix(out *tui.Output) error {
 	return nil
 }
 
+// unixDeactivateCommands returns the shell commands that deactivate the
+// environment in the calling shell, without checking it is active, each line
+// prefixed with indent
+func unixDeactivateCommands(indent string) string {
+	lines := []string{
+		// Restore original LD_LIBRARY_PATH, LD_PRELOAD and PATH
+		"if [ -n \"$_GGO_ORIG_LD_LIBRARY_PATH\" ]; then",
+		"  export LD_LIBRARY_PATH=\"$_GGO_ORIG_LD_LIBRARY_PATH\"",
+		"else",
+		"  unset LD_LIBRARY_PATH",
+		"fi",
+		"if [ -n \"$_GGO_ORIG_LD_PRELOAD\" ]; then",
+		"  export LD_PRELOAD=\"$_GGO_ORIG_LD_PRELOAD\"",
+		"else",
+		"  unset LD_PRELOAD",
+		"fi",
+		"if [ -n \"$_GGO_ORIG_PATH\" ]; then",
+		"  export PATH=\"$_GGO_ORIG_PATH\"",
+		"fi",
+		// Unset TensorFusion environment variables
+		"unset TENSOR_FUSION_OPERATOR_CONNECTION_INFO",
+		"unset TF_LOG_PATH",
+		"unset TF_LOG_LEVEL",
+		"unset TF_ENABLE_LOG",
+		"unset TF_GPU_VENDOR",
+		// Unset internal tracking variables
+		"unset _GGO_ORIG_LD_LIBRARY_PATH",
+		"unset _GGO_ORIG_LD_PRELOAD",
+		"unset _GGO_ORIG_PATH",
+		"unset _GGO_ACTIVE",
+		"unset _GGO_LIBS_PATH",
+		"unset _GGO_BIN_PATH",
+		"unset _GGO_CLEAN_FILE",
+		"unset " + restoreFileEnv,
+		"unset " + studio.ConnectionEnv,
+		// Remove ggo wrapper function
+		"unset -f ggo 2>/dev/null",
+		"unset _ggo_real",
+	}
+	var script strings.Builder
+	for _, line := range lines {
+		script.WriteString(indent + line + "\n")
+	}
+	return script.String()
+}
+
 // cleanEnvEvalWindows outputs shell commands to restore environment for eval mode (Windows)
 func cleanEnvEvalWindows(out *tui.Output) error {
 	shell := detectWindowsShell()
@@ -1479,31 +2386,8 @@ func cleanEnvEvalPowerShell(out *tui.Output) error {
 	script.WriteString("if (-not $env:_GGO_ACTIVE) {\n")
 	script.WriteString("  [Console]::Error.WriteLine('GPU Go environment is not active')\n")
 	script.WriteString("} else {\n")
-
-	// Restore original PATH
-	script.WriteString("  if ($env:_GGO_ORIG_PATH) {\n")
-	script.WriteString("    $env:PATH = $env:_GGO_ORIG_PATH\n")
-	script.WriteString("  }\n\n")
-
-	// Unset TensorFusion environment variables
-	script.WriteString("  Remove-Item Env:TENSOR_FUSION_OPERATOR_CONNECTION_INFO -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:TF_LOG_PATH -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:TF_LOG_LEVEL -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:TF_ENABLE_LOG -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:TF_GPU_VENDOR -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:CUDA_PATH -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:CUDA_HOME -ErrorAction SilentlyContinue\n\n")
-
-	// Unset internal tracking variables
-	script.WriteString("  Remove-Item Env:_GGO_ORIG_PATH -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:_GGO_ACTIVE -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:_GGO_CACHE_PATH -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Item Env:_GGO_CLEAN_FILE -ErrorAction SilentlyContinue\n\n")
-
-	// Remove ggo wrapper function (use Global scope since we defined it as Global)
-	script.WriteString("  Remove-Item Function:ggo -ErrorAction SilentlyContinue\n")
-	script.WriteString("  Remove-Variable _ggo_real -Scope Global -ErrorAction SilentlyContinue\n\n")
-
+	script.WriteString(powerShellDeactivateCommands("  "))
+	script.WriteString("\n")
 	script.WriteString("  [Console]::Error.WriteLine('GPU Go environment deactivated')\n")
 	script.WriteString("}\n")
 
@@ -1512,25 +2396,48 @@ func cleanEnvEvalPowerShell(out *tui.Output) error {
 	return nil
 }
 
-// cleanEnvEvalCMD outputs guidance for CMD users to restore environment
-// Note: CMD doesn't support eval-style command execution
+// powerShellDeactivateCommands returns the PowerShell commands that
+// deactivate the environment in the calling session, without checking it is
+// active, each line prefixed with indent
+func powerShellDeactivateCommands(indent string) string {
+	var script strings.Builder
+
+	// Remove the PATH entries added on activation
+	script.WriteString(powerShellPathCleanup(indent))
+	script.WriteString(powerShellWSLENVCleanup(indent))
+
+	// Unset TensorFusion environment variables
+	for _, name := range []string{
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO", "TF_LOG_PATH", "TF_LOG_LEVEL", "TF_ENABLE_LOG", "TF_GPU_VENDOR", "CUDA_PATH", "CUDA_HOME",
+		"_GGO_PATH_ADDED", "_GGO_ORIG_PATH", "_GGO_ACTIVE", "_GGO_LIBS_PATH", "_GGO_BIN_PATH", "_GGO_CLEAN_FILE", restoreFileEnv, studio.ConnectionEnv,
+	} {
+		fmt.Fprintf(&script, "%sRemove-Item Env:%s -ErrorAction SilentlyContinue\n", indent, name)
+	}
+
+	// Remove ggo wrapper function (use Global scope since we defined it as Global)
+	script.WriteString(indent + "Remove-Item Function:ggo -ErrorAction SilentlyContinue\n")
+	script.WriteString(indent + "Remove-Variable _ggo_real -Scope Global -ErrorAction SilentlyContinue\n")
+	return script.String()
+}
+
+// cleanEnvEvalCMD deactivates the environment in CMD the same way activation
+// works: the clean script plus removal of the ggo macro go into a temporary
+// .cmd and a single `call` line is printed for for /f
 func cleanEnvEvalCMD(out *tui.Output) error {
-	// Check if clean script exists
-	cleanFile := os.Getenv("_GGO_CLEAN_FILE")
-	if cleanFile == "" {
-		fmt.Fprintf(os.Stderr, "GPU Go environment is not active or clean file not found.\n")
+	if os.Getenv("_GGO_ACTIVE") == "" {
+		fmt.Fprint(os.Stderr, i18n.T("GPU Go environment is not active\n"))
 		return nil
 	}
 
-	// Output message to stderr
-	fmt.Fprintf(os.Stderr, "CMD does not support eval-style deactivation.\n")
-	fmt.Fprintf(os.Stderr, "Please run the following command to deactivate GPU environment:\n")
-	fmt.Fprintf(os.Stderr, "\n")
-	fmt.Fprintf(os.Stderr, "  call \"%s\"\n", cleanFile)
-	fmt.Fprintf(os.Stderr, "\n")
-
-	// Output the call command to stdout so user can copy-paste
-	fmt.Printf("call \"%s\"\n", cleanFile)
+	callFile, err := writeCMDEvalScript("ggo-clean-*.cmd", generateCleanScriptCMD())
+	if err != nil {
+		klog.Warningf("Failed to write CMD clean script: error=%v", err)
+		if cleanFile := os.Getenv("_GGO_CLEAN_FILE"); cleanFile != "" {
+			fmt.Printf("call \"%s\"\n", cleanFile)
+		}
+		return nil
+	}
+	fmt.Printf("call \"%s\"\n", callFile)
 	return nil
 }
 
@@ -1542,9 +2449,9 @@ func cleanCurrentEnv(out *tui.Output) error {
 	if !out.IsJSON() {
 		// Print prompt to stderr so it doesn't interfere with eval
 		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, styles.Subtitle.Render("Clean GPU Go Environment"))
+		fmt.Fprintln(os.Stderr, styles.Subtitle.Render(i18n.T("Clean GPU Go Environment")))
 		fmt.Fprintln(os.Stderr)
-		fmt.Fprint(os.Stderr, "Would you like to deactivate GPU environment in your current shell? [Y/n]: ")
+		fmt.Fprint(os.Stderr, i18n.T("Would you like to deactivate GPU environment in your current shell? [Y/n]: "))
 
 		reader := bufio.NewReader(os.Stdin)
 		response, _ := reader.ReadString('\n')
@@ -1554,37 +2461,35 @@ func cleanCurrentEnv(out *tui.Output) error {
 			// User confirmed cleanup - need to guide them to use eval
 			// Direct command execution doesn't work because we need shell to eval the unset commands
 			fmt.Fprintln(os.Stderr)
-			fmt.Fprintln(os.Stderr, styles.Subtitle.Render("To deactivate, run:"))
+			fmt.Fprintln(os.Stderr, styles.Subtitle.Render(i18n.T("To deactivate, run:")))
 			fmt.Fprintln(os.Stderr)
 			if platform.IsWindows() {
 				shell := detectWindowsShell()
 				if shell == shellPowerShell {
 					fmt.Fprintln(os.Stderr, "   ggo clean -y | Out-String | Invoke-Expression")
 					fmt.Fprintln(os.Stderr)
-					fmt.Fprintln(os.Stderr, "Or if you activated via 'ggo use ... -y | Out-String | Invoke-Expression', just run:")
+					fmt.Fprintln(os.Stderr, i18n.T("Or if you activated via 'ggo use ... -y | Out-String | Invoke-Expression', just run:"))
 					fmt.Fprintln(os.Stderr)
 					fmt.Fprintln(os.Stderr, "   ggo clean")
 					fmt.Fprintln(os.Stderr)
-					fmt.Fprintln(os.Stderr, "(The wrapper function will handle it automatically)")
+					fmt.Fprintln(os.Stderr, i18n.T("(The wrapper function will handle it automatically)"))
 				} else {
-					// CMD user - guide to use clean.bat
-					cleanFile := os.Getenv("_GGO_CLEAN_FILE")
-					if cleanFile != "" {
-						fmt.Fprintf(os.Stderr, "   call \"%s\"\n", cleanFile)
-					} else {
-						fmt.Fprintln(os.Stderr, "   call clean.bat (in ggo config directory)")
-					}
+					fmt.Fprintln(os.Stderr, "   for /f \"delims=\" %i in ('ggo clean -y') do @%i")
+					fmt.Fprintln(os.Stderr)
+					fmt.Fprintln(os.Stderr, i18n.T("Or if you activated via 'for /f ... ggo use ... -y', just run:"))
+					fmt.Fprintln(os.Stderr)
+					fmt.Fprintln(os.Stderr, "   ggo clean")
 					fmt.Fprintln(os.Stderr)
-					fmt.Fprintln(os.Stderr, "Or switch to PowerShell for better experience.")
+					fmt.Fprintln(os.Stderr, i18n.T("(The doskey macro will handle it automatically)"))
 				}
 			} else {
 				fmt.Fprintln(os.Stderr, "   eval \"$(ggo clean -y)\"")
 				fmt.Fprintln(os.Stderr)
-				fmt.Fprintln(os.Stderr, "Or if you activated via 'eval \"$(ggo use ...)\"', just run:")
+				fmt.Fprintln(os.Stderr, i18n.T("Or if you activated via 'eval \"$(ggo use ...)\"', just run:"))
 				fmt.Fprintln(os.Stderr)
 				fmt.Fprintln(os.Stderr, "   ggo clean")
 				fmt.Fprintln(os.Stderr)
-				fmt.Fprintln(os.Stderr, "(The wrapper function will handle it automatically)")
+				fmt.Fprintln(os.Stderr, i18n.T("(The wrapper function will handle it automatically)"))
 			}
 			fmt.Fprintln(os.Stderr)
 			return nil
@@ -1592,7 +2497,7 @@ func cleanCurrentEnv(out *tui.Output) error {
 
 		// User chose not to clean, show how to clean later
 		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, "You can deactivate later by running:")
+		fmt.Fprintln(os.Stderr, i18n.T("You can deactivate later by running:"))
 		fmt.Fprintln(os.Stderr)
 		if platform.IsWindows() {
 			shell := detectWindowsShell()
@@ -1610,7 +2515,7 @@ func cleanCurrentEnv(out *tui.Output) error {
 			fmt.Fprintln(os.Stderr, "   ggo clean    (if environment is activated)")
 		}
 		fmt.Fprintln(os.Stderr)
-		fmt.Fprintln(os.Stderr, "To clean up all GPU Go connections (including shell profiles):")
+		fmt.Fprintln(os.Stderr, i18n.T("To clean up all GPU Go connections (including shell profiles):"))
 		fmt.Fprintln(os.Stderr)
 		fmt.Fprintln(os.Stderr, "   ggo clean --all")
 		fmt.Fprintln(os.Stderr)
@@ -1619,188 +2524,125 @@ func cleanCurrentEnv(out *tui.Output) error {
 	return nil
 }
 
-// cleanEnv cleans up a specific GPU environment
-func cleanEnv(shortCode string, out *tui.Output) error {
+// cleanEnv removes the artifacts recorded for one share code. Artifacts still
+// used by another recorded connection are kept.
+func cleanEnv(shortCode string, verbose bool, out *tui.Output) error {
 	klog.Infof("Cleaning up GPU environment: short_link=%s", shortCode)
 
-	tmpDirs, _ := filepath.Glob(paths.GlobPattern("gpugo-"))
-	for _, dir := range tmpDirs {
-		if err := os.RemoveAll(dir); err != nil {
-			klog.Warningf("Failed to remove temp directory: dir=%s error=%v", dir, err)
-		}
+	conn, err := studio.NewUseRegistry(paths).Release(shortCode)
+	if err != nil {
+		klog.Errorf("Failed to release GPU environment: short_link=%s error=%v", shortCode, err)
+		return err
+	}
+	if conn == nil {
+		return fmt.Errorf("no GPU environment recorded for %s (run 'ggo clean --all' to remove every recorded environment)", shortCode)
 	}
 
-	return out.Render(&cmdutil.ActionData{
-		Success: true,
-		Message: "GPU environment cleaned up successfully",
-		ID:      shortCode,
-	})
+	result := &cleanResult{Connections: []string{shortCode}}
+	result.Actions = removeUseArtifacts(conn, cleanActionPrinter(verbose, out))
+	return out.Render(result)
 }
 
-// cleanAllEnv cleans up all GPU environments
-func cleanAllEnv(out *tui.Output) error {
+// cleanAllEnv removes the artifacts of every recorded GPU environment
+func cleanAllEnv(verbose bool, out *tui.Output) error {
 	klog.Info("Cleaning up all GPU environments...")
 
-	// Clean temporary directories
-	tmpDirs, _ := filepath.Glob(paths.GlobPattern("gpugo-"))
-	for _, dir := range tmpDirs {
-		if err := os.RemoveAll(dir); err != nil {
-			klog.Warningf("Failed to remove temp directory: dir=%s error=%v", dir, err)
-		} else {
-			klog.V(4).Infof("Removed temp directory: dir=%s", dir)
-		}
-	}
-
-	// Clean current-os studio config
-	currentOSConfigDir := paths.StudioConfigDir("current-os")
-	if err := os.RemoveAll(currentOSConfigDir); err != nil {
-		klog.Warningf("Failed to remove current-os config: error=%v", err)
+	conns, err := studio.NewUseRegistry(paths).ReleaseAll()
+	if err != nil {
+		klog.Errorf("Failed to release GPU environments: error=%v", err)
+		return err
 	}
 
-	// Try to remove source lines from shell profiles
-	removeFromShellProfiles()
-
-	// On Windows, also clean up permanent environment variables set by setx
-	if platform.IsWindows() {
-		removePermanentWinEnv()
+	result := &cleanResult{All: true, Connections: []string{}}
+	for i := range conns {
+		result.Connections = append(result.Connections, conns[i].ID())
+		result.Actions = append(result.Actions, removeUseArtifacts(&conns[i], cleanActionPrinter(verbose, out))...)
 	}
-
-	return out.Render(&cleanAllResult{})
+	return out.Render(result)
 }
 
-// removeFromShellProfiles removes GPU Go source lines from shell profiles
-func removeFromShellProfiles() {
-	if platform.IsWindows() {
-		removeFromPowerShellProfile()
-		return
-	}
-
-	home := os.Getenv("HOME")
-	if home == "" {
-		return
+// planClean shows what 'ggo clean' would remove for a connection, or every
+// connection with all, without changing anything
+func planClean(shortCode string, all bool, out *tui.Output) error {
+	registry := studio.NewUseRegistry(paths)
+	result := &cleanResult{DryRun: true, All: all, Connections: []string{}}
+
+	if !all {
+		conn, err := registry.Plan(shortCode)
+		if err != nil {
+			klog.Errorf("Failed to read GPU environment: short_link=%s error=%v", shortCode, err)
+			return err
+		}
+		if conn == nil {
+			return fmt.Errorf("no GPU environment recorded for %s (run 'ggo clean --all --dry-run' to see every recorded environment)", shortCode)
+		}
+		result.Connections = append(result.Connections, shortCode)
+		result.Actions = planUseArtifacts(conn)
+		return out.Render(result)
 	}
 
-	profiles := []string{
-		filepath.Join(home, ".bashrc"),
-		filepath.Join(home, ".zshrc"),
-		filepath.Join(home, ".profile"),
+	conns, err := registry.List()
+	if err != nil {
+		klog.Errorf("Failed to read GPU environments: error=%v", err)
+		return err
 	}
-
-	marker := "# GPU Go environment"
-	for _, profile := range profiles {
-		removeLineFromFile(profile, marker)
+	for i := range conns {
+		result.Connections = append(result.Connections, conns[i].ID())
+		result.Actions = appendUniqueActions(result.Actions, planUseArtifacts(&conns[i]))
 	}
+	return out.Render(result)
 }
 
-// removeFromPowerShellProfile removes GPU Go source lines from PowerShell profile
-func removeFromPowerShellProfile() {
-	// Check standard PowerShell profile locations
-	// 1. Current User, All Hosts
-	// 2. Current User, Current Host
-
-	// We primarily target the one we added to, which is likely $PROFILE (Current User, Current Host)
-	// But we can check standard paths.
-
-	// Get My Documents path
-	home := os.Getenv("USERPROFILE")
-	if home == "" {
-		return
-	}
-
-	docs := filepath.Join(home, "Documents")
-	// Check for OneDrive
-	oneDriveDocs := filepath.Join(home, "OneDrive", "Documents")
-
-	candidates := []string{
-		filepath.Join(docs, "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"),
-		filepath.Join(docs, "PowerShell", "Microsoft.PowerShell_profile.ps1"),
-		filepath.Join(oneDriveDocs, "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"),
-		filepath.Join(oneDriveDocs, "PowerShell", "Microsoft.PowerShell_profile.ps1"),
-	}
-
-	// Also check $PROFILE env if set and distinct
-	if profileEnv := os.Getenv("PROFILE"); profileEnv != "" {
-		candidates = append(candidates, profileEnv)
-	}
-
-	marker := "# GPU Go environment"
-	seen := make(map[string]bool)
-
-	for _, profile := range candidates {
-		if seen[profile] {
-			continue
-		}
-		seen[profile] = true
-
-		// removeLineFromFile handles file reading/existence checks
-		removeLineFromFile(profile, marker)
+// recordUseConnection saves the artifacts created so far for a connection.
+// Failing to record only means 'ggo clean <code>' cannot find them later.
+func recordUseConnection(rec *studio.UseConnection) {
+	if err := studio.NewUseRegistry(paths).Record(rec); err != nil {
+		klog.Warningf("Failed to record GPU environment: short_link=%s error=%v", rec.ShortCode, err)
 	}
 }
 
-// removePermanentWinEnv removes permanent environment variables on Windows
-func removePermanentWinEnv() {
-	// List of variables we set in setenv.bat
-	vars := []string{
-		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO",
-		"TF_LOG_PATH",
-		"TF_LOG_LEVEL",
-		"TF_ENABLE_LOG",
-		"TF_GPU_VENDOR",
-		"CUDA_PATH",
-		"CUDA_HOME",
-	}
-
-	for _, v := range vars {
-		// Use reg delete to remove from HKCU\Environment
-		// reg delete HKCU\Environment /F /V VariableName
-		cmd := exec.Command("reg", "delete", "HKCU\\Environment", "/F", "/V", v)
-		// We ignore errors as the variable might not exist
-		_ = cmd.Run()
-	}
+// removePermanentWinEnvVar removes a permanent user environment variable
+// set by setenv.bat on Windows. It reports whether the variable was set.
+func removePermanentWinEnvVar(name string) bool {
+	// reg delete HKCU\Environment /F /V VariableName fails when the variable
+	// does not exist
+	return exec.Command("reg", "delete", userEnvKey, "/F", "/V", name).Run() == nil
 }
 
-// removeLineFromFile removes lines containing marker from a file
-func removeLineFromFile(filePath, marker string) {
+// removeProfileLine removes a line added by 'ggo use' from a shell profile,
+// together with the marker comment written right before it. It reports
+// whether the profile held the line.
+func removeProfileLine(filePath, line string) (bool, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
 	}
 
 	lines := strings.Split(string(data), "\n")
 	var newLines []string
-	skipNext := false
-
-	for _, line := range lines {
-		if strings.Contains(line, marker) {
-			skipNext = true // Skip this line and the source line that follows
+	for _, l := range lines {
+		if strings.TrimSpace(l) != line {
+			newLines = append(newLines, l)
 			continue
 		}
-		trimmed := strings.TrimSpace(line)
-		// Support both Unix "source" and PowerShell "." (dot sourcing)
-		if skipNext && (strings.HasPrefix(trimmed, "source ") || strings.HasPrefix(trimmed, ".")) {
-			skipNext = false
-			continue
+		if n := len(newLines); n > 0 && strings.TrimSpace(newLines[n-1]) == profileMarker {
+			newLines = newLines[:n-1]
 		}
-		skipNext = false
-		newLines = append(newLines, line)
 	}
 
 	newContent := strings.Join(newLines, "\n")
-	if newContent != string(data) {
-		_ = os.WriteFile(filePath, []byte(newContent), 0644)
+	if newContent == string(data) {
+		return false, nil
 	}
+	return true, os.WriteFile(filePath, []byte(newContent), 0644)
 }
 
-// cleanAllResult implements Renderable for clean all command
-type cleanAllResult struct{}
-
-func (r *cleanAllResult) RenderJSON() any {
-	return tui.NewActionResult(true, "All GPU environments cleaned up successfully", "")
-}
-
-func (r *cleanAllResult) RenderTUI(out *tui.Output) {
-	out.Println("All GPU environments cleaned up successfully!")
-	out.Println()
+// renderShellNote tells how to deactivate the current shell after its
+// environment's artifacts were removed
+func renderShellNote(out *tui.Output) {
 	out.Println("Note: Environment variables in your current shell may still be set.")
 	out.Println()
 	if platform.IsWindows() {
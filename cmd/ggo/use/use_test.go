@@ -0,0 +1,140 @@
+package use
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeForCMD(t *testing.T) {
+	assert.Equal(t, `C:\gpu go\libs`, escapeForCMD(`C:\gpu go\libs`))
+	assert.Equal(t, `100%%`, escapeForCMD(`100%`))
+	assert.Equal(t, `%%PATH%%;a&b`, escapeForCMD(`%PATH%;a&b`))
+}
+
+func TestEscapeForCMDEcho(t *testing.T) {
+	assert.Equal(t, `plain`, escapeForCMDEcho(`plain`))
+	assert.Equal(t, `native+tcp://h:1/?a=1^&b=2`, escapeForCMDEcho(`native+tcp://h:1/?a=1&b=2`))
+	assert.Equal(t, `^^ ^| ^< ^> %%X%%`, escapeForCMDEcho(`^ | < > %X%`))
+}
+
+func TestWriteCMDEvalScript(t *testing.T) {
+	path, err := writeCMDEvalScript("ggo-test-*.cmd", "@echo off\nset \"A=1\"\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.Remove(path) })
+
+	assert.True(t, strings.HasSuffix(path, ".cmd"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "@echo off\r\nset \"A=1\"\r\n(goto) 2>nul & del \"%~f0\"\r\n", string(data))
+}
+
+func TestCMDWrapperMacro(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	macro := cmdWrapperMacro(`C:\Users\me\.gpugo\env\clean.bat`)
+	require.True(t, strings.HasPrefix(macro, "doskey ggo="))
+
+	// A bare clean runs the clean script in the session
+	assert.Contains(t, macro, `if /i "$*"=="clean" (call "C:\Users\me\.gpugo\env\clean.bat")`)
+	// clean with arguments evaluates the output of 'ggo clean -y'
+	assert.Contains(t, macro, `else if /i "$1"=="clean" (for /f "delims=" %i in ('call "`+exe+`" clean -y $2 $3 $4') do @%i)`)
+	// Everything else passes through
+	assert.True(t, strings.HasSuffix(macro, `else ("`+exe+`" $*)`))
+}
+
+func TestCMDActivationScript(t *testing.T) {
+	config := &studio.GPUEnvConfig{
+		Vendor:        studio.VendorNvidia,
+		ConnectionURL: "native+tcp://10.0.0.1:9000/?x=1&y=2",
+		BinPath:       `C:\gpugo\bin`,
+	}
+	envResult := &studio.GPUEnvResult{EnvVars: map[string]string{
+		"B_VAR": "50%",
+		"A_VAR": "a",
+	}}
+
+	script := cmdActivationScript(config, envResult, `C:\gpugo\env\clean.bat`, `C:\gpugo\libs`, takeover{})
+	lines := strings.Split(script, "\n")
+
+	assert.Equal(t, "@echo off", lines[0])
+	assert.Contains(t, lines, `set "_GGO_CLEAN_FILE=C:\gpugo\env\clean.bat"`)
+	assert.Contains(t, lines, `set "PATH=C:\gpugo\bin;C:\gpugo\libs;%PATH%"`)
+	assert.Contains(t, lines, `set "_GGO_PATH_ADDED=C:\gpugo\bin;C:\gpugo\libs;%_GGO_PATH_ADDED%"`)
+	assert.NotContains(t, script, "_GGO_ORIG_PATH", "the whole PATH is no longer saved")
+	assert.Contains(t, lines, `echo Connection URL: native+tcp://10.0.0.1:9000/?x=1^&y=2 1>&2`)
+	assert.Contains(t, lines, cmdWrapperMacro(`C:\gpugo\env\clean.bat`))
+
+	// Variables are set in a stable order, with % escaped
+	a := strings.Index(script, `set "A_VAR=a"`)
+	b := strings.Index(script, `set "B_VAR=50%%"`)
+	require.True(t, a >= 0 && b >= 0)
+	assert.Less(t, a, b)
+
+	// Everything printed goes to stderr, leaving stdout to the caller
+	for _, line := range lines {
+		if strings.HasPrefix(line, "echo") {
+			assert.True(t, strings.HasSuffix(line, "1>&2"), line)
+		}
+	}
+}
+
+func TestSessionUsesConnection(t *testing.T) {
+	conn := &studio.UseConnection{ShortCode: "abc123"}
+
+	t.Setenv(studio.ConnectionEnv, "")
+	assert.False(t, sessionUsesConnection(conn))
+
+	t.Setenv(studio.ConnectionEnv, conn.ID())
+	assert.True(t, sessionUsesConnection(conn))
+
+	t.Setenv(studio.ConnectionEnv, "other")
+	assert.False(t, sessionUsesConnection(conn))
+}
+
+func TestRemovePathEntries(t *testing.T) {
+	added := []string{`C:\gpugo\bin`, `C:\gpugo\libs`}
+
+	// Entries the user added during the session are kept
+	path := `C:\tools;C:\gpugo\bin;C:\gpugo\libs;C:\Windows;C:\Python\Scripts`
+	assert.Equal(t, `C:\tools;C:\Windows;C:\Python\Scripts`, removePathEntries(path, added))
+
+	// Matching ignores case, repeated activations are removed too and empty
+	// entries stay in place
+	path = `c:\GPUGO\LIBS;C:\gpugo\bin;C:\gpugo\libs;;C:\Windows;`
+	assert.Equal(t, `;C:\Windows;`, removePathEntries(path, added))
+
+	assert.Equal(t, "", removePathEntries("", added))
+	assert.Equal(t, `C:\Windows`, removePathEntries(`C:\Windows`, nil))
+}
+
+func TestParseRegQueryValue(t *testing.T) {
+	output := "\r\nHKEY_CURRENT_USER\\Environment\r\n" +
+		"    Path    REG_EXPAND_SZ    %USERPROFILE%\\bin;C:\\Program Files\\Tool\r\n\r\n"
+	valueType, data, ok := parseRegQueryValue(output, "Path")
+	require.True(t, ok)
+	assert.Equal(t, "REG_EXPAND_SZ", valueType)
+	assert.Equal(t, `%USERPROFILE%\bin;C:\Program Files\Tool`, data)
+
+	_, _, ok = parseRegQueryValue(output, "TEMP")
+	assert.False(t, ok)
+}
+
+func TestCleanScriptsRemoveAddedPathEntries(t *testing.T) {
+	cmd := generateCleanScriptCMD()
+	assert.Contains(t, cmd, `for %%e in ("%_GGO_PATH_ADDED:;=" "%") do if not "%%~e"=="" call set "PATH=%%PATH:%%~e;=%%"`)
+	assert.Contains(t, cmd, `set "_GGO_PATH_ADDED="`)
+
+	ps := generateCleanScriptWindows()
+	assert.Contains(t, ps, powerShellPathCleanup(""))
+	assert.Contains(t, ps, "Remove-Item Env:_GGO_PATH_ADDED")
+
+	// The saved PATH is only restored for sessions of an older ggo
+	cleanup := powerShellPathCleanup("")
+	assert.Less(t, strings.Index(cleanup, "$env:_GGO_PATH_ADDED"), strings.Index(cleanup, "$env:_GGO_ORIG_PATH"))
+}
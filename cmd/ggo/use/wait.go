@@ -0,0 +1,64 @@
+package use
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"k8s.io/klog/v2"
+)
+
+// waitForFreeSlot returns at once when the share's worker has a free client
+// slot. Otherwise it fails, or with wait queues until a slot frees up,
+// polling with poll for at most timeout (0 waits indefinitely).
+func waitForFreeSlot(ctx context.Context, shareInfo *api.SharePublicInfo, poll func(ctx context.Context) (*api.ShareQueueStatus, error), wait bool, timeout time.Duration, quiet bool, out *tui.Output) error {
+	if !shareInfo.Queue.Saturated() {
+		return nil
+	}
+	if !wait {
+		return &cmdutil.ShareBusyError{WorkerID: shareInfo.WorkerID, Queue: shareInfo.Queue}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var last string
+	err := cmdutil.WaitForShareSlot(ctx, shareInfo.Queue, poll, func(q *api.ShareQueueStatus) {
+		status := cmdutil.FormatShareQueue(q)
+		if status == last {
+			return
+		}
+		last = status
+		klog.Infof("Waiting for a free slot: worker_id=%s status=%q", shareInfo.WorkerID, status)
+		msg := i18n.Tf("GPU worker %s is busy, waiting for a free slot: %s", shareInfo.WorkerID, status)
+		switch {
+		case out.IsJSON():
+		case quiet:
+			// stdout carries the commands for eval
+			fmt.Fprintln(os.Stderr, msg)
+		default:
+			out.Info(msg)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("GPU worker %s is busy: %w", shareInfo.WorkerID, err)
+	}
+	klog.Infof("Free slot on GPU worker: worker_id=%s", shareInfo.WorkerID)
+	return nil
+}
+
+// newWaiterID identifies this client in a worker's queue while it waits
+func newWaiterID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
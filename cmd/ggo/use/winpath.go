@@ -0,0 +1,80 @@
+package use
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"k8s.io/klog/v2"
+)
+
+// userEnvKey is the registry key setx writes permanent user variables to
+const userEnvKey = `HKCU\Environment`
+
+// windowsPathEntries returns the directories activation prepends to PATH on
+// Windows, in order: GPU binaries first, then the libraries
+func windowsPathEntries(config *studio.GPUEnvConfig) []string {
+	libsPath := config.LibsPath
+	if libsPath == "" {
+		libsPath = paths.LibsDir()
+	}
+	return []string{getGPUBinDir(config), libsPath}
+}
+
+// removePathEntries removes every occurrence of entries from a ;-separated
+// PATH value, comparing case-insensitively as Windows does. All other
+// entries, including empty ones, are kept in place.
+func removePathEntries(path string, entries []string) string {
+	if path == "" {
+		return path
+	}
+	parts := strings.Split(path, ";")
+	kept := slices.DeleteFunc(parts, func(part string) bool {
+		return part != "" && slices.ContainsFunc(entries, func(e string) bool {
+			return strings.EqualFold(part, e)
+		})
+	})
+	return strings.Join(kept, ";")
+}
+
+// parseRegQueryValue extracts the type and data of value name from the output
+// of `reg query <key> /v <name>`, whose value lines look like
+// "    Path    REG_EXPAND_SZ    C:\a;C:\b"
+func parseRegQueryValue(output, name string) (valueType, data string, ok bool) {
+	for line := range strings.SplitSeq(output, "\n") {
+		valueName, rest, found := strings.Cut(strings.TrimSpace(line), "    ")
+		if !found || !strings.EqualFold(valueName, name) {
+			continue
+		}
+		valueType, data, _ = strings.Cut(strings.TrimLeft(rest, " "), "    ")
+		return valueType, data, true
+	}
+	return "", "", false
+}
+
+// removePersistedPathEntries removes entries from the user PATH persisted in
+// the registry. They end up there when PATH is saved with setx from an
+// activated session; every other entry of the persisted value is kept. It
+// reports whether the persisted PATH held any of the entries.
+func removePersistedPathEntries(entries []string) (bool, error) {
+	output, err := exec.Command("reg", "query", userEnvKey, "/v", "Path").Output()
+	if err != nil {
+		// No user PATH is persisted
+		return false, nil
+	}
+	valueType, path, ok := parseRegQueryValue(string(output), "Path")
+	if !ok {
+		return false, nil
+	}
+	cleaned := removePathEntries(path, entries)
+	if cleaned == path {
+		return false, nil
+	}
+	if err := exec.Command("reg", "add", userEnvKey, "/v", "Path", "/t", valueType, "/d", cleaned, "/f").Run(); err != nil {
+		klog.Warningf("Failed to remove GPU Go entries from the user PATH: error=%v", err)
+		return false, err
+	}
+	klog.V(4).Infof("Removed GPU Go entries from the user PATH: entries=%v", entries)
+	return true, nil
+}
@@ -0,0 +1,297 @@
+package use
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"k8s.io/klog/v2"
+)
+
+// validateWSLFlags checks that --wsl is used on Windows and with a temporary
+// environment
+func validateWSLFlags(ci, longTerm, pyEnv bool) error {
+	switch {
+	case !platform.IsWindows():
+		return fmt.Errorf("--wsl is only available on Windows; inside WSL run ggo use directly")
+	case ci:
+		return cmdutil.UsageErrorf("--wsl cannot be combined with --ci")
+	case longTerm:
+		return cmdutil.UsageErrorf("--wsl cannot be combined with --long-term")
+	case pyEnv:
+		return cmdutil.UsageErrorf("--wsl cannot be combined with --conda-env or --venv")
+	}
+	return nil
+}
+
+// setupWSLEnv downloads the Linux GPU client libraries for the architecture
+// of a WSL distribution, installs them together with an env script inside
+// the distro and activates the environment for wsl.exe. With yes, the
+// commands that pass the environment to wsl.exe from the calling PowerShell
+// or CMD session are printed for eval.
+func setupWSLEnv(ctx context.Context, shareInfo *api.SharePublicInfo, rec *studio.UseConnection, distro string, yes, skipSignature bool, out *tui.Output) error {
+	target := &studio.WSLEnvTarget{Distro: distro}
+	if err := target.ResolveDistro(ctx); err != nil {
+		klog.Errorf("Failed to find WSL distribution: error=%v", err)
+		return fmt.Errorf("failed to find a WSL distribution (install one with 'wsl --install -d Ubuntu'): %w", err)
+	}
+	arch, err := target.Arch(ctx)
+	if err != nil {
+		klog.Errorf("Failed to detect WSL architecture: distro=%s error=%v", target.Distro, err)
+		return err
+	}
+
+	if _, err := ensureRemoteGPUClientLibsFor(ctx, out, shareInfo.HardwareVendor, "linux", arch, yes, skipSignature); err != nil {
+		klog.Errorf("Failed to ensure GPU client libraries: distro=%s arch=%s error=%v", target.Distro, arch, err)
+		return cmdutil.DependencyError(fmt.Errorf("failed to download GPU client libraries: %w", err))
+	}
+
+	studioName := useStudioName(rec)
+	if !yes && !out.IsJSON() {
+		out.Infof("Installing GPU client libraries into WSL distribution %s...", target.Distro)
+	}
+	result, err := target.Setup(ctx, &studio.WSLEnvOptions{
+		LibsDir:        paths.LibsDirForPlatform("linux", arch),
+		Vendor:         studio.ParseVendor(shareInfo.HardwareVendor),
+		ConnectionURL:  shareInfo.ConnectionURL,
+		Name:           studioName,
+		ConnectionName: rec.ID(),
+	})
+	if err != nil {
+		klog.Errorf("Failed to set up GPU environment in WSL: distro=%s error=%v", target.Distro, err)
+		return err
+	}
+	rec.AddDirs(result.HostDir)
+
+	// The Windows session forwarding the environment to wsl.exe is
+	// deactivated like any other
+	configDir := paths.StudioConfigDir(studioName)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	cleanPSFile := filepath.Join(configDir, "clean.ps1")
+	if err := os.WriteFile(cleanPSFile, []byte(generateCleanScriptWindows()), 0644); err != nil {
+		klog.Warningf("Failed to write PowerShell clean script: %v", err)
+	}
+	cleanBatFile := filepath.Join(configDir, "clean.bat")
+	if err := os.WriteFile(cleanBatFile, []byte(generateCleanScriptCMD()), 0644); err != nil {
+		klog.Warningf("Failed to write CMD clean script: %v", err)
+	}
+	rec.AddDirs(configDir)
+	recordUseConnection(rec)
+
+	if yes {
+		if detectWindowsShell() == shellPowerShell {
+			fmt.Print(wslEvalPowerShell(result, cleanPSFile))
+			return nil
+		}
+		callFile, err := writeCMDEvalScript("ggo-use-*.cmd", wslEvalCMD(result, cleanBatFile))
+		if err != nil {
+			klog.Errorf("Failed to write CMD activation script: error=%v", err)
+			return err
+		}
+		fmt.Printf("call \"%s\"\n", callFile)
+		return nil
+	}
+
+	if !out.IsJSON() {
+		styles := tui.DefaultStyles()
+		out.Println()
+		out.Success("GPU environment configured in WSL successfully!")
+		out.Println()
+		out.Printf("   Distribution:   %s\n", result.Distro)
+		out.Printf("   Connection URL: %s\n", shareInfo.ConnectionURL)
+		out.Printf("   Hardware:       %s\n", shareInfo.HardwareVendor)
+		out.Printf("   Log Path:       %s\n", result.EnvVars["TF_LOG_PATH"])
+		out.Println()
+
+		out.Println(styles.Subtitle.Render(i18n.T("Activate Environment")))
+		out.Println()
+		out.Printf("Would you like to open a WSL shell with the GPU environment? [Y/n]: ")
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		if isYesResponse(response) {
+			out.Println()
+			if err := launchWSLShell(result); err != nil {
+				klog.Warningf("Failed to launch WSL shell: distro=%s error=%v", result.Distro, err)
+				out.Warning("Failed to launch shell automatically.")
+				printWSLActivation(result, rec, out)
+				return nil
+			}
+			out.Println()
+			out.Println(styles.Muted.Render(i18n.T("GPU shell session ended. Environment deactivated.")))
+			out.Println()
+		} else {
+			printWSLActivation(result, rec, out)
+		}
+	}
+	return out.Render(&wslEnvResult{shareInfo: shareInfo, result: result})
+}
+
+// printWSLActivation shows the ways to activate an environment set up in WSL
+func printWSLActivation(result *studio.WSLEnvResult, rec *studio.UseConnection, out *tui.Output) {
+	styles := tui.DefaultStyles()
+	out.Println()
+	out.Println(styles.Subtitle.Render(i18n.T("Manual Activation")))
+	out.Println()
+	out.Println("Inside WSL, run:")
+	out.Printf("\n   source %s\n\n", result.EnvFile)
+	out.Println("Or open an activated WSL shell from Windows:")
+	out.Printf("\n   %s\n\n", strings.Join(wslShellArgs(result), " "))
+	out.Println("Or pass the environment to every wsl.exe command of this session:")
+	out.Println("\n   PowerShell: ggo use " + rec.ShortCode + " --wsl -y | Out-String | Invoke-Expression")
+	out.Println("   CMD:        for /f \"delims=\" %i in ('ggo use " + rec.ShortCode + " --wsl -y') do @%i")
+	out.Println()
+}
+
+// wslShellArgs returns the wsl.exe command line that opens an interactive
+// bash in the distro with the environment activated
+func wslShellArgs(result *studio.WSLEnvResult) []string {
+	return []string{"wsl", "-d", result.Distro, "--", "bash", "--rcfile", result.RCFile, "-i"}
+}
+
+// launchWSLShell opens an interactive WSL shell with the GPU environment
+func launchWSLShell(result *studio.WSLEnvResult) error {
+	args := wslShellArgs(result)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Ctrl+C reaches every process of the console; it is meant for the shell
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	go func() {
+		for range sigChan {
+		}
+	}()
+	defer signal.Stop(sigChan)
+	return cmd.Run()
+}
+
+// wslEnvNames returns the names of the environment's variables in order, as
+// a WSLENV list. Without flags, WSLENV passes the values verbatim; they are
+// Linux paths already.
+func wslEnvNames(result *studio.WSLEnvResult) string {
+	return strings.Join(studio.SortedKeys(result.EnvVars), ":") + ":"
+}
+
+// wslEvalPowerShell returns the PowerShell commands that set the
+// environment's variables in the session and list them in WSLENV, so every
+// wsl.exe started from the session sees them. _GGO_WSLENV_ADDED records the
+// names for clean.
+func wslEvalPowerShell(result *studio.WSLEnvResult, cleanFile string) string {
+	var script strings.Builder
+	fmt.Fprintf(&script, "$env:_GGO_CLEAN_FILE = \"%s\"\n\n", escapeForPowerShell(cleanFile))
+	for _, k := range studio.SortedKeys(result.EnvVars) {
+		fmt.Fprintf(&script, "$env:%s = \"%s\"\n", k, escapeForPowerShell(result.EnvVars[k]))
+	}
+	names := wslEnvNames(result)
+	fmt.Fprintf(&script, "$env:WSLENV = \"%s\" + $env:WSLENV\n", names)
+	fmt.Fprintf(&script, "$env:_GGO_WSLENV_ADDED = \"%s\" + $env:_GGO_WSLENV_ADDED\n", names)
+	script.WriteString("$env:_GGO_ACTIVE = \"1\"\n\n")
+
+	script.WriteString(powerShellWrapperFunction())
+	script.WriteString("\n")
+
+	fmt.Fprintf(&script, "[Console]::Error.WriteLine(\"GPU Go environment activated for WSL distribution %s\")\n", escapeForPowerShell(result.Distro))
+	fmt.Fprintf(&script, "[Console]::Error.WriteLine(\"Commands run with wsl.exe use the remote GPU, e.g. wsl -d %s -- python3 train.py\")\n", escapeForPowerShell(result.Distro))
+	script.WriteString("[Console]::Error.WriteLine(\"\")\n")
+	script.WriteString("[Console]::Error.WriteLine(\"To deactivate and restore your environment, run:\")\n")
+	script.WriteString("[Console]::Error.WriteLine(\"  ggo clean\")\n")
+	return script.String()
+}
+
+// wslEvalCMD returns the batch commands that do what wslEvalPowerShell
+// does in CMD, see outputEvalCommandsCMD
+func wslEvalCMD(result *studio.WSLEnvResult, cleanBat string) string {
+	var script strings.Builder
+	script.WriteString("@echo off\n")
+	script.WriteString("REM GPU Go WSL environment activation (generated by ggo use --wsl, deletes itself)\n\n")
+	fmt.Fprintf(&script, "set \"_GGO_CLEAN_FILE=%s\"\n\n", escapeForCMD(cleanBat))
+	for _, k := range studio.SortedKeys(result.EnvVars) {
+		fmt.Fprintf(&script, "set \"%s=%s\"\n", k, escapeForCMD(result.EnvVars[k]))
+	}
+	names := wslEnvNames(result)
+	fmt.Fprintf(&script, "set \"WSLENV=%s%%WSLENV%%\"\n", names)
+	fmt.Fprintf(&script, "set \"_GGO_WSLENV_ADDED=%s%%_GGO_WSLENV_ADDED%%\"\n", names)
+	script.WriteString("set \"_GGO_ACTIVE=1\"\n\n")
+
+	script.WriteString("REM Define ggo wrapper macro for automatic clean handling\n")
+	script.WriteString(cmdWrapperMacro(cleanBat) + "\n\n")
+
+	fmt.Fprintf(&script, "echo GPU Go environment activated for WSL distribution %s 1>&2\n", escapeForCMDEcho(result.Distro))
+	fmt.Fprintf(&script, "echo Commands run with wsl.exe use the remote GPU, e.g. wsl -d %s -- python3 train.py 1>&2\n", escapeForCMDEcho(result.Distro))
+	script.WriteString("echo. 1>&2\n")
+	script.WriteString("echo To deactivate and restore your environment, run: 1>&2\n")
+	script.WriteString("echo   ggo clean 1>&2\n")
+	return script.String()
+}
+
+// powerShellWSLENVCleanup returns PowerShell commands that unset the
+// variables recorded in _GGO_WSLENV_ADDED and take them out of WSLENV
+func powerShellWSLENVCleanup(indent string) string {
+	lines := []string{
+		"if ($env:_GGO_WSLENV_ADDED) {",
+		"  $ggoWSL = $env:_GGO_WSLENV_ADDED -split ':' | Where-Object { $_ }",
+		"  foreach ($ggoName in $ggoWSL) { Remove-Item \"Env:$ggoName\" -ErrorAction SilentlyContinue }",
+		"  $env:WSLENV = ($env:WSLENV -split ':' | Where-Object { $_ -and $ggoWSL -notcontains $_ }) -join ':'",
+		"  Remove-Variable ggoWSL, ggoName -ErrorAction SilentlyContinue",
+		"  Remove-Item Env:_GGO_WSLENV_ADDED -ErrorAction SilentlyContinue",
+		"}",
+	}
+	var script strings.Builder
+	for _, line := range lines {
+		script.WriteString(indent + line + "\n")
+	}
+	return script.String()
+}
+
+// cmdWSLENVCleanup returns the batch commands doing what
+// powerShellWSLENVCleanup does. Activation prepends each name followed by
+// ":" to WSLENV, so removing each "name:" keeps the rest.
+func cmdWSLENVCleanup() string {
+	var script strings.Builder
+	script.WriteString("REM Remove the variables GPU Go passed to WSL\n")
+	script.WriteString("if defined _GGO_WSLENV_ADDED (\n")
+	script.WriteString("  for %%e in (\"%_GGO_WSLENV_ADDED::=\" \"%\") do if not \"%%~e\"==\"\" (\n")
+	script.WriteString("    set \"%%~e=\"\n")
+	script.WriteString("    call set \"WSLENV=%%WSLENV:%%~e:=%%\"\n")
+	script.WriteString("  )\n")
+	script.WriteString("  set \"_GGO_WSLENV_ADDED=\"\n")
+	script.WriteString(")\n")
+	return script.String()
+}
+
+// wslEnvResult implements Renderable for an environment set up in WSL
+type wslEnvResult struct {
+	shareInfo *api.SharePublicInfo
+	result    *studio.WSLEnvResult
+}
+
+func (r *wslEnvResult) RenderJSON() any {
+	return map[string]any{
+		"success":  true,
+		"distro":   r.result.Distro,
+		"dir":      r.result.Dir,
+		"env_file": r.result.EnvFile,
+		"rc_file":  r.result.RCFile,
+		"env_vars": r.result.EnvVars,
+		"share":    r.shareInfo,
+	}
+}
+
+func (r *wslEnvResult) RenderTUI(out *tui.Output) {
+	// TUI output is handled in setupWSLEnv
+}
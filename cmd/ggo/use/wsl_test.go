@@ -0,0 +1,44 @@
+package use
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/studio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWSLEvalScripts(t *testing.T) {
+	result := &studio.WSLEnvResult{
+		Distro: "Ubuntu",
+		EnvVars: map[string]string{
+			"TF_LOG_PATH":     "/home/me/.gpugo/wsl/abc123/logs/client.log",
+			"LD_LIBRARY_PATH": "/home/me/.gpugo/wsl/abc123/libs",
+		},
+	}
+	assert.Equal(t, "LD_LIBRARY_PATH:TF_LOG_PATH:", wslEnvNames(result))
+
+	ps := wslEvalPowerShell(result, `C:\gpugo\env\clean.ps1`)
+	assert.Contains(t, ps, `$env:LD_LIBRARY_PATH = "/home/me/.gpugo/wsl/abc123/libs"`+"\n")
+	assert.Contains(t, ps, `$env:WSLENV = "LD_LIBRARY_PATH:TF_LOG_PATH:" + $env:WSLENV`+"\n")
+	assert.Contains(t, ps, `$env:_GGO_WSLENV_ADDED = "LD_LIBRARY_PATH:TF_LOG_PATH:" + $env:_GGO_WSLENV_ADDED`+"\n")
+	assert.Contains(t, ps, powerShellWrapperFunction())
+
+	cmd := wslEvalCMD(result, `C:\gpugo\env\clean.bat`)
+	lines := strings.Split(cmd, "\n")
+	assert.Equal(t, "@echo off", lines[0])
+	assert.Contains(t, lines, `set "WSLENV=LD_LIBRARY_PATH:TF_LOG_PATH:%WSLENV%"`)
+	assert.Contains(t, lines, `set "_GGO_WSLENV_ADDED=LD_LIBRARY_PATH:TF_LOG_PATH:%_GGO_WSLENV_ADDED%"`)
+	assert.Contains(t, lines, cmdWrapperMacro(`C:\gpugo\env\clean.bat`))
+	for _, line := range lines {
+		if strings.HasPrefix(line, "echo") {
+			assert.True(t, strings.HasSuffix(line, "1>&2"), line)
+		}
+	}
+}
+
+func TestCleanScriptsRemoveWSLENVEntries(t *testing.T) {
+	assert.Contains(t, generateCleanScriptCMD(), cmdWSLENVCleanup())
+	assert.Contains(t, cmdWSLENVCleanup(), `call set "WSLENV=%%WSLENV:%%~e:=%%"`)
+	assert.Contains(t, generateCleanScriptWindows(), powerShellWSLENVCleanup(""))
+}
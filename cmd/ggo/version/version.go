@@ -1,12 +1,16 @@
 package version
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 
 	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -20,36 +24,98 @@ var (
 
 // NewVersionCmd creates the version command
 func NewVersionCmd() *cobra.Command {
+	var check bool
+	var channel string
+
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Display version information",
-		Long:  `Display version and build metadata for ggo CLI.`,
+		Long: `Display version and build metadata for ggo CLI.
+
+With --check, also query the releases API for the latest ggo version on the
+release channel (the machine's deps channel unless --channel is given).`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out := cmdutil.NewOutput(outputFormat)
-			return out.Render(&versionResult{})
+			if !check {
+				return out.Render(&versionResult{})
+			}
+
+			if channel != "" {
+				parsed, err := deps.ParseChannel(channel)
+				if err != nil {
+					return cmdutil.UsageError(err)
+				}
+				channel = parsed
+			}
+
+			latest, err := deps.NewManager(deps.WithChannel(channel)).
+				LatestCLIRelease(context.Background(), runtime.GOOS, runtime.GOARCH)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to check for updates: error=%v", err)
+				return err
+			}
+
+			return out.Render(&versionResult{checked: true, latest: latest})
 		},
 	}
+	cmd.Flags().BoolVar(&check, "check", false, "Check whether a newer version is available")
+	cmd.Flags().StringVar(&channel, "channel", "", "Release channel to check (stable, beta, nightly)")
 	cmdutil.AddOutputFlag(cmd, &outputFormat)
 	return cmd
 }
 
+// UpdateAvailable reports whether latest is newer than the running version.
+// Development builds are never considered up to date.
+func UpdateAvailable(latest string) bool {
+	return latest != "" && (Version == "dev" || deps.CompareVersions(latest, Version))
+}
+
 // versionResult implements Renderable for version command
-type versionResult struct{}
+type versionResult struct {
+	checked bool
+	latest  *deps.Library
+}
+
+func (r *versionResult) latestVersion() string {
+	if r.latest == nil {
+		return ""
+	}
+	return r.latest.Version
+}
 
 func (r *versionResult) RenderJSON() any {
-	return map[string]string{
+	result := map[string]any{
 		"version":    Version,
 		"commit":     Commit,
 		"build_date": BuildDate,
 		"go_version": GoVersion,
 		"platform":   fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 	}
+	if r.checked {
+		result["latest_version"] = r.latestVersion()
+		result["update_available"] = UpdateAvailable(r.latestVersion())
+	}
+	return result
 }
 
 func (r *versionResult) RenderTUI(out *tui.Output) {
 	fmt.Printf("ggo version %s\n", Version)
-	fmt.Printf("Commit: %s\n", Commit)
-	fmt.Printf("Build Date: %s\n", BuildDate)
-	fmt.Printf("Go Version: %s\n", GoVersion)
-	fmt.Printf("Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Printf(i18n.T("Commit: %s\n"), Commit)
+	fmt.Printf(i18n.T("Build Date: %s\n"), BuildDate)
+	fmt.Printf(i18n.T("Go Version: %s\n"), GoVersion)
+	fmt.Printf(i18n.T("Platform: %s/%s\n"), runtime.GOOS, runtime.GOARCH)
+
+	if !r.checked {
+		return
+	}
+	fmt.Println()
+	switch latest := r.latestVersion(); {
+	case latest == "":
+		out.Warning("No ggo release found for this platform")
+	case UpdateAvailable(latest):
+		out.Infof("ggo %s is available, run 'ggo self-update' to install it", latest)
+	default:
+		out.Success("ggo is up to date")
+	}
 }
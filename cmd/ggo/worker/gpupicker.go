@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"k8s.io/klog/v2"
+)
+
+// gpuSelectItems describes the GPUs of an agent with their live state and
+// the workers they are assigned to
+func gpuSelectItems(gpus []api.GPUInfo, workers []api.WorkerInfo) []tui.GPUSelectItem {
+	items := make([]tui.GPUSelectItem, 0, len(gpus))
+	for _, g := range gpus {
+		item := tui.GPUSelectItem{
+			GPUID:      g.GPUID,
+			Vendor:     g.Vendor,
+			Model:      g.Model,
+			VRAMMb:     g.VRAMMb,
+			MIGEnabled: g.MIGEnabled,
+			Health:     g.Health,
+		}
+		if m := g.Metrics; m != nil {
+			total := m.VRAMTotalMb
+			if total == 0 {
+				total = g.VRAMMb
+			}
+			item.HasMetrics = true
+			item.Utilization = m.Utilization
+			item.VRAMFreeMb = max(total-m.VRAMUsedMb, 0)
+		}
+		for _, p := range g.Partitions {
+			item.MIGInstances++
+			if p.WorkerID != "" {
+				item.MIGInUse++
+			}
+		}
+		for _, w := range workers {
+			if !slices.ContainsFunc(w.GPUIDs, func(id string) bool { return strings.EqualFold(id, g.GPUID) }) {
+				continue
+			}
+			item.Workers = append(item.Workers, w.Name)
+			// Workers on MIG instances leave the rest of the GPU to others
+			if w.MIGProfile == "" && !g.MIGEnabled {
+				item.FullyAllocated = true
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// checkGPUSelection returns why the selected GPUs must not be allocated, and
+// warnings about GPUs that can be allocated only after confirmation
+func checkGPUSelection(items []tui.GPUSelectItem, selected []string) (blocked error, warnings []string) {
+	var missing []string
+	for _, item := range items {
+		if !slices.Contains(selected, item.GPUID) {
+			continue
+		}
+		if slices.Contains(item.Health, api.GPUHealthNotDetected) {
+			missing = append(missing, item.GPUID)
+			continue
+		}
+		if item.FullyAllocated {
+			warnings = append(warnings, i18n.Tf("%s is already allocated to %s", item.GPUID, strings.Join(item.Workers, ", ")))
+		}
+		if len(item.Health) > 0 {
+			warnings = append(warnings, i18n.Tf("%s is unhealthy: %s", item.GPUID, strings.Join(item.Health, ", ")))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the agent does not detect %s; pick other GPUs", strings.Join(missing, ", ")), nil
+	}
+	return nil, warnings
+}
+
+// selectWorkerGPUs lets the user pick GPUs of an agent, showing their
+// utilization, free VRAM, MIG layout, assigned workers and health. GPUs the
+// agent does not detect cannot be picked; picking GPUs in use by other
+// workers or flagged unhealthy needs confirmation.
+func selectWorkerGPUs(ctx context.Context, client *api.Client, agent *api.AgentInfo) ([]string, error) {
+	workers := agent.Workers
+	if resp, err := client.ListWorkers(ctx, agent.AgentID, ""); err != nil {
+		klog.Warningf("Failed to list workers of agent, GPU assignments may be incomplete: agent_id=%s error=%v", agent.AgentID, err)
+	} else {
+		workers = resp.Workers
+	}
+	items := gpuSelectItems(agent.GPUs, workers)
+	styles := tui.DefaultStyles()
+
+	for {
+		gpuIDs, err := tui.MultiSelectPrompt("Select GPU(s) to allocate:", tui.FormatGPUOptions(items))
+		if err != nil {
+			return nil, err
+		}
+		blocked, warnings := checkGPUSelection(items, gpuIDs)
+		if blocked != nil {
+			fmt.Println(styles.Error.Render("✗ " + blocked.Error()))
+			continue
+		}
+		if len(warnings) == 0 {
+			return gpuIDs, nil
+		}
+		fmt.Println()
+		for _, w := range warnings {
+			fmt.Println(styles.Warning.Render("! " + w))
+		}
+		confirmed, err := tui.ConfirmPrompt("Allocate the selected GPUs anyway?")
+		if err != nil {
+			return nil, err
+		}
+		if confirmed {
+			return gpuIDs, nil
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGPUSelectItems(t *testing.T) {
+	gpus := []api.GPUInfo{
+		{GPUID: "gpu-0", Model: "A100", VRAMMb: 40960, Metrics: &api.GPUMetrics{Utilization: 87, VRAMUsedMb: 30960}},
+		{GPUID: "gpu-1", Model: "A100", VRAMMb: 40960, MIGEnabled: true, Partitions: []api.GPUPartition{
+			{UUID: "MIG-1", Profile: "1g.10gb", WorkerID: "w2"},
+			{UUID: "MIG-2", Profile: "1g.10gb"},
+		}},
+		{GPUID: "gpu-2", Model: "A100", VRAMMb: 40960, Health: []string{api.GPUHealthOverheating}},
+	}
+	workers := []api.WorkerInfo{
+		{Name: "trainer", GPUIDs: []string{"GPU-0"}},
+		{Name: "notebook", GPUIDs: []string{"gpu-1"}, MIGProfile: "1g.10gb"},
+	}
+
+	items := gpuSelectItems(gpus, workers)
+	require.Len(t, items, 3)
+
+	assert.True(t, items[0].HasMetrics)
+	assert.Equal(t, int64(10000), items[0].VRAMFreeMb)
+	assert.Equal(t, []string{"trainer"}, items[0].Workers)
+	assert.True(t, items[0].FullyAllocated)
+
+	assert.Equal(t, 2, items[1].MIGInstances)
+	assert.Equal(t, 1, items[1].MIGInUse)
+	assert.Equal(t, []string{"notebook"}, items[1].Workers)
+	assert.False(t, items[1].FullyAllocated, "MIG workers leave room on the GPU")
+
+	assert.False(t, items[2].HasMetrics)
+	assert.Empty(t, items[2].Workers)
+}
+
+func TestCheckGPUSelection(t *testing.T) {
+	gpus := []api.GPUInfo{
+		{GPUID: "gpu-0"},
+		{GPUID: "gpu-1"},
+		{GPUID: "gpu-2", Health: []string{api.GPUHealthOverheating}},
+		{GPUID: "gpu-3", Health: []string{api.GPUHealthNotDetected}},
+	}
+	items := gpuSelectItems(gpus, []api.WorkerInfo{{Name: "trainer", GPUIDs: []string{"gpu-1"}}})
+
+	blocked, warnings := checkGPUSelection(items, []string{"gpu-0"})
+	assert.NoError(t, blocked)
+	assert.Empty(t, warnings)
+
+	blocked, warnings = checkGPUSelection(items, []string{"gpu-1", "gpu-2"})
+	assert.NoError(t, blocked)
+	assert.Len(t, warnings, 2)
+
+	blocked, _ = checkGPUSelection(items, []string{"gpu-0", "gpu-3"})
+	assert.ErrorContains(t, blocked, "gpu-3")
+}
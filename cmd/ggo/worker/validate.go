@@ -0,0 +1,315 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/tui"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+// Checks of a proposed worker config run by ggo itself
+const (
+	checkAgent = "agent"
+	checkGPUs  = "gpus"
+	checkPort  = "port"
+	checkVRAM  = "vram"
+	// checkServer stands for the server's validation when it cannot run
+	checkServer = "server"
+)
+
+const agentStatusOnline = "online"
+
+// Where a validation check ran
+const (
+	sourceLocal  = "local"
+	sourceServer = "server"
+)
+
+// lowVRAMFraction is the share of a GPU's VRAM below which free VRAM is
+// reported as low
+const lowVRAMFraction = 0.1
+
+// migMemoryPattern extracts the memory of a MIG profile such as 1g.10gb
+var migMemoryPattern = regexp.MustCompile(`(\d+)gb`)
+
+// portInUse reports whether port is taken on this machine
+var portInUse = func(port int) error {
+	_, err := utils.CheckPortAvailability(port)
+	return err
+}
+
+// validationCheck is a check of a proposed worker config and where it ran
+type validationCheck struct {
+	api.WorkerValidationCheck
+	Source string `json:"source"`
+}
+
+// workerValidation is the report of 'ggo worker create --dry-run'. Valid is
+// false if any check failed.
+type workerValidation struct {
+	Valid  bool              `json:"valid"`
+	Checks []validationCheck `json:"checks"`
+}
+
+func (v *workerValidation) add(source string, checks ...api.WorkerValidationCheck) {
+	for _, c := range checks {
+		v.Checks = append(v.Checks, validationCheck{WorkerValidationCheck: c, Source: source})
+		if c.Status == api.ValidationFail {
+			v.Valid = false
+		}
+	}
+}
+
+// failed counts the failed checks
+func (v *workerValidation) failed() int {
+	n := 0
+	for _, c := range v.Checks {
+		if c.Status == api.ValidationFail {
+			n++
+		}
+	}
+	return n
+}
+
+// validateWorkerCreate checks req against the agent's GPUs and workers, then
+// has the server validate it, without creating anything. Errors are returned
+// only when the server cannot be asked.
+func validateWorkerCreate(ctx context.Context, client *api.Client, req *api.WorkerCreateRequest) (*workerValidation, error) {
+	v := &workerValidation{Valid: true}
+
+	agent, err := client.GetAgent(ctx, req.AgentID)
+	if err != nil {
+		klog.Warningf("Failed to get agent for validation: agent_id=%s error=%v", req.AgentID, err)
+		v.add(sourceLocal, api.WorkerValidationCheck{
+			Name: checkAgent, Status: api.ValidationFail, Detail: fmt.Sprintf("cannot get agent %s: %v", req.AgentID, err),
+		})
+	} else {
+		workers := agent.Workers
+		if resp, err := client.ListWorkers(ctx, agent.AgentID, ""); err != nil {
+			klog.Warningf("Failed to list workers of agent, port and GPU checks may be incomplete: agent_id=%s error=%v", agent.AgentID, err)
+		} else {
+			workers = resp.Workers
+		}
+		v.add(sourceLocal, localWorkerChecks(agent, workers, req, isLocalAgent(agent.AgentID))...)
+	}
+
+	resp, err := client.ValidateWorker(ctx, req)
+	var statusErr *api.StatusError
+	switch {
+	case api.IsNotFound(err):
+		v.add(sourceServer, api.WorkerValidationCheck{
+			Name: checkServer, Status: api.ValidationSkip, Detail: "the server does not support validation; only local checks ran",
+		})
+	case errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusBadRequest && statusErr.StatusCode < http.StatusInternalServerError:
+		// The server rejects the config outright
+		v.add(sourceServer, api.WorkerValidationCheck{
+			Name: checkServer, Status: api.ValidationFail, Detail: strings.TrimSpace(statusErr.Body),
+		})
+	case err != nil:
+		return nil, fmt.Errorf("failed to validate worker config: %w", err)
+	default:
+		v.add(sourceServer, resp.Checks...)
+		if !resp.Valid && v.Valid {
+			// A verdict without a failed check still counts
+			v.add(sourceServer, api.WorkerValidationCheck{Name: checkServer, Status: api.ValidationFail, Detail: "rejected by the server"})
+		}
+	}
+	return v, nil
+}
+
+// isLocalAgent reports whether agentID is the agent registered on this
+// machine, whose ports can be checked directly
+func isLocalAgent(agentID string) bool {
+	cfg, err := config.NewManager("", "").LoadConfig()
+	return err == nil && cfg != nil && cfg.AgentID == agentID
+}
+
+// localWorkerChecks checks req against the agent's GPUs and the workers it
+// runs. With local set, the port is also checked on this machine.
+func localWorkerChecks(agent *api.AgentInfo, workers []api.WorkerInfo, req *api.WorkerCreateRequest, local bool) []api.WorkerValidationCheck {
+	agentCheck := api.WorkerValidationCheck{Name: checkAgent, Status: api.ValidationPass, Detail: fmt.Sprintf("%s (%s)", agent.Hostname, agent.Status)}
+	if agent.Status != agentStatusOnline {
+		agentCheck.Status = api.ValidationWarn
+		agentCheck.Detail = fmt.Sprintf("%s is %s; the worker starts once the agent is online", agent.Hostname, agent.Status)
+	}
+	return []api.WorkerValidationCheck{
+		agentCheck,
+		checkWorkerGPUs(agent, workers, req.GPUIDs),
+		checkWorkerPort(agent, workers, req.ListenPort, local),
+		checkWorkerVRAM(agent, req),
+	}
+}
+
+// findGPU returns the GPU of agent with the given ID
+func findGPU(agent *api.AgentInfo, id string) *api.GPUInfo {
+	for i := range agent.GPUs {
+		if strings.EqualFold(agent.GPUs[i].GPUID, id) {
+			return &agent.GPUs[i]
+		}
+	}
+	return nil
+}
+
+func checkWorkerGPUs(agent *api.AgentInfo, workers []api.WorkerInfo, gpuIDs []string) api.WorkerValidationCheck {
+	check := api.WorkerValidationCheck{Name: checkGPUs}
+	if len(gpuIDs) == 0 {
+		check.Status, check.Detail = api.ValidationFail, "no GPUs given"
+		return check
+	}
+
+	var missing, selected []string
+	for _, id := range gpuIDs {
+		if gpu := findGPU(agent, id); gpu != nil {
+			selected = append(selected, gpu.GPUID)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		check.Status = api.ValidationFail
+		check.Detail = fmt.Sprintf("agent %s has no GPU %s", agent.Hostname, strings.Join(missing, ", "))
+		return check
+	}
+
+	blocked, warnings := checkGPUSelection(gpuSelectItems(agent.GPUs, workers), selected)
+	switch {
+	case blocked != nil:
+		check.Status, check.Detail = api.ValidationFail, blocked.Error()
+	case len(warnings) > 0:
+		check.Status, check.Detail = api.ValidationWarn, strings.Join(warnings, "; ")
+	default:
+		check.Status, check.Detail = api.ValidationPass, strings.Join(selected, ", ")
+	}
+	return check
+}
+
+func checkWorkerPort(agent *api.AgentInfo, workers []api.WorkerInfo, port int, local bool) api.WorkerValidationCheck {
+	check := api.WorkerValidationCheck{Name: checkPort, Status: api.ValidationPass, Detail: strconv.Itoa(port)}
+	if port < 1 || port > 65535 {
+		check.Status, check.Detail = api.ValidationFail, fmt.Sprintf("port %d is out of range 1-65535", port)
+		return check
+	}
+	for _, w := range workers {
+		if w.ListenPort == port && (w.AgentID == "" || w.AgentID == agent.AgentID) {
+			check.Status, check.Detail = api.ValidationFail, fmt.Sprintf("port %d is used by worker %s", port, w.Name)
+			return check
+		}
+	}
+	if local {
+		if err := portInUse(port); err != nil {
+			check.Status, check.Detail = api.ValidationFail, fmt.Sprintf("%v on this machine", err)
+		}
+	}
+	return check
+}
+
+// checkWorkerVRAM checks that a MIG instance fits its GPU, or that the GPUs
+// of a worker using whole GPUs have VRAM left
+func checkWorkerVRAM(agent *api.AgentInfo, req *api.WorkerCreateRequest) api.WorkerValidationCheck {
+	check := api.WorkerValidationCheck{Name: checkVRAM, Status: api.ValidationPass}
+	var gpus []*api.GPUInfo
+	for _, id := range req.GPUIDs {
+		if gpu := findGPU(agent, id); gpu != nil {
+			gpus = append(gpus, gpu)
+		}
+	}
+	if len(gpus) == 0 {
+		check.Status, check.Detail = api.ValidationSkip, "no known GPUs to check"
+		return check
+	}
+
+	if req.MIGProfile != "" {
+		gpu := gpus[0]
+		if !gpu.MIGEnabled {
+			check.Status = api.ValidationFail
+			check.Detail = fmt.Sprintf("MIG mode is not enabled on %s (nvidia-smi -i %d -mig 1)", gpu.GPUID, gpu.GPUIndex)
+			return check
+		}
+		m := migMemoryPattern.FindStringSubmatch(req.MIGProfile)
+		if m == nil || gpu.VRAMMb == 0 {
+			check.Status, check.Detail = api.ValidationSkip, "GPU memory unknown"
+			return check
+		}
+		gb, _ := strconv.ParseInt(m[1], 10, 64)
+		if gb*1024 > gpu.VRAMMb {
+			check.Status = api.ValidationFail
+			check.Detail = fmt.Sprintf("MIG profile %s needs %d GB, %s has %d MiB", req.MIGProfile, gb, gpu.GPUID, gpu.VRAMMb)
+			return check
+		}
+		check.Detail = fmt.Sprintf("%s of %d MiB on %s", req.MIGProfile, gpu.VRAMMb, gpu.GPUID)
+		return check
+	}
+
+	var total int64
+	var low []string
+	for _, gpu := range gpus {
+		total += gpu.VRAMMb
+		m := gpu.Metrics
+		if m == nil {
+			continue
+		}
+		size := m.VRAMTotalMb
+		if size == 0 {
+			size = gpu.VRAMMb
+		}
+		free := max(size-m.VRAMUsedMb, 0)
+		if size > 0 && float64(free) < float64(size)*lowVRAMFraction {
+			low = append(low, fmt.Sprintf("%s has %d of %d MiB free", gpu.GPUID, free, size))
+		}
+	}
+	if len(low) > 0 {
+		slices.Sort(low)
+		check.Status, check.Detail = api.ValidationWarn, strings.Join(low, "; ")
+		return check
+	}
+	check.Detail = fmt.Sprintf("%d MiB on %d GPU(s)", total, len(gpus))
+	return check
+}
+
+// RenderJSON returns the report
+func (v *workerValidation) RenderJSON() any {
+	return v
+}
+
+// RenderTUI prints a line per check
+func (v *workerValidation) RenderTUI(out *tui.Output) {
+	styles := tui.DefaultStyles()
+	out.Println()
+	out.Println(styles.Subtitle.Render(i18n.T("Worker Config Validation")))
+	out.Println()
+	for _, c := range v.Checks {
+		var icon string
+		switch c.Status {
+		case api.ValidationPass:
+			icon = styles.Success.Render("✓")
+		case api.ValidationWarn:
+			icon = styles.Warning.Render("!")
+		case api.ValidationFail:
+			icon = styles.Error.Render("✗")
+		default:
+			icon = styles.Muted.Render("-")
+		}
+		line := fmt.Sprintf("  %s %s %s", icon, c.Name, tui.Muted("("+c.Source+")"))
+		if c.Detail != "" {
+			line += tui.Muted(" · " + c.Detail)
+		}
+		out.Println(line)
+	}
+	out.Println()
+	if v.Valid {
+		out.Success("Worker config is valid; nothing was created")
+	} else {
+		out.Errorf("%d check(s) failed; nothing was created", v.failed())
+	}
+}
@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkStatuses(checks []api.WorkerValidationCheck) map[string]string {
+	statuses := make(map[string]string, len(checks))
+	for _, c := range checks {
+		statuses[c.Name] = c.Status
+	}
+	return statuses
+}
+
+func TestLocalWorkerChecks(t *testing.T) {
+	agent := &api.AgentInfo{
+		AgentID:  "agent-1",
+		Hostname: "gpu-box",
+		Status:   agentStatusOnline,
+		GPUs: []api.GPUInfo{
+			{GPUID: "gpu-0", VRAMMb: 40960, Metrics: &api.GPUMetrics{VRAMUsedMb: 39960}},
+			{GPUID: "gpu-1", VRAMMb: 40960, MIGEnabled: true},
+			{GPUID: "gpu-2", VRAMMb: 24576, Health: []string{api.GPUHealthNotDetected}},
+		},
+	}
+	workers := []api.WorkerInfo{{Name: "trainer", AgentID: "agent-1", GPUIDs: []string{"gpu-0"}, ListenPort: 9001}}
+
+	t.Run("valid MIG worker", func(t *testing.T) {
+		req := &api.WorkerCreateRequest{AgentID: "agent-1", GPUIDs: []string{"GPU-1"}, ListenPort: 9002, MIGProfile: "3g.20gb"}
+		checks := localWorkerChecks(agent, workers, req, false)
+		assert.Equal(t, map[string]string{
+			checkAgent: api.ValidationPass,
+			checkGPUs:  api.ValidationPass,
+			checkPort:  api.ValidationPass,
+			checkVRAM:  api.ValidationPass,
+		}, checkStatuses(checks))
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		req := &api.WorkerCreateRequest{AgentID: "agent-1", GPUIDs: []string{"gpu-0"}, ListenPort: 9001}
+		statuses := checkStatuses(localWorkerChecks(agent, workers, req, false))
+		assert.Equal(t, api.ValidationWarn, statuses[checkGPUs], "allocated GPUs need confirmation, not a failure")
+		assert.Equal(t, api.ValidationFail, statuses[checkPort])
+		assert.Equal(t, api.ValidationWarn, statuses[checkVRAM], "1000 of 40960 MiB free")
+	})
+
+	t.Run("missing and undetected GPUs", func(t *testing.T) {
+		req := &api.WorkerCreateRequest{GPUIDs: []string{"gpu-9"}, ListenPort: 9002}
+		assert.Equal(t, api.ValidationFail, checkStatuses(localWorkerChecks(agent, workers, req, false))[checkGPUs])
+		req.GPUIDs = []string{"gpu-2"}
+		assert.Equal(t, api.ValidationFail, checkStatuses(localWorkerChecks(agent, workers, req, false))[checkGPUs])
+	})
+
+	t.Run("MIG profile too large", func(t *testing.T) {
+		req := &api.WorkerCreateRequest{GPUIDs: []string{"gpu-1"}, ListenPort: 9002, MIGProfile: "7g.80gb"}
+		check := checkWorkerVRAM(agent, req)
+		assert.Equal(t, api.ValidationFail, check.Status)
+		assert.Contains(t, check.Detail, "needs 80 GB")
+
+		req.GPUIDs = []string{"gpu-0"}
+		req.MIGProfile = "1g.10gb"
+		assert.Contains(t, checkWorkerVRAM(agent, req).Detail, "MIG mode is not enabled")
+	})
+
+	t.Run("port taken on this machine", func(t *testing.T) {
+		orig := portInUse
+		portInUse = func(port int) error { return errors.New("port 9002 is already in use") }
+		t.Cleanup(func() { portInUse = orig })
+
+		req := &api.WorkerCreateRequest{GPUIDs: []string{"gpu-1"}, ListenPort: 9002}
+		assert.Equal(t, api.ValidationPass, checkWorkerPort(agent, workers, 9002, false).Status, "remote agents' ports are not probed")
+		check := checkWorkerPort(agent, workers, req.ListenPort, true)
+		require.Equal(t, api.ValidationFail, check.Status)
+		assert.Contains(t, check.Detail, "on this machine")
+	})
+
+	t.Run("offline agent", func(t *testing.T) {
+		offline := *agent
+		offline.Status = "offline"
+		req := &api.WorkerCreateRequest{GPUIDs: []string{"gpu-1"}, ListenPort: 9002}
+		assert.Equal(t, api.ValidationWarn, checkStatuses(localWorkerChecks(&offline, workers, req, false))[checkAgent])
+	})
+}
+
+func TestWorkerValidation(t *testing.T) {
+	v := &workerValidation{Valid: true}
+	v.add(sourceLocal, api.WorkerValidationCheck{Name: checkGPUs, Status: api.ValidationWarn})
+	assert.True(t, v.Valid, "warnings keep the config valid")
+	v.add(sourceServer, api.WorkerValidationCheck{Name: "license", Status: api.ValidationFail})
+	assert.False(t, v.Valid)
+	assert.Equal(t, 1, v.failed())
+	assert.Equal(t, sourceServer, v.Checks[1].Source)
+}
+
+func TestValidateWorkerCreate_OldServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/agents/agent-1":
+			_ = json.NewEncoder(w).Encode(api.AgentInfo{AgentID: "agent-1", Status: agentStatusOnline, GPUs: []api.GPUInfo{{GPUID: "gpu-0"}}})
+		case "/api/v1/workers":
+			_ = json.NewEncoder(w).Encode(api.WorkerListResponse{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(api.WithBaseURL(server.URL))
+	v, err := validateWorkerCreate(context.Background(), client, &api.WorkerCreateRequest{
+		AgentID: "agent-1", GPUIDs: []string{"gpu-0"}, ListenPort: 9001,
+	})
+	require.NoError(t, err)
+	assert.True(t, v.Valid)
+	last := v.Checks[len(v.Checks)-1]
+	assert.Equal(t, checkServer, last.Name)
+	assert.Equal(t, api.ValidationSkip, last.Status, "servers without validation leave the local checks")
+}
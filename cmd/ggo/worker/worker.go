@@ -4,15 +4,23 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/NexusGPU/gpu-go/cmd/ggo/auth"
 	"github.com/NexusGPU/gpu-go/cmd/ggo/cmdutil"
+	"github.com/NexusGPU/gpu-go/internal/agent"
 	"github.com/NexusGPU/gpu-go/internal/api"
 	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/i18n"
+	"github.com/NexusGPU/gpu-go/internal/platform"
 	"github.com/NexusGPU/gpu-go/internal/tui"
 	"github.com/NexusGPU/gpu-go/internal/utils"
 	"github.com/spf13/cobra"
@@ -25,6 +33,10 @@ var (
 	outputFormat string
 )
 
+// migProfilePattern matches MIG profiles such as 1g.10gb, 1c.3g.20gb and
+// 1g.10gb+me
+var migProfilePattern = regexp.MustCompile(`^(\d+c\.)?\d+g\.\d+gb(\+[a-z]+)?$`)
+
 // NewWorkerCmd creates the worker command
 func NewWorkerCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -47,11 +59,14 @@ func NewWorkerCmd() *cobra.Command {
 	cmdutil.AddOutputFlag(cmd, &outputFormat)
 
 	cmd.AddCommand(newWorkerListCmd())
-	cmd.AddCommand(newWorkerCreateCmd())
+	cmd.AddCommand(cmdutil.Audited(newWorkerCreateCmd()))
 	cmd.AddCommand(newWorkerGetCmd())
-	cmd.AddCommand(newWorkerUpdateCmd())
-	cmd.AddCommand(newWorkerDeleteCmd())
-	cmd.AddCommand(newWorkerShareCmd())
+	cmd.AddCommand(cmdutil.Audited(newWorkerUpdateCmd()))
+	cmd.AddCommand(cmdutil.Audited(newWorkerDeleteCmd()))
+	cmd.AddCommand(cmdutil.Audited(newWorkerShareCmd()))
+	cmd.AddCommand(newWorkerCrashesCmd())
+	cmd.AddCommand(newWorkerLogsCmd())
+	cmd.AddCommand(cmdutil.Audited(newWorkerSessionsCmd()))
 
 	return cmd
 }
@@ -66,12 +81,15 @@ func getClient() *api.Client {
 		token = os.Getenv("GPU_GO_USER_TOKEN")
 	}
 
-	// Try agent config secret before PAT token
-	if token == "" {
+	// Try agent config secret before PAT token. The agent is registered with
+	// the default endpoint, so its secret is never sent to a profile's one.
+	agentSecret := false
+	if token == "" && platform.DefaultPaths().ProfileDir() == "" {
 		cfgMgr := config.NewManager("", "")
 		if agentCfg, err := cfgMgr.LoadConfig(); err == nil && agentCfg != nil && agentCfg.AgentSecret != "" {
 			klog.V(2).Infof("Using agent secret for authentication")
 			token = agentCfg.AgentSecret
+			agentSecret = true
 		}
 	}
 
@@ -81,10 +99,16 @@ func getClient() *api.Client {
 			token = savedToken
 		}
 	}
-	return api.NewClient(
+	opts := []api.ClientOption{
 		api.WithBaseURL(serverURL),
 		api.WithUserToken(token),
-	)
+	}
+	// Scope errors name the missing scope; user tokens can be recreated
+	// with it, the agent secret cannot
+	if !agentSecret {
+		opts = append(opts, api.WithScopeHint(auth.ScopeHint))
+	}
+	return api.NewClient(opts...)
 }
 
 func getOutput() *tui.Output {
@@ -94,16 +118,33 @@ func getOutput() *tui.Output {
 func newWorkerListCmd() *cobra.Command {
 	var agentID string
 	var hostname string
+	var team string
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all workers",
-		Long:  `List all GPU workers for the current user.`,
+		Long: `List all GPU workers for the current user.
+
+With --team, list the workers shared with a team you belong to instead; use
+one with 'ggo use --team <team> --worker <name>'.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := getClient()
 			ctx := context.Background()
 			out := getOutput()
 
+			if team != "" {
+				if agentID != "" || hostname != "" {
+					return cmdutil.UsageErrorf("--team cannot be combined with --agent-id or --hostname")
+				}
+				resp, err := client.ListTeamWorkers(ctx, team)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to list team workers: team=%s error=%v", team, err)
+					return err
+				}
+				return out.Render(&teamWorkerListResult{team: team, workers: resp.Workers})
+			}
+
 			resp, err := client.ListWorkers(ctx, agentID, hostname)
 			if err != nil {
 				cmd.SilenceUsage = true
@@ -117,10 +158,54 @@ func newWorkerListCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&agentID, "agent-id", "", "Filter by agent ID")
 	cmd.Flags().StringVar(&hostname, "hostname", "", "Filter by hostname")
+	cmd.Flags().StringVar(&team, "team", "", "List the workers shared with this team")
 
 	return cmd
 }
 
+// teamWorkerListResult implements Renderable for worker list --team
+type teamWorkerListResult struct {
+	team    string
+	workers []api.TeamWorker
+}
+
+func (r *teamWorkerListResult) RenderJSON() any {
+	return tui.NewListResult(r.workers)
+}
+
+func (r *teamWorkerListResult) RenderTUI(out *tui.Output) {
+	if len(r.workers) == 0 {
+		out.Infof("No workers shared with team %s", r.team)
+		return
+	}
+
+	styles := tui.DefaultStyles()
+	var rows [][]string
+	for _, w := range r.workers {
+		statusIcon := tui.StatusIcon(w.Status)
+		statusStyled := styles.StatusStyle(w.Status).Render(statusIcon + " " + w.Status)
+		route := "direct"
+		if w.Relay {
+			route = "relay"
+		}
+		rows = append(rows, []string{
+			w.Name,
+			w.WorkerID,
+			w.Owner,
+			w.HardwareVendor,
+			statusStyled,
+			route,
+		})
+	}
+
+	table := tui.NewTable().
+		Headers("NAME", "WORKER ID", "OWNER", "VENDOR", "STATUS", "ROUTE").
+		Rows(rows)
+
+	out.Println(table.String())
+	out.Println(styles.Muted.Render(i18n.Tf("Connect with: ggo use --team %s --worker <name>", r.team)))
+}
+
 // workerListResult implements Renderable for worker list
 type workerListResult struct {
 	workers []api.WorkerInfo
@@ -175,6 +260,11 @@ func newWorkerCreateCmd() *cobra.Command {
 	var gpuIDs []string
 	var listenPort int
 	var enabled bool
+	var envFlags []string
+	var haPeer string
+	var migProfile string
+	var warm bool
+	var dryRun bool
 
 	cmd := &cobra.Command{
 		Use:   "create",
@@ -182,8 +272,56 @@ func newWorkerCreateCmd() *cobra.Command {
 		Long: `Create a new GPU worker on a remote server.
 
 If required parameters (--agent-id, --name, --gpu-ids) are not provided,
-the command enters interactive TUI mode to guide you through the setup.`,
+the command enters interactive TUI mode to guide you through the setup. The
+GPU picker shows each GPU's utilization, free VRAM, MIG layout, the workers it
+is assigned to and the health the agent reports. GPUs the agent does not
+detect cannot be picked, and picking GPUs in use or unhealthy asks first.
+
+With --ha-peer, a second agent stands by for the worker. When the primary agent
+stops sending heartbeats, the standby starts an equivalent worker on equivalent
+GPUs and the platform repoints the worker's shares to it. 'ggo worker get'
+shows which agent is serving.
+
+With --mig, the worker runs on a MIG instance of the given profile that the
+agent creates on the worker's only GPU, and destroys once the worker stopped.
+MIG mode must be enabled on the GPU ('nvidia-smi -i <index> -mig 1'); 'ggo agent
+get' lists MIG-capable GPUs and their instances.
+
+With --warm, the agent keeps the worker running while it is disabled: the
+process is up with its GPU context created and it listens, but refuses
+sessions. Enabling the worker, which the platform also does when one of its
+shares is first resolved, then serves clients without a cold start. A warm
+worker holds its GPU memory while it waits. The agent's connection proxy
+(ggo agent start --proxy) holds the port and refuses the sessions; without
+it the worker is stopped while disabled as usual.
+
+With --dry-run, nothing is created. The config is checked against the agent
+(the GPUs exist and are free, the port is not taken by another worker or, for
+this machine's agent, by another process, a MIG instance fits its GPU) and
+validated by the server (for example, that the license allows the GPU count),
+and a report is printed. The command fails if any check fails, so provisioning
+scripts can validate a config before applying it.`,
+		Example: `  # Create a worker with NCCL and proxy settings
+  ggo worker create --agent-id agent_xxx --name trainer --gpu-ids gpu-0 \
+    --env NCCL_DEBUG=INFO --env HTTPS_PROXY=http://proxy:3128
+
+  # Create a worker that fails over to a standby agent
+  ggo worker create --agent-id agent_xxx --name inference --gpu-ids gpu-0 --ha-peer agent_yyy
+
+  # Create a worker on a 1g.10gb MIG instance of an A100
+  ggo worker create --agent-id agent_xxx --name notebook --gpu-ids gpu-0 --mig 1g.10gb
+
+  # Create a disabled worker kept warm until its share is first used
+  ggo worker create --agent-id agent_xxx --name demo --gpu-ids gpu-0 --enabled=false --warm
+
+  # Validate a config in a provisioning script without creating the worker
+  ggo worker create --agent-id agent_xxx --name trainer --gpu-ids gpu-0,gpu-1 --port 9002 --dry-run -o json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := parseEnvFlags(envFlags)
+			if err != nil {
+				return err
+			}
+
 			client := getClient()
 			ctx := context.Background()
 			out := getOutput()
@@ -199,7 +337,6 @@ the command enters interactive TUI mode to guide you through the setup.`,
 				}
 
 				// Enter interactive TUI mode
-				var err error
 				agentID, name, gpuIDs, listenPort, enabled, err = interactiveWorkerCreate(ctx, client, cmd)
 				if err != nil {
 					cmd.SilenceUsage = true
@@ -213,6 +350,38 @@ the command enters interactive TUI mode to guide you through the setup.`,
 				GPUIDs:     gpuIDs,
 				ListenPort: listenPort,
 				Enabled:    enabled,
+				Env:        env,
+				HAPeer:     haPeer,
+				MIGProfile: migProfile,
+				Warm:       warm,
+			}
+			if haPeer != "" && haPeer == agentID {
+				return cmdutil.UsageErrorf("--ha-peer must be a different agent than --agent-id")
+			}
+			if migProfile != "" {
+				if !migProfilePattern.MatchString(migProfile) {
+					return cmdutil.UsageErrorf("invalid --mig profile %q (e.g. 1g.10gb, 3g.40gb)", migProfile)
+				}
+				if len(gpuIDs) != 1 {
+					return fmt.Errorf("--mig needs exactly one GPU in --gpu-ids")
+				}
+			}
+
+			if dryRun {
+				report, err := validateWorkerCreate(ctx, client, req)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to validate worker config: agent_id=%s error=%v", agentID, err)
+					return err
+				}
+				if err := out.Render(report); err != nil {
+					return err
+				}
+				if !report.Valid {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("worker config is invalid: %d check(s) failed", report.failed())
+				}
+				return nil
 			}
 
 			resp, err := client.CreateWorker(ctx, req)
@@ -231,6 +400,11 @@ the command enters interactive TUI mode to guide you through the setup.`,
 	cmd.Flags().StringSliceVar(&gpuIDs, "gpu-ids", nil, "GPU IDs to allocate (required, or use interactive mode)")
 	cmd.Flags().IntVar(&listenPort, "port", 9001, "Listen port")
 	cmd.Flags().BoolVar(&enabled, "enabled", true, "Enable worker")
+	cmd.Flags().StringArrayVarP(&envFlags, "env", "e", nil, "Extra worker environment variable KEY=VALUE (repeatable)")
+	cmd.Flags().StringVar(&haPeer, "ha-peer", "", "Standby agent ID that takes over the worker when the agent fails")
+	cmd.Flags().StringVar(&migProfile, "mig", "", "Run the worker on a MIG instance of this profile (e.g. 1g.10gb)")
+	cmd.Flags().BoolVar(&warm, "warm", false, "Keep the worker running while disabled, so enabling it starts serving at once")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate the config and print a report without creating the worker")
 
 	return cmd
 }
@@ -243,8 +417,8 @@ func interactiveWorkerCreate(ctx context.Context, client *api.Client, cmd *cobra
 	totalSteps := 5
 
 	fmt.Println()
-	fmt.Println(styles.Title.Render("🚀 Create GPU Worker"))
-	fmt.Println(styles.Muted.Render("Follow the steps below to configure your new worker"))
+	fmt.Println(styles.Title.Render(i18n.T("🚀 Create GPU Worker")))
+	fmt.Println(styles.Muted.Render(i18n.T("Follow the steps below to configure your new worker")))
 
 	// Step 1: Enter worker name
 	tui.StepHeader(1, totalSteps, "Worker Name")
@@ -296,18 +470,7 @@ func interactiveWorkerCreate(ctx context.Context, client *api.Client, cmd *cobra
 		return "", "", nil, 0, false, fmt.Errorf("selected agent has no GPUs available")
 	}
 
-	var gpuItems []tui.GPUSelectItem
-	for _, g := range selectedAgent.GPUs {
-		gpuItems = append(gpuItems, tui.GPUSelectItem{
-			GPUID:  g.GPUID,
-			Vendor: g.Vendor,
-			Model:  g.Model,
-			VRAMMb: g.VRAMMb,
-		})
-	}
-
-	gpuOptions := tui.FormatGPUOptions(gpuItems)
-	gpuIDs, err = tui.MultiSelectPrompt("Select GPU(s) to allocate:", gpuOptions)
+	gpuIDs, err = selectWorkerGPUs(ctx, client, selectedAgent)
 	if err != nil {
 		return "", "", nil, 0, false, fmt.Errorf("failed to select GPUs: %w", err)
 	}
@@ -340,7 +503,7 @@ func interactiveWorkerCreate(ctx context.Context, client *api.Client, cmd *cobra
 		}
 		port, err = strconv.Atoi(portStr)
 		if err != nil {
-			return "", "", nil, 0, false, fmt.Errorf("invalid port number: %s", portStr)
+			return "", "", nil, 0, false, cmdutil.UsageErrorf("invalid port number: %s", portStr)
 		}
 	}
 
@@ -433,7 +596,7 @@ func (r *workerDetailResult) RenderTUI(out *tui.Output) {
 	styles := tui.DefaultStyles()
 
 	out.Println()
-	out.Println(styles.Title.Render("Worker Details"))
+	out.Println(styles.Title.Render(i18n.T("Worker Details")))
 	out.Println()
 
 	// Format PID
@@ -449,39 +612,416 @@ func (r *workerDetailResult) RenderTUI(out *tui.Output) {
 		AddWithStatus("Status", r.worker.Status, r.worker.Status).
 		Add("Listen Port", fmt.Sprintf("%d", r.worker.ListenPort)).
 		AddWithStatus("Enabled", boolToYesNo(r.worker.Enabled), boolToYesNo(r.worker.Enabled)).
+		Add("Warm", boolToYesNo(r.worker.Warm)).
 		Add("PID", pid).
 		Add("Restarts", fmt.Sprintf("%d", r.worker.Restarts)).
 		Add("GPU IDs", strings.Join(r.worker.GPUIDs, ", "))
 
+	if r.worker.MIGProfile != "" {
+		status.Add("MIG Profile", r.worker.MIGProfile)
+	}
+
+	if !r.worker.Fairness.IsZero() {
+		status.Add("Fairness", cmdutil.FormatFairness(r.worker.Fairness, 0))
+	}
+
+	if !r.worker.Tuning.IsZero() {
+		status.Add("GPU Tuning", formatGPUTuning(r.worker.Tuning))
+	}
+
+	if len(r.worker.Env) > 0 {
+		names := make([]string, 0, len(r.worker.Env))
+		for k := range r.worker.Env {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		status.Add("Env", strings.Join(names, ", "))
+	}
+
+	if ha := r.worker.HA; ha != nil {
+		status.AddWithStatus("HA", ha.State, haStateStyle(ha.State)).
+			Add("HA Primary", ha.PrimaryAgentID).
+			Add("HA Standby", ha.StandbyAgentID).
+			Add("Serving Agent", ha.ActiveAgentID)
+		if ha.LastFailoverAt != nil {
+			status.Add("Last Failover", ha.LastFailoverAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+
 	out.Println(status.String())
 
 	if len(r.worker.Connections) > 0 {
 		out.Println()
-		out.Println(styles.Subtitle.Render("Active Connections"))
+		out.Println(styles.Subtitle.Render(i18n.T("Active Connections")))
 		out.Println()
 
 		var rows [][]string
 		for _, conn := range r.worker.Connections {
+			traffic := "-"
+			if conn.BytesIn > 0 || conn.BytesOut > 0 {
+				traffic = fmt.Sprintf("↓ %s ↑ %s", cmdutil.FormatBytes(conn.BytesIn), cmdutil.FormatBytes(conn.BytesOut))
+			}
 			rows = append(rows, []string{
 				conn.ClientIP,
+				cmdutil.ValueOrDash(conn.ClientHostname),
+				cmdutil.ValueOrDash(conn.ShareCode),
+				cmdutil.ValueOrDash(conn.ProtocolVersion),
 				conn.ConnectedAt.Format("2006-01-02 15:04:05"),
+				traffic,
 			})
 		}
 
 		connTable := tui.NewTable().
-			Headers("CLIENT IP", "CONNECTED AT").
+			Headers("CLIENT IP", "HOSTNAME", "SHARE CODE", "PROTOCOL", "CONNECTED AT", "TRAFFIC").
 			Rows(rows)
 
 		out.Println(connTable.String())
 	}
 }
 
+func newWorkerCrashesCmd() *cobra.Command {
+	var local bool
+	var stateDir string
+	var tailLines int
+
+	cmd := &cobra.Command{
+		Use:   "crashes <worker-id>",
+		Short: "Show worker crash reports",
+		Long: `Show crash reports captured by the agent when a worker exited abnormally.
+
+Each report includes the tail of the worker log, NVIDIA Xid errors and other
+kernel messages about the worker process, and the inferred exit reason.
+
+Examples:
+  # Crash reports uploaded to the control plane
+  ggo worker crashes <worker-id>
+
+  # Reports stored on this GPU server, including ones not yet uploaded
+  ggo worker crashes <worker-id> --local
+
+  # Agent started with a custom state directory
+  ggo worker crashes <worker-id> --local --state-dir /data/gpugo/state`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workerID := args[0]
+			out := getOutput()
+
+			var crashes []api.WorkerCrashReport
+			if local {
+				reports, err := agent.LoadCrashReports(stateDir, workerID)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to load crash reports: error=%v", err)
+					return err
+				}
+				crashes = reports
+			} else {
+				resp, err := getClient().ListWorkerCrashes(context.Background(), workerID)
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to list worker crashes: error=%v", err)
+					return err
+				}
+				crashes = resp.Crashes
+			}
+
+			// Newest first
+			sort.SliceStable(crashes, func(i, j int) bool {
+				return crashes[i].DetectedAt.After(crashes[j].DetectedAt)
+			})
+			return out.Render(&workerCrashesResult{workerID: workerID, crashes: crashes, tailLines: tailLines})
+		},
+	}
+
+	cmd.Flags().BoolVar(&local, "local", false, "Read crash reports stored on this machine instead of the server")
+	cmd.Flags().StringVar(&stateDir, "state-dir", config.NewManager("", "").StateDir(), "Agent state directory (with --local)")
+	cmd.Flags().IntVar(&tailLines, "tail", 20, "Number of worker log lines to show for the latest crash")
+
+	return cmd
+}
+
+// workerCrashesResult implements Renderable for worker crash reports
+type workerCrashesResult struct {
+	workerID  string
+	crashes   []api.WorkerCrashReport
+	tailLines int
+}
+
+func (r *workerCrashesResult) RenderJSON() any {
+	return tui.NewListResult(r.crashes)
+}
+
+func (r *workerCrashesResult) RenderTUI(out *tui.Output) {
+	if len(r.crashes) == 0 {
+		out.Infof("No crashes recorded for worker %s", r.workerID)
+		return
+	}
+	styles := tui.DefaultStyles()
+
+	var rows [][]string
+	for _, c := range r.crashes {
+		pid := "-"
+		if c.PID > 0 {
+			pid = strconv.Itoa(c.PID)
+		}
+		exitCode := "-"
+		if c.ExitCode != nil {
+			exitCode = strconv.Itoa(*c.ExitCode)
+		}
+		signal := c.Signal
+		if signal == "" {
+			signal = "-"
+		}
+		xids := "-"
+		if len(c.XIDs) > 0 {
+			parts := make([]string, len(c.XIDs))
+			for i, x := range c.XIDs {
+				parts[i] = strconv.Itoa(x)
+			}
+			xids = strings.Join(parts, ",")
+		}
+		rows = append(rows, []string{
+			c.DetectedAt.Local().Format("2006-01-02 15:04:05"),
+			pid,
+			strconv.Itoa(c.Restarts),
+			exitCode,
+			c.Reason,
+			signal,
+			xids,
+		})
+	}
+	out.Println(tui.NewTable().Headers("DETECTED AT", "PID", "RESTARTS", "EXIT", "REASON", "SIGNAL", "XIDS").Rows(rows).String())
+
+	latest := r.crashes[0]
+	if len(latest.KernelEvents) > 0 {
+		out.Println()
+		out.Println(styles.Subtitle.Render(i18n.T("Kernel Events (latest crash)")))
+		for _, e := range latest.KernelEvents {
+			out.Println("  " + e)
+		}
+	}
+	if latest.LogTail != "" && r.tailLines > 0 {
+		lines := strings.Split(strings.TrimRight(latest.LogTail, "\n"), "\n")
+		if len(lines) > r.tailLines {
+			lines = lines[len(lines)-r.tailLines:]
+		}
+		out.Println()
+		out.Println(styles.Subtitle.Render(i18n.T("Worker Log (latest crash)")))
+		if latest.LogFile != "" {
+			out.Println(styles.Muted.Render(latest.LogFile))
+		}
+		for _, l := range lines {
+			out.Println("  " + l)
+		}
+	}
+}
+
+func newWorkerLogsCmd() *cobra.Command {
+	var follow bool
+	var tailLines int
+	var local bool
+	var stdio bool
+	var stateDir string
+
+	cmd := &cobra.Command{
+		Use:   "logs <worker-id>",
+		Short: "Show worker logs",
+		Long: `Show the log of a worker.
+
+On the GPU server running the worker, the log file written under the agent
+state directory is read directly. Anywhere else the log is streamed from the
+worker's agent through the control plane.
+
+With --stdio, the worker's stdout and stderr are shown instead, as captured by
+the agent. They hold what the worker prints before its log is set up, such as
+license and driver errors. The agent rotates them by size and keeps a few
+rotated files, configured with 'ggo agent start --worker-log-max-size' and
+--worker-log-max-files or in the agent config.
+
+Examples:
+  # Last 200 lines
+  ggo worker logs <worker-id>
+
+  # Keep streaming new lines until interrupted
+  ggo worker logs <worker-id> -f
+
+  # The whole log
+  ggo worker logs <worker-id> --tail -1
+
+  # What the worker printed to stdout and stderr
+  ggo worker logs <worker-id> --stdio
+
+  # Agent started with a custom state directory
+  ggo worker logs <worker-id> --local --state-dir /data/gpugo/state`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workerID := args[0]
+			w := cmd.OutOrStdout()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			followLocal, hasLocal := agent.FollowWorkerLog, agent.HasWorkerLog
+			if stdio {
+				followLocal, hasLocal = agent.FollowWorkerOutput, agent.HasWorkerOutput
+			}
+			if local || hasLocal(stateDir, workerID) {
+				err := followLocal(ctx, stateDir, workerID, tailLines, follow, func(lines []string) error {
+					for _, l := range lines {
+						if _, err := fmt.Fprintln(w, l); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to read worker log: worker_id=%s error=%v", workerID, err)
+					return err
+				}
+				return nil
+			}
+
+			if err := getClient().StreamWorkerLogs(ctx, workerID, tailLines, follow, stdio, w); err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to stream worker log: worker_id=%s error=%v", workerID, err)
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep streaming new log lines")
+	cmd.Flags().IntVar(&tailLines, "tail", 200, "Number of lines to show from the end of the log (-1 for all)")
+	cmd.Flags().BoolVar(&local, "local", false, "Read the log file on this machine instead of streaming from the agent")
+	cmd.Flags().BoolVar(&stdio, "stdio", false, "Show the worker's captured stdout and stderr instead of its log")
+	cmd.Flags().StringVar(&stateDir, "state-dir", config.NewManager("", "").StateDir(), "Agent state directory (on the GPU server)")
+
+	return cmd
+}
+
+func newWorkerSessionsCmd() *cobra.Command {
+	var kill string
+	var stateDir string
+
+	cmd := &cobra.Command{
+		Use:   "sessions <worker-id>",
+		Short: "List or end the client sessions of a worker",
+		Long: `List the clients connected to a worker: client address, client PID, connect
+time, bytes transferred and the share code used.
+
+On the GPU server running the worker, sessions are read from the agent's live
+status; anywhere else they come from the worker's last status report. Traffic
+and share codes are known only when the agent runs with --proxy.
+
+With --kill, the worker's agent closes the named session. This also requires
+the agent to run with --proxy.
+
+Examples:
+  ggo worker sessions <worker-id>
+  ggo worker sessions <worker-id> --kill 203.0.113.7:51234`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workerID := args[0]
+			ctx := context.Background()
+			out := getOutput()
+
+			if kill != "" {
+				if err := getClient().KillWorkerSession(ctx, workerID, kill); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to end worker session: worker_id=%s session=%s error=%v", workerID, kill, err)
+					return err
+				}
+				return out.Render(&cmdutil.ActionData{
+					Success: true,
+					Message: "Asked the agent to end session %s of worker %s",
+					Args:    []any{kill, workerID},
+					ID:      kill,
+				})
+			}
+
+			paths := platform.DefaultPaths().WithStateDir(stateDir)
+			if sessions, ok := agent.LiveWorkerSessions(paths, workerID, time.Now()); ok {
+				return out.Render(&workerSessionsResult{workerID: workerID, sessions: sessions})
+			}
+			resp, err := getClient().GetWorker(ctx, workerID)
+			if err != nil {
+				cmd.SilenceUsage = true
+				klog.Errorf("Failed to get worker: error=%v", err)
+				return err
+			}
+			return out.Render(&workerSessionsResult{workerID: workerID, sessions: resp.Connections})
+		},
+	}
+
+	cmd.Flags().StringVar(&kill, "kill", "", "End the session with this ID (client IP:port, as listed)")
+	cmd.Flags().StringVar(&stateDir, "state-dir", config.NewManager("", "").StateDir(), "Agent state directory (on the GPU server)")
+	return cmd
+}
+
+// workerSessionsResult implements Renderable for worker sessions
+type workerSessionsResult struct {
+	workerID string
+	sessions []api.ConnectionInfo
+}
+
+// workerSessionJSON adds the session ID to a connection for scripting
+type workerSessionJSON struct {
+	SessionID string `json:"session_id"`
+	api.ConnectionInfo
+}
+
+func (r *workerSessionsResult) RenderJSON() any {
+	sessions := make([]workerSessionJSON, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		sessions = append(sessions, workerSessionJSON{SessionID: s.SessionID(), ConnectionInfo: s})
+	}
+	return tui.NewListResult(sessions)
+}
+
+func (r *workerSessionsResult) RenderTUI(out *tui.Output) {
+	if len(r.sessions) == 0 {
+		out.Infof("No clients connected to worker %s", r.workerID)
+		return
+	}
+
+	var rows [][]string
+	for _, s := range r.sessions {
+		pid := "-"
+		if s.ClientPID > 0 {
+			pid = strconv.Itoa(s.ClientPID)
+		}
+		shareCode := s.ShareCode
+		if shareCode == "" {
+			shareCode = "-"
+		}
+		rows = append(rows, []string{
+			s.SessionID(),
+			pid,
+			s.ConnectedAt.Format("2006-01-02 15:04:05"),
+			cmdutil.FormatBytes(s.BytesIn),
+			cmdutil.FormatBytes(s.BytesOut),
+			shareCode,
+		})
+	}
+	out.PrintTable([]string{"Session", "Client PID", "Connected At", "In", "Out", "Share Code"}, rows)
+}
+
 func newWorkerUpdateCmd() *cobra.Command {
 	var name string
 	var gpuIDs []string
 	var listenPort int
 	var enabled bool
 	var disabled bool
+	var envFlags []string
+	var unsetEnv []string
+	var perClientCompute int
+	var scheduling string
+	var powerLimit int
+	var lockClocks string
+	var persistenceMode string
+	var computeMode string
+	var warm bool
 
 	cmd := &cobra.Command{
 		Use:   "update [worker-id]",
@@ -489,7 +1029,41 @@ func newWorkerUpdateCmd() *cobra.Command {
 		Long: `Update configuration of an existing worker.
 
 If worker-id is not provided or no update flags are specified,
-the command enters interactive TUI mode.`,
+the command enters interactive TUI mode.
+
+Fairness controls divide the GPU time of a worker shared by several clients:
+--per-client-compute caps the SM share of each client and --scheduling
+round-robin interleaves the clients' kernels so that none starves the others.
+Share info shows consumers the slice they are guaranteed.
+
+GPU tuning caps the worker's GPUs while it runs, e.g. to keep shared GPUs
+cool or fair: --power-limit, --lock-clocks, --persistence-mode and
+--compute-mode. The agent applies them through nvidia-smi or rocm-smi when
+it starts the worker and restores the previous settings once it stopped.
+Persistence and compute modes are NVIDIA only.
+
+--warm keeps the worker running while it is disabled, with the agent's
+connection proxy refusing its sessions, so enabling it serves clients without
+a cold start; --warm=false turns it off and stops a disabled worker.
+
+Examples:
+  # Cap every client at a quarter of the GPU and take turns
+  ggo worker update worker-1 --per-client-compute 25 --scheduling round-robin
+
+  # Remove the per-client cap
+  ggo worker update worker-1 --per-client-compute 0
+
+  # Cap the GPU at 250 W and lock its graphics clock to 1200-1500 MHz
+  ggo worker update worker-1 --power-limit 250 --lock-clocks 1200,1500
+
+  # Give the worker's process the GPU to itself
+  ggo worker update worker-1 --compute-mode exclusive-process
+
+  # Remove the power limit and unlock the clocks
+  ggo worker update worker-1 --power-limit 0 --lock-clocks 0
+
+  # Keep the disabled worker warm for a fast start
+  ggo worker update worker-1 --warm`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := getClient()
@@ -506,7 +1080,13 @@ the command enters interactive TUI mode.`,
 				cmd.Flags().Changed("gpu-ids") ||
 				cmd.Flags().Changed("port") ||
 				cmd.Flags().Changed("enabled") ||
-				cmd.Flags().Changed("disabled")
+				cmd.Flags().Changed("disabled") ||
+				cmd.Flags().Changed("env") ||
+				cmd.Flags().Changed("unset-env") ||
+				cmd.Flags().Changed("per-client-compute") ||
+				cmd.Flags().Changed("scheduling") ||
+				cmd.Flags().Changed("warm") ||
+				hasGPUTuningFlags(cmd)
 
 			needsInteractive := workerID == "" || !hasUpdateFlags
 
@@ -551,6 +1131,54 @@ the command enters interactive TUI mode.`,
 				notDisabled := !disabled
 				req.Enabled = &notDisabled
 			}
+			if cmd.Flags().Changed("warm") {
+				req.Warm = &warm
+			}
+			if len(envFlags) > 0 || len(unsetEnv) > 0 {
+				env, err := mergeEnvFlags(ctx, client, workerID, envFlags, unsetEnv)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				req.Env = &env
+			}
+			if cmd.Flags().Changed("per-client-compute") || cmd.Flags().Changed("scheduling") {
+				var computeFlag *int
+				var schedulingFlag *string
+				if cmd.Flags().Changed("per-client-compute") {
+					computeFlag = &perClientCompute
+				}
+				if cmd.Flags().Changed("scheduling") {
+					schedulingFlag = &scheduling
+				}
+				fairness, err := mergeFairnessFlags(ctx, client, workerID, computeFlag, schedulingFlag)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				req.Fairness = fairness
+			}
+			if hasGPUTuningFlags(cmd) {
+				var flags gpuTuningFlags
+				if cmd.Flags().Changed("power-limit") {
+					flags.powerLimit = &powerLimit
+				}
+				if cmd.Flags().Changed("lock-clocks") {
+					flags.lockClocks = &lockClocks
+				}
+				if cmd.Flags().Changed("persistence-mode") {
+					flags.persistenceMode = &persistenceMode
+				}
+				if cmd.Flags().Changed("compute-mode") {
+					flags.computeMode = &computeMode
+				}
+				tuning, err := mergeGPUTuningFlags(ctx, client, workerID, flags)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				req.Tuning = tuning
+			}
 
 			resp, err := client.UpdateWorker(ctx, workerID, req)
 			if err != nil {
@@ -568,10 +1196,215 @@ the command enters interactive TUI mode.`,
 	cmd.Flags().IntVar(&listenPort, "port", 0, "Listen port")
 	cmd.Flags().BoolVar(&enabled, "enabled", false, "Enable worker")
 	cmd.Flags().BoolVar(&disabled, "disabled", false, "Disable worker")
+	cmd.Flags().StringArrayVarP(&envFlags, "env", "e", nil, "Set worker environment variable KEY=VALUE (repeatable)")
+	cmd.Flags().StringSliceVar(&unsetEnv, "unset-env", nil, "Remove worker environment variables by name")
+	cmd.Flags().IntVar(&perClientCompute, "per-client-compute", 0, "Cap the compute of each client in percent (1-100, 0 removes the cap)")
+	cmd.Flags().StringVar(&scheduling, "scheduling", "", "Client scheduling hint: round-robin or fifo")
+	cmd.Flags().IntVar(&powerLimit, "power-limit", 0, "Cap the power draw of each GPU in watts (0 removes the limit)")
+	cmd.Flags().StringVar(&lockClocks, "lock-clocks", "", "Lock the graphics clock to MIN,MAX or a single MHz value (0 unlocks)")
+	cmd.Flags().StringVar(&persistenceMode, "persistence-mode", "", "GPU persistence mode: on, off, or unset to leave it alone")
+	cmd.Flags().StringVar(&computeMode, "compute-mode", "", "GPU compute mode: default, exclusive-process, prohibited, or unset to leave it alone")
+	cmd.Flags().BoolVar(&warm, "warm", false, "Keep the worker running while disabled (--warm=false turns it off)")
 
 	return cmd
 }
 
+// mergeFairnessFlags applies --per-client-compute and --scheduling, where
+// given (non-nil), to the worker's current fairness controls
+func mergeFairnessFlags(ctx context.Context, client *api.Client, workerID string, perClientCompute *int, scheduling *string) (*api.WorkerFairness, error) {
+	if perClientCompute != nil && (*perClientCompute < 0 || *perClientCompute > 100) {
+		return nil, cmdutil.UsageErrorf("invalid --per-client-compute %d: expected 1-100, or 0 to remove the cap", *perClientCompute)
+	}
+	if scheduling != nil && *scheduling != api.SchedulingRoundRobin && *scheduling != api.SchedulingFIFO {
+		return nil, cmdutil.UsageErrorf("invalid --scheduling %q: expected %s or %s", *scheduling, api.SchedulingRoundRobin, api.SchedulingFIFO)
+	}
+
+	worker, err := client.GetWorker(ctx, workerID)
+	if err != nil {
+		klog.Errorf("Failed to get worker: error=%v", err)
+		return nil, err
+	}
+
+	fairness := &api.WorkerFairness{}
+	if worker.Fairness != nil {
+		*fairness = *worker.Fairness
+	}
+	if perClientCompute != nil {
+		fairness.PerClientComputePercent = *perClientCompute
+	}
+	if scheduling != nil {
+		fairness.Scheduling = *scheduling
+	}
+	return fairness, nil
+}
+
+// gpuTuningFlags are the GPU tuning flags of worker update, nil where not
+// given
+type gpuTuningFlags struct {
+	powerLimit      *int
+	lockClocks      *string
+	persistenceMode *string
+	computeMode     *string
+}
+
+func hasGPUTuningFlags(cmd *cobra.Command) bool {
+	return cmd.Flags().Changed("power-limit") ||
+		cmd.Flags().Changed("lock-clocks") ||
+		cmd.Flags().Changed("persistence-mode") ||
+		cmd.Flags().Changed("compute-mode")
+}
+
+// mergeGPUTuningFlags applies the given GPU tuning flags to the worker's
+// current tuning
+func mergeGPUTuningFlags(ctx context.Context, client *api.Client, workerID string, flags gpuTuningFlags) (*api.WorkerGPUTuning, error) {
+	worker, err := client.GetWorker(ctx, workerID)
+	if err != nil {
+		klog.Errorf("Failed to get worker: error=%v", err)
+		return nil, err
+	}
+
+	tuning := &api.WorkerGPUTuning{}
+	if worker.Tuning != nil {
+		*tuning = *worker.Tuning
+	}
+	if err := applyGPUTuningFlags(tuning, flags); err != nil {
+		return nil, err
+	}
+	return tuning, nil
+}
+
+// applyGPUTuningFlags sets the fields of the given flags on tuning
+func applyGPUTuningFlags(tuning *api.WorkerGPUTuning, flags gpuTuningFlags) error {
+	if flags.powerLimit != nil {
+		if *flags.powerLimit < 0 {
+			return cmdutil.UsageErrorf("invalid --power-limit %d: expected watts, or 0 to remove the limit", *flags.powerLimit)
+		}
+		tuning.PowerLimitWatts = *flags.powerLimit
+	}
+	if flags.lockClocks != nil {
+		minMHz, maxMHz, err := parseClockRange(*flags.lockClocks)
+		if err != nil {
+			return err
+		}
+		tuning.MinClockMHz, tuning.MaxClockMHz = minMHz, maxMHz
+	}
+	if flags.persistenceMode != nil {
+		switch *flags.persistenceMode {
+		case "on":
+			on := true
+			tuning.PersistenceMode = &on
+		case "off":
+			off := false
+			tuning.PersistenceMode = &off
+		case "", "unset":
+			tuning.PersistenceMode = nil
+		default:
+			return cmdutil.UsageErrorf("invalid --persistence-mode %q: expected on, off or unset", *flags.persistenceMode)
+		}
+	}
+	if flags.computeMode != nil {
+		switch mode := *flags.computeMode; mode {
+		case api.ComputeModeDefault, api.ComputeModeExclusiveProcess, api.ComputeModeProhibited:
+			tuning.ComputeMode = mode
+		case "", "unset":
+			tuning.ComputeMode = ""
+		default:
+			return cmdutil.UsageErrorf("invalid --compute-mode %q: expected %s, %s, %s or unset", mode,
+				api.ComputeModeDefault, api.ComputeModeExclusiveProcess, api.ComputeModeProhibited)
+		}
+	}
+	return nil
+}
+
+// parseClockRange parses --lock-clocks: MIN,MAX, a single MHz value locking
+// the clock to it, or 0 (or empty) to unlock
+func parseClockRange(value string) (int, int, error) {
+	if value == "" || value == "0" {
+		return 0, 0, nil
+	}
+	minStr, maxStr, isRange := strings.Cut(value, ",")
+	if !isRange {
+		maxStr = minStr
+	}
+	minMHz, err1 := strconv.Atoi(strings.TrimSpace(minStr))
+	maxMHz, err2 := strconv.Atoi(strings.TrimSpace(maxStr))
+	if err1 != nil || err2 != nil || minMHz <= 0 || maxMHz < minMHz {
+		return 0, 0, cmdutil.UsageErrorf("invalid --lock-clocks %q: expected MIN,MAX or a single clock in MHz, or 0 to unlock", value)
+	}
+	return minMHz, maxMHz, nil
+}
+
+// formatGPUTuning describes the GPU tuning of a worker
+func formatGPUTuning(t *api.WorkerGPUTuning) string {
+	if t.IsZero() {
+		return "none"
+	}
+	var parts []string
+	if t.PowerLimitWatts > 0 {
+		parts = append(parts, fmt.Sprintf("%d W power limit", t.PowerLimitWatts))
+	}
+	if t.MaxClockMHz > 0 {
+		if t.MinClockMHz == t.MaxClockMHz {
+			parts = append(parts, fmt.Sprintf("clock locked at %d MHz", t.MaxClockMHz))
+		} else {
+			parts = append(parts, fmt.Sprintf("clock locked to %d-%d MHz", t.MinClockMHz, t.MaxClockMHz))
+		}
+	}
+	if t.PersistenceMode != nil {
+		if *t.PersistenceMode {
+			parts = append(parts, "persistence mode on")
+		} else {
+			parts = append(parts, "persistence mode off")
+		}
+	}
+	if t.ComputeMode != "" {
+		parts = append(parts, t.ComputeMode+" compute mode")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseEnvFlags parses repeated KEY=VALUE flags into an env map
+func parseEnvFlags(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, cmdutil.UsageErrorf("invalid env %q: expected KEY=VALUE", v)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+// mergeEnvFlags applies --env and --unset-env to the worker's current env
+func mergeEnvFlags(ctx context.Context, client *api.Client, workerID string, set, unset []string) (map[string]string, error) {
+	updates, err := parseEnvFlags(set)
+	if err != nil {
+		return nil, err
+	}
+
+	worker, err := client.GetWorker(ctx, workerID)
+	if err != nil {
+		klog.Errorf("Failed to get worker: error=%v", err)
+		return nil, err
+	}
+
+	env := make(map[string]string, len(worker.Env)+len(updates))
+	for k, v := range worker.Env {
+		env[k] = v
+	}
+	for k, v := range updates {
+		env[k] = v
+	}
+	for _, k := range unset {
+		delete(env, k)
+	}
+	return env, nil
+}
+
 // interactiveWorkerUpdate guides user through worker update via TUI
 func interactiveWorkerUpdate(ctx context.Context, client *api.Client, workerID string) (
 	selectedWorkerID string, req *api.WorkerUpdateRequest, err error,
@@ -579,8 +1412,8 @@ func interactiveWorkerUpdate(ctx context.Context, client *api.Client, workerID s
 	styles := tui.DefaultStyles()
 
 	fmt.Println()
-	fmt.Println(styles.Title.Render("✏️  Update GPU Worker"))
-	fmt.Println(styles.Muted.Render("Follow the steps below to update your worker"))
+	fmt.Println(styles.Title.Render(i18n.T("✏️  Update GPU Worker")))
+	fmt.Println(styles.Muted.Render(i18n.T("Follow the steps below to update your worker")))
 
 	// Step 1: Select worker if not provided
 	if workerID == "" {
@@ -624,7 +1457,7 @@ func interactiveWorkerUpdate(ctx context.Context, client *api.Client, workerID s
 	tui.StepHeader(2, 3, "Select Fields to Update")
 
 	fmt.Println()
-	fmt.Println(styles.Subtitle.Render("Current configuration:"))
+	fmt.Println(styles.Subtitle.Render(i18n.T("Current configuration:")))
 	status := tui.NewStatusTable().
 		Add("Name", worker.Name).
 		Add("Port", fmt.Sprintf("%d", worker.ListenPort)).
@@ -660,7 +1493,7 @@ func interactiveWorkerUpdate(ctx context.Context, client *api.Client, workerID s
 			}
 			port, err := strconv.Atoi(portStr)
 			if err != nil {
-				return "", nil, fmt.Errorf("invalid port number: %s", portStr)
+				return "", nil, cmdutil.UsageErrorf("invalid port number: %s", portStr)
 			}
 			req.ListenPort = &port
 
@@ -729,18 +1562,34 @@ func (r *workerUpdateResult) RenderTUI(out *tui.Output) {
 	status := tui.NewStatusTable().
 		Add("Worker ID", r.worker.WorkerID).
 		AddWithStatus("Status", r.worker.Status, r.worker.Status)
+	if !r.worker.Fairness.IsZero() {
+		status.Add("Fairness", cmdutil.FormatFairness(r.worker.Fairness, 0))
+	}
+	if !r.worker.Tuning.IsZero() {
+		status.Add("GPU Tuning", formatGPUTuning(r.worker.Tuning))
+	}
 
 	out.Println(status.String())
 }
 
 func newWorkerDeleteCmd() *cobra.Command {
 	var force bool
+	var yes bool
+	var wait bool
 
 	cmd := &cobra.Command{
 		Use:   "delete [worker-id]",
 		Short: "Delete a worker",
 		Long: `Delete a GPU worker.
 
+The worker stops accepting new connections right away, but keeps serving
+connected clients until they disconnect or the agent's drain grace period
+(--drain-grace of 'ggo agent start', 5 minutes by default) runs out. The
+command shows the drain progress until the worker has stopped; interrupting
+it does not cancel the deletion.
+
+With --force the worker is stopped at once, cutting off connected clients.
+
 If worker-id is not provided, the command enters interactive TUI mode
 to let you select a worker to delete.`,
 		Args: cobra.MaximumNArgs(1),
@@ -758,7 +1607,7 @@ to let you select a worker to delete.`,
 			// Interactive mode if no worker-id provided
 			if workerID == "" {
 				if out.IsJSON() {
-					return fmt.Errorf("worker-id is required in JSON mode")
+					return cmdutil.UsageErrorf("worker-id is required in JSON mode")
 				}
 
 				var err error
@@ -769,7 +1618,7 @@ to let you select a worker to delete.`,
 				}
 			}
 
-			if !force && !out.IsJSON() {
+			if !force && !yes && !out.IsJSON() {
 				displayID := workerID
 				if workerName != "" {
 					displayID = fmt.Sprintf("%s (%s)", workerName, workerID[:12]+"...")
@@ -784,31 +1633,92 @@ to let you select a worker to delete.`,
 				}
 			}
 
-			if err := client.DeleteWorker(ctx, workerID); err != nil {
+			if err := client.DeleteWorker(ctx, workerID, force); err != nil {
 				cmd.SilenceUsage = true
 				klog.Errorf("Failed to delete worker: error=%v", err)
 				return err
 			}
 
+			if wait && !force {
+				waitCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				if err := waitForWorkerDrain(waitCtx, client, out, workerID); err != nil {
+					cmd.SilenceUsage = true
+					klog.Errorf("Failed to wait for worker to stop: worker_id=%s error=%v", workerID, err)
+					return err
+				}
+			}
+
 			return out.Render(&cmdutil.ActionData{
 				Success: true,
-				Message: fmt.Sprintf("Worker %s deleted successfully!", workerID),
+				Message: "Worker %s deleted successfully!",
+				Args:    []any{workerID},
 				ID:      workerID,
 			})
 		},
 	}
 
-	cmd.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation and stop the worker without draining its connections")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+	cmd.Flags().BoolVar(&wait, "wait", true, "Wait until the worker has drained and stopped")
 
 	return cmd
 }
 
+// workerDrainPollInterval is how often `worker delete` checks drain progress
+const workerDrainPollInterval = 2 * time.Second
+
+// waitForWorkerDrain polls a deleted worker until its agent has stopped it,
+// reporting the connected clients while it drains. Interrupting the wait
+// leaves the worker draining on the agent.
+func waitForWorkerDrain(ctx context.Context, client *api.Client, out *tui.Output, workerID string) error {
+	ticker := time.NewTicker(workerDrainPollInterval)
+	defer ticker.Stop()
+
+	var last string
+	for {
+		worker, err := client.GetWorker(ctx, workerID)
+		switch {
+		case api.IsNotFound(err):
+			return nil
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		case worker.Status == "stopped":
+			return nil
+		}
+
+		progress := i18n.T("Waiting for the agent to stop the worker...")
+		if worker.Status == "stopping" {
+			progress = i18n.Tf("Draining: %d client(s) connected", len(worker.Connections))
+			if worker.DrainDeadline != nil {
+				progress += i18n.Tf(", stopping by %s", worker.DrainDeadline.Local().Format("15:04:05"))
+			}
+		}
+		if progress != last && !out.IsJSON() {
+			out.Info(progress)
+			last = progress
+		}
+
+		select {
+		case <-ctx.Done():
+			if !out.IsJSON() {
+				out.Warning("Stopped waiting; the worker keeps draining on its agent")
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 // interactiveWorkerDelete guides user through worker deletion via TUI
 func interactiveWorkerDelete(ctx context.Context, client *api.Client) (workerID, workerName string, err error) {
 	styles := tui.DefaultStyles()
 
 	fmt.Println()
-	fmt.Println(styles.Title.Render("🗑️  Delete GPU Worker"))
+	fmt.Println(styles.Title.Render(i18n.T("🗑️  Delete GPU Worker")))
 
 	// Step 1: Select worker
 	tui.StepHeader(1, 1, "Select Worker to Delete")
@@ -845,6 +1755,10 @@ func newWorkerShareCmd() *cobra.Command {
 	var connectionIP string
 	var expiresIn string
 	var maxUses int
+	var relay bool
+	var team string
+	var preferIPv6 bool
+	var alias string
 
 	cmd := &cobra.Command{
 		Use:   "share [worker-name]",
@@ -867,17 +1781,41 @@ Examples:
   # Share with specific IP
   ggo worker share my-worker --connection-ip 192.168.1.100
 
+  # Share over IPv6 on a dual-stack host; clients without IPv6 fall back
+  # to the host's IPv4 address
+  ggo worker share my-worker --prefer-ipv6
+  ggo worker share my-worker --connection-ip 2001:db8::10
+
   # Share with expiration
-  ggo worker share my-worker --expires-in 24h`,
+  ggo worker share my-worker --expires-in 24h
+
+  # Share through the platform relay, for clients that cannot reach the host
+  ggo worker share my-worker --relay
+
+  # Name the share, so that you can use it by name when signed in
+  ggo worker share my-worker --alias lab-3090
+  ggo use lab-3090
+
+  # Share with a platform team: members connect with their own login,
+  # no share code needed
+  ggo worker share my-worker --team ml-infra
+  ggo use --team ml-infra --worker my-worker`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if alias != "" {
+				if err := cmdutil.ValidateShareAlias(alias); err != nil {
+					return err
+				}
+			}
+
 			client := getClient()
 			ctx := context.Background()
 			out := getOutput()
 
 			// Determine if we need interactive mode
 			needsWorkerSelection := len(args) == 0
-			needsIPSelection := connectionIP == ""
+			// Relayed shares connect through the relay, not a host IP
+			needsIPSelection := connectionIP == "" && !relay
 			totalSteps := 0
 			if needsWorkerSelection {
 				totalSteps++
@@ -892,8 +1830,8 @@ Examples:
 			if !out.IsJSON() && (needsWorkerSelection || needsIPSelection) {
 				styles := tui.DefaultStyles()
 				fmt.Println()
-				fmt.Println(styles.Title.Render("🔗 Share GPU Worker"))
-				fmt.Println(styles.Muted.Render("Create a shareable link for your GPU worker"))
+				fmt.Println(styles.Title.Render(i18n.T("🔗 Share GPU Worker")))
+				fmt.Println(styles.Muted.Render(i18n.T("Create a shareable link for your GPU worker")))
 			}
 
 			// Step 1: Get worker (from arg or selection)
@@ -906,27 +1844,40 @@ Examples:
 				return err
 			}
 
+			var networkIPs []string
+			if !relay {
+				networkIPs = orderIPsByFamily(agentNetworkIPs(ctx, client, agentID), preferIPv6)
+			}
+
 			// Step 2: Get connection IP (from flag, agent IPs, or manual input)
 			if needsIPSelection {
 				currentStep++
-				selectedIP, err := selectConnectionIP(ctx, client, agentID, out, currentStep, totalSteps)
+				selectedIP, err := selectConnectionIP(networkIPs, out, currentStep, totalSteps)
 				if err != nil {
 					cmd.SilenceUsage = true
 					return err
 				}
 				connectionIP = selectedIP
 			}
+			if ip, err := utils.ParseIPLiteral(connectionIP); err == nil {
+				// The platform expects bare addresses, not [2001:db8::1]
+				connectionIP = ip.String()
+			}
 
 			// Step 3: Create share link
 			req := &api.ShareCreateRequest{
 				WorkerID:     workerID,
 				ConnectionIP: connectionIP,
+				Relay:        relay,
+				Team:         team,
+				FallbackIPs:  fallbackIPs(connectionIP, networkIPs),
+				Alias:        alias,
 			}
 
 			if expiresIn != "" {
 				duration, err := time.ParseDuration(expiresIn)
 				if err != nil {
-					return fmt.Errorf("invalid expiration duration: %w", err)
+					return cmdutil.UsageErrorf("invalid expiration duration: %w", err)
 				}
 				expiresAt := time.Now().Add(duration)
 				req.ExpiresAt = &expiresAt
@@ -939,8 +1890,8 @@ Examples:
 			resp, err := client.CreateShare(ctx, req)
 			if err != nil {
 				cmd.SilenceUsage = true
-				klog.Errorf("Failed to create share: error=%v", err)
-				return err
+				klog.Errorf("Failed to create share: alias=%s error=%v", alias, err)
+				return cmdutil.ShareAliasError(ctx, client, alias, err)
 			}
 
 			return out.Render(&workerShareResult{
@@ -953,6 +1904,10 @@ Examples:
 	cmd.Flags().StringVar(&connectionIP, "connection-ip", "", "Connection IP address (skip interactive selection)")
 	cmd.Flags().StringVar(&expiresIn, "expires-in", "", "Expiration duration (e.g., 24h, 7d)")
 	cmd.Flags().IntVar(&maxUses, "max-uses", 0, "Maximum number of uses (0 = unlimited)")
+	cmd.Flags().BoolVar(&relay, "relay", false, "Serve the share through the platform relay (works behind NAT and firewalls)")
+	cmd.Flags().StringVar(&team, "team", "", "Bind the share to a platform team, so its members can use the worker without a share code")
+	cmd.Flags().BoolVar(&preferIPv6, "prefer-ipv6", false, "Offer the worker's IPv6 addresses first when selecting the connection IP")
+	cmd.Flags().StringVar(&alias, "alias", "", "Name for the share, unique in your account, that 'ggo use <alias>' accepts when signed in")
 
 	return cmd
 }
@@ -977,12 +1932,12 @@ func selectWorker(ctx context.Context, client *api.Client, args []string, out *t
 				return w.WorkerID, w.Name, w.AgentID, nil
 			}
 		}
-		return "", "", "", fmt.Errorf("worker '%s' not found", workerNameArg)
+		return "", "", "", cmdutil.NotFoundErrorf("worker '%s' not found", workerNameArg)
 	}
 
 	// Interactive selection if running in TUI mode
 	if out.IsJSON() {
-		return "", "", "", fmt.Errorf("worker name is required in JSON output mode")
+		return "", "", "", cmdutil.UsageErrorf("worker name is required in JSON output mode")
 	}
 
 	// Show step header
@@ -1012,29 +1967,62 @@ func selectWorker(ctx context.Context, client *api.Client, args []string, out *t
 		}
 	}
 
-	return "", "", "", fmt.Errorf("selected worker not found")
+	return "", "", "", cmdutil.NotFoundErrorf("selected worker not found")
+}
+
+// agentNetworkIPs returns the network IPs the worker's agent reported
+func agentNetworkIPs(ctx context.Context, client *api.Client, agentID string) []string {
+	if agentID == "" {
+		return nil
+	}
+	agent, err := client.GetAgent(ctx, agentID)
+	if err != nil {
+		klog.Warningf("Failed to get agent info: error=%v", err)
+		return nil
+	}
+	return agent.NetworkIPs
+}
+
+// orderIPsByFamily moves the addresses of the preferred family first,
+// keeping the agent's order otherwise
+func orderIPsByFamily(ips []string, preferIPv6 bool) []string {
+	var preferred, other []string
+	for _, ip := range ips {
+		parsed := net.ParseIP(ip)
+		if parsed != nil && (parsed.To4() == nil) == preferIPv6 {
+			preferred = append(preferred, ip)
+		} else {
+			other = append(other, ip)
+		}
+	}
+	return append(preferred, other...)
+}
+
+// fallbackIPs picks an address of the other IP family than connectionIP from
+// the agent's addresses, for clients that cannot reach connectionIP
+func fallbackIPs(connectionIP string, networkIPs []string) []string {
+	primary := net.ParseIP(connectionIP)
+	if primary == nil {
+		return nil
+	}
+	for _, ip := range networkIPs {
+		parsed := net.ParseIP(ip)
+		if parsed != nil && (parsed.To4() == nil) != (primary.To4() == nil) {
+			return []string{ip}
+		}
+	}
+	return nil
 }
 
 // selectConnectionIP selects an IP from agent's network IPs or manual input
-func selectConnectionIP(ctx context.Context, client *api.Client, agentID string, out *tui.Output, stepNum, totalSteps int) (string, error) {
+func selectConnectionIP(networkIPs []string, out *tui.Output, stepNum, totalSteps int) (string, error) {
 	if out.IsJSON() {
-		return "", fmt.Errorf("--connection-ip is required in JSON output mode")
+		return "", cmdutil.UsageErrorf("--connection-ip is required in JSON output mode")
 	}
 
 	// Show step header
 	tui.StepHeader(stepNum, totalSteps, "Select Connection IP")
 
-	// Get agent info to retrieve network IPs
-	var networkIPs []string
-	if agentID != "" {
-		agent, err := client.GetAgent(ctx, agentID)
-		if err != nil {
-			klog.Warningf("Failed to get agent info: error=%v", err)
-		} else if len(agent.NetworkIPs) > 0 {
-			networkIPs = agent.NetworkIPs
-		}
-	}
-
 	if len(networkIPs) == 0 {
 		// No IPs available, ask for manual input
 		return tui.InputPrompt("Enter connection IP address")
@@ -1068,6 +2056,18 @@ func (r *workerShareResult) RenderTUI(out *tui.Output) {
 		Add("Short Link", tui.URL(r.share.ShortLink)).
 		Add("Connection URL", r.share.ConnectionURL)
 
+	if r.share.Alias != "" {
+		status.Add("Alias", styles.Bold.Render(r.share.Alias))
+	}
+	for _, u := range r.share.FallbackConnectionURLs {
+		status.Add("Fallback URL", u)
+	}
+	if r.share.Relay {
+		status.Add("Route", "via relay")
+	}
+	if r.share.Team != "" {
+		status.Add("Team", r.share.Team)
+	}
 	if r.share.ExpiresAt != nil {
 		status.Add("Expires At", r.share.ExpiresAt.Format("2006-01-02 15:04:05"))
 	}
@@ -1078,25 +2078,49 @@ func (r *workerShareResult) RenderTUI(out *tui.Output) {
 	out.Println(status.String())
 
 	out.Println()
-	out.Println(styles.Title.Render("📋 Share this link with others:"))
+	if r.share.Team != "" {
+		out.Println(styles.Title.Render(i18n.Tf("📋 Members of team %s can now connect with:", r.share.Team)))
+		out.Println()
+		out.Println("  " + tui.Code(fmt.Sprintf("ggo use --team %s --worker %s", r.share.Team, r.workerName)))
+		out.Println()
+		out.Println(styles.Muted.Render(i18n.T("  They sign in with 'ggo login'; no share code is needed.")))
+		out.Println()
+		return
+	}
+
+	out.Println(styles.Title.Render(i18n.T("📋 Share this link with others:")))
 	out.Println()
 
 	// Usage instruction box
-	out.Println(styles.Subtitle.Render("Option 1: Update client environment"))
+	out.Println(styles.Subtitle.Render(i18n.T("Option 1: Update client environment")))
 	out.Println()
 	out.Println("  " + tui.Code(fmt.Sprintf("ggo use %s", r.share.ShortCode)))
 	out.Println()
-	out.Println(styles.Muted.Render("  This sets up the remote GPU environment for the current session."))
+	out.Println(styles.Muted.Render(i18n.T("  This sets up the remote GPU environment for the current session.")))
+	if r.share.Alias != "" {
+		out.Println(styles.Muted.Render(i18n.Tf("  Signed in to your account, 'ggo use %s' works too.", r.share.Alias)))
+	}
 
 	out.Println()
-	out.Println(styles.Subtitle.Render("Option 2: Create an AI Studio with this GPU"))
+	out.Println(styles.Subtitle.Render(i18n.T("Option 2: Create an AI Studio with this GPU")))
 	out.Println()
 	out.Println("  " + tui.Code(fmt.Sprintf("ggo studio create <name> -s %s", r.share.ConnectionURL)))
 	out.Println()
-	out.Println(styles.Muted.Render("  This creates a containerized development environment with remote GPU access."))
+	out.Println(styles.Muted.Render(i18n.T("  This creates a containerized development environment with remote GPU access.")))
 	out.Println()
 }
 
+// haStateStyle maps an HA state to the status style it is shown with
+func haStateStyle(state string) string {
+	switch state {
+	case api.HAStateProtected:
+		return "active"
+	case api.HAStateStandbyOffline, api.HAStateFailedOver:
+		return "pending"
+	}
+	return "unknown"
+}
+
 func boolToYesNo(b bool) string {
 	if b {
 		return "yes"
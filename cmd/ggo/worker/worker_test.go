@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyGPUTuningFlags(t *testing.T) {
+	on := true
+	tuning := &api.WorkerGPUTuning{PowerLimitWatts: 300, PersistenceMode: &on}
+	powerLimit, clocks, mode := 0, "1500", api.ComputeModeExclusiveProcess
+	require.NoError(t, applyGPUTuningFlags(tuning, gpuTuningFlags{powerLimit: &powerLimit, lockClocks: &clocks, computeMode: &mode}))
+	assert.Equal(t, &api.WorkerGPUTuning{MinClockMHz: 1500, MaxClockMHz: 1500, PersistenceMode: &on, ComputeMode: mode}, tuning)
+	assert.Equal(t, "clock locked at 1500 MHz, persistence mode on, exclusive-process compute mode", formatGPUTuning(tuning))
+
+	clocks = "1200,1500"
+	require.NoError(t, applyGPUTuningFlags(tuning, gpuTuningFlags{lockClocks: &clocks}))
+	assert.Equal(t, 1200, tuning.MinClockMHz)
+
+	for _, invalid := range []gpuTuningFlags{
+		{lockClocks: ptr("1500,1200")},
+		{lockClocks: ptr("fast")},
+		{persistenceMode: ptr("yes")},
+		{computeMode: ptr("exclusive-thread")},
+	} {
+		assert.Error(t, applyGPUTuningFlags(&api.WorkerGPUTuning{}, invalid))
+	}
+}
+
+func ptr(s string) *string { return &s }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring the simulated failures; the flag of the
+// same name without the prefix, e.g. --drop-after, overrides each
+const (
+	envDelay         = "MOCK_WORKER_DELAY"
+	envDropAfter     = "MOCK_WORKER_DROP_AFTER"
+	envCrashAfter    = "MOCK_WORKER_CRASH_AFTER"
+	envCrashExitCode = "MOCK_WORKER_CRASH_EXIT_CODE"
+)
+
+// Environment variables the agent sets for its workers, see
+// internal/agent/connfile.go
+const (
+	envConnectionInfoPath   = "TF_CONNECTION_INFO_PATH"
+	envConnectionInfoFormat = "TF_CONNECTION_INFO_FORMAT"
+	connectionInfoFormatV2  = "v2"
+)
+
+// config is how the mock worker behaves
+type config struct {
+	port int
+	// delay holds back every response
+	delay time.Duration
+	// dropAfter resets a connection once it carried that many bytes in
+	// either direction; 0 never does
+	dropAfter int64
+	// crashAfter makes the process exit with crashExitCode that long after
+	// it started, so a supervisor restarting it sees periodic crashes; 0
+	// never does
+	crashAfter    time.Duration
+	crashExitCode int
+	// connectionInfoPath is the connection file listing the client sessions,
+	// in connectionInfoFormat
+	connectionInfoPath   string
+	connectionInfoFormat string
+}
+
+// parseConfig reads the configuration from the environment and args. Flags
+// other than the known ones are ignored, as the agent passes those of the
+// real worker.
+func parseConfig(args []string, getenv func(string) string) (*config, error) {
+	values := map[string]string{
+		"delay":           getenv(envDelay),
+		"drop-after":      getenv(envDropAfter),
+		"crash-after":     getenv(envCrashAfter),
+		"crash-exit-code": getenv(envCrashExitCode),
+	}
+	port := "8080"
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") {
+			continue
+		}
+		if name == "p" {
+			name = "port"
+		}
+		if _, known := values[name]; !known && name != "port" {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag --%s needs a value", name)
+			}
+			value = args[i+1]
+			i++
+		}
+		if name == "port" {
+			port = value
+		} else {
+			values[name] = value
+		}
+	}
+
+	cfg := &config{
+		crashExitCode:        1,
+		connectionInfoPath:   getenv(envConnectionInfoPath),
+		connectionInfoFormat: getenv(envConnectionInfoFormat),
+	}
+	var err error
+	if cfg.port, err = strconv.Atoi(port); err != nil {
+		return nil, fmt.Errorf("invalid port %q", port)
+	}
+	if cfg.delay, err = parseDuration("delay", values["delay"]); err != nil {
+		return nil, err
+	}
+	if cfg.crashAfter, err = parseDuration("crash-after", values["crash-after"]); err != nil {
+		return nil, err
+	}
+	if v := values["drop-after"]; v != "" {
+		if cfg.dropAfter, err = strconv.ParseInt(v, 10, 64); err != nil || cfg.dropAfter < 0 {
+			return nil, fmt.Errorf("invalid drop-after %q: want a number of bytes", v)
+		}
+	}
+	if v := values["crash-exit-code"]; v != "" {
+		if cfg.crashExitCode, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid crash-exit-code %q", v)
+		}
+	}
+	return cfg, nil
+}
+
+func parseDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a duration such as 500ms", name, value)
+	}
+	return d, nil
+}
@@ -1,28 +1,33 @@
+// Command mock-worker stands in for tensor-fusion-worker in tests. It answers
+// HTTP requests with a fixed page and echoes any other stream, lists its
+// client sessions in the agent's connection file (TF_CONNECTION_INFO_PATH),
+// and can simulate failures: delayed responses (--delay), connections reset
+// after a number of bytes (--drop-after) and crashes some time after start
+// (--crash-after, --crash-exit-code), which a supervisor restarting it turns
+// into crash/restart cycles. Each flag can also be set with the
+// MOCK_WORKER_* environment variable of the same name, e.g.
+// MOCK_WORKER_DROP_AFTER, so workers the agent starts can be configured
+// through their env.
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
-	"strconv"
+	"time"
 )
 
 func main() {
-	port := 8080
-	// Manually parse os.Args to allow arbitrary flags while looking for -p or --port
-	for i := 1; i < len(os.Args); i++ {
-		arg := os.Args[i]
-		if (arg == "-p" || arg == "--port") && i+1 < len(os.Args) {
-			p, err := strconv.Atoi(os.Args[i+1])
-			if err == nil {
-				port = p
-			}
-			i++ // Skip the value
-		}
+	cfg, err := parseConfig(os.Args[1:], os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
 	}
 
-	addr := fmt.Sprintf(":%d", port)
+	addr := fmt.Sprintf(":%d", cfg.port)
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error listening on %s: %v\n", addr, err)
@@ -30,50 +35,132 @@ func main() {
 	}
 	defer listener.Close()
 
+	if cfg.crashAfter > 0 {
+		time.AfterFunc(cfg.crashAfter, func() {
+			fmt.Fprintf(os.Stderr, "Simulated crash after %s, exiting with code %d\n", cfg.crashAfter, cfg.crashExitCode)
+			os.Exit(cfg.crashExitCode)
+		})
+	}
+
 	fmt.Printf("Mock worker echo server listening on %s...\n", addr)
+	newMockWorker(cfg).serve(listener)
+}
+
+// mockWorker serves client connections as configured
+type mockWorker struct {
+	cfg      *config
+	sessions *sessionTable
+}
+
+func newMockWorker(cfg *config) *mockWorker {
+	return &mockWorker{cfg: cfg, sessions: newSessionTable(cfg.connectionInfoPath, cfg.connectionInfoFormat)}
+}
 
+func (w *mockWorker) serve(listener net.Listener) {
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
 			fmt.Fprintf(os.Stderr, "Error accepting connection: %v\n", err)
 			continue
 		}
 
-		go handleConnection(conn)
+		go w.handleConnection(conn)
 	}
 }
 
-func handleConnection(conn net.Conn) {
+var httpMethods = [][]byte{[]byte("GET "), []byte("POST "), []byte("HEAD "), []byte("PUT "), []byte("DELETE "), []byte("OPTIONS ")}
+
+func isHTTPRequest(data []byte) bool {
+	for _, m := range httpMethods {
+		if bytes.HasPrefix(data, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *mockWorker) handleConnection(conn net.Conn) {
 	defer conn.Close()
+	s := w.sessions.open(conn)
+	defer w.sessions.close(s)
 	fmt.Printf("Accepted connection from %s\n", conn.RemoteAddr())
 
-	// Read first 1K bytes to print and echo
 	buf := make([]byte, 1024)
-	n, err := conn.Read(buf)
-	if n > 0 {
-		msg := string(buf[:n])
-		fmt.Printf("[%s] Received: %s", conn.RemoteAddr(), msg)
-		if n == 1024 {
-			fmt.Print("... (truncated)")
-		}
-		fmt.Println()
+	for first := true; ; first = false {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			w.sessions.transferred(s, n, 0)
+			if w.dropAfter(conn, s) {
+				return
+			}
 
-		// Send HTTP 200 response instead of echoing
-		response := "HTTP/1.1 200 OK\r\n" +
-			"Content-Type: text/plain\r\n" +
-			"Connection: close\r\n" +
-			"\r\n" +
-			"this is mock tensor-fusion-worker server\n"
+			// HTTP requests get a page and the connection is closed; any
+			// other stream is echoed
+			reply := buf[:n]
+			isHTTP := first && isHTTPRequest(reply)
+			if isHTTP {
+				msg := string(reply)
+				fmt.Printf("[%s] Received: %s", conn.RemoteAddr(), msg)
+				if n == len(buf) {
+					fmt.Print("... (truncated)")
+				}
+				fmt.Println()
 
-		if _, wErr := conn.Write([]byte(response)); wErr != nil {
-			fmt.Fprintf(os.Stderr, "Error sending response to %s: %v\n", conn.RemoteAddr(), wErr)
+				reply = []byte("HTTP/1.1 200 OK\r\n" +
+					"Content-Type: text/plain\r\n" +
+					"Connection: close\r\n" +
+					"\r\n" +
+					"this is mock tensor-fusion-worker server\n")
+			}
+
+			time.Sleep(w.cfg.delay)
+			if !w.write(conn, s, reply) || isHTTP {
+				break
+			}
 		}
-	}
 
-	// Handle read error if it wasn't EOF
-	if err != nil && err != io.EOF {
-		fmt.Fprintf(os.Stderr, "Error reading from %s: %v\n", conn.RemoteAddr(), err)
+		// Handle read error if it wasn't EOF
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(os.Stderr, "Error reading from %s: %v\n", conn.RemoteAddr(), err)
+			}
+			break
+		}
 	}
 
 	fmt.Printf("Closing connection from %s\n", conn.RemoteAddr())
 }
+
+// write sends data, cut short if the connection reaches --drop-after on the
+// way. It reports whether the connection is still open.
+func (w *mockWorker) write(conn net.Conn, s *session, data []byte) bool {
+	if w.cfg.dropAfter > 0 {
+		if left := w.cfg.dropAfter - w.sessions.total(s); int64(len(data)) > left {
+			data = data[:max(left, 0)]
+		}
+	}
+	n, err := conn.Write(data)
+	w.sessions.transferred(s, 0, n)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error sending response to %s: %v\n", conn.RemoteAddr(), err)
+		return false
+	}
+	return !w.dropAfter(conn, s)
+}
+
+// dropAfter resets the connection once it carried --drop-after bytes and
+// reports whether it did
+func (w *mockWorker) dropAfter(conn net.Conn, s *session) bool {
+	if w.cfg.dropAfter == 0 || w.sessions.total(s) < w.cfg.dropAfter {
+		return false
+	}
+	fmt.Printf("Dropping connection from %s after %d bytes\n", conn.RemoteAddr(), w.sessions.total(s))
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		// Close with a reset, as a crashed peer or a broken network would
+		_ = tcp.SetLinger(0)
+	}
+	return true
+}
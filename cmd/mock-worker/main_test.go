@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	env := map[string]string{envDelay: "200ms", envDropAfter: "100", envConnectionInfoPath: "/tmp/w.txt"}
+	cfg, err := parseConfig([]string{"--isolation", "soft", "-p", "9001", "--drop-after=10", "--crash-after", "1m"}, func(k string) string { return env[k] })
+	require.NoError(t, err)
+	assert.Equal(t, 9001, cfg.port)
+	assert.Equal(t, 200*time.Millisecond, cfg.delay)
+	assert.EqualValues(t, 10, cfg.dropAfter, "flags override the environment")
+	assert.Equal(t, time.Minute, cfg.crashAfter)
+	assert.Equal(t, 1, cfg.crashExitCode)
+	assert.Equal(t, "/tmp/w.txt", cfg.connectionInfoPath)
+
+	_, err = parseConfig([]string{"--delay", "soon"}, func(string) string { return "" })
+	assert.Error(t, err)
+	_, err = parseConfig([]string{"--drop-after"}, func(string) string { return "" })
+	assert.Error(t, err)
+}
+
+func startMockWorker(t *testing.T, cfg *config) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+	go newMockWorker(cfg).serve(lis)
+	return lis.Addr().String()
+}
+
+func TestMockWorker_ListsSessionsAndDrops(t *testing.T) {
+	connFile := filepath.Join(t.TempDir(), "worker-1.txt")
+	addr := startMockWorker(t, &config{dropAfter: 10, connectionInfoPath: connFile, connectionInfoFormat: connectionInfoFormatV2})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(reply), "streams are echoed")
+
+	var rec session
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(connFile)
+		if err != nil || len(data) == 0 {
+			return false
+		}
+		require.NoError(t, json.Unmarshal(data, &rec))
+		return rec.BytesOut == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "127.0.0.1", rec.ClientIP)
+	assert.Equal(t, conn.LocalAddr().(*net.TCPAddr).Port, rec.ClientPort)
+	assert.NotEmpty(t, rec.SessionID)
+	assert.EqualValues(t, 4, rec.BytesIn)
+
+	// 8 bytes carried so far; 1 more each way reaches the limit of 10
+	_, err = conn.Write([]byte("xy"))
+	require.NoError(t, err)
+	_, err = bufio.NewReader(conn).ReadString('\n')
+	assert.Error(t, err, "the connection is reset")
+
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(connFile)
+		return len(data) == 0
+	}, 5*time.Second, 10*time.Millisecond, "closed sessions leave the file")
+}
+
+func TestMockWorker_HTTPWithDelay(t *testing.T) {
+	connFile := filepath.Join(t.TempDir(), "worker-1.txt")
+	addr := startMockWorker(t, &config{delay: 100 * time.Millisecond, connectionInfoPath: connFile})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	started := time.Now()
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: worker\r\n\r\n"))
+	require.NoError(t, err)
+	resp, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(started), 100*time.Millisecond)
+	assert.True(t, strings.HasPrefix(string(resp), "HTTP/1.1 200 OK"))
+	assert.Contains(t, string(resp), "mock tensor-fusion-worker")
+}
+
+func TestSessionTable_V1Format(t *testing.T) {
+	connFile := filepath.Join(t.TempDir(), "worker-1.txt")
+	table := newSessionTable(connFile, "")
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	s := table.open(server)
+	s.ClientIP, s.ClientPort = "10.0.0.9", 50000
+	table.transferred(s, 1, 0)
+	data, err := os.ReadFile(connFile)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.9,50000,0\n", string(data))
+}
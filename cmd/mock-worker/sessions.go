@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// session is a client connection, listed in the connection file like the
+// real worker does
+type session struct {
+	SessionID       string    `json:"session_id"`
+	ClientIP        string    `json:"client_ip"`
+	ClientPort      int       `json:"client_port,omitempty"`
+	ClientPID       int       `json:"client_pid,omitempty"`
+	ProtocolVersion string    `json:"protocol_version,omitempty"`
+	ConnectedAt     time.Time `json:"connected_at"`
+	BytesIn         int64     `json:"bytes_in,omitempty"`
+	BytesOut        int64     `json:"bytes_out,omitempty"`
+}
+
+// sessionTable tracks the open sessions and rewrites the connection file on
+// every change
+type sessionTable struct {
+	mu       sync.Mutex
+	path     string
+	format   string
+	sessions []*session
+}
+
+func newSessionTable(path, format string) *sessionTable {
+	t := &sessionTable{path: path, format: format}
+	// A restarted worker starts without sessions
+	t.writeLocked()
+	return t
+}
+
+func (t *sessionTable) open(conn net.Conn) *session {
+	s := &session{SessionID: newSessionID(), ProtocolVersion: "mock", ConnectedAt: time.Now().UTC()}
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		s.ClientIP, s.ClientPort = addr.IP.String(), addr.Port
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sessions = append(t.sessions, s)
+	t.writeLocked()
+	return s
+}
+
+// transferred adds to the bytes a session carried
+func (t *sessionTable) transferred(s *session, in, out int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s.BytesIn += int64(in)
+	s.BytesOut += int64(out)
+	t.writeLocked()
+}
+
+// total returns the bytes a session carried in both directions
+func (t *sessionTable) total(s *session) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return s.BytesIn + s.BytesOut
+}
+
+func (t *sessionTable) close(s *session) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, open := range t.sessions {
+		if open == s {
+			t.sessions = append(t.sessions[:i], t.sessions[i+1:]...)
+			break
+		}
+	}
+	t.writeLocked()
+}
+
+// writeLocked rewrites the connection file, in the v2 JSONL format when the
+// agent reads it and as clientIP,clientPort,clientPID lines otherwise
+func (t *sessionTable) writeLocked() {
+	if t.path == "" {
+		return
+	}
+	var b strings.Builder
+	for _, s := range t.sessions {
+		if t.format == connectionInfoFormatV2 {
+			line, _ := json.Marshal(s)
+			b.Write(line)
+		} else {
+			fmt.Fprintf(&b, "%s,%d,%d", s.ClientIP, s.ClientPort, s.ClientPID)
+		}
+		b.WriteString("\n")
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing connection file %s: %v\n", t.path, err)
+		return
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing connection file %s: %v\n", t.path, err)
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
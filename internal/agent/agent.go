@@ -1,7 +1,6 @@
 package agent
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
@@ -11,11 +10,13 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/NexusGPU/gpu-go/internal/api"
 	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/deps"
 	"github.com/NexusGPU/gpu-go/internal/hypervisor"
 	"github.com/NexusGPU/gpu-go/internal/platform"
 	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
@@ -23,8 +24,6 @@ import (
 )
 
 const (
-	statusReportInterval = 30 * time.Second
-	forceRefreshInterval = 6 * time.Hour
 	// EnvConnectionInfoPath is the environment variable name for connection info file path
 	// Set to worker-specific file: {connectionsDir}/{workerID}.txt
 	// Worker writes connection info to this file, one line per connection
@@ -43,6 +42,12 @@ const (
 	// HardMemLimiterEnv sets memory limit in megabytes
 	HardMemLimiterEnv = "TF_GPU_MEMORY_LIMIT"
 
+	// Fairness environment variables for workers serving several clients
+	// ClientSMLimiterEnv caps the compute of each client in percent (1-100)
+	ClientSMLimiterEnv = "TF_CLIENT_SM_PERCENT_LIMIT"
+	// ClientSchedulingEnv hints how the worker schedules its clients' kernels
+	ClientSchedulingEnv = "TF_CLIENT_SCHEDULING"
+
 	// URL auth environment variables
 	// EnvURLAuth enables URL-based authentication on workers
 	EnvURLAuth = "TF_ENABLE_URL_AUTH"
@@ -53,14 +58,20 @@ const (
 	envCUDAVisibleDevices = "CUDA_VISIBLE_DEVICES"
 	envHIPVisibleDevices  = "HIP_VISIBLE_DEVICES"
 	envROCRVisibleDevices = "ROCR_VISIBLE_DEVICES"
+	envMUSAVisibleDevices = "MUSA_VISIBLE_DEVICES"
+	envMLUVisibleDevices  = "MLU_VISIBLE_DEVICES"
 )
 
 // workerSnapshot captures worker state for change detection
 type workerSnapshot struct {
-	Status   string
-	PID      int
-	Restarts int
-	GPUIDs   []string
+	Status         string
+	PID            int
+	Restarts       int
+	GPUIDs         []string
+	TLSFingerprint string
+	RelayConnected bool
+	Health         string
+	Warm           bool
 }
 
 // gpuSnapshot captures GPU state for change detection
@@ -91,16 +102,98 @@ type Agent struct {
 	hypervisorMgr hypervisor.HypervisorManager
 	reconciler    *hypervisor.Reconciler
 
-	// Dependencies for worker binary
-	workerBinaryPath string
+	// Dependencies for worker binary. Both are guarded by mu; workerExecutables
+	// holds the release workers are being upgraded to.
+	workerBinaryPath  string
+	workerExecutables map[string]string // workerID -> remote-gpu-worker binary
+
+	// Upgrades workers to new remote-gpu-worker releases; nil unless enabled
+	upgrade *workerUpgrader
+
+	// Optional TCP proxy in front of worker ports for usage accounting
+	proxy *connProxy
+
+	// Tunnels to the platform relay for workers shared through it
+	relay *relayClient
+
+	// Holds back stopping disabled and deleted workers until clients left
+	drain *workerDrainer
+
+	// Slots for concurrent `ggo worker logs` streams
+	logStreams chan struct{}
+
+	// The ggo binary that starts workers to capture their output; empty
+	// starts them directly
+	workerLauncher string
+
+	// Site-specific scripts run on lifecycle events; nil without hooks
+	hooks *hookRunner
+
+	// Runs allowlisted remote commands for `ggo agent exec`
+	exec *execRunner
+
+	// Lifecycle events waiting for upload to the platform; nil before Start
+	events *eventQueue
+
+	// Status reports the platform was unreachable for; nil before Start
+	reports *reportQueue
+
+	// Serves GPU, worker and session state to tooling on this host; nil
+	// unless enabled
+	localAPI *localAPI
+
+	// MIG instances of workers with a MIG profile; nil without nvidia-smi
+	mig *migManager
+
+	// GPU settings changed for workers with GPU tuning; nil without
+	// nvidia-smi or rocm-smi
+	gpuTuning *gpuTuningManager
+
+	// Set while a server-requested secret rotation is in progress
+	rotating atomic.Bool
+
+	// Health of the SSE and long-poll transports of the agent's topics
+	transport *transportState
+
+	// Detects stuck subsystems and restarts them, or the agent; nil before
+	// Start
+	watchdog *watchdog
+
+	// Set when the reconciler probes worker health
+	healthProbes bool
 
 	// Change tracking state
 	mu               sync.RWMutex
 	lastForceRefresh time.Time
 	prevWorkers      map[string]*workerSnapshot // workerID -> snapshot
-	prevConnections  map[string][]string        // workerID -> []connectionLine
+	prevConnections  map[string][]string        // workerID -> connectionKeys
 	prevGPUs         map[string]*gpuSnapshot    // gpuID -> snapshot
+	prevGPUHealth    map[string]string          // gpuID -> health flags of the last report
+	gpusDetected     bool                       // prevGPUs holds a detection
 	connectionsDir   string                     // directory containing per-worker connection files
+	lastReportAt     time.Time                  // last status report accepted by the server
+	offlineSince     time.Time                  // first failed report while the platform is unreachable
+	localReporting   ReportSettings             // set with SetReportSettings
+	serverReporting  ReportSettings             // from the server's agent config
+	localWorkerLogs  WorkerLogSettings          // set with SetWorkerLogSettings
+	serverWorkerLogs WorkerLogSettings          // from the server's agent config
+	localDisk        DiskSettings               // set with SetDiskSettings
+	serverDisk       DiskSettings               // from the server's agent config
+	diskUsage        *api.AgentDiskUsage        // measured by the last disk check
+	reportReset      chan struct{}              // signals a changed report interval
+	workerConfigs    []api.WorkerConfig         // workers from the last pulled config
+	relayConfig      *api.RelayConfig           // relay from the last pulled config
+	upgrading        map[string]bool            // workerID -> routed as disabled while upgraded
+	failedOver       map[string]bool            // workerID -> taken over from the HA primary, until the platform acknowledges
+	warm             map[string]bool            // workerID -> kept running while disabled, refusing sessions
+	warmUnproxied    map[string]bool            // workerID -> warm but stopped while disabled, for lack of the proxy
+
+	// Crash capture state
+	crashMu        sync.Mutex
+	crashSnapshots map[string]*crashSnapshot          // workerID -> last observed process state
+	pendingCrashes map[string][]api.WorkerCrashReport // workerID -> reports waiting for the next status upload
+	crashLoops     map[string]*crashLoop              // workerID -> crash loop in progress
+	kernelLog      func(since time.Time) []string
 }
 
 // NewAgent creates a new agent
@@ -108,9 +201,13 @@ func NewAgent(client *api.Client, configMgr *config.Manager) *Agent {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	hostname, _ := os.Hostname()
-	paths := platform.DefaultPaths()
+	// PID, connection and share code files live in the agent's own
+	// directories, so instances on one host do not clobber each other
+	paths := platform.DefaultPaths().WithConfigDir(configMgr.ConfigDir()).WithStateDir(configMgr.StateDir())
+	// Cannot fail without an explicit proxy URL
+	relayDial, _ := newRelayDialer("")
 
-	return &Agent{
+	a := &Agent{
 		client:          client,
 		config:          configMgr,
 		paths:           paths,
@@ -121,7 +218,19 @@ func NewAgent(client *api.Client, configMgr *config.Manager) *Agent {
 		prevConnections: make(map[string][]string),
 		prevGPUs:        make(map[string]*gpuSnapshot),
 		connectionsDir:  paths.ConnectionsDir(),
-	}
+		crashSnapshots:  make(map[string]*crashSnapshot),
+		pendingCrashes:  make(map[string][]api.WorkerCrashReport),
+		crashLoops:      make(map[string]*crashLoop),
+		failedOver:      make(map[string]bool),
+		kernelLog:       readKernelLog,
+		relay:           newRelayClient(relayDial),
+		logStreams:      make(chan struct{}, maxWorkerLogStreams),
+		reportReset:     make(chan struct{}, 1),
+		transport:       newTransportState(),
+	}
+	a.drain = newWorkerDrainer(DefaultDrainGrace, a.workerConnectionCount)
+	a.EnableRemoteExec(nil)
+	return a
 }
 
 // NewAgentWithHypervisor creates a new agent with hypervisor manager
@@ -132,12 +241,21 @@ func NewAgentWithHypervisor(client *api.Client, configMgr *config.Manager, hvMgr
 
 	// Create reconciler
 	agent.reconciler = hypervisor.NewReconciler(hypervisor.ReconcilerConfig{
-		Manager: hvMgr,
+		Manager:     hvMgr,
+		ReadyToStop: agent.readyToStopWorker,
+		OnWorkerStarting: func(workerID string) {
+			agent.fireWorkerHook(HookPreWorkerStart, workerID, true)
+		},
 		OnWorkerStarted: func(workerID string) {
 			klog.Infof("Worker started via reconciler: worker_id=%s", workerID)
+			agent.recordWorkerEvent(api.AgentEventWorkerStarted, workerID, api.AgentEventSeverityInfo, "Worker started", nil)
 		},
 		OnWorkerStopped: func(workerID string) {
 			klog.Infof("Worker stopped via reconciler: worker_id=%s", workerID)
+			agent.fireWorkerHook(HookPostWorkerStop, workerID, false)
+			agent.releaseMIGInstance(workerID)
+			agent.releaseGPUTuning(workerID)
+			agent.recordWorkerEvent(api.AgentEventWorkerStopped, workerID, api.AgentEventSeverityInfo, "Worker stopped", nil)
 		},
 		OnReconcileComplete: func(added, removed, updated int) {
 			klog.V(4).Infof("Reconciliation complete: added=%d removed=%d updated=%d", added, removed, updated)
@@ -147,6 +265,70 @@ func NewAgentWithHypervisor(client *api.Client, configMgr *config.Manager, hvMgr
 	return agent
 }
 
+// EnableConnectionProxy makes the agent own each worker's ListenPort and
+// forward to the worker on a loopback port, so traffic and session time can be
+// attributed per client and share code. Must be called before Start.
+//
+// Workers cannot be told to bind to loopback only; the agent warns when a
+// backend port is reachable from other hosts so it can be firewalled.
+func (a *Agent) EnableConnectionProxy() {
+	a.proxy = newConnProxy(filepath.Join(a.config.StateDir(), proxyPortsFile))
+}
+
+// EnableWorkerTLS makes the connection proxy terminate TLS on worker ports
+// with per-worker certificates obtained according to mode (see TLSMode*).
+// Certificate fingerprints are reported with the worker status so clients can
+// pin them. Must be called after EnableConnectionProxy and before Start.
+func (a *Agent) EnableWorkerTLS(mode string) error {
+	if a.proxy == nil {
+		return fmt.Errorf("worker TLS requires the connection proxy")
+	}
+	if _, err := ParseTLSMode(mode); err != nil {
+		return err
+	}
+	hosts := func() []string {
+		return append([]string{a.hostname}, getNetworkIPs()...)
+	}
+	issue := func(ctx context.Context, workerID string, csrPEM []byte) ([]byte, error) {
+		resp, err := a.client.IssueWorkerCertificate(ctx, a.agentID, workerID, &api.WorkerCertificateRequest{CSR: string(csrPEM)})
+		if err != nil {
+			return nil, err
+		}
+		return []byte(resp.Certificate), nil
+	}
+	a.proxy.certs = newCertManager(filepath.Join(a.config.StateDir(), workerCertsDir), mode, hosts, issue)
+	return nil
+}
+
+// SetRelayProxy routes relay tunnels through an HTTP(S) or SOCKS5 proxy
+// instead of the one configured in the environment. Must be called before
+// Start.
+func (a *Agent) SetRelayProxy(proxyURL string) error {
+	dial, err := newRelayDialer(proxyURL)
+	if err != nil {
+		return err
+	}
+	a.relay.dial = dial
+	return nil
+}
+
+// SetDrainGrace sets how long disabled and deleted workers keep serving their
+// connected clients before they are stopped; 0 stops them right away. It only
+// applies with the connection proxy.
+func (a *Agent) SetDrainGrace(grace time.Duration) {
+	a.drain.SetGrace(grace)
+}
+
+// readyToStopWorker drains a worker only when the connection proxy serves its
+// listen port. Otherwise the worker binds the port itself and would keep
+// accepting new clients while it drains, so it is stopped right away.
+func (a *Agent) readyToStopWorker(workerID string) bool {
+	if a.proxy == nil {
+		return true
+	}
+	return a.drain.ReadyToStop(workerID)
+}
+
 // Register registers the agent with the server using a temporary token.
 // Registration does not send a status report; status is reported only after Start() via statusReportLoop.
 func (a *Agent) Register(tempToken string, gpus []api.GPUInfo) error {
@@ -240,6 +422,21 @@ func (a *Agent) Start() error {
 		klog.Warningf("Failed to write PID file: error=%v", err)
 	}
 
+	// Queue events from the first reconcile on, including those an earlier
+	// agent could not upload
+	a.events = newEventQueue(filepath.Join(a.config.StateDir(), eventsFile))
+	a.reports = newReportQueue(filepath.Join(a.config.StateDir(), reportsFile))
+
+	if a.hypervisorMgr != nil {
+		a.mig = newMIGManager(filepath.Join(a.config.StateDir(), migStateFile))
+		a.gpuTuning = newGPUTuningManager(filepath.Join(a.config.StateDir(), gpuTuningStateFile))
+	}
+
+	// Workers left running by a restart must be known before reconciling
+	if a.hypervisorMgr != nil {
+		a.adoptHandoffWorkers()
+	}
+
 	// Start reconciler if available
 	if a.reconciler != nil {
 		a.reconciler.Start()
@@ -262,11 +459,28 @@ func (a *Agent) Start() error {
 		klog.Errorf("CRITICAL: All config pull attempts failed — workers will NOT start until config is received via SSE. error=%v", pullErr)
 	}
 
-	// Start background tasks
-	a.wg.Add(3)
+	// Start background tasks, watched from now on
+	a.watchdog = newWatchdog()
+	a.wg.Add(6)
 	go a.statusReportLoop()
 	go a.sseConfigListener()
 	go a.sseRestartListener()
+	go a.liveStatusLoop()
+	go a.eventLoop()
+	go a.watchdogLoop()
+	if a.hypervisorMgr != nil {
+		a.wg.Add(2)
+		go a.crashWatchLoop()
+		go a.standbyLoop()
+	}
+	if a.upgrade != nil {
+		a.wg.Add(1)
+		go a.workerUpgradeLoop()
+	}
+	if a.localAPI != nil {
+		a.wg.Add(1)
+		go a.serveLocalAPI()
+	}
 
 	klog.Infof("Agent started: agent_id=%s pid=%d", a.agentID, os.Getpid())
 
@@ -289,6 +503,13 @@ func (a *Agent) Stop() {
 		a.reconciler.Stop()
 	}
 
+	if a.proxy != nil {
+		a.proxy.Stop()
+	}
+	if a.relay != nil {
+		a.relay.Stop()
+	}
+
 	// Stop hypervisor manager
 	if a.hypervisorMgr != nil {
 		if err := a.hypervisorMgr.Stop(); err != nil {
@@ -303,6 +524,9 @@ func (a *Agent) Stop() {
 
 	a.wg.Wait()
 
+	// Remove after the loops exit so the snapshot isn't rewritten
+	a.removeLiveStatus()
+
 	klog.Info("Agent stopped")
 }
 
@@ -315,14 +539,24 @@ func (a *Agent) statusReportLoop() {
 		klog.Errorf("Failed to report initial status: error=%v", err)
 	}
 
-	ticker := time.NewTicker(statusReportInterval)
+	interval := a.reportSettings().Interval
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-a.ctx.Done():
 			return
+		case <-a.reportReset:
+			if next := a.reportSettings().Interval; next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
 		case <-ticker.C:
+			if a.proxy != nil {
+				a.proxy.Retry()
+				a.proxy.EnforceQuotas()
+			}
 			if err := a.reportStatus(); err != nil {
 				klog.Errorf("Failed to report status: error=%v", err)
 			}
@@ -332,6 +566,8 @@ func (a *Agent) statusReportLoop() {
 
 // handleHeartbeatResponse handles WebSocket heartbeat responses
 func (a *Agent) handleHeartbeatResponse(resp *api.HeartbeatResponse) {
+	a.handleSecretRotation(resp.SecretRotation)
+
 	if resp.ConfigVersion > a.configVersion {
 		klog.Infof("Config version changed, pulling new config: old_version=%d new_version=%d", a.configVersion, resp.ConfigVersion)
 
@@ -349,7 +585,7 @@ func (a *Agent) pullConfig() error {
 	}
 
 	// Update local config version and license
-	if err := a.config.UpdateConfigVersion(resp.ConfigVersion, resp.License); err != nil {
+	if err := a.updateLicense(resp.ConfigVersion, resp.License); err != nil {
 		return err
 	}
 
@@ -370,28 +606,34 @@ func (a *Agent) pullConfig() error {
 		return err
 	}
 
-	// Write share codes files for each worker
+	// Write share codes files for each worker; those of warm workers
+	// depend on whether they serve, see syncWarmWorkers
 	for _, w := range resp.Workers {
+		if w.Warm {
+			continue
+		}
 		if err := a.writeShareCodes(w.WorkerID, w.ShareCodes); err != nil {
 			klog.Warningf("Failed to write share codes for worker %s: %v", w.WorkerID, err)
 		}
 	}
 
+	a.applyWorkerLogConfig(resp.WorkerLogs)
+	a.applyDiskConfig(resp.Disk)
+
 	// Reconcile workers with hypervisor if available
 	if a.reconciler != nil {
-		infos, err := a.convertToWorkerInfos(resp.Workers)
-		if err != nil {
-			return fmt.Errorf("failed to convert worker infos (workers won't start): %w", err)
-		}
-		klog.Infof("Setting desired workers for reconciler: count=%d", len(infos))
-		for _, info := range infos {
-			klog.Infof("  worker=%s executable=%s", info.WorkerUID, info.WorkerRunningInfo.Executable)
+		a.mu.Lock()
+		a.workerConfigs = resp.Workers
+		a.relayConfig = resp.Relay
+		a.mu.Unlock()
+		if err := a.applyWorkers(); err != nil {
+			return err
 		}
-		a.reconciler.SetDesiredWorkers(infos)
 	} else {
 		klog.Infof("No reconciler available (client-only mode), skipping worker reconciliation")
 	}
 
+	a.applyReportingConfig(resp.Reporting)
 	a.configVersion = resp.ConfigVersion
 
 	klog.Infof("Config pulled successfully: version=%d workers=%d", resp.ConfigVersion, len(resp.Workers))
@@ -399,6 +641,37 @@ func (a *Agent) pullConfig() error {
 	return nil
 }
 
+// applyWorkers hands the workers of the last pulled config to the
+// reconciler, the connection proxy and the relay
+func (a *Agent) applyWorkers() error {
+	a.mu.RLock()
+	workers, relay := a.workerConfigs, a.relayConfig
+	a.mu.RUnlock()
+	workers = a.standbyWorkers(workers)
+	a.syncWarmWorkers(workers)
+
+	infos, err := a.convertToWorkerInfos(workers)
+	if err != nil {
+		return fmt.Errorf("failed to convert worker infos (workers won't start): %w", err)
+	}
+	klog.Infof("Setting desired workers for reconciler: count=%d", len(infos))
+	for _, info := range infos {
+		klog.Infof("  worker=%s executable=%s", info.WorkerUID, info.WorkerRunningInfo.Executable)
+	}
+	if a.drain != nil {
+		a.drain.Sync(workers)
+	}
+	a.reconciler.SetDesiredWorkers(infos)
+	routed := a.routedWorkers(workers)
+	if a.proxy != nil {
+		a.proxy.Sync(a.proxiedWorkers(routed))
+	}
+	if a.relay != nil {
+		a.relay.Sync(relay, routed)
+	}
+	return nil
+}
+
 // convertToWorkerInfos converts API worker configs to hypervisor WorkerInfo
 func (a *Agent) convertToWorkerInfos(apiWorkers []api.WorkerConfig) ([]*hvApi.WorkerInfo, error) {
 	// Load config to get license information
@@ -442,7 +715,7 @@ func (a *Agent) convertToWorkerInfos(apiWorkers []api.WorkerConfig) ([]*hvApi.Wo
 
 	infos := make([]*hvApi.WorkerInfo, 0, len(apiWorkers))
 	for _, w := range apiWorkers {
-		if !w.Enabled {
+		if !w.Enabled && !a.keepsWarm(w) {
 			continue
 		}
 
@@ -464,10 +737,11 @@ func (a *Agent) convertToWorkerInfos(apiWorkers []api.WorkerConfig) ([]*hvApi.Wo
 
 		// Set TF_LOG_PATH for tensor-fusion-worker to save logs to a specific file
 		// Use timestamp in filename to create a new log file for each worker restart
-		logsDir := filepath.Join(a.config.StateDir(), "logs")
+		logsDir := workerLogsDir(a.config.StateDir())
 		if err := os.MkdirAll(logsDir, 0755); err != nil {
 			klog.Warningf("Failed to create logs directory: path=%s error=%v", logsDir, err)
 		}
+		pruneWorkerLogs(logsDir, w.WorkerID, a.workerLogSettings().MaxFiles)
 		timestamp := time.Now().Format("2006-01-02_15-04-05")
 		workerLogPath := filepath.Join(logsDir, fmt.Sprintf("worker-%s-%s.log", w.WorkerID, timestamp))
 		envVars["TF_LOG_PATH"] = workerLogPath
@@ -477,34 +751,73 @@ func (a *Agent) convertToWorkerInfos(apiWorkers []api.WorkerConfig) ([]*hvApi.Wo
 		// Worker will write connection info to this file, one line per connection
 		connectionInfoPath := filepath.Join(a.connectionsDir, w.WorkerID+".txt")
 		envVars[EnvConnectionInfoPath] = connectionInfoPath
+		envVars[EnvConnectionInfoFormat] = ConnectionInfoFormatV2
 		klog.V(4).Infof("Worker %s: Set %s=%s", w.WorkerID, EnvConnectionInfoPath, connectionInfoPath)
 
-		// Set hard limiter environment variables for Fractional GPU support
-		// TODO: use MIG for partitioned
-		if w.ComputePercent > 0 {
-			envVars[HardSMLimiterEnv] = fmt.Sprintf("%d", w.ComputePercent)
-			klog.Infof("Worker %s: Setting compute limit to %d%% (%s=%d)",
-				w.WorkerID, w.ComputePercent, HardSMLimiterEnv, w.ComputePercent)
+		vendor := resolveWorkerVendor(w.WorkerID, w.GPUIDs, gpuVendorByID)
+		gpuIndices := resolveWorkerGPUIndices(w.WorkerID, w.GPUIndices, w.GPUIDs, gpuIndexByID)
+		if w.MIGProfile != "" {
+			// The MIG instance partitions the GPU in hardware, replacing the
+			// software limiters
+			migUUID, err := a.ensureMIGInstance(w)
+			if err != nil {
+				klog.Errorf("Skipping worker without MIG instance: worker_id=%s profile=%s error=%v", w.WorkerID, w.MIGProfile, err)
+				continue
+			}
+			envVars[envCUDAVisibleDevices] = migUUID
+		} else {
+			// Set hard limiter environment variables for Fractional GPU support
+			if w.ComputePercent > 0 {
+				envVars[HardSMLimiterEnv] = fmt.Sprintf("%d", w.ComputePercent)
+				klog.Infof("Worker %s: Setting compute limit to %d%% (%s=%d)",
+					w.WorkerID, w.ComputePercent, HardSMLimiterEnv, w.ComputePercent)
+			}
+			if w.VRAMMb > 0 {
+				envVars[HardMemLimiterEnv] = fmt.Sprintf("%d", w.VRAMMb)
+				klog.Infof("Worker %s: Setting memory limit to %d MB (%s=%d)",
+					w.WorkerID, w.VRAMMb, HardMemLimiterEnv, w.VRAMMb)
+			}
+
+			for k, v := range buildGPUVisibilityEnv(vendor, gpuIndices) {
+				envVars[k] = v
+			}
 		}
-		if w.VRAMMb > 0 {
-			envVars[HardMemLimiterEnv] = fmt.Sprintf("%d", w.VRAMMb)
-			klog.Infof("Worker %s: Setting memory limit to %d MB (%s=%d)",
-				w.WorkerID, w.VRAMMb, HardMemLimiterEnv, w.VRAMMb)
+
+		if w.Warm {
+			envVars[EnvEagerInit] = "1"
 		}
 
-		vendor := resolveWorkerVendor(w.WorkerID, w.GPUIDs, gpuVendorByID)
-		gpuIndices := resolveWorkerGPUIndices(w.WorkerID, w.GPUIndices, w.GPUIDs, gpuIndexByID)
-		for k, v := range buildGPUVisibilityEnv(vendor, gpuIndices) {
+		for k, v := range buildFairnessEnv(w.Fairness) {
 			envVars[k] = v
+			klog.Infof("Worker %s: Setting client fairness %s=%s", w.WorkerID, k, v)
+		}
+
+		// A GPU that cannot be tuned still serves the worker, only uncapped
+		if err := a.ensureGPUTuning(w, vendor, gpuIndices); err != nil {
+			klog.Warningf("Failed to apply GPU tuning, starting worker untuned: worker_id=%s error=%v", w.WorkerID, err)
+		}
+
+		workerPort := w.ListenPort
+		if a.proxy != nil {
+			backendPort, err := a.proxy.backendPort(w.WorkerID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set up connection proxy for worker %s: %w", w.WorkerID, err)
+			}
+			workerPort = backendPort
+		}
+
+		if len(w.Env) > 0 {
+			envVars = mergeWorkerEnv(w.WorkerID, envVars, w.Env)
 		}
 
 		info.WorkerRunningInfo = &hvApi.WorkerRunningInfo{
 			Type:       hvApi.WorkerRuntimeTypeProcess,
-			Executable: a.workerBinaryPath,
-			Args:       []string{"-p", fmt.Sprintf("%d", w.ListenPort), "-n", "native"},
+			Executable: a.workerExecutable(w.WorkerID),
+			Args:       []string{"-p", fmt.Sprintf("%d", workerPort), "-n", "native"},
 			WorkingDir: a.config.StateDir(),
 			Env:        envVars,
 		}
+		a.captureWorkerOutput(w.WorkerID, info.WorkerRunningInfo)
 		klog.Infof("Set environment variables for worker %s: TF_LICENSE (len=%d, empty=%v), TF_LICENSE_SIGN (len=%d, empty=%v)",
 			w.WorkerID, len(cfg.License.Plain), cfg.License.Plain == "", len(cfg.License.Encrypted), cfg.License.Encrypted == "")
 
@@ -525,7 +838,7 @@ func (a *Agent) loadGPUVendorByID() (map[string]string, error) {
 		if id == "" {
 			continue
 		}
-		vendor := strings.ToLower(strings.TrimSpace(gpu.Vendor))
+		vendor := deps.NormalizeVendorSlug(gpu.Vendor)
 		if vendor == "" {
 			continue
 		}
@@ -652,9 +965,13 @@ func buildGPUVisibilityEnv(vendor string, gpuIndices []int) map[string]string {
 	switch vendor {
 	case vendorNVIDIA:
 		env[envCUDAVisibleDevices] = value
-	case vendorAMD, "hygon":
+	case vendorAMD, vendorHygon:
 		env[envHIPVisibleDevices] = value
 		env[envROCRVisibleDevices] = value
+	case vendorMThreads:
+		env[envMUSAVisibleDevices] = value
+	case vendorCambricon:
+		env[envMLUVisibleDevices] = value
 	}
 
 	return env
@@ -679,13 +996,14 @@ func normalizeWorkerStatus(status string) string {
 	}
 }
 
-// shouldForceRefresh checks if 6 hours have passed since last force refresh
+// shouldForceRefresh checks if the force refresh interval has passed since
+// the last force refresh
 func (a *Agent) shouldForceRefresh() bool {
 	a.mu.RLock()
 	lastRefresh := a.lastForceRefresh
 	a.mu.RUnlock()
 
-	return time.Since(lastRefresh) >= forceRefreshInterval
+	return time.Since(lastRefresh) >= a.reportSettings().ForceRefresh
 }
 
 // updateForceRefreshTime updates the last force refresh timestamp
@@ -720,7 +1038,6 @@ func (a *Agent) detectGPUChanges(devices []*hvApi.DeviceInfo) (map[string]bool,
 	}
 
 	a.mu.Lock()
-	defer a.mu.Unlock()
 
 	// Compare with previous state
 	currentMap := make(map[string]*gpuSnapshot)
@@ -756,11 +1073,46 @@ func (a *Agent) detectGPUChanges(devices []*hvApi.DeviceInfo) (map[string]bool,
 	}
 
 	// Update previous state
+	prevGPUs, detected := a.prevGPUs, a.gpusDetected
 	a.prevGPUs = currentMap
+	a.gpusDetected = true
+	a.mu.Unlock()
+
+	// The first detection after start is not a change
+	if detected {
+		diff := diffGPUs(prevGPUs, currentMap)
+		a.fireGPUChangeHook(diff)
+		a.recordGPUChanges(diff)
+	}
 
 	return changes, nil
 }
 
+// recordGPUChanges adds detected GPU changes to the history of the state store
+func (a *Agent) recordGPUChanges(diff []HookGPU) {
+	if len(diff) == 0 {
+		return
+	}
+	now := time.Now()
+	changes := make([]config.GPUChange, len(diff))
+	for i, gpu := range diff {
+		changes[i] = config.GPUChange{
+			GPUID:         gpu.GPUID,
+			At:            now,
+			Change:        gpu.Change,
+			GPUIndex:      gpu.Index,
+			Vendor:        gpu.Vendor,
+			Model:         gpu.Model,
+			VRAMMb:        gpu.VRAMMb,
+			DriverVersion: gpu.DriverVersion,
+			CUDAVersion:   gpu.CUDAVersion,
+		}
+	}
+	if err := a.config.Update(func(tx config.StoreTx) error { return tx.AddGPUChanges(changes) }); err != nil {
+		klog.Warningf("Failed to record GPU changes: count=%d error=%v", len(changes), err)
+	}
+}
+
 // detectWorkerChanges compares current workers with previous state
 // Returns workerID -> changed flag
 func (a *Agent) detectWorkerChanges(currentWorkers []*hvApi.WorkerInfo) map[string]bool {
@@ -781,11 +1133,22 @@ func (a *Agent) detectWorkerChanges(currentWorkers []*hvApi.WorkerInfo) map[stri
 			pid = int(w.WorkerRunningInfo.PID)
 			restarts = w.WorkerRunningInfo.Restarts
 		}
+		status, _ = a.drainStatus(w.WorkerUID, status)
 		currentMap[w.WorkerUID] = &workerSnapshot{
 			Status:   status,
 			PID:      pid,
 			Restarts: restarts,
 			GPUIDs:   w.AllocatedDevices,
+			Warm:     a.warm[w.WorkerUID],
+		}
+		if a.proxy != nil {
+			currentMap[w.WorkerUID].TLSFingerprint = a.proxy.TLSFingerprint(w.WorkerUID)
+		}
+		if a.relay != nil {
+			currentMap[w.WorkerUID].RelayConnected = a.relay.Connected(w.WorkerUID)
+		}
+		if health := a.workerHealth(w.WorkerUID); health != nil {
+			currentMap[w.WorkerUID].Health = health.Status
 		}
 	}
 
@@ -800,6 +1163,10 @@ func (a *Agent) detectWorkerChanges(currentWorkers []*hvApi.WorkerInfo) map[stri
 		if current.Status != prev.Status ||
 			current.PID != prev.PID ||
 			current.Restarts != prev.Restarts ||
+			current.TLSFingerprint != prev.TLSFingerprint ||
+			current.RelayConnected != prev.RelayConnected ||
+			current.Health != prev.Health ||
+			current.Warm != prev.Warm ||
 			!slices.Equal(current.GPUIDs, prev.GPUIDs) {
 			changes[workerID] = true
 		} else {
@@ -821,11 +1188,11 @@ func (a *Agent) detectWorkerChanges(currentWorkers []*hvApi.WorkerInfo) map[stri
 }
 
 // readConnectionsFromDir reads connection files from the connections directory
-// Each worker has its own file: {connectionsDir}/{workerID}.txt
-// File format: one connection per line: clientIP,clientPort,clientPID
-// Returns workerID -> []connectionLine
-func (a *Agent) readConnectionsFromDir() (map[string][]string, error) {
-	connections := make(map[string][]string)
+// Each worker has its own file: {connectionsDir}/{workerID}.txt, in the
+// format readWorkerConnectionFile reads
+// Returns workerID -> connections
+func (a *Agent) readConnectionsFromDir() (map[string][]api.ConnectionInfo, error) {
+	connections := make(map[string][]api.ConnectionInfo)
 
 	klog.V(5).Infof("Reading connections from directory: %s", a.connectionsDir)
 	klog.Infof("[DEBUG] Scanning connections directory: %s", a.connectionsDir)
@@ -859,18 +1226,18 @@ func (a *Agent) readConnectionsFromDir() (map[string][]string, error) {
 		// Read connection lines from worker's file
 		filePath := filepath.Join(a.connectionsDir, entry.Name())
 		klog.Infof("[DEBUG] Reading connection file for worker %s: %s", workerID, filePath)
-		connLines, err := a.readWorkerConnectionFile(filePath)
+		conns, err := readWorkerConnectionFile(filePath)
 		if err != nil {
 			klog.V(4).Infof("Failed to read connection file for worker %s: %v", workerID, err)
 			klog.Infof("[DEBUG] Failed to read connection file for worker %s: %v", workerID, err)
 			continue
 		}
 
-		klog.Infof("[DEBUG] Worker %s: Read %d connection line(s) from file", workerID, len(connLines))
-		if len(connLines) > 0 {
-			connections[workerID] = connLines
-			klog.V(4).Infof("Worker %s has %d active connection(s): %v", workerID, len(connLines), connLines)
-			klog.Infof("[DEBUG] Worker %s has %d active connection(s): %v", workerID, len(connLines), connLines)
+		klog.Infof("[DEBUG] Worker %s: Read %d connection(s) from file", workerID, len(conns))
+		if len(conns) > 0 {
+			connections[workerID] = conns
+			klog.V(4).Infof("Worker %s has %d active connection(s): %v", workerID, len(conns), connectionKeys(conns))
+			klog.Infof("[DEBUG] Worker %s has %d active connection(s): %v", workerID, len(conns), connectionKeys(conns))
 		}
 	}
 
@@ -890,41 +1257,6 @@ func (a *Agent) readConnectionsFromDir() (map[string][]string, error) {
 	return connections, nil
 }
 
-// readWorkerConnectionFile reads a single worker's connection file
-// Format: one connection per line: clientIP,clientPort,clientPID
-func (a *Agent) readWorkerConnectionFile(filePath string) (lines []string, err error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close file: %w", closeErr)
-		}
-	}()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		// Validate format: should have at least clientIP
-		if parts := strings.Split(line, ","); len(parts) >= 1 && parts[0] != "" {
-			lines = append(lines, line)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to scan file: %w", err)
-	}
-
-	return lines, nil
-}
-
 // detectConnectionChanges compares current connections with previous state
 // Returns workerID -> changed flag
 func (a *Agent) detectConnectionChanges() (map[string]bool, error) {
@@ -940,15 +1272,15 @@ func (a *Agent) detectConnectionChanges() (map[string]bool, error) {
 	defer a.mu.Unlock()
 
 	// Check for changed or new connections
-	for workerID, current := range currentConnections {
+	currentKeys := make(map[string][]string, len(currentConnections))
+	for workerID, conns := range currentConnections {
+		current := connectionKeys(conns)
+		currentKeys[workerID] = current
 		prev, exists := a.prevConnections[workerID]
 		if !exists {
 			changes[workerID] = true
 			continue
 		}
-		// Sort and compare
-		slices.Sort(current)
-		slices.Sort(prev)
 		if !slices.Equal(current, prev) {
 			changes[workerID] = true
 		} else {
@@ -964,58 +1296,24 @@ func (a *Agent) detectConnectionChanges() (map[string]bool, error) {
 	}
 
 	// Update previous state
-	a.prevConnections = currentConnections
+	a.prevConnections = currentKeys
 
 	return changes, nil
 }
 
-// parseConnectionsToAPI converts connection strings to API ConnectionInfo
-// Input format per line: clientIP,clientPort,clientPID
-func parseConnectionsToAPI(connectionLines []string) []api.ConnectionInfo {
-	connections := make([]api.ConnectionInfo, 0, len(connectionLines))
-	for _, line := range connectionLines {
-		parts := strings.Split(line, ",")
-		if len(parts) < 1 || parts[0] == "" {
-			continue
-		}
-		// Trim whitespace and null bytes from IP address
-		clientIP := strings.Trim(strings.TrimSpace(parts[0]), "\x00")
-		if clientIP == "" {
-			continue
-		}
-		var clientPort, clientPID int
-		if len(parts) >= 2 {
-			// Trim whitespace and null bytes from port
-			portStr := strings.Trim(strings.TrimSpace(parts[1]), "\x00")
-			if port, err := strconv.Atoi(portStr); err == nil {
-				clientPort = port
-			}
-		}
-		if len(parts) >= 3 {
-			// Trim whitespace and null bytes from PID
-			pidStr := strings.Trim(strings.TrimSpace(parts[2]), "\x00")
-			if pid, err := strconv.Atoi(pidStr); err == nil {
-				clientPID = pid
-			}
-		}
-		connections = append(connections, api.ConnectionInfo{
-			ClientIP:    clientIP,
-			ClientPort:  clientPort,
-			ClientPID:   clientPID,
-			ConnectedAt: time.Now(),
-		})
-	}
-	return connections
-}
-
 // reportStatus reports current status to the server
 func (a *Agent) reportStatus() error {
 	klog.Infof("Reporting agent status to server: agent_id=%s", a.agentID)
+	ctx := a.ctx
+	if a.watchdog != nil {
+		ctx = a.watchdog.beginStatusPass(a.ctx)
+		defer a.watchdog.endStatusPass()
+	}
 
-	// Check if we should force refresh (every 6 hours)
+	// Check if we should force refresh (every 6 hours by default)
 	forceRefresh := a.shouldForceRefresh()
 	if forceRefresh {
-		klog.V(4).Infof("Force refresh triggered (6-hour interval)")
+		klog.V(4).Infof("Force refresh triggered")
 		a.updateForceRefreshTime()
 	}
 
@@ -1039,6 +1337,20 @@ func (a *Agent) reportStatus() error {
 	if err != nil {
 		return err
 	}
+	// Crash reports and proxy usage were drained into the worker status; put
+	// them back unless the report is actually delivered
+	delivered := false
+	defer func() {
+		if delivered {
+			return
+		}
+		for _, w := range workerStatuses {
+			a.restoreCrashes(w.WorkerID, w.Crashes)
+			if a.proxy != nil {
+				a.proxy.RestoreUsage(w.WorkerID, w.Usage)
+			}
+		}
+	}()
 
 	// 5. Get license expiration
 	licenseExpiration, err := a.getLicenseExpiration()
@@ -1046,27 +1358,53 @@ func (a *Agent) reportStatus() error {
 		return err
 	}
 
-	// 6. Collect metrics (best-effort, never blocks status report)
+	// A network self-test run since the last report rides along with it
+	netTest := a.pendingNetTest()
+
+	// In changes-only mode, an unchanged status is replaced by a keepalive
 	now := time.Now()
+	if settings := a.reportSettings(); settings.changesOnly() && netTest == nil && statusUnchanged(gpuStatuses, workerStatuses) {
+		return a.sendKeepalive(ctx, now, licenseExpiration, settings.Keepalive)
+	}
+
+	// 6. Collect metrics (best-effort, never blocks status report)
 	metricsStr := a.collectMetricsLineProtocol(gpuStatuses, workerStatuses, now)
 
 	// 7. Send request
 	req := &api.AgentStatusRequest{
+		ReportID:          newReportID(),
 		Timestamp:         now,
 		GPUs:              gpuStatuses,
 		Workers:           workerStatuses,
 		LicenseExpiration: licenseExpiration,
+		LicenseStatus:     LicenseStatus(licenseExpiration, now),
 		Metrics:           metricsStr,
+		Disk:              a.lastDiskUsage(),
+	}
+	if netTest != nil {
+		req.NetTest = netTest.Summary()
 	}
 
-	resp, err := a.client.ReportAgentStatus(a.ctx, a.agentID, req)
+	resp, err := a.client.ReportAgentStatus(ctx, a.agentID, req)
 	if err != nil {
+		a.reportFailed(req, err)
 		return err
 	}
+	delivered = true
+	if netTest != nil {
+		a.netTestReported(netTest, now)
+	}
+
+	a.mu.Lock()
+	a.lastReportAt = now
+	a.mu.Unlock()
 
 	// 8. Handle response
 	a.handleReportResponse(resp)
 
+	// 9. Deliver reports queued while the platform was unreachable
+	a.reportDelivered()
+
 	return nil
 }
 
@@ -1080,12 +1418,22 @@ func (a *Agent) collectGPUStatus(forceRefresh bool) ([]api.GPUStatus, map[string
 
 	// Get current device info from hypervisor if available
 	var liveDevices []*hvApi.DeviceInfo
+	var liveMetrics map[string]*hvApi.GPUUsageMetrics
+	devicesListed := false
 	if a.hypervisorMgr != nil && a.hypervisorMgr.IsStarted() {
 		var err error
 		liveDevices, err = a.hypervisorMgr.ListDevices()
 		if err != nil {
 			klog.Warningf("Failed to list devices from hypervisor: %v", err)
+		} else {
+			devicesListed = true
+		}
+		// Metrics only feed health flags here, so failing is not worth a warning
+		hvMetrics, err := a.hypervisorMgr.GetDeviceMetrics()
+		if err != nil {
+			klog.V(4).Infof("Failed to collect GPU metrics for health: %v", err)
 		}
+		liveMetrics = metricsByGPU(hvMetrics)
 	}
 
 	// Create map for live devices
@@ -1104,6 +1452,14 @@ func (a *Agent) collectGPUStatus(forceRefresh bool) ([]api.GPUStatus, map[string
 		}
 	}
 
+	var migGPUs map[string]*migGPU
+	if a.mig != nil {
+		migGPUs, err = a.mig.inventory(a.ctx)
+		if err != nil {
+			klog.Warningf("Failed to list MIG instances: error=%v", err)
+		}
+	}
+
 	// Build GPU status
 	gpuStatuses := make([]api.GPUStatus, len(gpuConfigs))
 	for i, gpu := range gpuConfigs {
@@ -1123,6 +1479,30 @@ func (a *Agent) collectGPUStatus(forceRefresh bool) ([]api.GPUStatus, map[string
 
 		gpuChanged := forceRefresh || gpuChanges[gpu.GPUID]
 
+		_, listed := liveDeviceMap[normalizeGPUID(gpu.GPUID)]
+		health := gpuHealth(devicesListed, listed, liveMetrics[normalizeGPUID(gpu.GPUID)])
+		if a.gpuHealthChanged(gpu.GPUID, health) {
+			if len(health) > 0 {
+				klog.Warningf("GPU is unhealthy: gpu_id=%s health=%v", gpu.GPUID, health)
+			} else {
+				klog.Infof("GPU is healthy again: gpu_id=%s", gpu.GPUID)
+			}
+			gpuChanged = true
+			gpuChanges[gpu.GPUID] = true
+		}
+
+		var migCapable, migEnabled bool
+		var partitions []api.GPUPartition
+		if migGPU := migGPUs[normalizeGPUID(gpu.GPUID)]; migGPU != nil {
+			var partitionsChanged bool
+			migCapable, migEnabled = migGPU.Capable, migGPU.Enabled
+			partitions, partitionsChanged = a.mig.partitions(migGPU)
+			if partitionsChanged {
+				gpuChanged = true
+				gpuChanges[gpu.GPUID] = true
+			}
+		}
+
 		gpuStatuses[i] = api.GPUStatus{
 			GPUID:         gpu.GPUID,
 			GPUIndex:      gpuIndex,
@@ -1133,6 +1513,10 @@ func (a *Agent) collectGPUStatus(forceRefresh bool) ([]api.GPUStatus, map[string
 			DriverVersion: driverVer,
 			CUDAVersion:   cudaVer,
 			GPUChanged:    gpuChanged,
+			MIGCapable:    migCapable,
+			MIGEnabled:    migEnabled,
+			Partitions:    partitions,
+			Health:        health,
 		}
 	}
 
@@ -1143,7 +1527,7 @@ func (a *Agent) collectGPUStatus(forceRefresh bool) ([]api.GPUStatus, map[string
 func (a *Agent) collectWorkerStatus(
 	forceRefresh bool,
 	connectionChanges map[string]bool,
-	currentConnections map[string][]string,
+	currentConnections map[string][]api.ConnectionInfo,
 	gpuChanges map[string]bool,
 ) ([]api.WorkerStatus, error) {
 	// If hypervisor is available, use it as SSoT
@@ -1158,7 +1542,7 @@ func (a *Agent) collectWorkerStatus(
 func (a *Agent) collectWorkerStatusFromHypervisor(
 	forceRefresh bool,
 	connectionChanges map[string]bool,
-	currentConnections map[string][]string,
+	currentConnections map[string][]api.ConnectionInfo,
 	gpuChanges map[string]bool,
 ) ([]api.WorkerStatus, error) {
 	hvWorkers := a.hypervisorMgr.ListWorkers()
@@ -1205,6 +1589,7 @@ func (a *Agent) collectWorkerStatusFromHypervisor(
 		} else {
 			stoppedCount++
 		}
+		status, drainDeadline := a.drainStatus(w.WorkerUID, status)
 
 		// Compute change flags
 		workerChanged := forceRefresh || workerChanges[w.WorkerUID]
@@ -1214,14 +1599,26 @@ func (a *Agent) collectWorkerStatusFromHypervisor(
 		// Get connections for this worker
 		// Initialize as empty slice (not nil) so it marshals to [] instead of null in JSON
 		connections := make([]api.ConnectionInfo, 0)
-		if connLines, ok := currentConnections[w.WorkerUID]; ok {
-			connections = parseConnectionsToAPI(connLines)
+		if conns, ok := currentConnections[w.WorkerUID]; ok {
+			connections = conns
+			if a.proxy != nil {
+				a.proxy.RewriteConnections(w.WorkerUID, connections)
+			}
 			if len(connections) > 0 {
 				klog.V(4).Infof("Worker %s: Reporting %d connection(s) to server", w.WorkerUID, len(connections))
 				klog.Infof("[DEBUG] Worker %s: Sending %d connection(s) to server: %+v", w.WorkerUID, len(connections), connections)
 			}
 		}
 
+		var usage []api.ShareUsage
+		var tlsFingerprint string
+		if a.proxy != nil {
+			usage = a.proxy.DrainUsage(w.WorkerUID)
+			tlsFingerprint = a.proxy.TLSFingerprint(w.WorkerUID)
+		}
+		relayConnected := a.relay != nil && a.relay.Connected(w.WorkerUID)
+		crashes := a.takeCrashes(w.WorkerUID)
+
 		gpuIndices := resolveWorkerGPUIndices(w.WorkerUID, nil, w.AllocatedDevices, gpuIndexByID)
 		workerStatuses = append(workerStatuses, api.WorkerStatus{
 			WorkerID:          w.WorkerUID,
@@ -1231,6 +1628,14 @@ func (a *Agent) collectWorkerStatusFromHypervisor(
 			GPUIDs:            w.AllocatedDevices,
 			GPUIndices:        gpuIndices,
 			Connections:       connections,
+			Usage:             usage,
+			Crashes:           crashes,
+			CrashLoop:         a.workerCrashLoop(w.WorkerUID, time.Now()),
+			TLSFingerprint:    tlsFingerprint,
+			RelayConnected:    relayConnected,
+			DrainDeadline:     drainDeadline,
+			Health:            a.workerHealth(w.WorkerUID),
+			Warm:              a.isWarm(w.WorkerUID),
 			WorkerChanged:     &workerChanged,
 			ConnectionChanged: &connectionChanged,
 			GPUChanged:        &gpuChanged,
@@ -1250,7 +1655,7 @@ func (a *Agent) collectWorkerStatusFromHypervisor(
 // collectWorkerStatusFromConfig gets worker status from local config (fallback)
 func (a *Agent) collectWorkerStatusFromConfig(
 	forceRefresh bool,
-	currentConnections map[string][]string,
+	currentConnections map[string][]api.ConnectionInfo,
 	gpuChanges map[string]bool,
 ) ([]api.WorkerStatus, error) {
 	workerConfigs, err := a.config.LoadWorkers()
@@ -1275,8 +1680,8 @@ func (a *Agent) collectWorkerStatusFromConfig(
 		if w.Connections != nil {
 			connections = w.Connections
 		}
-		if connLines, ok := currentConnections[w.WorkerID]; ok {
-			connections = parseConnectionsToAPI(connLines)
+		if conns, ok := currentConnections[w.WorkerID]; ok {
+			connections = conns
 			if len(connections) > 0 {
 				klog.V(4).Infof("Worker %s: Reporting %d connection(s) to server (config fallback)", w.WorkerID, len(connections))
 			}
@@ -1323,17 +1728,28 @@ func (a *Agent) handleReportResponse(resp *api.AgentStatusResponse) {
 	// Update license if server returned a new one
 	if resp.License != nil {
 		klog.Infof("Server returned new license, updating config")
-		if err := a.config.UpdateConfigVersion(a.configVersion, *resp.License); err != nil {
+		if err := a.updateLicense(a.configVersion, *resp.License); err != nil {
 			klog.Errorf("Failed to update license: error=%v", err)
 		}
 	}
 
 	// Write share codes if server returned them
 	for workerID, codes := range resp.WorkerShareCodes {
+		if a.isWarm(workerID) {
+			continue
+		}
 		if err := a.writeShareCodes(workerID, codes); err != nil {
 			klog.Warningf("Failed to write share codes for worker %s: %v", workerID, err)
 		}
+		if a.proxy != nil {
+			a.proxy.UpdateShareCodes(workerID, codes)
+		}
 	}
+	if a.proxy != nil {
+		a.proxy.UpdateQuotas(resp.ShareQuotas)
+	}
+
+	a.handleSecretRotation(resp.SecretRotation)
 
 	// Pull new config if version changed
 	if resp.ConfigVersion > a.configVersion {
@@ -1344,6 +1760,20 @@ func (a *Agent) handleReportResponse(resp *api.AgentStatusResponse) {
 	}
 }
 
+// updateLicense saves the config version and license, running the license
+// renewal hooks when the license changed
+func (a *Agent) updateLicense(version int, license api.License) error {
+	var previous string
+	if cfg, err := a.config.LoadConfig(); err == nil && cfg != nil {
+		previous = cfg.License.Plain
+	}
+	if err := a.config.UpdateConfigVersion(version, license); err != nil {
+		return err
+	}
+	a.fireLicenseRenewalHook(previous, license.Plain)
+	return nil
+}
+
 // anyGPUChanged checks if any GPU in the list has changed
 func (a *Agent) anyGPUChanged(gpuIDs []string, gpuChanges map[string]bool) bool {
 	for _, gpuID := range gpuIDs {
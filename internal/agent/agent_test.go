@@ -453,6 +453,15 @@ func (m *mockHypervisorManager) RegisterWorkerHandler(handler framework.WorkerCh
 func (m *mockHypervisorManager) RegisterDeviceHandler(handler framework.DeviceChangeHandler) {
 }
 
+func (m *mockHypervisorManager) Detach() []*hvApi.WorkerInfo {
+	m.started = false
+	return nil
+}
+
+func (m *mockHypervisorManager) AdoptWorkers(workers []*hvApi.WorkerInfo) int {
+	return 0
+}
+
 func TestAgent_DetectChanges(t *testing.T) {
 	// Initialize mock hypervisor
 	mockHv := &mockHypervisorManager{
@@ -595,6 +604,7 @@ func TestAgent_ConvertToWorkerInfos_IncludesConnectionInfoPath(t *testing.T) {
 	// TF_CONNECTION_INFO_PATH should point to worker-specific file, not directory
 	expectedPath := filepath.Join(agent.connectionsDir, "worker_1.txt")
 	assert.Equal(t, expectedPath, infos[0].WorkerRunningInfo.Env[EnvConnectionInfoPath])
+	assert.Equal(t, ConnectionInfoFormatV2, infos[0].WorkerRunningInfo.Env[EnvConnectionInfoFormat])
 }
 
 func TestAgent_LicenseParsing(t *testing.T) {
@@ -640,3 +650,12 @@ func TestAgent_LicenseParsing(t *testing.T) {
 	require.NotNil(t, receivedReq.LicenseExpiration)
 	assert.Equal(t, int64(1735689600000), *receivedReq.LicenseExpiration)
 }
+
+func TestBuildGPUVisibilityEnv(t *testing.T) {
+	assert.Equal(t, map[string]string{envCUDAVisibleDevices: "0,2"}, buildGPUVisibilityEnv(vendorNVIDIA, []int{0, 2}))
+	assert.Equal(t, map[string]string{envHIPVisibleDevices: "1", envROCRVisibleDevices: "1"}, buildGPUVisibilityEnv(vendorHygon, []int{1}))
+	assert.Equal(t, map[string]string{envMUSAVisibleDevices: "3"}, buildGPUVisibilityEnv(vendorMThreads, []int{3}))
+	assert.Equal(t, map[string]string{envMLUVisibleDevices: "0,1"}, buildGPUVisibilityEnv(vendorCambricon, []int{0, 1}))
+	assert.Empty(t, buildGPUVisibilityEnv(vendorNVIDIA, []int{-1}))
+	assert.Empty(t, buildGPUVisibilityEnv("", []int{0}))
+}
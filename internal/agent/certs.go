@@ -0,0 +1,237 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+// Worker TLS modes
+const (
+	// TLSModeSelfSigned serves self-signed certificates; clients trust them by
+	// the fingerprint published in share info
+	TLSModeSelfSigned = "self-signed"
+	// TLSModePlatform has the server sign a certificate for each worker
+	TLSModePlatform = "platform"
+)
+
+const (
+	// workerCertsDir holds per-worker certificates under the state dir
+	workerCertsDir = "certs"
+
+	selfSignedCertValidity = 365 * 24 * time.Hour
+	// certRenewBefore renews certificates this long before they expire
+	certRenewBefore  = 30 * 24 * time.Hour
+	certIssueTimeout = 30 * time.Second
+)
+
+// ParseTLSMode validates a worker TLS mode; empty disables TLS
+func ParseTLSMode(mode string) (string, error) {
+	switch mode {
+	case "", TLSModeSelfSigned, TLSModePlatform:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid TLS mode %q (valid: %s, %s)", mode, TLSModeSelfSigned, TLSModePlatform)
+}
+
+// certIssuer signs a PEM CSR for a worker and returns the PEM certificate chain
+type certIssuer func(ctx context.Context, workerID string, csrPEM []byte) ([]byte, error)
+
+// workerCert is a loaded certificate with its pinned fingerprint
+type workerCert struct {
+	cert        *tls.Certificate
+	fingerprint string
+	notAfter    time.Time
+}
+
+// certManager obtains, caches and renews the certificates the connection
+// proxy serves on worker ports. Certificates are stored as
+// <dir>/<workerID>.crt/.key so fingerprints survive agent restarts and
+// clients that pinned them keep working.
+type certManager struct {
+	mu    sync.Mutex
+	dir   string
+	mode  string
+	hosts func() []string
+	issue certIssuer
+	certs map[string]*workerCert
+}
+
+func newCertManager(dir, mode string, hosts func() []string, issue certIssuer) *certManager {
+	return &certManager{
+		dir:   dir,
+		mode:  mode,
+		hosts: hosts,
+		issue: issue,
+		certs: make(map[string]*workerCert),
+	}
+}
+
+// Get returns the worker's certificate, loading it from disk or obtaining a
+// new one when it is missing or due for renewal
+func (m *certManager) Get(workerID string) (*tls.Certificate, error) {
+	c, err := m.ensure(workerID)
+	if err != nil {
+		return nil, err
+	}
+	return c.cert, nil
+}
+
+// Fingerprint returns the fingerprint of the worker's current certificate,
+// empty if none has been loaded yet
+func (m *certManager) Fingerprint(workerID string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.certs[workerID]; ok {
+		return c.fingerprint
+	}
+	return ""
+}
+
+// Prune forgets and deletes certificates of workers no longer configured
+func (m *certManager) Prune(known map[string]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for workerID := range m.certs {
+		if !known[workerID] {
+			delete(m.certs, workerID)
+		}
+	}
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if ext != ".crt" && ext != ".key" {
+			continue
+		}
+		if workerID := e.Name()[:len(e.Name())-len(ext)]; !known[workerID] {
+			_ = os.Remove(filepath.Join(m.dir, e.Name()))
+		}
+	}
+}
+
+func (m *certManager) ensure(workerID string) (*workerCert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if c, ok := m.certs[workerID]; ok && now.Add(certRenewBefore).Before(c.notAfter) {
+		return c, nil
+	}
+
+	certPath, keyPath := m.paths(workerID)
+	if c, err := loadWorkerCert(certPath, keyPath); err == nil && now.Add(certRenewBefore).Before(c.notAfter) {
+		m.certs[workerID] = c
+		return c, nil
+	}
+
+	certPEM, keyPEM, err := m.obtain(workerID)
+	if err != nil {
+		// Keep serving a still-valid certificate if renewal fails
+		if c, ok := m.certs[workerID]; ok && now.Before(c.notAfter) {
+			klog.Warningf("Failed to renew worker certificate, using current one: worker_id=%s expires=%s error=%v",
+				workerID, c.notAfter.Format(time.RFC3339), err)
+			return c, nil
+		}
+		return nil, err
+	}
+	c, err := parseWorkerCert(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+	if err := utils.AtomicWriteFile(keyPath, keyPEM, 0600); err != nil {
+		return nil, fmt.Errorf("failed to save certificate key: %w", err)
+	}
+	if err := utils.AtomicWriteFile(certPath, certPEM, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save certificate: %w", err)
+	}
+	m.certs[workerID] = c
+	klog.Infof("Worker certificate issued: worker_id=%s mode=%s fingerprint=%s expires=%s",
+		workerID, m.mode, c.fingerprint, c.notAfter.Format(time.RFC3339))
+	return c, nil
+}
+
+// obtain creates a new key and certificate for the worker
+func (m *certManager) obtain(workerID string) ([]byte, []byte, error) {
+	var hosts []string
+	if m.hosts != nil {
+		hosts = m.hosts()
+	}
+	if m.mode != TLSModePlatform {
+		return utils.GenerateSelfSignedCert(workerID, hosts, selfSignedCertValidity)
+	}
+
+	key, keyPEM, err := utils.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.CertificateRequest{Subject: pkix.Name{CommonName: workerID}}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if h != "" {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate request: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), certIssueTimeout)
+	defer cancel()
+	certPEM, err := m.issue(ctx, workerID, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to obtain platform certificate: %w", err)
+	}
+	return certPEM, keyPEM, nil
+}
+
+func (m *certManager) paths(workerID string) (string, string) {
+	return filepath.Join(m.dir, workerID+".crt"), filepath.Join(m.dir, workerID+".key")
+}
+
+func loadWorkerCert(certPath, keyPath string) (*workerCert, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseWorkerCert(certPEM, keyPEM)
+}
+
+func parseWorkerCert(certPEM, keyPEM []byte) (*workerCert, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid worker certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid worker certificate: %w", err)
+	}
+	cert.Leaf = leaf
+	return &workerCert{
+		cert:        &cert,
+		fingerprint: utils.CertFingerprint(leaf.Raw),
+		notAfter:    leaf.NotAfter,
+	}, nil
+}
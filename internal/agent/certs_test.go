@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertManager_SelfSignedPersistsAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	hosts := func() []string { return []string{"gpu-host", "10.0.0.5"} }
+	m := newCertManager(dir, TLSModeSelfSigned, hosts, nil)
+
+	assert.Empty(t, m.Fingerprint("worker-1"))
+	cert, err := m.Get("worker-1")
+	require.NoError(t, err)
+	fp := m.Fingerprint("worker-1")
+	require.Len(t, fp, 64)
+	assert.Equal(t, utils.CertFingerprint(cert.Leaf.Raw), fp)
+	assert.Equal(t, []string{"gpu-host"}, cert.Leaf.DNSNames)
+	require.Len(t, cert.Leaf.IPAddresses, 1)
+	assert.Equal(t, "10.0.0.5", cert.Leaf.IPAddresses[0].String())
+
+	info, err := os.Stat(filepath.Join(dir, "worker-1.key"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A restarted agent serves the same certificate, keeping pins valid
+	restarted := newCertManager(dir, TLSModeSelfSigned, hosts, nil)
+	_, err = restarted.Get("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, fp, restarted.Fingerprint("worker-1"))
+
+	_, err = restarted.Get("worker-2")
+	require.NoError(t, err)
+	restarted.Prune(map[string]bool{"worker-2": true})
+	assert.Empty(t, restarted.Fingerprint("worker-1"))
+	assert.NoFileExists(t, filepath.Join(dir, "worker-1.crt"))
+	assert.NoFileExists(t, filepath.Join(dir, "worker-1.key"))
+	assert.FileExists(t, filepath.Join(dir, "worker-2.crt"))
+}
+
+func TestCertManager_RenewsExpiringCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM, err := utils.GenerateSelfSignedCert("worker-1", nil, certRenewBefore/2)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "worker-1.crt"), certPEM, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "worker-1.key"), keyPEM, 0600))
+	old, err := parseWorkerCert(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	m := newCertManager(dir, TLSModeSelfSigned, nil, nil)
+	cert, err := m.Get("worker-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, old.fingerprint, m.Fingerprint("worker-1"))
+	assert.True(t, cert.Leaf.NotAfter.After(time.Now().Add(certRenewBefore)))
+}
+
+func TestCertManager_PlatformIssued(t *testing.T) {
+	caKey, _, err := utils.GenerateKey()
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	var gotWorker string
+	issue := func(_ context.Context, workerID string, csrPEM []byte) ([]byte, error) {
+		gotWorker = workerID
+		block, _ := pem.Decode(csrPEM)
+		require.NotNil(t, block)
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		require.NoError(t, err)
+		require.NoError(t, csr.CheckSignature())
+		assert.Equal(t, workerID, csr.Subject.CommonName)
+
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caTmpl, csr.PublicKey, caKey)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+	}
+
+	m := newCertManager(t.TempDir(), TLSModePlatform, func() []string { return []string{"gpu-host"} }, issue)
+	cert, err := m.Get("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, "worker-1", gotWorker)
+	assert.Equal(t, "test CA", cert.Leaf.Issuer.CommonName)
+	assert.Equal(t, []string{"gpu-host"}, cert.Leaf.DNSNames)
+}
+
+func TestConnProxy_TerminatesTLS(t *testing.T) {
+	proxy := newConnProxy("")
+	proxy.certs = newCertManager(t.TempDir(), TLSModeSelfSigned, nil, nil)
+	proxy.listen = func(int) (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	defer proxy.Stop()
+
+	backendPort, err := proxy.backendPort("worker-1")
+	require.NoError(t, err)
+	backend, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort)))
+	require.NoError(t, err)
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	proxy.Sync([]api.WorkerConfig{{WorkerID: "worker-1", ListenPort: 9001, Enabled: true}})
+	fp := proxy.TLSFingerprint("worker-1")
+	require.NotEmpty(t, fp, "certificate must be ready before clients connect")
+	proxy.mu.Lock()
+	addr := proxy.listeners["worker-1"].ln.Addr().String()
+	proxy.mu.Unlock()
+
+	err = utils.VerifyPinnedCert(context.Background(), addr, "00"+fp[2:])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fingerprint mismatch")
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true}) //nolint:gosec // test server is self-signed
+	require.NoError(t, err)
+	payload := []byte("hello worker")
+	_, err = conn.Write(payload)
+	require.NoError(t, err)
+	buf := make([]byte, len(payload))
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, payload, buf)
+	require.NoError(t, conn.Close())
+
+	// The rejected handshake above is not counted as a session
+	sessions, bytesIn := 0, int64(0)
+	require.Eventually(t, func() bool {
+		for _, u := range proxy.DrainUsage("worker-1") {
+			sessions += u.Sessions
+			bytesIn += u.BytesIn
+		}
+		return bytesIn == int64(len(payload))
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, sessions)
+
+	require.NoError(t, utils.VerifyPinnedCert(context.Background(), addr, "SHA256:"+fp))
+}
+
+func TestParseTLSMode(t *testing.T) {
+	for _, mode := range []string{"", TLSModeSelfSigned, TLSModePlatform} {
+		got, err := ParseTLSMode(mode)
+		require.NoError(t, err)
+		assert.Equal(t, mode, got)
+	}
+	_, err := ParseTLSMode("acme")
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+)
+
+// Workers list their client sessions in a connection file, one line per
+// session. Version 1 lines are clientIP,clientPort,clientPID; version 2 lines
+// are JSON objects (connectionRecordV2) with the session's metadata. A file
+// may mix both, so workers can be upgraded one at a time.
+const (
+	// EnvConnectionInfoFormat tells workers the newest connection file format
+	// the agent reads
+	EnvConnectionInfoFormat = "TF_CONNECTION_INFO_FORMAT"
+	// ConnectionInfoFormatV2 is the JSONL session format
+	ConnectionInfoFormatV2 = "v2"
+)
+
+// connectionRecordV2 is a line of a v2 connection file
+type connectionRecordV2 struct {
+	SessionID       string    `json:"session_id"`
+	ShareCode       string    `json:"share_code,omitempty"`
+	ClientIP        string    `json:"client_ip"`
+	ClientPort      int       `json:"client_port,omitempty"`
+	ClientPID       int       `json:"client_pid,omitempty"`
+	ClientHostname  string    `json:"client_hostname,omitempty"`
+	ProtocolVersion string    `json:"protocol_version,omitempty"`
+	ConnectedAt     time.Time `json:"connected_at"`
+	BytesIn         int64     `json:"bytes_in,omitempty"`
+	BytesOut        int64     `json:"bytes_out,omitempty"`
+}
+
+// readWorkerConnectionFile reads the sessions listed in a worker's
+// connection file; a missing file has none. Lines that cannot be parsed are
+// skipped.
+func readWorkerConnectionFile(filePath string) (conns []api.ConnectionInfo, err error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file: %w", closeErr)
+		}
+	}()
+
+	now := time.Now()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if conn, ok := parseConnectionLine(scanner.Text(), now); ok {
+			conns = append(conns, conn)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan file: %w", err)
+	}
+	return conns, nil
+}
+
+// parseConnectionLine parses a v1 or v2 connection file line. Version 1
+// lines carry no connect time, so now is used.
+func parseConnectionLine(line string, now time.Time) (api.ConnectionInfo, bool) {
+	// Workers may leave null bytes behind when they rewrite the file
+	line = strings.Trim(strings.TrimSpace(line), "\x00")
+	if line == "" {
+		return api.ConnectionInfo{}, false
+	}
+
+	if strings.HasPrefix(line, "{") {
+		var rec connectionRecordV2
+		if err := json.Unmarshal([]byte(line), &rec); err != nil || rec.ClientIP == "" {
+			return api.ConnectionInfo{}, false
+		}
+		if rec.ConnectedAt.IsZero() {
+			rec.ConnectedAt = now
+		}
+		return api.ConnectionInfo{
+			ClientIP:        rec.ClientIP,
+			ClientPort:      rec.ClientPort,
+			ClientPID:       rec.ClientPID,
+			ConnectedAt:     rec.ConnectedAt,
+			ShareCode:       rec.ShareCode,
+			BytesIn:         rec.BytesIn,
+			BytesOut:        rec.BytesOut,
+			WorkerSessionID: rec.SessionID,
+			ClientHostname:  rec.ClientHostname,
+			ProtocolVersion: rec.ProtocolVersion,
+		}, true
+	}
+
+	parts := strings.Split(line, ",")
+	conn := api.ConnectionInfo{
+		ClientIP:    strings.Trim(strings.TrimSpace(parts[0]), "\x00"),
+		ConnectedAt: now,
+	}
+	if conn.ClientIP == "" {
+		return api.ConnectionInfo{}, false
+	}
+	if len(parts) >= 2 {
+		if port, err := strconv.Atoi(strings.Trim(strings.TrimSpace(parts[1]), "\x00")); err == nil {
+			conn.ClientPort = port
+		}
+	}
+	if len(parts) >= 3 {
+		if pid, err := strconv.Atoi(strings.Trim(strings.TrimSpace(parts[2]), "\x00")); err == nil {
+			conn.ClientPID = pid
+		}
+	}
+	return conn, true
+}
+
+// connectionKeys identifies the sessions of a worker, sorted, to tell when
+// clients come and go; byte counters changing do not count as a change
+func connectionKeys(conns []api.ConnectionInfo) []string {
+	keys := make([]string, 0, len(conns))
+	for _, c := range conns {
+		if c.WorkerSessionID != "" {
+			keys = append(keys, c.WorkerSessionID)
+			continue
+		}
+		keys = append(keys, fmt.Sprintf("%s,%d,%d", c.ClientIP, c.ClientPort, c.ClientPID))
+	}
+	slices.Sort(keys)
+	return keys
+}
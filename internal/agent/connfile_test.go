@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWorkerConnectionFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "w1.txt")
+	content := "10.0.0.2,5000,77\n" +
+		`{"session_id":"s-1","share_code":"abc","client_ip":"10.0.0.3","client_port":5001,"client_pid":88,` +
+		`"client_hostname":"laptop","protocol_version":"2.1","connected_at":"2026-10-01T08:00:00Z","bytes_in":10,"bytes_out":20}` + "\n" +
+		`{"session_id":"s-2"}` + "\n" +
+		"{broken\n" +
+		"\x00\x00\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	conns, err := readWorkerConnectionFile(path)
+	require.NoError(t, err)
+	require.Len(t, conns, 2, "lines without a client IP or that fail to parse are skipped")
+
+	assert.Equal(t, "10.0.0.2", conns[0].ClientIP)
+	assert.Equal(t, 5000, conns[0].ClientPort)
+	assert.Equal(t, 77, conns[0].ClientPID)
+	assert.False(t, conns[0].ConnectedAt.IsZero())
+
+	assert.Equal(t, api.ConnectionInfo{
+		ClientIP:        "10.0.0.3",
+		ClientPort:      5001,
+		ClientPID:       88,
+		ConnectedAt:     time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+		ShareCode:       "abc",
+		BytesIn:         10,
+		BytesOut:        20,
+		WorkerSessionID: "s-1",
+		ClientHostname:  "laptop",
+		ProtocolVersion: "2.1",
+	}, conns[1])
+
+	conns, err = readWorkerConnectionFile(filepath.Join(t.TempDir(), "missing.txt"))
+	require.NoError(t, err)
+	assert.Empty(t, conns)
+}
+
+func TestConnectionKeys(t *testing.T) {
+	before := []api.ConnectionInfo{
+		{ClientIP: "10.0.0.3", WorkerSessionID: "s-1", BytesIn: 10},
+		{ClientIP: "10.0.0.2", ClientPort: 5000, ClientPID: 77},
+	}
+	after := []api.ConnectionInfo{
+		{ClientIP: "10.0.0.2", ClientPort: 5000, ClientPID: 77},
+		{ClientIP: "10.0.0.3", WorkerSessionID: "s-1", BytesIn: 4096},
+	}
+	assert.Equal(t, []string{"10.0.0.2,5000,77", "s-1"}, connectionKeys(before))
+	assert.Equal(t, connectionKeys(before), connectionKeys(after), "traffic is not a change")
+}
@@ -0,0 +1,413 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// crashWatchInterval is how often worker process state is sampled. It is
+	// much shorter than the status interval so that back-to-back restarts and
+	// the exit code of a stopped worker are observed individually.
+	crashWatchInterval = 5 * time.Second
+	// crashLogTailBytes is how much of the end of the worker log is kept per crash
+	crashLogTailBytes = 32 * 1024
+	// maxCrashReportsPerWorker bounds the local crash history of each worker
+	maxCrashReportsPerWorker = 20
+	crashesDirName           = "crashes"
+)
+
+// Crash reasons, from the exit status or inferred from kernel events
+const (
+	crashReasonExitCode  = "exit-code"
+	crashReasonSignaled  = "signaled"
+	crashReasonSegfault  = "segfault"
+	crashReasonOOMKilled = "oom-killed"
+	crashReasonGPUXid    = "gpu-xid"
+	crashReasonUnknown   = "unknown"
+)
+
+var (
+	// NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, name=worker, GPU has fallen off the bus.
+	xidPattern = regexp.MustCompile(`NVRM: Xid \([^)]*\): (\d+)`)
+	// worker[1234]: segfault at 0 ip ... / Out of memory: Killed process 1234 (worker)
+	segfaultPattern = regexp.MustCompile(`\[(\d+)\]: segfault at`)
+	oomPattern      = regexp.MustCompile(`Killed process (\d+)`)
+)
+
+// signalNames maps the signals a worker commonly dies from to their names
+var signalNames = map[int]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	3:  "SIGQUIT",
+	4:  "SIGILL",
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	8:  "SIGFPE",
+	9:  "SIGKILL",
+	11: "SIGSEGV",
+	13: "SIGPIPE",
+	15: "SIGTERM",
+}
+
+// crashSnapshot is the process state of a worker at the previous crash sample
+type crashSnapshot struct {
+	PID      int
+	Running  bool
+	ExitCode int
+	Restarts int
+	// Accounted is the restart count up to which exits have been reported
+	Accounted int
+	SeenAt    time.Time
+}
+
+// CrashesDir returns the directory holding per-worker crash reports
+func CrashesDir(stateDir string) string {
+	return filepath.Join(stateDir, crashesDirName)
+}
+
+// LoadCrashReports returns the locally stored crash reports of a worker, oldest first
+func LoadCrashReports(stateDir, workerID string) ([]api.WorkerCrashReport, error) {
+	if workerID == "" || workerID == "." || workerID == ".." || strings.ContainsAny(workerID, `/\`) {
+		return nil, fmt.Errorf("invalid worker ID %q", workerID)
+	}
+	return utils.LoadJSONSlice[api.WorkerCrashReport](filepath.Join(CrashesDir(stateDir), workerID+".json"))
+}
+
+// saveCrashReport appends a report to the worker's local crash history,
+// keeping only the most recent maxCrashReportsPerWorker entries, and returns
+// the history
+func saveCrashReport(stateDir string, report api.WorkerCrashReport) ([]api.WorkerCrashReport, error) {
+	reports, err := LoadCrashReports(stateDir, report.WorkerID)
+	if err != nil {
+		klog.Warningf("Discarding unreadable crash history: worker_id=%s error=%v", report.WorkerID, err)
+		reports = nil
+	}
+	reports = append(reports, report)
+	if len(reports) > maxCrashReportsPerWorker {
+		reports = reports[len(reports)-maxCrashReportsPerWorker:]
+	}
+	return reports, utils.SaveJSONSlice(filepath.Join(CrashesDir(stateDir), report.WorkerID+".json"), reports, 0644)
+}
+
+// crashWatchLoop samples worker process state and captures a crash report
+// whenever a worker exits abnormally
+func (a *Agent) crashWatchLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(crashWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if a.hypervisorMgr.IsStarted() {
+				a.checkWorkerCrashes(a.hypervisorMgr.ListWorkers())
+			}
+		}
+	}
+}
+
+// checkWorkerCrashes compares workers with the previous sample. A worker
+// crashed if it went from running to stopped with a non-zero exit code, or if
+// the backend restarted it without that exit having been seen.
+func (a *Agent) checkWorkerCrashes(workers []*hvApi.WorkerInfo) {
+	type detected struct {
+		workerID  string
+		prev, cur *crashSnapshot
+		exits     int
+	}
+	var found []detected
+	var restarts []config.WorkerRestart
+	now := time.Now()
+
+	a.crashMu.Lock()
+	current := make(map[string]*crashSnapshot, len(workers))
+	for _, w := range workers {
+		info := w.WorkerRunningInfo
+		if info == nil {
+			continue
+		}
+		cur := &crashSnapshot{
+			PID:       int(info.PID),
+			Running:   info.IsRunning,
+			ExitCode:  info.ExitCode,
+			Restarts:  info.Restarts,
+			Accounted: info.Restarts,
+			SeenAt:    now,
+		}
+		current[w.WorkerUID] = cur
+
+		prev, ok := a.crashSnapshots[w.WorkerUID]
+		if !ok {
+			continue
+		}
+		// The backend clears the PID on exit; keep the one of the exited process
+		if cur.PID == 0 {
+			cur.PID = prev.PID
+		}
+		if cur.Running && prev.PID != 0 && cur.PID != prev.PID {
+			restarts = append(restarts, config.WorkerRestart{
+				WorkerID:    w.WorkerUID,
+				At:          now,
+				PID:         cur.PID,
+				PreviousPID: prev.PID,
+				Restarts:    cur.Restarts,
+			})
+		}
+		switch {
+		case prev.Running && !cur.Running && cur.ExitCode != 0:
+			// The backend bumps Restarts when it relaunches the process, so
+			// that restart is already accounted for by this exit
+			cur.Accounted = cur.Restarts + 1
+			found = append(found, detected{workerID: w.WorkerUID, prev: prev, cur: cur, exits: 1})
+		case cur.Restarts > prev.Accounted:
+			found = append(found, detected{workerID: w.WorkerUID, prev: prev, cur: cur, exits: cur.Restarts - prev.Accounted})
+		default:
+			cur.Accounted = max(prev.Accounted, cur.Restarts)
+		}
+	}
+	a.crashSnapshots = current
+	a.crashMu.Unlock()
+
+	// Forensics read logs and the kernel ring buffer, so run them unlocked
+	for _, d := range found {
+		a.recordCrash(a.captureCrash(d.workerID, d.prev, d.cur, d.exits))
+		for i := range restarts {
+			if restarts[i].WorkerID == d.workerID {
+				restarts[i].Crashed = true
+			}
+		}
+	}
+	a.recordWorkerRestarts(restarts)
+}
+
+// recordWorkerRestarts adds worker restarts to the history of the state store
+func (a *Agent) recordWorkerRestarts(restarts []config.WorkerRestart) {
+	if len(restarts) == 0 {
+		return
+	}
+	err := a.config.Update(func(tx config.StoreTx) error {
+		for _, r := range restarts {
+			if err := tx.AddWorkerRestart(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		klog.Warningf("Failed to record worker restarts: count=%d error=%v", len(restarts), err)
+	}
+}
+
+// captureCrash snapshots the forensics of an abnormal worker exit: the exit
+// status, the tail of its log and related kernel events
+func (a *Agent) captureCrash(workerID string, prev, cur *crashSnapshot, exits int) api.WorkerCrashReport {
+	report := api.WorkerCrashReport{
+		WorkerID:   workerID,
+		PID:        prev.PID,
+		Restarts:   cur.Restarts,
+		Exits:      exits,
+		DetectedAt: time.Now(),
+		Reason:     crashReasonUnknown,
+	}
+	// Once the worker is relaunched the backend resets its exit code
+	if !cur.Running && cur.ExitCode != 0 {
+		exitCode := cur.ExitCode
+		report.ExitCode = &exitCode
+		report.Reason, report.Signal = classifyExitCode(exitCode)
+	}
+
+	logsDir := workerLogsDir(a.config.StateDir())
+	if logPath := latestWorkerLog(logsDir, workerID); logPath != "" {
+		report.LogFile = logPath
+		tail, err := readLogTail(logPath, crashLogTailBytes)
+		if err != nil {
+			klog.Warningf("Failed to read worker log for crash report: worker_id=%s path=%s error=%v", workerID, logPath, err)
+		}
+		report.LogTail = tail
+	}
+
+	if a.kernelLog != nil {
+		// Allow some slack before the previous sample in case the kernel and
+		// wall clocks are not aligned
+		events := a.kernelLog(prev.SeenAt.Add(-crashWatchInterval))
+		var reason, signal string
+		report.KernelEvents, report.XIDs, reason, signal = classifyKernelEvents(events, prev.PID)
+		// Kernel messages name the precise cause (segfault, OOM kill), so they
+		// refine the exit status; an Xid only explains otherwise unknown exits
+		if reason != crashReasonUnknown && (reason != crashReasonGPUXid || report.Reason == crashReasonUnknown) {
+			report.Reason = reason
+		}
+		if signal != "" {
+			report.Signal = signal
+		}
+	}
+
+	exitCode := "-"
+	if report.ExitCode != nil {
+		exitCode = strconv.Itoa(*report.ExitCode)
+	}
+	klog.Warningf("Worker crash captured: worker_id=%s pid=%d restarts=%d exit_code=%s reason=%s signal=%s xids=%v",
+		workerID, report.PID, report.Restarts, exitCode, report.Reason, report.Signal, report.XIDs)
+	return report
+}
+
+// classifyExitCode derives the crash reason and signal from an exit code. Go
+// reports -1 for processes killed by a signal; shells and wrappers use 128+n.
+func classifyExitCode(code int) (reason, signal string) {
+	switch {
+	case code < 0:
+		return crashReasonSignaled, ""
+	case code > 128 && code < 128+65:
+		n := code - 128
+		if name, ok := signalNames[n]; ok {
+			return crashReasonSignaled, name
+		}
+		return crashReasonSignaled, fmt.Sprintf("signal %d", n)
+	default:
+		return crashReasonExitCode, ""
+	}
+}
+
+// recordCrash persists a crash report and queues it for the next status upload
+func (a *Agent) recordCrash(report api.WorkerCrashReport) {
+	history, err := saveCrashReport(a.config.StateDir(), report)
+	if err != nil {
+		klog.Warningf("Failed to save crash report: worker_id=%s error=%v", report.WorkerID, err)
+	}
+	a.updateCrashLoop(report, history)
+	a.recordCrashEvent(report)
+	a.crashMu.Lock()
+	defer a.crashMu.Unlock()
+	if a.pendingCrashes == nil {
+		a.pendingCrashes = make(map[string][]api.WorkerCrashReport)
+	}
+	a.pendingCrashes[report.WorkerID] = append(a.pendingCrashes[report.WorkerID], report)
+}
+
+// takeCrashes returns and clears the crash reports queued for a worker
+func (a *Agent) takeCrashes(workerID string) []api.WorkerCrashReport {
+	a.crashMu.Lock()
+	defer a.crashMu.Unlock()
+	crashes := a.pendingCrashes[workerID]
+	delete(a.pendingCrashes, workerID)
+	return crashes
+}
+
+// restoreCrashes re-queues crash reports whose upload failed
+func (a *Agent) restoreCrashes(workerID string, crashes []api.WorkerCrashReport) {
+	if len(crashes) == 0 {
+		return
+	}
+	a.crashMu.Lock()
+	defer a.crashMu.Unlock()
+	if a.pendingCrashes == nil {
+		a.pendingCrashes = make(map[string][]api.WorkerCrashReport)
+	}
+	a.pendingCrashes[workerID] = append(crashes, a.pendingCrashes[workerID]...)
+}
+
+// latestWorkerLog returns the most recently written log file of a worker
+func latestWorkerLog(logsDir, workerID string) string {
+	matches, err := filepath.Glob(filepath.Join(logsDir, fmt.Sprintf("worker-%s-*.log", workerID)))
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	var latest string
+	var latestMod time.Time
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().After(latestMod) {
+			latest, latestMod = m, info.ModTime()
+		}
+	}
+	return latest
+}
+
+// readLogTail returns up to maxBytes from the end of a file, starting at a
+// line boundary when the file had to be truncated
+func readLogTail(path string, maxBytes int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := info.Size() - maxBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	if offset > 0 {
+		if idx := strings.IndexByte(string(data), '\n'); idx >= 0 {
+			data = data[idx+1:]
+		}
+	}
+	return string(data), nil
+}
+
+// classifyKernelEvents keeps the kernel messages relevant to a crashed worker
+// (NVIDIA Xid errors and messages naming its PID) and infers the exit reason
+func classifyKernelEvents(lines []string, pid int) (events []string, xids []int, reason, signal string) {
+	reason = crashReasonUnknown
+	pidStr := strconv.Itoa(pid)
+	seenXID := make(map[int]bool)
+
+	for _, line := range lines {
+		if m := xidPattern.FindStringSubmatch(line); m != nil {
+			events = append(events, line)
+			if xid, err := strconv.Atoi(m[1]); err == nil && !seenXID[xid] {
+				seenXID[xid] = true
+				xids = append(xids, xid)
+			}
+			continue
+		}
+		if pid <= 0 {
+			continue
+		}
+		if m := segfaultPattern.FindStringSubmatch(line); m != nil && m[1] == pidStr {
+			events = append(events, line)
+			reason, signal = crashReasonSegfault, "SIGSEGV"
+			continue
+		}
+		if m := oomPattern.FindStringSubmatch(line); m != nil && m[1] == pidStr {
+			events = append(events, line)
+			reason, signal = crashReasonOOMKilled, "SIGKILL"
+		}
+	}
+
+	if reason == crashReasonUnknown && len(xids) > 0 {
+		reason = crashReasonGPUXid
+	}
+	sort.Ints(xids)
+	return events, xids, reason, signal
+}
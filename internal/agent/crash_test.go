@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyKernelEvents(t *testing.T) {
+	lines := []string{
+		"NVRM: Xid (PCI:0000:3b:00): 79, pid=4242, name=worker, GPU has fallen off the bus.",
+		"worker[4242]: segfault at 0 ip 00007f sp 00007ffd error 4 in libcuda.so",
+		"other[17]: segfault at 0 ip 00007f sp 00007ffd error 4",
+		"NVRM: Xid (PCI:0000:3b:00): 13, pid=4242, Graphics Exception",
+		"NVRM: Xid (PCI:0000:3b:00): 79, pid=4242, GPU has fallen off the bus.",
+		"eth0: link up",
+	}
+
+	events, xids, reason, signal := classifyKernelEvents(lines, 4242)
+	assert.Len(t, events, 4)
+	assert.Equal(t, []int{13, 79}, xids)
+	assert.Equal(t, crashReasonSegfault, reason)
+	assert.Equal(t, "SIGSEGV", signal)
+
+	_, _, reason, signal = classifyKernelEvents([]string{"Out of memory: Killed process 4242 (worker) total-vm:1kB"}, 4242)
+	assert.Equal(t, crashReasonOOMKilled, reason)
+	assert.Equal(t, "SIGKILL", signal)
+
+	_, xids, reason, signal = classifyKernelEvents(lines[:1], 1)
+	assert.Equal(t, []int{79}, xids)
+	assert.Equal(t, crashReasonGPUXid, reason)
+	assert.Empty(t, signal)
+
+	events, _, reason, _ = classifyKernelEvents(nil, 4242)
+	assert.Empty(t, events)
+	assert.Equal(t, crashReasonUnknown, reason)
+}
+
+func TestReadLogTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "worker.log")
+	require.NoError(t, os.WriteFile(path, []byte("first line\nsecond line\nthird line\n"), 0644))
+
+	tail, err := readLogTail(path, 1024)
+	require.NoError(t, err)
+	assert.Equal(t, "first line\nsecond line\nthird line\n", tail)
+
+	// Truncated reads start at the next full line
+	tail, err = readLogTail(path, 15)
+	require.NoError(t, err)
+	assert.Equal(t, "third line\n", tail)
+}
+
+func TestSaveCrashReport_KeepsMostRecent(t *testing.T) {
+	stateDir := t.TempDir()
+	for i := 1; i <= maxCrashReportsPerWorker+3; i++ {
+		_, err := saveCrashReport(stateDir, api.WorkerCrashReport{WorkerID: "w1", Restarts: i})
+		require.NoError(t, err)
+	}
+
+	reports, err := LoadCrashReports(stateDir, "w1")
+	require.NoError(t, err)
+	require.Len(t, reports, maxCrashReportsPerWorker)
+	assert.Equal(t, 4, reports[0].Restarts)
+	assert.Equal(t, maxCrashReportsPerWorker+3, reports[len(reports)-1].Restarts)
+
+	reports, err = LoadCrashReports(stateDir, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+}
+
+func TestClassifyExitCode(t *testing.T) {
+	reason, signal := classifyExitCode(-1)
+	assert.Equal(t, crashReasonSignaled, reason)
+	assert.Empty(t, signal)
+
+	reason, signal = classifyExitCode(139)
+	assert.Equal(t, crashReasonSignaled, reason)
+	assert.Equal(t, "SIGSEGV", signal)
+
+	reason, signal = classifyExitCode(2)
+	assert.Equal(t, crashReasonExitCode, reason)
+	assert.Empty(t, signal)
+}
+
+func TestCheckWorkerCrashes(t *testing.T) {
+	tmpDir := t.TempDir()
+	configMgr := config.NewManager(tmpDir, tmpDir)
+	logsDir := filepath.Join(configMgr.StateDir(), "logs")
+	require.NoError(t, os.MkdirAll(logsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(logsDir, "worker-w1-2026-01-01_00-00-00.log"),
+		[]byte("starting\nCUDA error: illegal memory access\n"), 0644))
+
+	a := NewAgent(nil, configMgr)
+	a.kernelLog = func(time.Time) []string {
+		return []string{"NVRM: Xid (PCI:0000:3b:00): 13, pid=100, Graphics Exception"}
+	}
+	info := &hvApi.WorkerRunningInfo{IsRunning: true, PID: 100}
+	workers := []*hvApi.WorkerInfo{{WorkerUID: "w1", WorkerRunningInfo: info}}
+
+	a.checkWorkerCrashes(workers)
+	assert.Empty(t, a.takeCrashes("w1"))
+
+	// Process exits with a failure and stays stopped
+	info.IsRunning, info.PID, info.ExitCode = false, 0, 1
+	a.checkWorkerCrashes(workers)
+
+	crashes := a.takeCrashes("w1")
+	require.Len(t, crashes, 1)
+	assert.Equal(t, 100, crashes[0].PID)
+	require.NotNil(t, crashes[0].ExitCode)
+	assert.Equal(t, 1, *crashes[0].ExitCode)
+	assert.Equal(t, crashReasonExitCode, crashes[0].Reason)
+	assert.Equal(t, []int{13}, crashes[0].XIDs)
+	assert.Contains(t, crashes[0].LogTail, "illegal memory access")
+	assert.Empty(t, a.takeCrashes("w1"), "crashes are handed out once")
+
+	// The relaunch that follows is not a second crash
+	info.IsRunning, info.PID, info.ExitCode, info.Restarts = true, 101, 0, 1
+	a.checkWorkerCrashes(workers)
+	assert.Empty(t, a.takeCrashes("w1"))
+
+	// Two exits and restarts between samples are counted, not merged away
+	info.PID, info.Restarts = 102, 3
+	a.checkWorkerCrashes(workers)
+	crashes = a.takeCrashes("w1")
+	require.Len(t, crashes, 1)
+	assert.Equal(t, 2, crashes[0].Exits)
+	assert.Nil(t, crashes[0].ExitCode)
+
+	// A failed upload puts them back in front of newer reports
+	a.restoreCrashes("w1", crashes)
+	assert.Len(t, a.takeCrashes("w1"), 1)
+
+	stored, err := LoadCrashReports(configMgr.StateDir(), "w1")
+	require.NoError(t, err)
+	assert.Len(t, stored, 2)
+
+	_, err = LoadCrashReports(configMgr.StateDir(), "../w1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package agent
+
+import "time"
+
+// readKernelLog returns nil on non-Linux platforms; crash reports then only
+// carry the worker log tail.
+func readKernelLog(since time.Time) []string {
+	return nil
+}
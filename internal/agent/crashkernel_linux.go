@@ -0,0 +1,86 @@
+//go:build linux
+
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const dmesgTimeout = 5 * time.Second
+
+// readKernelLog returns kernel ring buffer messages logged at or after since.
+// dmesg timestamps are seconds since boot, so they are converted using
+// /proc/uptime. Returns nil if dmesg is unavailable (e.g. restricted by
+// kernel.dmesg_restrict for non-root agents).
+func readKernelLog(since time.Time) []string {
+	bootTime, ok := readBootTime()
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dmesgTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "dmesg").Output()
+	if err != nil {
+		klog.V(4).Infof("Failed to read kernel log: %v", err)
+		return nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		ts, msg, ok := parseDmesgLine(line)
+		if !ok {
+			continue
+		}
+		if bootTime.Add(ts).Before(since) {
+			continue
+		}
+		lines = append(lines, msg)
+	}
+	return lines
+}
+
+// readBootTime derives the boot time from /proc/uptime
+func readBootTime() (time.Time, bool) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		klog.V(4).Infof("Failed to read /proc/uptime: %v", err)
+		return time.Time{}, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return time.Time{}, false
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Now().Add(-time.Duration(uptime * float64(time.Second))), true
+}
+
+// parseDmesgLine splits "[ 1234.567890] message" into its offset and message
+func parseDmesgLine(line string) (time.Duration, string, bool) {
+	if !strings.HasPrefix(line, "[") {
+		return 0, "", false
+	}
+	end := strings.IndexByte(line, ']')
+	if end < 0 {
+		return 0, "", false
+	}
+	secs, err := strconv.ParseFloat(strings.TrimSpace(line[1:end]), 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return time.Duration(secs * float64(time.Second)), strings.TrimSpace(line[end+1:]), true
+}
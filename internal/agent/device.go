@@ -17,8 +17,11 @@ import (
 )
 
 const (
-	vendorNVIDIA = "nvidia"
-	vendorAMD    = "amd"
+	vendorNVIDIA    = deps.VendorNVIDIA
+	vendorAMD       = deps.VendorAMD
+	vendorHygon     = deps.VendorHygon
+	vendorMThreads  = deps.VendorMThreads
+	vendorCambricon = deps.VendorCambricon
 )
 
 // ConvertDevicesToGPUInfo converts hypervisor DeviceInfo to API GPUInfo
@@ -69,8 +72,8 @@ func DownloadOrFindAccelerator() (string, error) {
 	if vendor == "" {
 		vendor = "stub" // Fallback to stub if detection fails (use lowercase for slug matching)
 	}
-	// Normalize vendor to lowercase for slug matching
-	vendorSlug := strings.ToLower(vendor)
+	// Normalize vendor names such as "Moore Threads" for slug matching
+	vendorSlug := deps.NormalizeVendorSlug(vendor)
 
 	// Step 2: Initialize deps manager and fetch manifest
 	// This will auto-sync on first use if manifest doesn't exist
@@ -182,7 +185,7 @@ func detectVendor() (string, string) {
 	// Priority 1: Check environment variable
 	if vendor := os.Getenv("ACCELERATOR_VENDOR"); vendor != "" {
 		version := os.Getenv("ACCELERATOR_VERSION")
-		return strings.ToLower(vendor), version
+		return deps.NormalizeVendorSlug(vendor), version
 	}
 
 	// Priority 2: System detection
@@ -240,6 +243,14 @@ func detectVendorFromSystem() string {
 		return vendorAMD
 	}
 
+	// Check for Moore Threads (MUSA) and Cambricon (MLU)
+	if _, err := exec.LookPath("mthreads-gmi"); err == nil {
+		return vendorMThreads
+	}
+	if _, err := exec.LookPath("cnmon"); err == nil {
+		return vendorCambricon
+	}
+
 	// Check PCI devices (Linux)
 	if runtime.GOOS == "linux" {
 		if vendor := detectVendorFromPCI(); vendor != "" {
@@ -259,7 +270,8 @@ func detectVendorFromSystem() string {
 					vendorPath := filepath.Join("/sys/class/drm", name, "device", "vendor")
 					if data, err := os.ReadFile(vendorPath); err == nil {
 						vendorID := strings.TrimSpace(strings.ToLower(string(data)))
-						// NVIDIA: 0x10de (hex: 10de), AMD: 0x1002 (hex: 1002)
+						// NVIDIA: 0x10de (hex: 10de), AMD: 0x1002 (hex: 1002),
+						// Moore Threads: 0x1ed5
 						// Vendor ID format: 0x10de or 10de
 						if strings.Contains(vendorID, "10de") {
 							return vendorNVIDIA
@@ -267,6 +279,9 @@ func detectVendorFromSystem() string {
 						if strings.Contains(vendorID, "1002") {
 							return vendorAMD
 						}
+						if strings.Contains(vendorID, "1ed5") {
+							return vendorMThreads
+						}
 					}
 				}
 			}
@@ -291,6 +306,12 @@ func detectVendorFromPCI() string {
 	if strings.Contains(outputStr, vendorAMD) || strings.Contains(outputStr, "radeon") {
 		return vendorAMD
 	}
+	if strings.Contains(outputStr, "moore threads") {
+		return vendorMThreads
+	}
+	if strings.Contains(outputStr, vendorCambricon) {
+		return vendorCambricon
+	}
 
 	return ""
 }
@@ -303,6 +324,10 @@ func DetectVendorFromLibPath(libPath string) string {
 		return vendorNVIDIA
 	case strings.Contains(lower, vendorAMD):
 		return vendorAMD
+	case strings.Contains(lower, vendorMThreads), strings.Contains(lower, "musa"):
+		return vendorMThreads
+	case strings.Contains(lower, vendorCambricon):
+		return vendorCambricon
 	case strings.Contains(lower, "example"), strings.Contains(lower, "stub"):
 		return "stub"
 	default:
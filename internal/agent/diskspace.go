@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package agent
+
+import "errors"
+
+// diskSpace is not implemented on this platform, so no disk pressure events
+// are recorded
+func diskSpace(path string) (free, total uint64, err error) {
+	return 0, 0, errors.New("disk space is not available on this platform")
+}
@@ -0,0 +1,398 @@
+package agent
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultDiskMinFreePercent and DefaultDiskMinFreeMB are the free space
+	// on the filesystems of the cache and state directories below which the
+	// agent prunes and reports disk pressure
+	DefaultDiskMinFreePercent = 5
+	DefaultDiskMinFreeMB      = 2048
+	// DefaultMaxLogsMB caps the logs directory, beyond which rotated logs are
+	// pruned
+	DefaultMaxLogsMB = 1024
+	// DefaultMaxCacheMB caps downloaded libraries and staged worker releases
+	DefaultMaxCacheMB = 10240
+
+	// workerLogTimestamp is the start time in the name of a worker's own log
+	workerLogTimestamp = "2006-01-02_15-04-05"
+)
+
+// DiskSettings tunes the disk guardrails of the agent. Zero values leave the
+// setting to the server's agent config, or the default.
+type DiskSettings struct {
+	MinFreePercent int
+	MinFreeMB      int
+	MaxLogsMB      int
+	MaxCacheMB     int
+}
+
+// merge fills the unset settings of s from other
+func (s DiskSettings) merge(other DiskSettings) DiskSettings {
+	if s.MinFreePercent <= 0 {
+		s.MinFreePercent = other.MinFreePercent
+	}
+	if s.MinFreeMB <= 0 {
+		s.MinFreeMB = other.MinFreeMB
+	}
+	if s.MaxLogsMB <= 0 {
+		s.MaxLogsMB = other.MaxLogsMB
+	}
+	if s.MaxCacheMB <= 0 {
+		s.MaxCacheMB = other.MaxCacheMB
+	}
+	return s
+}
+
+// minFreeBytes returns the free space a filesystem of total bytes must keep
+func (s DiskSettings) minFreeBytes(total uint64) uint64 {
+	return max(total/100*uint64(s.MinFreePercent), uint64(s.MinFreeMB)<<20)
+}
+
+// SetDiskSettings sets local disk settings, which take precedence over the
+// server's agent config. Must be called before Start.
+func (a *Agent) SetDiskSettings(s DiskSettings) {
+	a.mu.Lock()
+	a.localDisk = s
+	a.mu.Unlock()
+}
+
+// applyDiskConfig takes the disk settings from the server's agent config;
+// they apply from the next disk check on
+func (a *Agent) applyDiskConfig(cfg *api.DiskConfig) {
+	var server DiskSettings
+	if cfg != nil {
+		server = DiskSettings{
+			MinFreePercent: cfg.MinFreePercent,
+			MinFreeMB:      cfg.MinFreeMB,
+			MaxLogsMB:      cfg.MaxLogsMB,
+			MaxCacheMB:     cfg.MaxCacheMB,
+		}
+	}
+	a.mu.Lock()
+	a.serverDisk = server
+	a.mu.Unlock()
+}
+
+// diskSettings returns the effective disk settings: local settings, then
+// the server's, then the defaults
+func (a *Agent) diskSettings() DiskSettings {
+	a.mu.RLock()
+	s := a.localDisk.merge(a.serverDisk)
+	a.mu.RUnlock()
+	return s.merge(DiskSettings{
+		MinFreePercent: DefaultDiskMinFreePercent,
+		MinFreeMB:      DefaultDiskMinFreeMB,
+		MaxLogsMB:      DefaultMaxLogsMB,
+		MaxCacheMB:     DefaultMaxCacheMB,
+	})
+}
+
+// lastDiskUsage returns the disk usage of the last check for the status
+// report, nil before the first check
+func (a *Agent) lastDiskUsage() *api.AgentDiskUsage {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.diskUsage == nil {
+		return nil
+	}
+	usage := *a.diskUsage
+	return &usage
+}
+
+// checkDisk measures the agent's directories and the free space left. Worker
+// releases no longer used are always removed; rotated logs and logs of
+// workers the agent no longer runs are removed, oldest first, once the logs
+// directory exceeds its cap or free space runs low. What pruning cannot fix
+// is recorded as disk_pressure.
+func (a *Agent) checkDisk(now time.Time) {
+	s := a.diskSettings()
+	stateDir := a.config.StateDir()
+	logsDir := workerLogsDir(stateDir)
+	cacheDir := a.paths.CacheDir()
+	releasesDir := filepath.Join(stateDir, workerReleasesDir)
+
+	pruneWorkerReleases(releasesDir, a.usedWorkerBinaries())
+
+	logs := dirSize(logsDir)
+	fsDir, free, total := lowestFreeSpace(cacheDir, stateDir)
+	var needed int64
+	if total > 0 {
+		needed = int64(s.minFreeBytes(total)) - int64(free)
+	}
+	if excess := max(logs-int64(s.MaxLogsMB)<<20, needed); excess > 0 {
+		freed := pruneLogs(logsDir, a.configuredWorkers(), excess)
+		if freed > 0 {
+			klog.Infof("Pruned worker logs: path=%s freed_mb=%d", logsDir, freed>>20)
+			logs = dirSize(logsDir)
+			fsDir, free, total = lowestFreeSpace(cacheDir, stateDir)
+		}
+	}
+	cache := dirSize(cacheDir) + dirSize(releasesDir)
+
+	usage := &api.AgentDiskUsage{CacheBytes: cache, LogsBytes: logs, FreeBytes: free, TotalBytes: total, CheckedAt: now}
+	if total > 0 && free < s.minFreeBytes(total) {
+		usage.Pressure = true
+		a.recordDiskPressure(fsDir, free, total)
+	}
+	if a.checkDirCap("Logs", logsDir, logs, s.MaxLogsMB) {
+		usage.Pressure = true
+	}
+	if a.checkDirCap("Cache", cacheDir, cache, s.MaxCacheMB) {
+		usage.Pressure = true
+	}
+
+	a.mu.Lock()
+	a.diskUsage = usage
+	a.mu.Unlock()
+}
+
+// recordDiskPressure records disk_pressure when the filesystem of dir runs
+// low on space; workers, their logs and client libraries are kept there
+func (a *Agent) recordDiskPressure(dir string, free, total uint64) {
+	klog.Warningf("Low disk space: path=%s free_mb=%d total_mb=%d", dir, free>>20, total>>20)
+	a.recordEvent(api.AgentEvent{
+		Type:     api.AgentEventDiskPressure,
+		Severity: api.AgentEventSeverityWarning,
+		Message:  fmt.Sprintf("Low disk space on %s: %d MiB free of %d MiB", dir, free>>20, total>>20),
+		Details: map[string]string{
+			"path":        dir,
+			"free_bytes":  strconv.FormatUint(free, 10),
+			"total_bytes": strconv.FormatUint(total, 10),
+		},
+	}, api.AgentEventDiskPressure+"/"+filepath.Clean(dir))
+}
+
+// checkDirCap records disk_pressure and returns true when dir, named by what
+// it holds, uses more than maxMB even after pruning
+func (a *Agent) checkDirCap(what, dir string, used int64, maxMB int) bool {
+	limit := int64(maxMB) << 20
+	if used <= limit {
+		return false
+	}
+	klog.Warningf("%s directory over its cap: path=%s used_mb=%d max_mb=%d", what, dir, used>>20, maxMB)
+	a.recordEvent(api.AgentEvent{
+		Type:     api.AgentEventDiskPressure,
+		Severity: api.AgentEventSeverityWarning,
+		Message:  fmt.Sprintf("%s directory %s uses %d MiB, over its cap of %d MiB", what, dir, used>>20, maxMB),
+		Details: map[string]string{
+			"path":        dir,
+			"used_bytes":  strconv.FormatInt(used, 10),
+			"limit_bytes": strconv.FormatInt(limit, 10),
+		},
+	}, api.AgentEventDiskPressure+"/cap/"+filepath.Clean(dir))
+	return true
+}
+
+// lowestFreeSpace returns the directory of dirs whose filesystem has the
+// least space free, with its free and total bytes; total is 0 if the space
+// is unknown
+func lowestFreeSpace(dirs ...string) (dir string, free, total uint64) {
+	for _, d := range dirs {
+		f, t, err := diskSpace(d)
+		if err != nil || t == 0 {
+			klog.V(4).Infof("Skipping disk space check: path=%s error=%v", d, err)
+			continue
+		}
+		if total == 0 || f < free {
+			dir, free, total = d, f, t
+		}
+	}
+	return dir, free, total
+}
+
+// dirSize returns the bytes taken by the regular files under dir
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// configuredWorkers returns the IDs of the workers in the last pulled
+// config, or nil before a config was pulled
+func (a *Agent) configuredWorkers() map[string]bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.workerConfigs == nil {
+		return nil
+	}
+	ids := make(map[string]bool, len(a.workerConfigs))
+	for _, w := range a.workerConfigs {
+		ids[w.WorkerID] = true
+	}
+	return ids
+}
+
+// workerLogOwner returns the worker a file in the logs directory belongs to
+// and whether it is the worker's current file: its captured output or the
+// log of its last start. Other files, such as rotated output, are not
+// current. ok is false for files the agent did not write.
+func workerLogOwner(name string) (workerID string, current, ok bool) {
+	if rest, found := strings.CutPrefix(name, "output-"); found {
+		if id, found := strings.CutSuffix(rest, ".log"); found {
+			return id, true, id != ""
+		}
+		if i := strings.LastIndex(rest, ".log."); i > 0 {
+			return rest[:i], false, true
+		}
+		return "", false, false
+	}
+	if rest, found := strings.CutPrefix(name, "worker-"); found {
+		rest, found = strings.CutSuffix(rest, ".log")
+		if !found || len(rest) < len(workerLogTimestamp)+2 {
+			return "", false, false
+		}
+		// Whether it is the last start is decided among the worker's logs
+		return rest[:len(rest)-len(workerLogTimestamp)-1], false, true
+	}
+	return "", false, false
+}
+
+// pruneLogs removes files from logsDir, oldest first, until at least excess
+// bytes are freed, and returns the bytes freed. Only rotated output, logs of
+// earlier worker starts and, when workers is not nil, all logs of workers
+// not in it are removed.
+func pruneLogs(logsDir string, workers map[string]bool, excess int64) int64 {
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		return 0
+	}
+
+	type logFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var candidates []logFile
+	// latest holds the log of the last start of each worker, which is kept
+	latest := make(map[string]logFile)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		workerID, current, ok := workerLogOwner(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		f := logFile{path: filepath.Join(logsDir, entry.Name()), size: info.Size(), modTime: info.ModTime()}
+		if workers != nil && !workers[workerID] {
+			candidates = append(candidates, f)
+			continue
+		}
+		if current {
+			continue
+		}
+		if strings.HasPrefix(entry.Name(), "worker-") {
+			prev, seen := latest[workerID]
+			if !seen || f.path > prev.path {
+				latest[workerID] = f
+				if !seen {
+					continue
+				}
+				f = prev
+			}
+		}
+		candidates = append(candidates, f)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].modTime.Before(candidates[j].modTime) })
+	var freed int64
+	for _, f := range candidates {
+		if freed >= excess {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			klog.V(4).Infof("Failed to remove worker log: path=%s error=%v", f.path, err)
+			continue
+		}
+		klog.V(4).Infof("Removed worker log: path=%s", f.path)
+		freed += f.size
+	}
+	return freed
+}
+
+// usedWorkerBinaries returns the remote-gpu-worker binaries workers run or
+// are being upgraded to, and the one recorded for the next agent start
+func (a *Agent) usedWorkerBinaries() []string {
+	a.mu.RLock()
+	used := []string{a.workerBinaryPath}
+	for _, path := range a.workerExecutables {
+		used = append(used, path)
+	}
+	a.mu.RUnlock()
+
+	state, err := utils.LoadJSON[workerUpgradeState](filepath.Join(a.config.StateDir(), workerUpgradeFile))
+	if err == nil && state != nil && state.Path != "" {
+		used = append(used, state.Path)
+	}
+	return used
+}
+
+// pruneWorkerReleases removes the staged releases in dir that hold none of
+// the used binaries. The newest release is kept, as it may be waiting for
+// the maintenance window.
+func pruneWorkerReleases(dir string, used []string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+	if len(versions) < 2 {
+		return
+	}
+	newest := slices.MaxFunc(versions, func(a, b string) int {
+		switch {
+		case deps.CompareVersions(a, b):
+			return 1
+		case deps.CompareVersions(b, a):
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+
+	for _, version := range versions {
+		path := filepath.Join(dir, version)
+		if version == newest || slices.ContainsFunc(used, func(bin string) bool {
+			return bin != "" && filepath.Dir(filepath.Clean(bin)) == path
+		}) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			klog.Warningf("Failed to remove unused worker release: path=%s error=%v", path, err)
+			continue
+		}
+		klog.Infof("Removed unused worker release: version=%s", version)
+	}
+}
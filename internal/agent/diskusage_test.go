@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskSettings(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(nil, config.NewManager(dir, dir))
+	assert.Equal(t, DiskSettings{
+		MinFreePercent: DefaultDiskMinFreePercent,
+		MinFreeMB:      DefaultDiskMinFreeMB,
+		MaxLogsMB:      DefaultMaxLogsMB,
+		MaxCacheMB:     DefaultMaxCacheMB,
+	}, a.diskSettings())
+
+	a.applyDiskConfig(&api.DiskConfig{MinFreePercent: 10, MaxLogsMB: 256})
+	a.SetDiskSettings(DiskSettings{MaxLogsMB: 64})
+	s := a.diskSettings()
+	assert.Equal(t, 10, s.MinFreePercent)
+	assert.Equal(t, 64, s.MaxLogsMB, "local settings win")
+
+	assert.Equal(t, uint64(DefaultDiskMinFreeMB)<<20, s.minFreeBytes(1<<30), "the larger threshold applies")
+	assert.Equal(t, uint64(100<<30), s.minFreeBytes(1000<<30))
+}
+
+func TestWorkerLogOwner(t *testing.T) {
+	for name, want := range map[string]struct {
+		id          string
+		current, ok bool
+	}{
+		"output-w1.log":                      {"w1", true, true},
+		"output-w1.log.2":                    {"w1", false, true},
+		"worker-w-1-2026-01-02_03-04-05.log": {"w-1", false, true},
+		"worker-2026-01-02_03-04-05.log":     {"", false, false},
+		"audit.log":                          {"", false, false},
+		"output-w1.txt":                      {"", false, false},
+	} {
+		id, current, ok := workerLogOwner(name)
+		assert.Equal(t, want.ok, ok, name)
+		if want.ok {
+			assert.Equal(t, want.id, id, name)
+			assert.Equal(t, want.current, current, name)
+		}
+	}
+}
+
+func writeLogFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	start := time.Now().Add(-time.Hour)
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0644))
+		modTime := start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestPruneLogs(t *testing.T) {
+	dir := t.TempDir()
+	// Written oldest first
+	writeLogFiles(t, dir,
+		"output-old.log",
+		"output-w1.log.2",
+		"worker-w1-2026-01-01_00-00-00.log",
+		"output-w1.log.1",
+		"worker-w1-2026-01-02_00-00-00.log",
+		"output-w1.log",
+		"notes.txt",
+	)
+
+	freed := pruneLogs(dir, map[string]bool{"w1": true}, 250)
+	assert.Equal(t, int64(300), freed, "removes whole files until enough is freed")
+	assert.ElementsMatch(t, []string{
+		"output-w1.log.1",
+		"worker-w1-2026-01-02_00-00-00.log",
+		"output-w1.log",
+		"notes.txt",
+	}, listDir(t, dir), "the deleted worker's log and the oldest rotated logs go first")
+
+	freed = pruneLogs(dir, map[string]bool{"w1": true}, 1<<30)
+	assert.Equal(t, int64(100), freed)
+	assert.ElementsMatch(t, []string{"worker-w1-2026-01-02_00-00-00.log", "output-w1.log", "notes.txt"}, listDir(t, dir),
+		"current logs are kept")
+
+	assert.Zero(t, pruneLogs(dir, nil, 1<<30))
+}
+
+func TestPruneWorkerReleases(t *testing.T) {
+	dir := t.TempDir()
+	for _, version := range []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, version), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version, "remote-gpu-worker"), nil, 0755))
+	}
+
+	pruneWorkerReleases(dir, []string{"", filepath.Join(dir, "1.1.0", "remote-gpu-worker")})
+	assert.ElementsMatch(t, []string{"1.1.0", "1.3.0"}, listDir(t, dir), "used and newest releases are kept")
+}
+
+func TestCheckDisk(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(nil, config.NewManager(dir, dir))
+	a.paths = a.paths.WithCacheDir(filepath.Join(dir, "cache"))
+	a.events = newEventQueue(filepath.Join(dir, eventsFile))
+	a.workerConfigs = []api.WorkerConfig{{WorkerID: "w1"}}
+
+	logsDir := workerLogsDir(dir)
+	require.NoError(t, os.MkdirAll(logsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(logsDir, "output-w1.log"), make([]byte, 2<<20), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(logsDir, "output-w1.log.1"), make([]byte, 1<<20), 0644))
+	require.NoError(t, os.MkdirAll(a.paths.LibsDir(), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(a.paths.LibsDir(), "libcuda.so"), make([]byte, 1<<20), 0644))
+
+	// Free space is not checked here, only the directory caps
+	a.SetDiskSettings(DiskSettings{MinFreePercent: 1, MinFreeMB: 1, MaxLogsMB: 1, MaxCacheMB: 1})
+	a.checkDisk(time.Now())
+
+	usage := a.lastDiskUsage()
+	require.NotNil(t, usage)
+	assert.Equal(t, int64(2<<20), usage.LogsBytes, "rotated output was pruned")
+	assert.Equal(t, int64(1<<20), usage.CacheBytes)
+	assert.True(t, usage.Pressure, "the current output alone exceeds the logs cap")
+	assert.NoFileExists(t, filepath.Join(logsDir, "output-w1.log.1"))
+
+	var capped []string
+	for _, e := range a.events.peek(10) {
+		assert.Equal(t, api.AgentEventDiskPressure, e.Type)
+		if e.Details["limit_bytes"] != "" {
+			capped = append(capped, e.Details["path"])
+		}
+	}
+	assert.Equal(t, []string{logsDir}, capped)
+}
@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"k8s.io/klog/v2"
+)
+
+// DefaultDrainGrace is how long a disabled or deleted worker keeps serving
+// its connected clients before it is stopped anyway, when the connection
+// proxy is enabled
+const DefaultDrainGrace = 5 * time.Minute
+
+// workerDrainer lets clients of a worker that is being disabled or deleted
+// finish their work. It is only used with the connection proxy: the worker
+// stops accepting connections (its proxy listener and relay tunnels are
+// closed once it is no longer enabled), and
+// the reconciler postpones stopping it until its last connection ended or
+// the grace period ran out.
+type workerDrainer struct {
+	mu      sync.Mutex
+	grace   time.Duration
+	force   map[string]bool      // workerID -> stop without draining, from the last config
+	started map[string]time.Time // workerID -> when draining began
+
+	// connections returns the number of clients connected to a worker
+	connections func(workerID string) int
+	now         func() time.Time
+}
+
+func newWorkerDrainer(grace time.Duration, connections func(workerID string) int) *workerDrainer {
+	return &workerDrainer{
+		grace:       grace,
+		force:       make(map[string]bool),
+		started:     make(map[string]time.Time),
+		connections: connections,
+		now:         time.Now,
+	}
+}
+
+// SetGrace changes the grace period; 0 stops workers without draining
+func (d *workerDrainer) SetGrace(grace time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.grace = grace
+}
+
+// Grace returns the grace period
+func (d *workerDrainer) Grace() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.grace
+}
+
+// Sync records which workers the server asked to stop without draining and
+// cancels the drain of workers that were enabled again
+func (d *workerDrainer) Sync(workers []api.WorkerConfig) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.force = make(map[string]bool)
+	for _, w := range workers {
+		if w.Enabled {
+			if _, ok := d.started[w.WorkerID]; ok {
+				klog.Infof("Worker enabled again, cancelling drain: worker_id=%s", w.WorkerID)
+				delete(d.started, w.WorkerID)
+			}
+			continue
+		}
+		if w.ForceStop {
+			d.force[w.WorkerID] = true
+		}
+	}
+}
+
+// ReadyToStop starts draining a worker on first use and reports whether it
+// can be stopped now: it has no clients left, the grace period is over, or
+// the server asked for a forced stop
+func (d *workerDrainer) ReadyToStop(workerID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.force[workerID] || d.grace <= 0 {
+		delete(d.started, workerID)
+		return true
+	}
+
+	now := d.now()
+	started, ok := d.started[workerID]
+	if !ok {
+		started = now
+		d.started[workerID] = started
+		klog.Infof("Draining worker: worker_id=%s grace=%s", workerID, d.grace)
+	}
+
+	conns := d.connections(workerID)
+	switch {
+	case conns == 0:
+		klog.Infof("Worker drained: worker_id=%s waited=%s", workerID, now.Sub(started).Round(time.Second))
+	case now.Sub(started) >= d.grace:
+		klog.Warningf("Drain grace period over, stopping worker with connected clients: worker_id=%s connections=%d", workerID, conns)
+	default:
+		return false
+	}
+	delete(d.started, workerID)
+	return true
+}
+
+// Deadline returns when a draining worker will be stopped at the latest
+func (d *workerDrainer) Deadline(workerID string) (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	started, ok := d.started[workerID]
+	if !ok {
+		return time.Time{}, false
+	}
+	return started.Add(d.grace), true
+}
+
+// withRetiredWorkers adds a disabled entry for each running worker the
+// config no longer lists, so the connection proxy keeps the sessions of a
+// deleted worker open while it drains instead of forgetting the worker
+func withRetiredWorkers(workers []api.WorkerConfig, running []string) []api.WorkerConfig {
+	listed := make(map[string]bool, len(workers))
+	for _, w := range workers {
+		listed[w.WorkerID] = true
+	}
+	// Clipped so appending never writes into the caller's array
+	result := slices.Clip(workers)
+	for _, id := range running {
+		if !listed[id] {
+			result = append(result, api.WorkerConfig{WorkerID: id})
+		}
+	}
+	return result
+}
+
+// proxiedWorkers returns the workers the connection proxy should know about:
+// the configured ones plus deleted ones that are still running
+func (a *Agent) proxiedWorkers(workers []api.WorkerConfig) []api.WorkerConfig {
+	if a.hypervisorMgr == nil {
+		return workers
+	}
+	running := make([]string, 0)
+	for _, w := range a.hypervisorMgr.ListWorkers() {
+		running = append(running, w.WorkerUID)
+	}
+	return withRetiredWorkers(workers, running)
+}
+
+// workerConnectionCount counts the clients connected to a worker, as listed
+// in the connection file the worker maintains
+func (a *Agent) workerConnectionCount(workerID string) int {
+	conns, err := readWorkerConnectionFile(filepath.Join(a.connectionsDir, workerID+".txt"))
+	if err != nil {
+		klog.Warningf("Failed to read worker connections: worker_id=%s error=%v", workerID, err)
+		return 0
+	}
+	return len(conns)
+}
+
+// drainStatus reports a running worker that is draining as stopping, with
+// the time it will be stopped at the latest
+func (a *Agent) drainStatus(workerID, status string) (string, *time.Time) {
+	if a.drain == nil || status != workerStatusRunning {
+		return status, nil
+	}
+	deadline, ok := a.drain.Deadline(workerID)
+	if !ok {
+		return status, nil
+	}
+	return workerStatusStopping, &deadline
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerDrainer_WaitsForConnections(t *testing.T) {
+	conns := map[string]int{"w1": 2}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newWorkerDrainer(time.Minute, func(workerID string) int { return conns[workerID] })
+	d.now = func() time.Time { return now }
+
+	d.Sync([]api.WorkerConfig{{WorkerID: "w1"}})
+	assert.False(t, d.ReadyToStop("w1"), "clients still connected")
+	deadline, ok := d.Deadline("w1")
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(time.Minute), deadline)
+
+	conns["w1"] = 0
+	assert.True(t, d.ReadyToStop("w1"), "last client left")
+	_, ok = d.Deadline("w1")
+	assert.False(t, ok)
+}
+
+func TestWorkerDrainer_GraceAndForce(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newWorkerDrainer(time.Minute, func(string) int { return 1 })
+	d.now = func() time.Time { return now }
+
+	assert.False(t, d.ReadyToStop("w1"))
+	now = now.Add(time.Minute)
+	assert.True(t, d.ReadyToStop("w1"), "grace period over")
+
+	d.Sync([]api.WorkerConfig{{WorkerID: "w2", ForceStop: true}})
+	assert.True(t, d.ReadyToStop("w2"), "forced stop skips draining")
+
+	// Re-enabling a worker cancels its drain
+	assert.False(t, d.ReadyToStop("w3"))
+	d.Sync([]api.WorkerConfig{{WorkerID: "w3", Enabled: true}})
+	_, ok := d.Deadline("w3")
+	assert.False(t, ok)
+
+	d.SetGrace(0)
+	assert.True(t, d.ReadyToStop("w3"), "draining disabled")
+}
+
+func TestWithRetiredWorkers(t *testing.T) {
+	workers := make([]api.WorkerConfig, 1, 4)
+	workers[0] = api.WorkerConfig{WorkerID: "w1", Enabled: true}
+
+	result := withRetiredWorkers(workers, []string{"w1", "w2"})
+	assert.Equal(t, []api.WorkerConfig{
+		{WorkerID: "w1", Enabled: true},
+		{WorkerID: "w2"},
+	}, result)
+	assert.Empty(t, workers[:2][1].WorkerID, "caller's array is not written")
+}
+
+func TestAgent_DrainsOnlyProxiedWorkers(t *testing.T) {
+	a := &Agent{drain: newWorkerDrainer(time.Minute, func(string) int { return 1 })}
+	assert.True(t, a.readyToStopWorker("w1"), "the worker's own listener would keep accepting clients")
+	_, ok := a.drain.Deadline("w1")
+	assert.False(t, ok)
+
+	a.proxy = newConnProxy("")
+	assert.False(t, a.readyToStopWorker("w1"), "the proxy stopped accepting clients, so the worker drains")
+}
@@ -0,0 +1,281 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// eventsFile persists events not yet accepted by the platform
+	eventsFile = "events.json"
+
+	// maxQueuedEvents bounds the local event buffer; once full, the oldest
+	// events are dropped
+	maxQueuedEvents = 1000
+	// eventBatchSize bounds the events uploaded per request
+	eventBatchSize = 50
+
+	// eventUploadInterval is how often queued events are uploaded. Together
+	// with eventBatchSize it caps the rate at which an agent sends events.
+	eventUploadInterval = 10 * time.Second
+	// eventMaxBackoff bounds the wait between failed uploads
+	eventMaxBackoff = 5 * time.Minute
+	// eventCheckInterval is how often license, disk and GPU conditions are
+	// checked for events
+	eventCheckInterval = time.Minute
+	// eventUploadTimeout bounds a single upload request
+	eventUploadTimeout = 30 * time.Second
+
+	// eventDedupWindow is how long an event suppresses repeats of itself,
+	// e.g. a crash-looping worker or an Xid logged over and over
+	eventDedupWindow = 5 * time.Minute
+
+	// licenseExpiryWarning is how long before the license expires the agent
+	// starts recording license_expiring events, at most once a day
+	licenseExpiryWarning = 7 * 24 * time.Hour
+)
+
+// eventDedupWindows overrides eventDedupWindow. Workers are started and
+// stopped deliberately by the reconciler, so each of those is kept; polled
+// conditions persist and are repeated less often.
+var eventDedupWindows = map[string]time.Duration{
+	api.AgentEventWorkerStarted:   0,
+	api.AgentEventWorkerStopped:   0,
+	api.AgentEventLicenseExpiring: 24 * time.Hour,
+	api.AgentEventDiskPressure:    time.Hour,
+}
+
+// eventQueue buffers agent events on disk until the platform accepts them,
+// so events survive agent restarts and platform outages
+type eventQueue struct {
+	mu     sync.Mutex
+	path   string
+	events []api.AgentEvent
+	// recent holds when each dedup key was last recorded
+	recent map[string]time.Time
+}
+
+// newEventQueue loads the events an earlier agent left at path
+func newEventQueue(path string) *eventQueue {
+	events, err := utils.LoadJSONSlice[api.AgentEvent](path)
+	if err != nil {
+		klog.Warningf("Discarding unreadable event queue: path=%s error=%v", path, err)
+		events = nil
+	}
+	return &eventQueue{path: path, events: events, recent: make(map[string]time.Time)}
+}
+
+// add records an event unless one with the same dedup key was recorded within
+// its dedup window. It reports whether the event was recorded.
+func (q *eventQueue) add(event api.AgentEvent, dedupKey string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	window, ok := eventDedupWindows[event.Type]
+	if !ok {
+		window = eventDedupWindow
+	}
+	if last, seen := q.recent[dedupKey]; seen && event.OccurredAt.Sub(last) < window {
+		return false
+	}
+	q.recent[dedupKey] = event.OccurredAt
+	for key, last := range q.recent {
+		if event.OccurredAt.Sub(last) > 24*time.Hour {
+			delete(q.recent, key)
+		}
+	}
+
+	q.events = append(q.events, event)
+	if dropped := len(q.events) - maxQueuedEvents; dropped > 0 {
+		klog.Warningf("Event queue full, dropping oldest events: dropped=%d", dropped)
+		q.events = q.events[dropped:]
+	}
+	q.saveLocked()
+	return true
+}
+
+// peek returns up to n of the oldest events
+func (q *eventQueue) peek(n int) []api.AgentEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]api.AgentEvent(nil), q.events[:min(n, len(q.events))]...)
+}
+
+// remove drops uploaded events. Events may have been dropped from the front
+// while uploading, so they are matched by ID.
+func (q *eventQueue) remove(uploaded []api.AgentEvent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	done := make(map[string]bool, len(uploaded))
+	for _, e := range uploaded {
+		done[e.ID] = true
+	}
+	kept := q.events[:0]
+	for _, e := range q.events {
+		if !done[e.ID] {
+			kept = append(kept, e)
+		}
+	}
+	q.events = kept
+	q.saveLocked()
+}
+
+func (q *eventQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events)
+}
+
+func (q *eventQueue) saveLocked() {
+	if err := utils.SaveJSONSlice(q.path, q.events, 0644); err != nil {
+		klog.Warningf("Failed to save event queue: path=%s error=%v", q.path, err)
+	}
+}
+
+// recordEvent queues an event for upload; it is a no-op before Start.
+// dedupKey identifies repeats of the same condition.
+func (a *Agent) recordEvent(event api.AgentEvent, dedupKey string) {
+	if a.events == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	event.ID = "evt_" + rand.Text()
+	if a.events.add(event, dedupKey) {
+		klog.V(2).Infof("Agent event recorded: type=%s worker_id=%s gpu_id=%s message=%q", event.Type, event.WorkerID, event.GPUID, event.Message)
+		if a.localAPI != nil {
+			a.localAPI.publish(event)
+		}
+	}
+}
+
+// recordWorkerEvent records a worker lifecycle event
+func (a *Agent) recordWorkerEvent(eventType, workerID, severity, message string, details map[string]string) {
+	a.recordEvent(api.AgentEvent{
+		Type:     eventType,
+		Severity: severity,
+		WorkerID: workerID,
+		Message:  message,
+		Details:  details,
+	}, eventType+"/"+workerID)
+}
+
+// recordCrashEvent records a captured worker crash
+func (a *Agent) recordCrashEvent(report api.WorkerCrashReport) {
+	details := map[string]string{
+		"reason":   report.Reason,
+		"restarts": strconv.Itoa(report.Restarts),
+	}
+	if report.ExitCode != nil {
+		details["exit_code"] = strconv.Itoa(*report.ExitCode)
+	}
+	if report.Signal != "" {
+		details["signal"] = report.Signal
+	}
+	a.recordEvent(api.AgentEvent{
+		Type:       api.AgentEventWorkerCrashed,
+		Severity:   api.AgentEventSeverityError,
+		OccurredAt: report.DetectedAt,
+		WorkerID:   report.WorkerID,
+		Message:    fmt.Sprintf("Worker crashed: %s", report.Reason),
+		Details:    details,
+	}, api.AgentEventWorkerCrashed+"/"+report.WorkerID+"/"+report.Reason)
+}
+
+// eventLoop checks for event conditions and uploads queued events, backing
+// off while the platform is unreachable
+func (a *Agent) eventLoop() {
+	defer a.wg.Done()
+
+	check := time.NewTicker(eventCheckInterval)
+	defer check.Stop()
+	upload := time.NewTimer(eventUploadInterval)
+	defer upload.Stop()
+
+	lastCheck := time.Now()
+	backoff := eventUploadInterval
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case now := <-check.C:
+			a.checkEventConditions(lastCheck, now)
+			lastCheck = now
+		case <-upload.C:
+			if err := a.uploadEvents(); err != nil {
+				klog.Warningf("Failed to upload agent events, retrying in %s: queued=%d error=%v", backoff, a.events.len(), err)
+				upload.Reset(backoff)
+				backoff = min(backoff*2, eventMaxBackoff)
+				continue
+			}
+			backoff = eventUploadInterval
+			upload.Reset(eventUploadInterval)
+		}
+	}
+}
+
+// uploadEvents sends the queued events in batches of eventBatchSize. At most
+// one batch is sent per call, so a backlog drains at a bounded rate.
+func (a *Agent) uploadEvents() error {
+	batch := a.events.peek(eventBatchSize)
+	if len(batch) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(a.ctx, eventUploadTimeout)
+	defer cancel()
+	if err := a.client.ReportAgentEvents(ctx, a.agentID, &api.AgentEventsRequest{Events: batch}); err != nil {
+		return err
+	}
+	a.events.remove(batch)
+	klog.V(2).Infof("Agent events uploaded: count=%d queued=%d", len(batch), a.events.len())
+	return nil
+}
+
+// checkEventConditions records events for conditions that are polled rather
+// than observed: an expiring license, a filling disk (see checkDisk) and GPU
+// errors logged by the kernel since the previous check
+func (a *Agent) checkEventConditions(since, now time.Time) {
+	if exp, err := a.getLicenseExpiration(); err == nil && exp != nil {
+		a.checkLicenseExpiry(time.UnixMilli(*exp), now)
+	}
+	a.checkDisk(now)
+	if a.hypervisorMgr != nil && a.kernelLog != nil {
+		for _, xid := range a.gpuXIDEvents(since) {
+			a.recordEvent(api.AgentEvent{
+				Type:     api.AgentEventGPUError,
+				Severity: api.AgentEventSeverityError,
+				GPUID:    xid.GPUID,
+				Message:  fmt.Sprintf("GPU reported Xid %d", xid.XID),
+				Details:  map[string]string{"xid": strconv.Itoa(xid.XID), "kernel_message": xid.Message},
+			}, fmt.Sprintf("%s/%s/%d", api.AgentEventGPUError, xid.GPUID, xid.XID))
+		}
+	}
+}
+
+// checkLicenseExpiry records license_expiring within licenseExpiryWarning of
+// the expiration
+func (a *Agent) checkLicenseExpiry(expiresAt, now time.Time) {
+	left := expiresAt.Sub(now)
+	if left > licenseExpiryWarning {
+		return
+	}
+	message := fmt.Sprintf("License expires in %s", left.Round(time.Hour))
+	if left <= 0 {
+		message = "License expired"
+	}
+	a.recordEvent(api.AgentEvent{
+		Type:     api.AgentEventLicenseExpiring,
+		Severity: api.AgentEventSeverityWarning,
+		Message:  message,
+		Details:  map[string]string{"expires_at": expiresAt.UTC().Format(time.RFC3339)},
+	}, api.AgentEventLicenseExpiring)
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventQueue_Dedup(t *testing.T) {
+	q := newEventQueue(filepath.Join(t.TempDir(), eventsFile))
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	event := func(eventType string, offset time.Duration) api.AgentEvent {
+		return api.AgentEvent{ID: fmt.Sprintf("evt_%d", offset), Type: eventType, OccurredAt: at.Add(offset)}
+	}
+
+	assert.True(t, q.add(event(api.AgentEventGPUError, 0), "gpu_error/GPU-1/79"))
+	assert.False(t, q.add(event(api.AgentEventGPUError, time.Minute), "gpu_error/GPU-1/79"), "repeat within the window")
+	assert.True(t, q.add(event(api.AgentEventGPUError, time.Minute), "gpu_error/GPU-1/48"), "another condition")
+	assert.True(t, q.add(event(api.AgentEventGPUError, eventDedupWindow), "gpu_error/GPU-1/79"))
+
+	assert.True(t, q.add(event(api.AgentEventWorkerStarted, 0), "worker_started/w1"))
+	assert.True(t, q.add(event(api.AgentEventWorkerStarted, time.Second), "worker_started/w1"), "deliberate starts are all kept")
+
+	assert.True(t, q.add(event(api.AgentEventDiskPressure, 0), "disk_pressure/cache"))
+	assert.False(t, q.add(event(api.AgentEventDiskPressure, 30*time.Minute), "disk_pressure/cache"))
+	assert.Equal(t, 6, q.len())
+}
+
+func TestEventQueue_PersistsAndBoundsEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), eventsFile)
+	q := newEventQueue(path)
+	for i := range maxQueuedEvents + 5 {
+		q.add(api.AgentEvent{ID: fmt.Sprintf("evt_%d", i), Type: api.AgentEventWorkerCrashed, OccurredAt: time.Now()}, fmt.Sprintf("key-%d", i))
+	}
+	assert.Equal(t, maxQueuedEvents, q.len())
+	assert.Equal(t, "evt_5", q.peek(1)[0].ID, "the oldest events are dropped")
+
+	q.remove(q.peek(10))
+	reloaded := newEventQueue(path)
+	assert.Equal(t, maxQueuedEvents-10, reloaded.len())
+	assert.Equal(t, "evt_15", reloaded.peek(1)[0].ID)
+}
+
+// eventsServer plays the platform's events API, failing the first requests
+type eventsServer struct {
+	mu       sync.Mutex
+	failures int
+	received [][]string
+}
+
+func (s *eventsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.URL.Path != "/api/v1/agents/agent_test123/events" {
+		http.NotFound(w, r)
+		return
+	}
+	var req api.AgentEventsRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	var ids []string
+	for _, e := range req.Events {
+		ids = append(ids, e.ID)
+	}
+	s.received = append(s.received, ids)
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func newEventsAgent(t *testing.T, serverURL string) *Agent {
+	tmpDir := t.TempDir()
+	configMgr := config.NewManager(filepath.Join(tmpDir, "config"), filepath.Join(tmpDir, "state"))
+	a := NewAgent(api.NewClient(api.WithBaseURL(serverURL)), configMgr)
+	a.agentID = "agent_test123"
+	a.events = newEventQueue(filepath.Join(tmpDir, "state", eventsFile))
+	return a
+}
+
+func TestAgent_UploadEventsRetriesWithSameIDs(t *testing.T) {
+	platform := &eventsServer{failures: 1}
+	server := httptest.NewServer(platform)
+	defer server.Close()
+	a := newEventsAgent(t, server.URL)
+
+	for i := range eventBatchSize + 3 {
+		a.recordWorkerEvent(api.AgentEventWorkerStarted, fmt.Sprintf("w%d", i), api.AgentEventSeverityInfo, "Worker started", nil)
+	}
+
+	require.Error(t, a.uploadEvents())
+	assert.Equal(t, eventBatchSize+3, a.events.len(), "failed uploads keep the events")
+
+	require.NoError(t, a.uploadEvents())
+	assert.Equal(t, 3, a.events.len(), "one batch per upload")
+	require.Len(t, platform.received, 2)
+	assert.Equal(t, platform.received[0], platform.received[1], "a retry resends the same event IDs")
+
+	require.NoError(t, a.uploadEvents())
+	assert.Zero(t, a.events.len())
+	require.NoError(t, a.uploadEvents(), "nothing to send")
+	assert.Len(t, platform.received, 3)
+}
+
+func TestAgent_CheckLicenseExpiry(t *testing.T) {
+	a := newEventsAgent(t, "http://127.0.0.1:0")
+	now := time.Now()
+
+	a.checkLicenseExpiry(now.Add(30*24*time.Hour), now)
+	assert.Zero(t, a.events.len())
+
+	a.checkLicenseExpiry(now.Add(3*24*time.Hour), now)
+	a.checkLicenseExpiry(now.Add(3*24*time.Hour), now.Add(time.Minute))
+	require.Equal(t, 1, a.events.len(), "at most one reminder a day")
+	event := a.events.peek(1)[0]
+	assert.Equal(t, api.AgentEventLicenseExpiring, event.Type)
+	assert.Equal(t, "License expires in 72h0m0s", event.Message)
+}
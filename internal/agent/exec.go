@@ -0,0 +1,422 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ExecAllowlistFile is the exec policy in the agent config directory;
+	// without it the agent refuses every remote command
+	ExecAllowlistFile = "exec-allowlist.json"
+	// DefaultExecTimeout bounds a remote command unless the policy says otherwise
+	DefaultExecTimeout = 30 * time.Second
+	// DefaultExecOutputLimit bounds the output sent back for a remote command
+	DefaultExecOutputLimit = 1 << 20
+
+	// execAuditFile records every remote command request in the state directory
+	execAuditFile = "exec-audit.log"
+	// execFlushInterval is how often pending output is sent to the server
+	execFlushInterval = 500 * time.Millisecond
+	// maxRemoteExecs caps concurrently running remote commands per agent
+	maxRemoteExecs = 2
+	// execAnyArgs ends an allowlist entry that accepts further arguments
+	execAnyArgs = "*"
+)
+
+// ExecPolicy is the allowlist of commands the platform may run on the agent
+// with `ggo agent exec`. Each entry is a command line matched word by word
+// against the requested command; an entry ending in "*" also accepts any
+// further arguments:
+//
+//	{"allow": ["nvidia-smi", "nvidia-smi -q *", "dmesg -T"], "timeout_seconds": 60}
+//
+// Commands run directly, not through a shell, with the agent's privileges.
+type ExecPolicy struct {
+	Allow          []string `json:"allow"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+	MaxOutputBytes int      `json:"max_output_bytes,omitempty"`
+}
+
+// LoadExecPolicy reads an exec policy; a missing file yields nil
+func LoadExecPolicy(path string) (*ExecPolicy, error) {
+	policy, err := utils.LoadJSON[ExecPolicy](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exec allowlist %s: %w", path, err)
+	}
+	if policy == nil {
+		return nil, nil
+	}
+	for _, entry := range policy.Allow {
+		if fields := strings.Fields(entry); len(fields) == 0 || fields[0] == execAnyArgs {
+			return nil, fmt.Errorf("invalid exec allowlist entry %q in %s", entry, path)
+		}
+	}
+	return policy, nil
+}
+
+// Allows reports whether command matches an allowlist entry
+func (p *ExecPolicy) Allows(command []string) bool {
+	if len(command) == 0 {
+		return false
+	}
+	for _, entry := range p.Allow {
+		fields := strings.Fields(entry)
+		if n := len(fields) - 1; n > 0 && fields[n] == execAnyArgs {
+			if len(command) >= n && slices.Equal(command[:n], fields[:n]) {
+				return true
+			}
+			continue
+		}
+		if slices.Equal(command, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// timeout returns how long a command may run, at most the policy's limit
+func (p *ExecPolicy) timeout(requested int) time.Duration {
+	limit := DefaultExecTimeout
+	if p.TimeoutSeconds > 0 {
+		limit = time.Duration(p.TimeoutSeconds) * time.Second
+	}
+	if requested > 0 {
+		return min(time.Duration(requested)*time.Second, limit)
+	}
+	return limit
+}
+
+func (p *ExecPolicy) outputLimit() int {
+	if p.MaxOutputBytes > 0 {
+		return p.MaxOutputBytes
+	}
+	return DefaultExecOutputLimit
+}
+
+// execAuditEntry is one line of the exec audit log
+type execAuditEntry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	RequestID   string    `json:"request_id"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	Command     []string  `json:"command"`
+	Allowed     bool      `json:"allowed"`
+	ExitCode    *int      `json:"exit_code,omitempty"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	OutputBytes int       `json:"output_bytes,omitempty"`
+	Truncated   bool      `json:"truncated,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// execRunner runs remote commands allowed by its policy and streams their
+// output through send. A nil policy refuses every command.
+type execRunner struct {
+	policy    *ExecPolicy
+	auditPath string
+	slots     chan struct{}
+	// send delivers a chunk; it returns nil if the chunk could not be sent
+	send func(*api.AgentExecChunk) *api.AgentExecChunkResponse
+	// audit is called with every finished or refused request
+	audit func(execAuditEntry)
+	mu    sync.Mutex // serializes audit log writes
+}
+
+func newExecRunner(policy *ExecPolicy, auditPath string, send func(*api.AgentExecChunk) *api.AgentExecChunkResponse) *execRunner {
+	return &execRunner{
+		policy:    policy,
+		auditPath: auditPath,
+		slots:     make(chan struct{}, maxRemoteExecs),
+		send:      send,
+	}
+}
+
+// admit checks req against the policy and takes a slot for it; on refusal
+// the requester is told why and the request is audited
+func (r *execRunner) admit(req api.AgentExecRequest) bool {
+	reason := ""
+	switch {
+	case r.policy == nil:
+		reason = "remote exec is disabled on this agent (no " + ExecAllowlistFile + " in its config directory)"
+	case !r.policy.Allows(req.Command):
+		reason = "command is not in the agent's exec allowlist"
+	default:
+		select {
+		case r.slots <- struct{}{}:
+			return true
+		default:
+			reason = "too many concurrent remote commands on this agent"
+		}
+	}
+	klog.Warningf("Remote exec refused: request_id=%s requested_by=%s command=%q reason=%s", req.RequestID, req.RequestedBy, req.Command, reason)
+	r.writeAudit(execAuditEntry{RequestID: req.RequestID, RequestedBy: req.RequestedBy, Command: req.Command, Error: reason})
+	r.send(&api.AgentExecChunk{RequestID: req.RequestID, EOF: true, Error: reason})
+	return false
+}
+
+// run executes an admitted request until it exits, times out, the requester
+// goes away or ctx is done, and releases its slot
+func (r *execRunner) run(ctx context.Context, req api.AgentExecRequest) {
+	defer func() { <-r.slots }()
+
+	timeout := r.policy.timeout(req.TimeoutSeconds)
+	klog.Infof("Running remote command: request_id=%s requested_by=%s command=%q timeout=%s", req.RequestID, req.RequestedBy, req.Command, timeout)
+	start := time.Now()
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	output := &execOutput{limit: r.policy.outputLimit()}
+	cmd := exec.CommandContext(runCtx, req.Command[0], req.Command[1:]...)
+	cmd.Stdout = output.writer(false)
+	cmd.Stderr = output.writer(true)
+	cmd.WaitDelay = hookWaitDelay
+	setHookProcAttr(cmd)
+
+	// The requester going away stops the command
+	var gone bool
+	flush := func(final *api.AgentExecChunk) {
+		chunk := final
+		if chunk == nil {
+			chunk = &api.AgentExecChunk{RequestID: req.RequestID}
+		}
+		chunk.Stdout, chunk.Stderr = output.take()
+		if final == nil && chunk.Stdout == "" && chunk.Stderr == "" {
+			return
+		}
+		if resp := r.send(chunk); final == nil && (resp == nil || resp.Closed) {
+			gone = true
+			cancel()
+		}
+	}
+
+	err := cmd.Start()
+	if err == nil {
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		ticker := time.NewTicker(execFlushInterval)
+	wait:
+		for {
+			select {
+			case err = <-done:
+				break wait
+			case <-ticker.C:
+				if !gone {
+					flush(nil)
+				}
+			}
+		}
+		ticker.Stop()
+	}
+
+	entry := execAuditEntry{
+		RequestID:   req.RequestID,
+		RequestedBy: req.RequestedBy,
+		Command:     req.Command,
+		Allowed:     true,
+		DurationMs:  time.Since(start).Milliseconds(),
+		OutputBytes: output.written(),
+		Truncated:   output.isTruncated(),
+	}
+	final := &api.AgentExecChunk{RequestID: req.RequestID, EOF: true, Truncated: entry.Truncated}
+	switch {
+	case gone:
+		entry.Error = "requester disconnected"
+	case ctx.Err() != nil:
+		entry.Error = "agent stopped"
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		entry.Error = fmt.Sprintf("timed out after %s", timeout)
+	case cmd.ProcessState != nil:
+		code := cmd.ProcessState.ExitCode()
+		entry.ExitCode = &code
+		final.ExitCode = &code
+	case err != nil:
+		entry.Error = err.Error()
+	}
+	final.Error = entry.Error
+	if !gone {
+		flush(final)
+	}
+
+	klog.Infof("Remote command finished: request_id=%s command=%q exit_code=%s duration=%s output_bytes=%d truncated=%t error=%q",
+		req.RequestID, req.Command, formatExitCode(entry.ExitCode), time.Since(start).Round(time.Millisecond), entry.OutputBytes, entry.Truncated, entry.Error)
+	r.writeAudit(entry)
+}
+
+// writeAudit appends entry to the exec audit log
+func (r *execRunner) writeAudit(entry execAuditEntry) {
+	entry.Timestamp = time.Now().UTC()
+	if r.audit != nil {
+		r.audit(entry)
+	}
+	if r.auditPath == "" {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(r.auditPath), 0700); err != nil {
+		klog.Warningf("Failed to write exec audit log: path=%s error=%v", r.auditPath, err)
+		return
+	}
+	f, err := os.OpenFile(r.auditPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		klog.Warningf("Failed to write exec audit log: path=%s error=%v", r.auditPath, err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		klog.Warningf("Failed to write exec audit log: path=%s error=%v", r.auditPath, err)
+	}
+}
+
+func formatExitCode(code *int) string {
+	if code == nil {
+		return "-"
+	}
+	return strconv.Itoa(*code)
+}
+
+// execOutput collects a command's stdout and stderr until they are sent,
+// keeping at most limit bytes in total
+type execOutput struct {
+	mu             sync.Mutex
+	stdout, stderr bytes.Buffer
+	total          int
+	limit          int
+	truncated      bool
+}
+
+type execOutputWriter struct {
+	o      *execOutput
+	stderr bool
+}
+
+func (o *execOutput) writer(stderr bool) io.Writer {
+	return &execOutputWriter{o: o, stderr: stderr}
+}
+
+func (w *execOutputWriter) Write(p []byte) (int, error) {
+	o := w.o
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	keep := p[:min(len(p), max(o.limit-o.total, 0))]
+	if len(keep) < len(p) {
+		o.truncated = true
+	}
+	if w.stderr {
+		o.stderr.Write(keep)
+	} else {
+		o.stdout.Write(keep)
+	}
+	o.total += len(keep)
+	return len(p), nil
+}
+
+// take returns and clears the output not sent yet
+func (o *execOutput) take() (string, string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	stdout, stderr := o.stdout.String(), o.stderr.String()
+	o.stdout.Reset()
+	o.stderr.Reset()
+	return stdout, stderr
+}
+
+func (o *execOutput) written() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.total
+}
+
+func (o *execOutput) isTruncated() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.truncated
+}
+
+// parseAgentExecRequest recognizes an AgentExecRequest among the frames of
+// the agent's config topic
+func parseAgentExecRequest(data string) (api.AgentExecRequest, bool) {
+	var req api.AgentExecRequest
+	if !strings.HasPrefix(data, "{") || json.Unmarshal([]byte(data), &req) != nil {
+		return req, false
+	}
+	return req, req.RequestID != "" && len(req.Command) > 0
+}
+
+// EnableRemoteExec lets the platform run the commands allowed by policy with
+// `ggo agent exec`. Agents refuse (and audit) every command until it is
+// called with a policy. Must be called before Start.
+func (a *Agent) EnableRemoteExec(policy *ExecPolicy) {
+	a.exec = newExecRunner(policy, filepath.Join(a.config.StateDir(), execAuditFile), a.sendAgentExecChunk)
+	a.exec.audit = a.recordExecEvent
+}
+
+// handleAgentExecRequest runs the command named by req if the policy allows it
+func (a *Agent) handleAgentExecRequest(req api.AgentExecRequest) {
+	if a.exec == nil {
+		klog.Warningf("Remote exec not set up, ignoring request: request_id=%s", req.RequestID)
+		return
+	}
+	if !a.exec.admit(req) {
+		return
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.exec.run(a.ctx, req)
+	}()
+}
+
+// sendAgentExecChunk delivers a chunk; returns nil if it could not be sent
+func (a *Agent) sendAgentExecChunk(chunk *api.AgentExecChunk) *api.AgentExecChunkResponse {
+	resp, err := a.client.SendAgentExecChunk(context.Background(), a.agentID, chunk)
+	if err != nil {
+		klog.Warningf("Failed to send exec output: request_id=%s error=%v", chunk.RequestID, err)
+		return nil
+	}
+	return resp
+}
+
+// recordExecEvent puts a remote command on the agent's timeline
+func (a *Agent) recordExecEvent(entry execAuditEntry) {
+	severity, message := api.AgentEventSeverityInfo, "Remote command ran"
+	if !entry.Allowed {
+		severity, message = api.AgentEventSeverityWarning, "Remote command refused"
+	}
+	details := map[string]string{
+		"request_id": entry.RequestID,
+		"command":    strings.Join(entry.Command, " "),
+		"exit_code":  formatExitCode(entry.ExitCode),
+	}
+	if entry.RequestedBy != "" {
+		details["requested_by"] = entry.RequestedBy
+	}
+	if entry.Error != "" {
+		details["error"] = entry.Error
+	}
+	a.recordEvent(api.AgentEvent{
+		Type:     api.AgentEventRemoteExec,
+		Severity: severity,
+		Message:  message,
+		Details:  details,
+	}, api.AgentEventRemoteExec+"/"+entry.RequestID)
+}
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecPolicy_Allows(t *testing.T) {
+	policy := &ExecPolicy{Allow: []string{"nvidia-smi", "nvidia-smi -q *", "dmesg  -T"}}
+
+	assert.True(t, policy.Allows([]string{"nvidia-smi"}))
+	assert.True(t, policy.Allows([]string{"nvidia-smi", "-q"}))
+	assert.True(t, policy.Allows([]string{"nvidia-smi", "-q", "-d", "ECC"}))
+	assert.True(t, policy.Allows([]string{"dmesg", "-T"}))
+
+	assert.False(t, policy.Allows([]string{"nvidia-smi", "-r"}), "arguments must be allowed explicitly")
+	assert.False(t, policy.Allows([]string{"dmesg", "-T", "-C"}))
+	assert.False(t, policy.Allows([]string{"/tmp/nvidia-smi"}))
+	assert.False(t, policy.Allows(nil))
+}
+
+func TestLoadExecPolicy(t *testing.T) {
+	dir := t.TempDir()
+
+	policy, err := LoadExecPolicy(filepath.Join(dir, ExecAllowlistFile))
+	require.NoError(t, err)
+	assert.Nil(t, policy, "no allowlist means no remote exec")
+
+	path := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"allow": ["*"]}`), 0600))
+	_, err = LoadExecPolicy(path)
+	assert.ErrorContains(t, err, "invalid exec allowlist entry")
+}
+
+func TestParseAgentExecRequest(t *testing.T) {
+	req, ok := parseAgentExecRequest(`{"request_id":"r1","exec":["nvidia-smi","-L"],"requested_by":"me@example.com"}`)
+	require.True(t, ok)
+	assert.Equal(t, []string{"nvidia-smi", "-L"}, req.Command)
+
+	_, ok = parseAgentExecRequest(`{"request_id":"r2","worker_id":"w1","tail":100}`)
+	assert.False(t, ok, "worker log requests are not exec requests")
+	_, ok = parseAgentExecRequest("config-updated")
+	assert.False(t, ok)
+}
+
+// execRecorder collects the chunks and audit entries of an execRunner
+type execRecorder struct {
+	mu      sync.Mutex
+	chunks  []*api.AgentExecChunk
+	entries []execAuditEntry
+}
+
+func newRecordingExecRunner(t *testing.T, policy *ExecPolicy) (*execRunner, *execRecorder) {
+	rec := &execRecorder{}
+	r := newExecRunner(policy, filepath.Join(t.TempDir(), execAuditFile), func(chunk *api.AgentExecChunk) *api.AgentExecChunkResponse {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.chunks = append(rec.chunks, chunk)
+		return &api.AgentExecChunkResponse{}
+	})
+	r.audit = func(entry execAuditEntry) {
+		rec.entries = append(rec.entries, entry)
+	}
+	return r, rec
+}
+
+func (rec *execRecorder) output() (stdout, stderr string) {
+	for _, c := range rec.chunks {
+		stdout += c.Stdout
+		stderr += c.Stderr
+	}
+	return stdout, stderr
+}
+
+func TestExecRunner_RefusesCommands(t *testing.T) {
+	r, rec := newRecordingExecRunner(t, nil)
+	assert.False(t, r.admit(api.AgentExecRequest{RequestID: "r1", Command: []string{"nvidia-smi"}}))
+	require.Len(t, rec.chunks, 1)
+	assert.True(t, rec.chunks[0].EOF)
+	assert.Contains(t, rec.chunks[0].Error, "disabled")
+
+	r, rec = newRecordingExecRunner(t, &ExecPolicy{Allow: []string{"nvidia-smi"}})
+	assert.False(t, r.admit(api.AgentExecRequest{RequestID: "r2", Command: []string{"rm", "-rf", "/"}}))
+	assert.Contains(t, rec.chunks[0].Error, "allowlist")
+	require.Len(t, rec.entries, 1)
+	assert.False(t, rec.entries[0].Allowed)
+
+	audit, err := os.ReadFile(r.auditPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(audit), `"command":["rm","-rf","/"]`)
+}
+
+func TestExecRunner_Run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	script := "echo out; echo err >&2; exit 3"
+	r, rec := newRecordingExecRunner(t, &ExecPolicy{Allow: []string{"sh -c *"}})
+	req := api.AgentExecRequest{RequestID: "r1", Command: []string{"sh", "-c", script}}
+	require.True(t, r.admit(req))
+	r.run(context.Background(), req)
+
+	stdout, stderr := rec.output()
+	assert.Equal(t, "out\n", stdout)
+	assert.Equal(t, "err\n", stderr)
+	last := rec.chunks[len(rec.chunks)-1]
+	assert.True(t, last.EOF)
+	require.NotNil(t, last.ExitCode)
+	assert.Equal(t, 3, *last.ExitCode)
+
+	require.Len(t, rec.entries, 1)
+	assert.True(t, rec.entries[0].Allowed)
+	assert.Equal(t, 8, rec.entries[0].OutputBytes)
+	assert.Empty(t, r.slots, "the slot is released")
+}
+
+func TestExecRunner_LimitsOutputAndTime(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	r, rec := newRecordingExecRunner(t, &ExecPolicy{Allow: []string{"sh -c *"}, MaxOutputBytes: 10, TimeoutSeconds: 1})
+
+	req := api.AgentExecRequest{RequestID: "r1", Command: []string{"sh", "-c", "echo " + strings.Repeat("x", 100)}}
+	require.True(t, r.admit(req))
+	r.run(context.Background(), req)
+	stdout, _ := rec.output()
+	assert.Equal(t, strings.Repeat("x", 10), stdout)
+	assert.True(t, rec.chunks[len(rec.chunks)-1].Truncated)
+
+	rec.chunks = nil
+	req = api.AgentExecRequest{RequestID: "r2", Command: []string{"sh", "-c", "sleep 30"}, TimeoutSeconds: 60}
+	require.True(t, r.admit(req))
+	r.run(context.Background(), req)
+	last := rec.chunks[len(rec.chunks)-1]
+	assert.Contains(t, last.Error, "timed out after 1s", "the policy caps the requested timeout")
+	assert.Nil(t, last.ExitCode)
+}
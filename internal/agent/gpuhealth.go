@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+)
+
+// overheatCelsius is the temperature from which a GPU is flagged as
+// overheating; most datacenter GPUs start throttling around it
+const overheatCelsius = 90
+
+// gpuHealth returns the health flags of a configured GPU. listed tells
+// whether the hypervisor lists the GPU and is only meaningful when devices
+// were listed at all; metrics is nil when none were collected.
+func gpuHealth(devicesListed, listed bool, metrics *hvApi.GPUUsageMetrics) []string {
+	var flags []string
+	if devicesListed && !listed {
+		flags = append(flags, api.GPUHealthNotDetected)
+	}
+	if metrics != nil && metrics.Temperature >= overheatCelsius {
+		flags = append(flags, api.GPUHealthOverheating)
+	}
+	return flags
+}
+
+// metricsByGPU keys hypervisor metrics by normalized GPU ID
+func metricsByGPU(metrics map[string]*hvApi.GPUUsageMetrics) map[string]*hvApi.GPUUsageMetrics {
+	byGPU := make(map[string]*hvApi.GPUUsageMetrics, len(metrics))
+	for uuid, m := range metrics {
+		if m.DeviceUUID != "" {
+			uuid = m.DeviceUUID
+		}
+		byGPU[normalizeGPUID(uuid)] = m
+	}
+	return byGPU
+}
+
+// gpuHealthChanged records the health flags of a GPU and reports whether
+// they differ from the previous report
+func (a *Agent) gpuHealthChanged(gpuID string, flags []string) bool {
+	key := strings.Join(flags, ",")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.prevGPUHealth == nil {
+		a.prevGPUHealth = make(map[string]string)
+	}
+	prev, seen := a.prevGPUHealth[gpuID]
+	a.prevGPUHealth[gpuID] = key
+	return seen && prev != key || !seen && len(flags) > 0
+}
@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGPUHealth(t *testing.T) {
+	assert.Empty(t, gpuHealth(true, true, &hvApi.GPUUsageMetrics{Temperature: 60}))
+	assert.Empty(t, gpuHealth(false, false, nil), "without a device list nothing is known")
+	assert.Equal(t, []string{api.GPUHealthNotDetected}, gpuHealth(true, false, nil))
+	assert.Equal(t, []string{api.GPUHealthOverheating}, gpuHealth(true, true, &hvApi.GPUUsageMetrics{Temperature: overheatCelsius}))
+}
+
+func TestMetricsByGPU(t *testing.T) {
+	m := &hvApi.GPUUsageMetrics{DeviceUUID: "GPU-ABC", Temperature: 50}
+	byGPU := metricsByGPU(map[string]*hvApi.GPUUsageMetrics{"0": m})
+	assert.Same(t, m, byGPU["gpu-abc"])
+}
+
+func TestGPUHealthChanged(t *testing.T) {
+	a := &Agent{}
+	assert.False(t, a.gpuHealthChanged("gpu-0", nil), "a healthy GPU seen for the first time is no change")
+	assert.False(t, a.gpuHealthChanged("gpu-0", nil))
+	assert.True(t, a.gpuHealthChanged("gpu-0", []string{api.GPUHealthOverheating}))
+	assert.False(t, a.gpuHealthChanged("gpu-0", []string{api.GPUHealthOverheating}))
+	assert.True(t, a.gpuHealthChanged("gpu-0", nil))
+	assert.True(t, a.gpuHealthChanged("gpu-1", []string{api.GPUHealthNotDetected}))
+}
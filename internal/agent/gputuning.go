@@ -0,0 +1,351 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// gpuTuningStateFile persists the GPU settings to restore once workers
+	// with GPU tuning stopped
+	gpuTuningStateFile = "gpu-tuning.json"
+	// gpuTuningCommandTimeout bounds the nvidia-smi or rocm-smi invocations
+	// tuning or restoring the GPUs of a worker
+	gpuTuningCommandTimeout = 30 * time.Second
+)
+
+// smiRunner runs a GPU management tool with args and returns its output
+type smiRunner func(ctx context.Context, args ...string) (string, error)
+
+// gpuSettings are the settings of a GPU the tuning changes, as nvidia-smi
+// reports them
+type gpuSettings struct {
+	PowerLimit      string `json:"power_limit,omitempty"`
+	PersistenceMode string `json:"persistence_mode,omitempty"`
+	ComputeMode     string `json:"compute_mode,omitempty"`
+}
+
+// gpuTuningRecord is a GPU tuned for a worker, with the settings it had
+// before any worker tuned it
+type gpuTuningRecord struct {
+	WorkerID string              `json:"worker_id"`
+	Vendor   string              `json:"vendor"`
+	GPU      string              `json:"gpu"`
+	Tuning   api.WorkerGPUTuning `json:"tuning"`
+	Original gpuSettings         `json:"original"`
+}
+
+// gpuTuningManager applies the GPU tuning of workers through nvidia-smi
+// (GPUs addressed by UUID) or rocm-smi (GPUs addressed by index). The
+// settings a GPU had before are persisted so that they are restored once the
+// last worker tuning it stopped, even by a restarted agent.
+type gpuTuningManager struct {
+	mu      sync.Mutex
+	path    string
+	nvidia  smiRunner
+	rocm    smiRunner
+	records []gpuTuningRecord
+}
+
+// newGPUTuningManager returns a manager persisting its records at path, or
+// nil where neither nvidia-smi nor rocm-smi is installed
+func newGPUTuningManager(path string) *gpuTuningManager {
+	nvidia, rocm := lookupSMI("nvidia-smi"), lookupSMI("rocm-smi")
+	if nvidia == nil && rocm == nil {
+		return nil
+	}
+	return newGPUTuningManagerWithRunners(path, nvidia, rocm)
+}
+
+// lookupSMI returns a runner for the named tool, or nil if it is not in PATH
+func lookupSMI(name string) smiRunner {
+	smiPath, err := exec.LookPath(name)
+	if err != nil {
+		return nil
+	}
+	return func(ctx context.Context, args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, smiPath, args...).CombinedOutput()
+		if err != nil {
+			return string(out), fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+		return string(out), nil
+	}
+}
+
+func newGPUTuningManagerWithRunners(path string, nvidia, rocm smiRunner) *gpuTuningManager {
+	records, err := utils.LoadJSONSlice[gpuTuningRecord](path)
+	if err != nil {
+		klog.Warningf("Ignoring unreadable GPU tuning state: path=%s error=%v", path, err)
+		records = nil
+	}
+	return &gpuTuningManager{path: path, nvidia: nvidia, rocm: rocm, records: records}
+}
+
+// ensure tunes the GPUs of a worker, replacing the worker's earlier tuning
+// if it changed. vendor selects the tool; GPUs are UUIDs for NVIDIA and
+// indices for AMD.
+func (m *gpuTuningManager) ensure(ctx context.Context, workerID, vendor string, gpus []string, tuning api.WorkerGPUTuning) error {
+	if vendor == "" {
+		vendor = vendorNVIDIA
+		if m.nvidia == nil {
+			vendor = vendorAMD
+		}
+	}
+	switch {
+	case vendor == vendorNVIDIA && m.nvidia == nil:
+		return fmt.Errorf("GPU tuning of NVIDIA GPUs requires nvidia-smi")
+	case vendor == vendorAMD && m.rocm == nil:
+		return fmt.Errorf("GPU tuning of AMD GPUs requires rocm-smi")
+	case vendor != vendorNVIDIA && vendor != vendorAMD:
+		return fmt.Errorf("GPU tuning is not supported for %s GPUs", vendor)
+	case vendor == vendorAMD && (tuning.PersistenceMode != nil || tuning.ComputeMode != ""):
+		return fmt.Errorf("persistence and compute modes are only supported on NVIDIA GPUs")
+	case len(gpus) == 0:
+		return fmt.Errorf("no GPU to tune")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := m.recordsLocked(workerID)
+	if len(current) == len(gpus) && !slices.ContainsFunc(current, func(r gpuTuningRecord) bool {
+		return r.Vendor != vendor || !slices.Contains(gpus, r.GPU) || !reflect.DeepEqual(r.Tuning, tuning)
+	}) {
+		return nil
+	}
+	m.releaseLocked(ctx, workerID)
+
+	ctx, cancel := context.WithTimeout(ctx, gpuTuningCommandTimeout)
+	defer cancel()
+	for _, gpu := range gpus {
+		record := gpuTuningRecord{WorkerID: workerID, Vendor: vendor, GPU: gpu, Tuning: tuning}
+		if holder, ok := m.holderLocked(vendor, gpu); ok {
+			// The GPU is already tuned for another worker, whose record has
+			// the settings to restore
+			record.Original = holder.Original
+		} else if vendor == vendorNVIDIA {
+			original, err := m.querySettings(ctx, gpu)
+			if err != nil {
+				m.releaseLocked(ctx, workerID)
+				return err
+			}
+			record.Original = original
+		}
+		// Record before tuning, so that a crash halfway still restores
+		m.records = append(m.records, record)
+		m.saveLocked()
+		if err := m.apply(ctx, record); err != nil {
+			m.releaseLocked(ctx, workerID)
+			return err
+		}
+	}
+	return nil
+}
+
+// release restores the GPUs tuned for the worker
+func (m *gpuTuningManager) release(ctx context.Context, workerID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.releaseLocked(ctx, workerID)
+}
+
+// releaseLocked forgets the records of the worker, restoring each GPU's
+// original settings, or the tuning of another worker still holding it
+func (m *gpuTuningManager) releaseLocked(ctx context.Context, workerID string) {
+	released := m.recordsLocked(workerID)
+	if len(released) == 0 {
+		return
+	}
+	m.records = slices.DeleteFunc(m.records, func(r gpuTuningRecord) bool { return r.WorkerID == workerID })
+	m.saveLocked()
+
+	ctx, cancel := context.WithTimeout(ctx, gpuTuningCommandTimeout)
+	defer cancel()
+	for _, record := range released {
+		var err error
+		if holder, ok := m.holderLocked(record.Vendor, record.GPU); ok {
+			err = m.apply(ctx, holder)
+		} else {
+			err = m.restore(ctx, record)
+		}
+		if err != nil {
+			klog.Warningf("Failed to restore GPU settings: worker_id=%s gpu=%s error=%v", workerID, record.GPU, err)
+		}
+	}
+}
+
+func (m *gpuTuningManager) recordsLocked(workerID string) []gpuTuningRecord {
+	var records []gpuTuningRecord
+	for _, r := range m.records {
+		if r.WorkerID == workerID {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// holderLocked returns the record of a worker tuning the GPU
+func (m *gpuTuningManager) holderLocked(vendor, gpu string) (gpuTuningRecord, bool) {
+	for _, r := range m.records {
+		if r.Vendor == vendor && r.GPU == gpu {
+			return r, true
+		}
+	}
+	return gpuTuningRecord{}, false
+}
+
+func (m *gpuTuningManager) saveLocked() {
+	if err := utils.SaveJSONSlice(m.path, m.records, 0644); err != nil {
+		klog.Warningf("Failed to save GPU tuning state: path=%s error=%v", m.path, err)
+	}
+}
+
+// querySettings reads the settings of an NVIDIA GPU the tuning may change
+func (m *gpuTuningManager) querySettings(ctx context.Context, gpu string) (gpuSettings, error) {
+	out, err := m.nvidia(ctx, "-i", gpu, "--query-gpu=power.limit,persistence_mode,compute_mode", "--format=csv,noheader,nounits")
+	if err != nil {
+		return gpuSettings{}, err
+	}
+	fields := strings.Split(strings.TrimSpace(out), ",")
+	if len(fields) != 3 {
+		return gpuSettings{}, fmt.Errorf("unexpected nvidia-smi output %q", strings.TrimSpace(out))
+	}
+	return gpuSettings{
+		PowerLimit:      strings.TrimSpace(fields[0]),
+		PersistenceMode: strings.TrimSpace(fields[1]),
+		ComputeMode:     strings.TrimSpace(fields[2]),
+	}, nil
+}
+
+// apply sets the tuning of a record on its GPU
+func (m *gpuTuningManager) apply(ctx context.Context, r gpuTuningRecord) error {
+	t := r.Tuning
+	var commands [][]string
+	if r.Vendor == vendorAMD {
+		if t.PowerLimitWatts > 0 {
+			commands = append(commands, []string{"-d", r.GPU, "--autorespond", "yes", "--setpoweroverdrive", strconv.Itoa(t.PowerLimitWatts)})
+		}
+		if t.MaxClockMHz > 0 {
+			commands = append(commands, []string{"-d", r.GPU, "--autorespond", "yes", "--setsrange", strconv.Itoa(t.MinClockMHz), strconv.Itoa(t.MaxClockMHz)})
+		}
+		return runSMI(ctx, m.rocm, commands)
+	}
+
+	if t.PowerLimitWatts > 0 {
+		commands = append(commands, []string{"-i", r.GPU, "-pl", strconv.Itoa(t.PowerLimitWatts)})
+	}
+	if t.MaxClockMHz > 0 {
+		commands = append(commands, []string{"-i", r.GPU, "-lgc", fmt.Sprintf("%d,%d", t.MinClockMHz, t.MaxClockMHz)})
+	}
+	if t.PersistenceMode != nil {
+		commands = append(commands, []string{"-i", r.GPU, "-pm", boolToSMI(*t.PersistenceMode)})
+	}
+	if t.ComputeMode != "" {
+		commands = append(commands, []string{"-i", r.GPU, "-c", nvidiaComputeMode(t.ComputeMode)})
+	}
+	return runSMI(ctx, m.nvidia, commands)
+}
+
+// restore undoes the tuning of a record on its GPU: NVIDIA GPUs get their
+// original settings back, AMD GPUs their defaults
+func (m *gpuTuningManager) restore(ctx context.Context, r gpuTuningRecord) error {
+	t, o := r.Tuning, r.Original
+	var commands [][]string
+	if r.Vendor == vendorAMD {
+		if t.PowerLimitWatts > 0 {
+			commands = append(commands, []string{"-d", r.GPU, "--autorespond", "yes", "--resetpoweroverdrive"})
+		}
+		if t.MaxClockMHz > 0 {
+			commands = append(commands, []string{"-d", r.GPU, "--resetclocks"})
+		}
+		return runSMI(ctx, m.rocm, commands)
+	}
+
+	if t.PowerLimitWatts > 0 && o.PowerLimit != "" && o.PowerLimit != "[N/A]" {
+		commands = append(commands, []string{"-i", r.GPU, "-pl", o.PowerLimit})
+	}
+	if t.MaxClockMHz > 0 {
+		commands = append(commands, []string{"-i", r.GPU, "-rgc"})
+	}
+	if t.PersistenceMode != nil && o.PersistenceMode != "" && o.PersistenceMode != "[N/A]" {
+		commands = append(commands, []string{"-i", r.GPU, "-pm", boolToSMI(strings.EqualFold(o.PersistenceMode, "Enabled"))})
+	}
+	if t.ComputeMode != "" && o.ComputeMode != "" && o.ComputeMode != "[N/A]" {
+		commands = append(commands, []string{"-i", r.GPU, "-c", strings.ToUpper(o.ComputeMode)})
+	}
+	return runSMI(ctx, m.nvidia, commands)
+}
+
+// runSMI runs all commands, returning the first error
+func runSMI(ctx context.Context, smi smiRunner, commands [][]string) error {
+	var firstErr error
+	for _, args := range commands {
+		if _, err := smi(ctx, args...); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func boolToSMI(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// nvidiaComputeMode returns the nvidia-smi name of an api.ComputeMode*
+// constant, e.g. EXCLUSIVE_PROCESS
+func nvidiaComputeMode(mode string) string {
+	return strings.ToUpper(strings.ReplaceAll(mode, "-", "_"))
+}
+
+// ensureGPUTuning tunes the GPUs of a worker with GPU tuning, and restores
+// them once the tuning was removed
+func (a *Agent) ensureGPUTuning(w api.WorkerConfig, vendor string, gpuIndices []int) error {
+	if w.Tuning.IsZero() {
+		if a.gpuTuning != nil {
+			a.gpuTuning.release(a.ctx, w.WorkerID)
+		}
+		return nil
+	}
+	if a.gpuTuning == nil {
+		return fmt.Errorf("GPU tuning requires nvidia-smi or rocm-smi")
+	}
+	gpus := w.GPUIDs
+	if vendor == vendorAMD {
+		gpus = make([]string, 0, len(gpuIndices))
+		for _, index := range gpuIndices {
+			gpus = append(gpus, strconv.Itoa(index))
+		}
+	}
+	return a.gpuTuning.ensure(a.ctx, w.WorkerID, vendor, gpus, *w.Tuning)
+}
+
+// releaseGPUTuning restores the GPUs of a stopped worker unless it is about
+// to be started again with GPU tuning
+func (a *Agent) releaseGPUTuning(workerID string) {
+	if a.gpuTuning == nil {
+		return
+	}
+	a.mu.RLock()
+	restarting := slices.ContainsFunc(a.workerConfigs, func(w api.WorkerConfig) bool {
+		return w.WorkerID == workerID && w.Enabled && !w.Tuning.IsZero()
+	})
+	a.mu.RUnlock()
+	if restarting {
+		return
+	}
+	a.gpuTuning.release(a.ctx, workerID)
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSMI plays nvidia-smi or rocm-smi, recording the calls
+type recordingSMI struct {
+	calls []string
+}
+
+func (f *recordingSMI) run(_ context.Context, args ...string) (string, error) {
+	call := strings.Join(args, " ")
+	f.calls = append(f.calls, call)
+	if strings.Contains(call, "--query-gpu") {
+		return "300.00, Disabled, Default\n", nil
+	}
+	return "", nil
+}
+
+func TestGPUTuningManager_EnsureAndRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), gpuTuningStateFile)
+	smi := &recordingSMI{}
+	m := newGPUTuningManagerWithRunners(path, smi.run, nil)
+	ctx := context.Background()
+	on := true
+	tuning := api.WorkerGPUTuning{PowerLimitWatts: 250, MinClockMHz: 1200, MaxClockMHz: 1500, PersistenceMode: &on, ComputeMode: api.ComputeModeExclusiveProcess}
+
+	require.NoError(t, m.ensure(ctx, "worker_1", vendorNVIDIA, []string{"GPU-aaaa"}, tuning))
+	assert.Equal(t, []string{
+		"-i GPU-aaaa --query-gpu=power.limit,persistence_mode,compute_mode --format=csv,noheader,nounits",
+		"-i GPU-aaaa -pl 250",
+		"-i GPU-aaaa -lgc 1200,1500",
+		"-i GPU-aaaa -pm 1",
+		"-i GPU-aaaa -c EXCLUSIVE_PROCESS",
+	}, smi.calls)
+
+	smi.calls = nil
+	require.NoError(t, m.ensure(ctx, "worker_1", vendorNVIDIA, []string{"GPU-aaaa"}, tuning))
+	assert.Empty(t, smi.calls, "unchanged tuning is not applied again")
+
+	// A restarted agent restores the settings the GPU had
+	m = newGPUTuningManagerWithRunners(path, smi.run, nil)
+	m.release(ctx, "worker_1")
+	assert.Equal(t, []string{
+		"-i GPU-aaaa -pl 300.00",
+		"-i GPU-aaaa -rgc",
+		"-i GPU-aaaa -pm 0",
+		"-i GPU-aaaa -c DEFAULT",
+	}, smi.calls)
+	assert.Empty(t, m.records)
+}
+
+func TestGPUTuningManager_SharedGPU(t *testing.T) {
+	smi := &recordingSMI{}
+	m := newGPUTuningManagerWithRunners(filepath.Join(t.TempDir(), gpuTuningStateFile), smi.run, nil)
+	ctx := context.Background()
+
+	require.NoError(t, m.ensure(ctx, "worker_1", vendorNVIDIA, []string{"GPU-aaaa"}, api.WorkerGPUTuning{PowerLimitWatts: 250}))
+	require.NoError(t, m.ensure(ctx, "worker_2", vendorNVIDIA, []string{"GPU-aaaa"}, api.WorkerGPUTuning{PowerLimitWatts: 200}))
+	assert.Len(t, smi.calls, 3, "the GPU's settings are only queried once")
+
+	smi.calls = nil
+	m.release(ctx, "worker_2")
+	assert.Equal(t, []string{"-i GPU-aaaa -pl 250"}, smi.calls, "the remaining worker's tuning is applied again")
+
+	smi.calls = nil
+	m.release(ctx, "worker_1")
+	assert.Equal(t, []string{"-i GPU-aaaa -pl 300.00"}, smi.calls)
+}
+
+func TestGPUTuningManager_AMD(t *testing.T) {
+	smi := &recordingSMI{}
+	m := newGPUTuningManagerWithRunners(filepath.Join(t.TempDir(), gpuTuningStateFile), nil, smi.run)
+	ctx := context.Background()
+
+	on := true
+	assert.Error(t, m.ensure(ctx, "worker_1", vendorAMD, []string{"0"}, api.WorkerGPUTuning{PersistenceMode: &on}))
+	assert.Error(t, m.ensure(ctx, "worker_1", vendorNVIDIA, []string{"GPU-aaaa"}, api.WorkerGPUTuning{PowerLimitWatts: 250}))
+	assert.Empty(t, smi.calls)
+
+	require.NoError(t, m.ensure(ctx, "worker_1", "", []string{"0"}, api.WorkerGPUTuning{PowerLimitWatts: 200, MinClockMHz: 500, MaxClockMHz: 1800}))
+	m.release(ctx, "worker_1")
+	assert.Equal(t, []string{
+		"-d 0 --autorespond yes --setpoweroverdrive 200",
+		"-d 0 --autorespond yes --setsrange 500 1800",
+		"-d 0 --autorespond yes --resetpoweroverdrive",
+		"-d 0 --resetclocks",
+	}, smi.calls)
+}
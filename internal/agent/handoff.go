@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// restartRequestFile asks the running agent to restart itself
+	restartRequestFile = "agent-restart.json"
+
+	// handoffFile lists the workers an agent left running on restart for
+	// the next agent to adopt
+	handoffFile = "agent-handoff.json"
+
+	// handoffMaxAge bounds how old a handoff may be. Past it the recorded
+	// PIDs may belong to other processes, so the workers are started anew.
+	handoffMaxAge = 5 * time.Minute
+
+	// restartPollInterval is how often the agent looks for a restart request
+	restartPollInterval = time.Second
+)
+
+// RestartRequest asks the running agent to restart, written by 'ggo agent restart'
+type RestartRequest struct {
+	RequestedAt     time.Time `json:"requested_at"`
+	PreserveWorkers bool      `json:"preserve_workers"`
+}
+
+// workerHandoff records the workers left running for the next agent
+type workerHandoff struct {
+	CreatedAt time.Time           `json:"created_at"`
+	Workers   []*hvApi.WorkerInfo `json:"workers"`
+}
+
+// RequestRestart asks the agent using stateDir to restart
+func RequestRestart(stateDir string, req RestartRequest) error {
+	return utils.SaveJSON(filepath.Join(stateDir, restartRequestFile), req, 0644)
+}
+
+// RestartPending reports whether a restart request has not been picked up yet
+func RestartPending(stateDir string) bool {
+	_, err := os.Stat(filepath.Join(stateDir, restartRequestFile))
+	return err == nil
+}
+
+// RestartRequests delivers restart requests to the process running the
+// agent, which stops the agent with StopForRestart and starts it again
+func (a *Agent) RestartRequests() <-chan RestartRequest {
+	requests := make(chan RestartRequest, 1)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(restartPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			req := a.takeRestartRequest()
+			if req == nil {
+				continue
+			}
+			select {
+			case requests <- *req:
+			default:
+			}
+		}
+	}()
+	return requests
+}
+
+// takeRestartRequest reads and removes a pending restart request
+func (a *Agent) takeRestartRequest() *RestartRequest {
+	path := filepath.Join(a.config.StateDir(), restartRequestFile)
+	req, err := utils.LoadJSON[RestartRequest](path)
+	if err != nil {
+		klog.Warningf("Ignoring unreadable restart request: path=%s error=%v", path, err)
+	}
+	if req == nil && err == nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Failed to remove restart request: path=%s error=%v", path, err)
+	}
+	return req
+}
+
+// StopForRestart stops the agent ahead of a restart. With preserveWorkers
+// the worker processes keep running and serving their clients, and are
+// recorded for the next agent to adopt; only connections through the
+// connection proxy are cut. Shutdown is not reported, since the platform
+// would otherwise mark the workers stopped.
+func (a *Agent) StopForRestart(preserveWorkers bool) {
+	if !preserveWorkers || a.hypervisorMgr == nil {
+		a.Stop()
+		return
+	}
+	klog.Info("Stopping agent for restart, workers keep running...")
+
+	a.cancel()
+	if a.reconciler != nil {
+		a.reconciler.Stop()
+	}
+	if a.proxy != nil {
+		a.proxy.Stop()
+	}
+	if a.relay != nil {
+		a.relay.Stop()
+	}
+
+	workers := a.hypervisorMgr.Detach()
+	path := filepath.Join(a.config.StateDir(), handoffFile)
+	if err := utils.SaveJSON(path, workerHandoff{CreatedAt: time.Now(), Workers: workers}, 0600); err != nil {
+		klog.Errorf("Failed to record workers for the next agent, they will be restarted: error=%v", err)
+	}
+
+	if err := a.removePIDFile(); err != nil {
+		klog.Warningf("Failed to remove PID file: error=%v", err)
+	}
+	a.wg.Wait()
+	a.removeLiveStatus()
+
+	klog.Infof("Agent stopped for restart: workers=%d", len(workers))
+}
+
+// adoptHandoffWorkers takes over the workers a restarted agent left running
+func (a *Agent) adoptHandoffWorkers() {
+	path := filepath.Join(a.config.StateDir(), handoffFile)
+	handoff, err := utils.LoadJSON[workerHandoff](path)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Failed to remove worker handoff: path=%s error=%v", path, err)
+	}
+	switch {
+	case err != nil:
+		klog.Warningf("Ignoring unreadable worker handoff: path=%s error=%v", path, err)
+		return
+	case handoff == nil:
+		return
+	case time.Since(handoff.CreatedAt) > handoffMaxAge:
+		klog.Warningf("Ignoring stale worker handoff, workers will be restarted: created_at=%s", handoff.CreatedAt)
+		return
+	}
+	adopted := a.hypervisorMgr.AdoptWorkers(handoff.Workers)
+	klog.Infof("Adopted workers from previous agent: adopted=%d recorded=%d", adopted, len(handoff.Workers))
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handoffHypervisor leaves its workers running on Detach and records adoptions
+type handoffHypervisor struct {
+	*processHypervisor
+	adopted []*hvApi.WorkerInfo
+}
+
+func (h *handoffHypervisor) Detach() []*hvApi.WorkerInfo {
+	return h.ListWorkers()
+}
+
+func (h *handoffHypervisor) AdoptWorkers(workers []*hvApi.WorkerInfo) int {
+	h.adopted = workers
+	return len(workers)
+}
+
+func TestRestartRequest(t *testing.T) {
+	hv := newProcessHypervisor()
+	a := newUpgradeAgent(t, hv, "/opt/worker/remote-gpu-worker")
+	stateDir := a.config.StateDir()
+
+	assert.Nil(t, a.takeRestartRequest())
+	assert.False(t, RestartPending(stateDir))
+
+	require.NoError(t, RequestRestart(stateDir, RestartRequest{RequestedAt: time.Now(), PreserveWorkers: true}))
+	assert.True(t, RestartPending(stateDir))
+	req := a.takeRestartRequest()
+	require.NotNil(t, req)
+	assert.True(t, req.PreserveWorkers)
+	assert.False(t, RestartPending(stateDir), "a request is handled once")
+}
+
+func TestStopForRestart_HandsOverWorkers(t *testing.T) {
+	hv := &handoffHypervisor{processHypervisor: newProcessHypervisor()}
+	a := newUpgradeAgent(t, hv.processHypervisor, "/opt/worker/remote-gpu-worker")
+	a.hypervisorMgr = hv
+
+	a.StopForRestart(true)
+	assert.Len(t, hv.executables(), 2, "workers keep running")
+
+	// The next agent adopts them before reconciling
+	next := NewAgentWithHypervisor(a.client, a.config, hv, "/opt/worker/remote-gpu-worker")
+	next.adoptHandoffWorkers()
+	require.Len(t, hv.adopted, 2)
+	assert.ElementsMatch(t, []string{"w1", "w2"}, []string{hv.adopted[0].WorkerUID, hv.adopted[1].WorkerUID})
+	assert.NoFileExists(t, filepath.Join(a.config.StateDir(), handoffFile), "a handoff is used once")
+
+	// A stale handoff is not trusted: its PIDs may have been reused
+	hv.adopted = nil
+	path := filepath.Join(a.config.StateDir(), handoffFile)
+	require.NoError(t, utils.SaveJSON(path, workerHandoff{
+		CreatedAt: time.Now().Add(-time.Hour),
+		Workers:   []*hvApi.WorkerInfo{{WorkerUID: "w1"}},
+	}, 0600))
+	next.adoptHandoffWorkers()
+	assert.Nil(t, hv.adopted)
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
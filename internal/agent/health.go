@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/hypervisor"
+	"k8s.io/klog/v2"
+)
+
+// nvidiaGPUsProcDir lists one directory per NVIDIA GPU, named after its PCI
+// bus location, with the GPU UUID in its information file
+const nvidiaGPUsProcDir = "/proc/driver/nvidia/gpus"
+
+// NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, name=worker, GPU has fallen off the bus.
+var xidBusPattern = regexp.MustCompile(`NVRM: Xid \(PCI:([0-9A-Fa-f:.]+)\): (\d+)`)
+
+// EnableHealthProbes turns on worker health probes in the reconciler. With
+// xid, Xid errors are read from the kernel log unless cfg supplies its own
+// source. Without hypervisor integration there are no workers to probe. Must
+// be called before Start.
+func (a *Agent) EnableHealthProbes(cfg hypervisor.HealthConfig, xid bool) {
+	if a.reconciler == nil {
+		return
+	}
+	if xid && cfg.XIDEvents == nil && a.kernelLog != nil {
+		cfg.XIDEvents = a.gpuXIDEvents
+	}
+	a.reconciler.EnableHealthProbes(cfg)
+	a.healthProbes = true
+}
+
+// gpuXIDEvents returns the Xid errors logged since the given time, with the
+// GPU resolved from the PCI bus location the driver logged
+func (a *Agent) gpuXIDEvents(since time.Time) []hypervisor.XIDEvent {
+	lines := a.kernelLog(since)
+	if len(lines) == 0 {
+		return nil
+	}
+	return parseXIDEvents(lines, nvidiaGPUsByBus(nvidiaGPUsProcDir))
+}
+
+// parseXIDEvents extracts Xid errors from kernel messages. Errors on buses
+// without a known GPU are dropped.
+func parseXIDEvents(lines []string, gpuByBus map[string]string) []hypervisor.XIDEvent {
+	var events []hypervisor.XIDEvent
+	for _, line := range lines {
+		m := xidBusPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		xid, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		gpuID, ok := gpuByBus[normalizeBusID(m[1])]
+		if !ok {
+			klog.V(4).Infof("Xid error on unknown GPU: bus=%s xid=%d", m[1], xid)
+			continue
+		}
+		events = append(events, hypervisor.XIDEvent{GPUID: gpuID, XID: xid, Message: line})
+	}
+	return events
+}
+
+// nvidiaGPUsByBus maps PCI bus locations to GPU UUIDs from the driver's proc
+// files. It is empty without the NVIDIA driver.
+func nvidiaGPUsByBus(dir string) map[string]string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "information"))
+	gpus := make(map[string]string, len(files))
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		var uuid string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if key, value, ok := strings.Cut(scanner.Text(), ":"); ok && strings.TrimSpace(key) == "GPU UUID" {
+				uuid = strings.TrimSpace(value)
+				break
+			}
+		}
+		_ = f.Close()
+		if uuid != "" {
+			gpus[normalizeBusID(filepath.Base(filepath.Dir(path)))] = uuid
+		}
+	}
+	return gpus
+}
+
+// normalizeBusID reduces a PCI location such as 0000:3B:00.0 to the
+// domain:bus:device form used in Xid messages
+func normalizeBusID(bus string) string {
+	bus = strings.ToLower(bus)
+	if i := strings.LastIndexByte(bus, '.'); i > 0 {
+		bus = bus[:i]
+	}
+	return bus
+}
+
+// workerHealth converts the reconciler's probe results for the status report
+func (a *Agent) workerHealth(workerID string) *api.WorkerHealth {
+	if a.reconciler == nil {
+		return nil
+	}
+	health, ok := a.reconciler.WorkerHealth(workerID)
+	if !ok {
+		return nil
+	}
+	probes := make([]api.WorkerProbeResult, len(health.Probes))
+	for i, p := range health.Probes {
+		probes[i] = api.WorkerProbeResult{
+			Probe:     p.Probe,
+			OK:        p.OK,
+			Error:     p.Error,
+			LatencyMs: p.Latency.Milliseconds(),
+		}
+	}
+	return &api.WorkerHealth{
+		Status:              health.Status,
+		ConsecutiveFailures: health.ConsecutiveFailures,
+		Probes:              probes,
+		XIDs:                health.XIDs,
+		Restarts:            health.Restarts,
+		RestartsExhausted:   health.RestartsExhausted,
+		CheckedAt:           health.CheckedAt,
+	}
+}
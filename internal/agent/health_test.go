@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/hypervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNvidiaGPUsByBus(t *testing.T) {
+	dir := t.TempDir()
+	write := func(bus, info string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, bus), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, bus, "information"), []byte(info), 0644))
+	}
+	write("0000:3b:00.0", "Model: \t\t NVIDIA A100-SXM4-80GB\nIRQ:   \t\t 150\nGPU UUID: \t GPU-aaaa\nBus Location: \t 0000:3b:00.0\n")
+	write("0000:AF:00.0", "Model: \t\t NVIDIA A100-SXM4-80GB\nGPU UUID: \t GPU-bbbb\n")
+	write("0000:d8:00.0", "Model: \t\t unknown\n")
+
+	assert.Equal(t, map[string]string{
+		"0000:3b:00": "GPU-aaaa",
+		"0000:af:00": "GPU-bbbb",
+	}, nvidiaGPUsByBus(dir))
+	assert.Empty(t, nvidiaGPUsByBus(filepath.Join(dir, "missing")))
+}
+
+func TestParseXIDEvents(t *testing.T) {
+	gpus := map[string]string{"0000:3b:00": "GPU-aaaa"}
+	events := parseXIDEvents([]string{
+		"NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, name=worker, GPU has fallen off the bus.",
+		"NVRM: Xid (PCI:0000:AF:00): 48, pid=1234, name=worker, An uncorrectable double bit error",
+		"worker[1234]: segfault at 0 ip 0000 sp 0000 error 4",
+		"NVRM: Xid (PCI:0000:3B:00.0): 13, Graphics Exception",
+	}, gpus)
+
+	require.Len(t, events, 2, "Xids on unknown buses are dropped")
+	assert.Equal(t, hypervisor.XIDEvent{
+		GPUID:   "GPU-aaaa",
+		XID:     79,
+		Message: "NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, name=worker, GPU has fallen off the bus.",
+	}, events[0])
+	assert.Equal(t, "GPU-aaaa", events[1].GPUID)
+	assert.Equal(t, 13, events[1].XID)
+}
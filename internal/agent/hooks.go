@@ -0,0 +1,392 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"k8s.io/klog/v2"
+)
+
+// Lifecycle events site-specific hook scripts can subscribe to
+const (
+	// HookPreWorkerStart runs before a worker process is started; the start
+	// waits for it, so it can prepare the host, e.g. set up a cgroup
+	HookPreWorkerStart = "pre-worker-start"
+	// HookPostWorkerStop runs after a worker process was stopped
+	HookPostWorkerStop = "post-worker-stop"
+	// HookGPUChange runs when GPUs appear, disappear or change, e.g. after a
+	// driver upgrade
+	HookGPUChange = "on-gpu-change"
+	// HookLicenseRenewal runs when the platform issued a new license
+	HookLicenseRenewal = "on-license-renewal"
+)
+
+const (
+	// DefaultHookTimeout bounds a single hook script
+	DefaultHookTimeout = 30 * time.Second
+
+	// hookQueueSize bounds hooks waiting to run; events beyond it are dropped
+	hookQueueSize = 64
+	// hookOutputLimit bounds the script output kept for the agent log
+	hookOutputLimit = 16 * 1024
+	// hookWaitDelay bounds the wait for a killed script's output to close,
+	// which background children of the script may hold open
+	hookWaitDelay = 5 * time.Second
+)
+
+// HookPayload is the JSON document hook scripts receive on stdin. Only the
+// section of the event is set: Worker for worker events, GPUs for
+// on-gpu-change and License for on-license-renewal.
+type HookPayload struct {
+	Event     string       `json:"event"`
+	Timestamp time.Time    `json:"timestamp"`
+	AgentID   string       `json:"agent_id"`
+	Hostname  string       `json:"hostname"`
+	Worker    *HookWorker  `json:"worker,omitempty"`
+	GPUs      []HookGPU    `json:"gpus,omitempty"`
+	License   *HookLicense `json:"license,omitempty"`
+}
+
+// HookWorker describes the worker a worker event is about
+type HookWorker struct {
+	WorkerID       string   `json:"worker_id"`
+	GPUIDs         []string `json:"gpu_ids,omitempty"`
+	ListenPort     int      `json:"listen_port,omitempty"`
+	VRAMMb         int64    `json:"vram_mb,omitempty"`
+	ComputePercent int      `json:"compute_percent,omitempty"`
+}
+
+// HookGPU describes a GPU that changed; Change is added, removed or changed
+type HookGPU struct {
+	GPUID         string `json:"gpu_id"`
+	Change        string `json:"change"`
+	Index         int    `json:"index"`
+	Vendor        string `json:"vendor,omitempty"`
+	Model         string `json:"model,omitempty"`
+	VRAMMb        int64  `json:"vram_mb,omitempty"`
+	DriverVersion string `json:"driver_version,omitempty"`
+	CUDAVersion   string `json:"cuda_version,omitempty"`
+}
+
+// HookLicense describes a renewed license. The license itself is not passed
+// to scripts.
+type HookLicense struct {
+	// ExpiresAt is the new expiry in Unix milliseconds, when known
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+	// PreviousExpiresAt is the expiry of the replaced license, when known
+	PreviousExpiresAt int64 `json:"previous_expires_at,omitempty"`
+}
+
+type hookJob struct {
+	payload *HookPayload
+	done    chan struct{}
+}
+
+// hookRunner executes the scripts of an event from the hooks directory: the
+// executable file named after the event and then every executable in
+// <event>.d in lexical order. Scripts get the payload as JSON on stdin and
+// GGO_HOOK_EVENT (plus GGO_WORKER_ID for worker events) in their environment.
+//
+// Hooks run one at a time in event order, so a worker's post-worker-stop
+// always finishes before the pre-worker-start of its restart. A failing or
+// timed out script is logged and never stops the agent.
+type hookRunner struct {
+	dir     string
+	timeout time.Duration
+	queue   chan hookJob
+}
+
+// newHookRunner starts a runner for the scripts in dir that stops with ctx
+func newHookRunner(ctx context.Context, dir string, timeout time.Duration) *hookRunner {
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	r := &hookRunner{dir: dir, timeout: timeout, queue: make(chan hookJob, hookQueueSize)}
+	go r.loop(ctx)
+	return r
+}
+
+func (r *hookRunner) loop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-r.queue:
+			r.runScripts(ctx, job.payload)
+			if job.done != nil {
+				close(job.done)
+			}
+		}
+	}
+}
+
+// Run runs the event's scripts and waits until they finished
+func (r *hookRunner) Run(ctx context.Context, payload *HookPayload) {
+	if r == nil || !r.hasScripts(payload.Event) {
+		return
+	}
+	job := hookJob{payload: payload, done: make(chan struct{})}
+	select {
+	case r.queue <- job:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+	}
+}
+
+// Fire queues the event's scripts without waiting for them
+func (r *hookRunner) Fire(payload *HookPayload) {
+	if r == nil || !r.hasScripts(payload.Event) {
+		return
+	}
+	select {
+	case r.queue <- hookJob{payload: payload}:
+	default:
+		klog.Warningf("Hook queue full, dropping event: event=%s", payload.Event)
+	}
+}
+
+func (r *hookRunner) hasScripts(event string) bool {
+	return len(hookScripts(r.dir, event)) > 0
+}
+
+func (r *hookRunner) runScripts(ctx context.Context, payload *HookPayload) {
+	input, err := json.Marshal(payload)
+	if err != nil {
+		klog.Errorf("Failed to encode hook payload: event=%s error=%v", payload.Event, err)
+		return
+	}
+	env := []string{"GGO_HOOK_EVENT=" + payload.Event}
+	if payload.Worker != nil {
+		env = append(env, "GGO_WORKER_ID="+payload.Worker.WorkerID)
+	}
+
+	for _, script := range hookScripts(r.dir, payload.Event) {
+		if ctx.Err() != nil {
+			return
+		}
+		start := time.Now()
+		output, err := runHookScript(ctx, script, input, env, r.timeout)
+		if err != nil {
+			klog.Warningf("Hook failed: event=%s script=%s duration=%s error=%v output=%q",
+				payload.Event, script, time.Since(start).Round(time.Millisecond), err, output)
+			continue
+		}
+		klog.V(2).Infof("Hook ran: event=%s script=%s duration=%s output=%q",
+			payload.Event, script, time.Since(start).Round(time.Millisecond), output)
+	}
+}
+
+// runHookScript runs one script with input on stdin and returns its combined
+// output, truncated to hookOutputLimit
+func runHookScript(ctx context.Context, script string, input []byte, env []string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := hookCommand(ctx, script)
+	cmd.Dir = filepath.Dir(script)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(input)
+	var output limitedBuffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = hookWaitDelay
+	setHookProcAttr(cmd)
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	return strings.TrimSpace(output.String()), err
+}
+
+// hookCommand builds the command for a script. Windows cannot execute
+// scripts directly, so PowerShell and batch files go through their shell.
+func hookCommand(ctx context.Context, script string) *exec.Cmd {
+	if platform.IsWindows() {
+		switch strings.ToLower(filepath.Ext(script)) {
+		case ".ps1":
+			return exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", script)
+		case ".bat", ".cmd":
+			return exec.CommandContext(ctx, "cmd.exe", "/C", script)
+		}
+	}
+	return exec.CommandContext(ctx, script)
+}
+
+// hookScripts lists the scripts of an event in the order they run
+func hookScripts(dir, event string) []string {
+	if dir == "" {
+		return nil
+	}
+	var scripts []string
+	if isHookScript(filepath.Join(dir, event)) {
+		scripts = append(scripts, filepath.Join(dir, event))
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, event+".d"))
+	if err != nil {
+		return scripts
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if path := filepath.Join(dir, event+".d", name); isHookScript(path) {
+			scripts = append(scripts, path)
+		}
+	}
+	return scripts
+}
+
+// isHookScript reports whether path is a regular file the agent can run:
+// executable on Unix, an executable or script extension on Windows
+func isHookScript(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if platform.IsWindows() {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".exe", ".bat", ".cmd", ".ps1":
+			return true
+		}
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// limitedBuffer keeps the first hookOutputLimit bytes written to it
+type limitedBuffer struct {
+	buf bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := hookOutputLimit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// EnableHooks runs site-specific scripts from dir on lifecycle events (see
+// the Hook* constants), each bounded by timeout. A missing directory means
+// no hooks. Must be called before Start.
+func (a *Agent) EnableHooks(dir string, timeout time.Duration) {
+	a.hooks = newHookRunner(a.ctx, dir, timeout)
+}
+
+// newHookPayload returns the payload of an event with the agent fields set
+func (a *Agent) newHookPayload(event string) *HookPayload {
+	return &HookPayload{
+		Event:     event,
+		Timestamp: time.Now().UTC(),
+		AgentID:   a.agentID,
+		Hostname:  a.hostname,
+	}
+}
+
+// fireWorkerHook runs a worker event's hooks, waiting for them when wait is
+// set. Worker details come from the last config pulled from the platform.
+func (a *Agent) fireWorkerHook(event, workerID string, wait bool) {
+	if a.hooks == nil {
+		return
+	}
+	payload := a.newHookPayload(event)
+	payload.Worker = &HookWorker{WorkerID: workerID}
+	if workers, err := a.config.LoadWorkers(); err == nil {
+		if i := slices.IndexFunc(workers, func(w config.WorkerConfig) bool { return w.WorkerID == workerID }); i >= 0 {
+			w := workers[i]
+			payload.Worker = &HookWorker{
+				WorkerID:       w.WorkerID,
+				GPUIDs:         w.GPUIDs,
+				ListenPort:     w.ListenPort,
+				VRAMMb:         w.VRAMMb,
+				ComputePercent: w.ComputePercent,
+			}
+		}
+	}
+	if wait {
+		a.hooks.Run(a.ctx, payload)
+		return
+	}
+	a.hooks.Fire(payload)
+}
+
+// fireGPUChangeHook reports GPUs that changed between two detections
+func (a *Agent) fireGPUChangeHook(gpus []HookGPU) {
+	if a.hooks == nil || len(gpus) == 0 {
+		return
+	}
+	payload := a.newHookPayload(HookGPUChange)
+	payload.GPUs = gpus
+	a.hooks.Fire(payload)
+}
+
+// diffGPUs returns the GPUs added, changed or removed between two
+// detections, sorted by ID
+func diffGPUs(prev, current map[string]*gpuSnapshot) []HookGPU {
+	var gpus []HookGPU
+	for id, gpu := range current {
+		old, existed := prev[id]
+		switch {
+		case !existed:
+			gpus = append(gpus, hookGPU(gpu, config.GPUAdded))
+		case *old != *gpu:
+			gpus = append(gpus, hookGPU(gpu, config.GPUChanged))
+		}
+	}
+	for id, gpu := range prev {
+		if _, exists := current[id]; !exists {
+			gpus = append(gpus, hookGPU(gpu, config.GPURemoved))
+		}
+	}
+	slices.SortFunc(gpus, func(x, y HookGPU) int { return strings.Compare(x.GPUID, y.GPUID) })
+	return gpus
+}
+
+func hookGPU(gpu *gpuSnapshot, change string) HookGPU {
+	return HookGPU{
+		GPUID:         gpu.GPUID,
+		Change:        change,
+		Index:         gpu.GPUIndex,
+		Vendor:        gpu.Vendor,
+		Model:         gpu.Model,
+		VRAMMb:        gpu.VRAMMb,
+		DriverVersion: gpu.DriverVersion,
+		CUDAVersion:   gpu.CUDAVersion,
+	}
+}
+
+// fireLicenseRenewalHook reports a license replaced by a different one
+func (a *Agent) fireLicenseRenewalHook(previousPlain, plain string) {
+	if a.hooks == nil || previousPlain == "" || plain == previousPlain {
+		return
+	}
+	payload := a.newHookPayload(HookLicenseRenewal)
+	payload.License = &HookLicense{
+		ExpiresAt:         parseLicenseExpiration(plain),
+		PreviousExpiresAt: parseLicenseExpiration(previousPlain),
+	}
+	a.hooks.Fire(payload)
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package agent
+
+import "os/exec"
+
+// setHookProcAttr keeps the default of killing just the hook process
+func setHookProcAttr(_ *exec.Cmd) {}
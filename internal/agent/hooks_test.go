@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHookScript(t *testing.T, path, body string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755))
+}
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts in tests are shell scripts")
+	}
+}
+
+func TestHookScripts(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writeHookScript(t, filepath.Join(dir, HookPostWorkerStop), "true")
+	writeHookScript(t, filepath.Join(dir, HookPostWorkerStop+".d", "20-second"), "true")
+	writeHookScript(t, filepath.Join(dir, HookPostWorkerStop+".d", "10-first"), "true")
+	writeHookScript(t, filepath.Join(dir, HookPostWorkerStop+".d", ".hidden"), "true")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, HookPostWorkerStop+".d", "README"), []byte("docs"), 0644))
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, HookPostWorkerStop),
+		filepath.Join(dir, HookPostWorkerStop+".d", "10-first"),
+		filepath.Join(dir, HookPostWorkerStop+".d", "20-second"),
+	}, hookScripts(dir, HookPostWorkerStop))
+
+	assert.Empty(t, hookScripts(dir, HookGPUChange))
+	assert.Empty(t, hookScripts("", HookGPUChange))
+	assert.Empty(t, hookScripts(filepath.Join(dir, "missing"), HookGPUChange))
+}
+
+func TestRunHookScript(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hook")
+	writeHookScript(t, script, `echo "$GGO_HOOK_EVENT $GGO_WORKER_ID"; cat`)
+
+	output, err := runHookScript(context.Background(), script, []byte(`{"event":"x"}`),
+		[]string{"GGO_HOOK_EVENT=x", "GGO_WORKER_ID=w1"}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "x w1\n{\"event\":\"x\"}", output)
+
+	writeHookScript(t, script, "echo failing >&2; exit 3")
+	output, err = runHookScript(context.Background(), script, nil, nil, time.Minute)
+	assert.Error(t, err)
+	assert.Equal(t, "failing", output)
+
+	writeHookScript(t, script, "sleep 10")
+	start := time.Now()
+	_, err = runHookScript(context.Background(), script, nil, nil, 100*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestHookRunner_RunsInOrder(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	log := filepath.Join(dir, "events.log")
+	for _, event := range []string{HookPostWorkerStop, HookPreWorkerStart} {
+		writeHookScript(t, filepath.Join(dir, event), `cat >> `+log+`; echo >> `+log)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := newHookRunner(ctx, dir, time.Minute)
+
+	r.Fire(&HookPayload{Event: HookPostWorkerStop, Worker: &HookWorker{WorkerID: "w1"}})
+	// Run waits for the earlier event too, since hooks run one at a time
+	r.Run(ctx, &HookPayload{Event: HookPreWorkerStart, Worker: &HookWorker{WorkerID: "w1"}})
+	// Events without scripts are skipped
+	r.Run(ctx, &HookPayload{Event: HookGPUChange})
+
+	data, err := os.ReadFile(log)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var first, second HookPayload
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, HookPostWorkerStop, first.Event)
+	assert.Equal(t, HookPreWorkerStart, second.Event)
+	assert.Equal(t, "w1", second.Worker.WorkerID)
+}
+
+func TestHookRunner_Nil(t *testing.T) {
+	var r *hookRunner
+	r.Fire(&HookPayload{Event: HookGPUChange})
+	r.Run(context.Background(), &HookPayload{Event: HookGPUChange})
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	var b limitedBuffer
+	n, err := b.Write(make([]byte, hookOutputLimit-1))
+	require.NoError(t, err)
+	assert.Equal(t, hookOutputLimit-1, n)
+	n, err = b.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Len(t, b.String(), hookOutputLimit)
+}
+
+func TestAgentHooks(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	out := filepath.Join(dir, "payloads")
+	for _, event := range []string{HookPreWorkerStart, HookGPUChange, HookLicenseRenewal} {
+		writeHookScript(t, filepath.Join(dir, event), `cat > `+out+`.$GGO_HOOK_EVENT`)
+	}
+
+	mgr := config.NewManager(t.TempDir(), t.TempDir())
+	require.NoError(t, mgr.SaveWorkers([]config.WorkerConfig{{WorkerID: "w1", GPUIDs: []string{"gpu-0"}, ListenPort: 9001}}))
+	a := NewAgent(nil, mgr)
+	defer a.cancel()
+	a.agentID = "agent-1"
+	a.EnableHooks(dir, time.Minute)
+
+	readPayload := func(event string) HookPayload {
+		t.Helper()
+		var payload HookPayload
+		require.Eventually(t, func() bool {
+			data, err := os.ReadFile(out + "." + event)
+			return err == nil && json.Unmarshal(data, &payload) == nil
+		}, 5*time.Second, 10*time.Millisecond)
+		return payload
+	}
+
+	a.fireWorkerHook(HookPreWorkerStart, "w1", true)
+	payload := readPayload(HookPreWorkerStart)
+	assert.Equal(t, "agent-1", payload.AgentID)
+	require.NotNil(t, payload.Worker)
+	assert.Equal(t, []string{"gpu-0"}, payload.Worker.GPUIDs)
+	assert.Equal(t, 9001, payload.Worker.ListenPort)
+
+	a.fireGPUChangeHook(diffGPUs(
+		map[string]*gpuSnapshot{
+			"gpu-0": {GPUID: "gpu-0", DriverVersion: "550"},
+			"gpu-1": {GPUID: "gpu-1", GPUIndex: 1},
+		},
+		map[string]*gpuSnapshot{
+			"gpu-0": {GPUID: "gpu-0", DriverVersion: "560"},
+			"gpu-2": {GPUID: "gpu-2", GPUIndex: 2},
+		}))
+	payload = readPayload(HookGPUChange)
+	require.Len(t, payload.GPUs, 3)
+	assert.Equal(t, "changed", payload.GPUs[0].Change)
+	assert.Equal(t, "560", payload.GPUs[0].DriverVersion)
+	assert.Equal(t, "removed", payload.GPUs[1].Change)
+	assert.Equal(t, "added", payload.GPUs[2].Change)
+
+	// The first license and an unchanged one are not renewals
+	a.fireLicenseRenewalHook("", "gpu|pro|1000")
+	a.fireLicenseRenewalHook("gpu|pro|1000", "gpu|pro|1000")
+	a.fireLicenseRenewalHook("gpu|pro|1000", "gpu|pro|2000")
+	payload = readPayload(HookLicenseRenewal)
+	require.NotNil(t, payload.License)
+	assert.Equal(t, int64(2000), payload.License.ExpiresAt)
+	assert.Equal(t, int64(1000), payload.License.PreviousExpiresAt)
+}
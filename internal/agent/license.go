@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+// licenseRequestFile holds the last exported offline renewal request; only
+// a bundle answering it can be imported
+const licenseRequestFile = "license_request.json"
+
+// LicenseStatus returns api.LicenseStatusExpiring within licenseExpiryWarning
+// of the expiration, api.LicenseStatusExpired after it, and "" otherwise or
+// when the expiration is unknown
+func LicenseStatus(expiration *int64, now time.Time) string {
+	if expiration == nil {
+		return ""
+	}
+	left := time.UnixMilli(*expiration).Sub(now)
+	switch {
+	case left <= 0:
+		return api.LicenseStatusExpired
+	case left <= licenseExpiryWarning:
+		return api.LicenseStatusExpiring
+	}
+	return ""
+}
+
+// LicenseExpiration returns the expiration of a license in Unix milliseconds,
+// or nil when the license carries none
+func LicenseExpiration(license api.License) *int64 {
+	if exp := parseLicenseExpiration(license.Plain); exp > 0 {
+		return &exp
+	}
+	return nil
+}
+
+// NewLicenseRenewalRequest creates a signed offline license renewal request
+// for the registered agent and keeps it as the pending request, replacing
+// any earlier one
+func NewLicenseRenewalRequest(configMgr *config.Manager, now time.Time) (*api.LicenseRenewalRequest, error) {
+	cfg, err := configMgr.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg == nil || cfg.AgentID == "" {
+		return nil, fmt.Errorf("agent not registered")
+	}
+	gpus, err := configMgr.LoadGPUs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GPUs: %w", err)
+	}
+	hostname, _ := os.Hostname()
+
+	req := &api.LicenseRenewalRequest{
+		AgentID:           cfg.AgentID,
+		Hostname:          hostname,
+		GPUIDs:            make([]string, 0, len(gpus)),
+		Nonce:             rand.Text(),
+		CreatedAt:         now.UTC(),
+		LicenseExpiration: LicenseExpiration(cfg.License),
+	}
+	for _, g := range gpus {
+		req.GPUIDs = append(req.GPUIDs, g.GPUID)
+	}
+	req.Signature = signLicenseRenewalRequest(req, cfg.AgentSecret)
+
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.AtomicWriteFile(filepath.Join(configMgr.ConfigDir(), licenseRequestFile), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to save pending license request: %w", err)
+	}
+	return req, nil
+}
+
+// ImportLicenseBundle validates an offline renewal bundle against the pending
+// request and installs its license. The bundle must be signed with the agent
+// secret, answer the pending request and extend the current license.
+func ImportLicenseBundle(configMgr *config.Manager, bundle *api.LicenseRenewalBundle, now time.Time) (time.Time, error) {
+	cfg, err := configMgr.LoadConfig()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg == nil || cfg.AgentID == "" {
+		return time.Time{}, fmt.Errorf("agent not registered")
+	}
+	if bundle.AgentID != cfg.AgentID {
+		return time.Time{}, fmt.Errorf("license bundle is for agent %s, this agent is %s", bundle.AgentID, cfg.AgentID)
+	}
+
+	pendingPath := filepath.Join(configMgr.ConfigDir(), licenseRequestFile)
+	data, err := os.ReadFile(pendingPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, fmt.Errorf("no pending license request; run 'ggo agent license export-request' first")
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read pending license request: %w", err)
+	}
+	var pending api.LicenseRenewalRequest
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse pending license request: %w", err)
+	}
+	if bundle.Nonce != pending.Nonce {
+		return time.Time{}, fmt.Errorf("license bundle does not answer the pending request; export a new request and renew again")
+	}
+	if !hmac.Equal([]byte(bundle.Signature), []byte(signLicenseRenewalBundle(bundle, cfg.AgentSecret))) {
+		return time.Time{}, fmt.Errorf("license bundle signature is invalid")
+	}
+
+	if bundle.License.Plain == "" || bundle.License.Encrypted == "" {
+		return time.Time{}, fmt.Errorf("license bundle carries no license")
+	}
+	exp := LicenseExpiration(bundle.License)
+	if exp == nil {
+		return time.Time{}, fmt.Errorf("license bundle carries no expiration")
+	}
+	expiresAt := time.UnixMilli(*exp)
+	if !expiresAt.After(now) {
+		return time.Time{}, fmt.Errorf("license in bundle expired at %s", expiresAt.Format(time.RFC3339))
+	}
+	if current := LicenseExpiration(cfg.License); current != nil && *exp <= *current {
+		return time.Time{}, fmt.Errorf("license in bundle does not extend the current license, which expires at %s",
+			time.UnixMilli(*current).Format(time.RFC3339))
+	}
+
+	if err := configMgr.UpdateConfigVersion(cfg.ConfigVersion, bundle.License); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save license: %w", err)
+	}
+	if err := os.Remove(pendingPath); err != nil {
+		klog.Warningf("Failed to remove pending license request: path=%s error=%v", pendingPath, err)
+	}
+	klog.Infof("License installed from offline renewal bundle: agent_id=%s expires_at=%s", cfg.AgentID, expiresAt.Format(time.RFC3339))
+	return expiresAt, nil
+}
+
+// signLicenseRenewalRequest signs the fields of a renewal request
+func signLicenseRenewalRequest(req *api.LicenseRenewalRequest, secret string) string {
+	expiration := ""
+	if req.LicenseExpiration != nil {
+		expiration = strconv.FormatInt(*req.LicenseExpiration, 10)
+	}
+	return licenseHMAC(secret, req.AgentID, req.Hostname, strings.Join(req.GPUIDs, ","), req.Nonce,
+		req.CreatedAt.UTC().Format(time.RFC3339Nano), expiration)
+}
+
+// signLicenseRenewalBundle signs the fields of a renewal bundle
+func signLicenseRenewalBundle(bundle *api.LicenseRenewalBundle, secret string) string {
+	return licenseHMAC(secret, bundle.AgentID, bundle.Nonce, bundle.IssuedAt.UTC().Format(time.RFC3339Nano),
+		bundle.License.Plain, bundle.License.Encrypted)
+}
+
+// licenseHMAC returns the hex HMAC-SHA256 of the newline-joined fields
+func licenseHMAC(secret string, fields ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicenseStatus(t *testing.T) {
+	now := time.Now()
+	ms := func(d time.Duration) *int64 {
+		v := now.Add(d).UnixMilli()
+		return &v
+	}
+	assert.Empty(t, LicenseStatus(nil, now))
+	assert.Empty(t, LicenseStatus(ms(30*24*time.Hour), now))
+	assert.Equal(t, api.LicenseStatusExpiring, LicenseStatus(ms(3*24*time.Hour), now))
+	assert.Equal(t, api.LicenseStatusExpired, LicenseStatus(ms(-time.Minute), now))
+}
+
+func TestOfflineLicenseRenewal(t *testing.T) {
+	now := time.Now()
+	license := func(expiresIn time.Duration) api.License {
+		return api.License{Plain: fmt.Sprintf("gpu-0|pro|%d", now.Add(expiresIn).UnixMilli()), Encrypted: "sig"}
+	}
+	newSetup := func(t *testing.T) *config.Manager {
+		tmpDir := t.TempDir()
+		configMgr := config.NewManager(tmpDir, tmpDir)
+		require.NoError(t, configMgr.SaveConfig(&config.Config{
+			ConfigVersion: 3,
+			AgentID:       "agent_test123",
+			AgentSecret:   "secret",
+			License:       license(2 * 24 * time.Hour),
+		}))
+		require.NoError(t, configMgr.SaveGPUs([]config.GPUConfig{{GPUID: "gpu-0"}}))
+		return configMgr
+	}
+	// fulfill answers a request the way the platform does
+	fulfill := func(req *api.LicenseRenewalRequest, l api.License) *api.LicenseRenewalBundle {
+		bundle := &api.LicenseRenewalBundle{AgentID: req.AgentID, Nonce: req.Nonce, IssuedAt: now, License: l}
+		bundle.Signature = signLicenseRenewalBundle(bundle, "secret")
+		return bundle
+	}
+
+	t.Run("request is signed with the agent secret", func(t *testing.T) {
+		req, err := NewLicenseRenewalRequest(newSetup(t), now)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gpu-0"}, req.GPUIDs)
+		assert.NotEmpty(t, req.Nonce)
+		require.NotNil(t, req.LicenseExpiration)
+		assert.Equal(t, signLicenseRenewalRequest(req, "secret"), req.Signature)
+		assert.NotEqual(t, signLicenseRenewalRequest(req, "other"), req.Signature)
+	})
+
+	t.Run("valid bundle is installed once", func(t *testing.T) {
+		configMgr := newSetup(t)
+		req, err := NewLicenseRenewalRequest(configMgr, now)
+		require.NoError(t, err)
+		bundle := fulfill(req, license(90*24*time.Hour))
+
+		expiresAt, err := ImportLicenseBundle(configMgr, bundle, now)
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(90*24*time.Hour).UnixMilli(), expiresAt.UnixMilli())
+		cfg, err := configMgr.LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, bundle.License, cfg.License)
+		assert.Equal(t, 3, cfg.ConfigVersion)
+
+		_, err = ImportLicenseBundle(configMgr, bundle, now)
+		assert.ErrorContains(t, err, "no pending license request")
+	})
+
+	t.Run("invalid bundles are rejected", func(t *testing.T) {
+		configMgr := newSetup(t)
+		req, err := NewLicenseRenewalRequest(configMgr, now)
+		require.NoError(t, err)
+
+		tampered := fulfill(req, license(90*24*time.Hour))
+		tampered.License.Plain = fmt.Sprintf("gpu-0|pro|%d", now.Add(900*24*time.Hour).UnixMilli())
+		_, err = ImportLicenseBundle(configMgr, tampered, now)
+		assert.ErrorContains(t, err, "signature")
+
+		stale := fulfill(&api.LicenseRenewalRequest{AgentID: req.AgentID, Nonce: "old"}, license(90*24*time.Hour))
+		_, err = ImportLicenseBundle(configMgr, stale, now)
+		assert.ErrorContains(t, err, "pending request")
+
+		other := fulfill(&api.LicenseRenewalRequest{AgentID: "agent_other", Nonce: req.Nonce}, license(90*24*time.Hour))
+		_, err = ImportLicenseBundle(configMgr, other, now)
+		assert.ErrorContains(t, err, "agent_other")
+
+		_, err = ImportLicenseBundle(configMgr, fulfill(req, license(24*time.Hour)), now)
+		assert.ErrorContains(t, err, "does not extend")
+
+		cfg, err := configMgr.LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, license(2*24*time.Hour), cfg.License, "the current license is kept")
+	})
+}
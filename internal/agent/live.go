@@ -0,0 +1,326 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// liveStatusInterval is how often the live status snapshot is rewritten.
+	// Sampling is local only (hypervisor + files), so it can be much shorter
+	// than the server report interval.
+	liveStatusInterval = 2 * time.Second
+	liveStatusFileName = "agent-live.json"
+)
+
+// Heartbeat modes reported in the live status
+const (
+	// HeartbeatModeGRPC means config changes are pushed over the gRPC
+	// transport's subscription
+	HeartbeatModeGRPC = "grpc"
+	// HeartbeatModeSSE means config changes are pushed over the SSE connection
+	HeartbeatModeSSE = "sse"
+	// HeartbeatModeLongPoll means SSE failed repeatedly and config changes
+	// are long-polled from the platform
+	HeartbeatModeLongPoll = "long-poll"
+	// HeartbeatModePolling means neither SSE nor long polling works and config
+	// changes are only picked up by the periodic status report
+	HeartbeatModePolling = "polling"
+)
+
+// LiveGPU is one GPU in the live status snapshot
+type LiveGPU struct {
+	GPUID       string  `json:"gpu_id"`
+	GPUIndex    int     `json:"gpu_index"`
+	Vendor      string  `json:"vendor"`
+	Model       string  `json:"model"`
+	Utilization float64 `json:"utilization"`
+	VRAMUsedMb  int64   `json:"vram_used_mb"`
+	VRAMTotalMb int64   `json:"vram_total_mb"`
+	Temperature float64 `json:"temperature,omitempty"`
+	PowerW      float64 `json:"power_w,omitempty"`
+
+	DriverVersion string `json:"driver_version,omitempty"`
+	CUDAVersion   string `json:"cuda_version,omitempty"`
+	// ECCMode is the ECC mode the driver reports, such as "enabled"; it is
+	// shown locally only and not reported to the platform
+	ECCMode string `json:"ecc_mode,omitempty"`
+	// Health lists the api.GPUHealth* flags of the GPU; empty when healthy
+	Health []string `json:"health,omitempty"`
+	// Workers are the IDs of the workers the GPU is allocated to
+	Workers []string `json:"workers,omitempty"`
+}
+
+// LiveWorker is one worker in the live status snapshot
+type LiveWorker struct {
+	WorkerID string   `json:"worker_id"`
+	Status   string   `json:"status"`
+	PID      int      `json:"pid,omitempty"`
+	Restarts int      `json:"restarts,omitempty"`
+	GPUIDs   []string `json:"gpu_ids"`
+	// Warm is set while the worker is kept running disabled, refusing sessions
+	Warm bool `json:"warm,omitempty"`
+	// Connections are the worker's client sessions, with the proxy's
+	// accounting when the agent runs the connection proxy
+	Connections []api.ConnectionInfo `json:"connections,omitempty"`
+}
+
+// LiveStatus is the snapshot a running agent writes to its state directory so
+// that `ggo agent status --watch` can show what the hypervisor sees without
+// attaching to the agent process
+type LiveStatus struct {
+	PID           int       `json:"pid"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	LastReportAt  time.Time `json:"last_report_at"`
+	HeartbeatMode string    `json:"heartbeat_mode"`
+	// Transport details the heartbeat mode
+	Transport *TransportStatus `json:"transport,omitempty"`
+	GPUs      []LiveGPU        `json:"gpus"`
+	Workers   []LiveWorker     `json:"workers"`
+	// Disk is the disk usage of the last disk check, nil before the first
+	Disk *api.AgentDiskUsage `json:"disk,omitempty"`
+	// OfflineSince is when the platform became unreachable, nil while it is
+	// reachable
+	OfflineSince *time.Time `json:"offline_since,omitempty"`
+	// QueuedReports counts the status reports waiting for delivery
+	QueuedReports int `json:"queued_reports,omitempty"`
+}
+
+// LiveStatusPath returns the path of the live status snapshot
+func LiveStatusPath(paths *platform.Paths) string {
+	return filepath.Join(paths.StateDir(), liveStatusFileName)
+}
+
+// ReadLiveStatus reads the live status snapshot; returns nil if the agent has
+// not written one
+func ReadLiveStatus(paths *platform.Paths) (*LiveStatus, error) {
+	return utils.LoadJSON[LiveStatus](LiveStatusPath(paths))
+}
+
+// LiveWorkerSessions returns the client sessions of a worker from the live
+// status snapshot. ok is false unless the agent on this host runs the worker
+// and its snapshot is recent.
+func LiveWorkerSessions(paths *platform.Paths, workerID string, now time.Time) (sessions []api.ConnectionInfo, ok bool) {
+	live, err := ReadLiveStatus(paths)
+	if err != nil || live == nil || now.Sub(live.UpdatedAt) > 5*liveStatusInterval {
+		return nil, false
+	}
+	for _, w := range live.Workers {
+		if w.WorkerID == workerID {
+			return w.Connections, true
+		}
+	}
+	return nil, false
+}
+
+// LiveWorkerLoad returns the metrics of a worker's GPUs and its client
+// sessions from the live status snapshot. ok is false unless the agent on
+// this host runs the worker and its snapshot is recent.
+func LiveWorkerLoad(paths *platform.Paths, workerID string, now time.Time) (gpus []api.GPUMetrics, sessions []api.ConnectionInfo, ok bool) {
+	live, err := ReadLiveStatus(paths)
+	if err != nil || live == nil || now.Sub(live.UpdatedAt) > 5*liveStatusInterval {
+		return nil, nil, false
+	}
+	for _, w := range live.Workers {
+		if w.WorkerID != workerID {
+			continue
+		}
+		for _, g := range live.GPUs {
+			if slices.Contains(w.GPUIDs, g.GPUID) {
+				gpus = append(gpus, api.GPUMetrics{
+					GPUID:       g.GPUID,
+					Utilization: g.Utilization,
+					VRAMUsedMb:  g.VRAMUsedMb,
+					VRAMTotalMb: g.VRAMTotalMb,
+					Temperature: g.Temperature,
+				})
+			}
+		}
+		return gpus, w.Connections, true
+	}
+	return nil, nil, false
+}
+
+// ReadConnections reads the per-worker connection files written by workers.
+// Returns workerID -> active connections; workers without connections are omitted.
+func ReadConnections(paths *platform.Paths) map[string][]api.ConnectionInfo {
+	return readConnections(paths.ConnectionsDir())
+}
+
+func readConnections(dir string) map[string][]api.ConnectionInfo {
+	result := make(map[string][]api.ConnectionInfo)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result
+	}
+	for _, entry := range entries {
+		workerID := strings.TrimSuffix(entry.Name(), ".txt")
+		if entry.IsDir() || workerID == entry.Name() || workerID == "" {
+			continue
+		}
+		conns, err := readWorkerConnectionFile(filepath.Join(dir, entry.Name()))
+		if err != nil || len(conns) == 0 {
+			continue
+		}
+		result[workerID] = conns
+	}
+	return result
+}
+
+// liveStatusLoop keeps the live status snapshot fresh while the agent runs
+func (a *Agent) liveStatusLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(liveStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		a.writeLiveStatus()
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeLiveStatus samples the hypervisor and writes the live status snapshot
+func (a *Agent) writeLiveStatus() {
+	if err := utils.SaveJSON(LiveStatusPath(a.paths), a.liveStatus(), 0644); err != nil {
+		klog.V(4).Infof("Failed to write live status: %v", err)
+	}
+}
+
+// liveStatus samples the hypervisor, worker connections and transports
+func (a *Agent) liveStatus() *LiveStatus {
+	var (
+		devices []*hvApi.DeviceInfo
+		metrics map[string]*hvApi.GPUUsageMetrics
+		workers []*hvApi.WorkerInfo
+	)
+	if a.hypervisorMgr != nil && a.hypervisorMgr.IsStarted() {
+		var err error
+		if devices, err = a.hypervisorMgr.ListDevices(); err != nil {
+			klog.V(4).Infof("Failed to list devices for live status: %v", err)
+		}
+		if metrics, err = a.hypervisorMgr.GetDeviceMetrics(); err != nil {
+			klog.V(4).Infof("Failed to collect GPU metrics for live status: %v", err)
+		}
+		workers = a.hypervisorMgr.ListWorkers()
+	}
+
+	transport := a.transport.status(time.Now())
+	if a.client != nil {
+		transport.API = a.client.AgentTransport()
+	}
+	a.mu.RLock()
+	lastReport := a.lastReportAt
+	a.mu.RUnlock()
+
+	status := buildLiveStatus(devices, metrics, workers, time.Now())
+	connections := readConnections(a.connectionsDir)
+	for i := range status.Workers {
+		w := &status.Workers[i]
+		w.Connections = connections[w.WorkerID]
+		w.Warm = a.isWarm(w.WorkerID)
+		if a.proxy != nil {
+			a.proxy.RewriteConnections(w.WorkerID, w.Connections)
+		}
+	}
+	status.PID = os.Getpid()
+	status.LastReportAt = lastReport
+	status.HeartbeatMode = transport.Mode
+	status.Transport = &transport
+	status.Disk = a.lastDiskUsage()
+	status.OfflineSince = a.offlineStatus()
+	status.QueuedReports = a.reports.len()
+	return status
+}
+
+// removeLiveStatus removes the snapshot so a stopped agent is not shown as live
+func (a *Agent) removeLiveStatus() {
+	if err := os.Remove(LiveStatusPath(a.paths)); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Failed to remove live status: error=%v", err)
+	}
+}
+
+// buildLiveStatus converts hypervisor state into a live status snapshot
+func buildLiveStatus(
+	devices []*hvApi.DeviceInfo,
+	metrics map[string]*hvApi.GPUUsageMetrics,
+	workers []*hvApi.WorkerInfo,
+	now time.Time,
+) *LiveStatus {
+	usage := make(map[string]*api.GPUMetrics, len(metrics))
+	for _, m := range ConvertMetricsToGPUMetrics(metrics) {
+		usage[strings.ToLower(m.GPUID)] = m
+	}
+	byGPU := metricsByGPU(metrics)
+	allocated := make(map[string][]string)
+	for _, w := range workers {
+		for _, id := range w.AllocatedDevices {
+			id = normalizeGPUID(id)
+			allocated[id] = append(allocated[id], w.WorkerUID)
+		}
+	}
+
+	status := &LiveStatus{
+		UpdatedAt: now,
+		GPUs:      make([]LiveGPU, 0, len(devices)),
+		Workers:   make([]LiveWorker, 0, len(workers)),
+	}
+	for i, gpu := range ConvertDevicesToGPUInfo(devices) {
+		live := LiveGPU{
+			GPUID:       gpu.GPUID,
+			GPUIndex:    gpu.GPUIndex,
+			Vendor:      gpu.Vendor,
+			Model:       gpu.Model,
+			VRAMTotalMb: gpu.VRAMMb,
+
+			DriverVersion: gpu.DriverVersion,
+			CUDAVersion:   gpu.CUDAVersion,
+			ECCMode:       devices[i].Properties["eccMode"],
+			Health:        gpuHealth(true, true, byGPU[gpu.GPUID]),
+			Workers:       allocated[gpu.GPUID],
+		}
+		if m, ok := usage[gpu.GPUID]; ok {
+			live.Utilization = m.Utilization
+			live.VRAMUsedMb = m.VRAMUsedMb
+			live.Temperature = m.Temperature
+			live.PowerW = m.PowerUsageW
+		}
+		sort.Strings(live.Workers)
+		status.GPUs = append(status.GPUs, live)
+	}
+	sort.Slice(status.GPUs, func(i, j int) bool { return status.GPUs[i].GPUIndex < status.GPUs[j].GPUIndex })
+
+	for _, w := range workers {
+		live := LiveWorker{
+			WorkerID: w.WorkerUID,
+			Status:   workerStatusStopped,
+			GPUIDs:   w.AllocatedDevices,
+		}
+		if w.WorkerRunningInfo != nil {
+			if w.WorkerRunningInfo.IsRunning {
+				live.Status = workerStatusRunning
+			}
+			live.PID = int(w.WorkerRunningInfo.PID)
+			live.Restarts = w.WorkerRunningInfo.Restarts
+		}
+		status.Workers = append(status.Workers, live)
+	}
+	sort.Slice(status.Workers, func(i, j int) bool { return status.Workers[i].WorkerID < status.Workers[j].WorkerID })
+
+	return status
+}
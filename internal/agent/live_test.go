@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLiveStatus(t *testing.T) {
+	devices := []*hvApi.DeviceInfo{
+		{UUID: "GPU-B", Index: 1, Vendor: "nvidia", Model: "RTX 4090", TotalMemoryBytes: 24 * 1024 * 1024 * 1024},
+		{
+			UUID: "GPU-A", Index: 0, Vendor: "nvidia", Model: "RTX 4090", TotalMemoryBytes: 24 * 1024 * 1024 * 1024,
+			Properties: map[string]string{"driverVersion": "550.54", "cudaVersion": "12.4", "eccMode": "enabled"},
+		},
+	}
+	metrics := map[string]*hvApi.GPUUsageMetrics{
+		"GPU-A": {DeviceUUID: "GPU-A", ComputePercentage: 42.5, MemoryBytes: 2048 * 1024 * 1024, Temperature: 93},
+	}
+	workers := []*hvApi.WorkerInfo{
+		{WorkerUID: "w2", AllocatedDevices: []string{"gpu-b"}},
+		{
+			WorkerUID:         "w1",
+			AllocatedDevices:  []string{"gpu-a"},
+			WorkerRunningInfo: &hvApi.WorkerRunningInfo{IsRunning: true, PID: 4321, Restarts: 2},
+		},
+	}
+
+	status := buildLiveStatus(devices, metrics, workers, time.Now())
+
+	require.Len(t, status.GPUs, 2)
+	assert.Equal(t, "gpu-a", status.GPUs[0].GPUID)
+	assert.InDelta(t, 42.5, status.GPUs[0].Utilization, 0.001)
+	assert.Equal(t, int64(2048), status.GPUs[0].VRAMUsedMb)
+	assert.Equal(t, int64(24576), status.GPUs[0].VRAMTotalMb)
+	assert.Equal(t, "550.54", status.GPUs[0].DriverVersion)
+	assert.Equal(t, "12.4", status.GPUs[0].CUDAVersion)
+	assert.Equal(t, "enabled", status.GPUs[0].ECCMode)
+	assert.Equal(t, []string{api.GPUHealthOverheating}, status.GPUs[0].Health)
+	assert.Equal(t, []string{"w1"}, status.GPUs[0].Workers)
+	assert.Zero(t, status.GPUs[1].Utilization)
+	assert.Empty(t, status.GPUs[1].Health)
+	assert.Equal(t, []string{"w2"}, status.GPUs[1].Workers)
+
+	require.Len(t, status.Workers, 2)
+	assert.Equal(t, LiveWorker{WorkerID: "w1", Status: workerStatusRunning, PID: 4321, Restarts: 2, GPUIDs: []string{"gpu-a"}}, status.Workers[0])
+	assert.Equal(t, workerStatusStopped, status.Workers[1].Status)
+}
+
+func TestReadConnections(t *testing.T) {
+	paths := platform.DefaultPaths().WithStateDir(t.TempDir())
+	dir := paths.ConnectionsDir()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "w1.txt"), []byte("10.0.0.2,5000,77\n\n10.0.0.3\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "w2.txt"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.log"), []byte("10.0.0.9"), 0644))
+
+	conns := ReadConnections(paths)
+	require.Len(t, conns, 1)
+	require.Len(t, conns["w1"], 2)
+	assert.Equal(t, "10.0.0.2", conns["w1"][0].ClientIP)
+	assert.Equal(t, 5000, conns["w1"][0].ClientPort)
+	assert.Equal(t, 77, conns["w1"][0].ClientPID)
+	assert.Equal(t, "10.0.0.3", conns["w1"][1].ClientIP)
+}
+
+func TestLiveWorkerLoad(t *testing.T) {
+	paths := platform.DefaultPaths().WithStateDir(t.TempDir())
+	now := time.Now()
+	_, _, ok := LiveWorkerLoad(paths, "w1", now)
+	assert.False(t, ok, "no snapshot")
+
+	require.NoError(t, utils.SaveJSON(LiveStatusPath(paths), &LiveStatus{
+		UpdatedAt: now,
+		GPUs: []LiveGPU{
+			{GPUID: "gpu-0", Utilization: 80, VRAMUsedMb: 1000, VRAMTotalMb: 24000},
+			{GPUID: "gpu-1", Utilization: 5},
+		},
+		Workers: []LiveWorker{{
+			WorkerID:    "w1",
+			GPUIDs:      []string{"gpu-0"},
+			Connections: []api.ConnectionInfo{{ClientIP: "10.0.0.2", ShareCode: "abc"}},
+		}},
+	}, 0644))
+
+	gpus, sessions, ok := LiveWorkerLoad(paths, "w1", now)
+	require.True(t, ok)
+	require.Len(t, gpus, 1)
+	assert.Equal(t, api.GPUMetrics{GPUID: "gpu-0", Utilization: 80, VRAMUsedMb: 1000, VRAMTotalMb: 24000}, gpus[0])
+	assert.Len(t, sessions, 1)
+
+	_, _, ok = LiveWorkerLoad(paths, "w2", now)
+	assert.False(t, ok, "the agent does not run the worker")
+	_, _, ok = LiveWorkerLoad(paths, "w1", now.Add(time.Minute))
+	assert.False(t, ok, "stale snapshot")
+}
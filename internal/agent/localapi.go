@@ -0,0 +1,336 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// LocalAPITokenFile holds the bearer token of the local API in the agent
+	// config directory; it is created on first use and readable by its owner only
+	LocalAPITokenFile = "local-api.token"
+
+	// localAPIReconcileInterval is the minimum time between two reconciles
+	// triggered through the local API, each of which pulls the config from
+	// the platform
+	localAPIReconcileInterval = 5 * time.Second
+	// localAPIEventBuffer bounds the events queued for a slow event stream;
+	// further events are dropped for that stream
+	localAPIEventBuffer = 64
+	// localAPIKeepalive is how often an idle event stream gets a comment so
+	// proxies and clients can tell it is alive
+	localAPIKeepalive = 30 * time.Second
+)
+
+// localAPI serves the agent's GPU, worker and session state to tooling on
+// the same host, authenticated with a bearer token
+type localAPI struct {
+	listener net.Listener
+	// socketPath is the Unix socket to remove on shutdown; empty for TCP
+	socketPath string
+	token      string
+
+	mu            sync.Mutex
+	subscribers   map[chan api.AgentEvent]struct{}
+	lastReconcile time.Time
+}
+
+// LocalAPIReconcileResponse is the result of POST /v1/reconcile
+type LocalAPIReconcileResponse struct {
+	ConfigVersion int `json:"config_version"`
+	// ConfigError is set when the config could not be pulled; workers are
+	// then reconciled against the last pulled config
+	ConfigError string `json:"config_error,omitempty"`
+}
+
+// LocalAPISession is one client session in GET /v1/sessions
+type LocalAPISession struct {
+	WorkerID string `json:"worker_id"`
+	api.ConnectionInfo
+}
+
+// ParseLocalAPIAddr parses the address of the local API: unix:<path> for a
+// Unix socket, or host:port with a loopback host. Other hosts are refused so
+// the API is never reachable from the network.
+func ParseLocalAPIAddr(addr string) (network, address string, err error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		if path == "" {
+			return "", "", fmt.Errorf("local API socket path is empty")
+		}
+		return "unix", path, nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid local API address %q (use unix:<path> or 127.0.0.1:<port>): %w", addr, err)
+	}
+	if host == "localhost" {
+		return "tcp", addr, nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return "", "", fmt.Errorf("local API must listen on a loopback address or Unix socket, not %q", host)
+	}
+	return "tcp", addr, nil
+}
+
+// LoadLocalAPIToken reads the local API token from configDir, creating one
+// when there is none
+func LoadLocalAPIToken(configDir string) (string, error) {
+	path := filepath.Join(configDir, LocalAPITokenFile)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	token := rand.Text()
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// EnableLocalAPI serves the local API on addr (see ParseLocalAPIAddr) while
+// the agent runs. Must be called before Start.
+func (a *Agent) EnableLocalAPI(addr string) error {
+	network, address, err := ParseLocalAPIAddr(addr)
+	if err != nil {
+		return err
+	}
+	token, err := LoadLocalAPIToken(a.config.ConfigDir())
+	if err != nil {
+		return fmt.Errorf("failed to load local API token: %w", err)
+	}
+
+	l := &localAPI{token: token, subscribers: make(map[chan api.AgentEvent]struct{})}
+	if network == "unix" {
+		// A socket left by an agent that did not shut down cleanly would
+		// make the listen fail
+		if info, err := os.Lstat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(address)
+		}
+		l.socketPath = address
+	}
+	if l.listener, err = net.Listen(network, address); err != nil {
+		return fmt.Errorf("failed to listen for the local API: %w", err)
+	}
+	if network == "unix" {
+		// The token authenticates; the mode keeps other users from even connecting
+		if err := os.Chmod(address, 0660); err != nil {
+			klog.Warningf("Failed to restrict local API socket: path=%s error=%v", address, err)
+		}
+	}
+	a.localAPI = l
+	return nil
+}
+
+// serveLocalAPI serves the local API until the agent stops
+func (a *Agent) serveLocalAPI() {
+	defer a.wg.Done()
+
+	srv := &http.Server{
+		Handler:           a.localAPIHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Ends event streams when the agent stops
+		BaseContext: func(net.Listener) context.Context { return a.ctx },
+	}
+	go func() {
+		<-a.ctx.Done()
+		_ = srv.Close()
+	}()
+
+	klog.Infof("Local API listening: addr=%s", a.localAPI.listener.Addr())
+	if err := srv.Serve(a.localAPI.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Errorf("Local API stopped: error=%v", err)
+	}
+	if a.localAPI.socketPath != "" {
+		_ = os.Remove(a.localAPI.socketPath)
+	}
+}
+
+// localAPIHandler routes the local API behind token authentication
+func (a *Agent) localAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", func(w http.ResponseWriter, r *http.Request) {
+		writeLocalAPIJSON(w, http.StatusOK, a.liveStatus())
+	})
+	mux.HandleFunc("GET /v1/gpus", func(w http.ResponseWriter, r *http.Request) {
+		writeLocalAPIJSON(w, http.StatusOK, map[string]any{"gpus": a.liveStatus().GPUs})
+	})
+	mux.HandleFunc("GET /v1/workers", func(w http.ResponseWriter, r *http.Request) {
+		writeLocalAPIJSON(w, http.StatusOK, map[string]any{"workers": a.liveStatus().Workers})
+	})
+	mux.HandleFunc("GET /v1/workers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		worker, ok := findLiveWorker(a.liveStatus(), r.PathValue("id"))
+		if !ok {
+			writeLocalAPIError(w, http.StatusNotFound, "worker not found")
+			return
+		}
+		writeLocalAPIJSON(w, http.StatusOK, worker)
+	})
+	mux.HandleFunc("GET /v1/workers/{id}/sessions", func(w http.ResponseWriter, r *http.Request) {
+		worker, ok := findLiveWorker(a.liveStatus(), r.PathValue("id"))
+		if !ok {
+			writeLocalAPIError(w, http.StatusNotFound, "worker not found")
+			return
+		}
+		writeLocalAPIJSON(w, http.StatusOK, map[string]any{"sessions": localAPISessions(worker)})
+	})
+	mux.HandleFunc("GET /v1/sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions := []LocalAPISession{}
+		for _, worker := range a.liveStatus().Workers {
+			sessions = append(sessions, localAPISessions(worker)...)
+		}
+		writeLocalAPIJSON(w, http.StatusOK, map[string]any{"sessions": sessions})
+	})
+	mux.HandleFunc("POST /v1/reconcile", a.handleLocalAPIReconcile)
+	mux.HandleFunc("GET /v1/events", a.handleLocalAPIEvents)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.localAPI.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeLocalAPIError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// handleLocalAPIReconcile pulls the config from the platform and reconciles
+// workers against it
+func (a *Agent) handleLocalAPIReconcile(w http.ResponseWriter, r *http.Request) {
+	l := a.localAPI
+	l.mu.Lock()
+	if wait := localAPIReconcileInterval - time.Since(l.lastReconcile); wait > 0 {
+		l.mu.Unlock()
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(wait.Round(time.Second)/time.Second)+1))
+		writeLocalAPIError(w, http.StatusTooManyRequests, "reconcile triggered too recently")
+		return
+	}
+	l.lastReconcile = time.Now()
+	l.mu.Unlock()
+
+	klog.Infof("Reconcile requested through the local API")
+	var resp LocalAPIReconcileResponse
+	if err := a.pullConfig(); err != nil {
+		klog.Warningf("Failed to pull config for local API reconcile: error=%v", err)
+		resp.ConfigError = err.Error()
+	}
+	if a.reconciler != nil {
+		a.reconciler.TriggerReconcile()
+	}
+	resp.ConfigVersion = a.configVersion
+	writeLocalAPIJSON(w, http.StatusAccepted, resp)
+}
+
+// handleLocalAPIEvents streams agent events as server-sent events until the
+// client disconnects or the agent stops
+func (a *Agent) handleLocalAPIEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeLocalAPIError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	events := a.localAPI.subscribe()
+	defer a.localAPI.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(localAPIKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func (l *localAPI) subscribe() chan api.AgentEvent {
+	ch := make(chan api.AgentEvent, localAPIEventBuffer)
+	l.mu.Lock()
+	l.subscribers[ch] = struct{}{}
+	l.mu.Unlock()
+	return ch
+}
+
+func (l *localAPI) unsubscribe(ch chan api.AgentEvent) {
+	l.mu.Lock()
+	delete(l.subscribers, ch)
+	l.mu.Unlock()
+}
+
+// publish hands an event to every event stream without blocking the agent
+func (l *localAPI) publish(event api.AgentEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ch := range l.subscribers {
+		select {
+		case ch <- event:
+		default:
+			klog.V(4).Infof("Local API event stream too slow, dropping event: type=%s", event.Type)
+		}
+	}
+}
+
+func findLiveWorker(status *LiveStatus, workerID string) (LiveWorker, bool) {
+	for _, w := range status.Workers {
+		if w.WorkerID == workerID {
+			return w, true
+		}
+	}
+	return LiveWorker{}, false
+}
+
+func localAPISessions(worker LiveWorker) []LocalAPISession {
+	sessions := make([]LocalAPISession, 0, len(worker.Connections))
+	for _, c := range worker.Connections {
+		sessions = append(sessions, LocalAPISession{WorkerID: worker.WorkerID, ConnectionInfo: c})
+	}
+	return sessions
+}
+
+func writeLocalAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeLocalAPIError(w http.ResponseWriter, status int, message string) {
+	writeLocalAPIJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocalAPIAddr(t *testing.T) {
+	tests := []struct {
+		addr, network, address string
+		wantErr                bool
+	}{
+		{addr: "unix:/run/ggo/agent.sock", network: "unix", address: "/run/ggo/agent.sock"},
+		{addr: "127.0.0.1:9123", network: "tcp", address: "127.0.0.1:9123"},
+		{addr: "[::1]:9123", network: "tcp", address: "[::1]:9123"},
+		{addr: "localhost:9123", network: "tcp", address: "localhost:9123"},
+		{addr: "0.0.0.0:9123", wantErr: true},
+		{addr: ":9123", wantErr: true},
+		{addr: "10.0.0.5:9123", wantErr: true},
+		{addr: "unix:", wantErr: true},
+		{addr: "9123", wantErr: true},
+	}
+	for _, tt := range tests {
+		network, address, err := ParseLocalAPIAddr(tt.addr)
+		if tt.wantErr {
+			assert.Error(t, err, tt.addr)
+			continue
+		}
+		require.NoError(t, err, tt.addr)
+		assert.Equal(t, tt.network, network)
+		assert.Equal(t, tt.address, address)
+	}
+}
+
+func TestLoadLocalAPIToken(t *testing.T) {
+	dir := t.TempDir()
+	token, err := LoadLocalAPIToken(dir)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	info, err := os.Stat(filepath.Join(dir, LocalAPITokenFile))
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	again, err := LoadLocalAPIToken(dir)
+	require.NoError(t, err)
+	assert.Equal(t, token, again, "the token is kept across restarts")
+}
+
+func TestLocalAPI(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(api.NewClient(api.WithBaseURL("http://127.0.0.1:1")), config.NewManager(dir, dir))
+	require.NoError(t, a.EnableLocalAPI("127.0.0.1:0"))
+	defer func() { _ = a.localAPI.listener.Close() }()
+	token, err := LoadLocalAPIToken(dir)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(a.localAPIHandler())
+	defer srv.Close()
+	get := func(path, token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := get("/v1/gpus", "")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = get("/v1/gpus", "wrong")
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	for _, path := range []string{"/v1/status", "/v1/gpus", "/v1/workers", "/v1/sessions"} {
+		resp = get(path, token)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"), path)
+	}
+	resp = get("/v1/workers/w1/sessions", token)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Events recorded after a stream opened are sent to it
+	a.events = newEventQueue(filepath.Join(dir, eventsFile))
+	resp = get("/v1/events", token)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	require.Eventually(t, func() bool {
+		a.localAPI.mu.Lock()
+		defer a.localAPI.mu.Unlock()
+		return len(a.localAPI.subscribers) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	a.recordWorkerEvent(api.AgentEventWorkerStarted, "w1", api.AgentEventSeverityInfo, "Worker started", nil)
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []string
+	for scanner.Scan() && scanner.Text() != "" {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "id: evt_"))
+	assert.Equal(t, "event: "+api.AgentEventWorkerStarted, lines[1])
+	assert.Contains(t, lines[2], `"worker_id":"w1"`)
+}
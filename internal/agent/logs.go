@@ -0,0 +1,287 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// workerLogPollInterval is how often a followed log is checked for new
+	// lines and for a newer log file after a worker restart
+	workerLogPollInterval = 500 * time.Millisecond
+	// workerLogTailBytes bounds how far back the initial tail reads
+	workerLogTailBytes = 4 << 20
+	// workerLogChunkLines caps the lines sent to the server per chunk
+	workerLogChunkLines = 500
+	// maxWorkerLogStreams caps concurrent remote log streams per agent
+	maxWorkerLogStreams = 8
+)
+
+// ErrNoWorkerLog is returned when a worker has not written a log on this host
+var ErrNoWorkerLog = errors.New("no log found for worker")
+
+// errLogViewerGone stops a remote stream once nobody is reading it
+var errLogViewerGone = errors.New("log viewer disconnected")
+
+// workerLogsDir returns where the hypervisor-managed workers write their logs
+func workerLogsDir(stateDir string) string {
+	return filepath.Join(stateDir, "logs")
+}
+
+// HasWorkerLog reports whether a worker has written a log under the agent
+// state directory, i.e. whether it runs on this host
+func HasWorkerLog(stateDir, workerID string) bool {
+	return latestWorkerLog(workerLogsDir(stateDir), workerID) != ""
+}
+
+// FollowWorkerLog passes the last tail lines of a worker's log to emit (the
+// whole log when tail is negative). With follow it keeps passing new lines
+// until ctx is done, switching to the new log file when the worker restarts.
+func FollowWorkerLog(ctx context.Context, stateDir, workerID string, tail int, follow bool,
+	emit func(lines []string) error) error {
+	logsDir := workerLogsDir(stateDir)
+	return followLog(ctx, func() string { return latestWorkerLog(logsDir, workerID) }, workerID, tail, follow, emit)
+}
+
+// followLog follows the log file returned by locate, which returns "" while
+// there is none and a different file once the log moved to a new one
+func followLog(ctx context.Context, locate func() string, workerID string, tail int, follow bool,
+	emit func(lines []string) error) error {
+	path := locate()
+	if path == "" && !follow {
+		return fmt.Errorf("%w: %s", ErrNoWorkerLog, workerID)
+	}
+
+	var offset int64
+	var partial []byte
+	if path != "" {
+		lines, size, err := readLastLines(path, tail)
+		if err != nil {
+			return err
+		}
+		offset = size
+		if err := emitLines(lines, emit); err != nil {
+			return err
+		}
+	}
+	if !follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(workerLogPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if latest := locate(); latest != path {
+			// A restarted worker logs to a new timestamped file; drain the
+			// old one first so its last lines are not lost
+			if path != "" {
+				lines, _, _ := readNewLines(path, offset, partial)
+				if err := emitLines(lines, emit); err != nil {
+					return err
+				}
+			}
+			path, offset, partial = latest, 0, nil
+		}
+		if path == "" {
+			continue
+		}
+
+		lines, next, err := readNewLines(path, offset, partial)
+		if err != nil {
+			klog.V(4).Infof("Failed to read worker log: path=%s error=%v", path, err)
+			continue
+		}
+		offset, partial = next.offset, next.partial
+		if err := emitLines(lines, emit); err != nil {
+			return err
+		}
+	}
+}
+
+// emitLines passes lines to emit in chunks of at most workerLogChunkLines
+func emitLines(lines []string, emit func([]string) error) error {
+	for len(lines) > 0 {
+		n := min(len(lines), workerLogChunkLines)
+		if err := emit(lines[:n]); err != nil {
+			return err
+		}
+		lines = lines[n:]
+	}
+	return nil
+}
+
+// readLastLines returns the last n complete lines of a file (all when n is
+// negative) and the size of the file when it was read
+func readLastLines(path string, n int) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := info.Size()
+	if n == 0 {
+		return nil, size, nil
+	}
+
+	start := int64(0)
+	if n > 0 {
+		start = max(size-workerLogTailBytes, 0)
+	}
+	data := make([]byte, size-start)
+	if _, err := f.ReadAt(data, start); err != nil && !errors.Is(err, io.EOF) {
+		return nil, 0, err
+	}
+	if start > 0 {
+		// Drop the line cut in half by the read window
+		if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+			data = data[idx+1:]
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, size, nil
+}
+
+// logPosition is where following a log file resumes
+type logPosition struct {
+	offset  int64
+	partial []byte // an incomplete last line, held back until it ends
+}
+
+// readNewLines returns the complete lines written to path after offset
+func readNewLines(path string, offset int64, partial []byte) ([]string, logPosition, error) {
+	pos := logPosition{offset: offset, partial: partial}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, pos, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, pos, err
+	}
+	if info.Size() < offset {
+		// Truncated; start over
+		pos = logPosition{}
+	}
+	if info.Size() == pos.offset {
+		return nil, pos, nil
+	}
+
+	data := make([]byte, info.Size()-pos.offset)
+	n, err := f.ReadAt(data, pos.offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, pos, err
+	}
+	data = append(pos.partial, data[:n]...)
+	pos.offset += int64(n)
+
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		pos.partial = data
+		return nil, pos, nil
+	}
+	pos.partial = append([]byte(nil), data[end+1:]...)
+	return strings.Split(string(data[:end]), "\n"), pos, nil
+}
+
+// parseWorkerLogRequest recognizes a WorkerLogRequest among the frames of the
+// agent's config topic; any other frame is a config update notification
+func parseWorkerLogRequest(data string) (api.WorkerLogRequest, bool) {
+	var req api.WorkerLogRequest
+	if !strings.HasPrefix(data, "{") || json.Unmarshal([]byte(data), &req) != nil {
+		return req, false
+	}
+	return req, req.RequestID != "" && req.WorkerID != ""
+}
+
+// handleWorkerLogRequest starts streaming the worker log named by req
+func (a *Agent) handleWorkerLogRequest(req api.WorkerLogRequest) {
+	select {
+	case a.logStreams <- struct{}{}:
+	default:
+		klog.Warningf("Too many worker log streams, rejecting request: worker_id=%s request_id=%s", req.WorkerID, req.RequestID)
+		a.sendWorkerLogChunk(&api.WorkerLogChunk{
+			RequestID: req.RequestID,
+			WorkerID:  req.WorkerID,
+			EOF:       true,
+			Error:     "too many concurrent log streams on this agent",
+		})
+		return
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer func() { <-a.logStreams }()
+		a.streamWorkerLog(req)
+	}()
+}
+
+// streamWorkerLog sends a worker log to the server for a WorkerLogRequest
+// until the log ends (without follow), the viewer goes away or the agent stops
+func (a *Agent) streamWorkerLog(req api.WorkerLogRequest) {
+	klog.Infof("Streaming worker log: worker_id=%s request_id=%s follow=%t output=%t", req.WorkerID, req.RequestID, req.Follow, req.Output)
+
+	follow := FollowWorkerLog
+	if req.Output {
+		follow = FollowWorkerOutput
+	}
+	err := follow(a.ctx, a.config.StateDir(), req.WorkerID, req.Tail, req.Follow, func(lines []string) error {
+		resp := a.sendWorkerLogChunk(&api.WorkerLogChunk{RequestID: req.RequestID, WorkerID: req.WorkerID, Lines: lines})
+		if resp == nil || resp.Closed {
+			return errLogViewerGone
+		}
+		return nil
+	})
+	if errors.Is(err, errLogViewerGone) {
+		klog.Infof("Worker log viewer disconnected: worker_id=%s request_id=%s", req.WorkerID, req.RequestID)
+		return
+	}
+
+	final := &api.WorkerLogChunk{RequestID: req.RequestID, WorkerID: req.WorkerID, EOF: true}
+	if err != nil {
+		klog.Warningf("Failed to stream worker log: worker_id=%s request_id=%s error=%v", req.WorkerID, req.RequestID, err)
+		final.Error = err.Error()
+	}
+	a.sendWorkerLogChunk(final)
+}
+
+// sendWorkerLogChunk delivers a chunk; returns nil if it could not be sent
+func (a *Agent) sendWorkerLogChunk(chunk *api.WorkerLogChunk) *api.WorkerLogChunkResponse {
+	resp, err := a.client.SendWorkerLogChunk(context.Background(), a.agentID, chunk)
+	if err != nil {
+		klog.Warningf("Failed to send worker log chunk: worker_id=%s request_id=%s error=%v", chunk.WorkerID, chunk.RequestID, err)
+		return nil
+	}
+	return resp
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeWorkerLog(t *testing.T, stateDir, name, content string) string {
+	t.Helper()
+	dir := workerLogsDir(stateDir)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func appendWorkerLog(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestFollowWorkerLog_Tail(t *testing.T) {
+	stateDir := t.TempDir()
+	writeWorkerLog(t, stateDir, "worker-w1-20260101-000000.log", "one\ntwo\nthree\nfour\n")
+
+	var got []string
+	collect := func(lines []string) error {
+		got = append(got, lines...)
+		return nil
+	}
+
+	require.NoError(t, FollowWorkerLog(context.Background(), stateDir, "w1", 2, false, collect))
+	assert.Equal(t, []string{"three", "four"}, got)
+
+	got = nil
+	require.NoError(t, FollowWorkerLog(context.Background(), stateDir, "w1", -1, false, collect))
+	assert.Equal(t, []string{"one", "two", "three", "four"}, got)
+
+	assert.True(t, HasWorkerLog(stateDir, "w1"))
+	assert.False(t, HasWorkerLog(stateDir, "w2"))
+	err := FollowWorkerLog(context.Background(), stateDir, "w2", 10, false, collect)
+	assert.ErrorIs(t, err, ErrNoWorkerLog)
+}
+
+func TestFollowWorkerLog_FollowsAcrossRestart(t *testing.T) {
+	stateDir := t.TempDir()
+	first := writeWorkerLog(t, stateDir, "worker-w1-20260101-000000.log", "started\n")
+
+	var mu sync.Mutex
+	var got []string
+	lines := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), got...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowWorkerLog(ctx, stateDir, "w1", 10, true, func(l []string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, l...)
+			return nil
+		})
+	}()
+
+	require.Eventually(t, func() bool { return len(lines()) == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	// An incomplete line is held back until it ends
+	appendWorkerLog(t, first, "partial")
+	time.Sleep(2 * workerLogPollInterval)
+	assert.Equal(t, []string{"started"}, lines())
+	appendWorkerLog(t, first, " line\n")
+	require.Eventually(t, func() bool { return len(lines()) == 2 }, 2*time.Second, 10*time.Millisecond)
+
+	// The restarted worker writes to a newer file
+	second := writeWorkerLog(t, stateDir, "worker-w1-20260101-000100.log", "")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(second, later, later))
+	appendWorkerLog(t, second, "restarted\n")
+	require.NoError(t, os.Chtimes(second, later, later))
+	require.Eventually(t, func() bool { return len(lines()) == 3 }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"started", "partial line", "restarted"}, lines())
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestReadNewLines_Truncated(t *testing.T) {
+	path := writeWorkerLog(t, t.TempDir(), "worker-w1-1.log", "a long first line\n")
+
+	lines, pos, err := readNewLines(path, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a long first line"}, lines)
+
+	require.NoError(t, os.WriteFile(path, []byte("new\n"), 0644))
+	lines, _, err = readNewLines(path, pos.offset, pos.partial)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new"}, lines)
+}
+
+func TestParseWorkerLogRequest(t *testing.T) {
+	req, ok := parseWorkerLogRequest(`{"request_id":"r1","worker_id":"w1","tail":50,"follow":true}`)
+	assert.True(t, ok)
+	assert.Equal(t, api.WorkerLogRequest{RequestID: "r1", WorkerID: "w1", Tail: 50, Follow: true}, req)
+
+	// Everything else on the config topic is a config update notification
+	for _, data := range []string{"config-updated", `{"config_version":7}`, `{"request_id":"r1"}`, `{"worker_id":`} {
+		_, ok := parseWorkerLogRequest(data)
+		assert.False(t, ok, data)
+	}
+}
@@ -103,7 +103,8 @@ func escapeTagValue(s string) string {
 }
 
 // collectMetricsLineProtocol gathers GPU and system metrics, then builds
-// the InfluxDB line protocol string. Returns empty string on failure.
+// the InfluxDB line protocol string, including the heartbeat transport
+// health. Returns empty string when there is nothing to report.
 func (a *Agent) collectMetricsLineProtocol(
 	gpuStatuses []api.GPUStatus,
 	workerStatuses []api.WorkerStatus,
@@ -123,18 +124,34 @@ func (a *Agent) collectMetricsLineProtocol(
 	// Collect system metrics (best-effort, nil on non-Linux)
 	sysMetrics := collectSystemMetrics()
 
-	// Nothing to report
-	if len(gpuMetrics) == 0 && sysMetrics == nil && len(workerStatuses) == 0 {
-		return ""
+	var lines []string
+	if len(gpuMetrics) > 0 || sysMetrics != nil || len(workerStatuses) > 0 {
+		lines = append(lines, buildMetricsLineProtocol(
+			a.agentID,
+			a.hostname,
+			gpuMetrics,
+			gpuStatuses,
+			workerStatuses,
+			sysMetrics,
+			now.UnixMilli(),
+		))
 	}
+	if a.transport != nil {
+		lines = append(lines, buildTransportLine(a.agentID, a.transport.status(now), now))
+	}
+	return strings.Join(lines, "\n")
+}
 
-	return buildMetricsLineProtocol(
-		a.agentID,
-		a.hostname,
-		gpuMetrics,
-		gpuStatuses,
-		workerStatuses,
-		sysMetrics,
-		now.UnixMilli(),
-	)
+// buildTransportLine builds the agent_transport line protocol line. Ages of
+// events that never happened are -1.
+func buildTransportLine(agentID string, status TransportStatus, now time.Time) string {
+	age := func(t time.Time) int64 {
+		if t.IsZero() {
+			return -1
+		}
+		return int64(now.Sub(t).Seconds())
+	}
+	return fmt.Sprintf("agent_transport,agent_id=%s,mode=%s reconnects=%di,fallbacks=%di,last_sse_age_s=%di,last_poll_age_s=%di %d",
+		escapeTagValue(agentID), escapeTagValue(status.Mode), status.Reconnects, status.Fallbacks,
+		age(status.LastSSEAt), age(status.LastPollAt), now.UnixMilli())
 }
@@ -0,0 +1,363 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// migStateFile persists the MIG instances the agent created for workers
+	migStateFile = "mig.json"
+	// migCommandTimeout bounds a single nvidia-smi invocation
+	migCommandTimeout = 30 * time.Second
+)
+
+var (
+	// "GPU 0: NVIDIA A100-SXM4-40GB (UUID: GPU-5fd4...)"
+	migGPULine = regexp.MustCompile(`^GPU (\d+): .*\(UUID: (GPU-[^)]+)\)`)
+	// "  MIG 1g.5gb      Device  0: (UUID: MIG-c6d4...)"
+	migDeviceLine = regexp.MustCompile(`^\s+MIG (\S+)\s+Device\s+\d+: \(UUID: (MIG-[^)]+)\)`)
+	// "Successfully created GPU instance ID  9 on GPU  0 using profile MIG 1g.5gb (ID 19)"
+	migCreatedGI = regexp.MustCompile(`created GPU instance ID\s+(\d+)`)
+	// "  0    9  MIG 1g.5gb   19   ..." rows of `nvidia-smi mig -lgi`
+	migGIRow = regexp.MustCompile(`^\|?\s*(\d+)\s+MIG\s+(\S+)\s+\d+\s+(\d+)\s`)
+)
+
+// migGPU is a GPU as seen by nvidia-smi, with its MIG instances
+type migGPU struct {
+	Index     int
+	UUID      string
+	Capable   bool
+	Enabled   bool
+	Instances []migInstance
+}
+
+// migInstance is a MIG device, in the order nvidia-smi lists them
+type migInstance struct {
+	Profile string
+	UUID    string
+}
+
+// migAllocation is a MIG instance the agent created for a worker
+type migAllocation struct {
+	WorkerID      string `json:"worker_id"`
+	GPUID         string `json:"gpu_id"`
+	GPUIndex      int    `json:"gpu_index"`
+	Profile       string `json:"profile"`
+	GPUInstanceID int    `json:"gpu_instance_id"`
+	UUID          string `json:"uuid"`
+}
+
+// migManager creates and destroys MIG instances for workers through
+// nvidia-smi. Instances are created on demand when a worker with a MIG profile
+// is started and destroyed once it stopped; MIG mode itself is left to the
+// administrator since enabling it resets the GPU.
+type migManager struct {
+	mu   sync.Mutex
+	path string
+	smi  func(ctx context.Context, args ...string) (string, error)
+	// allocations are the instances created for workers, possibly several
+	// per worker while an outdated one is still in use
+	allocations []migAllocation
+	// signatures holds each GPU's last reported partitions, to flag changes
+	signatures map[string]string
+}
+
+// newMIGManager returns a manager persisting its allocations at path, or nil
+// where nvidia-smi cannot partition GPUs
+func newMIGManager(path string) *migManager {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	smiPath, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return nil
+	}
+	return newMIGManagerWithRunner(path, func(ctx context.Context, args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, smiPath, args...).CombinedOutput()
+		if err != nil {
+			return string(out), fmt.Errorf("nvidia-smi %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+		return string(out), nil
+	})
+}
+
+func newMIGManagerWithRunner(path string, smi func(ctx context.Context, args ...string) (string, error)) *migManager {
+	allocations, err := utils.LoadJSONSlice[migAllocation](path)
+	if err != nil {
+		klog.Warningf("Ignoring unreadable MIG state: path=%s error=%v", path, err)
+		allocations = nil
+	}
+	return &migManager{path: path, smi: smi, allocations: allocations, signatures: make(map[string]string)}
+}
+
+// inventory lists the GPUs with their MIG mode and instances, keyed by
+// normalized GPU UUID
+func (m *migManager) inventory(ctx context.Context) (map[string]*migGPU, error) {
+	ctx, cancel := context.WithTimeout(ctx, migCommandTimeout)
+	defer cancel()
+
+	modes, err := m.smi(ctx, "--query-gpu=index,uuid,mig.mode.current", "--format=csv,noheader")
+	if err != nil {
+		return nil, err
+	}
+	listing, err := m.smi(ctx, "-L")
+	if err != nil {
+		return nil, err
+	}
+	return parseMIGInventory(modes, listing), nil
+}
+
+// parseMIGInventory combines the MIG mode query with the `nvidia-smi -L`
+// listing
+func parseMIGInventory(modes, listing string) map[string]*migGPU {
+	gpus := make(map[string]*migGPU)
+	for _, line := range strings.Split(modes, "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+		if err != nil {
+			continue
+		}
+		mode := strings.TrimSpace(fields[2])
+		gpus[normalizeGPUID(fields[1])] = &migGPU{
+			Index:   index,
+			UUID:    strings.TrimSpace(fields[1]),
+			Capable: mode != "[N/A]" && mode != "N/A",
+			Enabled: mode == "Enabled",
+		}
+	}
+
+	var current *migGPU
+	for _, line := range strings.Split(listing, "\n") {
+		if match := migGPULine.FindStringSubmatch(line); match != nil {
+			current = gpus[normalizeGPUID(match[2])]
+			continue
+		}
+		if match := migDeviceLine.FindStringSubmatch(line); match != nil && current != nil {
+			current.Instances = append(current.Instances, migInstance{Profile: match[1], UUID: match[2]})
+		}
+	}
+	return gpus
+}
+
+// ensure returns the UUID of the worker's MIG instance of profile on gpuID,
+// creating it if needed. Instances of the worker with another profile or on
+// another GPU are destroyed, or kept for release once the worker stopped if
+// still in use.
+func (m *migManager) ensure(ctx context.Context, workerID, gpuID, profile string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gpus, err := m.inventory(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list MIG instances: %w", err)
+	}
+	gpu := gpus[normalizeGPUID(gpuID)]
+	switch {
+	case gpu == nil:
+		return "", fmt.Errorf("GPU %s not found by nvidia-smi", gpuID)
+	case !gpu.Capable:
+		return "", fmt.Errorf("GPU %s does not support MIG", gpuID)
+	case !gpu.Enabled:
+		return "", fmt.Errorf("MIG mode is disabled on GPU %d; enable it with 'nvidia-smi -i %d -mig 1' (resets the GPU)", gpu.Index, gpu.Index)
+	}
+
+	var existing string
+	for _, alloc := range m.allocationsLocked(workerID) {
+		if existing == "" && alloc.Profile == profile && normalizeGPUID(alloc.GPUID) == normalizeGPUID(gpuID) && hasMIGInstance(gpu, alloc.UUID) {
+			existing = alloc.UUID
+			continue
+		}
+		m.destroyLocked(ctx, alloc)
+	}
+	if existing != "" {
+		return existing, nil
+	}
+
+	alloc, err := m.createLocked(ctx, gpu, profile)
+	if err != nil {
+		return "", err
+	}
+	alloc.WorkerID = workerID
+	alloc.GPUID = gpuID
+	m.allocations = append(m.allocations, alloc)
+	m.saveLocked()
+	klog.Infof("MIG instance created: worker_id=%s gpu_index=%d profile=%s uuid=%s", workerID, gpu.Index, profile, alloc.UUID)
+	return alloc.UUID, nil
+}
+
+// createLocked creates a GPU instance of profile with its default compute
+// instance, and finds its UUID among the devices nvidia-smi lists afterwards
+func (m *migManager) createLocked(ctx context.Context, gpu *migGPU, profile string) (migAllocation, error) {
+	ctx, cancel := context.WithTimeout(ctx, migCommandTimeout)
+	defer cancel()
+
+	index := strconv.Itoa(gpu.Index)
+	out, err := m.smi(ctx, "mig", "-i", index, "-cgi", profile, "-C")
+	if err != nil {
+		return migAllocation{}, fmt.Errorf("failed to create MIG instance %s on GPU %d: %w", profile, gpu.Index, err)
+	}
+	match := migCreatedGI.FindStringSubmatch(out)
+	if match == nil {
+		return migAllocation{}, fmt.Errorf("unexpected nvidia-smi output creating MIG instance %s on GPU %d: %s", profile, gpu.Index, strings.TrimSpace(out))
+	}
+	alloc := migAllocation{GPUIndex: gpu.Index, Profile: profile}
+	alloc.GPUInstanceID, _ = strconv.Atoi(match[1])
+
+	listing, err := m.smi(ctx, "-L")
+	if err == nil {
+		if gpus := parseMIGInventory(fmt.Sprintf("%d, %s, Enabled", gpu.Index, gpu.UUID), listing); gpus[normalizeGPUID(gpu.UUID)] != nil {
+			for _, instance := range gpus[normalizeGPUID(gpu.UUID)].Instances {
+				if instance.Profile == profile && !hasMIGInstance(gpu, instance.UUID) && !m.allocatedLocked(instance.UUID) {
+					alloc.UUID = instance.UUID
+					break
+				}
+			}
+		}
+	}
+	if alloc.UUID == "" {
+		m.destroyLocked(ctx, alloc)
+		if err == nil {
+			err = fmt.Errorf("new instance not listed")
+		}
+		return migAllocation{}, fmt.Errorf("failed to find MIG instance %s created on GPU %d: %w", profile, gpu.Index, err)
+	}
+	return alloc, nil
+}
+
+// release destroys the MIG instances created for the worker
+func (m *migManager) release(ctx context.Context, workerID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, alloc := range m.allocationsLocked(workerID) {
+		m.destroyLocked(ctx, alloc)
+	}
+}
+
+// destroyLocked destroys the compute and GPU instance of alloc, forgetting
+// it unless it is still in use
+func (m *migManager) destroyLocked(ctx context.Context, alloc migAllocation) {
+	ctx, cancel := context.WithTimeout(ctx, migCommandTimeout)
+	defer cancel()
+
+	index, gi := strconv.Itoa(alloc.GPUIndex), strconv.Itoa(alloc.GPUInstanceID)
+	if _, err := m.smi(ctx, "mig", "-i", index, "-gi", gi, "-dci"); err != nil {
+		klog.V(2).Infof("Failed to destroy MIG compute instances, destroying GPU instance anyway: gpu_index=%d gi=%d error=%v", alloc.GPUIndex, alloc.GPUInstanceID, err)
+	}
+	if _, err := m.smi(ctx, "mig", "-i", index, "-gi", gi, "-dgi"); err != nil {
+		// Gone already when the GPU was reset or reconfigured by hand
+		listed, lerr := m.smi(ctx, "mig", "-i", index, "-lgi")
+		if (lerr != nil && !strings.Contains(listed, "No GPU instances found")) || listsGPUInstance(listed, alloc) {
+			klog.Warningf("Failed to destroy MIG instance, retrying later: worker_id=%s uuid=%s error=%v", alloc.WorkerID, alloc.UUID, err)
+			return
+		}
+		klog.Infof("MIG instance already gone: worker_id=%s uuid=%s", alloc.WorkerID, alloc.UUID)
+	} else {
+		klog.Infof("MIG instance destroyed: worker_id=%s gpu_index=%d profile=%s uuid=%s", alloc.WorkerID, alloc.GPUIndex, alloc.Profile, alloc.UUID)
+	}
+	m.allocations = slices.DeleteFunc(m.allocations, func(a migAllocation) bool { return a.UUID == alloc.UUID })
+	m.saveLocked()
+}
+
+// listsGPUInstance reports whether `nvidia-smi mig -lgi` output lists the
+// GPU instance of alloc
+func listsGPUInstance(listed string, alloc migAllocation) bool {
+	for _, line := range strings.Split(listed, "\n") {
+		match := migGIRow.FindStringSubmatch(line)
+		if match != nil && match[1] == strconv.Itoa(alloc.GPUIndex) && match[3] == strconv.Itoa(alloc.GPUInstanceID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *migManager) allocationsLocked(workerID string) []migAllocation {
+	var allocs []migAllocation
+	for _, alloc := range m.allocations {
+		if alloc.WorkerID == workerID {
+			allocs = append(allocs, alloc)
+		}
+	}
+	return allocs
+}
+
+func (m *migManager) allocatedLocked(uuid string) bool {
+	return slices.ContainsFunc(m.allocations, func(a migAllocation) bool { return a.UUID == uuid })
+}
+
+func (m *migManager) saveLocked() {
+	if err := utils.SaveJSONSlice(m.path, m.allocations, 0644); err != nil {
+		klog.Warningf("Failed to save MIG state: path=%s error=%v", m.path, err)
+	}
+}
+
+func hasMIGInstance(gpu *migGPU, uuid string) bool {
+	return slices.ContainsFunc(gpu.Instances, func(i migInstance) bool { return i.UUID == uuid })
+}
+
+// partitions returns the GPU's MIG instances with the workers they were
+// created for, and whether they changed since the previous call
+func (m *migManager) partitions(gpu *migGPU) ([]api.GPUPartition, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	owners := make(map[string]string, len(m.allocations))
+	for _, alloc := range m.allocations {
+		owners[alloc.UUID] = alloc.WorkerID
+	}
+	parts := make([]api.GPUPartition, 0, len(gpu.Instances))
+	signature := make([]string, 0, len(gpu.Instances))
+	for _, instance := range gpu.Instances {
+		parts = append(parts, api.GPUPartition{UUID: instance.UUID, Profile: instance.Profile, WorkerID: owners[instance.UUID]})
+		signature = append(signature, instance.UUID+"="+owners[instance.UUID])
+	}
+	key := normalizeGPUID(gpu.UUID)
+	sig := strconv.FormatBool(gpu.Enabled) + ";" + strings.Join(signature, ",")
+	changed := m.signatures[key] != sig
+	m.signatures[key] = sig
+	return parts, changed
+}
+
+// ensureMIGInstance returns the UUID of the MIG instance of a worker with a
+// MIG profile, creating it if needed
+func (a *Agent) ensureMIGInstance(w api.WorkerConfig) (string, error) {
+	if a.mig == nil {
+		return "", fmt.Errorf("MIG partitioning requires nvidia-smi on Linux")
+	}
+	if len(w.GPUIDs) != 1 {
+		return "", fmt.Errorf("a MIG worker needs exactly one GPU, got %d", len(w.GPUIDs))
+	}
+	return a.mig.ensure(a.ctx, w.WorkerID, w.GPUIDs[0], w.MIGProfile)
+}
+
+// releaseMIGInstance destroys the MIG instances of a stopped worker unless it
+// is about to be started again on them
+func (a *Agent) releaseMIGInstance(workerID string) {
+	if a.mig == nil {
+		return
+	}
+	a.mu.RLock()
+	restarting := slices.ContainsFunc(a.workerConfigs, func(w api.WorkerConfig) bool {
+		return w.WorkerID == workerID && w.Enabled && w.MIGProfile != ""
+	})
+	a.mu.RUnlock()
+	if restarting {
+		return
+	}
+	a.mig.release(a.ctx, workerID)
+}
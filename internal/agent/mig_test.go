@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const migTestListing = `GPU 0: NVIDIA A100-SXM4-40GB (UUID: GPU-aaaa)
+  MIG 3g.20gb     Device  0: (UUID: MIG-1111)
+GPU 1: NVIDIA A100-SXM4-40GB (UUID: GPU-bbbb)
+GPU 2: NVIDIA GeForce RTX 4090 (UUID: GPU-cccc)
+`
+
+func TestParseMIGInventory(t *testing.T) {
+	modes := "0, GPU-aaaa, Enabled\n1, GPU-bbbb, Disabled\n2, GPU-cccc, [N/A]\n"
+	gpus := parseMIGInventory(modes, migTestListing)
+
+	require.Len(t, gpus, 3)
+	assert.Equal(t, &migGPU{Index: 0, UUID: "GPU-aaaa", Capable: true, Enabled: true,
+		Instances: []migInstance{{Profile: "3g.20gb", UUID: "MIG-1111"}}}, gpus["gpu-aaaa"])
+	assert.True(t, gpus["gpu-bbbb"].Capable)
+	assert.False(t, gpus["gpu-bbbb"].Enabled)
+	assert.False(t, gpus["gpu-cccc"].Capable)
+}
+
+// fakeSMI plays nvidia-smi for one MIG-enabled GPU
+type fakeSMI struct {
+	instances []migInstance
+	gis       []int
+	created   int
+	busy      bool // destroying instances fails as if in use
+}
+
+func (f *fakeSMI) run(_ context.Context, args ...string) (string, error) {
+	call := strings.Join(args, " ")
+	switch {
+	case strings.HasPrefix(call, "--query-gpu"):
+		return "0, GPU-aaaa, Enabled\n", nil
+	case call == "-L":
+		listing := "GPU 0: NVIDIA A100-SXM4-40GB (UUID: GPU-aaaa)\n"
+		for i, instance := range f.instances {
+			listing += fmt.Sprintf("  MIG %s     Device  %d: (UUID: %s)\n", instance.Profile, i, instance.UUID)
+		}
+		return listing, nil
+	case strings.Contains(call, "-cgi"):
+		gi := f.created + 7
+		f.created++
+		f.gis = append(f.gis, gi)
+		f.instances = append(f.instances, migInstance{Profile: args[4], UUID: fmt.Sprintf("MIG-%d", gi)})
+		return fmt.Sprintf("Successfully created GPU instance ID  %d on GPU  0 using profile MIG %s (ID 19)\n", gi, args[4]), nil
+	case strings.HasSuffix(call, "-dci"):
+		return "", nil
+	case strings.HasSuffix(call, "-dgi"):
+		if f.busy {
+			return "", fmt.Errorf("in use by another client")
+		}
+		for i, gi := range f.gis {
+			if fmt.Sprint(gi) == args[4] {
+				f.instances = append(f.instances[:i], f.instances[i+1:]...)
+				f.gis = append(f.gis[:i], f.gis[i+1:]...)
+			}
+		}
+		return "", nil
+	case strings.HasSuffix(call, "-lgi"):
+		var listed string
+		for i, gi := range f.gis {
+			listed += fmt.Sprintf("|   0  MIG %s          19        %d          0:4     |\n", f.instances[i].Profile, gi)
+		}
+		return listed, nil
+	}
+	return "", fmt.Errorf("unexpected call %q", call)
+}
+
+func TestMIGManager_EnsureAndRelease(t *testing.T) {
+	smi := &fakeSMI{}
+	path := filepath.Join(t.TempDir(), migStateFile)
+	m := newMIGManagerWithRunner(path, smi.run)
+	ctx := context.Background()
+
+	uuid, err := m.ensure(ctx, "w1", "GPU-AAAA", "1g.5gb")
+	require.NoError(t, err)
+	assert.Equal(t, "MIG-7", uuid)
+
+	again, err := m.ensure(ctx, "w1", "GPU-AAAA", "1g.5gb")
+	require.NoError(t, err)
+	assert.Equal(t, uuid, again, "the instance is reused")
+	assert.Len(t, smi.instances, 1)
+
+	reloaded := newMIGManagerWithRunner(path, smi.run)
+	parts, changed := reloaded.partitions(&migGPU{UUID: "GPU-aaaa", Enabled: true, Instances: smi.instances})
+	assert.True(t, changed)
+	require.Len(t, parts, 1)
+	assert.Equal(t, "w1", parts[0].WorkerID, "allocations survive restarts")
+	_, changed = reloaded.partitions(&migGPU{UUID: "GPU-aaaa", Enabled: true, Instances: smi.instances})
+	assert.False(t, changed)
+
+	uuid, err = m.ensure(ctx, "w1", "GPU-AAAA", "2g.10gb")
+	require.NoError(t, err)
+	assert.Equal(t, "MIG-8", uuid)
+	assert.Equal(t, []migInstance{{Profile: "2g.10gb", UUID: "MIG-8"}}, smi.instances, "a changed profile replaces the instance")
+
+	m.release(ctx, "w1")
+	assert.Empty(t, smi.instances)
+	assert.Empty(t, m.allocations)
+}
+
+func TestMIGManager_KeepsBusyInstanceForRetry(t *testing.T) {
+	smi := &fakeSMI{}
+	m := newMIGManagerWithRunner(filepath.Join(t.TempDir(), migStateFile), smi.run)
+	ctx := context.Background()
+
+	_, err := m.ensure(ctx, "w1", "GPU-aaaa", "1g.5gb")
+	require.NoError(t, err)
+
+	smi.busy = true
+	m.release(ctx, "w1")
+	assert.Len(t, m.allocations, 1, "an instance in use is released later")
+
+	smi.busy = false
+	m.release(ctx, "w1")
+	assert.Empty(t, m.allocations)
+}
+
+func TestMIGManager_RequiresMIGMode(t *testing.T) {
+	m := newMIGManagerWithRunner(filepath.Join(t.TempDir(), migStateFile), func(_ context.Context, args ...string) (string, error) {
+		if args[0] == "-L" {
+			return migTestListing, nil
+		}
+		return "0, GPU-aaaa, Enabled\n1, GPU-bbbb, Disabled\n2, GPU-cccc, [N/A]\n", nil
+	})
+	ctx := context.Background()
+
+	_, err := m.ensure(ctx, "w1", "GPU-bbbb", "1g.5gb")
+	assert.ErrorContains(t, err, "nvidia-smi -i 1 -mig 1")
+	_, err = m.ensure(ctx, "w1", "GPU-cccc", "1g.5gb")
+	assert.ErrorContains(t, err, "does not support MIG")
+	_, err = m.ensure(ctx, "w1", "GPU-dddd", "1g.5gb")
+	assert.ErrorContains(t, err, "not found")
+}
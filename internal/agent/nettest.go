@@ -0,0 +1,325 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"golang.org/x/net/websocket"
+	"k8s.io/klog/v2"
+)
+
+const (
+	netTestFileName = "nettest.json"
+
+	// DefaultNetTestSamples is how many requests and WebSocket messages are
+	// timed for latency
+	DefaultNetTestSamples = 5
+	// DefaultNetTestBytes is how much data is downloaded from the API and the
+	// CDN to measure throughput
+	DefaultNetTestBytes = 16 << 20
+	// DefaultNetTestCDNPath is the test object on the CDN
+	DefaultNetTestCDNPath = "/nettest/16MiB.bin"
+
+	// netTestProbeTimeout bounds each probe, so one blackholed endpoint does
+	// not stall the whole test
+	netTestProbeTimeout = 60 * time.Second
+)
+
+// Probes of the network self-test, as named in NetTestSummary.Failed
+const (
+	NetProbeAPI       = "api"
+	NetProbeWebSocket = "websocket"
+	NetProbeCDN       = "cdn"
+	NetProbePorts     = "ports"
+)
+
+// NetTestConfig configures RunNetTest
+type NetTestConfig struct {
+	Client  *api.Client
+	AgentID string
+	// CDNURL is the object downloaded to measure CDN throughput
+	CDNURL string
+	// Samples is the number of timed requests and WebSocket round trips
+	Samples int
+	// Bytes caps the API and CDN downloads
+	Bytes int64
+	// Ports are checked for reachability from the platform; none skips the check
+	Ports []int
+}
+
+// LatencyStats summarizes timed samples
+type LatencyStats struct {
+	Samples  int     `json:"samples"`
+	MinMs    float64 `json:"min_ms"`
+	MedianMs float64 `json:"median_ms"`
+	MaxMs    float64 `json:"max_ms"`
+}
+
+// NetProbe is the outcome of one probe of the network self-test
+type NetProbe struct {
+	Target string `json:"target"`
+	// ConnectMs is the time to open the WebSocket
+	ConnectMs float64       `json:"connect_ms,omitempty"`
+	Latency   *LatencyStats `json:"latency,omitempty"`
+	// Bytes downloaded in DownloadMs, for throughput
+	Bytes          int64   `json:"bytes,omitempty"`
+	DownloadMs     float64 `json:"download_ms,omitempty"`
+	ThroughputMbps float64 `json:"throughput_mbps,omitempty"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// NetTestResult is the outcome of 'ggo agent nettest'. The latest result is
+// kept in the state directory, where the running agent picks it up and
+// attaches its summary to the next status report.
+type NetTestResult struct {
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs float64   `json:"duration_ms"`
+	API        NetProbe  `json:"api"`
+	WebSocket  NetProbe  `json:"websocket"`
+	CDN        NetProbe  `json:"cdn"`
+	// Address is the address of this host the platform connected to for Ports
+	Address    string          `json:"address,omitempty"`
+	Ports      []api.PortReach `json:"ports,omitempty"`
+	PortsError string          `json:"ports_error,omitempty"`
+	// ReportedAt is set once the summary was delivered with a status report
+	ReportedAt *time.Time `json:"reported_at,omitempty"`
+}
+
+// RunNetTest measures the latency and throughput of the agent's links to the
+// platform API and the CDN, and optionally whether the platform can reach
+// cfg.Ports. Failed probes are recorded in the result rather than returned.
+func RunNetTest(ctx context.Context, cfg NetTestConfig) *NetTestResult {
+	if cfg.Samples <= 0 {
+		cfg.Samples = DefaultNetTestSamples
+	}
+	if cfg.Bytes <= 0 {
+		cfg.Bytes = DefaultNetTestBytes
+	}
+	result := &NetTestResult{StartedAt: time.Now()}
+	result.API = probeAPI(ctx, cfg)
+	result.WebSocket = probeWebSocket(ctx, cfg)
+	result.CDN = probeCDN(ctx, cfg)
+	if len(cfg.Ports) > 0 {
+		probeCtx, cancel := context.WithTimeout(ctx, netTestProbeTimeout)
+		resp, err := cfg.Client.CheckAgentPorts(probeCtx, cfg.AgentID, &api.PortReachRequest{Ports: cfg.Ports})
+		cancel()
+		if err != nil {
+			result.PortsError = err.Error()
+		} else {
+			result.Address = resp.Address
+			result.Ports = resp.Ports
+		}
+	}
+	result.DurationMs = milliseconds(time.Since(result.StartedAt))
+	klog.Infof("Network self-test finished: api_ms=%.1f ws_ms=%.1f cdn_mbps=%.1f duration_ms=%.0f",
+		medianMs(result.API.Latency), medianMs(result.WebSocket.Latency), result.CDN.ThroughputMbps, result.DurationMs)
+	return result
+}
+
+func probeAPI(ctx context.Context, cfg NetTestConfig) NetProbe {
+	ctx, cancel := context.WithTimeout(ctx, netTestProbeTimeout)
+	defer cancel()
+
+	probe := NetProbe{Target: cfg.Client.GetBaseURL()}
+	var samples []time.Duration
+	for range cfg.Samples {
+		start := time.Now()
+		if err := cfg.Client.PingAgentNetTest(ctx, cfg.AgentID); err != nil {
+			probe.Error = err.Error()
+			return probe
+		}
+		samples = append(samples, time.Since(start))
+	}
+	probe.Latency = latencyStats(samples)
+
+	start := time.Now()
+	n, err := cfg.Client.DownloadAgentNetTest(ctx, cfg.AgentID, cfg.Bytes, io.Discard)
+	probe.setDownload(n, time.Since(start))
+	if err != nil {
+		probe.Error = err.Error()
+	}
+	return probe
+}
+
+func probeWebSocket(ctx context.Context, cfg NetTestConfig) NetProbe {
+	ctx, cancel := context.WithTimeout(ctx, netTestProbeTimeout)
+	defer cancel()
+
+	probe := NetProbe{Target: cfg.Client.GetBaseURL()}
+	start := time.Now()
+	conn, err := cfg.Client.DialAgentNetTest(ctx, cfg.AgentID)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	defer func() { _ = conn.Close() }()
+	probe.ConnectMs = milliseconds(time.Since(start))
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var samples []time.Duration
+	for i := range cfg.Samples {
+		msg := fmt.Sprintf("ping %d", i)
+		start := time.Now()
+		if err := websocket.Message.Send(conn, msg); err != nil {
+			probe.Error = err.Error()
+			return probe
+		}
+		var echo string
+		if err := websocket.Message.Receive(conn, &echo); err != nil {
+			probe.Error = err.Error()
+			return probe
+		}
+		if echo != msg {
+			probe.Error = fmt.Sprintf("unexpected echo %q", echo)
+			return probe
+		}
+		samples = append(samples, time.Since(start))
+	}
+	probe.Latency = latencyStats(samples)
+	return probe
+}
+
+func probeCDN(ctx context.Context, cfg NetTestConfig) NetProbe {
+	ctx, cancel := context.WithTimeout(ctx, netTestProbeTimeout)
+	defer cancel()
+
+	probe := NetProbe{Target: cfg.CDNURL}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.CDNURL, nil)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		probe.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		return probe
+	}
+	n, err := io.CopyN(io.Discard, resp.Body, cfg.Bytes)
+	probe.setDownload(n, time.Since(start))
+	if err != nil && !errors.Is(err, io.EOF) {
+		probe.Error = err.Error()
+	}
+	return probe
+}
+
+func (p *NetProbe) setDownload(n int64, elapsed time.Duration) {
+	p.Bytes = n
+	p.DownloadMs = milliseconds(elapsed)
+	if elapsed > 0 {
+		p.ThroughputMbps = float64(n) * 8 / 1e6 / elapsed.Seconds()
+	}
+}
+
+func latencyStats(samples []time.Duration) *LatencyStats {
+	if len(samples) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	return &LatencyStats{
+		Samples:  len(sorted),
+		MinMs:    milliseconds(sorted[0]),
+		MedianMs: milliseconds(sorted[len(sorted)/2]),
+		MaxMs:    milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+func medianMs(stats *LatencyStats) float64 {
+	if stats == nil {
+		return 0
+	}
+	return stats.MedianMs
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Summary condenses the result for the status report
+func (r *NetTestResult) Summary() *api.NetTestSummary {
+	summary := &api.NetTestSummary{
+		RanAt:             r.StartedAt,
+		APILatencyMs:      medianMs(r.API.Latency),
+		APIThroughputMbps: r.API.ThroughputMbps,
+		WSConnectMs:       r.WebSocket.ConnectMs,
+		WSRoundTripMs:     medianMs(r.WebSocket.Latency),
+		CDNThroughputMbps: r.CDN.ThroughputMbps,
+	}
+	for _, p := range []struct {
+		name  string
+		probe NetProbe
+	}{{NetProbeAPI, r.API}, {NetProbeWebSocket, r.WebSocket}, {NetProbeCDN, r.CDN}} {
+		if p.probe.Error != "" {
+			summary.Failed = append(summary.Failed, p.name)
+		}
+	}
+	if r.PortsError != "" {
+		summary.Failed = append(summary.Failed, NetProbePorts)
+	}
+	for _, p := range r.Ports {
+		if !p.Reachable {
+			summary.UnreachablePorts = append(summary.UnreachablePorts, p.Port)
+		}
+	}
+	return summary
+}
+
+// NetTestPath returns the path of the latest network self-test result
+func NetTestPath(paths *platform.Paths) string {
+	return filepath.Join(paths.StateDir(), netTestFileName)
+}
+
+// SaveNetTest stores result as the latest network self-test, to be attached
+// to the running agent's next status report
+func SaveNetTest(paths *platform.Paths, result *NetTestResult) error {
+	return utils.SaveJSON(NetTestPath(paths), result, 0644)
+}
+
+// LoadNetTest returns the latest network self-test; nil if none was run
+func LoadNetTest(paths *platform.Paths) (*NetTestResult, error) {
+	return utils.LoadJSON[NetTestResult](NetTestPath(paths))
+}
+
+// pendingNetTest returns the latest network self-test if its summary has not
+// been reported yet
+func (a *Agent) pendingNetTest() *NetTestResult {
+	result, err := LoadNetTest(a.paths)
+	if err != nil {
+		klog.Warningf("Failed to read network self-test: error=%v", err)
+		return nil
+	}
+	if result == nil || result.ReportedAt != nil {
+		return nil
+	}
+	return result
+}
+
+// netTestReported marks a network self-test as delivered, unless a newer
+// test replaced it in the meantime
+func (a *Agent) netTestReported(result *NetTestResult, now time.Time) {
+	latest, err := LoadNetTest(a.paths)
+	if err != nil || latest == nil || !latest.StartedAt.Equal(result.StartedAt) {
+		return
+	}
+	latest.ReportedAt = &now
+	if err := SaveNetTest(a.paths, latest); err != nil {
+		klog.Warningf("Failed to mark network self-test reported: error=%v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func newNetTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/agents/agent_1/nettest/ping", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gpugo_secret", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.SuccessResponse{Success: true})
+	})
+	mux.HandleFunc("/api/v1/agents/agent_1/nettest/download", func(w http.ResponseWriter, r *http.Request) {
+		size, _ := strconv.Atoi(r.URL.Query().Get("bytes"))
+		_, _ = w.Write(bytes.Repeat([]byte{'x'}, size))
+	})
+	mux.Handle("/api/v1/agents/agent_1/nettest/ws", websocket.Handler(func(conn *websocket.Conn) {
+		_, _ = io.Copy(conn, conn)
+	}))
+	mux.HandleFunc("/api/v1/agents/agent_1/nettest/reach", func(w http.ResponseWriter, r *http.Request) {
+		var req api.PortReachRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		resp := api.PortReachResponse{Address: "203.0.113.7"}
+		for _, port := range req.Ports {
+			resp.Ports = append(resp.Ports, api.PortReach{Port: port, Reachable: port != 9002})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc("/cdn/test.bin", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte{'y'}, 4096))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunNetTest(t *testing.T) {
+	server := newNetTestServer(t)
+	client := api.NewClient(api.WithBaseURL(server.URL), api.WithAgentSecret("gpugo_secret"))
+
+	result := RunNetTest(t.Context(), NetTestConfig{
+		Client:  client,
+		AgentID: "agent_1",
+		CDNURL:  server.URL + "/cdn/test.bin",
+		Samples: 3,
+		Bytes:   1024,
+		Ports:   []int{9001, 9002},
+	})
+
+	assert.Empty(t, result.API.Error)
+	require.NotNil(t, result.API.Latency)
+	assert.Equal(t, 3, result.API.Latency.Samples)
+	assert.Equal(t, int64(1024), result.API.Bytes)
+
+	assert.Empty(t, result.WebSocket.Error)
+	require.NotNil(t, result.WebSocket.Latency)
+	assert.Equal(t, 3, result.WebSocket.Latency.Samples)
+	assert.Positive(t, result.WebSocket.ConnectMs)
+
+	assert.Empty(t, result.CDN.Error)
+	assert.Equal(t, int64(1024), result.CDN.Bytes, "the download stops at the configured size")
+
+	assert.Equal(t, "203.0.113.7", result.Address)
+	summary := result.Summary()
+	assert.Empty(t, summary.Failed)
+	assert.Equal(t, []int{9002}, summary.UnreachablePorts)
+
+	result = RunNetTest(t.Context(), NetTestConfig{Client: client, AgentID: "agent_1", CDNURL: server.URL + "/cdn/missing.bin", Samples: 1, Bytes: 1024})
+	assert.Equal(t, []string{NetProbeCDN}, result.Summary().Failed)
+	assert.Nil(t, result.Ports, "ports are only checked on request")
+}
+
+func TestAgent_NetTestReported(t *testing.T) {
+	paths := platform.DefaultPaths().WithStateDir(t.TempDir())
+	a := &Agent{paths: paths}
+	assert.Nil(t, a.pendingNetTest())
+
+	first := &NetTestResult{StartedAt: time.Now().Add(-time.Minute)}
+	require.NoError(t, SaveNetTest(paths, first))
+	pending := a.pendingNetTest()
+	require.NotNil(t, pending)
+
+	// A newer test run before the report was delivered stays pending
+	second := &NetTestResult{StartedAt: time.Now()}
+	require.NoError(t, SaveNetTest(paths, second))
+	a.netTestReported(pending, time.Now())
+	assert.NotNil(t, a.pendingNetTest())
+
+	a.netTestReported(a.pendingNetTest(), time.Now())
+	assert.Nil(t, a.pendingNetTest())
+	stored, err := LoadNetTest(paths)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.ReportedAt)
+}
@@ -4,45 +4,54 @@ import (
 	"net"
 )
 
-// getNetworkIPs returns the list of non-loopback IP addresses
+// getNetworkIPs returns the list of non-loopback IP addresses, IPv4 first
 func getNetworkIPs() []string {
-	var ips []string
-
 	interfaces, err := net.Interfaces()
 	if err != nil {
-		return ips
+		return nil
 	}
 
+	var addrs []net.Addr
 	for _, iface := range interfaces {
 		// Skip loopback and down interfaces
 		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
 			continue
 		}
 
-		addrs, err := iface.Addrs()
+		ifaceAddrs, err := iface.Addrs()
 		if err != nil {
 			continue
 		}
+		addrs = append(addrs, ifaceAddrs...)
+	}
+
+	return networkIPs(addrs)
+}
+
+// networkIPs picks the addresses clients can connect to, IPv4 addresses
+// first and then IPv6 ones. Link-local IPv6 addresses are left out: they
+// only work with a zone that is meaningless on the client.
+func networkIPs(addrs []net.Addr) []string {
+	var ipv4, ipv6 []string
+	for _, addr := range addrs {
+		var ip net.IP
+		switch v := addr.(type) {
+		case *net.IPNet:
+			ip = v.IP
+		case *net.IPAddr:
+			ip = v.IP
+		}
+
+		if ip == nil || ip.IsLoopback() {
+			continue
+		}
 
-		for _, addr := range addrs {
-			var ip net.IP
-			switch v := addr.(type) {
-			case *net.IPNet:
-				ip = v.IP
-			case *net.IPAddr:
-				ip = v.IP
-			}
-
-			if ip == nil || ip.IsLoopback() {
-				continue
-			}
-
-			// Only include IPv4 addresses for now
-			if ip.To4() != nil {
-				ips = append(ips, ip.String())
-			}
+		if ip.To4() != nil {
+			ipv4 = append(ipv4, ip.String())
+		} else if ip.IsGlobalUnicast() {
+			ipv6 = append(ipv6, ip.String())
 		}
 	}
 
-	return ips
+	return append(ipv4, ipv6...)
 }
@@ -0,0 +1,25 @@
+package agent
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkIPs(t *testing.T) {
+	ipNet := func(s string) net.Addr {
+		return &net.IPNet{IP: net.ParseIP(s)}
+	}
+	addrs := []net.Addr{
+		ipNet("2001:db8::10"),
+		ipNet("fe80::1"),
+		ipNet("192.168.1.10"),
+		ipNet("::1"),
+		ipNet("127.0.0.1"),
+		&net.IPAddr{IP: net.ParseIP("10.0.0.5")},
+		ipNet("fd00::5"),
+	}
+
+	assert.Equal(t, []string{"192.168.1.10", "10.0.0.5", "2001:db8::10", "fd00::5"}, networkIPs(addrs))
+}
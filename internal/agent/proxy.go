@@ -0,0 +1,815 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	proxyDialTimeout  = 5 * time.Second
+	proxyProbeTimeout = 500 * time.Millisecond
+	// proxyHandshakeTimeout bounds the TLS handshake of a client connection
+	proxyHandshakeTimeout = 10 * time.Second
+
+	// proxyPortsFile persists the worker -> backend port mapping in the state
+	// dir so restarted agents hand running workers the same port
+	proxyPortsFile = "proxy-ports.json"
+)
+
+// usageKey identifies who consumed a worker: the share code (when it can be
+// attributed) and the remote client IP
+type usageKey struct {
+	shareCode string
+	clientIP  string
+}
+
+// proxySession tracks one proxied client connection. Byte counters are updated
+// lock-free by the copy goroutines; the reported* cursors are guarded by
+// connProxy.mu and record what has already been included in a status report.
+type proxySession struct {
+	key        usageKey
+	client     net.Conn
+	backend    net.Conn
+	clientPort int
+	localPort  int // source port used towards the worker, as seen by the worker
+	started    time.Time
+	bytesIn    atomic.Int64 // client -> worker
+	bytesOut   atomic.Int64 // worker -> client
+	closedAt   atomic.Pointer[time.Time]
+
+	counted     bool
+	quotaEnded  bool // closed for exceeding the share quota
+	reportedIn  int64
+	reportedOut int64
+	reportedAt  time.Time
+}
+
+// shareQuota is the quota of a share as last received from the platform
+type shareQuota struct {
+	api.ShareQuotaState
+	receivedAt time.Time
+}
+
+// proxyListener accepts client connections on a worker's public ListenPort
+type proxyListener struct {
+	workerID    string
+	listenPort  int
+	backendPort int
+	shareCode   string
+	// refuse is set while the worker is kept warm: the port is held and
+	// sessions are refused until the worker is enabled
+	refuse bool
+	ln     net.Listener
+}
+
+// connProxy sits in front of each worker's ListenPort and forwards traffic to
+// the worker on a loopback port, attributing bytes and session time per client
+// IP and share code so they can be reported with the worker status.
+//
+// With certs set the proxy also terminates TLS, so the worker protocol, which
+// is plain TCP, is encrypted between the client and the agent.
+//
+// The worker binary has no bind-address flag, so its backend port may also be
+// reachable from other hosts. Such connections bypass accounting; the proxy
+// probes for this once per worker and warns so the port can be firewalled.
+type connProxy struct {
+	mu           sync.Mutex
+	wg           sync.WaitGroup
+	stopped      bool
+	portsPath    string
+	desired      map[string]api.WorkerConfig // workerID -> enabled worker config from the last Sync
+	listeners    map[string]*proxyListener   // workerID -> listener
+	backendPorts map[string]int              // workerID -> loopback port the worker listens on
+	probed       map[string]bool             // workerID -> backend exposure already checked
+	sessions     map[string][]*proxySession  // workerID -> sessions not yet fully reported
+	carry        map[string][]api.ShareUsage
+	quotas       map[string]shareQuota // share code -> quota
+	certs        *certManager          // nil serves plain TCP
+	listen       func(port int) (net.Listener, error)
+}
+
+// newConnProxy creates a proxy whose backend port mapping is persisted at
+// portsPath (empty keeps it in memory only)
+func newConnProxy(portsPath string) *connProxy {
+	p := &connProxy{
+		portsPath:    portsPath,
+		desired:      make(map[string]api.WorkerConfig),
+		listeners:    make(map[string]*proxyListener),
+		backendPorts: make(map[string]int),
+		probed:       make(map[string]bool),
+		sessions:     make(map[string][]*proxySession),
+		carry:        make(map[string][]api.ShareUsage),
+		quotas:       make(map[string]shareQuota),
+		listen: func(port int) (net.Listener, error) {
+			return net.Listen("tcp", fmt.Sprintf(":%d", port))
+		},
+	}
+	if portsPath != "" {
+		ports, err := utils.LoadJSON[map[string]int](portsPath)
+		if err != nil {
+			klog.Warningf("Failed to load proxy backend ports, reallocating: path=%s error=%v", portsPath, err)
+		} else if ports != nil {
+			p.backendPorts = *ports
+		}
+	}
+	return p
+}
+
+// backendPort returns the stable loopback port the worker should listen on
+// instead of its public ListenPort. Ports survive agent restarts, so a running
+// worker keeps the port it was started with.
+func (p *connProxy) backendPort(workerID string) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if port, ok := p.backendPorts[workerID]; ok {
+		return port, nil
+	}
+	inUse := make(map[int]bool, len(p.backendPorts))
+	for _, port := range p.backendPorts {
+		inUse[port] = true
+	}
+	// The kernel picks a free ephemeral port; the worker binds it shortly
+	// after, so only a first allocation can race with another process
+	var port int
+	for range 5 {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return 0, fmt.Errorf("failed to allocate backend port: %w", err)
+		}
+		port = ln.Addr().(*net.TCPAddr).Port
+		_ = ln.Close()
+		if !inUse[port] {
+			break
+		}
+	}
+	if inUse[port] {
+		return 0, fmt.Errorf("failed to allocate backend port: no free port")
+	}
+	p.backendPorts[workerID] = port
+	p.savePortsLocked()
+	return port, nil
+}
+
+func (p *connProxy) savePortsLocked() {
+	if p.portsPath == "" {
+		return
+	}
+	if err := utils.SaveJSON(p.portsPath, p.backendPorts, 0644); err != nil {
+		klog.Warningf("Failed to save proxy backend ports: path=%s error=%v", p.portsPath, err)
+	}
+}
+
+// Sync starts, restarts or stops listeners so that exactly the enabled and
+// warm workers are proxied, and forgets all state of workers that were
+// removed. Sessions of warm workers are refused.
+func (p *connProxy) Sync(workers []api.WorkerConfig) {
+	known := make(map[string]bool, len(workers))
+	desired := make(map[string]api.WorkerConfig, len(workers))
+	for _, w := range workers {
+		known[w.WorkerID] = true
+		if (w.Enabled || keptWarm(w)) && w.ListenPort > 0 {
+			desired[w.WorkerID] = w
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	p.desired = desired
+
+	for workerID, l := range p.listeners {
+		w, ok := desired[workerID]
+		if ok && w.ListenPort == l.listenPort {
+			l.shareCode = singleShareCode(w.ShareCodes)
+			l.refuse = keptWarm(w)
+			continue
+		}
+		klog.Infof("Stopping connection proxy: worker_id=%s port=%d", workerID, l.listenPort)
+		_ = l.ln.Close()
+		delete(p.listeners, workerID)
+	}
+
+	pruned := false
+	for workerID := range p.backendPorts {
+		if !known[workerID] {
+			delete(p.backendPorts, workerID)
+			pruned = true
+		}
+	}
+	if pruned {
+		p.savePortsLocked()
+	}
+	for workerID, sessions := range p.sessions {
+		if known[workerID] {
+			continue
+		}
+		for _, s := range sessions {
+			_ = s.client.Close()
+			_ = s.backend.Close()
+		}
+		delete(p.sessions, workerID)
+	}
+	for workerID := range p.carry {
+		if !known[workerID] {
+			delete(p.carry, workerID)
+		}
+	}
+	for workerID := range p.probed {
+		if !known[workerID] {
+			delete(p.probed, workerID)
+		}
+	}
+	if p.certs != nil {
+		p.certs.Prune(known)
+	}
+
+	p.bindLocked()
+}
+
+// Retry binds listeners that failed to start earlier, e.g. because the port
+// was still held by a previous process, checks new workers for a backend
+// port reachable from other hosts and renews expiring TLS certificates
+func (p *connProxy) Retry() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.bindLocked()
+	var unprobed []*proxyListener
+	served := make([]string, 0, len(p.listeners))
+	for workerID, l := range p.listeners {
+		served = append(served, workerID)
+		if !p.probed[workerID] {
+			unprobed = append(unprobed, l)
+		}
+	}
+	p.mu.Unlock()
+
+	if p.certs != nil {
+		for _, workerID := range served {
+			if _, err := p.certs.Get(workerID); err != nil {
+				klog.Errorf("Failed to renew worker certificate: worker_id=%s error=%v", workerID, err)
+			}
+		}
+	}
+
+	for _, l := range unprobed {
+		if checked := probeBackendExposure(l.workerID, l.backendPort); checked {
+			p.mu.Lock()
+			p.probed[l.workerID] = true
+			p.mu.Unlock()
+		}
+	}
+}
+
+// TLSFingerprint returns the fingerprint of the certificate served for the
+// worker, empty when TLS termination is off or no certificate exists yet
+func (p *connProxy) TLSFingerprint(workerID string) string {
+	if p.certs == nil {
+		return ""
+	}
+	return p.certs.Fingerprint(workerID)
+}
+
+// UpdateShareCodes refreshes the share codes used to attribute new
+// connections to a worker
+func (p *connProxy) UpdateShareCodes(workerID string, codes []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if w, ok := p.desired[workerID]; ok {
+		w.ShareCodes = codes
+		p.desired[workerID] = w
+	}
+	if l, ok := p.listeners[workerID]; ok {
+		l.shareCode = singleShareCode(codes)
+	}
+}
+
+// bindLocked starts a listener for every desired worker that has none
+func (p *connProxy) bindLocked() {
+	for workerID, w := range p.desired {
+		if _, ok := p.listeners[workerID]; ok {
+			continue
+		}
+		backend, ok := p.backendPorts[workerID]
+		if !ok {
+			klog.Warningf("No backend port allocated for proxied worker: worker_id=%s", workerID)
+			continue
+		}
+		if p.certs != nil {
+			// Obtain the certificate up front so its fingerprint can be
+			// reported before the first client connects
+			if _, err := p.certs.Get(workerID); err != nil {
+				klog.Errorf("Failed to obtain worker certificate, will retry: worker_id=%s error=%v", workerID, err)
+				continue
+			}
+		}
+		ln, err := p.listen(w.ListenPort)
+		if err != nil {
+			klog.Errorf("Failed to start connection proxy, will retry: worker_id=%s port=%d error=%v", workerID, w.ListenPort, err)
+			continue
+		}
+		if p.certs != nil {
+			ln = tls.NewListener(ln, &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+					return p.certs.Get(workerID)
+				},
+			})
+		}
+		l := &proxyListener{
+			workerID:    workerID,
+			listenPort:  w.ListenPort,
+			backendPort: backend,
+			shareCode:   singleShareCode(w.ShareCodes),
+			refuse:      keptWarm(w),
+			ln:          ln,
+		}
+		p.listeners[workerID] = l
+		p.wg.Add(1)
+		go p.serve(l)
+		klog.Infof("Connection proxy started: worker_id=%s port=%d backend=127.0.0.1:%d tls=%v", workerID, w.ListenPort, backend, p.certs != nil)
+	}
+}
+
+// probeBackendExposure warns when a worker's backend port accepts connections
+// on a non-loopback address. It returns false while the worker is not yet
+// listening, so the check is repeated later.
+func probeBackendExposure(workerID string, backendPort int) bool {
+	port := strconv.Itoa(backendPort)
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", port), proxyProbeTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+
+	hostIP := nonLoopbackIP()
+	if hostIP == "" {
+		return true
+	}
+	conn, err = net.DialTimeout("tcp", net.JoinHostPort(hostIP, port), proxyProbeTimeout)
+	if err != nil {
+		return true
+	}
+	_ = conn.Close()
+	klog.Warningf("Worker backend port is reachable beyond loopback and bypasses usage accounting; "+
+		"block external access to it with a firewall rule: worker_id=%s port=%d", workerID, backendPort)
+	return true
+}
+
+// nonLoopbackIP returns an address of the host other than loopback,
+// preferring IPv4 on dual-stack hosts
+func nonLoopbackIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	var ipv6 string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP.String()
+		}
+		if ipv6 == "" {
+			ipv6 = ipNet.IP.String()
+		}
+	}
+	return ipv6
+}
+
+// singleShareCode returns the share code when it is unambiguous. The worker
+// protocol carries the code after the TCP handshake, so with several codes the
+// proxy can only attribute traffic to the client IP.
+func singleShareCode(codes []string) string {
+	if len(codes) == 1 {
+		return codes[0]
+	}
+	return ""
+}
+
+func (p *connProxy) serve(l *proxyListener) {
+	defer p.wg.Done()
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				klog.Warningf("Connection proxy accept failed: worker_id=%s error=%v", l.workerID, err)
+			}
+			return
+		}
+		p.mu.Lock()
+		shareCode, refuse := l.shareCode, l.refuse
+		p.mu.Unlock()
+		if refuse {
+			klog.Infof("Proxied connection refused, worker is kept warm: worker_id=%s client=%s", l.workerID, conn.RemoteAddr())
+			_ = conn.Close()
+			continue
+		}
+
+		p.wg.Add(1)
+		go p.handle(l.workerID, l.backendPort, shareCode, conn)
+	}
+}
+
+func (p *connProxy) handle(workerID string, backendPort int, shareCode string, client net.Conn) {
+	defer p.wg.Done()
+	defer func() { _ = client.Close() }()
+
+	if tlsConn, ok := client.(*tls.Conn); ok {
+		// Handshake before dialing the worker so scanners and plain TCP
+		// clients are neither forwarded nor counted as sessions
+		ctx, cancel := context.WithTimeout(context.Background(), proxyHandshakeTimeout)
+		err := tlsConn.HandshakeContext(ctx)
+		cancel()
+		if err != nil {
+			klog.V(4).Infof("TLS handshake failed: worker_id=%s client=%s error=%v", workerID, client.RemoteAddr(), err)
+			return
+		}
+	}
+
+	backend, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort)), proxyDialTimeout)
+	if err != nil {
+		klog.Warningf("Connection proxy failed to reach worker: worker_id=%s backend_port=%d error=%v", workerID, backendPort, err)
+		return
+	}
+	defer func() { _ = backend.Close() }()
+
+	clientIP, clientPort := splitAddr(client.RemoteAddr())
+	_, localPort := splitAddr(backend.LocalAddr())
+	now := time.Now()
+	s := &proxySession{
+		key:        usageKey{shareCode: shareCode, clientIP: clientIP},
+		client:     client,
+		backend:    backend,
+		clientPort: clientPort,
+		localPort:  localPort,
+		started:    now,
+		reportedAt: now,
+	}
+	p.mu.Lock()
+	if p.stopped {
+		// Stop already closed the registered sessions; this one was still dialing
+		p.mu.Unlock()
+		return
+	}
+	// Admit under the same lock that registers the session so concurrent
+	// connections of one consumer cannot all pass the concurrency check
+	if reason := p.admitLocked(s.key, now); reason != "" {
+		p.carry[workerID] = append(p.carry[workerID], api.ShareUsage{ShareCode: shareCode, ClientIP: clientIP, QuotaDenied: 1})
+		p.mu.Unlock()
+		klog.Infof("Proxied connection refused by share quota: worker_id=%s client=%s:%d share_code=%s reason=%s",
+			workerID, clientIP, clientPort, shareCode, reason)
+		return
+	}
+	p.sessions[workerID] = append(p.sessions[workerID], s)
+	p.mu.Unlock()
+	klog.V(4).Infof("Proxied connection opened: worker_id=%s client=%s:%d share_code=%s", workerID, clientIP, clientPort, shareCode)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(&countingWriter{w: backend, n: &s.bytesIn}, client)
+		closeWrite(backend)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(&countingWriter{w: client, n: &s.bytesOut}, backend)
+		closeWrite(client)
+		done <- struct{}{}
+	}()
+	<-done
+	<-done
+
+	closedAt := time.Now()
+	s.closedAt.Store(&closedAt)
+	klog.V(4).Infof("Proxied connection closed: worker_id=%s client=%s:%d in=%d out=%d duration=%s",
+		workerID, clientIP, clientPort, s.bytesIn.Load(), s.bytesOut.Load(), closedAt.Sub(s.started).Round(time.Second))
+}
+
+// DrainUsage returns usage accumulated since the previous drain for a worker,
+// aggregated per share code and client IP, and forgets closed sessions
+func (p *connProxy) DrainUsage(workerID string) []api.ShareUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	agg := make(map[usageKey]*api.ShareUsage)
+	var order []usageKey
+	add := func(u api.ShareUsage) {
+		key := usageKey{shareCode: u.ShareCode, clientIP: u.ClientIP}
+		acc, ok := agg[key]
+		if !ok {
+			acc = &api.ShareUsage{ShareCode: u.ShareCode, ClientIP: u.ClientIP}
+			agg[key] = acc
+			order = append(order, key)
+		}
+		acc.BytesIn += u.BytesIn
+		acc.BytesOut += u.BytesOut
+		acc.Sessions += u.Sessions
+		acc.DurationSeconds += u.DurationSeconds
+		acc.QuotaDenied += u.QuotaDenied
+	}
+
+	for _, u := range p.carry[workerID] {
+		add(u)
+	}
+	delete(p.carry, workerID)
+
+	remaining := p.sessions[workerID][:0]
+	for _, s := range p.sessions[workerID] {
+		until := now
+		closedAt := s.closedAt.Load()
+		if closedAt != nil {
+			until = *closedAt
+		}
+		in, out := s.bytesIn.Load(), s.bytesOut.Load()
+		u := api.ShareUsage{
+			ShareCode:       s.key.shareCode,
+			ClientIP:        s.key.clientIP,
+			BytesIn:         in - s.reportedIn,
+			BytesOut:        out - s.reportedOut,
+			DurationSeconds: until.Sub(s.reportedAt).Seconds(),
+		}
+		if !s.counted {
+			u.Sessions = 1
+			s.counted = true
+		}
+		s.reportedIn, s.reportedOut, s.reportedAt = in, out, until
+		add(u)
+		if closedAt == nil {
+			remaining = append(remaining, s)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(p.sessions, workerID)
+	} else {
+		p.sessions[workerID] = remaining
+	}
+
+	usage := make([]api.ShareUsage, 0, len(order))
+	for _, key := range order {
+		usage = append(usage, *agg[key])
+	}
+	return usage
+}
+
+// RestoreUsage puts back usage that could not be delivered so it is included
+// in the next report instead of being lost
+func (p *connProxy) RestoreUsage(workerID string, usage []api.ShareUsage) {
+	if len(usage) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.carry[workerID] = append(p.carry[workerID], usage...)
+}
+
+// RewriteConnections replaces loopback entries written by the worker (which
+// only sees the proxy) with the real client address, and adds what the proxy
+// knows about each session: share code, traffic and connect time
+func (p *connProxy) RewriteConnections(workerID string, conns []api.ConnectionInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	byLocalPort := make(map[int]*proxySession, len(p.sessions[workerID]))
+	for _, s := range p.sessions[workerID] {
+		byLocalPort[s.localPort] = s
+	}
+	for i := range conns {
+		ip := net.ParseIP(conns[i].ClientIP)
+		if ip == nil || !ip.IsLoopback() {
+			continue
+		}
+		if s, ok := byLocalPort[conns[i].ClientPort]; ok {
+			conns[i].ClientIP = s.key.clientIP
+			conns[i].ClientPort = s.clientPort
+			conns[i].ShareCode = s.key.shareCode
+			conns[i].BytesIn = s.bytesIn.Load()
+			conns[i].BytesOut = s.bytesOut.Load()
+			conns[i].ConnectedAt = s.started
+		}
+	}
+}
+
+// KillSession closes the open proxied session of a worker whose client
+// address matches sessionID (see api.ConnectionInfo.SessionID); returns false
+// if there is none
+func (p *connProxy) KillSession(workerID, sessionID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, s := range p.sessions[workerID] {
+		if s.closedAt.Load() != nil {
+			continue
+		}
+		conn := api.ConnectionInfo{ClientIP: s.key.clientIP, ClientPort: s.clientPort}
+		if conn.SessionID() != sessionID {
+			continue
+		}
+		_ = s.client.Close()
+		_ = s.backend.Close()
+		return true
+	}
+	return false
+}
+
+// UpdateQuotas replaces the share quotas and applies them to open sessions
+// at once, so that a lowered quota does not wait for the next check
+func (p *connProxy) UpdateQuotas(quotas map[string]api.ShareQuotaState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.quotas = make(map[string]shareQuota, len(quotas))
+	for code, q := range quotas {
+		if !q.IsZero() {
+			p.quotas[code] = shareQuota{ShareQuotaState: q, receivedAt: now}
+		}
+	}
+	p.enforceQuotasLocked(now)
+}
+
+// EnforceQuotas ends open sessions that outlasted their share's max session
+// duration or whose share used up its weekly GPU-hours
+func (p *connProxy) EnforceQuotas() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enforceQuotasLocked(time.Now())
+}
+
+func (p *connProxy) enforceQuotasLocked(now time.Time) {
+	if len(p.quotas) == 0 {
+		return
+	}
+	exhausted := make(map[string]bool, len(p.quotas))
+	for code, q := range p.quotas {
+		exhausted[code] = q.GPUHoursPerWeek > 0 && p.gpuHoursLocked(code, now) >= q.GPUHoursPerWeek
+	}
+	for workerID, sessions := range p.sessions {
+		for _, s := range sessions {
+			q, ok := p.quotas[s.key.shareCode]
+			if !ok || s.quotaEnded || s.closedAt.Load() != nil {
+				continue
+			}
+			var reason string
+			switch {
+			case exhausted[s.key.shareCode]:
+				reason = "weekly GPU-hours used up"
+			case q.MaxSessionMinutes > 0 && now.Sub(s.started) >= time.Duration(q.MaxSessionMinutes)*time.Minute:
+				reason = fmt.Sprintf("session exceeded %d minutes", q.MaxSessionMinutes)
+			default:
+				continue
+			}
+			s.quotaEnded = true
+			_ = s.client.Close()
+			_ = s.backend.Close()
+			p.carry[workerID] = append(p.carry[workerID], api.ShareUsage{ShareCode: s.key.shareCode, ClientIP: s.key.clientIP, QuotaDenied: 1})
+			klog.Infof("Proxied connection ended by share quota: worker_id=%s client=%s:%d share_code=%s reason=%s",
+				workerID, s.key.clientIP, s.clientPort, s.key.shareCode, reason)
+		}
+	}
+}
+
+// admitLocked returns why a new session of the consumer must be refused by
+// its share's quota, or "" to admit it
+func (p *connProxy) admitLocked(key usageKey, now time.Time) string {
+	q, ok := p.quotas[key.shareCode]
+	if key.shareCode == "" || !ok {
+		return ""
+	}
+	if q.MaxConcurrentSessions > 0 {
+		open := 0
+		for _, sessions := range p.sessions {
+			for _, s := range sessions {
+				if s.key == key && !s.quotaEnded && s.closedAt.Load() == nil {
+					open++
+				}
+			}
+		}
+		if open >= q.MaxConcurrentSessions {
+			return fmt.Sprintf("consumer already has %d open session(s)", open)
+		}
+	}
+	if q.GPUHoursPerWeek > 0 && p.gpuHoursLocked(key.shareCode, now) >= q.GPUHoursPerWeek {
+		return "weekly GPU-hours used up"
+	}
+	return ""
+}
+
+// gpuHoursLocked returns the GPU-hours a share has used this quota week: what
+// the platform accounted when it sent the quota plus the session time the
+// proxy has not reported yet. A quota received before the current week began
+// is stale, so only local session time since the week start counts then.
+func (p *connProxy) gpuHoursLocked(shareCode string, now time.Time) float64 {
+	q := p.quotas[shareCode]
+	weekStart := quotaWeekStart(now)
+	used := q.GPUHoursUsed
+	if q.receivedAt.Before(weekStart) {
+		used = 0
+	}
+	gpus := func(workerID string) float64 {
+		return float64(max(len(p.desired[workerID].GPUIDs), 1))
+	}
+	for workerID, sessions := range p.sessions {
+		for _, s := range sessions {
+			if s.key.shareCode != shareCode {
+				continue
+			}
+			until := now
+			if closedAt := s.closedAt.Load(); closedAt != nil {
+				until = *closedAt
+			}
+			from := s.reportedAt
+			if from.Before(weekStart) {
+				from = weekStart
+			}
+			if until.After(from) {
+				used += until.Sub(from).Hours() * gpus(workerID)
+			}
+		}
+	}
+	for workerID, usage := range p.carry {
+		for _, u := range usage {
+			if u.ShareCode == shareCode {
+				used += u.DurationSeconds / 3600 * gpus(workerID)
+			}
+		}
+	}
+	return used
+}
+
+// quotaWeekStart returns the start of the quota week containing t, Monday
+// 00:00 UTC
+func quotaWeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Stop closes all listeners and proxied connections and waits for them to finish
+func (p *connProxy) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	for workerID, l := range p.listeners {
+		_ = l.ln.Close()
+		delete(p.listeners, workerID)
+	}
+	for _, sessions := range p.sessions {
+		for _, s := range sessions {
+			_ = s.client.Close()
+			_ = s.backend.Close()
+		}
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// closeWrite half-closes conn; for TLS this sends close_notify
+func closeWrite(conn net.Conn) {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+		return
+	}
+	_ = conn.Close()
+}
+
+func splitAddr(addr net.Addr) (string, int) {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP.String(), tcp.Port
+	}
+	host, portStr, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String(), 0
+	}
+	port, _ := strconv.Atoi(portStr)
+	return host, port
+}
@@ -0,0 +1,377 @@
+package agent
+
+import (
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnProxy_ForwardsAndAccountsUsage(t *testing.T) {
+	proxy := newConnProxy("")
+	proxy.listen = func(int) (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	defer proxy.Stop()
+
+	backendPort, err := proxy.backendPort("worker-1")
+	require.NoError(t, err)
+	again, err := proxy.backendPort("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, backendPort, again, "backend port must be stable across config pulls")
+
+	// Echo server standing in for the worker
+	backend, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort)))
+	require.NoError(t, err)
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	proxy.Sync([]api.WorkerConfig{
+		{WorkerID: "worker-1", ListenPort: 9001, Enabled: true, ShareCodes: []string{"abc123"}},
+		{WorkerID: "worker-2", ListenPort: 9002, Enabled: false},
+	})
+	proxy.mu.Lock()
+	require.Len(t, proxy.listeners, 1)
+	addr := proxy.listeners["worker-1"].ln.Addr().String()
+	proxy.mu.Unlock()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	payload := []byte("hello worker")
+	_, err = conn.Write(payload)
+	require.NoError(t, err)
+	buf := make([]byte, len(payload))
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, payload, buf)
+
+	// Active session is reported incrementally
+	usage := proxy.DrainUsage("worker-1")
+	require.Len(t, usage, 1)
+	assert.Equal(t, "abc123", usage[0].ShareCode)
+	assert.Equal(t, "127.0.0.1", usage[0].ClientIP)
+	assert.Equal(t, int64(len(payload)), usage[0].BytesIn)
+	assert.Equal(t, int64(len(payload)), usage[0].BytesOut)
+	assert.Equal(t, 1, usage[0].Sessions)
+
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		proxy.mu.Lock()
+		defer proxy.mu.Unlock()
+		sessions := proxy.sessions["worker-1"]
+		return len(sessions) == 1 && sessions[0].closedAt.Load() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Session already counted and bytes already reported; only remaining duration
+	usage = proxy.DrainUsage("worker-1")
+	require.Len(t, usage, 1)
+	assert.Zero(t, usage[0].Sessions)
+	assert.Zero(t, usage[0].BytesIn)
+	assert.Empty(t, proxy.DrainUsage("worker-1"))
+
+	// Undelivered usage is carried to the next drain
+	proxy.RestoreUsage("worker-1", []api.ShareUsage{{ClientIP: "10.0.0.1", BytesIn: 5, Sessions: 1}})
+	usage = proxy.DrainUsage("worker-1")
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(5), usage[0].BytesIn)
+
+	proxy.Sync(nil)
+	proxy.mu.Lock()
+	assert.Empty(t, proxy.listeners)
+	proxy.mu.Unlock()
+}
+
+func TestConnProxy_PersistsPortsAndPrunesRemovedWorkers(t *testing.T) {
+	portsPath := filepath.Join(t.TempDir(), proxyPortsFile)
+	proxy := newConnProxy(portsPath)
+	port1, err := proxy.backendPort("worker-1")
+	require.NoError(t, err)
+	port2, err := proxy.backendPort("worker-2")
+	require.NoError(t, err)
+	assert.NotEqual(t, port1, port2)
+
+	// A restarted agent hands running workers the same ports
+	restarted := newConnProxy(portsPath)
+	again, err := restarted.backendPort("worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, port1, again)
+
+	restarted.RestoreUsage("worker-2", []api.ShareUsage{{ClientIP: "10.0.0.1", BytesIn: 5}})
+	restarted.Sync([]api.WorkerConfig{{WorkerID: "worker-1", Enabled: false}})
+	restarted.mu.Lock()
+	assert.NotContains(t, restarted.backendPorts, "worker-2")
+	assert.NotContains(t, restarted.carry, "worker-2")
+	restarted.mu.Unlock()
+
+	reloaded := newConnProxy(portsPath)
+	assert.Equal(t, map[string]int{"worker-1": port1}, reloaded.backendPorts)
+}
+
+func TestConnProxy_RetriesFailedListenersAndRefreshesShareCodes(t *testing.T) {
+	proxy := newConnProxy("")
+	defer proxy.Stop()
+	busy := true
+	proxy.listen = func(int) (net.Listener, error) {
+		if busy {
+			return nil, errors.New("address already in use")
+		}
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	_, err := proxy.backendPort("worker-1")
+	require.NoError(t, err)
+
+	proxy.Sync([]api.WorkerConfig{{WorkerID: "worker-1", ListenPort: 9001, Enabled: true, ShareCodes: []string{"abc123"}}})
+	proxy.mu.Lock()
+	assert.Empty(t, proxy.listeners)
+	proxy.mu.Unlock()
+
+	busy = false
+	proxy.Retry()
+	proxy.mu.Lock()
+	require.Contains(t, proxy.listeners, "worker-1")
+	assert.Equal(t, "abc123", proxy.listeners["worker-1"].shareCode)
+	proxy.mu.Unlock()
+
+	proxy.UpdateShareCodes("worker-1", []string{"def456"})
+	proxy.mu.Lock()
+	assert.Equal(t, "def456", proxy.listeners["worker-1"].shareCode)
+	assert.Equal(t, []string{"def456"}, proxy.desired["worker-1"].ShareCodes)
+	proxy.mu.Unlock()
+}
+
+func TestConnProxy_RefusesWarmWorkerSessions(t *testing.T) {
+	proxy := newConnProxy("")
+	proxy.listen = func(int) (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	defer proxy.Stop()
+
+	backendPort, err := proxy.backendPort("worker-1")
+	require.NoError(t, err)
+	backend, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort)))
+	require.NoError(t, err)
+	defer backend.Close()
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	warm := api.WorkerConfig{WorkerID: "worker-1", ListenPort: 9001, Warm: true, ShareCodes: []string{"abc123"}}
+	proxy.Sync([]api.WorkerConfig{warm})
+	proxy.mu.Lock()
+	require.Contains(t, proxy.listeners, "worker-1", "the port of a warm worker is held")
+	addr := proxy.listeners["worker-1"].ln.Addr().String()
+	proxy.mu.Unlock()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "the session is refused")
+	_ = conn.Close()
+	assert.Zero(t, accepted.Load(), "a refused session never reaches the worker")
+	assert.Empty(t, proxy.DrainUsage("worker-1"))
+
+	// Enabled: the same listener forwards at once
+	warm.Enabled = true
+	proxy.Sync([]api.WorkerConfig{warm})
+	proxy.mu.Lock()
+	assert.Equal(t, addr, proxy.listeners["worker-1"].ln.Addr().String())
+	proxy.mu.Unlock()
+	conn, err = net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	buf := make([]byte, 5)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf))
+
+	// A forced stop releases the port
+	warm.Enabled, warm.ForceStop = false, true
+	proxy.Sync([]api.WorkerConfig{warm})
+	proxy.mu.Lock()
+	assert.Empty(t, proxy.listeners)
+	proxy.mu.Unlock()
+}
+
+func TestSingleShareCode(t *testing.T) {
+	assert.Equal(t, "abc", singleShareCode([]string{"abc"}))
+	assert.Empty(t, singleShareCode([]string{"abc", "def"}))
+	assert.Empty(t, singleShareCode(nil))
+}
+
+func TestConnProxy_KillSession(t *testing.T) {
+	proxy := newConnProxy("")
+	proxy.listen = func(int) (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	defer proxy.Stop()
+
+	backendPort, err := proxy.backendPort("worker-1")
+	require.NoError(t, err)
+	backend, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort)))
+	require.NoError(t, err)
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	proxy.Sync([]api.WorkerConfig{{WorkerID: "worker-1", ListenPort: 9001, Enabled: true}})
+	proxy.mu.Lock()
+	addr := proxy.listeners["worker-1"].ln.Addr().String()
+	proxy.mu.Unlock()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(conn, make([]byte, 4))
+	require.NoError(t, err)
+
+	sessionID := conn.LocalAddr().String()
+	assert.False(t, proxy.KillSession("worker-1", "127.0.0.1:1"))
+	assert.False(t, proxy.KillSession("worker-2", sessionID))
+	assert.True(t, proxy.KillSession("worker-1", sessionID))
+
+	// The client sees the connection end
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+	require.Eventually(t, func() bool {
+		return !proxy.KillSession("worker-1", sessionID)
+	}, 2*time.Second, 10*time.Millisecond, "a closed session cannot be killed again")
+}
+
+func TestConnProxy_EnforcesShareQuotas(t *testing.T) {
+	proxy := newConnProxy("")
+	proxy.listen = func(int) (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	defer proxy.Stop()
+
+	backendPort, err := proxy.backendPort("worker-1")
+	require.NoError(t, err)
+	backend, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(backendPort)))
+	require.NoError(t, err)
+	defer backend.Close()
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	proxy.Sync([]api.WorkerConfig{{WorkerID: "worker-1", ListenPort: 9001, Enabled: true, ShareCodes: []string{"abc123"}}})
+	proxy.UpdateQuotas(map[string]api.ShareQuotaState{
+		"abc123": {ShareQuota: api.ShareQuota{MaxConcurrentSessions: 1}},
+	})
+	proxy.mu.Lock()
+	addr := proxy.listeners["worker-1"].ln.Addr().String()
+	proxy.mu.Unlock()
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		require.NoError(t, conn.SetDeadline(time.Now().Add(2*time.Second)))
+		_, _ = conn.Write([]byte("ping"))
+		return conn
+	}
+	assertEchoes := func(conn net.Conn) {
+		_, err := io.ReadFull(conn, make([]byte, 4))
+		require.NoError(t, err)
+	}
+	assertClosed := func(conn net.Conn) {
+		_, err := conn.Read(make([]byte, 1))
+		assert.Error(t, err)
+	}
+
+	first := dial()
+	defer first.Close()
+	assertEchoes(first)
+
+	second := dial()
+	defer second.Close()
+	assertClosed(second)
+
+	usage := proxy.DrainUsage("worker-1")
+	require.Len(t, usage, 1)
+	assert.Equal(t, 1, usage[0].Sessions, "the refused session is not counted as used")
+	assert.Equal(t, 1, usage[0].QuotaDenied)
+
+	// An open session past the max duration is ended
+	proxy.mu.Lock()
+	proxy.sessions["worker-1"][0].started = time.Now().Add(-time.Hour)
+	proxy.mu.Unlock()
+	proxy.UpdateQuotas(map[string]api.ShareQuotaState{
+		"abc123": {ShareQuota: api.ShareQuota{MaxSessionMinutes: 30}},
+	})
+	assertClosed(first)
+
+	// No new sessions once the weekly GPU-hours are used up
+	proxy.UpdateQuotas(map[string]api.ShareQuotaState{
+		"abc123": {ShareQuota: api.ShareQuota{GPUHoursPerWeek: 10}, GPUHoursUsed: 10},
+	})
+	third := dial()
+	defer third.Close()
+	assertClosed(third)
+
+	proxy.UpdateQuotas(nil)
+	fourth := dial()
+	defer fourth.Close()
+	assertEchoes(fourth)
+}
+
+func TestQuotaWeekStart(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, quotaWeekStart(time.Date(2026, 10, 16, 13, 5, 0, 0, time.UTC)))
+	assert.Equal(t, monday, quotaWeekStart(monday))
+	assert.Equal(t, monday, quotaWeekStart(time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, monday.AddDate(0, 0, 7), quotaWeekStart(time.Date(2026, 10, 19, 0, 0, 1, 0, time.UTC)))
+}
@@ -0,0 +1,455 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+	"k8s.io/klog/v2"
+)
+
+// RelayProxyEnv names an HTTP(S) or SOCKS5 proxy URL used to reach the relay.
+// Without it the standard HTTPS_PROXY/NO_PROXY and ALL_PROXY variables apply.
+const RelayProxyEnv = "GGO_RELAY_PROXY"
+
+const (
+	// relayProtocol is sent first on every tunnel
+	relayProtocol = "GGO-RELAY/1"
+
+	// relayPoolSize is the number of idle tunnels kept open per worker. Each
+	// tunnel carries one client connection and is replaced as soon as it is
+	// used, so this bounds how many clients can connect at the same instant.
+	relayPoolSize = 4
+
+	relayDialTimeout      = 15 * time.Second
+	relayHandshakeTimeout = 15 * time.Second
+	relayMinBackoff       = time.Second
+	relayMaxBackoff       = time.Minute
+)
+
+// relayDialFunc opens a connection to the relay at addr
+type relayDialFunc func(ctx context.Context, addr string) (net.Conn, error)
+
+// relayWorker is the tunnel pool of one worker
+type relayWorker struct {
+	listenPort int
+	cancel     context.CancelFunc
+	idle       atomic.Int32 // tunnels registered with the relay and waiting for a client
+}
+
+// relayClient keeps outbound tunnels to the platform relay for workers shared
+// through it, for clients whose network cannot reach the GPU host directly.
+//
+// The agent opens the tunnels, so no inbound port is needed. Each tunnel
+// registers with "GGO-RELAY/1 <token> <workerID>" and waits; when a client
+// connects to the relay, the relay sends "CONNECT <client-addr>" on an idle
+// tunnel and from then on forwards raw bytes. The agent pipes the tunnel to
+// the worker's ListenPort on loopback, so the connection proxy and its TLS
+// termination apply exactly as for direct clients.
+type relayClient struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	addr    string
+	token   string
+	workers map[string]*relayWorker
+	dial    relayDialFunc
+	// local dials the worker's ListenPort
+	local func(ctx context.Context, port int) (net.Conn, error)
+}
+
+func newRelayClient(dial relayDialFunc) *relayClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &relayClient{
+		ctx:     ctx,
+		cancel:  cancel,
+		workers: make(map[string]*relayWorker),
+		dial:    dial,
+		local: func(ctx context.Context, port int) (net.Conn, error) {
+			d := net.Dialer{Timeout: proxyDialTimeout}
+			return d.DialContext(ctx, "tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		},
+	}
+}
+
+// Sync opens tunnels for enabled workers that are served through the relay
+// and closes those of workers that no longer are. A nil relay closes all.
+func (c *relayClient) Sync(relay *api.RelayConfig, workers []api.WorkerConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ctx.Err() != nil {
+		return
+	}
+
+	if relay == nil || relay.Addr != c.addr || relay.Token != c.token {
+		for workerID := range c.workers {
+			c.stopWorkerLocked(workerID)
+		}
+		c.addr, c.token = "", ""
+		if relay != nil {
+			c.addr, c.token = relay.Addr, relay.Token
+		}
+	}
+
+	desired := make(map[string]int)
+	if c.addr != "" {
+		for _, w := range workers {
+			if w.Enabled && w.Relay && w.ListenPort > 0 {
+				desired[w.WorkerID] = w.ListenPort
+			}
+		}
+	}
+	for workerID, rw := range c.workers {
+		if port, ok := desired[workerID]; !ok || port != rw.listenPort {
+			c.stopWorkerLocked(workerID)
+		}
+	}
+	for workerID, port := range desired {
+		if _, ok := c.workers[workerID]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(c.ctx)
+		rw := &relayWorker{listenPort: port, cancel: cancel}
+		c.workers[workerID] = rw
+		klog.Infof("Opening relay tunnels: worker_id=%s relay=%s tunnels=%d", workerID, c.addr, relayPoolSize)
+		for range relayPoolSize {
+			c.wg.Add(1)
+			go c.runTunnel(ctx, c.addr, c.token, workerID, rw)
+		}
+	}
+}
+
+// Connected reports whether the worker has at least one tunnel registered
+// with the relay
+func (c *relayClient) Connected(workerID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rw, ok := c.workers[workerID]
+	return ok && rw.idle.Load() > 0
+}
+
+// Stop closes all tunnels and waits for piped connections to finish
+func (c *relayClient) Stop() {
+	c.mu.Lock()
+	c.cancel()
+	for workerID := range c.workers {
+		c.stopWorkerLocked(workerID)
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+}
+
+func (c *relayClient) stopWorkerLocked(workerID string) {
+	if rw, ok := c.workers[workerID]; ok {
+		rw.cancel()
+		delete(c.workers, workerID)
+		klog.Infof("Closed relay tunnels: worker_id=%s", workerID)
+	}
+}
+
+// runTunnel keeps one tunnel slot of a worker open, reconnecting with
+// exponential backoff until ctx is cancelled
+func (c *relayClient) runTunnel(ctx context.Context, addr, token, workerID string, rw *relayWorker) {
+	defer c.wg.Done()
+	backoff := relayMinBackoff
+	for ctx.Err() == nil {
+		err := c.serveTunnel(ctx, addr, token, workerID, rw)
+		if err == nil {
+			backoff = relayMinBackoff
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		klog.V(2).Infof("Relay tunnel failed, retrying: worker_id=%s relay=%s backoff=%s error=%v", workerID, addr, backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, relayMaxBackoff)
+	}
+}
+
+// serveTunnel registers one tunnel and waits for a client. Once paired, the
+// tunnel is handed to a piping goroutine and serveTunnel returns so the slot
+// is refilled right away.
+func (c *relayClient) serveTunnel(ctx context.Context, addr, token, workerID string, rw *relayWorker) error {
+	dialCtx, cancel := context.WithTimeout(ctx, relayDialTimeout)
+	conn, err := c.dial(dialCtx, addr)
+	cancel()
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+
+	br := bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(relayHandshakeTimeout))
+	if _, err := fmt.Fprintf(conn, "%s %s %s\n", relayProtocol, token, workerID); err != nil {
+		stop()
+		_ = conn.Close()
+		return fmt.Errorf("failed to register tunnel: %w", err)
+	}
+	if line, err := readRelayLine(br); err != nil || line != "OK" {
+		stop()
+		_ = conn.Close()
+		if err != nil {
+			return fmt.Errorf("failed to register tunnel: %w", err)
+		}
+		return fmt.Errorf("relay rejected tunnel: %s", line)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	// Idle until the relay pairs the tunnel with a client; it may send
+	// PING lines meanwhile to keep middleboxes from dropping the connection
+	rw.idle.Add(1)
+	var clientAddr string
+	for {
+		line, err := readRelayLine(br)
+		if err != nil {
+			rw.idle.Add(-1)
+			stop()
+			_ = conn.Close()
+			return fmt.Errorf("tunnel closed: %w", err)
+		}
+		if addr, ok := strings.CutPrefix(line, "CONNECT "); ok {
+			clientAddr = addr
+			break
+		}
+	}
+	rw.idle.Add(-1)
+	stop()
+
+	local, err := c.local(ctx, rw.listenPort)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to connect to worker port %d: %w", rw.listenPort, err)
+	}
+	klog.V(4).Infof("Relay client connected: worker_id=%s client=%s", workerID, clientAddr)
+
+	// Paired tunnels outlive the worker's tunnel slots, so a worker that is
+	// disabled or unshared drains its clients instead of cutting them off
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		pipeRelay(c.ctx, &bufferedConn{Conn: conn, r: br}, local)
+	}()
+	return nil
+}
+
+// pipeRelay copies between the tunnel and the worker until both directions
+// are done or ctx is cancelled
+func pipeRelay(ctx context.Context, tunnel, local net.Conn) {
+	stop := context.AfterFunc(ctx, func() {
+		_ = tunnel.Close()
+		_ = local.Close()
+	})
+	defer stop()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(local, tunnel)
+		closeWrite(local)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(tunnel, local)
+		closeWrite(tunnel)
+		done <- struct{}{}
+	}()
+	<-done
+	<-done
+	_ = tunnel.Close()
+	_ = local.Close()
+}
+
+func readRelayLine(br *bufio.Reader) (string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// bufferedConn is a net.Conn whose reads drain a bufio.Reader first, so
+// bytes read ahead with the control lines are not lost
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
+
+func (b *bufferedConn) CloseWrite() error {
+	if cw, ok := b.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return b.Conn.Close()
+}
+
+// newRelayDialer returns a dialer that reaches the relay over TLS, through
+// proxyURL when set (http, https, socks5 or socks5h) or otherwise through
+// the proxy configured in the environment
+func newRelayDialer(proxyURL string) (relayDialFunc, error) {
+	var fixed *url.URL
+	if proxyURL != "" {
+		u, err := parseRelayProxy(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		fixed = u
+	}
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relay address %q: %w", addr, err)
+		}
+		via := fixed
+		if via == nil {
+			if via, err = relayProxyFromEnvironment(addr); err != nil {
+				return nil, err
+			}
+		}
+		raw, err := dialVia(ctx, via, addr)
+		if err != nil {
+			return nil, err
+		}
+		conn := tls.Client(raw, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+		if err := conn.HandshakeContext(ctx); err != nil {
+			_ = raw.Close()
+			return nil, fmt.Errorf("relay TLS handshake failed: %w", err)
+		}
+		return conn, nil
+	}, nil
+}
+
+func parseRelayProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid relay proxy URL %q", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	}
+	return nil, fmt.Errorf("unsupported relay proxy scheme %q (valid: http, https, socks5, socks5h)", u.Scheme)
+}
+
+// relayProxyFromEnvironment resolves the proxy for addr from GGO_RELAY_PROXY,
+// HTTPS_PROXY/NO_PROXY and ALL_PROXY, returning nil for a direct connection
+func relayProxyFromEnvironment(addr string) (*url.URL, error) {
+	if raw := os.Getenv(RelayProxyEnv); raw != "" {
+		return parseRelayProxy(raw)
+	}
+	u, err := httpproxy.FromEnvironment().ProxyFunc()(&url.URL{Scheme: "https", Host: addr})
+	if err != nil || u != nil {
+		return u, err
+	}
+	for _, env := range []string{"ALL_PROXY", "all_proxy"} {
+		if raw := os.Getenv(env); raw != "" {
+			return parseRelayProxy(raw)
+		}
+	}
+	return nil, nil
+}
+
+// dialVia opens a TCP connection to addr, tunnelled through via when set
+func dialVia(ctx context.Context, via *url.URL, addr string) (net.Conn, error) {
+	direct := &net.Dialer{Timeout: relayDialTimeout, KeepAlive: 30 * time.Second}
+	if via == nil {
+		return direct.DialContext(ctx, "tcp", addr)
+	}
+
+	if via.Scheme == "socks5" || via.Scheme == "socks5h" {
+		d, err := proxy.FromURL(via, direct)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SOCKS proxy: %w", err)
+		}
+		cd, ok := d.(proxy.ContextDialer)
+		if !ok {
+			return nil, fmt.Errorf("SOCKS proxy dialer does not support contexts")
+		}
+		return cd.DialContext(ctx, "tcp", addr)
+	}
+
+	proxyAddr := via.Host
+	if via.Port() == "" {
+		port := "80"
+		if via.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(via.Hostname(), port)
+	}
+	conn, err := direct.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %w", proxyAddr, err)
+	}
+	if via.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: via.Hostname(), MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("proxy TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+	if err := httpConnect(ctx, conn, via, addr); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// httpConnect asks an HTTP proxy on conn to open a tunnel to addr
+func httpConnect(ctx context.Context, conn net.Conn, via *url.URL, addr string) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if via.User != nil {
+		password, _ := via.User.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(via.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err := req.Write(conn); err != nil {
+		return fmt.Errorf("failed to send CONNECT to proxy: %w", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return fmt.Errorf("failed to read proxy response: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy refused tunnel to %s: %s", addr, resp.Status)
+	}
+	// The relay speaks only after the TLS ClientHello, so nothing may follow
+	// the proxy's response yet
+	if br.Buffered() > 0 {
+		return fmt.Errorf("proxy sent unexpected data after CONNECT response")
+	}
+	return nil
+}
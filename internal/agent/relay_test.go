@@ -0,0 +1,237 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRelay accepts tunnels over plain TCP and hands registered ones to tunnels
+type fakeRelay struct {
+	ln      net.Listener
+	tunnels chan net.Conn
+	hello   chan string
+}
+
+func newFakeRelay(t *testing.T) *fakeRelay {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	r := &fakeRelay{ln: ln, tunnels: make(chan net.Conn, 16), hello: make(chan string, 16)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				_ = conn.Close()
+				continue
+			}
+			r.hello <- strings.TrimSpace(line)
+			_, _ = conn.Write([]byte("OK\n"))
+			r.tunnels <- conn
+		}
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return r
+}
+
+func echoServer(t *testing.T) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return ln
+}
+
+func TestRelayClient_PipesClientToWorker(t *testing.T) {
+	relay := newFakeRelay(t)
+	worker := echoServer(t)
+
+	var dialedPort atomic.Int32
+	c := newRelayClient(func(ctx context.Context, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", addr)
+	})
+	c.local = func(ctx context.Context, port int) (net.Conn, error) {
+		dialedPort.Store(int32(port))
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", worker.Addr().String())
+	}
+	defer c.Stop()
+
+	c.Sync(&api.RelayConfig{Addr: relay.ln.Addr().String(), Token: "tok"}, []api.WorkerConfig{
+		{WorkerID: "worker-1", ListenPort: 9001, Enabled: true, Relay: true},
+		{WorkerID: "worker-2", ListenPort: 9002, Enabled: true},
+	})
+	assert.Equal(t, "GGO-RELAY/1 tok worker-1", <-relay.hello)
+	require.Eventually(t, func() bool { return c.Connected("worker-1") }, 2*time.Second, 10*time.Millisecond)
+	assert.False(t, c.Connected("worker-2"), "worker not shared through the relay")
+
+	// Client bytes may arrive together with the CONNECT line
+	tunnel := <-relay.tunnels
+	_, err := tunnel.Write([]byte("CONNECT 203.0.113.7:51000\nhello worker"))
+	require.NoError(t, err)
+	buf := make([]byte, len("hello worker"))
+	_ = tunnel.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = io.ReadFull(tunnel, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello worker", string(buf))
+	assert.Equal(t, int32(9001), dialedPort.Load())
+	require.NoError(t, tunnel.Close())
+
+	// The used tunnel is replaced, keeping the pool full
+	require.Eventually(t, func() bool { return len(relay.hello) == relayPoolSize }, 2*time.Second, 10*time.Millisecond)
+
+	active := <-relay.tunnels
+	_, err = active.Write([]byte("CONNECT 203.0.113.8:51000\nping"))
+	require.NoError(t, err)
+	buf = make([]byte, len("ping"))
+	_ = active.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = io.ReadFull(active, buf)
+	require.NoError(t, err)
+
+	// Unsharing the worker closes its idle tunnels; paired ones keep
+	// serving their client while the worker drains
+	c.Sync(&api.RelayConfig{Addr: relay.ln.Addr().String(), Token: "tok"}, []api.WorkerConfig{
+		{WorkerID: "worker-1", ListenPort: 9001, Enabled: true},
+	})
+	assert.False(t, c.Connected("worker-1"))
+	_, err = active.Write([]byte("still here"))
+	require.NoError(t, err)
+	buf = make([]byte, len("still here"))
+	_ = active.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = io.ReadFull(active, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "still here", string(buf))
+	require.NoError(t, active.Close())
+
+	idle := <-relay.tunnels
+	_ = idle.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = idle.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestRelayClient_RetriesRejectedTunnel(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	attempts := make(chan struct{}, 16)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = bufio.NewReader(conn).ReadString('\n')
+			_, _ = conn.Write([]byte("ERR unknown token\n"))
+			_ = conn.Close()
+			attempts <- struct{}{}
+		}
+	}()
+
+	c := newRelayClient(func(ctx context.Context, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", addr)
+	})
+	defer c.Stop()
+	c.Sync(&api.RelayConfig{Addr: ln.Addr().String(), Token: "bad"}, []api.WorkerConfig{
+		{WorkerID: "worker-1", ListenPort: 9001, Enabled: true, Relay: true},
+	})
+
+	select {
+	case <-attempts:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tunnel was not attempted")
+	}
+	assert.False(t, c.Connected("worker-1"))
+}
+
+func TestDialVia_HTTPConnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		creds := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+		if req.Method != http.MethodConnect || req.Host != "relay.example.com:443" ||
+			req.Header.Get("Proxy-Authorization") != "Basic "+creds {
+			_, _ = conn.Write([]byte("HTTP/1.1 403 Forbidden\r\n\r\n"))
+			return
+		}
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		_, _ = io.Copy(conn, br)
+	}()
+
+	via, err := parseRelayProxy("http://user:pass@" + ln.Addr().String())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, err := dialVia(ctx, via, "relay.example.com:443")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
+
+func TestRelayProxySelection(t *testing.T) {
+	for _, raw := range []string{"http://proxy:3128", "https://proxy", "socks5://127.0.0.1:1080", "socks5h://proxy:1080"} {
+		_, err := parseRelayProxy(raw)
+		assert.NoError(t, err, raw)
+	}
+	_, err := parseRelayProxy("ftp://proxy")
+	assert.Error(t, err)
+	_, err = parseRelayProxy("proxy:3128")
+	assert.Error(t, err)
+
+	t.Setenv(RelayProxyEnv, "")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("ALL_PROXY", "socks5://127.0.0.1:1080")
+	u, err := relayProxyFromEnvironment("relay.example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, &url.URL{Scheme: "socks5", Host: "127.0.0.1:1080"}, u)
+
+	t.Setenv(RelayProxyEnv, "http://corp-proxy:8080")
+	u, err = relayProxyFromEnvironment("relay.example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, "corp-proxy:8080", u.Host)
+}
@@ -0,0 +1,171 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultReportInterval is how often the agent reports its status
+	DefaultReportInterval = 30 * time.Second
+	// DefaultForceRefreshInterval is how often every GPU and worker is
+	// reported as changed so the server can repair drifted state
+	DefaultForceRefreshInterval = 6 * time.Hour
+	// DefaultKeepaliveInterval is how often a changes-only agent tells the
+	// server it is alive while nothing changes
+	DefaultKeepaliveInterval = 5 * time.Minute
+	// MinReportInterval protects the server from agents reporting too often
+	MinReportInterval = 10 * time.Second
+)
+
+// ReportSettings tunes status reporting. Zero durations and a nil ChangesOnly
+// leave the setting to the server's agent config, or the default.
+type ReportSettings struct {
+	Interval     time.Duration
+	ForceRefresh time.Duration
+	Keepalive    time.Duration
+	// ChangesOnly skips status reports in which nothing changed, sending
+	// only a keepalive, for agents on metered or cellular links
+	ChangesOnly *bool
+}
+
+// merge fills the unset settings of s from other
+func (s ReportSettings) merge(other ReportSettings) ReportSettings {
+	if s.Interval <= 0 {
+		s.Interval = other.Interval
+	}
+	if s.ForceRefresh <= 0 {
+		s.ForceRefresh = other.ForceRefresh
+	}
+	if s.Keepalive <= 0 {
+		s.Keepalive = other.Keepalive
+	}
+	if s.ChangesOnly == nil {
+		s.ChangesOnly = other.ChangesOnly
+	}
+	return s
+}
+
+func (s ReportSettings) changesOnly() bool {
+	return s.ChangesOnly != nil && *s.ChangesOnly
+}
+
+// reportSettingsFromAPI converts the server's reporting config. Intervals
+// below MinReportInterval are raised to it.
+func reportSettingsFromAPI(cfg *api.ReportingConfig) ReportSettings {
+	if cfg == nil {
+		return ReportSettings{}
+	}
+	s := ReportSettings{
+		Interval:     time.Duration(cfg.IntervalSeconds) * time.Second,
+		ForceRefresh: time.Duration(cfg.ForceRefreshSeconds) * time.Second,
+		Keepalive:    time.Duration(cfg.KeepaliveSeconds) * time.Second,
+		ChangesOnly:  cfg.ChangesOnly,
+	}
+	if s.Interval > 0 && s.Interval < MinReportInterval {
+		s.Interval = MinReportInterval
+	}
+	return s
+}
+
+// SetReportSettings sets local reporting settings, which take precedence over
+// the server's agent config. Must be called before Start.
+func (a *Agent) SetReportSettings(s ReportSettings) {
+	a.mu.Lock()
+	a.localReporting = s
+	a.mu.Unlock()
+}
+
+// applyReportingConfig takes the reporting settings from the server's agent
+// config and resets the report ticker when the interval changed
+func (a *Agent) applyReportingConfig(cfg *api.ReportingConfig) {
+	server := reportSettingsFromAPI(cfg)
+	a.mu.Lock()
+	before := a.localReporting.merge(a.serverReporting)
+	a.serverReporting = server
+	after := a.localReporting.merge(a.serverReporting)
+	a.mu.Unlock()
+
+	if before.Interval == after.Interval && before.changesOnly() == after.changesOnly() &&
+		before.ForceRefresh == after.ForceRefresh && before.Keepalive == after.Keepalive {
+		return
+	}
+	effective := a.reportSettings()
+	klog.Infof("Status reporting changed: interval=%s force_refresh=%s changes_only=%t keepalive=%s",
+		effective.Interval, effective.ForceRefresh, effective.changesOnly(), effective.Keepalive)
+	select {
+	case a.reportReset <- struct{}{}:
+	default:
+	}
+}
+
+// reportSettings returns the effective reporting settings: local settings,
+// then the server's, then the defaults
+func (a *Agent) reportSettings() ReportSettings {
+	a.mu.RLock()
+	s := a.localReporting.merge(a.serverReporting)
+	a.mu.RUnlock()
+	return s.merge(ReportSettings{
+		Interval:     DefaultReportInterval,
+		ForceRefresh: DefaultForceRefreshInterval,
+		Keepalive:    DefaultKeepaliveInterval,
+	})
+}
+
+// statusUnchanged reports whether a status report would tell the server
+// nothing new: no GPU or worker changed and no crashes or usage are pending
+func statusUnchanged(gpus []api.GPUStatus, workers []api.WorkerStatus) bool {
+	for _, g := range gpus {
+		if g.GPUChanged {
+			return false
+		}
+	}
+	for _, w := range workers {
+		if isSet(w.WorkerChanged) || isSet(w.ConnectionChanged) || isSet(w.GPUChanged) ||
+			len(w.Crashes) > 0 || len(w.Usage) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isSet(b *bool) bool {
+	return b != nil && *b
+}
+
+// sendKeepalive replaces an unchanged status report in changes-only mode. It
+// carries only the license expiration, so the server can still renew the
+// license, and is sent at most once per keepalive interval.
+func (a *Agent) sendKeepalive(ctx context.Context, now time.Time, licenseExpiration *int64, interval time.Duration) error {
+	a.mu.RLock()
+	lastReport := a.lastReportAt
+	a.mu.RUnlock()
+	if now.Sub(lastReport) < interval {
+		klog.V(4).Infof("Status unchanged, skipping report: last_report=%s", lastReport.Format(time.RFC3339))
+		return nil
+	}
+
+	req := &api.AgentStatusRequest{
+		ReportID:          newReportID(),
+		Timestamp:         now,
+		Event:             api.AgentStatusEventKeepalive,
+		LicenseExpiration: licenseExpiration,
+		LicenseStatus:     LicenseStatus(licenseExpiration, now),
+	}
+	resp, err := a.client.ReportAgentStatus(ctx, a.agentID, req)
+	if err != nil {
+		a.reportFailed(req, err)
+		return err
+	}
+
+	a.mu.Lock()
+	a.lastReportAt = now
+	a.mu.Unlock()
+
+	a.handleReportResponse(resp)
+	a.reportDelivered()
+	return nil
+}
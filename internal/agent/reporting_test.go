@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgent_ReportSettings(t *testing.T) {
+	on, off := true, false
+	a := &Agent{reportReset: make(chan struct{}, 1)}
+
+	s := a.reportSettings()
+	assert.Equal(t, DefaultReportInterval, s.Interval)
+	assert.Equal(t, DefaultForceRefreshInterval, s.ForceRefresh)
+	assert.Equal(t, DefaultKeepaliveInterval, s.Keepalive)
+	assert.False(t, s.changesOnly())
+
+	a.applyReportingConfig(&api.ReportingConfig{IntervalSeconds: 120, ForceRefreshSeconds: 86400, ChangesOnly: &on})
+	s = a.reportSettings()
+	assert.Equal(t, 2*time.Minute, s.Interval)
+	assert.Equal(t, 24*time.Hour, s.ForceRefresh)
+	assert.Equal(t, DefaultKeepaliveInterval, s.Keepalive)
+	assert.True(t, s.changesOnly())
+	assert.Len(t, a.reportReset, 1, "the report loop is told about the new interval")
+
+	// Local settings win over the server's
+	a.SetReportSettings(ReportSettings{Interval: time.Minute, ChangesOnly: &off})
+	s = a.reportSettings()
+	assert.Equal(t, time.Minute, s.Interval)
+	assert.Equal(t, 24*time.Hour, s.ForceRefresh)
+	assert.False(t, s.changesOnly())
+
+	// Intervals from the server are bounded, and dropping the config restores the defaults
+	a.SetReportSettings(ReportSettings{})
+	a.applyReportingConfig(&api.ReportingConfig{IntervalSeconds: 1})
+	assert.Equal(t, MinReportInterval, a.reportSettings().Interval)
+	a.applyReportingConfig(nil)
+	assert.Equal(t, DefaultReportInterval, a.reportSettings().Interval)
+}
+
+func TestStatusUnchanged(t *testing.T) {
+	changed, unchanged := true, false
+	worker := api.WorkerStatus{WorkerID: "worker_1", WorkerChanged: &unchanged, ConnectionChanged: &unchanged, GPUChanged: &unchanged}
+
+	assert.True(t, statusUnchanged([]api.GPUStatus{{GPUID: "GPU-0"}}, []api.WorkerStatus{worker}))
+	assert.False(t, statusUnchanged([]api.GPUStatus{{GPUID: "GPU-0", GPUChanged: true}}, nil))
+
+	w := worker
+	w.ConnectionChanged = &changed
+	assert.False(t, statusUnchanged(nil, []api.WorkerStatus{w}))
+	w = worker
+	w.Crashes = []api.WorkerCrashReport{{WorkerID: "worker_1"}}
+	assert.False(t, statusUnchanged(nil, []api.WorkerStatus{w}), "crash reports are always delivered")
+	w = worker
+	w.Usage = []api.ShareUsage{{ClientIP: "10.0.0.1", BytesIn: 1}}
+	assert.False(t, statusUnchanged(nil, []api.WorkerStatus{w}), "usage deltas are always delivered")
+}
+
+func TestAgent_SendKeepalive(t *testing.T) {
+	var mu sync.Mutex
+	var received []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		received = append(received, body)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.AgentStatusResponse{Success: true})
+	}))
+	defer server.Close()
+
+	a := &Agent{
+		client:  api.NewClient(api.WithBaseURL(server.URL), api.WithAgentSecret("gpugo_secret123")),
+		ctx:     context.Background(),
+		agentID: "agent_test123",
+	}
+	expiration := int64(1700000000000)
+	now := time.Now()
+
+	require.NoError(t, a.sendKeepalive(context.Background(), now, &expiration, time.Minute))
+	require.NoError(t, a.sendKeepalive(context.Background(), now.Add(30*time.Second), &expiration, time.Minute))
+	require.NoError(t, a.sendKeepalive(context.Background(), now.Add(time.Minute), &expiration, time.Minute))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 2, "keepalives are sent once per keepalive interval")
+	assert.Equal(t, "keepalive", received[0]["event"])
+	assert.Equal(t, float64(expiration), received[0]["license_expiration"])
+	assert.Nil(t, received[0]["gpus"])
+	assert.Nil(t, received[0]["workers"])
+	assert.Nil(t, received[0]["metrics"])
+}
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// reportsFile persists status reports the platform was unreachable for
+	reportsFile = "reports.json"
+
+	// maxQueuedReports bounds the local report buffer, about two hours of
+	// reports at the default interval; once full, the oldest reports are
+	// dropped
+	maxQueuedReports = 240
+	// reportBackfillBatch bounds the queued reports delivered after each
+	// successful status report, so a backlog drains at a bounded rate
+	reportBackfillBatch = 10
+	// reportBackfillTimeout bounds a single backfill request
+	reportBackfillTimeout = 30 * time.Second
+)
+
+// reportQueue buffers status reports on disk while the platform is
+// unreachable, so that their metrics and timeline reach the console once it
+// is reachable again, even across agent restarts
+type reportQueue struct {
+	mu      sync.Mutex
+	path    string
+	reports []api.AgentStatusRequest
+}
+
+// newReportQueue loads the reports an earlier agent left at path
+func newReportQueue(path string) *reportQueue {
+	reports, err := utils.LoadJSONSlice[api.AgentStatusRequest](path)
+	if err != nil {
+		klog.Warningf("Discarding unreadable report queue: path=%s error=%v", path, err)
+		reports = nil
+	}
+	return &reportQueue{path: path, reports: reports}
+}
+
+// add queues a report that could not be delivered
+func (q *reportQueue) add(report api.AgentStatusRequest) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.reports = append(q.reports, report)
+	if dropped := len(q.reports) - maxQueuedReports; dropped > 0 {
+		klog.Warningf("Report queue full, dropping oldest reports: dropped=%d", dropped)
+		q.reports = q.reports[dropped:]
+	}
+	q.saveLocked()
+}
+
+// peek returns up to n of the oldest reports
+func (q *reportQueue) peek(n int) []api.AgentStatusRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]api.AgentStatusRequest(nil), q.reports[:min(n, len(q.reports))]...)
+}
+
+// remove drops a delivered report. Reports may have been dropped from the
+// front while delivering, so it is matched by ID.
+func (q *reportQueue) remove(reportID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	kept := q.reports[:0]
+	for _, r := range q.reports {
+		if r.ReportID != reportID {
+			kept = append(kept, r)
+		}
+	}
+	q.reports = kept
+	q.saveLocked()
+}
+
+func (q *reportQueue) len() int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.reports)
+}
+
+func (q *reportQueue) saveLocked() {
+	if err := utils.SaveJSONSlice(q.path, q.reports, 0644); err != nil {
+		klog.Warningf("Failed to save report queue: path=%s error=%v", q.path, err)
+	}
+}
+
+// newReportID returns the dedup key of a status report
+func newReportID() string {
+	return "rpt_" + rand.Text()
+}
+
+// reportFailed queues a status report the platform was unreachable for.
+// Keepalives carry nothing worth backfilling and are not queued. Crash
+// reports, proxy usage and network self-tests are put back for the next
+// report instead, so they are not delivered twice.
+func (a *Agent) reportFailed(req *api.AgentStatusRequest, err error) {
+	if !api.IsUnreachable(err) {
+		return
+	}
+	a.mu.Lock()
+	if a.offlineSince.IsZero() {
+		a.offlineSince = req.Timestamp
+		klog.Warningf("Platform unreachable, queueing status reports until it is back: error=%v", err)
+	}
+	a.mu.Unlock()
+	if a.reports == nil || req.Event == api.AgentStatusEventKeepalive {
+		return
+	}
+
+	queued := *req
+	queued.Backfill = true
+	queued.NetTest = nil
+	queued.Workers = make([]api.WorkerStatus, len(req.Workers))
+	for i, w := range req.Workers {
+		w.Crashes, w.Usage = nil, nil
+		queued.Workers[i] = w
+	}
+	a.reports.add(queued)
+}
+
+// reportDelivered notes that the platform is reachable and delivers up to
+// reportBackfillBatch queued reports, oldest first
+func (a *Agent) reportDelivered() {
+	a.mu.Lock()
+	if !a.offlineSince.IsZero() {
+		klog.Infof("Platform reachable again: offline_for=%s queued_reports=%d", time.Since(a.offlineSince).Round(time.Second), a.reports.len())
+		a.offlineSince = time.Time{}
+	}
+	a.mu.Unlock()
+	if a.reports == nil {
+		return
+	}
+
+	for _, report := range a.reports.peek(reportBackfillBatch) {
+		ctx, cancel := context.WithTimeout(a.ctx, reportBackfillTimeout)
+		_, err := a.client.ReportAgentStatus(ctx, a.agentID, &report)
+		cancel()
+		if err != nil {
+			if !api.IsUnreachable(err) {
+				// Refused for good, e.g. too old to be accepted
+				klog.Warningf("Dropping queued status report the platform refused: report_id=%s error=%v", report.ReportID, err)
+				a.reports.remove(report.ReportID)
+				continue
+			}
+			klog.V(2).Infof("Failed to deliver queued status report, retrying after the next report: report_id=%s error=%v", report.ReportID, err)
+			return
+		}
+		a.reports.remove(report.ReportID)
+		klog.V(2).Infof("Queued status report delivered: report_id=%s timestamp=%s queued=%d", report.ReportID, report.Timestamp.Format(time.RFC3339), a.reports.len())
+	}
+}
+
+// offlineStatus returns since when the platform has been unreachable, nil
+// while it is reachable
+func (a *Agent) offlineStatus() *time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.offlineSince.IsZero() {
+		return nil
+	}
+	since := a.offlineSince
+	return &since
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportQueue_PersistsAndBoundsReports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), reportsFile)
+	q := newReportQueue(path)
+	for i := range maxQueuedReports + 5 {
+		q.add(api.AgentStatusRequest{ReportID: fmt.Sprintf("rpt_%d", i), Timestamp: time.Now()})
+	}
+	assert.Equal(t, maxQueuedReports, q.len())
+	assert.Equal(t, "rpt_5", q.peek(1)[0].ReportID, "the oldest reports are dropped")
+
+	q.remove("rpt_5")
+	reloaded := newReportQueue(path)
+	assert.Equal(t, maxQueuedReports-1, reloaded.len())
+	assert.Equal(t, "rpt_6", reloaded.peek(1)[0].ReportID)
+}
+
+// statusServer plays the platform's status API, unreachable while down
+type statusServer struct {
+	mu       sync.Mutex
+	down     bool
+	received []api.AgentStatusRequest
+}
+
+func (s *statusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var req api.AgentStatusRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	s.received = append(s.received, req)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(api.SuccessResponse{Success: true})
+}
+
+func (s *statusServer) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func TestAgent_ReportStatusBackfillsAfterOutage(t *testing.T) {
+	srv := &statusServer{down: true}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "config")
+	stateDir := filepath.Join(tmpDir, "state")
+	configMgr := config.NewManager(configDir, stateDir)
+	require.NoError(t, configMgr.SaveGPUs([]config.GPUConfig{
+		{GPUID: "GPU-0", GPUIndex: 0, Vendor: "nvidia", Model: "RTX 4090", VRAMMb: 24576},
+	}))
+	a := &Agent{
+		client:  api.NewClient(api.WithBaseURL(server.URL), api.WithAgentSecret("gpugo_secret123")),
+		config:  configMgr,
+		ctx:     context.Background(),
+		agentID: "agent_test123",
+		paths:   platform.DefaultPaths().WithConfigDir(configDir),
+		reports: newReportQueue(filepath.Join(stateDir, reportsFile)),
+	}
+
+	require.Error(t, a.reportStatus())
+	require.Error(t, a.reportStatus())
+	assert.Equal(t, 2, a.reports.len(), "unreachable reports are queued")
+	require.NotNil(t, a.offlineStatus())
+
+	srv.setDown(false)
+	require.NoError(t, a.reportStatus())
+	assert.Nil(t, a.offlineStatus())
+	assert.Zero(t, a.reports.len())
+
+	require.Len(t, srv.received, 3)
+	live, first, second := srv.received[0], srv.received[1], srv.received[2]
+	assert.False(t, live.Backfill)
+	assert.True(t, first.Backfill)
+	assert.True(t, second.Backfill)
+	assert.True(t, first.Timestamp.Before(second.Timestamp), "the oldest report is delivered first")
+	assert.NotEqual(t, first.ReportID, second.ReportID)
+	assert.NotEmpty(t, live.ReportID)
+	assert.Len(t, first.GPUs, 1)
+}
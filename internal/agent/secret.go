@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"k8s.io/klog/v2"
+)
+
+// RotateSecret replaces the agent secret: it requests a new secret with the
+// current one, writes it to config, and verifies it against the server. If
+// verification fails the previous secret is restored in both config and
+// client, which the server still accepts until the new secret is used.
+func RotateSecret(ctx context.Context, client *api.Client, configMgr *config.Manager) error {
+	cfg, err := configMgr.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg == nil || cfg.AgentID == "" {
+		return fmt.Errorf("agent not registered")
+	}
+
+	resp, err := client.RotateAgentSecret(ctx, cfg.AgentID)
+	if err != nil {
+		return fmt.Errorf("failed to request new agent secret: %w", err)
+	}
+	if resp.AgentSecret == "" {
+		return fmt.Errorf("server returned an empty agent secret")
+	}
+
+	previous, err := configMgr.UpdateAgentSecret(resp.AgentSecret)
+	if err != nil {
+		return fmt.Errorf("failed to save new agent secret: %w", err)
+	}
+	client.SetAgentSecret(resp.AgentSecret)
+
+	if _, err := client.GetAgentConfig(ctx, cfg.AgentID); err != nil {
+		client.SetAgentSecret(previous)
+		if _, restoreErr := configMgr.UpdateAgentSecret(previous); restoreErr != nil {
+			klog.Errorf("Failed to restore previous agent secret: error=%v", restoreErr)
+		}
+		return fmt.Errorf("new agent secret was rejected, kept the previous one: %w", err)
+	}
+
+	klog.Infof("Agent secret rotated: agent_id=%s", cfg.AgentID)
+	return nil
+}
+
+// handleSecretRotation rotates the secret when the server asks for it. Only
+// one rotation runs at a time; repeated signals while it runs are ignored.
+func (a *Agent) handleSecretRotation(reason string) {
+	if reason == "" || !a.rotating.CompareAndSwap(false, true) {
+		return
+	}
+	defer a.rotating.Store(false)
+
+	klog.Warningf("Server requested agent secret rotation: reason=%s", reason)
+	if err := RotateSecret(a.ctx, a.client, a.config); err != nil {
+		klog.Errorf("Failed to rotate agent secret: error=%v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateSecret(t *testing.T) {
+	acceptNew := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/agents/agent_test123/secret/rotate":
+			if auth != "Bearer old-secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(api.AgentSecretRotateResponse{AgentSecret: "new-secret"})
+		case "/api/v1/agents/agent_test123/config":
+			if auth == "Bearer new-secret" && !acceptNew {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(api.AgentConfigResponse{ConfigVersion: 1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	newSetup := func(t *testing.T) (*api.Client, *config.Manager) {
+		tmpDir := t.TempDir()
+		configMgr := config.NewManager(tmpDir, tmpDir)
+		require.NoError(t, configMgr.SaveConfig(&config.Config{AgentID: "agent_test123", AgentSecret: "old-secret"}))
+		return api.NewClient(api.WithBaseURL(server.URL), api.WithAgentSecret("old-secret")), configMgr
+	}
+
+	t.Run("new secret is saved after verification", func(t *testing.T) {
+		acceptNew = true
+		client, configMgr := newSetup(t)
+		require.NoError(t, RotateSecret(context.Background(), client, configMgr))
+
+		cfg, err := configMgr.LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "new-secret", cfg.AgentSecret)
+	})
+
+	t.Run("rejected secret is rolled back", func(t *testing.T) {
+		acceptNew = false
+		client, configMgr := newSetup(t)
+		assert.Error(t, RotateSecret(context.Background(), client, configMgr))
+
+		cfg, err := configMgr.LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "old-secret", cfg.AgentSecret)
+
+		// The client keeps working with the previous secret
+		_, err = client.RotateAgentSecret(context.Background(), "agent_test123")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"k8s.io/klog/v2"
+)
+
+// parseSessionKillRequest recognizes a WorkerSessionKillRequest among the
+// frames of the agent's config topic. It must be tried before
+// parseWorkerLogRequest, which accepts any frame naming a request and worker.
+func parseSessionKillRequest(data string) (api.WorkerSessionKillRequest, bool) {
+	var req api.WorkerSessionKillRequest
+	if !strings.HasPrefix(data, "{") || json.Unmarshal([]byte(data), &req) != nil {
+		return req, false
+	}
+	return req, req.WorkerID != "" && req.KillSession != ""
+}
+
+// handleSessionKillRequest ends the client session named by req. Only
+// sessions served through the connection proxy can be ended; without it the
+// agent never holds the client connection.
+func (a *Agent) handleSessionKillRequest(req api.WorkerSessionKillRequest) {
+	if a.proxy == nil {
+		klog.Warningf("Cannot end worker session without the connection proxy (start the agent with --proxy): worker_id=%s session=%s",
+			req.WorkerID, req.KillSession)
+		return
+	}
+	if !a.proxy.KillSession(req.WorkerID, req.KillSession) {
+		klog.Warningf("Worker session to end not found: worker_id=%s session=%s", req.WorkerID, req.KillSession)
+		return
+	}
+	klog.Infof("Ended worker session on request: worker_id=%s session=%s request_id=%s", req.WorkerID, req.KillSession, req.RequestID)
+}
@@ -0,0 +1,19 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSessionKillRequest(t *testing.T) {
+	req, ok := parseSessionKillRequest(`{"request_id":"req_1","worker_id":"w1","kill_session":"10.0.0.2:51234"}`)
+	assert.True(t, ok)
+	assert.Equal(t, "w1", req.WorkerID)
+	assert.Equal(t, "10.0.0.2:51234", req.KillSession)
+
+	_, ok = parseSessionKillRequest(`{"request_id":"req_2","worker_id":"w1","follow":true}`)
+	assert.False(t, ok, "log requests are left to parseWorkerLogRequest")
+	_, ok = parseSessionKillRequest("config-updated")
+	assert.False(t, ok)
+}
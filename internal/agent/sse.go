@@ -3,196 +3,181 @@ package agent
 import (
 	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/NexusGPU/gpu-go/internal/api"
 	"k8s.io/klog/v2"
 )
 
 const (
-	sseEndpoint               = "https://sse.tensor-fusion.ai/v1/stream"
 	sseReconnectMin           = 1 * time.Second
 	sseReconnectMax           = 30 * time.Second
 	sseDebounceDelay          = 500 * time.Millisecond
 	sseTopicHeader            = "x-sse-topic"
 	sseVGPURestartTopicSuffix = "_vgpu_restart"
+	// sseConnectTimeout bounds the wait for the SSE response headers, so a
+	// network that silently drops the connection counts as a failure
+	sseConnectTimeout = 15 * time.Second
 )
 
-// sseConfigListener connects to the SSE endpoint for config-update events and
-// triggers config re-fetch on new events. It reconnects automatically with
-// exponential backoff.
-func (a *Agent) sseConfigListener() {
-	defer a.wg.Done()
-
-	backoff := sseReconnectMin
-
-	for {
-		select {
-		case <-a.ctx.Done():
-			return
-		default:
-		}
-
-		err := a.listenSSE()
-		if err != nil {
-			klog.Warningf("SSE config connection error: %v", err)
-		}
+// sseEndpoint is the SSE broker; a variable for tests
+var sseEndpoint = "https://sse.tensor-fusion.ai/v1/stream"
 
-		select {
-		case <-a.ctx.Done():
-			return
-		case <-time.After(backoff):
-		}
-
-		backoff = min(backoff*2, sseReconnectMax)
-	}
+// sseConfigListener keeps the subscription to config-update events (topic =
+// agentID) alive and triggers config re-fetch on new events. The topic also
+// carries worker log, session kill and remote exec requests, so an agent
+// holds no extra connection open for rarely used commands.
+func (a *Agent) sseConfigListener() {
+	h := &configEventHandler{a: a}
+	defer h.stop()
+	a.listenWithFallback(listenerConfig, a.agentID, func() (bool, error) {
+		return a.listenTopic(listenerConfig, a.agentID, h.handle)
+	}, h.handle)
 }
 
-// sseRestartListener connects to the SSE endpoint for vGPU restart events and
+// sseRestartListener keeps the subscription to vGPU restart events alive and
 // routes them to the reconciler. It runs on a dedicated connection so that
 // messages can be attributed to the restart topic without relying on the
 // broker setting the SSE `event:` field (which sse.tensor-fusion.ai does not).
 func (a *Agent) sseRestartListener() {
-	defer a.wg.Done()
-
-	backoff := sseReconnectMin
-
-	for {
-		select {
-		case <-a.ctx.Done():
-			return
-		default:
-		}
+	a.listenWithFallback(listenerRestart, a.vgpuRestartTopic(), a.listenSSERestart, func(dataLines []string) {
+		a.handleVGPURestartEvent(dataLines)
+	})
+}
 
-		err := a.listenSSERestart()
-		if err != nil {
-			klog.Warningf("SSE restart connection error: %v", err)
-		}
+// configEventHandler handles messages on the config-update topic. Session
+// kill, remote exec and worker log requests are served directly; anything else triggers
+// a config pull, debounced so event bursts result in one pullConfig call.
+type configEventHandler struct {
+	a     *Agent
+	mu    sync.Mutex
+	timer *time.Timer
+}
 
-		select {
-		case <-a.ctx.Done():
-			return
-		case <-time.After(backoff):
+func (h *configEventHandler) handle(dataLines []string) {
+	data := strings.Join(dataLines, "")
+	if data == "" {
+		return
+	}
+	if req, ok := parseSessionKillRequest(data); ok {
+		h.a.handleSessionKillRequest(req)
+		return
+	}
+	if req, ok := parseAgentExecRequest(data); ok {
+		h.a.handleAgentExecRequest(req)
+		return
+	}
+	if req, ok := parseWorkerLogRequest(data); ok {
+		h.a.handleWorkerLogRequest(req)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+	h.timer = time.AfterFunc(sseDebounceDelay, func() {
+		klog.Infof("Config event received, triggering config re-fetch")
+		if err := h.a.pullConfig(); err != nil {
+			klog.Errorf("Failed to pull config after config event: %v", err)
 		}
+	})
+}
 
-		backoff = min(backoff*2, sseReconnectMax)
+func (h *configEventHandler) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.timer != nil {
+		h.timer.Stop()
 	}
 }
 
-// listenSSE opens a single SSE connection for config-update events (topic =
-// agentID) and triggers a debounced pullConfig on every received frame.
-func (a *Agent) listenSSE() error {
-	ctx, cancel := context.WithCancel(a.ctx)
-	defer cancel()
+// listenSSERestart opens a single subscription for vGPU restart events
+// (topic = agentID + "_vgpu_restart"). Every received frame is forwarded
+// directly to handleVGPURestartEvent.
+func (a *Agent) listenSSERestart() (bool, error) {
+	return a.listenTopic(listenerRestart, a.vgpuRestartTopic(), func(dataLines []string) {
+		a.handleVGPURestartEvent(dataLines)
+	})
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sseEndpoint, nil)
-	if err != nil {
-		return err
+// listenTopic opens a single subscription to topic: over the API client's
+// gRPC transport when it is in use, over SSE otherwise
+func (a *Agent) listenTopic(name, topic string, handle func(dataLines []string)) (bool, error) {
+	established, err := a.listenGRPCTopic(name, topic, handle)
+	if errors.Is(err, api.ErrGRPCUnavailable) {
+		return a.listenSSETopic(name, topic, handle)
 	}
-	// Subscribe only to the config-update topic.
-	// Restart events are handled by a separate connection (listenSSERestart)
-	// because the SSE broker does not include the topic name in the frame,
-	// making it impossible to route events from a multi-topic subscription.
-	req.Header.Set(sseTopicHeader, a.agentID)
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
+	return established, err
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
+// listenGRPCTopic subscribes to topic over the gRPC transport and passes the
+// data lines of every received message to handle. It returns
+// api.ErrGRPCUnavailable when the client does not use gRPC.
+func (a *Agent) listenGRPCTopic(name, topic string, handle func(dataLines []string)) (bool, error) {
+	if a.client == nil {
+		return false, api.ErrGRPCUnavailable
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		klog.Warningf("SSE config endpoint returned status %d", resp.StatusCode)
-		return nil
+	stream, err := a.client.OpenAgentTopic(a.ctx, a.agentID, topic)
+	if err != nil {
+		return false, err
 	}
+	defer stream.Close()
 
-	klog.Infof("SSE config connection established: topic=%s", a.agentID)
-
-	scanner := bufio.NewScanner(resp.Body)
-	var debounceTimer *time.Timer
-	var eventDataLines []string
-
-	flushEvent := func() {
-		if len(eventDataLines) == 0 {
-			return
-		}
-		eventDataLines = nil
-		// Debounce config pull so event bursts result in one pullConfig call.
-		if debounceTimer != nil {
-			debounceTimer.Stop()
-		}
-		debounceTimer = time.AfterFunc(sseDebounceDelay, func() {
-			klog.Infof("SSE config event received, triggering config re-fetch")
-			if err := a.pullConfig(); err != nil {
-				klog.Errorf("Failed to pull config after SSE event: %v", err)
-			}
-		})
-	}
+	klog.Infof("gRPC %s subscription established: topic=%s", name, topic)
+	a.transport.setGRPC(name, true)
+	defer a.transport.setGRPC(name, false)
 
-	for scanner.Scan() {
-		select {
-		case <-ctx.Done():
-			if debounceTimer != nil {
-				debounceTimer.Stop()
+	for {
+		data, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || a.ctx.Err() != nil {
+				klog.Infof("gRPC %s subscription closed, will reconnect", name)
+				return true, nil
 			}
-			return nil
-		default:
+			return true, err
 		}
-
-		line := scanner.Text()
-		switch {
-		case line == "":
-			flushEvent()
-		case strings.HasPrefix(line, "data:"):
-			eventDataLines = append(eventDataLines, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
-		}
-	}
-	flushEvent()
-
-	if debounceTimer != nil {
-		debounceTimer.Stop()
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
+		handle(strings.Split(data, "\n"))
 	}
-
-	klog.Infof("SSE config connection closed by server, will reconnect")
-	return nil
 }
 
-// listenSSERestart opens a single SSE connection for vGPU restart events
-// (topic = agentID + "_vgpu_restart"). Every received frame is forwarded
-// directly to handleVGPURestartEvent.
-func (a *Agent) listenSSERestart() error {
+// listenSSETopic opens a single SSE connection subscribed to topic and passes
+// the data lines of every received frame to handle. name identifies the
+// listener in logs and transport state. It reports whether the connection
+// was established.
+func (a *Agent) listenSSETopic(name, topic string, handle func(dataLines []string)) (bool, error) {
 	ctx, cancel := context.WithCancel(a.ctx)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sseEndpoint, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
-	req.Header.Set(sseTopicHeader, a.vgpuRestartTopic())
+	req.Header.Set(sseTopicHeader, topic)
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = sseConnectTimeout
+	resp, err := (&http.Client{Transport: transport}).Do(req)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		klog.Warningf("SSE restart endpoint returned status %d", resp.StatusCode)
-		return nil
+		return false, fmt.Errorf("SSE %s endpoint returned status %d", name, resp.StatusCode)
 	}
 
-	klog.Infof("SSE restart connection established: topic=%s", a.vgpuRestartTopic())
+	klog.Infof("SSE %s connection established: topic=%s", name, topic)
+	a.transport.setConnected(name, true)
+	defer a.transport.setConnected(name, false)
 
 	scanner := bufio.NewScanner(resp.Body)
 	var eventDataLines []string
@@ -201,14 +186,15 @@ func (a *Agent) listenSSERestart() error {
 		if len(eventDataLines) == 0 {
 			return
 		}
-		a.handleVGPURestartEvent(eventDataLines)
+		a.transport.sseActivity()
+		handle(eventDataLines)
 		eventDataLines = nil
 	}
 
 	for scanner.Scan() {
 		select {
 		case <-ctx.Done():
-			return nil
+			return true, nil
 		default:
 		}
 
@@ -223,11 +209,11 @@ func (a *Agent) listenSSERestart() error {
 	flushEvent()
 
 	if err := scanner.Err(); err != nil {
-		return err
+		return true, err
 	}
 
-	klog.Infof("SSE restart connection closed by server, will reconnect")
-	return nil
+	klog.Infof("SSE %s connection closed by server, will reconnect", name)
+	return true, nil
 }
 
 func (a *Agent) vgpuRestartTopic() string {
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"slices"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"k8s.io/klog/v2"
+)
+
+// DefaultFailoverAfter is how long the primary of an HA pair may miss
+// heartbeats before the standby takes over its workers
+const DefaultFailoverAfter = 30 * time.Second
+
+// standbyCheckInterval is how often a standby asks the platform about its primaries
+const standbyCheckInterval = 10 * time.Second
+
+// standbyWorkers returns workers with the standby workers this agent has not
+// taken over disabled, so they stay stopped and unrouted. A takeover the
+// platform has acknowledged is forgotten locally: from then on the platform
+// decides, and clearing Active fails the worker back to the primary.
+func (a *Agent) standbyWorkers(workers []api.WorkerConfig) []api.WorkerConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var gated []api.WorkerConfig
+	for i, w := range workers {
+		if w.Standby == nil {
+			delete(a.failedOver, w.WorkerID)
+			continue
+		}
+		if w.Standby.Active {
+			delete(a.failedOver, w.WorkerID)
+			continue
+		}
+		if a.failedOver[w.WorkerID] {
+			continue
+		}
+		if gated == nil {
+			gated = slices.Clone(workers)
+		}
+		gated[i].Enabled = false
+	}
+	if gated == nil {
+		return workers
+	}
+	return gated
+}
+
+// standbyLoop watches the primaries of the workers this agent stands by for
+func (a *Agent) standbyLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(standbyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.checkStandbyPeers()
+		}
+	}
+}
+
+// checkStandbyPeers takes over the standby workers whose primary has been
+// silent for longer than the failover delay
+func (a *Agent) checkStandbyPeers() {
+	a.mu.RLock()
+	var waiting []api.WorkerConfig
+	for _, w := range a.workerConfigs {
+		if w.Standby != nil && !w.Standby.Active && w.Enabled && !a.failedOver[w.WorkerID] {
+			waiting = append(waiting, w)
+		}
+	}
+	a.mu.RUnlock()
+	if len(waiting) == 0 {
+		return
+	}
+
+	peers := make(map[string]*api.AgentPeerStatus)
+	tookOver := false
+	for _, w := range waiting {
+		primary := w.Standby.PrimaryAgentID
+		peer, ok := peers[primary]
+		if !ok {
+			var err error
+			peer, err = a.client.GetAgentPeerStatus(a.ctx, a.agentID, primary)
+			if err != nil {
+				// Without the platform the share cannot be repointed either
+				klog.Warningf("Failed to check HA primary: primary_agent_id=%s error=%v", primary, err)
+			}
+			peers[primary] = peer
+		}
+		if peer == nil || !primaryFailed(peer, w.Standby, time.Now()) {
+			continue
+		}
+
+		klog.Warningf("HA primary stopped sending heartbeats, taking over worker: worker_id=%s primary_agent_id=%s last_seen=%v",
+			w.WorkerID, primary, peer.LastSeenAt)
+		err := a.client.ReportWorkerFailover(a.ctx, a.agentID, w.WorkerID, &api.WorkerFailoverRequest{
+			PrimaryAgentID:  primary,
+			PrimaryLastSeen: peer.LastSeenAt,
+		})
+		if err != nil {
+			klog.Errorf("Failed to report failover, worker stays on standby: worker_id=%s error=%v", w.WorkerID, err)
+			continue
+		}
+		a.mu.Lock()
+		a.failedOver[w.WorkerID] = true
+		a.mu.Unlock()
+		tookOver = true
+	}
+
+	if tookOver {
+		if err := a.applyWorkers(); err != nil {
+			klog.Errorf("Failed to start workers taken over from HA primary: error=%v", err)
+		}
+	}
+}
+
+// primaryFailed reports whether the primary has been offline for longer than
+// the standby's failover delay
+func primaryFailed(peer *api.AgentPeerStatus, standby *api.WorkerStandby, now time.Time) bool {
+	if peer.Online {
+		return false
+	}
+	if peer.LastSeenAt == nil {
+		// Never seen: the pair was configured while the primary was down
+		return true
+	}
+	after := DefaultFailoverAfter
+	if standby.FailoverAfterSeconds > 0 {
+		after = time.Duration(standby.FailoverAfterSeconds) * time.Second
+	}
+	return now.Sub(*peer.LastSeenAt) >= after
+}
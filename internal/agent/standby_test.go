@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrimaryFailed(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+	standby := &api.WorkerStandby{PrimaryAgentID: "agent_primary"}
+
+	assert.False(t, primaryFailed(&api.AgentPeerStatus{Online: true, LastSeenAt: ago(time.Hour)}, standby, now))
+	assert.False(t, primaryFailed(&api.AgentPeerStatus{LastSeenAt: ago(10 * time.Second)}, standby, now))
+	assert.True(t, primaryFailed(&api.AgentPeerStatus{LastSeenAt: ago(DefaultFailoverAfter)}, standby, now))
+	assert.True(t, primaryFailed(&api.AgentPeerStatus{}, standby, now), "a primary never seen")
+
+	patient := &api.WorkerStandby{PrimaryAgentID: "agent_primary", FailoverAfterSeconds: 120}
+	assert.False(t, primaryFailed(&api.AgentPeerStatus{LastSeenAt: ago(time.Minute)}, patient, now))
+	assert.True(t, primaryFailed(&api.AgentPeerStatus{LastSeenAt: ago(2 * time.Minute)}, patient, now))
+}
+
+// peerServer plays the platform for a standby agent
+type peerServer struct {
+	mu        sync.Mutex
+	online    bool
+	failovers []string
+}
+
+func (p *peerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch r.URL.Path {
+	case "/api/v1/agents/agent_test123/peers/agent_primary":
+		seen := time.Now().Add(-time.Hour)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.AgentPeerStatus{AgentID: "agent_primary", Online: p.online, LastSeenAt: &seen})
+	case "/api/v1/agents/agent_test123/workers/w2/failover":
+		var req api.WorkerFailoverRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		p.failovers = append(p.failovers, req.PrimaryAgentID)
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestStandby_TakesOverFromFailedPrimary(t *testing.T) {
+	platform := &peerServer{online: true}
+	server := httptest.NewServer(platform)
+	defer server.Close()
+
+	hv := newProcessHypervisor()
+	tmpDir := t.TempDir()
+	configMgr := config.NewManager(filepath.Join(tmpDir, "config"), filepath.Join(tmpDir, "state"))
+	require.NoError(t, configMgr.SaveConfig(&config.Config{
+		AgentID: "agent_test123",
+		License: api.License{Plain: "test|pro|9999999999", Encrypted: "enc"},
+	}))
+	a := NewAgentWithHypervisor(api.NewClient(api.WithBaseURL(server.URL)), configMgr, hv, "/opt/worker/remote-gpu-worker")
+	a.agentID = "agent_test123"
+	a.connectionsDir = filepath.Join(tmpDir, "connections")
+	a.workerConfigs = []api.WorkerConfig{
+		{WorkerID: "w1", ListenPort: 9001, Enabled: true},
+		{WorkerID: "w2", ListenPort: 9002, Enabled: true, Standby: &api.WorkerStandby{PrimaryAgentID: "agent_primary"}},
+	}
+	a.reconciler.Start()
+	t.Cleanup(a.reconciler.Stop)
+
+	started := func() []string {
+		var ids []string
+		for id := range hv.executables() {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	require.NoError(t, a.applyWorkers())
+	require.Eventually(t, func() bool { return len(started()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"w1"}, started(), "the standby worker is held back")
+
+	// A healthy primary keeps the standby idle
+	a.checkStandbyPeers()
+	assert.Empty(t, platform.failovers)
+
+	platform.mu.Lock()
+	platform.online = false
+	platform.mu.Unlock()
+	a.checkStandbyPeers()
+	assert.Equal(t, []string{"agent_primary"}, platform.failovers)
+	require.Eventually(t, func() bool { return len(started()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// Once acknowledged, the platform owns the decision
+	a.mu.Lock()
+	a.workerConfigs[1].Standby.Active = true
+	a.mu.Unlock()
+	require.NoError(t, a.applyWorkers())
+	assert.Empty(t, a.failedOver)
+	assert.Len(t, started(), 2)
+
+	// Failing back stops the standby worker
+	a.mu.Lock()
+	a.workerConfigs[1].Standby.Active = false
+	a.mu.Unlock()
+	require.NoError(t, a.applyWorkers())
+	require.Eventually(t, func() bool { return len(started()) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,240 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Listeners subscribed to the agent's SSE topics
+const (
+	listenerConfig  = "config"
+	listenerRestart = "restart"
+)
+
+const (
+	// sseDemoteAfter is the number of consecutive failed SSE connection
+	// attempts after which a listener falls back to long polling
+	sseDemoteAfter = 3
+	// sseReprobeInterval is how often a listener that fell back to long
+	// polling tries SSE again
+	sseReprobeInterval = 5 * time.Minute
+	// longPollWait is how long the platform holds a poll open; it stays below
+	// the API client's request timeout
+	longPollWait = 25 * time.Second
+	// longPollMinInterval spaces polls the platform answers without waiting
+	longPollMinInterval = time.Second
+)
+
+// TransportStatus describes how the agent receives pushes from the platform
+type TransportStatus struct {
+	// Mode is the transport of the config topic, a HeartbeatMode constant
+	Mode string `json:"mode"`
+	// API is the transport of the agent's calls to the platform, "grpc" or
+	// "rest"
+	API string `json:"api,omitempty"`
+	// Reconnects counts SSE connection attempts after the first
+	Reconnects int64 `json:"reconnects"`
+	// Fallbacks counts the times a listener fell back to long polling
+	Fallbacks int64 `json:"fallbacks"`
+	// LastSSEAt is when an SSE connection was last established or delivered
+	// a message
+	LastSSEAt time.Time `json:"last_sse_at"`
+	// LastPollAt is when a long poll last succeeded
+	LastPollAt time.Time `json:"last_poll_at"`
+}
+
+// transportState tracks the transports of the agent's listeners
+type transportState struct {
+	mu         sync.Mutex
+	connected  map[string]bool // listener -> SSE connection established
+	grpc       map[string]bool // listener -> subscribed over gRPC
+	polling    map[string]bool // listener -> fell back to long polling
+	reconnects int64
+	fallbacks  int64
+	lastSSE    time.Time
+	lastPoll   time.Time
+}
+
+func newTransportState() *transportState {
+	return &transportState{connected: make(map[string]bool), grpc: make(map[string]bool), polling: make(map[string]bool)}
+}
+
+// setConnected records a listener's SSE connection going up or down
+func (t *transportState) setConnected(listener string, up bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.connected[listener] = up
+	if up {
+		t.lastSSE = time.Now()
+	}
+}
+
+// setGRPC records a listener's gRPC subscription going up or down
+func (t *transportState) setGRPC(listener string, up bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.grpc[listener] = up
+}
+
+// sseActivity records a message received over SSE
+func (t *transportState) sseActivity() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastSSE = time.Now()
+}
+
+// setPolling records a listener falling back to long polling or returning
+// to SSE
+func (t *transportState) setPolling(listener string, polling bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if polling && !t.polling[listener] {
+		t.fallbacks++
+	}
+	t.polling[listener] = polling
+}
+
+func (t *transportState) pollSucceeded() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastPoll = time.Now()
+}
+
+func (t *transportState) reconnecting() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reconnects++
+}
+
+// sseUp reports whether the listener's SSE connection is established
+func (t *transportState) sseUp(listener string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connected[listener]
+}
+
+// pollingListener reports whether the listener fell back to long polling
+func (t *transportState) pollingListener(listener string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.polling[listener]
+}
+
+// status summarizes the transport. The mode is that of the config topic:
+// long polling only counts while polls succeed, otherwise config changes
+// wait for the periodic status report.
+func (t *transportState) status(now time.Time) TransportStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	mode := HeartbeatModePolling
+	switch {
+	case t.grpc[listenerConfig]:
+		mode = HeartbeatModeGRPC
+	case t.connected[listenerConfig]:
+		mode = HeartbeatModeSSE
+	case t.polling[listenerConfig] && now.Sub(t.lastPoll) < 2*longPollWait:
+		mode = HeartbeatModeLongPoll
+	}
+	return TransportStatus{
+		Mode:       mode,
+		Reconnects: t.reconnects,
+		Fallbacks:  t.fallbacks,
+		LastSSEAt:  t.lastSSE,
+		LastPollAt: t.lastPoll,
+	}
+}
+
+// listenWithFallback keeps a listener subscribed to topic until the agent
+// stops, passing the data lines of every message to handle. It uses SSE
+// while that works; after sseDemoteAfter consecutive failed connection
+// attempts it falls back to long polling the platform, and tries SSE again
+// every sseReprobeInterval.
+func (a *Agent) listenWithFallback(listener, topic string, listenSSE func() (bool, error), handle func(dataLines []string)) {
+	defer a.wg.Done()
+
+	backoff := sseReconnectMin
+	failures := 0
+	var demotedAt time.Time
+	var cursor string
+	first := true
+	for a.ctx.Err() == nil {
+		if failures >= sseDemoteAfter && time.Since(demotedAt) < sseReprobeInterval {
+			started := time.Now()
+			var wait time.Duration
+			if err := a.pollTopic(topic, &cursor, handle); err != nil {
+				klog.Warningf("Long poll %s failed: error=%v", listener, err)
+				wait = backoff
+				backoff = min(backoff*2, sseReconnectMax)
+			} else {
+				backoff = sseReconnectMin
+				wait = longPollMinInterval - time.Since(started)
+			}
+			if !a.sleep(wait) {
+				return
+			}
+			continue
+		}
+
+		if !first {
+			a.transport.reconnecting()
+		}
+		first = false
+		established, err := listenSSE()
+		if err != nil {
+			klog.Warningf("SSE %s connection error: %v", listener, err)
+		}
+		if established {
+			failures = 0
+			backoff = sseReconnectMin
+			if a.transport.pollingListener(listener) {
+				klog.Infof("SSE %s connection works again, leaving long polling", listener)
+				a.transport.setPolling(listener, false)
+			}
+		} else if failures++; failures >= sseDemoteAfter {
+			if failures == sseDemoteAfter {
+				klog.Warningf("SSE %s connection failed %d times in a row, falling back to long polling", listener, failures)
+				a.transport.setPolling(listener, true)
+			}
+			demotedAt = time.Now()
+			backoff = sseReconnectMin
+			continue
+		}
+
+		if !a.sleep(backoff) {
+			return
+		}
+		backoff = min(backoff*2, sseReconnectMax)
+	}
+}
+
+// pollTopic long-polls topic once, passing every received message to handle
+func (a *Agent) pollTopic(topic string, cursor *string, handle func(dataLines []string)) error {
+	resp, err := a.client.PollAgentTopic(a.ctx, a.agentID, topic, *cursor, longPollWait)
+	if err != nil {
+		return err
+	}
+	a.transport.pollSucceeded()
+	*cursor = resp.Cursor
+	for _, m := range resp.Messages {
+		handle(strings.Split(m.Data, "\n"))
+	}
+	return nil
+}
+
+// sleep waits for d, reporting false if the agent stopped meanwhile
+func (a *Agent) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return a.ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-a.ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
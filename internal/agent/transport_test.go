@@ -0,0 +1,170 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/api/agentpb"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAgent_ListenFallsBackToLongPolling(t *testing.T) {
+	var sseAttempts atomic.Int32
+	sse := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sseAttempts.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer sse.Close()
+	oldEndpoint := sseEndpoint
+	sseEndpoint = sse.URL
+	defer func() { sseEndpoint = oldEndpoint }()
+
+	var polls atomic.Int32
+	platform := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/agents/agent_test123/poll", r.URL.Path)
+		assert.Equal(t, "agent_test123_vgpu_restart", r.URL.Query().Get("topic"))
+		w.Header().Set("Content-Type", "application/json")
+		if polls.Add(1) == 1 {
+			assert.Empty(t, r.URL.Query().Get("cursor"))
+			_, _ = w.Write([]byte(`{"messages":[{"data":"worker-1\nworker-2"}],"cursor":"c1"}`))
+			return
+		}
+		assert.Equal(t, "c1", r.URL.Query().Get("cursor"))
+		_, _ = w.Write([]byte(`{"messages":[],"cursor":"c1"}`))
+	}))
+	defer platform.Close()
+
+	a := newEventsAgent(t, platform.URL)
+	var mu sync.Mutex
+	var received [][]string
+	a.wg.Add(1)
+	go a.listenWithFallback(listenerRestart, a.vgpuRestartTopic(), a.listenSSERestart, func(dataLines []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, dataLines)
+	})
+
+	require.Eventually(t, func() bool { return polls.Load() >= 2 }, 10*time.Second, 50*time.Millisecond)
+	a.cancel()
+	a.wg.Wait()
+
+	assert.EqualValues(t, sseDemoteAfter, sseAttempts.Load(), "SSE is not retried until the re-probe")
+	mu.Lock()
+	assert.Equal(t, [][]string{{"worker-1", "worker-2"}}, received)
+	mu.Unlock()
+
+	status := a.transport.status(time.Now())
+	assert.EqualValues(t, 1, status.Fallbacks)
+	assert.EqualValues(t, sseDemoteAfter-1, status.Reconnects)
+	assert.False(t, status.LastPollAt.IsZero())
+	assert.True(t, status.LastSSEAt.IsZero())
+}
+
+// topicService publishes messages on the gRPC subscription of a topic
+type topicService struct {
+	agentpb.UnimplementedAgentServiceServer
+	messages []string
+}
+
+func (s *topicService) Subscribe(req *agentpb.SubscribeRequest, stream grpc.ServerStreamingServer[agentpb.TopicMessage]) error {
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for _, data := range s.messages {
+		if err := stream.Send(&agentpb.TopicMessage{Data: data}); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestAgent_ListenTopicOverGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	agentpb.RegisterAgentServiceServer(srv, &topicService{messages: []string{"worker-1\nworker-2"}})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	platform := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/capabilities", r.URL.Path, "no REST calls besides the negotiation")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"grpc_endpoint":"grpc://%s"}`, lis.Addr())
+	}))
+	defer platform.Close()
+
+	tmpDir := t.TempDir()
+	client := api.NewClient(api.WithBaseURL(platform.URL), api.WithAgentSecret("gpugo_secret"), api.WithAgentTransport(api.AgentTransportAuto))
+	defer func() { _ = client.Close() }()
+	a := NewAgent(client, config.NewManager(filepath.Join(tmpDir, "config"), filepath.Join(tmpDir, "state")))
+	a.agentID = "agent_test123"
+
+	received := make(chan []string, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		established, err := a.listenTopic(listenerRestart, a.vgpuRestartTopic(), func(dataLines []string) {
+			received <- dataLines
+		})
+		assert.True(t, established)
+		assert.NoError(t, err)
+	}()
+
+	select {
+	case lines := <-received:
+		assert.Equal(t, []string{"worker-1", "worker-2"}, lines)
+	case <-time.After(10 * time.Second):
+		t.Fatal("no message received over gRPC")
+	}
+	assert.Equal(t, "grpc", client.AgentTransport())
+	a.cancel()
+	<-done
+}
+
+func TestTransportState_Mode(t *testing.T) {
+	ts := newTransportState()
+	now := time.Now()
+	assert.Equal(t, HeartbeatModePolling, ts.status(now).Mode)
+
+	ts.setConnected(listenerConfig, true)
+	assert.Equal(t, HeartbeatModeSSE, ts.status(now).Mode)
+	ts.setGRPC(listenerConfig, true)
+	assert.Equal(t, HeartbeatModeGRPC, ts.status(now).Mode)
+	ts.setGRPC(listenerConfig, false)
+	ts.setConnected(listenerConfig, false)
+
+	ts.setPolling(listenerConfig, true)
+	ts.setPolling(listenerConfig, true)
+	assert.Equal(t, HeartbeatModePolling, ts.status(now).Mode, "no poll succeeded yet")
+	ts.pollSucceeded()
+	assert.Equal(t, HeartbeatModeLongPoll, ts.status(time.Now()).Mode)
+	assert.Equal(t, HeartbeatModePolling, ts.status(time.Now().Add(time.Minute)).Mode, "polls stopped succeeding")
+	assert.EqualValues(t, 1, ts.status(now).Fallbacks)
+
+	ts.setPolling(listenerRestart, true)
+	assert.EqualValues(t, 2, ts.status(now).Fallbacks)
+}
+
+func TestBuildTransportLine(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	line := buildTransportLine("agent-abc", TransportStatus{
+		Mode:       HeartbeatModeLongPoll,
+		Reconnects: 4,
+		Fallbacks:  1,
+		LastPollAt: now.Add(-3 * time.Second),
+	}, now)
+	assert.Equal(t, "agent_transport,agent_id=agent-abc,mode=long-poll reconnects=4i,fallbacks=1i,last_sse_age_s=-1i,last_poll_age_s=3i 1700000000000", line)
+}
@@ -0,0 +1,512 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/hypervisor"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultUpgradeCheckInterval is how often the agent looks for a newer
+	// remote-gpu-worker release
+	DefaultUpgradeCheckInterval = 6 * time.Hour
+	// DefaultUpgradeHealthTimeout bounds how long an upgraded worker has to
+	// pass its health checks before the release is rolled back
+	DefaultUpgradeHealthTimeout = 3 * time.Minute
+
+	// workerReleasesDir keeps staged remote-gpu-worker releases side by side
+	// in the state dir, so workers can be switched back to the previous one
+	workerReleasesDir = "worker-releases"
+	// workerUpgradeFile records the release the workers were upgraded to and
+	// the releases that were rolled back
+	workerUpgradeFile = "worker-upgrade.json"
+)
+
+// WorkerReleaseSource finds and downloads remote-gpu-worker releases;
+// implemented by deps.Manager
+type WorkerReleaseSource interface {
+	LatestRemoteGPUWorker(ctx context.Context) (*deps.Library, error)
+	StageLibrary(ctx context.Context, lib deps.Library, dir string) (string, error)
+}
+
+// WorkerUpgradeConfig configures automatic remote-gpu-worker upgrades. Zero
+// durations use the defaults.
+type WorkerUpgradeConfig struct {
+	Releases WorkerReleaseSource
+	// CurrentVersion is the release of the binary the agent was started with
+	CurrentVersion string
+	// Window limits when workers are restarted; the zero window allows any time
+	Window        MaintenanceWindow
+	CheckInterval time.Duration
+	// HealthTimeout is how long an upgraded worker has to be reported healthy
+	// by the health probes or, without probes, how long its process must stay up
+	HealthTimeout time.Duration
+}
+
+// workerUpgradeState is persisted in workerUpgradeFile
+type workerUpgradeState struct {
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path,omitempty"`
+	// Failed lists releases that were rolled back and are not tried again
+	Failed []string `json:"failed,omitempty"`
+}
+
+// stagedWorkerRelease is a downloaded release waiting for the maintenance window
+type stagedWorkerRelease struct {
+	version string
+	path    string
+}
+
+// workerUpgrader holds the state of the upgrade loop. It is only used from
+// that loop, apart from the fields guarded by Agent.mu.
+type workerUpgrader struct {
+	cfg       WorkerUpgradeConfig
+	statePath string
+	version   string
+	failed    []string
+	pending   *stagedWorkerRelease
+	lastCheck time.Time
+
+	now  func() time.Time
+	tick time.Duration // how often the window and the release check are evaluated
+	poll time.Duration // how often a worker is checked while it drains or restarts
+}
+
+// EnableWorkerUpgrades makes the agent download newer remote-gpu-worker
+// releases and roll them out to its workers one at a time within the
+// maintenance window, rolling back when an upgraded worker fails its health
+// checks. A release the workers were upgraded to before the agent restarted
+// is used again. Must be called before Start.
+func (a *Agent) EnableWorkerUpgrades(cfg WorkerUpgradeConfig) error {
+	if a.reconciler == nil {
+		return fmt.Errorf("worker upgrades require hypervisor integration")
+	}
+	if cfg.Releases == nil {
+		return fmt.Errorf("worker upgrades require a release source")
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultUpgradeCheckInterval
+	}
+	if cfg.HealthTimeout <= 0 {
+		cfg.HealthTimeout = DefaultUpgradeHealthTimeout
+	}
+
+	u := &workerUpgrader{
+		cfg:       cfg,
+		statePath: filepath.Join(a.config.StateDir(), workerUpgradeFile),
+		version:   cfg.CurrentVersion,
+		now:       time.Now,
+		tick:      time.Minute,
+		poll:      2 * time.Second,
+	}
+	state, err := utils.LoadJSON[workerUpgradeState](u.statePath)
+	if err != nil {
+		klog.Warningf("Failed to load worker upgrade state: path=%s error=%v", u.statePath, err)
+	}
+	if state != nil {
+		u.failed = state.Failed
+		if state.Path != "" && deps.CompareVersions(state.Version, cfg.CurrentVersion) {
+			if _, err := os.Stat(state.Path); err == nil {
+				klog.Infof("Using upgraded remote-gpu-worker: version=%s path=%s", state.Version, state.Path)
+				a.workerBinaryPath = state.Path
+				u.version = state.Version
+			}
+		}
+	}
+	a.upgrade = u
+	return nil
+}
+
+// workerUpgradeLoop checks for new releases every check interval and rolls a
+// staged release out while the maintenance window is open
+func (a *Agent) workerUpgradeLoop() {
+	defer a.wg.Done()
+	u := a.upgrade
+
+	ticker := time.NewTicker(u.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := u.now()
+		if now.Sub(u.lastCheck) >= u.cfg.CheckInterval {
+			u.lastCheck = now
+			a.checkWorkerRelease()
+		}
+		if u.pending != nil && u.cfg.Window.Contains(now) {
+			a.rollOutWorkerRelease()
+		}
+	}
+}
+
+// checkWorkerRelease stages the newest remote-gpu-worker release when it is
+// newer than the one the workers run and was not rolled back before
+func (a *Agent) checkWorkerRelease() {
+	u := a.upgrade
+	lib, err := u.cfg.Releases.LatestRemoteGPUWorker(a.ctx)
+	if err != nil {
+		klog.Warningf("Failed to check for remote-gpu-worker releases: error=%v", err)
+		return
+	}
+	if lib == nil || !deps.CompareVersions(lib.Version, u.version) {
+		return
+	}
+	if slices.Contains(u.failed, lib.Version) {
+		klog.V(4).Infof("Skipping remote-gpu-worker release that was rolled back: version=%s", lib.Version)
+		return
+	}
+	if u.pending != nil && u.pending.version == lib.Version {
+		return
+	}
+
+	path, err := u.cfg.Releases.StageLibrary(a.ctx, *lib, filepath.Join(a.config.StateDir(), workerReleasesDir))
+	if err != nil {
+		klog.Errorf("Failed to download remote-gpu-worker release: version=%s error=%v", lib.Version, err)
+		return
+	}
+	u.pending = &stagedWorkerRelease{version: lib.Version, path: path}
+	klog.Infof("New remote-gpu-worker release downloaded: version=%s current=%s window=%s", lib.Version, u.version, u.cfg.Window)
+}
+
+// rollOutWorkerRelease upgrades the running workers one at a time. When the
+// window closes, the remaining workers are upgraded in the next one; when a
+// worker fails its health checks, all workers go back to the previous release.
+func (a *Agent) rollOutWorkerRelease() {
+	u := a.upgrade
+	rel := u.pending
+
+	pending := a.workersToUpgrade(rel.path)
+	for i, workerID := range pending {
+		if !u.cfg.Window.Contains(u.now()) {
+			klog.Infof("Maintenance window closed, continuing the worker upgrade in the next one: version=%s remaining=%d", rel.version, len(pending)-i)
+			return
+		}
+		klog.Infof("Upgrading worker: worker_id=%s version=%s", workerID, rel.version)
+		if err := a.upgradeWorker(workerID, rel.path); err != nil {
+			if a.ctx.Err() != nil {
+				return
+			}
+			klog.Errorf("Upgraded worker failed its health checks, rolling back: worker_id=%s version=%s error=%v", workerID, rel.version, err)
+			a.rollBackWorkerRelease(rel)
+			return
+		}
+	}
+
+	// Workers started from now on run the release as well
+	a.mu.Lock()
+	a.workerBinaryPath = rel.path
+	a.workerExecutables = nil
+	a.mu.Unlock()
+	u.version = rel.version
+	u.pending = nil
+	a.saveWorkerUpgradeState(rel.path)
+	klog.Infof("Workers upgraded: version=%s workers=%d", rel.version, len(pending))
+}
+
+// workersToUpgrade returns the desired, running workers that do not run path yet
+func (a *Agent) workersToUpgrade(path string) []string {
+	a.mu.RLock()
+	enabled := make(map[string]bool, len(a.workerConfigs))
+	for _, w := range a.workerConfigs {
+		enabled[w.WorkerID] = w.Enabled
+	}
+	a.mu.RUnlock()
+
+	var ids []string
+	for _, w := range a.hypervisorMgr.ListWorkers() {
+		info := w.WorkerRunningInfo
+		if !enabled[w.WorkerUID] || info == nil || !info.IsRunning || workerBinary(info) == path {
+			continue
+		}
+		ids = append(ids, w.WorkerUID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// upgradeWorker drains a worker, restarts it with the binary at path and
+// waits for it to pass its health checks. The worker takes no new clients
+// until then.
+func (a *Agent) upgradeWorker(workerID, path string) error {
+	a.setWorkerUpgrading(workerID, true)
+	defer a.setWorkerUpgrading(workerID, false)
+
+	if err := a.waitWorkerIdle(workerID); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	if a.workerExecutables == nil {
+		a.workerExecutables = make(map[string]string)
+	}
+	a.workerExecutables[workerID] = path
+	a.mu.Unlock()
+	if err := a.applyWorkers(); err != nil {
+		return err
+	}
+	return a.awaitUpgradedWorker(workerID, path)
+}
+
+// setWorkerUpgrading routes a worker as disabled while it is upgraded, so the
+// connection proxy and the relay stop taking new clients for it
+func (a *Agent) setWorkerUpgrading(workerID string, upgrading bool) {
+	a.mu.Lock()
+	if upgrading {
+		if a.upgrading == nil {
+			a.upgrading = make(map[string]bool)
+		}
+		a.upgrading[workerID] = true
+	} else {
+		delete(a.upgrading, workerID)
+	}
+	a.mu.Unlock()
+
+	if err := a.applyWorkers(); err != nil {
+		klog.Warningf("Failed to apply worker config: error=%v", err)
+	}
+}
+
+// waitWorkerIdle waits for the clients of a worker to disconnect, for up to
+// the drain grace period. Only workers behind the connection proxy are
+// drained; others take new clients on their own port until they stop.
+func (a *Agent) waitWorkerIdle(workerID string) error {
+	if a.proxy == nil || a.drain == nil {
+		return nil
+	}
+	u := a.upgrade
+	deadline := u.now().Add(a.drain.Grace())
+	for {
+		conns := a.workerConnectionCount(workerID)
+		if conns == 0 {
+			return nil
+		}
+		if !u.now().Before(deadline) {
+			klog.Warningf("Drain grace period over, upgrading worker with connected clients: worker_id=%s connections=%d", workerID, conns)
+			return nil
+		}
+		select {
+		case <-a.ctx.Done():
+			return a.ctx.Err()
+		case <-time.After(u.poll):
+		}
+	}
+}
+
+// awaitUpgradedWorker waits for a worker to run path and pass its health
+// checks: with health probes it must be reported healthy within the health
+// timeout, without them its process must stay up for that long
+func (a *Agent) awaitUpgradedWorker(workerID, path string) error {
+	u := a.upgrade
+	deadline := u.now().Add(u.cfg.HealthTimeout)
+	var pid uint32
+
+	for {
+		var info *hvApi.WorkerRunningInfo
+		for _, w := range a.hypervisorMgr.ListWorkers() {
+			if w.WorkerUID == workerID {
+				info = w.WorkerRunningInfo
+			}
+		}
+
+		switch {
+		case info == nil || workerBinary(info) != path || !info.IsRunning:
+			if pid != 0 {
+				return fmt.Errorf("worker exited after the upgrade")
+			}
+		case pid == 0:
+			pid = info.PID
+		case info.PID != pid:
+			return fmt.Errorf("worker restarted after the upgrade: pid=%d previous_pid=%d", info.PID, pid)
+		}
+
+		if pid != 0 && a.healthProbes {
+			if health, ok := a.reconciler.WorkerHealth(workerID); ok {
+				switch health.Status {
+				case hypervisor.HealthHealthy:
+					return nil
+				case hypervisor.HealthUnhealthy:
+					return fmt.Errorf("worker is unhealthy: %s", describeProbes(health.Probes))
+				}
+			}
+		}
+
+		if !u.now().Before(deadline) {
+			switch {
+			case pid == 0:
+				return fmt.Errorf("worker did not start within %s", u.cfg.HealthTimeout)
+			case a.healthProbes:
+				return fmt.Errorf("worker was not reported healthy within %s", u.cfg.HealthTimeout)
+			}
+			return nil
+		}
+		select {
+		case <-a.ctx.Done():
+			return a.ctx.Err()
+		case <-time.After(u.poll):
+		}
+	}
+}
+
+// rollBackWorkerRelease puts every worker back on the release the agent ran
+// before and records the release as failed, so it is not tried again
+func (a *Agent) rollBackWorkerRelease(rel *stagedWorkerRelease) {
+	u := a.upgrade
+	a.mu.Lock()
+	a.workerExecutables = nil
+	path := a.workerBinaryPath
+	a.mu.Unlock()
+	if err := a.applyWorkers(); err != nil {
+		klog.Errorf("Failed to roll back workers: version=%s error=%v", rel.version, err)
+	}
+
+	u.failed = append(u.failed, rel.version)
+	u.pending = nil
+	a.saveWorkerUpgradeState(path)
+	if err := os.RemoveAll(filepath.Dir(rel.path)); err != nil {
+		klog.Warningf("Failed to remove rolled back release: path=%s error=%v", rel.path, err)
+	}
+	klog.Warningf("Rolled back remote-gpu-worker release: version=%s running=%s", rel.version, u.version)
+}
+
+func (a *Agent) saveWorkerUpgradeState(path string) {
+	u := a.upgrade
+	state := workerUpgradeState{Version: u.version, Path: path, Failed: u.failed}
+	if u.version == u.cfg.CurrentVersion {
+		// The binary from the deps manifest is used without the state file
+		state.Version, state.Path = "", ""
+	}
+	if err := utils.SaveJSON(u.statePath, state, 0644); err != nil {
+		klog.Warningf("Failed to save worker upgrade state: path=%s error=%v", u.statePath, err)
+	}
+}
+
+// describeProbes summarizes the failed probes of an unhealthy worker
+func describeProbes(probes []hypervisor.ProbeResult) string {
+	var failed []string
+	for _, p := range probes {
+		if !p.OK {
+			failed = append(failed, p.Probe+": "+p.Error)
+		}
+	}
+	if len(failed) == 0 {
+		return "probes failed"
+	}
+	return strings.Join(failed, "; ")
+}
+
+// routedWorkers returns the workers as the connection proxy and the relay
+// should serve them: workers being upgraded are disabled
+func (a *Agent) routedWorkers(workers []api.WorkerConfig) []api.WorkerConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.upgrading) == 0 {
+		return workers
+	}
+	routed := slices.Clone(workers)
+	for i := range routed {
+		if a.upgrading[routed[i].WorkerID] {
+			routed[i].Enabled = false
+		}
+	}
+	return routed
+}
+
+// workerExecutable returns the remote-gpu-worker binary a worker runs: the
+// release it is being upgraded to, or the agent's current one
+func (a *Agent) workerExecutable(workerID string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if path, ok := a.workerExecutables[workerID]; ok {
+		return path
+	}
+	return a.workerBinaryPath
+}
+
+// MaintenanceWindow is a daily time range in local time, such as
+// 02:00-05:00. A range whose end is before its start spans midnight. The zero
+// window is always open.
+type MaintenanceWindow struct {
+	start, end time.Duration // since midnight
+	set        bool
+}
+
+// ParseMaintenanceWindow parses HH:MM-HH:MM; the empty string is the window
+// that is always open
+func ParseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return MaintenanceWindow{}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: expected HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: %w", s, err)
+	}
+	if start == end {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: start and end are the same", s)
+	}
+	return MaintenanceWindow{start: start, end: end, set: true}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	m, err := strconv.Atoi(mm)
+	if err != nil || m < 0 || m > 59 || len(mm) != 2 {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// Contains reports whether t falls within the window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	if !w.set {
+		return true
+	}
+	hour, min, sec := t.Clock()
+	now := time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second
+	if w.start < w.end {
+		return now >= w.start && now < w.end
+	}
+	return now >= w.start || now < w.end
+}
+
+func (w MaintenanceWindow) String() string {
+	if !w.set {
+		return "any time"
+	}
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(w.start) + "-" + clock(w.end)
+}
@@ -0,0 +1,241 @@
+package agent
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/NexusGPU/gpu-go/internal/deps"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	at := func(clock string) time.Time {
+		parsed, err := time.Parse("15:04", clock)
+		require.NoError(t, err)
+		return time.Date(2026, 3, 1, parsed.Hour(), parsed.Minute(), 0, 0, time.Local)
+	}
+
+	always, err := ParseMaintenanceWindow("")
+	require.NoError(t, err)
+	assert.True(t, always.Contains(at("13:00")))
+	assert.Equal(t, "any time", always.String())
+
+	night, err := ParseMaintenanceWindow("02:00-05:30")
+	require.NoError(t, err)
+	assert.Equal(t, "02:00-05:30", night.String())
+	assert.True(t, night.Contains(at("02:00")))
+	assert.True(t, night.Contains(at("05:29")))
+	assert.False(t, night.Contains(at("05:30")))
+	assert.False(t, night.Contains(at("01:59")))
+
+	overnight, err := ParseMaintenanceWindow("23:00-01:00")
+	require.NoError(t, err)
+	assert.True(t, overnight.Contains(at("23:30")))
+	assert.True(t, overnight.Contains(at("00:30")))
+	assert.False(t, overnight.Contains(at("12:00")))
+
+	for _, bad := range []string{"02:00", "2-5", "25:00-03:00", "02:60-03:00", "02:0-03:00", "03:00-03:00"} {
+		_, err := ParseMaintenanceWindow(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+// processHypervisor keeps track of started workers like the real manager.
+// Workers started from a release in broken never come up.
+type processHypervisor struct {
+	mockHypervisorManager
+
+	mu      sync.Mutex
+	nextPID uint32
+	workers map[string]*hvApi.WorkerInfo
+	broken  string
+}
+
+func newProcessHypervisor() *processHypervisor {
+	return &processHypervisor{
+		mockHypervisorManager: mockHypervisorManager{started: true},
+		// Far above any real PID, so the reconciler does not wait for them to exit
+		nextPID: 2_000_000_000,
+		workers: make(map[string]*hvApi.WorkerInfo),
+	}
+}
+
+func (h *processHypervisor) ListWorkers() []*hvApi.WorkerInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	workers := make([]*hvApi.WorkerInfo, 0, len(h.workers))
+	for _, w := range h.workers {
+		info := *w.WorkerRunningInfo
+		info.Env = maps.Clone(info.Env)
+		copied := *w
+		copied.WorkerRunningInfo = &info
+		workers = append(workers, &copied)
+	}
+	return workers
+}
+
+func (h *processHypervisor) StartWorker(info *hvApi.WorkerInfo) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextPID++
+	running := *info.WorkerRunningInfo
+	running.PID = h.nextPID
+	running.IsRunning = h.broken == "" || !strings.Contains(running.Executable, h.broken)
+	started := *info
+	started.WorkerRunningInfo = &running
+	h.workers[info.WorkerUID] = &started
+	return nil
+}
+
+func (h *processHypervisor) StopWorker(workerUID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.workers, workerUID)
+	return nil
+}
+
+func (h *processHypervisor) executables() map[string]string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	exes := make(map[string]string, len(h.workers))
+	for id, w := range h.workers {
+		exes[id] = w.WorkerRunningInfo.Executable
+	}
+	return exes
+}
+
+// stagingReleases offers one release and stages it as an empty file
+type stagingReleases struct {
+	lib *deps.Library
+}
+
+func (s *stagingReleases) LatestRemoteGPUWorker(ctx context.Context) (*deps.Library, error) {
+	return s.lib, nil
+}
+
+func (s *stagingReleases) StageLibrary(ctx context.Context, lib deps.Library, dir string) (string, error) {
+	path := filepath.Join(dir, lib.Version, lib.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, nil, 0755)
+}
+
+// newUpgradeAgent returns a started agent running two workers from oldPath
+func newUpgradeAgent(t *testing.T, hv *processHypervisor, oldPath string) *Agent {
+	t.Helper()
+	tmpDir := t.TempDir()
+	configMgr := config.NewManager(filepath.Join(tmpDir, "config"), filepath.Join(tmpDir, "state"))
+	require.NoError(t, configMgr.SaveConfig(&config.Config{
+		AgentID: "agent_test123",
+		License: api.License{Plain: "test|pro|9999999999", Encrypted: "enc"},
+	}))
+
+	a := NewAgentWithHypervisor(api.NewClient(), configMgr, hv, oldPath)
+	a.connectionsDir = filepath.Join(tmpDir, "connections")
+	a.workerConfigs = []api.WorkerConfig{
+		{WorkerID: "w1", ListenPort: 9001, Enabled: true},
+		{WorkerID: "w2", ListenPort: 9002, Enabled: true},
+	}
+	a.reconciler.Start()
+	t.Cleanup(a.reconciler.Stop)
+
+	require.NoError(t, a.applyWorkers())
+	require.Eventually(t, func() bool {
+		return len(hv.executables()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	return a
+}
+
+func enableTestUpgrades(t *testing.T, a *Agent, version string) {
+	t.Helper()
+	require.NoError(t, a.EnableWorkerUpgrades(WorkerUpgradeConfig{
+		Releases:       &stagingReleases{lib: &deps.Library{Name: "remote-gpu-worker", Version: version}},
+		CurrentVersion: "1.0.0",
+		HealthTimeout:  200 * time.Millisecond,
+	}))
+	a.upgrade.poll = 10 * time.Millisecond
+}
+
+func TestWorkerUpgrade_RollsOutRelease(t *testing.T) {
+	hv := newProcessHypervisor()
+	a := newUpgradeAgent(t, hv, "/opt/worker/1.0.0/remote-gpu-worker")
+	enableTestUpgrades(t, a, "1.1.0")
+
+	a.checkWorkerRelease()
+	require.NotNil(t, a.upgrade.pending)
+	newPath := a.upgrade.pending.path
+	assert.Equal(t, filepath.Join(a.config.StateDir(), workerReleasesDir, "1.1.0", "remote-gpu-worker"), newPath)
+
+	a.rollOutWorkerRelease()
+	assert.Equal(t, map[string]string{"w1": newPath, "w2": newPath}, hv.executables())
+	assert.Nil(t, a.upgrade.pending)
+	assert.Equal(t, "1.1.0", a.upgrade.version)
+	assert.Equal(t, newPath, a.workerExecutable("w3"), "new workers start from the release")
+	assert.Empty(t, a.upgrading)
+
+	state, err := utils.LoadJSON[workerUpgradeState](a.upgrade.statePath)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, workerUpgradeState{Version: "1.1.0", Path: newPath}, *state)
+
+	// A restarted agent keeps running the release
+	restarted := NewAgentWithHypervisor(api.NewClient(), a.config, hv, "/opt/worker/1.0.0/remote-gpu-worker")
+	enableTestUpgrades(t, restarted, "1.1.0")
+	assert.Equal(t, newPath, restarted.workerExecutable("w1"))
+	restarted.checkWorkerRelease()
+	assert.Nil(t, restarted.upgrade.pending)
+}
+
+func TestWorkerUpgrade_RollsBackUnhealthyRelease(t *testing.T) {
+	hv := newProcessHypervisor()
+	oldPath := "/opt/worker/1.0.0/remote-gpu-worker"
+	a := newUpgradeAgent(t, hv, oldPath)
+	enableTestUpgrades(t, a, "2.0.0")
+	hv.broken = "2.0.0"
+
+	a.checkWorkerRelease()
+	require.NotNil(t, a.upgrade.pending)
+	a.rollOutWorkerRelease()
+
+	require.Eventually(t, func() bool {
+		exes := hv.executables()
+		return exes["w1"] == oldPath && exes["w2"] == oldPath
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "1.0.0", a.upgrade.version)
+	assert.Equal(t, []string{"2.0.0"}, a.upgrade.failed)
+	assert.NoDirExists(t, filepath.Join(a.config.StateDir(), workerReleasesDir, "2.0.0"))
+
+	// The release is not tried again
+	a.checkWorkerRelease()
+	assert.Nil(t, a.upgrade.pending)
+}
+
+func TestWorkerUpgrade_WaitsForWindow(t *testing.T) {
+	hv := newProcessHypervisor()
+	oldPath := "/opt/worker/1.0.0/remote-gpu-worker"
+	a := newUpgradeAgent(t, hv, oldPath)
+	enableTestUpgrades(t, a, "1.1.0")
+	window, err := ParseMaintenanceWindow("02:00-03:00")
+	require.NoError(t, err)
+	a.upgrade.cfg.Window = window
+	a.upgrade.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local) }
+
+	a.checkWorkerRelease()
+	require.NotNil(t, a.upgrade.pending)
+	a.rollOutWorkerRelease()
+
+	assert.Equal(t, map[string]string{"w1": oldPath, "w2": oldPath}, hv.executables())
+	assert.NotNil(t, a.upgrade.pending, "the release waits for the next window")
+}
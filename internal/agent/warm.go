@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"k8s.io/klog/v2"
+)
+
+// EnvEagerInit makes the worker create its GPU context when it starts
+// instead of on its first session; set for warm workers
+const EnvEagerInit = "TF_EAGER_INIT"
+
+// keptWarm reports whether a worker that does not serve is kept running,
+// refusing sessions. A forced stop overrides warm mode.
+func keptWarm(w api.WorkerConfig) bool {
+	return w.Warm && !w.Enabled && !w.ForceStop
+}
+
+// keepsWarm reports whether the agent keeps a worker running warm. The
+// connection proxy holds the worker's port and refuses its sessions; without
+// the proxy clients would reach the worker directly, so it is stopped while
+// disabled like any other.
+func (a *Agent) keepsWarm(w api.WorkerConfig) bool {
+	return a.proxy != nil && keptWarm(w)
+}
+
+// syncWarmWorkers points the share codes of warm workers at what they serve:
+// none while they are kept warm and their shares once enabled. Sessions of a
+// warm worker are refused by the connection proxy; the empty share codes
+// file keeps clients that reach the worker's backend port directly from
+// being attributed to a share. Enabling a warm worker only rewrites the
+// file and lets the proxy forward; the process keeps its GPU context.
+func (a *Agent) syncWarmWorkers(workers []api.WorkerConfig) {
+	warm := make(map[string]bool)
+	unproxied := make(map[string]bool)
+	for _, w := range workers {
+		if !w.Warm {
+			continue
+		}
+		codes := w.ShareCodes
+		if keptWarm(w) && a.proxy == nil {
+			unproxied[w.WorkerID] = true
+		} else if keptWarm(w) {
+			warm[w.WorkerID] = true
+			codes = nil
+		}
+		if err := a.writeWorkerShareCodes(w.WorkerID, codes); err != nil {
+			klog.Warningf("Failed to write share codes of warm worker: worker_id=%s error=%v", w.WorkerID, err)
+		}
+	}
+
+	a.mu.Lock()
+	prev, prevUnproxied := a.warm, a.warmUnproxied
+	a.warm, a.warmUnproxied = warm, unproxied
+	a.mu.Unlock()
+	for id := range unproxied {
+		if !prevUnproxied[id] {
+			klog.Warningf("Not keeping disabled worker warm, warm workers need the connection proxy (--proxy): worker_id=%s", id)
+		}
+	}
+	for id := range warm {
+		if !prev[id] {
+			klog.Infof("Keeping worker warm, refusing sessions until enabled: worker_id=%s", id)
+		}
+	}
+	for id := range prev {
+		if !warm[id] {
+			klog.Infof("Warm worker no longer kept warm: worker_id=%s", id)
+		}
+	}
+}
+
+// isWarm reports whether a worker is running warm, refusing sessions
+func (a *Agent) isWarm(workerID string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.warm[workerID]
+}
+
+// writeWorkerShareCodes replaces the share codes a worker accepts; unlike
+// writeShareCodes it empties the file when there are none
+func (a *Agent) writeWorkerShareCodes(workerID string, codes []string) error {
+	if len(codes) > 0 {
+		return a.writeShareCodes(workerID, codes)
+	}
+	if err := os.MkdirAll(a.paths.ConfigDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	path := filepath.Join(a.paths.ConfigDir(), workerID+"_share_codes")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		return fmt.Errorf("failed to write share codes file: %w", err)
+	}
+	return nil
+}
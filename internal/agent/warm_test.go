@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeptWarm(t *testing.T) {
+	assert.True(t, keptWarm(api.WorkerConfig{Warm: true}))
+	assert.False(t, keptWarm(api.WorkerConfig{Warm: true, Enabled: true}), "an enabled worker serves")
+	assert.False(t, keptWarm(api.WorkerConfig{Warm: true, ForceStop: true}), "a forced stop overrides warm mode")
+	assert.False(t, keptWarm(api.WorkerConfig{}))
+}
+
+func TestWarmWorker_ActivatesWithoutRestart(t *testing.T) {
+	hv := newProcessHypervisor()
+	tmpDir := t.TempDir()
+	configMgr := config.NewManager(filepath.Join(tmpDir, "config"), filepath.Join(tmpDir, "state"))
+	require.NoError(t, configMgr.SaveConfig(&config.Config{
+		AgentID: "agent_test123",
+		License: api.License{Plain: "test|pro|9999999999", Encrypted: "enc"},
+	}))
+	a := NewAgentWithHypervisor(api.NewClient(), configMgr, hv, "/opt/worker/remote-gpu-worker")
+	a.connectionsDir = filepath.Join(tmpDir, "connections")
+	a.EnableConnectionProxy()
+	a.proxy.listen = func(int) (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+	t.Cleanup(a.proxy.Stop)
+	a.workerConfigs = []api.WorkerConfig{
+		{WorkerID: "w1", ListenPort: 9001, Warm: true, ShareCodes: []string{"code1"}},
+		{WorkerID: "w2", ListenPort: 9002},
+	}
+	a.reconciler.Start()
+	t.Cleanup(a.reconciler.Stop)
+
+	codesPath := filepath.Join(tmpDir, "config", "w1_share_codes")
+	running := func() map[string]int64 {
+		pids := make(map[string]int64)
+		for _, w := range hv.ListWorkers() {
+			pids[w.WorkerUID] = int64(w.WorkerRunningInfo.PID)
+		}
+		return pids
+	}
+
+	require.NoError(t, a.applyWorkers())
+	require.Eventually(t, func() bool { return len(running()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	pid, ok := running()["w1"]
+	require.True(t, ok, "the disabled warm worker runs")
+	assert.True(t, a.isWarm("w1"))
+	codes, err := os.ReadFile(codesPath)
+	require.NoError(t, err)
+	assert.Empty(t, codes, "a warm worker is authorized for no share")
+	assert.Equal(t, "1", hv.ListWorkers()[0].WorkerRunningInfo.Env[EnvEagerInit])
+
+	// The proxy holds the port and refuses sessions
+	a.proxy.mu.Lock()
+	require.Contains(t, a.proxy.listeners, "w1")
+	addr := a.proxy.listeners["w1"].ln.Addr().String()
+	a.proxy.mu.Unlock()
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "a warm worker refuses sessions")
+	_ = conn.Close()
+
+	a.mu.Lock()
+	a.workerConfigs[0].Enabled = true
+	a.mu.Unlock()
+	require.NoError(t, a.applyWorkers())
+	assert.False(t, a.isWarm("w1"))
+	codes, err = os.ReadFile(codesPath)
+	require.NoError(t, err)
+	assert.Equal(t, "code1\n", string(codes))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, map[string]int64{"w1": pid}, running(), "enabling keeps the warm process")
+	a.proxy.mu.Lock()
+	assert.False(t, a.proxy.listeners["w1"].refuse, "the proxy forwards once enabled")
+	a.proxy.mu.Unlock()
+
+	// A forced stop stops the worker instead of keeping it warm
+	a.mu.Lock()
+	a.workerConfigs[0].Enabled = false
+	a.workerConfigs[0].ForceStop = true
+	a.mu.Unlock()
+	require.NoError(t, a.applyWorkers())
+	require.Eventually(t, func() bool { return len(running()) == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWarmWorker_NeedsConnectionProxy(t *testing.T) {
+	hv := newProcessHypervisor()
+	tmpDir := t.TempDir()
+	configMgr := config.NewManager(filepath.Join(tmpDir, "config"), filepath.Join(tmpDir, "state"))
+	require.NoError(t, configMgr.SaveConfig(&config.Config{
+		AgentID: "agent_test123",
+		License: api.License{Plain: "test|pro|9999999999", Encrypted: "enc"},
+	}))
+	a := NewAgentWithHypervisor(api.NewClient(), configMgr, hv, "/opt/worker/remote-gpu-worker")
+	a.connectionsDir = filepath.Join(tmpDir, "connections")
+	a.workerConfigs = []api.WorkerConfig{{WorkerID: "w1", ListenPort: 9001, Warm: true, ShareCodes: []string{"code1"}}}
+	a.reconciler.Start()
+	t.Cleanup(a.reconciler.Stop)
+
+	// Without the proxy nothing would refuse the worker's sessions
+	require.NoError(t, a.applyWorkers())
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, hv.ListWorkers(), "the disabled worker is stopped")
+	assert.False(t, a.isWarm("w1"))
+	codes, err := os.ReadFile(filepath.Join(tmpDir, "config", "w1_share_codes"))
+	require.NoError(t, err)
+	assert.Equal(t, "code1\n", string(codes))
+}
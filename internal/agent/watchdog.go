@@ -0,0 +1,472 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// watchdogInterval is how often the watchdog checks the agent's subsystems
+	watchdogInterval = 15 * time.Second
+
+	// watchdogMinStall is the least time the status loop or the heartbeat may
+	// go without progress before it counts as stuck. Agents reporting less
+	// often get watchdogStallIntervals report intervals instead.
+	watchdogMinStall       = 2 * time.Minute
+	watchdogStallIntervals = 3
+
+	// hypervisorProbeTimeout is how long listing the GPUs may take before the
+	// hypervisor counts as hanging
+	hypervisorProbeTimeout = time.Minute
+	// watchdogPingTimeout bounds the request telling whether the platform is
+	// reachable while the heartbeat is dead
+	watchdogPingTimeout = 10 * time.Second
+
+	// watchdogRecoveries is how many times a stuck status loop or heartbeat
+	// is restarted, watchdogRecoveryGrace apart, before the whole agent is
+	// restarted
+	watchdogRecoveries    = 2
+	watchdogRecoveryGrace = time.Minute
+	// watchdogRestartBackoff is the least time between two agent restarts by
+	// the watchdog, so a fault a restart does not cure cannot loop restarts
+	watchdogRestartBackoff = time.Hour
+
+	// watchdogDir holds the diagnostic dumps and the watchdog state
+	watchdogDir = "watchdog"
+	// watchdogStateFile records the last agent restart by the watchdog
+	watchdogStateFile = "watchdog.json"
+	// watchdogMaxDumps bounds the dumps kept; older ones are removed
+	watchdogMaxDumps = 10
+)
+
+// Subsystems the watchdog watches
+const (
+	watchStatusLoop = "status_loop"
+	watchHypervisor = "hypervisor"
+	watchHeartbeat  = "heartbeat"
+)
+
+// watchdogState survives agent restarts
+type watchdogState struct {
+	LastRestartAt time.Time `json:"last_restart_at"`
+	Subsystem     string    `json:"subsystem"`
+	Reason        string    `json:"reason"`
+}
+
+// watchdogRecovery tracks the restarts of a stuck subsystem
+type watchdogRecovery struct {
+	attempts int
+	lastAt   time.Time
+}
+
+// watchdog tracks the progress of the agent's subsystems. Subsystems mark
+// their progress on it and the watchdog loop judges it.
+type watchdog struct {
+	mu sync.Mutex
+	// started is when watching began; it stands in for progress a subsystem
+	// has not made yet
+	started time.Time
+
+	// Status report passes
+	passStarted  time.Time // zero between passes
+	passDone     time.Time
+	passDuration time.Duration // of the last completed pass
+	passCancel   context.CancelFunc
+
+	// Hypervisor probes, run by the watchdog in the background
+	probeStarted  time.Time // zero while no probe is in flight
+	probeDuration time.Duration
+
+	recoveries map[string]*watchdogRecovery
+	// restarting is set once an agent restart was requested
+	restarting bool
+}
+
+func newWatchdog() *watchdog {
+	return &watchdog{started: time.Now(), recoveries: make(map[string]*watchdogRecovery)}
+}
+
+// beginStatusPass marks the start of a status report pass and returns the
+// context for its requests, which the watchdog cancels if the pass stalls
+func (w *watchdog) beginStatusPass(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+	w.mu.Lock()
+	w.passStarted = time.Now()
+	w.passCancel = cancel
+	w.mu.Unlock()
+	return ctx
+}
+
+// endStatusPass marks the end of the pass begun last, whatever its outcome
+func (w *watchdog) endStatusPass() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	if !w.passStarted.IsZero() {
+		w.passDuration = now.Sub(w.passStarted)
+	}
+	w.passStarted = time.Time{}
+	w.passDone = now
+	if w.passCancel != nil {
+		w.passCancel()
+		w.passCancel = nil
+	}
+}
+
+// statusStalled returns how long the status loop has gone without completing
+// a pass, if that is longer than stall
+func (w *watchdog) statusStalled(now time.Time, stall time.Duration) (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	since := w.passDone
+	if since.IsZero() {
+		since = w.started
+	}
+	return now.Sub(since), now.Sub(since) > stall
+}
+
+// cancelStatusPass aborts the requests of the pass in progress
+func (w *watchdog) cancelStatusPass() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.passCancel != nil {
+		w.passCancel()
+	}
+}
+
+// startProbe marks a hypervisor probe in flight. It returns false while the
+// previous one has not returned, and how long that has been.
+func (w *watchdog) startProbe(now time.Time) (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.probeStarted.IsZero() {
+		return now.Sub(w.probeStarted), false
+	}
+	w.probeStarted = now
+	return 0, true
+}
+
+func (w *watchdog) endProbe() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.probeDuration = time.Since(w.probeStarted)
+	w.probeStarted = time.Time{}
+}
+
+// recover decides what to do about a stuck subsystem: restart it, give the
+// last restart time to work, or restart the agent once maxRestarts did not
+// help
+func (w *watchdog) recover(subsystem string, maxRestarts int, now time.Time) (restartComponent, restartAgent bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.restarting {
+		return false, false
+	}
+	r := w.recoveries[subsystem]
+	if r == nil {
+		r = &watchdogRecovery{}
+		w.recoveries[subsystem] = r
+	}
+	if r.attempts > 0 && now.Sub(r.lastAt) < watchdogRecoveryGrace {
+		return false, false
+	}
+	if r.attempts >= maxRestarts {
+		w.restarting = true
+		return false, true
+	}
+	r.attempts++
+	r.lastAt = now
+	return true, false
+}
+
+// deferRestart keeps watching a subsystem whose agent restart was held back,
+// to try again after watchdogRecoveryGrace
+func (w *watchdog) deferRestart(subsystem string, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.restarting = false
+	if r := w.recoveries[subsystem]; r != nil {
+		r.lastAt = now
+	}
+}
+
+// healthy forgets the restarts of a subsystem that recovered
+func (w *watchdog) healthy(subsystem string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if r := w.recoveries[subsystem]; r != nil {
+		klog.Infof("Watchdog: subsystem recovered: subsystem=%s restarts=%d", subsystem, r.attempts)
+		delete(w.recoveries, subsystem)
+	}
+}
+
+// timings describes the progress of every subsystem for a diagnostic dump
+func (w *watchdog) timings(now time.Time) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	since := func(t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return now.Sub(t).Round(time.Millisecond).String() + " ago"
+	}
+	lines := []string{
+		"watching_since: " + since(w.started),
+		"status_pass_in_progress_since: " + since(w.passStarted),
+		"status_pass_last_done: " + since(w.passDone),
+		"status_pass_last_duration: " + w.passDuration.String(),
+		"hypervisor_probe_in_flight_since: " + since(w.probeStarted),
+		"hypervisor_probe_last_duration: " + w.probeDuration.String(),
+	}
+	subsystems := make([]string, 0, len(w.recoveries))
+	for s := range w.recoveries {
+		subsystems = append(subsystems, s)
+	}
+	slices.Sort(subsystems)
+	for _, s := range subsystems {
+		lines = append(lines, fmt.Sprintf("restarts_%s: %d", s, w.recoveries[s].attempts))
+	}
+	return lines
+}
+
+// watchdogLoop checks the agent's subsystems until the agent stops
+func (a *Agent) watchdogLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.checkWatchdog(time.Now())
+		}
+	}
+}
+
+// checkWatchdog checks each subsystem once
+func (a *Agent) checkWatchdog(now time.Time) {
+	stall := a.watchdogStall()
+
+	if elapsed, stuck := a.watchdog.statusStalled(now, stall); stuck {
+		a.handleStuck(watchStatusLoop, fmt.Sprintf("no status report pass completed for %s", elapsed.Round(time.Second)), now)
+		// The heartbeat depends on the status loop; it is judged once that
+		// is back
+		return
+	}
+	a.watchdog.healthy(watchStatusLoop)
+
+	if a.hypervisorMgr != nil && a.hypervisorMgr.IsStarted() {
+		a.checkHypervisor(now)
+	}
+	a.checkHeartbeat(now, stall)
+}
+
+// checkHypervisor starts a hypervisor probe, or judges the one still running
+func (a *Agent) checkHypervisor(now time.Time) {
+	elapsed, ok := a.watchdog.startProbe(now)
+	if !ok {
+		if elapsed > hypervisorProbeTimeout {
+			a.handleStuck(watchHypervisor, fmt.Sprintf("listing GPUs has not returned for %s", elapsed.Round(time.Second)), now)
+		}
+		return
+	}
+	a.watchdog.healthy(watchHypervisor)
+	go func() {
+		defer a.watchdog.endProbe()
+		if _, err := a.hypervisorMgr.ListDevices(); err != nil {
+			klog.V(4).Infof("Watchdog: hypervisor probe failed: error=%v", err)
+		}
+	}()
+}
+
+// checkHeartbeat judges the heartbeat dead when no report was accepted for
+// stall while the platform answers other requests. An unreachable platform
+// is left to the offline report queue.
+func (a *Agent) checkHeartbeat(now time.Time, stall time.Duration) {
+	a.mu.RLock()
+	last := a.lastReportAt
+	a.mu.RUnlock()
+	if last.IsZero() || last.Before(a.watchdog.started) {
+		last = a.watchdog.started
+	}
+	if now.Sub(last) <= stall {
+		a.watchdog.healthy(watchHeartbeat)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, watchdogPingTimeout)
+	defer cancel()
+	if err := a.client.PingAgentNetTest(ctx, a.agentID); err != nil {
+		klog.V(2).Infof("Watchdog: heartbeat dead with the platform unreachable, not intervening: error=%v", err)
+		return
+	}
+	a.handleStuck(watchHeartbeat, fmt.Sprintf("no status report accepted for %s though the platform is reachable", now.Sub(last).Round(time.Second)), now)
+}
+
+// watchdogStall returns how long the status loop and the heartbeat may go
+// without progress, from the effective report or keepalive interval
+func (a *Agent) watchdogStall() time.Duration {
+	settings := a.reportSettings()
+	interval := settings.Interval
+	if settings.changesOnly() && settings.Keepalive > interval {
+		interval = settings.Keepalive
+	}
+	return max(watchdogMinStall, watchdogStallIntervals*interval)
+}
+
+// handleStuck records a dump for a stuck subsystem and restarts it, or the
+// agent once restarting the subsystem did not help
+func (a *Agent) handleStuck(subsystem, reason string, now time.Time) {
+	// The hypervisor runs in the agent and owns the workers, so only an agent
+	// restart, which hands the workers over, restarts it
+	maxRestarts := watchdogRecoveries
+	if subsystem == watchHypervisor {
+		maxRestarts = 0
+	}
+	restartComponent, restartAgent := a.watchdog.recover(subsystem, maxRestarts, now)
+	if !restartComponent && !restartAgent {
+		return
+	}
+
+	dump, err := a.writeWatchdogDump(subsystem, reason, now)
+	if err != nil {
+		klog.Warningf("Watchdog: failed to write diagnostic dump: error=%v", err)
+	}
+
+	if restartComponent {
+		klog.Warningf("Watchdog: subsystem stuck, restarting it: subsystem=%s reason=%q dump=%s", subsystem, reason, dump)
+		a.restartSubsystem(subsystem)
+		return
+	}
+	a.escalateRestart(subsystem, reason, dump, now)
+}
+
+// restartSubsystem aborts the stuck status report pass, if any, and drops
+// the connection to the platform so the next report dials a new one
+func (a *Agent) restartSubsystem(subsystem string) {
+	if subsystem == watchStatusLoop {
+		a.watchdog.cancelStatusPass()
+	}
+	if err := a.client.Close(); err != nil {
+		klog.V(4).Infof("Watchdog: failed to close API connection: error=%v", err)
+	}
+}
+
+// escalateRestart reports the restart and asks the process running the agent
+// to restart it with its workers, unless the watchdog restarted it within
+// watchdogRestartBackoff
+func (a *Agent) escalateRestart(subsystem, reason, dump string, now time.Time) {
+	statePath := filepath.Join(a.config.StateDir(), watchdogDir, watchdogStateFile)
+	prev, err := utils.LoadJSON[watchdogState](statePath)
+	if err != nil {
+		klog.Warningf("Watchdog: ignoring unreadable state: path=%s error=%v", statePath, err)
+	}
+	details := map[string]string{"subsystem": subsystem, "reason": reason}
+	if dump != "" {
+		details["dump"] = dump
+	}
+
+	if prev != nil && now.Sub(prev.LastRestartAt) < watchdogRestartBackoff {
+		klog.Errorf("Watchdog: subsystem still stuck after an agent restart, not restarting again before %s: subsystem=%s reason=%q dump=%s",
+			prev.LastRestartAt.Add(watchdogRestartBackoff).Format(time.RFC3339), subsystem, reason, dump)
+		details["last_restart_at"] = prev.LastRestartAt.UTC().Format(time.RFC3339)
+		a.recordEvent(api.AgentEvent{
+			Type:     api.AgentEventWatchdog,
+			Severity: api.AgentEventSeverityError,
+			Message:  fmt.Sprintf("Agent %s stuck after a restart: %s", strings.ReplaceAll(subsystem, "_", " "), reason),
+			Details:  details,
+		}, api.AgentEventWatchdog+"/"+subsystem+"/restart")
+		a.watchdog.deferRestart(subsystem, now)
+		return
+	}
+
+	klog.Errorf("Watchdog: subsystem still stuck, restarting the agent: subsystem=%s reason=%q dump=%s", subsystem, reason, dump)
+	a.recordEvent(api.AgentEvent{
+		Type:     api.AgentEventWatchdog,
+		Severity: api.AgentEventSeverityError,
+		Message:  fmt.Sprintf("Agent restarted by its watchdog: %s stuck, %s", strings.ReplaceAll(subsystem, "_", " "), reason),
+		Details:  details,
+	}, api.AgentEventWatchdog+"/"+subsystem+"/restart")
+
+	state := watchdogState{LastRestartAt: now, Subsystem: subsystem, Reason: reason}
+	if err := utils.SaveJSON(statePath, state, 0644); err != nil {
+		klog.Warningf("Watchdog: failed to save state: path=%s error=%v", statePath, err)
+	}
+	if err := RequestRestart(a.config.StateDir(), RestartRequest{RequestedAt: now, PreserveWorkers: true}); err != nil {
+		klog.Errorf("Watchdog: failed to request agent restart: error=%v", err)
+	}
+}
+
+// writeWatchdogDump writes the timings of the subsystems and the stacks of
+// all goroutines to a new file in the watchdog directory, keeping the latest
+// watchdogMaxDumps, and returns its path
+func (a *Agent) writeWatchdogDump(subsystem, reason string, now time.Time) (string, error) {
+	dir := filepath.Join(a.config.StateDir(), watchdogDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create watchdog directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("dump-%s-%s.txt", now.UTC().Format("20060102T150405.000Z"), subsystem))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dump: %w", err)
+	}
+
+	a.mu.RLock()
+	lastReport, offlineSince := a.lastReportAt, a.offlineSince
+	a.mu.RUnlock()
+	fmt.Fprintf(f, "GPU Go agent watchdog dump\n\n")
+	fmt.Fprintf(f, "time: %s\nagent_id: %s\npid: %d\nsubsystem: %s\nreason: %s\ngoroutines: %d\n",
+		now.UTC().Format(time.RFC3339Nano), a.agentID, os.Getpid(), subsystem, reason, runtime.NumGoroutine())
+	fmt.Fprintf(f, "last_report_at: %s\noffline_since: %s\n", formatDumpTime(lastReport), formatDumpTime(offlineSince))
+	for _, line := range a.watchdog.timings(now) {
+		fmt.Fprintln(f, line)
+	}
+	fmt.Fprintf(f, "\n")
+	werr := pprof.Lookup("goroutine").WriteTo(f, 2)
+	if err := f.Close(); werr == nil {
+		werr = err
+	}
+	if werr != nil {
+		return "", fmt.Errorf("failed to write dump: %w", werr)
+	}
+
+	pruneWatchdogDumps(dir)
+	return path, nil
+}
+
+func formatDumpTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// pruneWatchdogDumps removes all but the latest watchdogMaxDumps dumps in dir
+func pruneWatchdogDumps(dir string) {
+	dumps, err := filepath.Glob(filepath.Join(dir, "dump-*.txt"))
+	if err != nil || len(dumps) <= watchdogMaxDumps {
+		return
+	}
+	// Names start with the UTC time, so they sort oldest first
+	slices.Sort(dumps)
+	for _, path := range dumps[:len(dumps)-watchdogMaxDumps] {
+		if err := os.Remove(path); err != nil {
+			klog.V(4).Infof("Watchdog: failed to remove old dump: path=%s error=%v", path, err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hangingHypervisor never returns from listing GPUs until released
+type hangingHypervisor struct {
+	mockHypervisorManager
+	release chan struct{}
+}
+
+func (h *hangingHypervisor) ListDevices() ([]*hvApi.DeviceInfo, error) {
+	<-h.release
+	return nil, nil
+}
+
+func newWatchdogAgent(t *testing.T, serverURL string) *Agent {
+	t.Helper()
+	tmpDir := t.TempDir()
+	configMgr := config.NewManager(filepath.Join(tmpDir, "config"), filepath.Join(tmpDir, "state"))
+	return &Agent{
+		client:   api.NewClient(api.WithBaseURL(serverURL), api.WithAgentSecret("gpugo_secret")),
+		config:   configMgr,
+		ctx:      context.Background(),
+		agentID:  "agent_1",
+		events:   newEventQueue(filepath.Join(configMgr.StateDir(), eventsFile)),
+		watchdog: newWatchdog(),
+	}
+}
+
+func watchdogDumps(t *testing.T, a *Agent) []string {
+	t.Helper()
+	dumps, err := filepath.Glob(filepath.Join(a.config.StateDir(), watchdogDir, "dump-*.txt"))
+	require.NoError(t, err)
+	return dumps
+}
+
+func TestWatchdog_Recover(t *testing.T) {
+	w := newWatchdog()
+	now := time.Now()
+
+	restart, escalate := w.recover(watchStatusLoop, 2, now)
+	assert.True(t, restart)
+	assert.False(t, escalate)
+	restart, escalate = w.recover(watchStatusLoop, 2, now.Add(30*time.Second))
+	assert.False(t, restart || escalate, "a restart gets time to work")
+	restart, _ = w.recover(watchStatusLoop, 2, now.Add(watchdogRecoveryGrace))
+	assert.True(t, restart)
+	_, escalate = w.recover(watchStatusLoop, 2, now.Add(2*watchdogRecoveryGrace))
+	assert.True(t, escalate, "restarts did not help")
+	restart, escalate = w.recover(watchHeartbeat, 2, now.Add(3*watchdogRecoveryGrace))
+	assert.False(t, restart || escalate, "nothing more once the agent restarts")
+
+	w = newWatchdog()
+	_, escalate = w.recover(watchHypervisor, 0, now)
+	assert.True(t, escalate)
+
+	w = newWatchdog()
+	w.recover(watchHeartbeat, 2, now)
+	w.healthy(watchHeartbeat)
+	restart, _ = w.recover(watchHeartbeat, 2, now.Add(time.Second))
+	assert.True(t, restart, "a recovered subsystem starts over")
+}
+
+func TestAgent_WatchdogRestartsStuckStatusLoop(t *testing.T) {
+	a := newWatchdogAgent(t, "http://127.0.0.1:1")
+	stateDir := a.config.StateDir()
+	start := time.Now()
+
+	passCtx := a.watchdog.beginStatusPass(context.Background())
+	a.checkWatchdog(start.Add(time.Minute))
+	assert.NoError(t, passCtx.Err(), "a pass within the stall time is left alone")
+
+	now := start.Add(watchdogMinStall + time.Second)
+	a.checkWatchdog(now)
+	assert.ErrorIs(t, passCtx.Err(), context.Canceled, "the stuck pass is aborted")
+	dumps := watchdogDumps(t, a)
+	require.Len(t, dumps, 1)
+	dump, err := os.ReadFile(dumps[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(dump), "subsystem: status_loop")
+	assert.Contains(t, string(dump), "status_pass_in_progress_since:")
+	assert.Contains(t, string(dump), "goroutine ", "goroutine stacks are dumped")
+
+	// The pass ignores the cancellation: a second restart, then the agent
+	a.checkWatchdog(now.Add(watchdogRecoveryGrace))
+	assert.False(t, RestartPending(stateDir))
+	a.checkWatchdog(now.Add(2 * watchdogRecoveryGrace))
+	assert.True(t, RestartPending(stateDir))
+	req := a.takeRestartRequest()
+	require.NotNil(t, req)
+	assert.True(t, req.PreserveWorkers)
+
+	events := a.events.peek(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, api.AgentEventWatchdog, events[0].Type)
+	assert.Equal(t, api.AgentEventSeverityError, events[0].Severity)
+	assert.Equal(t, watchStatusLoop, events[0].Details["subsystem"])
+	assert.Len(t, watchdogDumps(t, a), 3)
+
+	// Stuck again right after the restart: no restart loop
+	a.watchdog = newWatchdog()
+	a.watchdog.beginStatusPass(context.Background())
+	now = time.Now().Add(watchdogMinStall + time.Second)
+	for i := range watchdogRecoveries + 1 {
+		a.checkWatchdog(now.Add(time.Duration(i) * watchdogRecoveryGrace))
+	}
+	assert.False(t, RestartPending(stateDir))
+}
+
+func TestAgent_WatchdogHypervisorHang(t *testing.T) {
+	a := newWatchdogAgent(t, "http://127.0.0.1:1")
+	hv := &hangingHypervisor{mockHypervisorManager: mockHypervisorManager{started: true}, release: make(chan struct{})}
+	t.Cleanup(func() { close(hv.release) })
+	a.hypervisorMgr = hv
+	a.watchdog.endStatusPass()
+
+	now := time.Now()
+	a.checkWatchdog(now)
+	a.checkWatchdog(now.Add(hypervisorProbeTimeout / 2))
+	assert.False(t, RestartPending(a.config.StateDir()))
+
+	// A hung hypervisor owns the workers; only an agent restart helps
+	a.checkWatchdog(now.Add(hypervisorProbeTimeout + time.Second))
+	assert.True(t, RestartPending(a.config.StateDir()))
+	require.Len(t, watchdogDumps(t, a), 1)
+	events := a.events.peek(10)
+	require.Len(t, events, 1)
+	assert.Equal(t, watchHypervisor, events[0].Details["subsystem"])
+}
+
+func TestAgent_WatchdogHeartbeat(t *testing.T) {
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.SuccessResponse{Success: true})
+	}))
+	defer server.Close()
+
+	a := newWatchdogAgent(t, server.URL)
+	// Stalls after 3m, so the status loop stays fine while time passes
+	a.localReporting = ReportSettings{Interval: time.Minute}
+	now := time.Now()
+	a.watchdog.started = now.Add(-10 * time.Minute)
+	a.watchdog.endStatusPass()
+
+	a.lastReportAt = now.Add(-time.Minute)
+	a.checkWatchdog(now)
+	assert.Empty(t, watchdogDumps(t, a))
+
+	// Reports stopped being accepted while the platform answers
+	a.lastReportAt = now.Add(-3*time.Minute - time.Second)
+	a.checkWatchdog(now)
+	require.Len(t, watchdogDumps(t, a), 1)
+	assert.Equal(t, 1, a.watchdog.recoveries[watchHeartbeat].attempts)
+
+	// An unreachable platform is not the agent's fault
+	down.Store(true)
+	for i := 1; i <= watchdogRecoveries; i++ {
+		a.checkWatchdog(now.Add(time.Duration(i) * watchdogRecoveryGrace))
+	}
+	assert.Len(t, watchdogDumps(t, a), 1)
+	assert.False(t, RestartPending(a.config.StateDir()))
+
+	// Reports are accepted again
+	down.Store(false)
+	a.lastReportAt = now
+	a.checkWatchdog(now)
+	assert.NotContains(t, a.watchdog.recoveries, watchHeartbeat)
+}
+
+func TestWatchdogStall(t *testing.T) {
+	on := true
+	a := &Agent{}
+	assert.Equal(t, watchdogMinStall, a.watchdogStall())
+	a.localReporting = ReportSettings{Interval: time.Minute}
+	assert.Equal(t, 3*time.Minute, a.watchdogStall())
+	a.localReporting = ReportSettings{Interval: time.Minute, ChangesOnly: &on, Keepalive: 10 * time.Minute}
+	assert.Equal(t, 30*time.Minute, a.watchdogStall(), "changes-only agents report every keepalive")
+}
+
+func TestPruneWatchdogDumps(t *testing.T) {
+	dir := t.TempDir()
+	for i := range watchdogMaxDumps + 3 {
+		name := fmt.Sprintf("dump-20261016T1200%02d.000Z-heartbeat.txt", i)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	pruneWatchdogDumps(dir)
+	dumps, err := filepath.Glob(filepath.Join(dir, "dump-*.txt"))
+	require.NoError(t, err)
+	require.Len(t, dumps, watchdogMaxDumps)
+	assert.Equal(t, "dump-20261016T120003.000Z-heartbeat.txt", filepath.Base(dumps[0]), "the oldest are removed")
+}
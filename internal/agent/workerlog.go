@@ -0,0 +1,264 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultWorkerLogMaxSizeMB is the size at which captured worker output
+	// is rotated
+	DefaultWorkerLogMaxSizeMB = 50
+	// DefaultWorkerLogMaxFiles is how many rotated output files, and how many
+	// of the logs the worker writes itself, are kept per worker
+	DefaultWorkerLogMaxFiles = 5
+
+	// crashLoopThreshold abnormal exits within crashLoopWindow put a worker
+	// in crash loop; it leaves it after crashLoopWindow without a crash
+	crashLoopThreshold = 3
+	crashLoopWindow    = 10 * time.Minute
+	// crashLoopErrorLines is how many error lines a crash loop carries
+	crashLoopErrorLines = 10
+)
+
+// errorLinePattern picks the lines of worker output that explain a failure
+var errorLinePattern = regexp.MustCompile(`(?i)\b(error|fatal|panic|failed|failure|exception|abort(ed)?)\b`)
+
+// WorkerLogSettings tunes the rotation of captured worker output. Zero values
+// leave the setting to the server's agent config, or the default.
+type WorkerLogSettings struct {
+	MaxSizeMB int
+	MaxFiles  int
+}
+
+// merge fills the unset settings of s from other
+func (s WorkerLogSettings) merge(other WorkerLogSettings) WorkerLogSettings {
+	if s.MaxSizeMB <= 0 {
+		s.MaxSizeMB = other.MaxSizeMB
+	}
+	if s.MaxFiles <= 0 {
+		s.MaxFiles = other.MaxFiles
+	}
+	return s
+}
+
+// SetWorkerLogSettings sets local worker log settings, which take precedence
+// over the server's agent config. Must be called before Start.
+func (a *Agent) SetWorkerLogSettings(s WorkerLogSettings) {
+	a.mu.Lock()
+	a.localWorkerLogs = s
+	a.mu.Unlock()
+}
+
+// applyWorkerLogConfig takes the worker log settings from the server's agent
+// config; they apply to workers started from then on
+func (a *Agent) applyWorkerLogConfig(cfg *api.WorkerLogConfig) {
+	var server WorkerLogSettings
+	if cfg != nil {
+		server = WorkerLogSettings{MaxSizeMB: cfg.MaxSizeMB, MaxFiles: cfg.MaxFiles}
+	}
+	a.mu.Lock()
+	a.serverWorkerLogs = server
+	a.mu.Unlock()
+}
+
+// workerLogSettings returns the effective worker log settings: local
+// settings, then the server's, then the defaults
+func (a *Agent) workerLogSettings() WorkerLogSettings {
+	a.mu.RLock()
+	s := a.localWorkerLogs.merge(a.serverWorkerLogs)
+	a.mu.RUnlock()
+	return s.merge(WorkerLogSettings{MaxSizeMB: DefaultWorkerLogMaxSizeMB, MaxFiles: DefaultWorkerLogMaxFiles})
+}
+
+// EnableWorkerOutputCapture starts workers through launcher, the ggo
+// binary, as 'ggo agent worker-exec', which appends their stdout and stderr
+// to a rotated file. Must be called before Start.
+func (a *Agent) EnableWorkerOutputCapture(launcher string) {
+	a.workerLauncher = launcher
+}
+
+// WorkerOutputLog returns the file holding a worker's captured stdout and
+// stderr; rotated files have the suffixes .1, .2 and so on
+func WorkerOutputLog(stateDir, workerID string) string {
+	return filepath.Join(workerLogsDir(stateDir), "output-"+workerID+".log")
+}
+
+// captureWorkerOutput makes running redirect the worker's output to its
+// output log when output capture is enabled. The worker binary and its
+// arguments follow "--" in the launcher's arguments.
+func (a *Agent) captureWorkerOutput(workerID string, running *hvApi.WorkerRunningInfo) {
+	if a.workerLauncher == "" {
+		return
+	}
+	s := a.workerLogSettings()
+	args := []string{"agent", "worker-exec",
+		"--log", WorkerOutputLog(a.config.StateDir(), workerID),
+		"--max-size-mb", strconv.Itoa(s.MaxSizeMB),
+		"--max-files", strconv.Itoa(s.MaxFiles),
+		"--", running.Executable,
+	}
+	running.Args = append(args, running.Args...)
+	running.Executable = a.workerLauncher
+}
+
+// workerBinary returns the remote-gpu-worker binary a worker runs, also when
+// it is started through the output capturing launcher
+func workerBinary(running *hvApi.WorkerRunningInfo) string {
+	if i := slices.Index(running.Args, "--"); i >= 0 && i+1 < len(running.Args) {
+		return running.Args[i+1]
+	}
+	return running.Executable
+}
+
+// pruneWorkerLogs deletes all but the keep most recent logs a worker wrote
+// itself, one per start
+func pruneWorkerLogs(logsDir, workerID string, keep int) {
+	matches, err := filepath.Glob(filepath.Join(logsDir, fmt.Sprintf("worker-%s-*.log", workerID)))
+	if err != nil || len(matches) <= keep {
+		return
+	}
+	// The names end in the start time, so they sort oldest first
+	sort.Strings(matches)
+	for _, path := range matches[:len(matches)-keep] {
+		if err := os.Remove(path); err != nil {
+			klog.V(4).Infof("Failed to remove old worker log: path=%s error=%v", path, err)
+			continue
+		}
+		klog.V(4).Infof("Removed old worker log: path=%s", path)
+	}
+}
+
+// HasWorkerOutput reports whether output of a worker was captured under the
+// agent state directory
+func HasWorkerOutput(stateDir, workerID string) bool {
+	_, err := os.Stat(WorkerOutputLog(stateDir, workerID))
+	return err == nil
+}
+
+// FollowWorkerOutput is FollowWorkerLog for the worker's captured stdout and
+// stderr. After a rotation, following continues in the new file.
+func FollowWorkerOutput(ctx context.Context, stateDir, workerID string, tail int, follow bool,
+	emit func(lines []string) error) error {
+	path := WorkerOutputLog(stateDir, workerID)
+	return followLog(ctx, func() string {
+		if _, err := os.Stat(path); err != nil {
+			return ""
+		}
+		return path
+	}, workerID, tail, follow, emit)
+}
+
+// crashLoop tracks a worker in crash loop
+type crashLoop struct {
+	loop      api.WorkerCrashLoop
+	lastCrash time.Time
+}
+
+// updateCrashLoop checks the crash history of a worker after a crash and
+// puts the worker in crash loop when it crashed crashLoopThreshold times
+// within crashLoopWindow
+func (a *Agent) updateCrashLoop(report api.WorkerCrashReport, history []api.WorkerCrashReport) {
+	var crashes int
+	since := report.DetectedAt
+	for _, r := range history {
+		if report.DetectedAt.Sub(r.DetectedAt) > crashLoopWindow {
+			continue
+		}
+		crashes += max(r.Exits, 1)
+		if r.DetectedAt.Before(since) {
+			since = r.DetectedAt
+		}
+	}
+	if crashes < crashLoopThreshold {
+		return
+	}
+
+	loop := &crashLoop{
+		loop: api.WorkerCrashLoop{
+			Crashes:    crashes,
+			Since:      since,
+			LastErrors: a.lastWorkerErrors(report),
+		},
+		lastCrash: report.DetectedAt,
+	}
+	a.crashMu.Lock()
+	_, was := a.crashLoops[report.WorkerID]
+	a.crashLoops[report.WorkerID] = loop
+	a.crashMu.Unlock()
+	if !was {
+		klog.Warningf("Worker in crash loop: worker_id=%s crashes=%d since=%s last_error=%q",
+			report.WorkerID, crashes, since.Format(time.RFC3339), lastOrEmpty(loop.loop.LastErrors))
+	}
+}
+
+// workerCrashLoop returns the crash loop of a worker for its status, or nil
+// once the worker ran crashLoopWindow without crashing
+func (a *Agent) workerCrashLoop(workerID string, now time.Time) *api.WorkerCrashLoop {
+	a.crashMu.Lock()
+	defer a.crashMu.Unlock()
+	loop, ok := a.crashLoops[workerID]
+	if !ok {
+		return nil
+	}
+	if now.Sub(loop.lastCrash) > crashLoopWindow {
+		delete(a.crashLoops, workerID)
+		klog.Infof("Worker left crash loop: worker_id=%s", workerID)
+		return nil
+	}
+	result := loop.loop
+	return &result
+}
+
+// lastWorkerErrors returns the error lines a crashed worker printed last:
+// from its captured output, or else from the tail of its own log
+func (a *Agent) lastWorkerErrors(report api.WorkerCrashReport) []string {
+	if tail, err := readLogTail(WorkerOutputLog(a.config.StateDir(), report.WorkerID), crashLogTailBytes); err == nil {
+		if lines := lastErrorLines(tail, crashLoopErrorLines); len(lines) > 0 {
+			return lines
+		}
+	}
+	return lastErrorLines(report.LogTail, crashLoopErrorLines)
+}
+
+// lastErrorLines returns the last n lines of text that look like errors, or
+// its last n lines when none do
+func lastErrorLines(text string, n int) []string {
+	var all, errs []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		all = append(all, line)
+		if errorLinePattern.MatchString(line) {
+			errs = append(errs, line)
+		}
+	}
+	if len(errs) == 0 {
+		errs = all
+	}
+	if len(errs) > n {
+		errs = errs[len(errs)-n:]
+	}
+	return errs
+}
+
+func lastOrEmpty(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[len(lines)-1]
+}
@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/config"
+	hvApi "github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerLogSettings(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(nil, config.NewManager(dir, dir))
+	assert.Equal(t, WorkerLogSettings{MaxSizeMB: DefaultWorkerLogMaxSizeMB, MaxFiles: DefaultWorkerLogMaxFiles}, a.workerLogSettings())
+
+	a.applyWorkerLogConfig(&api.WorkerLogConfig{MaxSizeMB: 10, MaxFiles: 2})
+	a.SetWorkerLogSettings(WorkerLogSettings{MaxFiles: 7})
+	assert.Equal(t, WorkerLogSettings{MaxSizeMB: 10, MaxFiles: 7}, a.workerLogSettings(), "local settings win")
+}
+
+func TestCaptureWorkerOutput(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(nil, config.NewManager(dir, dir))
+	running := &hvApi.WorkerRunningInfo{Executable: "/opt/remote-gpu-worker", Args: []string{"-p", "9001", "-n", "native"}}
+
+	a.captureWorkerOutput("w1", running)
+	assert.Equal(t, "/opt/remote-gpu-worker", running.Executable, "capture is off by default")
+
+	a.EnableWorkerOutputCapture("/usr/local/bin/ggo")
+	a.captureWorkerOutput("w1", running)
+	assert.Equal(t, "/usr/local/bin/ggo", running.Executable)
+	assert.Equal(t, []string{"agent", "worker-exec",
+		"--log", WorkerOutputLog(dir, "w1"), "--max-size-mb", "50", "--max-files", "5",
+		"--", "/opt/remote-gpu-worker", "-p", "9001", "-n", "native"}, running.Args)
+	assert.Equal(t, "/opt/remote-gpu-worker", workerBinary(running))
+}
+
+func TestPruneWorkerLogs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"worker-w1-2026-01-01_00-00-00.log",
+		"worker-w1-2026-01-02_00-00-00.log",
+		"worker-w1-2026-01-03_00-00-00.log",
+		"worker-w2-2026-01-01_00-00-00.log",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	pruneWorkerLogs(dir, "w1", 2)
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	require.NoError(t, err)
+	for i := range matches {
+		matches[i] = filepath.Base(matches[i])
+	}
+	assert.Equal(t, []string{
+		"worker-w1-2026-01-02_00-00-00.log",
+		"worker-w1-2026-01-03_00-00-00.log",
+		"worker-w2-2026-01-01_00-00-00.log",
+	}, matches)
+}
+
+func TestLastErrorLines(t *testing.T) {
+	text := "starting\nloading license\nERROR: license expired\nshutting down\nfatal: exiting\n"
+	assert.Equal(t, []string{"ERROR: license expired", "fatal: exiting"}, lastErrorLines(text, 10))
+	assert.Equal(t, []string{"fatal: exiting"}, lastErrorLines(text, 1))
+	assert.Equal(t, []string{"b", "c"}, lastErrorLines("a\nb\nc\n", 2), "without error lines the last lines are used")
+	assert.Empty(t, lastErrorLines("", 2))
+}
+
+func TestCrashLoop(t *testing.T) {
+	dir := t.TempDir()
+	a := NewAgent(nil, config.NewManager(dir, dir))
+	out := WorkerOutputLog(dir, "w1")
+	require.NoError(t, os.MkdirAll(filepath.Dir(out), 0755))
+	require.NoError(t, os.WriteFile(out, []byte("init\nerror: CUDA driver version is insufficient\n"), 0644))
+
+	start := time.Now()
+	var history []api.WorkerCrashReport
+	for i := range crashLoopThreshold {
+		report := api.WorkerCrashReport{WorkerID: "w1", Exits: 1, DetectedAt: start.Add(time.Duration(i) * time.Minute)}
+		history = append(history, report)
+		a.updateCrashLoop(report, history)
+		if i < crashLoopThreshold-1 {
+			assert.Nil(t, a.workerCrashLoop("w1", report.DetectedAt))
+		}
+	}
+
+	last := history[len(history)-1].DetectedAt
+	loop := a.workerCrashLoop("w1", last)
+	require.NotNil(t, loop)
+	assert.Equal(t, crashLoopThreshold, loop.Crashes)
+	assert.Equal(t, start, loop.Since)
+	assert.Equal(t, []string{"error: CUDA driver version is insufficient"}, loop.LastErrors)
+
+	assert.Nil(t, a.workerCrashLoop("w1", last.Add(crashLoopWindow+time.Second)), "a worker that stopped crashing leaves the loop")
+	assert.Nil(t, a.workerCrashLoop("w1", last))
+}
@@ -0,0 +1,3344 @@
+// Agent <-> platform gRPC transport. The messages mirror the JSON types of
+// the REST API in internal/api/types.go; fields keep their JSON names.
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: agent.proto
+
+package agentpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GPUPartition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Profile       string                 `protobuf:"bytes,2,opt,name=profile,proto3" json:"profile,omitempty"`
+	WorkerId      string                 `protobuf:"bytes,3,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GPUPartition) Reset() {
+	*x = GPUPartition{}
+	mi := &file_agent_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPUPartition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPUPartition) ProtoMessage() {}
+
+func (x *GPUPartition) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPUPartition.ProtoReflect.Descriptor instead.
+func (*GPUPartition) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GPUPartition) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *GPUPartition) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *GPUPartition) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+type GPUMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GpuId         string                 `protobuf:"bytes,1,opt,name=gpu_id,json=gpuId,proto3" json:"gpu_id,omitempty"`
+	Utilization   float64                `protobuf:"fixed64,2,opt,name=utilization,proto3" json:"utilization,omitempty"`
+	VramUsedMb    int64                  `protobuf:"varint,3,opt,name=vram_used_mb,json=vramUsedMb,proto3" json:"vram_used_mb,omitempty"`
+	VramTotalMb   int64                  `protobuf:"varint,4,opt,name=vram_total_mb,json=vramTotalMb,proto3" json:"vram_total_mb,omitempty"`
+	Temperature   float64                `protobuf:"fixed64,5,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	PowerUsageW   float64                `protobuf:"fixed64,6,opt,name=power_usage_w,json=powerUsageW,proto3" json:"power_usage_w,omitempty"`
+	PcieRxKb      float64                `protobuf:"fixed64,7,opt,name=pcie_rx_kb,json=pcieRxKb,proto3" json:"pcie_rx_kb,omitempty"`
+	PcieTxKb      float64                `protobuf:"fixed64,8,opt,name=pcie_tx_kb,json=pcieTxKb,proto3" json:"pcie_tx_kb,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GPUMetrics) Reset() {
+	*x = GPUMetrics{}
+	mi := &file_agent_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPUMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPUMetrics) ProtoMessage() {}
+
+func (x *GPUMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPUMetrics.ProtoReflect.Descriptor instead.
+func (*GPUMetrics) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GPUMetrics) GetGpuId() string {
+	if x != nil {
+		return x.GpuId
+	}
+	return ""
+}
+
+func (x *GPUMetrics) GetUtilization() float64 {
+	if x != nil {
+		return x.Utilization
+	}
+	return 0
+}
+
+func (x *GPUMetrics) GetVramUsedMb() int64 {
+	if x != nil {
+		return x.VramUsedMb
+	}
+	return 0
+}
+
+func (x *GPUMetrics) GetVramTotalMb() int64 {
+	if x != nil {
+		return x.VramTotalMb
+	}
+	return 0
+}
+
+func (x *GPUMetrics) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *GPUMetrics) GetPowerUsageW() float64 {
+	if x != nil {
+		return x.PowerUsageW
+	}
+	return 0
+}
+
+func (x *GPUMetrics) GetPcieRxKb() float64 {
+	if x != nil {
+		return x.PcieRxKb
+	}
+	return 0
+}
+
+func (x *GPUMetrics) GetPcieTxKb() float64 {
+	if x != nil {
+		return x.PcieTxKb
+	}
+	return 0
+}
+
+type GPUInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GpuId         string                 `protobuf:"bytes,1,opt,name=gpu_id,json=gpuId,proto3" json:"gpu_id,omitempty"`
+	GpuIndex      int32                  `protobuf:"varint,2,opt,name=gpu_index,json=gpuIndex,proto3" json:"gpu_index,omitempty"`
+	Vendor        string                 `protobuf:"bytes,3,opt,name=vendor,proto3" json:"vendor,omitempty"`
+	Model         string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`
+	VramMb        int64                  `protobuf:"varint,5,opt,name=vram_mb,json=vramMb,proto3" json:"vram_mb,omitempty"`
+	DriverVersion string                 `protobuf:"bytes,6,opt,name=driver_version,json=driverVersion,proto3" json:"driver_version,omitempty"`
+	CudaVersion   string                 `protobuf:"bytes,7,opt,name=cuda_version,json=cudaVersion,proto3" json:"cuda_version,omitempty"`
+	MigEnabled    bool                   `protobuf:"varint,8,opt,name=mig_enabled,json=migEnabled,proto3" json:"mig_enabled,omitempty"`
+	Partitions    []*GPUPartition        `protobuf:"bytes,9,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	Metrics       *GPUMetrics            `protobuf:"bytes,10,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	Health        []string               `protobuf:"bytes,11,rep,name=health,proto3" json:"health,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GPUInfo) Reset() {
+	*x = GPUInfo{}
+	mi := &file_agent_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPUInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPUInfo) ProtoMessage() {}
+
+func (x *GPUInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPUInfo.ProtoReflect.Descriptor instead.
+func (*GPUInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GPUInfo) GetGpuId() string {
+	if x != nil {
+		return x.GpuId
+	}
+	return ""
+}
+
+func (x *GPUInfo) GetGpuIndex() int32 {
+	if x != nil {
+		return x.GpuIndex
+	}
+	return 0
+}
+
+func (x *GPUInfo) GetVendor() string {
+	if x != nil {
+		return x.Vendor
+	}
+	return ""
+}
+
+func (x *GPUInfo) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *GPUInfo) GetVramMb() int64 {
+	if x != nil {
+		return x.VramMb
+	}
+	return 0
+}
+
+func (x *GPUInfo) GetDriverVersion() string {
+	if x != nil {
+		return x.DriverVersion
+	}
+	return ""
+}
+
+func (x *GPUInfo) GetCudaVersion() string {
+	if x != nil {
+		return x.CudaVersion
+	}
+	return ""
+}
+
+func (x *GPUInfo) GetMigEnabled() bool {
+	if x != nil {
+		return x.MigEnabled
+	}
+	return false
+}
+
+func (x *GPUInfo) GetPartitions() []*GPUPartition {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *GPUInfo) GetMetrics() *GPUMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *GPUInfo) GetHealth() []string {
+	if x != nil {
+		return x.Health
+	}
+	return nil
+}
+
+type RegisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Os            string                 `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
+	Arch          string                 `protobuf:"bytes,3,opt,name=arch,proto3" json:"arch,omitempty"`
+	Gpus          []*GPUInfo             `protobuf:"bytes,4,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	NetworkIps    []string               `protobuf:"bytes,5,rep,name=network_ips,json=networkIps,proto3" json:"network_ips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_agent_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RegisterRequest) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetGpus() []*GPUInfo {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetNetworkIps() []string {
+	if x != nil {
+		return x.NetworkIps
+	}
+	return nil
+}
+
+type License struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plain         string                 `protobuf:"bytes,1,opt,name=plain,proto3" json:"plain,omitempty"`
+	Encrypted     string                 `protobuf:"bytes,2,opt,name=encrypted,proto3" json:"encrypted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *License) Reset() {
+	*x = License{}
+	mi := &file_agent_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *License) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*License) ProtoMessage() {}
+
+func (x *License) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use License.ProtoReflect.Descriptor instead.
+func (*License) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *License) GetPlain() string {
+	if x != nil {
+		return x.Plain
+	}
+	return ""
+}
+
+func (x *License) GetEncrypted() string {
+	if x != nil {
+		return x.Encrypted
+	}
+	return ""
+}
+
+type RegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	AgentSecret   string                 `protobuf:"bytes,2,opt,name=agent_secret,json=agentSecret,proto3" json:"agent_secret,omitempty"`
+	License       *License               `protobuf:"bytes,3,opt,name=license,proto3" json:"license,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_agent_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RegisterResponse) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RegisterResponse) GetAgentSecret() string {
+	if x != nil {
+		return x.AgentSecret
+	}
+	return ""
+}
+
+func (x *RegisterResponse) GetLicense() *License {
+	if x != nil {
+		return x.License
+	}
+	return nil
+}
+
+type GetConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_agent_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetConfigRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+type WorkerStandby struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	PrimaryAgentId       string                 `protobuf:"bytes,1,opt,name=primary_agent_id,json=primaryAgentId,proto3" json:"primary_agent_id,omitempty"`
+	FailoverAfterSeconds int32                  `protobuf:"varint,2,opt,name=failover_after_seconds,json=failoverAfterSeconds,proto3" json:"failover_after_seconds,omitempty"`
+	Active               bool                   `protobuf:"varint,3,opt,name=active,proto3" json:"active,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *WorkerStandby) Reset() {
+	*x = WorkerStandby{}
+	mi := &file_agent_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerStandby) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerStandby) ProtoMessage() {}
+
+func (x *WorkerStandby) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerStandby.ProtoReflect.Descriptor instead.
+func (*WorkerStandby) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WorkerStandby) GetPrimaryAgentId() string {
+	if x != nil {
+		return x.PrimaryAgentId
+	}
+	return ""
+}
+
+func (x *WorkerStandby) GetFailoverAfterSeconds() int32 {
+	if x != nil {
+		return x.FailoverAfterSeconds
+	}
+	return 0
+}
+
+func (x *WorkerStandby) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+type WorkerFairness struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	PerClientComputePercent int32                  `protobuf:"varint,1,opt,name=per_client_compute_percent,json=perClientComputePercent,proto3" json:"per_client_compute_percent,omitempty"`
+	Scheduling              string                 `protobuf:"bytes,2,opt,name=scheduling,proto3" json:"scheduling,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *WorkerFairness) Reset() {
+	*x = WorkerFairness{}
+	mi := &file_agent_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerFairness) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerFairness) ProtoMessage() {}
+
+func (x *WorkerFairness) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerFairness.ProtoReflect.Descriptor instead.
+func (*WorkerFairness) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WorkerFairness) GetPerClientComputePercent() int32 {
+	if x != nil {
+		return x.PerClientComputePercent
+	}
+	return 0
+}
+
+func (x *WorkerFairness) GetScheduling() string {
+	if x != nil {
+		return x.Scheduling
+	}
+	return ""
+}
+
+type WorkerGPUTuning struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PowerLimitWatts int32                  `protobuf:"varint,1,opt,name=power_limit_watts,json=powerLimitWatts,proto3" json:"power_limit_watts,omitempty"`
+	MinClockMhz     int32                  `protobuf:"varint,2,opt,name=min_clock_mhz,json=minClockMhz,proto3" json:"min_clock_mhz,omitempty"`
+	MaxClockMhz     int32                  `protobuf:"varint,3,opt,name=max_clock_mhz,json=maxClockMhz,proto3" json:"max_clock_mhz,omitempty"`
+	PersistenceMode *bool                  `protobuf:"varint,4,opt,name=persistence_mode,json=persistenceMode,proto3,oneof" json:"persistence_mode,omitempty"`
+	ComputeMode     string                 `protobuf:"bytes,5,opt,name=compute_mode,json=computeMode,proto3" json:"compute_mode,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WorkerGPUTuning) Reset() {
+	*x = WorkerGPUTuning{}
+	mi := &file_agent_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerGPUTuning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerGPUTuning) ProtoMessage() {}
+
+func (x *WorkerGPUTuning) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerGPUTuning.ProtoReflect.Descriptor instead.
+func (*WorkerGPUTuning) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WorkerGPUTuning) GetPowerLimitWatts() int32 {
+	if x != nil {
+		return x.PowerLimitWatts
+	}
+	return 0
+}
+
+func (x *WorkerGPUTuning) GetMinClockMhz() int32 {
+	if x != nil {
+		return x.MinClockMhz
+	}
+	return 0
+}
+
+func (x *WorkerGPUTuning) GetMaxClockMhz() int32 {
+	if x != nil {
+		return x.MaxClockMhz
+	}
+	return 0
+}
+
+func (x *WorkerGPUTuning) GetPersistenceMode() bool {
+	if x != nil && x.PersistenceMode != nil {
+		return *x.PersistenceMode
+	}
+	return false
+}
+
+func (x *WorkerGPUTuning) GetComputeMode() string {
+	if x != nil {
+		return x.ComputeMode
+	}
+	return ""
+}
+
+type WorkerConfig struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId       string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	GpuIds         []string               `protobuf:"bytes,2,rep,name=gpu_ids,json=gpuIds,proto3" json:"gpu_ids,omitempty"`
+	GpuIndices     []int32                `protobuf:"varint,3,rep,packed,name=gpu_indices,json=gpuIndices,proto3" json:"gpu_indices,omitempty"`
+	VramMb         int64                  `protobuf:"varint,4,opt,name=vram_mb,json=vramMb,proto3" json:"vram_mb,omitempty"`
+	ComputePercent int32                  `protobuf:"varint,5,opt,name=compute_percent,json=computePercent,proto3" json:"compute_percent,omitempty"`
+	IsolationMode  string                 `protobuf:"bytes,6,opt,name=isolation_mode,json=isolationMode,proto3" json:"isolation_mode,omitempty"`
+	ListenPort     int32                  `protobuf:"varint,7,opt,name=listen_port,json=listenPort,proto3" json:"listen_port,omitempty"`
+	Enabled        bool                   `protobuf:"varint,8,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	ShareCodes     []string               `protobuf:"bytes,9,rep,name=share_codes,json=shareCodes,proto3" json:"share_codes,omitempty"`
+	MigProfile     string                 `protobuf:"bytes,10,opt,name=mig_profile,json=migProfile,proto3" json:"mig_profile,omitempty"`
+	Env            map[string]string      `protobuf:"bytes,11,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Relay          bool                   `protobuf:"varint,12,opt,name=relay,proto3" json:"relay,omitempty"`
+	ForceStop      bool                   `protobuf:"varint,13,opt,name=force_stop,json=forceStop,proto3" json:"force_stop,omitempty"`
+	Standby        *WorkerStandby         `protobuf:"bytes,14,opt,name=standby,proto3" json:"standby,omitempty"`
+	Fairness       *WorkerFairness        `protobuf:"bytes,15,opt,name=fairness,proto3" json:"fairness,omitempty"`
+	Tuning         *WorkerGPUTuning       `protobuf:"bytes,16,opt,name=tuning,proto3" json:"tuning,omitempty"`
+	Warm           bool                   `protobuf:"varint,17,opt,name=warm,proto3" json:"warm,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WorkerConfig) Reset() {
+	*x = WorkerConfig{}
+	mi := &file_agent_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerConfig) ProtoMessage() {}
+
+func (x *WorkerConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerConfig.ProtoReflect.Descriptor instead.
+func (*WorkerConfig) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WorkerConfig) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *WorkerConfig) GetGpuIds() []string {
+	if x != nil {
+		return x.GpuIds
+	}
+	return nil
+}
+
+func (x *WorkerConfig) GetGpuIndices() []int32 {
+	if x != nil {
+		return x.GpuIndices
+	}
+	return nil
+}
+
+func (x *WorkerConfig) GetVramMb() int64 {
+	if x != nil {
+		return x.VramMb
+	}
+	return 0
+}
+
+func (x *WorkerConfig) GetComputePercent() int32 {
+	if x != nil {
+		return x.ComputePercent
+	}
+	return 0
+}
+
+func (x *WorkerConfig) GetIsolationMode() string {
+	if x != nil {
+		return x.IsolationMode
+	}
+	return ""
+}
+
+func (x *WorkerConfig) GetListenPort() int32 {
+	if x != nil {
+		return x.ListenPort
+	}
+	return 0
+}
+
+func (x *WorkerConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *WorkerConfig) GetShareCodes() []string {
+	if x != nil {
+		return x.ShareCodes
+	}
+	return nil
+}
+
+func (x *WorkerConfig) GetMigProfile() string {
+	if x != nil {
+		return x.MigProfile
+	}
+	return ""
+}
+
+func (x *WorkerConfig) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *WorkerConfig) GetRelay() bool {
+	if x != nil {
+		return x.Relay
+	}
+	return false
+}
+
+func (x *WorkerConfig) GetForceStop() bool {
+	if x != nil {
+		return x.ForceStop
+	}
+	return false
+}
+
+func (x *WorkerConfig) GetStandby() *WorkerStandby {
+	if x != nil {
+		return x.Standby
+	}
+	return nil
+}
+
+func (x *WorkerConfig) GetFairness() *WorkerFairness {
+	if x != nil {
+		return x.Fairness
+	}
+	return nil
+}
+
+func (x *WorkerConfig) GetTuning() *WorkerGPUTuning {
+	if x != nil {
+		return x.Tuning
+	}
+	return nil
+}
+
+func (x *WorkerConfig) GetWarm() bool {
+	if x != nil {
+		return x.Warm
+	}
+	return false
+}
+
+type RelayConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelayConfig) Reset() {
+	*x = RelayConfig{}
+	mi := &file_agent_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelayConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelayConfig) ProtoMessage() {}
+
+func (x *RelayConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelayConfig.ProtoReflect.Descriptor instead.
+func (*RelayConfig) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RelayConfig) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *RelayConfig) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type ReportingConfig struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	IntervalSeconds     int32                  `protobuf:"varint,1,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	ForceRefreshSeconds int32                  `protobuf:"varint,2,opt,name=force_refresh_seconds,json=forceRefreshSeconds,proto3" json:"force_refresh_seconds,omitempty"`
+	ChangesOnly         *bool                  `protobuf:"varint,3,opt,name=changes_only,json=changesOnly,proto3,oneof" json:"changes_only,omitempty"`
+	KeepaliveSeconds    int32                  `protobuf:"varint,4,opt,name=keepalive_seconds,json=keepaliveSeconds,proto3" json:"keepalive_seconds,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ReportingConfig) Reset() {
+	*x = ReportingConfig{}
+	mi := &file_agent_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportingConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportingConfig) ProtoMessage() {}
+
+func (x *ReportingConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportingConfig.ProtoReflect.Descriptor instead.
+func (*ReportingConfig) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ReportingConfig) GetIntervalSeconds() int32 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+func (x *ReportingConfig) GetForceRefreshSeconds() int32 {
+	if x != nil {
+		return x.ForceRefreshSeconds
+	}
+	return 0
+}
+
+func (x *ReportingConfig) GetChangesOnly() bool {
+	if x != nil && x.ChangesOnly != nil {
+		return *x.ChangesOnly
+	}
+	return false
+}
+
+func (x *ReportingConfig) GetKeepaliveSeconds() int32 {
+	if x != nil {
+		return x.KeepaliveSeconds
+	}
+	return 0
+}
+
+type WorkerLogConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxSizeMb     int32                  `protobuf:"varint,1,opt,name=max_size_mb,json=maxSizeMb,proto3" json:"max_size_mb,omitempty"`
+	MaxFiles      int32                  `protobuf:"varint,2,opt,name=max_files,json=maxFiles,proto3" json:"max_files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkerLogConfig) Reset() {
+	*x = WorkerLogConfig{}
+	mi := &file_agent_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerLogConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerLogConfig) ProtoMessage() {}
+
+func (x *WorkerLogConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerLogConfig.ProtoReflect.Descriptor instead.
+func (*WorkerLogConfig) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WorkerLogConfig) GetMaxSizeMb() int32 {
+	if x != nil {
+		return x.MaxSizeMb
+	}
+	return 0
+}
+
+func (x *WorkerLogConfig) GetMaxFiles() int32 {
+	if x != nil {
+		return x.MaxFiles
+	}
+	return 0
+}
+
+type DiskConfig struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MinFreePercent int32                  `protobuf:"varint,1,opt,name=min_free_percent,json=minFreePercent,proto3" json:"min_free_percent,omitempty"`
+	MinFreeMb      int32                  `protobuf:"varint,2,opt,name=min_free_mb,json=minFreeMb,proto3" json:"min_free_mb,omitempty"`
+	MaxLogsMb      int32                  `protobuf:"varint,3,opt,name=max_logs_mb,json=maxLogsMb,proto3" json:"max_logs_mb,omitempty"`
+	MaxCacheMb     int32                  `protobuf:"varint,4,opt,name=max_cache_mb,json=maxCacheMb,proto3" json:"max_cache_mb,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DiskConfig) Reset() {
+	*x = DiskConfig{}
+	mi := &file_agent_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskConfig) ProtoMessage() {}
+
+func (x *DiskConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskConfig.ProtoReflect.Descriptor instead.
+func (*DiskConfig) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DiskConfig) GetMinFreePercent() int32 {
+	if x != nil {
+		return x.MinFreePercent
+	}
+	return 0
+}
+
+func (x *DiskConfig) GetMinFreeMb() int32 {
+	if x != nil {
+		return x.MinFreeMb
+	}
+	return 0
+}
+
+func (x *DiskConfig) GetMaxLogsMb() int32 {
+	if x != nil {
+		return x.MaxLogsMb
+	}
+	return 0
+}
+
+func (x *DiskConfig) GetMaxCacheMb() int32 {
+	if x != nil {
+		return x.MaxCacheMb
+	}
+	return 0
+}
+
+type ConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConfigVersion int32                  `protobuf:"varint,1,opt,name=config_version,json=configVersion,proto3" json:"config_version,omitempty"`
+	Workers       []*WorkerConfig        `protobuf:"bytes,2,rep,name=workers,proto3" json:"workers,omitempty"`
+	License       *License               `protobuf:"bytes,3,opt,name=license,proto3" json:"license,omitempty"`
+	Relay         *RelayConfig           `protobuf:"bytes,4,opt,name=relay,proto3" json:"relay,omitempty"`
+	Reporting     *ReportingConfig       `protobuf:"bytes,5,opt,name=reporting,proto3" json:"reporting,omitempty"`
+	WorkerLogs    *WorkerLogConfig       `protobuf:"bytes,6,opt,name=worker_logs,json=workerLogs,proto3" json:"worker_logs,omitempty"`
+	Disk          *DiskConfig            `protobuf:"bytes,7,opt,name=disk,proto3" json:"disk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigResponse) Reset() {
+	*x = ConfigResponse{}
+	mi := &file_agent_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigResponse) ProtoMessage() {}
+
+func (x *ConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigResponse.ProtoReflect.Descriptor instead.
+func (*ConfigResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ConfigResponse) GetConfigVersion() int32 {
+	if x != nil {
+		return x.ConfigVersion
+	}
+	return 0
+}
+
+func (x *ConfigResponse) GetWorkers() []*WorkerConfig {
+	if x != nil {
+		return x.Workers
+	}
+	return nil
+}
+
+func (x *ConfigResponse) GetLicense() *License {
+	if x != nil {
+		return x.License
+	}
+	return nil
+}
+
+func (x *ConfigResponse) GetRelay() *RelayConfig {
+	if x != nil {
+		return x.Relay
+	}
+	return nil
+}
+
+func (x *ConfigResponse) GetReporting() *ReportingConfig {
+	if x != nil {
+		return x.Reporting
+	}
+	return nil
+}
+
+func (x *ConfigResponse) GetWorkerLogs() *WorkerLogConfig {
+	if x != nil {
+		return x.WorkerLogs
+	}
+	return nil
+}
+
+func (x *ConfigResponse) GetDisk() *DiskConfig {
+	if x != nil {
+		return x.Disk
+	}
+	return nil
+}
+
+type GPUStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GpuId         string                 `protobuf:"bytes,1,opt,name=gpu_id,json=gpuId,proto3" json:"gpu_id,omitempty"`
+	GpuIndex      int32                  `protobuf:"varint,2,opt,name=gpu_index,json=gpuIndex,proto3" json:"gpu_index,omitempty"`
+	UsedByWorker  *string                `protobuf:"bytes,3,opt,name=used_by_worker,json=usedByWorker,proto3,oneof" json:"used_by_worker,omitempty"`
+	Vendor        string                 `protobuf:"bytes,4,opt,name=vendor,proto3" json:"vendor,omitempty"`
+	Model         string                 `protobuf:"bytes,5,opt,name=model,proto3" json:"model,omitempty"`
+	VramMb        int64                  `protobuf:"varint,6,opt,name=vram_mb,json=vramMb,proto3" json:"vram_mb,omitempty"`
+	DriverVersion string                 `protobuf:"bytes,7,opt,name=driver_version,json=driverVersion,proto3" json:"driver_version,omitempty"`
+	CudaVersion   string                 `protobuf:"bytes,8,opt,name=cuda_version,json=cudaVersion,proto3" json:"cuda_version,omitempty"`
+	GpuChanged    bool                   `protobuf:"varint,9,opt,name=gpu_changed,json=gpuChanged,proto3" json:"gpu_changed,omitempty"`
+	MigCapable    bool                   `protobuf:"varint,10,opt,name=mig_capable,json=migCapable,proto3" json:"mig_capable,omitempty"`
+	MigEnabled    bool                   `protobuf:"varint,11,opt,name=mig_enabled,json=migEnabled,proto3" json:"mig_enabled,omitempty"`
+	Partitions    []*GPUPartition        `protobuf:"bytes,12,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	Health        []string               `protobuf:"bytes,13,rep,name=health,proto3" json:"health,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GPUStatus) Reset() {
+	*x = GPUStatus{}
+	mi := &file_agent_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GPUStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPUStatus) ProtoMessage() {}
+
+func (x *GPUStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPUStatus.ProtoReflect.Descriptor instead.
+func (*GPUStatus) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GPUStatus) GetGpuId() string {
+	if x != nil {
+		return x.GpuId
+	}
+	return ""
+}
+
+func (x *GPUStatus) GetGpuIndex() int32 {
+	if x != nil {
+		return x.GpuIndex
+	}
+	return 0
+}
+
+func (x *GPUStatus) GetUsedByWorker() string {
+	if x != nil && x.UsedByWorker != nil {
+		return *x.UsedByWorker
+	}
+	return ""
+}
+
+func (x *GPUStatus) GetVendor() string {
+	if x != nil {
+		return x.Vendor
+	}
+	return ""
+}
+
+func (x *GPUStatus) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *GPUStatus) GetVramMb() int64 {
+	if x != nil {
+		return x.VramMb
+	}
+	return 0
+}
+
+func (x *GPUStatus) GetDriverVersion() string {
+	if x != nil {
+		return x.DriverVersion
+	}
+	return ""
+}
+
+func (x *GPUStatus) GetCudaVersion() string {
+	if x != nil {
+		return x.CudaVersion
+	}
+	return ""
+}
+
+func (x *GPUStatus) GetGpuChanged() bool {
+	if x != nil {
+		return x.GpuChanged
+	}
+	return false
+}
+
+func (x *GPUStatus) GetMigCapable() bool {
+	if x != nil {
+		return x.MigCapable
+	}
+	return false
+}
+
+func (x *GPUStatus) GetMigEnabled() bool {
+	if x != nil {
+		return x.MigEnabled
+	}
+	return false
+}
+
+func (x *GPUStatus) GetPartitions() []*GPUPartition {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *GPUStatus) GetHealth() []string {
+	if x != nil {
+		return x.Health
+	}
+	return nil
+}
+
+type ConnectionInfo struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ClientIp        string                 `protobuf:"bytes,1,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`
+	ClientPort      int32                  `protobuf:"varint,2,opt,name=client_port,json=clientPort,proto3" json:"client_port,omitempty"`
+	ClientPid       int32                  `protobuf:"varint,3,opt,name=client_pid,json=clientPid,proto3" json:"client_pid,omitempty"`
+	ConnectedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=connected_at,json=connectedAt,proto3" json:"connected_at,omitempty"`
+	ShareCode       string                 `protobuf:"bytes,5,opt,name=share_code,json=shareCode,proto3" json:"share_code,omitempty"`
+	BytesIn         int64                  `protobuf:"varint,6,opt,name=bytes_in,json=bytesIn,proto3" json:"bytes_in,omitempty"`
+	BytesOut        int64                  `protobuf:"varint,7,opt,name=bytes_out,json=bytesOut,proto3" json:"bytes_out,omitempty"`
+	WorkerSessionId string                 `protobuf:"bytes,8,opt,name=worker_session_id,json=workerSessionId,proto3" json:"worker_session_id,omitempty"`
+	ClientHostname  string                 `protobuf:"bytes,9,opt,name=client_hostname,json=clientHostname,proto3" json:"client_hostname,omitempty"`
+	ProtocolVersion string                 `protobuf:"bytes,10,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ConnectionInfo) Reset() {
+	*x = ConnectionInfo{}
+	mi := &file_agent_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectionInfo) ProtoMessage() {}
+
+func (x *ConnectionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectionInfo.ProtoReflect.Descriptor instead.
+func (*ConnectionInfo) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ConnectionInfo) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+func (x *ConnectionInfo) GetClientPort() int32 {
+	if x != nil {
+		return x.ClientPort
+	}
+	return 0
+}
+
+func (x *ConnectionInfo) GetClientPid() int32 {
+	if x != nil {
+		return x.ClientPid
+	}
+	return 0
+}
+
+func (x *ConnectionInfo) GetConnectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ConnectedAt
+	}
+	return nil
+}
+
+func (x *ConnectionInfo) GetShareCode() string {
+	if x != nil {
+		return x.ShareCode
+	}
+	return ""
+}
+
+func (x *ConnectionInfo) GetBytesIn() int64 {
+	if x != nil {
+		return x.BytesIn
+	}
+	return 0
+}
+
+func (x *ConnectionInfo) GetBytesOut() int64 {
+	if x != nil {
+		return x.BytesOut
+	}
+	return 0
+}
+
+func (x *ConnectionInfo) GetWorkerSessionId() string {
+	if x != nil {
+		return x.WorkerSessionId
+	}
+	return ""
+}
+
+func (x *ConnectionInfo) GetClientHostname() string {
+	if x != nil {
+		return x.ClientHostname
+	}
+	return ""
+}
+
+func (x *ConnectionInfo) GetProtocolVersion() string {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return ""
+}
+
+type ShareUsage struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ShareCode       string                 `protobuf:"bytes,1,opt,name=share_code,json=shareCode,proto3" json:"share_code,omitempty"`
+	ClientIp        string                 `protobuf:"bytes,2,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`
+	BytesIn         int64                  `protobuf:"varint,3,opt,name=bytes_in,json=bytesIn,proto3" json:"bytes_in,omitempty"`
+	BytesOut        int64                  `protobuf:"varint,4,opt,name=bytes_out,json=bytesOut,proto3" json:"bytes_out,omitempty"`
+	Sessions        int32                  `protobuf:"varint,5,opt,name=sessions,proto3" json:"sessions,omitempty"`
+	DurationSeconds float64                `protobuf:"fixed64,6,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	QuotaDenied     int32                  `protobuf:"varint,7,opt,name=quota_denied,json=quotaDenied,proto3" json:"quota_denied,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ShareUsage) Reset() {
+	*x = ShareUsage{}
+	mi := &file_agent_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareUsage) ProtoMessage() {}
+
+func (x *ShareUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareUsage.ProtoReflect.Descriptor instead.
+func (*ShareUsage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ShareUsage) GetShareCode() string {
+	if x != nil {
+		return x.ShareCode
+	}
+	return ""
+}
+
+func (x *ShareUsage) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+func (x *ShareUsage) GetBytesIn() int64 {
+	if x != nil {
+		return x.BytesIn
+	}
+	return 0
+}
+
+func (x *ShareUsage) GetBytesOut() int64 {
+	if x != nil {
+		return x.BytesOut
+	}
+	return 0
+}
+
+func (x *ShareUsage) GetSessions() int32 {
+	if x != nil {
+		return x.Sessions
+	}
+	return 0
+}
+
+func (x *ShareUsage) GetDurationSeconds() float64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *ShareUsage) GetQuotaDenied() int32 {
+	if x != nil {
+		return x.QuotaDenied
+	}
+	return 0
+}
+
+type WorkerCrashReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Pid           int32                  `protobuf:"varint,2,opt,name=pid,proto3" json:"pid,omitempty"`
+	Restarts      int32                  `protobuf:"varint,3,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	Exits         int32                  `protobuf:"varint,4,opt,name=exits,proto3" json:"exits,omitempty"`
+	DetectedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=detected_at,json=detectedAt,proto3" json:"detected_at,omitempty"`
+	ExitCode      *int32                 `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	Signal        string                 `protobuf:"bytes,7,opt,name=signal,proto3" json:"signal,omitempty"`
+	Reason        string                 `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	LogFile       string                 `protobuf:"bytes,9,opt,name=log_file,json=logFile,proto3" json:"log_file,omitempty"`
+	LogTail       string                 `protobuf:"bytes,10,opt,name=log_tail,json=logTail,proto3" json:"log_tail,omitempty"`
+	KernelEvents  []string               `protobuf:"bytes,11,rep,name=kernel_events,json=kernelEvents,proto3" json:"kernel_events,omitempty"`
+	Xids          []int32                `protobuf:"varint,12,rep,packed,name=xids,proto3" json:"xids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkerCrashReport) Reset() {
+	*x = WorkerCrashReport{}
+	mi := &file_agent_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerCrashReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerCrashReport) ProtoMessage() {}
+
+func (x *WorkerCrashReport) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerCrashReport.ProtoReflect.Descriptor instead.
+func (*WorkerCrashReport) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *WorkerCrashReport) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *WorkerCrashReport) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *WorkerCrashReport) GetRestarts() int32 {
+	if x != nil {
+		return x.Restarts
+	}
+	return 0
+}
+
+func (x *WorkerCrashReport) GetExits() int32 {
+	if x != nil {
+		return x.Exits
+	}
+	return 0
+}
+
+func (x *WorkerCrashReport) GetDetectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DetectedAt
+	}
+	return nil
+}
+
+func (x *WorkerCrashReport) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *WorkerCrashReport) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *WorkerCrashReport) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *WorkerCrashReport) GetLogFile() string {
+	if x != nil {
+		return x.LogFile
+	}
+	return ""
+}
+
+func (x *WorkerCrashReport) GetLogTail() string {
+	if x != nil {
+		return x.LogTail
+	}
+	return ""
+}
+
+func (x *WorkerCrashReport) GetKernelEvents() []string {
+	if x != nil {
+		return x.KernelEvents
+	}
+	return nil
+}
+
+func (x *WorkerCrashReport) GetXids() []int32 {
+	if x != nil {
+		return x.Xids
+	}
+	return nil
+}
+
+type WorkerCrashLoop struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Crashes       int32                  `protobuf:"varint,1,opt,name=crashes,proto3" json:"crashes,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
+	LastErrors    []string               `protobuf:"bytes,3,rep,name=last_errors,json=lastErrors,proto3" json:"last_errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkerCrashLoop) Reset() {
+	*x = WorkerCrashLoop{}
+	mi := &file_agent_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerCrashLoop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerCrashLoop) ProtoMessage() {}
+
+func (x *WorkerCrashLoop) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerCrashLoop.ProtoReflect.Descriptor instead.
+func (*WorkerCrashLoop) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WorkerCrashLoop) GetCrashes() int32 {
+	if x != nil {
+		return x.Crashes
+	}
+	return 0
+}
+
+func (x *WorkerCrashLoop) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *WorkerCrashLoop) GetLastErrors() []string {
+	if x != nil {
+		return x.LastErrors
+	}
+	return nil
+}
+
+type WorkerProbeResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Probe         string                 `protobuf:"bytes,1,opt,name=probe,proto3" json:"probe,omitempty"`
+	Ok            bool                   `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	LatencyMs     int64                  `protobuf:"varint,4,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkerProbeResult) Reset() {
+	*x = WorkerProbeResult{}
+	mi := &file_agent_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerProbeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerProbeResult) ProtoMessage() {}
+
+func (x *WorkerProbeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerProbeResult.ProtoReflect.Descriptor instead.
+func (*WorkerProbeResult) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *WorkerProbeResult) GetProbe() string {
+	if x != nil {
+		return x.Probe
+	}
+	return ""
+}
+
+func (x *WorkerProbeResult) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *WorkerProbeResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *WorkerProbeResult) GetLatencyMs() int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+type WorkerHealth struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Status              string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	ConsecutiveFailures int32                  `protobuf:"varint,2,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	Probes              []*WorkerProbeResult   `protobuf:"bytes,3,rep,name=probes,proto3" json:"probes,omitempty"`
+	Xids                []int32                `protobuf:"varint,4,rep,packed,name=xids,proto3" json:"xids,omitempty"`
+	Restarts            int32                  `protobuf:"varint,5,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	RestartsExhausted   bool                   `protobuf:"varint,6,opt,name=restarts_exhausted,json=restartsExhausted,proto3" json:"restarts_exhausted,omitempty"`
+	CheckedAt           *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *WorkerHealth) Reset() {
+	*x = WorkerHealth{}
+	mi := &file_agent_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerHealth) ProtoMessage() {}
+
+func (x *WorkerHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerHealth.ProtoReflect.Descriptor instead.
+func (*WorkerHealth) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WorkerHealth) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WorkerHealth) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *WorkerHealth) GetProbes() []*WorkerProbeResult {
+	if x != nil {
+		return x.Probes
+	}
+	return nil
+}
+
+func (x *WorkerHealth) GetXids() []int32 {
+	if x != nil {
+		return x.Xids
+	}
+	return nil
+}
+
+func (x *WorkerHealth) GetRestarts() int32 {
+	if x != nil {
+		return x.Restarts
+	}
+	return 0
+}
+
+func (x *WorkerHealth) GetRestartsExhausted() bool {
+	if x != nil {
+		return x.RestartsExhausted
+	}
+	return false
+}
+
+func (x *WorkerHealth) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+type WorkerStatus struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	WorkerId          string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Status            string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Pid               int32                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`
+	Restarts          int32                  `protobuf:"varint,4,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	GpuIds            []string               `protobuf:"bytes,5,rep,name=gpu_ids,json=gpuIds,proto3" json:"gpu_ids,omitempty"`
+	GpuIndices        []int32                `protobuf:"varint,6,rep,packed,name=gpu_indices,json=gpuIndices,proto3" json:"gpu_indices,omitempty"`
+	Connections       []*ConnectionInfo      `protobuf:"bytes,7,rep,name=connections,proto3" json:"connections,omitempty"`
+	Usage             []*ShareUsage          `protobuf:"bytes,8,rep,name=usage,proto3" json:"usage,omitempty"`
+	Crashes           []*WorkerCrashReport   `protobuf:"bytes,9,rep,name=crashes,proto3" json:"crashes,omitempty"`
+	CrashLoop         *WorkerCrashLoop       `protobuf:"bytes,10,opt,name=crash_loop,json=crashLoop,proto3" json:"crash_loop,omitempty"`
+	TlsFingerprint    string                 `protobuf:"bytes,11,opt,name=tls_fingerprint,json=tlsFingerprint,proto3" json:"tls_fingerprint,omitempty"`
+	RelayConnected    bool                   `protobuf:"varint,12,opt,name=relay_connected,json=relayConnected,proto3" json:"relay_connected,omitempty"`
+	DrainDeadline     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=drain_deadline,json=drainDeadline,proto3" json:"drain_deadline,omitempty"`
+	Health            *WorkerHealth          `protobuf:"bytes,14,opt,name=health,proto3" json:"health,omitempty"`
+	WorkerChanged     *bool                  `protobuf:"varint,15,opt,name=worker_changed,json=workerChanged,proto3,oneof" json:"worker_changed,omitempty"`
+	ConnectionChanged *bool                  `protobuf:"varint,16,opt,name=connection_changed,json=connectionChanged,proto3,oneof" json:"connection_changed,omitempty"`
+	GpuChanged        *bool                  `protobuf:"varint,17,opt,name=gpu_changed,json=gpuChanged,proto3,oneof" json:"gpu_changed,omitempty"`
+	Warm              bool                   `protobuf:"varint,18,opt,name=warm,proto3" json:"warm,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *WorkerStatus) Reset() {
+	*x = WorkerStatus{}
+	mi := &file_agent_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkerStatus) ProtoMessage() {}
+
+func (x *WorkerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkerStatus.ProtoReflect.Descriptor instead.
+func (*WorkerStatus) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *WorkerStatus) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *WorkerStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WorkerStatus) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *WorkerStatus) GetRestarts() int32 {
+	if x != nil {
+		return x.Restarts
+	}
+	return 0
+}
+
+func (x *WorkerStatus) GetGpuIds() []string {
+	if x != nil {
+		return x.GpuIds
+	}
+	return nil
+}
+
+func (x *WorkerStatus) GetGpuIndices() []int32 {
+	if x != nil {
+		return x.GpuIndices
+	}
+	return nil
+}
+
+func (x *WorkerStatus) GetConnections() []*ConnectionInfo {
+	if x != nil {
+		return x.Connections
+	}
+	return nil
+}
+
+func (x *WorkerStatus) GetUsage() []*ShareUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *WorkerStatus) GetCrashes() []*WorkerCrashReport {
+	if x != nil {
+		return x.Crashes
+	}
+	return nil
+}
+
+func (x *WorkerStatus) GetCrashLoop() *WorkerCrashLoop {
+	if x != nil {
+		return x.CrashLoop
+	}
+	return nil
+}
+
+func (x *WorkerStatus) GetTlsFingerprint() string {
+	if x != nil {
+		return x.TlsFingerprint
+	}
+	return ""
+}
+
+func (x *WorkerStatus) GetRelayConnected() bool {
+	if x != nil {
+		return x.RelayConnected
+	}
+	return false
+}
+
+func (x *WorkerStatus) GetDrainDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DrainDeadline
+	}
+	return nil
+}
+
+func (x *WorkerStatus) GetHealth() *WorkerHealth {
+	if x != nil {
+		return x.Health
+	}
+	return nil
+}
+
+func (x *WorkerStatus) GetWorkerChanged() bool {
+	if x != nil && x.WorkerChanged != nil {
+		return *x.WorkerChanged
+	}
+	return false
+}
+
+func (x *WorkerStatus) GetConnectionChanged() bool {
+	if x != nil && x.ConnectionChanged != nil {
+		return *x.ConnectionChanged
+	}
+	return false
+}
+
+func (x *WorkerStatus) GetGpuChanged() bool {
+	if x != nil && x.GpuChanged != nil {
+		return *x.GpuChanged
+	}
+	return false
+}
+
+func (x *WorkerStatus) GetWarm() bool {
+	if x != nil {
+		return x.Warm
+	}
+	return false
+}
+
+type NetTestSummary struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	RanAt             *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=ran_at,json=ranAt,proto3" json:"ran_at,omitempty"`
+	ApiLatencyMs      float64                `protobuf:"fixed64,2,opt,name=api_latency_ms,json=apiLatencyMs,proto3" json:"api_latency_ms,omitempty"`
+	ApiThroughputMbps float64                `protobuf:"fixed64,3,opt,name=api_throughput_mbps,json=apiThroughputMbps,proto3" json:"api_throughput_mbps,omitempty"`
+	WsConnectMs       float64                `protobuf:"fixed64,4,opt,name=ws_connect_ms,json=wsConnectMs,proto3" json:"ws_connect_ms,omitempty"`
+	WsRoundTripMs     float64                `protobuf:"fixed64,5,opt,name=ws_round_trip_ms,json=wsRoundTripMs,proto3" json:"ws_round_trip_ms,omitempty"`
+	CdnThroughputMbps float64                `protobuf:"fixed64,6,opt,name=cdn_throughput_mbps,json=cdnThroughputMbps,proto3" json:"cdn_throughput_mbps,omitempty"`
+	UnreachablePorts  []int32                `protobuf:"varint,7,rep,packed,name=unreachable_ports,json=unreachablePorts,proto3" json:"unreachable_ports,omitempty"`
+	Failed            []string               `protobuf:"bytes,8,rep,name=failed,proto3" json:"failed,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *NetTestSummary) Reset() {
+	*x = NetTestSummary{}
+	mi := &file_agent_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetTestSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetTestSummary) ProtoMessage() {}
+
+func (x *NetTestSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetTestSummary.ProtoReflect.Descriptor instead.
+func (*NetTestSummary) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *NetTestSummary) GetRanAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RanAt
+	}
+	return nil
+}
+
+func (x *NetTestSummary) GetApiLatencyMs() float64 {
+	if x != nil {
+		return x.ApiLatencyMs
+	}
+	return 0
+}
+
+func (x *NetTestSummary) GetApiThroughputMbps() float64 {
+	if x != nil {
+		return x.ApiThroughputMbps
+	}
+	return 0
+}
+
+func (x *NetTestSummary) GetWsConnectMs() float64 {
+	if x != nil {
+		return x.WsConnectMs
+	}
+	return 0
+}
+
+func (x *NetTestSummary) GetWsRoundTripMs() float64 {
+	if x != nil {
+		return x.WsRoundTripMs
+	}
+	return 0
+}
+
+func (x *NetTestSummary) GetCdnThroughputMbps() float64 {
+	if x != nil {
+		return x.CdnThroughputMbps
+	}
+	return 0
+}
+
+func (x *NetTestSummary) GetUnreachablePorts() []int32 {
+	if x != nil {
+		return x.UnreachablePorts
+	}
+	return nil
+}
+
+func (x *NetTestSummary) GetFailed() []string {
+	if x != nil {
+		return x.Failed
+	}
+	return nil
+}
+
+type AgentDiskUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CacheBytes    int64                  `protobuf:"varint,1,opt,name=cache_bytes,json=cacheBytes,proto3" json:"cache_bytes,omitempty"`
+	LogsBytes     int64                  `protobuf:"varint,2,opt,name=logs_bytes,json=logsBytes,proto3" json:"logs_bytes,omitempty"`
+	FreeBytes     uint64                 `protobuf:"varint,3,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`
+	TotalBytes    uint64                 `protobuf:"varint,4,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	Pressure      bool                   `protobuf:"varint,5,opt,name=pressure,proto3" json:"pressure,omitempty"`
+	CheckedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentDiskUsage) Reset() {
+	*x = AgentDiskUsage{}
+	mi := &file_agent_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentDiskUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentDiskUsage) ProtoMessage() {}
+
+func (x *AgentDiskUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentDiskUsage.ProtoReflect.Descriptor instead.
+func (*AgentDiskUsage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AgentDiskUsage) GetCacheBytes() int64 {
+	if x != nil {
+		return x.CacheBytes
+	}
+	return 0
+}
+
+func (x *AgentDiskUsage) GetLogsBytes() int64 {
+	if x != nil {
+		return x.LogsBytes
+	}
+	return 0
+}
+
+func (x *AgentDiskUsage) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *AgentDiskUsage) GetTotalBytes() uint64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *AgentDiskUsage) GetPressure() bool {
+	if x != nil {
+		return x.Pressure
+	}
+	return false
+}
+
+func (x *AgentDiskUsage) GetCheckedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return nil
+}
+
+type StatusRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AgentId           string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Timestamp         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Gpus              []*GPUStatus           `protobuf:"bytes,3,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	Workers           []*WorkerStatus        `protobuf:"bytes,4,rep,name=workers,proto3" json:"workers,omitempty"`
+	Event             string                 `protobuf:"bytes,5,opt,name=event,proto3" json:"event,omitempty"`
+	LicenseExpiration *int64                 `protobuf:"varint,6,opt,name=license_expiration,json=licenseExpiration,proto3,oneof" json:"license_expiration,omitempty"`
+	LicenseStatus     string                 `protobuf:"bytes,7,opt,name=license_status,json=licenseStatus,proto3" json:"license_status,omitempty"`
+	Metrics           string                 `protobuf:"bytes,8,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	NetTest           *NetTestSummary        `protobuf:"bytes,9,opt,name=net_test,json=netTest,proto3" json:"net_test,omitempty"`
+	Disk              *AgentDiskUsage        `protobuf:"bytes,10,opt,name=disk,proto3" json:"disk,omitempty"`
+	ReportId          string                 `protobuf:"bytes,11,opt,name=report_id,json=reportId,proto3" json:"report_id,omitempty"`
+	Backfill          bool                   `protobuf:"varint,12,opt,name=backfill,proto3" json:"backfill,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *StatusRequest) Reset() {
+	*x = StatusRequest{}
+	mi := &file_agent_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusRequest) ProtoMessage() {}
+
+func (x *StatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StatusRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *StatusRequest) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *StatusRequest) GetGpus() []*GPUStatus {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+func (x *StatusRequest) GetWorkers() []*WorkerStatus {
+	if x != nil {
+		return x.Workers
+	}
+	return nil
+}
+
+func (x *StatusRequest) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *StatusRequest) GetLicenseExpiration() int64 {
+	if x != nil && x.LicenseExpiration != nil {
+		return *x.LicenseExpiration
+	}
+	return 0
+}
+
+func (x *StatusRequest) GetLicenseStatus() string {
+	if x != nil {
+		return x.LicenseStatus
+	}
+	return ""
+}
+
+func (x *StatusRequest) GetMetrics() string {
+	if x != nil {
+		return x.Metrics
+	}
+	return ""
+}
+
+func (x *StatusRequest) GetNetTest() *NetTestSummary {
+	if x != nil {
+		return x.NetTest
+	}
+	return nil
+}
+
+func (x *StatusRequest) GetDisk() *AgentDiskUsage {
+	if x != nil {
+		return x.Disk
+	}
+	return nil
+}
+
+func (x *StatusRequest) GetReportId() string {
+	if x != nil {
+		return x.ReportId
+	}
+	return ""
+}
+
+func (x *StatusRequest) GetBackfill() bool {
+	if x != nil {
+		return x.Backfill
+	}
+	return false
+}
+
+type ShareCodes struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Codes         []string               `protobuf:"bytes,1,rep,name=codes,proto3" json:"codes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareCodes) Reset() {
+	*x = ShareCodes{}
+	mi := &file_agent_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareCodes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareCodes) ProtoMessage() {}
+
+func (x *ShareCodes) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareCodes.ProtoReflect.Descriptor instead.
+func (*ShareCodes) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ShareCodes) GetCodes() []string {
+	if x != nil {
+		return x.Codes
+	}
+	return nil
+}
+
+type ShareQuotaState struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	GpuHoursPerWeek       float64                `protobuf:"fixed64,1,opt,name=gpu_hours_per_week,json=gpuHoursPerWeek,proto3" json:"gpu_hours_per_week,omitempty"`
+	MaxSessionMinutes     int32                  `protobuf:"varint,2,opt,name=max_session_minutes,json=maxSessionMinutes,proto3" json:"max_session_minutes,omitempty"`
+	MaxConcurrentSessions int32                  `protobuf:"varint,3,opt,name=max_concurrent_sessions,json=maxConcurrentSessions,proto3" json:"max_concurrent_sessions,omitempty"`
+	GpuHoursUsed          float64                `protobuf:"fixed64,4,opt,name=gpu_hours_used,json=gpuHoursUsed,proto3" json:"gpu_hours_used,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ShareQuotaState) Reset() {
+	*x = ShareQuotaState{}
+	mi := &file_agent_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareQuotaState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareQuotaState) ProtoMessage() {}
+
+func (x *ShareQuotaState) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareQuotaState.ProtoReflect.Descriptor instead.
+func (*ShareQuotaState) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ShareQuotaState) GetGpuHoursPerWeek() float64 {
+	if x != nil {
+		return x.GpuHoursPerWeek
+	}
+	return 0
+}
+
+func (x *ShareQuotaState) GetMaxSessionMinutes() int32 {
+	if x != nil {
+		return x.MaxSessionMinutes
+	}
+	return 0
+}
+
+func (x *ShareQuotaState) GetMaxConcurrentSessions() int32 {
+	if x != nil {
+		return x.MaxConcurrentSessions
+	}
+	return 0
+}
+
+func (x *ShareQuotaState) GetGpuHoursUsed() float64 {
+	if x != nil {
+		return x.GpuHoursUsed
+	}
+	return 0
+}
+
+type StatusResponse struct {
+	state            protoimpl.MessageState      `protogen:"open.v1"`
+	Success          bool                        `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ConfigVersion    int32                       `protobuf:"varint,2,opt,name=config_version,json=configVersion,proto3" json:"config_version,omitempty"`
+	License          *License                    `protobuf:"bytes,3,opt,name=license,proto3" json:"license,omitempty"`
+	WorkerShareCodes map[string]*ShareCodes      `protobuf:"bytes,4,rep,name=worker_share_codes,json=workerShareCodes,proto3" json:"worker_share_codes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	SecretRotation   string                      `protobuf:"bytes,5,opt,name=secret_rotation,json=secretRotation,proto3" json:"secret_rotation,omitempty"`
+	ShareQuotas      map[string]*ShareQuotaState `protobuf:"bytes,6,rep,name=share_quotas,json=shareQuotas,proto3" json:"share_quotas,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_agent_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *StatusResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StatusResponse) GetConfigVersion() int32 {
+	if x != nil {
+		return x.ConfigVersion
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetLicense() *License {
+	if x != nil {
+		return x.License
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetWorkerShareCodes() map[string]*ShareCodes {
+	if x != nil {
+		return x.WorkerShareCodes
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetSecretRotation() string {
+	if x != nil {
+		return x.SecretRotation
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetShareQuotas() map[string]*ShareQuotaState {
+	if x != nil {
+		return x.ShareQuotas
+	}
+	return nil
+}
+
+type SystemMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CpuUsage      float64                `protobuf:"fixed64,1,opt,name=cpu_usage,json=cpuUsage,proto3" json:"cpu_usage,omitempty"`
+	MemoryUsedMb  int64                  `protobuf:"varint,2,opt,name=memory_used_mb,json=memoryUsedMb,proto3" json:"memory_used_mb,omitempty"`
+	MemoryTotalMb int64                  `protobuf:"varint,3,opt,name=memory_total_mb,json=memoryTotalMb,proto3" json:"memory_total_mb,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SystemMetrics) Reset() {
+	*x = SystemMetrics{}
+	mi := &file_agent_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SystemMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemMetrics) ProtoMessage() {}
+
+func (x *SystemMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemMetrics.ProtoReflect.Descriptor instead.
+func (*SystemMetrics) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SystemMetrics) GetCpuUsage() float64 {
+	if x != nil {
+		return x.CpuUsage
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetMemoryUsedMb() int64 {
+	if x != nil {
+		return x.MemoryUsedMb
+	}
+	return 0
+}
+
+func (x *SystemMetrics) GetMemoryTotalMb() int64 {
+	if x != nil {
+		return x.MemoryTotalMb
+	}
+	return 0
+}
+
+type MetricsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	System        *SystemMetrics         `protobuf:"bytes,3,opt,name=system,proto3" json:"system,omitempty"`
+	Gpus          []*GPUMetrics          `protobuf:"bytes,4,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsRequest) Reset() {
+	*x = MetricsRequest{}
+	mi := &file_agent_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsRequest) ProtoMessage() {}
+
+func (x *MetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsRequest.ProtoReflect.Descriptor instead.
+func (*MetricsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *MetricsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *MetricsRequest) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *MetricsRequest) GetSystem() *SystemMetrics {
+	if x != nil {
+		return x.System
+	}
+	return nil
+}
+
+func (x *MetricsRequest) GetGpus() []*GPUMetrics {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+type MetricsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsResponse) Reset() {
+	*x = MetricsResponse{}
+	mi := &file_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsResponse) ProtoMessage() {}
+
+func (x *MetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsResponse.ProtoReflect.Descriptor instead.
+func (*MetricsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{32}
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Topic         string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SubscribeRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+type TopicMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// data holds the data lines of the message, separated by newlines
+	Data          string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicMessage) Reset() {
+	*x = TopicMessage{}
+	mi := &file_agent_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicMessage) ProtoMessage() {}
+
+func (x *TopicMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicMessage.ProtoReflect.Descriptor instead.
+func (*TopicMessage) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *TopicMessage) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+var File_agent_proto protoreflect.FileDescriptor
+
+const file_agent_proto_rawDesc = "" +
+	"\n" +
+	"\vagent.proto\x12\x0egpugo.agent.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"Y\n" +
+	"\fGPUPartition\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x18\n" +
+	"\aprofile\x18\x02 \x01(\tR\aprofile\x12\x1b\n" +
+	"\tworker_id\x18\x03 \x01(\tR\bworkerId\"\x8d\x02\n" +
+	"\n" +
+	"GPUMetrics\x12\x15\n" +
+	"\x06gpu_id\x18\x01 \x01(\tR\x05gpuId\x12 \n" +
+	"\vutilization\x18\x02 \x01(\x01R\vutilization\x12 \n" +
+	"\fvram_used_mb\x18\x03 \x01(\x03R\n" +
+	"vramUsedMb\x12\"\n" +
+	"\rvram_total_mb\x18\x04 \x01(\x03R\vvramTotalMb\x12 \n" +
+	"\vtemperature\x18\x05 \x01(\x01R\vtemperature\x12\"\n" +
+	"\rpower_usage_w\x18\x06 \x01(\x01R\vpowerUsageW\x12\x1c\n" +
+	"\n" +
+	"pcie_rx_kb\x18\a \x01(\x01R\bpcieRxKb\x12\x1c\n" +
+	"\n" +
+	"pcie_tx_kb\x18\b \x01(\x01R\bpcieTxKb\"\xfb\x02\n" +
+	"\aGPUInfo\x12\x15\n" +
+	"\x06gpu_id\x18\x01 \x01(\tR\x05gpuId\x12\x1b\n" +
+	"\tgpu_index\x18\x02 \x01(\x05R\bgpuIndex\x12\x16\n" +
+	"\x06vendor\x18\x03 \x01(\tR\x06vendor\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x12\x17\n" +
+	"\avram_mb\x18\x05 \x01(\x03R\x06vramMb\x12%\n" +
+	"\x0edriver_version\x18\x06 \x01(\tR\rdriverVersion\x12!\n" +
+	"\fcuda_version\x18\a \x01(\tR\vcudaVersion\x12\x1f\n" +
+	"\vmig_enabled\x18\b \x01(\bR\n" +
+	"migEnabled\x12<\n" +
+	"\n" +
+	"partitions\x18\t \x03(\v2\x1c.gpugo.agent.v1.GPUPartitionR\n" +
+	"partitions\x124\n" +
+	"\ametrics\x18\n" +
+	" \x01(\v2\x1a.gpugo.agent.v1.GPUMetricsR\ametrics\x12\x16\n" +
+	"\x06health\x18\v \x03(\tR\x06health\"\x9f\x01\n" +
+	"\x0fRegisterRequest\x12\x1a\n" +
+	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x0e\n" +
+	"\x02os\x18\x02 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x03 \x01(\tR\x04arch\x12+\n" +
+	"\x04gpus\x18\x04 \x03(\v2\x17.gpugo.agent.v1.GPUInfoR\x04gpus\x12\x1f\n" +
+	"\vnetwork_ips\x18\x05 \x03(\tR\n" +
+	"networkIps\"=\n" +
+	"\aLicense\x12\x14\n" +
+	"\x05plain\x18\x01 \x01(\tR\x05plain\x12\x1c\n" +
+	"\tencrypted\x18\x02 \x01(\tR\tencrypted\"\x83\x01\n" +
+	"\x10RegisterResponse\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
+	"\fagent_secret\x18\x02 \x01(\tR\vagentSecret\x121\n" +
+	"\alicense\x18\x03 \x01(\v2\x17.gpugo.agent.v1.LicenseR\alicense\"-\n" +
+	"\x10GetConfigRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\x87\x01\n" +
+	"\rWorkerStandby\x12(\n" +
+	"\x10primary_agent_id\x18\x01 \x01(\tR\x0eprimaryAgentId\x124\n" +
+	"\x16failover_after_seconds\x18\x02 \x01(\x05R\x14failoverAfterSeconds\x12\x16\n" +
+	"\x06active\x18\x03 \x01(\bR\x06active\"m\n" +
+	"\x0eWorkerFairness\x12;\n" +
+	"\x1aper_client_compute_percent\x18\x01 \x01(\x05R\x17perClientComputePercent\x12\x1e\n" +
+	"\n" +
+	"scheduling\x18\x02 \x01(\tR\n" +
+	"scheduling\"\xed\x01\n" +
+	"\x0fWorkerGPUTuning\x12*\n" +
+	"\x11power_limit_watts\x18\x01 \x01(\x05R\x0fpowerLimitWatts\x12\"\n" +
+	"\rmin_clock_mhz\x18\x02 \x01(\x05R\vminClockMhz\x12\"\n" +
+	"\rmax_clock_mhz\x18\x03 \x01(\x05R\vmaxClockMhz\x12.\n" +
+	"\x10persistence_mode\x18\x04 \x01(\bH\x00R\x0fpersistenceMode\x88\x01\x01\x12!\n" +
+	"\fcompute_mode\x18\x05 \x01(\tR\vcomputeModeB\x13\n" +
+	"\x11_persistence_mode\"\xb3\x05\n" +
+	"\fWorkerConfig\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x17\n" +
+	"\agpu_ids\x18\x02 \x03(\tR\x06gpuIds\x12\x1f\n" +
+	"\vgpu_indices\x18\x03 \x03(\x05R\n" +
+	"gpuIndices\x12\x17\n" +
+	"\avram_mb\x18\x04 \x01(\x03R\x06vramMb\x12'\n" +
+	"\x0fcompute_percent\x18\x05 \x01(\x05R\x0ecomputePercent\x12%\n" +
+	"\x0eisolation_mode\x18\x06 \x01(\tR\risolationMode\x12\x1f\n" +
+	"\vlisten_port\x18\a \x01(\x05R\n" +
+	"listenPort\x12\x18\n" +
+	"\aenabled\x18\b \x01(\bR\aenabled\x12\x1f\n" +
+	"\vshare_codes\x18\t \x03(\tR\n" +
+	"shareCodes\x12\x1f\n" +
+	"\vmig_profile\x18\n" +
+	" \x01(\tR\n" +
+	"migProfile\x127\n" +
+	"\x03env\x18\v \x03(\v2%.gpugo.agent.v1.WorkerConfig.EnvEntryR\x03env\x12\x14\n" +
+	"\x05relay\x18\f \x01(\bR\x05relay\x12\x1d\n" +
+	"\n" +
+	"force_stop\x18\r \x01(\bR\tforceStop\x127\n" +
+	"\astandby\x18\x0e \x01(\v2\x1d.gpugo.agent.v1.WorkerStandbyR\astandby\x12:\n" +
+	"\bfairness\x18\x0f \x01(\v2\x1e.gpugo.agent.v1.WorkerFairnessR\bfairness\x127\n" +
+	"\x06tuning\x18\x10 \x01(\v2\x1f.gpugo.agent.v1.WorkerGPUTuningR\x06tuning\x12\x12\n" +
+	"\x04warm\x18\x11 \x01(\bR\x04warm\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"7\n" +
+	"\vRelayConfig\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"\xd6\x01\n" +
+	"\x0fReportingConfig\x12)\n" +
+	"\x10interval_seconds\x18\x01 \x01(\x05R\x0fintervalSeconds\x122\n" +
+	"\x15force_refresh_seconds\x18\x02 \x01(\x05R\x13forceRefreshSeconds\x12&\n" +
+	"\fchanges_only\x18\x03 \x01(\bH\x00R\vchangesOnly\x88\x01\x01\x12+\n" +
+	"\x11keepalive_seconds\x18\x04 \x01(\x05R\x10keepaliveSecondsB\x0f\n" +
+	"\r_changes_only\"N\n" +
+	"\x0fWorkerLogConfig\x12\x1e\n" +
+	"\vmax_size_mb\x18\x01 \x01(\x05R\tmaxSizeMb\x12\x1b\n" +
+	"\tmax_files\x18\x02 \x01(\x05R\bmaxFiles\"\x98\x01\n" +
+	"\n" +
+	"DiskConfig\x12(\n" +
+	"\x10min_free_percent\x18\x01 \x01(\x05R\x0eminFreePercent\x12\x1e\n" +
+	"\vmin_free_mb\x18\x02 \x01(\x05R\tminFreeMb\x12\x1e\n" +
+	"\vmax_logs_mb\x18\x03 \x01(\x05R\tmaxLogsMb\x12 \n" +
+	"\fmax_cache_mb\x18\x04 \x01(\x05R\n" +
+	"maxCacheMb\"\x86\x03\n" +
+	"\x0eConfigResponse\x12%\n" +
+	"\x0econfig_version\x18\x01 \x01(\x05R\rconfigVersion\x126\n" +
+	"\aworkers\x18\x02 \x03(\v2\x1c.gpugo.agent.v1.WorkerConfigR\aworkers\x121\n" +
+	"\alicense\x18\x03 \x01(\v2\x17.gpugo.agent.v1.LicenseR\alicense\x121\n" +
+	"\x05relay\x18\x04 \x01(\v2\x1b.gpugo.agent.v1.RelayConfigR\x05relay\x12=\n" +
+	"\treporting\x18\x05 \x01(\v2\x1f.gpugo.agent.v1.ReportingConfigR\treporting\x12@\n" +
+	"\vworker_logs\x18\x06 \x01(\v2\x1f.gpugo.agent.v1.WorkerLogConfigR\n" +
+	"workerLogs\x12.\n" +
+	"\x04disk\x18\a \x01(\v2\x1a.gpugo.agent.v1.DiskConfigR\x04disk\"\xc7\x03\n" +
+	"\tGPUStatus\x12\x15\n" +
+	"\x06gpu_id\x18\x01 \x01(\tR\x05gpuId\x12\x1b\n" +
+	"\tgpu_index\x18\x02 \x01(\x05R\bgpuIndex\x12)\n" +
+	"\x0eused_by_worker\x18\x03 \x01(\tH\x00R\fusedByWorker\x88\x01\x01\x12\x16\n" +
+	"\x06vendor\x18\x04 \x01(\tR\x06vendor\x12\x14\n" +
+	"\x05model\x18\x05 \x01(\tR\x05model\x12\x17\n" +
+	"\avram_mb\x18\x06 \x01(\x03R\x06vramMb\x12%\n" +
+	"\x0edriver_version\x18\a \x01(\tR\rdriverVersion\x12!\n" +
+	"\fcuda_version\x18\b \x01(\tR\vcudaVersion\x12\x1f\n" +
+	"\vgpu_changed\x18\t \x01(\bR\n" +
+	"gpuChanged\x12\x1f\n" +
+	"\vmig_capable\x18\n" +
+	" \x01(\bR\n" +
+	"migCapable\x12\x1f\n" +
+	"\vmig_enabled\x18\v \x01(\bR\n" +
+	"migEnabled\x12<\n" +
+	"\n" +
+	"partitions\x18\f \x03(\v2\x1c.gpugo.agent.v1.GPUPartitionR\n" +
+	"partitions\x12\x16\n" +
+	"\x06health\x18\r \x03(\tR\x06healthB\x11\n" +
+	"\x0f_used_by_worker\"\x83\x03\n" +
+	"\x0eConnectionInfo\x12\x1b\n" +
+	"\tclient_ip\x18\x01 \x01(\tR\bclientIp\x12\x1f\n" +
+	"\vclient_port\x18\x02 \x01(\x05R\n" +
+	"clientPort\x12\x1d\n" +
+	"\n" +
+	"client_pid\x18\x03 \x01(\x05R\tclientPid\x12=\n" +
+	"\fconnected_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vconnectedAt\x12\x1d\n" +
+	"\n" +
+	"share_code\x18\x05 \x01(\tR\tshareCode\x12\x19\n" +
+	"\bbytes_in\x18\x06 \x01(\x03R\abytesIn\x12\x1b\n" +
+	"\tbytes_out\x18\a \x01(\x03R\bbytesOut\x12*\n" +
+	"\x11worker_session_id\x18\b \x01(\tR\x0fworkerSessionId\x12'\n" +
+	"\x0fclient_hostname\x18\t \x01(\tR\x0eclientHostname\x12)\n" +
+	"\x10protocol_version\x18\n" +
+	" \x01(\tR\x0fprotocolVersion\"\xea\x01\n" +
+	"\n" +
+	"ShareUsage\x12\x1d\n" +
+	"\n" +
+	"share_code\x18\x01 \x01(\tR\tshareCode\x12\x1b\n" +
+	"\tclient_ip\x18\x02 \x01(\tR\bclientIp\x12\x19\n" +
+	"\bbytes_in\x18\x03 \x01(\x03R\abytesIn\x12\x1b\n" +
+	"\tbytes_out\x18\x04 \x01(\x03R\bbytesOut\x12\x1a\n" +
+	"\bsessions\x18\x05 \x01(\x05R\bsessions\x12)\n" +
+	"\x10duration_seconds\x18\x06 \x01(\x01R\x0fdurationSeconds\x12!\n" +
+	"\fquota_denied\x18\a \x01(\x05R\vquotaDenied\"\x80\x03\n" +
+	"\x11WorkerCrashReport\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x10\n" +
+	"\x03pid\x18\x02 \x01(\x05R\x03pid\x12\x1a\n" +
+	"\brestarts\x18\x03 \x01(\x05R\brestarts\x12\x14\n" +
+	"\x05exits\x18\x04 \x01(\x05R\x05exits\x12;\n" +
+	"\vdetected_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"detectedAt\x12 \n" +
+	"\texit_code\x18\x06 \x01(\x05H\x00R\bexitCode\x88\x01\x01\x12\x16\n" +
+	"\x06signal\x18\a \x01(\tR\x06signal\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\x12\x19\n" +
+	"\blog_file\x18\t \x01(\tR\alogFile\x12\x19\n" +
+	"\blog_tail\x18\n" +
+	" \x01(\tR\alogTail\x12#\n" +
+	"\rkernel_events\x18\v \x03(\tR\fkernelEvents\x12\x12\n" +
+	"\x04xids\x18\f \x03(\x05R\x04xidsB\f\n" +
+	"\n" +
+	"_exit_code\"~\n" +
+	"\x0fWorkerCrashLoop\x12\x18\n" +
+	"\acrashes\x18\x01 \x01(\x05R\acrashes\x120\n" +
+	"\x05since\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x1f\n" +
+	"\vlast_errors\x18\x03 \x03(\tR\n" +
+	"lastErrors\"n\n" +
+	"\x11WorkerProbeResult\x12\x14\n" +
+	"\x05probe\x18\x01 \x01(\tR\x05probe\x12\x0e\n" +
+	"\x02ok\x18\x02 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"latency_ms\x18\x04 \x01(\x03R\tlatencyMs\"\xae\x02\n" +
+	"\fWorkerHealth\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x121\n" +
+	"\x14consecutive_failures\x18\x02 \x01(\x05R\x13consecutiveFailures\x129\n" +
+	"\x06probes\x18\x03 \x03(\v2!.gpugo.agent.v1.WorkerProbeResultR\x06probes\x12\x12\n" +
+	"\x04xids\x18\x04 \x03(\x05R\x04xids\x12\x1a\n" +
+	"\brestarts\x18\x05 \x01(\x05R\brestarts\x12-\n" +
+	"\x12restarts_exhausted\x18\x06 \x01(\bR\x11restartsExhausted\x129\n" +
+	"\n" +
+	"checked_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\"\xbb\x06\n" +
+	"\fWorkerStatus\x12\x1b\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x10\n" +
+	"\x03pid\x18\x03 \x01(\x05R\x03pid\x12\x1a\n" +
+	"\brestarts\x18\x04 \x01(\x05R\brestarts\x12\x17\n" +
+	"\agpu_ids\x18\x05 \x03(\tR\x06gpuIds\x12\x1f\n" +
+	"\vgpu_indices\x18\x06 \x03(\x05R\n" +
+	"gpuIndices\x12@\n" +
+	"\vconnections\x18\a \x03(\v2\x1e.gpugo.agent.v1.ConnectionInfoR\vconnections\x120\n" +
+	"\x05usage\x18\b \x03(\v2\x1a.gpugo.agent.v1.ShareUsageR\x05usage\x12;\n" +
+	"\acrashes\x18\t \x03(\v2!.gpugo.agent.v1.WorkerCrashReportR\acrashes\x12>\n" +
+	"\n" +
+	"crash_loop\x18\n" +
+	" \x01(\v2\x1f.gpugo.agent.v1.WorkerCrashLoopR\tcrashLoop\x12'\n" +
+	"\x0ftls_fingerprint\x18\v \x01(\tR\x0etlsFingerprint\x12'\n" +
+	"\x0frelay_connected\x18\f \x01(\bR\x0erelayConnected\x12A\n" +
+	"\x0edrain_deadline\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\rdrainDeadline\x124\n" +
+	"\x06health\x18\x0e \x01(\v2\x1c.gpugo.agent.v1.WorkerHealthR\x06health\x12*\n" +
+	"\x0eworker_changed\x18\x0f \x01(\bH\x00R\rworkerChanged\x88\x01\x01\x122\n" +
+	"\x12connection_changed\x18\x10 \x01(\bH\x01R\x11connectionChanged\x88\x01\x01\x12$\n" +
+	"\vgpu_changed\x18\x11 \x01(\bH\x02R\n" +
+	"gpuChanged\x88\x01\x01\x12\x12\n" +
+	"\x04warm\x18\x12 \x01(\bR\x04warmB\x11\n" +
+	"\x0f_worker_changedB\x15\n" +
+	"\x13_connection_changedB\x0e\n" +
+	"\f_gpu_changed\"\xdb\x02\n" +
+	"\x0eNetTestSummary\x121\n" +
+	"\x06ran_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05ranAt\x12$\n" +
+	"\x0eapi_latency_ms\x18\x02 \x01(\x01R\fapiLatencyMs\x12.\n" +
+	"\x13api_throughput_mbps\x18\x03 \x01(\x01R\x11apiThroughputMbps\x12\"\n" +
+	"\rws_connect_ms\x18\x04 \x01(\x01R\vwsConnectMs\x12'\n" +
+	"\x10ws_round_trip_ms\x18\x05 \x01(\x01R\rwsRoundTripMs\x12.\n" +
+	"\x13cdn_throughput_mbps\x18\x06 \x01(\x01R\x11cdnThroughputMbps\x12+\n" +
+	"\x11unreachable_ports\x18\a \x03(\x05R\x10unreachablePorts\x12\x16\n" +
+	"\x06failed\x18\b \x03(\tR\x06failed\"\xe7\x01\n" +
+	"\x0eAgentDiskUsage\x12\x1f\n" +
+	"\vcache_bytes\x18\x01 \x01(\x03R\n" +
+	"cacheBytes\x12\x1d\n" +
+	"\n" +
+	"logs_bytes\x18\x02 \x01(\x03R\tlogsBytes\x12\x1d\n" +
+	"\n" +
+	"free_bytes\x18\x03 \x01(\x04R\tfreeBytes\x12\x1f\n" +
+	"\vtotal_bytes\x18\x04 \x01(\x04R\n" +
+	"totalBytes\x12\x1a\n" +
+	"\bpressure\x18\x05 \x01(\bR\bpressure\x129\n" +
+	"\n" +
+	"checked_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcheckedAt\"\x95\x04\n" +
+	"\rStatusRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12-\n" +
+	"\x04gpus\x18\x03 \x03(\v2\x19.gpugo.agent.v1.GPUStatusR\x04gpus\x126\n" +
+	"\aworkers\x18\x04 \x03(\v2\x1c.gpugo.agent.v1.WorkerStatusR\aworkers\x12\x14\n" +
+	"\x05event\x18\x05 \x01(\tR\x05event\x122\n" +
+	"\x12license_expiration\x18\x06 \x01(\x03H\x00R\x11licenseExpiration\x88\x01\x01\x12%\n" +
+	"\x0elicense_status\x18\a \x01(\tR\rlicenseStatus\x12\x18\n" +
+	"\ametrics\x18\b \x01(\tR\ametrics\x129\n" +
+	"\bnet_test\x18\t \x01(\v2\x1e.gpugo.agent.v1.NetTestSummaryR\anetTest\x122\n" +
+	"\x04disk\x18\n" +
+	" \x01(\v2\x1e.gpugo.agent.v1.AgentDiskUsageR\x04disk\x12\x1b\n" +
+	"\treport_id\x18\v \x01(\tR\breportId\x12\x1a\n" +
+	"\bbackfill\x18\f \x01(\bR\bbackfillB\x15\n" +
+	"\x13_license_expiration\"\"\n" +
+	"\n" +
+	"ShareCodes\x12\x14\n" +
+	"\x05codes\x18\x01 \x03(\tR\x05codes\"\xcc\x01\n" +
+	"\x0fShareQuotaState\x12+\n" +
+	"\x12gpu_hours_per_week\x18\x01 \x01(\x01R\x0fgpuHoursPerWeek\x12.\n" +
+	"\x13max_session_minutes\x18\x02 \x01(\x05R\x11maxSessionMinutes\x126\n" +
+	"\x17max_concurrent_sessions\x18\x03 \x01(\x05R\x15maxConcurrentSessions\x12$\n" +
+	"\x0egpu_hours_used\x18\x04 \x01(\x01R\fgpuHoursUsed\"\xa7\x04\n" +
+	"\x0eStatusResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12%\n" +
+	"\x0econfig_version\x18\x02 \x01(\x05R\rconfigVersion\x121\n" +
+	"\alicense\x18\x03 \x01(\v2\x17.gpugo.agent.v1.LicenseR\alicense\x12b\n" +
+	"\x12worker_share_codes\x18\x04 \x03(\v24.gpugo.agent.v1.StatusResponse.WorkerShareCodesEntryR\x10workerShareCodes\x12'\n" +
+	"\x0fsecret_rotation\x18\x05 \x01(\tR\x0esecretRotation\x12R\n" +
+	"\fshare_quotas\x18\x06 \x03(\v2/.gpugo.agent.v1.StatusResponse.ShareQuotasEntryR\vshareQuotas\x1a_\n" +
+	"\x15WorkerShareCodesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x120\n" +
+	"\x05value\x18\x02 \x01(\v2\x1a.gpugo.agent.v1.ShareCodesR\x05value:\x028\x01\x1a_\n" +
+	"\x10ShareQuotasEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.gpugo.agent.v1.ShareQuotaStateR\x05value:\x028\x01\"z\n" +
+	"\rSystemMetrics\x12\x1b\n" +
+	"\tcpu_usage\x18\x01 \x01(\x01R\bcpuUsage\x12$\n" +
+	"\x0ememory_used_mb\x18\x02 \x01(\x03R\fmemoryUsedMb\x12&\n" +
+	"\x0fmemory_total_mb\x18\x03 \x01(\x03R\rmemoryTotalMb\"\xcc\x01\n" +
+	"\x0eMetricsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x125\n" +
+	"\x06system\x18\x03 \x01(\v2\x1d.gpugo.agent.v1.SystemMetricsR\x06system\x12.\n" +
+	"\x04gpus\x18\x04 \x03(\v2\x1a.gpugo.agent.v1.GPUMetricsR\x04gpus\"\x11\n" +
+	"\x0fMetricsResponse\"C\n" +
+	"\x10SubscribeRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\"\"\n" +
+	"\fTopicMessage\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\tR\x04data2\x9c\x03\n" +
+	"\fAgentService\x12M\n" +
+	"\bRegister\x12\x1f.gpugo.agent.v1.RegisterRequest\x1a .gpugo.agent.v1.RegisterResponse\x12M\n" +
+	"\tGetConfig\x12 .gpugo.agent.v1.GetConfigRequest\x1a\x1e.gpugo.agent.v1.ConfigResponse\x12M\n" +
+	"\fReportStatus\x12\x1d.gpugo.agent.v1.StatusRequest\x1a\x1e.gpugo.agent.v1.StatusResponse\x12P\n" +
+	"\rReportMetrics\x12\x1e.gpugo.agent.v1.MetricsRequest\x1a\x1f.gpugo.agent.v1.MetricsResponse\x12M\n" +
+	"\tSubscribe\x12 .gpugo.agent.v1.SubscribeRequest\x1a\x1c.gpugo.agent.v1.TopicMessage0\x01B1Z/github.com/NexusGPU/gpu-go/internal/api/agentpbb\x06proto3"
+
+var (
+	file_agent_proto_rawDescOnce sync.Once
+	file_agent_proto_rawDescData []byte
+)
+
+func file_agent_proto_rawDescGZIP() []byte {
+	file_agent_proto_rawDescOnce.Do(func() {
+		file_agent_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_agent_proto_rawDesc), len(file_agent_proto_rawDesc)))
+	})
+	return file_agent_proto_rawDescData
+}
+
+var file_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
+var file_agent_proto_goTypes = []any{
+	(*GPUPartition)(nil),          // 0: gpugo.agent.v1.GPUPartition
+	(*GPUMetrics)(nil),            // 1: gpugo.agent.v1.GPUMetrics
+	(*GPUInfo)(nil),               // 2: gpugo.agent.v1.GPUInfo
+	(*RegisterRequest)(nil),       // 3: gpugo.agent.v1.RegisterRequest
+	(*License)(nil),               // 4: gpugo.agent.v1.License
+	(*RegisterResponse)(nil),      // 5: gpugo.agent.v1.RegisterResponse
+	(*GetConfigRequest)(nil),      // 6: gpugo.agent.v1.GetConfigRequest
+	(*WorkerStandby)(nil),         // 7: gpugo.agent.v1.WorkerStandby
+	(*WorkerFairness)(nil),        // 8: gpugo.agent.v1.WorkerFairness
+	(*WorkerGPUTuning)(nil),       // 9: gpugo.agent.v1.WorkerGPUTuning
+	(*WorkerConfig)(nil),          // 10: gpugo.agent.v1.WorkerConfig
+	(*RelayConfig)(nil),           // 11: gpugo.agent.v1.RelayConfig
+	(*ReportingConfig)(nil),       // 12: gpugo.agent.v1.ReportingConfig
+	(*WorkerLogConfig)(nil),       // 13: gpugo.agent.v1.WorkerLogConfig
+	(*DiskConfig)(nil),            // 14: gpugo.agent.v1.DiskConfig
+	(*ConfigResponse)(nil),        // 15: gpugo.agent.v1.ConfigResponse
+	(*GPUStatus)(nil),             // 16: gpugo.agent.v1.GPUStatus
+	(*ConnectionInfo)(nil),        // 17: gpugo.agent.v1.ConnectionInfo
+	(*ShareUsage)(nil),            // 18: gpugo.agent.v1.ShareUsage
+	(*WorkerCrashReport)(nil),     // 19: gpugo.agent.v1.WorkerCrashReport
+	(*WorkerCrashLoop)(nil),       // 20: gpugo.agent.v1.WorkerCrashLoop
+	(*WorkerProbeResult)(nil),     // 21: gpugo.agent.v1.WorkerProbeResult
+	(*WorkerHealth)(nil),          // 22: gpugo.agent.v1.WorkerHealth
+	(*WorkerStatus)(nil),          // 23: gpugo.agent.v1.WorkerStatus
+	(*NetTestSummary)(nil),        // 24: gpugo.agent.v1.NetTestSummary
+	(*AgentDiskUsage)(nil),        // 25: gpugo.agent.v1.AgentDiskUsage
+	(*StatusRequest)(nil),         // 26: gpugo.agent.v1.StatusRequest
+	(*ShareCodes)(nil),            // 27: gpugo.agent.v1.ShareCodes
+	(*ShareQuotaState)(nil),       // 28: gpugo.agent.v1.ShareQuotaState
+	(*StatusResponse)(nil),        // 29: gpugo.agent.v1.StatusResponse
+	(*SystemMetrics)(nil),         // 30: gpugo.agent.v1.SystemMetrics
+	(*MetricsRequest)(nil),        // 31: gpugo.agent.v1.MetricsRequest
+	(*MetricsResponse)(nil),       // 32: gpugo.agent.v1.MetricsResponse
+	(*SubscribeRequest)(nil),      // 33: gpugo.agent.v1.SubscribeRequest
+	(*TopicMessage)(nil),          // 34: gpugo.agent.v1.TopicMessage
+	nil,                           // 35: gpugo.agent.v1.WorkerConfig.EnvEntry
+	nil,                           // 36: gpugo.agent.v1.StatusResponse.WorkerShareCodesEntry
+	nil,                           // 37: gpugo.agent.v1.StatusResponse.ShareQuotasEntry
+	(*timestamppb.Timestamp)(nil), // 38: google.protobuf.Timestamp
+}
+var file_agent_proto_depIdxs = []int32{
+	0,  // 0: gpugo.agent.v1.GPUInfo.partitions:type_name -> gpugo.agent.v1.GPUPartition
+	1,  // 1: gpugo.agent.v1.GPUInfo.metrics:type_name -> gpugo.agent.v1.GPUMetrics
+	2,  // 2: gpugo.agent.v1.RegisterRequest.gpus:type_name -> gpugo.agent.v1.GPUInfo
+	4,  // 3: gpugo.agent.v1.RegisterResponse.license:type_name -> gpugo.agent.v1.License
+	35, // 4: gpugo.agent.v1.WorkerConfig.env:type_name -> gpugo.agent.v1.WorkerConfig.EnvEntry
+	7,  // 5: gpugo.agent.v1.WorkerConfig.standby:type_name -> gpugo.agent.v1.WorkerStandby
+	8,  // 6: gpugo.agent.v1.WorkerConfig.fairness:type_name -> gpugo.agent.v1.WorkerFairness
+	9,  // 7: gpugo.agent.v1.WorkerConfig.tuning:type_name -> gpugo.agent.v1.WorkerGPUTuning
+	10, // 8: gpugo.agent.v1.ConfigResponse.workers:type_name -> gpugo.agent.v1.WorkerConfig
+	4,  // 9: gpugo.agent.v1.ConfigResponse.license:type_name -> gpugo.agent.v1.License
+	11, // 10: gpugo.agent.v1.ConfigResponse.relay:type_name -> gpugo.agent.v1.RelayConfig
+	12, // 11: gpugo.agent.v1.ConfigResponse.reporting:type_name -> gpugo.agent.v1.ReportingConfig
+	13, // 12: gpugo.agent.v1.ConfigResponse.worker_logs:type_name -> gpugo.agent.v1.WorkerLogConfig
+	14, // 13: gpugo.agent.v1.ConfigResponse.disk:type_name -> gpugo.agent.v1.DiskConfig
+	0,  // 14: gpugo.agent.v1.GPUStatus.partitions:type_name -> gpugo.agent.v1.GPUPartition
+	38, // 15: gpugo.agent.v1.ConnectionInfo.connected_at:type_name -> google.protobuf.Timestamp
+	38, // 16: gpugo.agent.v1.WorkerCrashReport.detected_at:type_name -> google.protobuf.Timestamp
+	38, // 17: gpugo.agent.v1.WorkerCrashLoop.since:type_name -> google.protobuf.Timestamp
+	21, // 18: gpugo.agent.v1.WorkerHealth.probes:type_name -> gpugo.agent.v1.WorkerProbeResult
+	38, // 19: gpugo.agent.v1.WorkerHealth.checked_at:type_name -> google.protobuf.Timestamp
+	17, // 20: gpugo.agent.v1.WorkerStatus.connections:type_name -> gpugo.agent.v1.ConnectionInfo
+	18, // 21: gpugo.agent.v1.WorkerStatus.usage:type_name -> gpugo.agent.v1.ShareUsage
+	19, // 22: gpugo.agent.v1.WorkerStatus.crashes:type_name -> gpugo.agent.v1.WorkerCrashReport
+	20, // 23: gpugo.agent.v1.WorkerStatus.crash_loop:type_name -> gpugo.agent.v1.WorkerCrashLoop
+	38, // 24: gpugo.agent.v1.WorkerStatus.drain_deadline:type_name -> google.protobuf.Timestamp
+	22, // 25: gpugo.agent.v1.WorkerStatus.health:type_name -> gpugo.agent.v1.WorkerHealth
+	38, // 26: gpugo.agent.v1.NetTestSummary.ran_at:type_name -> google.protobuf.Timestamp
+	38, // 27: gpugo.agent.v1.AgentDiskUsage.checked_at:type_name -> google.protobuf.Timestamp
+	38, // 28: gpugo.agent.v1.StatusRequest.timestamp:type_name -> google.protobuf.Timestamp
+	16, // 29: gpugo.agent.v1.StatusRequest.gpus:type_name -> gpugo.agent.v1.GPUStatus
+	23, // 30: gpugo.agent.v1.StatusRequest.workers:type_name -> gpugo.agent.v1.WorkerStatus
+	24, // 31: gpugo.agent.v1.StatusRequest.net_test:type_name -> gpugo.agent.v1.NetTestSummary
+	25, // 32: gpugo.agent.v1.StatusRequest.disk:type_name -> gpugo.agent.v1.AgentDiskUsage
+	4,  // 33: gpugo.agent.v1.StatusResponse.license:type_name -> gpugo.agent.v1.License
+	36, // 34: gpugo.agent.v1.StatusResponse.worker_share_codes:type_name -> gpugo.agent.v1.StatusResponse.WorkerShareCodesEntry
+	37, // 35: gpugo.agent.v1.StatusResponse.share_quotas:type_name -> gpugo.agent.v1.StatusResponse.ShareQuotasEntry
+	38, // 36: gpugo.agent.v1.MetricsRequest.timestamp:type_name -> google.protobuf.Timestamp
+	30, // 37: gpugo.agent.v1.MetricsRequest.system:type_name -> gpugo.agent.v1.SystemMetrics
+	1,  // 38: gpugo.agent.v1.MetricsRequest.gpus:type_name -> gpugo.agent.v1.GPUMetrics
+	27, // 39: gpugo.agent.v1.StatusResponse.WorkerShareCodesEntry.value:type_name -> gpugo.agent.v1.ShareCodes
+	28, // 40: gpugo.agent.v1.StatusResponse.ShareQuotasEntry.value:type_name -> gpugo.agent.v1.ShareQuotaState
+	3,  // 41: gpugo.agent.v1.AgentService.Register:input_type -> gpugo.agent.v1.RegisterRequest
+	6,  // 42: gpugo.agent.v1.AgentService.GetConfig:input_type -> gpugo.agent.v1.GetConfigRequest
+	26, // 43: gpugo.agent.v1.AgentService.ReportStatus:input_type -> gpugo.agent.v1.StatusRequest
+	31, // 44: gpugo.agent.v1.AgentService.ReportMetrics:input_type -> gpugo.agent.v1.MetricsRequest
+	33, // 45: gpugo.agent.v1.AgentService.Subscribe:input_type -> gpugo.agent.v1.SubscribeRequest
+	5,  // 46: gpugo.agent.v1.AgentService.Register:output_type -> gpugo.agent.v1.RegisterResponse
+	15, // 47: gpugo.agent.v1.AgentService.GetConfig:output_type -> gpugo.agent.v1.ConfigResponse
+	29, // 48: gpugo.agent.v1.AgentService.ReportStatus:output_type -> gpugo.agent.v1.StatusResponse
+	32, // 49: gpugo.agent.v1.AgentService.ReportMetrics:output_type -> gpugo.agent.v1.MetricsResponse
+	34, // 50: gpugo.agent.v1.AgentService.Subscribe:output_type -> gpugo.agent.v1.TopicMessage
+	46, // [46:51] is the sub-list for method output_type
+	41, // [41:46] is the sub-list for method input_type
+	41, // [41:41] is the sub-list for extension type_name
+	41, // [41:41] is the sub-list for extension extendee
+	0,  // [0:41] is the sub-list for field type_name
+}
+
+func init() { file_agent_proto_init() }
+func file_agent_proto_init() {
+	if File_agent_proto != nil {
+		return
+	}
+	file_agent_proto_msgTypes[9].OneofWrappers = []any{}
+	file_agent_proto_msgTypes[12].OneofWrappers = []any{}
+	file_agent_proto_msgTypes[16].OneofWrappers = []any{}
+	file_agent_proto_msgTypes[19].OneofWrappers = []any{}
+	file_agent_proto_msgTypes[23].OneofWrappers = []any{}
+	file_agent_proto_msgTypes[26].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_proto_rawDesc), len(file_agent_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   38,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_agent_proto_goTypes,
+		DependencyIndexes: file_agent_proto_depIdxs,
+		MessageInfos:      file_agent_proto_msgTypes,
+	}.Build()
+	File_agent_proto = out.File
+	file_agent_proto_goTypes = nil
+	file_agent_proto_depIdxs = nil
+}
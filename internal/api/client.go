@@ -1,13 +1,23 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/NexusGPU/gpu-go/internal/api/agentpb"
 	"github.com/go-resty/resty/v2"
+	"golang.org/x/net/websocket"
 	"k8s.io/klog/v2"
 )
 
@@ -29,7 +39,13 @@ type Client struct {
 	baseURL     string
 	httpClient  *resty.Client
 	userToken   string
+	secretMu    sync.RWMutex // agentSecret changes while an agent is running on rotation
 	agentSecret string
+	scopeHint   func(scope string) string
+	// agentTransport is an AgentTransport* constant; grpc is set up once the
+	// server offers it
+	agentTransport string
+	grpc           grpcTransport
 }
 
 // ClientOption is a function that configures the client
@@ -56,6 +72,14 @@ func WithAgentSecret(secret string) ClientOption {
 	}
 }
 
+// WithScopeHint sets how to get a token with a missing scope, added to the
+// message of a ScopeError
+func WithScopeHint(hint func(scope string) string) ClientOption {
+	return func(c *Client) {
+		c.scopeHint = hint
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client
 func WithHTTPClient(httpClient *resty.Client) ClientOption {
 	return func(c *Client) {
@@ -67,8 +91,9 @@ func WithHTTPClient(httpClient *resty.Client) ClientOption {
 // The base URL defaults to GPU_GO_ENDPOINT env var if set, otherwise https://tensor-fusion.ai
 func NewClient(opts ...ClientOption) *Client {
 	c := &Client{
-		baseURL:    GetDefaultBaseURL(),
-		httpClient: resty.New().SetTimeout(defaultTimeout),
+		baseURL:        GetDefaultBaseURL(),
+		httpClient:     resty.New().SetTimeout(defaultTimeout),
+		agentTransport: AgentTransportREST,
 	}
 
 	for _, opt := range opts {
@@ -95,6 +120,8 @@ func (c *Client) SetUserToken(token string) {
 
 // SetAgentSecret sets the agent secret for authentication
 func (c *Client) SetAgentSecret(secret string) {
+	c.secretMu.Lock()
+	defer c.secretMu.Unlock()
 	c.agentSecret = secret
 }
 
@@ -103,9 +130,128 @@ func (c *Client) userAuthHeader() string {
 }
 
 func (c *Client) agentAuthHeader() string {
+	c.secretMu.RLock()
+	defer c.secretMu.RUnlock()
 	return "Bearer " + c.agentSecret
 }
 
+// StatusError is returned when the server answers with an unexpected status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed: status %d, body: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is a 404 response from the server
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether err is a 409 response from the server
+func IsConflict(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict
+}
+
+// IsUnreachable reports whether err means the request did not get through
+// to a working server: a network error, a timeout or a 5xx, 408 or 429
+// response. Unlike a refused request, the same request may succeed later.
+func IsUnreachable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	switch statusErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return statusErr.StatusCode >= http.StatusInternalServerError
+}
+
+// ScopeError is returned when the server refuses a request because the token
+// lacks a scope the request needs
+type ScopeError struct {
+	*StatusError
+	// Scope is the scope the request needs
+	Scope string
+	// TokenScopes are the scopes of the token, when the server lists them
+	TokenScopes []string
+	// Operation is the refused request, such as POST /api/v1/workers
+	Operation string
+	// Hint tells how to get a token with Scope, see WithScopeHint
+	Hint string
+}
+
+func (e *ScopeError) Error() string {
+	msg := fmt.Sprintf("token is missing the %q scope required for %s", e.Scope, e.Operation)
+	if len(e.TokenScopes) > 0 {
+		msg += fmt.Sprintf(" (token scopes: %s)", strings.Join(e.TokenScopes, ", "))
+	}
+	if e.Hint != "" {
+		msg += "; " + e.Hint
+	}
+	return msg
+}
+
+func (e *ScopeError) Unwrap() error {
+	return e.StatusError
+}
+
+// MissingScope returns the scope a request refused for lacking one needs
+func MissingScope(err error) (string, bool) {
+	var scopeErr *ScopeError
+	if errors.As(err, &scopeErr) {
+		return scopeErr.Scope, true
+	}
+	return "", false
+}
+
+// scopeErrorBody is the body of a 403 response to a token lacking a scope
+type scopeErrorBody struct {
+	Error         string   `json:"error"`
+	RequiredScope string   `json:"required_scope"`
+	TokenScopes   []string `json:"token_scopes,omitempty"`
+}
+
+// statusError builds the error for an unexpected response to method path:
+// a ScopeError when the token lacks a scope, a StatusError otherwise
+func (c *Client) statusError(method, path string, code int, body string) error {
+	statusErr := &StatusError{StatusCode: code, Body: body}
+	if code != http.StatusForbidden {
+		return statusErr
+	}
+	var parsed scopeErrorBody
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil || parsed.Error != "insufficient_scope" || parsed.RequiredScope == "" {
+		return statusErr
+	}
+	scopeErr := &ScopeError{
+		StatusError: statusErr,
+		Scope:       parsed.RequiredScope,
+		TokenScopes: parsed.TokenScopes,
+		Operation:   method + " " + path,
+	}
+	if c.scopeHint != nil {
+		scopeErr.Hint = c.scopeHint(parsed.RequiredScope)
+	}
+	return scopeErr
+}
+
+// responseError is statusError for a resty response
+func (c *Client) responseError(resp *resty.Response) error {
+	path := ""
+	if resp.Request.RawRequest != nil {
+		path = resp.Request.RawRequest.URL.Path
+	}
+	return c.statusError(resp.Request.Method, path, resp.StatusCode(), resp.String())
+}
+
 // authType constants for request helpers
 type authType int
 
@@ -139,7 +285,7 @@ func doGet[T any](c *Client, ctx context.Context, path string, auth authType, cu
 	}
 
 	if httpResp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("request failed: status %d, body: %s", httpResp.StatusCode(), httpResp.String())
+		return nil, c.responseError(httpResp)
 	}
 
 	return &resp, nil
@@ -181,7 +327,7 @@ func doPost[T any](c *Client, ctx context.Context, path string, body any, auth a
 		}
 	}
 	if !statusOk {
-		return nil, fmt.Errorf("request failed: status %d, body: %s", httpResp.StatusCode(), httpResp.String())
+		return nil, c.responseError(httpResp)
 	}
 
 	return &resp, nil
@@ -208,7 +354,7 @@ func doPostNoResponse(c *Client, ctx context.Context, path string, body any, aut
 	}
 
 	if httpResp.StatusCode() != http.StatusOK {
-		return fmt.Errorf("request failed: status %d, body: %s", httpResp.StatusCode(), httpResp.String())
+		return c.responseError(httpResp)
 	}
 
 	return nil
@@ -237,7 +383,7 @@ func doPatch[T any](c *Client, ctx context.Context, path string, body any, auth
 	}
 
 	if httpResp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("request failed: status %d, body: %s", httpResp.StatusCode(), httpResp.String())
+		return nil, c.responseError(httpResp)
 	}
 
 	return &resp, nil
@@ -262,7 +408,7 @@ func doDelete(c *Client, ctx context.Context, path string, auth authType) error
 	}
 
 	if httpResp.StatusCode() != http.StatusOK && httpResp.StatusCode() != http.StatusNoContent {
-		return fmt.Errorf("request failed: status %d, body: %s", httpResp.StatusCode(), httpResp.String())
+		return c.responseError(httpResp)
 	}
 
 	return nil
@@ -276,10 +422,35 @@ func (c *Client) GenerateToken(ctx context.Context, tokenType string) (*TokenRes
 	return doPost[TokenResponse](c, ctx, "/api/v1/tokens/generate", req, authUser, "", http.StatusOK)
 }
 
+// ListPersonalAccessTokens lists the personal access tokens of the user
+func (c *Client) ListPersonalAccessTokens(ctx context.Context) (*PATListResponse, error) {
+	return doGet[PATListResponse](c, ctx, "/api/v1/tokens/pat", authUser, "")
+}
+
+// CreatePersonalAccessToken creates a personal access token
+func (c *Client) CreatePersonalAccessToken(ctx context.Context, req *PATCreateRequest) (*PATCreateResponse, error) {
+	return doPost[PATCreateResponse](c, ctx, "/api/v1/tokens/pat", req, authUser, "")
+}
+
+// RevokePersonalAccessToken revokes a personal access token
+func (c *Client) RevokePersonalAccessToken(ctx context.Context, tokenID string) error {
+	return doDelete(c, ctx, "/api/v1/tokens/pat/"+url.PathEscape(tokenID), authUser)
+}
+
 // --- Agent APIs ---
 
 // RegisterAgent registers an agent with the server
 func (c *Client) RegisterAgent(ctx context.Context, tempToken string, req *AgentRegisterRequest) (*AgentRegisterResponse, error) {
+	if resp, ok, err := callGRPC(c, ctx, agentpb.AgentService_Register_FullMethodName, "Bearer "+tempToken,
+		func(ctx context.Context, svc agentpb.AgentServiceClient) (*AgentRegisterResponse, error) {
+			resp, err := svc.Register(ctx, toPBRegisterRequest(req))
+			if err != nil {
+				return nil, err
+			}
+			return fromPBRegisterResponse(resp), nil
+		}); ok {
+		return resp, err
+	}
 	return doPost[AgentRegisterResponse](c, ctx, "/api/v1/agents/register", req, authCustom, "Bearer "+tempToken)
 }
 
@@ -293,6 +464,11 @@ func (c *Client) GetAgent(ctx context.Context, agentID string) (*AgentInfo, erro
 	return doGet[AgentInfo](c, ctx, "/api/v1/agents/"+agentID, authUser, "")
 }
 
+// UpdateAgent updates an agent's owner-managed metadata such as labels
+func (c *Client) UpdateAgent(ctx context.Context, agentID string, req *AgentUpdateRequest) (*AgentInfo, error) {
+	return doPatch[AgentInfo](c, ctx, "/api/v1/agents/"+agentID, req, authUser)
+}
+
 // DeleteAgent deletes an agent (requires user/PAT authentication)
 func (c *Client) DeleteAgent(ctx context.Context, agentID string) error {
 	return doDelete(c, ctx, "/api/v1/agents/"+agentID, authUser)
@@ -306,19 +482,199 @@ func (c *Client) SelfDeleteAgent(ctx context.Context, agentID string) error {
 
 // GetAgentConfig gets the agent configuration
 func (c *Client) GetAgentConfig(ctx context.Context, agentID string) (*AgentConfigResponse, error) {
+	if resp, ok, err := callGRPC(c, ctx, agentpb.AgentService_GetConfig_FullMethodName, c.agentAuthHeader(),
+		func(ctx context.Context, svc agentpb.AgentServiceClient) (*AgentConfigResponse, error) {
+			resp, err := svc.GetConfig(ctx, &agentpb.GetConfigRequest{AgentId: agentID})
+			if err != nil {
+				return nil, err
+			}
+			return fromPBConfigResponse(resp), nil
+		}); ok {
+		return resp, err
+	}
 	return doGet[AgentConfigResponse](c, ctx, "/api/v1/agents/"+agentID+"/config", authAgent, "")
 }
 
+// RotateAgentSecret asks the server for a new agent secret, authenticating
+// with the current one
+func (c *Client) RotateAgentSecret(ctx context.Context, agentID string) (*AgentSecretRotateResponse, error) {
+	return doPost[AgentSecretRotateResponse](c, ctx, "/api/v1/agents/"+agentID+"/secret/rotate", struct{}{}, authAgent, "")
+}
+
+// RenewLicenseOffline fulfills the offline license renewal request of an
+// agent that cannot reach the platform, returning the bundle to import on it
+func (c *Client) RenewLicenseOffline(ctx context.Context, req *LicenseRenewalRequest) (*LicenseRenewalBundle, error) {
+	return doPost[LicenseRenewalBundle](c, ctx, "/api/v1/agents/"+req.AgentID+"/license/offline-renewal", req, authUser, "")
+}
+
+// IssueWorkerCertificate asks the server to sign a TLS certificate for a
+// worker served by the agent
+func (c *Client) IssueWorkerCertificate(ctx context.Context, agentID, workerID string, req *WorkerCertificateRequest) (*WorkerCertificateResponse, error) {
+	return doPost[WorkerCertificateResponse](c, ctx, "/api/v1/agents/"+agentID+"/workers/"+workerID+"/certificate", req, authAgent, "")
+}
+
+// GetAgentPeerStatus returns the heartbeat status of an agent's HA peer
+func (c *Client) GetAgentPeerStatus(ctx context.Context, agentID, peerID string) (*AgentPeerStatus, error) {
+	return doGet[AgentPeerStatus](c, ctx, "/api/v1/agents/"+agentID+"/peers/"+peerID, authAgent, "")
+}
+
+// ReportWorkerFailover tells the server that the agent took over a worker
+// from its HA peer
+func (c *Client) ReportWorkerFailover(ctx context.Context, agentID, workerID string, req *WorkerFailoverRequest) error {
+	return doPostNoResponse(c, ctx, "/api/v1/agents/"+agentID+"/workers/"+workerID+"/failover", req, authAgent)
+}
+
 // ReportAgentStatus reports the agent status to the server and returns the response
 func (c *Client) ReportAgentStatus(ctx context.Context, agentID string, req *AgentStatusRequest) (*AgentStatusResponse, error) {
+	if resp, ok, err := callGRPC(c, ctx, agentpb.AgentService_ReportStatus_FullMethodName, c.agentAuthHeader(),
+		func(ctx context.Context, svc agentpb.AgentServiceClient) (*AgentStatusResponse, error) {
+			resp, err := svc.ReportStatus(ctx, toPBStatusRequest(agentID, req))
+			if err != nil {
+				return nil, err
+			}
+			return fromPBStatusResponse(resp), nil
+		}); ok {
+		return resp, err
+	}
 	return doPost[AgentStatusResponse](c, ctx, "/api/v1/agents/"+agentID+"/status", req, authAgent, "")
 }
 
+// ReportAgentEvents uploads a batch of agent events to the server
+func (c *Client) ReportAgentEvents(ctx context.Context, agentID string, req *AgentEventsRequest) error {
+	return doPostNoResponse(c, ctx, "/api/v1/agents/"+agentID+"/events", req, authAgent)
+}
+
+// PollAgentTopic long-polls the messages published on one of the agent's SSE
+// topics after cursor; the server holds the request for up to wait until a
+// message arrives. An empty cursor starts with new messages.
+func (c *Client) PollAgentTopic(ctx context.Context, agentID, topic, cursor string, wait time.Duration) (*AgentPollResponse, error) {
+	query := url.Values{}
+	query.Set("topic", topic)
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	query.Set("wait", strconv.Itoa(int(wait.Seconds())))
+	return doGet[AgentPollResponse](c, ctx, "/api/v1/agents/"+agentID+"/poll?"+query.Encode(), authAgent, "")
+}
+
 // ReportAgentMetrics reports the agent metrics to the server
 func (c *Client) ReportAgentMetrics(ctx context.Context, agentID string, req *AgentMetricsRequest) error {
+	if _, ok, err := callGRPC(c, ctx, agentpb.AgentService_ReportMetrics_FullMethodName, c.agentAuthHeader(),
+		func(ctx context.Context, svc agentpb.AgentServiceClient) (*agentpb.MetricsResponse, error) {
+			return svc.ReportMetrics(ctx, toPBMetricsRequest(agentID, req))
+		}); ok {
+		return err
+	}
 	return doPostNoResponse(c, ctx, "/api/v1/agents/"+agentID+"/metrics", req, authAgent)
 }
 
+// PingAgentNetTest makes the smallest authenticated request to the server,
+// timed by the network self-test
+func (c *Client) PingAgentNetTest(ctx context.Context, agentID string) error {
+	_, err := doGet[SuccessResponse](c, ctx, "/api/v1/agents/"+agentID+"/nettest/ping", authAgent, "")
+	return err
+}
+
+// DownloadAgentNetTest streams size bytes of test data from the server to w
+// and returns how many were received
+func (c *Client) DownloadAgentNetTest(ctx context.Context, agentID string, size int64, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+"/api/v1/agents/"+agentID+"/nettest/download?bytes="+strconv.FormatInt(size, 10), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", c.agentAuthHeader())
+
+	// Not the resty client: it would buffer the whole body
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return 0, c.statusError(resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, string(body))
+	}
+	return io.Copy(w, resp.Body)
+}
+
+// DialAgentNetTest opens the server's echo WebSocket for the network self-test
+func (c *Client) DialAgentNetTest(ctx context.Context, agentID string) (*websocket.Conn, error) {
+	target, err := url.Parse(c.baseURL + "/api/v1/agents/" + agentID + "/nettest/ws")
+	if err != nil {
+		return nil, err
+	}
+	origin := *target
+	switch target.Scheme {
+	case "https":
+		target.Scheme = "wss"
+	case "http":
+		target.Scheme = "ws"
+	}
+	cfg, err := websocket.NewConfig(target.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+	cfg.Header.Set("Authorization", c.agentAuthHeader())
+	return cfg.DialContext(ctx)
+}
+
+// CheckAgentPorts asks the server to connect back to ports of the agent
+func (c *Client) CheckAgentPorts(ctx context.Context, agentID string, req *PortReachRequest) (*PortReachResponse, error) {
+	return doPost[PortReachResponse](c, ctx, "/api/v1/agents/"+agentID+"/nettest/reach", req, authAgent, "")
+}
+
+// ExecAgentCommand runs a command on an agent and passes the output chunks
+// to onChunk as the server relays them, until the chunk with EOF
+func (c *Client) ExecAgentCommand(ctx context.Context, agentID string, req *AgentExecStartRequest, onChunk func(*AgentExecChunk) error) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.baseURL+"/api/v1/agents/"+agentID+"/exec", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Authorization", c.userAuthHeader())
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/x-ndjson")
+
+	// Not the resty client: its timeout would cut off a long-running command
+	resp, err := (&http.Client{}).Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return c.statusError(resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, string(body))
+	}
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk AgentExecChunk
+		if err := dec.Decode(&chunk); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("exec output stream interrupted: %w", err)
+		}
+		if err := onChunk(&chunk); err != nil {
+			return err
+		}
+		if chunk.EOF {
+			return nil
+		}
+	}
+}
+
+// SendAgentExecChunk delivers command output for an AgentExecRequest
+func (c *Client) SendAgentExecChunk(ctx context.Context, agentID string, chunk *AgentExecChunk) (*AgentExecChunkResponse, error) {
+	return doPost[AgentExecChunkResponse](c, ctx, "/api/v1/agents/"+agentID+"/exec-output", chunk, authAgent, "")
+}
+
 // --- Worker APIs ---
 
 // CreateWorker creates a new worker
@@ -326,6 +682,12 @@ func (c *Client) CreateWorker(ctx context.Context, req *WorkerCreateRequest) (*W
 	return doPost[WorkerInfo](c, ctx, "/api/v1/workers", req, authUser, "")
 }
 
+// ValidateWorker has the server check a worker config, as CreateWorker
+// would, without creating the worker
+func (c *Client) ValidateWorker(ctx context.Context, req *WorkerCreateRequest) (*WorkerValidationResponse, error) {
+	return doPost[WorkerValidationResponse](c, ctx, "/api/v1/workers/validate", req, authUser, "")
+}
+
 // ListWorkers lists all workers for the current user
 func (c *Client) ListWorkers(ctx context.Context, agentID, hostname string) (*WorkerListResponse, error) {
 	var resp WorkerListResponse
@@ -348,7 +710,7 @@ func (c *Client) ListWorkers(ctx context.Context, agentID, hostname string) (*Wo
 	}
 
 	if httpResp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("request failed: status %d, body: %s", httpResp.StatusCode(), httpResp.String())
+		return nil, c.responseError(httpResp)
 	}
 
 	return &resp, nil
@@ -364,9 +726,65 @@ func (c *Client) UpdateWorker(ctx context.Context, workerID string, req *WorkerU
 	return doPatch[WorkerInfo](c, ctx, "/api/v1/workers/"+workerID, req, authUser)
 }
 
-// DeleteWorker deletes a worker
-func (c *Client) DeleteWorker(ctx context.Context, workerID string) error {
-	return doDelete(c, ctx, "/api/v1/workers/"+workerID, authUser)
+// DeleteWorker deletes a worker. The agent first drains the worker's client
+// connections unless force is set.
+func (c *Client) DeleteWorker(ctx context.Context, workerID string, force bool) error {
+	path := "/api/v1/workers/" + workerID
+	if force {
+		path += "?force=true"
+	}
+	return doDelete(c, ctx, path, authUser)
+}
+
+// KillWorkerSession asks the worker's agent to end one client session; the
+// session ID is ConnectionInfo.SessionID
+func (c *Client) KillWorkerSession(ctx context.Context, workerID, sessionID string) error {
+	return doDelete(c, ctx, "/api/v1/workers/"+workerID+"/sessions/"+url.PathEscape(sessionID), authUser)
+}
+
+// ListWorkerCrashes lists crash reports uploaded for a worker
+func (c *Client) ListWorkerCrashes(ctx context.Context, workerID string) (*WorkerCrashListResponse, error) {
+	return doGet[WorkerCrashListResponse](c, ctx, "/api/v1/workers/"+workerID+"/crashes", authUser, "")
+}
+
+// StreamWorkerLogs writes a worker's log, or with output its captured stdout
+// and stderr, to w as the server relays it from the worker's agent. With
+// follow the stream stays open until ctx is done.
+func (c *Client) StreamWorkerLogs(ctx context.Context, workerID string, tail int, follow, output bool, w io.Writer) error {
+	query := url.Values{}
+	query.Set("tail", strconv.Itoa(tail))
+	query.Set("follow", strconv.FormatBool(follow))
+	if output {
+		query.Set("output", "true")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+"/api/v1/workers/"+workerID+"/logs?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", c.userAuthHeader())
+	req.Header.Set("Accept", "text/plain")
+
+	// Not the resty client: its timeout would cut off a followed stream
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return c.statusError(resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, string(body))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("log stream interrupted: %w", err)
+	}
+	return nil
+}
+
+// SendWorkerLogChunk delivers worker log lines for a WorkerLogRequest
+func (c *Client) SendWorkerLogChunk(ctx context.Context, agentID string, chunk *WorkerLogChunk) (*WorkerLogChunkResponse, error) {
+	return doPost[WorkerLogChunkResponse](c, ctx, "/api/v1/agents/"+agentID+"/worker-logs", chunk, authAgent, "")
 }
 
 // --- Share APIs ---
@@ -381,16 +799,85 @@ func (c *Client) ListShares(ctx context.Context) (*ShareListResponse, error) {
 	return doGet[ShareListResponse](c, ctx, "/api/v1/shares", authUser, "")
 }
 
+// ResolveShareAlias finds the share with the given alias among the shares
+// of the user's account
+func (c *Client) ResolveShareAlias(ctx context.Context, alias string) (*ShareInfo, error) {
+	return doGet[ShareInfo](c, ctx, "/api/v1/shares/aliases/"+url.PathEscape(alias), authUser, "")
+}
+
 // GetSharePublic gets public share information by short code
 func (c *Client) GetSharePublic(ctx context.Context, shortCode string) (*SharePublicInfo, error) {
 	return doGet[SharePublicInfo](c, ctx, "/s/"+shortCode, authNone, "")
 }
 
+// GetShareStats gets the live GPU load of the worker behind a share and the
+// sessions opened with it
+func (c *Client) GetShareStats(ctx context.Context, shortCode string) (*ShareStats, error) {
+	return doGet[ShareStats](c, ctx, "/s/"+shortCode+"/stats", authNone, "")
+}
+
+// WaitSharePublic gets share information as a client waiting for a free slot
+// on a saturated worker. The platform keeps waiterID in the worker's queue
+// while it polls and reports its position in Queue.
+func (c *Client) WaitSharePublic(ctx context.Context, shortCode, waiterID string) (*SharePublicInfo, error) {
+	query := url.Values{}
+	query.Set("waiter", waiterID)
+	return doGet[SharePublicInfo](c, ctx, "/s/"+shortCode+"?"+query.Encode(), authNone, "")
+}
+
 // DeleteShare deletes a share
 func (c *Client) DeleteShare(ctx context.Context, shareID string) error {
 	return doDelete(c, ctx, "/api/v1/shares/"+shareID, authUser)
 }
 
+// UpdateShare updates a share's settings
+func (c *Client) UpdateShare(ctx context.Context, shareID string, req *ShareUpdateRequest) (*ShareInfo, error) {
+	return doPatch[ShareInfo](c, ctx, "/api/v1/shares/"+shareID, req, authUser)
+}
+
+// RegisterShareConsumer records the client resolving a share in the share's audit trail
+func (c *Client) RegisterShareConsumer(ctx context.Context, shortCode string, req *ShareConsumerRequest) error {
+	return doPostNoResponse(c, ctx, "/s/"+shortCode+"/consumers", req, authNone)
+}
+
+// ListShareConsumers lists the clients that have resolved a share
+func (c *Client) ListShareConsumers(ctx context.Context, shareID string) (*ShareConsumerListResponse, error) {
+	return doGet[ShareConsumerListResponse](c, ctx, "/api/v1/shares/"+shareID+"/consumers", authUser, "")
+}
+
+// GetShareConsumption gets the consumption of a share since the given time,
+// aggregated by the platform
+func (c *Client) GetShareConsumption(ctx context.Context, shareID string, since time.Time) (*ShareConsumption, error) {
+	query := url.Values{}
+	query.Set("since", since.UTC().Format(time.RFC3339))
+	return doGet[ShareConsumption](c, ctx, "/api/v1/shares/"+shareID+"/consumption?"+query.Encode(), authUser, "")
+}
+
+// ListTeamWorkers lists the workers shared with a team the user belongs to
+func (c *Client) ListTeamWorkers(ctx context.Context, team string) (*TeamWorkerListResponse, error) {
+	return doGet[TeamWorkerListResponse](c, ctx, "/api/v1/teams/"+team+"/workers", authUser, "")
+}
+
+// GetTeamWorkerShare resolves the team share of a worker, given by ID or
+// name, for a member of the team
+func (c *Client) GetTeamWorkerShare(ctx context.Context, team, worker string) (*TeamShareInfo, error) {
+	return doGet[TeamShareInfo](c, ctx, "/api/v1/teams/"+team+"/workers/"+worker+"/share", authUser, "")
+}
+
+// --- Audit APIs ---
+
+// UploadAuditEntries uploads entries of the local CLI audit log
+func (c *Client) UploadAuditEntries(ctx context.Context, req *AuditUploadRequest) error {
+	return doPostNoResponse(c, ctx, "/api/v1/audit/entries", req, authUser)
+}
+
+// --- Studio APIs ---
+
+// ListStudioTemplates lists the curated studio templates of the platform registry
+func (c *Client) ListStudioTemplates(ctx context.Context) (*StudioTemplatesResponse, error) {
+	return doGet[StudioTemplatesResponse](c, ctx, "/api/v1/studio/templates", authNone, "")
+}
+
 // --- Ecosystem/Releases APIs ---
 
 // GetReleases fetches middleware releases from the ecosystem API
@@ -416,7 +903,7 @@ func (c *Client) GetReleases(ctx context.Context, vendor string, size int) (*Rel
 	}
 
 	if httpResp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("request failed: status %d, body: %s", httpResp.StatusCode(), httpResp.String())
+		return nil, c.responseError(httpResp)
 	}
 
 	return &resp, nil
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -246,6 +247,36 @@ func TestClient_CreateWorker(t *testing.T) {
 	assert.Equal(t, "pending", resp.Status)
 }
 
+func TestClient_ValidateWorker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/workers/validate", r.URL.Path)
+
+		var req WorkerCreateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, []string{"GPU-0", "GPU-1"}, req.GPUIDs)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(WorkerValidationResponse{
+			Valid: false,
+			Checks: []WorkerValidationCheck{
+				{Name: "license", Status: ValidationFail, Detail: "license allows 1 GPU per worker"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithUserToken("test-user-token"))
+	resp, err := client.ValidateWorker(context.Background(), &WorkerCreateRequest{
+		AgentID: "agent_xxxxxxxxxxxx",
+		GPUIDs:  []string{"GPU-0", "GPU-1"},
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Valid)
+	require.Len(t, resp.Checks, 1)
+	assert.Equal(t, ValidationFail, resp.Checks[0].Status)
+}
+
 func TestClient_ListWorkers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "GET", r.Method)
@@ -331,6 +362,7 @@ func TestClient_DeleteWorker(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "DELETE", r.Method)
 		assert.Equal(t, "/api/v1/workers/worker_yyyy", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("force"))
 
 		resp := SuccessResponse{Success: true}
 		w.Header().Set("Content-Type", "application/json")
@@ -343,7 +375,27 @@ func TestClient_DeleteWorker(t *testing.T) {
 		WithUserToken("test-user-token"),
 	)
 
-	err := client.DeleteWorker(context.Background(), "worker_yyyy")
+	err := client.DeleteWorker(context.Background(), "worker_yyyy", true)
+	require.NoError(t, err)
+}
+
+func TestClient_KillWorkerSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/api/v1/workers/worker_yyyy/sessions/[2001:db8::1]:51234", r.URL.Path)
+		assert.Equal(t, "/api/v1/workers/worker_yyyy/sessions/%5B2001:db8::1%5D:51234", r.URL.EscapedPath())
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SuccessResponse{Success: true})
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithUserToken("test-user-token"),
+	)
+
+	err := client.KillWorkerSession(context.Background(), "worker_yyyy", "[2001:db8::1]:51234")
 	require.NoError(t, err)
 }
 
@@ -413,6 +465,58 @@ func TestClient_GetSharePublic(t *testing.T) {
 	assert.Equal(t, "tcp://192.168.1.50:9001", resp.ConnectionURL)
 }
 
+func TestClient_GetShareStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/s/abc123/stats", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"), "the share code is the credential")
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ShareStats{
+			WorkerID: "worker_yyyy",
+			GPUs:     []GPUMetrics{{GPUID: "gpu-0", Utilization: 42, VRAMUsedMb: 1024, VRAMTotalMb: 24576}},
+			Sessions: []ConnectionInfo{{ClientIP: "10.0.0.5", ShareCode: "abc123", BytesIn: 100, BytesOut: 200}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	resp, err := client.GetShareStats(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "worker_yyyy", resp.WorkerID)
+	require.Len(t, resp.GPUs, 1)
+	assert.Equal(t, float64(42), resp.GPUs[0].Utilization)
+	require.Len(t, resp.Sessions, 1)
+	assert.Equal(t, int64(200), resp.Sessions[0].BytesOut)
+}
+
+func TestClient_WaitSharePublic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/s/abc123", r.URL.Path)
+		assert.Equal(t, "w-1", r.URL.Query().Get("waiter"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SharePublicInfo{
+			WorkerID: "worker_yyyy",
+			Queue:    &ShareQueueStatus{Connections: 4, MaxConnections: 4, Waiting: 2, Position: 2, EstimatedWaitSeconds: 300},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	resp, err := client.WaitSharePublic(context.Background(), "abc123", "w-1")
+	require.NoError(t, err)
+	require.NotNil(t, resp.Queue)
+	assert.True(t, resp.Queue.Saturated())
+	assert.Equal(t, 2, resp.Queue.Position)
+
+	var unlimited *ShareQueueStatus
+	assert.False(t, unlimited.Saturated())
+	assert.False(t, (&ShareQueueStatus{Connections: 9}).Saturated(), "no limit")
+}
+
 func TestClient_ListShares(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "GET", r.Method)
@@ -473,6 +577,347 @@ func TestClient_DeleteShare(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestClient_UpdateAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/api/v1/agents/agent_xxxx", r.URL.Path)
+
+		var req AgentUpdateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]string{"rack": "a1"}, req.Labels)
+
+		resp := AgentInfo{AgentID: "agent_xxxx", Labels: req.Labels}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithUserToken("test-user-token"),
+	)
+
+	resp, err := client.UpdateAgent(context.Background(), "agent_xxxx", &AgentUpdateRequest{
+		Labels: map[string]string{"rack": "a1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a1", resp.Labels["rack"])
+}
+
+func TestClient_IssueWorkerCertificate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/agents/agent_xxxx/workers/worker_xxxx/certificate", r.URL.Path)
+		assert.Equal(t, "Bearer gpugo_test-agent-secret", r.Header.Get("Authorization"))
+
+		var req WorkerCertificateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.CSR, "CERTIFICATE REQUEST")
+
+		resp := WorkerCertificateResponse{Certificate: "-----BEGIN CERTIFICATE-----\n..."}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithAgentSecret("gpugo_test-agent-secret"),
+	)
+
+	resp, err := client.IssueWorkerCertificate(context.Background(), "agent_xxxx", "worker_xxxx", &WorkerCertificateRequest{
+		CSR: "-----BEGIN CERTIFICATE REQUEST-----\n...",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Certificate, "BEGIN CERTIFICATE")
+}
+
+func TestClient_WorkerFailover(t *testing.T) {
+	seen := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gpugo_test-agent-secret", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/api/v1/agents/agent_standby/peers/agent_primary":
+			assert.Equal(t, "GET", r.Method)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(AgentPeerStatus{AgentID: "agent_primary", LastSeenAt: &seen})
+		case "/api/v1/agents/agent_standby/workers/worker_xxxx/failover":
+			assert.Equal(t, "POST", r.Method)
+			var req WorkerFailoverRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "agent_primary", req.PrimaryAgentID)
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithAgentSecret("gpugo_test-agent-secret"),
+	)
+
+	peer, err := client.GetAgentPeerStatus(context.Background(), "agent_standby", "agent_primary")
+	require.NoError(t, err)
+	assert.False(t, peer.Online)
+	require.NotNil(t, peer.LastSeenAt)
+	assert.True(t, seen.Equal(*peer.LastSeenAt))
+
+	require.NoError(t, client.ReportWorkerFailover(context.Background(), "agent_standby", "worker_xxxx", &WorkerFailoverRequest{
+		PrimaryAgentID:  "agent_primary",
+		PrimaryLastSeen: peer.LastSeenAt,
+	}))
+}
+
+func TestClient_UpdateShare(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/api/v1/shares/share_xxxx", r.URL.Path)
+
+		var req ShareUpdateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.NotNil(t, req.Notifications)
+		assert.Equal(t, "https://hooks.example.com/gpu", req.Notifications.WebhookURL)
+		assert.Equal(t, []string{ShareEventFirstUse}, req.Notifications.Events)
+
+		resp := ShareInfo{ShareID: "share_xxxx", Notifications: req.Notifications}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithUserToken("test-user-token"),
+	)
+
+	resp, err := client.UpdateShare(context.Background(), "share_xxxx", &ShareUpdateRequest{
+		Notifications: &ShareNotifications{
+			WebhookURL: "https://hooks.example.com/gpu",
+			Events:     []string{ShareEventFirstUse},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Notifications)
+	assert.Equal(t, "https://hooks.example.com/gpu", resp.Notifications.WebhookURL)
+}
+
+func TestClient_RegisterShareConsumer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/s/abc123/consumers", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var req ShareConsumerRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "laptop", req.Hostname)
+		assert.Equal(t, "use", req.Via)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	err := client.RegisterShareConsumer(context.Background(), "abc123", &ShareConsumerRequest{
+		Hostname:      "laptop",
+		OS:            "linux",
+		ClientVersion: "1.2.3",
+		Via:           "use",
+	})
+	require.NoError(t, err)
+}
+
+func TestClient_UploadAuditEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/audit/entries", r.URL.Path)
+		assert.Equal(t, "Bearer test-user-token", r.Header.Get("Authorization"))
+
+		var req AuditUploadRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Entries, 1)
+		assert.Equal(t, "ggo worker delete", req.Entries[0].Command)
+		assert.Equal(t, []string{"w-1"}, req.Entries[0].Args)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithUserToken("test-user-token"),
+	)
+
+	err := client.UploadAuditEntries(context.Background(), &AuditUploadRequest{
+		Entries: []AuditEntry{{
+			Timestamp: time.Now(),
+			User:      "alice",
+			Command:   "ggo worker delete",
+			Args:      []string{"w-1"},
+			Result:    "success",
+		}},
+	})
+	require.NoError(t, err)
+}
+
+func TestClient_StreamWorkerLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/workers/worker_xxxx/logs", r.URL.Path)
+		assert.Equal(t, "50", r.URL.Query().Get("tail"))
+		assert.Equal(t, "true", r.URL.Query().Get("follow"))
+		assert.Equal(t, "true", r.URL.Query().Get("output"))
+		assert.Equal(t, "Bearer test-user-token", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("line 1\nline 2\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithUserToken("test-user-token"),
+	)
+
+	var buf strings.Builder
+	err := client.StreamWorkerLogs(context.Background(), "worker_xxxx", 50, true, true, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "line 1\nline 2\n", buf.String())
+}
+
+func TestClient_SendWorkerLogChunk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/v1/agents/agent_xxxx/worker-logs", r.URL.Path)
+		assert.Equal(t, "Bearer test-agent-secret", r.Header.Get("Authorization"))
+
+		var chunk WorkerLogChunk
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&chunk))
+		assert.Equal(t, "req-1", chunk.RequestID)
+		assert.Equal(t, []string{"hello"}, chunk.Lines)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(WorkerLogChunkResponse{Closed: true})
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithAgentSecret("test-agent-secret"),
+	)
+
+	resp, err := client.SendWorkerLogChunk(context.Background(), "agent_xxxx", &WorkerLogChunk{
+		RequestID: "req-1",
+		WorkerID:  "worker_xxxx",
+		Lines:     []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Closed)
+}
+
+func TestClient_ListShareConsumers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/shares/share_xxxx/consumers", r.URL.Path)
+
+		resp := ShareConsumerListResponse{
+			Consumers: []ShareConsumer{
+				{Hostname: "laptop", OS: "darwin", ClientVersion: "1.2.3", Via: "studio", Uses: 2},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithUserToken("test-user-token"),
+	)
+
+	resp, err := client.ListShareConsumers(context.Background(), "share_xxxx")
+	require.NoError(t, err)
+	require.Len(t, resp.Consumers, 1)
+	assert.Equal(t, "laptop", resp.Consumers[0].Hostname)
+	assert.Equal(t, 2, resp.Consumers[0].Uses)
+}
+
+func TestClient_GetShareConsumption(t *testing.T) {
+	since := time.Date(2026, 10, 9, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/api/v1/shares/share_xxxx/consumption", r.URL.Path)
+		assert.Equal(t, "2026-10-09T12:00:00Z", r.URL.Query().Get("since"))
+		assert.Equal(t, "Bearer test-user-token", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ShareConsumption{
+			ShareID:       "share_xxxx",
+			Since:         since,
+			Until:         since.Add(7 * 24 * time.Hour),
+			BucketSeconds: 86400,
+			Totals:        ShareConsumptionCounts{Sessions: 8, UniqueConsumers: 3, GPUHours: 12.5, FailedSessions: 2},
+			Buckets: []ShareConsumptionBucket{
+				{Start: since, ShareConsumptionCounts: ShareConsumptionCounts{Sessions: 8, UniqueConsumers: 3, GPUHours: 12.5, FailedSessions: 2}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithUserToken("test-user-token"),
+	)
+
+	usage, err := client.GetShareConsumption(context.Background(), "share_xxxx", since.In(time.FixedZone("CEST", 2*3600)))
+	require.NoError(t, err)
+	assert.Equal(t, 8, usage.Totals.Sessions)
+	assert.InDelta(t, 0.25, usage.Totals.ErrorRate(), 1e-9)
+	require.Len(t, usage.Buckets, 1)
+	assert.Equal(t, 12.5, usage.Buckets[0].GPUHours, "bucket counts are embedded")
+	assert.Zero(t, (&ShareConsumptionCounts{}).ErrorRate())
+}
+
+func TestClient_TeamWorkers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "Bearer test-user-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/teams/ml-infra/workers":
+			json.NewEncoder(w).Encode(TeamWorkerListResponse{
+				Workers: []TeamWorker{{WorkerID: "worker_1", Name: "my-worker", Owner: "alice", HardwareVendor: "nvidia", Status: "running"}},
+			})
+		case "/api/v1/teams/ml-infra/workers/my-worker/share":
+			json.NewEncoder(w).Encode(TeamShareInfo{
+				SharePublicInfo: SharePublicInfo{WorkerID: "worker_1", HardwareVendor: "nvidia", ConnectionURL: "native+10.0.0.1+9001"},
+				ShortCode:       "team42",
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithUserToken("test-user-token"),
+	)
+
+	list, err := client.ListTeamWorkers(context.Background(), "ml-infra")
+	require.NoError(t, err)
+	require.Len(t, list.Workers, 1)
+	assert.Equal(t, "my-worker", list.Workers[0].Name)
+
+	share, err := client.GetTeamWorkerShare(context.Background(), "ml-infra", "my-worker")
+	require.NoError(t, err)
+	assert.Equal(t, "team42", share.ShortCode)
+	assert.Equal(t, "worker_1", share.WorkerID)
+	assert.Equal(t, "native+10.0.0.1+9001", share.ConnectionURL)
+}
+
 func TestClient_ReportAgentMetrics(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "POST", r.Method)
@@ -532,4 +977,107 @@ func TestClient_ErrorHandling(t *testing.T) {
 	_, err := client.ListAgents(context.Background())
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "401")
+	assert.False(t, IsNotFound(err))
+}
+
+func TestClient_IsNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithUserToken("test-user-token"))
+
+	_, err := client.GetWorker(context.Background(), "worker_gone")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestIsUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	client := NewClient(WithBaseURL(server.URL), WithAgentSecret("gpugo_secret"))
+
+	_, err := client.ReportAgentStatus(context.Background(), "agent_1", &AgentStatusRequest{})
+	assert.True(t, IsUnreachable(err), "5xx")
+	server.Close()
+	_, err = client.ReportAgentStatus(context.Background(), "agent_1", &AgentStatusRequest{})
+	assert.True(t, IsUnreachable(err), "connection refused")
+
+	assert.False(t, IsUnreachable(&StatusError{StatusCode: http.StatusUnauthorized}))
+	assert.True(t, IsUnreachable(&StatusError{StatusCode: http.StatusTooManyRequests}))
+	assert.False(t, IsUnreachable(nil))
+}
+
+func TestClient_PersonalAccessTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-user-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/tokens/pat":
+			json.NewEncoder(w).Encode(PATListResponse{Tokens: []PersonalAccessToken{
+				{ID: "pat_1", Name: "ci", Scopes: []string{ScopeReadOnly}},
+			}})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/tokens/pat":
+			var req PATCreateRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, []string{ScopeShareOnly}, req.Scopes)
+			require.NotNil(t, req.ExpiresInDays)
+			assert.Equal(t, 30, *req.ExpiresInDays)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(PATCreateResponse{
+				PersonalAccessToken: PersonalAccessToken{ID: "pat_2", Name: req.Name, Scopes: req.Scopes},
+				Token:               "gpugo_pat_xxxx",
+			})
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/tokens/pat/pat_1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithUserToken("test-user-token"))
+	ctx := context.Background()
+
+	list, err := client.ListPersonalAccessTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, list.Tokens, 1)
+	assert.Equal(t, []string{ScopeReadOnly}, list.Tokens[0].Scopes)
+
+	days := 30
+	created, err := client.CreatePersonalAccessToken(ctx, &PATCreateRequest{Name: "bot", Scopes: []string{ScopeShareOnly}, ExpiresInDays: &days})
+	require.NoError(t, err)
+	assert.Equal(t, "pat_2", created.ID)
+	assert.Equal(t, "gpugo_pat_xxxx", created.Token)
+
+	require.NoError(t, client.RevokePersonalAccessToken(ctx, "pat_1"))
+}
+
+func TestClient_ScopeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		if r.URL.Path == "/api/v1/workers" {
+			w.Write([]byte(`{"error":"insufficient_scope","required_scope":"worker-admin","token_scopes":["read-only"]}`))
+			return
+		}
+		w.Write([]byte(`{"error":"forbidden"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithUserToken("test-user-token"),
+		WithScopeHint(func(scope string) string { return "create a token with " + scope }))
+
+	_, err := client.CreateWorker(context.Background(), &WorkerCreateRequest{Name: "w"})
+	scope, ok := MissingScope(err)
+	require.True(t, ok)
+	assert.Equal(t, ScopeWorkerAdmin, scope)
+	assert.EqualError(t, err, `token is missing the "worker-admin" scope required for POST /api/v1/workers (token scopes: read-only); create a token with worker-admin`)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+
+	_, err = client.ListAgents(context.Background())
+	_, ok = MissingScope(err)
+	assert.False(t, ok, "other 403s are plain status errors")
 }
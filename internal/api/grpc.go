@@ -0,0 +1,283 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api/agentpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// Transports of the agent's calls to the platform: registration, config
+// pull, status and metrics reports and the command channel
+const (
+	// AgentTransportREST uses REST+JSON only
+	AgentTransportREST = "rest"
+	// AgentTransportAuto uses gRPC when the server offers it, see
+	// ServerCapabilities, and REST otherwise or while gRPC fails
+	AgentTransportAuto = "auto"
+)
+
+const (
+	// capabilityRecheckInterval is how long a server without gRPC is not asked
+	// again
+	capabilityRecheckInterval = 30 * time.Minute
+	// grpcRetryAfter is how long calls go over REST after the gRPC transport
+	// failed
+	grpcRetryAfter = 5 * time.Minute
+)
+
+// ErrGRPCUnavailable is returned by the gRPC-only calls, such as
+// OpenAgentTopic, when the gRPC transport is not in use
+var ErrGRPCUnavailable = errors.New("gRPC transport not available")
+
+// ServerCapabilities lists the optional features of the server, from
+// GET /api/v1/capabilities
+type ServerCapabilities struct {
+	// GRPCEndpoint serves the gRPC agent transport, as grpcs://host:port
+	// (TLS) or grpc://host:port; empty when the server does not offer it
+	GRPCEndpoint string `json:"grpc_endpoint,omitempty"`
+}
+
+// grpcTransport is the negotiated gRPC connection of a client
+type grpcTransport struct {
+	mu        sync.Mutex
+	conn      *grpc.ClientConn
+	service   agentpb.AgentServiceClient
+	checkedAt time.Time // last time the server's capabilities were fetched
+	downUntil time.Time // calls go over REST until then after a failure
+}
+
+// WithAgentTransport sets the transport of the agent's calls, an
+// AgentTransport* constant; REST by default
+func WithAgentTransport(transport string) ClientOption {
+	return func(c *Client) {
+		c.agentTransport = transport
+	}
+}
+
+// ParseAgentTransport validates an agent transport name
+func ParseAgentTransport(s string) (string, error) {
+	switch s {
+	case "", AgentTransportAuto:
+		return AgentTransportAuto, nil
+	case AgentTransportREST:
+		return AgentTransportREST, nil
+	}
+	return "", fmt.Errorf("unknown agent transport %q (use %s or %s)", s, AgentTransportAuto, AgentTransportREST)
+}
+
+// GetServerCapabilities fetches the optional features of the server
+func (c *Client) GetServerCapabilities(ctx context.Context) (*ServerCapabilities, error) {
+	return doGet[ServerCapabilities](c, ctx, "/api/v1/capabilities", authNone, "")
+}
+
+// AgentTransport reports the transport the agent's calls currently use,
+// "grpc" or AgentTransportREST
+func (c *Client) AgentTransport() string {
+	c.grpc.mu.Lock()
+	defer c.grpc.mu.Unlock()
+	if c.grpc.service != nil && time.Now().After(c.grpc.downUntil) {
+		return "grpc"
+	}
+	return AgentTransportREST
+}
+
+// Close releases the client's gRPC connection, if any
+func (c *Client) Close() error {
+	c.grpc.mu.Lock()
+	defer c.grpc.mu.Unlock()
+	if c.grpc.conn == nil {
+		return nil
+	}
+	err := c.grpc.conn.Close()
+	c.grpc.conn, c.grpc.service = nil, nil
+	return err
+}
+
+// agentService returns the gRPC agent service, or nil when calls go over
+// REST. The server's capabilities are fetched on first use.
+func (c *Client) agentService(ctx context.Context) agentpb.AgentServiceClient {
+	if c.agentTransport != AgentTransportAuto {
+		return nil
+	}
+	t := &c.grpc
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if now.Before(t.downUntil) {
+		return nil
+	}
+	if t.service != nil {
+		return t.service
+	}
+	if !t.checkedAt.IsZero() && now.Sub(t.checkedAt) < capabilityRecheckInterval {
+		return nil
+	}
+	t.checkedAt = now
+
+	caps, err := c.GetServerCapabilities(ctx)
+	if err != nil {
+		// Servers without the endpoint predate gRPC
+		if !IsNotFound(err) {
+			klog.Warningf("Failed to get server capabilities, using REST: error=%v", err)
+		}
+		return nil
+	}
+	if caps.GRPCEndpoint == "" {
+		return nil
+	}
+	conn, err := dialGRPC(caps.GRPCEndpoint)
+	if err != nil {
+		klog.Warningf("Failed to set up gRPC transport, using REST: endpoint=%s error=%v", caps.GRPCEndpoint, err)
+		return nil
+	}
+	klog.Infof("Using gRPC transport for agent calls: endpoint=%s", caps.GRPCEndpoint)
+	t.conn, t.service = conn, agentpb.NewAgentServiceClient(conn)
+	return t.service
+}
+
+// dialGRPC creates a connection to a grpc:// or grpcs:// endpoint; it
+// connects on first use
+func dialGRPC(endpoint string) (*grpc.ClientConn, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gRPC endpoint: %w", err)
+	}
+	var creds credentials.TransportCredentials
+	switch u.Scheme {
+	case "grpcs":
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	case "grpc":
+		creds = insecure.NewCredentials()
+	default:
+		return nil, fmt.Errorf("unsupported gRPC endpoint scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("gRPC endpoint %q has no host", endpoint)
+	}
+	return grpc.NewClient(u.Host, grpc.WithTransportCredentials(creds))
+}
+
+// grpcFailed reports whether err means the gRPC transport itself failed, in
+// which case calls go over REST for a while
+func (c *Client) grpcFailed(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Unimplemented:
+	default:
+		return false
+	}
+	c.grpc.mu.Lock()
+	defer c.grpc.mu.Unlock()
+	if time.Now().After(c.grpc.downUntil) {
+		klog.Warningf("gRPC transport failed, using REST for %s: error=%v", grpcRetryAfter, err)
+	}
+	c.grpc.downUntil = time.Now().Add(grpcRetryAfter)
+	return true
+}
+
+// grpcError converts a gRPC status to the error the REST call would have
+// returned, so callers handle both transports alike
+func (c *Client) grpcError(method string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	code := http.StatusInternalServerError
+	switch st.Code() {
+	case codes.InvalidArgument, codes.OutOfRange:
+		code = http.StatusBadRequest
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		code = http.StatusConflict
+	case codes.ResourceExhausted:
+		code = http.StatusTooManyRequests
+	case codes.DeadlineExceeded, codes.Canceled:
+		return fmt.Errorf("request failed: %w", err)
+	}
+	return c.statusError("gRPC", method, code, st.Message())
+}
+
+// callGRPC runs call over the gRPC transport, authenticated with auth. It
+// reports false when the call has to go over REST instead: gRPC is not in
+// use, or the transport failed.
+func callGRPC[T any](c *Client, ctx context.Context, method, auth string, call func(context.Context, agentpb.AgentServiceClient) (T, error)) (T, bool, error) {
+	var zero T
+	service := c.agentService(ctx)
+	if service == nil {
+		return zero, false, nil
+	}
+	ctx, cancel := context.WithTimeout(metadata.AppendToOutgoingContext(ctx, "authorization", auth), defaultTimeout)
+	defer cancel()
+	resp, err := call(ctx, service)
+	if err != nil {
+		if c.grpcFailed(err) {
+			return zero, false, nil
+		}
+		return zero, true, c.grpcError(method, err)
+	}
+	return resp, true, nil
+}
+
+// AgentTopicStream receives the messages published on an agent topic over
+// the gRPC transport
+type AgentTopicStream struct {
+	stream grpc.ServerStreamingClient[agentpb.TopicMessage]
+	cancel context.CancelFunc
+}
+
+// OpenAgentTopic subscribes to one of the agent's topics over the gRPC
+// transport, the counterpart of the SSE stream. It returns
+// ErrGRPCUnavailable when the gRPC transport is not in use.
+func (c *Client) OpenAgentTopic(ctx context.Context, agentID, topic string) (*AgentTopicStream, error) {
+	service := c.agentService(ctx)
+	if service == nil {
+		return nil, ErrGRPCUnavailable
+	}
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(ctx, "authorization", c.agentAuthHeader()))
+	stream, err := service.Subscribe(ctx, &agentpb.SubscribeRequest{AgentId: agentID, Topic: topic})
+	if err == nil {
+		// The stream is established once the server sent its headers
+		_, err = stream.Header()
+	}
+	if err != nil {
+		cancel()
+		if c.grpcFailed(err) {
+			return nil, ErrGRPCUnavailable
+		}
+		return nil, c.grpcError(agentpb.AgentService_Subscribe_FullMethodName, err)
+	}
+	return &AgentTopicStream{stream: stream, cancel: cancel}, nil
+}
+
+// Recv returns the data of the next message; its lines are separated by
+// newlines
+func (s *AgentTopicStream) Recv() (string, error) {
+	msg, err := s.stream.Recv()
+	if err != nil {
+		return "", err
+	}
+	return msg.Data, nil
+}
+
+// Close ends the subscription
+func (s *AgentTopicStream) Close() {
+	s.cancel()
+}
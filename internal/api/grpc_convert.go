@@ -0,0 +1,407 @@
+package api
+
+import (
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api/agentpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Conversions between the REST types and the messages of the gRPC agent
+// transport. Requests are converted to messages, responses back.
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toTimestampPtr(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return toTimestamp(*t)
+}
+
+func toInt32s(in []int) []int32 {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]int32, len(in))
+	for i, v := range in {
+		out[i] = int32(v)
+	}
+	return out
+}
+
+func fromInt32s(in []int32) []int {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]int, len(in))
+	for i, v := range in {
+		out[i] = int(v)
+	}
+	return out
+}
+
+func toPBPartitions(in []GPUPartition) []*agentpb.GPUPartition {
+	out := make([]*agentpb.GPUPartition, 0, len(in))
+	for _, p := range in {
+		out = append(out, &agentpb.GPUPartition{Uuid: p.UUID, Profile: p.Profile, WorkerId: p.WorkerID})
+	}
+	return out
+}
+
+func toPBGPUMetrics(m *GPUMetrics) *agentpb.GPUMetrics {
+	if m == nil {
+		return nil
+	}
+	return &agentpb.GPUMetrics{
+		GpuId:       m.GPUID,
+		Utilization: m.Utilization,
+		VramUsedMb:  m.VRAMUsedMb,
+		VramTotalMb: m.VRAMTotalMb,
+		Temperature: m.Temperature,
+		PowerUsageW: m.PowerUsageW,
+		PcieRxKb:    m.PCIeRxKB,
+		PcieTxKb:    m.PCIeTxKB,
+	}
+}
+
+func toPBRegisterRequest(req *AgentRegisterRequest) *agentpb.RegisterRequest {
+	gpus := make([]*agentpb.GPUInfo, 0, len(req.GPUs))
+	for _, g := range req.GPUs {
+		gpus = append(gpus, &agentpb.GPUInfo{
+			GpuId:         g.GPUID,
+			GpuIndex:      int32(g.GPUIndex),
+			Vendor:        g.Vendor,
+			Model:         g.Model,
+			VramMb:        g.VRAMMb,
+			DriverVersion: g.DriverVersion,
+			CudaVersion:   g.CUDAVersion,
+			MigEnabled:    g.MIGEnabled,
+			Partitions:    toPBPartitions(g.Partitions),
+			Metrics:       toPBGPUMetrics(g.Metrics),
+			Health:        g.Health,
+		})
+	}
+	return &agentpb.RegisterRequest{
+		Hostname:   req.Hostname,
+		Os:         req.OS,
+		Arch:       req.Arch,
+		Gpus:       gpus,
+		NetworkIps: req.NetworkIPs,
+	}
+}
+
+func fromPBLicense(l *agentpb.License) License {
+	if l == nil {
+		return License{}
+	}
+	return License{Plain: l.Plain, Encrypted: l.Encrypted}
+}
+
+func fromPBRegisterResponse(resp *agentpb.RegisterResponse) *AgentRegisterResponse {
+	return &AgentRegisterResponse{
+		AgentID:     resp.AgentId,
+		AgentSecret: resp.AgentSecret,
+		License:     fromPBLicense(resp.License),
+	}
+}
+
+func fromPBWorkerConfig(w *agentpb.WorkerConfig) WorkerConfig {
+	cfg := WorkerConfig{
+		WorkerID:       w.WorkerId,
+		GPUIDs:         w.GpuIds,
+		GPUIndices:     fromInt32s(w.GpuIndices),
+		VRAMMb:         w.VramMb,
+		ComputePercent: int(w.ComputePercent),
+		IsolationMode:  w.IsolationMode,
+		ListenPort:     int(w.ListenPort),
+		Enabled:        w.Enabled,
+		ShareCodes:     w.ShareCodes,
+		MIGProfile:     w.MigProfile,
+		Env:            w.Env,
+		Relay:          w.Relay,
+		ForceStop:      w.ForceStop,
+		Warm:           w.Warm,
+	}
+	if s := w.Standby; s != nil {
+		cfg.Standby = &WorkerStandby{
+			PrimaryAgentID:       s.PrimaryAgentId,
+			FailoverAfterSeconds: int(s.FailoverAfterSeconds),
+			Active:               s.Active,
+		}
+	}
+	if f := w.Fairness; f != nil {
+		cfg.Fairness = &WorkerFairness{
+			PerClientComputePercent: int(f.PerClientComputePercent),
+			Scheduling:              f.Scheduling,
+		}
+	}
+	if t := w.Tuning; t != nil {
+		cfg.Tuning = &WorkerGPUTuning{
+			PowerLimitWatts: int(t.PowerLimitWatts),
+			MinClockMHz:     int(t.MinClockMhz),
+			MaxClockMHz:     int(t.MaxClockMhz),
+			PersistenceMode: t.PersistenceMode,
+			ComputeMode:     t.ComputeMode,
+		}
+	}
+	return cfg
+}
+
+func fromPBConfigResponse(resp *agentpb.ConfigResponse) *AgentConfigResponse {
+	cfg := &AgentConfigResponse{
+		ConfigVersion: int(resp.ConfigVersion),
+		Workers:       make([]WorkerConfig, 0, len(resp.Workers)),
+		License:       fromPBLicense(resp.License),
+	}
+	for _, w := range resp.Workers {
+		cfg.Workers = append(cfg.Workers, fromPBWorkerConfig(w))
+	}
+	if r := resp.Relay; r != nil {
+		cfg.Relay = &RelayConfig{Addr: r.Addr, Token: r.Token}
+	}
+	if r := resp.Reporting; r != nil {
+		cfg.Reporting = &ReportingConfig{
+			IntervalSeconds:     int(r.IntervalSeconds),
+			ForceRefreshSeconds: int(r.ForceRefreshSeconds),
+			ChangesOnly:         r.ChangesOnly,
+			KeepaliveSeconds:    int(r.KeepaliveSeconds),
+		}
+	}
+	if l := resp.WorkerLogs; l != nil {
+		cfg.WorkerLogs = &WorkerLogConfig{MaxSizeMB: int(l.MaxSizeMb), MaxFiles: int(l.MaxFiles)}
+	}
+	if d := resp.Disk; d != nil {
+		cfg.Disk = &DiskConfig{
+			MinFreePercent: int(d.MinFreePercent),
+			MinFreeMB:      int(d.MinFreeMb),
+			MaxLogsMB:      int(d.MaxLogsMb),
+			MaxCacheMB:     int(d.MaxCacheMb),
+		}
+	}
+	return cfg
+}
+
+func toPBConnections(in []ConnectionInfo) []*agentpb.ConnectionInfo {
+	out := make([]*agentpb.ConnectionInfo, 0, len(in))
+	for _, c := range in {
+		out = append(out, &agentpb.ConnectionInfo{
+			ClientIp:        c.ClientIP,
+			ClientPort:      int32(c.ClientPort),
+			ClientPid:       int32(c.ClientPID),
+			ConnectedAt:     toTimestamp(c.ConnectedAt),
+			ShareCode:       c.ShareCode,
+			BytesIn:         c.BytesIn,
+			BytesOut:        c.BytesOut,
+			WorkerSessionId: c.WorkerSessionID,
+			ClientHostname:  c.ClientHostname,
+			ProtocolVersion: c.ProtocolVersion,
+		})
+	}
+	return out
+}
+
+func toPBUsage(in []ShareUsage) []*agentpb.ShareUsage {
+	out := make([]*agentpb.ShareUsage, 0, len(in))
+	for _, u := range in {
+		out = append(out, &agentpb.ShareUsage{
+			ShareCode:       u.ShareCode,
+			ClientIp:        u.ClientIP,
+			BytesIn:         u.BytesIn,
+			BytesOut:        u.BytesOut,
+			Sessions:        int32(u.Sessions),
+			DurationSeconds: u.DurationSeconds,
+			QuotaDenied:     int32(u.QuotaDenied),
+		})
+	}
+	return out
+}
+
+func toPBCrashes(in []WorkerCrashReport) []*agentpb.WorkerCrashReport {
+	out := make([]*agentpb.WorkerCrashReport, 0, len(in))
+	for _, c := range in {
+		crash := &agentpb.WorkerCrashReport{
+			WorkerId:     c.WorkerID,
+			Pid:          int32(c.PID),
+			Restarts:     int32(c.Restarts),
+			Exits:        int32(c.Exits),
+			DetectedAt:   toTimestamp(c.DetectedAt),
+			Signal:       c.Signal,
+			Reason:       c.Reason,
+			LogFile:      c.LogFile,
+			LogTail:      c.LogTail,
+			KernelEvents: c.KernelEvents,
+			Xids:         toInt32s(c.XIDs),
+		}
+		if c.ExitCode != nil {
+			code := int32(*c.ExitCode)
+			crash.ExitCode = &code
+		}
+		out = append(out, crash)
+	}
+	return out
+}
+
+func toPBHealth(h *WorkerHealth) *agentpb.WorkerHealth {
+	if h == nil {
+		return nil
+	}
+	probes := make([]*agentpb.WorkerProbeResult, 0, len(h.Probes))
+	for _, p := range h.Probes {
+		probes = append(probes, &agentpb.WorkerProbeResult{Probe: p.Probe, Ok: p.OK, Error: p.Error, LatencyMs: p.LatencyMs})
+	}
+	return &agentpb.WorkerHealth{
+		Status:              h.Status,
+		ConsecutiveFailures: int32(h.ConsecutiveFailures),
+		Probes:              probes,
+		Xids:                toInt32s(h.XIDs),
+		Restarts:            int32(h.Restarts),
+		RestartsExhausted:   h.RestartsExhausted,
+		CheckedAt:           toTimestamp(h.CheckedAt),
+	}
+}
+
+func toPBWorkerStatus(w *WorkerStatus) *agentpb.WorkerStatus {
+	status := &agentpb.WorkerStatus{
+		WorkerId:          w.WorkerID,
+		Status:            w.Status,
+		Pid:               int32(w.PID),
+		Restarts:          int32(w.Restarts),
+		GpuIds:            w.GPUIDs,
+		GpuIndices:        toInt32s(w.GPUIndices),
+		Connections:       toPBConnections(w.Connections),
+		Usage:             toPBUsage(w.Usage),
+		Crashes:           toPBCrashes(w.Crashes),
+		TlsFingerprint:    w.TLSFingerprint,
+		RelayConnected:    w.RelayConnected,
+		DrainDeadline:     toTimestampPtr(w.DrainDeadline),
+		Health:            toPBHealth(w.Health),
+		Warm:              w.Warm,
+		WorkerChanged:     w.WorkerChanged,
+		ConnectionChanged: w.ConnectionChanged,
+		GpuChanged:        w.GPUChanged,
+	}
+	if l := w.CrashLoop; l != nil {
+		status.CrashLoop = &agentpb.WorkerCrashLoop{
+			Crashes:    int32(l.Crashes),
+			Since:      toTimestamp(l.Since),
+			LastErrors: l.LastErrors,
+		}
+	}
+	return status
+}
+
+func toPBStatusRequest(agentID string, req *AgentStatusRequest) *agentpb.StatusRequest {
+	gpus := make([]*agentpb.GPUStatus, 0, len(req.GPUs))
+	for _, g := range req.GPUs {
+		gpus = append(gpus, &agentpb.GPUStatus{
+			GpuId:         g.GPUID,
+			GpuIndex:      int32(g.GPUIndex),
+			UsedByWorker:  g.UsedByWorker,
+			Vendor:        g.Vendor,
+			Model:         g.Model,
+			VramMb:        g.VRAMMb,
+			DriverVersion: g.DriverVersion,
+			CudaVersion:   g.CUDAVersion,
+			GpuChanged:    g.GPUChanged,
+			MigCapable:    g.MIGCapable,
+			MigEnabled:    g.MIGEnabled,
+			Partitions:    toPBPartitions(g.Partitions),
+			Health:        g.Health,
+		})
+	}
+	workers := make([]*agentpb.WorkerStatus, 0, len(req.Workers))
+	for i := range req.Workers {
+		workers = append(workers, toPBWorkerStatus(&req.Workers[i]))
+	}
+
+	pb := &agentpb.StatusRequest{
+		AgentId:           agentID,
+		Timestamp:         toTimestamp(req.Timestamp),
+		Gpus:              gpus,
+		Workers:           workers,
+		Event:             string(req.Event),
+		LicenseExpiration: req.LicenseExpiration,
+		LicenseStatus:     req.LicenseStatus,
+		Metrics:           req.Metrics,
+		ReportId:          req.ReportID,
+		Backfill:          req.Backfill,
+	}
+	if n := req.NetTest; n != nil {
+		pb.NetTest = &agentpb.NetTestSummary{
+			RanAt:             toTimestamp(n.RanAt),
+			ApiLatencyMs:      n.APILatencyMs,
+			ApiThroughputMbps: n.APIThroughputMbps,
+			WsConnectMs:       n.WSConnectMs,
+			WsRoundTripMs:     n.WSRoundTripMs,
+			CdnThroughputMbps: n.CDNThroughputMbps,
+			UnreachablePorts:  toInt32s(n.UnreachablePorts),
+			Failed:            n.Failed,
+		}
+	}
+	if d := req.Disk; d != nil {
+		pb.Disk = &agentpb.AgentDiskUsage{
+			CacheBytes: d.CacheBytes,
+			LogsBytes:  d.LogsBytes,
+			FreeBytes:  d.FreeBytes,
+			TotalBytes: d.TotalBytes,
+			Pressure:   d.Pressure,
+			CheckedAt:  toTimestamp(d.CheckedAt),
+		}
+	}
+	return pb
+}
+
+func fromPBStatusResponse(resp *agentpb.StatusResponse) *AgentStatusResponse {
+	out := &AgentStatusResponse{
+		Success:        resp.Success,
+		ConfigVersion:  int(resp.ConfigVersion),
+		SecretRotation: resp.SecretRotation,
+	}
+	if resp.License != nil {
+		license := fromPBLicense(resp.License)
+		out.License = &license
+	}
+	if len(resp.WorkerShareCodes) > 0 {
+		out.WorkerShareCodes = make(map[string][]string, len(resp.WorkerShareCodes))
+		for workerID, codes := range resp.WorkerShareCodes {
+			out.WorkerShareCodes[workerID] = codes.GetCodes()
+		}
+	}
+	if len(resp.ShareQuotas) > 0 {
+		out.ShareQuotas = make(map[string]ShareQuotaState, len(resp.ShareQuotas))
+		for code, q := range resp.ShareQuotas {
+			out.ShareQuotas[code] = ShareQuotaState{
+				ShareQuota: ShareQuota{
+					GPUHoursPerWeek:       q.GpuHoursPerWeek,
+					MaxSessionMinutes:     int(q.MaxSessionMinutes),
+					MaxConcurrentSessions: int(q.MaxConcurrentSessions),
+				},
+				GPUHoursUsed: q.GpuHoursUsed,
+			}
+		}
+	}
+	return out
+}
+
+func toPBMetricsRequest(agentID string, req *AgentMetricsRequest) *agentpb.MetricsRequest {
+	gpus := make([]*agentpb.GPUMetrics, 0, len(req.GPUs))
+	for i := range req.GPUs {
+		gpus = append(gpus, toPBGPUMetrics(&req.GPUs[i]))
+	}
+	return &agentpb.MetricsRequest{
+		AgentId:   agentID,
+		Timestamp: toTimestamp(req.Timestamp),
+		System: &agentpb.SystemMetrics{
+			CpuUsage:      req.System.CPUUsage,
+			MemoryUsedMb:  req.System.MemoryUsedMb,
+			MemoryTotalMb: req.System.MemoryTotalMb,
+		},
+		Gpus: gpus,
+	}
+}
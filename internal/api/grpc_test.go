@@ -0,0 +1,194 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api/agentpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAgentService is a platform serving the gRPC agent transport
+type fakeAgentService struct {
+	agentpb.UnimplementedAgentServiceServer
+	t          *testing.T
+	statusReqs []*agentpb.StatusRequest
+}
+
+func (s *fakeAgentService) checkAuth(ctx context.Context, want string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	assert.Equal(s.t, []string{want}, md.Get("authorization"))
+}
+
+func (s *fakeAgentService) GetConfig(ctx context.Context, req *agentpb.GetConfigRequest) (*agentpb.ConfigResponse, error) {
+	s.checkAuth(ctx, "Bearer gpugo_secret")
+	if req.AgentId != "agent_1" {
+		return nil, status.Error(codes.NotFound, "agent not found")
+	}
+	return &agentpb.ConfigResponse{
+		ConfigVersion: 7,
+		Workers: []*agentpb.WorkerConfig{{
+			WorkerId: "worker_1", GpuIds: []string{"GPU-0"}, ListenPort: 9001, Enabled: true,
+			Fairness: &agentpb.WorkerFairness{Scheduling: SchedulingRoundRobin},
+			Tuning:   &agentpb.WorkerGPUTuning{PowerLimitWatts: 250, ComputeMode: ComputeModeExclusiveProcess},
+			Warm:     true,
+		}},
+		License: &agentpb.License{Plain: "plain", Encrypted: "sig"},
+	}, nil
+}
+
+func (s *fakeAgentService) ReportStatus(ctx context.Context, req *agentpb.StatusRequest) (*agentpb.StatusResponse, error) {
+	s.checkAuth(ctx, "Bearer gpugo_secret")
+	s.statusReqs = append(s.statusReqs, req)
+	return &agentpb.StatusResponse{
+		Success:          true,
+		ConfigVersion:    7,
+		WorkerShareCodes: map[string]*agentpb.ShareCodes{"worker_1": {Codes: []string{"abc123"}}},
+	}, nil
+}
+
+func (s *fakeAgentService) Subscribe(req *agentpb.SubscribeRequest, stream grpc.ServerStreamingServer[agentpb.TopicMessage]) error {
+	s.checkAuth(stream.Context(), "Bearer gpugo_secret")
+	for _, data := range []string{`{"config_version":8}`, "line1\nline2"} {
+		if err := stream.Send(&agentpb.TopicMessage{Data: req.Topic + ":" + data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startAgentPlatform serves svc over gRPC, and REST with handler; the REST
+// server advertises the gRPC endpoint unless svc is nil
+func startAgentPlatform(t *testing.T, svc agentpb.AgentServiceServer, handler http.HandlerFunc) string {
+	t.Helper()
+	caps := ServerCapabilities{}
+	if svc != nil {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := grpc.NewServer()
+		agentpb.RegisterAgentServiceServer(srv, svc)
+		go func() { _ = srv.Serve(lis) }()
+		t.Cleanup(srv.Stop)
+		caps.GRPCEndpoint = "grpc://" + lis.Addr().String()
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/capabilities" {
+			_ = json.NewEncoder(w).Encode(caps)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestClient_GRPCTransport(t *testing.T) {
+	svc := &fakeAgentService{t: t}
+	restCalls := 0
+	baseURL := startAgentPlatform(t, svc, func(w http.ResponseWriter, r *http.Request) {
+		restCalls++
+		// Metrics are not implemented by the fake, so they fall back to REST
+		assert.Equal(t, "/api/v1/agents/agent_1/metrics", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	client := NewClient(WithBaseURL(baseURL), WithAgentSecret("gpugo_secret"), WithAgentTransport(AgentTransportAuto))
+	defer func() { _ = client.Close() }()
+	ctx := context.Background()
+
+	cfg, err := client.GetAgentConfig(ctx, "agent_1")
+	require.NoError(t, err)
+	assert.Equal(t, "grpc", client.AgentTransport())
+	assert.Equal(t, 7, cfg.ConfigVersion)
+	require.Len(t, cfg.Workers, 1)
+	assert.Equal(t, 9001, cfg.Workers[0].ListenPort)
+	assert.Equal(t, SchedulingRoundRobin, cfg.Workers[0].Fairness.Scheduling)
+	assert.Equal(t, &WorkerGPUTuning{PowerLimitWatts: 250, ComputeMode: ComputeModeExclusiveProcess}, cfg.Workers[0].Tuning)
+	assert.True(t, cfg.Workers[0].Warm)
+	assert.Equal(t, "sig", cfg.License.Encrypted)
+
+	_, err = client.GetAgentConfig(ctx, "agent_gone")
+	assert.True(t, IsNotFound(err), "gRPC statuses map to the REST errors")
+
+	expiration := int64(1768379729916)
+	resp, err := client.ReportAgentStatus(ctx, "agent_1", &AgentStatusRequest{
+		Timestamp: time.Now(),
+		Workers: []WorkerStatus{{
+			WorkerID: "worker_1", Status: "Running", GPUIndices: []int{0},
+			Connections: []ConnectionInfo{{ClientIP: "10.0.0.9", ClientPort: 50000, BytesIn: 42}},
+		}},
+		LicenseExpiration: &expiration,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"worker_1": {"abc123"}}, resp.WorkerShareCodes)
+	require.Len(t, svc.statusReqs, 1)
+	sent := svc.statusReqs[0]
+	assert.Equal(t, "agent_1", sent.AgentId)
+	assert.Equal(t, expiration, sent.GetLicenseExpiration())
+	assert.Equal(t, int64(42), sent.Workers[0].Connections[0].BytesIn)
+
+	assert.Equal(t, 0, restCalls)
+	require.NoError(t, client.ReportAgentMetrics(ctx, "agent_1", &AgentMetricsRequest{Timestamp: time.Now()}))
+	assert.Equal(t, 1, restCalls)
+	assert.Equal(t, AgentTransportREST, client.AgentTransport(), "falls back to REST for a while")
+}
+
+func TestClient_GRPCTransportNotOffered(t *testing.T) {
+	baseURL := startAgentPlatform(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/agents/agent_1/config", r.URL.Path)
+		_ = json.NewEncoder(w).Encode(AgentConfigResponse{ConfigVersion: 3})
+	})
+
+	client := NewClient(WithBaseURL(baseURL), WithAgentSecret("gpugo_secret"), WithAgentTransport(AgentTransportAuto))
+	cfg, err := client.GetAgentConfig(context.Background(), "agent_1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.ConfigVersion)
+	assert.Equal(t, AgentTransportREST, client.AgentTransport())
+
+	_, err = client.OpenAgentTopic(context.Background(), "agent_1", "agent_1")
+	assert.ErrorIs(t, err, ErrGRPCUnavailable)
+}
+
+func TestClient_OpenAgentTopic(t *testing.T) {
+	baseURL := startAgentPlatform(t, &fakeAgentService{t: t}, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected REST request: %s", r.URL.Path)
+	})
+
+	client := NewClient(WithBaseURL(baseURL), WithAgentSecret("gpugo_secret"), WithAgentTransport(AgentTransportAuto))
+	defer func() { _ = client.Close() }()
+
+	stream, err := client.OpenAgentTopic(context.Background(), "agent_1", "agent_1_vgpu_restart")
+	require.NoError(t, err)
+	defer stream.Close()
+
+	data, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, `agent_1_vgpu_restart:{"config_version":8}`, data)
+	data, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "agent_1_vgpu_restart:line1\nline2", data)
+	_, err = stream.Recv()
+	assert.Error(t, err, "the server ended the stream")
+}
+
+func TestParseAgentTransport(t *testing.T) {
+	transport, err := ParseAgentTransport("")
+	require.NoError(t, err)
+	assert.Equal(t, AgentTransportAuto, transport)
+	transport, err = ParseAgentTransport("rest")
+	require.NoError(t, err)
+	assert.Equal(t, AgentTransportREST, transport)
+	_, err = ParseAgentTransport("quic")
+	assert.Error(t, err)
+}
@@ -1,6 +1,10 @@
 package api
 
-import "time"
+import (
+	"net"
+	"strconv"
+	"time"
+)
 
 // TokenResponse represents the response from POST /api/v1/tokens/generate
 type TokenResponse struct {
@@ -9,6 +13,51 @@ type TokenResponse struct {
 	InstallCommand string    `json:"install_command"`
 }
 
+// Personal access token scopes. A token without scopes has full access.
+const (
+	// ScopeReadOnly allows reading agents, workers and shares
+	ScopeReadOnly = "read-only"
+	// ScopeWorkerAdmin allows creating, updating and deleting workers
+	ScopeWorkerAdmin = "worker-admin"
+	// ScopeShareOnly allows managing shares and nothing else
+	ScopeShareOnly = "share-only"
+)
+
+// TokenScopes lists the scopes a personal access token can be limited to
+var TokenScopes = []string{ScopeReadOnly, ScopeWorkerAdmin, ScopeShareOnly}
+
+// PersonalAccessToken is a personal access token as listed by the server;
+// the token itself is only returned once, on creation
+type PersonalAccessToken struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Prefix is the start of the token, to tell tokens apart
+	Prefix     string     `json:"prefix,omitempty"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// PATCreateRequest is the request body of POST /api/v1/tokens/pat
+type PATCreateRequest struct {
+	Name   string   `json:"name,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	// ExpiresInDays is nil for a token that never expires
+	ExpiresInDays *int `json:"expires_in_days"`
+}
+
+// PATCreateResponse is the response from POST /api/v1/tokens/pat
+type PATCreateResponse struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// PATListResponse is the response from GET /api/v1/tokens/pat
+type PATListResponse struct {
+	Tokens []PersonalAccessToken `json:"tokens"`
+}
+
 // GPUInfo represents GPU information for agent registration
 type GPUInfo struct {
 	GPUID         string `json:"gpu_id"`
@@ -18,6 +67,32 @@ type GPUInfo struct {
 	VRAMMb        int64  `json:"vram_mb"`
 	DriverVersion string `json:"driver_version,omitempty"`
 	CUDAVersion   string `json:"cuda_version,omitempty"`
+	// MIGEnabled is set for NVIDIA GPUs in MIG mode, split into Partitions
+	MIGEnabled bool           `json:"mig_enabled,omitempty"`
+	Partitions []GPUPartition `json:"partitions,omitempty"`
+	// Metrics are the latest the agent reported, when it reports metrics
+	Metrics *GPUMetrics `json:"metrics,omitempty"`
+	// Health lists the GPUHealth* flags the agent last reported; empty when
+	// the GPU is healthy
+	Health []string `json:"health,omitempty"`
+}
+
+// GPU health flags reported by agents
+const (
+	// GPUHealthNotDetected marks a configured GPU the hypervisor does not list
+	GPUHealthNotDetected = "not-detected"
+	// GPUHealthOverheating marks a GPU at or above the agent's temperature limit
+	GPUHealthOverheating = "overheating"
+)
+
+// GPUPartition is a MIG instance of a GPU
+type GPUPartition struct {
+	// UUID identifies the instance in CUDA_VISIBLE_DEVICES (MIG-...)
+	UUID string `json:"uuid"`
+	// Profile is the MIG profile, e.g. 1g.10gb
+	Profile string `json:"profile"`
+	// WorkerID is the worker the agent created the instance for, if any
+	WorkerID string `json:"worker_id,omitempty"`
 }
 
 // AgentRegisterRequest represents the request body for agent registration
@@ -55,8 +130,16 @@ type AgentInfo struct {
 	Workers    []WorkerInfo `json:"workers,omitempty"`
 	GPUCount   int          `json:"gpu_count,omitempty"`
 	GPUSummary string       `json:"gpu_summary,omitempty"`
-	LastSeenAt time.Time    `json:"last_seen_at"`
-	CreatedAt  time.Time    `json:"created_at"`
+	// Labels are arbitrary key=value metadata set by the owner
+	Labels     map[string]string `json:"labels,omitempty"`
+	LastSeenAt time.Time         `json:"last_seen_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// AgentUpdateRequest represents the request body for agent updates
+type AgentUpdateRequest struct {
+	// Labels replaces the agent's labels
+	Labels map[string]string `json:"labels"`
 }
 
 // AgentListResponse represents the response from GET /api/v1/agents
@@ -75,6 +158,140 @@ type WorkerConfig struct {
 	ListenPort     int      `json:"listen_port"`
 	Enabled        bool     `json:"enabled"`
 	ShareCodes     []string `json:"share_codes,omitempty"`
+	// MIGProfile runs the worker on a MIG instance of this profile (e.g.
+	// 1g.10gb), created by the agent on the worker's only GPU
+	MIGProfile string `json:"mig_profile,omitempty"`
+	// Env holds extra environment variables for the worker process
+	// (e.g. NCCL settings, HTTP proxy); agent-managed variables take precedence
+	Env map[string]string `json:"env,omitempty"`
+	// Relay makes the agent serve the worker through the platform relay, for
+	// clients that cannot reach the GPU host directly
+	Relay bool `json:"relay,omitempty"`
+	// ForceStop makes the agent stop a disabled worker at once instead of
+	// draining its client connections first. A worker deleted with force stays
+	// in the config, disabled with ForceStop set, until the agent stopped it.
+	ForceStop bool `json:"force_stop,omitempty"`
+	// Standby is set on the standby agent of an HA pair; the agent keeps the
+	// worker stopped until it takes over from the primary
+	Standby *WorkerStandby `json:"standby,omitempty"`
+	// Fairness divides the worker's GPU time between its clients
+	Fairness *WorkerFairness `json:"fairness,omitempty"`
+	// Tuning caps the worker's GPUs while it runs
+	Tuning *WorkerGPUTuning `json:"tuning,omitempty"`
+	// Warm keeps the worker running while it is disabled: its process is up
+	// with the GPU context created and it listens, but refuses sessions.
+	// Enabling it, which the platform does when one of its shares is first
+	// resolved, serves clients without a cold start.
+	Warm bool `json:"warm,omitempty"`
+}
+
+// Client scheduling hints of a worker
+const (
+	// SchedulingFIFO runs clients' kernels in arrival order (the default)
+	SchedulingFIFO = "fifo"
+	// SchedulingRoundRobin interleaves clients' kernels in turn so that no
+	// client starves the others
+	SchedulingRoundRobin = "round-robin"
+)
+
+// WorkerFairness controls how a worker shared by several clients divides
+// its GPU time between them
+type WorkerFairness struct {
+	// PerClientComputePercent caps the SM share of each client (1-100);
+	// 0 leaves clients uncapped
+	PerClientComputePercent int `json:"per_client_compute_percent,omitempty"`
+	// Scheduling is the scheduling hint, SchedulingFIFO when empty
+	Scheduling string `json:"scheduling,omitempty"`
+}
+
+// IsZero reports whether f sets no fairness control
+func (f *WorkerFairness) IsZero() bool {
+	return f == nil || (f.PerClientComputePercent == 0 && (f.Scheduling == "" || f.Scheduling == SchedulingFIFO))
+}
+
+// GuaranteedComputePercent returns the SM share each of maxClients clients
+// can count on, or 0 when nothing is guaranteed. A client gets its whole cap
+// when the caps of all clients fit on the GPU; otherwise round-robin
+// scheduling still guarantees an equal share.
+func (f *WorkerFairness) GuaranteedComputePercent(maxClients int) int {
+	if f == nil || maxClients <= 0 {
+		return 0
+	}
+	if f.PerClientComputePercent > 0 && f.PerClientComputePercent*maxClients <= 100 {
+		return f.PerClientComputePercent
+	}
+	if f.Scheduling == SchedulingRoundRobin {
+		share := 100 / maxClients
+		if f.PerClientComputePercent > 0 {
+			share = min(share, f.PerClientComputePercent)
+		}
+		return share
+	}
+	return 0
+}
+
+// GPU compute modes, see WorkerGPUTuning
+const (
+	// ComputeModeDefault lets any number of processes use the GPU
+	ComputeModeDefault = "default"
+	// ComputeModeExclusiveProcess lets a single process use the GPU
+	ComputeModeExclusiveProcess = "exclusive-process"
+	// ComputeModeProhibited lets no process use the GPU
+	ComputeModeProhibited = "prohibited"
+)
+
+// WorkerGPUTuning caps the GPUs of a worker, e.g. to keep shared GPUs cool
+// or fair. The agent applies it through nvidia-smi or rocm-smi when it
+// starts the worker and restores the previous settings once the worker
+// stopped. Zero fields leave their setting alone.
+type WorkerGPUTuning struct {
+	// PowerLimitWatts caps the power draw of each GPU
+	PowerLimitWatts int `json:"power_limit_watts,omitempty"`
+	// MinClockMHz and MaxClockMHz lock the graphics clock into this range
+	MinClockMHz int `json:"min_clock_mhz,omitempty"`
+	MaxClockMHz int `json:"max_clock_mhz,omitempty"`
+	// PersistenceMode keeps the driver loaded while no process uses the GPU
+	// (NVIDIA only)
+	PersistenceMode *bool `json:"persistence_mode,omitempty"`
+	// ComputeMode is one of the ComputeMode* constants (NVIDIA only)
+	ComputeMode string `json:"compute_mode,omitempty"`
+}
+
+// IsZero reports whether t changes no GPU setting
+func (t *WorkerGPUTuning) IsZero() bool {
+	return t == nil || (t.PowerLimitWatts == 0 && t.MaxClockMHz == 0 && t.PersistenceMode == nil && t.ComputeMode == "")
+}
+
+// WorkerStandby describes the primary a standby agent backs up a worker for
+type WorkerStandby struct {
+	PrimaryAgentID string `json:"primary_agent_id"`
+	// FailoverAfterSeconds is how long the primary may miss heartbeats before
+	// the standby takes over; 0 uses the agent's default
+	FailoverAfterSeconds int `json:"failover_after_seconds,omitempty"`
+	// Active is set by the platform once the standby has taken over, and
+	// cleared to fail back to the primary
+	Active bool `json:"active,omitempty"`
+}
+
+// AgentPeerStatus is the platform's view of an HA peer's heartbeat, from
+// GET /api/v1/agents/{agent_id}/peers/{peer_id}
+type AgentPeerStatus struct {
+	AgentID    string     `json:"agent_id"`
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// WorkerFailoverRequest reports that a standby agent took over a worker;
+// the platform then repoints the worker's shares to the standby
+type WorkerFailoverRequest struct {
+	PrimaryAgentID  string     `json:"primary_agent_id"`
+	PrimaryLastSeen *time.Time `json:"primary_last_seen,omitempty"`
+}
+
+// RelayConfig tells the agent where to open relay tunnels
+type RelayConfig struct {
+	Addr  string `json:"addr"`  // host:port of the relay, served over TLS
+	Token string `json:"token"` // authenticates the agent's tunnels
 }
 
 // AgentConfigResponse represents the response from GET /api/v1/agents/{agent_id}/config
@@ -82,6 +299,51 @@ type AgentConfigResponse struct {
 	ConfigVersion int            `json:"config_version"`
 	Workers       []WorkerConfig `json:"workers"`
 	License       License        `json:"license"`
+	// Relay is set when at least one worker is served through the relay
+	Relay *RelayConfig `json:"relay,omitempty"`
+	// Reporting overrides the agent's status report timing; nil keeps the
+	// agent's defaults
+	Reporting *ReportingConfig `json:"reporting,omitempty"`
+	// WorkerLogs overrides how worker output is rotated; nil keeps the
+	// agent's defaults
+	WorkerLogs *WorkerLogConfig `json:"worker_logs,omitempty"`
+	// Disk overrides the agent's disk guardrails; nil keeps the agent's
+	// defaults
+	Disk *DiskConfig `json:"disk,omitempty"`
+}
+
+// DiskConfig tunes when the agent prunes its logs and cached worker releases
+// and reports disk pressure. Zero values keep the agent's defaults; settings
+// passed to the agent locally win.
+type DiskConfig struct {
+	// MinFreePercent and MinFreeMB are the free space on the filesystems of
+	// the cache and state directories below which the agent prunes and
+	// reports disk pressure
+	MinFreePercent int `json:"min_free_percent,omitempty"`
+	MinFreeMB      int `json:"min_free_mb,omitempty"`
+	// MaxLogsMB and MaxCacheMB cap the logs and cache directories
+	MaxLogsMB  int `json:"max_logs_mb,omitempty"`
+	MaxCacheMB int `json:"max_cache_mb,omitempty"`
+}
+
+// WorkerLogConfig tunes the rotation of the worker output the agent
+// captures. Zero values keep the agent's defaults; settings passed to the
+// agent locally win.
+type WorkerLogConfig struct {
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+	// MaxFiles is how many rotated files are kept per worker
+	MaxFiles int `json:"max_files,omitempty"`
+}
+
+// ReportingConfig tunes how often an agent reports its status. Zero values
+// keep the agent's defaults; settings passed to the agent locally win.
+type ReportingConfig struct {
+	IntervalSeconds     int `json:"interval_seconds,omitempty"`
+	ForceRefreshSeconds int `json:"force_refresh_seconds,omitempty"`
+	// ChangesOnly skips reports in which nothing changed; the agent sends a
+	// keepalive every KeepaliveSeconds instead
+	ChangesOnly      *bool `json:"changes_only,omitempty"`
+	KeepaliveSeconds int   `json:"keepalive_seconds,omitempty"`
 }
 
 // GPUStatus represents GPU status for status report
@@ -95,6 +357,13 @@ type GPUStatus struct {
 	DriverVersion string  `json:"driver_version,omitempty"`
 	CUDAVersion   string  `json:"cuda_version,omitempty"`
 	GPUChanged    bool    `json:"gpu_changed,omitempty"`
+	// MIGCapable is set for GPUs that support MIG, MIGEnabled when MIG mode
+	// is on; Partitions lists the GPU's MIG instances
+	MIGCapable bool           `json:"mig_capable,omitempty"`
+	MIGEnabled bool           `json:"mig_enabled,omitempty"`
+	Partitions []GPUPartition `json:"partitions,omitempty"`
+	// Health lists GPUHealth* flags; empty when the GPU is healthy
+	Health []string `json:"health,omitempty"`
 }
 
 // ConnectionInfo represents client connection information
@@ -103,6 +372,134 @@ type ConnectionInfo struct {
 	ClientPort  int       `json:"client_port,omitempty"`
 	ClientPID   int       `json:"client_pid,omitempty"`
 	ConnectedAt time.Time `json:"connected_at"`
+	// Set when the agent's connection proxy serves the worker, or by workers
+	// writing v2 connection files
+	ShareCode string `json:"share_code,omitempty"`
+	BytesIn   int64  `json:"bytes_in,omitempty"`
+	BytesOut  int64  `json:"bytes_out,omitempty"`
+	// Reported by workers writing v2 connection files: the worker's own ID of
+	// the session, the client's hostname and the protocol version the client
+	// negotiated
+	WorkerSessionID string `json:"worker_session_id,omitempty"`
+	ClientHostname  string `json:"client_hostname,omitempty"`
+	ProtocolVersion string `json:"protocol_version,omitempty"`
+}
+
+// SessionID identifies a client session of a worker by the client address
+func (c ConnectionInfo) SessionID() string {
+	return net.JoinHostPort(c.ClientIP, strconv.Itoa(c.ClientPort))
+}
+
+// WorkerSessionKillRequest asks an agent to end one client session of a
+// worker; it is pushed to the agent on its config topic when a user runs
+// `ggo worker sessions --kill`
+type WorkerSessionKillRequest struct {
+	RequestID   string `json:"request_id"`
+	WorkerID    string `json:"worker_id"`
+	KillSession string `json:"kill_session"` // ConnectionInfo.SessionID
+}
+
+// ShareUsage represents traffic and session time attributed to one client of a worker
+type ShareUsage struct {
+	ShareCode       string  `json:"share_code,omitempty"`
+	ClientIP        string  `json:"client_ip"`
+	BytesIn         int64   `json:"bytes_in"`
+	BytesOut        int64   `json:"bytes_out"`
+	Sessions        int     `json:"sessions"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	// QuotaDenied counts sessions the agent refused or ended because they
+	// exceeded the share's quota
+	QuotaDenied int `json:"quota_denied,omitempty"`
+}
+
+// WorkerCrashReport describes an abnormal worker exit captured by the agent
+type WorkerCrashReport struct {
+	WorkerID     string    `json:"worker_id"`
+	PID          int       `json:"pid,omitempty"`
+	Restarts     int       `json:"restarts"`
+	Exits        int       `json:"exits"` // abnormal exits covered by this report
+	DetectedAt   time.Time `json:"detected_at"`
+	ExitCode     *int      `json:"exit_code,omitempty"`
+	Signal       string    `json:"signal,omitempty"`
+	Reason       string    `json:"reason"`
+	LogFile      string    `json:"log_file,omitempty"`
+	LogTail      string    `json:"log_tail,omitempty"`
+	KernelEvents []string  `json:"kernel_events,omitempty"`
+	XIDs         []int     `json:"xids,omitempty"`
+}
+
+// WorkerCrashLoop describes a worker that crashed repeatedly within a short
+// window, with the error lines it printed last
+type WorkerCrashLoop struct {
+	Crashes    int       `json:"crashes"` // abnormal exits within the window
+	Since      time.Time `json:"since"`
+	LastErrors []string  `json:"last_errors,omitempty"`
+}
+
+// WorkerCrashListResponse represents the response for listing worker crash reports
+type WorkerCrashListResponse struct {
+	Crashes []WorkerCrashReport `json:"crashes"`
+}
+
+// WorkerLogRequest asks an agent to stream a worker's log; it is pushed to
+// the agent on its config topic when a user runs `ggo worker logs`
+type WorkerLogRequest struct {
+	RequestID string `json:"request_id"`
+	WorkerID  string `json:"worker_id"`
+	Tail      int    `json:"tail"`
+	Follow    bool   `json:"follow"`
+	// Output asks for the worker's captured stdout and stderr instead of
+	// the log the worker writes itself
+	Output bool `json:"output,omitempty"`
+}
+
+// WorkerLogChunk carries worker log lines from the agent to the viewer of a
+// WorkerLogRequest. EOF ends the stream; Error explains why it ended early.
+type WorkerLogChunk struct {
+	RequestID string   `json:"request_id"`
+	WorkerID  string   `json:"worker_id"`
+	Lines     []string `json:"lines,omitempty"`
+	EOF       bool     `json:"eof,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// WorkerLogChunkResponse tells the agent whether anyone is still reading
+type WorkerLogChunkResponse struct {
+	Closed bool `json:"closed"` // the viewer went away; stop streaming
+}
+
+// AgentExecStartRequest asks the server to run a diagnostic command on an
+// agent; the agent only runs commands on its local exec allowlist
+type AgentExecStartRequest struct {
+	Command        []string `json:"command"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+}
+
+// AgentExecRequest asks an agent to run a command; it is pushed to the agent
+// on its config topic when a user runs `ggo agent exec`
+type AgentExecRequest struct {
+	RequestID      string   `json:"request_id"`
+	Command        []string `json:"exec"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+	RequestedBy    string   `json:"requested_by,omitempty"` // user who ran the command, set by the server
+}
+
+// AgentExecChunk carries command output from the agent to the user of an
+// AgentExecRequest. EOF ends the stream with the exit code of a command that
+// ran, or Error explaining why it did not run or was stopped.
+type AgentExecChunk struct {
+	RequestID string `json:"request_id"`
+	Stdout    string `json:"stdout,omitempty"`
+	Stderr    string `json:"stderr,omitempty"`
+	EOF       bool   `json:"eof,omitempty"`
+	ExitCode  *int   `json:"exit_code,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"` // output beyond the agent's limit was dropped
+	Error     string `json:"error,omitempty"`
+}
+
+// AgentExecChunkResponse tells the agent whether the user is still reading
+type AgentExecChunkResponse struct {
+	Closed bool `json:"closed"` // the user went away; stop the command
 }
 
 // WorkerStatus represents worker status for status report
@@ -114,18 +511,64 @@ type WorkerStatus struct {
 	GPUIDs      []string         `json:"gpu_ids"`
 	GPUIndices  []int            `json:"gpu_indices,omitempty"`
 	Connections []ConnectionInfo `json:"connections"` // Removed omitempty - always send connections field (empty array or with data)
+	// Usage is reported only when the agent runs in connection proxy mode, as deltas since the previous report
+	Usage []ShareUsage `json:"usage,omitempty"`
+	// Crashes detected since the previous report, each sent once
+	Crashes []WorkerCrashReport `json:"crashes,omitempty"`
+	// CrashLoop is set while the worker keeps crashing
+	CrashLoop *WorkerCrashLoop `json:"crash_loop,omitempty"`
+	// TLSFingerprint is the SHA-256 of the certificate the agent serves on the
+	// worker port when TLS termination is enabled
+	TLSFingerprint string `json:"tls_fingerprint,omitempty"`
+	// RelayConnected reports whether the agent holds tunnels to the relay for
+	// a worker that is served through it
+	RelayConnected bool `json:"relay_connected,omitempty"`
+	// DrainDeadline is when a stopping worker is stopped even if clients are
+	// still connected
+	DrainDeadline *time.Time `json:"drain_deadline,omitempty"`
+	// Health holds the latest health probe results; nil when probes are off
+	// or the worker was not probed yet
+	Health *WorkerHealth `json:"health,omitempty"`
+	// Warm is set while a disabled warm worker runs, refusing sessions
+	Warm bool `json:"warm,omitempty"`
 	// Optimization flags - only update DB when these are true
 	WorkerChanged     *bool `json:"worker_changed,omitempty"`     // true if status/pid/restarts/gpu_ids changed
 	ConnectionChanged *bool `json:"connection_changed,omitempty"` // true if connections changed
 	GPUChanged        *bool `json:"gpu_changed,omitempty"`        // true if vendor/model/vram/driver/cuda changed
 }
 
+// WorkerHealth is the outcome of the agent's health probes of a worker
+type WorkerHealth struct {
+	Status              string              `json:"status"` // healthy, unhealthy or unknown
+	ConsecutiveFailures int                 `json:"consecutive_failures,omitempty"`
+	Probes              []WorkerProbeResult `json:"probes"`
+	// XIDs are critical GPU Xid errors seen since the worker started
+	XIDs []int `json:"xids,omitempty"`
+	// Restarts triggered by failed probes within the restart window
+	Restarts int `json:"restarts,omitempty"`
+	// RestartsExhausted is set when an unhealthy worker is left running
+	// because it used up its restarts
+	RestartsExhausted bool      `json:"restarts_exhausted,omitempty"`
+	CheckedAt         time.Time `json:"checked_at"`
+}
+
+// WorkerProbeResult is the latest result of one health probe (tcp, ping or xid)
+type WorkerProbeResult struct {
+	Probe     string `json:"probe"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+}
+
 // AgentStatusEvent represents special events in status report
 type AgentStatusEvent string
 
 const (
 	// AgentStatusEventShutdown indicates the agent is shutting down
 	AgentStatusEventShutdown AgentStatusEvent = "shutdown"
+	// AgentStatusEventKeepalive replaces an unchanged status report in
+	// changes-only mode; GPUs and workers are omitted and remain as last reported
+	AgentStatusEventKeepalive AgentStatusEvent = "keepalive"
 )
 
 // AgentStatusRequest represents the request body for agent status report
@@ -137,9 +580,75 @@ type AgentStatusRequest struct {
 	// License optimization - client reports current license expiration
 	// Server only regenerates if < 10 minutes remaining
 	LicenseExpiration *int64 `json:"license_expiration,omitempty"` // Unix timestamp in milliseconds
+	// LicenseStatus warns that the license is about to expire or has
+	// expired, see LicenseStatus* constants
+	LicenseStatus string `json:"license_status,omitempty"`
 	// Metrics contains InfluxDB v2 line protocol string with GPU/system/worker metrics
 	// Forwarded by the backend to GreptimeDB for time-series storage
 	Metrics string `json:"metrics,omitempty"`
+	// NetTest summarizes a network self-test run since the previous report
+	NetTest *NetTestSummary `json:"net_test,omitempty"`
+	// Disk is the disk usage of the agent's directories at the last check
+	Disk *AgentDiskUsage `json:"disk,omitempty"`
+	// ReportID identifies the report, so that the server can drop a report
+	// it receives twice, e.g. one whose first delivery timed out after the
+	// server processed it
+	ReportID string `json:"report_id,omitempty"`
+	// Backfill marks a report queued while the platform was unreachable and
+	// delivered late. The server records its metrics and fills the timeline
+	// with it but must not take its GPU and worker state as current.
+	Backfill bool `json:"backfill,omitempty"`
+}
+
+// AgentDiskUsage is the space the agent's directories take and the free
+// space left on the fullest of their filesystems
+type AgentDiskUsage struct {
+	// CacheBytes covers downloaded libraries and staged worker releases
+	CacheBytes int64 `json:"cache_bytes"`
+	// LogsBytes covers worker logs and captured worker output
+	LogsBytes  int64  `json:"logs_bytes"`
+	FreeBytes  uint64 `json:"free_bytes,omitempty"`
+	TotalBytes uint64 `json:"total_bytes,omitempty"`
+	// Pressure is set while free space is below the agent's threshold or a
+	// directory exceeds its cap even after pruning
+	Pressure bool `json:"pressure,omitempty"`
+	// CheckedAt is when the usage was measured
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// NetTestSummary condenses a network self-test of the agent ('ggo agent
+// nettest'). Zero values mean the probe was not run or failed; Failed names
+// the probes that failed (api, websocket, cdn, ports).
+type NetTestSummary struct {
+	RanAt             time.Time `json:"ran_at"`
+	APILatencyMs      float64   `json:"api_latency_ms,omitempty"`
+	APIThroughputMbps float64   `json:"api_throughput_mbps,omitempty"`
+	WSConnectMs       float64   `json:"ws_connect_ms,omitempty"`
+	WSRoundTripMs     float64   `json:"ws_round_trip_ms,omitempty"`
+	CDNThroughputMbps float64   `json:"cdn_throughput_mbps,omitempty"`
+	UnreachablePorts  []int     `json:"unreachable_ports,omitempty"`
+	Failed            []string  `json:"failed,omitempty"`
+}
+
+// PortReachRequest asks the platform to connect back to ports on the agent's
+// host, as a remote client would
+type PortReachRequest struct {
+	Ports []int `json:"ports"`
+}
+
+// PortReachResponse reports which ports the platform could connect to
+type PortReachResponse struct {
+	// Address is the agent address the platform connected to
+	Address string      `json:"address"`
+	Ports   []PortReach `json:"ports"`
+}
+
+// PortReach is the outcome of connecting to one port
+type PortReach struct {
+	Port      int     `json:"port"`
+	Reachable bool    `json:"reachable"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
 }
 
 // AgentStatusResponse represents the response from agent status report
@@ -148,6 +657,10 @@ type AgentStatusResponse struct {
 	ConfigVersion    int                 `json:"config_version"`
 	License          *License            `json:"license,omitempty"`            // null if no regeneration needed
 	WorkerShareCodes map[string][]string `json:"worker_share_codes,omitempty"` // workerID -> []shareCode
+	SecretRotation   string              `json:"secret_rotation,omitempty"`    // see SecretRotation* constants
+	// ShareQuotas holds the quotas of the agent's shares that have one,
+	// keyed by share code; shares missing from it are unlimited
+	ShareQuotas map[string]ShareQuotaState `json:"share_quotas,omitempty"`
 }
 
 // SuccessResponse represents a simple success response
@@ -157,22 +670,50 @@ type SuccessResponse struct {
 
 // WorkerInfo represents worker information
 type WorkerInfo struct {
-	WorkerID      string           `json:"worker_id"`
-	AgentID       string           `json:"agent_id,omitempty"`
-	AgentHostname string           `json:"agent_hostname,omitempty"`
-	Name          string           `json:"name"`
-	GPUIDs        []string         `json:"gpu_ids"`
-	GPUIndices    []int            `json:"gpu_indices,omitempty"`
-	GPUs          []GPUInfo        `json:"gpus,omitempty"`
-	ListenPort    int              `json:"listen_port"`
-	Enabled       bool             `json:"enabled"`
-	IsDefault     bool             `json:"is_default,omitempty"`
-	Status        string           `json:"status"`
-	PID           int              `json:"pid,omitempty"`
-	Restarts      int              `json:"restarts,omitempty"`
-	Connections   []ConnectionInfo `json:"connections,omitempty"`
-	StartedAt     *time.Time       `json:"started_at,omitempty"`
-	CreatedAt     time.Time        `json:"created_at,omitempty"`
+	WorkerID      string            `json:"worker_id"`
+	AgentID       string            `json:"agent_id,omitempty"`
+	AgentHostname string            `json:"agent_hostname,omitempty"`
+	Name          string            `json:"name"`
+	GPUIDs        []string          `json:"gpu_ids"`
+	GPUIndices    []int             `json:"gpu_indices,omitempty"`
+	GPUs          []GPUInfo         `json:"gpus,omitempty"`
+	ListenPort    int               `json:"listen_port"`
+	Enabled       bool              `json:"enabled"`
+	IsDefault     bool              `json:"is_default,omitempty"`
+	MIGProfile    string            `json:"mig_profile,omitempty"`
+	Status        string            `json:"status"`
+	PID           int               `json:"pid,omitempty"`
+	Restarts      int               `json:"restarts,omitempty"`
+	Connections   []ConnectionInfo  `json:"connections,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+	StartedAt     *time.Time        `json:"started_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at,omitempty"`
+	DrainDeadline *time.Time        `json:"drain_deadline,omitempty"`
+	// HA is set for workers backed by a standby agent
+	HA *WorkerHAStatus `json:"ha,omitempty"`
+	// Fairness divides the worker's GPU time between its clients
+	Fairness *WorkerFairness `json:"fairness,omitempty"`
+	// Tuning caps the worker's GPUs while it runs
+	Tuning *WorkerGPUTuning `json:"tuning,omitempty"`
+	// Warm keeps the worker running while it is disabled, see
+	// WorkerConfig.Warm
+	Warm bool `json:"warm,omitempty"`
+}
+
+// HA states of a worker
+const (
+	HAStateProtected      = "protected"       // primary serving, standby ready
+	HAStateStandbyOffline = "standby_offline" // primary serving, no standby to fail over to
+	HAStateFailedOver     = "failed_over"     // standby serving
+)
+
+// WorkerHAStatus describes the HA pair serving a worker
+type WorkerHAStatus struct {
+	PrimaryAgentID string     `json:"primary_agent_id"`
+	StandbyAgentID string     `json:"standby_agent_id"`
+	ActiveAgentID  string     `json:"active_agent_id"`
+	State          string     `json:"state"`
+	LastFailoverAt *time.Time `json:"last_failover_at,omitempty"`
 }
 
 // WorkerCreateRequest represents the request body for worker creation
@@ -182,6 +723,40 @@ type WorkerCreateRequest struct {
 	GPUIDs     []string `json:"gpu_ids"`
 	ListenPort int      `json:"listen_port"`
 	Enabled    bool     `json:"enabled"`
+	// Env holds extra environment variables for the worker process
+	Env map[string]string `json:"env,omitempty"`
+	// HAPeer is the agent that stands by to take over the worker, on
+	// equivalent GPUs, when AgentID stops sending heartbeats
+	HAPeer string `json:"ha_peer,omitempty"`
+	// MIGProfile runs the worker on a MIG instance of this profile, created
+	// on its only GPU
+	MIGProfile string `json:"mig_profile,omitempty"`
+	// Warm keeps the worker running while it is disabled, see
+	// WorkerConfig.Warm
+	Warm bool `json:"warm,omitempty"`
+}
+
+// Worker validation check states
+const (
+	ValidationPass = "pass"
+	ValidationWarn = "warn"
+	ValidationFail = "fail"
+	ValidationSkip = "skip"
+)
+
+// WorkerValidationCheck is the outcome of one check of a proposed worker
+// config, see Validation* constants for Status
+type WorkerValidationCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// WorkerValidationResponse represents the response from POST
+// /api/v1/workers/validate. Valid is false if any check failed.
+type WorkerValidationResponse struct {
+	Valid  bool                    `json:"valid"`
+	Checks []WorkerValidationCheck `json:"checks"`
 }
 
 // WorkerUpdateRequest represents the request body for worker update
@@ -190,6 +765,17 @@ type WorkerUpdateRequest struct {
 	GPUIDs     []string `json:"gpu_ids,omitempty"`
 	ListenPort *int     `json:"listen_port,omitempty"`
 	Enabled    *bool    `json:"enabled,omitempty"`
+	// Env replaces the worker's extra environment variables when non-nil;
+	// a pointer so that an empty map clears them
+	Env *map[string]string `json:"env,omitempty"`
+	// Fairness replaces the worker's fairness controls when non-nil; an
+	// empty value removes them
+	Fairness *WorkerFairness `json:"fairness,omitempty"`
+	// Tuning replaces the worker's GPU tuning when non-nil; an empty value
+	// removes it
+	Tuning *WorkerGPUTuning `json:"tuning,omitempty"`
+	// Warm turns warm mode on or off when non-nil
+	Warm *bool `json:"warm,omitempty"`
 }
 
 // WorkerListResponse represents the response from GET /api/v1/workers
@@ -209,14 +795,168 @@ type ShareInfo struct {
 	MaxUses        *int       `json:"max_uses,omitempty"`
 	UsedCount      int        `json:"used_count"`
 	CreatedAt      time.Time  `json:"created_at"`
+	// Alias is the owner's name for the share, unique among their shares
+	Alias string `json:"alias,omitempty"`
+	// Notifications sent to the owner about the share's use, if configured
+	Notifications *ShareNotifications `json:"notifications,omitempty"`
+	// Relay is set when ConnectionURL points at the platform relay
+	Relay bool `json:"relay,omitempty"`
+	// Team is set for shares bound to a platform team
+	Team string `json:"team,omitempty"`
+	// FallbackConnectionURLs reach the worker over the other IP family, for
+	// clients that cannot reach ConnectionURL
+	FallbackConnectionURLs []string `json:"fallback_connection_urls,omitempty"`
+	// Quota limits the share's use, enforced by the agent
+	Quota *ShareQuota `json:"quota,omitempty"`
+	// QuotaUsage is the share's consumption counted against Quota
+	QuotaUsage *ShareQuotaUsage `json:"quota_usage,omitempty"`
 }
 
 // ShareCreateRequest represents the request body for share creation
 type ShareCreateRequest struct {
-	WorkerID     string     `json:"worker_id"`
-	ConnectionIP string     `json:"connection_ip"`
-	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
-	MaxUses      *int       `json:"max_uses,omitempty"`
+	WorkerID      string              `json:"worker_id"`
+	ConnectionIP  string              `json:"connection_ip"`
+	ExpiresAt     *time.Time          `json:"expires_at,omitempty"`
+	MaxUses       *int                `json:"max_uses,omitempty"`
+	Notifications *ShareNotifications `json:"notifications,omitempty"`
+	// Relay routes the share through the platform relay instead of
+	// ConnectionIP, for clients whose network blocks the worker port
+	Relay bool `json:"relay,omitempty"`
+	// Team binds the share to a platform team: members resolve it with their
+	// own login instead of the share code
+	Team string `json:"team,omitempty"`
+	// FallbackIPs are addresses of the other IP family that clients try
+	// when ConnectionIP is unreachable, e.g. the IPv4 address of a share
+	// made over IPv6
+	FallbackIPs []string    `json:"fallback_ips,omitempty"`
+	Quota       *ShareQuota `json:"quota,omitempty"`
+	// Alias names the share for 'ggo use <alias>' in the owner's account;
+	// creating a second share with the same alias is refused with 409
+	Alias string `json:"alias,omitempty"`
+}
+
+// ShareUpdateRequest represents the request body for share updates
+type ShareUpdateRequest struct {
+	// Notifications replaces the share's notification settings; an empty
+	// value (no webhook, no email) turns notifications off
+	Notifications *ShareNotifications `json:"notifications,omitempty"`
+	// Quota replaces the share's quota when non-nil; an empty value removes it
+	Quota *ShareQuota `json:"quota,omitempty"`
+}
+
+// ShareQuota limits how much a share's consumers may use its worker. The
+// agent enforces it on the connections it proxies; zero fields are unlimited.
+type ShareQuota struct {
+	// GPUHoursPerWeek caps the GPU time of all consumers together per
+	// calendar week (from Monday 00:00 UTC): session hours times the number
+	// of GPUs of the worker
+	GPUHoursPerWeek float64 `json:"gpu_hours_per_week,omitempty"`
+	// MaxSessionMinutes ends sessions that last longer
+	MaxSessionMinutes int `json:"max_session_minutes,omitempty"`
+	// MaxConcurrentSessions caps the open sessions of each consumer (client IP)
+	MaxConcurrentSessions int `json:"max_concurrent_sessions,omitempty"`
+}
+
+// IsZero reports whether q sets no limit
+func (q *ShareQuota) IsZero() bool {
+	return q == nil || (q.GPUHoursPerWeek == 0 && q.MaxSessionMinutes == 0 && q.MaxConcurrentSessions == 0)
+}
+
+// ShareQuotaUsage is a share's consumption in the current quota week
+type ShareQuotaUsage struct {
+	WeekStart      time.Time `json:"week_start"`
+	GPUHours       float64   `json:"gpu_hours"`
+	ActiveSessions int       `json:"active_sessions"`
+	// QuotaDenied counts sessions refused or ended by the quota this week
+	QuotaDenied int `json:"quota_denied,omitempty"`
+}
+
+// ShareConsumptionCounts is the consumption of a share over a period
+type ShareConsumptionCounts struct {
+	Sessions int `json:"sessions"`
+	// UniqueConsumers counts distinct client machines
+	UniqueConsumers int     `json:"unique_consumers"`
+	GPUHours        float64 `json:"gpu_hours"`
+	// BytesTransferred sums both directions; 0 when the agent does not run
+	// the connection proxy
+	BytesTransferred int64 `json:"bytes_transferred"`
+	// FailedSessions ended with an error or were refused
+	FailedSessions int `json:"failed_sessions"`
+}
+
+// ErrorRate is the fraction of sessions that failed
+func (c *ShareConsumptionCounts) ErrorRate() float64 {
+	if c.Sessions == 0 {
+		return 0
+	}
+	return float64(c.FailedSessions) / float64(c.Sessions)
+}
+
+// ShareConsumptionBucket is the consumption of a share in one interval starting
+// at Start
+type ShareConsumptionBucket struct {
+	Start time.Time `json:"start"`
+	ShareConsumptionCounts
+}
+
+// ShareConsumption is the consumption of a share aggregated by the platform since
+// a point in time, in total and in consecutive buckets
+type ShareConsumption struct {
+	ShareID       string                   `json:"share_id"`
+	Since         time.Time                `json:"since"`
+	Until         time.Time                `json:"until"`
+	BucketSeconds int                      `json:"bucket_seconds"`
+	Totals        ShareConsumptionCounts   `json:"totals"`
+	Buckets       []ShareConsumptionBucket `json:"buckets"`
+}
+
+// ShareQuotaState is a share's quota as sent to the agent enforcing it,
+// with the GPU-hours the platform has accounted for the week so far
+type ShareQuotaState struct {
+	ShareQuota
+	GPUHoursUsed float64 `json:"gpu_hours_used"`
+}
+
+// Share events the owner can be notified about
+const (
+	// ShareEventFirstUse fires when the share is resolved for the first time
+	ShareEventFirstUse = "first_use"
+	// ShareEventExhausted fires when the share reaches its max uses
+	ShareEventExhausted = "exhausted"
+)
+
+// ShareNotifications configures how the owner is told about a share's use
+type ShareNotifications struct {
+	WebhookURL string   `json:"webhook_url,omitempty"`
+	Email      string   `json:"email,omitempty"`
+	Events     []string `json:"events,omitempty"` // ShareEventFirstUse, ShareEventExhausted
+}
+
+// ShareConsumerRequest registers the client resolving a share with its owner
+type ShareConsumerRequest struct {
+	Hostname      string `json:"hostname"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch,omitempty"`
+	ClientVersion string `json:"client_version"`
+	Via           string `json:"via"` // command that resolved the share, e.g. "use" or "studio"
+}
+
+// ShareConsumer is a client that has resolved a share
+type ShareConsumer struct {
+	Hostname      string    `json:"hostname"`
+	OS            string    `json:"os"`
+	Arch          string    `json:"arch,omitempty"`
+	ClientVersion string    `json:"client_version"`
+	Via           string    `json:"via"`
+	ClientIP      string    `json:"client_ip,omitempty"`
+	Uses          int       `json:"uses"`
+	FirstSeenAt   time.Time `json:"first_seen_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+}
+
+// ShareConsumerListResponse represents the response for listing a share's consumers
+type ShareConsumerListResponse struct {
+	Consumers []ShareConsumer `json:"consumers"`
 }
 
 // ShareListResponse represents the response from GET /api/v1/shares
@@ -230,6 +970,76 @@ type SharePublicInfo struct {
 	HardwareVendor string `json:"hardware_vendor"`
 	ConnectionURL  string `json:"connection_url"`
 	AgentArch      string `json:"agent_arch,omitempty"` // Architecture of the agent (e.g., "amd64", "arm64")
+	// TLSFingerprint pins the worker's certificate when the agent terminates TLS
+	TLSFingerprint string `json:"tls_fingerprint,omitempty"`
+	// Relay is set when ConnectionURL points at the platform relay
+	Relay bool `json:"relay,omitempty"`
+	// FallbackConnectionURLs reach the worker over the other IP family;
+	// clients try them when ConnectionURL is unreachable
+	FallbackConnectionURLs []string `json:"fallback_connection_urls,omitempty"`
+	// Queue is the worker's client load; unset when the platform does not
+	// report it
+	Queue *ShareQueueStatus `json:"queue,omitempty"`
+	// Fairness tells consumers how the worker divides its GPU time between
+	// clients
+	Fairness *WorkerFairness `json:"fairness,omitempty"`
+}
+
+// ShareStats is the live load of a shared worker as the consumers of a share
+// see it: the worker's GPUs and the client sessions opened with the share
+type ShareStats struct {
+	WorkerID  string       `json:"worker_id"`
+	SampledAt time.Time    `json:"sampled_at"`
+	GPUs      []GPUMetrics `json:"gpus"`
+	// Sessions carry traffic counters when the agent runs the connection
+	// proxy
+	Sessions []ConnectionInfo `json:"sessions,omitempty"`
+	// SessionVRAMUsedMb is the GPU memory held by the share's sessions; 0
+	// when the worker does not account memory per client
+	SessionVRAMUsedMb int64 `json:"session_vram_used_mb,omitempty"`
+}
+
+// ShareQueueStatus is the client load of a shared worker that limits how
+// many clients it serves at once
+type ShareQueueStatus struct {
+	Connections    int `json:"connections"`     // clients connected now
+	MaxConnections int `json:"max_connections"` // 0 means unlimited
+	// Waiting is the number of clients queued for a free slot
+	Waiting int `json:"waiting,omitempty"`
+	// Position is the caller's place in the queue (1 is next), set when it
+	// resolved the share as a waiter
+	Position int `json:"position,omitempty"`
+	// EstimatedWaitSeconds is the platform's estimate of when a slot frees
+	// up for the caller
+	EstimatedWaitSeconds int `json:"estimated_wait_seconds,omitempty"`
+}
+
+// Saturated reports whether the worker has no free client slot
+func (q *ShareQueueStatus) Saturated() bool {
+	return q != nil && q.MaxConnections > 0 && q.Connections >= q.MaxConnections
+}
+
+// TeamWorker is a worker shared with a team the user belongs to
+type TeamWorker struct {
+	WorkerID       string    `json:"worker_id"`
+	Name           string    `json:"name"`
+	Owner          string    `json:"owner,omitempty"`
+	Hostname       string    `json:"hostname,omitempty"`
+	HardwareVendor string    `json:"hardware_vendor"`
+	Status         string    `json:"status"`
+	Relay          bool      `json:"relay,omitempty"`
+	SharedAt       time.Time `json:"shared_at"`
+}
+
+// TeamWorkerListResponse represents the response from GET /api/v1/teams/{team}/workers
+type TeamWorkerListResponse struct {
+	Workers []TeamWorker `json:"workers"`
+}
+
+// TeamShareInfo is the team share of a worker as resolved for a team member
+type TeamShareInfo struct {
+	SharePublicInfo
+	ShortCode string `json:"short_code"`
 }
 
 // SystemMetrics represents system metrics for metrics report
@@ -258,9 +1068,134 @@ type AgentMetricsRequest struct {
 	GPUs      []GPUMetrics  `json:"gpus"`
 }
 
+// Agent event types shown on the agent's timeline in the console
+const (
+	AgentEventWorkerStarted   = "worker_started"
+	AgentEventWorkerStopped   = "worker_stopped"
+	AgentEventWorkerCrashed   = "worker_crashed"
+	AgentEventGPUError        = "gpu_error"
+	AgentEventLicenseExpiring = "license_expiring"
+	AgentEventDiskPressure    = "disk_pressure"
+	AgentEventRemoteExec      = "remote_exec"
+	AgentEventWatchdog        = "watchdog"
+)
+
+// Agent event severities
+const (
+	AgentEventSeverityInfo    = "info"
+	AgentEventSeverityWarning = "warning"
+	AgentEventSeverityError   = "error"
+)
+
+// AgentEvent is a structured lifecycle event recorded by the agent. ID is
+// assigned once when the event is recorded, so the server can drop events
+// an agent uploads again after a failed request.
+type AgentEvent struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Severity   string            `json:"severity"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	WorkerID   string            `json:"worker_id,omitempty"`
+	GPUID      string            `json:"gpu_id,omitempty"`
+	Message    string            `json:"message"`
+	Details    map[string]string `json:"details,omitempty"`
+}
+
+// AgentEventsRequest represents the request body for uploading agent events
+type AgentEventsRequest struct {
+	Events []AgentEvent `json:"events"`
+}
+
+// AgentPollResponse carries the messages published on an agent topic, for
+// agents whose network does not keep an SSE connection open
+type AgentPollResponse struct {
+	Messages []AgentPollMessage `json:"messages"`
+	// Cursor is passed to the next poll to receive only newer messages
+	Cursor string `json:"cursor"`
+}
+
+// AgentPollMessage is one message published on an agent topic
+type AgentPollMessage struct {
+	// Data holds the data lines of the SSE frame, separated by newlines
+	Data string `json:"data"`
+}
+
 // HeartbeatResponse represents the response from WebSocket heartbeat
 type HeartbeatResponse struct {
-	ConfigVersion int `json:"config_version"`
+	ConfigVersion  int    `json:"config_version"`
+	SecretRotation string `json:"secret_rotation,omitempty"` // see SecretRotation* constants
+}
+
+// Secret rotation signals sent by the server with heartbeat and status responses
+const (
+	SecretRotationExpiring    = "expiring"
+	SecretRotationCompromised = "compromised"
+)
+
+// AgentSecretRotateResponse carries the agent's new secret. The server keeps
+// accepting the previous secret until the new one has been used, so an agent
+// that fails to persist the new secret can keep working with the old one.
+type AgentSecretRotateResponse struct {
+	AgentSecret string     `json:"agent_secret"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// LicenseRenewalRequest is the challenge an agent without access to the
+// platform exports for an offline license renewal. Signature is an
+// HMAC-SHA256 of the other fields keyed with the agent secret, so the
+// platform can tell the request comes from the agent.
+type LicenseRenewalRequest struct {
+	AgentID           string    `json:"agent_id"`
+	Hostname          string    `json:"hostname"`
+	GPUIDs            []string  `json:"gpu_ids"`
+	Nonce             string    `json:"nonce"`
+	CreatedAt         time.Time `json:"created_at"`
+	LicenseExpiration *int64    `json:"license_expiration,omitempty"` // Unix timestamp in milliseconds
+	Signature         string    `json:"signature"`
+}
+
+// LicenseRenewalBundle is the platform's answer to a LicenseRenewalRequest,
+// carried back to the agent out of band. Signature is an HMAC-SHA256 of the
+// agent ID, nonce, issue time and license keyed with the agent secret.
+type LicenseRenewalBundle struct {
+	AgentID   string    `json:"agent_id"`
+	Nonce     string    `json:"nonce"`
+	IssuedAt  time.Time `json:"issued_at"`
+	License   License   `json:"license"`
+	Signature string    `json:"signature"`
+}
+
+// License states reported with agent status while the license runs out
+const (
+	LicenseStatusExpiring = "expiring"
+	LicenseStatusExpired  = "expired"
+)
+
+// WorkerCertificateRequest asks the server to sign a worker certificate
+type WorkerCertificateRequest struct {
+	CSR string `json:"csr"` // PEM-encoded certificate signing request
+}
+
+// WorkerCertificateResponse carries a platform-issued worker certificate
+type WorkerCertificateResponse struct {
+	Certificate string    `json:"certificate"` // PEM-encoded leaf followed by any intermediates
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// AuditEntry is a CLI action recorded in a user's local audit log
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	User      string    `json:"user"`
+	Host      string    `json:"host,omitempty"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args,omitempty"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AuditUploadRequest uploads local audit log entries for centralized retention
+type AuditUploadRequest struct {
+	Entries []AuditEntry `json:"entries"`
 }
 
 // IsolationModeType mirrors tensor-fusion's IsolationModeType
@@ -293,6 +1228,10 @@ type ReleaseArtifact struct {
 	URL      string            `json:"url"`
 	SHA256   string            `json:"sha256"`
 	Metadata map[string]string `json:"metadata,omitempty"`
+	// Signature is the publisher's base64 Ed25519 signature of the artifact,
+	// see deps.ArtifactSignaturePayload; KeyID names the signing key
+	Signature string `json:"signature,omitempty"`
+	KeyID     string `json:"keyId,omitempty"`
 }
 
 // ReleaseRequirements represents version requirements for a release
@@ -313,6 +1252,7 @@ type ReleaseInfo struct {
 	Vendor       VendorInfo          `json:"vendor"`
 	Version      string              `json:"version"`
 	ReleaseType  string              `json:"releaseType"`
+	Channel      string              `json:"channel,omitempty"` // stable, beta or nightly; empty means stable
 	ReleaseDate  time.Time           `json:"releaseDate"`
 	Artifacts    []ReleaseArtifact   `json:"artifacts"`
 	Requirements ReleaseRequirements `json:"requirements"`
@@ -324,3 +1264,30 @@ type ReleasesResponse struct {
 	Releases []ReleaseInfo `json:"releases"`
 	Count    int           `json:"count"`
 }
+
+// StudioTemplate is a curated studio environment: an image with recommended
+// resources, ports and notes
+type StudioTemplate struct {
+	Name        string   `json:"name"` // e.g. "sd-webui"
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Image       string   `json:"image"`
+	Tags        []string `json:"tags,omitempty"`
+	// Recommended resources of the studio and its remote GPU
+	CPUs      float64 `json:"cpus,omitempty"`
+	Memory    string  `json:"memory,omitempty"`
+	MinVRAMMb int64   `json:"min_vram_mb,omitempty"`
+	// Ports (host:container), Volumes (host-path-or-volume:container[:ro])
+	// and Env (KEY=VALUE) are passed to the studio as with -p, -v and -e
+	Ports   []string `json:"ports,omitempty"`
+	Volumes []string `json:"volumes,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	Command []string `json:"command,omitempty"`
+	// Notes are shown once the studio is created, e.g. how to open its UI
+	Notes string `json:"notes,omitempty"`
+}
+
+// StudioTemplatesResponse represents the response from GET /api/v1/studio/templates
+type StudioTemplatesResponse struct {
+	Templates []StudioTemplate `json:"templates"`
+}
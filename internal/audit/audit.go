@@ -0,0 +1,178 @@
+// Package audit records mutating CLI actions in an append-only local log.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+)
+
+// Entry results
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Redacted replaces the value of sensitive flags in recorded args
+const Redacted = "<redacted>"
+
+// Entry is one recorded CLI action, stored as a single JSON line
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	User      string    `json:"user"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args,omitempty"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Log is an append-only JSONL audit log. Entries are only ever appended;
+// nothing in ggo rewrites or truncates the file.
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLog returns the audit log stored at path
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the log file path
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes an entry to the end of the log, filling in the timestamp
+// and user when they are unset
+func (l *Log) Append(e Entry) error {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	e.Timestamp = e.Timestamp.UTC()
+	if e.User == "" {
+		e.User = CurrentUser()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	// A single write per line keeps concurrent appends from interleaving
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// List returns the entries recorded at or after since, oldest first. A zero
+// since returns every entry. Malformed lines, such as one cut short by a
+// crash, are skipped.
+func (l *Log) List(since time.Time) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if !since.IsZero() && e.Timestamp.Before(since) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Uploaded returns the timestamp of the last entry uploaded to the
+// platform, zero if nothing has been uploaded yet
+func (l *Log) Uploaded() (time.Time, error) {
+	data, err := os.ReadFile(l.cursorPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid audit upload cursor: %w", err)
+	}
+	return t, nil
+}
+
+// MarkUploaded records that entries up to and including t were uploaded
+func (l *Log) MarkUploaded(t time.Time) error {
+	return utils.AtomicWriteFile(l.cursorPath(), []byte(t.UTC().Format(time.RFC3339Nano)+"\n"), 0600)
+}
+
+func (l *Log) cursorPath() string {
+	return l.path + ".uploaded"
+}
+
+// CurrentUser returns the name of the local user running the CLI
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, env := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(env); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// IsSensitiveFlag reports whether a flag carries a credential whose value
+// must not be written to the audit log
+func IsSensitiveFlag(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"token", "secret", "password"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatFlag renders a flag as it is recorded, redacting sensitive values
+func FormatFlag(name, value string) string {
+	if IsSensitiveFlag(name) {
+		value = Redacted
+	}
+	return "--" + name + "=" + value
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_AppendAndList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "audit.jsonl")
+	log := NewLog(path)
+
+	entries, err := log.List(time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, entries, "missing log is empty")
+
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	require.NoError(t, log.Append(Entry{Timestamp: old, User: "alice", Command: "ggo worker delete", Args: []string{"w-1"}, Result: ResultSuccess}))
+	require.NoError(t, log.Append(Entry{Command: "ggo share create", Result: ResultFailure, Error: "boom"}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err = log.List(time.Time{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "alice", entries[0].User)
+	assert.Equal(t, []string{"w-1"}, entries[0].Args)
+	assert.NotEmpty(t, entries[1].User, "user is filled in")
+	assert.False(t, entries[1].Timestamp.IsZero(), "timestamp is filled in")
+
+	entries, err = log.List(time.Now().Add(-7 * 24 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ggo share create", entries[0].Command)
+	assert.Equal(t, "boom", entries[0].Error)
+}
+
+func TestLog_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewLog(path)
+	require.NoError(t, log.Append(Entry{Command: "ggo studio rm", Result: ResultSuccess}))
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"timestamp":"2026-`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, err := log.List(time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLog_UploadCursor(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	cursor, err := log.Uploaded()
+	require.NoError(t, err)
+	assert.True(t, cursor.IsZero())
+
+	now := time.Now()
+	require.NoError(t, log.MarkUploaded(now))
+	cursor, err = log.Uploaded()
+	require.NoError(t, err)
+	assert.True(t, now.Equal(cursor))
+}
+
+func TestFormatFlag(t *testing.T) {
+	assert.Equal(t, "--name=demo", FormatFlag("name", "demo"))
+	assert.Equal(t, "--token=<redacted>", FormatFlag("token", "gpugo_abc"))
+	assert.Equal(t, "--agent-secret=<redacted>", FormatFlag("agent-secret", "s"))
+	assert.True(t, IsSensitiveFlag("Password"))
+	assert.False(t, IsSensitiveFlag("port"))
+}
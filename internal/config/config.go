@@ -7,8 +7,10 @@ import (
 	"sync"
 
 	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/credentials"
 	"github.com/NexusGPU/gpu-go/internal/platform"
 	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -60,6 +62,9 @@ type Manager struct {
 	configDir string
 	stateDir  string
 	mu        sync.RWMutex
+
+	storeMu sync.Mutex
+	store   Store
 }
 
 // NewManager creates a new configuration manager
@@ -108,19 +113,48 @@ func (m *Manager) WorkersPath() string {
 	return filepath.Join(m.configDir, workersFile)
 }
 
+// ConfigDir returns the configuration directory
+func (m *Manager) ConfigDir() string {
+	return m.configDir
+}
+
 // StateDir returns the state directory
 func (m *Manager) StateDir() string {
 	return m.stateDir
 }
 
-// LoadConfig loads the agent configuration
+// LoadConfig loads the agent configuration. The agent secret is resolved from
+// the OS keyring, and a plaintext secret is moved there when one is available.
 func (m *Manager) LoadConfig() (*Config, error) {
+	cfg, err := m.loadRawConfig()
+	if err != nil || cfg == nil {
+		return cfg, err
+	}
+
+	if credentials.NeedsMigration(cfg.AgentSecret) {
+		if err := m.SaveConfig(cfg); err != nil {
+			klog.Warningf("Failed to move agent secret to OS keyring: error=%v", err)
+		}
+	}
+
+	secret, err := credentials.Open(cfg.AgentSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg.AgentSecret = secret
+	return cfg, nil
+}
+
+// loadRawConfig loads the agent configuration as stored, without resolving
+// the agent secret
+func (m *Manager) loadRawConfig() (*Config, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return utils.LoadJSON[Config](m.ConfigPath())
 }
 
-// SaveConfig saves the agent configuration
+// SaveConfig saves the agent configuration, keeping the agent secret in the
+// OS keyring when one is available
 func (m *Manager) SaveConfig(cfg *Config) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -128,43 +162,55 @@ func (m *Manager) SaveConfig(cfg *Config) error {
 	if err := m.EnsureDirs(); err != nil {
 		return err
 	}
-	return utils.SaveJSON(m.ConfigPath(), cfg, 0600)
+	previous, _ := utils.LoadJSON[Config](m.ConfigPath())
+
+	stored := *cfg
+	stored.AgentSecret = credentials.Seal(m.agentSecretAccount(), cfg.AgentSecret)
+	if err := utils.SaveJSON(m.ConfigPath(), &stored, 0600); err != nil {
+		return err
+	}
+
+	// The secret went back to the file, e.g. with --no-keyring
+	if previous != nil && !credentials.IsRef(stored.AgentSecret) {
+		if err := credentials.Forget(previous.AgentSecret); err != nil {
+			klog.Warningf("Failed to remove agent secret from OS keyring: error=%v", err)
+		}
+	}
+	return nil
+}
+
+// agentSecretAccount names the keyring entry of the agent secret; it includes
+// the config directory so agents with separate configs don't share an entry
+func (m *Manager) agentSecretAccount() string {
+	return "agent-secret:" + m.configDir
 }
 
 // LoadGPUs loads GPU configurations
 func (m *Manager) LoadGPUs() ([]GPUConfig, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return utils.LoadJSONSlice[GPUConfig](m.GPUsPath())
+	store, err := m.Store()
+	if err != nil {
+		return nil, err
+	}
+	return store.LoadGPUs()
 }
 
 // SaveGPUs saves GPU configurations
 func (m *Manager) SaveGPUs(gpus []GPUConfig) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if err := m.EnsureDirs(); err != nil {
-		return err
-	}
-	return utils.SaveJSONSlice(m.GPUsPath(), gpus, 0644)
+	return m.Update(func(tx StoreTx) error { return tx.SaveGPUs(gpus) })
 }
 
 // LoadWorkers loads worker configurations
 func (m *Manager) LoadWorkers() ([]WorkerConfig, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return utils.LoadJSONSlice[WorkerConfig](m.WorkersPath())
+	store, err := m.Store()
+	if err != nil {
+		return nil, err
+	}
+	return store.LoadWorkers()
 }
 
 // SaveWorkers saves worker configurations
 func (m *Manager) SaveWorkers(workers []WorkerConfig) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if err := m.EnsureDirs(); err != nil {
-		return err
-	}
-	return utils.SaveJSONSlice(m.WorkersPath(), workers, 0644)
+	return m.Update(func(tx StoreTx) error { return tx.SaveWorkers(workers) })
 }
 
 // UpdateConfigVersion updates the config version and license
@@ -182,6 +228,22 @@ func (m *Manager) UpdateConfigVersion(version int, license api.License) error {
 	return m.SaveConfig(cfg)
 }
 
+// UpdateAgentSecret replaces the agent secret and returns the previous one so
+// the caller can restore it. The config file is replaced atomically.
+func (m *Manager) UpdateAgentSecret(secret string) (string, error) {
+	cfg, err := m.LoadConfig()
+	if err != nil {
+		return "", err
+	}
+	if cfg == nil {
+		return "", fmt.Errorf("agent not registered")
+	}
+
+	previous := cfg.AgentSecret
+	cfg.AgentSecret = secret
+	return previous, m.SaveConfig(cfg)
+}
+
 // GetConfigVersion returns the current config version
 func (m *Manager) GetConfigVersion() (int, error) {
 	cfg, err := m.LoadConfig()
@@ -215,15 +277,27 @@ func (m *Manager) IsRegistered() (bool, error) {
 	return true, nil
 }
 
-// RemoveConfig removes local agent configuration files (config, gpus, workers).
+// RemoveConfig removes local agent configuration files (config, gpus, workers,
+// the SQLite state store) and the agent secret in the OS keyring.
 // After this call IsRegistered returns false. The config directory itself is
 // left in place so that the caller (e.g. an uninstall script) can remove it.
 func (m *Manager) RemoveConfig() error {
+	if cfg, err := m.loadRawConfig(); err == nil && cfg != nil {
+		if err := credentials.Forget(cfg.AgentSecret); err != nil {
+			klog.Warningf("Failed to remove agent secret from OS keyring: error=%v", err)
+		}
+	}
+
+	if err := m.Close(); err != nil {
+		klog.Warningf("Failed to close state store: error=%v", err)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	var errs []error
-	for _, path := range []string{m.ConfigPath(), m.GPUsPath(), m.WorkersPath()} {
+	paths := append([]string{m.ConfigPath()}, m.storeFiles(StoreJSON)...)
+	for _, path := range append(paths, m.storeFiles(StoreSQLite)...) {
 		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 			errs = append(errs, err)
 		}
@@ -6,10 +6,38 @@ import (
 	"testing"
 
 	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/credentials"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// TestMain keeps the tests away from the developer's OS keyring
+func TestMain(m *testing.M) {
+	credentials.SetKeyring(nil)
+	os.Exit(m.Run())
+}
+
+// memKeyring is an in-memory OS keyring
+type memKeyring map[string]string
+
+func (k memKeyring) Get(account string) (string, error) {
+	secret, ok := k[account]
+	if !ok {
+		return "", credentials.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k memKeyring) Set(account, secret string) error {
+	k[account] = secret
+	return nil
+}
+
+func (k memKeyring) Delete(account string) error {
+	delete(k, account)
+	return nil
+}
+
 func TestManager_SaveAndLoadConfig(t *testing.T) {
 	tmpDir := t.TempDir()
 	configDir := filepath.Join(tmpDir, "config")
@@ -144,6 +172,67 @@ func TestManager_UpdateConfigVersion(t *testing.T) {
 	assert.Equal(t, "updated|pro|1768379729916", loaded.License.Plain)
 }
 
+func TestManager_UpdateAgentSecret(t *testing.T) {
+	mgr := NewManager(t.TempDir(), t.TempDir())
+
+	_, err := mgr.UpdateAgentSecret("new-secret")
+	assert.Error(t, err, "unregistered agents have no secret to replace")
+
+	require.NoError(t, mgr.SaveConfig(&Config{AgentID: "agent_1", AgentSecret: "old-secret"}))
+	previous, err := mgr.UpdateAgentSecret("new-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "old-secret", previous)
+
+	cfg, err := mgr.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "new-secret", cfg.AgentSecret)
+	assert.Equal(t, "agent_1", cfg.AgentID)
+}
+
+func TestManager_AgentSecretInKeyring(t *testing.T) {
+	mgr := NewManager(t.TempDir(), t.TempDir())
+	require.NoError(t, mgr.SaveConfig(&Config{AgentID: "agent_1", AgentSecret: "gpugo_plain_secret"}))
+
+	// A keyring showing up moves the plaintext secret there on load
+	keyring := memKeyring{}
+	defer credentials.SetKeyring(keyring)()
+
+	cfg, err := mgr.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "gpugo_plain_secret", cfg.AgentSecret)
+
+	data, err := os.ReadFile(mgr.ConfigPath())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "gpugo_plain_secret")
+	assert.Len(t, keyring, 1)
+
+	registered, err := mgr.IsRegistered()
+	require.NoError(t, err)
+	assert.True(t, registered)
+
+	require.NoError(t, mgr.RemoveConfig())
+	assert.Empty(t, keyring)
+}
+
+func TestManager_AgentSecretKeyringDisabled(t *testing.T) {
+	mgr := NewManager(t.TempDir(), t.TempDir())
+	keyring := memKeyring{}
+	defer credentials.SetKeyring(keyring)()
+	require.NoError(t, mgr.SaveConfig(&Config{AgentID: "agent_1", AgentSecret: "gpugo_plain_secret"}))
+	require.Len(t, keyring, 1)
+
+	// With the keyring disabled the secret is still readable and returns to
+	// the file on the next save
+	credentials.Disable()
+	_, err := mgr.UpdateAgentSecret("gpugo_new_secret")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(mgr.ConfigPath())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "gpugo_new_secret")
+	assert.Empty(t, keyring)
+}
+
 func TestManager_GetConfigVersion(t *testing.T) {
 	tmpDir := t.TempDir()
 	mgr := NewManager(tmpDir, tmpDir)
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"go.yaml.in/yaml/v3"
+	"k8s.io/klog/v2"
+)
+
+// ProjectDefaultsFile is the per-project defaults file looked up in the
+// working directory
+const ProjectDefaultsFile = "ggo.yaml"
+
+// Sources of a default, from the highest precedence to the lowest. A flag
+// given on the command line beats all of them.
+const (
+	SourceEnv     = "env"
+	SourceProject = "project"
+	SourceUser    = "user"
+)
+
+// Setting is a flag whose default can be set in ggo.yaml. The key is the flag
+// name, and the default applies to every command that has the flag.
+type Setting struct {
+	Key   string
+	Env   string
+	Usage string
+}
+
+// Settings lists the keys accepted in ggo.yaml
+var Settings = []Setting{
+	{Key: "server", Env: "GPU_GO_ENDPOINT", Usage: "Control plane URL"},
+	{Key: "output", Env: "GGO_OUTPUT", Usage: "Output format (table, json)"},
+	{Key: "mode", Env: "GGO_MODE", Usage: "Studio container/VM mode"},
+	{Key: "image", Env: "GGO_IMAGE", Usage: "Studio container image"},
+	{Key: "platform", Env: "GGO_PLATFORM", Usage: "Studio image platform"},
+	{Key: "docker-host", Env: "GGO_DOCKER_HOST", Usage: "Docker socket for studios"},
+	{Key: "colima-profile", Env: "GGO_COLIMA_PROFILE", Usage: "Colima profile for studios"},
+	{Key: "wsl-distro", Env: "GGO_WSL_DISTRO", Usage: "WSL distribution for studios"},
+}
+
+// LookupSetting returns the setting with the given key
+func LookupSetting(key string) (Setting, bool) {
+	idx := slices.IndexFunc(Settings, func(s Setting) bool { return s.Key == key })
+	if idx < 0 {
+		return Setting{}, false
+	}
+	return Settings[idx], true
+}
+
+// UserDefaultsPath returns the per-user defaults file,
+// $XDG_CONFIG_HOME/ggo/config.yaml or ~/.config/ggo/config.yaml
+func UserDefaultsPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = "."
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "ggo", "config.yaml")
+}
+
+// LoadDefaults reads a defaults file; a missing file yields empty defaults.
+// Unknown keys are ignored with a warning, so a project file written for a
+// newer ggo still works.
+func LoadDefaults(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	defaults := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if _, ok := LookupSetting(key); !ok {
+			klog.Warningf("Ignoring unknown setting in %s: %s", path, key)
+			continue
+		}
+		switch value.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("%s: %s must be a single value", path, key)
+		case nil:
+			continue
+		}
+		defaults[key] = fmt.Sprint(value)
+	}
+	return defaults, nil
+}
+
+// SaveDefaults writes a defaults file
+func SaveDefaults(path string, defaults map[string]string) error {
+	data, err := yaml.Marshal(defaults)
+	if err != nil {
+		return err
+	}
+	return utils.AtomicWriteFile(path, data, 0644)
+}
+
+// Defaults are the user and project defaults layered over each other, with
+// the environment taking precedence over both
+type Defaults struct {
+	UserPath    string
+	ProjectPath string
+	User        map[string]string
+	Project     map[string]string
+}
+
+// LoadLayeredDefaults reads the user defaults and the ggo.yaml in projectDir
+func LoadLayeredDefaults(projectDir string) (*Defaults, error) {
+	d := &Defaults{
+		UserPath:    UserDefaultsPath(),
+		ProjectPath: filepath.Join(projectDir, ProjectDefaultsFile),
+	}
+	var err error
+	if d.User, err = LoadDefaults(d.UserPath); err != nil {
+		return nil, err
+	}
+	if d.Project, err = LoadDefaults(d.ProjectPath); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Lookup returns the effective default for key and where it comes from
+func (d *Defaults) Lookup(key string) (value, source string, ok bool) {
+	setting, known := LookupSetting(key)
+	if !known {
+		return "", "", false
+	}
+	if value := os.Getenv(setting.Env); value != "" {
+		return value, SourceEnv, true
+	}
+	if value, ok := d.Project[key]; ok {
+		return value, SourceProject, true
+	}
+	if value, ok := d.User[key]; ok {
+		return value, SourceUser, true
+	}
+	return "", "", false
+}
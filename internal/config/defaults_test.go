@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaults_Precedence(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GPU_GO_ENDPOINT", "")
+	t.Setenv("GGO_MODE", "")
+
+	userPath := UserDefaultsPath()
+	assert.Equal(t, filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "ggo", "config.yaml"), userPath)
+	require.NoError(t, SaveDefaults(userPath, map[string]string{
+		"server":   "https://user.example.com",
+		"mode":     "colima",
+		"platform": "linux/arm64",
+	}))
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ProjectDefaultsFile),
+		[]byte("server: https://project.example.com\nmode: docker\nfuture-key: 1\n"), 0644))
+
+	d, err := LoadLayeredDefaults(projectDir)
+	require.NoError(t, err)
+
+	value, source, ok := d.Lookup("platform")
+	assert.True(t, ok)
+	assert.Equal(t, "linux/arm64", value)
+	assert.Equal(t, SourceUser, source)
+
+	value, source, _ = d.Lookup("server")
+	assert.Equal(t, "https://project.example.com", value)
+	assert.Equal(t, SourceProject, source)
+
+	t.Setenv("GGO_MODE", "wsl")
+	value, source, _ = d.Lookup("mode")
+	assert.Equal(t, "wsl", value)
+	assert.Equal(t, SourceEnv, source)
+
+	_, _, ok = d.Lookup("docker-host")
+	assert.False(t, ok)
+	_, _, ok = d.Lookup("future-key")
+	assert.False(t, ok, "unknown keys are ignored")
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir := t.TempDir()
+
+	d, err := LoadDefaults(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, d)
+
+	path := filepath.Join(dir, ProjectDefaultsFile)
+	require.NoError(t, os.WriteFile(path, []byte("server: [a, b]\n"), 0644))
+	_, err = LoadDefaults(path)
+	assert.ErrorContains(t, err, "must be a single value")
+
+	require.NoError(t, os.WriteFile(path, []byte("server: :\n  bad"), 0644))
+	_, err = LoadDefaults(path)
+	assert.Error(t, err)
+}
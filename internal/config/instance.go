@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+)
+
+const (
+	instancesFile = "agent-instances.json"
+	instancesDir  = "instances"
+)
+
+// Instance is one of several isolated agents on a host that is split between
+// teams. Each has its own config and state directories and may be pinned to a
+// subset of the host's GPUs.
+type Instance struct {
+	Name      string   `json:"name"`
+	ConfigDir string   `json:"config_dir"`
+	StateDir  string   `json:"state_dir"`
+	GPUs      []string `json:"gpus,omitempty"` // GPU UUIDs; empty means all GPUs
+}
+
+// Instances is the on-disk set of agent instances of a host
+type Instances struct {
+	Instances []Instance `json:"instances"`
+}
+
+// Get returns the instance with the given name, or nil
+func (s *Instances) Get(name string) *Instance {
+	for i := range s.Instances {
+		if s.Instances[i].Name == name {
+			return &s.Instances[i]
+		}
+	}
+	return nil
+}
+
+// Set adds an instance or replaces an existing one with the same name
+func (s *Instances) Set(inst Instance) {
+	if existing := s.Get(inst.Name); existing != nil {
+		*existing = inst
+		return
+	}
+	s.Instances = append(s.Instances, inst)
+}
+
+// Remove deletes an instance
+func (s *Instances) Remove(name string) bool {
+	idx := slices.IndexFunc(s.Instances, func(i Instance) bool { return i.Name == name })
+	if idx < 0 {
+		return false
+	}
+	s.Instances = slices.Delete(s.Instances, idx, idx+1)
+	return true
+}
+
+// Conflict returns an error when inst would share a directory or a GPU with
+// another instance
+func (s *Instances) Conflict(inst Instance) error {
+	for _, other := range s.Instances {
+		if other.Name == inst.Name {
+			continue
+		}
+		if samePath(other.ConfigDir, inst.ConfigDir) || samePath(other.ConfigDir, inst.StateDir) {
+			return fmt.Errorf("instance %s already uses directory %s", other.Name, other.ConfigDir)
+		}
+		if samePath(other.StateDir, inst.StateDir) || samePath(other.StateDir, inst.ConfigDir) {
+			return fmt.Errorf("instance %s already uses directory %s", other.Name, other.StateDir)
+		}
+		for _, gpu := range inst.GPUs {
+			if slices.ContainsFunc(other.GPUs, func(g string) bool { return strings.EqualFold(g, gpu) }) {
+				return fmt.Errorf("GPU %s is already assigned to instance %s", gpu, other.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func samePath(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// InstancesPath returns the path to the agent instances file
+func (m *Manager) InstancesPath() string {
+	return filepath.Join(m.configDir, instancesFile)
+}
+
+// InstanceDirs returns the default config and state directories of an
+// instance, below the host's own
+func (m *Manager) InstanceDirs(name string) (configDir, stateDir string) {
+	return filepath.Join(m.configDir, instancesDir, name), filepath.Join(m.stateDir, instancesDir, name)
+}
+
+// LoadInstances loads all agent instances, returning an empty set if none exist
+func (m *Manager) LoadInstances() (*Instances, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	instances, err := utils.LoadJSON[Instances](m.InstancesPath())
+	if err != nil {
+		return nil, err
+	}
+	if instances == nil {
+		return &Instances{}, nil
+	}
+	return instances, nil
+}
+
+// SaveInstances saves all agent instances
+func (m *Manager) SaveInstances(instances *Instances) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.EnsureDirs(); err != nil {
+		return err
+	}
+	return utils.SaveJSON(m.InstancesPath(), instances, 0644)
+}
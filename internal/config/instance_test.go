@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SaveAndLoadInstances(t *testing.T) {
+	mgr := NewManager(t.TempDir(), t.TempDir())
+
+	instances, err := mgr.LoadInstances()
+	require.NoError(t, err)
+	assert.Empty(t, instances.Instances)
+
+	configDir, stateDir := mgr.InstanceDirs("team-a")
+	instances.Set(Instance{Name: "team-a", ConfigDir: configDir, StateDir: stateDir, GPUs: []string{"GPU-aaa"}})
+	instances.Set(Instance{Name: "team-b", ConfigDir: "/srv/b/config", StateDir: "/srv/b/state"})
+	require.NoError(t, mgr.SaveInstances(instances))
+
+	loaded, err := mgr.LoadInstances()
+	require.NoError(t, err)
+	require.Len(t, loaded.Instances, 2)
+	assert.Equal(t, []string{"GPU-aaa"}, loaded.Get("team-a").GPUs)
+	assert.True(t, loaded.Remove("team-b"))
+	assert.False(t, loaded.Remove("team-b"))
+	assert.Nil(t, loaded.Get("team-b"))
+}
+
+func TestInstances_Conflict(t *testing.T) {
+	instances := &Instances{Instances: []Instance{
+		{Name: "team-a", ConfigDir: "/srv/a/config", StateDir: "/srv/a/state", GPUs: []string{"GPU-aaa"}},
+	}}
+
+	assert.NoError(t, instances.Conflict(Instance{Name: "team-b", ConfigDir: "/srv/b/config", StateDir: "/srv/b/state", GPUs: []string{"GPU-bbb"}}))
+	assert.NoError(t, instances.Conflict(Instance{Name: "team-a", ConfigDir: "/srv/a/config", StateDir: "/srv/a/state", GPUs: []string{"GPU-aaa"}}),
+		"an instance does not conflict with itself")
+
+	assert.ErrorContains(t, instances.Conflict(Instance{Name: "team-b", ConfigDir: "/srv/b/config", StateDir: "/srv/a/state/"}),
+		"instance team-a already uses directory /srv/a/state")
+	assert.ErrorContains(t, instances.Conflict(Instance{Name: "team-b", ConfigDir: "/srv/b/config", StateDir: "/srv/b/state", GPUs: []string{"gpu-AAA"}}),
+		"GPU gpu-AAA is already assigned to instance team-a")
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/NexusGPU/gpu-go/internal/credentials"
+	"github.com/NexusGPU/gpu-go/internal/errors"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	profilesFile = "profiles.json"
+	profilesDir  = "profiles"
+
+	// ProfileEnv selects the active profile when --profile is not given
+	ProfileEnv = "GGO_PROFILE"
+)
+
+// Profile is a named control plane endpoint with its own credentials
+type Profile struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	Token    string `json:"token,omitempty"`
+}
+
+// Profiles is the on-disk set of profiles and the currently selected one
+type Profiles struct {
+	Current  string    `json:"current,omitempty"`
+	Profiles []Profile `json:"profiles"`
+}
+
+// Get returns the profile with the given name, or nil
+func (p *Profiles) Get(name string) *Profile {
+	for i := range p.Profiles {
+		if p.Profiles[i].Name == name {
+			return &p.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// Set adds a profile or replaces an existing one with the same name
+func (p *Profiles) Set(profile Profile) {
+	if existing := p.Get(profile.Name); existing != nil {
+		*existing = profile
+		return
+	}
+	p.Profiles = append(p.Profiles, profile)
+}
+
+// Remove deletes a profile and clears the selection if it was current
+func (p *Profiles) Remove(name string) bool {
+	idx := slices.IndexFunc(p.Profiles, func(pr Profile) bool { return pr.Name == name })
+	if idx < 0 {
+		return false
+	}
+	p.Profiles = slices.Delete(p.Profiles, idx, idx+1)
+	if p.Current == name {
+		p.Current = ""
+	}
+	return true
+}
+
+// ProfilesPath returns the path to the profiles file
+func (m *Manager) ProfilesPath() string {
+	return filepath.Join(m.configDir, profilesFile)
+}
+
+// ProfileConfigDir returns the directory of a profile. State tied to the
+// profile's control plane (login token, cached release manifests) lives here;
+// machine-local state such as studios and the agent config stays global.
+func (m *Manager) ProfileConfigDir(name string) string {
+	return filepath.Join(m.configDir, profilesDir, name)
+}
+
+// LoadProfiles loads all profiles, returning an empty set if none exist.
+// Tokens are resolved from the OS keyring, and plaintext tokens are moved
+// there when one is available.
+func (m *Manager) LoadProfiles() (*Profiles, error) {
+	m.mu.RLock()
+	profiles, err := utils.LoadJSON[Profiles](m.ProfilesPath())
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if profiles == nil {
+		return &Profiles{}, nil
+	}
+
+	migrate := false
+	for i := range profiles.Profiles {
+		p := &profiles.Profiles[i]
+		migrate = migrate || credentials.NeedsMigration(p.Token)
+		token, err := credentials.Open(p.Token)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+		p.Token = token
+	}
+	if migrate {
+		if err := m.SaveProfiles(profiles); err != nil {
+			klog.Warningf("Failed to move profile tokens to OS keyring: error=%v", err)
+		}
+	}
+	return profiles, nil
+}
+
+// SaveProfiles saves all profiles. Tokens go to the OS keyring when one is
+// available; otherwise they stay in the file, which is owner-only.
+func (m *Manager) SaveProfiles(profiles *Profiles) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.EnsureDirs(); err != nil {
+		return err
+	}
+	previous, err := utils.LoadJSON[Profiles](m.ProfilesPath())
+	if err != nil || previous == nil {
+		previous = &Profiles{}
+	}
+
+	stored := &Profiles{Current: profiles.Current, Profiles: make([]Profile, len(profiles.Profiles))}
+	for i, p := range profiles.Profiles {
+		p.Token = credentials.Seal(m.profileTokenAccount(p.Name), p.Token)
+		stored.Profiles[i] = p
+	}
+	if err := utils.SaveJSON(m.ProfilesPath(), stored, 0600); err != nil {
+		return err
+	}
+
+	// Drop keyring entries no longer referenced: the profile was removed, lost
+	// its token or now keeps it in the file
+	for _, p := range previous.Profiles {
+		if current := stored.Get(p.Name); current == nil || !credentials.IsRef(current.Token) {
+			if err := credentials.Forget(p.Token); err != nil {
+				klog.Warningf("Failed to remove profile token from OS keyring: profile=%s error=%v", p.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// profileTokenAccount names the keyring entry of a profile's token
+func (m *Manager) profileTokenAccount(name string) string {
+	return "profile-token:" + m.ProfileConfigDir(name)
+}
+
+// ApplyProfile activates a profile for the current process by exporting its
+// endpoint, token and directory as environment variables, replacing any
+// values already there. An empty name falls back to GGO_PROFILE and then to
+// the saved current profile; the current profile is skipped when
+// GPU_GO_ENDPOINT is already set, so that an environment-selected endpoint is
+// never paired with the profile's credentials.
+func (m *Manager) ApplyProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = os.Getenv(ProfileEnv)
+	}
+
+	profiles, err := m.LoadProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profiles: %w", err)
+	}
+	if name == "" {
+		if os.Getenv("GPU_GO_ENDPOINT") != "" {
+			return nil, nil
+		}
+		name = profiles.Current
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	profile := profiles.Get(name)
+	if profile == nil {
+		return nil, errors.NotFound("profile", name)
+	}
+
+	_ = os.Setenv("GPU_GO_ENDPOINT", profile.Endpoint)
+	// A token from the environment belongs to whatever endpoint it was set
+	// for, so it is replaced even when the profile has none; commands then
+	// use the profile's own login token
+	if profile.Token != "" {
+		_ = os.Setenv("GPU_GO_TOKEN", profile.Token)
+	} else {
+		_ = os.Unsetenv("GPU_GO_TOKEN")
+		_ = os.Unsetenv("GPU_GO_USER_TOKEN")
+	}
+	_ = os.Setenv(ProfileEnv, profile.Name)
+	_ = os.Setenv(platform.ProfileDirEnv, m.ProfileConfigDir(profile.Name))
+	return profile, nil
+}
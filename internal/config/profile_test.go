@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/credentials"
+	"github.com/NexusGPU/gpu-go/internal/errors"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SaveAndLoadProfiles(t *testing.T) {
+	mgr := NewManager(t.TempDir(), t.TempDir())
+
+	profiles, err := mgr.LoadProfiles()
+	require.NoError(t, err)
+	assert.Empty(t, profiles.Profiles)
+
+	profiles.Set(Profile{Name: "staging", Endpoint: "https://staging.example.com", Token: "tok-1"})
+	profiles.Set(Profile{Name: "prod", Endpoint: "https://tensor-fusion.ai"})
+	profiles.Set(Profile{Name: "staging", Endpoint: "https://staging2.example.com", Token: "tok-2"})
+	profiles.Current = "staging"
+	require.NoError(t, mgr.SaveProfiles(profiles))
+
+	info, err := os.Stat(mgr.ProfilesPath())
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	loaded, err := mgr.LoadProfiles()
+	require.NoError(t, err)
+	require.Len(t, loaded.Profiles, 2)
+	assert.Equal(t, "https://staging2.example.com", loaded.Get("staging").Endpoint)
+	assert.Equal(t, "staging", loaded.Current)
+
+	assert.True(t, loaded.Remove("staging"))
+	assert.False(t, loaded.Remove("staging"))
+	assert.Empty(t, loaded.Current)
+}
+
+func TestManager_ProfileTokensInKeyring(t *testing.T) {
+	mgr := NewManager(t.TempDir(), t.TempDir())
+	keyring := memKeyring{}
+	defer credentials.SetKeyring(keyring)()
+
+	profiles := &Profiles{}
+	profiles.Set(Profile{Name: "staging", Endpoint: "https://staging.example.com", Token: "tok-staging"})
+	profiles.Set(Profile{Name: "prod", Endpoint: "https://tensor-fusion.ai", Token: "tok-prod"})
+	require.NoError(t, mgr.SaveProfiles(profiles))
+
+	data, err := os.ReadFile(mgr.ProfilesPath())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "tok-")
+	assert.Len(t, keyring, 2)
+
+	loaded, err := mgr.LoadProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, "tok-staging", loaded.Get("staging").Token)
+
+	require.True(t, loaded.Remove("staging"))
+	require.NoError(t, mgr.SaveProfiles(loaded))
+	assert.Len(t, keyring, 1)
+}
+
+func TestManager_ApplyProfile(t *testing.T) {
+	configDir := t.TempDir()
+	mgr := NewManager(configDir, t.TempDir())
+	require.NoError(t, mgr.SaveProfiles(&Profiles{
+		Current: "saved",
+		Profiles: []Profile{
+			{Name: "saved", Endpoint: "https://saved.example.com", Token: "saved-token"},
+			{Name: "staging", Endpoint: "https://staging.example.com", Token: "staging-token"},
+			{Name: "self-hosted", Endpoint: "https://gpu.example.com"},
+		},
+	}))
+
+	t.Run("current profile is skipped for an environment endpoint", func(t *testing.T) {
+		t.Setenv(ProfileEnv, "")
+		t.Setenv(platform.ProfileDirEnv, "")
+		t.Setenv("GPU_GO_ENDPOINT", "https://env.example.com")
+		t.Setenv("GPU_GO_TOKEN", "env-token")
+
+		profile, err := mgr.ApplyProfile("")
+		require.NoError(t, err)
+		assert.Nil(t, profile)
+		assert.Equal(t, "https://env.example.com", os.Getenv("GPU_GO_ENDPOINT"))
+		assert.Equal(t, "env-token", os.Getenv("GPU_GO_TOKEN"))
+		assert.Empty(t, os.Getenv(platform.ProfileDirEnv))
+	})
+
+	t.Run("current profile", func(t *testing.T) {
+		t.Setenv(ProfileEnv, "")
+		t.Setenv(platform.ProfileDirEnv, "")
+		t.Setenv("GPU_GO_ENDPOINT", "")
+		t.Setenv("GPU_GO_TOKEN", "")
+
+		profile, err := mgr.ApplyProfile("")
+		require.NoError(t, err)
+		require.NotNil(t, profile)
+		assert.Equal(t, "saved", profile.Name)
+		assert.Equal(t, "https://saved.example.com", os.Getenv("GPU_GO_ENDPOINT"))
+		assert.Equal(t, "saved-token", os.Getenv("GPU_GO_TOKEN"))
+		assert.Equal(t, "saved", os.Getenv(ProfileEnv))
+		assert.Equal(t, filepath.Join(configDir, "profiles", "saved"), os.Getenv(platform.ProfileDirEnv))
+	})
+
+	t.Run("explicit profile overrides environment", func(t *testing.T) {
+		t.Setenv(ProfileEnv, "")
+		t.Setenv(platform.ProfileDirEnv, "")
+		t.Setenv("GPU_GO_ENDPOINT", "https://env.example.com")
+		t.Setenv("GPU_GO_TOKEN", "env-token")
+
+		_, err := mgr.ApplyProfile("staging")
+		require.NoError(t, err)
+		assert.Equal(t, "https://staging.example.com", os.Getenv("GPU_GO_ENDPOINT"))
+		assert.Equal(t, "staging-token", os.Getenv("GPU_GO_TOKEN"))
+	})
+
+	t.Run("profile without token drops environment token", func(t *testing.T) {
+		t.Setenv(ProfileEnv, "")
+		t.Setenv(platform.ProfileDirEnv, "")
+		t.Setenv("GPU_GO_ENDPOINT", "")
+		t.Setenv("GPU_GO_TOKEN", "saas-token")
+
+		_, err := mgr.ApplyProfile("self-hosted")
+		require.NoError(t, err)
+		assert.Equal(t, "https://gpu.example.com", os.Getenv("GPU_GO_ENDPOINT"))
+		assert.Empty(t, os.Getenv("GPU_GO_TOKEN"))
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := mgr.ApplyProfile("missing")
+		assert.ErrorIs(t, err, errors.ErrNotFound)
+	})
+}
@@ -0,0 +1,6 @@
+//go:build !nosqlite
+
+package config
+
+// The CGO-free SQLite driver; it registers itself as "sqlite"
+import _ "modernc.org/sqlite"
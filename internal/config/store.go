@@ -0,0 +1,326 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+// State store backends
+const (
+	// StoreJSON keeps GPUs and workers in gpus.json and workers.json
+	StoreJSON = "json"
+	// StoreSQLite keeps GPUs, workers and their history in an embedded
+	// SQLite database
+	StoreSQLite = "sqlite"
+
+	stateDBFile = "state.db"
+	// migratedSuffix is appended to the files of a backend whose state moved
+	// to another one; they are kept as a backup
+	migratedSuffix = ".migrated"
+)
+
+// GPU change kinds recorded in the GPU history
+const (
+	GPUAdded   = "added"
+	GPUChanged = "changed"
+	GPURemoved = "removed"
+)
+
+// ErrNoHistory is returned when history is requested from a backend that does
+// not keep it
+var ErrNoHistory = errors.New("history is only kept by the sqlite state store (ggo agent start --state-store sqlite)")
+
+// Store persists the GPUs and workers of the agent. Reads outside Update see
+// the latest committed state.
+type Store interface {
+	// Backend names the implementation, StoreJSON or StoreSQLite
+	Backend() string
+	LoadGPUs() ([]GPUConfig, error)
+	LoadWorkers() ([]WorkerConfig, error)
+	// Update runs fn in a transaction: its writes are kept if it returns nil
+	// and discarded otherwise
+	Update(fn func(tx StoreTx) error) error
+	// WorkerRestarts returns the restarts of a worker, or of all workers for
+	// an empty ID, newest first; limit <= 0 returns all of them
+	WorkerRestarts(workerID string, limit int) ([]WorkerRestart, error)
+	// GPUChanges returns the changes of a GPU, or of all GPUs for an empty
+	// ID, newest first; limit <= 0 returns all of them
+	GPUChanges(gpuID string, limit int) ([]GPUChange, error)
+	Close() error
+}
+
+// StoreTx reads and writes the state within Store.Update
+type StoreTx interface {
+	LoadGPUs() ([]GPUConfig, error)
+	SaveGPUs(gpus []GPUConfig) error
+	LoadWorkers() ([]WorkerConfig, error)
+	SaveWorkers(workers []WorkerConfig) error
+	AddWorkerRestart(restart WorkerRestart) error
+	AddGPUChanges(changes []GPUChange) error
+}
+
+// WorkerRestart records a worker process replaced by a new one
+type WorkerRestart struct {
+	WorkerID    string    `json:"worker_id"`
+	At          time.Time `json:"at"`
+	PID         int       `json:"pid"`
+	PreviousPID int       `json:"previous_pid"`
+	// Restarts is the restart count reported by the backend
+	Restarts int `json:"restarts"`
+	// Crashed is set when the previous process exited abnormally
+	Crashed bool `json:"crashed"`
+}
+
+// GPUChange records a GPU that appeared, disappeared or changed properties
+type GPUChange struct {
+	GPUID         string    `json:"gpu_id"`
+	At            time.Time `json:"at"`
+	Change        string    `json:"change"`
+	GPUIndex      int       `json:"gpu_index"`
+	Vendor        string    `json:"vendor,omitempty"`
+	Model         string    `json:"model,omitempty"`
+	VRAMMb        int64     `json:"vram_mb,omitempty"`
+	DriverVersion string    `json:"driver_version,omitempty"`
+	CUDAVersion   string    `json:"cuda_version,omitempty"`
+}
+
+// ParseStoreBackend validates a state store backend name
+func ParseStoreBackend(name string) (string, error) {
+	switch name {
+	case StoreJSON, StoreSQLite:
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown state store %q (expected %s or %s)", name, StoreJSON, StoreSQLite)
+	}
+}
+
+// StateDBPath returns the path of the SQLite state store
+func (m *Manager) StateDBPath() string {
+	return filepath.Join(m.stateDir, stateDBFile)
+}
+
+// Store returns the state store, opening it on first use. The SQLite store is
+// used once its database exists, and the JSON files otherwise.
+func (m *Manager) Store() (Store, error) {
+	m.storeMu.Lock()
+	defer m.storeMu.Unlock()
+	if m.store != nil {
+		return m.store, nil
+	}
+	backend := StoreJSON
+	if _, err := os.Stat(m.StateDBPath()); err == nil {
+		backend = StoreSQLite
+	}
+	store, err := m.openStore(backend)
+	if err != nil {
+		return nil, err
+	}
+	m.store = store
+	return store, nil
+}
+
+// UseStore switches the state store to backend, moving the GPUs, workers and,
+// between SQLite stores, the history from the current one. The files of the
+// previous backend are renamed with a .migrated suffix.
+func (m *Manager) UseStore(backend string) error {
+	current, err := m.Store()
+	if err != nil {
+		return err
+	}
+	if current.Backend() == backend {
+		return nil
+	}
+
+	m.storeMu.Lock()
+	defer m.storeMu.Unlock()
+	if err := m.EnsureDirs(); err != nil {
+		return err
+	}
+	next, err := m.openStore(backend)
+	if err != nil {
+		return err
+	}
+	if err := migrateStore(current, next); err != nil {
+		_ = next.Close()
+		return fmt.Errorf("failed to migrate state from %s to %s: %w", current.Backend(), backend, err)
+	}
+	if err := current.Close(); err != nil {
+		klog.Warningf("Failed to close state store: backend=%s error=%v", current.Backend(), err)
+	}
+	for _, path := range m.storeFiles(current.Backend()) {
+		if err := os.Rename(path, path+migratedSuffix); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("Failed to set aside migrated state: path=%s error=%v", path, err)
+		}
+	}
+	m.store = next
+	klog.Infof("State store migrated: from=%s to=%s", current.Backend(), backend)
+	return nil
+}
+
+// Update runs fn in a transaction of the state store
+func (m *Manager) Update(fn func(tx StoreTx) error) error {
+	store, err := m.Store()
+	if err != nil {
+		return err
+	}
+	return store.Update(fn)
+}
+
+// Close closes the state store; it is reopened on next use
+func (m *Manager) Close() error {
+	m.storeMu.Lock()
+	defer m.storeMu.Unlock()
+	if m.store == nil {
+		return nil
+	}
+	err := m.store.Close()
+	m.store = nil
+	return err
+}
+
+func (m *Manager) openStore(backend string) (Store, error) {
+	switch backend {
+	case StoreSQLite:
+		return openSQLiteStore(m.StateDBPath())
+	default:
+		return &jsonStore{m: m}, nil
+	}
+}
+
+// storeFiles returns the files holding the state of a backend
+func (m *Manager) storeFiles(backend string) []string {
+	if backend == StoreSQLite {
+		path := m.StateDBPath()
+		return []string{path, path + "-wal", path + "-shm"}
+	}
+	return []string{m.GPUsPath(), m.WorkersPath()}
+}
+
+// migrateStore copies the state of src into dst in one transaction
+func migrateStore(src, dst Store) error {
+	gpus, err := src.LoadGPUs()
+	if err != nil {
+		return err
+	}
+	workers, err := src.LoadWorkers()
+	if err != nil {
+		return err
+	}
+	restarts, err := src.WorkerRestarts("", 0)
+	if err != nil && !errors.Is(err, ErrNoHistory) {
+		return err
+	}
+	changes, err := src.GPUChanges("", 0)
+	if err != nil && !errors.Is(err, ErrNoHistory) {
+		return err
+	}
+	return dst.Update(func(tx StoreTx) error {
+		if err := tx.SaveGPUs(gpus); err != nil {
+			return err
+		}
+		if err := tx.SaveWorkers(workers); err != nil {
+			return err
+		}
+		// History is listed newest first; insert it oldest first
+		for i := len(restarts) - 1; i >= 0; i-- {
+			if err := tx.AddWorkerRestart(restarts[i]); err != nil {
+				return err
+			}
+		}
+		for i := len(changes) - 1; i >= 0; i-- {
+			if err := tx.AddGPUChanges(changes[i : i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// jsonStore keeps the state in gpus.json and workers.json. Updates are
+// serialized within the process and each file is replaced atomically, but an
+// update writing both files is not atomic across them. History is not kept.
+type jsonStore struct {
+	m *Manager
+	// updateMu serializes updates so read-modify-write cycles don't interleave
+	updateMu sync.Mutex
+}
+
+func (s *jsonStore) Backend() string {
+	return StoreJSON
+}
+
+func (s *jsonStore) LoadGPUs() ([]GPUConfig, error) {
+	s.m.mu.RLock()
+	defer s.m.mu.RUnlock()
+	return utils.LoadJSONSlice[GPUConfig](s.m.GPUsPath())
+}
+
+func (s *jsonStore) LoadWorkers() ([]WorkerConfig, error) {
+	s.m.mu.RLock()
+	defer s.m.mu.RUnlock()
+	return utils.LoadJSONSlice[WorkerConfig](s.m.WorkersPath())
+}
+
+func (s *jsonStore) Update(fn func(tx StoreTx) error) error {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+	return fn(jsonTx{s})
+}
+
+func (s *jsonStore) WorkerRestarts(string, int) ([]WorkerRestart, error) {
+	return nil, ErrNoHistory
+}
+
+func (s *jsonStore) GPUChanges(string, int) ([]GPUChange, error) {
+	return nil, ErrNoHistory
+}
+
+func (s *jsonStore) Close() error {
+	return nil
+}
+
+// jsonTx writes through to the files of a jsonStore
+type jsonTx struct {
+	s *jsonStore
+}
+
+func (tx jsonTx) LoadGPUs() ([]GPUConfig, error) {
+	return tx.s.LoadGPUs()
+}
+
+func (tx jsonTx) SaveGPUs(gpus []GPUConfig) error {
+	return tx.save(tx.s.m.GPUsPath(), func(path string) error { return utils.SaveJSONSlice(path, gpus, 0644) })
+}
+
+func (tx jsonTx) LoadWorkers() ([]WorkerConfig, error) {
+	return tx.s.LoadWorkers()
+}
+
+func (tx jsonTx) SaveWorkers(workers []WorkerConfig) error {
+	return tx.save(tx.s.m.WorkersPath(), func(path string) error { return utils.SaveJSONSlice(path, workers, 0644) })
+}
+
+func (tx jsonTx) save(path string, write func(path string) error) error {
+	m := tx.s.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.EnsureDirs(); err != nil {
+		return err
+	}
+	return write(path)
+}
+
+func (tx jsonTx) AddWorkerRestart(WorkerRestart) error {
+	return nil
+}
+
+func (tx jsonTx) AddGPUChanges([]GPUChange) error {
+	return nil
+}
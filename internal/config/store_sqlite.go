@@ -0,0 +1,276 @@
+package config
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// sqliteDriver is the database/sql driver of the SQLite store, registered by
+// modernc.org/sqlite unless built with the nosqlite tag
+const sqliteDriver = "sqlite"
+
+// errNoSQLite is returned when the SQLite store is used by a build without
+// the driver
+var errNoSQLite = errors.New("this build of ggo has no SQLite support (built with the nosqlite tag)")
+
+// sqliteMigrations are applied in order; PRAGMA user_version counts those
+// already applied. Never edit an entry, append a new one.
+var sqliteMigrations = []string{
+	`CREATE TABLE gpus (
+		gpu_id   TEXT PRIMARY KEY,
+		position INTEGER NOT NULL,
+		data     TEXT NOT NULL
+	);
+	CREATE TABLE workers (
+		worker_id TEXT PRIMARY KEY,
+		position  INTEGER NOT NULL,
+		data      TEXT NOT NULL
+	);
+	CREATE TABLE worker_restarts (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		worker_id    TEXT NOT NULL,
+		at           INTEGER NOT NULL,
+		pid          INTEGER NOT NULL,
+		previous_pid INTEGER NOT NULL,
+		restarts     INTEGER NOT NULL,
+		crashed      INTEGER NOT NULL
+	);
+	CREATE INDEX worker_restarts_worker ON worker_restarts (worker_id, id);
+	CREATE TABLE gpu_changes (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		gpu_id         TEXT NOT NULL,
+		at             INTEGER NOT NULL,
+		change         TEXT NOT NULL,
+		gpu_index      INTEGER NOT NULL,
+		vendor         TEXT NOT NULL,
+		model          TEXT NOT NULL,
+		vram_mb        INTEGER NOT NULL,
+		driver_version TEXT NOT NULL,
+		cuda_version   TEXT NOT NULL
+	);
+	CREATE INDEX gpu_changes_gpu ON gpu_changes (gpu_id, id);`,
+}
+
+// sqliteStore keeps the state in an SQLite database. GPUs and workers are
+// rows holding their JSON encoding, so new fields need no migration; history
+// is kept in worker_restarts and gpu_changes.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	if !slices.Contains(sql.Drivers(), sqliteDriver) {
+		return nil, errNoSQLite
+	}
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		return nil, err
+	}
+	// One connection keeps the pragmas in effect and serializes writers of
+	// this process; other processes wait on the busy timeout
+	db.SetMaxOpenConns(1)
+	s := &sqliteStore{db: db}
+	if err := s.init(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open state store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *sqliteStore) init() error {
+	for _, pragma := range []string{"PRAGMA busy_timeout = 5000", "PRAGMA journal_mode = WAL"} {
+		if _, err := s.db.Exec(pragma); err != nil {
+			return err
+		}
+	}
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(sqliteMigrations) {
+		return fmt.Errorf("schema version %d is newer than this ggo supports (%d)", version, len(sqliteMigrations))
+	}
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Backend() string {
+	return StoreSQLite
+}
+
+func (s *sqliteStore) LoadGPUs() ([]GPUConfig, error) {
+	return loadRows[GPUConfig](s.db, "SELECT data FROM gpus ORDER BY position")
+}
+
+func (s *sqliteStore) LoadWorkers() ([]WorkerConfig, error) {
+	return loadRows[WorkerConfig](s.db, "SELECT data FROM workers ORDER BY position")
+}
+
+func (s *sqliteStore) Update(fn func(tx StoreTx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(sqliteTx{tx}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) WorkerRestarts(workerID string, limit int) ([]WorkerRestart, error) {
+	rows, err := s.db.Query(`SELECT worker_id, at, pid, previous_pid, restarts, crashed FROM worker_restarts
+		WHERE ? = '' OR worker_id = ? ORDER BY id DESC LIMIT ?`, workerID, workerID, sqlLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var restarts []WorkerRestart
+	for rows.Next() {
+		var r WorkerRestart
+		var at int64
+		if err := rows.Scan(&r.WorkerID, &at, &r.PID, &r.PreviousPID, &r.Restarts, &r.Crashed); err != nil {
+			return nil, err
+		}
+		r.At = time.Unix(0, at)
+		restarts = append(restarts, r)
+	}
+	return restarts, rows.Err()
+}
+
+func (s *sqliteStore) GPUChanges(gpuID string, limit int) ([]GPUChange, error) {
+	rows, err := s.db.Query(`SELECT gpu_id, at, change, gpu_index, vendor, model, vram_mb, driver_version, cuda_version FROM gpu_changes
+		WHERE ? = '' OR gpu_id = ? ORDER BY id DESC LIMIT ?`, gpuID, gpuID, sqlLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var changes []GPUChange
+	for rows.Next() {
+		var c GPUChange
+		var at int64
+		if err := rows.Scan(&c.GPUID, &at, &c.Change, &c.GPUIndex, &c.Vendor, &c.Model, &c.VRAMMb, &c.DriverVersion, &c.CUDAVersion); err != nil {
+			return nil, err
+		}
+		c.At = time.Unix(0, at)
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// sqlLimit maps a limit of zero or less to SQLite's "no limit"
+func sqlLimit(limit int) int {
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
+
+// querier is implemented by *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// loadRows decodes the JSON data column of each row
+func loadRows[T any](q querier, query string) ([]T, error) {
+	rows, err := q.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []T
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var item T
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+// sqliteTx implements StoreTx within a database transaction
+type sqliteTx struct {
+	tx *sql.Tx
+}
+
+func (t sqliteTx) LoadGPUs() ([]GPUConfig, error) {
+	return loadRows[GPUConfig](t.tx, "SELECT data FROM gpus ORDER BY position")
+}
+
+func (t sqliteTx) SaveGPUs(gpus []GPUConfig) error {
+	return replaceRows(t.tx, "gpus", "gpu_id", gpus, func(g GPUConfig) string { return g.GPUID })
+}
+
+func (t sqliteTx) LoadWorkers() ([]WorkerConfig, error) {
+	return loadRows[WorkerConfig](t.tx, "SELECT data FROM workers ORDER BY position")
+}
+
+func (t sqliteTx) SaveWorkers(workers []WorkerConfig) error {
+	return replaceRows(t.tx, "workers", "worker_id", workers, func(w WorkerConfig) string { return w.WorkerID })
+}
+
+func (t sqliteTx) AddWorkerRestart(r WorkerRestart) error {
+	_, err := t.tx.Exec(`INSERT INTO worker_restarts (worker_id, at, pid, previous_pid, restarts, crashed) VALUES (?, ?, ?, ?, ?, ?)`,
+		r.WorkerID, r.At.UnixNano(), r.PID, r.PreviousPID, r.Restarts, r.Crashed)
+	return err
+}
+
+func (t sqliteTx) AddGPUChanges(changes []GPUChange) error {
+	for _, c := range changes {
+		if _, err := t.tx.Exec(`INSERT INTO gpu_changes (gpu_id, at, change, gpu_index, vendor, model, vram_mb, driver_version, cuda_version)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			c.GPUID, c.At.UnixNano(), c.Change, c.GPUIndex, c.Vendor, c.Model, c.VRAMMb, c.DriverVersion, c.CUDAVersion); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceRows replaces the contents of table with items, keeping their order
+func replaceRows[T any](tx *sql.Tx, table, keyColumn string, items []T, key func(T) string) error {
+	if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+		return err
+	}
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO "+table+" ("+keyColumn+", position, data) VALUES (?, ?, ?)", key(item), i, string(data)); err != nil {
+			return fmt.Errorf("failed to store %s %q: %w", table, key(item), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"database/sql"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	if !slices.Contains(sql.Drivers(), sqliteDriver) {
+		t.Skip("built with the nosqlite tag")
+	}
+	mgr := NewManager(t.TempDir(), t.TempDir())
+	gpus := []GPUConfig{{GPUID: "gpu-1", GPUIndex: 1}, {GPUID: "gpu-0"}}
+	workers := []WorkerConfig{{WorkerID: "w1", GPUIDs: []string{"gpu-0"}, ListenPort: 9001, Enabled: true}}
+	require.NoError(t, mgr.SaveGPUs(gpus))
+	require.NoError(t, mgr.SaveWorkers(workers))
+
+	require.NoError(t, mgr.UseStore(StoreSQLite))
+	assert.NoFileExists(t, mgr.GPUsPath())
+	assert.FileExists(t, mgr.GPUsPath()+migratedSuffix, "the JSON files are kept as a backup")
+
+	// A new manager picks up the database
+	mgr = NewManager(mgr.ConfigDir(), mgr.StateDir())
+	store, err := mgr.Store()
+	require.NoError(t, err)
+	assert.Equal(t, StoreSQLite, store.Backend())
+	loaded, err := mgr.LoadGPUs()
+	require.NoError(t, err)
+	assert.Equal(t, gpus, loaded, "order is kept")
+	loadedWorkers, err := mgr.LoadWorkers()
+	require.NoError(t, err)
+	assert.Equal(t, workers, loadedWorkers)
+
+	// A failed update leaves no trace
+	err = mgr.Update(func(tx StoreTx) error {
+		if err := tx.SaveWorkers(nil); err != nil {
+			return err
+		}
+		return os.ErrInvalid
+	})
+	assert.ErrorIs(t, err, os.ErrInvalid)
+	loadedWorkers, err = mgr.LoadWorkers()
+	require.NoError(t, err)
+	assert.Len(t, loadedWorkers, 1)
+
+	at := time.Now().Truncate(time.Millisecond)
+	require.NoError(t, mgr.Update(func(tx StoreTx) error {
+		if err := tx.AddWorkerRestart(WorkerRestart{WorkerID: "w1", At: at, PID: 11, PreviousPID: 10, Restarts: 1, Crashed: true}); err != nil {
+			return err
+		}
+		if err := tx.AddWorkerRestart(WorkerRestart{WorkerID: "w2", At: at, PID: 21, PreviousPID: 20}); err != nil {
+			return err
+		}
+		return tx.AddGPUChanges([]GPUChange{{GPUID: "gpu-0", At: at, Change: GPUChanged, DriverVersion: "560"}})
+	}))
+	restarts, err := store.WorkerRestarts("w1", 0)
+	require.NoError(t, err)
+	require.Len(t, restarts, 1)
+	assert.True(t, restarts[0].Crashed)
+	assert.True(t, at.Equal(restarts[0].At))
+	restarts, err = store.WorkerRestarts("", 1)
+	require.NoError(t, err)
+	require.Len(t, restarts, 1)
+	assert.Equal(t, "w2", restarts[0].WorkerID, "newest first")
+	changes, err := store.GPUChanges("gpu-0", 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "560", changes[0].DriverVersion)
+
+	// Back to JSON
+	require.NoError(t, mgr.UseStore(StoreJSON))
+	assert.NoFileExists(t, mgr.StateDBPath())
+	loaded, err = NewManager(mgr.ConfigDir(), mgr.StateDir()).LoadGPUs()
+	require.NoError(t, err)
+	assert.Equal(t, gpus, loaded)
+	require.NoError(t, mgr.Close())
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONStore(t *testing.T) {
+	mgr := NewManager(t.TempDir(), t.TempDir())
+	store, err := mgr.Store()
+	require.NoError(t, err)
+	assert.Equal(t, StoreJSON, store.Backend())
+
+	require.NoError(t, mgr.SaveGPUs([]GPUConfig{{GPUID: "gpu-0"}}))
+	err = mgr.Update(func(tx StoreTx) error {
+		workers, err := tx.LoadWorkers()
+		if err != nil {
+			return err
+		}
+		return tx.SaveWorkers(append(workers, WorkerConfig{WorkerID: "w1", ListenPort: 9001}))
+	})
+	require.NoError(t, err)
+
+	workers, err := mgr.LoadWorkers()
+	require.NoError(t, err)
+	assert.Equal(t, []WorkerConfig{{WorkerID: "w1", ListenPort: 9001}}, workers)
+	assert.FileExists(t, mgr.WorkersPath(), "the JSON files stay the source of truth")
+
+	require.NoError(t, mgr.Update(func(tx StoreTx) error { return tx.AddWorkerRestart(WorkerRestart{WorkerID: "w1"}) }))
+	_, err = store.WorkerRestarts("", 0)
+	assert.ErrorIs(t, err, ErrNoHistory)
+
+	fail := errors.New("fail")
+	assert.ErrorIs(t, mgr.Update(func(StoreTx) error { return fail }), fail)
+}
+
+func TestUseStoreWithoutDriver(t *testing.T) {
+	if _, err := openSQLiteStore(""); !errors.Is(err, errNoSQLite) {
+		t.Skip("built with SQLite support")
+	}
+	mgr := NewManager(t.TempDir(), t.TempDir())
+	require.NoError(t, mgr.SaveGPUs([]GPUConfig{{GPUID: "gpu-0"}}))
+
+	assert.ErrorIs(t, mgr.UseStore(StoreSQLite), errNoSQLite)
+	gpus, err := mgr.LoadGPUs()
+	require.NoError(t, err)
+	assert.Len(t, gpus, 1, "the JSON state is kept when the switch fails")
+
+	// An existing database is not silently ignored
+	require.NoError(t, os.WriteFile(mgr.StateDBPath(), nil, 0644))
+	_, err = NewManager(mgr.ConfigDir(), mgr.StateDir()).LoadGPUs()
+	assert.ErrorIs(t, err, errNoSQLite)
+
+	_, err = ParseStoreBackend("bolt")
+	assert.ErrorContains(t, err, "unknown state store")
+}
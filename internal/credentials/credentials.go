@@ -0,0 +1,190 @@
+// Package credentials keeps secrets such as the agent secret and login tokens
+// out of plaintext config files by storing them in the OS keyring: the macOS
+// Keychain, the Windows Credential Manager or a libsecret Secret Service on
+// Linux. Config files then hold a reference to the keyring entry instead of
+// the secret.
+//
+// Hosts without a usable keyring, typically headless servers, keep secrets in
+// the owner-only config files as before.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Service is the keyring service ggo stores its secrets under
+	Service = "gpu-go"
+
+	// NoKeyringEnv disables the OS keyring when set to 1 or true, like --no-keyring
+	NoKeyringEnv = "GGO_NO_KEYRING"
+
+	// refPrefix marks a config value that refers to a keyring entry
+	refPrefix = "keyring:"
+)
+
+var (
+	// ErrNotFound is returned when the keyring has no entry for an account
+	ErrNotFound = errors.New("credential not found in OS keyring")
+	// ErrUnavailable is returned when a secret is in the OS keyring but the
+	// keyring cannot be used from this process
+	ErrUnavailable = errors.New("OS keyring is not available")
+)
+
+// Keyring is an OS credential store holding secrets by account name
+type Keyring interface {
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+var (
+	mu       sync.Mutex
+	disabled bool
+	probed   bool
+	keyring  Keyring
+)
+
+// Disable keeps new secrets out of the OS keyring for this process. Secrets
+// already in the keyring can still be read, so configs move back to plaintext
+// files the next time they are saved.
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	disabled = true
+}
+
+// SetKeyring replaces the OS keyring, for tests. Nil means no keyring.
+// The returned function restores the previous one.
+func SetKeyring(k Keyring) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	prevKeyring, prevProbed, prevDisabled := keyring, probed, disabled
+	keyring, probed, disabled = k, true, false
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		keyring, probed, disabled = prevKeyring, prevProbed, prevDisabled
+	}
+}
+
+// osKeyring returns the platform keyring, or nil if there is none usable
+func osKeyring() Keyring {
+	mu.Lock()
+	defer mu.Unlock()
+	if !probed {
+		keyring = newOSKeyring()
+		probed = true
+	}
+	return keyring
+}
+
+// storeKeyring returns the keyring new secrets go to, or nil when secrets
+// stay in config files
+func storeKeyring() Keyring {
+	mu.Lock()
+	off := disabled
+	mu.Unlock()
+	if off {
+		return nil
+	}
+	switch strings.ToLower(os.Getenv(NoKeyringEnv)) {
+	case "1", "true":
+		return nil
+	}
+	return osKeyring()
+}
+
+// Enabled reports whether new secrets are stored in the OS keyring
+func Enabled() bool {
+	return storeKeyring() != nil
+}
+
+// IsRef reports whether a config value refers to a keyring entry
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, refPrefix)
+}
+
+// Seal stores secret in the OS keyring under account and returns the value to
+// write to the config file: a reference to the entry, or the secret itself
+// when the keyring is disabled or unusable
+func Seal(account, secret string) string {
+	if secret == "" || IsRef(secret) {
+		return secret
+	}
+	k := storeKeyring()
+	if k == nil {
+		return secret
+	}
+	if err := k.Set(account, secret); err != nil {
+		klog.Warningf("Failed to store credential in OS keyring, keeping it in the config file: account=%s error=%v", account, err)
+		return secret
+	}
+	return refPrefix + account
+}
+
+// Open resolves a value read from a config file to the secret, looking up
+// references in the OS keyring
+func Open(value string) (string, error) {
+	account, ok := strings.CutPrefix(value, refPrefix)
+	if !ok {
+		return value, nil
+	}
+	k := osKeyring()
+	if k == nil {
+		return "", fmt.Errorf("%w: credential %s is stored in it", ErrUnavailable, account)
+	}
+	secret, err := k.Get(account)
+	if err != nil {
+		return "", fmt.Errorf("failed to read credential %s from OS keyring: %w", account, err)
+	}
+	return secret, nil
+}
+
+// NeedsMigration reports whether a value read from a config file is a
+// plaintext secret that would now be moved to the OS keyring
+func NeedsMigration(value string) bool {
+	return value != "" && !IsRef(value) && Enabled()
+}
+
+// Forget deletes the keyring entry a config value refers to; plaintext values
+// are left alone
+func Forget(value string) error {
+	account, ok := strings.CutPrefix(value, refPrefix)
+	if !ok {
+		return nil
+	}
+	k := osKeyring()
+	if k == nil {
+		return fmt.Errorf("%w: credential %s is stored in it", ErrUnavailable, account)
+	}
+	if err := k.Delete(account); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Location describes where a config value keeps its secret, for display
+func Location(value string) string {
+	if IsRef(value) {
+		return "OS keyring"
+	}
+	return "config file"
+}
+
+// Redact masks a secret for display, keeping just enough to tell secrets apart
+func Redact(secret string) string {
+	if len(secret) <= 12 {
+		if secret == "" {
+			return ""
+		}
+		return "****"
+	}
+	return secret[:8] + "..." + secret[len(secret)-4:]
+}
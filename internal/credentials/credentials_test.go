@@ -0,0 +1,145 @@
+package credentials
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKeyring struct {
+	secrets map[string]string
+	setErr  error
+}
+
+func newFakeKeyring() *fakeKeyring {
+	return &fakeKeyring{secrets: map[string]string{}}
+}
+
+func (f *fakeKeyring) Get(account string) (string, error) {
+	secret, ok := f.secrets[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (f *fakeKeyring) Set(account, secret string) error {
+	if f.setErr != nil {
+		return f.setErr
+	}
+	f.secrets[account] = secret
+	return nil
+}
+
+func (f *fakeKeyring) Delete(account string) error {
+	if _, ok := f.secrets[account]; !ok {
+		return ErrNotFound
+	}
+	delete(f.secrets, account)
+	return nil
+}
+
+func TestSealAndOpen(t *testing.T) {
+	kr := newFakeKeyring()
+	defer SetKeyring(kr)()
+
+	value := Seal("token:test", "gpugo_secret_value")
+	assert.True(t, IsRef(value))
+	assert.NotContains(t, value, "gpugo_secret_value")
+	assert.Equal(t, "gpugo_secret_value", kr.secrets["token:test"])
+	assert.Equal(t, "OS keyring", Location(value))
+
+	secret, err := Open(value)
+	require.NoError(t, err)
+	assert.Equal(t, "gpugo_secret_value", secret)
+
+	// Sealing a reference or nothing leaves it as is
+	assert.Equal(t, value, Seal("token:test", value))
+	assert.Empty(t, Seal("token:test", ""))
+}
+
+func TestSeal_FallsBackToPlaintext(t *testing.T) {
+	t.Run("no keyring", func(t *testing.T) {
+		defer SetKeyring(nil)()
+		assert.Equal(t, "secret", Seal("a", "secret"))
+		assert.False(t, Enabled())
+	})
+
+	t.Run("keyring error", func(t *testing.T) {
+		kr := newFakeKeyring()
+		kr.setErr = errors.New("locked")
+		defer SetKeyring(kr)()
+		assert.Equal(t, "secret", Seal("a", "secret"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		kr := newFakeKeyring()
+		defer SetKeyring(kr)()
+		Disable()
+		assert.Equal(t, "secret", Seal("a", "secret"))
+		assert.Empty(t, kr.secrets)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		kr := newFakeKeyring()
+		defer SetKeyring(kr)()
+		t.Setenv(NoKeyringEnv, "1")
+		assert.Equal(t, "secret", Seal("a", "secret"))
+		assert.False(t, NeedsMigration("secret"))
+	})
+}
+
+func TestOpen(t *testing.T) {
+	kr := newFakeKeyring()
+	kr.secrets["a"] = "secret"
+	defer SetKeyring(kr)()
+
+	secret, err := Open("plaintext")
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", secret)
+
+	// References stay readable with the keyring disabled
+	Disable()
+	secret, err = Open(refPrefix + "a")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+
+	_, err = Open(refPrefix + "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestOpen_NoKeyring(t *testing.T) {
+	defer SetKeyring(nil)()
+
+	_, err := Open(refPrefix + "a")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestNeedsMigration(t *testing.T) {
+	defer SetKeyring(newFakeKeyring())()
+
+	assert.True(t, NeedsMigration("secret"))
+	assert.False(t, NeedsMigration(""))
+	assert.False(t, NeedsMigration(refPrefix+"a"))
+}
+
+func TestForget(t *testing.T) {
+	kr := newFakeKeyring()
+	kr.secrets["a"] = "secret"
+	defer SetKeyring(kr)()
+
+	require.NoError(t, Forget("plaintext"))
+	require.NoError(t, Forget(refPrefix+"a"))
+	assert.Empty(t, kr.secrets)
+	// Already gone is fine
+	require.NoError(t, Forget(refPrefix+"a"))
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "", Redact(""))
+	assert.Equal(t, "****", Redact("short"))
+	assert.Equal(t, "****", Redact("exactly12chr"))
+	assert.Equal(t, "gpugo_ab...wxyz", Redact("gpugo_abcdefghijklmnopqrstuvwxyz"))
+}
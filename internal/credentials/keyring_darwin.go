@@ -0,0 +1,80 @@
+//go:build darwin
+
+package credentials
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	securityPath = "/usr/bin/security"
+	// securityNotFound is the exit code of security(1) for a missing item
+	securityNotFound = 44
+	keyringTimeout   = 10 * time.Second
+)
+
+// keychain stores secrets as generic passwords in the user's login keychain
+// through security(1)
+type keychain struct{}
+
+func newOSKeyring() Keyring {
+	if _, err := exec.LookPath(securityPath); err != nil {
+		return nil
+	}
+	return keychain{}
+}
+
+func (keychain) Get(account string) (string, error) {
+	out, err := runSecurity(nil, "find-generic-password", "-s", Service, "-a", account, "-w")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (keychain) Set(account, secret string) error {
+	// Passed on stdin in interactive mode so the secret never shows up in the
+	// process list; -X takes it hex-encoded to avoid quoting issues
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+		quoteSecurityArg(Service), quoteSecurityArg(account), hex.EncodeToString([]byte(secret)))
+	_, err := runSecurity(strings.NewReader(command), "-i")
+	return err
+}
+
+func (keychain) Delete(account string) error {
+	_, err := runSecurity(nil, "delete-generic-password", "-s", Service, "-a", account)
+	return err
+}
+
+func runSecurity(stdin *strings.Reader, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyringTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, securityPath, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == securityNotFound {
+			return nil, ErrNotFound
+		}
+		if exitErr != nil && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("security %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("security %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// quoteSecurityArg quotes an argument for security(1) interactive mode
+func quoteSecurityArg(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,82 @@
+//go:build linux
+
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const keyringTimeout = 10 * time.Second
+
+// secretService stores secrets in a freedesktop Secret Service (GNOME
+// Keyring, KWallet) through secret-tool from libsecret
+type secretService struct {
+	path string
+}
+
+// newOSKeyring returns the Secret Service when secret-tool is installed and
+// a D-Bus session is running; servers reached over SSH usually have neither
+func newOSKeyring() Keyring {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil
+	}
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil
+	}
+	return secretService{path: path}
+}
+
+func (s secretService) Get(account string) (string, error) {
+	out, err := s.run(nil, "lookup", "service", Service, "account", account)
+	if err != nil {
+		return "", err
+	}
+	// lookup exits 1 without output when nothing matches
+	if len(out) == 0 {
+		return "", ErrNotFound
+	}
+	return string(out), nil
+}
+
+func (s secretService) Set(account, secret string) error {
+	// secret-tool reads the secret from stdin, keeping it out of the process list
+	_, err := s.run(strings.NewReader(secret), "store", "--label", Service+" "+account,
+		"service", Service, "account", account)
+	return err
+}
+
+func (s secretService) Delete(account string) error {
+	_, err := s.run(nil, "clear", "service", Service, "account", account)
+	return err
+}
+
+func (s secretService) run(stdin *strings.Reader, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyringTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.path, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if len(out) == 0 && len(exitErr.Stderr) == 0 && args[0] == "lookup" {
+				return nil, ErrNotFound
+			}
+			if len(exitErr.Stderr) > 0 {
+				return nil, fmt.Errorf("secret-tool %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+			}
+		}
+		return nil, fmt.Errorf("secret-tool %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,8 @@
+//go:build !darwin && !linux && !windows
+
+package credentials
+
+// newOSKeyring reports no keyring; secrets stay in config files
+func newOSKeyring() Keyring {
+	return nil
+}
@@ -0,0 +1,332 @@
+package deps
+
+import (
+	"context"
+	"debug/elf"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// ABI issue kinds reported by CheckLibrariesABI
+const (
+	ABIIssueInvalid    = "invalid"
+	ABIIssueArch       = "arch"
+	ABIIssueGLIBC      = "glibc"
+	ABIIssueCollision  = "collision"
+	ABIIssueMissingDLL = "missing-dll"
+)
+
+const (
+	abiProbeTimeout    = 5 * time.Second
+	glibcVersionPrefix = "GLIBC_"
+)
+
+// ABIIssue is a reason a downloaded library would fail to load on this host
+type ABIIssue struct {
+	Library string `json:"library"`
+	Kind    string `json:"kind"`
+	Detail  string `json:"detail"`
+}
+
+func (i ABIIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Library, i.Detail)
+}
+
+// abiHost describes what the host loader provides
+type abiHost struct {
+	goos   string
+	goarch string
+	// glibc is the host glibc version, empty when unknown
+	glibc string
+	// musl is set on hosts whose C library is musl, which cannot load glibc objects
+	musl bool
+	// systemDirs are searched for libraries the host already provides
+	systemDirs []string
+}
+
+var glibcVersionPattern = regexp.MustCompile(`(\d+\.\d+(?:\.\d+)?)\s*$`)
+
+// detectABIHost inspects the current machine's loader
+func detectABIHost(ctx context.Context) *abiHost {
+	host := &abiHost{goos: runtime.GOOS, goarch: runtime.GOARCH}
+	switch runtime.GOOS {
+	case osLinux:
+		host.systemDirs = linuxSystemLibDirs()
+		host.glibc = hostGLIBCVersion(ctx)
+		if musl, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(musl) > 0 && host.glibc == "" {
+			host.musl = true
+		}
+	case osWindows:
+		root := os.Getenv("SystemRoot")
+		if root == "" {
+			root = `C:\Windows`
+		}
+		host.systemDirs = []string{filepath.Join(root, "System32")}
+	}
+	return host
+}
+
+// linuxSystemLibDirs returns the default search directories of the dynamic loader
+func linuxSystemLibDirs() []string {
+	dirs := []string{"/lib", "/lib64", "/usr/lib", "/usr/lib64", "/usr/local/lib"}
+	if triplets, err := filepath.Glob("/usr/lib/*-linux-gnu*"); err == nil {
+		dirs = append(dirs, triplets...)
+	}
+	if triplets, err := filepath.Glob("/lib/*-linux-gnu*"); err == nil {
+		dirs = append(dirs, triplets...)
+	}
+	return dirs
+}
+
+// hostGLIBCVersion returns the glibc version of the host, or "" if it has none
+func hostGLIBCVersion(ctx context.Context) string {
+	ctx, cancel := context.WithTimeout(ctx, abiProbeTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "getconf", "GNU_LIBC_VERSION").Output(); err == nil {
+		if version := parseGLIBCVersion(string(out)); version != "" {
+			return version
+		}
+	}
+	if out, err := exec.CommandContext(ctx, "ldd", "--version").Output(); err == nil {
+		first, _, _ := strings.Cut(string(out), "\n")
+		if strings.Contains(strings.ToLower(first), "glibc") || strings.Contains(strings.ToLower(first), "gnu libc") {
+			return parseGLIBCVersion(first)
+		}
+	}
+	klog.V(2).Info("Could not determine host glibc version")
+	return ""
+}
+
+// parseGLIBCVersion extracts the version from "glibc 2.35" or
+// "ldd (Ubuntu GLIBC 2.35-0ubuntu3) 2.35"
+func parseGLIBCVersion(s string) string {
+	m := glibcVersionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// compareVersions compares dotted numeric versions like 2.34 and 2.4
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// requiredGLIBC returns the newest GLIBC_x.y version among symbol versions
+func requiredGLIBC(versions []string) string {
+	var newest string
+	for _, v := range versions {
+		version, ok := strings.CutPrefix(v, glibcVersionPrefix)
+		if !ok || parseGLIBCVersion(version) != version {
+			continue
+		}
+		if newest == "" || compareVersions(version, newest) > 0 {
+			newest = version
+		}
+	}
+	return newest
+}
+
+// elfMachines maps GOARCH to the ELF machine the loader accepts
+var elfMachines = map[string]elf.Machine{
+	"amd64":   elf.EM_X86_64,
+	"arm64":   elf.EM_AARCH64,
+	"386":     elf.EM_386,
+	"arm":     elf.EM_ARM,
+	"ppc64le": elf.EM_PPC64,
+	"riscv64": elf.EM_RISCV,
+	"s390x":   elf.EM_S390,
+}
+
+// peMachines maps GOARCH to the PE machine Windows loads in-process
+var peMachines = map[string]uint16{
+	"amd64": pe.IMAGE_FILE_MACHINE_AMD64,
+	"arm64": pe.IMAGE_FILE_MACHINE_ARM64,
+	"386":   pe.IMAGE_FILE_MACHINE_I386,
+}
+
+// CheckLibrariesABI inspects downloaded shared libraries and reports why the
+// host's loader would refuse them: a foreign architecture, a glibc newer than
+// the host's, or a library of the same name the host already provides (e.g.
+// a locally installed driver) that would shadow or be shadowed by the shim.
+// Libraries that are not downloaded yet are skipped.
+func (m *Manager) CheckLibrariesABI(ctx context.Context, libs []Library) []ABIIssue {
+	host := detectABIHost(ctx)
+	libsDir := m.GetLibsDir()
+	var issues []ABIIssue
+	for _, lib := range libs {
+		if !isSharedLibrary(lib.Name) || (lib.Platform != "" && lib.Platform != host.goos) {
+			continue
+		}
+		path := m.GetLibraryPath(lib.Name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		issues = append(issues, host.check(path, libsDir)...)
+	}
+	return issues
+}
+
+// check inspects one library; libsDir holds its siblings
+func (h *abiHost) check(path, libsDir string) []ABIIssue {
+	if strings.HasSuffix(strings.ToLower(path), ".dll") {
+		return h.checkPE(path, libsDir)
+	}
+	return h.checkELF(path)
+}
+
+func (h *abiHost) checkELF(path string) []ABIIssue {
+	name := filepath.Base(path)
+	f, err := elf.Open(path)
+	if err != nil {
+		return []ABIIssue{{Library: name, Kind: ABIIssueInvalid, Detail: fmt.Sprintf("not a valid ELF file: %v", err)}}
+	}
+	defer func() { _ = f.Close() }()
+
+	if want, ok := elfMachines[h.goarch]; ok && f.Machine != want {
+		return []ABIIssue{{Library: name, Kind: ABIIssueArch,
+			Detail: fmt.Sprintf("built for %s, but this host is %s", strings.TrimPrefix(f.Machine.String(), "EM_"), h.goarch)}}
+	}
+
+	var issues []ABIIssue
+	symbols, err := f.ImportedSymbols()
+	if err != nil && !errors.Is(err, elf.ErrNoSymbols) {
+		klog.V(2).Infof("Failed to read imported symbols: library=%s error=%v", name, err)
+	}
+	versions := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		versions = append(versions, s.Version)
+	}
+	if need := requiredGLIBC(versions); need != "" {
+		switch {
+		case h.musl:
+			issues = append(issues, ABIIssue{Library: name, Kind: ABIIssueGLIBC,
+				Detail: fmt.Sprintf("requires glibc %s, but this host uses musl libc", need)})
+		case h.glibc != "" && compareVersions(need, h.glibc) > 0:
+			issues = append(issues, ABIIssue{Library: name, Kind: ABIIssueGLIBC,
+				Detail: fmt.Sprintf("requires glibc %s, but this host has glibc %s", need, h.glibc)})
+		}
+	}
+
+	soname := name
+	if names, err := f.DynString(elf.DT_SONAME); err == nil && len(names) > 0 {
+		soname = names[0]
+	}
+	if existing := h.findSystemLibrary(soname, path); existing != "" {
+		issues = append(issues, ABIIssue{Library: name, Kind: ABIIssueCollision,
+			Detail: fmt.Sprintf("SONAME %s is also provided by %s (a locally installed driver?)", soname, existing)})
+	}
+	return issues
+}
+
+func (h *abiHost) checkPE(path, libsDir string) []ABIIssue {
+	name := filepath.Base(path)
+	f, err := pe.Open(path)
+	if err != nil {
+		return []ABIIssue{{Library: name, Kind: ABIIssueInvalid, Detail: fmt.Sprintf("not a valid DLL: %v", err)}}
+	}
+	defer func() { _ = f.Close() }()
+
+	if want, ok := peMachines[h.goarch]; ok && f.Machine != want {
+		return []ABIIssue{{Library: name, Kind: ABIIssueArch,
+			Detail: fmt.Sprintf("built for machine type 0x%x, but this host is %s", f.Machine, h.goarch)}}
+	}
+
+	var issues []ABIIssue
+	symbols, err := f.ImportedSymbols()
+	if err != nil {
+		klog.V(2).Infof("Failed to read imported DLLs: library=%s error=%v", name, err)
+	}
+	for _, dll := range importedDLLs(symbols) {
+		lower := strings.ToLower(dll)
+		// API sets are resolved by the loader rather than from files
+		if strings.HasPrefix(lower, "api-ms-") || strings.HasPrefix(lower, "ext-ms-") {
+			continue
+		}
+		if findInDirs(dll, append([]string{libsDir}, filepath.SplitList(os.Getenv("PATH"))...)) || h.findSystemLibrary(dll, "") != "" {
+			continue
+		}
+		issues = append(issues, ABIIssue{Library: name, Kind: ABIIssueMissingDLL,
+			Detail: fmt.Sprintf("depends on %s, which is not installed (install the Visual C++ runtime?)", dll)})
+	}
+
+	// The loader prefers System32 over PATH, so a driver's DLL wins over the shim
+	if existing := h.findSystemLibrary(name, path); existing != "" {
+		issues = append(issues, ABIIssue{Library: name, Kind: ABIIssueCollision,
+			Detail: fmt.Sprintf("%s is also provided by %s (a locally installed driver?)", name, existing)})
+	}
+	return issues
+}
+
+// findSystemLibrary returns the path of a library named name in the host's
+// system directories, ignoring self and links to it
+func (h *abiHost) findSystemLibrary(name, self string) string {
+	selfInfo, _ := os.Stat(self)
+	for _, dir := range h.systemDirs {
+		candidate := filepath.Join(dir, name)
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if selfInfo != nil && os.SameFile(info, selfInfo) {
+			continue
+		}
+		return candidate
+	}
+	return ""
+}
+
+// findInDirs reports whether one of dirs holds a file named name
+func findInDirs(name string, dirs []string) bool {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// importedDLLs returns the DLLs named by "symbol:dll" imports, in order
+func importedDLLs(symbols []string) []string {
+	var dlls []string
+	seen := make(map[string]bool)
+	for _, s := range symbols {
+		_, dll, ok := strings.Cut(s, ":")
+		if !ok || seen[strings.ToLower(dll)] {
+			continue
+		}
+		seen[strings.ToLower(dll)] = true
+		dlls = append(dlls, dll)
+	}
+	return dlls
+}
@@ -0,0 +1,69 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGLIBCVersion(t *testing.T) {
+	assert.Equal(t, "2.35", parseGLIBCVersion("glibc 2.35\n"))
+	assert.Equal(t, "2.35", parseGLIBCVersion("ldd (Ubuntu GLIBC 2.35-0ubuntu3) 2.35"))
+	assert.Empty(t, parseGLIBCVersion("musl libc (x86_64)"))
+}
+
+func TestRequiredGLIBC(t *testing.T) {
+	assert.Equal(t, "2.34", requiredGLIBC([]string{"GLIBC_2.2.5", "GLIBC_2.34", "GLIBC_2.4", "GLIBC_PRIVATE", "GCC_3.0", ""}))
+	assert.Empty(t, requiredGLIBC([]string{"GLIBC_PRIVATE"}))
+
+	assert.Equal(t, 1, compareVersions("2.34", "2.4"))
+	assert.Equal(t, -1, compareVersions("2.2.5", "2.3"))
+	assert.Equal(t, 0, compareVersions("2.17", "2.17.0"))
+}
+
+func TestImportedDLLs(t *testing.T) {
+	symbols := []string{"LoadLibraryA:KERNEL32.dll", "GetProcAddress:KERNEL32.dll", "memcpy:VCRUNTIME140.dll", "kernel32.dll"}
+	assert.Equal(t, []string{"KERNEL32.dll", "VCRUNTIME140.dll"}, importedDLLs(symbols))
+}
+
+func TestABIHost_RejectsInvalidLibraries(t *testing.T) {
+	dir := t.TempDir()
+	host := &abiHost{goos: runtime.GOOS, goarch: runtime.GOARCH}
+	for _, name := range []string{"libcuda.so.1", "nvcuda.dll"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("<html>Access denied</html>"), 0644))
+
+		issues := host.check(path, dir)
+		require.Len(t, issues, 1, name)
+		assert.Equal(t, ABIIssueInvalid, issues[0].Kind)
+	}
+}
+
+func TestABIHost_CheckELF(t *testing.T) {
+	if runtime.GOOS != osLinux {
+		t.Skip("the test binary is an ELF file on Linux only")
+	}
+	self, err := os.Executable()
+	require.NoError(t, err)
+	systemDir := t.TempDir()
+
+	host := &abiHost{goos: osLinux, goarch: runtime.GOARCH, systemDirs: []string{systemDir}}
+	assert.Empty(t, host.check(self, ""))
+
+	require.NoError(t, os.WriteFile(filepath.Join(systemDir, filepath.Base(self)), []byte("driver"), 0644))
+	issues := host.check(self, "")
+	require.Len(t, issues, 1)
+	assert.Equal(t, ABIIssueCollision, issues[0].Kind)
+
+	host.goarch = "arm64"
+	if runtime.GOARCH == "arm64" {
+		host.goarch = "amd64"
+	}
+	issues = host.check(self, "")
+	require.Len(t, issues, 1)
+	assert.Equal(t, ABIIssueArch, issues[0].Kind)
+}
@@ -0,0 +1,200 @@
+package deps
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Auto-sync policies of the release manifest, see Settings.AutoSync. Any
+// other policy is an interval such as 24h or 7d: a manifest older than that
+// is synced before it is used.
+const (
+	// AutoSyncNever never syncs on its own; only 'ggo deps sync' and
+	// 'ggo deps update' do
+	AutoSyncNever = "never"
+	// AutoSyncOnDemand syncs only when the manifest is missing or lacks the
+	// platform a command needs, however old it is
+	AutoSyncOnDemand = "on-demand"
+)
+
+// DefaultAutoSync is the policy when none is set, see AutoSyncInterval
+const DefaultAutoSync = "7d"
+
+// Environment variables overriding the saved auto-sync settings, e.g. on CI
+const (
+	AutoSyncEnv       = "GGO_DEPS_AUTO_SYNC"
+	BackgroundSyncEnv = "GGO_DEPS_BACKGROUND_SYNC"
+)
+
+// backgroundSyncThrottle is how long a background sync that was started
+// keeps further commands from starting another
+const backgroundSyncThrottle = 10 * time.Minute
+
+// backgroundSyncStamp records when a background sync was last started
+const backgroundSyncStamp = "releases-background-sync"
+
+// ParseAutoSync validates an auto-sync policy and returns it normalized
+func ParseAutoSync(s string) (string, error) {
+	policy := strings.ToLower(strings.TrimSpace(s))
+	switch policy {
+	case AutoSyncNever, AutoSyncOnDemand:
+		return policy, nil
+	}
+	if _, err := parseSyncInterval(policy); err != nil {
+		return "", fmt.Errorf("invalid auto-sync policy %q (valid: an interval such as 24h or 7d, %s, %s)", s, AutoSyncNever, AutoSyncOnDemand)
+	}
+	return policy, nil
+}
+
+// parseSyncInterval parses a Go duration, also accepting whole days such as 7d
+func parseSyncInterval(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid interval %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+	return d, nil
+}
+
+// SyncPolicy is the effective auto-sync policy of the release manifest
+type SyncPolicy struct {
+	// Policy is AutoSyncNever, AutoSyncOnDemand or an interval
+	Policy string `json:"auto_sync"`
+	// Interval is how old a manifest may get before it is stale;
+	// AutoSyncInterval unless Policy is an interval
+	Interval time.Duration `json:"-"`
+	// Background syncs a stale manifest after the command completes, using
+	// the cached one meanwhile
+	Background bool `json:"background_sync"`
+}
+
+// syncsWhenStale reports whether a manifest older than the interval is
+// synced at all
+func (p SyncPolicy) syncsWhenStale() bool {
+	return p.Policy != AutoSyncNever && p.Policy != AutoSyncOnDemand
+}
+
+// SyncPolicy returns the auto-sync policy from the environment or the saved
+// settings, every interval by default
+func (m *Manager) SyncPolicy() SyncPolicy {
+	settings := m.effectiveSettings()
+	policy := SyncPolicy{Policy: settings.AutoSync, Background: settings.BackgroundSync}
+	if env := os.Getenv(AutoSyncEnv); env != "" {
+		if p, err := ParseAutoSync(env); err != nil {
+			klog.Warningf("Ignoring %s: %v", AutoSyncEnv, err)
+		} else {
+			policy.Policy = p
+		}
+	}
+	if env := os.Getenv(BackgroundSyncEnv); env != "" {
+		policy.Background = env == "1"
+	}
+
+	if policy.Policy == "" {
+		policy.Policy = DefaultAutoSync
+	}
+	policy.Interval = AutoSyncInterval
+	if d, err := parseSyncInterval(policy.Policy); err == nil {
+		policy.Interval = d
+	}
+	return policy
+}
+
+// SetAutoSync saves the auto-sync policy; background is left unchanged
+// when nil
+func (m *Manager) SetAutoSync(policy string, background *bool) error {
+	settings, err := m.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if policy != "" {
+		if policy, err = ParseAutoSync(policy); err != nil {
+			return err
+		}
+		settings.AutoSync = policy
+	}
+	if background != nil {
+		settings.BackgroundSync = *background
+	}
+	return m.SaveSettings(settings)
+}
+
+// ManifestStatus describes the age of the cached release manifest
+type ManifestStatus struct {
+	SyncPolicy
+	Synced   bool      `json:"synced"`
+	SyncedAt time.Time `json:"synced_at,omitzero"`
+	// Stale is set once the manifest is older than the policy's interval
+	Stale bool `json:"stale"`
+}
+
+// ManifestStatus returns the status of the cached release manifest
+func (m *Manager) ManifestStatus() (*ManifestStatus, error) {
+	manifest, err := m.LoadReleaseManifest()
+	if err != nil {
+		return nil, err
+	}
+	status := &ManifestStatus{SyncPolicy: m.SyncPolicy()}
+	if manifest != nil {
+		status.Synced = true
+		status.SyncedAt = manifest.UpdatedAt
+		status.Stale = time.Since(manifest.UpdatedAt) > status.Interval
+	}
+	return status, nil
+}
+
+// BackgroundSync is a platform whose stale release manifest a command used,
+// to be synced once the command has completed
+type BackgroundSync struct {
+	OS   string
+	Arch string
+}
+
+var (
+	pendingSyncMu sync.Mutex
+	pendingSync   *BackgroundSync
+)
+
+// queueBackgroundSync records that the manifest of a platform is stale
+func queueBackgroundSync(targetOS, targetArch string) {
+	pendingSyncMu.Lock()
+	defer pendingSyncMu.Unlock()
+	pendingSync = &BackgroundSync{OS: targetOS, Arch: targetArch}
+}
+
+// TakeBackgroundSync returns the platform to sync after the command, if a
+// stale manifest was used under the background policy, and clears it
+func TakeBackgroundSync() *BackgroundSync {
+	pendingSyncMu.Lock()
+	defer pendingSyncMu.Unlock()
+	s := pendingSync
+	pendingSync = nil
+	return s
+}
+
+// ClaimBackgroundSync reports whether a background sync may start now,
+// and if so records that one did. Commands run in quick succession on a
+// stale manifest would otherwise each start one.
+func (m *Manager) ClaimBackgroundSync() bool {
+	stamp := filepath.Join(m.paths.ControlPlaneDir(), backgroundSyncStamp)
+	if info, err := os.Stat(stamp); err == nil && time.Since(info.ModTime()) < backgroundSyncThrottle {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(stamp), 0755); err != nil {
+		return false
+	}
+	return os.WriteFile(stamp, []byte(time.Now().Format(time.RFC3339)+"\n"), 0644) == nil
+}
@@ -0,0 +1,111 @@
+package deps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAutoSync(t *testing.T) {
+	for in, want := range map[string]string{
+		"never":      AutoSyncNever,
+		" On-Demand": AutoSyncOnDemand,
+		"24h":        "24h",
+		"7D":         "7d",
+		"90m":        "90m",
+	} {
+		got, err := ParseAutoSync(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "always", "0d", "-1h", "d"} {
+		_, err := ParseAutoSync(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestFetchReleaseManifest_SyncPolicy(t *testing.T) {
+	t.Setenv(AutoSyncEnv, "")
+	t.Setenv(BackgroundSyncEnv, "")
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(api.ReleasesResponse{Releases: []api.ReleaseInfo{{
+			Vendor:  api.VendorInfo{Slug: "stub", Name: "STUB"},
+			Version: "1.0.0",
+			Artifacts: []api.ReleaseArtifact{{
+				CPUArch: runtime.GOARCH, OS: runtime.GOOS, URL: "https://example.com/libcuda.so.1", SHA256: "abc123",
+				Metadata: map[string]string{"type": LibraryTypeVGPULibrary},
+			}},
+		}}})
+	}))
+	defer server.Close()
+	mgr := NewManager(
+		WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())),
+		WithAPIClient(api.NewClient(api.WithBaseURL(server.URL))),
+	)
+	ctx := context.Background()
+	lib := Library{Name: "libcuda.so.1", Version: "0.9.0", Platform: runtime.GOOS, Arch: runtime.GOARCH, Type: LibraryTypeVGPULibrary}
+	saveAged := func(age time.Duration) {
+		require.NoError(t, mgr.saveReleaseManifest(&ReleaseManifest{UpdatedAt: time.Now().Add(-age), Libraries: []Library{lib}}))
+	}
+	fetch := func() bool {
+		_, synced, err := mgr.FetchReleaseManifest(ctx)
+		require.NoError(t, err)
+		return synced
+	}
+
+	// Never: no manifest at all is an error, an outdated one is used
+	require.NoError(t, mgr.SetAutoSync(AutoSyncNever, nil))
+	_, _, err := mgr.FetchReleaseManifest(ctx)
+	assert.ErrorContains(t, err, "ggo deps sync")
+	saveAged(30 * 24 * time.Hour)
+	assert.False(t, fetch())
+
+	// On-demand ignores age, which is still reported against the default
+	require.NoError(t, mgr.SetAutoSync(AutoSyncOnDemand, nil))
+	assert.False(t, fetch())
+	status, err := mgr.ManifestStatus()
+	require.NoError(t, err)
+	assert.True(t, status.Stale)
+	assert.Zero(t, requests)
+
+	// Intervals
+	require.NoError(t, mgr.SetAutoSync("1d", nil))
+	saveAged(2 * time.Hour)
+	assert.False(t, fetch())
+	saveAged(2 * 24 * time.Hour)
+	status, err = mgr.ManifestStatus()
+	require.NoError(t, err)
+	assert.True(t, status.Stale)
+	assert.True(t, fetch())
+	assert.Equal(t, 1, requests)
+
+	// The environment overrides the settings
+	t.Setenv(AutoSyncEnv, "30d")
+	saveAged(2 * 24 * time.Hour)
+	assert.False(t, fetch())
+	t.Setenv(AutoSyncEnv, "")
+
+	// Background uses the outdated manifest and queues a sync for later
+	background := true
+	require.NoError(t, mgr.SetAutoSync("", &background))
+	assert.Equal(t, "1d", mgr.SyncPolicy().Policy)
+	assert.Nil(t, TakeBackgroundSync())
+	assert.False(t, fetch())
+	assert.Equal(t, &BackgroundSync{OS: runtime.GOOS, Arch: runtime.GOARCH}, TakeBackgroundSync())
+	assert.Nil(t, TakeBackgroundSync())
+	assert.Equal(t, 1, requests)
+
+	assert.True(t, mgr.ClaimBackgroundSync())
+	assert.False(t, mgr.ClaimBackgroundSync(), "a sync was just started")
+}
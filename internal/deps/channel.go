@@ -0,0 +1,178 @@
+package deps
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+// DepsSettingsFile stores the machine's release channel and version pins.
+// It is kept apart from the deps manifest so pins survive manifest refreshes.
+const DepsSettingsFile = "deps-settings.json"
+
+// Release channels, from most to least conservative
+const (
+	ChannelStable  = "stable"
+	ChannelBeta    = "beta"
+	ChannelNightly = "nightly"
+)
+
+// channelRank orders channels; a channel accepts releases of equal or lower rank
+var channelRank = map[string]int{
+	ChannelStable:  0,
+	ChannelBeta:    1,
+	ChannelNightly: 2,
+}
+
+// Settings is the per-machine deps selection policy
+type Settings struct {
+	Channel string            `json:"channel,omitempty"`
+	Pins    map[string]string `json:"pins,omitempty"` // library type -> version
+	// Mirror is a self-hosted base URL that replaces the public CDN
+	Mirror string `json:"mirror,omitempty"`
+	// AutoSync is when the release manifest syncs on its own, see ParseAutoSync
+	AutoSync string `json:"auto_sync,omitempty"`
+	// BackgroundSync syncs an outdated release manifest after the command
+	// that used it instead of before
+	BackgroundSync bool `json:"background_sync,omitempty"`
+}
+
+// ParseChannel validates a channel name
+func ParseChannel(s string) (string, error) {
+	c := strings.ToLower(strings.TrimSpace(s))
+	if _, ok := channelRank[c]; !ok {
+		return "", fmt.Errorf("unknown release channel %q (valid: stable, beta, nightly)", s)
+	}
+	return c, nil
+}
+
+// ParseLibraryType validates a library type name
+func ParseLibraryType(s string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(s))
+	switch t {
+	case LibraryTypeVGPULibrary, LibraryTypeRemoteGPUWorker, LibraryTypeRemoteGPUClient:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown library type %q (valid: %s, %s, %s)",
+		s, LibraryTypeVGPULibrary, LibraryTypeRemoteGPUWorker, LibraryTypeRemoteGPUClient)
+}
+
+// normalizeChannel maps a release's channel tag to a known channel; untagged
+// releases predate channels and are treated as stable
+func normalizeChannel(s string) string {
+	c := strings.ToLower(strings.TrimSpace(s))
+	if _, ok := channelRank[c]; ok {
+		return c
+	}
+	return ChannelStable
+}
+
+// channelAccepts reports whether a library released on libChannel may be
+// selected by a machine following channel
+func channelAccepts(channel, libChannel string) bool {
+	return channelRank[normalizeChannel(libChannel)] <= channelRank[normalizeChannel(channel)]
+}
+
+func (m *Manager) settingsPath() string {
+	return filepath.Join(m.paths.ConfigDir(), DepsSettingsFile)
+}
+
+// LoadSettings loads the deps settings, returning defaults when none are saved
+func (m *Manager) LoadSettings() (*Settings, error) {
+	settings, err := utils.LoadJSON[Settings](m.settingsPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deps settings: %w", err)
+	}
+	if settings == nil {
+		settings = &Settings{}
+	}
+	if settings.Channel == "" {
+		settings.Channel = ChannelStable
+	}
+	if settings.Pins == nil {
+		settings.Pins = make(map[string]string)
+	}
+	return settings, nil
+}
+
+// SaveSettings saves the deps settings
+func (m *Manager) SaveSettings(settings *Settings) error {
+	return utils.SaveJSON(m.settingsPath(), settings, 0644)
+}
+
+// SetChannel sets the machine's default release channel
+func (m *Manager) SetChannel(channel string) error {
+	channel, err := ParseChannel(channel)
+	if err != nil {
+		return err
+	}
+	settings, err := m.LoadSettings()
+	if err != nil {
+		return err
+	}
+	settings.Channel = channel
+	return m.SaveSettings(settings)
+}
+
+// Pin fixes the version selected for a library type regardless of channel
+func (m *Manager) Pin(libType, version string) error {
+	libType, err := ParseLibraryType(libType)
+	if err != nil {
+		return err
+	}
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return fmt.Errorf("version is required")
+	}
+	settings, err := m.LoadSettings()
+	if err != nil {
+		return err
+	}
+	settings.Pins[libType] = version
+	return m.SaveSettings(settings)
+}
+
+// HasRelease reports whether the cached release manifest lists version for
+// libType. cached is false when no manifest has been synced yet, in which case
+// found carries no information.
+func (m *Manager) HasRelease(libType, version string) (found, cached bool, err error) {
+	manifest, err := m.LoadReleaseManifest()
+	if err != nil || manifest == nil {
+		return false, false, err
+	}
+	for _, lib := range manifest.Libraries {
+		if lib.Type == libType && lib.Version == version {
+			return true, true, nil
+		}
+	}
+	return false, true, nil
+}
+
+// Unpin removes a version pin; returns false if the type was not pinned
+func (m *Manager) Unpin(libType string) (bool, error) {
+	settings, err := m.LoadSettings()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := settings.Pins[libType]; !ok {
+		return false, nil
+	}
+	delete(settings.Pins, libType)
+	return true, m.SaveSettings(settings)
+}
+
+// effectiveSettings returns the saved settings with a WithChannel override applied
+func (m *Manager) effectiveSettings() *Settings {
+	settings, err := m.LoadSettings()
+	if err != nil {
+		klog.Warningf("Using default deps settings: %v", err)
+		settings = &Settings{Channel: ChannelStable, Pins: map[string]string{}}
+	}
+	if m.channel != "" {
+		settings.Channel = m.channel
+	}
+	return settings
+}
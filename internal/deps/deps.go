@@ -4,6 +4,7 @@ package deps
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -14,6 +15,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -38,7 +40,8 @@ const (
 	// DownloadedManifestFile is the filename for the downloaded dependencies manifest
 	DownloadedManifestFile = "downloaded-manifest.json"
 
-	// AutoSyncInterval is the interval for auto-syncing the manifest
+	// AutoSyncInterval is the default interval for auto-syncing the manifest,
+	// see SyncPolicy
 	AutoSyncInterval = 7 * 24 * time.Hour
 )
 
@@ -62,6 +65,12 @@ type Library struct {
 	// Vendor information from release
 	VendorSlug string `json:"vendorSlug,omitempty"` // e.g., "stub", "nvidia", "amd"
 	VendorName string `json:"vendorName,omitempty"` // e.g., "STUB", "NVIDIA", "AMD"
+	// Channel is the release channel the library was published on (stable, beta, nightly)
+	Channel string `json:"channel,omitempty"`
+	// Signature is the publisher's base64 Ed25519 signature over
+	// ArtifactSignaturePayload, made with the key KeyID
+	Signature string `json:"signature,omitempty"`
+	KeyID     string `json:"keyId,omitempty"`
 }
 
 // Key returns a unique identifier for this library (name + vendor + platform + arch)
@@ -126,7 +135,15 @@ type Manager struct {
 	apiClient  *api.Client
 	paths      *platform.Paths
 	httpClient *http.Client
-	mu         sync.RWMutex
+	channel    string // overrides the saved channel when set
+	mirror     string // overrides the saved mirror base URL when set
+	lock       *Lockfile
+	shared     *sharedCache
+	// pinnedKeys are the publisher keys compiled in; skipSignature disables
+	// verification against them
+	pinnedKeys    map[string]ed25519.PublicKey
+	skipSignature bool
+	mu            sync.RWMutex
 }
 
 // NewManager creates a new dependency manager
@@ -138,6 +155,15 @@ func NewManager(opts ...ManagerOption) *Manager {
 		httpClient: &http.Client{
 			Timeout: 5 * time.Minute,
 		},
+		skipSignature: insecureSkipSignatureFromEnv(),
+	}
+	if keys, err := parsePublisherKeys(publisherKeys); err != nil {
+		klog.Errorf("Ignoring pinned publisher keys: error=%v", err)
+	} else {
+		m.pinnedKeys = keys
+	}
+	if dir := resolveSharedCacheDir(); dir != "" {
+		m.shared = &sharedCache{dir: dir}
 	}
 	for _, opt := range opts {
 		opt(m)
@@ -166,6 +192,32 @@ func WithPaths(paths *platform.Paths) ManagerOption {
 	}
 }
 
+// WithMirrorURL downloads artifacts from a self-hosted mirror instead of the CDN
+func WithMirrorURL(url string) ManagerOption {
+	return func(m *Manager) {
+		m.mirror = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithSharedCacheDir stores downloads in a content-addressed cache shared by
+// all users of the host; empty disables the shared cache
+func WithSharedCacheDir(dir string) ManagerOption {
+	return func(m *Manager) {
+		m.shared = nil
+		if dir != "" {
+			m.shared = &sharedCache{dir: dir}
+		}
+	}
+}
+
+// SharedCacheDir returns the shared cache directory, empty when not in use
+func (m *Manager) SharedCacheDir() string {
+	if m.shared == nil {
+		return ""
+	}
+	return m.shared.dir
+}
+
 // WithAPIBaseURL sets a custom API base URL
 func WithAPIBaseURL(url string) ManagerOption {
 	return func(m *Manager) {
@@ -178,6 +230,13 @@ func WithAPIBaseURL(url string) ManagerOption {
 	}
 }
 
+// WithChannel selects releases from the given channel instead of the saved default
+func WithChannel(channel string) ManagerOption {
+	return func(m *Manager) {
+		m.channel = channel
+	}
+}
+
 // WithAPIClient sets a custom API client
 func WithAPIClient(client *api.Client) ManagerOption {
 	return func(m *Manager) {
@@ -218,7 +277,23 @@ func (m *Manager) SyncReleases(ctx context.Context, osStr, arch string) (*Releas
 		targetArch = runtime.GOARCH
 	}
 
-	for _, release := range releasesResp.Releases {
+	manifest.Libraries = m.librariesFromReleases(releasesResp.Releases, targetOS, targetArch)
+
+	// Save to cache
+	if err := m.saveReleaseManifest(manifest); err != nil {
+		return nil, err
+	}
+
+	klog.Infof("Synced %d libraries for platform %s/%s", len(manifest.Libraries), targetOS, targetArch)
+
+	return manifest, nil
+}
+
+// librariesFromReleases converts API releases to libraries for a platform;
+// an empty targetOS or targetArch matches every OS or architecture
+func (m *Manager) librariesFromReleases(releases []api.ReleaseInfo, targetOS, targetArch string) []Library {
+	libs := []Library{}
+	for _, release := range releases {
 		// Find matching artifacts for target platform
 		for _, artifact := range release.Artifacts {
 			// Map OS names: linux, darwin, windows
@@ -233,7 +308,7 @@ func (m *Manager) SyncReleases(ctx context.Context, osStr, arch string) (*Releas
 				artifactArch = "amd64"
 			}
 
-			if artifactOS == targetOS && artifactArch == targetArch {
+			if (targetOS == "" || artifactOS == targetOS) && (targetArch == "" || artifactArch == targetArch) {
 				// Extract library name from URL
 				libName := m.extractLibraryName(release.Vendor.Slug, artifact.URL)
 				if libName == "" {
@@ -261,22 +336,17 @@ func (m *Manager) SyncReleases(ctx context.Context, osStr, arch string) (*Releas
 					SHA256:     artifact.SHA256,
 					Size:       size,
 					Type:       libType,
-					VendorSlug: strings.ToLower(release.Vendor.Slug),
+					VendorSlug: NormalizeVendorSlug(release.Vendor.Slug),
 					VendorName: release.Vendor.Name,
+					Channel:    normalizeChannel(release.Channel),
+					Signature:  artifact.Signature,
+					KeyID:      artifact.KeyID,
 				}
-				manifest.Libraries = append(manifest.Libraries, lib)
+				libs = append(libs, lib)
 			}
 		}
 	}
-
-	// Save to cache
-	if err := m.saveReleaseManifest(manifest); err != nil {
-		return nil, err
-	}
-
-	klog.Infof("Synced %d libraries for platform %s/%s", len(manifest.Libraries), targetOS, targetArch)
-
-	return manifest, nil
+	return libs
 }
 
 // extractLibraryName extracts library name from URL
@@ -316,7 +386,7 @@ func (m *Manager) LoadReleaseManifest() (*ReleaseManifest, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	manifestPath := filepath.Join(m.paths.ConfigDir(), ReleaseManifestFile)
+	manifestPath := filepath.Join(m.paths.ControlPlaneDir(), ReleaseManifestFile)
 	data, err := os.ReadFile(manifestPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -335,7 +405,7 @@ func (m *Manager) LoadReleaseManifest() (*ReleaseManifest, error) {
 
 // saveReleaseManifest saves the release manifest to local cache
 func (m *Manager) saveReleaseManifest(manifest *ReleaseManifest) error {
-	manifestPath := filepath.Join(m.paths.ConfigDir(), ReleaseManifestFile)
+	manifestPath := filepath.Join(m.paths.ControlPlaneDir(), ReleaseManifestFile)
 
 	data, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
@@ -381,21 +451,34 @@ func (m *Manager) FetchReleaseManifestForPlatform(ctx context.Context, targetOS,
 	}
 
 	synced := false
-	// Check if manifest needs refresh:
-	// 1. No cached manifest
-	// 2. Manifest is outdated
-	// 3. Manifest doesn't have libraries for the target platform
-	needsSync := manifest == nil || time.Since(manifest.UpdatedAt) > AutoSyncInterval
-	if !needsSync && manifest != nil {
-		// Check if we have libraries for the target platform
-		hasTargetPlatform := false
+	hasTargetPlatform := false
+	if manifest != nil {
 		for _, lib := range manifest.Libraries {
 			if lib.Platform == actualOS && lib.Arch == actualArch {
 				hasTargetPlatform = true
 				break
 			}
 		}
-		needsSync = !hasTargetPlatform
+	}
+	// A manifest missing or lacking the target platform must be synced unless
+	// the policy forbids it; an outdated one only when the policy asks for it
+	policy := m.SyncPolicy()
+	needsSync := !hasTargetPlatform
+	if needsSync && policy.Policy == AutoSyncNever {
+		if manifest == nil {
+			return nil, false, fmt.Errorf("no release manifest and auto-sync is %s, run 'ggo deps sync' first", AutoSyncNever)
+		}
+		return nil, false, fmt.Errorf("release manifest has no libraries for %s/%s and auto-sync is %s, run 'ggo deps sync --os %s --arch %s' first",
+			actualOS, actualArch, AutoSyncNever, actualOS, actualArch)
+	}
+	if !needsSync && policy.syncsWhenStale() && time.Since(manifest.UpdatedAt) > policy.Interval {
+		if policy.Background {
+			klog.V(4).Infof("Release manifest is outdated (last sync: %s), syncing in the background after the command",
+				manifest.UpdatedAt.Format(time.RFC3339))
+			queueBackgroundSync(actualOS, actualArch)
+		} else {
+			needsSync = true
+		}
 	}
 
 	if needsSync {
@@ -481,12 +564,16 @@ func CompareVersions(v1, v2 string) bool {
 
 // SelectRequiredDeps selects the required dependencies from release manifest
 // For each library type, it selects all artifacts from the latest version
-// This ensures that types with multiple files (like remote-gpu-client) get all files
+// available on the machine's release channel, or from the pinned version if
+// the type is pinned. A lockfile (see WithLockfile) overrides both for the
+// types it covers. This ensures that types with multiple files (like
+// remote-gpu-client) get all files
 func (m *Manager) SelectRequiredDeps(manifest *ReleaseManifest) *DepsManifest {
 	deps := &DepsManifest{
 		UpdatedAt: time.Now(),
 		Libraries: make(map[string]Library),
 	}
+	settings := m.effectiveSettings()
 
 	// Group libraries by type and version
 	typeVersionLibs := make(map[string]map[string][]Library) // type -> version -> []Library
@@ -501,34 +588,58 @@ func (m *Manager) SelectRequiredDeps(manifest *ReleaseManifest) *DepsManifest {
 		typeVersionLibs[lib.Type][lib.Version] = append(typeVersionLibs[lib.Type][lib.Version], lib)
 	}
 
-	// For each type, find the latest version and include ALL its artifacts
+	// For each type, find the selected version and include ALL its artifacts
 	for libType, versionLibs := range typeVersionLibs {
-		// Get all versions and sort them (newest first)
-		versions := make([]string, 0, len(versionLibs))
-		for v := range versionLibs {
-			versions = append(versions, v)
-		}
-		// Sort versions in descending order using semantic version comparison
-		sort.Slice(versions, func(i, j int) bool {
-			return CompareVersions(versions[i], versions[j])
-		})
-
-		if len(versions) == 0 {
-			continue
+		libs, _ := m.selectVersion(libType, versionLibs, settings)
+		for _, lib := range libs {
+			deps.Libraries[lib.Key()] = lib
 		}
+	}
 
-		// Take the latest version and include all its artifacts
-		latestVersion := versions[0]
-		libs := versionLibs[latestVersion]
+	return deps
+}
 
-		klog.V(4).Infof("Selected latest version for type %s: %s (%d artifacts)", libType, latestVersion, len(libs))
+// selectVersion picks the artifacts of one library type from its released
+// versions: the lockfile wins over a pin, which wins over the latest version
+// on the channel. It also returns which of these made the choice, one of the
+// SelectedBy constants.
+func (m *Manager) selectVersion(libType string, versionLibs map[string][]Library, settings *Settings) ([]Library, string) {
+	if m.lock != nil {
+		if libs, ok := m.lock.lockedLibraries(libType, versionLibs); ok {
+			klog.V(4).Infof("Selected locked libraries for type %s (%d artifacts)", libType, len(libs))
+			return libs, SelectedByLockfile
+		}
+	}
+	if pinned, ok := settings.Pins[libType]; ok {
+		if libs, found := versionLibs[pinned]; found {
+			klog.V(4).Infof("Selected pinned version for type %s: %s (%d artifacts)", libType, pinned, len(libs))
+			return libs, SelectedByPin
+		}
+		klog.Warningf("Pinned version %s for type %s is not available, falling back to channel %s", pinned, libType, settings.Channel)
+	}
 
-		for _, lib := range libs {
-			deps.Libraries[lib.Key()] = lib
+	// Get versions on the channel and sort them (newest first)
+	versions := make([]string, 0, len(versionLibs))
+	for v, libs := range versionLibs {
+		if channelAccepts(settings.Channel, libs[0].Channel) {
+			versions = append(versions, v)
 		}
 	}
+	// Sort versions in descending order using semantic version comparison
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareVersions(versions[i], versions[j])
+	})
 
-	return deps
+	if len(versions) == 0 {
+		return nil, ""
+	}
+
+	// Take the latest version and include all its artifacts
+	latestVersion := versions[0]
+	libs := versionLibs[latestVersion]
+
+	klog.V(4).Infof("Selected latest %s version for type %s: %s (%d artifacts)", settings.Channel, libType, latestVersion, len(libs))
+	return libs, SelectedByChannel
 }
 
 // LoadDepsManifest loads the deps manifest from local storage
@@ -540,7 +651,7 @@ func (m *Manager) LoadDepsManifest() (*DepsManifest, error) {
 }
 
 func (m *Manager) loadDepsManifestUnsafe() (*DepsManifest, error) {
-	manifestPath := filepath.Join(m.paths.ConfigDir(), DepsManifestFile)
+	manifestPath := filepath.Join(m.paths.ControlPlaneDir(), DepsManifestFile)
 	data, err := os.ReadFile(manifestPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -566,7 +677,7 @@ func (m *Manager) SaveDepsManifest(manifest *DepsManifest) error {
 }
 
 func (m *Manager) saveDepsManifestUnsafe(manifest *DepsManifest) error {
-	manifestPath := filepath.Join(m.paths.ConfigDir(), DepsManifestFile)
+	manifestPath := filepath.Join(m.paths.ControlPlaneDir(), DepsManifestFile)
 
 	data, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
@@ -717,6 +828,11 @@ func downloadToFile(tmpFile *os.File, reader io.Reader, size int64, progressFn f
 //
 //nolint:gocyclo // pre-existing complexity, refactoring out of scope
 func (m *Manager) downloadLibraryToDir(ctx context.Context, lib Library, libsDir string, progressFn func(downloaded, total int64)) error {
+	// The SHA256 checked below is only as trustworthy as its signature
+	if err := m.VerifySignature(lib); err != nil {
+		return err
+	}
+
 	// Ensure cache directory exists
 	cacheDir := m.paths.CacheDir()
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
@@ -752,55 +868,52 @@ func (m *Manager) downloadLibraryToDir(ctx context.Context, lib Library, libsDir
 		}
 	}
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lib.URL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := m.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to download library: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download library: status %d", resp.StatusCode)
-	}
-
-	// Create temp file
-	tmpFile, err := os.Create(tmpPath)
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer func() { _ = os.Remove(tmpPath) }()
-
-	// Download with progress and hash verification
-	hash := sha256.New()
-	reader := io.TeeReader(resp.Body, hash)
-
-	downloadedBytes, err := downloadToFile(tmpFile, reader, lib.Size, progressFn)
-	if err != nil {
-		return err
+	// Another user of the host may already have downloaded the artifact
+	var blob string
+	if m.shared != nil && lib.SHA256 != "" {
+		blob, _ = m.shared.lookup(lib.SHA256)
 	}
 
-	// Verify hash (skip if SHA256 is empty)
-	if lib.SHA256 != "" {
-		actualHash := hex.EncodeToString(hash.Sum(nil))
-		if actualHash != lib.SHA256 {
-			return fmt.Errorf("hash mismatch: expected %s, got %s", lib.SHA256, actualHash)
+	var size int64
+	if blob == "" {
+		defer func() { _ = os.Remove(tmpPath) }()
+		downloadedBytes, err := m.fetchArtifact(ctx, lib, tmpPath, progressFn)
+		if err != nil {
+			return err
+		}
+		size = downloadedBytes
+		if m.shared != nil && lib.SHA256 != "" {
+			if blob, err = m.shared.store(tmpPath, lib.SHA256); err != nil {
+				klog.Warningf("Failed to add library to shared cache, keeping a private copy: name=%s error=%v", lib.Name, err)
+				blob = ""
+			}
+		}
+	} else if info, err := os.Stat(blob); err == nil {
+		size = info.Size()
+		if progressFn != nil {
+			progressFn(size, size)
 		}
 	}
 
-	// Move to final destination
-	if err := os.Rename(tmpPath, destPath); err != nil {
-		return fmt.Errorf("failed to move file: %w", err)
-	}
+	if blob != "" {
+		// Shared blobs are already read-only and executable; they must not
+		// be chmod-ed through a link
+		how, err := m.shared.materialize(blob, lib.SHA256, destPath)
+		if err != nil {
+			return err
+		}
+		klog.V(2).Infof("Library linked from shared cache: name=%s blob=%s method=%s", lib.Name, blob, how)
+	} else {
+		// Move to final destination
+		if err := os.Rename(tmpPath, destPath); err != nil {
+			return fmt.Errorf("failed to move file: %w", err)
+		}
 
-	// Set executable permission on Unix
-	if !platform.IsWindows() {
-		if err := os.Chmod(destPath, 0755); err != nil {
-			return fmt.Errorf("failed to set permissions: %w", err)
+		// Set executable permission on Unix
+		if !platform.IsWindows() {
+			if err := os.Chmod(destPath, 0755); err != nil {
+				return fmt.Errorf("failed to set permissions: %w", err)
+			}
 		}
 	}
 
@@ -821,7 +934,7 @@ func (m *Manager) downloadLibraryToDir(ctx context.Context, lib Library, libsDir
 
 	// Update size if it was zero (discovered during download)
 	if lib.Size == 0 {
-		lib.Size = downloadedBytes
+		lib.Size = size
 	}
 
 	// Update downloaded manifest
@@ -832,6 +945,50 @@ func (m *Manager) downloadLibraryToDir(ctx context.Context, lib Library, libsDir
 	return nil
 }
 
+// fetchArtifact downloads lib into tmpPath and verifies its hash, returning
+// the number of bytes downloaded
+func (m *Manager) fetchArtifact(ctx context.Context, lib Library, tmpPath string, progressFn func(downloaded, total int64)) (int64, error) {
+	// Create request, through the mirror if one is configured
+	req, client, err := m.artifactRequest(ctx, lib.URL)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download library: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download library: status %d", resp.StatusCode)
+	}
+
+	// Create temp file
+	tmpFile, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	// Download with progress and hash verification
+	hash := sha256.New()
+	reader := io.TeeReader(resp.Body, hash)
+
+	downloadedBytes, err := downloadToFile(tmpFile, reader, lib.Size, progressFn)
+	if err != nil {
+		return 0, err
+	}
+
+	// Verify hash (skip if SHA256 is empty)
+	if lib.SHA256 != "" {
+		actualHash := hex.EncodeToString(hash.Sum(nil))
+		if actualHash != lib.SHA256 {
+			return 0, fmt.Errorf("hash mismatch: expected %s, got %s", lib.SHA256, actualHash)
+		}
+	}
+	return downloadedBytes, nil
+}
+
 // updateDownloadedManifestUnsafe updates the downloaded manifest with a library
 // Caller must hold m.mu lock
 func (m *Manager) updateDownloadedManifestUnsafe(lib Library) error {
@@ -926,20 +1083,28 @@ func (m *Manager) verifyLibraryUnsafe(path, expectedHash string) bool {
 		_, err := os.Stat(path)
 		return err == nil
 	}
+	return fileHasHash(path, expectedHash)
+}
+
+// fileHasHash reports whether the file at path has the given SHA-256
+func fileHasHash(path, expectedHash string) bool {
+	actualHash, err := fileSHA256(path)
+	return err == nil && actualHash == expectedHash
+}
 
+// fileSHA256 returns the hex SHA-256 of the file at path
+func fileSHA256(path string) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return false
+		return "", err
 	}
 	defer func() { _ = file.Close() }()
 
 	hash := sha256.New()
 	if _, err := io.Copy(hash, file); err != nil {
-		return false
+		return "", err
 	}
-
-	actualHash := hex.EncodeToString(hash.Sum(nil))
-	return actualHash == expectedHash
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // GetLibraryPath returns the path to a library in cache
@@ -1066,6 +1231,8 @@ func (m *Manager) EnsureLibraryByType(ctx context.Context, libType string, vendo
 		}
 	}
 
+	vendorSlug = NormalizeVendorSlug(vendorSlug)
+
 	// Find library of the specified type
 	var targetLib *Library
 	for _, lib := range deps.Libraries {
@@ -1151,7 +1318,7 @@ func (m *Manager) GetRemoteGPUWorkerPath(ctx context.Context) (string, error) {
 
 // EnsureLibrariesByTypes ensures ALL libraries of the specified types exist and are downloaded
 // This is different from EnsureLibraryByType which only returns one library
-// vendorSlug filters by vendor (e.g., "nvidia", "amd"). Empty string matches all vendors.
+// vendorSlug filters by vendor (e.g., "nvidia", "amd", "Moore Threads", see NormalizeVendorSlug). Empty string matches all vendors.
 // Returns the list of all libraries that were checked/downloaded
 func (m *Manager) EnsureLibrariesByTypes(ctx context.Context, libTypes []string, vendorSlug string, progressFn func(lib Library, downloaded, total int64)) ([]Library, error) {
 	return m.EnsureLibrariesByTypesForPlatform(ctx, libTypes, vendorSlug, "", "", progressFn)
@@ -1201,7 +1368,7 @@ func (m *Manager) EnsureLibrariesByTypesForPlatform(ctx context.Context, libType
 	}
 
 	// Normalize vendor slug for matching
-	normalizedVendor := strings.ToLower(vendorSlug)
+	normalizedVendor := NormalizeVendorSlug(vendorSlug)
 
 	// Find all libraries matching the specified types and vendor
 	var targetLibs []Library
@@ -1269,6 +1436,32 @@ func (m *Manager) EnsureLibrariesByTypesForPlatform(ctx context.Context, libType
 	return targetLibs, nil
 }
 
+// ResolveLibrariesForPlatform returns the libraries of libTypes for vendorSlug
+// that would be installed on a platform, without downloading them, e.g. for
+// another machine to fetch them from the CDN
+func (m *Manager) ResolveLibrariesForPlatform(ctx context.Context, libTypes []string, vendorSlug, targetOS, targetArch string) ([]Library, error) {
+	manifest, _, err := m.FetchReleaseManifestForPlatform(ctx, targetOS, targetArch)
+	if err != nil {
+		return nil, err
+	}
+	platformManifest := &ReleaseManifest{Libraries: m.GetLibrariesForPlatform(manifest, targetOS, targetArch, "")}
+	required := m.SelectRequiredDeps(platformManifest)
+
+	normalizedVendor := NormalizeVendorSlug(vendorSlug)
+	var libs []Library
+	for _, lib := range required.Libraries {
+		if !slices.Contains(libTypes, lib.Type) {
+			continue
+		}
+		if normalizedVendor != "" && lib.VendorSlug != normalizedVendor {
+			continue
+		}
+		libs = append(libs, lib)
+	}
+	sort.Slice(libs, func(i, j int) bool { return libs[i].Name < libs[j].Name })
+	return libs, nil
+}
+
 // CheckUpdates checks if deps manifest has updates compared to downloaded manifest
 // Returns the libraries that need to be downloaded
 func (m *Manager) CheckUpdates(ctx context.Context) ([]Library, error) {
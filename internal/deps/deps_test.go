@@ -2,6 +2,8 @@ package deps
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -106,6 +108,55 @@ func TestSelectRequiredDeps(t *testing.T) {
 	assert.Equal(t, "2.6.3", clientLib.Version)
 }
 
+func TestSelectRequiredDeps_ChannelsAndPins(t *testing.T) {
+	manifest := &ReleaseManifest{
+		Libraries: []Library{
+			{Name: "libaccel-nvidia.so", Version: "1.41.0", Platform: "linux", Arch: "amd64", Type: LibraryTypeVGPULibrary},
+			{Name: "libaccel-nvidia.so", Version: "1.42.0", Platform: "linux", Arch: "amd64", Type: LibraryTypeVGPULibrary, Channel: ChannelStable},
+			{Name: "libaccel-nvidia.so", Version: "1.43.0", Platform: "linux", Arch: "amd64", Type: LibraryTypeVGPULibrary, Channel: ChannelBeta},
+			{Name: "libaccel-nvidia.so", Version: "1.44.0", Platform: "linux", Arch: "amd64", Type: LibraryTypeVGPULibrary, Channel: ChannelNightly},
+		},
+	}
+	key := "libaccel-nvidia.so:linux:amd64"
+	mgr := NewManager(WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())))
+
+	assert.Equal(t, "1.42.0", mgr.SelectRequiredDeps(manifest).Libraries[key].Version, "default channel is stable")
+
+	require.NoError(t, mgr.SetChannel("beta"))
+	assert.Equal(t, "1.43.0", mgr.SelectRequiredDeps(manifest).Libraries[key].Version)
+
+	nightly := NewManager(WithPaths(mgr.paths), WithChannel(ChannelNightly))
+	assert.Equal(t, "1.44.0", nightly.SelectRequiredDeps(manifest).Libraries[key].Version, "override wins over saved channel")
+
+	require.NoError(t, mgr.Pin(LibraryTypeVGPULibrary, "1.41.0"))
+	assert.Equal(t, "1.41.0", mgr.SelectRequiredDeps(manifest).Libraries[key].Version)
+	assert.Equal(t, "1.41.0", nightly.SelectRequiredDeps(manifest).Libraries[key].Version, "pins take precedence over channels")
+
+	require.NoError(t, mgr.Pin(LibraryTypeVGPULibrary, "0.1.0"))
+	assert.Equal(t, "1.43.0", mgr.SelectRequiredDeps(manifest).Libraries[key].Version, "missing pin falls back to channel")
+
+	removed, err := mgr.Unpin(LibraryTypeVGPULibrary)
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	assert.Error(t, mgr.Pin("vgpu-libary", "1.41.0"), "unknown library types are rejected")
+
+	_, cached, err := mgr.HasRelease(LibraryTypeVGPULibrary, "1.41.0")
+	require.NoError(t, err)
+	assert.False(t, cached)
+	require.NoError(t, mgr.saveReleaseManifest(manifest))
+	found, cached, err := mgr.HasRelease(LibraryTypeVGPULibrary, "1.41.0")
+	require.NoError(t, err)
+	assert.True(t, cached)
+	assert.True(t, found)
+	found, _, err = mgr.HasRelease(LibraryTypeVGPULibrary, "0.1.0")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = ParseChannel("canary")
+	assert.Error(t, err)
+}
+
 func TestFetchReleaseManifest(t *testing.T) {
 	// Use temp directory to avoid cached manifests
 	tmpDir := t.TempDir()
@@ -161,6 +212,44 @@ func TestFetchReleaseManifest(t *testing.T) {
 	assert.Equal(t, LibraryTypeVGPULibrary, result.Libraries[0].Type)
 }
 
+func TestResolveLibrariesForPlatform(t *testing.T) {
+	paths := platform.DefaultPaths().WithConfigDir(t.TempDir())
+	artifact := func(arch, url, libType string) api.ReleaseArtifact {
+		return api.ReleaseArtifact{CPUArch: arch, OS: "linux", URL: url, Metadata: map[string]string{"type": libType}}
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := api.ReleasesResponse{Releases: []api.ReleaseInfo{
+			{
+				Vendor:  api.VendorInfo{Slug: "nvidia", Name: "NVIDIA"},
+				Version: "1.0.0",
+				Artifacts: []api.ReleaseArtifact{
+					artifact("arm64", "https://example.com/arm64/libcuda.so", LibraryTypeVGPULibrary),
+					artifact("amd64", "https://example.com/amd64/libcuda.so", LibraryTypeVGPULibrary),
+					artifact("amd64", "https://example.com/amd64/tensor-fusion-worker", LibraryTypeRemoteGPUWorker),
+				},
+			},
+			{
+				Vendor:    api.VendorInfo{Slug: "amd", Name: "AMD"},
+				Version:   "1.0.0",
+				Artifacts: []api.ReleaseArtifact{artifact("amd64", "https://example.com/amd64/libamdhip64.so", LibraryTypeVGPULibrary)},
+			},
+		}}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	mgr := NewManager(WithPaths(paths), WithAPIClient(api.NewClient(api.WithBaseURL(server.URL))))
+	libs, err := mgr.ResolveLibrariesForPlatform(context.Background(), []string{LibraryTypeVGPULibrary}, "NVIDIA", "linux", "amd64")
+	require.NoError(t, err)
+	require.Len(t, libs, 1)
+	assert.Equal(t, "https://example.com/amd64/libcuda.so", libs[0].URL)
+
+	downloaded, err := mgr.LoadDownloadedManifest()
+	require.NoError(t, err)
+	assert.Empty(t, downloaded.Libraries, "nothing is downloaded")
+}
+
 func TestGetLibrariesForPlatform(t *testing.T) {
 	manifest := &ReleaseManifest{
 		Libraries: []Library{
@@ -360,3 +449,101 @@ func TestInstallLibrary(t *testing.T) {
 	assert.True(t, exists)
 	assert.Equal(t, "1.0.0", installed.Version)
 }
+
+func TestMirrorURL(t *testing.T) {
+	assert.Equal(t, "https://m.example.com/ggo/vgpu/libcuda.so.1",
+		MirrorURL("https://m.example.com/ggo/", "https://cdn.tensor-fusion.ai/vgpu/libcuda.so.1?x=1"))
+	assert.Equal(t, "https://cdn.tensor-fusion.ai/a", MirrorURL("", "https://cdn.tensor-fusion.ai/a"))
+
+	_, err := ParseMirrorURL("ftp://m.example.com")
+	assert.Error(t, err)
+
+	target, err := ParseMirrorTarget("s3://bucket/ggo/")
+	require.NoError(t, err)
+	assert.Equal(t, "https://bucket.s3.amazonaws.com/ggo", target.BaseURL())
+
+	target, err = ParseMirrorTarget(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, target.BaseURL())
+}
+
+func TestMirrorAndDownloadThroughMirror(t *testing.T) {
+	origRegistry := GPUBinaryRegistry
+	GPUBinaryRegistry = nil
+	defer func() { GPUBinaryRegistry = origRegistry }()
+
+	content := []byte("fake libcuda")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	var cdnURL string
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/ecosystem/releases":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(api.ReleasesResponse{Releases: []api.ReleaseInfo{{
+				Vendor:  api.VendorInfo{Slug: "nvidia", Name: "NVIDIA"},
+				Version: "1.0.0",
+				Artifacts: []api.ReleaseArtifact{
+					{OS: "linux", CPUArch: "amd64", URL: cdnURL + "/vgpu/1.0.0/libcuda.so.1", SHA256: checksum},
+					{OS: "windows", CPUArch: "x86_64", URL: cdnURL + "/vgpu/1.0.0/nvcuda.dll", SHA256: checksum},
+				},
+			}}})
+		case "/vgpu/1.0.0/libcuda.so.1", "/vgpu/1.0.0/nvcuda.dll":
+			_, _ = w.Write(content)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer cdn.Close()
+	cdnURL = cdn.URL
+
+	mirrorDir := t.TempDir()
+	target, err := ParseMirrorTarget(mirrorDir)
+	require.NoError(t, err)
+
+	publisher := NewManager(
+		WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())),
+		WithAPIClient(api.NewClient(api.WithBaseURL(cdn.URL))),
+	)
+	_, err = publisher.Mirror(context.Background(), target, "", nil)
+	assert.ErrorContains(t, err, "base URL is required")
+
+	result, err := publisher.Mirror(context.Background(), target, "https://mirror.example.com/ggo", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Artifacts)
+	assert.FileExists(t, filepath.Join(mirrorDir, "vgpu", "1.0.0", "libcuda.so.1"))
+	assert.FileExists(t, filepath.Join(mirrorDir, "vgpu", "1.0.0", "nvcuda.dll"))
+
+	data, err := os.ReadFile(filepath.Join(mirrorDir, ReleaseManifestFile))
+	require.NoError(t, err)
+	var manifest ReleaseManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.Len(t, manifest.Libraries, 2)
+	for _, lib := range manifest.Libraries {
+		assert.True(t, strings.HasPrefix(lib.URL, "https://mirror.example.com/ggo/vgpu/1.0.0/"), lib.URL)
+		assert.Equal(t, checksum, lib.SHA256)
+	}
+
+	// A client configured with the mirror never contacts the original host
+	mirror := httptest.NewServer(http.FileServer(http.Dir(mirrorDir)))
+	defer mirror.Close()
+	paths := platform.DefaultPaths().WithConfigDir(t.TempDir()).WithCacheDir(t.TempDir())
+	signer := newTestSigner(t, "release-1")
+	client := NewManager(WithPaths(paths), signer.trust())
+	require.NoError(t, client.SetMirror(mirror.URL))
+
+	lib := signer.sign(Library{Name: "libcuda.so.1", Version: "1.0.0", Platform: "linux", Arch: "amd64",
+		URL: DefaultCDNBaseURL + "/vgpu/1.0.0/libcuda.so.1", SHA256: checksum})
+	require.NoError(t, client.DownloadLibrary(context.Background(), lib, nil))
+	assert.FileExists(t, client.GetLibraryPath(lib.Name))
+
+	// Redirects from the mirror back to the public CDN are refused
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, DefaultCDNBaseURL+r.URL.Path, http.StatusFound)
+	}))
+	defer redirecting.Close()
+	other := NewManager(WithPaths(paths.WithCacheDir(t.TempDir())), WithMirrorURL(redirecting.URL), signer.trust())
+	err = other.DownloadLibrary(context.Background(), lib, nil)
+	assert.ErrorContains(t, err, "public CDN")
+}
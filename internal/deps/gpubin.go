@@ -1,4 +1,4 @@
-// Package deps manages GPU binary tools like nvidia-smi, amdsmi
+// Package deps manages GPU binary tools like nvidia-smi, rocm-smi
 package deps
 
 import (
@@ -8,11 +8,15 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
 	"k8s.io/klog/v2"
 )
 
@@ -22,36 +26,107 @@ const (
 	osLinux   = "linux"
 )
 
-// GPUBinaryInfo contains the download URL and expected binary name for a GPU tool
+// GPUToolsManifestFile records the installed GPU tool bundles under cache/bin
+const GPUToolsManifestFile = "gpu-tools.json"
+
+// GPUBinaryInfo describes the tool bundle a vendor ships for one platform
 type GPUBinaryInfo struct {
-	URL        string // CDN download URL (zip file)
-	BinaryName string // Expected binary name (e.g., "nvidia-smi", "amdsmi")
+	URL string // CDN download URL (zip file)
+	// Tools are the executables in the bundle, primary tool first
+	// (e.g., "rocm-smi", "rocminfo"). Other files in the zip, such as
+	// libraries the tools load, are installed alongside them.
+	Tools []string
 }
 
-// GPUBinaryRegistry maps vendor -> os -> arch -> GPUBinaryInfo
+// GPUBinaryRegistry maps vendor -> os -> arch -> GPUBinaryInfo. Entries with
+// an empty URL list the tools of a bundle that is not published on the CDN
+// yet; GetGPUBinaryInfo skips them.
 // TODO: Fill in actual CDN URLs
 var GPUBinaryRegistry = map[string]map[string]map[string]GPUBinaryInfo{
 	"nvidia": {
 		"linux": {
-			"amd64": {URL: "https://cdn.tensor-fusion.ai/nvidia-smi-linux-amd64-550.54.15.zip", BinaryName: "nvidia-smi"},
-			"arm64": {URL: "https://cdn.tensor-fusion.ai/nvidia-smi-linux-arm64-550.54.15.zip", BinaryName: "nvidia-smi"},
+			"amd64": {URL: "https://cdn.tensor-fusion.ai/nvidia-smi-linux-amd64-550.54.15.zip", Tools: []string{"nvidia-smi"}},
+			"arm64": {URL: "https://cdn.tensor-fusion.ai/nvidia-smi-linux-arm64-550.54.15.zip", Tools: []string{"nvidia-smi"}},
 		},
 		"windows": {
-			"amd64": {URL: "https://cdn.tensor-fusion.ai/nvidia-smi-windows-amd64-550.54.15.zip", BinaryName: "nvidia-smi"},
+			"amd64": {URL: "https://cdn.tensor-fusion.ai/nvidia-smi-windows-amd64-550.54.15.zip", Tools: []string{"nvidia-smi"}},
 		},
 	},
 	"amd": {
 		"linux": {
-			"amd64": {URL: "", BinaryName: "amdsmi"},
-			"arm64": {URL: "", BinaryName: "amdsmi"},
+			"amd64": {URL: "", Tools: []string{"rocm-smi", "rocminfo"}},
+			"arm64": {URL: "", Tools: []string{"rocm-smi", "rocminfo"}},
+		},
+	},
+	"intel": {
+		"linux": {
+			"amd64": {URL: "", Tools: []string{"xpu-smi"}},
+		},
+		"windows": {
+			"amd64": {URL: "", Tools: []string{"xpu-smi"}},
+		},
+	},
+	"mthreads": {
+		"linux": {
+			"amd64": {URL: "", Tools: []string{"mthreads-gmi"}},
+			"arm64": {URL: "", Tools: []string{"mthreads-gmi"}},
+		},
+	},
+	"cambricon": {
+		"linux": {
+			"amd64": {URL: "", Tools: []string{"cnmon"}},
+			"arm64": {URL: "", Tools: []string{"cnmon"}},
 		},
 	},
 }
 
-// GetGPUBinaryInfo returns the download URL and binary name for a GPU vendor/os/arch combination
-// Returns nil if no binary is available for this combination
-func GetGPUBinaryInfo(vendor, osName, arch string) *GPUBinaryInfo {
-	vendor = strings.ToLower(vendor)
+// gpuToolVendorAliases maps hardware vendor names to the registry vendor
+// whose tools they use
+var gpuToolVendorAliases = map[string]string{
+	// Hygon DCUs run a ROCm-derived stack and work with the AMD tools
+	VendorHygon: VendorAMD,
+}
+
+// GPUToolBundle is a vendor tool bundle installed in its own bin directory
+type GPUToolBundle struct {
+	Vendor      string    `json:"vendor"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	URL         string    `json:"url"`
+	Dir         string    `json:"dir"`
+	Tools       []string  `json:"tools"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// ToolPath returns the path of a tool in the bundle directory
+func (b *GPUToolBundle) ToolPath(tool string) string {
+	if b.OS == osWindows {
+		tool += ".exe"
+	}
+	return filepath.Join(b.Dir, tool)
+}
+
+// installed reports whether every tool of the bundle is present on disk
+func (b *GPUToolBundle) installed() bool {
+	for _, tool := range b.Tools {
+		if _, err := os.Stat(b.ToolPath(tool)); err != nil {
+			return false
+		}
+	}
+	return len(b.Tools) > 0
+}
+
+// gpuToolsManifest is the on-disk record of installed bundles
+type gpuToolsManifest struct {
+	Bundles []GPUToolBundle `json:"bundles"`
+}
+
+// normalizeGPUToolTarget resolves vendor aliases and common arch aliases
+func normalizeGPUToolTarget(vendor, osName, arch string) (string, string, string) {
+	vendor = NormalizeVendorSlug(vendor)
+	if alias, ok := gpuToolVendorAliases[vendor]; ok {
+		vendor = alias
+	}
 	osName = strings.ToLower(osName)
 	arch = strings.ToLower(arch)
 
@@ -62,11 +137,18 @@ func GetGPUBinaryInfo(vendor, osName, arch string) *GPUBinaryInfo {
 	if arch == "aarch64" {
 		arch = "arm64"
 	}
+	return vendor, osName, arch
+}
+
+// GetGPUBinaryInfo returns the tool bundle for a GPU vendor/os/arch combination
+// Returns nil if no bundle is available for this combination
+func GetGPUBinaryInfo(vendor, osName, arch string) *GPUBinaryInfo {
+	vendor, osName, arch = normalizeGPUToolTarget(vendor, osName, arch)
 
 	if osMap, ok := GPUBinaryRegistry[vendor]; ok {
 		if archMap, ok := osMap[osName]; ok {
 			if info, ok := archMap[arch]; ok {
-				if info.URL != "" {
+				if info.URL != "" && len(info.Tools) > 0 {
 					return &info
 				}
 			}
@@ -75,63 +157,133 @@ func GetGPUBinaryInfo(vendor, osName, arch string) *GPUBinaryInfo {
 	return nil
 }
 
-// GetGPUBinaryPath returns the expected path for a GPU binary in the cache
-// The binary will be stored in ~/.gpugo/cache/bin/
-func GetGPUBinaryPath(paths *platform.Paths, binaryName string) string {
-	binDir := filepath.Join(paths.CacheDir(), "bin")
-	name := binaryName
-	if runtime.GOOS == osWindows {
-		name += ".exe"
+// GPUBinDir returns the bin directory holding a vendor's tools for a platform:
+// ~/.gpugo/cache/bin/{vendor}/{os}-{arch}. Each vendor gets its own directory
+// so that only the tools matching the remote GPU end up on PATH.
+func GPUBinDir(paths *platform.Paths, vendor, osName, arch string) string {
+	vendor, osName, arch = normalizeGPUToolTarget(vendor, osName, arch)
+	if vendor == "" {
+		vendor = "unknown"
 	}
-	return filepath.Join(binDir, name)
+	return filepath.Join(paths.CacheDir(), "bin", vendor, osName+"-"+arch)
+}
+
+func gpuToolsManifestPath(paths *platform.Paths) string {
+	return filepath.Join(paths.CacheDir(), "bin", GPUToolsManifestFile)
 }
 
-// EnsureGPUBinary ensures the GPU binary (like nvidia-smi) exists in the cache
-// Downloads and extracts if not present
-// Returns the path to the binary, or empty string if not available for this platform
-func EnsureGPUBinary(ctx context.Context, paths *platform.Paths, vendor string) (string, error) {
+// LoadGPUToolBundles returns the installed GPU tool bundles
+func LoadGPUToolBundles(paths *platform.Paths) ([]GPUToolBundle, error) {
+	manifest, err := utils.LoadJSON[gpuToolsManifest](gpuToolsManifestPath(paths))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GPU tools manifest: %w", err)
+	}
+	if manifest == nil {
+		return nil, nil
+	}
+	return manifest.Bundles, nil
+}
+
+// recordGPUToolBundle adds or replaces a bundle in the manifest
+func recordGPUToolBundle(paths *platform.Paths, bundle GPUToolBundle) error {
+	bundles, err := LoadGPUToolBundles(paths)
+	if err != nil {
+		klog.Warningf("Rewriting unreadable GPU tools manifest: %v", err)
+		bundles = nil
+	}
+	bundles = slices.DeleteFunc(bundles, func(b GPUToolBundle) bool {
+		return b.Vendor == bundle.Vendor && b.OS == bundle.OS && b.Arch == bundle.Arch
+	})
+	bundles = append(bundles, bundle)
+	return utils.SaveJSON(gpuToolsManifestPath(paths), gpuToolsManifest{Bundles: bundles}, 0644)
+}
+
+// findGPUToolBundle returns the manifest entry for a vendor/os/arch
+func findGPUToolBundle(paths *platform.Paths, vendor, osName, arch string) *GPUToolBundle {
+	bundles, err := LoadGPUToolBundles(paths)
+	if err != nil {
+		klog.V(2).Infof("Ignoring GPU tools manifest: %v", err)
+		return nil
+	}
+	for i := range bundles {
+		if bundles[i].Vendor == vendor && bundles[i].OS == osName && bundles[i].Arch == arch {
+			return &bundles[i]
+		}
+	}
+	return nil
+}
+
+// EnsureGPUBinary ensures the vendor's GPU tool bundle (like nvidia-smi or
+// rocm-smi + rocminfo) is installed in the cache. Downloads and extracts if
+// not present or if the registry points at a newer bundle.
+// Returns nil if no bundle is available for this platform
+func EnsureGPUBinary(ctx context.Context, paths *platform.Paths, vendor string) (*GPUToolBundle, error) {
 	return EnsureGPUBinaryForPlatform(ctx, paths, vendor, runtime.GOOS, runtime.GOARCH)
 }
 
-// EnsureGPUBinaryForPlatform ensures the GPU binary exists for a specific platform
+// EnsureGPUBinaryForPlatform ensures the GPU tool bundle exists for a specific platform
 // Used when preparing binaries for containers (e.g., Linux containers on macOS)
-func EnsureGPUBinaryForPlatform(ctx context.Context, paths *platform.Paths, vendor, osName, arch string) (string, error) {
+func EnsureGPUBinaryForPlatform(ctx context.Context, paths *platform.Paths, vendor, osName, arch string) (*GPUToolBundle, error) {
 	info := GetGPUBinaryInfo(vendor, osName, arch)
 	if info == nil {
 		klog.V(2).Infof("No GPU binary available for vendor=%s os=%s arch=%s", vendor, osName, arch)
-		return "", nil
+		return nil, nil
 	}
+	vendor, osName, arch = normalizeGPUToolTarget(vendor, osName, arch)
+	binDir := GPUBinDir(paths, vendor, osName, arch)
 
-	binDir := filepath.Join(paths.CacheDir(), "bin")
-	if err := os.MkdirAll(binDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create bin directory: %w", err)
+	if existing := findGPUToolBundle(paths, vendor, osName, arch); existing != nil &&
+		existing.URL == info.URL && existing.Dir == binDir && existing.installed() {
+		klog.V(2).Infof("GPU tools already installed: vendor=%s dir=%s", vendor, binDir)
+		return existing, nil
 	}
 
-	binaryName := info.BinaryName
-	if osName == osWindows {
-		binaryName += ".exe"
+	if err := os.MkdirAll(filepath.Dir(binDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create bin directory: %w", err)
 	}
-	binaryPath := filepath.Join(binDir, binaryName)
 
-	// Check if binary already exists
-	if _, err := os.Stat(binaryPath); err == nil {
-		klog.V(2).Infof("GPU binary already exists: %s", binaryPath)
-		return binaryPath, nil
+	// Download and extract, through the deps mirror if one is configured
+	url := MirrorURL(NewManager(WithPaths(paths)).mirrorBaseURL(), info.URL)
+	klog.Infof("Downloading GPU tools: vendor=%s os=%s arch=%s tools=%s url=%s",
+		vendor, osName, arch, strings.Join(info.Tools, ","), url)
+
+	// Extract next to the final directory and swap it in, so a failed
+	// download never leaves a half-installed bundle on PATH
+	stagingDir, err := os.MkdirTemp(filepath.Dir(binDir), ".staging-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
 	}
+	defer func() { _ = os.RemoveAll(stagingDir) }()
 
-	// Download and extract
-	klog.Infof("Downloading GPU binary: vendor=%s os=%s arch=%s url=%s", vendor, osName, arch, info.URL)
+	if err := downloadAndExtractGPUBinary(ctx, url, stagingDir, info.Tools, osName); err != nil {
+		return nil, fmt.Errorf("failed to download GPU binary: %w", err)
+	}
+	if err := os.RemoveAll(binDir); err != nil {
+		return nil, fmt.Errorf("failed to remove old GPU tools: %w", err)
+	}
+	if err := os.Rename(stagingDir, binDir); err != nil {
+		return nil, fmt.Errorf("failed to install GPU tools: %w", err)
+	}
 
-	if err := downloadAndExtractGPUBinary(ctx, info.URL, binDir, binaryName); err != nil {
-		return "", fmt.Errorf("failed to download GPU binary: %w", err)
+	bundle := GPUToolBundle{
+		Vendor:      vendor,
+		OS:          osName,
+		Arch:        arch,
+		URL:         info.URL,
+		Dir:         binDir,
+		Tools:       info.Tools,
+		InstalledAt: time.Now(),
+	}
+	if err := recordGPUToolBundle(paths, bundle); err != nil {
+		klog.Warningf("Failed to update GPU tools manifest: %v", err)
 	}
 
-	klog.Infof("GPU binary downloaded successfully: %s", binaryPath)
-	return binaryPath, nil
+	klog.Infof("GPU tools installed successfully: %s", binDir)
+	return &bundle, nil
 }
 
-// downloadAndExtractGPUBinary downloads a ZIP file and extracts the binary
-func downloadAndExtractGPUBinary(ctx context.Context, url, destDir, expectedBinaryName string) error {
+// downloadAndExtractGPUBinary downloads a ZIP file and extracts the tool bundle
+func downloadAndExtractGPUBinary(ctx context.Context, url, destDir string, tools []string, osName string) error {
 	// Create temporary file for download
 	tmpFile, err := os.CreateTemp("", "gpubin-*.zip")
 	if err != nil {
@@ -167,79 +319,107 @@ func downloadAndExtractGPUBinary(ctx context.Context, url, destDir, expectedBina
 	_ = tmpFile.Close()
 
 	// Extract the ZIP file
-	return extractZipBinary(tmpPath, destDir, expectedBinaryName)
+	return extractZipBundle(tmpPath, destDir, tools, osName)
 }
 
-// extractZipBinary extracts a binary from a ZIP file
-// If the ZIP contains a directory, it takes the first file from that directory
-func extractZipBinary(zipPath, destDir, expectedBinaryName string) error {
+// extractZipBundle extracts a tool bundle from a ZIP file into destDir.
+// A single top-level directory in the archive is stripped. A single-file
+// archive of a single-tool bundle is installed under the tool's name, which
+// keeps older one-binary archives working. Every tool must end up in destDir.
+func extractZipBundle(zipPath, destDir string, tools []string, osName string) error {
 	reader, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return fmt.Errorf("failed to open zip: %w", err)
 	}
 	defer func() { _ = reader.Close() }()
 
-	if len(reader.File) == 0 {
-		return fmt.Errorf("zip file is empty")
+	var files []*zip.File
+	for _, file := range reader.File {
+		if !file.FileInfo().IsDir() {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no file found in zip")
 	}
 
-	// Find the binary file to extract
-	var targetFile *zip.File
-	for _, file := range reader.File {
-		if file.FileInfo().IsDir() {
-			continue
+	exe := func(tool string) string {
+		if osName == osWindows {
+			return tool + ".exe"
 		}
-		// Use the first non-directory file
-		targetFile = file
-		break
+		return tool
 	}
 
-	if targetFile == nil {
-		return fmt.Errorf("no file found in zip")
+	prefix := commonZipDir(files)
+	for _, file := range files {
+		rel := strings.TrimPrefix(path.Clean(file.Name), prefix)
+		if len(files) == 1 && len(tools) == 1 {
+			rel = exe(tools[0])
+		}
+		if rel == "" || path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return fmt.Errorf("invalid path in zip: %s", file.Name)
+		}
+
+		mode := file.Mode().Perm() | 0644
+		if slices.ContainsFunc(tools, func(t string) bool { return exe(t) == rel }) {
+			mode = 0755
+		}
+
+		klog.V(2).Infof("Extracting %s from zip", file.Name)
+		if err := extractZipFile(file, filepath.Join(destDir, filepath.FromSlash(rel)), mode); err != nil {
+			return err
+		}
+	}
+
+	for _, tool := range tools {
+		if _, err := os.Stat(filepath.Join(destDir, exe(tool))); err != nil {
+			return fmt.Errorf("bundle does not contain %s", exe(tool))
+		}
+	}
+	return nil
+}
+
+// commonZipDir returns "dir/" if every file sits under the same top-level directory
+func commonZipDir(files []*zip.File) string {
+	first, _, ok := strings.Cut(path.Clean(files[0].Name), "/")
+	if !ok {
+		return ""
+	}
+	for _, file := range files[1:] {
+		if dir, _, ok := strings.Cut(path.Clean(file.Name), "/"); !ok || dir != first {
+			return ""
+		}
 	}
+	return first + "/"
+}
 
-	klog.V(2).Infof("Extracting %s from zip", targetFile.Name)
+// extractZipFile writes one zip entry to destPath
+func extractZipFile(file *zip.File, destPath string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
 
-	// Open the file in the ZIP
-	rc, err := targetFile.Open()
+	rc, err := file.Open()
 	if err != nil {
 		return fmt.Errorf("failed to open file in zip: %w", err)
 	}
 	defer func() { _ = rc.Close() }()
 
-	// Create destination file with expected name
-	destPath := filepath.Join(destDir, expectedBinaryName)
-	destFile, err := os.Create(destPath)
+	destFile, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
 	defer func() { _ = destFile.Close() }()
 
-	// Copy contents
 	if _, err := io.Copy(destFile, rc); err != nil {
-		_ = os.Remove(destPath)
 		return fmt.Errorf("failed to extract file: %w", err)
 	}
 
-	// Set executable permission on Unix
+	// Set permissions explicitly; the umask may have narrowed them on create
 	if runtime.GOOS != osWindows {
-		if err := os.Chmod(destPath, 0755); err != nil {
+		if err := os.Chmod(destPath, mode); err != nil {
 			return fmt.Errorf("failed to set permissions: %w", err)
 		}
 	}
-
 	return nil
 }
-
-// GetGPUBinaryName returns the expected binary name for a vendor (without extension)
-func GetGPUBinaryName(vendor string) string {
-	vendor = strings.ToLower(vendor)
-	switch vendor {
-	case "nvidia":
-		return "nvidia-smi"
-	case "amd", "hygon":
-		return "amdsmi"
-	default:
-		return ""
-	}
-}
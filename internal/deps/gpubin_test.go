@@ -0,0 +1,131 @@
+package deps
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestEnsureGPUBinary_InstallsBundle(t *testing.T) {
+	origRegistry := GPUBinaryRegistry
+	defer func() { GPUBinaryRegistry = origRegistry }()
+
+	downloads := 0
+	archives := map[string][]byte{
+		"/rocm-6.2.zip": makeZip(t, map[string]string{
+			"rocm/rocm-smi":             "#!/bin/sh\n",
+			"rocm/rocminfo":             "#!/bin/sh\n",
+			"rocm/lib/librocm_smi64.so": "lib",
+		}),
+		"/rocm-6.3.zip": makeZip(t, map[string]string{
+			"rocm-smi": "#!/bin/sh\necho 6.3\n",
+			"rocminfo": "#!/bin/sh\n",
+		}),
+		"/broken.zip": makeZip(t, map[string]string{"rocm-smi": "x"}),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		if data, ok := archives[r.URL.Path]; ok {
+			_, _ = w.Write(data)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	setURL := func(path string) {
+		GPUBinaryRegistry = map[string]map[string]map[string]GPUBinaryInfo{
+			"amd": {"linux": {"amd64": {URL: srv.URL + path, Tools: []string{"rocm-smi", "rocminfo"}}}},
+		}
+	}
+	paths := platform.DefaultPaths().WithConfigDir(t.TempDir()).WithCacheDir(t.TempDir())
+	ctx := context.Background()
+
+	setURL("/rocm-6.2.zip")
+	bundle, err := EnsureGPUBinaryForPlatform(ctx, paths, "hygon", "linux", "x86_64")
+	require.NoError(t, err)
+	require.NotNil(t, bundle)
+	assert.Equal(t, "amd", bundle.Vendor)
+	assert.Equal(t, GPUBinDir(paths, "amd", "linux", "amd64"), bundle.Dir)
+	assert.Equal(t, []string{"rocm-smi", "rocminfo"}, bundle.Tools)
+	assert.FileExists(t, filepath.Join(bundle.Dir, "lib", "librocm_smi64.so"))
+	if runtime.GOOS != osWindows {
+		info, err := os.Stat(bundle.ToolPath("rocminfo"))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&0100, "tools are executable")
+	}
+
+	bundles, err := LoadGPUToolBundles(paths)
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, srv.URL+"/rocm-6.2.zip", bundles[0].URL)
+
+	// Installed bundle is reused
+	_, err = EnsureGPUBinaryForPlatform(ctx, paths, "amd", "linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, 1, downloads)
+
+	// A new bundle URL replaces the installed one
+	setURL("/rocm-6.3.zip")
+	bundle, err = EnsureGPUBinaryForPlatform(ctx, paths, "amd", "linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, 2, downloads)
+	assert.NoFileExists(t, filepath.Join(bundle.Dir, "lib", "librocm_smi64.so"))
+	data, err := os.ReadFile(bundle.ToolPath("rocm-smi"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "6.3")
+
+	// A bundle missing a tool fails and keeps the installed one
+	setURL("/broken.zip")
+	_, err = EnsureGPUBinaryForPlatform(ctx, paths, "amd", "linux", "amd64")
+	assert.ErrorContains(t, err, "rocminfo")
+	assert.FileExists(t, bundle.ToolPath("rocminfo"))
+
+	// Vendors without a bundle return nil
+	bundle, err = EnsureGPUBinaryForPlatform(ctx, paths, "intel", "linux", "amd64")
+	require.NoError(t, err)
+	assert.Nil(t, bundle)
+}
+
+func TestExtractZipBundle_SingleBinaryArchive(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "nvidia.zip")
+	require.NoError(t, os.WriteFile(zipPath, makeZip(t, map[string]string{"nvidia-smi-550/smi": "bin"}), 0644))
+
+	dest := t.TempDir()
+	require.NoError(t, extractZipBundle(zipPath, dest, []string{"nvidia-smi"}, osWindows))
+	assert.FileExists(t, filepath.Join(dest, "nvidia-smi.exe"))
+}
+
+func TestExtractZipBundle_RejectsEscapingPaths(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "evil.zip")
+	require.NoError(t, os.WriteFile(zipPath, makeZip(t, map[string]string{
+		"xpu-smi":      "bin",
+		"../../escape": "x",
+	}), 0644))
+
+	assert.ErrorContains(t, extractZipBundle(zipPath, t.TempDir(), []string{"xpu-smi"}, osLinux), "invalid path")
+}
@@ -0,0 +1,168 @@
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+// LockfileName is the project lockfile looked up by 'ggo use' and
+// 'ggo studio create' in the working directory
+const LockfileName = "ggo.lock"
+
+// Lockfile pins the exact libraries a project runs against so every member
+// of a team downloads the same versions and hashes
+type Lockfile struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Libraries   []Library `json:"libraries"`
+}
+
+// WithLockfile selects library versions from lock instead of the release
+// channel and pins for every library type the lock covers
+func WithLockfile(lock *Lockfile) ManagerOption {
+	return func(m *Manager) {
+		m.lock = lock
+	}
+}
+
+// LoadLockfile reads a lockfile
+func LoadLockfile(path string) (*Lockfile, error) {
+	lock, err := utils.LoadJSON[Lockfile](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if lock == nil {
+		return nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+	return lock, nil
+}
+
+// FindLockfile loads the ggo.lock in dir; it returns nil without an error
+// when the project has no lockfile
+func FindLockfile(dir string) (*Lockfile, string, error) {
+	path := filepath.Join(dir, LockfileName)
+	lock, err := LoadLockfile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return lock, path, nil
+}
+
+// Save writes the lockfile with its libraries in a stable order, so the
+// file diffs cleanly under version control
+func (l *Lockfile) Save(path string) error {
+	sort.Slice(l.Libraries, func(i, j int) bool {
+		if l.Libraries[i].Type != l.Libraries[j].Type {
+			return l.Libraries[i].Type < l.Libraries[j].Type
+		}
+		return l.Libraries[i].Key() < l.Libraries[j].Key()
+	})
+	return utils.SaveJSON(path, l, 0644)
+}
+
+// Lock builds a lockfile from the libraries downloaded for this machine
+func (m *Manager) Lock() (*Lockfile, error) {
+	downloaded, err := m.LoadDownloadedManifest()
+	if err != nil {
+		return nil, err
+	}
+	if downloaded == nil || len(downloaded.Libraries) == 0 {
+		return nil, fmt.Errorf("no libraries are downloaded yet; run 'ggo deps download' or 'ggo use' first")
+	}
+	lock := &Lockfile{GeneratedAt: time.Now()}
+	for _, lib := range downloaded.Libraries {
+		if lib.Type == "" {
+			continue
+		}
+		lock.Libraries = append(lock.Libraries, lib)
+	}
+	return lock, nil
+}
+
+// ForPlatform returns the locked libraries built for osStr/arch
+func (l *Lockfile) ForPlatform(osStr, arch string) []Library {
+	var libs []Library
+	for _, lib := range l.Libraries {
+		if lib.Platform == osStr && lib.Arch == arch {
+			libs = append(libs, lib)
+		}
+	}
+	return libs
+}
+
+// Check returns an error unless lib is the exact library recorded in the lock
+func (l *Lockfile) Check(lib Library) error {
+	for _, locked := range l.Libraries {
+		if locked.Key() != lib.Key() {
+			continue
+		}
+		if locked.Version != lib.Version {
+			return fmt.Errorf("%s %s is not in %s (locked: %s)", lib.Name, lib.Version, LockfileName, locked.Version)
+		}
+		if locked.SHA256 != "" && lib.SHA256 != "" && locked.SHA256 != lib.SHA256 {
+			return fmt.Errorf("%s %s has hash %s, but %s records %s", lib.Name, lib.Version, lib.SHA256, LockfileName, locked.SHA256)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s (%s/%s) is not in %s", lib.Name, lib.Platform, lib.Arch, LockfileName)
+}
+
+// lockedLibraries selects the libraries of libType according to the lock.
+// Locked entries are used as recorded, hash included, for the platforms the
+// lock covers. Other platforms in released get the locked version, since
+// hashes differ per platform. ok is false when the lock does not cover libType.
+func (l *Lockfile) lockedLibraries(libType string, released map[string][]Library) (libs []Library, ok bool) {
+	var version string
+	covered := make(map[string]bool)
+	for _, lib := range l.Libraries {
+		if lib.Type != libType {
+			continue
+		}
+		version = lib.Version
+		covered[lib.Platform+"/"+lib.Arch] = true
+	}
+	if version == "" {
+		return nil, false
+	}
+
+	seen := make(map[string]bool)
+	for _, versionLibs := range released {
+		for _, lib := range versionLibs {
+			platform := lib.Platform + "/" + lib.Arch
+			if seen[platform] {
+				continue
+			}
+			seen[platform] = true
+			if covered[platform] {
+				continue
+			}
+			matched := 0
+			for _, candidate := range released[version] {
+				if candidate.Platform == lib.Platform && candidate.Arch == lib.Arch {
+					libs = append(libs, candidate)
+					matched++
+				}
+			}
+			if matched == 0 {
+				klog.Warningf("Locked %s version %s is not released for %s", libType, version, platform)
+			} else {
+				klog.Warningf("%s has no %s entry for %s; using locked version %s without hash pinning", LockfileName, libType, platform, version)
+			}
+		}
+	}
+	for _, lib := range l.Libraries {
+		if lib.Type == libType && seen[lib.Platform+"/"+lib.Arch] {
+			libs = append(libs, lib)
+		}
+	}
+	return libs, true
+}
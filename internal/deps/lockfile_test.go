@@ -0,0 +1,86 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockfile_SaveAndFind(t *testing.T) {
+	mgr := NewManager(WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())))
+
+	_, err := mgr.Lock()
+	assert.Error(t, err, "nothing downloaded yet")
+
+	client := Library{Name: "libcuda.so.1", Version: "1.2.0", Platform: "linux", Arch: "amd64", SHA256: "aaa", Type: LibraryTypeRemoteGPUClient}
+	vgpu := Library{Name: "libaccel.so", Version: "1.42.0", Platform: "linux", Arch: "amd64", SHA256: "bbb", Type: LibraryTypeVGPULibrary}
+	require.NoError(t, mgr.updateDownloadedManifestUnsafe(vgpu))
+	require.NoError(t, mgr.updateDownloadedManifestUnsafe(client))
+
+	lock, err := mgr.Lock()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	found, _, err := FindLockfile(dir)
+	require.NoError(t, err)
+	assert.Nil(t, found, "a project without a lockfile")
+
+	require.NoError(t, lock.Save(filepath.Join(dir, LockfileName)))
+	found, path, err := FindLockfile(dir)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, filepath.Join(dir, LockfileName), path)
+	assert.Equal(t, []Library{client, vgpu}, found.Libraries, "sorted by type")
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, _, err = FindLockfile(dir)
+	assert.Error(t, err, "a corrupt lockfile is not ignored")
+}
+
+func TestLockfile_Check(t *testing.T) {
+	locked := Library{Name: "libaccel.so", Version: "1.42.0", Platform: "linux", Arch: "amd64", SHA256: "aaa", Type: LibraryTypeVGPULibrary}
+	lock := &Lockfile{Libraries: []Library{locked}}
+
+	assert.NoError(t, lock.Check(locked))
+
+	newer := locked
+	newer.Version = "1.43.0"
+	assert.ErrorContains(t, lock.Check(newer), "locked: 1.42.0")
+
+	republished := locked
+	republished.SHA256 = "bbb"
+	assert.ErrorContains(t, lock.Check(republished), "records aaa")
+
+	other := locked
+	other.Arch = "arm64"
+	assert.ErrorContains(t, lock.Check(other), "is not in ggo.lock")
+}
+
+func TestSelectRequiredDeps_Lockfile(t *testing.T) {
+	manifest := &ReleaseManifest{
+		Libraries: []Library{
+			{Name: "libaccel.so", Version: "1.41.0", Platform: "linux", Arch: "amd64", SHA256: "old-amd64", Type: LibraryTypeVGPULibrary},
+			{Name: "libaccel.so", Version: "1.42.0", Platform: "linux", Arch: "amd64", SHA256: "new-amd64", Type: LibraryTypeVGPULibrary},
+			{Name: "libaccel.so", Version: "1.41.0", Platform: "linux", Arch: "arm64", SHA256: "old-arm64", Type: LibraryTypeVGPULibrary},
+			{Name: "libaccel.so", Version: "1.42.0", Platform: "linux", Arch: "arm64", SHA256: "new-arm64", Type: LibraryTypeVGPULibrary},
+			{Name: "libcuda.so.1", Version: "2.0.0", Platform: "linux", Arch: "amd64", Type: LibraryTypeRemoteGPUClient},
+		},
+	}
+	// Locked on amd64 only, with the hash recorded at lock time
+	locked := Library{Name: "libaccel.so", Version: "1.41.0", Platform: "linux", Arch: "amd64", SHA256: "locked-amd64", Type: LibraryTypeVGPULibrary}
+	mgr := NewManager(
+		WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())),
+		WithLockfile(&Lockfile{Libraries: []Library{locked}}),
+	)
+
+	deps := mgr.SelectRequiredDeps(manifest)
+	assert.Equal(t, locked, deps.Libraries["libaccel.so:linux:amd64"], "the lock entry is used as recorded")
+	arm := deps.Libraries["libaccel.so:linux:arm64"]
+	assert.Equal(t, "1.41.0", arm.Version, "other platforms get the locked version")
+	assert.Equal(t, "old-arm64", arm.SHA256)
+	assert.Equal(t, "2.0.0", deps.Libraries["libcuda.so.1:linux:amd64"].Version, "types outside the lock follow the channel")
+}
@@ -0,0 +1,423 @@
+package deps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// MirrorURL rewrites an artifact URL to the same path under a mirror base URL.
+// The path layout of the CDN is kept, so a mirror is a plain copy of it.
+func MirrorURL(baseURL, original string) string {
+	if baseURL == "" {
+		return original
+	}
+	u, err := url.Parse(original)
+	if err != nil || u.Path == "" {
+		return original
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(u.Path, "/")
+}
+
+// ParseMirrorURL validates a mirror base URL; empty clears the mirror
+func ParseMirrorURL(s string) (string, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "/")
+	if s == "" {
+		return "", nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid mirror URL %q: must be an http(s) URL", s)
+	}
+	return s, nil
+}
+
+// SetMirror sets the base URL that artifacts are downloaded from instead of
+// the public CDN; an empty URL restores the CDN
+func (m *Manager) SetMirror(mirrorURL string) error {
+	mirrorURL, err := ParseMirrorURL(mirrorURL)
+	if err != nil {
+		return err
+	}
+	settings, err := m.LoadSettings()
+	if err != nil {
+		return err
+	}
+	settings.Mirror = mirrorURL
+	return m.SaveSettings(settings)
+}
+
+// mirrorBaseURL returns the WithMirrorURL override or the saved mirror
+func (m *Manager) mirrorBaseURL() string {
+	if m.mirror != "" {
+		return m.mirror
+	}
+	return m.effectiveSettings().Mirror
+}
+
+// artifactRequest builds the download request for an artifact, going through
+// the mirror when one is configured. Checksums are those of the original
+// artifact, so a mirror can't substitute content.
+func (m *Manager) artifactRequest(ctx context.Context, artifactURL string) (*http.Request, *http.Client, error) {
+	client := m.httpClient
+	if mirror := m.mirrorBaseURL(); mirror != "" {
+		artifactURL = MirrorURL(mirror, artifactURL)
+		client = mirrorHTTPClient(m.httpClient)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifactURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return req, client, nil
+}
+
+// mirrorHTTPClient follows the mirror's redirects (e.g. to presigned object
+// storage URLs) but refuses any that lead back to the public CDN
+func mirrorHTTPClient(base *http.Client) *http.Client {
+	c := *base
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		if isPublicCDN(req.URL) {
+			return fmt.Errorf("mirror redirected to the public CDN (%s)", req.URL.Host)
+		}
+		return nil
+	}
+	return &c
+}
+
+func isPublicCDN(u *url.URL) bool {
+	cdn, err := url.Parse(DefaultCDNBaseURL)
+	return err == nil && strings.EqualFold(u.Hostname(), cdn.Hostname())
+}
+
+// MirrorTarget is where `ggo deps mirror` publishes artifacts
+type MirrorTarget interface {
+	// Put stores the file at relPath under the mirror root
+	Put(ctx context.Context, relPath, file string) error
+	// BaseURL is the URL clients reach the mirror root at, if it can be derived
+	BaseURL() string
+	String() string
+}
+
+// ParseMirrorTarget parses s3://bucket[/prefix], http(s)://root (uploaded
+// with PUT) or a local directory
+func ParseMirrorTarget(target string) (MirrorTarget, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("mirror target is required")
+	}
+	switch {
+	case strings.HasPrefix(target, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(target, "s3://"), "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("invalid S3 target %q: missing bucket", target)
+		}
+		return &s3Target{bucket: bucket, prefix: strings.Trim(prefix, "/")}, nil
+	case strings.HasPrefix(target, "http://"), strings.HasPrefix(target, "https://"):
+		root, err := ParseMirrorURL(target)
+		if err != nil {
+			return nil, err
+		}
+		return &httpTarget{root: root, client: &http.Client{Timeout: 10 * time.Minute}}, nil
+	default:
+		root := strings.TrimPrefix(target, "file://")
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid directory target %q: %w", target, err)
+		}
+		return &dirTarget{root: abs}, nil
+	}
+}
+
+// dirTarget writes into a directory served by any static web server
+type dirTarget struct {
+	root string
+}
+
+func (t *dirTarget) Put(_ context.Context, relPath, file string) error {
+	dest := filepath.Join(t.root, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	tmp := dest + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmp, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func (t *dirTarget) BaseURL() string { return "" }
+func (t *dirTarget) String() string  { return t.root }
+
+// httpTarget uploads with PUT, e.g. to a WebDAV or artifact repository root
+type httpTarget struct {
+	root   string
+	client *http.Client
+}
+
+func (t *httpTarget) Put(ctx context.Context, relPath, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	dest := t.root + "/" + relPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, dest, f)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = info.Size()
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", dest, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to upload %s: status %d", dest, resp.StatusCode)
+	}
+	return nil
+}
+
+func (t *httpTarget) BaseURL() string { return t.root }
+func (t *httpTarget) String() string  { return t.root }
+
+// s3Target uploads with the aws CLI so that its credential chain and
+// endpoint settings (e.g. for MinIO) apply unchanged
+type s3Target struct {
+	bucket string
+	prefix string
+}
+
+func (t *s3Target) key(relPath string) string {
+	if t.prefix == "" {
+		return relPath
+	}
+	return t.prefix + "/" + relPath
+}
+
+func (t *s3Target) Put(ctx context.Context, relPath, file string) error {
+	if _, err := exec.LookPath("aws"); err != nil {
+		return fmt.Errorf("aws CLI is required for S3 targets: %w", err)
+	}
+	dest := fmt.Sprintf("s3://%s/%s", t.bucket, t.key(relPath))
+	out, err := exec.CommandContext(ctx, "aws", "s3", "cp", "--only-show-errors", file, dest).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w: %s", dest, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (t *s3Target) BaseURL() string {
+	base := fmt.Sprintf("https://%s.s3.amazonaws.com", t.bucket)
+	if t.prefix != "" {
+		base += "/" + t.prefix
+	}
+	return base
+}
+
+func (t *s3Target) String() string {
+	return "s3://" + t.key("")
+}
+
+// MirrorResult summarizes a mirror run
+type MirrorResult struct {
+	Target    string    `json:"target"`
+	BaseURL   string    `json:"baseUrl"`
+	Artifacts int       `json:"artifacts"`
+	Bytes     int64     `json:"bytes"`
+	Manifest  string    `json:"manifest"`
+	Libraries []Library `json:"libraries"`
+}
+
+// Mirror copies every released artifact, for all platforms, plus the GPU tool
+// binaries to target and writes a release manifest whose URLs point at
+// baseURL. Each artifact is checksum-verified before it is published.
+func (m *Manager) Mirror(ctx context.Context, target MirrorTarget, baseURL string, progressFn func(artifact string, done, total int)) (*MirrorResult, error) {
+	if baseURL == "" {
+		baseURL = target.BaseURL()
+	}
+	baseURL, err := ParseMirrorURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("a base URL is required for %s: pass the URL clients reach the mirror at", target)
+	}
+
+	releasesResp, err := m.apiClient.GetReleases(ctx, "", 500)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases from API: %w", err)
+	}
+	libs := m.librariesFromReleases(releasesResp.Releases, "", "")
+
+	// Collect unique artifact URLs with their expected checksums
+	checksums := make(map[string]string)
+	for _, lib := range libs {
+		checksums[lib.URL] = lib.SHA256
+	}
+	for _, osMap := range GPUBinaryRegistry {
+		for _, archMap := range osMap {
+			for _, info := range archMap {
+				if info.URL != "" {
+					if _, ok := checksums[info.URL]; !ok {
+						checksums[info.URL] = ""
+					}
+				}
+			}
+		}
+	}
+	urls := make([]string, 0, len(checksums))
+	for u := range checksums {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	result := &MirrorResult{Target: target.String(), BaseURL: baseURL}
+	for i, artifactURL := range urls {
+		relPath, err := mirrorPath(artifactURL)
+		if err != nil {
+			return nil, err
+		}
+		if progressFn != nil {
+			progressFn(relPath, i, len(urls))
+		}
+		n, err := m.mirrorArtifact(ctx, target, artifactURL, relPath, checksums[artifactURL])
+		if err != nil {
+			return nil, err
+		}
+		result.Artifacts++
+		result.Bytes += n
+	}
+	if progressFn != nil {
+		progressFn("", len(urls), len(urls))
+	}
+
+	manifest := &ReleaseManifest{
+		Version:   fmt.Sprintf("mirror-%d", time.Now().Unix()),
+		UpdatedAt: time.Now(),
+		Libraries: make([]Library, 0, len(libs)),
+	}
+	for _, lib := range libs {
+		lib.URL = MirrorURL(baseURL, lib.URL)
+		manifest.Libraries = append(manifest.Libraries, lib)
+	}
+	if err := putJSON(ctx, target, ReleaseManifestFile, manifest); err != nil {
+		return nil, fmt.Errorf("failed to publish mirrored manifest: %w", err)
+	}
+	result.Manifest = baseURL + "/" + ReleaseManifestFile
+	result.Libraries = manifest.Libraries
+
+	klog.Infof("Mirrored %d artifacts to %s", result.Artifacts, target)
+	return result, nil
+}
+
+// mirrorPath returns the path of an artifact under the mirror root
+func mirrorPath(artifactURL string) (string, error) {
+	u, err := url.Parse(artifactURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid artifact URL %q: %w", artifactURL, err)
+	}
+	p := path.Clean("/" + u.Path)
+	if p == "/" {
+		return "", fmt.Errorf("invalid artifact URL %q: empty path", artifactURL)
+	}
+	return strings.TrimPrefix(p, "/"), nil
+}
+
+// mirrorArtifact downloads one artifact from its original location, verifies
+// it and publishes it to target
+func (m *Manager) mirrorArtifact(ctx context.Context, target MirrorTarget, artifactURL, relPath, expectedHash string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, artifactURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download %s: %w", artifactURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download %s: status %d", artifactURL, resp.StatusCode)
+	}
+
+	tmpFile, err := os.CreateTemp("", "ggo-mirror-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	hash := sha256.New()
+	n, err := downloadToFile(tmpFile, io.TeeReader(resp.Body, hash), resp.ContentLength, nil)
+	if err != nil {
+		return 0, err
+	}
+	if expectedHash != "" {
+		if actual := hex.EncodeToString(hash.Sum(nil)); actual != expectedHash {
+			return 0, fmt.Errorf("hash mismatch for %s: expected %s, got %s", artifactURL, expectedHash, actual)
+		}
+	}
+
+	if err := target.Put(ctx, relPath, tmpPath); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func putJSON(ctx context.Context, target MirrorTarget, relPath string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp("", "ggo-mirror-*.json")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return target.Put(ctx, relPath, tmpPath)
+}
@@ -0,0 +1,143 @@
+package deps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"k8s.io/klog/v2"
+)
+
+// LibraryTypeCLI marks release artifacts that are the ggo binary itself
+const LibraryTypeCLI = "ggo"
+
+// selfUpdateProbeTimeout bounds the sanity run of a freshly downloaded binary
+const selfUpdateProbeTimeout = 10 * time.Second
+
+// LatestCLIRelease returns the newest ggo binary for the platform on the
+// manager's channel, or nil if the releases API lists none
+func (m *Manager) LatestCLIRelease(ctx context.Context, targetOS, targetArch string) (*Library, error) {
+	resp, err := m.apiClient.GetReleases(ctx, "", 500)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases from API: %w", err)
+	}
+
+	channel := m.effectiveSettings().Channel
+	var latest *Library
+	for _, lib := range m.librariesFromReleases(resp.Releases, targetOS, targetArch) {
+		if lib.Type != LibraryTypeCLI || !channelAccepts(channel, lib.Channel) {
+			continue
+		}
+		if latest == nil || CompareVersions(lib.Version, latest.Version) {
+			latest = &lib
+		}
+	}
+	return latest, nil
+}
+
+// SelfUpdate replaces the binary at exePath with lib. The download must match
+// lib.SHA256 and the new binary must run `version` successfully before it is
+// swapped in; if the swap fails midway the previous binary is restored.
+func (m *Manager) SelfUpdate(ctx context.Context, lib Library, exePath string, progressFn func(downloaded, total int64)) error {
+	if lib.SHA256 == "" {
+		return fmt.Errorf("release %s has no SHA256 checksum, refusing to install", lib.Version)
+	}
+
+	exePath, err := filepath.EvalSymlinks(exePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	info, err := os.Stat(exePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat executable: %w", err)
+	}
+
+	// Stage next to the executable so the final rename stays on one filesystem
+	newPath := exePath + ".new"
+	oldPath := exePath + ".old"
+	defer func() { _ = os.Remove(newPath) }()
+
+	if err := m.downloadVerified(ctx, lib, newPath, progressFn); err != nil {
+		return err
+	}
+	if err := os.Chmod(newPath, info.Mode().Perm()|0755); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if platform.IsWindows() {
+		_ = os.Remove(newPath + ":Zone.Identifier")
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, selfUpdateProbeTimeout)
+	defer cancel()
+	if output, err := exec.CommandContext(probeCtx, newPath, "version").CombinedOutput(); err != nil {
+		return fmt.Errorf("downloaded binary failed to run: %w: %s", err, output)
+	}
+
+	// A running executable can be renamed (but not removed) on Windows, so
+	// the current binary is moved aside rather than overwritten
+	_ = os.Remove(oldPath)
+	if err := os.Rename(exePath, oldPath); err != nil {
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(newPath, exePath); err != nil {
+		if rbErr := os.Rename(oldPath, exePath); rbErr != nil {
+			return fmt.Errorf("failed to install new binary: %w (rollback failed: %v, previous binary is at %s)", err, rbErr, oldPath)
+		}
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+
+	if !platform.IsWindows() {
+		if err := os.Remove(oldPath); err != nil {
+			klog.Warningf("Failed to remove previous binary: path=%s error=%v", oldPath, err)
+		}
+	}
+	return nil
+}
+
+// CleanSelfUpdateBackup removes the previous binary left behind by a self
+// update on Windows, where it cannot be deleted while still running
+func CleanSelfUpdateBackup(exePath string) {
+	if exePath, err := filepath.EvalSymlinks(exePath); err == nil {
+		_ = os.Remove(exePath + ".old")
+	}
+}
+
+// downloadVerified downloads lib to destPath and checks its signature and SHA256
+func (m *Manager) downloadVerified(ctx context.Context, lib Library, destPath string, progressFn func(downloaded, total int64)) error {
+	if err := m.VerifySignature(lib); err != nil {
+		return err
+	}
+	req, client, err := m.artifactRequest(ctx, lib.URL)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", lib.Name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: status %d", lib.Name, resp.StatusCode)
+	}
+
+	f, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	hash := sha256.New()
+	if _, err := downloadToFile(f, io.TeeReader(resp.Body, hash), lib.Size, progressFn); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != lib.SHA256 {
+		return fmt.Errorf("hash mismatch: expected %s, got %s", lib.SHA256, actual)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package deps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cliRelease(version, channel, url string) api.ReleaseInfo {
+	return api.ReleaseInfo{
+		Vendor:  api.VendorInfo{Slug: "nexusgpu"},
+		Version: version,
+		Channel: channel,
+		Artifacts: []api.ReleaseArtifact{{
+			OS:       runtime.GOOS,
+			CPUArch:  runtime.GOARCH,
+			URL:      url,
+			SHA256:   "abc",
+			Metadata: map[string]string{"type": LibraryTypeCLI},
+		}},
+	}
+}
+
+func TestLatestCLIRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.ReleasesResponse{Releases: []api.ReleaseInfo{
+			cliRelease("1.2.0", "", "https://example.com/1.2.0/ggo"),
+			cliRelease("1.3.0-beta.1", ChannelBeta, "https://example.com/1.3.0-beta.1/ggo"),
+			cliRelease("1.1.0", ChannelStable, "https://example.com/1.1.0/ggo"),
+		}})
+	}))
+	defer server.Close()
+
+	paths := platform.DefaultPaths().WithConfigDir(t.TempDir())
+	client := api.NewClient(api.WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	lib, err := NewManager(WithPaths(paths), WithAPIClient(client)).LatestCLIRelease(ctx, runtime.GOOS, runtime.GOARCH)
+	require.NoError(t, err)
+	require.NotNil(t, lib)
+	assert.Equal(t, "1.2.0", lib.Version)
+
+	lib, err = NewManager(WithPaths(paths), WithAPIClient(client), WithChannel(ChannelBeta)).LatestCLIRelease(ctx, runtime.GOOS, runtime.GOARCH)
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0-beta.1", lib.Version)
+
+	lib, err = NewManager(WithPaths(paths), WithAPIClient(client)).LatestCLIRelease(ctx, "plan9", runtime.GOARCH)
+	require.NoError(t, err)
+	assert.Nil(t, lib)
+}
+
+func TestSelfUpdate(t *testing.T) {
+	if runtime.GOOS == osWindows {
+		t.Skip("uses shell scripts as executables")
+	}
+
+	binaries := map[string]string{
+		"/good/ggo":   "#!/bin/sh\necho new\n",
+		"/broken/ggo": "#!/bin/sh\nexit 3\n",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, ok := binaries[r.URL.Path]; ok {
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	checksum := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	exePath := filepath.Join(t.TempDir(), "ggo")
+	require.NoError(t, os.WriteFile(exePath, []byte("#!/bin/sh\necho old\n"), 0755))
+	signer := newTestSigner(t, "release-1")
+	mgr := NewManager(WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())), signer.trust())
+	ctx := context.Background()
+
+	readExe := func() string {
+		data, err := os.ReadFile(exePath)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	// Checksum mismatch keeps the current binary
+	err := mgr.SelfUpdate(ctx, signer.sign(Library{Name: "ggo", Version: "1.0.0", URL: server.URL + "/good/ggo", SHA256: checksum("other")}), exePath, nil)
+	assert.ErrorContains(t, err, "hash mismatch")
+	assert.Contains(t, readExe(), "old")
+
+	// A binary that fails to run is not installed
+	err = mgr.SelfUpdate(ctx, signer.sign(Library{Name: "ggo", Version: "1.0.0", URL: server.URL + "/broken/ggo", SHA256: checksum(binaries["/broken/ggo"])}), exePath, nil)
+	assert.ErrorContains(t, err, "failed to run")
+	assert.Contains(t, readExe(), "old")
+
+	// Missing checksum is refused
+	err = mgr.SelfUpdate(ctx, Library{Name: "ggo", Version: "1.0.0", URL: server.URL + "/good/ggo"}, exePath, nil)
+	assert.ErrorContains(t, err, "checksum")
+
+	// An unsigned release is refused
+	good := Library{Name: "ggo", Version: "1.0.0", URL: server.URL + "/good/ggo", SHA256: checksum(binaries["/good/ggo"])}
+	err = mgr.SelfUpdate(ctx, good, exePath, nil)
+	assert.ErrorIs(t, err, ErrSignature)
+	assert.Contains(t, readExe(), "old")
+
+	require.NoError(t, mgr.SelfUpdate(ctx, signer.sign(good), exePath, nil))
+	assert.Contains(t, readExe(), "new")
+	assert.NoFileExists(t, exePath+".new")
+	assert.NoFileExists(t, exePath+".old")
+}
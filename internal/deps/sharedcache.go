@@ -0,0 +1,231 @@
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SharedCacheDirEnv points at a cache shared by all users of the host;
+	// "off" disables the shared cache
+	SharedCacheDirEnv = "GGO_SHARED_CACHE_DIR"
+
+	// DefaultSharedCacheDir is used on Linux when an administrator has
+	// created it, e.g. with: install -d -m 1777 /var/cache/gpu-go
+	DefaultSharedCacheDir = "/var/cache/gpu-go"
+
+	// sharedDirMode lets every user add blobs while the sticky bit keeps
+	// users from removing or replacing each other's
+	sharedDirMode = os.ModeDir | os.ModeSticky | 0777
+	// sharedBlobMode is read-only for everyone; executable so binaries can
+	// run from a link to the blob
+	sharedBlobMode = 0555
+)
+
+// Materialization methods, reported in logs
+const (
+	linkHard = "hardlink"
+	linkSym  = "symlink"
+	linkCopy = "copy"
+)
+
+// sharedCache is a content-addressed store of downloaded artifacts shared by
+// the users of a host, so multi-GB libraries are downloaded once per host
+// instead of once per HOME. Blobs live at <dir>/sha256/<ab>/<sha256> and are
+// materialized into each user's cache by hard link, symlink or copy.
+//
+// Any user can add blobs, so a blob is only trusted after its content has
+// been checked against the expected hash. A blob is only linked when neither
+// it nor the directories leading to it can be changed by another user, since
+// the owner could otherwise rewrite a library behind the link after the
+// check; any other blob is copied and the copy verified.
+type sharedCache struct {
+	dir string
+}
+
+// resolveSharedCacheDir returns the shared cache directory from the
+// environment, falling back to DefaultSharedCacheDir on Linux when it
+// exists. Empty means no shared cache.
+func resolveSharedCacheDir() string {
+	switch dir := os.Getenv(SharedCacheDirEnv); dir {
+	case "off":
+		return ""
+	case "":
+	default:
+		return dir
+	}
+	if runtime.GOOS != osLinux {
+		return ""
+	}
+	if info, err := os.Stat(DefaultSharedCacheDir); err == nil && info.IsDir() {
+		return DefaultSharedCacheDir
+	}
+	return ""
+}
+
+func (c *sharedCache) blobPath(hash string) string {
+	return filepath.Join(c.dir, "sha256", hash[:2], hash)
+}
+
+// lookup returns the blob for hash if the store holds it with the expected
+// content
+func (c *sharedCache) lookup(hash string) (string, bool) {
+	path := c.blobPath(hash)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	if !fileHasHash(path, hash) {
+		klog.Warningf("Ignoring shared cache blob with wrong content: path=%s", path)
+		return "", false
+	}
+	return path, true
+}
+
+// store adds the verified file at src to the store and returns the blob path.
+// A blob added concurrently by another user is reused after verification.
+func (c *sharedCache) store(src, hash string) (string, error) {
+	blob := c.blobPath(hash)
+	if err := c.mkdirShared(filepath.Dir(blob)); err != nil {
+		return "", err
+	}
+	if path, ok := c.lookup(hash); ok {
+		return path, nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(blob), "."+hash+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create shared cache blob: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if err := copyFileTo(tmp, src); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmpPath, sharedBlobMode); err != nil {
+		return "", fmt.Errorf("failed to set shared cache blob permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, blob); err != nil {
+		// With the sticky bit another user's blob cannot be replaced
+		if path, ok := c.lookup(hash); ok {
+			return path, nil
+		}
+		return "", fmt.Errorf("failed to add shared cache blob: %w", err)
+	}
+	return blob, nil
+}
+
+// mkdirShared creates dir and its parents up to the store root with
+// sharedDirMode. Directories that already exist are used as they are if
+// trustedDir accepts them.
+func (c *sharedCache) mkdirShared(dir string) error {
+	if dir == c.dir {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return fmt.Errorf("failed to create shared cache directory: %w", err)
+		}
+	} else if err := c.mkdirShared(filepath.Dir(dir)); err != nil {
+		return err
+	}
+	if err := os.Mkdir(dir, 0777); err != nil {
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create shared cache directory: %w", err)
+		}
+		// Another user could have created it to swap blobs stored in it
+		if !trustedDir(dir) {
+			return fmt.Errorf("refusing to use shared cache directory %s: it must be owned by root or the current user, and sticky if others can write to it", dir)
+		}
+		return nil
+	}
+	// Mkdir is subject to the umask; set the mode explicitly
+	if err := os.Chmod(dir, sharedDirMode); err != nil {
+		return fmt.Errorf("failed to set shared cache directory permissions: %w", err)
+	}
+	return nil
+}
+
+// trustedDir reports whether no other user can replace entries in dir: it is
+// owned by root or the current user, and sticky if others may write to it
+func trustedDir(dir string) bool {
+	info, err := os.Lstat(dir)
+	if err != nil || !info.IsDir() || !ownedByTrustedUser(info) {
+		return false
+	}
+	return info.Mode().Perm()&0022 == 0 || info.Mode()&os.ModeSticky != 0
+}
+
+// linkable reports whether blob can be linked into a user's cache: the blob
+// and every directory up to the store root are out of other users' reach
+func (c *sharedCache) linkable(blob string) bool {
+	info, err := os.Lstat(blob)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0022 != 0 || !ownedByTrustedUser(info) {
+		return false
+	}
+	root := filepath.Clean(c.dir)
+	for dir := filepath.Dir(blob); ; dir = filepath.Dir(dir) {
+		if !trustedDir(dir) {
+			return false
+		}
+		if dir == root || dir == filepath.Dir(dir) {
+			return dir == root
+		}
+	}
+}
+
+// materialize makes dest refer to blob. A linkable blob is hard linked when
+// it is on the same filesystem and the kernel allows linking it, otherwise
+// symlinked. Other blobs, and linkable ones that cannot be linked, are copied
+// and the copy is checked against hash.
+func (c *sharedCache) materialize(blob, hash, dest string) (string, error) {
+	if err := os.Remove(dest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to replace %s: %w", dest, err)
+	}
+	if c.linkable(blob) {
+		if err := os.Link(blob, dest); err == nil {
+			return linkHard, nil
+		}
+		if err := os.Symlink(blob, dest); err == nil {
+			return linkSym, nil
+		}
+	}
+
+	tmpPath := dest + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+	defer func() { _ = os.Remove(tmpPath) }()
+	if err := copyFileTo(tmp, blob); err != nil {
+		return "", err
+	}
+	// The blob may have changed since lookup verified it
+	if !fileHasHash(tmpPath, hash) {
+		return "", fmt.Errorf("shared cache blob %s changed while copying", blob)
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, dest); err != nil {
+		return "", fmt.Errorf("failed to move file: %w", err)
+	}
+	return linkCopy, nil
+}
+
+// copyFileTo copies src into dst and closes dst
+func copyFileTo(dst *os.File, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		_ = dst.Close()
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	if _, err := io.Copy(dst, in); err != nil {
+		_ = dst.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return dst.Close()
+}
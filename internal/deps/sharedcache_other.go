@@ -0,0 +1,12 @@
+//go:build !unix
+
+package deps
+
+import "os"
+
+// ownedByTrustedUser cannot check ownership on this platform, so nothing in
+// the shared cache is trusted: existing directories are not reused and blobs
+// are always copied
+func ownedByTrustedUser(_ os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,186 @@
+package deps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedCache_DownloadsOncePerHost(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shared cache permissions are POSIX-specific")
+	}
+	content := []byte("fake libcuda shared by all users")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	var requests atomic.Int32
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(content)
+	}))
+	defer cdn.Close()
+
+	sharedDir := filepath.Join(t.TempDir(), "gpu-go")
+	signer := newTestSigner(t, "release-1")
+	lib := signer.sign(Library{Name: "libcuda.so", Version: "1.0.0", Platform: "linux", Arch: "amd64",
+		URL: cdn.URL + "/vgpu/1.0.0/libcuda.so", SHA256: checksum})
+
+	newUser := func() *Manager {
+		paths := platform.DefaultPaths().WithConfigDir(t.TempDir()).WithCacheDir(t.TempDir())
+		return NewManager(WithPaths(paths), WithCDNBaseURL(cdn.URL), WithSharedCacheDir(sharedDir), signer.trust())
+	}
+
+	alice := newUser()
+	assert.Equal(t, sharedDir, alice.SharedCacheDir())
+	require.NoError(t, alice.DownloadLibrary(context.Background(), lib, nil))
+	assert.Equal(t, int32(1), requests.Load())
+
+	blob := alice.shared.blobPath(checksum)
+	info, err := os.Stat(blob)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(sharedBlobMode), info.Mode().Perm(), "blobs are read-only")
+	dirInfo, err := os.Stat(filepath.Dir(blob))
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSticky, dirInfo.Mode()&os.ModeSticky, "users cannot replace each other's blobs")
+
+	data, err := os.ReadFile(alice.GetLibraryPath(lib.Name))
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.FileExists(t, filepath.Join(alice.GetLibsDir(), "libcuda.so.1"))
+
+	// A second user links the blob instead of downloading it again
+	bob := newUser()
+	require.NoError(t, bob.DownloadLibrary(context.Background(), lib, nil))
+	assert.Equal(t, int32(1), requests.Load())
+	data, err = os.ReadFile(bob.GetLibraryPath(lib.Name))
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	// Cleaning a user's cache leaves the shared blob in place
+	require.NoError(t, bob.CleanCache())
+	assert.FileExists(t, blob)
+}
+
+func TestSharedCache_IgnoresTamperedBlob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shared cache permissions are POSIX-specific")
+	}
+	content := []byte("genuine library")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	var requests atomic.Int32
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(content)
+	}))
+	defer cdn.Close()
+
+	cache := &sharedCache{dir: t.TempDir()}
+	blob := cache.blobPath(checksum)
+	require.NoError(t, cache.mkdirShared(filepath.Dir(blob)))
+	require.NoError(t, os.WriteFile(blob, []byte("malicious"), 0644))
+
+	paths := platform.DefaultPaths().WithConfigDir(t.TempDir()).WithCacheDir(t.TempDir())
+	signer := newTestSigner(t, "release-1")
+	mgr := NewManager(WithPaths(paths), WithSharedCacheDir(cache.dir), signer.trust())
+	lib := signer.sign(Library{Name: "remote-gpu-worker", Version: "1.0.0", URL: cdn.URL + "/worker", SHA256: checksum})
+	require.NoError(t, mgr.DownloadLibrary(context.Background(), lib, nil))
+
+	assert.Equal(t, int32(1), requests.Load(), "tampered blob is not used")
+	data, err := os.ReadFile(mgr.GetLibraryPath(lib.Name))
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	_, ok := cache.lookup(checksum)
+	assert.True(t, ok, "the store is repaired with the verified download")
+}
+
+func TestResolveSharedCacheDir(t *testing.T) {
+	t.Setenv(SharedCacheDirEnv, "/srv/ggo-cache")
+	assert.Equal(t, "/srv/ggo-cache", resolveSharedCacheDir())
+
+	t.Setenv(SharedCacheDirEnv, "off")
+	assert.Empty(t, resolveSharedCacheDir())
+	assert.Empty(t, NewManager().SharedCacheDir())
+}
+
+func TestSharedCache_RejectsUntrustedDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shared cache permissions are POSIX-specific")
+	}
+	cache := &sharedCache{dir: t.TempDir()}
+	require.NoError(t, os.Chmod(cache.dir, sharedDirMode))
+	hash := strings.Repeat("ab", 32)
+	blobDir := filepath.Dir(cache.blobPath(hash))
+
+	// Pre-created by someone else without the sticky bit: any user could
+	// replace the blobs in it
+	require.NoError(t, os.MkdirAll(blobDir, 0755))
+	require.NoError(t, os.Chmod(blobDir, 0777))
+	assert.ErrorContains(t, cache.mkdirShared(blobDir), "refusing to use")
+
+	require.NoError(t, os.Chmod(blobDir, sharedDirMode))
+	assert.NoError(t, cache.mkdirShared(blobDir))
+
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner needs root")
+	}
+	require.NoError(t, os.Chown(blobDir, 65534, 65534))
+	assert.ErrorContains(t, cache.mkdirShared(blobDir), "refusing to use", "sticky but owned by another user")
+}
+
+func TestSharedCache_CopiesUntrustedBlob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shared cache permissions are POSIX-specific")
+	}
+	content := []byte("client library")
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	cache := &sharedCache{dir: t.TempDir()}
+	src := filepath.Join(t.TempDir(), "lib")
+	require.NoError(t, os.WriteFile(src, content, 0644))
+	blob, err := cache.store(src, hash)
+	require.NoError(t, err)
+
+	dest := filepath.Join(t.TempDir(), "libcuda.so")
+	how, err := cache.materialize(blob, hash, dest)
+	require.NoError(t, err)
+	assert.Equal(t, linkHard, how, "own read-only blob is linked")
+
+	// A blob its owner can still write to may change after verification
+	require.NoError(t, os.Chmod(blob, 0777))
+	how, err = cache.materialize(blob, hash, dest)
+	require.NoError(t, err)
+	assert.Equal(t, linkCopy, how)
+	require.NoError(t, os.Chmod(blob, sharedBlobMode))
+
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner needs root")
+	}
+	require.NoError(t, os.Chown(blob, 65534, 65534))
+	how, err = cache.materialize(blob, hash, dest)
+	require.NoError(t, err)
+	assert.Equal(t, linkCopy, how, "another user's blob is copied")
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	// The copy is verified, not just the blob at lookup time
+	require.NoError(t, os.Chmod(blob, 0644))
+	require.NoError(t, os.WriteFile(blob, []byte("rewritten"), 0644))
+	_, err = cache.materialize(blob, hash, dest)
+	assert.ErrorContains(t, err, "changed while copying")
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package deps
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByTrustedUser reports whether info belongs to root or the current
+// user, the only owners whose files other users cannot rewrite
+func ownedByTrustedUser(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	return st.Uid == 0 || int(st.Uid) == os.Getuid()
+}
@@ -0,0 +1,202 @@
+package deps
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// TrustedKeysFile lists additional publisher keys and revoked key IDs,
+	// so keys can be rotated without a new ggo release
+	TrustedKeysFile = "trusted-keys.json"
+
+	// InsecureSkipSignatureEnv disables signature verification when set to
+	// 1, for development against unsigned releases
+	InsecureSkipSignatureEnv = "GGO_INSECURE_SKIP_SIGNATURE"
+
+	// SignatureAlgorithmEd25519 is the only artifact signature algorithm
+	SignatureAlgorithmEd25519 = "ed25519"
+
+	// artifactSignatureContext prefixes the signed payload so a signature
+	// over an artifact cannot be replayed for another purpose
+	artifactSignatureContext = "ggo-artifact-v1"
+)
+
+// publisherKeys are the release publisher's public keys, pinned at build
+// time with -ldflags "-X github.com/NexusGPU/gpu-go/internal/deps.publisherKeys=<id>:<base64>[,...]"
+var publisherKeys = ""
+
+// ErrSignature is wrapped by every artifact signature verification failure
+var ErrSignature = errors.New("artifact signature verification failed")
+
+// TrustedKey is a publisher public key
+type TrustedKey struct {
+	ID        string `json:"id"`
+	PublicKey string `json:"public_key"` // base64-encoded Ed25519 public key
+	Comment   string `json:"comment,omitempty"`
+}
+
+// TrustFile is the content of trusted-keys.json. Keys are trusted in
+// addition to the pinned ones; revoked IDs are distrusted even if pinned.
+type TrustFile struct {
+	Keys    []TrustedKey `json:"keys,omitempty"`
+	Revoked []string     `json:"revoked,omitempty"`
+}
+
+// WithInsecureSkipSignature downloads artifacts without verifying their
+// publisher signature. Only the SHA256 from the API is checked.
+func WithInsecureSkipSignature(skip bool) ManagerOption {
+	return func(m *Manager) {
+		if skip {
+			m.skipSignature = true
+		}
+	}
+}
+
+// WithTrustedKeys replaces the pinned publisher keys, keyed by key ID
+func WithTrustedKeys(keys map[string]ed25519.PublicKey) ManagerOption {
+	return func(m *Manager) {
+		m.pinnedKeys = keys
+	}
+}
+
+// parsePublisherKeys parses the "<id>:<base64>[,...]" form of publisherKeys
+func parsePublisherKeys(s string) (map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid publisher key %q: expected <id>:<base64>", entry)
+		}
+		key, err := decodePublicKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid publisher key %s: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+func decodePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key is %d bytes, expected %d", len(raw), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+func (m *Manager) trustFilePath() string {
+	return filepath.Join(m.paths.ConfigDir(), TrustedKeysFile)
+}
+
+// TrustedKeys returns the publisher keys artifacts are verified against:
+// the pinned keys plus those in trusted-keys.json, minus revoked ones
+func (m *Manager) TrustedKeys() (map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey, len(m.pinnedKeys))
+	for id, key := range m.pinnedKeys {
+		keys[id] = key
+	}
+
+	trust, err := utils.LoadJSON[TrustFile](m.trustFilePath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", TrustedKeysFile, err)
+	}
+	if trust == nil {
+		return keys, nil
+	}
+	for _, k := range trust.Keys {
+		key, err := decodePublicKey(k.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s in %s: %w", k.ID, TrustedKeysFile, err)
+		}
+		keys[k.ID] = key
+	}
+	for _, id := range trust.Revoked {
+		delete(keys, id)
+	}
+	return keys, nil
+}
+
+// ArtifactSignaturePayload returns the bytes a publisher signs for lib. It
+// binds the content hash to the name, version, platform and type the
+// artifact is installed as, so a signed artifact cannot be substituted for
+// another one.
+func ArtifactSignaturePayload(lib Library) []byte {
+	return []byte(strings.Join([]string{
+		artifactSignatureContext,
+		lib.Name,
+		lib.Version,
+		lib.Platform,
+		lib.Arch,
+		lib.Type,
+		strings.ToLower(lib.VendorSlug),
+		strings.ToLower(lib.SHA256),
+	}, "\n") + "\n")
+}
+
+// VerifySignature checks lib was signed by a trusted publisher key. It
+// fails closed: unsigned artifacts and machines without trusted keys are
+// rejected unless verification is disabled.
+func (m *Manager) VerifySignature(lib Library) error {
+	if m.skipSignature {
+		klog.V(2).Infof("Skipping artifact signature verification: name=%s version=%s", lib.Name, lib.Version)
+		return nil
+	}
+	if lib.Signature == "" {
+		return fmt.Errorf("%w: %s %s is not signed", ErrSignature, lib.Name, lib.Version)
+	}
+	if lib.SHA256 == "" {
+		return fmt.Errorf("%w: %s %s has no SHA256 checksum", ErrSignature, lib.Name, lib.Version)
+	}
+	sig, err := base64.StdEncoding.DecodeString(lib.Signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: %s %s has a malformed signature", ErrSignature, lib.Name, lib.Version)
+	}
+
+	keys, err := m.TrustedKeys()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignature, err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: no trusted publisher keys (add one to %s)", ErrSignature, m.trustFilePath())
+	}
+
+	payload := ArtifactSignaturePayload(lib)
+	if lib.KeyID != "" {
+		key, ok := keys[lib.KeyID]
+		if !ok {
+			return fmt.Errorf("%w: %s %s is signed by untrusted key %s", ErrSignature, lib.Name, lib.Version, lib.KeyID)
+		}
+		if !ed25519.Verify(key, payload, sig) {
+			return fmt.Errorf("%w: invalid signature on %s %s", ErrSignature, lib.Name, lib.Version)
+		}
+		return nil
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, payload, sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: invalid signature on %s %s", ErrSignature, lib.Name, lib.Version)
+}
+
+// insecureSkipSignatureFromEnv reports whether InsecureSkipSignatureEnv
+// disables verification
+func insecureSkipSignatureFromEnv() bool {
+	return os.Getenv(InsecureSkipSignatureEnv) == "1"
+}
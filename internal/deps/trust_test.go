@@ -0,0 +1,112 @@
+package deps
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner signs libraries with a throwaway publisher key
+type testSigner struct {
+	id   string
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newTestSigner(t *testing.T, id string) *testSigner {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &testSigner{id: id, pub: pub, priv: priv}
+}
+
+// trust pins the signer's key in a manager
+func (s *testSigner) trust() ManagerOption {
+	return WithTrustedKeys(map[string]ed25519.PublicKey{s.id: s.pub})
+}
+
+func (s *testSigner) sign(lib Library) Library {
+	lib.KeyID = s.id
+	lib.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.priv, ArtifactSignaturePayload(lib)))
+	return lib
+}
+
+func TestParsePublisherKeys(t *testing.T) {
+	signer := newTestSigner(t, "release-1")
+	encoded := base64.StdEncoding.EncodeToString(signer.pub)
+
+	keys, err := parsePublisherKeys("release-1:" + encoded + ", ")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ed25519.PublicKey{"release-1": signer.pub}, keys)
+
+	keys, err = parsePublisherKeys("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = parsePublisherKeys("release-1")
+	assert.ErrorContains(t, err, "expected <id>:<base64>")
+	_, err = parsePublisherKeys("release-1:AAAA")
+	assert.ErrorContains(t, err, "expected 32")
+}
+
+func TestVerifySignature(t *testing.T) {
+	signer := newTestSigner(t, "release-1")
+	paths := platform.DefaultPaths().WithConfigDir(t.TempDir())
+	mgr := NewManager(WithPaths(paths), signer.trust())
+
+	lib := signer.sign(Library{Name: "libcuda.so.1", Version: "1.0.0", Platform: "linux", Arch: "amd64",
+		Type: LibraryTypeRemoteGPUClient, VendorSlug: "nvidia", SHA256: "abc123"})
+	require.NoError(t, mgr.VerifySignature(lib))
+
+	tampered := lib
+	tampered.SHA256 = "def456"
+	assert.ErrorIs(t, mgr.VerifySignature(tampered), ErrSignature)
+	renamed := lib
+	renamed.Name = "libnvidia-ml.so.1"
+	assert.ErrorIs(t, mgr.VerifySignature(renamed), ErrSignature, "the signature binds the installed name")
+
+	unsigned := lib
+	unsigned.Signature = ""
+	assert.ErrorContains(t, mgr.VerifySignature(unsigned), "not signed")
+
+	other := newTestSigner(t, "attacker")
+	assert.ErrorContains(t, mgr.VerifySignature(other.sign(lib)), "untrusted key attacker")
+
+	// Without pinned or configured keys nothing is trusted
+	assert.ErrorContains(t, NewManager(WithPaths(paths)).VerifySignature(lib), "no trusted publisher keys")
+
+	// Verification can be disabled for development
+	assert.NoError(t, NewManager(WithPaths(paths), WithInsecureSkipSignature(true)).VerifySignature(unsigned))
+	t.Setenv(InsecureSkipSignatureEnv, "1")
+	assert.NoError(t, NewManager(WithPaths(paths)).VerifySignature(unsigned))
+}
+
+func TestTrustedKeys_Rotation(t *testing.T) {
+	oldKey := newTestSigner(t, "release-1")
+	newKey := newTestSigner(t, "release-2")
+	paths := platform.DefaultPaths().WithConfigDir(t.TempDir())
+	mgr := NewManager(WithPaths(paths), oldKey.trust())
+
+	lib := Library{Name: "remote-gpu-worker", Version: "2.0.0", Platform: "linux", Arch: "amd64", SHA256: "abc123"}
+	assert.ErrorIs(t, mgr.VerifySignature(newKey.sign(lib)), ErrSignature)
+
+	// Trust the new key and revoke the pinned one through the trust file
+	require.NoError(t, utils.SaveJSON(filepath.Join(paths.ConfigDir(), TrustedKeysFile), &TrustFile{
+		Keys:    []TrustedKey{{ID: "release-2", PublicKey: base64.StdEncoding.EncodeToString(newKey.pub)}},
+		Revoked: []string{"release-1"},
+	}, 0644))
+
+	assert.NoError(t, mgr.VerifySignature(newKey.sign(lib)))
+	assert.ErrorContains(t, mgr.VerifySignature(oldKey.sign(lib)), "untrusted key release-1")
+
+	keys, err := mgr.TrustedKeys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
@@ -0,0 +1,33 @@
+package deps
+
+import "strings"
+
+// Vendor slugs of GPU vendors with their own client libraries
+const (
+	VendorNVIDIA    = "nvidia"
+	VendorAMD       = "amd"
+	VendorHygon     = "hygon"
+	VendorMThreads  = "mthreads"  // Moore Threads (MUSA)
+	VendorCambricon = "cambricon" // Cambricon MLUs
+)
+
+// vendorSlugAliases maps vendor names as reported by drivers, the hypervisor
+// and releases, with spaces, dashes and underscores removed, to vendor slugs
+var vendorSlugAliases = map[string]string{
+	"moorethreads": VendorMThreads,
+	"musa":         VendorMThreads,
+	"cambriconmlu": VendorCambricon,
+	"mlu":          VendorCambricon,
+}
+
+// NormalizeVendorSlug returns the vendor slug libraries are released under
+// for a vendor name, e.g. mthreads for "Moore Threads". Unknown names are
+// returned lowercased.
+func NormalizeVendorSlug(vendor string) string {
+	vendor = strings.ToLower(strings.TrimSpace(vendor))
+	key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(vendor)
+	if slug, ok := vendorSlugAliases[key]; ok {
+		return slug
+	}
+	return vendor
+}
@@ -0,0 +1,26 @@
+package deps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeVendorSlug(t *testing.T) {
+	tests := map[string]string{
+		"NVIDIA":        VendorNVIDIA,
+		" amd ":         VendorAMD,
+		"hygon":         VendorHygon,
+		"mthreads":      VendorMThreads,
+		"Moore Threads": VendorMThreads,
+		"moore_threads": VendorMThreads,
+		"MUSA":          VendorMThreads,
+		"Cambricon":     VendorCambricon,
+		"cambricon-mlu": VendorCambricon,
+		"stub":          "stub",
+		"":              "",
+	}
+	for vendor, want := range tests {
+		assert.Equal(t, want, NormalizeVendorSlug(vendor), vendor)
+	}
+}
@@ -0,0 +1,144 @@
+package deps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+
+	"k8s.io/klog/v2"
+)
+
+// Issue kinds reported by VerifyInstalled besides the ABI ones
+const (
+	ABIIssueChecksum      = "checksum"
+	ABIIssueNotExecutable = "not-executable"
+)
+
+// AgentLibraryTypes are the artifacts the agent runs workers with
+var AgentLibraryTypes = []string{LibraryTypeRemoteGPUWorker, LibraryTypeVGPULibrary}
+
+// VerifyInstalled checks the downloaded artifacts of libTypes in the deps
+// manifest before they are run: the file must still have the manifest's
+// SHA-256, binaries must be executable, and both must be built for this host.
+// A binary of a foreign architecture fails every start with "exec format
+// error", so a worker using it would crash in a loop. Artifacts that are not
+// downloaded yet are skipped.
+func (m *Manager) VerifyInstalled(ctx context.Context, libTypes []string) ([]ABIIssue, error) {
+	deps, err := m.LoadDepsManifest()
+	if err != nil || deps == nil {
+		return nil, err
+	}
+	host := detectABIHost(ctx)
+	libsDir := m.GetLibsDir()
+
+	keys := make([]string, 0, len(deps.Libraries))
+	for key := range deps.Libraries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var issues []ABIIssue
+	for _, key := range keys {
+		lib := deps.Libraries[key]
+		if !slices.Contains(libTypes, lib.Type) || (lib.Platform != "" && lib.Platform != host.goos) {
+			continue
+		}
+		path := m.GetLibraryPath(lib.Name)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		issues = append(issues, host.verify(lib, path, info, libsDir)...)
+	}
+	return issues, nil
+}
+
+// verify checks one downloaded artifact
+func (h *abiHost) verify(lib Library, path string, info os.FileInfo, libsDir string) []ABIIssue {
+	if lib.Arch != "" && lib.Arch != h.goarch {
+		// The deps manifest came from another machine, e.g. a copied state directory
+		return []ABIIssue{{Library: lib.Name, Kind: ABIIssueArch,
+			Detail: fmt.Sprintf("the deps manifest selected the %s/%s build, but this host is %s/%s", lib.Platform, lib.Arch, h.goos, h.goarch)}}
+	}
+	if lib.SHA256 != "" {
+		if actual, err := fileSHA256(path); err != nil || actual != lib.SHA256 {
+			return []ABIIssue{{Library: lib.Name, Kind: ABIIssueChecksum,
+				Detail: fmt.Sprintf("SHA-256 %s does not match the manifest's %s (modified or partially downloaded?)", ShortHash(actual), ShortHash(lib.SHA256))}}
+		}
+	}
+
+	var issues []ABIIssue
+	if !isSharedLibrary(lib.Name) && h.goos != osWindows && info.Mode().Perm()&0111 == 0 {
+		issues = append(issues, ABIIssue{Library: lib.Name, Kind: ABIIssueNotExecutable,
+			Detail: fmt.Sprintf("%s is not executable (mode %s)", path, info.Mode().Perm())})
+	}
+	if h.goos != osLinux && h.goos != osWindows {
+		// Only ELF and PE objects are inspected
+		return issues
+	}
+	for _, issue := range h.check(path, libsDir) {
+		// The agent host has the real driver installed, which the vGPU
+		// libraries deliberately share their names with
+		if issue.Kind == ABIIssueCollision {
+			continue
+		}
+		issue.Library = lib.Name
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// RepairInstalled syncs releases for this host, selects the deps again and
+// downloads anew the artifacts of libTypes VerifyInstalled reports, then
+// returns the issues that remain
+func (m *Manager) RepairInstalled(ctx context.Context, libTypes []string) ([]ABIIssue, error) {
+	manifest, err := m.SyncReleases(ctx, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.SaveDepsManifest(m.SelectRequiredDeps(manifest)); err != nil {
+		return nil, err
+	}
+
+	issues, err := m.VerifyInstalled(ctx, libTypes)
+	if err != nil || len(issues) == 0 {
+		return issues, err
+	}
+	broken := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		broken[issue.Library] = true
+	}
+
+	deps, err := m.LoadDepsManifest()
+	if err != nil || deps == nil {
+		return issues, err
+	}
+	for _, lib := range deps.Libraries {
+		if !broken[lib.Name] || !slices.Contains(libTypes, lib.Type) {
+			continue
+		}
+		klog.Infof("Downloading library again: name=%s version=%s platform=%s/%s", lib.Name, lib.Version, lib.Platform, lib.Arch)
+		if err := os.Remove(m.GetLibraryPath(lib.Name)); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove %s: %w", lib.Name, err)
+		}
+		if err := m.DownloadLibrary(ctx, lib, nil); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", lib.Name, err)
+		}
+	}
+	return m.VerifyInstalled(ctx, libTypes)
+}
+
+// ShortHash abbreviates a SHA-256 for messages; an empty hash is one that
+// could not be read
+func ShortHash(hash string) string {
+	if hash == "" {
+		return "(unreadable)"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
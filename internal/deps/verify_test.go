@@ -0,0 +1,94 @@
+package deps
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyInstalled(t *testing.T) {
+	if runtime.GOOS != osLinux {
+		t.Skip("the test binary is an ELF file on Linux only")
+	}
+	self, err := os.Executable()
+	require.NoError(t, err)
+	data, err := os.ReadFile(self)
+	require.NoError(t, err)
+
+	mgr := NewManager(WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())))
+	worker := Library{Name: "remote-gpu-worker", Version: "1.2.0", Platform: osLinux, Arch: runtime.GOARCH, Type: LibraryTypeRemoteGPUWorker}
+	path := mgr.GetLibraryPath(worker.Name)
+	require.NoError(t, os.MkdirAll(mgr.paths.CacheDir(), 0755))
+	require.NoError(t, os.WriteFile(path, data, 0755))
+	worker.SHA256, err = fileSHA256(path)
+	require.NoError(t, err)
+	// Not downloaded, so skipped
+	shim := Library{Name: "libcuda.so.1", Version: "1.2.0", Platform: osLinux, Arch: runtime.GOARCH, Type: LibraryTypeVGPULibrary, VendorSlug: "nvidia"}
+	save := func(libs ...Library) {
+		manifest := &DepsManifest{Libraries: map[string]Library{}}
+		for _, lib := range libs {
+			manifest.Libraries[lib.Key()] = lib
+		}
+		require.NoError(t, mgr.SaveDepsManifest(manifest))
+	}
+	ctx := context.Background()
+
+	save(worker, shim)
+	issues, err := mgr.VerifyInstalled(ctx, AgentLibraryTypes)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	require.NoError(t, os.Chmod(path, 0644))
+	issues, err = mgr.VerifyInstalled(ctx, AgentLibraryTypes)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, ABIIssueNotExecutable, issues[0].Kind)
+
+	foreign := worker
+	foreign.Arch = "arm64"
+	if runtime.GOARCH == "arm64" {
+		foreign.Arch = "amd64"
+	}
+	save(foreign)
+	issues, err = mgr.VerifyInstalled(ctx, AgentLibraryTypes)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, ABIIssueArch, issues[0].Kind)
+
+	corrupt := worker
+	corrupt.SHA256 = "0000000000000000000000000000000000000000000000000000000000000000"
+	save(corrupt)
+	issues, err = mgr.VerifyInstalled(ctx, AgentLibraryTypes)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, ABIIssueChecksum, issues[0].Kind)
+	assert.Contains(t, issues[0].Detail, "does not match the manifest's 000000000000")
+}
+
+func TestABIHost_VerifyForeignBinary(t *testing.T) {
+	if runtime.GOOS != osLinux {
+		t.Skip("the test binary is an ELF file on Linux only")
+	}
+	self, err := os.Executable()
+	require.NoError(t, err)
+	info, err := os.Stat(self)
+	require.NoError(t, err)
+
+	// The manifest claims the host's architecture but the binary is not built
+	// for it, which would fail with "exec format error"
+	host := &abiHost{goos: osLinux, goarch: "arm64"}
+	if runtime.GOARCH == "arm64" {
+		host.goarch = "amd64"
+	}
+	lib := Library{Name: "remote-gpu-worker", Platform: osLinux, Arch: host.goarch, Type: LibraryTypeRemoteGPUWorker}
+	issues := host.verify(lib, self, info, "")
+	require.Len(t, issues, 1)
+	assert.Equal(t, ABIIssueArch, issues[0].Kind)
+	assert.Equal(t, "remote-gpu-worker", issues[0].Library)
+	assert.Contains(t, issues[0].Detail, "but this host is "+host.goarch)
+}
@@ -0,0 +1,193 @@
+package deps
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+)
+
+// Sources of a library selection, reported by Which
+const (
+	SelectedByLockfile = "lockfile"
+	SelectedByPin      = "pin"
+	SelectedByChannel  = "channel"
+)
+
+// Hash checks of a cached library file, reported by Which
+const (
+	HashVerified   = "verified"
+	HashMismatch   = "mismatch"
+	HashUnverified = "unverified" // the release publishes no hash
+	HashMissing    = "missing"    // the file is not in the cache
+)
+
+// Resolution explains which library files a library type and vendor resolve
+// to on a platform, and why
+type Resolution struct {
+	Type     string `json:"type"`
+	Vendor   string `json:"vendor,omitempty"`
+	Platform string `json:"platform"`
+	Arch     string `json:"arch"`
+	Version  string `json:"version"`
+	// Source is the SelectedBy constant that chose Version
+	Source  string `json:"source"`
+	Channel string `json:"channel"`
+	// Pin is the pinned version of Type, even when it is not released and
+	// the channel chose instead
+	Pin string `json:"pin,omitempty"`
+	// Lockfile is the path of the project lockfile in use, if any
+	Lockfile string `json:"lockfile,omitempty"`
+	// ManifestDir holds the release, deps and downloaded manifests
+	ManifestDir string            `json:"manifestDir"`
+	LibsDir     string            `json:"libsDir"`
+	Libraries   []ResolvedLibrary `json:"libraries"`
+}
+
+// ResolvedLibrary is one file of a Resolution
+type ResolvedLibrary struct {
+	Library
+	Path string `json:"path"`
+	// Hash is the result of checking Path against SHA256, one of the Hash
+	// constants
+	Hash       string `json:"hash"`
+	ActualHash string `json:"actualHash,omitempty"`
+	// Entries are the manifest records of this library
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry is the record of a library in one manifest
+type ManifestEntry struct {
+	Manifest string `json:"manifest"`
+	Version  string `json:"version"`
+	SHA256   string `json:"sha256,omitempty"`
+	Channel  string `json:"channel,omitempty"`
+	// Matches is false when the record disagrees with the resolved library,
+	// e.g. a stale deps manifest or a download of another version
+	Matches bool `json:"matches"`
+}
+
+// Which resolves libType and vendorSlug to library files the way
+// EnsureLibrariesByTypesForPlatform does, from the cached release manifest
+// and without downloading anything. As there, empty targetOS and targetArch
+// mean the current platform and the flat libs directory.
+func (m *Manager) Which(libType, vendorSlug, targetOS, targetArch string) (*Resolution, error) {
+	res := &Resolution{
+		Type:        libType,
+		Vendor:      NormalizeVendorSlug(vendorSlug),
+		Platform:    targetOS,
+		Arch:        targetArch,
+		ManifestDir: m.paths.ControlPlaneDir(),
+		LibsDir:     m.paths.LibsDir(),
+	}
+	if res.Platform == "" {
+		res.Platform = runtime.GOOS
+	}
+	if res.Arch == "" {
+		res.Arch = runtime.GOARCH
+	}
+	if targetOS != "" {
+		res.LibsDir = m.paths.LibsDirForPlatform(res.Platform, res.Arch)
+	}
+
+	release, err := m.LoadReleaseManifest()
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return nil, fmt.Errorf("no release manifest cached; run 'ggo deps sync' first")
+	}
+	depsManifest, err := m.LoadDepsManifest()
+	if err != nil {
+		return nil, err
+	}
+	downloaded, err := m.LoadDownloadedManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := m.effectiveSettings()
+	res.Channel = settings.Channel
+	res.Pin = settings.Pins[libType]
+
+	versionLibs := make(map[string][]Library)
+	for _, lib := range release.Libraries {
+		if lib.Type == libType {
+			versionLibs[lib.Version] = append(versionLibs[lib.Version], lib)
+		}
+	}
+	libs, source := m.selectVersion(libType, versionLibs, settings)
+	res.Source = source
+
+	for _, lib := range libs {
+		if lib.Platform != res.Platform || lib.Arch != res.Arch {
+			continue
+		}
+		if res.Vendor != "" && lib.VendorSlug != res.Vendor {
+			continue
+		}
+		res.Version = lib.Version
+		resolved := ResolvedLibrary{Library: lib, Path: m.GetLibraryPathInDir(lib.Name, res.LibsDir)}
+		resolved.Hash, resolved.ActualHash = checkHash(resolved.Path, lib.SHA256)
+		resolved.Entries = m.manifestEntries(lib, release, depsManifest, downloaded)
+		res.Libraries = append(res.Libraries, resolved)
+	}
+	if len(res.Libraries) == 0 {
+		return nil, fmt.Errorf("no %s library released for vendor %q on %s/%s", libType, vendorSlug, res.Platform, res.Arch)
+	}
+	sort.Slice(res.Libraries, func(i, j int) bool { return res.Libraries[i].Name < res.Libraries[j].Name })
+	return res, nil
+}
+
+// manifestEntries collects the records of lib in the local manifests and the
+// lockfile, in the order they are consulted
+func (m *Manager) manifestEntries(lib Library, release *ReleaseManifest, depsManifest *DepsManifest, downloaded *DownloadedManifest) []ManifestEntry {
+	entry := func(manifest string, rec Library) ManifestEntry {
+		matches := rec.Version == lib.Version && (rec.SHA256 == "" || lib.SHA256 == "" || rec.SHA256 == lib.SHA256)
+		return ManifestEntry{Manifest: manifest, Version: rec.Version, SHA256: rec.SHA256, Channel: rec.Channel, Matches: matches}
+	}
+
+	var entries []ManifestEntry
+	if m.lock != nil {
+		for _, rec := range m.lock.Libraries {
+			if rec.Key() == lib.Key() {
+				entries = append(entries, entry(LockfileName, rec))
+			}
+		}
+	}
+	for _, rec := range release.Libraries {
+		if rec.Key() == lib.Key() && rec.Version == lib.Version {
+			entries = append(entries, entry(ReleaseManifestFile, rec))
+		}
+	}
+	if depsManifest != nil {
+		if rec, ok := depsManifest.Libraries[lib.Key()]; ok {
+			entries = append(entries, entry(DepsManifestFile, rec))
+		}
+	}
+	if downloaded != nil {
+		if rec, ok := downloaded.Libraries[lib.Key()]; ok {
+			entries = append(entries, entry(DownloadedManifestFile, rec))
+		}
+	}
+	return entries
+}
+
+// checkHash checks the file at path against expectedHash and returns one of
+// the Hash constants, with the actual hash on a mismatch
+func checkHash(path, expectedHash string) (string, string) {
+	if _, err := os.Stat(path); err != nil {
+		return HashMissing, ""
+	}
+	if expectedHash == "" {
+		return HashUnverified, ""
+	}
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return HashMissing, ""
+	}
+	if actual != expectedHash {
+		return HashMismatch, actual
+	}
+	return HashVerified, ""
+}
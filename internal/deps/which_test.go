@@ -0,0 +1,73 @@
+package deps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhich(t *testing.T) {
+	content := []byte("accel")
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	old := Library{Name: "libaccel.so", Version: "1.41.0", Platform: "linux", Arch: "amd64", SHA256: "old", Type: LibraryTypeVGPULibrary, VendorSlug: "nvidia"}
+	latest := Library{Name: "libaccel.so", Version: "1.42.0", Platform: "linux", Arch: "amd64", SHA256: hash, Type: LibraryTypeVGPULibrary, VendorSlug: "nvidia"}
+	amd := Library{Name: "libaccel-amd.so", Version: "1.42.0", Platform: "linux", Arch: "amd64", Type: LibraryTypeVGPULibrary, VendorSlug: "amd"}
+	arm := Library{Name: "libaccel.so", Version: "1.42.0", Platform: "linux", Arch: "arm64", SHA256: "arm", Type: LibraryTypeVGPULibrary, VendorSlug: "nvidia"}
+
+	mgr := NewManager(WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())))
+	_, err := mgr.Which(LibraryTypeVGPULibrary, "nvidia", "linux", "amd64")
+	assert.ErrorContains(t, err, "ggo deps sync", "nothing synced yet")
+
+	require.NoError(t, mgr.saveReleaseManifest(&ReleaseManifest{Libraries: []Library{old, latest, amd, arm}}))
+	require.NoError(t, mgr.SaveDepsManifest(&DepsManifest{Libraries: map[string]Library{old.Key(): old}}))
+
+	res, err := mgr.Which(LibraryTypeVGPULibrary, "NVIDIA", "linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, "1.42.0", res.Version)
+	assert.Equal(t, SelectedByChannel, res.Source)
+	assert.Equal(t, ChannelStable, res.Channel)
+	require.Len(t, res.Libraries, 1, "other vendors and platforms are not resolved")
+	lib := res.Libraries[0]
+	assert.Equal(t, filepath.Join(mgr.paths.LibsDirForPlatform("linux", "amd64"), "libaccel.so"), lib.Path)
+	assert.Equal(t, HashMissing, lib.Hash)
+	assert.Equal(t, []ManifestEntry{
+		{Manifest: ReleaseManifestFile, Version: "1.42.0", SHA256: hash, Matches: true},
+		{Manifest: DepsManifestFile, Version: "1.41.0", SHA256: "old", Matches: false},
+	}, lib.Entries, "a stale deps manifest is reported")
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(lib.Path), 0755))
+	require.NoError(t, os.WriteFile(lib.Path, content, 0644))
+	res, err = mgr.Which(LibraryTypeVGPULibrary, "nvidia", "linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, HashVerified, res.Libraries[0].Hash)
+
+	require.NoError(t, os.WriteFile(lib.Path, []byte("tampered"), 0644))
+	res, err = mgr.Which(LibraryTypeVGPULibrary, "nvidia", "linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, HashMismatch, res.Libraries[0].Hash)
+	assert.NotEmpty(t, res.Libraries[0].ActualHash)
+
+	require.NoError(t, mgr.Pin(LibraryTypeVGPULibrary, "1.41.0"))
+	res, err = mgr.Which(LibraryTypeVGPULibrary, "nvidia", "linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, SelectedByPin, res.Source)
+	assert.Equal(t, "1.41.0", res.Version)
+
+	locked := NewManager(WithPaths(mgr.paths), WithLockfile(&Lockfile{Libraries: []Library{latest}}))
+	res, err = locked.Which(LibraryTypeVGPULibrary, "nvidia", "linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, SelectedByLockfile, res.Source, "the lockfile wins over the pin")
+	assert.Equal(t, "1.42.0", res.Version)
+	assert.Equal(t, LockfileName, res.Libraries[0].Entries[0].Manifest)
+
+	_, err = mgr.Which(LibraryTypeRemoteGPUWorker, "", "linux", "amd64")
+	assert.ErrorContains(t, err, "no remote-gpu-worker library released")
+}
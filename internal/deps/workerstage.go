@@ -0,0 +1,76 @@
+package deps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LatestRemoteGPUWorker syncs releases and returns the remote-gpu-worker the
+// deps selection picks for this platform and channel, or nil if there is
+// none. The saved deps manifest is left alone.
+func (m *Manager) LatestRemoteGPUWorker(ctx context.Context) (*Library, error) {
+	manifest, err := m.SyncReleases(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return newestOfType(m.SelectRequiredDeps(manifest).Libraries, LibraryTypeRemoteGPUWorker), nil
+}
+
+// SelectedRemoteGPUWorker returns the remote-gpu-worker in the saved deps
+// manifest, which GetRemoteGPUWorkerPath installs, or nil if there is none
+func (m *Manager) SelectedRemoteGPUWorker() (*Library, error) {
+	deps, err := m.LoadDepsManifest()
+	if err != nil || deps == nil {
+		return nil, err
+	}
+	return newestOfType(deps.Libraries, LibraryTypeRemoteGPUWorker), nil
+}
+
+func newestOfType(libs map[string]Library, libType string) *Library {
+	var newest *Library
+	for _, lib := range libs {
+		if lib.Type != libType {
+			continue
+		}
+		if newest == nil || CompareVersions(lib.Version, newest.Version) {
+			newest = &lib
+		}
+	}
+	return newest
+}
+
+// StageLibrary downloads lib to <dir>/<version>/<name> unless a verified copy
+// is already there, and returns its path. Releases are kept side by side so a
+// process can be switched back to the previous one.
+func (m *Manager) StageLibrary(ctx context.Context, lib Library, dir string) (string, error) {
+	if lib.SHA256 == "" {
+		return "", fmt.Errorf("release %s has no SHA256 checksum, refusing to install", lib.Version)
+	}
+	if lib.Version == "" || strings.ContainsAny(lib.Version, `/\`) || lib.Version == "." || lib.Version == ".." {
+		return "", fmt.Errorf("invalid release version %q", lib.Version)
+	}
+
+	path := filepath.Join(dir, lib.Version, filepath.Base(lib.Name))
+	if fileHasHash(path, lib.SHA256) {
+		return path, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create release directory: %w", err)
+	}
+
+	tmpPath := path + ".download"
+	defer func() { _ = os.Remove(tmpPath) }()
+	if err := m.downloadVerified(ctx, lib, tmpPath, nil); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", fmt.Errorf("failed to install %s: %w", lib.Name, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,103 @@
+package deps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func workerRelease(version, url string) api.ReleaseInfo {
+	return api.ReleaseInfo{
+		Vendor:  api.VendorInfo{Slug: "nvidia", Name: "NVIDIA"},
+		Version: version,
+		Artifacts: []api.ReleaseArtifact{{
+			OS:       runtime.GOOS,
+			CPUArch:  runtime.GOARCH,
+			URL:      url,
+			SHA256:   "abc",
+			Metadata: map[string]string{"type": LibraryTypeRemoteGPUWorker},
+		}},
+	}
+}
+
+func TestLatestRemoteGPUWorker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.ReleasesResponse{Releases: []api.ReleaseInfo{
+			workerRelease("1.1.0", "https://example.com/1.1.0/remote-gpu-worker"),
+			workerRelease("1.2.0", "https://example.com/1.2.0/remote-gpu-worker"),
+		}})
+	}))
+	defer server.Close()
+
+	mgr := NewManager(
+		WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())),
+		WithAPIClient(api.NewClient(api.WithBaseURL(server.URL))),
+	)
+
+	lib, err := mgr.LatestRemoteGPUWorker(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, lib)
+	assert.Equal(t, "1.2.0", lib.Version)
+
+	selected, err := mgr.SelectedRemoteGPUWorker()
+	assert.NoError(t, err)
+	assert.Nil(t, selected, "the saved deps manifest is not touched")
+}
+
+func TestStageLibrary(t *testing.T) {
+	const body = "worker binary v2"
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte(body))
+	signer := newTestSigner(t, "release-1")
+	lib := signer.sign(Library{Name: "remote-gpu-worker", Version: "2.0.0", URL: server.URL + "/remote-gpu-worker", SHA256: hex.EncodeToString(sum[:])})
+	mgr := NewManager(WithPaths(platform.DefaultPaths().WithConfigDir(t.TempDir())), signer.trust())
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	path, err := mgr.StageLibrary(ctx, lib, dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2.0.0", "remote-gpu-worker"), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(data))
+
+	// A verified copy is reused
+	_, err = mgr.StageLibrary(ctx, lib, dir)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), downloads.Load())
+
+	bad := lib
+	bad.Version = "2.0.1"
+	bad.SHA256 = "0000"
+	_, err = mgr.StageLibrary(ctx, signer.sign(bad), dir)
+	assert.ErrorContains(t, err, "hash mismatch")
+	assert.NoFileExists(t, filepath.Join(dir, "2.0.1", "remote-gpu-worker"))
+
+	bad.SHA256 = ""
+	_, err = mgr.StageLibrary(ctx, bad, dir)
+	assert.Error(t, err)
+	bad.SHA256 = lib.SHA256
+	bad.Version = "../x"
+	_, err = mgr.StageLibrary(ctx, signer.sign(bad), dir)
+	assert.Error(t, err)
+}
@@ -14,6 +14,7 @@ var (
 	ErrBadRequest    = errors.New("bad request")
 	ErrUnavailable   = errors.New("unavailable")
 	ErrNotConfigured = errors.New("not configured")
+	ErrUnauthorized  = errors.New("unauthorized")
 )
 
 // Error is a typed error with code, message and optional details
@@ -106,3 +107,27 @@ func BadRequest(message string) *Error {
 		Err:     ErrBadRequest,
 	}
 }
+
+// kindError marks an error as one of the sentinel errors without changing
+// its message
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
+
+// WithKind marks err so that errors.Is(err, kind) holds, keeping its message
+// and what it wraps. Kind is one of the sentinel errors; nil stays nil.
+func WithKind(err, kind error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{err: err, kind: kind}
+}
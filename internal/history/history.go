@@ -0,0 +1,192 @@
+// Package history records the ggo commands run on this machine, so that they
+// can be listed, replayed and exported for support tickets independently of
+// the shell they were run from.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/audit"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+)
+
+// Entry results
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// DisableEnv turns off recording when set to a non-empty value
+const DisableEnv = "GGO_NO_HISTORY"
+
+// maxSize is the size past which the log drops its oldest entries, keeping
+// about half of it
+const maxSize = 1 << 20
+
+// Entry is one executed command, stored as a single JSON line
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Command is the command path, e.g. "ggo worker update"
+	Command string `json:"command"`
+	// Argv are the arguments ggo was run with, credentials redacted; they
+	// are what a replay runs again
+	Argv []string `json:"argv"`
+	// Flags are the values the command ran with for every flag set on the
+	// command line or from the configured defaults
+	Flags map[string]string `json:"flags,omitempty"`
+	// Dir is the working directory
+	Dir        string `json:"dir,omitempty"`
+	Result     string `json:"result"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Replayable reports whether the entry can be run again as recorded, which
+// is not the case once credentials were redacted from its arguments
+func (e Entry) Replayable() bool {
+	return !slices.ContainsFunc(e.Argv, func(arg string) bool { return strings.Contains(arg, audit.Redacted) })
+}
+
+// Log is the JSONL command history. Unlike the audit log it is trimmed,
+// dropping its oldest entries once it grows past maxSize.
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewLog returns the history stored at path
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the history file path
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes an entry to the end of the history, filling in the
+// timestamp when it is unset
+func (l *Log) Append(e Entry) error {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	e.Timestamp = e.Timestamp.UTC()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	// A single write per line keeps concurrent appends from interleaving
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	info, err := f.Stat()
+	if err := errors.Join(err, f.Close()); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if info.Size() > maxSize {
+		return l.trimLocked()
+	}
+	return nil
+}
+
+// trimLocked rewrites the history with the newest entries fitting in half
+// of maxSize
+func (l *Log) trimLocked() error {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	for len(data) > maxSize/2 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			data = nil
+			break
+		}
+		data = data[i+1:]
+	}
+	if err := utils.AtomicWriteFile(l.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to trim history: %w", err)
+	}
+	return nil
+}
+
+// List returns the recorded entries, oldest first. Malformed lines, such as
+// one cut short by a crash, are skipped.
+func (l *Log) List() ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// RedactArgs returns args with the values of credential flags, such as
+// --token, and of KEY=VALUE pairs with a credential KEY replaced
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		if name, ok := strings.CutPrefix(arg, "-"); ok && arg != "--" {
+			name = strings.TrimPrefix(name, "-")
+			name, _, hasValue := strings.Cut(name, "=")
+			if !audit.IsSensitiveFlag(name) {
+				continue
+			}
+			if hasValue {
+				redacted[i] = arg[:strings.Index(arg, "=")+1] + audit.Redacted
+			} else if i+1 < len(redacted) {
+				i++
+				redacted[i] = audit.Redacted
+			}
+			continue
+		}
+		if key, _, ok := strings.Cut(arg, "="); ok && audit.IsSensitiveFlag(key) {
+			redacted[i] = key + "=" + audit.Redacted
+		}
+	}
+	return redacted
+}
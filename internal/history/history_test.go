@@ -0,0 +1,61 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_AppendAndList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.jsonl")
+	log := NewLog(path)
+
+	entries, err := log.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries, "missing history is empty")
+
+	require.NoError(t, log.Append(Entry{Command: "ggo worker list", Argv: []string{"worker", "list"}, Result: ResultSuccess}))
+	require.NoError(t, log.Append(Entry{Command: "ggo share create", Argv: []string{"share", "create"}, Result: ResultFailure, Error: "boom"}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err = log.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []string{"worker", "list"}, entries[0].Argv)
+	assert.False(t, entries[0].Timestamp.IsZero(), "timestamp is filled in")
+	assert.Equal(t, "boom", entries[1].Error)
+}
+
+func TestLog_DropsOldestEntries(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "history.jsonl"))
+	big := strings.Repeat("x", 10*1024)
+	for i := 0; i < 120; i++ {
+		require.NoError(t, log.Append(Entry{Command: "ggo studio create", Argv: []string{big}, Result: ResultSuccess}))
+	}
+	require.NoError(t, log.Append(Entry{Command: "ggo studio list", Result: ResultSuccess}))
+
+	info, err := os.Stat(log.Path())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(maxSize))
+	entries, err := log.List()
+	require.NoError(t, err)
+	assert.Less(t, len(entries), 121)
+	assert.Equal(t, "ggo studio list", entries[len(entries)-1].Command, "the newest entries are kept")
+}
+
+func TestRedactArgs(t *testing.T) {
+	args := []string{"login", "--token", "gpugo_abc", "--agent-secret=s", "-e", "HF_TOKEN=hf_123", "-e", "MODE=fast", "--name", "demo"}
+	redacted := RedactArgs(args)
+	assert.Equal(t, []string{"login", "--token", "<redacted>", "--agent-secret=<redacted>", "-e", "HF_TOKEN=<redacted>", "-e", "MODE=fast", "--name", "demo"}, redacted)
+	assert.Equal(t, "gpugo_abc", args[2], "the arguments are left alone")
+
+	assert.False(t, Entry{Argv: redacted}.Replayable())
+	assert.True(t, Entry{Argv: []string{"worker", "list"}}.Replayable())
+}
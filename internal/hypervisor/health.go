@@ -0,0 +1,415 @@
+package hypervisor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"k8s.io/klog/v2"
+)
+
+// Worker health states
+const (
+	HealthUnknown   = "unknown"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+// Probe names
+const (
+	ProbeTCP  = "tcp"
+	ProbePing = "ping"
+	ProbeXID  = "xid"
+)
+
+const (
+	DefaultHealthInterval      = 30 * time.Second
+	DefaultProbeTimeout        = 5 * time.Second
+	DefaultFailureThreshold    = 3
+	DefaultMaxHealthRestarts   = 3
+	DefaultHealthRestartWindow = time.Hour
+	// DefaultStartGrace gives a started worker time to open its port before
+	// it is probed
+	DefaultStartGrace = 30 * time.Second
+)
+
+// DefaultCriticalXIDs are the NVIDIA Xid errors after which a GPU's workers
+// cannot be trusted to make progress: GPU stopped processing (43), double
+// bit ECC (48), NVLink (74), fallen off the bus (79), contained and
+// uncontained ECC (94, 95) and GSP errors (119, 120). Xids caused by
+// application faults, such as 13 and 31, are left to the crash reports.
+var DefaultCriticalXIDs = []int{43, 48, 74, 79, 94, 95, 119, 120}
+
+// PingFunc checks that the worker listening on addr answers requests, not
+// just that its port accepts connections
+type PingFunc func(ctx context.Context, workerID, addr string) error
+
+// XIDEvent is an Xid error the kernel attributed to a GPU
+type XIDEvent struct {
+	GPUID   string
+	XID     int
+	Message string
+}
+
+// HealthConfig configures worker health probes. Zero durations and a zero
+// FailureThreshold use the defaults; MaxRestarts 0 only reports unhealthy
+// workers.
+type HealthConfig struct {
+	Interval time.Duration
+	Timeout  time.Duration
+	// FailureThreshold is how many probe rounds in a row must fail before a
+	// worker is unhealthy. A critical Xid makes it unhealthy at once.
+	FailureThreshold int
+	// MaxRestarts bounds the restarts of an unhealthy worker within
+	// RestartWindow, so a broken GPU does not cause a restart loop
+	MaxRestarts   int
+	RestartWindow time.Duration
+	StartGrace    time.Duration
+
+	// TCP connects to the worker's port
+	TCP bool
+	// Ping is the app-level probe; nil disables it
+	Ping PingFunc
+	// XIDEvents returns the Xid errors logged since the given time; nil
+	// disables Xid monitoring
+	XIDEvents    func(since time.Time) []XIDEvent
+	CriticalXIDs []int
+}
+
+func (c *HealthConfig) withDefaults() *HealthConfig {
+	cfg := *c
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultHealthInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultProbeTimeout
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultFailureThreshold
+	}
+	if cfg.RestartWindow <= 0 {
+		cfg.RestartWindow = DefaultHealthRestartWindow
+	}
+	if cfg.StartGrace <= 0 {
+		cfg.StartGrace = DefaultStartGrace
+	}
+	if cfg.CriticalXIDs == nil {
+		cfg.CriticalXIDs = DefaultCriticalXIDs
+	}
+	return &cfg
+}
+
+// ProbeResult is the latest outcome of one probe
+type ProbeResult struct {
+	Probe   string
+	OK      bool
+	Error   string
+	Latency time.Duration
+}
+
+// WorkerHealth is the health of a running worker as seen by the probes
+type WorkerHealth struct {
+	Status              string
+	ConsecutiveFailures int
+	Probes              []ProbeResult
+	// XIDs are the critical Xids seen on the worker's GPUs since it started
+	XIDs []int
+	// Restarts counts restarts triggered by failed probes within the restart window
+	Restarts int
+	// RestartsExhausted is set when an unhealthy worker is left running
+	// because it used up its restarts
+	RestartsExhausted bool
+	CheckedAt         time.Time
+}
+
+// workerHealthState tracks one worker across probe rounds
+type workerHealthState struct {
+	WorkerHealth
+	pid            int
+	startedAt      time.Time
+	restartPending bool
+	restartTimes   []time.Time
+}
+
+// EnableHealthProbes turns on worker health probes. Must be called before Start.
+func (r *Reconciler) EnableHealthProbes(cfg HealthConfig) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	r.health = cfg.withDefaults()
+	r.healthStates = make(map[string]*workerHealthState)
+}
+
+// WorkerHealth returns the probe results of a worker, or false while it was
+// not probed
+func (r *Reconciler) WorkerHealth(workerID string) (WorkerHealth, bool) {
+	r.healthMu.Lock()
+	defer r.healthMu.Unlock()
+	state, ok := r.healthStates[workerID]
+	if !ok || state.CheckedAt.IsZero() {
+		return WorkerHealth{}, false
+	}
+	health := state.WorkerHealth
+	health.Probes = slices.Clone(health.Probes)
+	health.XIDs = slices.Clone(health.XIDs)
+	return health, true
+}
+
+func (r *Reconciler) healthLoop() {
+	ticker := time.NewTicker(r.health.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkHealth(time.Now())
+		}
+	}
+}
+
+// checkHealth runs one probe round over the running, desired workers and
+// queues restarts for the ones that became unhealthy
+func (r *Reconciler) checkHealth(now time.Time) {
+	cfg := r.health
+
+	r.mu.RLock()
+	desired := make(map[string]bool, len(r.desiredWorkers))
+	for id := range r.desiredWorkers {
+		desired[id] = true
+	}
+	r.mu.RUnlock()
+
+	var running []*api.WorkerInfo
+	for _, w := range r.manager.ListWorkers() {
+		if desired[w.WorkerUID] && w.WorkerRunningInfo != nil && w.WorkerRunningInfo.IsRunning {
+			running = append(running, w)
+		}
+	}
+
+	var xids []XIDEvent
+	if cfg.XIDEvents != nil {
+		r.healthMu.Lock()
+		since := r.lastXIDCheck
+		r.lastXIDCheck = now
+		r.healthMu.Unlock()
+		if since.IsZero() {
+			since = now.Add(-cfg.Interval)
+		}
+		xids = cfg.XIDEvents(since)
+	}
+
+	// Probes of different workers run in parallel so that one slow worker
+	// does not delay the others
+	results := make([][]ProbeResult, len(running))
+	probed := make([]bool, len(running))
+	var wg sync.WaitGroup
+	r.healthMu.Lock()
+	for i, w := range running {
+		state := r.healthStateLocked(w, now)
+		if now.Sub(state.startedAt) < cfg.StartGrace {
+			continue
+		}
+		probed[i] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.probeWorker(w)
+		}()
+	}
+	r.healthMu.Unlock()
+	wg.Wait()
+
+	var restarts []string
+	r.healthMu.Lock()
+	for i, w := range running {
+		state := r.healthStates[w.WorkerUID]
+		probes := results[i]
+		for _, ev := range xids {
+			if slices.Contains(w.AllocatedDevices, ev.GPUID) && slices.Contains(cfg.CriticalXIDs, ev.XID) {
+				if !slices.Contains(state.XIDs, ev.XID) {
+					state.XIDs = append(state.XIDs, ev.XID)
+				}
+				klog.Warningf("Critical GPU Xid error: worker_id=%s gpu_id=%s xid=%d message=%q", w.WorkerUID, ev.GPUID, ev.XID, ev.Message)
+			}
+		}
+		if !probed[i] {
+			continue
+		}
+		if cfg.XIDEvents != nil {
+			xidResult := ProbeResult{Probe: ProbeXID, OK: len(state.XIDs) == 0}
+			if !xidResult.OK {
+				xidResult.Error = fmt.Sprintf("critical Xid %v on allocated GPUs", state.XIDs)
+			}
+			probes = append(probes, xidResult)
+		}
+		if len(probes) == 0 {
+			continue
+		}
+		if r.updateHealthLocked(w.WorkerUID, state, probes, now) {
+			restarts = append(restarts, w.WorkerUID)
+		}
+	}
+	// Workers that are down for a moment, e.g. while restarting, keep their
+	// restart history
+	for id := range r.healthStates {
+		if !desired[id] {
+			delete(r.healthStates, id)
+		}
+	}
+	r.healthMu.Unlock()
+
+	if len(restarts) > 0 {
+		r.RequestWorkerRestarts(restarts)
+	}
+}
+
+// healthStateLocked returns the state of a worker, starting over when its
+// process changed
+func (r *Reconciler) healthStateLocked(w *api.WorkerInfo, now time.Time) *workerHealthState {
+	pid := int(w.WorkerRunningInfo.PID)
+	state, ok := r.healthStates[w.WorkerUID]
+	if !ok {
+		state = &workerHealthState{}
+		r.healthStates[w.WorkerUID] = state
+	}
+	if !ok || state.pid != pid {
+		state.WorkerHealth = WorkerHealth{Status: HealthUnknown}
+		state.pid = pid
+		state.startedAt = now
+		state.restartPending = false
+	}
+	return state
+}
+
+// updateHealthLocked records a probe round and reports whether the worker
+// should be restarted
+func (r *Reconciler) updateHealthLocked(workerID string, state *workerHealthState, probes []ProbeResult, now time.Time) bool {
+	cfg := r.health
+	failed := slices.ContainsFunc(probes, func(p ProbeResult) bool { return !p.OK })
+	if failed {
+		state.ConsecutiveFailures++
+	} else {
+		state.ConsecutiveFailures = 0
+	}
+	state.Probes = probes
+	state.CheckedAt = now
+
+	switch {
+	case len(state.XIDs) > 0 || state.ConsecutiveFailures >= cfg.FailureThreshold:
+		if state.Status != HealthUnhealthy {
+			klog.Warningf("Worker unhealthy: worker_id=%s failures=%d xids=%v probes=%s",
+				workerID, state.ConsecutiveFailures, state.XIDs, formatProbes(probes))
+		}
+		state.Status = HealthUnhealthy
+	case !failed:
+		if state.Status == HealthUnhealthy {
+			klog.Infof("Worker healthy again: worker_id=%s", workerID)
+		}
+		state.Status = HealthHealthy
+	}
+
+	state.restartTimes = slices.DeleteFunc(state.restartTimes, func(t time.Time) bool {
+		return now.Sub(t) >= cfg.RestartWindow
+	})
+	state.Restarts = len(state.restartTimes)
+	if state.Status != HealthUnhealthy || state.restartPending {
+		state.RestartsExhausted = false
+		return false
+	}
+	if len(state.restartTimes) >= cfg.MaxRestarts {
+		if !state.RestartsExhausted {
+			klog.Errorf("Unhealthy worker not restarted: worker_id=%s restarts=%d window=%s", workerID, state.Restarts, cfg.RestartWindow)
+		}
+		state.RestartsExhausted = true
+		return false
+	}
+	state.RestartsExhausted = false
+	state.restartTimes = append(state.restartTimes, now)
+	state.Restarts = len(state.restartTimes)
+	state.restartPending = true
+	klog.Warningf("Restarting unhealthy worker: worker_id=%s restart=%d/%d", workerID, state.Restarts, cfg.MaxRestarts)
+	return true
+}
+
+// probeWorker runs the TCP and ping probes against a worker's port
+func (r *Reconciler) probeWorker(w *api.WorkerInfo) []ProbeResult {
+	cfg := r.health
+	port := getPortFromArgs(w.WorkerRunningInfo.Args)
+	if port == 0 {
+		return nil
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+	var results []ProbeResult
+	run := func(probe string, fn func(ctx context.Context) error) {
+		ctx, cancel := context.WithTimeout(r.ctx, cfg.Timeout)
+		defer cancel()
+		start := time.Now()
+		err := fn(ctx)
+		result := ProbeResult{Probe: probe, OK: err == nil, Latency: time.Since(start)}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	if cfg.TCP {
+		run(ProbeTCP, func(ctx context.Context) error {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		})
+	}
+	if cfg.Ping != nil {
+		run(ProbePing, func(ctx context.Context) error {
+			return cfg.Ping(ctx, w.WorkerUID, addr)
+		})
+	}
+	return results
+}
+
+// CommandPing returns a PingFunc that runs an executable with the worker in
+// GGO_WORKER_ID and GGO_WORKER_ADDR (host:port). Exit status 0 is healthy.
+func CommandPing(path string) PingFunc {
+	return func(ctx context.Context, workerID, addr string) error {
+		cmd := exec.CommandContext(ctx, path)
+		cmd.Env = append(os.Environ(), "GGO_WORKER_ID="+workerID, "GGO_WORKER_ADDR="+addr)
+		// Do not wait for children that keep the output open after a timeout
+		cmd.WaitDelay = time.Second
+		output, err := cmd.CombinedOutput()
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out")
+		}
+		if err != nil {
+			if msg := strings.TrimSpace(string(output)); msg != "" {
+				return fmt.Errorf("%w: %s", err, msg)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+func formatProbes(probes []ProbeResult) string {
+	parts := make([]string, 0, len(probes))
+	for _, p := range probes {
+		if p.OK {
+			parts = append(parts, p.Probe+"=ok")
+		} else {
+			parts = append(parts, p.Probe+"="+p.Error)
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,176 @@
+package hypervisor
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/tensor-fusion/pkg/hypervisor/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHealthTestReconciler(t *testing.T, worker *api.WorkerInfo, cfg HealthConfig) (*Reconciler, *MockManager) {
+	t.Helper()
+	mockMgr := NewMockManager()
+	mockMgr.workers[worker.WorkerUID] = worker
+	r := NewReconciler(ReconcilerConfig{Manager: mockMgr})
+	t.Cleanup(r.Stop)
+	r.SetDesiredWorkers([]*api.WorkerInfo{worker})
+	r.EnableHealthProbes(cfg)
+	return r, mockMgr
+}
+
+func queuedRestarts(r *Reconciler) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var ids []string
+	for id := range r.forceRestarts {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestHealth_TCPProbe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	worker := &api.WorkerInfo{
+		WorkerUID:        "worker-1",
+		AllocatedDevices: []string{"gpu-0"},
+		WorkerRunningInfo: &api.WorkerRunningInfo{
+			Args:      []string{"-p", strconv.Itoa(port)},
+			PID:       100,
+			IsRunning: true,
+		},
+	}
+	r, mockMgr := newHealthTestReconciler(t, worker, HealthConfig{TCP: true, FailureThreshold: 2, MaxRestarts: 1})
+
+	now := time.Now()
+	r.checkHealth(now)
+	_, ok := r.WorkerHealth("worker-1")
+	assert.False(t, ok, "not probed during the start grace period")
+
+	now = now.Add(DefaultStartGrace)
+	r.checkHealth(now)
+	health, ok := r.WorkerHealth("worker-1")
+	require.True(t, ok)
+	assert.Equal(t, HealthHealthy, health.Status)
+	require.Len(t, health.Probes, 1)
+	assert.Equal(t, ProbeTCP, health.Probes[0].Probe)
+	assert.True(t, health.Probes[0].OK)
+
+	// The port stops accepting connections: unhealthy after two failed rounds
+	require.NoError(t, ln.Close())
+	r.checkHealth(now.Add(time.Minute))
+	health, _ = r.WorkerHealth("worker-1")
+	assert.Equal(t, HealthHealthy, health.Status)
+	assert.Equal(t, 1, health.ConsecutiveFailures)
+	assert.Empty(t, queuedRestarts(r))
+
+	r.checkHealth(now.Add(2 * time.Minute))
+	health, _ = r.WorkerHealth("worker-1")
+	assert.Equal(t, HealthUnhealthy, health.Status)
+	assert.Equal(t, 1, health.Restarts)
+	assert.Equal(t, []string{"worker-1"}, queuedRestarts(r))
+
+	// The restart is not requested again while it is pending
+	r.mu.Lock()
+	r.forceRestarts = map[string]struct{}{}
+	r.mu.Unlock()
+	r.checkHealth(now.Add(3 * time.Minute))
+	assert.Empty(t, queuedRestarts(r))
+
+	// The restarted worker starts over, but the restart budget is used up
+	mockMgr.mu.Lock()
+	worker.WorkerRunningInfo.PID = 101
+	mockMgr.mu.Unlock()
+	now = now.Add(4 * time.Minute)
+	r.checkHealth(now)
+	for i := 1; i <= 2; i++ {
+		r.checkHealth(now.Add(DefaultStartGrace + time.Duration(i)*time.Minute))
+	}
+	health, _ = r.WorkerHealth("worker-1")
+	assert.Equal(t, HealthUnhealthy, health.Status)
+	assert.True(t, health.RestartsExhausted)
+	assert.Empty(t, queuedRestarts(r))
+}
+
+func TestHealth_CriticalXID(t *testing.T) {
+	worker := &api.WorkerInfo{
+		WorkerUID:         "worker-1",
+		AllocatedDevices:  []string{"gpu-0"},
+		WorkerRunningInfo: &api.WorkerRunningInfo{PID: 100, IsRunning: true},
+	}
+	var since []time.Time
+	r, _ := newHealthTestReconciler(t, worker, HealthConfig{
+		MaxRestarts: 1,
+		XIDEvents: func(t time.Time) []XIDEvent {
+			since = append(since, t)
+			return []XIDEvent{
+				{GPUID: "gpu-0", XID: 13},
+				{GPUID: "gpu-1", XID: 79},
+			}
+		},
+	})
+
+	now := time.Now()
+	r.checkHealth(now)
+	now = now.Add(DefaultStartGrace)
+	r.checkHealth(now)
+	health, ok := r.WorkerHealth("worker-1")
+	require.True(t, ok)
+	assert.Equal(t, HealthHealthy, health.Status, "Xid 13 and Xids of other GPUs are ignored")
+	require.Len(t, since, 2)
+	assert.True(t, since[0].Equal(now.Add(-DefaultStartGrace-DefaultHealthInterval)), "the first round looks back one interval")
+	assert.True(t, since[1].Equal(now.Add(-DefaultStartGrace)), "later rounds continue where the previous one stopped")
+
+	r.health.XIDEvents = func(time.Time) []XIDEvent {
+		return []XIDEvent{{GPUID: "gpu-0", XID: 79, Message: "GPU has fallen off the bus."}}
+	}
+	r.checkHealth(now.Add(time.Minute))
+	health, _ = r.WorkerHealth("worker-1")
+	assert.Equal(t, HealthUnhealthy, health.Status, "a critical Xid needs no repeated failures")
+	assert.Equal(t, []int{79}, health.XIDs)
+	assert.Equal(t, []string{"worker-1"}, queuedRestarts(r))
+}
+
+func TestHealth_UndesiredWorkersAreForgotten(t *testing.T) {
+	worker := &api.WorkerInfo{
+		WorkerUID:         "worker-1",
+		WorkerRunningInfo: &api.WorkerRunningInfo{PID: 100, IsRunning: true},
+	}
+	r, _ := newHealthTestReconciler(t, worker, HealthConfig{
+		XIDEvents: func(time.Time) []XIDEvent { return nil },
+	})
+	now := time.Now()
+	r.checkHealth(now)
+	r.checkHealth(now.Add(DefaultStartGrace))
+	_, ok := r.WorkerHealth("worker-1")
+	require.True(t, ok)
+
+	r.SetDesiredWorkers(nil)
+	r.checkHealth(now.Add(time.Hour))
+	_, ok = r.WorkerHealth("worker-1")
+	assert.False(t, ok)
+}
+
+func TestCommandPing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ping commands in tests are shell scripts")
+	}
+	script := filepath.Join(t.TempDir(), "ping")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n[ \"$GGO_WORKER_ADDR\" = 127.0.0.1:9001 ] || { echo \"bad $GGO_WORKER_ID\"; exit 1; }\n"), 0755))
+
+	ping := CommandPing(script)
+	assert.NoError(t, ping(context.Background(), "worker-1", "127.0.0.1:9001"))
+	err := ping(context.Background(), "worker-1", "127.0.0.1:9002")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad worker-1")
+}
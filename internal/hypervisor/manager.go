@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -34,6 +36,8 @@ type HypervisorManager interface {
 	GetWorkerAllocation(workerUID string) (*api.WorkerAllocation, bool)
 	RegisterWorkerHandler(handler framework.WorkerChangeHandler) error
 	RegisterDeviceHandler(handler framework.DeviceChangeHandler)
+	Detach() []*api.WorkerInfo
+	AdoptWorkers(workers []*api.WorkerInfo) int
 }
 
 // ErrNotStarted is returned when the manager is not started
@@ -56,9 +60,17 @@ type Manager struct {
 	isolationMode tfv1.IsolationModeType
 	stateDir      string
 
+	// devices are the UUIDs (lowercase) of the GPUs this manager may use;
+	// nil allows all GPUs on the host
+	devices map[string]bool
+
 	// State
 	mu      sync.RWMutex
 	started bool
+
+	// adopted are worker processes left running by a previous agent,
+	// which the backend did not start and does not know about
+	adopted map[string]*api.WorkerInfo
 }
 
 // Config holds configuration for the hypervisor manager
@@ -74,6 +86,10 @@ type Config struct {
 
 	// StateDir for tensor-fusion state files (workers.json, devices.json)
 	StateDir string
+
+	// Devices limits the manager to the GPUs with these UUIDs, so agent
+	// instances sharing a host each get their own subset. Empty allows all.
+	Devices []string
 }
 
 // NewManager creates a new hypervisor manager
@@ -98,6 +114,10 @@ func NewManager(cfg Config) (*Manager, error) {
 	// Use given state dir for hypervisor backend state persistence
 	// This is where SingleNodeBackend persists worker state files
 	hypervisorStateDir := filepath.Join(homeDir, ".gpugo", "state")
+	if cfg.StateDir != "" {
+		// Agent instances on one host must not share worker state
+		hypervisorStateDir = cfg.StateDir
+	}
 	if err := os.MkdirAll(hypervisorStateDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create state directory %s for hypervisor backend: %w", hypervisorStateDir, err)
 	}
@@ -115,6 +135,14 @@ func NewManager(cfg Config) (*Manager, error) {
 		return nil, fmt.Errorf("failed to create state directory %s: %w", cfg.StateDir, err)
 	}
 
+	var devices map[string]bool
+	if len(cfg.Devices) > 0 {
+		devices = make(map[string]bool, len(cfg.Devices))
+		for _, uuid := range cfg.Devices {
+			devices[strings.ToLower(uuid)] = true
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Manager{
@@ -124,9 +152,15 @@ func NewManager(cfg Config) (*Manager, error) {
 		vendor:        cfg.Vendor,
 		isolationMode: cfg.IsolationMode,
 		stateDir:      cfg.StateDir,
+		devices:       devices,
 	}, nil
 }
 
+// allowsDevice reports whether the GPU with uuid is in the manager's subset
+func (m *Manager) allowsDevice(uuid string) bool {
+	return m.devices == nil || m.devices[strings.ToLower(uuid)]
+}
+
 // Start initializes and starts all hypervisor components
 func (m *Manager) Start() error {
 	m.mu.Lock()
@@ -205,10 +239,7 @@ func (m *Manager) Stop() error {
 	}
 
 	// Get list of all workers before stopping
-	var workers []*api.WorkerInfo
-	if m.backend != nil {
-		workers = m.backend.ListWorkers()
-	}
+	workers := m.listWorkersLocked()
 	m.mu.RUnlock()
 
 	// Stop all workers explicitly and wait for their processes to exit
@@ -272,7 +303,82 @@ func (m *Manager) Stop() error {
 	if !m.started {
 		return nil
 	}
+	return m.stopComponentsLocked()
+}
 
+// Detach shuts down the hypervisor components but leaves the worker
+// processes running, so the next agent can adopt them with AdoptWorkers.
+// It returns the running workers.
+func (m *Manager) Detach() []*api.WorkerInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.started {
+		return nil
+	}
+	var running []*api.WorkerInfo
+	for _, w := range m.listWorkersLocked() {
+		if w.WorkerRunningInfo != nil && w.WorkerRunningInfo.IsRunning && w.WorkerRunningInfo.PID > 0 {
+			running = append(running, w)
+		}
+	}
+	klog.Infof("Detaching from %d running worker(s)", len(running))
+	if err := m.stopComponentsLocked(); err != nil {
+		klog.Warningf("Failed to stop hypervisor manager cleanly: error=%v", err)
+	}
+	return running
+}
+
+// AdoptWorkers takes over worker processes a previous agent left running.
+// Workers whose process has exited are skipped and started anew by the
+// reconciler. It returns the number of workers adopted.
+func (m *Manager) AdoptWorkers(workers []*api.WorkerInfo) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.started {
+		return 0
+	}
+	if m.adopted == nil {
+		m.adopted = make(map[string]*api.WorkerInfo)
+	}
+	for _, w := range workers {
+		if w.WorkerRunningInfo == nil || w.WorkerRunningInfo.PID == 0 || !isProcessRunning(int(w.WorkerRunningInfo.PID)) {
+			klog.Infof("Worker left by previous agent is gone, it will be restarted: worker_uid=%s", w.WorkerUID)
+			continue
+		}
+		// Keep the GPUs accounted to the worker so they are not handed out twice
+		if _, err := m.allocationController.AllocateWorkerDevices(w); err != nil {
+			klog.Warningf("Failed to allocate devices of adopted worker: worker_uid=%s error=%v", w.WorkerUID, err)
+		}
+		m.adopted[w.WorkerUID] = w
+		klog.Infof("Adopted running worker: worker_uid=%s pid=%d", w.WorkerUID, w.WorkerRunningInfo.PID)
+	}
+	return len(m.adopted)
+}
+
+// listWorkersLocked returns the backend's workers and the adopted workers
+// whose process is still running
+func (m *Manager) listWorkersLocked() []*api.WorkerInfo {
+	var workers []*api.WorkerInfo
+	if m.backend != nil {
+		workers = m.backend.ListWorkers()
+	}
+	for _, w := range m.adopted {
+		running := *w.WorkerRunningInfo
+		running.IsRunning = isProcessRunning(int(running.PID))
+		if !running.IsRunning {
+			continue
+		}
+		adopted := *w
+		adopted.WorkerRunningInfo = &running
+		workers = append(workers, &adopted)
+	}
+	return workers
+}
+
+// stopComponentsLocked stops the hypervisor components. m.mu must be held.
+func (m *Manager) stopComponentsLocked() error {
 	klog.Info("Stopping hypervisor manager components")
 
 	var errs []error
@@ -297,6 +403,7 @@ func (m *Manager) Stop() error {
 
 	m.cancel()
 	m.started = false
+	m.adopted = nil
 
 	if len(errs) > 0 {
 		return fmt.Errorf("errors during shutdown: %v", errs)
@@ -314,7 +421,17 @@ func (m *Manager) ListDevices() ([]*api.DeviceInfo, error) {
 	if !m.started {
 		return nil, ErrNotStarted
 	}
-	return m.deviceController.ListDevices()
+	devices, err := m.deviceController.ListDevices()
+	if err != nil || m.devices == nil {
+		return devices, err
+	}
+	allowed := make([]*api.DeviceInfo, 0, len(devices))
+	for _, d := range devices {
+		if m.allowsDevice(d.UUID) {
+			allowed = append(allowed, d)
+		}
+	}
+	return allowed, nil
 }
 
 // GetDeviceMetrics returns metrics for all devices
@@ -325,7 +442,14 @@ func (m *Manager) GetDeviceMetrics() (map[string]*api.GPUUsageMetrics, error) {
 	if !m.started {
 		return nil, ErrNotStarted
 	}
-	return m.deviceController.GetDeviceMetrics()
+	metrics, err := m.deviceController.GetDeviceMetrics()
+	if err != nil || m.devices == nil {
+		return metrics, err
+	}
+	maps.DeleteFunc(metrics, func(uuid string, _ *api.GPUUsageMetrics) bool {
+		return !m.allowsDevice(uuid)
+	})
+	return metrics, nil
 }
 
 // ListWorkers returns all workers from the backend
@@ -337,7 +461,7 @@ func (m *Manager) ListWorkers() []*api.WorkerInfo {
 		return nil
 	}
 
-	return m.backend.ListWorkers()
+	return m.listWorkersLocked()
 }
 
 // StartWorker starts a worker with the given configuration
@@ -363,6 +487,15 @@ func (m *Manager) StartWorker(workerInfo *api.WorkerInfo) error {
 		}
 	}
 
+	for _, uuid := range workerInfo.AllocatedDevices {
+		if !m.allowsDevice(uuid) {
+			return fmt.Errorf("GPU %s is not assigned to this agent instance", uuid)
+		}
+	}
+
+	// An adopted worker whose process exited is replaced by a backend worker
+	delete(m.adopted, workerInfo.WorkerUID)
+
 	// Allocate devices for the worker
 	if _, err := m.allocationController.AllocateWorkerDevices(workerInfo); err != nil {
 		return fmt.Errorf("allocate devices: %w", err)
@@ -396,6 +529,13 @@ func (m *Manager) StopWorker(workerUID string) error {
 		klog.Warningf("Deallocate failed: worker_uid=%s error=%v", workerUID, err)
 	}
 
+	if adopted, ok := m.adopted[workerUID]; ok {
+		delete(m.adopted, workerUID)
+		terminateWorkerProcess(int(adopted.WorkerRunningInfo.PID))
+		klog.Infof("Adopted worker stopped: worker_uid=%s", workerUID)
+		return nil
+	}
+
 	if err := m.backend.StopWorker(workerUID); err != nil {
 		return fmt.Errorf("stop worker: %w", err)
 	}
@@ -407,13 +547,22 @@ func (m *Manager) StopWorker(workerUID string) error {
 // UpdateWorkerEnv updates environment variables for a worker without restarting its process.
 // The new env vars take effect on next process restart (crash recovery).
 func (m *Manager) UpdateWorkerEnv(workerUID string, env map[string]string) error {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	if !m.started {
 		return ErrNotStarted
 	}
 
+	if adopted, ok := m.adopted[workerUID]; ok {
+		// Like the backend, keep the env for the next start of the worker
+		running := *adopted.WorkerRunningInfo
+		running.Env = maps.Clone(env)
+		updated := *adopted
+		updated.WorkerRunningInfo = &running
+		m.adopted[workerUID] = &updated
+		return nil
+	}
 	return m.backend.UpdateWorkerEnv(workerUID, env)
 }
 
@@ -2,7 +2,9 @@ package hypervisor
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -316,6 +318,22 @@ func TestManager_StateFilePersistence(t *testing.T) {
 	assert.Equal(t, "persistent-worker", workers[0].WorkerUID)
 }
 
+func TestManager_DeviceSubset(t *testing.T) {
+	mgr, err := NewManager(Config{
+		LibPath:  "/nonexistent/path.so",
+		Vendor:   "stub",
+		StateDir: t.TempDir(),
+		Devices:  []string{"GPU-AAA"},
+	})
+	require.NoError(t, err)
+	assert.True(t, mgr.allowsDevice("gpu-aaa"))
+	assert.False(t, mgr.allowsDevice("GPU-BBB"))
+
+	all, err := NewManager(Config{LibPath: "/nonexistent/path.so", Vendor: "stub", StateDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.True(t, all.allowsDevice("GPU-BBB"))
+}
+
 func TestManager_NotStartedErrors(t *testing.T) {
 	mgr, err := NewManager(Config{
 		LibPath: "/nonexistent/path.so",
@@ -338,6 +356,66 @@ func TestManager_NotStartedErrors(t *testing.T) {
 
 	err = mgr.RegisterWorkerHandler(framework.WorkerChangeHandler{})
 	assert.ErrorIs(t, err, ErrNotStarted)
+
+	assert.Nil(t, mgr.Detach())
+	assert.Zero(t, mgr.AdoptWorkers([]*api.WorkerInfo{{WorkerUID: "test"}}))
+}
+
+func TestManager_E2E_AdoptWorkers(t *testing.T) {
+	libPath := getExampleLibPath()
+	if libPath == "" {
+		t.Skip("Example accelerator library not found")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses sleep as the worker process")
+	}
+
+	mgr, err := NewManager(Config{
+		LibPath:       libPath,
+		Vendor:        "stub",
+		IsolationMode: tfv1.IsolationModeShared,
+		StateDir:      t.TempDir(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, mgr.Start())
+	defer mgr.Stop()
+
+	// A worker process left running by a previous agent
+	proc := exec.Command("sleep", "60")
+	require.NoError(t, proc.Start())
+	exited := make(chan struct{})
+	go func() {
+		_ = proc.Wait()
+		close(exited)
+	}()
+
+	adopted := mgr.AdoptWorkers([]*api.WorkerInfo{
+		{
+			WorkerUID:         "adopted-worker",
+			WorkerRunningInfo: &api.WorkerRunningInfo{PID: uint32(proc.Process.Pid), IsRunning: true, Env: map[string]string{"A": "1"}},
+		},
+		{
+			WorkerUID:         "exited-worker",
+			WorkerRunningInfo: &api.WorkerRunningInfo{PID: 0},
+		},
+	})
+	assert.Equal(t, 1, adopted)
+
+	workers := mgr.ListWorkers()
+	require.Len(t, workers, 1)
+	assert.Equal(t, "adopted-worker", workers[0].WorkerUID)
+	assert.True(t, workers[0].WorkerRunningInfo.IsRunning)
+
+	require.NoError(t, mgr.UpdateWorkerEnv("adopted-worker", map[string]string{"A": "2"}))
+	assert.Equal(t, "2", mgr.ListWorkers()[0].WorkerRunningInfo.Env["A"])
+
+	require.NoError(t, mgr.StopWorker("adopted-worker"))
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("adopted worker process was not terminated")
+	}
+	assert.Empty(t, mgr.ListWorkers())
 }
 
 func TestManager_Idempotent(t *testing.T) {
@@ -32,3 +32,12 @@ func forceKillWorkerProcess(pid int) {
 		}
 	}
 }
+
+// terminateWorkerProcess asks a worker process group to exit with SIGTERM
+func terminateWorkerProcess(pid int) {
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil {
+		if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+			klog.Warningf("Failed to terminate worker process: pid=%d error=%v", pid, err)
+		}
+	}
+}
@@ -37,3 +37,8 @@ func forceKillWorkerProcess(pid int) {
 		klog.Infof("Force killed worker process: pid=%d", pid)
 	}
 }
+
+// terminateWorkerProcess stops a worker process; Windows has no SIGTERM
+func terminateWorkerProcess(pid int) {
+	forceKillWorkerProcess(pid)
+}
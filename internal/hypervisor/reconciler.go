@@ -11,6 +11,9 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// drainCheckInterval is how often workers waiting to be stopped are rechecked
+const drainCheckInterval = 5 * time.Second
+
 // Reconciler reconciles cloud-desired workers with hypervisor-actual workers
 type Reconciler struct {
 	manager HypervisorManager
@@ -22,7 +25,16 @@ type Reconciler struct {
 	desiredWorkers  map[string]*api.WorkerInfo
 	forceRestarts   map[string]struct{}
 
+	readyToStop func(workerID string) bool
+
+	// Health probes; nil unless enabled
+	healthMu     sync.Mutex
+	health       *HealthConfig
+	healthStates map[string]*workerHealthState
+	lastXIDCheck time.Time
+
 	// Callbacks for status updates
+	onWorkerStarting    func(workerID string)
 	onWorkerStarted     func(workerID string)
 	onWorkerStopped     func(workerID string)
 	onReconcileComplete func(added, removed, updated int)
@@ -32,7 +44,14 @@ type Reconciler struct {
 type ReconcilerConfig struct {
 	Manager HypervisorManager
 
-	// Optional callbacks
+	// ReadyToStop is asked before a worker that is no longer desired is
+	// stopped; returning false postpones the stop, e.g. while the worker's
+	// client connections drain. Nil stops workers right away.
+	ReadyToStop func(workerID string) bool
+
+	// Optional callbacks. OnWorkerStarting runs right before a worker is
+	// started and the start waits for it.
+	OnWorkerStarting    func(workerID string)
 	OnWorkerStarted     func(workerID string)
 	OnWorkerStopped     func(workerID string)
 	OnReconcileComplete func(added, removed, updated int)
@@ -49,6 +68,8 @@ func NewReconciler(cfg ReconcilerConfig) *Reconciler {
 		reconcileSignal:     make(chan struct{}, 1),
 		desiredWorkers:      make(map[string]*api.WorkerInfo),
 		forceRestarts:       make(map[string]struct{}),
+		readyToStop:         cfg.ReadyToStop,
+		onWorkerStarting:    cfg.OnWorkerStarting,
 		onWorkerStarted:     cfg.OnWorkerStarted,
 		onWorkerStopped:     cfg.OnWorkerStopped,
 		onReconcileComplete: cfg.OnReconcileComplete,
@@ -78,6 +99,9 @@ func (r *Reconciler) SetDesiredWorkers(infos []*api.WorkerInfo) {
 // Start begins the reconciliation loop
 func (r *Reconciler) Start() {
 	go r.reconcileLoop()
+	if r.health != nil {
+		go r.healthLoop()
+	}
 	klog.Info("Reconciler started")
 }
 
@@ -120,25 +144,39 @@ func (r *Reconciler) RequestWorkerRestarts(workerIDs []string) int {
 }
 
 func (r *Reconciler) reconcileLoop() {
-	// Initial reconciliation
-	r.reconcile()
-
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
+	// Draining workers are checked again well before the 30-second ticker
+	drainCheck := time.NewTimer(drainCheckInterval)
+	drainCheck.Stop()
+	defer drainCheck.Stop()
+	reconcile := func() {
+		if r.reconcile() {
+			drainCheck.Reset(drainCheckInterval)
+		}
+	}
+
+	// Initial reconciliation
+	reconcile()
+
 	for {
 		select {
 		case <-r.ctx.Done():
 			return
 		case <-ticker.C:
-			r.reconcile()
+			reconcile()
+		case <-drainCheck.C:
+			reconcile()
 		case <-r.reconcileSignal:
-			r.reconcile()
+			reconcile()
 		}
 	}
 }
 
-func (r *Reconciler) reconcile() {
+// reconcile starts, restarts and stops workers to match the desired state,
+// and reports whether workers that are no longer desired are still draining
+func (r *Reconciler) reconcile() bool {
 	r.mu.Lock()
 	desired := make(map[string]*api.WorkerInfo, len(r.desiredWorkers))
 	maps.Copy(desired, r.desiredWorkers)
@@ -199,8 +237,13 @@ func (r *Reconciler) reconcile() {
 	}
 
 	// 2. Find workers to stop (in actual but not in desired)
+	draining := false
 	for workerID := range actualMap {
 		if _, exists := desired[workerID]; !exists {
+			if r.readyToStop != nil && !r.readyToStop(workerID) {
+				draining = true
+				continue
+			}
 			if err := r.stopWorker(workerID); err != nil {
 				klog.Errorf("Failed to stop orphan worker: worker_id=%s error=%v", workerID, err)
 			} else {
@@ -230,9 +273,14 @@ func (r *Reconciler) reconcile() {
 			r.onReconcileComplete(added, removed, updated)
 		}
 	}
+	return draining
 }
 
 func (r *Reconciler) startWorker(info *api.WorkerInfo) error {
+	if r.onWorkerStarting != nil {
+		r.onWorkerStarting(info.WorkerUID)
+	}
+
 	if err := r.manager.StartWorker(info); err != nil {
 		return err
 	}
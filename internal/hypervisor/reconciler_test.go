@@ -109,6 +109,14 @@ func (m *MockManager) RegisterWorkerHandler(handler framework.WorkerChangeHandle
 
 func (m *MockManager) RegisterDeviceHandler(handler framework.DeviceChangeHandler) {}
 
+func (m *MockManager) Detach() []*api.WorkerInfo {
+	return nil
+}
+
+func (m *MockManager) AdoptWorkers(workers []*api.WorkerInfo) int {
+	return 0
+}
+
 // testReconciler wraps Reconciler with a mock manager
 type testReconciler struct {
 	*Reconciler
@@ -347,6 +355,51 @@ func TestReconciler_RequestWorkerRestarts(t *testing.T) {
 	assert.Equal(t, 1, mockMgr.stoppedCount)
 }
 
+func TestReconciler_ReadyToStop(t *testing.T) {
+	mockMgr := NewMockManager()
+	mockMgr.workers["worker-1"] = &api.WorkerInfo{WorkerUID: "worker-1"}
+
+	ready := false
+	var asked []string
+	r := NewReconciler(ReconcilerConfig{
+		Manager: mockMgr,
+		ReadyToStop: func(workerID string) bool {
+			asked = append(asked, workerID)
+			return ready
+		},
+	})
+	r.SetDesiredWorkers(nil)
+
+	// The stop waits while the worker drains
+	assert.True(t, r.reconcile(), "draining workers are checked again soon")
+	assert.Equal(t, []string{"worker-1"}, asked)
+	assert.Equal(t, 0, mockMgr.stoppedCount)
+	assert.Contains(t, mockMgr.workers, "worker-1")
+
+	ready = true
+	assert.False(t, r.reconcile())
+	assert.Equal(t, 1, mockMgr.stoppedCount)
+	assert.NotContains(t, mockMgr.workers, "worker-1")
+}
+
+func TestReconciler_OnWorkerStarting(t *testing.T) {
+	mockMgr := NewMockManager()
+
+	var started []int
+	r := NewReconciler(ReconcilerConfig{
+		Manager: mockMgr,
+		OnWorkerStarting: func(workerID string) {
+			// Runs before the manager sees the worker
+			started = append(started, mockMgr.startedCount)
+		},
+	})
+	r.SetDesiredWorkers([]*api.WorkerInfo{{WorkerUID: "worker-1"}})
+
+	r.reconcile()
+	assert.Equal(t, []int{0}, started)
+	assert.Equal(t, 1, mockMgr.startedCount)
+}
+
 func TestReconcilerStatus_String(t *testing.T) {
 	tests := []struct {
 		name     string
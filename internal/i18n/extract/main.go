@@ -0,0 +1,271 @@
+// Command extract collects the translatable messages of the CLI and adds them
+// to every message catalog in internal/i18n/locales.
+//
+// A message is translatable when it is a string literal passed to i18n.T or
+// i18n.Tf, or to one of the TUI sinks that translate their input (output
+// messages, table headers, status table keys, prompts, ...). Run it from the
+// internal/i18n directory with 'go generate' or from the repository root with
+// 'make i18n'. With -check it only reports catalogs that are out of date.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sourceDirs are the directories, relative to the repository root, whose
+// output goes through the TUI
+var sourceDirs = []string{"cmd/ggo", "internal/tui"}
+
+// outputSinks are Output methods whose first argument is translated
+var outputSinks = map[string]bool{
+	"Success": true, "Error": true, "Info": true, "Warning": true,
+	"Successf": true, "Errorf": true, "Infof": true, "Warningf": true,
+	"Printf": true, "Println": true,
+}
+
+// nonOutputReceivers share method names with Output but do not translate
+var nonOutputReceivers = map[string]bool{
+	"fmt": true, "klog": true, "log": true, "errors": true, "cmd": true, "t": true,
+}
+
+// tuiFuncs are tui package functions and the index of their translated argument
+var tuiFuncs = map[string]int{
+	"KeyValue": 0, "DetailBox": 0,
+	"SuccessMessage": 0, "ErrorMessage": 0, "WarningMessage": 0, "InfoMessage": 0,
+	"InputPrompt": 0, "InputPromptWithDefault": 0, "InputPromptOptional": 0,
+	"ConfirmPrompt": 0, "SelectPrompt": 0, "SelectPromptWithDefault": 0, "MultiSelectPrompt": 0,
+	"StepHeader": 2,
+}
+
+// commandPrefixes start messages that are commands for the user to run
+var commandPrefixes = []string{"ggo ", "eval ", "for /f ", "source ", ". $PROFILE"}
+
+// dataFields are the translated fields of the cmdutil renderables
+var dataFields = map[string]bool{
+	"Message": true, "Empty": true, "Title": true, "Headers": true, "Operation": true,
+}
+
+func main() {
+	root := flag.String("root", "../..", "repository root")
+	locales := flag.String("locales", "locales", "directory of the message catalogs")
+	check := flag.Bool("check", false, "report out-of-date catalogs instead of updating them")
+	flag.Parse()
+
+	msgs, err := Extract(*root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "extract: %v\n", err)
+		os.Exit(1)
+	}
+	stale, err := Sync(*locales, msgs, !*check)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "extract: %v\n", err)
+		os.Exit(1)
+	}
+	if *check && len(stale) > 0 {
+		fmt.Fprintf(os.Stderr, "message catalogs are out of date: %s\nrun 'make i18n' to update them\n", strings.Join(stale, ", "))
+		os.Exit(1)
+	}
+}
+
+// Extract returns the sorted, unique translatable messages below root
+func Extract(root string) ([]string, error) {
+	seen := map[string]bool{}
+	for _, dir := range sourceDirs {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			return extractFile(path, seen)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	msgs := make([]string, 0, len(seen))
+	for m := range seen {
+		msgs = append(msgs, m)
+	}
+	sort.Strings(msgs)
+	return msgs, nil
+}
+
+func extractFile(path string, seen map[string]bool) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return err
+	}
+	add := func(e ast.Expr) {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		if s, err := strconv.Unquote(lit.Value); err == nil && translatable(s) {
+			seen[s] = true
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || len(n.Args) == 0 {
+				return true
+			}
+			recv, _ := sel.X.(*ast.Ident)
+			name := sel.Sel.Name
+			switch {
+			case recv != nil && recv.Name == "i18n":
+				add(n.Args[0])
+			case recv != nil && recv.Name == "tui":
+				if idx, ok := tuiFuncs[name]; ok && idx < len(n.Args) {
+					add(n.Args[idx])
+				}
+			case name == "Headers":
+				for _, arg := range n.Args {
+					add(arg)
+				}
+			case name == "Add" || name == "AddWithStatus":
+				add(n.Args[0])
+			case outputSinks[name] && !nonOutputReceivers[rootIdent(sel.X)]:
+				if name != "Println" || len(n.Args) == 1 {
+					add(n.Args[0])
+				}
+			}
+		case *ast.KeyValueExpr:
+			key, ok := n.Key.(*ast.Ident)
+			if !ok || !dataFields[key.Name] {
+				return true
+			}
+			if list, ok := n.Value.(*ast.CompositeLit); ok {
+				for _, elt := range list.Elts {
+					add(elt)
+				}
+				return true
+			}
+			add(n.Value)
+		}
+		return true
+	})
+	return nil
+}
+
+// rootIdent returns the identifier a receiver expression such as
+// klog.V(2) or r.out starts from
+func rootIdent(e ast.Expr) string {
+	for {
+		switch x := e.(type) {
+		case *ast.Ident:
+			return x.Name
+		case *ast.SelectorExpr:
+			e = x.X
+		case *ast.CallExpr:
+			e = x.Fun
+		default:
+			return ""
+		}
+	}
+}
+
+// translatable reports whether s has words to translate, rather than only
+// format verbs, symbols, whitespace or a command line to copy
+func translatable(s string) bool {
+	trimmed := strings.TrimSpace(s)
+	for _, prefix := range commandPrefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return false
+		}
+	}
+
+	letters := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' {
+			// skip the verb
+			for i++; i < len(s) && !isLetter(s[i]) && s[i] != '%'; i++ {
+			}
+			continue
+		}
+		if isLetter(c) {
+			letters++
+		} else {
+			letters = 0
+		}
+		if letters >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Sync brings every catalog in dir to exactly msgs: new messages are added
+// untranslated and messages no longer used are dropped. It returns the names
+// of the catalogs that were out of date and, if write is set, rewrites them.
+func Sync(dir string, msgs []string, write bool) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		updated := make(map[string]string, len(msgs))
+		for _, m := range msgs {
+			updated[m] = catalog[m]
+		}
+		out, err := encode(updated)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(out, data) {
+			continue
+		}
+		stale = append(stale, filepath.Base(path))
+		if write {
+			if err := os.WriteFile(path, out, 0644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stale, nil
+}
+
+// encode writes a catalog with sorted keys and without HTML escaping, so
+// translators can edit it by hand
+func encode(catalog map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(catalog); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cmd.go")
+	src := `package cmd
+
+func render(out *tui.Output) {
+	out.Success("Worker created")
+	out.Warningf("Failed to stop %s: %v", id, err)
+	klog.Infof("Started: worker_id=%s", id)
+	klog.V(2).Infof("Polling: worker_id=%s", id)
+	fmt.Printf("export PATH=%s\n", dir)
+	fmt.Println(i18n.T("Press Enter to continue"))
+	out.Println("   ggo use abc123")
+	out.Println("  %s", "not a format")
+	tui.NewTable().Headers("NAME", "STATUS", "%")
+	tui.StepHeader(1, 3, "Select GPUs")
+	_ = cmdutil.ListData[string]{Headers: []string{"ID", "LABELS"}, Empty: "No agents found"}
+}
+`
+	require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+
+	seen := map[string]bool{}
+	require.NoError(t, extractFile(path, seen))
+	assert.Equal(t, map[string]bool{
+		"Worker created":          true,
+		"Failed to stop %s: %v":   true,
+		"Press Enter to continue": true,
+		"NAME":                    true,
+		"STATUS":                  true,
+		"Select GPUs":             true,
+		"ID":                      true,
+		"LABELS":                  true,
+		"No agents found":         true,
+	}, seen)
+}
+
+func TestTranslatable(t *testing.T) {
+	assert.True(t, translatable("Agent %s deleted"))
+	assert.True(t, translatable("ID"))
+	assert.False(t, translatable("%s: %s"))
+	assert.False(t, translatable("\r  %s: %.1f%%"))
+	assert.False(t, translatable("  ggo clean --all"))
+	assert.False(t, translatable("→"))
+}
+
+// TestCatalogsUpToDate fails when output strings were added or changed
+// without running 'make i18n'
+func TestCatalogsUpToDate(t *testing.T) {
+	msgs, err := Extract(filepath.Join("..", "..", ".."))
+	require.NoError(t, err)
+	require.NotEmpty(t, msgs)
+
+	stale, err := Sync(filepath.Join("..", "locales"), msgs, false)
+	require.NoError(t, err)
+	assert.Empty(t, stale, "message catalogs are out of date; run 'make i18n'")
+}
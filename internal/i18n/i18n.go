@@ -0,0 +1,158 @@
+// Package i18n translates the CLI's human-readable output.
+//
+// Messages are keyed by their English source text, so untranslated messages
+// fall back to English unchanged. Catalogs live in locales/<lang>.json and map
+// a source message (or printf format) to its translation; an empty value means
+// the message has not been translated yet. Run 'make i18n' after adding or
+// changing output strings to add them to every catalog.
+package i18n
+
+//go:generate go run ./extract
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// LangEnv overrides the language taken from the locale environment
+	LangEnv = "GPU_GO_LANG"
+
+	// DefaultLanguage is the source language of all messages
+	DefaultLanguage = "en"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	mu       sync.RWMutex
+	lang     string
+	catalog  map[string]string
+	initOnce sync.Once
+)
+
+// T returns the translation of msg in the current language, or msg itself
+// when it has no translation
+func T(msg string) string {
+	initOnce.Do(initFromEnv)
+	mu.RLock()
+	defer mu.RUnlock()
+	if s := catalog[msg]; s != "" {
+		return s
+	}
+	return msg
+}
+
+// Tf translates a printf format and formats it with args
+func Tf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Language returns the current language
+func Language() string {
+	initOnce.Do(initFromEnv)
+	mu.RLock()
+	defer mu.RUnlock()
+	return lang
+}
+
+// SetLanguage switches the current language. Unknown languages select the
+// default language.
+func SetLanguage(l string) {
+	initOnce.Do(func() {})
+	l = Match(l)
+	c, err := Catalog(l)
+	if err != nil {
+		l, c = DefaultLanguage, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	lang, catalog = l, c
+}
+
+// Languages returns the languages that ship a catalog
+func Languages() []string {
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		return []string{DefaultLanguage}
+	}
+	langs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		langs = append(langs, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Catalog loads the embedded catalog of a language
+func Catalog(l string) (map[string]string, error) {
+	data, err := locales.ReadFile(path.Join("locales", l+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("no catalog for language %q", l)
+	}
+	var c map[string]string
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid catalog for language %q: %w", l, err)
+	}
+	return c, nil
+}
+
+// Match maps a locale name such as "zh_CN.UTF-8", "zh" or "en-US" to the
+// closest shipped language, or the default language
+func Match(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if locale == "" {
+		return DefaultLanguage
+	}
+
+	langs := Languages()
+	for _, l := range langs {
+		if strings.EqualFold(l, locale) {
+			return l
+		}
+	}
+	base, _, _ := strings.Cut(locale, "-")
+	for _, l := range langs {
+		lb, _, _ := strings.Cut(l, "-")
+		if strings.EqualFold(lb, base) {
+			return l
+		}
+	}
+	return DefaultLanguage
+}
+
+// DetectLocale returns the locale selected by the environment: GPU_GO_LANG,
+// then the POSIX LC_ALL, LC_MESSAGES and LANG variables
+func DetectLocale() string {
+	for _, key := range []string{LangEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		switch v := os.Getenv(key); v {
+		case "":
+			continue
+		case "C", "POSIX":
+			return DefaultLanguage
+		default:
+			return v
+		}
+	}
+	return DefaultLanguage
+}
+
+func initFromEnv() {
+	l := Match(DetectLocale())
+	c, err := Catalog(l)
+	if err != nil {
+		l, c = DefaultLanguage, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	lang, catalog = l, c
+}
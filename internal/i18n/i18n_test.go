@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	assert.Equal(t, "zh-CN", Match("zh_CN.UTF-8"))
+	assert.Equal(t, "zh-CN", Match("zh-cn"))
+	assert.Equal(t, "zh-CN", Match("zh"))
+	assert.Equal(t, "zh-CN", Match("zh_TW.UTF-8"), "other Chinese locales fall back to zh-CN")
+	assert.Equal(t, "en", Match("en_US.UTF-8"))
+	assert.Equal(t, "en", Match("de_DE@euro"))
+	assert.Equal(t, "en", Match(""))
+}
+
+func TestDetectLocale(t *testing.T) {
+	for _, key := range []string{LangEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		t.Setenv(key, "")
+	}
+	assert.Equal(t, DefaultLanguage, DetectLocale())
+
+	t.Setenv("LANG", "zh_CN.UTF-8")
+	assert.Equal(t, "zh_CN.UTF-8", DetectLocale())
+
+	t.Setenv("LC_ALL", "C")
+	assert.Equal(t, DefaultLanguage, DetectLocale(), "LC_ALL=C overrides LANG")
+
+	t.Setenv(LangEnv, "zh")
+	assert.Equal(t, "zh", DetectLocale(), "GPU_GO_LANG overrides the locale")
+}
+
+func TestTranslate(t *testing.T) {
+	t.Cleanup(func() { SetLanguage(DefaultLanguage) })
+
+	SetLanguage("zh_CN.UTF-8")
+	assert.Equal(t, "zh-CN", Language())
+	assert.Equal(t, "未找到 Worker", T("No workers found"))
+	assert.Equal(t, "Worker w1 删除成功！", Tf("Worker %s deleted successfully!", "w1"))
+	assert.Equal(t, "not in any catalog", T("not in any catalog"), "untranslated messages fall back to English")
+
+	SetLanguage("fr")
+	assert.Equal(t, DefaultLanguage, Language())
+	assert.Equal(t, "No workers found", T("No workers found"))
+}
+
+var verbRe = regexp.MustCompile(`%[-+# 0-9.*]*[a-zA-Z%]`)
+
+// TestCatalogsKeepFormat guards against translations that would break the
+// printf arguments or the layout of the source message
+func TestCatalogsKeepFormat(t *testing.T) {
+	for _, lang := range Languages() {
+		catalog, err := Catalog(lang)
+		require.NoError(t, err, lang)
+		for msg, translation := range catalog {
+			if translation == "" {
+				continue
+			}
+			assert.Equal(t, verbRe.FindAllString(msg, -1), verbRe.FindAllString(translation, -1),
+				"%s: format verbs of %q", lang, msg)
+			assert.Equal(t, leadingSpace(msg), leadingSpace(translation), "%s: leading whitespace of %q", lang, msg)
+			assert.Equal(t, strings.HasSuffix(msg, "\n"), strings.HasSuffix(translation, "\n"), "%s: trailing newline of %q", lang, msg)
+		}
+	}
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " \n\r"))]
+}
@@ -0,0 +1,250 @@
+// Package inventory exports the fleet known to the platform (agents, GPUs,
+// workers and shares) as CSV, JSON or NDJSON for CMDBs and spreadsheets.
+package inventory
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat validates an export format name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSON, FormatNDJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported format %q (expected csv, json or ndjson)", s)
+}
+
+// Column is one exported field of a row of type T
+type Column[T any] struct {
+	Name  string
+	Value func(row *T) any
+}
+
+// Table describes how rows of one resource are exported
+type Table[T any] struct {
+	Columns []Column[T]
+	// Changed returns the latest time the row is known to have been created,
+	// started or seen, for --since filters
+	Changed func(row *T) time.Time
+}
+
+// Names lists the table's column names in export order
+func (t *Table[T]) Names() []string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Select returns the named columns in the given order, or all columns when
+// names is empty
+func (t *Table[T]) Select(names []string) ([]Column[T], error) {
+	if len(names) == 0 {
+		return t.Columns, nil
+	}
+	selected := make([]Column[T], 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(t.Columns, func(c Column[T]) bool { return c.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(t.Names(), ", "))
+		}
+		selected = append(selected, t.Columns[i])
+	}
+	return selected, nil
+}
+
+// Export writes the rows changed at or after since (all rows when since is
+// zero) with the selected columns and returns the number of rows written.
+// Rows are encoded and written one at a time, so large fleets stream out
+// instead of being rendered as a whole.
+func Export[T any](w io.Writer, format Format, table *Table[T], columns []string, rows []T, since time.Time) (int, error) {
+	cols, err := table.Select(columns)
+	if err != nil {
+		return 0, err
+	}
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+
+	enc, err := newEncoder(w, format, names)
+	if err != nil {
+		return 0, err
+	}
+	values := make([]any, len(cols))
+	count := 0
+	for i := range rows {
+		row := &rows[i]
+		if !since.IsZero() && table.Changed != nil && table.Changed(row).Before(since) {
+			continue
+		}
+		for j, c := range cols {
+			values[j] = c.Value(row)
+		}
+		if err := enc.write(values); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, enc.close()
+}
+
+// encoder writes rows in one format to a buffered writer
+type encoder struct {
+	format Format
+	names  []string
+	w      *bufio.Writer
+	csv    *csv.Writer
+	rows   int
+}
+
+func newEncoder(w io.Writer, format Format, names []string) (*encoder, error) {
+	e := &encoder{format: format, names: names, w: bufio.NewWriter(w)}
+	switch format {
+	case FormatCSV:
+		e.csv = csv.NewWriter(e.w)
+		if err := e.csv.Write(names); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		if _, err := e.w.WriteString("["); err != nil {
+			return nil, err
+		}
+	case FormatNDJSON:
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	return e, nil
+}
+
+func (e *encoder) write(values []any) error {
+	if e.format == FormatCSV {
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = csvValue(v)
+		}
+		e.rows++
+		return e.csv.Write(record)
+	}
+
+	var line []byte
+	if e.format == FormatJSON {
+		if e.rows > 0 {
+			line = append(line, ',')
+		}
+		line = append(line, "\n  "...)
+	}
+	// Objects are built field by field to keep the selected column order
+	line = append(line, '{')
+	for i, v := range values {
+		if i > 0 {
+			line = append(line, ',')
+		}
+		key, _ := json.Marshal(e.names[i])
+		value, err := json.Marshal(jsonValue(v))
+		if err != nil {
+			return fmt.Errorf("failed to encode column %s: %w", e.names[i], err)
+		}
+		line = append(line, key...)
+		line = append(line, ':')
+		line = append(line, value...)
+	}
+	line = append(line, '}')
+	if e.format == FormatNDJSON {
+		line = append(line, '\n')
+	}
+	e.rows++
+	_, err := e.w.Write(line)
+	return err
+}
+
+func (e *encoder) close() error {
+	switch e.format {
+	case FormatCSV:
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	case FormatJSON:
+		end := "\n]\n"
+		if e.rows == 0 {
+			end = "]\n"
+		}
+		if _, err := e.w.WriteString(end); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+// jsonValue maps unknown times to null
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.UTC()
+	case *time.Time:
+		if v == nil || v.IsZero() {
+			return nil
+		}
+		return v.UTC()
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return v
+}
+
+// csvValue flattens a value into one cell. Lists are joined with ';' and
+// maps become sorted key=value pairs, so cells never need nested quoting.
+func csvValue(v any) string {
+	switch v := jsonValue(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, ";")
+	case []int:
+		parts := make([]string, len(v))
+		for i, n := range v {
+			parts[i] = strconv.Itoa(n)
+		}
+		return strings.Join(parts, ";")
+	case map[string]string:
+		pairs := make([]string, 0, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			pairs = append(pairs, k+"="+v[k])
+		}
+		return strings.Join(pairs, ";")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,128 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAgents() []api.AgentInfo {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []api.AgentInfo{
+		{
+			AgentID:  "agent-1",
+			Hostname: "gpu-node-1",
+			Status:   "online",
+			GPUs: []api.GPUInfo{
+				{GPUID: "GPU-aaa", GPUIndex: 0, Model: "A100", VRAMMb: 81920, DriverVersion: "560.35.03", CUDAVersion: "12.6"},
+				{GPUID: "GPU-bbb", GPUIndex: 1, Model: "A100", VRAMMb: 81920},
+			},
+			Workers:    []api.WorkerInfo{{WorkerID: "w1", GPUIDs: []string{"GPU-bbb"}}},
+			Labels:     map[string]string{"team": "ml", "rack": "a1"},
+			LastSeenAt: now,
+			CreatedAt:  now.Add(-30 * 24 * time.Hour),
+		},
+		{
+			AgentID:    "agent-2",
+			Hostname:   "gpu-node-2",
+			Status:     "offline",
+			LastSeenAt: now.Add(-10 * 24 * time.Hour),
+			CreatedAt:  now.Add(-40 * 24 * time.Hour),
+		},
+	}
+}
+
+func TestParseFormatAndResource(t *testing.T) {
+	f, err := ParseFormat("NDJSON")
+	require.NoError(t, err)
+	assert.Equal(t, FormatNDJSON, f)
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+
+	r, err := ParseResource("gpu")
+	require.NoError(t, err)
+	assert.Equal(t, ResourceGPUs, r)
+	_, err = ParseResource("clusters")
+	assert.Error(t, err)
+}
+
+func TestExport_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := Export(&buf, FormatCSV, AgentTable, []string{"hostname", "labels", "last_seen_at"}, testAgents(), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"hostname", "labels", "last_seen_at"},
+		{"gpu-node-1", "rack=a1;team=ml", "2026-03-01T12:00:00Z"},
+		{"gpu-node-2", "", "2026-02-19T12:00:00Z"},
+	}, records)
+}
+
+func TestExport_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Export(&buf, FormatJSON, GPUTable, []string{"gpu_id", "model", "worker_ids"}, GPURows(testAgents()), time.Time{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(buf.String(), `[`+"\n  "+`{"gpu_id":"GPU-aaa","model":"A100","worker_ids":null}`), "columns keep their order")
+
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rows))
+	require.Len(t, rows, 2)
+	assert.Equal(t, []any{"w1"}, rows[1]["worker_ids"])
+
+	buf.Reset()
+	n, err := Export(&buf, FormatJSON, GPUTable, nil, nil, time.Time{})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestExport_NDJSONSince(t *testing.T) {
+	var buf bytes.Buffer
+	since := time.Date(2026, 2, 25, 0, 0, 0, 0, time.UTC)
+	n, err := Export(&buf, FormatNDJSON, AgentTable, nil, testAgents(), since)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	var row map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &row))
+	assert.Equal(t, "agent-1", row["agent_id"])
+	assert.Equal(t, float64(2), row["gpu_count"])
+	assert.Equal(t, []any{"A100"}, row["gpu_models"])
+	assert.Len(t, row, len(AgentTable.Columns))
+}
+
+func TestExport_UnknownColumn(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Export(&buf, FormatCSV, WorkerTable, []string{"worker_id", "bogus"}, nil, time.Time{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown column "bogus"`)
+	assert.Empty(t, buf.String(), "nothing is written for invalid columns")
+}
+
+func TestShareRows(t *testing.T) {
+	expires := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	rows := ShareRows(
+		[]api.ShareInfo{{ShareID: "s1", WorkerID: "w1", ExpiresAt: &expires}, {ShareID: "s2", WorkerID: "gone"}},
+		[]api.WorkerInfo{{WorkerID: "w1", AgentID: "agent-1"}},
+	)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "agent-1", rows[0].AgentID)
+	assert.Empty(t, rows[1].AgentID)
+
+	var buf bytes.Buffer
+	_, err := Export(&buf, FormatCSV, ShareTable, []string{"share_id", "agent_id", "max_uses", "expires_at"}, rows, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "share_id,agent_id,max_uses,expires_at\ns1,agent-1,,2026-04-01T00:00:00Z\ns2,,,\n", buf.String())
+}
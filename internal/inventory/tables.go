@@ -0,0 +1,196 @@
+package inventory
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+)
+
+// Resource is an exportable kind of inventory
+type Resource string
+
+const (
+	ResourceAgents  Resource = "agents"
+	ResourceGPUs    Resource = "gpus"
+	ResourceWorkers Resource = "workers"
+	ResourceShares  Resource = "shares"
+)
+
+// Resources lists the exportable resources
+var Resources = []Resource{ResourceAgents, ResourceGPUs, ResourceWorkers, ResourceShares}
+
+// ParseResource validates a resource name; singular names are accepted too
+func ParseResource(s string) (Resource, error) {
+	r := Resource(strings.ToLower(s))
+	if !strings.HasSuffix(string(r), "s") {
+		r += "s"
+	}
+	if slices.Contains(Resources, r) {
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown resource %q (expected agents, gpus, workers or shares)", s)
+}
+
+// GPU is a GPU together with the agent it is installed in
+type GPU struct {
+	Agent *api.AgentInfo
+	GPU   *api.GPUInfo
+}
+
+// Share is a share together with the agent serving its worker
+type Share struct {
+	Share   *api.ShareInfo
+	AgentID string
+}
+
+// GPURows flattens the GPUs of agents
+func GPURows(agents []api.AgentInfo) []GPU {
+	var rows []GPU
+	for i := range agents {
+		for j := range agents[i].GPUs {
+			rows = append(rows, GPU{Agent: &agents[i], GPU: &agents[i].GPUs[j]})
+		}
+	}
+	return rows
+}
+
+// ShareRows pairs shares with the agents of their workers
+func ShareRows(shares []api.ShareInfo, workers []api.WorkerInfo) []Share {
+	agentOf := make(map[string]string, len(workers))
+	for _, w := range workers {
+		agentOf[w.WorkerID] = w.AgentID
+	}
+	rows := make([]Share, len(shares))
+	for i := range shares {
+		rows[i] = Share{Share: &shares[i], AgentID: agentOf[shares[i].WorkerID]}
+	}
+	return rows
+}
+
+// agentChanged is the later of an agent's registration and last report
+func agentChanged(a *api.AgentInfo) time.Time {
+	if a.LastSeenAt.After(a.CreatedAt) {
+		return a.LastSeenAt
+	}
+	return a.CreatedAt
+}
+
+// AgentTable exports one row per agent
+var AgentTable = &Table[api.AgentInfo]{
+	Columns: []Column[api.AgentInfo]{
+		{"agent_id", func(a *api.AgentInfo) any { return a.AgentID }},
+		{"hostname", func(a *api.AgentInfo) any { return a.Hostname }},
+		{"status", func(a *api.AgentInfo) any { return a.Status }},
+		{"os", func(a *api.AgentInfo) any { return a.OS }},
+		{"arch", func(a *api.AgentInfo) any { return a.Arch }},
+		{"network_ips", func(a *api.AgentInfo) any { return a.NetworkIPs }},
+		{"gpu_count", func(a *api.AgentInfo) any { return max(len(a.GPUs), a.GPUCount) }},
+		{"gpu_models", func(a *api.AgentInfo) any { return gpuModels(a.GPUs) }},
+		{"worker_count", func(a *api.AgentInfo) any { return len(a.Workers) }},
+		{"labels", func(a *api.AgentInfo) any { return a.Labels }},
+		{"last_seen_at", func(a *api.AgentInfo) any { return a.LastSeenAt }},
+		{"created_at", func(a *api.AgentInfo) any { return a.CreatedAt }},
+	},
+	Changed: agentChanged,
+}
+
+// GPUTable exports one row per GPU
+var GPUTable = &Table[GPU]{
+	Columns: []Column[GPU]{
+		{"gpu_id", func(g *GPU) any { return g.GPU.GPUID }},
+		{"index", func(g *GPU) any { return g.GPU.GPUIndex }},
+		{"vendor", func(g *GPU) any { return g.GPU.Vendor }},
+		{"model", func(g *GPU) any { return g.GPU.Model }},
+		{"vram_mb", func(g *GPU) any { return g.GPU.VRAMMb }},
+		{"driver_version", func(g *GPU) any { return g.GPU.DriverVersion }},
+		{"cuda_version", func(g *GPU) any { return g.GPU.CUDAVersion }},
+		{"agent_id", func(g *GPU) any { return g.Agent.AgentID }},
+		{"hostname", func(g *GPU) any { return g.Agent.Hostname }},
+		{"worker_ids", func(g *GPU) any { return gpuWorkers(g) }},
+	},
+	Changed: func(g *GPU) time.Time { return agentChanged(g.Agent) },
+}
+
+// WorkerTable exports one row per worker
+var WorkerTable = &Table[api.WorkerInfo]{
+	Columns: []Column[api.WorkerInfo]{
+		{"worker_id", func(w *api.WorkerInfo) any { return w.WorkerID }},
+		{"name", func(w *api.WorkerInfo) any { return w.Name }},
+		{"agent_id", func(w *api.WorkerInfo) any { return w.AgentID }},
+		{"hostname", func(w *api.WorkerInfo) any { return w.AgentHostname }},
+		{"status", func(w *api.WorkerInfo) any { return w.Status }},
+		{"enabled", func(w *api.WorkerInfo) any { return w.Enabled }},
+		{"listen_port", func(w *api.WorkerInfo) any { return w.ListenPort }},
+		{"gpu_ids", func(w *api.WorkerInfo) any { return w.GPUIDs }},
+		{"pid", func(w *api.WorkerInfo) any { return w.PID }},
+		{"restarts", func(w *api.WorkerInfo) any { return w.Restarts }},
+		{"connections", func(w *api.WorkerInfo) any { return len(w.Connections) }},
+		{"started_at", func(w *api.WorkerInfo) any { return w.StartedAt }},
+		{"created_at", func(w *api.WorkerInfo) any { return w.CreatedAt }},
+	},
+	Changed: func(w *api.WorkerInfo) time.Time {
+		if w.StartedAt != nil && w.StartedAt.After(w.CreatedAt) {
+			return *w.StartedAt
+		}
+		return w.CreatedAt
+	},
+}
+
+// ShareTable exports one row per share
+var ShareTable = &Table[Share]{
+	Columns: []Column[Share]{
+		{"share_id", func(s *Share) any { return s.Share.ShareID }},
+		{"short_code", func(s *Share) any { return s.Share.ShortCode }},
+		{"short_link", func(s *Share) any { return s.Share.ShortLink }},
+		{"worker_id", func(s *Share) any { return s.Share.WorkerID }},
+		{"agent_id", func(s *Share) any { return s.AgentID }},
+		{"hardware_vendor", func(s *Share) any { return s.Share.HardwareVendor }},
+		{"connection_url", func(s *Share) any { return s.Share.ConnectionURL }},
+		{"relay", func(s *Share) any { return s.Share.Relay }},
+		{"used_count", func(s *Share) any { return s.Share.UsedCount }},
+		{"max_uses", func(s *Share) any { return s.Share.MaxUses }},
+		{"expires_at", func(s *Share) any { return s.Share.ExpiresAt }},
+		{"created_at", func(s *Share) any { return s.Share.CreatedAt }},
+	},
+	Changed: func(s *Share) time.Time { return s.Share.CreatedAt },
+}
+
+// ColumnNames lists the columns of a resource
+func ColumnNames(r Resource) []string {
+	switch r {
+	case ResourceAgents:
+		return AgentTable.Names()
+	case ResourceGPUs:
+		return GPUTable.Names()
+	case ResourceWorkers:
+		return WorkerTable.Names()
+	case ResourceShares:
+		return ShareTable.Names()
+	}
+	return nil
+}
+
+// gpuModels lists the distinct GPU models of an agent in index order
+func gpuModels(gpus []api.GPUInfo) []string {
+	var models []string
+	for _, g := range gpus {
+		if g.Model != "" && !slices.Contains(models, g.Model) {
+			models = append(models, g.Model)
+		}
+	}
+	return models
+}
+
+// gpuWorkers lists the agent's workers that are assigned the GPU
+func gpuWorkers(g *GPU) []string {
+	var ids []string
+	for _, w := range g.Agent.Workers {
+		if slices.Contains(w.GPUIDs, g.GPU.GPUID) {
+			ids = append(ids, w.WorkerID)
+		}
+	}
+	return ids
+}
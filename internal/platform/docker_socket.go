@@ -1,8 +1,10 @@
 package platform
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
@@ -36,6 +38,34 @@ func HasDockerSocket() bool {
 	return fileExists(orbstackSock)
 }
 
+// RootlessPodmanSocket returns the docker-compatible API socket of the
+// user's rootless Podman service as a DOCKER_HOST value, or "" when the
+// service is not listening (`systemctl --user enable --now podman.socket`)
+func RootlessPodmanSocket() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	socketPath := filepath.Join(runtimeDir, "podman", "podman.sock")
+	if !fileExists(socketPath) {
+		return ""
+	}
+	return "unix://" + socketPath
+}
+
+// DefaultDockerHost returns the DOCKER_HOST the docker CLI should use when
+// none is configured: rootless Podman's socket on Linux hosts that run
+// Podman instead of a Docker daemon, "" to keep the CLI's default
+func DefaultDockerHost() string {
+	if os.Getenv("DOCKER_HOST") != "" || fileExists("/var/run/docker.sock") {
+		return ""
+	}
+	return RootlessPodmanSocket()
+}
+
 func dockerHostSocketPath(dockerHost string) string {
 	if dockerHost == "" {
 		return ""
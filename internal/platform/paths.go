@@ -16,12 +16,17 @@ const (
 	osLinux   = "linux"
 )
 
+// ProfileDirEnv points at the directory of the active CLI profile. It is set
+// by the CLI when a named profile is selected and is empty otherwise.
+const ProfileDirEnv = "GGO_PROFILE_DIR"
+
 // Paths provides cross-platform path resolution for ggo
 type Paths struct {
-	configDir string
-	stateDir  string
-	cacheDir  string
-	userDir   string
+	configDir  string
+	stateDir   string
+	cacheDir   string
+	userDir    string
+	profileDir string
 }
 
 // DefaultPaths returns the default paths for the current platform
@@ -33,6 +38,7 @@ func DefaultPaths() *Paths {
 	p.configDir = p.defaultConfigDir()
 	p.stateDir = p.defaultStateDir()
 	p.cacheDir = p.defaultCacheDir()
+	p.profileDir = os.Getenv(ProfileDirEnv)
 	return p
 }
 
@@ -62,6 +68,22 @@ func (p *Paths) UserDir() string {
 	return p.userDir
 }
 
+// ProfileDir returns the directory of the active CLI profile, or "" when the
+// default endpoint is used
+func (p *Paths) ProfileDir() string {
+	return p.profileDir
+}
+
+// ControlPlaneDir returns the directory for state that belongs to a single
+// control plane, such as cached release manifests
+// Default: ConfigDir; with a CLI profile: the profile directory
+func (p *Paths) ControlPlaneDir() string {
+	if p.profileDir != "" {
+		return p.profileDir
+	}
+	return p.configDir
+}
+
 // LibDir returns the directory for shared libraries
 // All platforms: ~/.gpugo/lib (or UserDir/lib)
 func (p *Paths) LibDir() string {
@@ -97,6 +119,18 @@ func (p *Paths) AgentPIDFile() string {
 	return filepath.Join(p.stateDir, "agent.pid")
 }
 
+// AuditLogFile returns the path to the local audit log of CLI mutations
+// All platforms: ~/.gpugo/state/audit.jsonl (or StateDir/audit.jsonl)
+func (p *Paths) AuditLogFile() string {
+	return filepath.Join(p.stateDir, "audit.jsonl")
+}
+
+// HistoryFile returns the path to the local history of ggo commands
+// All platforms: ~/.gpugo/state/history.jsonl (or StateDir/history.jsonl)
+func (p *Paths) HistoryFile() string {
+	return filepath.Join(p.stateDir, "history.jsonl")
+}
+
 // ConnectionsDir returns the directory for worker connection files
 // Each worker writes its connections to a separate file: {workerID}.txt
 // All platforms: ~/.gpugo/state/connections (or StateDir/connections)
@@ -168,20 +202,33 @@ func (p *Paths) defaultUserDir() string {
 // WithConfigDir returns a new Paths with a custom config directory
 func (p *Paths) WithConfigDir(dir string) *Paths {
 	return &Paths{
-		configDir: dir,
-		stateDir:  p.stateDir,
-		cacheDir:  p.cacheDir,
-		userDir:   p.userDir,
+		configDir:  dir,
+		stateDir:   p.stateDir,
+		cacheDir:   p.cacheDir,
+		userDir:    p.userDir,
+		profileDir: p.profileDir,
 	}
 }
 
 // WithStateDir returns a new Paths with a custom state directory
 func (p *Paths) WithStateDir(dir string) *Paths {
 	return &Paths{
-		configDir: p.configDir,
-		stateDir:  dir,
-		cacheDir:  p.cacheDir,
-		userDir:   p.userDir,
+		configDir:  p.configDir,
+		stateDir:   dir,
+		cacheDir:   p.cacheDir,
+		userDir:    p.userDir,
+		profileDir: p.profileDir,
+	}
+}
+
+// WithCacheDir returns a new Paths with a custom cache directory
+func (p *Paths) WithCacheDir(dir string) *Paths {
+	return &Paths{
+		configDir:  p.configDir,
+		stateDir:   p.stateDir,
+		cacheDir:   dir,
+		userDir:    p.userDir,
+		profileDir: p.profileDir,
 	}
 }
 
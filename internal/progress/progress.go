@@ -0,0 +1,151 @@
+// Package progress emits machine-readable progress of long operations, such
+// as library downloads, image pulls and studio creation, as a stream of
+// newline-delimited JSON events. GUIs and wrappers driving the CLI with
+// --progress-events render progress bars from it.
+//
+// Events are only written after Enable; until then every function is a
+// no-op, so commands report progress unconditionally.
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	EventStepStarted  = "step-started"
+	EventProgress     = "progress"
+	EventStepFinished = "step-finished"
+	EventError        = "error"
+)
+
+// Steps reported by several commands
+const (
+	StepLibraries = "libraries"  // GPU client library downloads
+	StepImagePull = "image-pull" // studio image pull
+	StepContainer = "container"  // studio container creation until it runs
+	StepVerify    = "verify"     // studio GPU readiness probe
+)
+
+// Units of progress events
+const (
+	UnitBytes     = "bytes"
+	UnitLayers    = "layers"
+	UnitArtifacts = "artifacts"
+)
+
+// updateInterval is the minimum time between two progress events of one item
+const updateInterval = 200 * time.Millisecond
+
+// Event is one line of the progress stream. Step names the operation the
+// event belongs to; error events without a step report that the command
+// failed.
+type Event struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Step    string    `json:"step,omitempty"`
+	Message string    `json:"message,omitempty"`
+	// Item is the part of the step that progressed, e.g. a library name
+	Item    string `json:"item,omitempty"`
+	Current int64  `json:"current,omitempty"`
+	// Total is unset when the size of the work is not known
+	Total   int64    `json:"total,omitempty"`
+	Unit    string   `json:"unit,omitempty"`
+	Percent *float64 `json:"percent,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	out     io.Writer
+	updated map[string]time.Time // step + item -> time of the last progress event
+	now     = time.Now
+)
+
+// Enable writes events to w from now on
+func Enable(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+	updated = make(map[string]time.Time)
+}
+
+// Enabled reports whether events are written
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil
+}
+
+// StepStarted reports that step began
+func StepStarted(step, message string) {
+	emit(Event{Type: EventStepStarted, Step: step, Message: message})
+}
+
+// Update reports that item of step reached current of total units; total is
+// 0 when unknown. Updates of an item are throttled, except the final one.
+func Update(step, item string, current, total int64, unit string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if out == nil {
+		return
+	}
+	key := step + "\x00" + item
+	t := now()
+	last, seen := updated[key]
+	if seen && t.Sub(last) < updateInterval && (total <= 0 || current < total) {
+		return
+	}
+	updated[key] = t
+
+	e := Event{Type: EventProgress, Step: step, Item: item, Current: current, Total: total, Unit: unit}
+	if total > 0 {
+		percent := float64(current) / float64(total) * 100
+		e.Percent = &percent
+	}
+	writeLocked(e, t)
+}
+
+// StepFinished reports that step completed
+func StepFinished(step, message string) {
+	emit(Event{Type: EventStepFinished, Step: step, Message: message})
+}
+
+// Failed reports that step failed with err; an empty step reports that the
+// command failed
+func Failed(step string, err error) {
+	if err == nil {
+		return
+	}
+	emit(Event{Type: EventError, Step: step, Error: err.Error()})
+}
+
+func emit(e Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if out == nil {
+		return
+	}
+	if e.Type != EventStepStarted {
+		// Forget the step's throttling state so a repeated step starts fresh
+		for key := range updated {
+			if strings.HasPrefix(key, e.Step+"\x00") {
+				delete(updated, key)
+			}
+		}
+	}
+	writeLocked(e, now())
+}
+
+func writeLocked(e Event, t time.Time) {
+	e.Time = t
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_, _ = out.Write(append(data, '\n'))
+}
@@ -0,0 +1,65 @@
+package progress
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readEvents(t *testing.T, buf *bytes.Buffer) []Event {
+	var events []Event
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e), scanner.Text())
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestEvents(t *testing.T) {
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	defer func() {
+		now = time.Now
+		Enable(nil)
+	}()
+
+	StepStarted(StepLibraries, "ignored while disabled")
+	assert.False(t, Enabled())
+
+	var buf bytes.Buffer
+	Enable(&buf)
+	assert.True(t, Enabled())
+
+	StepStarted(StepLibraries, "Downloading GPU client libraries")
+	Update(StepLibraries, "libcuda.so", 10, 100, UnitBytes)
+	Update(StepLibraries, "libcuda.so", 20, 100, UnitBytes) // throttled
+	Update(StepLibraries, "libnvml.so", 5, 0, UnitBytes)    // other item
+	clock = clock.Add(updateInterval)
+	Update(StepLibraries, "libcuda.so", 50, 100, UnitBytes)
+	Update(StepLibraries, "libcuda.so", 100, 100, UnitBytes) // final update is never throttled
+	StepFinished(StepLibraries, "")
+	Failed(StepImagePull, errors.New("pull failed"))
+	Failed("", nil)
+
+	events := readEvents(t, &buf)
+	require.Len(t, events, 7)
+	assert.Equal(t, Event{Type: EventStepStarted, Time: events[0].Time, Step: StepLibraries, Message: "Downloading GPU client libraries"}, events[0])
+	assert.Equal(t, EventProgress, events[1].Type)
+	assert.Equal(t, int64(10), events[1].Current)
+	require.NotNil(t, events[1].Percent)
+	assert.InDelta(t, 10.0, *events[1].Percent, 0.001)
+	assert.Equal(t, "libnvml.so", events[2].Item)
+	assert.Nil(t, events[2].Percent, "no percentage without a total")
+	assert.Equal(t, int64(50), events[3].Current)
+	assert.Equal(t, int64(100), events[4].Current)
+	assert.Equal(t, EventStepFinished, events[5].Type)
+	assert.Equal(t, Event{Type: EventError, Time: events[6].Time, Step: StepImagePull, Error: "pull failed"}, events[6])
+}
@@ -0,0 +1,160 @@
+// Package protocol registers ggo as the handler of ggo:// links, so that a
+// share link clicked in a browser starts the matching ggo command
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+)
+
+// Scheme is the URL scheme ggo handles
+const Scheme = "ggo"
+
+// Link actions
+const (
+	// ActionUse sets up the shared GPU with 'ggo use <code>'
+	ActionUse = "use"
+	// ActionStudio creates a studio named after the code with
+	// 'ggo studio create <code> -s <code>'
+	ActionStudio = "studio"
+)
+
+// maxLinkLength bounds the links accepted from the browser
+const maxLinkLength = 512
+
+// shareCodePattern is what share codes look like; anything else in a link is
+// rejected before it gets near a command line. The leading alphanumeric keeps
+// a code from being taken for a flag.
+var shareCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{2,63}$`)
+
+// ErrUnsupported is returned when links cannot be registered on this OS
+var ErrUnsupported = errors.New("ggo:// links are not supported on this operating system")
+
+// Link is a parsed and validated ggo:// link
+type Link struct {
+	Action string `json:"action"`
+	Code   string `json:"code"`
+}
+
+// Parse validates a ggo:// link such as ggo://use/abc123. Only the known
+// actions with a single share code are accepted; query strings, fragments,
+// credentials, extra path segments and unusual characters are rejected.
+func Parse(raw string) (*Link, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) > maxLinkLength {
+		return nil, fmt.Errorf("link is longer than %d characters", maxLinkLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link: %w", err)
+	}
+	if !strings.EqualFold(u.Scheme, Scheme) {
+		return nil, fmt.Errorf("not a %s:// link: %q", Scheme, raw)
+	}
+	if u.User != nil || u.Port() != "" || u.RawQuery != "" || u.Fragment != "" || u.ForceQuery {
+		return nil, fmt.Errorf("unexpected parts in link %q", raw)
+	}
+
+	// ggo://use/abc123 has the action as host; ggo:use/abc123 is opaque
+	path := u.Host + u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+	// Browsers may append a trailing slash
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected %s://<action>/<share-code>, got %q", Scheme, raw)
+	}
+
+	link := &Link{Action: strings.ToLower(parts[0]), Code: parts[1]}
+	if link.Action != ActionUse && link.Action != ActionStudio {
+		return nil, fmt.Errorf("unknown link action %q (supported: %s, %s)", parts[0], ActionUse, ActionStudio)
+	}
+	if !shareCodePattern.MatchString(link.Code) {
+		return nil, fmt.Errorf("invalid share code %q", link.Code)
+	}
+	return link, nil
+}
+
+// Args returns the ggo arguments the link runs. 'ggo use' is not available
+// on macOS, where use links create a studio instead.
+func (l *Link) Args(goos string) []string {
+	if l.Action == ActionStudio || goos == "darwin" {
+		return []string{"studio", "create", platform.NormalizeName(l.Code), "-s", l.Code}
+	}
+	return []string{"use", l.Code}
+}
+
+// HandlerArgs are the arguments the OS passes to ggo for a clicked link,
+// followed by the link itself
+var HandlerArgs = []string{"protocol", "open"}
+
+// WindowsCommand returns the shell\open\command registry value that runs exe
+// for a link; Windows substitutes the link for %1
+func WindowsCommand(exe string) string {
+	return fmt.Sprintf(`"%s" %s "%%1"`, exe, strings.Join(HandlerArgs, " "))
+}
+
+// DesktopEntry returns the freedesktop.org entry that handles links on Linux.
+// The handler runs in a terminal so that the flow can prompt.
+func DesktopEntry(exe string) string {
+	var entry strings.Builder
+	entry.WriteString("[Desktop Entry]\n")
+	entry.WriteString("Type=Application\n")
+	entry.WriteString("Name=GPU Go\n")
+	entry.WriteString("Comment=Open GPU Go share links\n")
+	fmt.Fprintf(&entry, "Exec=%s %s %%u\n", quoteDesktopExec(exe), strings.Join(HandlerArgs, " "))
+	entry.WriteString("Terminal=true\n")
+	entry.WriteString("NoDisplay=true\n")
+	fmt.Fprintf(&entry, "MimeType=x-scheme-handler/%s;\n", Scheme)
+	return entry.String()
+}
+
+// quoteDesktopExec quotes an Exec argument as the Desktop Entry
+// Specification requires, escaping the characters special inside quotes.
+// The result is written to a key file, whose own escaping doubles the
+// backslashes once more.
+func quoteDesktopExec(arg string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, r := range arg {
+		switch r {
+		case '\\':
+			quoted.WriteString(`\\\\`)
+			continue
+		case '"', '`', '$':
+			quoted.WriteString(`\\`)
+		case '%':
+			quoted.WriteByte('%')
+		}
+		quoted.WriteRune(r)
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+// AppleScript returns the source of the macOS handler application. Link
+// clicks arrive as an Apple event, so a small AppleScript app receives them
+// and opens Terminal with the handler command, quoting both exe and the link
+// for the shell.
+func AppleScript(exe string) string {
+	var script strings.Builder
+	script.WriteString("on open location theLink\n")
+	script.WriteString("\ttell application \"Terminal\"\n")
+	script.WriteString("\t\tactivate\n")
+	fmt.Fprintf(&script, "\t\tdo script (quoted form of \"%s\") & \" %s \" & (quoted form of theLink)\n",
+		escapeAppleScript(exe), strings.Join(HandlerArgs, " "))
+	script.WriteString("\tend tell\n")
+	script.WriteString("end open location\n")
+	return script.String()
+}
+
+// escapeAppleScript escapes s for an AppleScript string literal
+func escapeAppleScript(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,67 @@
+package protocol
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for raw, want := range map[string]Link{
+		"ggo://use/abc123":      {Action: ActionUse, Code: "abc123"},
+		"ggo://use/abc123/":     {Action: ActionUse, Code: "abc123"},
+		"GGO://USE/AbC-12_3":    {Action: ActionUse, Code: "AbC-12_3"},
+		"ggo:use/abc123":        {Action: ActionUse, Code: "abc123"},
+		" ggo://studio/abc123 ": {Action: ActionStudio, Code: "abc123"},
+	} {
+		link, err := Parse(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, *link, raw)
+	}
+
+	for _, raw := range []string{
+		"https://gpu.tf/s/abc123",
+		"ggo://use",
+		"ggo://use/abc123/extra",
+		"ggo://run/abc123",
+		"ggo://use/abc123?exec=rm",
+		"ggo://use/abc123#x",
+		"ggo://user@use/abc123",
+		"ggo://use:22/abc123",
+		"ggo://use/abc%20123",
+		"ggo://use/abc;calc",
+		`ggo://use/abc"123`,
+		"ggo://use/--help",
+		"ggo://use/" + strings.Repeat("a", maxLinkLength),
+	} {
+		_, err := Parse(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestLinkArgs(t *testing.T) {
+	use := &Link{Action: ActionUse, Code: "abc123"}
+	assert.Equal(t, []string{"use", "abc123"}, use.Args("linux"))
+	assert.Equal(t, []string{"use", "abc123"}, use.Args("windows"))
+	// 'ggo use' is not available on macOS
+	assert.Equal(t, []string{"studio", "create", "abc123", "-s", "abc123"}, use.Args("darwin"))
+
+	studio := &Link{Action: ActionStudio, Code: "AbC123"}
+	assert.Equal(t, []string{"studio", "create", "abc123", "-s", "AbC123"}, studio.Args("linux"))
+}
+
+func TestHandlerCommands(t *testing.T) {
+	assert.Equal(t, `"C:\Program Files\ggo\ggo.exe" protocol open "%1"`,
+		WindowsCommand(`C:\Program Files\ggo\ggo.exe`))
+
+	entry := DesktopEntry(`/opt/my ggo/50%$"/ggo`)
+	assert.Contains(t, entry, `Exec="/opt/my ggo/50%%\\$\\"/ggo" protocol open %u`+"\n")
+	assert.Contains(t, entry, "MimeType=x-scheme-handler/ggo;\n")
+	assert.Contains(t, entry, "Terminal=true\n")
+
+	script := AppleScript(`/Users/me/bin/g"go`)
+	assert.Contains(t, script, "on open location theLink\n")
+	assert.Contains(t, script, `do script (quoted form of "/Users/me/bin/g\"go") & " protocol open " & (quoted form of theLink)`)
+}
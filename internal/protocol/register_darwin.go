@@ -0,0 +1,113 @@
+//go:build darwin
+
+package protocol
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// handlerApp is the AppleScript application receiving ggo:// links
+	handlerApp = "GPU Go Links.app"
+	// handlerBundleID identifies the handler application to LaunchServices
+	handlerBundleID = "ai.tensorfusion.ggo.links"
+	lsregister      = "/System/Library/Frameworks/CoreServices.framework/Frameworks/LaunchServices.framework/Support/lsregister"
+)
+
+// Install builds a handler application in ~/Applications that declares the
+// ggo:// scheme, registers it with LaunchServices and returns its path
+func Install(exe string) (string, error) {
+	app, err := appPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(app), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(app), err)
+	}
+
+	src, err := os.CreateTemp("", "ggo-links-*.applescript")
+	if err != nil {
+		return "", fmt.Errorf("failed to write handler script: %w", err)
+	}
+	defer func() { _ = os.Remove(src.Name()) }()
+	if _, err := src.WriteString(AppleScript(exe)); err != nil {
+		_ = src.Close()
+		return "", fmt.Errorf("failed to write handler script: %w", err)
+	}
+	if err := src.Close(); err != nil {
+		return "", fmt.Errorf("failed to write handler script: %w", err)
+	}
+
+	// osacompile replaces an existing application
+	_ = os.RemoveAll(app)
+	if err := run("osacompile", "-o", app, src.Name()); err != nil {
+		return "", err
+	}
+	plist := filepath.Join(app, "Contents", "Info.plist")
+	urlTypes := fmt.Sprintf(`[{"CFBundleURLName":"GPU Go share link","CFBundleURLSchemes":["%s"]}]`, Scheme)
+	for _, edit := range [][]string{
+		{"CFBundleIdentifier", "-string", handlerBundleID},
+		{"CFBundleURLTypes", "-json", urlTypes},
+		// No Dock icon for a handler that only opens Terminal
+		{"LSUIElement", "-bool", "true"},
+	} {
+		if err := run("plutil", append([]string{"-replace"}, append(edit, plist)...)...); err != nil {
+			return "", err
+		}
+	}
+	if err := run(lsregister, "-f", app); err != nil {
+		return "", err
+	}
+	return app, nil
+}
+
+// Uninstall unregisters and removes the handler application, returning its
+// path
+func Uninstall() (string, error) {
+	app, err := appPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(app); err != nil {
+		return app, nil
+	}
+	// Unregistering a bundle LaunchServices has forgotten is not an error
+	_ = run(lsregister, "-u", app)
+	if err := os.RemoveAll(app); err != nil {
+		return "", fmt.Errorf("failed to remove %s: %w", app, err)
+	}
+	return app, nil
+}
+
+// Installed returns the handler application, if any
+func Installed() (string, bool) {
+	app, err := appPath()
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(app); err != nil {
+		return "", false
+	}
+	return app, true
+}
+
+func appPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, "Applications", handlerApp), nil
+}
+
+// run runs a registration tool, including its output in the error
+func run(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", filepath.Base(name), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+//go:build linux
+
+package protocol
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// desktopFileName is the desktop entry registered for x-scheme-handler/ggo
+const desktopFileName = "ggo-url-handler.desktop"
+
+// Install writes a desktop entry handling ggo:// links, makes it the default
+// handler with xdg-mime and returns the path of the entry
+func Install(exe string) (string, error) {
+	dir := applicationsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, desktopFileName)
+	if err := os.WriteFile(path, []byte(DesktopEntry(exe)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write desktop entry: %w", err)
+	}
+
+	output, err := exec.Command("xdg-mime", "default", desktopFileName, "x-scheme-handler/"+Scheme).CombinedOutput()
+	if err != nil {
+		return path, fmt.Errorf("failed to set the default handler with xdg-mime: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	updateDesktopDatabase(dir)
+	return path, nil
+}
+
+// Uninstall removes the desktop entry and its default handler association,
+// returning the path of the entry
+func Uninstall() (string, error) {
+	dir := applicationsDir()
+	path := filepath.Join(dir, desktopFileName)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove desktop entry: %w", err)
+	}
+	if err := removeMimeDefault(filepath.Join(configDir(), "mimeapps.list")); err != nil {
+		klog.Warningf("Failed to remove the ggo:// association from mimeapps.list: error=%v", err)
+	}
+	updateDesktopDatabase(dir)
+	return path, nil
+}
+
+// Installed returns the desktop entry handling ggo:// links, if any
+func Installed() (string, bool) {
+	path := filepath.Join(applicationsDir(), desktopFileName)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// removeMimeDefault drops the lines associating the scheme with the desktop
+// entry from a mimeapps.list file
+func removeMimeDefault(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "x-scheme-handler/"+Scheme+"="+desktopFileName {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == len(lines) {
+		return nil
+	}
+	return os.WriteFile(path, []byte(strings.Join(kept, "\n")), 0644)
+}
+
+// updateDesktopDatabase refreshes the MIME cache of dir, where the tool is
+// installed; desktop environments also pick changes up on their own
+func updateDesktopDatabase(dir string) {
+	if err := exec.Command("update-desktop-database", dir).Run(); err != nil {
+		klog.V(4).Infof("update-desktop-database not run: error=%v", err)
+	}
+}
+
+func applicationsDir() string {
+	return filepath.Join(xdgDir("XDG_DATA_HOME", ".local/share"), "applications")
+}
+
+func configDir() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// xdgDir returns the XDG base directory in env, or its default under home
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, fallback)
+}
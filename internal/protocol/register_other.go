@@ -0,0 +1,18 @@
+//go:build !windows && !linux && !darwin
+
+package protocol
+
+// Install is not supported on this OS
+func Install(exe string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// Uninstall is not supported on this OS
+func Uninstall() (string, error) {
+	return "", ErrUnsupported
+}
+
+// Installed always reports no registration on this OS
+func Installed() (string, bool) {
+	return "", false
+}
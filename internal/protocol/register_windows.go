@@ -0,0 +1,67 @@
+//go:build windows
+
+package protocol
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// classesKey is the per-user registration of the scheme; it needs no
+// administrator rights and takes effect immediately
+const classesKey = `Software\Classes\` + Scheme
+
+// Install registers exe as the handler of ggo:// links for the current user
+// and returns where the registration was written
+func Install(exe string) (string, error) {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, classesKey, registry.SET_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed to create registry key: %w", err)
+	}
+	defer key.Close()
+	if err := key.SetStringValue("", "URL:GPU Go share link"); err != nil {
+		return "", fmt.Errorf("failed to write registry key: %w", err)
+	}
+	// Marks the key as a URL scheme
+	if err := key.SetStringValue("URL Protocol", ""); err != nil {
+		return "", fmt.Errorf("failed to write registry key: %w", err)
+	}
+
+	command, _, err := registry.CreateKey(registry.CURRENT_USER, classesKey+`\shell\open\command`, registry.SET_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed to create registry key: %w", err)
+	}
+	defer command.Close()
+	if err := command.SetStringValue("", WindowsCommand(exe)); err != nil {
+		return "", fmt.Errorf("failed to write registry key: %w", err)
+	}
+	return `HKCU\` + classesKey, nil
+}
+
+// Uninstall removes the registration and returns where it was
+func Uninstall() (string, error) {
+	// Keys can only be deleted once they have no subkeys
+	for _, path := range []string{`\shell\open\command`, `\shell\open`, `\shell`, ""} {
+		err := registry.DeleteKey(registry.CURRENT_USER, classesKey+path)
+		if err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return "", fmt.Errorf("failed to delete registry key %s: %w", classesKey+path, err)
+		}
+	}
+	return `HKCU\` + classesKey, nil
+}
+
+// Installed returns the handler command registered for ggo:// links, if any
+func Installed() (string, bool) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, classesKey+`\shell\open\command`, registry.QUERY_VALUE)
+	if err != nil {
+		return "", false
+	}
+	defer key.Close()
+	command, _, err := key.GetStringValue("")
+	if err != nil {
+		return "", false
+	}
+	return command, true
+}
@@ -150,6 +150,7 @@ func (b *AppleContainerBackend) Create(ctx context.Context, opts *CreateOptions)
 		GPUWorkerURL:   gpuWorkerURL,
 		HardwareVendor: opts.HardwareVendor,
 		Platform:       opts.Platform,
+		Lockfile:       opts.Lockfile,
 		MountUserHome:  false, // /Users is mounted directly into the container
 		SkipFileMounts: true,
 	}
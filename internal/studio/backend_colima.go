@@ -81,64 +81,22 @@ func (b *ColimaBackend) GetVMArch(ctx context.Context) string {
 	return NormalizeArch(status.Arch)
 }
 
-// pullImageWithProgress pulls a Docker image with progress output to stderr
-// If platform is specified, it will use --platform flag
-func (b *ColimaBackend) pullImageWithProgress(ctx context.Context, image, platform string) error {
-	// Check if image already exists locally with the correct platform
-	imageExistsLocally := false
-	checkCmd := exec.CommandContext(ctx, "docker", "image", "inspect", image)
-	checkCmd.Env = append(os.Environ(), fmt.Sprintf("DOCKER_HOST=%s", b.dockerHost))
-	if err := checkCmd.Run(); err == nil {
-		imageExistsLocally = true
-		if platform == "" {
-			klog.V(2).Infof("Image %s already exists locally, skipping pull", image)
-			return nil
-		}
-		// Image exists and platform is specified — check if it already matches
-		inspectCmd := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Os}}/{{.Architecture}}", image)
-		inspectCmd.Env = append(os.Environ(), fmt.Sprintf("DOCKER_HOST=%s", b.dockerHost))
-		if out, inspectErr := inspectCmd.Output(); inspectErr == nil {
-			localPlatform := strings.TrimSpace(string(out))
-			if localPlatform == platform {
-				klog.V(2).Infof("Image %s already exists locally with matching platform %s, skipping pull", image, platform)
-				return nil
-			}
-			klog.V(2).Infof("Image %s exists locally as %s but need %s, will pull", image, localPlatform, platform)
-		}
-	}
-
-	// Image doesn't exist or we need to ensure correct platform, pull it with progress
-	fmt.Fprintf(os.Stderr, "\n   Pulling image: %s\n", image)
-	if platform != "" {
-		fmt.Fprintf(os.Stderr, "   Platform: %s\n", platform)
-	}
-	fmt.Fprintf(os.Stderr, "   This may take a few minutes for large images...\n\n")
-
-	pullArgs := []string{"pull"}
-	if platform != "" {
-		pullArgs = append(pullArgs, "--platform", platform)
+// PullImage implements ImagePuller. It starts Colima if needed and
+// resolves an empty platform to the VM architecture, matching Create.
+func (b *ColimaBackend) PullImage(ctx context.Context, image string, opts PullOptions) error {
+	if err := b.EnsureRunning(ctx); err != nil {
+		return err
 	}
-	pullArgs = append(pullArgs, image)
-
-	pullCmd := exec.CommandContext(ctx, "docker", pullArgs...)
-	pullCmd.Env = append(os.Environ(), fmt.Sprintf("DOCKER_HOST=%s", b.dockerHost))
-	// Stream output to stderr so user can see progress
-	pullCmd.Stdout = os.Stderr
-	pullCmd.Stderr = os.Stderr
-
-	if err := pullCmd.Run(); err != nil {
-		if imageExistsLocally {
-			// Pull failed but image exists locally (e.g. local-only custom image
-			// with a different platform) — use the local image as-is
-			klog.V(2).Infof("Pull failed but image %s exists locally, using local image", image)
-			fmt.Fprintf(os.Stderr, "   Pull failed, using local image %s\n\n", image)
-			return nil
+	if opts.Platform == "" {
+		if vmArch := b.GetVMArch(ctx); vmArch != "" {
+			opts.Platform = "linux/" + vmArch
 		}
-		return fmt.Errorf("failed to pull image %s: %w", image, err)
 	}
-
-	fmt.Fprintf(os.Stderr, "\n   Image pulled successfully!\n\n")
-	return nil
+	return pullImage(ctx, func(ctx context.Context, args ...string) *exec.Cmd {
+		cmd := exec.CommandContext(ctx, "docker", args...)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("DOCKER_HOST=%s", b.dockerHost))
+		return cmd
+	}, image, opts)
 }
 
 func (b *ColimaBackend) Name() string {
@@ -501,11 +459,6 @@ func (b *ColimaBackend) Create(ctx context.Context, opts *CreateOptions) (*Envir
 	}
 	args = append(args, image)
 
-	// Pull image if not already cached locally
-	if err := b.pullImageWithProgress(ctx, image, platform); err != nil {
-		return nil, fmt.Errorf("failed to pull image: %w", err)
-	}
-
 	// Check if image has a default CMD or ENTRYPOINT
 	// Only use "sleep infinity" if image has no useful CMD and user provided no command
 	cmdToUse := opts.Command
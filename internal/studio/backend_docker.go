@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/NexusGPU/gpu-go/internal/platform"
 	"k8s.io/klog/v2"
 )
 
@@ -32,12 +33,16 @@ const (
 type DockerBackend struct {
 	dockerCmd  string // docker or podman
 	dockerHost string // Custom docker host (e.g., unix:///path/to/docker.sock)
+	mode       Mode   // ModeDocker, or ModePodman for the podman CLI
 }
 
-// NewDockerBackend creates a new Docker backend
+// NewDockerBackend creates a new Docker backend. On Linux hosts without a
+// Docker daemon it talks to rootless Podman's docker-compatible socket.
 func NewDockerBackend() *DockerBackend {
 	return &DockerBackend{
-		dockerCmd: "docker",
+		dockerCmd:  "docker",
+		dockerHost: platform.DefaultDockerHost(),
+		mode:       ModeDocker,
 	}
 }
 
@@ -46,22 +51,30 @@ func NewDockerBackendWithHost(dockerHost string) *DockerBackend {
 	return &DockerBackend{
 		dockerCmd:  "docker",
 		dockerHost: dockerHost,
+		mode:       ModeDocker,
 	}
 }
 
-// NewPodmanBackend creates a new Podman backend (uses Docker interface)
+// NewPodmanBackend creates a new Podman backend. The podman CLI takes the
+// docker CLI's commands and flags, so it shares the Docker backend.
 func NewPodmanBackend() *DockerBackend {
 	return &DockerBackend{
 		dockerCmd: "podman",
+		mode:      ModePodman,
 	}
 }
 
 func (b *DockerBackend) Name() string {
-	return "docker"
+	return string(b.mode)
 }
 
 func (b *DockerBackend) Mode() Mode {
-	return ModeDocker
+	return b.mode
+}
+
+// isPodman reports whether the backend drives the podman CLI
+func (b *DockerBackend) isPodman() bool {
+	return b.mode == ModePodman
 }
 
 func (b *DockerBackend) IsAvailable(ctx context.Context) bool {
@@ -79,6 +92,9 @@ func (b *DockerBackend) setDockerEnv(cmd *exec.Cmd) {
 
 // SocketPath implements BackendSocketPath. Returns the effective Docker socket path.
 func (b *DockerBackend) SocketPath(ctx context.Context) string {
+	if b.isPodman() {
+		return b.podmanSocketPath(ctx)
+	}
 	if b.dockerHost != "" {
 		return b.dockerHost
 	}
@@ -91,7 +107,11 @@ func (b *DockerBackend) SocketPath(ctx context.Context) string {
 // GetHostArch returns the architecture of the Docker host
 // Returns "amd64", "arm64", or empty string if detection fails
 func (b *DockerBackend) GetHostArch(ctx context.Context) string {
-	cmd := exec.CommandContext(ctx, b.dockerCmd, "info", "--format", "{{.Architecture}}")
+	format := "{{.Architecture}}"
+	if b.isPodman() {
+		format = "{{.Host.Arch}}"
+	}
+	cmd := exec.CommandContext(ctx, b.dockerCmd, "info", "--format", format)
 	b.setDockerEnv(cmd)
 	output, err := cmd.Output()
 	if err != nil {
@@ -102,64 +122,22 @@ func (b *DockerBackend) GetHostArch(ctx context.Context) string {
 	return NormalizeArch(arch)
 }
 
-// pullImageWithProgress pulls a Docker image with progress output to stderr
-// If platform is specified, it will use --platform flag
-func (b *DockerBackend) pullImageWithProgress(ctx context.Context, image, platform string) error {
-	// Check if image already exists locally with the correct platform
-	imageExistsLocally := false
-	checkCmd := exec.CommandContext(ctx, b.dockerCmd, "image", "inspect", image)
-	b.setDockerEnv(checkCmd)
-	if err := checkCmd.Run(); err == nil {
-		imageExistsLocally = true
-		if platform == "" {
-			klog.V(2).Infof("Image %s already exists locally, skipping pull", image)
-			return nil
-		}
-		// Image exists and platform is specified — check if it already matches
-		inspectCmd := exec.CommandContext(ctx, b.dockerCmd, "image", "inspect", "--format", "{{.Os}}/{{.Architecture}}", image)
-		b.setDockerEnv(inspectCmd)
-		if out, inspectErr := inspectCmd.Output(); inspectErr == nil {
-			localPlatform := strings.TrimSpace(string(out))
-			if localPlatform == platform {
-				klog.V(2).Infof("Image %s already exists locally with matching platform %s, skipping pull", image, platform)
-				return nil
-			}
-			klog.V(2).Infof("Image %s exists locally as %s but need %s, will pull", image, localPlatform, platform)
+// PullImage implements ImagePuller. An empty platform resolves to the
+// Docker host architecture, matching Create.
+func (b *DockerBackend) PullImage(ctx context.Context, image string, opts PullOptions) error {
+	if opts.Platform == "" {
+		if hostArch := b.GetHostArch(ctx); hostArch != "" {
+			opts.Platform = "linux/" + hostArch
 		}
 	}
+	return pullImage(ctx, b.command, image, opts)
+}
 
-	// Image doesn't exist or platform doesn't match, pull it with progress
-	fmt.Fprintf(os.Stderr, "\n   Pulling image: %s\n", image)
-	if platform != "" {
-		fmt.Fprintf(os.Stderr, "   Platform: %s\n", platform)
-	}
-	fmt.Fprintf(os.Stderr, "   This may take a few minutes for large images...\n\n")
-
-	pullArgs := []string{"pull"}
-	if platform != "" {
-		pullArgs = append(pullArgs, "--platform", platform)
-	}
-	pullArgs = append(pullArgs, image)
-
-	pullCmd := exec.CommandContext(ctx, b.dockerCmd, pullArgs...)
-	b.setDockerEnv(pullCmd)
-	// Stream output to stderr so user can see progress
-	pullCmd.Stdout = os.Stderr
-	pullCmd.Stderr = os.Stderr
-
-	if err := pullCmd.Run(); err != nil {
-		if imageExistsLocally {
-			// Pull failed but image exists locally (e.g. local-only custom image
-			// with a different platform) — use the local image as-is
-			klog.V(2).Infof("Pull failed but image %s exists locally, using local image", image)
-			fmt.Fprintf(os.Stderr, "   Pull failed, using local image %s\n\n", image)
-			return nil
-		}
-		return fmt.Errorf("failed to pull image %s: %w", image, err)
-	}
-
-	fmt.Fprintf(os.Stderr, "\n   Image pulled successfully!\n\n")
-	return nil
+// command builds a docker CLI invocation against the configured host
+func (b *DockerBackend) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, b.dockerCmd, args...)
+	b.setDockerEnv(cmd)
+	return cmd
 }
 
 func (b *DockerBackend) Create(ctx context.Context, opts *CreateOptions) (*Environment, error) {
@@ -207,15 +185,24 @@ func (b *DockerBackend) Create(ctx context.Context, opts *CreateOptions) (*Envir
 		args = append(args, "--platform", platform)
 	}
 
-	// Add local GPU passthrough if requested
+	// Add local GPU passthrough if requested. Podman passes GPUs through
+	// the CDI specs of the NVIDIA Container Toolkit.
 	if opts.UseLocalGPU {
-		args = append(args, "--gpus", "all")
+		if b.isPodman() {
+			args = append(args, "--device", podmanGPUDevice)
+		} else {
+			args = append(args, "--gpus", "all")
+		}
 	}
 
+	// Keep bind-mounted host directories writable under rootless,
+	// userns-remapped and SELinux-confined runtimes
+	args = append(args, b.runtimeSecurity(ctx).runArgs()...)
+
 	// Add labels
 	args = append(args, "--label", "ggo.managed=true")
 	args = append(args, "--label", fmt.Sprintf("ggo.name=%s", opts.Name))
-	args = append(args, "--label", "ggo.mode=docker")
+	args = append(args, "--label", "ggo.mode="+string(b.mode))
 
 	// Add port mappings and find SSH port
 	ports := resolvePortMappings(opts.Ports, opts.Image)
@@ -240,6 +227,7 @@ func (b *DockerBackend) Create(ctx context.Context, opts *CreateOptions) (*Envir
 		GPUWorkerURL:   gpuWorkerURL,
 		HardwareVendor: opts.HardwareVendor,
 		Platform:       opts.Platform,
+		Lockfile:       opts.Lockfile,
 		MountUserHome:  !opts.NoUserVolume,
 	}
 
@@ -305,11 +293,6 @@ func (b *DockerBackend) Create(ctx context.Context, opts *CreateOptions) (*Envir
 
 	klog.V(2).Infof("Running docker command: %s %v", b.dockerCmd, args)
 
-	// Pull image if not already cached locally
-	if err := b.pullImageWithProgress(ctx, image, platform); err != nil {
-		return nil, fmt.Errorf("failed to pull image: %w", err)
-	}
-
 	// Check if image has a default CMD or ENTRYPOINT
 	// Only use "sleep infinity" if image has no CMD and user provided no command
 	cmdToUse := opts.Command
@@ -358,7 +341,7 @@ func (b *DockerBackend) Create(ctx context.Context, opts *CreateOptions) (*Envir
 	env := &Environment{
 		ID:           containerID[:12],
 		Name:         strings.TrimPrefix(containerName, "ggo-"), // e.g., "andy-studio-0086"
-		Mode:         ModeDocker,
+		Mode:         b.mode,
 		Image:        image,
 		Status:       StatusRunning,
 		SSHHost:      "localhost",
@@ -406,18 +389,26 @@ func (b *DockerBackend) Remove(ctx context.Context, envID string) error {
 }
 
 func (b *DockerBackend) List(ctx context.Context) ([]*Environment, error) {
-	// Filter by both ggo.managed=true and ggo.mode=docker
-	// This ensures we only list containers created by the docker backend, not colima/wsl
+	// Filter by both ggo.managed=true and this backend's ggo.mode
+	// This ensures we only list containers created by this backend, not colima/wsl
+	format := "{{json .}}"
+	if b.isPodman() {
+		// podman's per-container template output differs from docker's
+		format = "json"
+	}
 	cmd := exec.CommandContext(ctx, b.dockerCmd, "ps", "-a",
 		"--filter", "label=ggo.managed=true",
-		"--filter", "label=ggo.mode=docker",
-		"--format", "{{json .}}")
+		"--filter", "label=ggo.mode="+string(b.mode),
+		"--format", format)
 	b.setDockerEnv(cmd)
 
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list containers: %w", err)
 	}
+	if b.isPodman() {
+		return parsePodmanContainers(output)
+	}
 
 	var envs []*Environment
 	for _, line := range strings.Split(string(output), "\n") {
@@ -464,7 +455,7 @@ func (b *DockerBackend) List(ctx context.Context) ([]*Environment, error) {
 		env := &Environment{
 			ID:      container.ID,
 			Name:    name,
-			Mode:    ModeDocker,
+			Mode:    b.mode,
 			Image:   container.Image,
 			Status:  status,
 			SSHHost: "localhost",
@@ -564,7 +555,7 @@ func (b *DockerBackend) Get(ctx context.Context, idOrName string) (*Environment,
 	env := &Environment{
 		ID:           c.ID[:12],
 		Name:         name,
-		Mode:         ModeDocker,
+		Mode:         b.mode,
 		Image:        c.Config.Image,
 		Status:       status,
 		SSHHost:      "localhost",
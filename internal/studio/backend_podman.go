@@ -0,0 +1,160 @@
+package studio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+)
+
+// podmanGPUDevice is the CDI device of all NVIDIA GPUs, generated by
+// `nvidia-ctk cdi generate`
+const podmanGPUDevice = "nvidia.com/gpu=all"
+
+// podmanCDISpecs are where the NVIDIA Container Toolkit writes its CDI spec
+var podmanCDISpecs = []string{"/etc/cdi/nvidia.yaml", "/var/run/cdi/nvidia.yaml"}
+
+// podmanInfo is the subset of `podman info --format json` used by the backend
+type podmanInfo struct {
+	Host struct {
+		Arch          string `json:"arch"`
+		CgroupVersion string `json:"cgroupVersion"`
+		MemTotal      int64  `json:"memTotal"`
+		Security      struct {
+			Rootless       bool `json:"rootless"`
+			SELinuxEnabled bool `json:"selinuxEnabled"`
+		} `json:"security"`
+		RemoteSocket struct {
+			Path string `json:"path"`
+		} `json:"remoteSocket"`
+	} `json:"host"`
+}
+
+// podmanInfo runs `podman info`
+func (b *DockerBackend) podmanInfo(ctx context.Context) (*podmanInfo, error) {
+	output, err := b.command(ctx, "info", "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("podman info failed: %w", err)
+	}
+	var info podmanInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse podman info: %w", err)
+	}
+	return &info, nil
+}
+
+// podmanSocketPath returns the API socket of the Podman service the CLI
+// talks to, which docker-compatible tools can use as DOCKER_HOST
+func (b *DockerBackend) podmanSocketPath(ctx context.Context) string {
+	if info, err := b.podmanInfo(ctx); err == nil && info.Host.RemoteSocket.Path != "" {
+		path := info.Host.RemoteSocket.Path
+		if !strings.Contains(path, "://") {
+			path = "unix://" + path
+		}
+		return path
+	}
+	if socket := platform.RootlessPodmanSocket(); socket != "" {
+		return socket
+	}
+	return "unix:///run/podman/podman.sock"
+}
+
+// podmanCapabilities probes the Podman host. GPUs are passed through with
+// CDI, so passthrough depends on the NVIDIA Container Toolkit's CDI spec.
+func (b *DockerBackend) podmanCapabilities(ctx context.Context) (*CapabilityReport, error) {
+	info, err := b.podmanInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &CapabilityReport{
+		NativeArch:     NormalizeArch(info.Host.Arch),
+		CgroupV2:       info.Host.CgroupVersion == "v2",
+		MaxMemoryBytes: info.Host.MemTotal,
+	}
+	if runtime.GOOS != OSLinux {
+		// A podman machine VM: its binfmt registrations and devices are not
+		// visible from here
+		return report, nil
+	}
+	for _, spec := range podmanCDISpecs {
+		if _, err := os.Stat(spec); err == nil {
+			report.GPUPassthrough = true
+		}
+	}
+	report.setForeignEmulation(localBinfmtEntries(), EmulationNone)
+	_, err = os.Stat("/dev/kvm")
+	report.NestedVirtualization = err == nil
+	return report, nil
+}
+
+// podmanContainer is one entry of `podman ps --format json`
+type podmanContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Labels map[string]string `json:"Labels"`
+	Ports  []struct {
+		HostPort      int    `json:"host_port"`
+		ContainerPort int    `json:"container_port"`
+		Protocol      string `json:"protocol"`
+	} `json:"Ports"`
+}
+
+// parsePodmanContainers converts `podman ps --format json` output into
+// environments
+func parsePodmanContainers(output []byte) ([]*Environment, error) {
+	var containers []podmanContainer
+	if err := json.Unmarshal(output, &containers); err != nil {
+		return nil, fmt.Errorf("failed to parse podman ps output: %w", err)
+	}
+
+	envs := make([]*Environment, 0, len(containers))
+	for _, c := range containers {
+		name := c.Labels["ggo.name"]
+		if name == "" && len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "ggo-")
+		}
+
+		var ports []string
+		sshPort := 0
+		for _, p := range c.Ports {
+			if p.HostPort == 0 {
+				continue
+			}
+			ports = append(ports, fmt.Sprintf("%d:%d", p.HostPort, p.ContainerPort))
+			if p.ContainerPort == 22 && (p.Protocol == "" || p.Protocol == DefaultProtocolTCP) {
+				sshPort = p.HostPort
+			}
+		}
+
+		status := StatusStopped
+		switch c.State {
+		case DockerStateRunning:
+			status = StatusRunning
+		case DockerStateCreated:
+			status = StatusPending
+		}
+
+		id := c.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		envs = append(envs, &Environment{
+			ID:      id,
+			Name:    name,
+			Mode:    ModePodman,
+			Image:   c.Image,
+			Status:  status,
+			SSHHost: "localhost",
+			SSHPort: sshPort,
+			SSHUser: "root",
+			Ports:   ports,
+		})
+	}
+	return envs, nil
+}
@@ -0,0 +1,55 @@
+package studio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePodmanContainers(t *testing.T) {
+	envs, err := parsePodmanContainers([]byte(`[
+		{
+			"Id": "3f2a9c1b7d4e5f60718293a4b5c6d7e8",
+			"Names": ["ggo-my-env-0042"],
+			"Image": "docker.io/tensorfusion/studio-torch:latest",
+			"State": "running",
+			"Labels": {"ggo.managed": "true", "ggo.mode": "podman", "ggo.name": "my-env"},
+			"Ports": [
+				{"host_ip": "", "container_port": 22, "host_port": 12345, "range": 1, "protocol": "tcp"},
+				{"host_ip": "", "container_port": 8888, "host_port": 8888, "range": 1, "protocol": "tcp"}
+			]
+		},
+		{"Id": "abc", "Names": ["ggo-old-0001"], "Image": "ubuntu", "State": "exited"}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, envs, 2)
+
+	assert.Equal(t, "3f2a9c1b7d4e", envs[0].ID)
+	assert.Equal(t, "my-env", envs[0].Name)
+	assert.Equal(t, ModePodman, envs[0].Mode)
+	assert.Equal(t, StatusRunning, envs[0].Status)
+	assert.Equal(t, 12345, envs[0].SSHPort)
+	assert.Equal(t, []string{"12345:22", "8888:8888"}, envs[0].Ports)
+
+	assert.Equal(t, "old-0001", envs[1].Name, "the container name without a ggo.name label")
+	assert.Equal(t, StatusStopped, envs[1].Status)
+	assert.Zero(t, envs[1].SSHPort)
+
+	_, err = parsePodmanContainers([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestRuntimeSecurity_RunArgs(t *testing.T) {
+	rootful := parseDockerSecurityOptions([]string{"name=apparmor", "name=seccomp,profile=builtin"})
+	assert.Empty(t, rootful.runArgs())
+
+	remapped := parseDockerSecurityOptions([]string{"name=seccomp,profile=builtin", "name=userns"})
+	assert.True(t, remapped.UsernsRemap)
+	assert.Equal(t, []string{"--userns=host"}, remapped.runArgs())
+
+	rootless := parseDockerSecurityOptions([]string{"name=seccomp,profile=builtin", "name=rootless", "name=selinux"})
+	assert.True(t, rootless.Rootless)
+	assert.Equal(t, []string{"--security-opt", "label=disable"}, rootless.runArgs(),
+		"container root is the invoking user, so only SELinux needs handling")
+}
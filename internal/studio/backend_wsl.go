@@ -209,6 +209,18 @@ func (b *WSLBackend) runInWSL(ctx context.Context, distro string, cmdArgs ...str
 	return cmd.CombinedOutput()
 }
 
+// PullImage implements ImagePuller using Docker inside the WSL distro
+func (b *WSLBackend) PullImage(ctx context.Context, image string, opts PullOptions) error {
+	distro, err := b.GetDistro(ctx)
+	if err != nil {
+		return err
+	}
+	return pullImage(ctx, func(ctx context.Context, args ...string) *exec.Cmd {
+		wslArgs := append([]string{"-d", distro, "--", "docker"}, args...)
+		return exec.CommandContext(ctx, "wsl", wslArgs...)
+	}, image, opts)
+}
+
 func (b *WSLBackend) Create(ctx context.Context, opts *CreateOptions) (*Environment, error) {
 	distro, err := b.GetDistro(ctx)
 	if err != nil {
@@ -0,0 +1,194 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultBatchConcurrency is how many environments a batch works on at once
+// when BatchOptions.Concurrency is not set
+const DefaultBatchConcurrency = 4
+
+// BatchOptions controls operations on many environments at once
+type BatchOptions struct {
+	// Concurrency bounds the environments worked on at once; 0 uses
+	// DefaultBatchConcurrency
+	Concurrency int
+	// OnProgress, if set, is called when an environment starts and when it
+	// finishes. Calls are serialized, so the callback needs no locking.
+	OnProgress func(BatchProgress)
+}
+
+// BatchProgress reports the state of one environment of a batch
+type BatchProgress struct {
+	// Name is the environment the event is about
+	Name string
+	// Done is false when work on the environment starts and true when it ends
+	Done bool
+	// Err is the failure of a finished environment
+	Err error
+	// Finished and Total count the finished and all environments of the batch
+	Finished int
+	Total    int
+}
+
+// BatchFailure is an environment a batch operation failed on
+type BatchFailure struct {
+	Name string
+	Err  error
+}
+
+// BatchError aggregates the environments a batch operation failed on
+type BatchError struct {
+	// Op is the operation, such as "remove"
+	Op       string
+	Failures []BatchFailure
+}
+
+func (e *BatchError) Error() string {
+	failed := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		failed = append(failed, fmt.Sprintf("%s (%v)", f.Name, f.Err))
+	}
+	return fmt.Sprintf("failed to %s %d environment(s): %s", e.Op, len(e.Failures), strings.Join(failed, "; "))
+}
+
+// Unwrap returns the errors of the failed environments
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// runBatch calls fn with the index of every name, at most opts.Concurrency
+// at once, and returns a *BatchError listing the failures in the order of
+// names. Names not started before ctx is done fail with the context's error.
+func runBatch(ctx context.Context, op string, names []string, opts *BatchOptions, fn func(ctx context.Context, i int) error) error {
+	concurrency := DefaultBatchConcurrency
+	var onProgress func(BatchProgress)
+	if opts != nil {
+		if opts.Concurrency > 0 {
+			concurrency = opts.Concurrency
+		}
+		onProgress = opts.OnProgress
+	}
+
+	var (
+		mu       sync.Mutex
+		finished int
+		wg       sync.WaitGroup
+	)
+	errs := make([]error, len(names))
+	report := func(p BatchProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if p.Done {
+			finished++
+		}
+		p.Finished, p.Total = finished, len(names)
+		if onProgress != nil {
+			onProgress(p)
+		}
+	}
+
+	sem := make(chan struct{}, concurrency)
+	for i, name := range names {
+		acquired := false
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+				acquired = true
+			case <-ctx.Done():
+			}
+		}
+		if !acquired {
+			errs[i] = ctx.Err()
+			report(BatchProgress{Name: name, Done: true, Err: errs[i]})
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			report(BatchProgress{Name: name})
+			errs[i] = fn(ctx, i)
+			report(BatchProgress{Name: name, Done: true, Err: errs[i]})
+		}()
+	}
+	wg.Wait()
+
+	batchErr := &BatchError{Op: op}
+	for i, err := range errs {
+		if err != nil {
+			batchErr.Failures = append(batchErr.Failures, BatchFailure{Name: names[i], Err: err})
+		}
+	}
+	if len(batchErr.Failures) > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+// CreateMany creates an environment for each of opts concurrently. It returns
+// the environments created, in the order of opts, and a *BatchError naming
+// the ones that failed.
+func (m *Manager) CreateMany(ctx context.Context, opts []*CreateOptions, batch *BatchOptions) ([]*Environment, error) {
+	names := make([]string, len(opts))
+	for i, o := range opts {
+		names[i] = o.Name
+	}
+	envs := make([]*Environment, len(opts))
+	err := runBatch(ctx, "create", names, batch, func(ctx context.Context, i int) error {
+		env, err := m.Create(ctx, opts[i])
+		envs[i] = env
+		return err
+	})
+	return compactEnvironments(envs), err
+}
+
+// RemoveMany removes the environments concurrently. It returns the names of
+// the removed environments and a *BatchError naming the ones that failed.
+func (m *Manager) RemoveMany(ctx context.Context, idsOrNames []string, batch *BatchOptions) ([]string, error) {
+	removed := make([]bool, len(idsOrNames))
+	err := runBatch(ctx, "remove", idsOrNames, batch, func(ctx context.Context, i int) error {
+		if err := m.Remove(ctx, idsOrNames[i]); err != nil {
+			return err
+		}
+		removed[i] = true
+		return nil
+	})
+	names := make([]string, 0, len(idsOrNames))
+	for i, name := range idsOrNames {
+		if removed[i] {
+			names = append(names, name)
+		}
+	}
+	return names, err
+}
+
+// RebuildMany rebuilds the environments concurrently, all with opts. It
+// returns the rebuilt environments, in the order of idsOrNames, and a
+// *BatchError naming the ones that failed.
+func (m *Manager) RebuildMany(ctx context.Context, idsOrNames []string, opts *RebuildOptions, batch *BatchOptions) ([]*Environment, error) {
+	envs := make([]*Environment, len(idsOrNames))
+	err := runBatch(ctx, "rebuild", idsOrNames, batch, func(ctx context.Context, i int) error {
+		env, err := m.Rebuild(ctx, idsOrNames[i], opts)
+		envs[i] = env
+		return err
+	})
+	return compactEnvironments(envs), err
+}
+
+func compactEnvironments(envs []*Environment) []*Environment {
+	result := make([]*Environment, 0, len(envs))
+	for _, env := range envs {
+		if env != nil {
+			result = append(result, env)
+		}
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+package studio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrentBackend is a MockBackend safe for concurrent use that records
+// how many removals run at once
+type concurrentBackend struct {
+	MockBackend
+	mu      sync.Mutex
+	active  atomic.Int32
+	maxSeen atomic.Int32
+	failOn  string
+}
+
+func newConcurrentBackend(names ...string) *concurrentBackend {
+	b := &concurrentBackend{MockBackend: MockBackend{mode: ModeDocker, available: true, envs: map[string]*Environment{}}}
+	for _, name := range names {
+		b.envs["id-"+name] = &Environment{ID: "id-" + name, Name: name, Mode: ModeDocker, Status: StatusRunning}
+	}
+	return b
+}
+
+func (b *concurrentBackend) Get(ctx context.Context, idOrName string) (*Environment, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.MockBackend.Get(ctx, idOrName)
+}
+
+func (b *concurrentBackend) List(ctx context.Context) ([]*Environment, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.MockBackend.List(ctx)
+}
+
+func (b *concurrentBackend) Remove(ctx context.Context, envID string) error {
+	n := b.active.Add(1)
+	defer b.active.Add(-1)
+	for {
+		seen := b.maxSeen.Load()
+		if n <= seen || b.maxSeen.CompareAndSwap(seen, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if env, ok := b.envs[envID]; ok && env.Name == b.failOn {
+		return errors.New("device busy")
+	}
+	return b.MockBackend.Remove(ctx, envID)
+}
+
+func batchManager(t *testing.T, backend Backend) *Manager {
+	t.Helper()
+	m := &Manager{
+		paths:    platform.DefaultPaths().WithConfigDir(t.TempDir()),
+		backends: make(map[Mode]Backend),
+	}
+	m.RegisterBackend(backend)
+	return m
+}
+
+func TestManager_RemoveMany(t *testing.T) {
+	names := make([]string, 12)
+	for i := range names {
+		names[i] = fmt.Sprintf("lab-%02d", i)
+	}
+	backend := newConcurrentBackend(names...)
+	backend.failOn = "lab-05"
+	m := batchManager(t, backend)
+	for _, env := range backend.envs {
+		require.NoError(t, m.saveEnvironment(cloneEnvironment(env)))
+	}
+
+	var events []BatchProgress
+	removed, err := m.RemoveMany(context.Background(), names, &BatchOptions{
+		Concurrency: 3,
+		OnProgress:  func(p BatchProgress) { events = append(events, p) },
+	})
+
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr.Failures, 1)
+	assert.Equal(t, "lab-05", batchErr.Failures[0].Name)
+	assert.EqualError(t, err, "failed to remove 1 environment(s): lab-05 (device busy)")
+	assert.Len(t, removed, 11)
+	assert.NotContains(t, removed, "lab-05")
+
+	assert.Equal(t, int32(3), backend.maxSeen.Load(), "removals run concurrently, bounded by Concurrency")
+	assert.Len(t, events, 2*len(names))
+	last := events[len(events)-1]
+	assert.True(t, last.Done)
+	assert.Equal(t, len(names), last.Finished)
+	assert.Equal(t, len(names), last.Total)
+
+	// Concurrent state updates must not lose each other's changes
+	state, err := m.loadState()
+	require.NoError(t, err)
+	require.Len(t, state, 1)
+	assert.Contains(t, state, "id-lab-05")
+}
+
+func TestManager_RemoveAllConcurrency(t *testing.T) {
+	backend := newConcurrentBackend("a", "b", "c", "d", "e")
+	m := batchManager(t, backend)
+
+	removed, err := m.RemoveAll(context.Background(), &BatchOptions{Concurrency: 1})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e"}, removed)
+	assert.Equal(t, int32(1), backend.maxSeen.Load())
+}
+
+func TestRunBatch_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls atomic.Int32
+	err := runBatch(ctx, "create", []string{"a", "b", "c"}, &BatchOptions{Concurrency: 1}, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		return nil
+	})
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Len(t, batchErr.Failures, 3)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, calls.Load())
+}
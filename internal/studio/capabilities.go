@@ -0,0 +1,317 @@
+package studio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EmulationType is how a backend runs images built for a foreign architecture
+type EmulationType string
+
+const (
+	// EmulationNone means foreign-architecture images cannot run
+	EmulationNone EmulationType = "none"
+	// EmulationQEMU is QEMU user-mode emulation registered via binfmt_misc
+	EmulationQEMU EmulationType = "qemu"
+	// EmulationRosetta is Apple's Rosetta translation for Linux VMs
+	EmulationRosetta EmulationType = "rosetta"
+)
+
+// CapabilityReport describes what a backend's runtime supports, so problems
+// can be reported before an environment is created rather than inside it
+type CapabilityReport struct {
+	Backend string `json:"backend"`
+	// NativeArch is the architecture containers run at natively (amd64, arm64)
+	NativeArch           string `json:"native_arch,omitempty"`
+	GPUPassthrough       bool   `json:"gpu_passthrough"`
+	NestedVirtualization bool   `json:"nested_virtualization"`
+	CgroupV2             bool   `json:"cgroup_v2"`
+	// MaxMemoryBytes is the memory available to containers; 0 if unknown
+	MaxMemoryBytes int64 `json:"max_memory_bytes,omitempty"`
+	// Emulation maps foreign platforms (e.g. linux/amd64) to how they run
+	Emulation map[string]EmulationType `json:"emulation,omitempty"`
+	// Error is set when probing failed and the report is incomplete
+	Error string `json:"error,omitempty"`
+}
+
+// CapabilityProber is implemented by backends that can report their runtime
+// capabilities
+type CapabilityProber interface {
+	Capabilities(ctx context.Context) (*CapabilityReport, error)
+}
+
+// ProbeCapabilities returns the backend's capability report. Backends that
+// cannot report, or fail to, get a report with only the backend name and Error set.
+func ProbeCapabilities(ctx context.Context, backend Backend) *CapabilityReport {
+	prober, ok := backend.(CapabilityProber)
+	if !ok {
+		return &CapabilityReport{Backend: backend.Name(), Error: "capability probing not supported"}
+	}
+	report, err := prober.Capabilities(ctx)
+	if report == nil {
+		report = &CapabilityReport{}
+	}
+	report.Backend = backend.Name()
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report
+}
+
+// EmulationFor returns how platform (e.g. linux/amd64) runs on the backend:
+// empty when it runs natively or emulation could not be probed
+func (r *CapabilityReport) EmulationFor(platform string) EmulationType {
+	arch := platformArch(platform)
+	if arch == "" || r.NativeArch == "" || arch == r.NativeArch || r.Emulation == nil {
+		return ""
+	}
+	if e, ok := r.Emulation["linux/"+arch]; ok {
+		return e
+	}
+	return EmulationNone
+}
+
+// PlatformWarnings returns the problems to expect when running an image for
+// platform on this backend
+func (r *CapabilityReport) PlatformWarnings(platform string) []string {
+	arch := platformArch(platform)
+	switch r.EmulationFor(platform) {
+	case EmulationQEMU:
+		return []string{fmt.Sprintf(
+			"%s images run under QEMU emulation on this %s %s backend: debuggers that rely on ptrace "+
+				"(debugpy, gdb, py-spy) do not work and Python/CUDA startup is several times slower. "+
+				"Use a linux/%s image if one is available.",
+			platform, r.NativeArch, r.Backend, r.NativeArch)}
+	case EmulationRosetta:
+		return []string{fmt.Sprintf(
+			"%s images run under Rosetta on this %s %s backend: expect slower startup and no "+
+				"AVX instructions; use a linux/%s image if one is available.",
+			platform, r.NativeArch, r.Backend, r.NativeArch)}
+	case EmulationNone:
+		return []string{fmt.Sprintf(
+			"%s backend runs %s natively and has no emulator registered for %s; "+
+				"the container will likely fail with 'exec format error'.",
+			r.Backend, r.NativeArch, arch)}
+	}
+	return nil
+}
+
+// platformArch extracts the normalized architecture from an os/arch[/variant] platform
+func platformArch(platform string) string {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return NormalizeArch(parts[1])
+}
+
+// foreignArch returns the other architecture studio images are built for
+func foreignArch(native string) string {
+	if native == ArchArm64 {
+		return ArchAmd64
+	}
+	return ArchArm64
+}
+
+// binfmtEntries maps foreign architectures to their QEMU binfmt_misc handler names
+var binfmtEntries = map[string]string{
+	ArchAmd64: "qemu-x86_64",
+	ArchArm64: "qemu-aarch64",
+}
+
+// dockerInfo is the subset of `docker info --format '{{json .}}'` used for probing
+type dockerInfo struct {
+	Architecture    string                     `json:"Architecture"`
+	OperatingSystem string                     `json:"OperatingSystem"`
+	CgroupVersion   string                     `json:"CgroupVersion"`
+	MemTotal        int64                      `json:"MemTotal"`
+	Runtimes        map[string]json.RawMessage `json:"Runtimes"`
+	// DiscoveredDevices lists CDI devices (Docker 28+), e.g. nvidia.com/gpu=all
+	DiscoveredDevices []struct {
+		Source string `json:"Source"`
+		ID     string `json:"ID"`
+	} `json:"DiscoveredDevices"`
+}
+
+// reportFromDockerInfo builds a capability report from `docker info` JSON
+func reportFromDockerInfo(data []byte) (*CapabilityReport, *dockerInfo, error) {
+	var info dockerInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse docker info: %w", err)
+	}
+	report := &CapabilityReport{
+		NativeArch:     NormalizeArch(info.Architecture),
+		CgroupV2:       info.CgroupVersion == "2",
+		MaxMemoryBytes: info.MemTotal,
+	}
+	if _, ok := info.Runtimes["nvidia"]; ok {
+		report.GPUPassthrough = true
+	}
+	for _, d := range info.DiscoveredDevices {
+		if d.Source == "cdi" && strings.Contains(d.ID, "gpu") {
+			report.GPUPassthrough = true
+		}
+	}
+	return report, &info, nil
+}
+
+// setForeignEmulation records how the foreign architecture runs, given the
+// binfmt_misc handler names registered in the container VM or host
+func (r *CapabilityReport) setForeignEmulation(binfmt []string, fallback EmulationType) {
+	if r.NativeArch == "" {
+		return
+	}
+	foreign := foreignArch(r.NativeArch)
+	emulation := fallback
+	for _, name := range binfmt {
+		switch {
+		case name == "rosetta" && foreign == ArchAmd64:
+			emulation = EmulationRosetta
+		case name == binfmtEntries[foreign] && emulation != EmulationRosetta:
+			emulation = EmulationQEMU
+		}
+	}
+	r.Emulation = map[string]EmulationType{"linux/" + foreign: emulation}
+}
+
+// localBinfmtEntries lists the binfmt_misc handlers registered on this host
+func localBinfmtEntries() []string {
+	entries, err := os.ReadDir("/proc/sys/fs/binfmt_misc")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// isLocalDockerHost reports whether host (a DOCKER_HOST value, empty for the
+// default) refers to a daemon on this machine
+func isLocalDockerHost(host string) bool {
+	return host == "" || strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "npipe://")
+}
+
+// Capabilities implements CapabilityProber
+func (b *DockerBackend) Capabilities(ctx context.Context) (*CapabilityReport, error) {
+	if b.isPodman() {
+		return b.podmanCapabilities(ctx)
+	}
+	output, err := b.command(ctx, "info", "--format", "{{json .}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker info failed: %w", err)
+	}
+	report, info, err := reportFromDockerInfo(output)
+	if err != nil {
+		return nil, err
+	}
+
+	host := b.dockerHost
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	desktop := strings.Contains(info.OperatingSystem, "Docker Desktop")
+	switch {
+	case desktop:
+		// Docker Desktop's VM ships QEMU binfmt handlers (Rosetta when enabled)
+		report.setForeignEmulation(nil, EmulationQEMU)
+	case runtime.GOOS == OSLinux && isLocalDockerHost(host):
+		report.setForeignEmulation(localBinfmtEntries(), EmulationNone)
+		_, err := os.Stat("/dev/kvm")
+		report.NestedVirtualization = err == nil
+	default:
+		// Remote daemon: its binfmt registrations are not visible from here
+	}
+	return report, nil
+}
+
+// colimaStatus is the subset of `colima status --json` used for probing
+type colimaStatus struct {
+	Arch   string `json:"arch"`
+	Driver string `json:"driver"`
+	Memory int64  `json:"memory"`
+}
+
+// Capabilities implements CapabilityProber. Colima runs Docker in a VM, so
+// GPUs cannot be passed through; foreign images use Rosetta on VZ VMs with
+// Rosetta enabled and QEMU otherwise.
+func (b *ColimaBackend) Capabilities(ctx context.Context) (*CapabilityReport, error) {
+	report, err := NewDockerBackendWithHost(b.dockerHost).Capabilities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.GPUPassthrough = false
+	report.NestedVirtualization = false
+
+	var binfmt []string
+	if output, err := exec.CommandContext(ctx, "colima", "ssh", "-p", b.profile, "--", "ls", "/proc/sys/fs/binfmt_misc").Output(); err == nil {
+		binfmt = strings.Fields(string(output))
+	}
+	report.setForeignEmulation(binfmt, EmulationQEMU)
+
+	if output, err := exec.CommandContext(ctx, "colima", "status", "-p", b.profile, "--json").Output(); err == nil {
+		var status colimaStatus
+		if json.Unmarshal(output, &status) == nil {
+			if status.Memory > 0 {
+				report.MaxMemoryBytes = status.Memory
+			}
+			if status.Arch != "" {
+				report.NativeArch = NormalizeArch(status.Arch)
+			}
+		}
+	}
+	return report, nil
+}
+
+// Capabilities implements CapabilityProber. Each Apple container is a
+// lightweight arm64 VM with a cgroup v2 kernel; amd64 images run under Rosetta
+// and GPUs are not exposed.
+func (b *AppleContainerBackend) Capabilities(ctx context.Context) (*CapabilityReport, error) {
+	return &CapabilityReport{
+		NativeArch: ArchArm64,
+		CgroupV2:   true,
+		Emulation:  map[string]EmulationType{"linux/" + ArchAmd64: EmulationRosetta},
+	}, nil
+}
+
+// Capabilities implements CapabilityProber. GPUs are available through the
+// WSL2 paravirtualized /dev/dxg device when the distro's Docker is set up for them.
+func (b *WSLBackend) Capabilities(ctx context.Context) (*CapabilityReport, error) {
+	distro, err := b.GetDistro(ctx)
+	if err != nil {
+		return nil, err
+	}
+	output, err := b.runInWSL(ctx, distro, "docker", "info", "--format", "{{json .}}")
+	if err != nil {
+		return nil, fmt.Errorf("docker info in WSL distro %s failed: %w", distro, err)
+	}
+	report, _, err := reportFromDockerInfo(output)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.runInWSL(ctx, distro, "test", "-e", "/dev/dxg"); err != nil {
+		report.GPUPassthrough = false
+	}
+	if _, err := b.runInWSL(ctx, distro, "test", "-e", "/dev/kvm"); err == nil {
+		report.NestedVirtualization = true
+	}
+	var binfmt []string
+	if out, err := b.runInWSL(ctx, distro, "ls", "/proc/sys/fs/binfmt_misc"); err == nil {
+		binfmt = strings.Fields(string(out))
+	}
+	report.setForeignEmulation(binfmt, EmulationNone)
+	return report, nil
+}
+
+var (
+	_ CapabilityProber = (*DockerBackend)(nil)
+	_ CapabilityProber = (*ColimaBackend)(nil)
+	_ CapabilityProber = (*AppleContainerBackend)(nil)
+	_ CapabilityProber = (*WSLBackend)(nil)
+)
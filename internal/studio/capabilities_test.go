@@ -0,0 +1,67 @@
+package studio
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportFromDockerInfo(t *testing.T) {
+	report, info, err := reportFromDockerInfo([]byte(`{
+		"Architecture": "aarch64",
+		"OperatingSystem": "Docker Desktop",
+		"CgroupVersion": "2",
+		"MemTotal": 8323002368,
+		"Runtimes": {"runc": {"path": "runc"}, "nvidia": {"path": "nvidia-container-runtime"}}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Docker Desktop", info.OperatingSystem)
+	assert.Equal(t, ArchArm64, report.NativeArch)
+	assert.True(t, report.CgroupV2)
+	assert.True(t, report.GPUPassthrough)
+	assert.Equal(t, int64(8323002368), report.MaxMemoryBytes)
+
+	report, _, err = reportFromDockerInfo([]byte(`{
+		"Architecture": "x86_64",
+		"CgroupVersion": "1",
+		"DiscoveredDevices": [{"Source": "cdi", "ID": "nvidia.com/gpu=all"}]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, ArchAmd64, report.NativeArch)
+	assert.False(t, report.CgroupV2)
+	assert.True(t, report.GPUPassthrough)
+
+	_, _, err = reportFromDockerInfo([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestCapabilityReport_PlatformWarnings(t *testing.T) {
+	report := &CapabilityReport{Backend: "colima", NativeArch: ArchArm64}
+
+	report.setForeignEmulation([]string{"qemu-x86_64", "status"}, EmulationNone)
+	assert.Equal(t, EmulationQEMU, report.EmulationFor("linux/amd64"))
+	warnings := report.PlatformWarnings("linux/amd64")
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "debugpy")
+	assert.Empty(t, report.PlatformWarnings("linux/arm64"), "native platform")
+
+	report.setForeignEmulation([]string{"qemu-x86_64", "rosetta"}, EmulationNone)
+	assert.Equal(t, EmulationRosetta, report.EmulationFor("linux/x86_64"))
+
+	report.setForeignEmulation(nil, EmulationNone)
+	warnings = report.PlatformWarnings("linux/amd64")
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "exec format error")
+
+	unprobed := &CapabilityReport{Backend: "docker", NativeArch: ArchAmd64}
+	assert.Empty(t, unprobed.PlatformWarnings("linux/arm64"), "unknown emulation is not reported")
+}
+
+func TestProbeCapabilities(t *testing.T) {
+	report := ProbeCapabilities(context.Background(), NewAppleContainerBackend())
+	assert.Equal(t, "apple-container", report.Backend)
+	assert.Empty(t, report.Error)
+	assert.Equal(t, EmulationRosetta, report.EmulationFor("linux/amd64"))
+}
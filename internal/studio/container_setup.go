@@ -19,12 +19,14 @@ type ContainerSetupConfig struct {
 	StudioName string
 	// GPUWorkerURL is the connection URL to the GPU worker
 	GPUWorkerURL string
-	// HardwareVendor is the GPU vendor (nvidia, amd, hygon)
+	// HardwareVendor is the GPU vendor (nvidia, amd, hygon, mthreads, cambricon)
 	HardwareVendor string
 	// Platform is the container platform (e.g., "linux/amd64", "linux/arm64")
 	// Used to determine which arch-specific libs to download and mount.
 	// If empty, defaults to linux/amd64.
 	Platform string
+	// Lockfile is a ggo.lock fixing the GPU client library versions (optional)
+	Lockfile string
 	// MountUserHome indicates whether to mount the user's home directory
 	MountUserHome bool
 	// SkipSSHMounts disables mounting SSH key files into the container
@@ -73,7 +75,7 @@ func SetupContainerGPUEnv(ctx context.Context, config *ContainerSetupConfig) (*C
 	// Step 1: Download GPU client libraries for Linux (container target)
 	// Libraries are downloaded for Linux with the target CPU architecture
 	if config.GPUWorkerURL != "" {
-		if err := ensureGPUClientLibraries(ctx, vendor, targetArch); err != nil {
+		if err := ensureGPUClientLibraries(ctx, vendor, targetArch, config.Lockfile); err != nil {
 			klog.Warningf("Failed to download GPU client libraries: %v (continuing anyway)", err)
 		} else {
 			result.LibrariesDownloaded = true
@@ -112,9 +114,9 @@ func SetupContainerGPUEnv(ctx context.Context, config *ContainerSetupConfig) (*C
 		// Copy volume mounts from GPU setup
 		result.VolumeMounts = append(result.VolumeMounts, envResult.VolumeMounts...)
 
-		// Add CUDA_VISIBLE_DEVICES for NVIDIA
-		if vendor == VendorNvidia {
-			result.EnvVars["CUDA_VISIBLE_DEVICES"] = "0"
+		// Select the remote GPU, e.g. CUDA_VISIBLE_DEVICES for NVIDIA
+		if name := VisibleDevicesEnv(vendor); name != "" {
+			result.EnvVars[name] = "0"
 		}
 
 		// Set TF_MAX_CACHE_REQUEST_COUNT=0 on macOS
@@ -152,14 +154,15 @@ func SetupContainerGPUEnv(ctx context.Context, config *ContainerSetupConfig) (*C
 		}
 	}
 
-	// Step 5: Download and mount GPU binary (like nvidia-smi) to /usr/local/bin/
+	// Step 5: Download and mount the vendor's GPU tools (like nvidia-smi) to /usr/local/bin/
 	if !config.SkipFileMounts && config.GPUWorkerURL != "" && config.HardwareVendor != "" {
-		gpuBinMount, err := ensureAndMountGPUBinary(ctx, paths, config.HardwareVendor, targetArch)
+		toolMounts, err := ensureAndMountGPUBinary(ctx, paths, config.HardwareVendor, targetArch)
 		if err != nil {
 			klog.Warningf("Failed to setup GPU binary mount: %v (continuing without it)", err)
-		} else if gpuBinMount != nil {
-			result.VolumeMounts = append(result.VolumeMounts, *gpuBinMount)
-			klog.Infof("GPU binary mount configured: %s -> %s", gpuBinMount.HostPath, gpuBinMount.ContainerPath)
+		}
+		for _, m := range toolMounts {
+			result.VolumeMounts = append(result.VolumeMounts, m)
+			klog.Infof("GPU binary mount configured: %s -> %s", m.HostPath, m.ContainerPath)
 		}
 	}
 
@@ -170,52 +173,51 @@ func SetupContainerGPUEnv(ctx context.Context, config *ContainerSetupConfig) (*C
 	return result, nil
 }
 
-// ensureAndMountGPUBinary downloads GPU binary (like nvidia-smi) and returns a volume mount
-// The binary is mounted to /usr/local/bin/ in the container
-func ensureAndMountGPUBinary(ctx context.Context, paths *platform.Paths, vendorSlug, targetArch string) (*VolumeMount, error) {
+// ensureAndMountGPUBinary downloads the vendor's GPU tool bundle (like
+// nvidia-smi, or rocm-smi and rocminfo) and returns a volume mount per tool.
+// The tools are mounted to /usr/local/bin/ in the container
+func ensureAndMountGPUBinary(ctx context.Context, paths *platform.Paths, vendorSlug, targetArch string) ([]VolumeMount, error) {
 	// Studios run in Linux containers, so download Linux binary with target CPU arch
-	binPath, err := deps.EnsureGPUBinaryForPlatform(ctx, paths, vendorSlug, "linux", targetArch)
+	bundle, err := deps.EnsureGPUBinaryForPlatform(ctx, paths, vendorSlug, "linux", targetArch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to ensure GPU binary: %w", err)
 	}
-	if binPath == "" {
+	if bundle == nil {
 		// No binary available for this vendor/platform combination
 		return nil, nil
 	}
 
-	// Get the binary name (e.g., "nvidia-smi")
-	binaryName := deps.GetGPUBinaryName(vendorSlug)
-	if binaryName == "" {
-		return nil, nil
+	mounts := make([]VolumeMount, 0, len(bundle.Tools))
+	for _, tool := range bundle.Tools {
+		mounts = append(mounts, VolumeMount{
+			HostPath:      bundle.ToolPath(tool),
+			ContainerPath: "/usr/local/bin/" + tool,
+			ReadOnly:      true,
+		})
 	}
-
-	// Mount the binary to /usr/local/bin/
-	containerPath := fmt.Sprintf("/usr/local/bin/%s", binaryName)
-
-	return &VolumeMount{
-		HostPath:      binPath,
-		ContainerPath: containerPath,
-		ReadOnly:      true,
-	}, nil
+	return mounts, nil
 }
 
 // ensureGPUClientLibraries downloads GPU client libraries for Linux containers
-// Libraries are downloaded for Linux platform with the specified target CPU architecture
-func ensureGPUClientLibraries(ctx context.Context, vendor GPUVendor, targetArch string) error {
-	depsMgr := deps.NewManager()
+// Libraries are downloaded for Linux platform with the specified target CPU architecture,
+// at the versions recorded in lockfile when one is given
+func ensureGPUClientLibraries(ctx context.Context, vendor GPUVendor, targetArch, lockfile string) error {
+	var lock *deps.Lockfile
+	if lockfile != "" {
+		var err error
+		if lock, err = deps.LoadLockfile(lockfile); err != nil {
+			return err
+		}
+	}
+	depsMgr := deps.NewManager(deps.WithLockfile(lock))
 
 	// Target library types needed for GPU client functionality
 	targetTypes := []string{deps.LibraryTypeRemoteGPUClient, deps.LibraryTypeVGPULibrary}
 
 	// Determine vendor slug for filtering
 	vendorSlug := ""
-	switch vendor {
-	case VendorNvidia:
-		vendorSlug = "nvidia"
-	case VendorAMD:
-		vendorSlug = "amd"
-	case VendorHygon:
-		vendorSlug = "hygon"
+	if vendor != VendorUnknown {
+		vendorSlug = string(vendor)
 	}
 
 	klog.Infof("Downloading GPU client libraries for %s (linux/%s)...", vendorSlug, targetArch)
@@ -0,0 +1,272 @@
+package studio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// DefinitionKind identifies studio environment definition files
+	DefinitionKind = "StudioEnvironment"
+	// DefinitionVersion is the version of the definition format written by
+	// this ggo; definitions of newer versions are refused
+	DefinitionVersion = 1
+)
+
+// ShareRef is the share a studio's remote GPU is used through
+type ShareRef struct {
+	Code string `json:"code" yaml:"code"`
+	// Server resolves the share; empty for the default server
+	Server string `json:"server,omitempty" yaml:"server,omitempty"`
+}
+
+// TemplateRef names the studio template an environment was created from
+type TemplateRef struct {
+	Name  string `json:"name" yaml:"name"`
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+	// Notes are shown once a studio is created from the definition
+	Notes string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// Definition describes a studio environment apart from the machine it was
+// created on, to create it again elsewhere. It holds the options the
+// environment was created with, not its container's filesystem. The SSH key,
+// the ggo.lock and the resolved GPU connection are left out: they are
+// machine specific or resolved again from the share.
+type Definition struct {
+	Kind    string `json:"kind" yaml:"kind"`
+	Version int    `json:"version" yaml:"version"`
+	Name    string `json:"name" yaml:"name"`
+
+	Share    *ShareRef    `json:"share,omitempty" yaml:"share,omitempty"`
+	Template *TemplateRef `json:"template,omitempty" yaml:"template,omitempty"`
+
+	Mode       Mode       `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Image      string     `json:"image" yaml:"image"`
+	Platform   string     `json:"platform,omitempty" yaml:"platform,omitempty"`
+	PullPolicy PullPolicy `json:"pull_policy,omitempty" yaml:"pull_policy,omitempty"`
+	Endpoint   string     `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	CPUs       float64    `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+	Memory     string     `json:"memory,omitempty" yaml:"memory,omitempty"`
+	// Ports (host:container) and Volumes (host-path-or-volume:container[:ro])
+	// are written as with -p and -v
+	Ports   []string `json:"ports,omitempty" yaml:"ports,omitempty"`
+	Volumes []string `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	// Env holds templated values as templates, resolved again on import
+	Env          map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Command      []string          `json:"command,omitempty" yaml:"command,omitempty"`
+	WorkDir      string            `json:"work_dir,omitempty" yaml:"work_dir,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	NoUserVolume bool              `json:"no_user_volume,omitempty" yaml:"no_user_volume,omitempty"`
+}
+
+// NewDefinition describes env from the options it was created with
+func NewDefinition(env *Environment) (*Definition, error) {
+	opts := env.CreateOptions
+	if opts == nil {
+		return nil, fmt.Errorf("environment %s was created by an older ggo that did not record its options; remove and create it again", env.Name)
+	}
+	def := &Definition{
+		Kind:         DefinitionKind,
+		Version:      DefinitionVersion,
+		Name:         env.Name,
+		Template:     opts.Template,
+		Mode:         opts.Mode,
+		Image:        opts.Image,
+		Platform:     opts.Platform,
+		PullPolicy:   opts.PullPolicy,
+		Endpoint:     opts.Endpoint,
+		CPUs:         opts.Resources.CPUs,
+		Memory:       opts.Resources.Memory,
+		Command:      opts.Command,
+		WorkDir:      opts.WorkDir,
+		Labels:       opts.Labels,
+		NoUserVolume: opts.NoUserVolume,
+	}
+	if def.Mode == ModeAuto {
+		def.Mode = ""
+	}
+
+	// Environments created before the share was recorded carry its code in
+	// the connection URL
+	def.Share = opts.Share
+	if def.Share == nil && opts.GPUWorkerURL != "" && opts.Endpoint == "" {
+		if code := ShareCodeFromWorkerURL(opts.GPUWorkerURL); code != "" {
+			def.Share = &ShareRef{Code: code}
+		} else {
+			def.Endpoint = opts.GPUWorkerURL
+		}
+	}
+
+	for _, p := range opts.Ports {
+		def.Ports = append(def.Ports, strconv.Itoa(p.HostPort)+":"+strconv.Itoa(p.ContainerPort))
+	}
+	for _, v := range opts.Volumes {
+		mount := v.HostPath + ":" + v.ContainerPath
+		if v.ReadOnly {
+			mount += MountOptionReadOnly
+		}
+		def.Volumes = append(def.Volumes, mount)
+	}
+	if len(opts.Envs) > 0 {
+		def.Env = make(map[string]string, len(opts.Envs))
+		for k, v := range opts.Envs {
+			if tmpl, ok := opts.EnvTemplates[k]; ok {
+				v = tmpl
+			}
+			def.Env[k] = v
+		}
+	}
+	return def, nil
+}
+
+// Definition describes an environment from local state, so it can be
+// exported while its backend is not running
+func (m *Manager) Definition(idOrName string) (*Definition, error) {
+	env, err := m.getFromState(idOrName)
+	if err != nil {
+		return nil, err
+	}
+	return NewDefinition(env)
+}
+
+// MarshalDefinition encodes def as YAML
+func MarshalDefinition(def *Definition) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("# GPU Go studio environment; create it with 'ggo studio import <file>'\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(def); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseDefinition decodes a definition written as YAML or JSON. Unknown
+// fields are refused, so a mistyped option is not silently dropped.
+func ParseDefinition(data []byte) (*Definition, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var def Definition
+	if err := dec.Decode(&def); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("the definition is empty")
+		}
+		return nil, fmt.Errorf("invalid definition: %w", err)
+	}
+	switch {
+	case def.Kind != DefinitionKind:
+		return nil, fmt.Errorf("not a studio environment definition: kind is %q, expected %q", def.Kind, DefinitionKind)
+	case def.Version > DefinitionVersion:
+		return nil, fmt.Errorf("the definition has version %d, this ggo reads up to %d; upgrade ggo", def.Version, DefinitionVersion)
+	case def.Version < 1:
+		return nil, fmt.Errorf("the definition has no valid version")
+	case def.Image == "":
+		return nil, fmt.Errorf("the definition has no image")
+	case def.Share != nil && def.Share.Code == "":
+		return nil, fmt.Errorf("the definition's share has no code")
+	}
+	return &def, nil
+}
+
+// ErrImportNotConfirmed is returned when the access a definition asks for
+// on the importing machine was not confirmed
+var ErrImportNotConfirmed = errors.New("import not confirmed")
+
+// DefinitionAccess is what a studio created from a definition reaches on the
+// importing machine beyond its own container and volumes. Definitions are
+// shared, so it is confirmed before they are imported.
+type DefinitionAccess struct {
+	// HostPaths are the host directories and files bind mounted into the
+	// studio
+	HostPaths []string `json:"host_paths,omitempty"`
+	// Secrets are the secrets of the local secret store its env templates
+	// read
+	Secrets []string `json:"secrets,omitempty"`
+	// Server is the server the share is resolved with, when not the default
+	Server string `json:"server,omitempty"`
+}
+
+// Empty reports whether the definition reaches nothing on the machine
+func (a *DefinitionAccess) Empty() bool {
+	return len(a.HostPaths) == 0 && len(a.Secrets) == 0 && a.Server == ""
+}
+
+// Access lists the host paths, secrets and server other than defaultServer
+// a studio created from def uses
+func (d *Definition) Access(defaultServer string) *DefinitionAccess {
+	access := &DefinitionAccess{}
+	for _, v := range d.Volumes {
+		source, _, _ := strings.Cut(v, ":")
+		if source != "" && !IsNamedVolume(source) && !slices.Contains(access.HostPaths, source) {
+			access.HostPaths = append(access.HostPaths, source)
+		}
+	}
+	for _, v := range d.Env {
+		for _, name := range envTemplateSecrets(v) {
+			if !slices.Contains(access.Secrets, name) {
+				access.Secrets = append(access.Secrets, name)
+			}
+		}
+	}
+	slices.Sort(access.Secrets)
+	if d.Share != nil && d.Share.Server != "" &&
+		strings.TrimSuffix(d.Share.Server, "/") != strings.TrimSuffix(defaultServer, "/") {
+		access.Server = d.Share.Server
+	}
+	return access
+}
+
+// envTemplateSecrets returns the secrets an env template reads, by running
+// it with a secret function that records their names
+func envTemplateSecrets(value string) []string {
+	if !IsEnvTemplate(value) {
+		return nil
+	}
+	var names []string
+	funcs := template.FuncMap{
+		"secret": func(name string) string {
+			names = append(names, name)
+			return ""
+		},
+		"host_ip":    func() string { return "" },
+		"share_code": func() string { return "" },
+	}
+	tmpl, err := template.New("env").Funcs(funcs).Parse(value)
+	if err != nil {
+		// Refused when the studio is created
+		return nil
+	}
+	_ = tmpl.Execute(io.Discard, nil)
+	return names
+}
+
+// ConfirmImport has confirm approve access before a definition is imported.
+// With a nil confirm, when no one can be asked, any access is refused.
+func ConfirmImport(access *DefinitionAccess, confirm func(*DefinitionAccess) (bool, error)) error {
+	if access.Empty() {
+		return nil
+	}
+	if confirm == nil {
+		return ErrImportNotConfirmed
+	}
+	ok, err := confirm(access)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrImportNotConfirmed
+	}
+	return nil
+}
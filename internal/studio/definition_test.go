@@ -0,0 +1,141 @@
+package studio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefinition(t *testing.T) {
+	env := &Environment{
+		Name: "my-env",
+		CreateOptions: &CreateOptions{
+			Name:         "my-env",
+			Mode:         ModeAuto,
+			Image:        "tensorfusion/studio-torch:latest",
+			GPUWorkerURL: "native+10.0.0.1+9001+abc123",
+			SSHPublicKey: "ssh-ed25519 AAAA studio",
+			Ports:        []PortMapping{{HostPort: 8888, ContainerPort: 8888}},
+			Volumes: []VolumeMount{
+				{HostPath: "/home/me/data", ContainerPath: "/data"},
+				{HostPath: "models", ContainerPath: "/models", ReadOnly: true},
+			},
+			Envs:         map[string]string{"HF_TOKEN": "hf_secret", "DEBUG": "1"},
+			EnvTemplates: map[string]string{"HF_TOKEN": `{{secret "hf"}}`},
+			Resources:    ResourceSpec{CPUs: 4, Memory: "8Gi"},
+			Platform:     "linux/amd64",
+			PullPolicy:   PullPolicyMissing,
+			Lockfile:     "/home/me/project/ggo.lock",
+			Share:        &ShareRef{Code: "abc123", Server: "https://staging.example.com"},
+			Template:     &TemplateRef{Name: "sd-webui", Notes: "Open http://localhost:7860"},
+		},
+	}
+
+	def, err := NewDefinition(env)
+	require.NoError(t, err)
+	assert.Equal(t, &Definition{
+		Kind:       DefinitionKind,
+		Version:    DefinitionVersion,
+		Name:       "my-env",
+		Share:      &ShareRef{Code: "abc123", Server: "https://staging.example.com"},
+		Template:   &TemplateRef{Name: "sd-webui", Notes: "Open http://localhost:7860"},
+		Image:      "tensorfusion/studio-torch:latest",
+		Platform:   "linux/amd64",
+		PullPolicy: PullPolicyMissing,
+		CPUs:       4,
+		Memory:     "8Gi",
+		Ports:      []string{"8888:8888"},
+		Volumes:    []string{"/home/me/data:/data", "models:/models:ro"},
+		Env:        map[string]string{"HF_TOKEN": `{{secret "hf"}}`, "DEBUG": "1"},
+	}, def, "secrets stay templates; the SSH key, lockfile and connection are left out")
+
+	data, err := MarshalDefinition(def)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hf_secret")
+	parsed, err := ParseDefinition(data)
+	require.NoError(t, err)
+	assert.Equal(t, def, parsed)
+}
+
+func TestNewDefinition_OlderEnvironments(t *testing.T) {
+	// The share code is taken from the connection URL
+	def, err := NewDefinition(&Environment{Name: "a", CreateOptions: &CreateOptions{
+		Image: "img", GPUWorkerURL: "native+10.0.0.1+9001+abc123",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, &ShareRef{Code: "abc123"}, def.Share)
+
+	def, err = NewDefinition(&Environment{Name: "b", CreateOptions: &CreateOptions{
+		Image: "img", GPUWorkerURL: "native+10.0.0.1+9001",
+	}})
+	require.NoError(t, err)
+	assert.Nil(t, def.Share)
+	assert.Equal(t, "native+10.0.0.1+9001", def.Endpoint)
+
+	_, err = NewDefinition(&Environment{Name: "c"})
+	assert.ErrorContains(t, err, "older ggo")
+}
+
+func TestParseDefinition(t *testing.T) {
+	def, err := ParseDefinition([]byte(`{"kind": "StudioEnvironment", "version": 1, "name": "a", "image": "img", "env": {"A": "1"}}`))
+	require.NoError(t, err, "JSON is read too")
+	assert.Equal(t, map[string]string{"A": "1"}, def.Env)
+
+	for input, want := range map[string]string{
+		"":                                    "empty",
+		"kind: Pod\nversion: 1\nimage: img":   "not a studio environment definition",
+		"kind: StudioEnvironment\nimage: img": "no valid version",
+		"kind: StudioEnvironment\nversion: 2\nimage: img":              "upgrade ggo",
+		"kind: StudioEnvironment\nversion: 1\nimage: img\ncpu: 4":      "field cpu not found",
+		"kind: StudioEnvironment\nversion: 1":                          "no image",
+		"kind: StudioEnvironment\nversion: 1\nimage: img\nshare: {}":   "share has no code",
+		"kind: StudioEnvironment\nversion: 1\nimage: img\nports: 8888": "invalid definition",
+	} {
+		_, err := ParseDefinition([]byte(input))
+		require.Error(t, err, input)
+		assert.True(t, strings.Contains(err.Error(), want), "%q: %v", input, err)
+	}
+}
+
+func TestDefinitionAccess(t *testing.T) {
+	const defaultServer = "https://tensor-fusion.ai"
+	def, err := ParseDefinition([]byte(`kind: StudioEnvironment
+version: 1
+name: a
+image: img
+share: {code: abc123, server: "https://evil.example.com"}
+volumes: ["/:/host", "~/.ssh:/x:ro", "models:/models", "/:/again"]
+env:
+  HF_TOKEN: '{{secret "hf"}}'
+  AWS: '{{ secret "aws" | printf "%s" }}:{{secret "hf"}}'
+  HOST: '{{host_ip}}'
+  PLAIN: value
+`))
+	require.NoError(t, err)
+
+	access := def.Access(defaultServer)
+	assert.Equal(t, &DefinitionAccess{
+		HostPaths: []string{"/", "~/.ssh"},
+		Secrets:   []string{"aws", "hf"},
+		Server:    "https://evil.example.com",
+	}, access, "named volumes are not host paths")
+
+	// Refused without consent
+	assert.ErrorIs(t, ConfirmImport(access, nil), ErrImportNotConfirmed)
+	var asked *DefinitionAccess
+	err = ConfirmImport(access, func(a *DefinitionAccess) (bool, error) {
+		asked = a
+		return false, nil
+	})
+	assert.ErrorIs(t, err, ErrImportNotConfirmed)
+	assert.Equal(t, access, asked)
+	assert.NoError(t, ConfirmImport(access, func(*DefinitionAccess) (bool, error) { return true, nil }))
+
+	// A definition that stays in its container needs no consent
+	safe := &Definition{Image: "img", Volumes: []string{"data:/data"}, Env: map[string]string{"A": "1"},
+		Share: &ShareRef{Code: "abc123", Server: defaultServer + "/"}}
+	assert.True(t, safe.Access(defaultServer).Empty())
+	assert.NoError(t, ConfirmImport(safe.Access(defaultServer), nil))
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/NexusGPU/gpu-go/internal/deps"
 	"github.com/NexusGPU/gpu-go/internal/platform"
 )
 
@@ -15,37 +16,66 @@ import (
 type GPUVendor string
 
 const (
-	VendorNvidia  GPUVendor = "nvidia"
-	VendorAMD     GPUVendor = "amd"
-	VendorHygon   GPUVendor = "hygon"
-	VendorUnknown GPUVendor = "unknown"
+	VendorNvidia    GPUVendor = "nvidia"
+	VendorAMD       GPUVendor = "amd"
+	VendorHygon     GPUVendor = "hygon"
+	VendorMThreads  GPUVendor = "mthreads"
+	VendorCambricon GPUVendor = "cambricon"
+	VendorUnknown   GPUVendor = "unknown"
 )
 
 // ParseVendor parses a vendor string to GPUVendor
 func ParseVendor(vendor string) GPUVendor {
-	switch strings.ToLower(vendor) {
-	case "nvidia":
+	switch deps.NormalizeVendorSlug(vendor) {
+	case deps.VendorNVIDIA:
 		return VendorNvidia
-	case "amd":
+	case deps.VendorAMD:
 		return VendorAMD
-	case "hygon":
+	case deps.VendorHygon:
 		return VendorHygon
+	case deps.VendorMThreads:
+		return VendorMThreads
+	case deps.VendorCambricon:
+		return VendorCambricon
 	default:
 		return VendorUnknown
 	}
 }
 
+// VisibleDevicesEnv returns the variable that selects the devices a process
+// of the vendor's runtime sees, or "" when none is set for it. The remote
+// GPU shows up as device 0 in studios.
+func VisibleDevicesEnv(vendor GPUVendor) string {
+	switch vendor {
+	case VendorNvidia:
+		return "CUDA_VISIBLE_DEVICES"
+	case VendorMThreads:
+		return "MUSA_VISIBLE_DEVICES"
+	case VendorCambricon:
+		return "MLU_VISIBLE_DEVICES"
+	default:
+		return ""
+	}
+}
+
 // GPUEnvConfig holds configuration for GPU environment setup
 type GPUEnvConfig struct {
 	Vendor        GPUVendor
 	ConnectionURL string // TENSOR_FUSION_OPERATOR_CONNECTION_INFO value
 	CachePath     string // Path to gpugo cache directory (for binaries like tensor-fusion-worker)
+	BinPath       string // Path to the vendor's GPU tools (nvidia-smi, rocm-smi, ...), prepended to PATH by ggo use
 	LibsPath      string // Path to gpugo libs directory (for .so/.dll files, used in LD paths)
 	LogPath       string // Path to logs directory (parent of logs-YYYY-mm-dd.txt)
 	StudioName    string // Name of the studio (for creating config files)
 	IsContainer   bool   // Whether this is for a container (affects paths)
+	// ConnectionName is exported as ConnectionEnv so activated shells know
+	// which `ggo use` environment they belong to
+	ConnectionName string
 }
 
+// ConnectionEnv names the `ggo use` environment an activated shell belongs to
+const ConnectionEnv = "_GGO_CONNECTION"
+
 // GPUEnvResult holds the result of GPU environment setup
 type GPUEnvResult struct {
 	EnvVars         map[string]string
@@ -66,6 +96,12 @@ func GetLibraryNames(vendor GPUVendor) []string {
 		return []string{"libcuda.so", "libnvidia-ml.so"}
 	case VendorAMD, VendorHygon:
 		return []string{"libamdhip64.so"}
+	case VendorMThreads:
+		// MUSA driver and MTML (the mthreads-gmi management library)
+		return []string{"libmusa.so", "libmtml.so"}
+	case VendorCambricon:
+		// CNDrv driver and CNDev (the cnmon management library)
+		return []string{"libcndrv.so", "libcndev.so"}
 	default:
 		return []string{}
 	}
@@ -109,6 +145,10 @@ func FindActualLibraryFiles(cachePath string, vendor GPUVendor) []string {
 		patterns = []string{"libcuda", "libnvidia", "nvcuda", "nvml", "libteleport", "libaccelerator"}
 	case VendorAMD, VendorHygon:
 		patterns = []string{"libamdhip", "librocm", "amdhip", "libteleport", "libaccelerator"}
+	case VendorMThreads:
+		patterns = []string{"libmusa", "libmtml", "libteleport", "libaccelerator"}
+	case VendorCambricon:
+		patterns = []string{"libcndrv", "libcndev", "libteleport", "libaccelerator"}
 	}
 
 	for _, entry := range entries {
@@ -138,18 +178,6 @@ func FindActualLibraryFiles(cachePath string, vendor GPUVendor) []string {
 	return libs
 }
 
-// gpuSMIBinaryName returns the GPU monitoring binary name for a vendor
-func gpuSMIBinaryName(vendor GPUVendor) string {
-	switch vendor {
-	case VendorNvidia:
-		return "nvidia-smi"
-	case VendorAMD, VendorHygon:
-		return "amdsmi"
-	default:
-		return ""
-	}
-}
-
 // GetWindowsLibraryNames returns the DLL names for a vendor (Windows)
 func GetWindowsLibraryNames(vendor GPUVendor) []string {
 	switch vendor {
@@ -207,6 +235,9 @@ func SetupGPUEnv(paths *platform.Paths, config *GPUEnvConfig) (*GPUEnvResult, er
 	result.EnvVars["TF_LOG_PATH"] = logFilePath
 	result.EnvVars["TF_LOG_LEVEL"] = getEnvDefault("TF_LOG_LEVEL", "info")
 	result.EnvVars["TF_ENABLE_LOG"] = getEnvDefault("TF_ENABLE_LOG", "1")
+	if config.ConnectionName != "" {
+		result.EnvVars[ConnectionEnv] = config.ConnectionName
+	}
 
 	// Get connections directory (for tensor-fusion-worker to write connection info)
 	connectionsDir := filepath.Join(paths.StateDir(), "connections")
@@ -288,18 +319,6 @@ func SetupGPUEnv(paths *platform.Paths, config *GPUEnvConfig) (*GPUEnvResult, er
 			})
 		}
 
-		// Mount GPU SMI binary (nvidia-smi / amdsmi) if available
-		if smiName := gpuSMIBinaryName(config.Vendor); smiName != "" {
-			smiPath := filepath.Join(cachePath, "bin", smiName)
-			if _, err := os.Stat(smiPath); err == nil {
-				result.VolumeMounts = append(result.VolumeMounts, VolumeMount{
-					HostPath:      smiPath,
-					ContainerPath: "/usr/local/bin/" + smiName,
-					ReadOnly:      true,
-				})
-			}
-		}
-
 		// Mount logs directory
 		result.VolumeMounts = append(result.VolumeMounts, VolumeMount{
 			HostPath:      logsDir,
@@ -461,6 +480,8 @@ func GeneratePowerShellScript(config *GPUEnvConfig, paths *platform.Paths) (stri
 	// libs directory contains only .dll files
 	script.WriteString("\n# Add GPU libraries to PATH (prepend for priority)\n")
 	fmt.Fprintf(&script, "$env:PATH = \"%s;$env:PATH\"\n", libsPath)
+	// Record the entry so that clean removes just it, keeping later PATH changes
+	fmt.Fprintf(&script, "$env:_GGO_PATH_ADDED = \"%s;$env:_GGO_PATH_ADDED\"\n", libsPath)
 
 	// Set CUDA_PATH for applications that check it - point to libs directory
 	script.WriteString("\n# Set CUDA_PATH for CUDA-aware applications\n")
@@ -521,6 +542,8 @@ func GenerateBatchScript(config *GPUEnvConfig, paths *platform.Paths) (string, e
 	// Add libs path to PATH at the FRONT - libs directory contains only .dll files
 	script.WriteString("\nREM Add GPU libraries to PATH (prepend for priority)\n")
 	fmt.Fprintf(&script, "set PATH=%s;%%PATH%%\n", libsPath)
+	// Record the entry so that clean removes just it, keeping later PATH changes
+	fmt.Fprintf(&script, "set _GGO_PATH_ADDED=%s;%%_GGO_PATH_ADDED%%\n", libsPath)
 
 	// Set CUDA_PATH - point to libs directory
 	script.WriteString("\nREM Set CUDA_PATH for CUDA-aware applications\n")
@@ -0,0 +1,120 @@
+package studio
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// EnvTemplateContext resolves the placeholders of templated env values:
+// {{secret "name"}}, {{host_ip}} and {{share_code}}
+type EnvTemplateContext struct {
+	// Secret returns a secret of the local secret store
+	Secret func(name string) (string, error)
+	// HostIP returns the address of this machine; HostIPv4 when nil
+	HostIP func() (string, error)
+	// ShareCode is the share code the studio's GPU worker is used with
+	ShareCode string
+}
+
+// IsEnvTemplate reports whether an env value has placeholders to resolve
+func IsEnvTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// ExpandEnvTemplates resolves the placeholders in the values of env in
+// place, so studio definitions can be shared without their credentials and
+// host specifics
+func ExpandEnvTemplates(env map[string]string, tc *EnvTemplateContext) error {
+	keys := make([]string, 0, len(env))
+	for k, v := range env {
+		if IsEnvTemplate(v) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	hostIP := tc.HostIP
+	if hostIP == nil {
+		hostIP = HostIPv4
+	}
+	funcs := template.FuncMap{
+		"secret": func(name string) (string, error) {
+			if tc.Secret == nil {
+				return "", fmt.Errorf("no secret store to read secret %q from", name)
+			}
+			return tc.Secret(name)
+		},
+		"host_ip": hostIP,
+		"share_code": func() (string, error) {
+			if tc.ShareCode == "" {
+				return "", fmt.Errorf("the studio has no share link to take the share code from")
+			}
+			return tc.ShareCode, nil
+		},
+	}
+
+	for _, k := range keys {
+		tmpl, err := template.New(k).Funcs(funcs).Option("missingkey=error").Parse(env[k])
+		if err != nil {
+			return fmt.Errorf("invalid template in env %s: %w", k, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, nil); err != nil {
+			return fmt.Errorf("failed to resolve env %s: %w", k, unwrapExecError(err))
+		}
+		env[k] = b.String()
+	}
+	return nil
+}
+
+// unwrapExecError drops the template position text/template puts in front of
+// errors returned by placeholder functions
+func unwrapExecError(err error) error {
+	var execErr template.ExecError
+	if errors.As(err, &execErr) {
+		if inner := errors.Unwrap(execErr.Err); inner != nil {
+			return inner
+		}
+	}
+	return err
+}
+
+// HostIPv4 returns the IPv4 address this machine reaches other hosts from:
+// the address of the interface with the default route, or else of the first
+// interface that is up and not a loopback
+func HostIPv4() (string, error) {
+	// Connecting a UDP socket picks the outgoing interface without sending
+	if conn, err := net.Dial("udp4", "192.0.2.1:9"); err == nil {
+		addr := conn.LocalAddr().(*net.UDPAddr)
+		conn.Close()
+		if !addr.IP.IsUnspecified() && !addr.IP.IsLoopback() {
+			return addr.IP.String(), nil
+		}
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf("failed to list network interfaces: %w", err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				return ipNet.IP.String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no IPv4 address found for this host")
+}
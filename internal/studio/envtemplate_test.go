@@ -0,0 +1,91 @@
+package studio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/credentials"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnvTemplates(t *testing.T) {
+	tc := &EnvTemplateContext{
+		Secret: func(name string) (string, error) {
+			if name == "hf" {
+				return "hf_secret", nil
+			}
+			return "", errors.New("secret not set")
+		},
+		HostIP:    func() (string, error) { return "10.0.0.7", nil },
+		ShareCode: "abc123",
+	}
+
+	env := map[string]string{
+		"HF_TOKEN":  `{{secret "hf"}}`,
+		"API_URL":   "http://{{host_ip}}:8000/v1",
+		"SHARE":     "{{share_code}}",
+		"PLAIN":     "a=b",
+		"UNTOUCHED": "{not a template}",
+	}
+	require.NoError(t, ExpandEnvTemplates(env, tc))
+	assert.Equal(t, map[string]string{
+		"HF_TOKEN":  "hf_secret",
+		"API_URL":   "http://10.0.0.7:8000/v1",
+		"SHARE":     "abc123",
+		"PLAIN":     "a=b",
+		"UNTOUCHED": "{not a template}",
+	}, env)
+
+	err := ExpandEnvTemplates(map[string]string{"X": `{{secret "missing"}}`}, tc)
+	assert.EqualError(t, err, "failed to resolve env X: secret not set")
+
+	err = ExpandEnvTemplates(map[string]string{"X": "{{share_code}}"}, &EnvTemplateContext{})
+	assert.ErrorContains(t, err, "no share link")
+
+	err = ExpandEnvTemplates(map[string]string{"X": "{{password}}"}, tc)
+	assert.ErrorContains(t, err, "invalid template in env X")
+}
+
+func TestSecretStore(t *testing.T) {
+	// Without a keyring secrets stay in the owner-only store file
+	defer credentials.SetKeyring(nil)()
+	dir := t.TempDir()
+	store := NewSecretStore(platform.DefaultPaths().WithConfigDir(dir))
+
+	_, err := store.Get("hf")
+	assert.ErrorContains(t, err, "ggo studio secret set hf")
+
+	_, err = store.Set("bad name", "x")
+	assert.Error(t, err)
+	_, err = store.Set("hf", "")
+	assert.Error(t, err)
+
+	_, err = store.Set("hf", "first")
+	require.NoError(t, err)
+	_, err = store.Set("hf", "second")
+	require.NoError(t, err)
+	value, err := store.Get("hf")
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+
+	secrets, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, SecretStoreFile))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	removed, err := store.Remove("hf")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = store.Remove("hf")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
@@ -0,0 +1,315 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// EnvVarSource tells where an environment variable of a studio is set
+type EnvVarSource string
+
+const (
+	// EnvSourceContainer variables were set when the container was created
+	EnvSourceContainer EnvVarSource = "container"
+	// EnvSourceLogin variables come from /etc/environment, which SSH and
+	// VS Code sessions read; they override container variables
+	EnvSourceLogin EnvVarSource = "login"
+)
+
+// etcEnvironment is read by pam_env for SSH sessions. GPU variables are
+// written here rather than to the container so sshd does not preload the
+// GPU client libraries.
+const etcEnvironment = "/etc/environment"
+
+// envSectionMarker separates `env` output from /etc/environment when both are
+// read with one exec
+const envSectionMarker = "--- ggo /etc/environment ---"
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvVar is an environment variable as studio sessions see it
+type EnvVar struct {
+	Key    string       `json:"key"`
+	Value  string       `json:"value"`
+	Source EnvVarSource `json:"source"`
+}
+
+// EnvChanges describes variables to set and remove in a studio environment
+type EnvChanges struct {
+	Set   map[string]string `json:"set,omitempty"`
+	Unset []string          `json:"unset,omitempty"`
+}
+
+// IsEmpty reports whether the changes change nothing
+func (c *EnvChanges) IsEmpty() bool {
+	return len(c.Set) == 0 && len(c.Unset) == 0
+}
+
+// Validate checks variable names, that values fit on one line of
+// /etc/environment and that no variable is both set and unset
+func (c *EnvChanges) Validate() error {
+	for k, v := range c.Set {
+		if !envKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid environment variable name %q", k)
+		}
+		// A line break would add lines, i.e. other variables, to the file
+		if strings.ContainsAny(v, "\n\r\x00") {
+			return fmt.Errorf("value of %s must not contain line breaks or NUL characters", k)
+		}
+	}
+	for _, k := range c.Unset {
+		if !envKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid environment variable name %q", k)
+		}
+		if _, ok := c.Set[k]; ok {
+			return fmt.Errorf("%s is both set and unset", k)
+		}
+	}
+	return nil
+}
+
+// IsGPUEnvVar reports whether a variable configures remote GPU access: the
+// worker connection, client library loading, limiter and logging settings
+// (TF_*) and device visibility
+func IsGPUEnvVar(key string) bool {
+	switch key {
+	case EnvLDPreload, EnvLDLibraryPath, ConnectionEnv,
+		"CUDA_VISIBLE_DEVICES", "NVIDIA_VISIBLE_DEVICES", "HIP_VISIBLE_DEVICES", "ROCR_VISIBLE_DEVICES":
+		return true
+	}
+	return strings.HasPrefix(key, "TENSOR_FUSION_") || strings.HasPrefix(key, "TF_")
+}
+
+// envLine is one line of /etc/environment; Key is empty for comments and
+// lines that are not assignments
+type envLine struct {
+	Key   string
+	Value string
+	Raw   string
+}
+
+// parseEnvironmentFile parses /etc/environment, keeping every line so it can
+// be written back unchanged apart from the edited variables
+func parseEnvironmentFile(content string) []envLine {
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return nil
+	}
+	var lines []envLine
+	for _, raw := range strings.Split(content, "\n") {
+		line := envLine{Raw: raw}
+		trimmed := strings.TrimPrefix(strings.TrimSpace(raw), "export ")
+		if key, value, ok := strings.Cut(trimmed, "="); ok && !strings.HasPrefix(trimmed, "#") && envKeyPattern.MatchString(key) {
+			line.Key = key
+			line.Value = unquoteEnvValue(value)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func unquoteEnvValue(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			return envValueEscaper.Replace(value[1 : len(value)-1])
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+var (
+	envValueQuoter  = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	envValueEscaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+)
+
+// formatEnvLine quotes a value the way container setup writes
+// /etc/environment. Backslashes are escaped so a trailing one cannot continue
+// the line.
+func formatEnvLine(key, value string) string {
+	return fmt.Sprintf(`%s="%s"`, key, envValueQuoter.Replace(value))
+}
+
+// parseEnvOutput parses `env` output into a map
+func parseEnvOutput(output string) map[string]string {
+	vars := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok && envKeyPattern.MatchString(key) {
+			vars[key] = value
+		}
+	}
+	return vars
+}
+
+// applyEnvChanges edits /etc/environment lines. Set variables replace their
+// line or are appended. Unset variables are removed, or blanked when the
+// container itself sets them, since sessions would inherit them otherwise.
+func applyEnvChanges(lines []envLine, container map[string]string, changes *EnvChanges) []envLine {
+	unset := make(map[string]bool, len(changes.Unset))
+	for _, k := range changes.Unset {
+		unset[k] = true
+	}
+	written := make(map[string]bool, len(changes.Set))
+	var result []envLine
+	for _, line := range lines {
+		if value, ok := changes.Set[line.Key]; ok && line.Key != "" {
+			if !written[line.Key] {
+				result = append(result, envLine{Key: line.Key, Value: value, Raw: formatEnvLine(line.Key, value)})
+				written[line.Key] = true
+			}
+			continue
+		}
+		if unset[line.Key] {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	keys := make([]string, 0, len(changes.Set))
+	for k := range changes.Set {
+		if !written[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result = append(result, envLine{Key: k, Value: changes.Set[k], Raw: formatEnvLine(k, changes.Set[k])})
+	}
+	for _, k := range changes.Unset {
+		if _, ok := container[k]; ok {
+			result = append(result, envLine{Key: k, Raw: formatEnvLine(k, "")})
+		}
+	}
+	return result
+}
+
+// effectiveEnv merges container variables with /etc/environment as pam_env
+// does. GPU variables are returned, and every /etc/environment variable but
+// PATH since `ggo studio env --set` may have added it.
+func effectiveEnv(container map[string]string, lines []envLine) []EnvVar {
+	vars := make(map[string]EnvVar)
+	for k, v := range container {
+		if IsGPUEnvVar(k) {
+			vars[k] = EnvVar{Key: k, Value: v, Source: EnvSourceContainer}
+		}
+	}
+	for _, line := range lines {
+		if line.Key != "" && line.Key != "PATH" {
+			vars[line.Key] = EnvVar{Key: line.Key, Value: line.Value, Source: EnvSourceLogin}
+		}
+	}
+	result := make([]EnvVar, 0, len(vars))
+	for _, v := range vars {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// readStudioEnv reads the container environment and /etc/environment of a
+// running environment
+func readStudioEnv(ctx context.Context, backend Backend, envID string) (map[string]string, []envLine, error) {
+	script := fmt.Sprintf("env; echo '%s'; cat %s 2>/dev/null; true", envSectionMarker, etcEnvironment)
+	output, err := backend.Exec(ctx, envID, []string{"sh", "-c", script})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read environment: %w, output: %s", err, output)
+	}
+	envOutput, fileOutput, ok := strings.Cut(string(output), envSectionMarker+"\n")
+	if !ok {
+		return nil, nil, fmt.Errorf("failed to read environment: unexpected output: %s", output)
+	}
+	return parseEnvOutput(envOutput), parseEnvironmentFile(fileOutput), nil
+}
+
+// writeEnvironmentFile replaces /etc/environment in the container
+func writeEnvironmentFile(ctx context.Context, backend Backend, envID string, lines []envLine) error {
+	var content strings.Builder
+	for _, line := range lines {
+		content.WriteString(line.Raw)
+		content.WriteString("\n")
+	}
+	script := fmt.Sprintf(`printf '%%s' "$1" > %[1]s.ggo-tmp && mv %[1]s.ggo-tmp %[1]s`, etcEnvironment)
+	if output, err := backend.Exec(ctx, envID, []string{"sh", "-c", script, "sh", content.String()}); err != nil {
+		return fmt.Errorf("failed to write %s: %w, output: %s", etcEnvironment, err, output)
+	}
+	return nil
+}
+
+// Env returns the GPU environment of a running studio as SSH and VS Code
+// sessions see it, sorted by name
+func (m *Manager) Env(ctx context.Context, idOrName string) ([]EnvVar, error) {
+	env, err := m.Get(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if env.Status != StatusRunning {
+		return nil, fmt.Errorf("environment %s is %s; start it to inspect its environment", env.Name, env.Status)
+	}
+	backend, err := m.GetBackend(env.Mode)
+	if err != nil {
+		return nil, err
+	}
+	container, lines, err := readStudioEnv(ctx, backend, env.ID)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveEnv(container, lines), nil
+}
+
+// UpdateEnv persists variable changes in the studio's /etc/environment and
+// restarts it so running processes and sessions pick them up. A stopped
+// studio is started for the edit and stopped again. Returns the environment
+// as sessions will see it.
+func (m *Manager) UpdateEnv(ctx context.Context, idOrName string, changes *EnvChanges) ([]EnvVar, error) {
+	if changes.IsEmpty() {
+		return nil, fmt.Errorf("nothing to change: specify variables to set or unset")
+	}
+	if err := changes.Validate(); err != nil {
+		return nil, err
+	}
+	env, err := m.Get(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := m.GetBackend(env.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	running := env.Status == StatusRunning
+	if !running {
+		if err := backend.Start(ctx, env.ID); err != nil {
+			return nil, err
+		}
+	}
+	container, lines, err := readStudioEnv(ctx, backend, env.ID)
+	if err == nil {
+		lines = applyEnvChanges(lines, container, changes)
+		err = writeEnvironmentFile(ctx, backend, env.ID, lines)
+	}
+	if err != nil {
+		if !running {
+			_ = backend.Stop(ctx, env.ID)
+		}
+		return nil, err
+	}
+
+	if err := backend.Stop(ctx, env.ID); err != nil {
+		return nil, fmt.Errorf("environment updated but not restarted: %w", err)
+	}
+	if running {
+		if err := backend.Start(ctx, env.ID); err != nil {
+			return nil, fmt.Errorf("environment updated but failed to start again: %w", err)
+		}
+	}
+	klog.Infof("Updated studio environment variables: env=%s set=%d unset=%d restarted=%t",
+		env.Name, len(changes.Set), len(changes.Unset), running)
+	return effectiveEnv(container, lines), nil
+}
@@ -0,0 +1,170 @@
+package studio
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleEtcEnvironment = `# set by the image
+PATH="/opt/conda/bin:/usr/bin:/bin"
+TENSOR_FUSION_OPERATOR_CONNECTION_INFO="native+10.0.0.5+9001+abc"
+LD_PRELOAD="/opt/gpugo/libs/libcuda.so:/opt/gpugo/libs/libnvidia-ml.so"
+TF_LOG_LEVEL=info
+`
+
+func TestParseEnvironmentFile(t *testing.T) {
+	lines := parseEnvironmentFile(sampleEtcEnvironment + "export TF_QUOTE='a \"b\"'\nnot an assignment\n")
+	require.Len(t, lines, 7)
+	assert.Equal(t, envLine{Raw: "# set by the image"}, lines[0])
+	assert.Equal(t, "PATH", lines[1].Key)
+	assert.Equal(t, "/opt/conda/bin:/usr/bin:/bin", lines[1].Value)
+	assert.Equal(t, "native+10.0.0.5+9001+abc", lines[2].Value)
+	assert.Equal(t, "info", lines[4].Value)
+	assert.Equal(t, "TF_QUOTE", lines[5].Key)
+	assert.Equal(t, `a "b"`, lines[5].Value)
+	assert.Empty(t, lines[6].Key)
+
+	assert.Empty(t, parseEnvironmentFile(""))
+}
+
+func TestApplyEnvChanges(t *testing.T) {
+	lines := parseEnvironmentFile(sampleEtcEnvironment)
+	container := map[string]string{"TF_ENABLE_LOG": "1", "HOME": "/root"}
+
+	result := applyEnvChanges(lines, container, &EnvChanges{
+		Set:   map[string]string{"TF_LOG_LEVEL": "debug", "TF_GPU_MEMORY_LIMIT": "4096", "TF_NOTE": `say "hi"`},
+		Unset: []string{"LD_PRELOAD", "TF_ENABLE_LOG"},
+	})
+
+	var raw []string
+	for _, line := range result {
+		raw = append(raw, line.Raw)
+	}
+	assert.Equal(t, []string{
+		"# set by the image",
+		`PATH="/opt/conda/bin:/usr/bin:/bin"`,
+		`TENSOR_FUSION_OPERATOR_CONNECTION_INFO="native+10.0.0.5+9001+abc"`,
+		`TF_LOG_LEVEL="debug"`,
+		`TF_GPU_MEMORY_LIMIT="4096"`,
+		`TF_NOTE="say \"hi\""`,
+		`TF_ENABLE_LOG=""`,
+	}, raw, "variables set on the container are blanked rather than removed")
+}
+
+func TestEffectiveEnv(t *testing.T) {
+	container := map[string]string{
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO": "native+10.0.0.1+9001+old",
+		"TF_ENABLE_LOG":                          "1",
+		"HOME":                                   "/root",
+	}
+	lines := parseEnvironmentFile(sampleEtcEnvironment + "EDITOR=vim\n")
+
+	assert.Equal(t, []EnvVar{
+		{Key: "EDITOR", Value: "vim", Source: EnvSourceLogin},
+		{Key: "LD_PRELOAD", Value: "/opt/gpugo/libs/libcuda.so:/opt/gpugo/libs/libnvidia-ml.so", Source: EnvSourceLogin},
+		{Key: "TENSOR_FUSION_OPERATOR_CONNECTION_INFO", Value: "native+10.0.0.5+9001+abc", Source: EnvSourceLogin},
+		{Key: "TF_ENABLE_LOG", Value: "1", Source: EnvSourceContainer},
+		{Key: "TF_LOG_LEVEL", Value: "info", Source: EnvSourceLogin},
+	}, effectiveEnv(container, lines))
+}
+
+func TestEnvChanges_Validate(t *testing.T) {
+	assert.NoError(t, (&EnvChanges{Set: map[string]string{"TF_LOG_LEVEL": "debug"}, Unset: []string{"LD_PRELOAD"}}).Validate())
+	assert.Error(t, (&EnvChanges{Set: map[string]string{"BAD-NAME": "x"}}).Validate())
+	assert.Error(t, (&EnvChanges{Unset: []string{"1X"}}).Validate())
+	assert.Error(t, (&EnvChanges{Set: map[string]string{"TF_X": "1"}, Unset: []string{"TF_X"}}).Validate())
+	for _, value := range []string{"x\nLD_PRELOAD=/tmp/evil.so", "x\rY=1", "x\x00"} {
+		assert.ErrorContains(t, (&EnvChanges{Set: map[string]string{"A": value}}).Validate(), "line breaks", "%q", value)
+	}
+}
+
+func TestFormatEnvLine_RoundTrip(t *testing.T) {
+	for _, value := range []string{`plain`, `say "hi"`, `C:\dir\`, `\"`, ``} {
+		line := formatEnvLine("TF_X", value)
+		lines := parseEnvironmentFile(line + "\nNEXT=1\n")
+		require.Len(t, lines, 2, line)
+		assert.Equal(t, value, lines[0].Value, line)
+	}
+	assert.Equal(t, `TF_X="a\\"`, formatEnvLine("TF_X", `a\`), "a trailing backslash cannot continue the line")
+}
+
+// envExecBackend keeps /etc/environment in memory and answers the commands
+// Env and UpdateEnv run
+func envExecBackend(t *testing.T, status EnvironmentStatus) (*MockBackend, *string, *[]string) {
+	file := sampleEtcEnvironment
+	var calls []string
+	backend := &MockBackend{
+		mode:      ModeDocker,
+		available: true,
+		envs: map[string]*Environment{
+			"env-1": {ID: "env-1", Name: "my-env", Mode: ModeDocker, Status: status},
+		},
+	}
+	backend.startFunc = func(ctx context.Context, envID string) error {
+		calls = append(calls, "start")
+		backend.envs[envID].Status = StatusRunning
+		return nil
+	}
+	backend.stopFunc = func(ctx context.Context, envID string) error {
+		calls = append(calls, "stop")
+		backend.envs[envID].Status = StatusStopped
+		return nil
+	}
+	backend.execFunc = func(ctx context.Context, envID string, cmd []string) ([]byte, error) {
+		require.Equal(t, StatusRunning, backend.envs[envID].Status, "exec needs a running container")
+		require.GreaterOrEqual(t, len(cmd), 3)
+		if strings.HasPrefix(cmd[2], "env;") {
+			calls = append(calls, "read")
+			return []byte("HOME=/root\nTF_ENABLE_LOG=1\n" + envSectionMarker + "\n" + file), nil
+		}
+		calls = append(calls, "write")
+		require.Len(t, cmd, 5)
+		file = cmd[4]
+		return nil, nil
+	}
+	return backend, &file, &calls
+}
+
+func TestManager_Env(t *testing.T) {
+	m := NewManager()
+	backend, _, _ := envExecBackend(t, StatusRunning)
+	m.RegisterBackend(backend)
+
+	vars, err := m.Env(context.Background(), "my-env")
+	require.NoError(t, err)
+	require.Len(t, vars, 4)
+	assert.Equal(t, EnvVar{Key: "TF_ENABLE_LOG", Value: "1", Source: EnvSourceContainer}, vars[2])
+
+	backend.envs["env-1"].Status = StatusStopped
+	_, err = m.Env(context.Background(), "my-env")
+	assert.Error(t, err)
+}
+
+func TestManager_UpdateEnv(t *testing.T) {
+	m := NewManager()
+	backend, file, calls := envExecBackend(t, StatusRunning)
+	m.RegisterBackend(backend)
+
+	vars, err := m.UpdateEnv(context.Background(), "my-env", &EnvChanges{Set: map[string]string{"TF_LOG_LEVEL": "debug"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "write", "stop", "start"}, *calls, "a running environment is restarted")
+	assert.Contains(t, *file, "TF_LOG_LEVEL=\"debug\"\n")
+	assert.NotContains(t, *file, "TF_LOG_LEVEL=info")
+	assert.Contains(t, vars, EnvVar{Key: "TF_LOG_LEVEL", Value: "debug", Source: EnvSourceLogin})
+
+	// A stopped environment is started for the edit and left stopped
+	*calls = nil
+	backend.envs["env-1"].Status = StatusStopped
+	_, err = m.UpdateEnv(context.Background(), "my-env", &EnvChanges{Unset: []string{"LD_PRELOAD"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start", "read", "write", "stop"}, *calls)
+	assert.NotContains(t, *file, "LD_PRELOAD")
+	assert.Equal(t, StatusStopped, backend.envs["env-1"].Status)
+
+	_, err = m.UpdateEnv(context.Background(), "my-env", &EnvChanges{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package studio
+
+import (
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+)
+
+// Sources of the GPU load in a GPUTopSample
+const (
+	// GPUTopSourceAgent is the live snapshot of the agent on this host
+	GPUTopSourceAgent = "agent"
+	// GPUTopSourcePlatform is the platform's share stats
+	GPUTopSourcePlatform = "platform"
+)
+
+// What limits the remote GPU work of a studio, see GPUTopSample.Bottleneck
+const (
+	BottleneckGPU     = "gpu"
+	BottleneckNetwork = "network"
+	BottleneckIdle    = "idle"
+	// BottleneckNone means neither the GPU nor the link is saturated
+	BottleneckNone = "none"
+	// BottleneckUnknown is reported until the link was measured twice
+	BottleneckUnknown = "unknown"
+)
+
+const (
+	// gpuBoundUtilization is the GPU utilization, in percent, from which
+	// work is GPU bound
+	gpuBoundUtilization = 85
+	// networkBoundUtilization is the utilization below which a GPU that the
+	// studio keeps busy with traffic waits on the link
+	networkBoundUtilization = 50
+	// idleUtilization and activeLinkBytesPerSec tell an idle studio from one
+	// talking to its worker
+	idleUtilization       = 5
+	activeLinkBytesPerSec = 64 << 10
+	// slowLinkLatencyMs is the connect time from which a link without
+	// traffic accounting is taken to hold back a GPU that is not busy
+	slowLinkLatencyMs = 30
+)
+
+// GPUTopSample is one sample of the remote GPU worker a studio uses and of
+// the link to it, for `ggo studio gpu-top`
+type GPUTopSample struct {
+	Studio   string `json:"studio,omitempty"`
+	WorkerID string `json:"worker_id,omitempty"`
+	// Source is where the GPU load came from, see GPUTopSource*
+	Source    string           `json:"source,omitempty"`
+	SampledAt time.Time        `json:"sampled_at"`
+	GPUs      []api.GPUMetrics `json:"gpus"`
+	// Sessions counts the worker's client sessions opened with the studio's
+	// share
+	Sessions int `json:"sessions"`
+	// SessionVRAMUsedMb is the GPU memory held by those sessions; 0 when the
+	// worker does not account memory per client
+	SessionVRAMUsedMb int64   `json:"session_vram_used_mb,omitempty"`
+	Link              GPULink `json:"link"`
+	// Bottleneck is what limits the studio's GPU work, see Bottleneck*
+	Bottleneck string `json:"bottleneck"`
+	// Error tells why the GPU load is missing
+	Error string `json:"error,omitempty"`
+}
+
+// GPULink is the link between this machine and a studio's GPU worker
+type GPULink struct {
+	GPUConnection
+	// Accounted is set when the agent's connection proxy counts the traffic
+	// of the share's sessions: SentBytes to and ReceivedBytes from the worker
+	Accounted     bool  `json:"accounted"`
+	SentBytes     int64 `json:"sent_bytes,omitempty"`
+	ReceivedBytes int64 `json:"received_bytes,omitempty"`
+	// Rates is the traffic since the previous sample; nil on the first
+	Rates *LinkRates `json:"rates,omitempty"`
+}
+
+// LinkRates is the throughput of a studio's link to its GPU worker
+type LinkRates struct {
+	SendBytesPerSec    float64 `json:"send_bytes_per_sec"`
+	ReceiveBytesPerSec float64 `json:"receive_bytes_per_sec"`
+}
+
+// ShareCodeFromWorkerURL returns the share code a GPU worker URL from a share
+// link ends in, such as abc123 in native+10.0.0.5+9001+abc123; empty for
+// URLs given with --endpoint
+func ShareCodeFromWorkerURL(workerURL string) string {
+	if rest, ok := strings.CutPrefix(workerURL, "native+"); ok && !strings.Contains(rest, "://") {
+		if parts := strings.Split(rest, "+"); len(parts) > 2 {
+			return parts[len(parts)-1]
+		}
+		return ""
+	}
+	if _, rest, ok := strings.Cut(workerURL, "://"); ok {
+		if _, code, ok := strings.Cut(rest, "+"); ok {
+			return code
+		}
+	}
+	return ""
+}
+
+// SetSessions counts the sessions opened with shareCode and their traffic.
+// Without the connection proxy sessions carry no share code, so all of them
+// are counted and the traffic is unknown.
+func (s *GPUTopSample) SetSessions(sessions []api.ConnectionInfo, shareCode string) {
+	accounted := false
+	for _, c := range sessions {
+		if c.ShareCode == "" {
+			continue
+		}
+		accounted = true
+		if c.ShareCode != shareCode {
+			continue
+		}
+		s.Sessions++
+		s.Link.SentBytes += c.BytesIn
+		s.Link.ReceivedBytes += c.BytesOut
+	}
+	if !accounted {
+		s.Sessions = len(sessions)
+	}
+	s.Link.Accounted = accounted
+}
+
+// Update measures the link throughput since prev, the previous sample of the
+// same studio, and decides the bottleneck
+func (s *GPUTopSample) Update(prev *GPUTopSample) {
+	if prev != nil && prev.WorkerID == s.WorkerID && prev.Source == s.Source && prev.Link.Accounted && s.Link.Accounted &&
+		s.Link.SentBytes >= prev.Link.SentBytes && s.Link.ReceivedBytes >= prev.Link.ReceivedBytes {
+		if elapsed := s.SampledAt.Sub(prev.SampledAt).Seconds(); elapsed > 0 {
+			s.Link.Rates = &LinkRates{
+				SendBytesPerSec:    float64(s.Link.SentBytes-prev.Link.SentBytes) / elapsed,
+				ReceiveBytesPerSec: float64(s.Link.ReceivedBytes-prev.Link.ReceivedBytes) / elapsed,
+			}
+		}
+	}
+	s.Bottleneck = s.bottleneck()
+}
+
+// MaxUtilization returns the utilization of the busiest GPU
+func (s *GPUTopSample) MaxUtilization() float64 {
+	var util float64
+	for _, g := range s.GPUs {
+		util = max(util, g.Utilization)
+	}
+	return util
+}
+
+func (s *GPUTopSample) bottleneck() string {
+	if s.Link.Addr != "" && !s.Link.Reachable {
+		return BottleneckNetwork
+	}
+	if len(s.GPUs) == 0 {
+		return BottleneckUnknown
+	}
+	util := s.MaxUtilization()
+	if util >= gpuBoundUtilization {
+		return BottleneckGPU
+	}
+	if s.Sessions == 0 && util < idleUtilization {
+		return BottleneckIdle
+	}
+	if s.Link.Rates == nil {
+		if s.Sessions > 0 && util < networkBoundUtilization && s.Link.LatencyMs >= slowLinkLatencyMs {
+			return BottleneckNetwork
+		}
+		return BottleneckUnknown
+	}
+	traffic := s.Link.Rates.SendBytesPerSec + s.Link.Rates.ReceiveBytesPerSec
+	switch {
+	case traffic >= activeLinkBytesPerSec && util < networkBoundUtilization:
+		// The studio keeps calling the GPU, but the GPU mostly waits
+		return BottleneckNetwork
+	case traffic < activeLinkBytesPerSec && util < idleUtilization:
+		return BottleneckIdle
+	}
+	return BottleneckNone
+}
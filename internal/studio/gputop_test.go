@@ -0,0 +1,85 @@
+package studio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareCodeFromWorkerURL(t *testing.T) {
+	assert.Equal(t, "abc123", ShareCodeFromWorkerURL("native+10.0.0.5+9001+abc123"))
+	assert.Equal(t, "abc123", ShareCodeFromWorkerURL("tcp://10.0.0.5:9001+abc123"))
+	assert.Empty(t, ShareCodeFromWorkerURL("native+10.0.0.5+9001"), "--endpoint URLs carry no share")
+	assert.Empty(t, ShareCodeFromWorkerURL("tcp://10.0.0.5:9001"))
+}
+
+func TestGPUTopSample_SetSessions(t *testing.T) {
+	s := &GPUTopSample{}
+	s.SetSessions([]api.ConnectionInfo{
+		{ShareCode: "abc", BytesIn: 100, BytesOut: 1000},
+		{ShareCode: "abc", BytesIn: 50, BytesOut: 500},
+		{ShareCode: "other", BytesIn: 9999},
+	}, "abc")
+	assert.Equal(t, 2, s.Sessions)
+	assert.True(t, s.Link.Accounted)
+	assert.Equal(t, int64(150), s.Link.SentBytes)
+	assert.Equal(t, int64(1500), s.Link.ReceivedBytes)
+
+	s = &GPUTopSample{}
+	s.SetSessions([]api.ConnectionInfo{{ClientIP: "10.0.0.1"}, {ClientIP: "10.0.0.2"}}, "abc")
+	assert.Equal(t, 2, s.Sessions, "without the proxy all sessions count")
+	assert.False(t, s.Link.Accounted)
+}
+
+func TestGPUTopSample_Bottleneck(t *testing.T) {
+	start := time.Now()
+	sample := func(util float64, sent, received int64, at time.Duration) *GPUTopSample {
+		s := &GPUTopSample{
+			WorkerID:  "w1",
+			SampledAt: start.Add(at),
+			GPUs:      []api.GPUMetrics{{GPUID: "gpu-0", Utilization: util}},
+			Sessions:  1,
+		}
+		s.Link.Addr, s.Link.Reachable, s.Link.LatencyMs = "10.0.0.5:9001", true, 2
+		s.Link.Accounted, s.Link.SentBytes, s.Link.ReceivedBytes = true, sent, received
+		return s
+	}
+
+	first := sample(20, 0, 0, 0)
+	first.Update(nil)
+	assert.Equal(t, BottleneckUnknown, first.Bottleneck, "throughput needs two samples")
+	assert.Nil(t, first.Link.Rates)
+
+	busyLink := sample(20, 1<<20, 3<<20, 2*time.Second)
+	busyLink.Update(first)
+	if assert.NotNil(t, busyLink.Link.Rates) {
+		assert.InDelta(t, 512<<10, busyLink.Link.Rates.SendBytesPerSec, 1)
+		assert.InDelta(t, 1536<<10, busyLink.Link.Rates.ReceiveBytesPerSec, 1)
+	}
+	assert.Equal(t, BottleneckNetwork, busyLink.Bottleneck)
+
+	busyGPU := sample(97, 1<<20, 3<<20, 4*time.Second)
+	busyGPU.Update(busyLink)
+	assert.Equal(t, BottleneckGPU, busyGPU.Bottleneck)
+
+	idle := sample(1, 1<<20, 3<<20, 6*time.Second)
+	idle.Update(busyGPU)
+	assert.Equal(t, BottleneckIdle, idle.Bottleneck)
+
+	steady := sample(60, 2<<20, 4<<20, 8*time.Second)
+	steady.Update(idle)
+	assert.Equal(t, BottleneckNone, steady.Bottleneck)
+
+	unreachable := sample(0, 0, 0, 10*time.Second)
+	unreachable.Link.Reachable = false
+	unreachable.Update(steady)
+	assert.Equal(t, BottleneckNetwork, unreachable.Bottleneck)
+	assert.Nil(t, unreachable.Link.Rates, "counters went back, e.g. after a worker restart")
+
+	slow := sample(10, 0, 0, 0)
+	slow.Link.Accounted, slow.Link.LatencyMs = false, 80
+	slow.Update(nil)
+	assert.Equal(t, BottleneckNetwork, slow.Bottleneck, "a slow link without accounting still points at the network")
+}
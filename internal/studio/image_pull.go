@@ -0,0 +1,220 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/progress"
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+)
+
+// PullPolicy controls when an image is pulled before an environment is created
+type PullPolicy string
+
+const (
+	// PullPolicyMissing pulls only when no local image matches the platform (default)
+	PullPolicyMissing PullPolicy = "missing"
+	// PullPolicyAlways pulls even if a local image exists
+	PullPolicyAlways PullPolicy = "always"
+	// PullPolicyNever uses only locally loaded images (e.g. via docker load)
+	PullPolicyNever PullPolicy = "never"
+)
+
+// ParsePullPolicy validates a --pull value; empty means PullPolicyMissing
+func ParsePullPolicy(s string) (PullPolicy, error) {
+	switch p := PullPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return PullPolicyMissing, nil
+	case PullPolicyMissing, PullPolicyAlways, PullPolicyNever:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid pull policy %q: must be one of never, missing, always", s)
+	}
+}
+
+// PullOptions configures an image pull
+type PullOptions struct {
+	// Platform to pull (e.g. linux/amd64); empty uses the backend's architecture
+	Platform string
+	Policy   PullPolicy
+	// Progress receives pull progress; terminals get the runtime's native
+	// per-layer progress bars, other writers a line per layer state change.
+	// It is unused while progress events are enabled.
+	Progress io.Writer
+}
+
+// ImagePuller is implemented by backends that can pull an image ahead of
+// Create, so that the pull step is shared and its progress visible
+type ImagePuller interface {
+	PullImage(ctx context.Context, image string, opts PullOptions) error
+}
+
+// cliCommand builds an invocation of a docker-compatible CLI
+type cliCommand func(ctx context.Context, args ...string) *exec.Cmd
+
+// pullImage applies opts.Policy to image using the CLI built by run
+func pullImage(ctx context.Context, run cliCommand, image string, opts PullOptions) error {
+	policy := opts.Policy
+	if policy == "" {
+		policy = PullPolicyMissing
+	}
+	progressOut := opts.Progress
+	if progressOut == nil || progress.Enabled() {
+		// Progress events replace the text output
+		progressOut = io.Discard
+	}
+
+	exists, localPlatform := inspectLocalImage(ctx, run, image)
+	switch policy {
+	case PullPolicyNever:
+		if !exists {
+			return fmt.Errorf("image %s not found locally and pull policy is never; load it first (e.g. docker load -i image.tar)", image)
+		}
+		if opts.Platform != "" && localPlatform != "" && localPlatform != opts.Platform {
+			klog.Warningf("Local image %s is %s, not %s; using it because pull policy is never", image, localPlatform, opts.Platform)
+		}
+		return nil
+	case PullPolicyMissing:
+		if exists && (opts.Platform == "" || localPlatform == opts.Platform) {
+			klog.V(2).Infof("Image %s already exists locally, skipping pull", image)
+			return nil
+		}
+		if exists {
+			klog.V(2).Infof("Image %s exists locally as %s but need %s, will pull", image, localPlatform, opts.Platform)
+		}
+	}
+
+	_, _ = fmt.Fprintf(progressOut, "\n   Pulling image: %s\n", image)
+	if opts.Platform != "" {
+		_, _ = fmt.Fprintf(progressOut, "   Platform: %s\n", opts.Platform)
+	}
+	_, _ = fmt.Fprintf(progressOut, "   This may take a few minutes for large images...\n\n")
+
+	progress.StepStarted(progress.StepImagePull, "Pulling image "+image)
+	args := []string{"pull"}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+	args = append(args, image)
+
+	cmd := run(ctx, args...)
+	var tracker *layerProgress
+	if f, ok := progressOut.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		// The runtime draws its own per-layer progress bars on a terminal
+		cmd.Stdout = f
+		cmd.Stderr = f
+	} else {
+		tracker = newLayerProgress(progressOut, image)
+		cmd.Stdout = tracker
+		cmd.Stderr = tracker
+	}
+	err := cmd.Run()
+	if tracker != nil {
+		tracker.Flush()
+	}
+	if err != nil {
+		if exists && policy == PullPolicyMissing {
+			// Pull failed but image exists locally (e.g. local-only custom image
+			// with a different platform) — use the local image as-is
+			klog.V(2).Infof("Pull failed but image %s exists locally, using local image", image)
+			_, _ = fmt.Fprintf(progressOut, "   Pull failed, using local image %s\n\n", image)
+			progress.StepFinished(progress.StepImagePull, "Pull failed, using local image "+image)
+			return nil
+		}
+		err = fmt.Errorf("failed to pull image %s: %w", image, err)
+		progress.Failed(progress.StepImagePull, err)
+		return err
+	}
+
+	_, _ = fmt.Fprintf(progressOut, "\n   Image pulled successfully!\n\n")
+	progress.StepFinished(progress.StepImagePull, "Image pulled")
+	return nil
+}
+
+// inspectLocalImage reports whether image exists locally and its os/arch
+func inspectLocalImage(ctx context.Context, run cliCommand, image string) (bool, string) {
+	out, err := run(ctx, "image", "inspect", "--format", "{{.Os}}/{{.Architecture}}", image).Output()
+	if err != nil {
+		return false, ""
+	}
+	return true, strings.TrimSpace(string(out))
+}
+
+// layerLine matches the per-layer status lines docker prints when its
+// output is not a terminal, e.g. "a1b2c3d4e5f6: Pull complete"
+var layerLine = regexp.MustCompile(`^([0-9a-f]{12}): (.+)$`)
+
+// layerProgress turns non-terminal pull output into lines that show how
+// many layers have completed, so long pulls don't look stalled, and into
+// progress events
+type layerProgress struct {
+	out     io.Writer
+	image   string
+	partial []byte
+	order   []string
+	status  map[string]string
+}
+
+func newLayerProgress(out io.Writer, image string) *layerProgress {
+	return &layerProgress{out: out, image: image, status: make(map[string]string)}
+}
+
+func (p *layerProgress) Write(b []byte) (int, error) {
+	p.partial = append(p.partial, b...)
+	for {
+		i := slices.Index(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+		p.line(string(p.partial[:i]))
+		p.partial = p.partial[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes any trailing output without a newline
+func (p *layerProgress) Flush() {
+	if len(p.partial) > 0 {
+		p.line(string(p.partial))
+		p.partial = nil
+	}
+}
+
+func (p *layerProgress) line(s string) {
+	s = strings.TrimSpace(strings.TrimRight(s, "\r"))
+	if s == "" {
+		return
+	}
+	m := layerLine.FindStringSubmatch(s)
+	if m == nil {
+		_, _ = fmt.Fprintf(p.out, "   %s\n", s)
+		return
+	}
+	id, status := m[1], m[2]
+	if prev, seen := p.status[id]; !seen {
+		p.order = append(p.order, id)
+	} else if prev == status {
+		return
+	}
+	p.status[id] = status
+	done := p.done()
+	_, _ = fmt.Fprintf(p.out, "   [%d/%d] %s: %s\n", done, len(p.order), id, status)
+	progress.Update(progress.StepImagePull, p.image, int64(done), int64(len(p.order)), progress.UnitLayers)
+}
+
+func (p *layerProgress) done() int {
+	n := 0
+	for _, id := range p.order {
+		if s := p.status[id]; s == "Pull complete" || s == "Already exists" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,92 @@
+package studio
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePullPolicy(t *testing.T) {
+	for in, want := range map[string]PullPolicy{
+		"":        PullPolicyMissing,
+		"missing": PullPolicyMissing,
+		"Always":  PullPolicyAlways,
+		" never ": PullPolicyNever,
+	} {
+		got, err := ParsePullPolicy(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := ParsePullPolicy("sometimes")
+	assert.Error(t, err)
+}
+
+// fakeCLI simulates a docker CLI: localPlatform empty means the image is absent
+func fakeCLI(localPlatform string, pulls *[]string) cliCommand {
+	return func(ctx context.Context, args ...string) *exec.Cmd {
+		switch args[0] {
+		case "image":
+			if localPlatform == "" {
+				return exec.CommandContext(ctx, "sh", "-c", "exit 1")
+			}
+			return exec.CommandContext(ctx, "sh", "-c", "echo "+localPlatform)
+		default:
+			*pulls = append(*pulls, strings.Join(args, " "))
+			return exec.CommandContext(ctx, "sh", "-c",
+				`printf 'latest: Pulling from studio\na1b2c3d4e5f6: Pulling fs layer\na1b2c3d4e5f6: Pull complete\n'`)
+		}
+	}
+}
+
+func TestPullImage_Policies(t *testing.T) {
+	ctx := context.Background()
+
+	var pulls []string
+	err := pullImage(ctx, fakeCLI("", &pulls), "img", PullOptions{Policy: PullPolicyNever})
+	assert.ErrorContains(t, err, "not found locally")
+	assert.Empty(t, pulls)
+
+	err = pullImage(ctx, fakeCLI("linux/amd64", &pulls), "img", PullOptions{Platform: "linux/amd64"})
+	require.NoError(t, err)
+	assert.Empty(t, pulls, "matching local image is reused")
+
+	err = pullImage(ctx, fakeCLI("linux/arm64", &pulls), "img", PullOptions{Platform: "linux/amd64"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pull --platform linux/amd64 img"}, pulls)
+
+	pulls = nil
+	var progress bytes.Buffer
+	err = pullImage(ctx, fakeCLI("linux/amd64", &pulls), "img", PullOptions{
+		Platform: "linux/amd64",
+		Policy:   PullPolicyAlways,
+		Progress: &progress,
+	})
+	require.NoError(t, err)
+	assert.Len(t, pulls, 1)
+	assert.Contains(t, progress.String(), "[1/1] a1b2c3d4e5f6: Pull complete")
+}
+
+func TestLayerProgress(t *testing.T) {
+	var out bytes.Buffer
+	p := newLayerProgress(&out, "img")
+
+	_, _ = p.Write([]byte("latest: Pulling from studio\naaaaaaaaaaaa: Pulling fs layer\nbbbbbbbbbbbb: Already exists\naaaa"))
+	_, _ = p.Write([]byte("aaaaaaaa: Pulling fs layer\naaaaaaaaaaaa: Download complete\n"))
+	_, _ = p.Write([]byte("aaaaaaaaaaaa: Pull complete\nStatus: Downloaded newer image"))
+	p.Flush()
+
+	assert.Equal(t, strings.Join([]string{
+		"   latest: Pulling from studio",
+		"   [0/1] aaaaaaaaaaaa: Pulling fs layer",
+		"   [1/2] bbbbbbbbbbbb: Already exists",
+		"   [1/2] aaaaaaaaaaaa: Download complete",
+		"   [2/2] aaaaaaaaaaaa: Pull complete",
+		"   Status: Downloaded newer image",
+	}, "\n")+"\n", out.String())
+}
@@ -0,0 +1,306 @@
+package studio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"k8s.io/klog/v2"
+)
+
+// Container runtimes `ggo libs inject` works with
+const (
+	InjectRuntimeDocker = "docker"
+	// InjectRuntimeContainerd is driven through nerdctl, which offers the
+	// docker commands (exec, cp, restart) for containerd
+	InjectRuntimeContainerd = "containerd"
+)
+
+// DefaultContainerdNamespace is the containerd namespace nerdctl uses by default
+const DefaultContainerdNamespace = "default"
+
+// Paths inside a container injected with the GPU client, matching those of
+// studio containers
+const (
+	containerLibsDir        = "/opt/gpugo/libs"
+	containerLogsDir        = "/var/log/tensor-fusion"
+	containerConnectionsDir = "/var/run/tensor-fusion/connections"
+	containerLDSoConf       = "/etc/ld.so.conf.d/zz_tensor-fusion.conf"
+	containerLDSoPreload    = "/etc/ld.so.preload"
+	containerProfileScript  = "/etc/profile.d/zz-tensor-fusion.sh"
+)
+
+// InjectTarget is an existing container, not created by ggo, to install the
+// GPU client libraries into
+type InjectTarget struct {
+	Runtime   string
+	Container string
+	// Namespace is the containerd namespace; ignored for docker
+	Namespace string
+	// DockerHost overrides DOCKER_HOST; ignored for containerd
+	DockerHost string
+}
+
+// InjectOptions describes what to install into the container
+type InjectOptions struct {
+	// LibsDir holds the Linux GPU client libraries for the container's arch,
+	// see EnsureContainerLibraries
+	LibsDir string
+	Vendor  GPUVendor
+	// ConnectionURL is the GPU worker the container connects to
+	ConnectionURL string
+	// Name names the connection file the GPU client writes; the container by
+	// default
+	Name string
+	// Tools maps GPU tools such as nvidia-smi to their host paths; they are
+	// copied to /usr/local/bin
+	Tools map[string]string
+	// Restart restarts the container so its main process loads the libraries
+	Restart bool
+}
+
+// InjectResult describes what was installed into a container
+type InjectResult struct {
+	Container string            `json:"container"`
+	Runtime   string            `json:"runtime"`
+	Libraries []string          `json:"libraries"`
+	Preload   []string          `json:"preload"`
+	Tools     []string          `json:"tools,omitempty"`
+	EnvVars   map[string]string `json:"env"`
+	Restarted bool              `json:"restarted"`
+}
+
+// Validate checks the runtime is supported and a container is given
+func (t *InjectTarget) Validate() error {
+	switch t.Runtime {
+	case InjectRuntimeDocker, InjectRuntimeContainerd:
+	default:
+		return fmt.Errorf("unsupported container runtime %q (use %s or %s)", t.Runtime, InjectRuntimeDocker, InjectRuntimeContainerd)
+	}
+	if t.Container == "" {
+		return fmt.Errorf("a container ID or name is required")
+	}
+	return nil
+}
+
+// command builds a docker-style command against the target's runtime
+func (t *InjectTarget) command(ctx context.Context, args ...string) *exec.Cmd {
+	if t.Runtime == InjectRuntimeContainerd {
+		namespace := t.Namespace
+		if namespace == "" {
+			namespace = DefaultContainerdNamespace
+		}
+		return exec.CommandContext(ctx, "nerdctl", append([]string{"--namespace", namespace}, args...)...)
+	}
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	if t.DockerHost != "" {
+		cmd.Env = append(os.Environ(), "DOCKER_HOST="+t.DockerHost)
+	}
+	return cmd
+}
+
+// run runs a runtime command, feeding it stdin when given
+func (t *InjectTarget) run(ctx context.Context, stdin []byte, args ...string) (string, error) {
+	cmd := t.command(ctx, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s %s: %w: %s", cmd.Args[0], args[0], err, msg)
+		}
+		return "", fmt.Errorf("%s %s: %w", cmd.Args[0], args[0], err)
+	}
+	return string(output), nil
+}
+
+// shell runs a shell script as root inside the container
+func (t *InjectTarget) shell(ctx context.Context, stdin []byte, script string) error {
+	args := []string{"exec", "-u", "0"}
+	if stdin != nil {
+		args = append(args, "-i")
+	}
+	args = append(args, t.Container, "sh", "-c", script)
+	_, err := t.run(ctx, stdin, args...)
+	return err
+}
+
+// Arch returns the CPU architecture of the running container, such as amd64
+func (t *InjectTarget) Arch(ctx context.Context) (string, error) {
+	output, err := t.run(ctx, nil, "exec", t.Container, "uname", "-m")
+	if err != nil {
+		return "", fmt.Errorf("failed to run a command in container %s, is it running? %w", t.Container, err)
+	}
+	arch := NormalizeArch(strings.TrimSpace(output))
+	if arch == "" {
+		return "", fmt.Errorf("failed to detect the architecture of container %s", t.Container)
+	}
+	return arch, nil
+}
+
+// EnsureContainerLibraries downloads the Linux GPU client libraries of
+// vendor for a container of arch and returns their directory
+func EnsureContainerLibraries(ctx context.Context, vendor GPUVendor, arch, lockfile string) (string, error) {
+	if err := ensureGPUClientLibraries(ctx, vendor, arch, lockfile); err != nil {
+		return "", err
+	}
+	return platform.DefaultPaths().LibsDirForPlatform("linux", arch), nil
+}
+
+// Inject installs the GPU client into the container: it copies the client
+// libraries and GPU tools, registers the libraries with the dynamic linker
+// and in /etc/ld.so.preload, and writes the connection settings to
+// /etc/environment and /etc/profile.d. Running it again replaces what an
+// earlier run wrote.
+func (t *InjectTarget) Inject(ctx context.Context, opts *InjectOptions) (*InjectResult, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	libs, err := listLibraries(opts.LibsDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(libs) == 0 {
+		return nil, fmt.Errorf("no GPU client libraries in %s", opts.LibsDir)
+	}
+
+	result := &InjectResult{
+		Container: t.Container,
+		Runtime:   t.Runtime,
+		Libraries: libs,
+		Preload:   injectPreload(opts.LibsDir, opts.Vendor),
+		EnvVars:   injectEnvVars(opts, t.Container),
+	}
+
+	if err := t.shell(ctx, nil, fmt.Sprintf("mkdir -p %s %s %s %s",
+		containerLibsDir, containerLogsDir, containerConnectionsDir, path.Dir(containerLDSoConf))); err != nil {
+		return nil, fmt.Errorf("failed to create directories in container: %w", err)
+	}
+	// A trailing /. copies the directory's contents
+	if _, err := t.run(ctx, nil, "cp", opts.LibsDir+string(filepath.Separator)+".", t.Container+":"+containerLibsDir); err != nil {
+		return nil, fmt.Errorf("failed to copy GPU client libraries: %w", err)
+	}
+	klog.Infof("Copied GPU client libraries into container: container=%s count=%d", t.Container, len(libs))
+
+	for _, tool := range SortedKeys(opts.Tools) {
+		if _, err := t.run(ctx, nil, "cp", opts.Tools[tool], t.Container+":/usr/local/bin/"+tool); err != nil {
+			klog.Warningf("Failed to copy GPU tool into container: container=%s tool=%s error=%v", t.Container, tool, err)
+			continue
+		}
+		result.Tools = append(result.Tools, tool)
+	}
+
+	if err := t.shell(ctx, []byte("# TensorFusion GPU libraries\n"+containerLibsDir+"\n"),
+		"cat > "+containerLDSoConf); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", containerLDSoConf, err)
+	}
+	if err := t.shell(ctx, []byte(linesOf(result.Preload)), replaceLinesScript(containerLDSoPreload, "^"+containerLibsDir+"/")); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", containerLDSoPreload, err)
+	}
+	environment, profile := injectEnvFiles(result.EnvVars)
+	if err := t.shell(ctx, []byte(environment), replaceLinesScript("/etc/environment", envKeysPattern(result.EnvVars))); err != nil {
+		return nil, fmt.Errorf("failed to write /etc/environment: %w", err)
+	}
+	if err := t.shell(ctx, []byte(profile), "mkdir -p /etc/profile.d && cat > "+containerProfileScript); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", containerProfileScript, err)
+	}
+	// Images without ldconfig still find the libraries through ld.so.preload
+	if err := t.shell(ctx, nil, "command -v ldconfig >/dev/null 2>&1 && ldconfig || true"); err != nil {
+		klog.Warningf("Failed to run ldconfig in container: container=%s error=%v", t.Container, err)
+	}
+
+	if opts.Restart {
+		if _, err := t.run(ctx, nil, "restart", t.Container); err != nil {
+			return result, fmt.Errorf("GPU client installed but the container failed to restart: %w", err)
+		}
+		result.Restarted = true
+	}
+	klog.Infof("Injected GPU client into container: container=%s runtime=%s vendor=%s restarted=%t",
+		t.Container, t.Runtime, opts.Vendor, result.Restarted)
+	return result, nil
+}
+
+// listLibraries returns the shared libraries in dir
+func listLibraries(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GPU client libraries: %w", err)
+	}
+	var libs []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.Contains(e.Name(), ".so") {
+			libs = append(libs, e.Name())
+		}
+	}
+	return libs, nil
+}
+
+// injectPreload returns the container paths of the libraries to preload
+func injectPreload(libsDir string, vendor GPUVendor) []string {
+	libs := FindActualLibraryFiles(libsDir, vendor)
+	preload := make([]string, 0, len(libs))
+	for _, lib := range libs {
+		preload = append(preload, containerLibsDir+"/"+lib)
+	}
+	return preload
+}
+
+// injectEnvVars returns the GPU client settings of an injected container
+func injectEnvVars(opts *InjectOptions, container string) map[string]string {
+	name := opts.Name
+	if name == "" {
+		name = container
+	}
+	name = platform.NormalizeName(name)
+	env := map[string]string{
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO": opts.ConnectionURL,
+		"TF_LOG_PATH":                            containerLogsDir + "/client.log",
+		"TF_LOG_LEVEL":                           getEnvDefault("TF_LOG_LEVEL", "info"),
+		"TF_ENABLE_LOG":                          getEnvDefault("TF_ENABLE_LOG", "1"),
+		"TF_CONNECTION_INFO_PATH":                containerConnectionsDir + "/" + name + ".txt",
+	}
+	if v := VisibleDevicesEnv(opts.Vendor); v != "" {
+		env[v] = "0"
+	}
+	return env
+}
+
+// injectEnvFiles renders env as /etc/environment lines, which PAM sessions
+// read, and as a profile script for login shells
+func injectEnvFiles(env map[string]string) (environment, profile string) {
+	var e, p strings.Builder
+	p.WriteString("# TensorFusion remote GPU, written by ggo libs inject\n")
+	for _, k := range SortedKeys(env) {
+		fmt.Fprintf(&e, "%s=%s\n", k, env[k])
+		fmt.Fprintf(&p, "export %s=%s\n", k, ShellQuote(env[k]))
+	}
+	return e.String(), p.String()
+}
+
+// envKeysPattern matches the /etc/environment lines setting any of env
+func envKeysPattern(env map[string]string) string {
+	return "^(" + strings.Join(SortedKeys(env), "|") + ")="
+}
+
+// replaceLinesScript replaces the lines of file matching pattern, an
+// extended regexp, with the script's standard input
+func replaceLinesScript(file, pattern string) string {
+	return fmt.Sprintf("touch %[1]s && { grep -Ev %[2]s %[1]s; cat; } > %[1]s.ggo && cat %[1]s.ggo > %[1]s && rm -f %[1]s.ggo",
+		file, ShellQuote(pattern))
+}
+
+func linesOf(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,91 @@
+package studio
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDockerCLI puts a docker on PATH that logs its arguments and input
+func fakeDockerCLI(t *testing.T) (logPath string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake docker is a shell script")
+	}
+	dir := t.TempDir()
+	logPath = filepath.Join(dir, "docker.log")
+	script := `#!/bin/sh
+echo "$*" >> "` + logPath + `"
+case "$*" in *"uname -m"*) echo aarch64 ;; esac
+if [ "$4" = "-i" ]; then cat >> "` + logPath + `"; fi
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func TestInjectTarget(t *testing.T) {
+	logPath := fakeDockerCLI(t)
+	libsDir := t.TempDir()
+	for _, lib := range []string{"libcuda.so", "libnvidia-ml.so", "libteleport.so"} {
+		require.NoError(t, os.WriteFile(filepath.Join(libsDir, lib), nil, 0644))
+	}
+	target := &InjectTarget{Runtime: InjectRuntimeDocker, Container: "my-app"}
+	ctx := context.Background()
+
+	arch, err := target.Arch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ArchArm64, arch)
+
+	result, err := target.Inject(ctx, &InjectOptions{
+		LibsDir:       libsDir,
+		Vendor:        VendorNvidia,
+		ConnectionURL: "native+10.0.0.5+9001+abc123",
+		Restart:       true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libcuda.so", "libnvidia-ml.so", "libteleport.so"}, result.Libraries)
+	assert.Equal(t, []string{"/opt/gpugo/libs/libcuda.so", "/opt/gpugo/libs/libnvidia-ml.so"}, result.Preload)
+	assert.Equal(t, "native+10.0.0.5+9001+abc123", result.EnvVars["TENSOR_FUSION_OPERATOR_CONNECTION_INFO"])
+	assert.Equal(t, "/var/run/tensor-fusion/connections/my-app.txt", result.EnvVars["TF_CONNECTION_INFO_PATH"])
+	assert.Equal(t, "0", result.EnvVars["CUDA_VISIBLE_DEVICES"])
+	assert.True(t, result.Restarted)
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	log := string(data)
+	assert.Contains(t, log, "cp "+libsDir+"/. my-app:/opt/gpugo/libs\n")
+	assert.Contains(t, log, "/opt/gpugo/libs/libcuda.so\n/opt/gpugo/libs/libnvidia-ml.so\n")
+	assert.Contains(t, log, "export TENSOR_FUSION_OPERATOR_CONNECTION_INFO='native+10.0.0.5+9001+abc123'\n")
+	assert.True(t, strings.HasSuffix(log, "restart my-app\n"), "restarts last")
+
+	_, err = (&InjectTarget{Runtime: "podman", Container: "x"}).Inject(ctx, &InjectOptions{LibsDir: libsDir})
+	assert.ErrorContains(t, err, "unsupported container runtime")
+}
+
+func TestReplaceLinesScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	file := filepath.Join(t.TempDir(), "environment")
+	require.NoError(t, os.WriteFile(file, []byte("LANG=C\nTF_LOG_LEVEL=debug\n"), 0644))
+
+	env := map[string]string{"TF_LOG_LEVEL": "info", "TENSOR_FUSION_OPERATOR_CONNECTION_INFO": "x"}
+	environment, _ := injectEnvFiles(env)
+	for range 2 {
+		cmd := exec.Command("sh", "-c", replaceLinesScript(file, envKeysPattern(env)))
+		cmd.Stdin = strings.NewReader(environment)
+		require.NoError(t, cmd.Run())
+	}
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "LANG=C\nTENSOR_FUSION_OPERATOR_CONNECTION_INFO=x\nTF_LOG_LEVEL=info\n", string(data), "earlier settings are replaced")
+}
@@ -17,6 +17,9 @@ import (
 
 	"github.com/NexusGPU/gpu-go/internal/errors"
 	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/progress"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -30,6 +33,8 @@ type Manager struct {
 	paths    *platform.Paths
 	backends map[Mode]Backend
 	mu       sync.RWMutex
+	// stateMu serializes updates of the state file by concurrent operations
+	stateMu sync.Mutex
 }
 
 // NewManager creates a new studio manager
@@ -91,7 +96,7 @@ func (m *Manager) detectBestBackend() (Backend, error) {
 			preferenceOrder = []Mode{ModeColima, ModeDocker, ModeKubernetes}
 		}
 	case OSLinux:
-		preferenceOrder = []Mode{ModeDocker, ModeColima, ModeKubernetes}
+		preferenceOrder = []Mode{ModeDocker, ModePodman, ModeColima, ModeKubernetes}
 	default:
 		preferenceOrder = []Mode{ModeDocker, ModeKubernetes}
 	}
@@ -127,7 +132,7 @@ func platformBackendHint(ctx context.Context, goos string) string {
 		if hint := linuxDockerPermissionHint(ctx); hint != "" {
 			return hint
 		}
-		return "Install and start Docker (https://docs.docker.com/get-docker/). If you get permission denied, add your user to the docker group: sudo usermod -aG docker $USER, then log out and back in."
+		return "Install and start Docker (https://docs.docker.com/get-docker/) or Podman (https://podman.io/docs/installation). If Docker reports permission denied, add your user to the docker group: sudo usermod -aG docker $USER, then log out and back in."
 	}
 	switch goos {
 	case "darwin":
@@ -212,15 +217,27 @@ func (m *Manager) Create(ctx context.Context, opts *CreateOptions) (*Environment
 	if err != nil {
 		return nil, err
 	}
+	recorded := *opts
 
-	env, err := backend.Create(ctx, opts)
-	if err != nil {
+	warnPlatformCapabilities(ctx, backend, opts.Platform)
+
+	if err := pullForCreate(ctx, backend, opts); err != nil {
+		return nil, err
+	}
+	if err := ensureNamedVolumes(ctx, backend, opts.Name, opts.Volumes); err != nil {
 		return nil, err
 	}
 
-	if err := m.waitForStableRunning(ctx, backend, env); err != nil {
+	progress.StepStarted(progress.StepContainer, "Creating container "+opts.Name)
+	env, err := backend.Create(ctx, opts)
+	if err == nil {
+		err = m.waitForStableRunning(ctx, backend, env)
+	}
+	if err != nil {
+		progress.Failed(progress.StepContainer, err)
 		return nil, err
 	}
+	progress.StepFinished(progress.StepContainer, "Container running")
 
 	// SSH server is now set up by setupSSHInContainer() during container creation
 	// No need to call EnsureSSHServer which would override the secure configuration
@@ -234,15 +251,68 @@ func (m *Manager) Create(ctx context.Context, opts *CreateOptions) (*Environment
 
 	m.clearUnreachableSSH(ctx, env)
 
-	// Save environment to local state
-	if err := m.saveEnvironment(env); err != nil {
-		// Log but don't fail
-		fmt.Fprintf(os.Stderr, "Warning: failed to save environment state: %v\n", err)
-	}
+	// Save environment to local state, with its options for 'ggo studio rebuild'
+	m.saveWithOptions(env, &recorded)
 
 	return env, nil
 }
 
+// Pull pulls image with the backend for mode ahead of any Create
+func (m *Manager) Pull(ctx context.Context, mode Mode, image string, opts PullOptions) error {
+	backend, err := m.GetBackend(mode)
+	if err != nil {
+		return err
+	}
+	puller, ok := backend.(ImagePuller)
+	if !ok {
+		return errors.Unavailable(fmt.Sprintf("backend %s does not support pulling images", backend.Name()))
+	}
+	return puller.PullImage(ctx, image, opts)
+}
+
+// warnPlatformCapabilities warns up-front when the requested platform will
+// be emulated or cannot run on the backend
+func warnPlatformCapabilities(ctx context.Context, backend Backend, platform string) {
+	if platform == "" {
+		return
+	}
+	if _, ok := backend.(CapabilityProber); !ok {
+		return
+	}
+	report := ProbeCapabilities(ctx, backend)
+	if report.Error != "" {
+		klog.V(2).Infof("Skipping capability checks: backend=%s error=%s", backend.Name(), report.Error)
+		return
+	}
+	for _, w := range report.PlatformWarnings(platform) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+}
+
+// pullForCreate is the pull step shared by all backends that support it;
+// others pull implicitly when the container starts
+func pullForCreate(ctx context.Context, backend Backend, opts *CreateOptions) error {
+	puller, ok := backend.(ImagePuller)
+	if !ok {
+		if opts.PullPolicy != "" && opts.PullPolicy != PullPolicyMissing {
+			fmt.Fprintf(os.Stderr, "Warning: backend %s does not support pull policies, ignoring pull=%s\n", backend.Name(), opts.PullPolicy)
+		}
+		return nil
+	}
+	image := opts.Image
+	if image == "" {
+		image = DefaultImageStudioTorch
+	}
+	if err := puller.PullImage(ctx, image, PullOptions{
+		Platform: opts.Platform,
+		Policy:   opts.PullPolicy,
+		Progress: os.Stderr,
+	}); err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	return nil
+}
+
 // Get gets an environment by ID or name
 func (m *Manager) Get(ctx context.Context, idOrName string) (*Environment, error) {
 	m.mu.RLock()
@@ -320,6 +390,8 @@ func (m *Manager) List(ctx context.Context) ([]*Environment, error) {
 		}
 
 		envCopy := cloneEnvironment(env)
+		// Options may hold secrets passed as env vars; they stay in the state file
+		envCopy.CreateOptions = nil
 		if _, offline := offlineModes[env.Mode]; offline {
 			envCopy.Status = StatusUnknown
 		} else if _, ok := runtimeIDs[env.Mode]; ok {
@@ -378,6 +450,51 @@ func (m *Manager) Start(ctx context.Context, idOrName string) error {
 	return backend.Start(ctx, env.ID)
 }
 
+// Resize changes the resources, ports or volumes of an environment and
+// returns it as it is after the change
+func (m *Manager) Resize(ctx context.Context, idOrName string, opts *ResizeOptions) (*Environment, error) {
+	if opts.IsEmpty() {
+		return nil, fmt.Errorf("nothing to change: specify resources, ports or volumes")
+	}
+	env, err := m.Get(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := m.GetBackend(env.Mode)
+	if err != nil {
+		return nil, err
+	}
+	resizer, ok := backend.(ResizableBackend)
+	if !ok {
+		return nil, fmt.Errorf("%s backend cannot resize environments in place; recreate the environment with the new settings", backend.Name())
+	}
+	if err := ensureNamedVolumes(ctx, backend, env.Name, opts.AddVolumes); err != nil {
+		return nil, err
+	}
+
+	newID, err := resizer.Resize(ctx, env.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resized, err := backend.Get(ctx, newID)
+	if err != nil {
+		return nil, fmt.Errorf("environment resized but could not be reloaded: %w", err)
+	}
+	var recorded *CreateOptions
+	if stored, err := m.getFromState(env.ID); err == nil && stored.CreateOptions != nil {
+		updated := *stored.CreateOptions
+		opts.applyTo(&updated)
+		recorded = &updated
+	}
+	if newID != env.ID {
+		_ = m.removeEnvironment(env.ID)
+	}
+	m.saveWithOptions(resized, recorded)
+	return resized, nil
+}
+
 // Remove removes an environment
 func (m *Manager) Remove(ctx context.Context, idOrName string) error {
 	env, err := m.Get(ctx, idOrName)
@@ -410,17 +527,16 @@ func (m *Manager) Remove(ctx context.Context, idOrName string) error {
 	return m.removeEnvironment(env.ID)
 }
 
-// RemoveAll removes all known environments. When runtimes are offline, stale state entries are still cleaned up.
-func (m *Manager) RemoveAll(ctx context.Context) ([]string, error) {
+// RemoveAll removes all known environments, batch.Concurrency at once. When
+// runtimes are offline, stale state entries are still cleaned up.
+func (m *Manager) RemoveAll(ctx context.Context, batch *BatchOptions) ([]string, error) {
 	envs, err := m.List(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	seen := make(map[string]struct{}, len(envs))
-	removed := make([]string, 0, len(envs))
-	failed := make([]string, 0)
-
+	unique := make([]*Environment, 0, len(envs))
 	for _, env := range envs {
 		if env == nil || env.ID == "" {
 			continue
@@ -429,28 +545,37 @@ func (m *Manager) RemoveAll(ctx context.Context) ([]string, error) {
 			continue
 		}
 		seen[env.ID] = struct{}{}
+		unique = append(unique, env)
+	}
 
+	names := make([]string, len(unique))
+	for i, env := range unique {
+		names[i] = env.Name
+	}
+	removed := make([]bool, len(unique))
+	err = runBatch(ctx, "remove", names, batch, func(ctx context.Context, i int) error {
+		env := unique[i]
 		switch env.Status {
 		case StatusUnknown, StatusDeleted:
 			if err := m.removeEnvironment(env.ID); err != nil {
-				failed = append(failed, fmt.Sprintf("%s (%v)", env.Name, err))
-				continue
+				return err
 			}
-			removed = append(removed, env.Name)
 		default:
 			if err := m.Remove(ctx, env.ID); err != nil {
-				failed = append(failed, fmt.Sprintf("%s (%v)", env.Name, err))
-				continue
+				return err
 			}
-			removed = append(removed, env.Name)
 		}
-	}
+		removed[i] = true
+		return nil
+	})
 
-	if len(failed) > 0 {
-		return removed, fmt.Errorf("failed to remove %d environment(s): %s", len(failed), strings.Join(failed, "; "))
+	removedNames := make([]string, 0, len(unique))
+	for i, name := range names {
+		if removed[i] {
+			removedNames = append(removedNames, name)
+		}
 	}
-
-	return removed, nil
+	return removedNames, err
 }
 
 // AddSSHConfig adds an SSH config entry for an environment
@@ -467,25 +592,21 @@ func (m *Manager) AddSSHConfig(env *Environment) error {
 		existingConfig = string(data)
 	}
 
-	// Get home directory for private key path
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return errors.Wrap(err, "failed to get user home directory")
-	}
-	privateKeyPath := filepath.Join(homeDir, ".ggo", "ssh", "id_ed25519")
-
 	// Generate new entry
 	hostName := fmt.Sprintf("ggo-%s", env.Name)
+	identity := ""
+	if keyPath := studioIdentityFile(m.paths, env.Name); keyPath != "" {
+		identity = "    IdentityFile " + keyPath + "\n"
+	}
 	entry := fmt.Sprintf(`
 # GPU Go Studio Environment: %s
 Host %s
     HostName %s
     Port %d
     User %s
-    IdentityFile %s
-    StrictHostKeyChecking no
+%s    StrictHostKeyChecking no
     UserKnownHostsFile /dev/null
-`, env.Name, hostName, env.SSHHost, env.SSHPort, env.SSHUser, privateKeyPath)
+`, env.Name, hostName, env.SSHHost, env.SSHPort, env.SSHUser, identity)
 
 	// Keep a single entry per studio host by removing any existing one first.
 	existingConfig = m.removeSSHConfigEntry(existingConfig, hostName)
@@ -506,8 +627,10 @@ Host %s
 	return nil
 }
 
-// RemoveSSHConfig removes an SSH config entry for an environment
+// RemoveSSHConfig removes the SSH config entry and SSH key pair of an
+// environment
 func (m *Manager) RemoveSSHConfig(envName string) error {
+	m.removeSSHKey(envName)
 	sshConfigPath := m.getSSHConfigPath()
 
 	data, err := os.ReadFile(sshConfigPath)
@@ -706,11 +829,8 @@ func (m *Manager) saveState(state map[string]*Environment) error {
 		return err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(m.getStatePath()), 0755); err != nil {
-		return err
-	}
-
-	return os.WriteFile(m.getStatePath(), data, 0644)
+	// Written atomically, so concurrent readers never see a partial file
+	return utils.AtomicWriteFile(m.getStatePath(), data, 0644)
 }
 
 // getFromState looks up an environment by ID or name from local state only.
@@ -736,6 +856,8 @@ func (m *Manager) getFromState(idOrName string) (*Environment, error) {
 }
 
 func (m *Manager) saveEnvironment(env *Environment) error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 	state, err := m.loadState()
 	if err != nil {
 		state = make(map[string]*Environment)
@@ -746,6 +868,8 @@ func (m *Manager) saveEnvironment(env *Environment) error {
 }
 
 func (m *Manager) removeEnvironment(id string) error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 	state, err := m.loadState()
 	if err != nil {
 		return nil
@@ -52,7 +52,7 @@ var _ = Describe("Manager RemoveAll", func() {
 			stateEnvOffline.ID: stateEnvOffline,
 		})).To(Succeed())
 
-		removed, err := mgr.RemoveAll(context.Background())
+		removed, err := mgr.RemoveAll(context.Background(), nil)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(removed).To(ConsistOf("runtime", "offline"))
 
@@ -1,8 +1,11 @@
 package studio
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -84,3 +87,80 @@ func TestManager_AddSSHConfigRejectsMissingSSHMetadata(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "does not have SSH configured")
 }
+
+func TestManager_AddSSHConfigUsesStudioKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	m := NewManager()
+	publicKey, keyPath, err := GetOrCreateStudioSSHKey(m.paths, "alpha")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(publicKey, "ssh-ed25519 "))
+	assert.Equal(t, filepath.Join(home, ".gpugo", "studio", "alpha", "keys", "id_ed25519"), keyPath)
+
+	again, _, err := GetOrCreateStudioSSHKey(m.paths, "alpha")
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, again, "the key pair is reused")
+
+	require.NoError(t, m.AddSSHConfig(&Environment{Name: "alpha", SSHHost: "127.0.0.1", SSHPort: 2201, SSHUser: "root"}))
+	require.NoError(t, m.AddSSHConfig(&Environment{Name: "beta", SSHHost: "127.0.0.1", SSHPort: 2202, SSHUser: "root"}))
+	configData, err := os.ReadFile(filepath.Join(home, ".ssh", "config"))
+	require.NoError(t, err)
+	config := string(configData)
+	assert.Contains(t, config, "    User root\n    IdentityFile "+keyPath+"\n")
+	assert.Equal(t, 1, strings.Count(config, "IdentityFile"), "a studio without a key pair gets no IdentityFile")
+
+	require.NoError(t, m.RemoveSSHConfig("alpha"))
+	assert.NoFileExists(t, keyPath, "the key pair goes with the studio")
+}
+
+func TestManager_RotateSSHKey(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	m := NewManager()
+	oldKey, keyPath, err := GetOrCreateStudioSSHKey(m.paths, "my-env")
+	require.NoError(t, err)
+
+	authorized := "ssh-rsa AAAA other\n" + oldKey + "\n"
+	backend := &MockBackend{
+		mode:      ModeDocker,
+		available: true,
+		envs: map[string]*Environment{
+			"env-1": {ID: "env-1", Name: "my-env", Mode: ModeDocker, Status: StatusStopped},
+		},
+	}
+	backend.execFunc = func(ctx context.Context, envID string, cmd []string) ([]byte, error) {
+		require.Equal(t, StatusRunning, backend.envs[envID].Status, "exec needs a running container")
+		require.Len(t, cmd, 6)
+		// Run the script against a local authorized_keys
+		file := filepath.Join(t.TempDir(), "authorized_keys")
+		require.NoError(t, os.WriteFile(file, []byte(authorized), 0o600))
+		script := strings.ReplaceAll(cmd[2], "/root/.ssh/authorized_keys", file)
+		script = strings.ReplaceAll(script, "mkdir -p /root/.ssh && chmod 700 /root/.ssh", "true")
+		output, err := exec.Command("sh", "-c", script, "sh", cmd[4], cmd[5]).CombinedOutput()
+		require.NoError(t, err, string(output))
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		authorized = string(data)
+		return nil, nil
+	}
+	m.RegisterBackend(backend)
+	m.saveWithOptions(backend.envs["env-1"], &CreateOptions{Name: "my-env", SSHPublicKey: oldKey})
+
+	_, err = m.RotateSSHKey(context.Background(), "my-env")
+	require.NoError(t, err)
+
+	newKey := readPublicKey(keyPath + ".pub")
+	assert.NotEqual(t, oldKey, newKey)
+	assert.Equal(t, "ssh-rsa AAAA other\n"+newKey+"\n", authorized, "the old key is replaced, others are kept")
+	assert.NoFileExists(t, keyPath+".new")
+	assert.Equal(t, StatusStopped, backend.envs["env-1"].Status, "a stopped studio is stopped again")
+
+	stored, err := m.getFromState("env-1")
+	require.NoError(t, err)
+	assert.Equal(t, newKey, stored.CreateOptions.SSHPublicKey, "rebuilds authorize the new key")
+}
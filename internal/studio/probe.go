@@ -0,0 +1,236 @@
+package studio
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+// probeTimeout bounds the readiness probe of one studio
+const probeTimeout = 45 * time.Second
+
+// Readiness probe check names
+const (
+	ProbeCheckLibraries  = "libraries"
+	ProbeCheckConnection = "connection"
+	ProbeCheckDevices    = "devices"
+)
+
+// Readiness probe check states
+const (
+	ProbePass = "pass"
+	ProbeFail = "fail"
+	ProbeSkip = "skip"
+)
+
+// ProbeCheck is the outcome of one readiness check. Hint suggests how to
+// fix a failed check.
+type ProbeCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// ProbeResult is the outcome of a studio's GPU readiness probe. Passed is
+// false if any check failed; skipped checks do not count.
+type ProbeResult struct {
+	Passed bool         `json:"passed"`
+	Checks []ProbeCheck `json:"checks"`
+	// Error tells why the probe could not run at all
+	Error string `json:"error,omitempty"`
+}
+
+// probeScript validates the remote GPU setup from inside the container, in
+// the environment SSH sessions get from /etc/environment: the preloaded GPU
+// client libraries exist and load, the GPU worker at $1:$2 accepts a TCP
+// connection, and the device listing tool $3 (run with args $4) works. It
+// prints key=value lines for parseProbeOutput.
+const probeScript = `[ -r /etc/environment ] && . /etc/environment
+with_timeout() { s=$1; shift; if command -v timeout >/dev/null 2>&1; then timeout "$s" "$@"; else "$@"; fi; }
+echo "preload=$LD_PRELOAD"
+missing=""
+for lib in $(echo "$LD_PRELOAD" | tr ':' ' '); do [ -r "$lib" ] || missing="$missing $lib"; done
+echo "preload_missing=$missing"
+if [ -n "$LD_PRELOAD" ] && [ -z "$missing" ]; then
+  echo "preload_error=$( (set -a; . /etc/environment; exec /bin/true) 2>&1 | head -n 1)"
+fi
+if [ -n "$1" ]; then
+  if command -v bash >/dev/null 2>&1; then
+    with_timeout 5 bash -c 'exec 3<>"/dev/tcp/$0/$1"' "$1" "$2" 2>/dev/null && echo connect=ok || echo connect=failed
+  elif command -v python3 >/dev/null 2>&1; then
+    python3 -c 'import socket,sys; socket.create_connection((sys.argv[1], int(sys.argv[2])), 5)' "$1" "$2" 2>/dev/null && echo connect=ok || echo connect=failed
+  elif command -v nc >/dev/null 2>&1; then
+    nc -z -w 5 "$1" "$2" 2>/dev/null && echo connect=ok || echo connect=failed
+  else
+    echo connect=unavailable
+  fi
+fi
+if [ -n "$3" ]; then
+  if command -v "$3" >/dev/null 2>&1; then
+    out=$( (set -a; [ -r /etc/environment ] && . /etc/environment; with_timeout 20 "$3" $4) 2>&1)
+    echo "devices_rc=$?"
+    echo "$out" | head -n 16 | sed 's/^/devices_out=/'
+  else
+    echo devices=missing
+  fi
+fi`
+
+// probeDeviceTools are the device listing tools checked per vendor
+var probeDeviceTools = map[GPUVendor][]string{
+	VendorNvidia: {"nvidia-smi", "-L"},
+}
+
+// Verify runs the GPU readiness probe in a studio
+func (m *Manager) Verify(ctx context.Context, idOrName string) (*ProbeResult, error) {
+	env, err := m.Get(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if workerURL, _ := m.probeTarget(env); workerURL == "" {
+		return nil, fmt.Errorf("studio %s has no remote GPU to verify", env.Name)
+	}
+	if env.Status != StatusRunning {
+		return nil, fmt.Errorf("studio %s is %s; start it with 'ggo studio start %s'", env.Name, env.Status, env.Name)
+	}
+	return m.Probe(ctx, env), nil
+}
+
+// probeTarget returns the GPU worker connection URL and vendor of a studio,
+// from local state when the backend does not report them
+func (m *Manager) probeTarget(env *Environment) (string, GPUVendor) {
+	workerURL, vendor := env.GPUWorkerURL, VendorUnknown
+	if stored, err := m.getFromState(env.ID); err == nil {
+		if workerURL == "" {
+			workerURL = stored.GPUWorkerURL
+		}
+		if opts := stored.CreateOptions; opts != nil {
+			vendor = ParseVendor(opts.HardwareVendor)
+			if workerURL == "" {
+				workerURL = cmp.Or(opts.Endpoint, opts.GPUWorkerURL)
+			}
+		}
+	}
+	return workerURL, vendor
+}
+
+// Probe validates the remote GPU environment of a running studio from
+// inside its container. Failures are reported in the result, not as errors.
+func (m *Manager) Probe(ctx context.Context, env *Environment) *ProbeResult {
+	backend, err := m.GetBackend(env.Mode)
+	if err != nil {
+		return &ProbeResult{Error: err.Error()}
+	}
+
+	workerURL, vendor := m.probeTarget(env)
+	var host, port string
+	addr, err := utils.ConnectionAddr(workerURL)
+	if err == nil {
+		host, port, _ = net.SplitHostPort(addr)
+	}
+	tool := probeDeviceTools[vendor]
+	toolName, toolArgs := "", ""
+	if len(tool) > 0 {
+		toolName, toolArgs = tool[0], strings.Join(tool[1:], " ")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	output, err := backend.Exec(ctx, env.ID, []string{"sh", "-c", probeScript, "sh", host, port, toolName, toolArgs})
+	if err != nil {
+		klog.Warningf("Studio readiness probe failed to run: name=%s error=%v output=%s", env.Name, err, strings.TrimSpace(string(output)))
+		return &ProbeResult{Error: fmt.Sprintf("failed to run probe in container: %v", err)}
+	}
+
+	result := parseProbeOutput(string(output), env.Name, addr, toolName, vendor)
+	klog.Infof("Studio readiness probe finished: name=%s passed=%v", env.Name, result.Passed)
+	return result
+}
+
+// parseProbeOutput turns the key=value output of probeScript into checks.
+// addr is the GPU worker address, empty if the connection URL has none;
+// tool is the device listing tool, empty if the vendor has none.
+func parseProbeOutput(output, name, addr, tool string, vendor GPUVendor) *ProbeResult {
+	values := make(map[string]string)
+	var deviceLines []string
+	for _, line := range strings.Split(output, "\n") {
+		k, v, ok := strings.Cut(strings.TrimRight(line, "\r"), "=")
+		if !ok {
+			continue
+		}
+		if k == "devices_out" {
+			if v = strings.TrimSpace(v); v != "" {
+				deviceLines = append(deviceLines, v)
+			}
+			continue
+		}
+		values[k] = strings.TrimSpace(v)
+	}
+
+	libs := ProbeCheck{Name: ProbeCheckLibraries, Status: ProbePass}
+	switch {
+	case values["preload"] == "":
+		libs.Status, libs.Detail = ProbeFail, "LD_PRELOAD is not set in /etc/environment"
+		libs.Hint = fmt.Sprintf("Recreate the GPU environment with 'ggo studio rebuild %s'", name)
+	case values["preload_missing"] != "":
+		libs.Status, libs.Detail = ProbeFail, "missing "+values["preload_missing"]
+		libs.Hint = fmt.Sprintf("Download the GPU client libraries with 'ggo deps download' and run 'ggo studio rebuild %s'", name)
+	case values["preload_error"] != "":
+		libs.Status, libs.Detail = ProbeFail, values["preload_error"]
+		libs.Hint = "The libraries do not fit the image; recreate the studio with --platform matching the image architecture"
+	default:
+		libs.Detail = fmt.Sprintf("%d preloaded", len(strings.Split(values["preload"], ":")))
+	}
+
+	conn := ProbeCheck{Name: ProbeCheckConnection, Detail: addr}
+	switch values["connect"] {
+	case "ok":
+		conn.Status = ProbePass
+	case "failed":
+		conn.Status = ProbeFail
+		conn.Hint = "Check that the GPU worker is running and that the container network can reach it; 'ggo studio stats' tests the connection from this machine"
+	default:
+		conn.Status = ProbeSkip
+		if addr == "" {
+			conn.Detail = "connection URL has no address"
+		} else {
+			conn.Detail = "no bash, python3 or nc in the image to test with"
+		}
+	}
+
+	devices := ProbeCheck{Name: ProbeCheckDevices}
+	switch {
+	case tool == "":
+		devices.Status, devices.Detail = ProbeSkip, fmt.Sprintf("no device listing tool for %s", vendor)
+	case values["devices"] == "missing":
+		devices.Status, devices.Detail = ProbeFail, tool+" not found"
+		devices.Hint = fmt.Sprintf("Run 'ggo studio rebuild %s' to mount %s", name, tool)
+	case values["devices_rc"] != "0" || len(deviceLines) == 0:
+		devices.Status = ProbeFail
+		devices.Detail = tool + " listed no devices"
+		if values["devices_rc"] != "0" {
+			devices.Detail = fmt.Sprintf("%s exited with status %s", tool, values["devices_rc"])
+			if len(deviceLines) > 0 {
+				devices.Detail += ": " + deviceLines[0]
+			}
+		}
+		devices.Hint = "The GPU worker did not list its devices; check that the share link is still valid and the worker is healthy"
+	default:
+		devices.Status = ProbePass
+		devices.Detail = fmt.Sprintf("%d device(s)", len(deviceLines))
+	}
+
+	result := &ProbeResult{Passed: true, Checks: []ProbeCheck{libs, conn, devices}}
+	for _, c := range result.Checks {
+		if c.Status == ProbeFail {
+			result.Passed = false
+		}
+	}
+	return result
+}
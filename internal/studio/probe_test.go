@@ -0,0 +1,72 @@
+package studio
+
+import (
+	"net"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProbeOutput(t *testing.T) {
+	healthy := "preload=/opt/gpugo/libs/libcuda.so:/opt/gpugo/libs/libnvidia-ml.so\npreload_missing=\npreload_error=\n" +
+		"connect=ok\ndevices_rc=0\ndevices_out=GPU 0: NVIDIA A100 (UUID: GPU-1)\ndevices_out=GPU 1: NVIDIA A100 (UUID: GPU-2)\n"
+	result := parseProbeOutput(healthy, "dev", "10.0.0.1:9001", "nvidia-smi", VendorNvidia)
+	assert.True(t, result.Passed)
+	require.Len(t, result.Checks, 3)
+	assert.Equal(t, ProbeCheck{Name: ProbeCheckLibraries, Status: ProbePass, Detail: "2 preloaded"}, result.Checks[0])
+	assert.Equal(t, ProbePass, result.Checks[1].Status)
+	assert.Equal(t, "2 device(s)", result.Checks[2].Detail)
+
+	broken := "preload=/opt/gpugo/libs/libcuda.so\npreload_missing= /opt/gpugo/libs/libcuda.so\nconnect=failed\n" +
+		"devices_rc=6\ndevices_out=Failed to initialize NVML: Unknown Error\n"
+	result = parseProbeOutput(broken, "dev", "10.0.0.1:9001", "nvidia-smi", VendorNvidia)
+	assert.False(t, result.Passed)
+	for _, c := range result.Checks {
+		assert.Equal(t, ProbeFail, c.Status, c.Name)
+		assert.NotEmpty(t, c.Hint, c.Name)
+	}
+	assert.Contains(t, result.Checks[0].Hint, "ggo studio rebuild dev")
+	assert.Equal(t, "nvidia-smi exited with status 6: Failed to initialize NVML: Unknown Error", result.Checks[2].Detail)
+
+	result = parseProbeOutput("preload=/lib/a.so\npreload_missing=\npreload_error=\nconnect=unavailable\n", "dev", "10.0.0.1:9001", "", VendorAMD)
+	assert.True(t, result.Passed, "skipped checks do not fail the probe")
+	assert.Equal(t, ProbeSkip, result.Checks[1].Status)
+	assert.Equal(t, ProbeSkip, result.Checks[2].Status)
+}
+
+// TestProbeScript runs the probe script against a local listener, with echo
+// standing in for the device listing tool
+func TestProbeScript(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil || runtime.GOOS == "windows" {
+		t.Skip("sh is not available")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = c.Close()
+		}
+	}()
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+
+	output, err := exec.Command(sh, "-c", probeScript, "sh", host, port, "echo", "GPU 0: test").CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	result := parseProbeOutput(string(output), "dev", ln.Addr().String(), "echo", VendorNvidia)
+	require.Len(t, result.Checks, 3)
+	if !strings.Contains(string(output), "connect=unavailable") {
+		assert.Equal(t, ProbePass, result.Checks[1].Status, string(output))
+	}
+	assert.Equal(t, ProbeCheck{Name: ProbeCheckDevices, Status: ProbePass, Detail: "1 device(s)"}, result.Checks[2])
+}
@@ -0,0 +1,167 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/klog/v2"
+)
+
+// RebuildOptions describes changes applied when an environment is rebuilt.
+// Zero values keep the options the environment was created with.
+type RebuildOptions struct {
+	// Image replaces the environment's image
+	Image string `json:"image,omitempty"`
+	// PullPolicy controls whether the image is pulled before the rebuild
+	PullPolicy PullPolicy `json:"pull_policy,omitempty"`
+}
+
+// VolumeBackend is an optional interface for backends that can list the
+// volumes of an environment, so a rebuilt environment gets them back
+type VolumeBackend interface {
+	Backend
+	// Volumes returns the named and anonymous volumes mounted in the
+	// environment, by volume name
+	Volumes(ctx context.Context, envID string) ([]VolumeMount, error)
+}
+
+// Rebuild replaces an environment's container with a fresh one created with
+// the options the environment was created with, optionally from a new image.
+// Named and anonymous volumes are reattached at the same paths, so data kept
+// on volumes and bind mounts survives; the rest of the container filesystem
+// is discarded. The old container is only removed once the new one runs, and
+// is started again if the rebuild fails.
+func (m *Manager) Rebuild(ctx context.Context, idOrName string, opts *RebuildOptions) (*Environment, error) {
+	env, err := m.Get(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := m.getFromState(env.ID)
+	if err != nil || stored.CreateOptions == nil {
+		return nil, fmt.Errorf("environment %s was created by an older ggo that did not record its options; remove and create it again", env.Name)
+	}
+
+	backend, err := m.GetBackend(env.Mode)
+	if err != nil {
+		return nil, err
+	}
+	volumeBackend, ok := backend.(VolumeBackend)
+	if !ok {
+		return nil, fmt.Errorf("%s backend cannot rebuild environments; remove and create the environment again", backend.Name())
+	}
+
+	createOpts := *stored.CreateOptions
+	if opts.Image != "" {
+		createOpts.Image = opts.Image
+	}
+	createOpts.PullPolicy = opts.PullPolicy
+
+	volumes, err := volumeBackend.Volumes(ctx, env.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	createOpts.Volumes = applyVolumeChanges(createOpts.Volumes, volumes, nil)
+	// Record the volumes before touching the container, so the data can be
+	// found again should the rebuild be interrupted
+	m.saveWithOptions(stored, &createOpts)
+
+	if err := pullForCreate(ctx, backend, &createOpts); err != nil {
+		return nil, err
+	}
+
+	// The old container must release its ports for the new one
+	wasRunning := env.Status == StatusRunning
+	if wasRunning {
+		if err := backend.Stop(ctx, env.ID); err != nil {
+			return nil, fmt.Errorf("failed to stop environment: %w", err)
+		}
+	}
+	restore := func() {
+		if !wasRunning {
+			return
+		}
+		if err := backend.Start(ctx, env.ID); err != nil {
+			klog.Errorf("Failed to restart original container of %s: id=%s error=%v", env.Name, env.ID, err)
+		}
+	}
+
+	rebuilt, err := backend.Create(ctx, &createOpts)
+	if err != nil {
+		restore()
+		return nil, fmt.Errorf("failed to create new container, original container kept: %w", err)
+	}
+	if err := m.waitForStableRunning(ctx, backend, rebuilt); err != nil {
+		if rmErr := backend.Remove(ctx, rebuilt.ID); rmErr != nil {
+			klog.Warningf("Failed to remove new container %s: %v", rebuilt.ID, rmErr)
+		}
+		restore()
+		return nil, fmt.Errorf("new container did not start, original container kept: %w", err)
+	}
+	m.clearUnreachableSSH(ctx, rebuilt)
+
+	// Removing the container keeps its volumes, which the new one now uses
+	if err := backend.Remove(ctx, env.ID); err != nil {
+		klog.Warningf("Failed to remove original container of %s: id=%s error=%v", env.Name, env.ID, err)
+	}
+	_ = m.removeEnvironment(env.ID)
+	m.saveWithOptions(rebuilt, &createOpts)
+	klog.Infof("Rebuilt environment %s: old_id=%s new_id=%s image=%s volumes=%d", env.Name, env.ID, rebuilt.ID, createOpts.Image, len(volumes))
+
+	return rebuilt, nil
+}
+
+// saveWithOptions saves env to local state along with the options needed to
+// create it again. The options are kept out of env itself, which is shown to
+// the user.
+func (m *Manager) saveWithOptions(env *Environment, opts *CreateOptions) {
+	saved := cloneEnvironment(env)
+	saved.CreateOptions = opts
+	if err := m.saveEnvironment(saved); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save environment state: %v\n", err)
+	}
+}
+
+// dockerVolumes lists the named and anonymous volumes of a container
+func dockerVolumes(ctx context.Context, run dockerRunner, envID string) ([]VolumeMount, error) {
+	output, err := run(ctx, "inspect", "--type", "container", envID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w, output: %s", err, output)
+	}
+	spec, err := parseDockerInspect(output)
+	if err != nil {
+		return nil, err
+	}
+	var volumes []VolumeMount
+	for _, m := range spec.Mounts {
+		if m.Type == "volume" {
+			volumes = append(volumes, VolumeMount{HostPath: m.Name, ContainerPath: m.Destination, ReadOnly: !m.RW})
+		}
+	}
+	return volumes, nil
+}
+
+// Volumes implements VolumeBackend
+func (b *DockerBackend) Volumes(ctx context.Context, envID string) ([]VolumeMount, error) {
+	return dockerVolumes(ctx, b.runDocker, envID)
+}
+
+// Volumes implements VolumeBackend
+func (b *ColimaBackend) Volumes(ctx context.Context, envID string) ([]VolumeMount, error) {
+	return dockerVolumes(ctx, b.runDocker, envID)
+}
+
+// Volumes implements VolumeBackend
+func (b *WSLBackend) Volumes(ctx context.Context, envID string) ([]VolumeMount, error) {
+	run, err := b.dockerRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dockerVolumes(ctx, run, envID)
+}
+
+var (
+	_ VolumeBackend = (*DockerBackend)(nil)
+	_ VolumeBackend = (*ColimaBackend)(nil)
+	_ VolumeBackend = (*WSLBackend)(nil)
+)
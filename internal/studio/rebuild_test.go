@@ -0,0 +1,111 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// volumeMockBackend is a MockBackend whose environments have volumes
+type volumeMockBackend struct {
+	*MockBackend
+	volumes map[string][]VolumeMount
+}
+
+func (b *volumeMockBackend) Volumes(ctx context.Context, envID string) ([]VolumeMount, error) {
+	return b.volumes[envID], nil
+}
+
+func newRebuildManager(t *testing.T) (*Manager, *volumeMockBackend, *[]CreateOptions) {
+	oldStable := createStabilityWindow
+	createStabilityWindow = 0
+	t.Cleanup(func() { createStabilityWindow = oldStable })
+
+	var created []CreateOptions
+	mock := &MockBackend{mode: ModeDocker, available: true, envs: map[string]*Environment{}}
+	mock.createFunc = func(ctx context.Context, opts *CreateOptions) (*Environment, error) {
+		created = append(created, *opts)
+		env := &Environment{
+			ID:     fmt.Sprintf("env-%d", len(created)),
+			Name:   opts.Name,
+			Mode:   ModeDocker,
+			Image:  opts.Image,
+			Status: StatusRunning,
+		}
+		mock.envs[env.ID] = env
+		return env, nil
+	}
+	backend := &volumeMockBackend{MockBackend: mock, volumes: map[string][]VolumeMount{}}
+
+	m := &Manager{
+		paths:    platform.DefaultPaths().WithConfigDir(t.TempDir()),
+		backends: make(map[Mode]Backend),
+	}
+	m.RegisterBackend(backend)
+	return m, backend, &created
+}
+
+func TestManager_Rebuild(t *testing.T) {
+	m, backend, created := newRebuildManager(t)
+	ctx := context.Background()
+
+	_, err := m.Create(ctx, &CreateOptions{
+		Name:    "my-env",
+		Mode:    ModeDocker,
+		Image:   "studio:1",
+		Envs:    map[string]string{"FOO": "bar"},
+		Volumes: []VolumeMount{{HostPath: "/home/me/code", ContainerPath: "/code"}},
+	})
+	require.NoError(t, err)
+	backend.volumes["env-1"] = []VolumeMount{{HostPath: "3f9a0c", ContainerPath: "/data"}}
+
+	rebuilt, err := m.Rebuild(ctx, "my-env", &RebuildOptions{Image: "studio:2"})
+	require.NoError(t, err)
+	assert.Equal(t, "env-2", rebuilt.ID)
+	assert.Nil(t, rebuilt.CreateOptions, "options stay out of the environment shown to the user")
+	assert.NotContains(t, backend.envs, "env-1")
+
+	require.Len(t, *created, 2)
+	opts := (*created)[1]
+	assert.Equal(t, "studio:2", opts.Image)
+	assert.Equal(t, map[string]string{"FOO": "bar"}, opts.Envs)
+	assert.Equal(t, []VolumeMount{
+		{HostPath: "/home/me/code", ContainerPath: "/code"},
+		{HostPath: "3f9a0c", ContainerPath: "/data"},
+	}, opts.Volumes)
+
+	state, err := m.loadState()
+	require.NoError(t, err)
+	assert.NotContains(t, state, "env-1")
+	require.Contains(t, state, "env-2")
+	assert.Equal(t, "studio:2", state["env-2"].CreateOptions.Image)
+}
+
+func TestManager_RebuildKeepsOriginalOnFailure(t *testing.T) {
+	m, backend, _ := newRebuildManager(t)
+	ctx := context.Background()
+
+	_, err := m.Create(ctx, &CreateOptions{Name: "my-env", Mode: ModeDocker, Image: "studio:1"})
+	require.NoError(t, err)
+
+	backend.createFunc = func(ctx context.Context, opts *CreateOptions) (*Environment, error) {
+		return nil, fmt.Errorf("image not found")
+	}
+	_, err = m.Rebuild(ctx, "my-env", &RebuildOptions{Image: "studio:missing"})
+	require.Error(t, err)
+
+	require.Contains(t, backend.envs, "env-1")
+	assert.Equal(t, StatusRunning, backend.envs["env-1"].Status, "the original container is started again")
+}
+
+func TestManager_RebuildRequiresRecordedOptions(t *testing.T) {
+	m, backend, _ := newRebuildManager(t)
+	backend.envs["old"] = &Environment{ID: "old", Name: "legacy", Mode: ModeDocker, Status: StatusRunning}
+
+	_, err := m.Rebuild(context.Background(), "legacy", &RebuildOptions{})
+	assert.ErrorContains(t, err, "remove and create it again")
+}
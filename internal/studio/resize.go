@@ -0,0 +1,424 @@
+package studio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// ResizeOptions describes changes to an existing environment. Zero values
+// leave the corresponding setting unchanged.
+type ResizeOptions struct {
+	Resources ResourceSpec `json:"resources,omitempty"`
+	// AddPorts adds port mappings, replacing any mapping of the same container port
+	AddPorts []PortMapping `json:"add_ports,omitempty"`
+	// RemovePorts lists container ports whose mappings are removed
+	RemovePorts []int `json:"remove_ports,omitempty"`
+	// AddVolumes adds mounts, replacing any mount at the same container path
+	AddVolumes []VolumeMount `json:"add_volumes,omitempty"`
+	// RemoveVolumes lists container paths whose mounts are removed
+	RemoveVolumes []string `json:"remove_volumes,omitempty"`
+}
+
+// IsEmpty reports whether the options request no change
+func (o *ResizeOptions) IsEmpty() bool {
+	return o.Resources.CPUs <= 0 && o.Resources.Memory == "" && !o.changesMounts()
+}
+
+// applyTo updates the options an environment was created with, so that a
+// rebuild keeps the change
+func (o *ResizeOptions) applyTo(create *CreateOptions) {
+	if o.Resources.CPUs > 0 {
+		create.Resources.CPUs = o.Resources.CPUs
+	}
+	if o.Resources.Memory != "" {
+		create.Resources.Memory = o.Resources.Memory
+	}
+	if ports, err := applyPortChanges(create.Ports, o.AddPorts, o.RemovePorts); err == nil {
+		create.Ports = ports
+	}
+	create.Volumes = applyVolumeChanges(create.Volumes, o.AddVolumes, o.RemoveVolumes)
+}
+
+// changesMounts reports whether ports or volumes change, which container
+// runtimes can only apply by recreating the container
+func (o *ResizeOptions) changesMounts() bool {
+	return len(o.AddPorts) > 0 || len(o.RemovePorts) > 0 || len(o.AddVolumes) > 0 || len(o.RemoveVolumes) > 0
+}
+
+// ResizableBackend is an optional interface for backends that can change the
+// resources, ports and volumes of an existing environment without losing its
+// filesystem. It returns the environment ID, which changes when the backend
+// had to recreate the container.
+type ResizableBackend interface {
+	Backend
+	Resize(ctx context.Context, envID string, opts *ResizeOptions) (string, error)
+}
+
+// dockerRunner runs a docker CLI command and returns its combined output
+type dockerRunner func(ctx context.Context, args ...string) ([]byte, error)
+
+// dockerContainerSpec is the subset of `docker inspect` needed to recreate a container
+type dockerContainerSpec struct {
+	ID    string `json:"Id"`
+	Name  string `json:"Name"`
+	State struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+	Config struct {
+		Labels      map[string]string `json:"Labels"`
+		WorkingDir  string            `json:"WorkingDir"`
+		Healthcheck *struct {
+			Test []string `json:"Test"`
+		} `json:"Healthcheck"`
+	} `json:"Config"`
+	HostConfig struct {
+		Init           *bool    `json:"Init"`
+		CapAdd         []string `json:"CapAdd"`
+		NanoCpus       int64    `json:"NanoCpus"`
+		Memory         int64    `json:"Memory"`
+		DeviceRequests []struct {
+			Count int `json:"Count"`
+		} `json:"DeviceRequests"`
+		PortBindings map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"PortBindings"`
+	} `json:"HostConfig"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+}
+
+func parseDockerInspect(data []byte) (*dockerContainerSpec, error) {
+	var specs []dockerContainerSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("failed to parse container inspect output: %w", err)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("container not found")
+	}
+	return &specs[0], nil
+}
+
+// portMappings returns the container's published ports sorted by container port
+func (s *dockerContainerSpec) portMappings() []PortMapping {
+	var ports []PortMapping
+	for key, bindings := range s.HostConfig.PortBindings {
+		portStr, protocol, _ := strings.Cut(key, "/")
+		containerPort, err := strconv.Atoi(portStr)
+		if err != nil {
+			continue
+		}
+		for _, b := range bindings {
+			hostPort, err := strconv.Atoi(b.HostPort)
+			if err != nil {
+				continue
+			}
+			ports = append(ports, PortMapping{HostPort: hostPort, ContainerPort: containerPort, Protocol: protocol})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].ContainerPort != ports[j].ContainerPort {
+			return ports[i].ContainerPort < ports[j].ContainerPort
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+	return ports
+}
+
+// volumeMounts returns bind mounts and volumes (named or anonymous, by name)
+// so a recreated container gets the same data
+func (s *dockerContainerSpec) volumeMounts() []VolumeMount {
+	var mounts []VolumeMount
+	for _, m := range s.Mounts {
+		source := m.Source
+		switch m.Type {
+		case "bind":
+		case "volume":
+			source = m.Name
+		default:
+			continue
+		}
+		mounts = append(mounts, VolumeMount{HostPath: source, ContainerPath: m.Destination, ReadOnly: !m.RW})
+	}
+	return mounts
+}
+
+// applyPortChanges removes and adds port mappings. The SSH mapping cannot be
+// removed since ggo relies on it to reach the environment.
+func applyPortChanges(current, add []PortMapping, remove []int) ([]PortMapping, error) {
+	drop := make(map[int]bool, len(remove)+len(add))
+	for _, p := range remove {
+		if p == 22 {
+			return nil, fmt.Errorf("the SSH port mapping cannot be removed")
+		}
+		drop[p] = true
+	}
+	for _, p := range add {
+		drop[p.ContainerPort] = true
+	}
+	var ports []PortMapping
+	for _, p := range current {
+		if !drop[p.ContainerPort] {
+			ports = append(ports, p)
+		}
+	}
+	return append(ports, add...), nil
+}
+
+// applyVolumeChanges removes and adds mounts by container path
+func applyVolumeChanges(current, add []VolumeMount, remove []string) []VolumeMount {
+	drop := make(map[string]bool, len(remove)+len(add))
+	for _, p := range remove {
+		drop[p] = true
+	}
+	for _, v := range add {
+		drop[v.ContainerPath] = true
+	}
+	var volumes []VolumeMount
+	for _, v := range current {
+		if !drop[v.ContainerPath] {
+			volumes = append(volumes, v)
+		}
+	}
+	return append(volumes, add...)
+}
+
+// dockerUpdateArgs builds `docker update` arguments for a resource change.
+// The swap limit is raised with the memory limit; docker rejects a memory
+// limit above the current swap limit otherwise.
+func dockerUpdateArgs(res ResourceSpec, envID string) []string {
+	args := []string{"update"}
+	if res.CPUs > 0 {
+		args = append(args, "--cpus", fmt.Sprintf("%.2f", res.CPUs))
+	}
+	if memory := normalizeContainerMemory(res.Memory); memory != "" {
+		args = append(args, "--memory", memory, "--memory-swap", memory)
+	}
+	return append(args, envID)
+}
+
+// dockerRecreateArgs builds `docker create` arguments that reproduce spec
+// from a committed image with new ports, volumes and resources. Env, command
+// and entrypoint are carried by the committed image.
+func dockerRecreateArgs(spec *dockerContainerSpec, name, image string, ports []PortMapping, volumes []VolumeMount, res ResourceSpec) []string {
+	args := []string{"create", "--name", name}
+	if spec.HostConfig.Init != nil && *spec.HostConfig.Init {
+		args = append(args, "--init")
+	}
+	if hc := spec.Config.Healthcheck; hc != nil && len(hc.Test) > 0 && hc.Test[0] == "NONE" {
+		args = append(args, "--no-healthcheck")
+	}
+	for _, c := range spec.HostConfig.CapAdd {
+		args = append(args, "--cap-add", c)
+	}
+	if len(spec.HostConfig.DeviceRequests) > 0 {
+		args = append(args, "--gpus", "all")
+	}
+
+	keys := make([]string, 0, len(spec.Config.Labels))
+	for k := range spec.Config.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--label", k+"="+spec.Config.Labels[k])
+	}
+
+	for _, p := range ports {
+		protocol := p.Protocol
+		if protocol == "" {
+			protocol = DefaultProtocolTCP
+		}
+		args = append(args, "-p", fmt.Sprintf("%d:%d/%s", p.HostPort, p.ContainerPort, protocol))
+	}
+	for _, v := range volumes {
+		mountOpt := fmt.Sprintf("%s:%s", v.HostPath, v.ContainerPath)
+		if v.ReadOnly {
+			mountOpt += MountOptionReadOnly
+		}
+		args = append(args, "-v", mountOpt)
+	}
+
+	if res.CPUs > 0 {
+		args = append(args, "--cpus", fmt.Sprintf("%.2f", res.CPUs))
+	} else if spec.HostConfig.NanoCpus > 0 {
+		args = append(args, "--cpus", fmt.Sprintf("%.2f", float64(spec.HostConfig.NanoCpus)/1e9))
+	}
+	if memory := normalizeContainerMemory(res.Memory); memory != "" {
+		args = append(args, "--memory", memory)
+	} else if spec.HostConfig.Memory > 0 {
+		args = append(args, "--memory", strconv.FormatInt(spec.HostConfig.Memory, 10))
+	}
+	if spec.Config.WorkingDir != "" {
+		args = append(args, "-w", spec.Config.WorkingDir)
+	}
+	return append(args, image)
+}
+
+// snapshotImageName names the image a container is committed to before it is
+// recreated; image references must be lowercase
+func snapshotImageName(containerName string, now time.Time) string {
+	return "ggo-snapshot/" + strings.ToLower(containerName) + ":" + now.Format("20060102150405")
+}
+
+// resizeDockerContainer applies opts to a container through a docker CLI.
+// Resource-only changes use `docker update` in place. Port and volume changes
+// commit the container's filesystem to a snapshot image and recreate it under
+// the same name with the same mounts; on failure the original container is
+// restored. hostPath maps user-supplied volume paths for the docker host.
+func resizeDockerContainer(ctx context.Context, run dockerRunner, envID string, opts *ResizeOptions, hostPath func(string) string) (string, error) {
+	if !opts.changesMounts() {
+		if output, err := run(ctx, dockerUpdateArgs(opts.Resources, envID)...); err != nil {
+			return "", fmt.Errorf("failed to update container: %w, output: %s", err, output)
+		}
+		return envID, nil
+	}
+
+	output, err := run(ctx, "inspect", "--type", "container", envID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w, output: %s", err, output)
+	}
+	spec, err := parseDockerInspect(output)
+	if err != nil {
+		return "", err
+	}
+
+	ports, err := applyPortChanges(spec.portMappings(), opts.AddPorts, opts.RemovePorts)
+	if err != nil {
+		return "", err
+	}
+	added := make([]VolumeMount, len(opts.AddVolumes))
+	for i, v := range opts.AddVolumes {
+		added[i] = v
+		if hostPath != nil {
+			added[i].HostPath = hostPath(v.HostPath)
+		}
+	}
+	volumes := applyVolumeChanges(spec.volumeMounts(), added, opts.RemoveVolumes)
+
+	name := strings.TrimPrefix(spec.Name, "/")
+	oldID := spec.ID
+	backupName := name + "-resizing"
+	image := snapshotImageName(name, time.Now())
+
+	if spec.State.Running {
+		if output, err := run(ctx, "stop", oldID); err != nil {
+			return "", fmt.Errorf("failed to stop container: %w, output: %s", err, output)
+		}
+	}
+	restore := func() {
+		if _, err := run(ctx, "rename", backupName, name); err != nil {
+			klog.Errorf("Failed to restore container name, it is named %s: error=%v", backupName, err)
+		}
+		if spec.State.Running {
+			if _, err := run(ctx, "start", oldID); err != nil {
+				klog.Errorf("Failed to restart original container %s: %v", name, err)
+			}
+		}
+	}
+
+	if output, err := run(ctx, "commit", oldID, image); err != nil {
+		if spec.State.Running {
+			_, _ = run(ctx, "start", oldID)
+		}
+		return "", fmt.Errorf("failed to snapshot container: %w, output: %s", err, output)
+	}
+	if output, err := run(ctx, "rename", oldID, backupName); err != nil {
+		if spec.State.Running {
+			_, _ = run(ctx, "start", oldID)
+		}
+		return "", fmt.Errorf("failed to rename container: %w, output: %s", err, output)
+	}
+
+	output, err = run(ctx, dockerRecreateArgs(spec, name, image, ports, volumes, opts.Resources)...)
+	if err != nil {
+		restore()
+		return "", fmt.Errorf("failed to recreate container: %w, output: %s", err, output)
+	}
+	newID := strings.TrimSpace(string(output))
+
+	if spec.State.Running {
+		if output, err := run(ctx, "start", newID); err != nil {
+			_, _ = run(ctx, "rm", "-f", newID)
+			restore()
+			return "", fmt.Errorf("failed to start recreated container: %w, output: %s", err, output)
+		}
+		// sshd was started with docker exec, not as part of the container command
+		if output, err := run(ctx, "exec", "-d", "--user", "root", newID, "/usr/sbin/sshd", "-D"); err != nil {
+			klog.Warningf("Failed to start SSH daemon in recreated container: %v, output: %s", err, output)
+		}
+	}
+
+	if output, err := run(ctx, "rm", "-f", oldID); err != nil {
+		klog.Warningf("Failed to remove original container %s: %v, output: %s", backupName, err, output)
+	}
+	klog.Infof("Recreated container %s from snapshot image %s", name, image)
+
+	if len(newID) > 12 {
+		newID = newID[:12]
+	}
+	return newID, nil
+}
+
+// runDocker runs a docker CLI command against the backend's daemon
+func (b *DockerBackend) runDocker(ctx context.Context, args ...string) ([]byte, error) {
+	return b.command(ctx, args...).CombinedOutput()
+}
+
+// Resize implements ResizableBackend
+func (b *DockerBackend) Resize(ctx context.Context, envID string, opts *ResizeOptions) (string, error) {
+	return resizeDockerContainer(ctx, b.runDocker, envID, opts, nil)
+}
+
+// runDocker runs a docker CLI command against the Colima VM's daemon
+func (b *ColimaBackend) runDocker(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("DOCKER_HOST=%s", b.dockerHost))
+	return cmd.CombinedOutput()
+}
+
+// Resize implements ResizableBackend. Container limits are bounded by the
+// Colima VM, whose size is changed with `colima start --cpu/--memory`.
+func (b *ColimaBackend) Resize(ctx context.Context, envID string, opts *ResizeOptions) (string, error) {
+	return resizeDockerContainer(ctx, b.runDocker, envID, opts, nil)
+}
+
+// dockerRunner returns a runner for the docker CLI inside the WSL distribution
+func (b *WSLBackend) dockerRunner(ctx context.Context) (dockerRunner, error) {
+	distro, err := b.GetDistro(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, args ...string) ([]byte, error) {
+		return b.runInWSL(ctx, distro, append([]string{"docker"}, args...)...)
+	}, nil
+}
+
+// Resize implements ResizableBackend
+func (b *WSLBackend) Resize(ctx context.Context, envID string, opts *ResizeOptions) (string, error) {
+	run, err := b.dockerRunner(ctx)
+	if err != nil {
+		return "", err
+	}
+	return resizeDockerContainer(ctx, run, envID, opts, b.windowsToWSLPath)
+}
+
+var (
+	_ ResizableBackend = (*DockerBackend)(nil)
+	_ ResizableBackend = (*ColimaBackend)(nil)
+	_ ResizableBackend = (*WSLBackend)(nil)
+)
@@ -0,0 +1,174 @@
+package studio
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDockerInspect = `[{
+	"Id": "0123456789abcdef0123",
+	"Name": "/ggo-my-env-ab12",
+	"State": {"Running": true},
+	"Config": {
+		"Labels": {"ggo.managed": "true", "ggo.name": "my-env"},
+		"WorkingDir": "/workspace",
+		"Healthcheck": {"Test": ["NONE"]}
+	},
+	"HostConfig": {
+		"Init": true,
+		"CapAdd": ["AUDIT_WRITE", "SYS_ADMIN"],
+		"NanoCpus": 4000000000,
+		"Memory": 8589934592,
+		"DeviceRequests": [{"Count": -1}],
+		"PortBindings": {
+			"22/tcp": [{"HostIp": "", "HostPort": "12022"}],
+			"8888/tcp": [{"HostIp": "", "HostPort": "8888"}]
+		}
+	},
+	"Mounts": [
+		{"Type": "bind", "Source": "/home/me", "Destination": "/home/me", "RW": true},
+		{"Type": "volume", "Name": "a1b2c3", "Source": "/var/lib/docker/volumes/a1b2c3/_data", "Destination": "/data", "RW": true},
+		{"Type": "bind", "Source": "/opt/libs", "Destination": "/opt/libs", "RW": false},
+		{"Type": "tmpfs", "Destination": "/tmp"}
+	]
+}]`
+
+func TestParseDockerInspect(t *testing.T) {
+	spec, err := parseDockerInspect([]byte(sampleDockerInspect))
+	require.NoError(t, err)
+
+	assert.Equal(t, []PortMapping{
+		{HostPort: 12022, ContainerPort: 22, Protocol: "tcp"},
+		{HostPort: 8888, ContainerPort: 8888, Protocol: "tcp"},
+	}, spec.portMappings())
+	assert.Equal(t, []VolumeMount{
+		{HostPath: "/home/me", ContainerPath: "/home/me"},
+		{HostPath: "a1b2c3", ContainerPath: "/data"},
+		{HostPath: "/opt/libs", ContainerPath: "/opt/libs", ReadOnly: true},
+	}, spec.volumeMounts())
+
+	_, err = parseDockerInspect([]byte(`[]`))
+	assert.Error(t, err)
+}
+
+func TestApplyPortAndVolumeChanges(t *testing.T) {
+	current := []PortMapping{{HostPort: 12022, ContainerPort: 22}, {HostPort: 8888, ContainerPort: 8888}}
+	ports, err := applyPortChanges(current, []PortMapping{{HostPort: 9999, ContainerPort: 8888}, {HostPort: 6006, ContainerPort: 6006}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []PortMapping{
+		{HostPort: 12022, ContainerPort: 22},
+		{HostPort: 9999, ContainerPort: 8888},
+		{HostPort: 6006, ContainerPort: 6006},
+	}, ports)
+
+	ports, err = applyPortChanges(current, nil, []int{8888})
+	require.NoError(t, err)
+	assert.Equal(t, []PortMapping{{HostPort: 12022, ContainerPort: 22}}, ports)
+
+	_, err = applyPortChanges(current, nil, []int{22})
+	assert.Error(t, err, "SSH mapping must be kept")
+
+	volumes := applyVolumeChanges(
+		[]VolumeMount{{HostPath: "/a", ContainerPath: "/data"}, {HostPath: "/home/me", ContainerPath: "/home/me"}},
+		[]VolumeMount{{HostPath: "/b", ContainerPath: "/data", ReadOnly: true}},
+		[]string{"/home/me"},
+	)
+	assert.Equal(t, []VolumeMount{{HostPath: "/b", ContainerPath: "/data", ReadOnly: true}}, volumes)
+}
+
+func TestDockerUpdateArgs(t *testing.T) {
+	assert.Equal(t, []string{"update", "--cpus", "8.00", "--memory", "16G", "--memory-swap", "16G", "abc"},
+		dockerUpdateArgs(ResourceSpec{CPUs: 8, Memory: "16Gi"}, "abc"))
+	assert.Equal(t, []string{"update", "--cpus", "2.50", "abc"}, dockerUpdateArgs(ResourceSpec{CPUs: 2.5}, "abc"))
+}
+
+func TestDockerRecreateArgs(t *testing.T) {
+	spec, err := parseDockerInspect([]byte(sampleDockerInspect))
+	require.NoError(t, err)
+
+	args := dockerRecreateArgs(spec, "ggo-my-env-ab12", "ggo-snapshot/ggo-my-env-ab12:1",
+		[]PortMapping{{HostPort: 12022, ContainerPort: 22}}, []VolumeMount{{HostPath: "a1b2c3", ContainerPath: "/data"}},
+		ResourceSpec{Memory: "16Gi"})
+	joined := strings.Join(args, " ")
+
+	assert.Equal(t, []string{"create", "--name", "ggo-my-env-ab12", "--init", "--no-healthcheck"}, args[:5])
+	assert.Contains(t, joined, "--cap-add AUDIT_WRITE --cap-add SYS_ADMIN")
+	assert.Contains(t, joined, "--gpus all")
+	assert.Contains(t, joined, "--label ggo.managed=true --label ggo.name=my-env")
+	assert.Contains(t, joined, "-p 12022:22/tcp")
+	assert.Contains(t, joined, "-v a1b2c3:/data")
+	assert.Contains(t, joined, "--cpus 4.00", "unchanged limits are kept")
+	assert.Contains(t, joined, "--memory 16G")
+	assert.Contains(t, joined, "-w /workspace")
+	assert.Equal(t, "ggo-snapshot/ggo-my-env-ab12:1", args[len(args)-1])
+}
+
+// fakeDocker records docker invocations and fails commands whose first
+// argument is listed in failOn
+type fakeDocker struct {
+	calls  []string
+	failOn map[string]bool
+}
+
+func (f *fakeDocker) run(_ context.Context, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, strings.Join(args, " "))
+	if f.failOn[args[0]] {
+		return []byte("boom"), errors.New("exit status 1")
+	}
+	switch args[0] {
+	case "inspect":
+		return []byte(sampleDockerInspect), nil
+	case "create":
+		return []byte("fedcba9876543210fedc\n"), nil
+	}
+	return nil, nil
+}
+
+func (f *fakeDocker) commands() []string {
+	cmds := make([]string, len(f.calls))
+	for i, c := range f.calls {
+		cmds[i] = strings.Fields(c)[0]
+	}
+	return cmds
+}
+
+func TestResizeDockerContainer_UpdatesInPlace(t *testing.T) {
+	docker := &fakeDocker{}
+	id, err := resizeDockerContainer(context.Background(), docker.run, "abc", &ResizeOptions{Resources: ResourceSpec{CPUs: 8}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", id)
+	assert.Equal(t, []string{"update --cpus 8.00 abc"}, docker.calls)
+}
+
+func TestResizeDockerContainer_Recreates(t *testing.T) {
+	docker := &fakeDocker{}
+	id, err := resizeDockerContainer(context.Background(), docker.run, "abc",
+		&ResizeOptions{AddVolumes: []VolumeMount{{HostPath: `C:\data`, ContainerPath: "/data"}}},
+		func(p string) string { return "/mnt/c/data" })
+	require.NoError(t, err)
+	assert.Equal(t, "fedcba987654", id)
+	assert.Equal(t, []string{"inspect", "stop", "commit", "rename", "create", "start", "exec", "rm"}, docker.commands())
+	assert.Contains(t, docker.calls[4], "-v /mnt/c/data:/data")
+	assert.NotContains(t, docker.calls[4], "a1b2c3", "replaced mount must be dropped")
+	assert.Equal(t, "rm -f 0123456789abcdef0123", docker.calls[7])
+}
+
+func TestResizeDockerContainer_RestoresOnFailure(t *testing.T) {
+	docker := &fakeDocker{failOn: map[string]bool{"create": true}}
+	_, err := resizeDockerContainer(context.Background(), docker.run, "abc",
+		&ResizeOptions{AddPorts: []PortMapping{{HostPort: 6006, ContainerPort: 6006}}}, nil)
+	require.Error(t, err)
+	assert.Equal(t, []string{"inspect", "stop", "commit", "rename", "create", "rename", "start"}, docker.commands())
+	assert.Equal(t, "rename ggo-my-env-ab12-resizing ggo-my-env-ab12", docker.calls[5])
+}
+
+func TestResizeOptions_IsEmpty(t *testing.T) {
+	assert.True(t, (&ResizeOptions{}).IsEmpty())
+	assert.False(t, (&ResizeOptions{Resources: ResourceSpec{Memory: "4Gi"}}).IsEmpty())
+	assert.False(t, (&ResizeOptions{RemoveVolumes: []string{"/data"}}).IsEmpty())
+}
@@ -0,0 +1,82 @@
+package studio
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// runtimeSecurity is how a container runtime maps users and labels files,
+// which decides whether the host directories bind-mounted into a studio
+// (the home directory, GPU libraries) are usable from inside it
+type runtimeSecurity struct {
+	// Rootless runtimes run as the invoking user and map container root to
+	// that user, so its files stay writable from the container
+	Rootless bool
+	// UsernsRemap is a rootful daemon mapping container users to
+	// subordinate IDs (dockerd --userns-remap): container root owns nothing
+	// on the host and bind mounts turn read-only for it
+	UsernsRemap bool
+	// SELinux confines containers to files labelled for them
+	SELinux bool
+}
+
+// parseDockerSecurityOptions reads the SecurityOptions of `docker info`,
+// e.g. ["name=seccomp,profile=builtin", "name=rootless", "name=userns"]
+func parseDockerSecurityOptions(options []string) runtimeSecurity {
+	var s runtimeSecurity
+	for _, option := range options {
+		for field := range strings.SplitSeq(option, ",") {
+			switch field {
+			case "name=rootless":
+				s.Rootless = true
+			case "name=userns":
+				s.UsernsRemap = true
+			case "name=selinux":
+				s.SELinux = true
+			}
+		}
+	}
+	return s
+}
+
+// runArgs returns the run flags keeping bind mounts usable. A userns-remapped
+// daemon runs studios in the host user namespace. SELinux separation is
+// turned off for studios rather than relabelling their mounts (:z), which
+// would relabel the user's home directory for the host as well.
+func (s runtimeSecurity) runArgs() []string {
+	var args []string
+	if s.UsernsRemap && !s.Rootless {
+		args = append(args, "--userns=host")
+	}
+	if s.SELinux {
+		args = append(args, "--security-opt", "label=disable")
+	}
+	return args
+}
+
+// runtimeSecurity probes how the backend's runtime maps users. A failed
+// probe is logged and treated as a rootful runtime without remapping.
+func (b *DockerBackend) runtimeSecurity(ctx context.Context) runtimeSecurity {
+	if b.isPodman() {
+		info, err := b.podmanInfo(ctx)
+		if err != nil {
+			klog.V(2).Infof("Failed to probe podman security options: %v", err)
+			return runtimeSecurity{}
+		}
+		return runtimeSecurity{Rootless: info.Host.Security.Rootless, SELinux: info.Host.Security.SELinuxEnabled}
+	}
+
+	output, err := b.command(ctx, "info", "--format", "{{json .SecurityOptions}}").Output()
+	var options []string
+	if err == nil {
+		err = json.Unmarshal(output, &options)
+	}
+	if err != nil {
+		klog.V(2).Infof("Failed to probe docker security options: %v", err)
+		return runtimeSecurity{}
+	}
+	return parseDockerSecurityOptions(options)
+}
@@ -0,0 +1,118 @@
+package studio
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/credentials"
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+)
+
+// SecretStoreFile holds the secrets env templates refer to with
+// {{secret "name"}}
+const SecretStoreFile = "studio-secrets.json"
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// StoredSecret is a named secret of the local secret store
+type StoredSecret struct {
+	Name string `json:"name"`
+	// Value refers to the OS keyring entry of the secret, or is the secret
+	// itself when there is no keyring
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SecretStore keeps named secrets for studio env templates in the OS keyring,
+// or in an owner-only file in the config dir without one
+type SecretStore struct {
+	path string
+}
+
+// NewSecretStore creates a secret store under paths' config dir
+func NewSecretStore(paths *platform.Paths) *SecretStore {
+	return &SecretStore{path: filepath.Join(paths.ConfigDir(), SecretStoreFile)}
+}
+
+// ValidateSecretName checks a secret name can be stored and referred to
+func ValidateSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// List returns the stored secrets; their values may be keyring references
+func (s *SecretStore) List() ([]StoredSecret, error) {
+	secrets, err := utils.LoadJSONSlice[StoredSecret](s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret store: %w", err)
+	}
+	return secrets, nil
+}
+
+// Get returns the secret stored under name
+func (s *SecretStore) Get(name string) (string, error) {
+	secrets, err := s.List()
+	if err != nil {
+		return "", err
+	}
+	idx := slices.IndexFunc(secrets, func(sec StoredSecret) bool { return sec.Name == name })
+	if idx < 0 {
+		return "", fmt.Errorf("secret %q is not set (see 'ggo studio secret set %s')", name, name)
+	}
+	return credentials.Open(secrets[idx].Value)
+}
+
+// Set stores value under name, replacing a previous secret of that name
+func (s *SecretStore) Set(name, value string) (*StoredSecret, error) {
+	if err := ValidateSecretName(name); err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, fmt.Errorf("secret %s must not be empty", name)
+	}
+	secrets, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	sec := StoredSecret{Name: name, Value: credentials.Seal(secretAccount(name), value), UpdatedAt: time.Now()}
+	if idx := slices.IndexFunc(secrets, func(e StoredSecret) bool { return e.Name == name }); idx >= 0 {
+		secrets[idx] = sec
+	} else {
+		secrets = append(secrets, sec)
+	}
+	if err := utils.SaveJSONSlice(s.path, secrets, 0600); err != nil {
+		return nil, fmt.Errorf("failed to save secret store: %w", err)
+	}
+	return &sec, nil
+}
+
+// Remove deletes the secret stored under name and reports whether there was
+// one
+func (s *SecretStore) Remove(name string) (bool, error) {
+	secrets, err := s.List()
+	if err != nil {
+		return false, err
+	}
+	idx := slices.IndexFunc(secrets, func(sec StoredSecret) bool { return sec.Name == name })
+	if idx < 0 {
+		return false, nil
+	}
+	if err := credentials.Forget(secrets[idx].Value); err != nil {
+		return false, fmt.Errorf("failed to remove secret %s from OS keyring: %w", name, err)
+	}
+	if err := utils.SaveJSONSlice(s.path, slices.Delete(secrets, idx, idx+1), 0600); err != nil {
+		return false, fmt.Errorf("failed to save secret store: %w", err)
+	}
+	return true, nil
+}
+
+// secretAccount names the keyring entry of a studio secret
+func secretAccount(name string) string {
+	return "studio-secret:" + name
+}
@@ -0,0 +1,165 @@
+package studio
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Snippet formats for ShareSnippet
+const (
+	SnippetDocker  = "docker"
+	SnippetCompose = "compose"
+)
+
+const (
+	// snippetLibsPath is where the client libraries are mounted, as in studios
+	snippetLibsPath = "/opt/gpugo/libs"
+	// snippetFetchImage downloads the client libraries into the libs volume;
+	// its busybox wget and sha256sum are all the download needs
+	snippetFetchImage = "alpine:3"
+)
+
+// SnippetLibrary is a client library the snippet downloads from the CDN
+type SnippetLibrary struct {
+	Name   string
+	URL    string
+	SHA256 string
+}
+
+// ShareSnippet describes a container that uses a shared GPU without ggo: a
+// one-off container downloads the client libraries into a volume, and the
+// workload container mounts it and preloads them
+type ShareSnippet struct {
+	// ConnectionInfo is the TENSOR_FUSION_OPERATOR_CONNECTION_INFO value,
+	// the share's connection URL followed by its short code
+	ConnectionInfo string
+	Vendor         GPUVendor
+	// Image is the workload image
+	Image string
+	// Volume names the volume holding the client libraries
+	Volume    string
+	Libraries []SnippetLibrary
+}
+
+// Render returns the snippet in format, SnippetDocker or SnippetCompose
+func (s *ShareSnippet) Render(format string) (string, error) {
+	if len(s.Libraries) == 0 {
+		return "", fmt.Errorf("no client libraries published for vendor %s", s.Vendor)
+	}
+	switch format {
+	case SnippetDocker:
+		return s.dockerRun(), nil
+	case SnippetCompose:
+		return s.compose(), nil
+	default:
+		return "", fmt.Errorf("unknown snippet format %q (use %s or %s)", format, SnippetDocker, SnippetCompose)
+	}
+}
+
+// Env returns the environment variables of the workload container
+func (s *ShareSnippet) Env() map[string]string {
+	env := map[string]string{
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO": s.ConnectionInfo,
+		"TF_LOG_PATH":                            "/tmp/tensor-fusion/logs.txt",
+		"TF_LOG_LEVEL":                           "info",
+		"TF_ENABLE_LOG":                          "1",
+		"TF_CONNECTION_INFO_PATH":                "/tmp/tensor-fusion/connections.txt",
+		"LD_LIBRARY_PATH":                        snippetLibsPath,
+	}
+	if preload := s.preload(); len(preload) > 0 {
+		env["LD_PRELOAD"] = strings.Join(preload, ":")
+	}
+	if name := VisibleDevicesEnv(s.Vendor); name != "" {
+		env[name] = "0"
+	}
+	return env
+}
+
+// preload returns the paths of the vendor's stub libraries among the
+// downloaded ones
+func (s *ShareSnippet) preload() []string {
+	var paths []string
+	for _, name := range GetLibraryNames(s.Vendor) {
+		for _, lib := range s.Libraries {
+			if lib.Name == name {
+				paths = append(paths, path.Join(snippetLibsPath, name))
+				break
+			}
+		}
+	}
+	return paths
+}
+
+// fetchScript downloads and verifies the libraries in the libs volume. Like
+// ggo, it adds a .1 link to unversioned shared libraries.
+func (s *ShareSnippet) fetchScript() []string {
+	lines := []string{"set -e", "cd " + snippetLibsPath}
+	for _, lib := range s.Libraries {
+		name := ShellQuote(lib.Name)
+		lines = append(lines, fmt.Sprintf("wget -q -O %s %s", name, ShellQuote(lib.URL)))
+		if lib.SHA256 != "" {
+			lines = append(lines, fmt.Sprintf("echo %s | sha256sum -c -", ShellQuote(lib.SHA256+"  "+lib.Name)))
+		}
+		if strings.HasSuffix(lib.Name, ".so") {
+			lines = append(lines, fmt.Sprintf("ln -sf %s %s", name, ShellQuote(lib.Name+".1")))
+		}
+	}
+	return lines
+}
+
+func (s *ShareSnippet) dockerRun() string {
+	var b strings.Builder
+	b.WriteString("# Download the GPU client libraries\n")
+	fmt.Fprintf(&b, "docker run --rm -v %s:%s %s sh -c %s\n\n",
+		s.Volume, snippetLibsPath, snippetFetchImage, ShellQuote(strings.Join(s.fetchScript(), " && ")))
+
+	b.WriteString("# Run the workload on the shared GPU\n")
+	fmt.Fprintf(&b, "docker run -it --rm \\\n  -v %s:%s:ro \\\n", s.Volume, snippetLibsPath)
+	env := s.Env()
+	for _, k := range SortedKeys(env) {
+		fmt.Fprintf(&b, "  -e %s \\\n", ShellQuote(k+"="+env[k]))
+	}
+	fmt.Fprintf(&b, "  %s\n", s.Image)
+	return b.String()
+}
+
+func (s *ShareSnippet) compose() string {
+	var b strings.Builder
+	b.WriteString("services:\n")
+	b.WriteString("  ggo-libs:\n")
+	fmt.Fprintf(&b, "    image: %s\n", snippetFetchImage)
+	fmt.Fprintf(&b, "    volumes:\n      - %s:%s\n", s.Volume, snippetLibsPath)
+	b.WriteString("    command:\n      - sh\n      - -c\n      - |\n")
+	for _, line := range s.fetchScript() {
+		fmt.Fprintf(&b, "        %s\n", line)
+	}
+	b.WriteString("  app:\n")
+	fmt.Fprintf(&b, "    image: %s\n", s.Image)
+	b.WriteString("    depends_on:\n      ggo-libs:\n        condition: service_completed_successfully\n")
+	b.WriteString("    environment:\n")
+	env := s.Env()
+	for _, k := range SortedKeys(env) {
+		fmt.Fprintf(&b, "      %s: %s\n", k, strconv.Quote(env[k]))
+	}
+	fmt.Fprintf(&b, "    volumes:\n      - %s:%s:ro\n", s.Volume, snippetLibsPath)
+	fmt.Fprintf(&b, "volumes:\n  %s: {}\n", s.Volume)
+	return b.String()
+}
+
+// ShellQuote quotes s as a single POSIX shell word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// SortedKeys returns the keys of m in order
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
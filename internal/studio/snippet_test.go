@@ -0,0 +1,64 @@
+package studio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testShareSnippet() *ShareSnippet {
+	return &ShareSnippet{
+		ConnectionInfo: "native+10.0.0.5+9001+abc123",
+		Vendor:         VendorNvidia,
+		Image:          DefaultImageStudioTorch,
+		Volume:         "ggo-libs-nvidia",
+		Libraries: []SnippetLibrary{
+			{Name: "libcuda.so", URL: "https://cdn.tensor-fusion.ai/nvidia/libcuda.so", SHA256: "aa11"},
+			{Name: "libnvidia-ml.so", URL: "https://cdn.tensor-fusion.ai/nvidia/libnvidia-ml.so"},
+			{Name: "libteleport.so", URL: "https://cdn.tensor-fusion.ai/libteleport.so", SHA256: "bb22"},
+		},
+	}
+}
+
+func TestShareSnippet_Env(t *testing.T) {
+	env := testShareSnippet().Env()
+	assert.Equal(t, "native+10.0.0.5+9001+abc123", env["TENSOR_FUSION_OPERATOR_CONNECTION_INFO"])
+	assert.Equal(t, "/opt/gpugo/libs", env["LD_LIBRARY_PATH"])
+	assert.Equal(t, "/opt/gpugo/libs/libcuda.so:/opt/gpugo/libs/libnvidia-ml.so", env["LD_PRELOAD"], "only stub libraries are preloaded")
+	assert.Equal(t, "0", env["CUDA_VISIBLE_DEVICES"])
+}
+
+func TestShareSnippet_Docker(t *testing.T) {
+	out, err := testShareSnippet().Render(SnippetDocker)
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "docker run --rm -v ggo-libs-nvidia:/opt/gpugo/libs alpine:3 sh -c 'set -e && cd /opt/gpugo/libs && "+
+		`wget -q -O '\''libcuda.so'\'' '\''https://cdn.tensor-fusion.ai/nvidia/libcuda.so'\'' && `+
+		`echo '\''aa11  libcuda.so'\'' | sha256sum -c - && `+
+		`ln -sf '\''libcuda.so'\'' '\''libcuda.so.1'\'' && `)
+	assert.NotContains(t, out, "libnvidia-ml.so  ", "libraries without a hash are not verified")
+	assert.Contains(t, out, "  -v ggo-libs-nvidia:/opt/gpugo/libs:ro \\\n")
+	assert.Contains(t, out, "  -e 'TENSOR_FUSION_OPERATOR_CONNECTION_INFO=native+10.0.0.5+9001+abc123' \\\n")
+	assert.Contains(t, out, "  "+DefaultImageStudioTorch+"\n")
+}
+
+func TestShareSnippet_Compose(t *testing.T) {
+	out, err := testShareSnippet().Render(SnippetCompose)
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "        wget -q -O 'libteleport.so' 'https://cdn.tensor-fusion.ai/libteleport.so'\n")
+	assert.Contains(t, out, "        condition: service_completed_successfully\n")
+	assert.Contains(t, out, "      TENSOR_FUSION_OPERATOR_CONNECTION_INFO: \"native+10.0.0.5+9001+abc123\"\n")
+	assert.Contains(t, out, "volumes:\n  ggo-libs-nvidia: {}\n")
+}
+
+func TestShareSnippet_Errors(t *testing.T) {
+	s := testShareSnippet()
+	_, err := s.Render("helm")
+	assert.ErrorContains(t, err, "unknown snippet format")
+
+	s.Libraries = nil
+	_, err = s.Render(SnippetDocker)
+	assert.ErrorContains(t, err, "no client libraries")
+}
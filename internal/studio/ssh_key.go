@@ -1,6 +1,7 @@
 package studio
 
 import (
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/pem"
@@ -9,71 +10,192 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/NexusGPU/gpu-go/internal/platform"
 	"golang.org/x/crypto/ssh"
 	"k8s.io/klog/v2"
 )
 
-// GetOrCreateStudioSSHKey gets or creates a dedicated SSH key pair for TF studio containers
-// Returns the public key content, private key path, and error if any
-func GetOrCreateStudioSSHKey() (publicKey string, privateKeyPath string, err error) {
+// StudioPrivateKeyPath returns the path of the SSH private key in ~/.ggo/ssh/
+// that studios created by older versions shared; the public key sits next to
+// it with a .pub suffix
+func StudioPrivateKeyPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get user home directory: %w", err)
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
 	}
+	return filepath.Join(homeDir, ".ggo", "ssh", "id_ed25519"), nil
+}
 
-	// Store dedicated SSH keys in ~/.ggo/ssh/
-	sshDir := filepath.Join(homeDir, ".ggo", "ssh")
-	privateKeyPath = filepath.Join(sshDir, "id_ed25519")
-	publicKeyPath := filepath.Join(sshDir, "id_ed25519.pub")
+// StudioKeyPath returns the path of the private key of a studio's own SSH key
+// pair, ~/.gpugo/studio/{name}/keys/id_ed25519; the public key sits next to it
+// with a .pub suffix
+func StudioKeyPath(paths *platform.Paths, name string) string {
+	return filepath.Join(paths.StudioDir(), platform.NormalizeName(name), "keys", "id_ed25519")
+}
 
-	// Check if key pair already exists
-	if pubContent, err := os.ReadFile(publicKeyPath); err == nil {
-		publicKey = strings.TrimSpace(string(pubContent))
-		if publicKey != "" {
-			klog.V(2).Infof("Using existing TF studio SSH key: %s", publicKeyPath)
-			return publicKey, privateKeyPath, nil
-		}
+// GetOrCreateStudioSSHKey gets or creates the SSH key pair of a studio, whose
+// public key the studio authorizes. Returns the public key content and the
+// private key path.
+func GetOrCreateStudioSSHKey(paths *platform.Paths, name string) (publicKey string, privateKeyPath string, err error) {
+	privateKeyPath = StudioKeyPath(paths, name)
+	if publicKey := readPublicKey(privateKeyPath + ".pub"); publicKey != "" {
+		klog.V(2).Infof("Using existing SSH key of studio %s: %s", name, privateKeyPath)
+		return publicKey, privateKeyPath, nil
+	}
+
+	klog.Infof("Generating SSH key pair for studio %s...", name)
+	publicKey, err = writeSSHKeyPair(privateKeyPath)
+	if err != nil {
+		return "", "", err
 	}
+	klog.Infof("Generated SSH key pair: %s", privateKeyPath)
+	return publicKey, privateKeyPath, nil
+}
+
+// readPublicKey returns the key in a public key file, or "" if there is none
+func readPublicKey(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
 
-	// Generate new Ed25519 key pair
-	klog.Infof("Generating new SSH key pair for TF studio containers...")
-	if err := os.MkdirAll(sshDir, 0700); err != nil {
-		return "", "", fmt.Errorf("failed to create SSH directory: %w", err)
+// writeSSHKeyPair generates an Ed25519 key pair and writes it to
+// privateKeyPath and privateKeyPath.pub. Returns the public key content.
+func writeSSHKeyPair(privateKeyPath string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(privateKeyPath), 0700); err != nil {
+		return "", fmt.Errorf("failed to create SSH directory: %w", err)
 	}
 
 	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to generate SSH key: %w", err)
+		return "", fmt.Errorf("failed to generate SSH key: %w", err)
 	}
 
 	// Convert to SSH format
 	sshPubKey, err := ssh.NewPublicKey(pubKey)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create SSH public key: %w", err)
+		return "", fmt.Errorf("failed to create SSH public key: %w", err)
+	}
+
+	// Write private key in PEM format
+	privKeyBytes, err := ssh.MarshalPrivateKey(privKey, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	if err := os.WriteFile(privateKeyPath, pem.EncodeToMemory(privKeyBytes), 0600); err != nil {
+		return "", fmt.Errorf("failed to write private key: %w", err)
 	}
 
 	// Write public key
 	publicKeyData := ssh.MarshalAuthorizedKey(sshPubKey)
-	if err := os.WriteFile(publicKeyPath, publicKeyData, 0644); err != nil {
-		return "", "", fmt.Errorf("failed to write public key: %w", err)
+	if err := os.WriteFile(privateKeyPath+".pub", publicKeyData, 0644); err != nil {
+		return "", fmt.Errorf("failed to write public key: %w", err)
 	}
 
-	// Write private key in PEM format
-	privKeyBytes, err := ssh.MarshalPrivateKey(privKey, "")
+	return strings.TrimSpace(string(publicKeyData)), nil
+}
+
+// studioIdentityFile returns the private key to connect to a studio with: its
+// own key pair, or for studios created by older versions the shared one.
+// Empty if neither exists, e.g. for a studio authorizing a key given with
+// --ssh-key.
+func studioIdentityFile(paths *platform.Paths, name string) string {
+	keyPath := StudioKeyPath(paths, name)
+	if _, err := os.Stat(keyPath); err == nil {
+		return keyPath
+	}
+	if keyPath, err := StudioPrivateKeyPath(); err == nil {
+		if _, err := os.Stat(keyPath); err == nil {
+			return keyPath
+		}
+	}
+	return ""
+}
+
+// rotateAuthorizedKeyScript replaces the key $1 with the key $2 in the
+// authorized_keys of root
+const rotateAuthorizedKeyScript = `set -e
+f=/root/.ssh/authorized_keys
+mkdir -p /root/.ssh && chmod 700 /root/.ssh
+touch "$f"
+{ grep -vxF "$1" "$f" || true; printf '%s\n' "$2"; } > "$f.ggo-tmp"
+mv "$f.ggo-tmp" "$f" && chmod 600 "$f"`
+
+// RotateSSHKey replaces the SSH key pair of a studio: a new pair is
+// generated, its public key authorized in the studio instead of the old one,
+// and the new pair takes the place of the old one. A studio that authorized
+// a key given with --ssh-key moves to a key pair of its own. A stopped
+// studio is started for the change and stopped again.
+func (m *Manager) RotateSSHKey(ctx context.Context, idOrName string) (*Environment, error) {
+	env, err := m.Get(ctx, idOrName)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to marshal private key: %w", err)
+		return nil, err
+	}
+	backend, err := m.GetBackend(env.Mode)
+	if err != nil {
+		return nil, err
 	}
 
-	privKeyPEM := pem.EncodeToMemory(privKeyBytes)
-	if err := os.WriteFile(privateKeyPath, privKeyPEM, 0600); err != nil {
-		return "", "", fmt.Errorf("failed to write private key: %w", err)
+	keyPath := StudioKeyPath(m.paths, env.Name)
+	stored, _ := m.getFromState(env.ID)
+	oldKey := readPublicKey(keyPath + ".pub")
+	if stored != nil && stored.CreateOptions != nil && stored.CreateOptions.SSHPublicKey != "" {
+		oldKey = stored.CreateOptions.SSHPublicKey
 	}
 
-	publicKey = strings.TrimSpace(string(publicKeyData))
-	klog.Infof("Generated new SSH key pair: %s", publicKeyPath)
-	klog.Infof("Private key saved to: %s", privateKeyPath)
+	newKeyPath := keyPath + ".new"
+	newKey, err := writeSSHKeyPair(newKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	discard := func() {
+		_ = os.Remove(newKeyPath)
+		_ = os.Remove(newKeyPath + ".pub")
+	}
 
-	return publicKey, privateKeyPath, nil
+	running := env.Status == StatusRunning
+	if !running {
+		if err := backend.Start(ctx, env.ID); err != nil {
+			discard()
+			return nil, err
+		}
+	}
+	output, err := backend.Exec(ctx, env.ID, []string{"sh", "-c", rotateAuthorizedKeyScript, "sh", oldKey, newKey})
+	if !running {
+		if stopErr := backend.Stop(ctx, env.ID); stopErr != nil {
+			klog.Warningf("Failed to stop environment again after key rotation: env=%s error=%v", env.Name, stopErr)
+		}
+	}
+	if err != nil {
+		discard()
+		return nil, fmt.Errorf("failed to authorize the new key: %w, output: %s", err, output)
+	}
+
+	if err := os.Rename(newKeyPath, keyPath); err != nil {
+		return nil, fmt.Errorf("new key authorized but not saved: %w", err)
+	}
+	if err := os.Rename(newKeyPath+".pub", keyPath+".pub"); err != nil {
+		return nil, fmt.Errorf("new key authorized but not saved: %w", err)
+	}
+	// Rebuilds authorize the new key
+	if stored != nil && stored.CreateOptions != nil {
+		opts := *stored.CreateOptions
+		opts.SSHPublicKey = newKey
+		m.saveWithOptions(stored, &opts)
+	}
+
+	klog.Infof("Rotated studio SSH key: env=%s key=%s", env.Name, keyPath)
+	return env, nil
+}
+
+// removeSSHKey removes the SSH key pair of a studio
+func (m *Manager) removeSSHKey(name string) {
+	keyDir := filepath.Dir(StudioKeyPath(m.paths, name))
+	if err := os.RemoveAll(keyDir); err != nil {
+		klog.Warningf("Failed to remove studio SSH key: dir=%s error=%v", keyDir, err)
+	}
 }
 
 // FormatSSHPublicKey formats and validates an SSH public key
@@ -0,0 +1,38 @@
+//go:build windows
+
+package studio
+
+import (
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+const windowResizePollInterval = 250 * time.Millisecond
+
+// watchWindowResize forwards local terminal size changes to the remote pty
+// until the returned stop function is called. Windows consoles have no
+// SIGWINCH, so the size is polled.
+func watchWindowResize(fd int, session *ssh.Session) func() {
+	done := make(chan struct{})
+	go func() {
+		width, height, _ := term.GetSize(fd)
+		ticker := time.NewTicker(windowResizePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w, h, err := term.GetSize(fd)
+				if err != nil || (w == width && h == height) {
+					continue
+				}
+				width, height = w, h
+				_ = session.WindowChange(height, width)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,216 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+)
+
+// SSHSessionOptions configures an SSH session into a studio environment
+type SSHSessionOptions struct {
+	// PrivateKeyPath defaults to the studio's own key
+	PrivateKeyPath string
+	// ForwardAgent forwards the local SSH agent (ssh -A)
+	ForwardAgent bool
+	// Command runs a remote command and exits instead of opening a shell
+	Command []string
+	// ForceEmbedded skips the local ssh binary and always uses the built-in client
+	ForceEmbedded bool
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// SSHExitError reports a non-zero exit status of the remote session
+type SSHExitError struct {
+	Code int
+}
+
+func (e *SSHExitError) Error() string {
+	return fmt.Sprintf("ssh session exited with status %d", e.Code)
+}
+
+// ExitCode returns the remote exit status for the process to exit with
+func (e *SSHExitError) ExitCode() int {
+	return e.Code
+}
+
+// SSHCommandArgs returns the arguments for the ssh binary to reach the environment
+func SSHCommandArgs(env *Environment, opts SSHSessionOptions) []string {
+	args := []string{
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+		"-o", "LogLevel=ERROR",
+		"-p", strconv.Itoa(env.SSHPort),
+	}
+	if opts.PrivateKeyPath != "" {
+		args = append(args, "-i", opts.PrivateKeyPath)
+	}
+	if opts.ForwardAgent {
+		args = append(args, "-A")
+	}
+	if len(opts.Command) == 0 {
+		args = append(args, "-t")
+	}
+	args = append(args, fmt.Sprintf("%s@%s", env.SSHUser, env.SSHHost))
+	if len(opts.Command) > 0 {
+		args = append(args, "--")
+		args = append(args, opts.Command...)
+	}
+	return args
+}
+
+// RunSSHSession opens an interactive shell (or runs opts.Command) in the
+// environment. The local ssh binary is preferred so that user config and
+// agents keep working; minimal installs without one fall back to an embedded
+// client. A non-zero remote exit status is returned as *SSHExitError.
+func RunSSHSession(ctx context.Context, env *Environment, opts SSHSessionOptions) error {
+	if env.SSHPort == 0 || env.SSHHost == "" {
+		return fmt.Errorf("SSH not configured for environment %s", env.Name)
+	}
+	if opts.Stdin == nil {
+		opts.Stdin = os.Stdin
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+	if opts.PrivateKeyPath == "" {
+		opts.PrivateKeyPath = studioIdentityFile(platform.DefaultPaths(), env.Name)
+	}
+
+	if !opts.ForceEmbedded {
+		if sshPath, err := exec.LookPath("ssh"); err == nil {
+			return runSSHBinary(ctx, sshPath, env, opts)
+		}
+		klog.V(2).Infof("ssh binary not found, using embedded SSH client")
+	}
+	return runEmbeddedSSH(env, opts)
+}
+
+func runSSHBinary(ctx context.Context, sshPath string, env *Environment, opts SSHSessionOptions) error {
+	cmd := exec.CommandContext(ctx, sshPath, SSHCommandArgs(env, opts)...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+
+	klog.V(2).Infof("Running: %s %s", sshPath, strings.Join(cmd.Args[1:], " "))
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return &SSHExitError{Code: exitErr.ExitCode()}
+		}
+		return fmt.Errorf("failed to run ssh: %w", err)
+	}
+	return nil
+}
+
+func runEmbeddedSSH(env *Environment, opts SSHSessionOptions) error {
+	if opts.PrivateKeyPath == "" {
+		return fmt.Errorf("no SSH private key available; run 'ggo studio create' to generate one or install an ssh client")
+	}
+	keyData, err := os.ReadFile(opts.PrivateKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read private key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyData)
+	if err != nil {
+		return fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	config := &ssh.ClientConfig{
+		User: env.SSHUser,
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		// Studio containers regenerate host keys on every create, same as the
+		// StrictHostKeyChecking=no entry written to ~/.ssh/config
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec
+	}
+	addr := net.JoinHostPort(env.SSHHost, strconv.Itoa(env.SSHPort))
+	client, err := ssh.Dial("tcp", addr, config)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer func() { _ = client.Close() }()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	if opts.ForwardAgent {
+		forwardLocalAgent(client, session)
+	}
+
+	session.Stdin = opts.Stdin
+	session.Stdout = opts.Stdout
+	session.Stderr = opts.Stderr
+
+	if len(opts.Command) > 0 {
+		return sshExitStatus(session.Run(strings.Join(opts.Command, " ")))
+	}
+
+	if f, ok := opts.Stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fd := int(f.Fd())
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		termType := os.Getenv("TERM")
+		if termType == "" {
+			termType = "xterm-256color"
+		}
+		if err := session.RequestPty(termType, height, width, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+			return fmt.Errorf("failed to request pty: %w", err)
+		}
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("failed to set terminal raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(fd, state) }()
+		defer watchWindowResize(fd, session)()
+	}
+
+	if err := session.Shell(); err != nil {
+		return fmt.Errorf("failed to start shell: %w", err)
+	}
+	return sshExitStatus(session.Wait())
+}
+
+func forwardLocalAgent(client *ssh.Client, session *ssh.Session) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		klog.Warningf("Agent forwarding requested but SSH_AUTH_SOCK is not set")
+		return
+	}
+	if err := agent.ForwardToRemote(client, socket); err != nil {
+		klog.Warningf("Failed to forward SSH agent: %v", err)
+		return
+	}
+	if err := agent.RequestAgentForwarding(session); err != nil {
+		klog.Warningf("Failed to request agent forwarding: %v", err)
+	}
+}
+
+func sshExitStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if exitErr, ok := err.(*ssh.ExitError); ok {
+		return &SSHExitError{Code: exitErr.ExitStatus()}
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package studio
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHCommandArgs(t *testing.T) {
+	env := &Environment{Name: "alpha", SSHHost: "127.0.0.1", SSHPort: 2222, SSHUser: "root"}
+
+	interactive := SSHCommandArgs(env, SSHSessionOptions{PrivateKeyPath: "/k", ForwardAgent: true})
+	assert.Contains(t, interactive, "-t")
+	assert.Contains(t, interactive, "-A")
+	assert.Equal(t, "root@127.0.0.1", interactive[len(interactive)-1])
+
+	remote := SSHCommandArgs(env, SSHSessionOptions{Command: []string{"nvidia-smi", "-L"}})
+	assert.NotContains(t, remote, "-t")
+	assert.Equal(t, []string{"root@127.0.0.1", "--", "nvidia-smi", "-L"}, remote[len(remote)-4:])
+}
+
+func TestRunSSHSession_EmbeddedPropagatesExitStatus(t *testing.T) {
+	keyPath := writeTestPrivateKey(t)
+	addr := startTestSSHServer(t, 3)
+
+	host, portStr, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	port, err := net.LookupPort("tcp", portStr)
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	err = RunSSHSession(context.Background(),
+		&Environment{Name: "alpha", SSHHost: host, SSHPort: port, SSHUser: "root"},
+		SSHSessionOptions{
+			PrivateKeyPath: keyPath,
+			Command:        []string{"echo", "hi"},
+			ForceEmbedded:  true,
+			Stdin:          bytes.NewReader(nil),
+			Stdout:         &stdout,
+			Stderr:         &bytes.Buffer{},
+		})
+
+	var exitErr *SSHExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.Code)
+	assert.Equal(t, "ran: echo hi", stdout.String())
+}
+
+func writeTestPrivateKey(t *testing.T) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0600))
+	return path
+}
+
+// startTestSSHServer accepts a single session, echoes the exec'd command and
+// exits with the given status
+func startTestSSHServer(t *testing.T, exitStatus uint32) string {
+	t.Helper()
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		_, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+		for newCh := range chans {
+			ch, requests, err := newCh.Accept()
+			if err != nil {
+				return
+			}
+			for req := range requests {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				_ = req.Reply(true, nil)
+				command := string(req.Payload[4:])
+				_, _ = ch.Write([]byte("ran: " + command))
+				status := make([]byte, 4)
+				binary.BigEndian.PutUint32(status, exitStatus)
+				_, _ = ch.SendRequest("exit-status", false, status)
+				_ = ch.Close()
+			}
+		}
+	}()
+
+	return ln.Addr().String()
+}
@@ -0,0 +1,376 @@
+package studio
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/utils"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// statsTimeout bounds sampling the usage of one backend's studios
+	statsTimeout = 15 * time.Second
+	// gpuProbeTimeout bounds the connect to a studio's GPU worker
+	gpuProbeTimeout = 3 * time.Second
+)
+
+// ResourceUsage is a sample of the resources a studio container uses.
+// CPUPercent is relative to one CPU, as in `docker stats`, so a busy
+// container on several CPUs exceeds 100.
+type ResourceUsage struct {
+	CPUPercent       float64 `json:"cpu_percent"`
+	MemoryUsageBytes int64   `json:"memory_usage_bytes"`
+	MemoryLimitBytes int64   `json:"memory_limit_bytes,omitempty"`
+	NetRxBytes       int64   `json:"net_rx_bytes"`
+	NetTxBytes       int64   `json:"net_tx_bytes"`
+	PIDs             int     `json:"pids,omitempty"`
+}
+
+// GPUConnection is the reachability of a studio's remote GPU worker from
+// this machine
+type GPUConnection struct {
+	Addr      string  `json:"addr"`
+	Reachable bool    `json:"reachable"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// EnvironmentStats is the live resource usage of a studio. Usage is only
+// sampled for running studios; Error tells why it is missing for one.
+type EnvironmentStats struct {
+	ID     string            `json:"id"`
+	Name   string            `json:"name"`
+	Mode   Mode              `json:"mode"`
+	Status EnvironmentStatus `json:"status"`
+	Usage  *ResourceUsage    `json:"usage,omitempty"`
+	GPU    *GPUConnection    `json:"gpu,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// StatsBackend is an optional interface for backends whose runtime reports
+// container resource usage. Backends without it are sampled from inside the
+// container through Exec. The result is keyed by the given environment IDs.
+type StatsBackend interface {
+	Backend
+	Stats(ctx context.Context, envIDs []string) (map[string]*ResourceUsage, error)
+}
+
+// Stats samples the resource usage of the named studios, or of all studios
+// when none are named, and checks their remote GPU workers are reachable
+func (m *Manager) Stats(ctx context.Context, idOrNames []string) ([]*EnvironmentStats, error) {
+	var envs []*Environment
+	if len(idOrNames) == 0 {
+		all, err := m.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, env := range all {
+			if env.Status != StatusDeleted {
+				envs = append(envs, env)
+			}
+		}
+	} else {
+		for _, idOrName := range idOrNames {
+			env, err := m.Get(ctx, idOrName)
+			if err != nil {
+				return nil, err
+			}
+			envs = append(envs, env)
+		}
+	}
+
+	stats := make([]*EnvironmentStats, len(envs))
+	running := make(map[Mode][]int)
+	for i, env := range envs {
+		stats[i] = &EnvironmentStats{ID: env.ID, Name: env.Name, Mode: env.Mode, Status: env.Status}
+		if env.Status == StatusRunning {
+			running[env.Mode] = append(running[env.Mode], i)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for mode, idx := range running {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.sampleUsage(ctx, mode, envs, stats, idx)
+		}()
+	}
+	for i, env := range envs {
+		if env.GPUWorkerURL == "" || env.Status != StatusRunning {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats[i].GPU = ProbeGPUWorker(ctx, env.GPUWorkerURL)
+		}()
+	}
+	wg.Wait()
+	return stats, nil
+}
+
+// sampleUsage fills in the usage of the studios at idx, all of one mode
+func (m *Manager) sampleUsage(ctx context.Context, mode Mode, envs []*Environment, stats []*EnvironmentStats, idx []int) {
+	ctx, cancel := context.WithTimeout(ctx, statsTimeout)
+	defer cancel()
+
+	backend, err := m.GetBackend(mode)
+	if err != nil {
+		for _, i := range idx {
+			stats[i].Error = err.Error()
+		}
+		return
+	}
+
+	if sb, ok := backend.(StatsBackend); ok {
+		ids := make([]string, len(idx))
+		for n, i := range idx {
+			ids[n] = envs[i].ID
+		}
+		usage, err := sb.Stats(ctx, ids)
+		for _, i := range idx {
+			switch {
+			case err != nil:
+				stats[i].Error = err.Error()
+			case usage[envs[i].ID] == nil:
+				stats[i].Error = "no usage reported by " + backend.Name()
+			default:
+				stats[i].Usage = usage[envs[i].ID]
+			}
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, i := range idx {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			usage, err := execStats(ctx, backend, envs[i].ID)
+			if err != nil {
+				klog.V(2).Infof("Failed to sample studio usage: name=%s error=%v", envs[i].Name, err)
+				stats[i].Error = err.Error()
+				return
+			}
+			stats[i].Usage = usage
+		}()
+	}
+	wg.Wait()
+}
+
+// ProbeGPUWorker measures the TCP connect time to a GPU worker
+func ProbeGPUWorker(ctx context.Context, connectionURL string) *GPUConnection {
+	addr, err := utils.ConnectionAddr(connectionURL)
+	if err != nil {
+		return &GPUConnection{Error: err.Error()}
+	}
+	conn := &GPUConnection{Addr: addr}
+	ctx, cancel := context.WithTimeout(ctx, gpuProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		conn.Error = err.Error()
+		return conn
+	}
+	conn.Reachable = true
+	conn.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	_ = c.Close()
+	return conn
+}
+
+// dockerStatsRow is one line of `docker stats --format '{{json .}}'`
+type dockerStatsRow struct {
+	ID       string `json:"ID"`
+	Name     string `json:"Name"`
+	CPUPerc  string `json:"CPUPerc"`
+	MemUsage string `json:"MemUsage"`
+	NetIO    string `json:"NetIO"`
+	PIDs     string `json:"PIDs"`
+}
+
+// Formats of a dockerStatsRow line. podman's own JSON has other keys, and
+// its template names the PID count PIDS.
+const (
+	dockerStatsFormat = "{{json .}}"
+	podmanStatsFormat = `{"ID":"{{.ID}}","Name":"{{.Name}}","CPUPerc":"{{.CPUPerc}}","MemUsage":"{{.MemUsage}}","NetIO":"{{.NetIO}}","PIDs":"{{.PIDS}}"}`
+)
+
+// dockerStats samples containers with `docker stats`, which takes about two
+// seconds to measure CPU usage
+func dockerStats(ctx context.Context, run dockerRunner, format string, envIDs []string) (map[string]*ResourceUsage, error) {
+	args := append([]string{"stats", "--no-stream", "--no-trunc", "--format", format}, envIDs...)
+	output, err := run(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("docker stats failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	rows, err := parseDockerStats(output)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]*ResourceUsage, len(envIDs))
+	for _, id := range envIDs {
+		for _, row := range rows {
+			if strings.HasPrefix(row.ID, id) || row.Name == id || strings.TrimPrefix(row.Name, "/") == id {
+				usage[id] = row.usage()
+				break
+			}
+		}
+	}
+	return usage, nil
+}
+
+func parseDockerStats(output []byte) ([]dockerStatsRow, error) {
+	var rows []dockerStatsRow
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var row dockerStatsRow
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse docker stats output: %w", err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (r *dockerStatsRow) usage() *ResourceUsage {
+	u := &ResourceUsage{}
+	u.CPUPercent, _ = strconv.ParseFloat(strings.TrimSuffix(r.CPUPerc, "%"), 64)
+	u.MemoryUsageBytes, u.MemoryLimitBytes = parseDockerSizePair(r.MemUsage)
+	u.NetRxBytes, u.NetTxBytes = parseDockerSizePair(r.NetIO)
+	u.PIDs, _ = strconv.Atoi(r.PIDs)
+	return u
+}
+
+// parseDockerSizePair parses "12.5MiB / 1.9GiB"
+func parseDockerSizePair(s string) (int64, int64) {
+	first, second, _ := strings.Cut(s, "/")
+	return parseDockerSize(first), parseDockerSize(second)
+}
+
+// dockerSizeUnits are the units docker prints sizes with, longest first
+var dockerSizeUnits = []struct {
+	suffix string
+	factor float64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"kB", 1e3}, {"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+// parseDockerSize parses a size such as "1.944GiB" or "648B"; unknown
+// values such as "--" yield 0
+func parseDockerSize(s string) int64 {
+	s = strings.TrimSpace(s)
+	for _, unit := range dockerSizeUnits {
+		if num, ok := strings.CutSuffix(s, unit.suffix); ok {
+			v, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(v * unit.factor)
+		}
+	}
+	return 0
+}
+
+// Stats implements StatsBackend
+func (b *DockerBackend) Stats(ctx context.Context, envIDs []string) (map[string]*ResourceUsage, error) {
+	format := dockerStatsFormat
+	if b.isPodman() {
+		format = podmanStatsFormat
+	}
+	return dockerStats(ctx, b.runDocker, format, envIDs)
+}
+
+// Stats implements StatsBackend
+func (b *ColimaBackend) Stats(ctx context.Context, envIDs []string) (map[string]*ResourceUsage, error) {
+	return dockerStats(ctx, b.runDocker, dockerStatsFormat, envIDs)
+}
+
+// Stats implements StatsBackend. The docker daemon in the WSL distribution
+// reads the containers' cgroups there.
+func (b *WSLBackend) Stats(ctx context.Context, envIDs []string) (map[string]*ResourceUsage, error) {
+	run, err := b.dockerRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dockerStats(ctx, run, dockerStatsFormat, envIDs)
+}
+
+var (
+	_ StatsBackend = (*DockerBackend)(nil)
+	_ StatsBackend = (*ColimaBackend)(nil)
+	_ StatsBackend = (*WSLBackend)(nil)
+)
+
+// execStatsScript reads the container's cgroup v2 counters, or the kernel's
+// when the container has no cgroup of its own (e.g. one VM per container),
+// sampling CPU time one second apart. /proc/stat counts in 1/100 s.
+const execStatsScript = `cpu() {
+  if [ -r /sys/fs/cgroup/cpu.stat ]; then awk '$1=="usage_usec"{print $2}' /sys/fs/cgroup/cpu.stat
+  else awk '$1=="cpu"{printf "%.0f\n", ($2+$3+$4+$7+$8)*10000}' /proc/stat; fi
+}
+echo cpu0=$(cpu); sleep 1; echo cpu1=$(cpu)
+if [ -r /sys/fs/cgroup/memory.current ]; then
+  echo mem=$(cat /sys/fs/cgroup/memory.current); echo memmax=$(cat /sys/fs/cgroup/memory.max)
+else
+  awk '$1=="MemTotal:"{t=$2} $1=="MemAvailable:"{a=$2} END{printf "mem=%.0f\nmemmax=%.0f\n", (t-a)*1024, t*1024}' /proc/meminfo
+fi
+awk 'NR>2{sub(/^ */,""); split($0,f,/[: ]+/); if(f[1]!="lo"){rx+=f[2]; tx+=f[10]}} END{printf "net=%.0f %.0f\n", rx, tx}' /proc/net/dev
+if [ -r /sys/fs/cgroup/pids.current ]; then echo pids=$(cat /sys/fs/cgroup/pids.current); fi`
+
+// execStats samples a container's usage from inside it
+func execStats(ctx context.Context, backend Backend, envID string) (*ResourceUsage, error) {
+	output, err := backend.Exec(ctx, envID, []string{"sh", "-c", execStatsScript})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage in container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseExecStats(string(output), time.Second)
+}
+
+// parseExecStats parses the key=value output of execStatsScript; cpu0 and
+// cpu1 are CPU microseconds sampled interval apart
+func parseExecStats(output string, interval time.Duration) (*ResourceUsage, error) {
+	values := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			values[k] = v
+		}
+	}
+	if values["cpu0"] == "" || values["mem"] == "" {
+		return nil, fmt.Errorf("unexpected usage output: %q", strings.TrimSpace(output))
+	}
+
+	u := &ResourceUsage{}
+	cpu0, _ := strconv.ParseInt(values["cpu0"], 10, 64)
+	cpu1, _ := strconv.ParseInt(values["cpu1"], 10, 64)
+	if cpu1 > cpu0 {
+		u.CPUPercent = float64(cpu1-cpu0) / float64(interval.Microseconds()) * 100
+	}
+	u.MemoryUsageBytes, _ = strconv.ParseInt(values["mem"], 10, 64)
+	// cgroup v2 reports "max" without a limit
+	u.MemoryLimitBytes, _ = strconv.ParseInt(values["memmax"], 10, 64)
+	if rx, tx, ok := strings.Cut(values["net"], " "); ok {
+		u.NetRxBytes, _ = strconv.ParseInt(rx, 10, 64)
+		u.NetTxBytes, _ = strconv.ParseInt(tx, 10, 64)
+	}
+	u.PIDs, _ = strconv.Atoi(values["pids"])
+	return u, nil
+}
@@ -0,0 +1,98 @@
+package studio
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDockerSize(t *testing.T) {
+	assert.Equal(t, int64(648), parseDockerSize("648B"))
+	assert.Equal(t, int64(1500), parseDockerSize("1.5kB"))
+	assert.Equal(t, int64(12*1<<20+1<<19), parseDockerSize(" 12.5MiB "))
+	assert.Equal(t, int64(2e9), parseDockerSize("2GB"))
+	assert.Zero(t, parseDockerSize("--"))
+
+	used, limit := parseDockerSizePair("256MiB / 1GiB")
+	assert.Equal(t, int64(256<<20), used)
+	assert.Equal(t, int64(1<<30), limit)
+}
+
+func TestDockerStats(t *testing.T) {
+	var gotArgs []string
+	run := func(ctx context.Context, args ...string) ([]byte, error) {
+		gotArgs = args
+		return []byte(`{"ID":"abc123def456","Name":"ggo-dev","CPUPerc":"153.25%","MemUsage":"256MiB / 1GiB","NetIO":"1.5kB / 648B","PIDs":"12"}
+{"ID":"fff000","Name":"other","CPUPerc":"0.00%","MemUsage":"0B / 0B","NetIO":"0B / 0B","PIDs":"0"}
+`), nil
+	}
+
+	usage, err := dockerStats(context.Background(), run, dockerStatsFormat, []string{"abc123", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stats", "--no-stream", "--no-trunc", "--format", "{{json .}}", "abc123", "missing"}, gotArgs)
+	require.Contains(t, usage, "abc123")
+	assert.Equal(t, &ResourceUsage{
+		CPUPercent:       153.25,
+		MemoryUsageBytes: 256 << 20,
+		MemoryLimitBytes: 1 << 30,
+		NetRxBytes:       1500,
+		NetTxBytes:       648,
+		PIDs:             12,
+	}, usage["abc123"])
+	assert.NotContains(t, usage, "missing")
+}
+
+func TestParseExecStats(t *testing.T) {
+	usage, err := parseExecStats("cpu0=1000000\ncpu1=1500000\nmem=104857600\nmemmax=max\nnet=2048 1024\npids=7\n", time.Second)
+	require.NoError(t, err)
+	assert.InDelta(t, 50.0, usage.CPUPercent, 0.001)
+	assert.Equal(t, int64(100<<20), usage.MemoryUsageBytes)
+	assert.Zero(t, usage.MemoryLimitBytes, "no cgroup memory limit")
+	assert.Equal(t, int64(2048), usage.NetRxBytes)
+	assert.Equal(t, int64(1024), usage.NetTxBytes)
+	assert.Equal(t, 7, usage.PIDs)
+
+	_, err = parseExecStats("sh: awk: not found", time.Second)
+	assert.ErrorContains(t, err, "unexpected usage output")
+}
+
+func TestManager_Stats(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	mock := &MockBackend{mode: ModeAppleContainer, available: true, envs: map[string]*Environment{
+		"env-1": {ID: "env-1", Name: "busy", Mode: ModeAppleContainer, Status: StatusRunning, GPUWorkerURL: "native+" + host + "+" + port},
+		"env-2": {ID: "env-2", Name: "idle", Mode: ModeAppleContainer, Status: StatusStopped},
+	}}
+	mock.execFunc = func(ctx context.Context, envID string, cmd []string) ([]byte, error) {
+		return []byte("cpu0=0\ncpu1=250000\nmem=1024\nmemmax=2048\nnet=1 2\n"), nil
+	}
+	m := &Manager{
+		paths:    platform.DefaultPaths().WithConfigDir(t.TempDir()),
+		backends: make(map[Mode]Backend),
+	}
+	m.RegisterBackend(mock)
+
+	stats, err := m.Stats(context.Background(), []string{"busy", "idle"})
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	busy := stats[0]
+	require.NotNil(t, busy.Usage)
+	assert.InDelta(t, 25.0, busy.Usage.CPUPercent, 0.001)
+	assert.Equal(t, int64(2048), busy.Usage.MemoryLimitBytes)
+	require.NotNil(t, busy.GPU)
+	assert.True(t, busy.GPU.Reachable)
+	assert.Equal(t, ln.Addr().String(), busy.GPU.Addr)
+
+	idle := stats[1]
+	assert.Nil(t, idle.Usage, "stopped studios are not sampled")
+	assert.Nil(t, idle.GPU)
+}
@@ -0,0 +1,90 @@
+package studio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+)
+
+// defaultImagePrefix is stripped from the default images to name their templates
+const defaultImagePrefix = "tensorfusion/studio-"
+
+// DefaultTemplates returns the templates built into ggo, one per default image
+func DefaultTemplates() []api.StudioTemplate {
+	images := DefaultImages()
+	templates := make([]api.StudioTemplate, 0, len(images))
+	for _, img := range images {
+		templates = append(templates, api.StudioTemplate{
+			Name:        strings.TrimPrefix(img.Name, defaultImagePrefix),
+			Description: img.Description,
+			Image:       img.Name + ":" + img.Tag,
+			Tags:        img.Features,
+		})
+	}
+	return templates
+}
+
+// FindTemplate returns the template with the given name, ignoring case, or nil
+func FindTemplate(templates []api.StudioTemplate, name string) *api.StudioTemplate {
+	for i := range templates {
+		if strings.EqualFold(templates[i].Name, name) {
+			return &templates[i]
+		}
+	}
+	return nil
+}
+
+// ResolveTemplate looks a template up among the built-in ones and then in the
+// platform registry
+func ResolveTemplate(ctx context.Context, client *api.Client, name string) (*api.StudioTemplate, error) {
+	if tmpl := FindTemplate(DefaultTemplates(), name); tmpl != nil {
+		return tmpl, nil
+	}
+	resp, err := client.ListStudioTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("template %q is not built in and the template registry is unavailable: %w", name, err)
+	}
+	tmpl := FindTemplate(resp.Templates, name)
+	if tmpl == nil {
+		return nil, fmt.Errorf("template %q not found (see 'ggo studio templates --remote')", name)
+	}
+	return tmpl, nil
+}
+
+// MergePortArgs returns the host:container port mappings of a template
+// followed by those given on the command line; a mapping of the same
+// container port on the command line replaces the template's.
+func MergePortArgs(template, args []string) []string {
+	return mergeArgs(template, args, func(p string) string {
+		return p[strings.LastIndex(p, ":")+1:]
+	})
+}
+
+// MergeVolumeArgs returns the volume mounts of a template followed by those
+// given on the command line; a mount at the same container path on the
+// command line replaces the template's.
+func MergeVolumeArgs(template, args []string) []string {
+	return mergeArgs(template, args, func(v string) string {
+		if parts := strings.Split(v, ":"); len(parts) > 1 {
+			return parts[1]
+		}
+		return v
+	})
+}
+
+// mergeArgs appends args to the template entries whose key is not in args
+func mergeArgs(template, args []string, key func(string) string) []string {
+	overridden := make(map[string]bool, len(args))
+	for _, arg := range args {
+		overridden[key(arg)] = true
+	}
+	var merged []string
+	for _, entry := range template {
+		if !overridden[key(entry)] {
+			merged = append(merged, entry)
+		}
+	}
+	return append(merged, args...)
+}
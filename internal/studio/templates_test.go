@@ -0,0 +1,46 @@
+package studio
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NexusGPU/gpu-go/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/studio/templates", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(api.StudioTemplatesResponse{Templates: []api.StudioTemplate{
+			{Name: "sd-webui", Image: "tensorfusion/sd-webui:1.10", Ports: []string{"7860:7860"}, Memory: "16Gi"},
+		}})
+	}))
+	defer server.Close()
+	client := api.NewClient(api.WithBaseURL(server.URL))
+
+	tmpl, err := ResolveTemplate(t.Context(), client, "torch")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultImageStudioTorch, tmpl.Image, "built-in templates need no registry")
+
+	tmpl, err = ResolveTemplate(t.Context(), client, "SD-WebUI")
+	require.NoError(t, err)
+	assert.Equal(t, "tensorfusion/sd-webui:1.10", tmpl.Image)
+	assert.Equal(t, "16Gi", tmpl.Memory)
+
+	_, err = ResolveTemplate(t.Context(), client, "missing")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestMergeTemplateArgs(t *testing.T) {
+	assert.Equal(t,
+		[]string{"8888:8888", "17860:7860"},
+		MergePortArgs([]string{"7860:7860", "8888:8888"}, []string{"17860:7860"}))
+	assert.Equal(t,
+		[]string{"sd-models:/models", "~/out:/outputs"},
+		MergeVolumeArgs([]string{"sd-models:/models", "sd-outputs:/outputs"}, []string{"~/out:/outputs"}))
+	assert.Nil(t, MergePortArgs(nil, nil))
+}
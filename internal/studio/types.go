@@ -18,6 +18,7 @@ const (
 	ModeColima         Mode = "colima"          // Colima (macOS/Linux)
 	ModeAppleContainer Mode = "apple-container" // Apple Container (macOS)
 	ModeDocker         Mode = "docker"          // Native Docker
+	ModePodman         Mode = "podman"          // Podman, rootful or rootless
 	ModeKubernetes     Mode = "k8s"             // Kubernetes (kind, minikube, etc.)
 	ModeAuto           Mode = "auto"            // Auto-detect best option
 )
@@ -83,6 +84,9 @@ type Environment struct {
 	Ports         []string          `json:"ports,omitempty"` // Port mappings in "hostPort:containerPort" format
 	CreatedAt     time.Time         `json:"created_at"`
 	Labels        map[string]string `json:"labels,omitempty"`
+	// CreateOptions are the options the environment was created with, kept
+	// in local state so 'ggo studio rebuild' can create it again
+	CreateOptions *CreateOptions `json:"create_options,omitempty"`
 }
 
 // EnvironmentStatus represents the status of an environment
@@ -144,7 +148,7 @@ type CreateOptions struct {
 	Mode           Mode              `json:"mode"`
 	Image          string            `json:"image"`
 	GPUWorkerURL   string            `json:"gpu_worker_url,omitempty"`  // TENSOR_FUSION_OPERATOR_CONNECTION_INFO
-	HardwareVendor string            `json:"hardware_vendor,omitempty"` // nvidia, amd, hygon
+	HardwareVendor string            `json:"hardware_vendor,omitempty"` // nvidia, amd, hygon, mthreads, cambricon
 	SSHPublicKey   string            `json:"ssh_public_key,omitempty"`
 	WorkDir        string            `json:"work_dir,omitempty"`
 	Ports          []PortMapping     `json:"ports,omitempty"`
@@ -163,6 +167,18 @@ type CreateOptions struct {
 	Platform string `json:"platform,omitempty"`
 	// UseLocalGPU enables local GPU passthrough (--gpus all) when no remote GPU share link is provided
 	UseLocalGPU bool `json:"use_local_gpu,omitempty"`
+	// PullPolicy controls whether the image is pulled before creation (default: missing)
+	PullPolicy PullPolicy `json:"pull_policy,omitempty"`
+	// Lockfile is a ggo.lock fixing the GPU client library versions
+	Lockfile string `json:"lockfile,omitempty"`
+	// Share is the share GPUWorkerURL was resolved from
+	Share *ShareRef `json:"share,omitempty"`
+	// Template is the studio template the options were taken from
+	Template *TemplateRef `json:"template,omitempty"`
+	// EnvTemplates holds the values of Envs given as templates, such as
+	// {{secret "hf"}}, before they were resolved, so an exported
+	// definition carries the template rather than the secret
+	EnvTemplates map[string]string `json:"env_templates,omitempty"`
 }
 
 // PortMapping represents a port mapping
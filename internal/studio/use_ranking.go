@@ -0,0 +1,118 @@
+package studio
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+)
+
+// UseRankingFile records `ggo use --fastest` probe results per set of share codes
+const UseRankingFile = "use-rankings.json"
+
+// ShareProbeResult is the outcome of probing one share
+type ShareProbeResult struct {
+	ShortCode string `json:"shortCode"`
+	WorkerID  string `json:"workerId,omitempty"`
+	Healthy   bool   `json:"healthy"`
+	LatencyMs int64  `json:"latencyMs,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ShareRanking orders a set of shares from fastest to slowest, with
+// unhealthy shares last
+type ShareRanking struct {
+	Codes    []string           `json:"codes"`
+	Results  []ShareProbeResult `json:"results"`
+	ProbedAt time.Time          `json:"probedAt"`
+}
+
+// Fastest returns the share code of the fastest healthy share, or "" if none
+// of them was healthy
+func (r *ShareRanking) Fastest() string {
+	for _, res := range r.Results {
+		if res.Healthy {
+			return res.ShortCode
+		}
+	}
+	return ""
+}
+
+// UseRankingStore persists share rankings in the config dir so that repeated
+// activations, e.g. from shell profiles, skip probing until a ranking expires
+type UseRankingStore struct {
+	path string
+}
+
+// NewUseRankingStore creates a store under paths' config dir
+func NewUseRankingStore(paths *platform.Paths) *UseRankingStore {
+	return &UseRankingStore{path: filepath.Join(paths.ConfigDir(), UseRankingFile)}
+}
+
+// rankingKey identifies a set of share codes regardless of their order
+func rankingKey(codes []string) string {
+	sorted := slices.Clone(codes)
+	slices.Sort(sorted)
+	return strings.Join(slices.Compact(sorted), ",")
+}
+
+func (s *UseRankingStore) list() ([]ShareRanking, error) {
+	rankings, err := utils.LoadJSONSlice[ShareRanking](s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read share rankings: %w", err)
+	}
+	return rankings, nil
+}
+
+// Get returns the ranking recorded for codes if it is younger than ttl,
+// otherwise nil
+func (s *UseRankingStore) Get(codes []string, ttl time.Duration) (*ShareRanking, error) {
+	rankings, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	key := rankingKey(codes)
+	for i := range rankings {
+		if rankingKey(rankings[i].Codes) == key && time.Since(rankings[i].ProbedAt) < ttl {
+			return &rankings[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Record saves a ranking, replacing the one for the same set of codes.
+// Expired rankings of other sets are dropped on the way.
+func (s *UseRankingStore) Record(ranking *ShareRanking, ttl time.Duration) error {
+	rankings, err := s.list()
+	if err != nil {
+		return err
+	}
+	if ranking.ProbedAt.IsZero() {
+		ranking.ProbedAt = time.Now()
+	}
+	key := rankingKey(ranking.Codes)
+	rankings = slices.DeleteFunc(rankings, func(r ShareRanking) bool {
+		return rankingKey(r.Codes) == key || time.Since(r.ProbedAt) >= ttl
+	})
+	rankings = append(rankings, *ranking)
+	return utils.SaveJSONSlice(s.path, rankings, 0644)
+}
+
+// Forget drops the ranking for codes, e.g. when its fastest share stopped
+// working before the ranking expired
+func (s *UseRankingStore) Forget(codes []string) error {
+	rankings, err := s.list()
+	if err != nil {
+		return err
+	}
+	key := rankingKey(codes)
+	remaining := slices.DeleteFunc(rankings, func(r ShareRanking) bool { return rankingKey(r.Codes) == key })
+	if len(remaining) == len(rankings) {
+		return nil
+	}
+	return utils.SaveJSONSlice(s.path, remaining, 0644)
+}
@@ -0,0 +1,243 @@
+package studio
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/NexusGPU/gpu-go/internal/utils"
+)
+
+// UseRegistryFile records the host artifacts created by `ggo use` per share code
+const UseRegistryFile = "use-connections.json"
+
+// ProfileLine is a line `ggo use` appended to a shell profile
+type ProfileLine struct {
+	File string `json:"file"`
+	Line string `json:"line"`
+}
+
+// UseConnection records everything `ggo use <code>` wrote to the host so that
+// `ggo clean <code>` can remove exactly that connection's artifacts
+type UseConnection struct {
+	// Name identifies the environment; it defaults to the share code and
+	// lets one share be used from several environments side by side
+	Name         string        `json:"name,omitempty"`
+	ShortCode    string        `json:"shortCode"`
+	WorkerID     string        `json:"workerId,omitempty"`
+	LongTerm     bool          `json:"longTerm,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+	Files        []string      `json:"files,omitempty"`
+	Dirs         []string      `json:"dirs,omitempty"`
+	ProfileLines []ProfileLine `json:"profileLines,omitempty"`
+	// WindowsEnv is set when a setx script for permanent user variables was written
+	WindowsEnv bool `json:"windowsEnv,omitempty"`
+	// PathEntries are the directories activation prepends to PATH on
+	// Windows; clean removes them from a user PATH persisted with setx
+	PathEntries []string `json:"pathEntries,omitempty"`
+	// CI is set for connections made by 'ggo use --ci', which 'ggo clean --ci'
+	// tears down
+	CI bool `json:"ci,omitempty"`
+}
+
+// ID returns the name the connection is recorded under
+func (c *UseConnection) ID() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.ShortCode
+}
+
+// AddFiles records files written for the connection
+func (c *UseConnection) AddFiles(files ...string) {
+	for _, f := range files {
+		if f != "" && !slices.Contains(c.Files, f) {
+			c.Files = append(c.Files, f)
+		}
+	}
+}
+
+// AddDirs records directories created for the connection
+func (c *UseConnection) AddDirs(dirs ...string) {
+	for _, d := range dirs {
+		if d != "" && !slices.Contains(c.Dirs, d) {
+			c.Dirs = append(c.Dirs, d)
+		}
+	}
+}
+
+// AddPathEntries records directories activation adds to PATH
+func (c *UseConnection) AddPathEntries(entries ...string) {
+	for _, e := range entries {
+		if e != "" && !slices.Contains(c.PathEntries, e) {
+			c.PathEntries = append(c.PathEntries, e)
+		}
+	}
+}
+
+// AddProfileLine records a line appended to a shell profile
+func (c *UseConnection) AddProfileLine(file, line string) {
+	pl := ProfileLine{File: file, Line: line}
+	if !slices.Contains(c.ProfileLines, pl) {
+		c.ProfileLines = append(c.ProfileLines, pl)
+	}
+}
+
+// UseRegistry persists UseConnection records in the config dir
+type UseRegistry struct {
+	path string
+}
+
+// NewUseRegistry creates a registry stored under paths' config dir
+func NewUseRegistry(paths *platform.Paths) *UseRegistry {
+	return &UseRegistry{path: filepath.Join(paths.ConfigDir(), UseRegistryFile)}
+}
+
+// List returns all recorded connections
+func (r *UseRegistry) List() ([]UseConnection, error) {
+	conns, err := utils.LoadJSONSlice[UseConnection](r.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read use registry: %w", err)
+	}
+	return conns, nil
+}
+
+// Get returns the connection recorded under id (its name or share code), or
+// nil if none is recorded
+func (r *UseRegistry) Get(id string) (*UseConnection, error) {
+	conns, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range conns {
+		if conns[i].ID() == id {
+			return &conns[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Record saves a connection, merging its artifacts into an existing record
+// with the same ID so repeated `ggo use` runs accumulate
+func (r *UseRegistry) Record(conn *UseConnection) error {
+	conns, err := r.List()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	idx := slices.IndexFunc(conns, func(c UseConnection) bool { return c.ID() == conn.ID() })
+	if idx < 0 {
+		if conn.CreatedAt.IsZero() {
+			conn.CreatedAt = now
+		}
+		conn.UpdatedAt = now
+		conns = append(conns, *conn)
+	} else {
+		existing := &conns[idx]
+		existing.AddFiles(conn.Files...)
+		existing.AddDirs(conn.Dirs...)
+		existing.AddPathEntries(conn.PathEntries...)
+		for _, pl := range conn.ProfileLines {
+			existing.AddProfileLine(pl.File, pl.Line)
+		}
+		if conn.WorkerID != "" {
+			existing.WorkerID = conn.WorkerID
+		}
+		existing.LongTerm = existing.LongTerm || conn.LongTerm
+		existing.WindowsEnv = existing.WindowsEnv || conn.WindowsEnv
+		existing.CI = existing.CI || conn.CI
+		existing.UpdatedAt = now
+	}
+	return utils.SaveJSONSlice(r.path, conns, 0644)
+}
+
+// Release forgets a connection and returns the artifacts that are safe to
+// delete: those not also referenced by another recorded connection. It
+// returns nil when id is not recorded.
+func (r *UseRegistry) Release(id string) (*UseConnection, error) {
+	conns, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(conns, func(c UseConnection) bool { return c.ID() == id })
+	if idx < 0 {
+		return nil, nil
+	}
+	orphaned := orphanedArtifacts(conns, idx)
+	if err := utils.SaveJSONSlice(r.path, slices.Delete(conns, idx, idx+1), 0644); err != nil {
+		return nil, err
+	}
+	return orphaned, nil
+}
+
+// Plan returns what Release would return for id without forgetting the
+// connection, e.g. to show what 'ggo clean --dry-run' would remove
+func (r *UseRegistry) Plan(id string) (*UseConnection, error) {
+	conns, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(conns, func(c UseConnection) bool { return c.ID() == id })
+	if idx < 0 {
+		return nil, nil
+	}
+	return orphanedArtifacts(conns, idx), nil
+}
+
+// orphanedArtifacts returns the artifacts of conns[idx] that no other
+// connection references
+func orphanedArtifacts(conns []UseConnection, idx int) *UseConnection {
+	released := conns[idx]
+	remaining := slices.Concat(conns[:idx], conns[idx+1:])
+
+	orphaned := &UseConnection{
+		Name:      released.Name,
+		ShortCode: released.ShortCode,
+		WorkerID:  released.WorkerID,
+		LongTerm:  released.LongTerm,
+		CI:        released.CI,
+		CreatedAt: released.CreatedAt,
+		UpdatedAt: released.UpdatedAt,
+	}
+	shared := func(match func(UseConnection) bool) bool {
+		return slices.ContainsFunc(remaining, match)
+	}
+	for _, f := range released.Files {
+		if !shared(func(c UseConnection) bool { return slices.Contains(c.Files, f) }) {
+			orphaned.AddFiles(f)
+		}
+	}
+	for _, d := range released.Dirs {
+		if !shared(func(c UseConnection) bool { return slices.Contains(c.Dirs, d) }) {
+			orphaned.AddDirs(d)
+		}
+	}
+	for _, e := range released.PathEntries {
+		if !shared(func(c UseConnection) bool { return slices.Contains(c.PathEntries, e) }) {
+			orphaned.AddPathEntries(e)
+		}
+	}
+	for _, pl := range released.ProfileLines {
+		if !shared(func(c UseConnection) bool { return slices.Contains(c.ProfileLines, pl) }) {
+			orphaned.AddProfileLine(pl.File, pl.Line)
+		}
+	}
+	orphaned.WindowsEnv = released.WindowsEnv &&
+		!shared(func(c UseConnection) bool { return c.WindowsEnv })
+	return orphaned
+}
+
+// ReleaseAll forgets every connection and returns all recorded artifacts
+func (r *UseRegistry) ReleaseAll() ([]UseConnection, error) {
+	conns, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.SaveJSONSlice(r.path, []UseConnection{}, 0644); err != nil {
+		return nil, err
+	}
+	return conns, nil
+}
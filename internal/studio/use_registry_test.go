@@ -0,0 +1,116 @@
+package studio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NexusGPU/gpu-go/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseRegistry_ReleaseKeepsSharedArtifacts(t *testing.T) {
+	reg := NewUseRegistry(platform.DefaultPaths().WithConfigDir(t.TempDir()))
+
+	conns, err := reg.List()
+	require.NoError(t, err)
+	assert.Empty(t, conns)
+
+	first := &UseConnection{ShortCode: "abc123", WorkerID: "w1"}
+	first.AddDirs("/home/u/.gpugo/studio/current-os/config")
+	first.AddFiles("/home/u/.gpugo/studio/current-os/config/env.sh")
+	first.AddPathEntries(`C:\gpugo\bin`, `C:\gpugo\libs`)
+	require.NoError(t, reg.Record(first))
+
+	// A later run for the same code adds to its record
+	again := &UseConnection{ShortCode: "abc123", LongTerm: true}
+	again.AddFiles("/home/u/.gpugo/profile.sh")
+	again.AddProfileLine("/home/u/.bashrc", "source /home/u/.gpugo/profile.sh")
+	require.NoError(t, reg.Record(again))
+
+	second := &UseConnection{ShortCode: "def456"}
+	second.AddDirs("/home/u/.gpugo/studio/current-os/config")
+	second.AddFiles("/home/u/.gpugo/studio/current-os/config/env.sh")
+	second.AddPathEntries(`C:\gpugo\libs`)
+	require.NoError(t, reg.Record(second))
+
+	recorded, err := reg.Get("abc123")
+	require.NoError(t, err)
+	require.NotNil(t, recorded)
+	assert.Equal(t, "w1", recorded.WorkerID)
+	assert.True(t, recorded.LongTerm)
+	assert.Len(t, recorded.Files, 2)
+
+	planned, err := reg.Plan("abc123")
+	require.NoError(t, err)
+	require.NotNil(t, planned)
+	recorded, err = reg.Get("abc123")
+	require.NoError(t, err)
+	require.NotNil(t, recorded, "planning keeps the record")
+
+	released, err := reg.Release("abc123")
+	require.NoError(t, err)
+	require.NotNil(t, released)
+	assert.Equal(t, planned, released)
+	assert.Equal(t, []string{"/home/u/.gpugo/profile.sh"}, released.Files, "files used by def456 are kept")
+	assert.Empty(t, released.Dirs)
+	assert.Equal(t, []string{`C:\gpugo\bin`}, released.PathEntries, "PATH entries used by def456 are kept")
+	assert.Equal(t, []ProfileLine{{File: "/home/u/.bashrc", Line: "source /home/u/.gpugo/profile.sh"}}, released.ProfileLines)
+
+	released, err = reg.Release("abc123")
+	require.NoError(t, err)
+	assert.Nil(t, released)
+
+	// Named environments for the same share are recorded separately
+	named := &UseConnection{Name: "training", ShortCode: "def456"}
+	named.AddDirs("/home/u/.gpugo/studio/use-training/config")
+	require.NoError(t, reg.Record(named))
+	recorded, err = reg.Get("training")
+	require.NoError(t, err)
+	require.NotNil(t, recorded)
+	assert.Equal(t, "def456", recorded.ShortCode)
+
+	all, err := reg.ReleaseAll()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "def456", all[0].ID())
+	assert.Equal(t, "training", all[1].ID())
+
+	conns, err = reg.List()
+	require.NoError(t, err)
+	assert.Empty(t, conns)
+}
+
+func TestUseRankingStore(t *testing.T) {
+	store := NewUseRankingStore(platform.DefaultPaths().WithConfigDir(t.TempDir()))
+
+	ranking, err := store.Get([]string{"abc", "def"}, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, ranking)
+
+	require.NoError(t, store.Record(&ShareRanking{
+		Codes: []string{"abc", "def"},
+		Results: []ShareProbeResult{
+			{ShortCode: "def", Healthy: false, Error: "connection refused"},
+			{ShortCode: "abc", Healthy: true, LatencyMs: 40},
+		},
+	}, time.Minute))
+
+	// The same set of codes in another order finds the ranking
+	ranking, err = store.Get([]string{"def", "abc"}, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, ranking)
+	assert.Equal(t, "abc", ranking.Fastest())
+
+	// Expired rankings are ignored
+	ranking, err = store.Get([]string{"abc", "def"}, 0)
+	require.NoError(t, err)
+	assert.Nil(t, ranking)
+
+	require.NoError(t, store.Forget([]string{"abc", "def"}))
+	ranking, err = store.Get([]string{"abc", "def"}, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, ranking)
+
+	assert.Empty(t, (&ShareRanking{Results: []ShareProbeResult{{ShortCode: "x"}}}).Fastest())
+}
@@ -0,0 +1,455 @@
+package studio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/errors"
+	"k8s.io/klog/v2"
+)
+
+// Labels of the named volumes ggo creates
+const (
+	// VolumeLabelManaged marks volumes created by ggo
+	VolumeLabelManaged = "ggo.managed"
+	// VolumeLabelStudio names the studio a volume was created for
+	VolumeLabelStudio = "ggo.studio"
+)
+
+var (
+	// namedVolumePattern matches the volume names docker accepts; other
+	// sources of a mount are host paths
+	namedVolumePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+	// anonymousVolumePattern matches the generated names of anonymous volumes
+	anonymousVolumePattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// IsNamedVolume reports whether the source of a mount names a volume rather
+// than a host path
+func IsNamedVolume(source string) bool {
+	return namedVolumePattern.MatchString(source) && !anonymousVolumePattern.MatchString(source)
+}
+
+// NamedVolume is a named volume of a container runtime
+type NamedVolume struct {
+	Name   string `json:"name"`
+	Mode   Mode   `json:"mode"`
+	Driver string `json:"driver,omitempty"`
+	// Studio is the studio the volume was created for, if any
+	Studio    string            `json:"studio,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	CreatedAt string            `json:"created_at,omitempty"`
+	// UsedBy lists the studios, or other containers, mounting the volume
+	UsedBy []string `json:"used_by"`
+}
+
+// NamedVolumeBackend is an optional interface for backends that manage named
+// volumes, which outlive the environments mounting them
+type NamedVolumeBackend interface {
+	VolumeBackend
+	// CreateVolume creates a named volume with labels
+	CreateVolume(ctx context.Context, name string, labels map[string]string) error
+	// InspectVolume returns a volume, or a not found error
+	InspectVolume(ctx context.Context, name string) (*NamedVolume, error)
+	// ListVolumes returns the volumes created by ggo
+	ListVolumes(ctx context.Context) ([]*NamedVolume, error)
+	// RemoveVolume removes a volume no container mounts
+	RemoveVolume(ctx context.Context, name string) error
+	// VolumeUsers returns the studios, or names of other containers, that
+	// mount a volume, running or not
+	VolumeUsers(ctx context.Context, name string) ([]string, error)
+}
+
+// namedVolumeBackend returns the backend for mode if it manages named volumes
+func (m *Manager) namedVolumeBackend(mode Mode) (NamedVolumeBackend, error) {
+	backend, err := m.GetBackend(mode)
+	if err != nil {
+		return nil, err
+	}
+	volumeBackend, ok := backend.(NamedVolumeBackend)
+	if !ok {
+		return nil, errors.Unavailable(fmt.Sprintf("%s backend does not support named volumes", backend.Name()))
+	}
+	return volumeBackend, nil
+}
+
+// CreateVolume creates a named volume, optionally tied to a studio by label
+func (m *Manager) CreateVolume(ctx context.Context, mode Mode, name, studio string) (*NamedVolume, error) {
+	if !IsNamedVolume(name) {
+		return nil, errors.BadRequest(fmt.Sprintf("invalid volume name %q: use letters, digits, '_', '.' and '-', starting with a letter or digit", name))
+	}
+	backend, err := m.namedVolumeBackend(mode)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := backend.InspectVolume(ctx, name); err == nil {
+		return nil, errors.Conflict("volume", fmt.Sprintf("%s already exists", name))
+	}
+	if err := backend.CreateVolume(ctx, name, volumeLabels(studio)); err != nil {
+		return nil, err
+	}
+	return backend.InspectVolume(ctx, name)
+}
+
+// ListVolumes returns the volumes created by ggo with the studios using them.
+// With ModeAuto, the volumes of every available backend are listed.
+func (m *Manager) ListVolumes(ctx context.Context, mode Mode) ([]*NamedVolume, error) {
+	var backends []NamedVolumeBackend
+	if mode == ModeAuto {
+		for _, backend := range m.ListAvailableBackends(ctx) {
+			if volumeBackend, ok := backend.(NamedVolumeBackend); ok {
+				backends = append(backends, volumeBackend)
+			}
+		}
+	} else {
+		backend, err := m.namedVolumeBackend(mode)
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, backend)
+	}
+
+	var volumes []*NamedVolume
+	for _, backend := range backends {
+		listed, err := backend.ListVolumes(ctx)
+		if err != nil {
+			if mode != ModeAuto {
+				return nil, err
+			}
+			klog.Warningf("Failed to list volumes: backend=%s error=%v", backend.Name(), err)
+			continue
+		}
+		for _, v := range listed {
+			if v.UsedBy, err = backend.VolumeUsers(ctx, v.Name); err != nil {
+				klog.Warningf("Failed to list users of volume %s: %v", v.Name, err)
+			}
+		}
+		volumes = append(volumes, listed...)
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		if volumes[i].Mode != volumes[j].Mode {
+			return volumes[i].Mode < volumes[j].Mode
+		}
+		return volumes[i].Name < volumes[j].Name
+	})
+	return volumes, nil
+}
+
+// RemoveVolume removes a named volume. Volumes still mounted by a studio are
+// refused unless force is set, in which case the runtime decides.
+func (m *Manager) RemoveVolume(ctx context.Context, mode Mode, name string, force bool) error {
+	backend, err := m.namedVolumeBackend(mode)
+	if err != nil {
+		return err
+	}
+	if _, err := backend.InspectVolume(ctx, name); err != nil {
+		return err
+	}
+	if !force {
+		users, err := backend.VolumeUsers(ctx, name)
+		if err != nil {
+			return err
+		}
+		if len(users) > 0 {
+			return errors.Conflict("volume", fmt.Sprintf("%s is used by %s; remove those first", name, strings.Join(users, ", ")))
+		}
+	}
+	return backend.RemoveVolume(ctx, name)
+}
+
+// OrphanedVolumes returns the named volumes mounted by the given environments
+// that nothing else mounts, i.e. that are left unused once the environments
+// are removed
+func (m *Manager) OrphanedVolumes(ctx context.Context, idOrNames []string) ([]*NamedVolume, error) {
+	type envVolumes struct {
+		backend NamedVolumeBackend
+		mode    Mode
+		names   []string
+	}
+	removing := make(map[string]bool, len(idOrNames))
+	var mounted []envVolumes
+	for _, idOrName := range idOrNames {
+		env, err := m.Get(ctx, idOrName)
+		if err != nil || env.Status == StatusDeleted || env.Status == StatusUnknown {
+			continue
+		}
+		removing[env.Name] = true
+		backend, err := m.namedVolumeBackend(env.Mode)
+		if err != nil {
+			continue
+		}
+		mounts, err := backend.Volumes(ctx, env.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list volumes of %s: %w", env.Name, err)
+		}
+		var names []string
+		for _, mount := range mounts {
+			if IsNamedVolume(mount.HostPath) {
+				names = append(names, mount.HostPath)
+			}
+		}
+		mounted = append(mounted, envVolumes{backend: backend, mode: env.Mode, names: names})
+	}
+
+	var orphans []*NamedVolume
+	seen := make(map[string]bool)
+	for _, ev := range mounted {
+		for _, name := range ev.names {
+			key := string(ev.mode) + "/" + name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			users, err := ev.backend.VolumeUsers(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list users of volume %s: %w", name, err)
+			}
+			if slices.ContainsFunc(users, func(user string) bool { return !removing[user] }) {
+				continue
+			}
+			orphans = append(orphans, &NamedVolume{Name: name, Mode: ev.mode, UsedBy: users})
+		}
+	}
+	return orphans, nil
+}
+
+// ensureNamedVolumes creates the named volumes among mounts that do not exist
+// yet, labelled with the studio mounting them, so 'ggo studio volume ls' shows
+// them
+func ensureNamedVolumes(ctx context.Context, backend Backend, studio string, mounts []VolumeMount) error {
+	volumeBackend, ok := backend.(NamedVolumeBackend)
+	if !ok {
+		return nil
+	}
+	for _, v := range mounts {
+		if !IsNamedVolume(v.HostPath) {
+			continue
+		}
+		if _, err := volumeBackend.InspectVolume(ctx, v.HostPath); err == nil {
+			continue
+		}
+		if err := volumeBackend.CreateVolume(ctx, v.HostPath, volumeLabels(studio)); err != nil {
+			return fmt.Errorf("failed to create volume %s: %w", v.HostPath, err)
+		}
+		klog.Infof("Created volume %s for studio %s", v.HostPath, studio)
+	}
+	return nil
+}
+
+func volumeLabels(studio string) map[string]string {
+	labels := map[string]string{VolumeLabelManaged: "true"}
+	if studio != "" {
+		labels[VolumeLabelStudio] = studio
+	}
+	return labels
+}
+
+// dockerVolumeInspect is the subset of `docker volume inspect` output used
+type dockerVolumeInspect struct {
+	Name      string            `json:"Name"`
+	Driver    string            `json:"Driver"`
+	Labels    map[string]string `json:"Labels"`
+	CreatedAt string            `json:"CreatedAt"`
+}
+
+func (v *dockerVolumeInspect) toNamedVolume(mode Mode) *NamedVolume {
+	return &NamedVolume{
+		Name:      v.Name,
+		Mode:      mode,
+		Driver:    v.Driver,
+		Studio:    v.Labels[VolumeLabelStudio],
+		Labels:    v.Labels,
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+func dockerInspectVolumes(ctx context.Context, run dockerRunner, mode Mode, names ...string) ([]*NamedVolume, error) {
+	output, err := run(ctx, append([]string{"volume", "inspect"}, names...)...)
+	if err != nil {
+		if strings.Contains(strings.ToLower(string(output)), "no such volume") {
+			return nil, errors.NotFound("volume", strings.Join(names, ", "))
+		}
+		return nil, fmt.Errorf("failed to inspect volume: %w, output: %s", err, output)
+	}
+	var inspected []dockerVolumeInspect
+	if err := json.Unmarshal(output, &inspected); err != nil {
+		return nil, fmt.Errorf("failed to parse volume inspect output: %w", err)
+	}
+	volumes := make([]*NamedVolume, 0, len(inspected))
+	for i := range inspected {
+		volumes = append(volumes, inspected[i].toNamedVolume(mode))
+	}
+	return volumes, nil
+}
+
+func dockerCreateVolume(ctx context.Context, run dockerRunner, name string, labels map[string]string) error {
+	args := []string{"volume", "create"}
+	for _, k := range SortedKeys(labels) {
+		args = append(args, "--label", k+"="+labels[k])
+	}
+	if output, err := run(ctx, append(args, name)...); err != nil {
+		return fmt.Errorf("failed to create volume: %w, output: %s", err, output)
+	}
+	return nil
+}
+
+func dockerInspectVolume(ctx context.Context, run dockerRunner, mode Mode, name string) (*NamedVolume, error) {
+	volumes, err := dockerInspectVolumes(ctx, run, mode, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(volumes) == 0 {
+		return nil, errors.NotFound("volume", name)
+	}
+	return volumes[0], nil
+}
+
+func dockerListVolumes(ctx context.Context, run dockerRunner, mode Mode) ([]*NamedVolume, error) {
+	output, err := run(ctx, "volume", "ls", "-q", "--filter", "label="+VolumeLabelManaged+"=true")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w, output: %s", err, output)
+	}
+	names := strings.Fields(string(output))
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return dockerInspectVolumes(ctx, run, mode, names...)
+}
+
+func dockerRemoveVolume(ctx context.Context, run dockerRunner, name string) error {
+	if output, err := run(ctx, "volume", "rm", name); err != nil {
+		return fmt.Errorf("failed to remove volume: %w, output: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// dockerVolumeUsers returns the studio names, or container names for other
+// containers, of the containers mounting a volume
+func dockerVolumeUsers(ctx context.Context, run dockerRunner, name string) ([]string, error) {
+	output, err := run(ctx, "ps", "-a", "--filter", "volume="+name, "--format", `{{.Names}}\t{{.Label "ggo.name"}}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w, output: %s", err, output)
+	}
+	return parseVolumeUsers(string(output)), nil
+}
+
+func parseVolumeUsers(output string) []string {
+	users := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		container, studio, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		switch {
+		case studio != "":
+			users = append(users, studio)
+		case container != "":
+			users = append(users, container)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// CreateVolume implements NamedVolumeBackend
+func (b *DockerBackend) CreateVolume(ctx context.Context, name string, labels map[string]string) error {
+	return dockerCreateVolume(ctx, b.runDocker, name, labels)
+}
+
+// InspectVolume implements NamedVolumeBackend
+func (b *DockerBackend) InspectVolume(ctx context.Context, name string) (*NamedVolume, error) {
+	return dockerInspectVolume(ctx, b.runDocker, b.Mode(), name)
+}
+
+// ListVolumes implements NamedVolumeBackend
+func (b *DockerBackend) ListVolumes(ctx context.Context) ([]*NamedVolume, error) {
+	return dockerListVolumes(ctx, b.runDocker, b.Mode())
+}
+
+// RemoveVolume implements NamedVolumeBackend
+func (b *DockerBackend) RemoveVolume(ctx context.Context, name string) error {
+	return dockerRemoveVolume(ctx, b.runDocker, name)
+}
+
+// VolumeUsers implements NamedVolumeBackend
+func (b *DockerBackend) VolumeUsers(ctx context.Context, name string) ([]string, error) {
+	return dockerVolumeUsers(ctx, b.runDocker, name)
+}
+
+// CreateVolume implements NamedVolumeBackend
+func (b *ColimaBackend) CreateVolume(ctx context.Context, name string, labels map[string]string) error {
+	return dockerCreateVolume(ctx, b.runDocker, name, labels)
+}
+
+// InspectVolume implements NamedVolumeBackend
+func (b *ColimaBackend) InspectVolume(ctx context.Context, name string) (*NamedVolume, error) {
+	return dockerInspectVolume(ctx, b.runDocker, b.Mode(), name)
+}
+
+// ListVolumes implements NamedVolumeBackend
+func (b *ColimaBackend) ListVolumes(ctx context.Context) ([]*NamedVolume, error) {
+	return dockerListVolumes(ctx, b.runDocker, b.Mode())
+}
+
+// RemoveVolume implements NamedVolumeBackend
+func (b *ColimaBackend) RemoveVolume(ctx context.Context, name string) error {
+	return dockerRemoveVolume(ctx, b.runDocker, name)
+}
+
+// VolumeUsers implements NamedVolumeBackend
+func (b *ColimaBackend) VolumeUsers(ctx context.Context, name string) ([]string, error) {
+	return dockerVolumeUsers(ctx, b.runDocker, name)
+}
+
+// CreateVolume implements NamedVolumeBackend
+func (b *WSLBackend) CreateVolume(ctx context.Context, name string, labels map[string]string) error {
+	run, err := b.dockerRunner(ctx)
+	if err != nil {
+		return err
+	}
+	return dockerCreateVolume(ctx, run, name, labels)
+}
+
+// InspectVolume implements NamedVolumeBackend
+func (b *WSLBackend) InspectVolume(ctx context.Context, name string) (*NamedVolume, error) {
+	run, err := b.dockerRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dockerInspectVolume(ctx, run, b.Mode(), name)
+}
+
+// ListVolumes implements NamedVolumeBackend
+func (b *WSLBackend) ListVolumes(ctx context.Context) ([]*NamedVolume, error) {
+	run, err := b.dockerRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dockerListVolumes(ctx, run, b.Mode())
+}
+
+// RemoveVolume implements NamedVolumeBackend
+func (b *WSLBackend) RemoveVolume(ctx context.Context, name string) error {
+	run, err := b.dockerRunner(ctx)
+	if err != nil {
+		return err
+	}
+	return dockerRemoveVolume(ctx, run, name)
+}
+
+// VolumeUsers implements NamedVolumeBackend
+func (b *WSLBackend) VolumeUsers(ctx context.Context, name string) ([]string, error) {
+	run, err := b.dockerRunner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return dockerVolumeUsers(ctx, run, name)
+}
+
+var (
+	_ NamedVolumeBackend = (*DockerBackend)(nil)
+	_ NamedVolumeBackend = (*ColimaBackend)(nil)
+	_ NamedVolumeBackend = (*WSLBackend)(nil)
+)
@@ -0,0 +1,167 @@
+package studio
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNamedVolume(t *testing.T) {
+	for source, named := range map[string]bool{
+		"data":           true,
+		"my-env_cache.1": true,
+		"/home/me/code":  false,
+		"./code":         false,
+		"~/code":         false,
+		"C":              false,
+		"3f9a0c1e2d4b5a6978c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2": false,
+	} {
+		assert.Equal(t, named, IsNamedVolume(source), source)
+	}
+}
+
+func TestParseVolumeUsers(t *testing.T) {
+	output := "ggo-my-env\tmy-env\npostgres\t\nggo-other\tother\n"
+	assert.Equal(t, []string{"my-env", "other", "postgres"}, parseVolumeUsers(output))
+	assert.Empty(t, parseVolumeUsers(""))
+}
+
+func TestDockerVolumes(t *testing.T) {
+	var calls []string
+	run := func(_ context.Context, args ...string) ([]byte, error) {
+		call := strings.Join(args, " ")
+		calls = append(calls, call)
+		switch {
+		case call == "volume ls -q --filter label=ggo.managed=true":
+			return []byte("data\ncache\n"), nil
+		case call == "volume inspect data cache":
+			return []byte(`[{"Name":"data","Driver":"local","Labels":{"ggo.managed":"true","ggo.studio":"my-env"},"CreatedAt":"2026-10-01T10:00:00Z"},
+				{"Name":"cache","Driver":"local","Labels":{"ggo.managed":"true"}}]`), nil
+		case call == "volume inspect missing":
+			return []byte("Error response from daemon: get missing: no such volume"), errors.New("exit status 1")
+		}
+		return nil, nil
+	}
+	ctx := context.Background()
+
+	volumes, err := dockerListVolumes(ctx, run, ModeDocker)
+	require.NoError(t, err)
+	require.Len(t, volumes, 2)
+	assert.Equal(t, "data", volumes[0].Name)
+	assert.Equal(t, "my-env", volumes[0].Studio)
+	assert.Equal(t, ModeDocker, volumes[0].Mode)
+	assert.Empty(t, volumes[1].Studio)
+
+	_, err = dockerInspectVolume(ctx, run, ModeDocker, "missing")
+	assert.ErrorContains(t, err, "not found")
+
+	require.NoError(t, dockerCreateVolume(ctx, run, "data2", volumeLabels("my-env")))
+	assert.Equal(t, "volume create --label ggo.managed=true --label ggo.studio=my-env data2", calls[len(calls)-1])
+}
+
+// namedVolumeMockBackend is a MockBackend with named volumes
+type namedVolumeMockBackend struct {
+	*volumeMockBackend
+	named map[string]*NamedVolume
+	users map[string][]string
+}
+
+func (b *namedVolumeMockBackend) CreateVolume(ctx context.Context, name string, labels map[string]string) error {
+	b.named[name] = &NamedVolume{Name: name, Mode: b.mode, Labels: labels, Studio: labels[VolumeLabelStudio]}
+	return nil
+}
+
+func (b *namedVolumeMockBackend) InspectVolume(ctx context.Context, name string) (*NamedVolume, error) {
+	if v, ok := b.named[name]; ok {
+		return v, nil
+	}
+	return nil, errors.New("no such volume")
+}
+
+func (b *namedVolumeMockBackend) ListVolumes(ctx context.Context) ([]*NamedVolume, error) {
+	var volumes []*NamedVolume
+	for _, v := range b.named {
+		volumes = append(volumes, v)
+	}
+	return volumes, nil
+}
+
+func (b *namedVolumeMockBackend) RemoveVolume(ctx context.Context, name string) error {
+	delete(b.named, name)
+	return nil
+}
+
+func (b *namedVolumeMockBackend) VolumeUsers(ctx context.Context, name string) ([]string, error) {
+	return b.users[name], nil
+}
+
+func newNamedVolumeManager(t *testing.T) (*Manager, *namedVolumeMockBackend) {
+	m, backend, _ := newRebuildManager(t)
+	named := &namedVolumeMockBackend{volumeMockBackend: backend, named: map[string]*NamedVolume{}, users: map[string][]string{}}
+	m.RegisterBackend(named)
+	return m, named
+}
+
+func TestManager_CreateLabelsNamedVolumes(t *testing.T) {
+	m, backend := newNamedVolumeManager(t)
+	backend.named["shared"] = &NamedVolume{Name: "shared", Mode: ModeDocker}
+
+	_, err := m.Create(context.Background(), &CreateOptions{
+		Name:  "my-env",
+		Mode:  ModeDocker,
+		Image: "studio:1",
+		Volumes: []VolumeMount{
+			{HostPath: "data", ContainerPath: "/data"},
+			{HostPath: "shared", ContainerPath: "/shared"},
+			{HostPath: "/home/me/code", ContainerPath: "/code"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Contains(t, backend.named, "data")
+	assert.Equal(t, "my-env", backend.named["data"].Studio)
+	assert.Empty(t, backend.named["shared"].Labels, "existing volumes are left alone")
+	assert.Len(t, backend.named, 2)
+}
+
+func TestManager_OrphanedVolumes(t *testing.T) {
+	m, backend := newNamedVolumeManager(t)
+	backend.envs["env-1"] = &Environment{ID: "env-1", Name: "my-env", Mode: ModeDocker, Status: StatusRunning}
+	backend.envs["env-2"] = &Environment{ID: "env-2", Name: "other", Mode: ModeDocker, Status: StatusRunning}
+	backend.volumes["env-1"] = []VolumeMount{
+		{HostPath: "data", ContainerPath: "/data"},
+		{HostPath: "shared", ContainerPath: "/shared"},
+		{HostPath: "3f9a0c1e2d4b5a6978c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2", ContainerPath: "/var/lib"},
+	}
+	backend.volumes["env-2"] = []VolumeMount{{HostPath: "shared", ContainerPath: "/shared"}}
+	backend.users["data"] = []string{"my-env"}
+	backend.users["shared"] = []string{"my-env", "other"}
+	ctx := context.Background()
+
+	orphans, err := m.OrphanedVolumes(ctx, []string{"my-env"})
+	require.NoError(t, err)
+	require.Len(t, orphans, 1)
+	assert.Equal(t, "data", orphans[0].Name)
+
+	orphans, err = m.OrphanedVolumes(ctx, []string{"my-env", "other"})
+	require.NoError(t, err)
+	assert.Len(t, orphans, 2, "volumes shared only among removed studios are orphaned too")
+}
+
+func TestManager_RemoveVolumeInUse(t *testing.T) {
+	m, backend := newNamedVolumeManager(t)
+	backend.named["data"] = &NamedVolume{Name: "data", Mode: ModeDocker}
+	backend.users["data"] = []string{"my-env"}
+	ctx := context.Background()
+
+	err := m.RemoveVolume(ctx, ModeDocker, "data", false)
+	assert.ErrorContains(t, err, "used by my-env")
+	assert.Contains(t, backend.named, "data")
+
+	require.NoError(t, m.RemoveVolume(ctx, ModeDocker, "data", true))
+	assert.NotContains(t, backend.named, "data")
+}
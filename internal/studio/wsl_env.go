@@ -0,0 +1,241 @@
+package studio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// wslEnvDir is where `ggo use --wsl` environments live, relative to the
+// distro user's home directory
+const wslEnvDir = ".gpugo/wsl"
+
+// WSLEnvTarget is a WSL distribution to set up a `ggo use` environment in
+// from the Windows host. The Linux GPU client libraries and the env script
+// are written into the distro's filesystem, so Linux toolchains running in
+// WSL use the remote GPU.
+type WSLEnvTarget struct {
+	// Distro is the WSL distribution; the default one when empty
+	Distro string
+}
+
+// WSLEnvOptions describes the environment to set up in the distro
+type WSLEnvOptions struct {
+	// LibsDir holds the Linux GPU client libraries for the distro's arch,
+	// see EnsureContainerLibraries
+	LibsDir string
+	Vendor  GPUVendor
+	// ConnectionURL is the GPU worker the environment connects to
+	ConnectionURL string
+	// Name names the environment's directory in the distro
+	Name string
+	// ConnectionName is exported as ConnectionEnv, see GPUEnvConfig
+	ConnectionName string
+}
+
+// WSLEnvResult describes an environment set up in a WSL distro. Paths are
+// Linux paths inside the distro, except HostDir.
+type WSLEnvResult struct {
+	Distro    string   `json:"distro"`
+	Dir       string   `json:"dir"`
+	EnvFile   string   `json:"env_file"`
+	RCFile    string   `json:"rc_file"`
+	Libraries []string `json:"libraries"`
+	// HostDir is Dir as seen from Windows, e.g. \\wsl$\Ubuntu\home\me\...
+	HostDir string            `json:"host_dir"`
+	EnvVars map[string]string `json:"env"`
+}
+
+// ResolveDistro fills in the default distribution when none was given
+func (t *WSLEnvTarget) ResolveDistro(ctx context.Context) error {
+	if t.Distro != "" {
+		return nil
+	}
+	distro, err := NewWSLBackend().getDefaultDistro(ctx)
+	if err != nil {
+		return err
+	}
+	t.Distro = distro
+	return nil
+}
+
+// run runs a command as the distro's default user, feeding it stdin when given
+func (t *WSLEnvTarget) run(ctx context.Context, stdin io.Reader, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "wsl", append([]string{"-d", t.Distro, "--"}, args...)...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("wsl -d %s %s: %w: %s", t.Distro, args[0], err, msg)
+		}
+		return "", fmt.Errorf("wsl -d %s %s: %w", t.Distro, args[0], err)
+	}
+	return string(output), nil
+}
+
+// shell runs a shell script in the distro with input as its standard input
+func (t *WSLEnvTarget) shell(ctx context.Context, input []byte, script string) error {
+	var stdin io.Reader
+	if input != nil {
+		stdin = bytes.NewReader(input)
+	}
+	_, err := t.run(ctx, stdin, "sh", "-c", script)
+	return err
+}
+
+// copyFile copies a host file to dst inside the distro
+func (t *WSLEnvTarget) copyFile(ctx context.Context, src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = t.run(ctx, f, "sh", "-c", "cat > "+ShellQuote(dst))
+	return err
+}
+
+// Arch returns the CPU architecture of the distro, such as amd64
+func (t *WSLEnvTarget) Arch(ctx context.Context) (string, error) {
+	output, err := t.run(ctx, nil, "uname", "-m")
+	if err != nil {
+		return "", fmt.Errorf("failed to run a command in WSL distribution %s: %w", t.Distro, err)
+	}
+	arch := NormalizeArch(strings.TrimSpace(output))
+	if arch == "" {
+		return "", fmt.Errorf("failed to detect the architecture of WSL distribution %s", t.Distro)
+	}
+	return arch, nil
+}
+
+// home returns the home directory of the distro's default user
+func (t *WSLEnvTarget) home(ctx context.Context) (string, error) {
+	output, err := t.run(ctx, nil, "sh", "-c", `printf %s "$HOME"`)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the home directory in WSL distribution %s: %w", t.Distro, err)
+	}
+	home := strings.TrimSpace(output)
+	if !strings.HasPrefix(home, "/") {
+		return "", fmt.Errorf("unexpected home directory %q in WSL distribution %s", home, t.Distro)
+	}
+	return home, nil
+}
+
+// Setup copies the GPU client libraries into the distro and writes the env
+// script that activates them, plus a bash rc file that sources the user's
+// ~/.bashrc and then the env script. Running it again replaces what an
+// earlier run wrote.
+func (t *WSLEnvTarget) Setup(ctx context.Context, opts *WSLEnvOptions) (*WSLEnvResult, error) {
+	libs, err := listLibraries(opts.LibsDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(libs) == 0 {
+		return nil, fmt.Errorf("no GPU client libraries in %s", opts.LibsDir)
+	}
+	home, err := t.home(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := path.Join(home, wslEnvDir, opts.Name)
+	result := &WSLEnvResult{
+		Distro:    t.Distro,
+		Dir:       dir,
+		EnvFile:   path.Join(dir, "env.sh"),
+		RCFile:    path.Join(dir, "bashrc"),
+		Libraries: libs,
+		HostDir:   WSLHostPath(t.Distro, dir),
+		EnvVars:   wslEnvVars(opts, dir, FindActualLibraryFiles(opts.LibsDir, opts.Vendor)),
+	}
+
+	libsDir := path.Join(dir, "libs")
+	// Libraries of an earlier run may belong to another release
+	if err := t.shell(ctx, nil, fmt.Sprintf("rm -rf %[1]s && mkdir -p %[1]s %[2]s %[3]s",
+		ShellQuote(libsDir), ShellQuote(path.Join(dir, "logs")), ShellQuote(path.Join(dir, "connections")))); err != nil {
+		return nil, fmt.Errorf("failed to create directories in WSL distribution %s: %w", t.Distro, err)
+	}
+	for _, lib := range libs {
+		if err := t.copyFile(ctx, filepath.Join(opts.LibsDir, lib), path.Join(libsDir, lib)); err != nil {
+			return nil, fmt.Errorf("failed to copy %s into WSL distribution %s: %w", lib, t.Distro, err)
+		}
+	}
+	klog.Infof("Copied GPU client libraries into WSL: distro=%s dir=%s count=%d", t.Distro, libsDir, len(libs))
+
+	if err := t.shell(ctx, []byte(wslEnvScript(result.EnvVars, opts)), "cat > "+ShellQuote(result.EnvFile)); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", result.EnvFile, err)
+	}
+	if err := t.shell(ctx, []byte(wslRCFile(result.EnvFile)), "cat > "+ShellQuote(result.RCFile)); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", result.RCFile, err)
+	}
+	klog.Infof("Set up GPU environment in WSL: distro=%s dir=%s vendor=%s", t.Distro, dir, opts.Vendor)
+	return result, nil
+}
+
+// wslEnvVars returns the settings of an environment in dir. The library
+// paths are set outright, so that Windows can pass them to wsl.exe through
+// WSLENV; the env script prepends them to the distro's own instead.
+func wslEnvVars(opts *WSLEnvOptions, dir string, preloadLibs []string) map[string]string {
+	libsDir := path.Join(dir, "libs")
+	env := map[string]string{
+		"TENSOR_FUSION_OPERATOR_CONNECTION_INFO": opts.ConnectionURL,
+		"TF_LOG_PATH":                            path.Join(dir, "logs", "client.log"),
+		"TF_LOG_LEVEL":                           getEnvDefault("TF_LOG_LEVEL", "info"),
+		"TF_ENABLE_LOG":                          getEnvDefault("TF_ENABLE_LOG", "1"),
+		"TF_CONNECTION_INFO_PATH":                path.Join(dir, "connections", opts.Name+".txt"),
+		"LD_LIBRARY_PATH":                        libsDir,
+	}
+	if len(preloadLibs) > 0 {
+		preload := make([]string, 0, len(preloadLibs))
+		for _, lib := range preloadLibs {
+			preload = append(preload, path.Join(libsDir, lib))
+		}
+		env["LD_PRELOAD"] = strings.Join(preload, ":")
+	}
+	if opts.ConnectionName != "" {
+		env[ConnectionEnv] = opts.ConnectionName
+	}
+	return env
+}
+
+// wslEnvScript renders env as a script sourced inside the distro
+func wslEnvScript(env map[string]string, opts *WSLEnvOptions) string {
+	var script strings.Builder
+	script.WriteString("# GPU Go environment setup script for WSL\n")
+	script.WriteString("# Generated by ggo use --wsl\n\n")
+	for _, k := range SortedKeys(env) {
+		switch k {
+		case "LD_LIBRARY_PATH", "LD_PRELOAD":
+			fmt.Fprintf(&script, "export %[1]s=%[2]s\"${%[1]s:+:$%[1]s}\"\n", k, ShellQuote(env[k]))
+		default:
+			fmt.Fprintf(&script, "export %s=%s\n", k, ShellQuote(env[k]))
+		}
+	}
+	script.WriteString("\n")
+	fmt.Fprintf(&script, "echo %s >&2\n", ShellQuote("GPU Go environment activated for vendor: "+string(opts.Vendor)))
+	return script.String()
+}
+
+// wslRCFile returns a bash rc file for `bash --rcfile` that keeps the user's
+// own settings and activates the environment
+func wslRCFile(envFile string) string {
+	return "# Generated by ggo use --wsl\n" +
+		"[ -f ~/.bashrc ] && . ~/.bashrc\n" +
+		". " + ShellQuote(envFile) + "\n"
+}
+
+// WSLHostPath returns the Windows path of a file inside a WSL distribution
+func WSLHostPath(distro, linuxPath string) string {
+	return `\\wsl$\` + distro + strings.ReplaceAll(linuxPath, "/", `\`)
+}
@@ -0,0 +1,91 @@
+package studio
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWSL puts a wsl on PATH that runs the command after "--" locally, with
+// home as the distro user's home directory
+func fakeWSL(t *testing.T) (home string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wsl is a shell script")
+	}
+	dir := t.TempDir()
+	home = t.TempDir()
+	script := `#!/bin/sh
+[ "$1" = "-d" ] && [ "$3" = "--" ] || exit 2
+shift 3
+HOME="` + home + `" exec "$@"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wsl"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return home
+}
+
+func TestWSLEnvTarget_Setup(t *testing.T) {
+	home := fakeWSL(t)
+	libsDir := t.TempDir()
+	for _, lib := range []string{"libcuda.so", "libnvidia-ml.so", "libteleport.so"} {
+		require.NoError(t, os.WriteFile(filepath.Join(libsDir, lib), []byte(lib), 0644))
+	}
+	target := &WSLEnvTarget{Distro: "Ubuntu"}
+	ctx := context.Background()
+	require.NoError(t, target.ResolveDistro(ctx))
+	assert.Equal(t, "Ubuntu", target.Distro)
+
+	arch, err := target.Arch(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, arch)
+
+	opts := &WSLEnvOptions{
+		LibsDir:        libsDir,
+		Vendor:         VendorNvidia,
+		ConnectionURL:  "native+10.0.0.5+9001+abc123",
+		Name:           "abc123",
+		ConnectionName: "abc123",
+	}
+	result, err := target.Setup(ctx, opts)
+	require.NoError(t, err)
+
+	dir := home + "/.gpugo/wsl/abc123"
+	assert.Equal(t, dir, result.Dir)
+	assert.Equal(t, dir+"/env.sh", result.EnvFile)
+	assert.Equal(t, `\\wsl$\Ubuntu`+strings.ReplaceAll(dir, "/", `\`), result.HostDir)
+	assert.Equal(t, []string{"libcuda.so", "libnvidia-ml.so", "libteleport.so"}, result.Libraries)
+	assert.Equal(t, dir+"/libs/libcuda.so:"+dir+"/libs/libnvidia-ml.so", result.EnvVars["LD_PRELOAD"])
+	assert.Equal(t, "abc123", result.EnvVars[ConnectionEnv])
+
+	data, err := os.ReadFile(filepath.Join(dir, "libs", "libteleport.so"))
+	require.NoError(t, err)
+	assert.Equal(t, "libteleport.so", string(data), "libraries are copied into the distro")
+
+	// The env script prepends the libraries to the distro's own settings
+	cmd := exec.Command("sh", "-c", `. "$0" 2>/dev/null; printf '%s\n%s\n' "$LD_LIBRARY_PATH" "$TENSOR_FUSION_OPERATOR_CONNECTION_INFO"`, result.EnvFile)
+	cmd.Env = append(os.Environ(), "LD_LIBRARY_PATH=/usr/lib/wsl/lib")
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, dir+"/libs:/usr/lib/wsl/lib\nnative+10.0.0.5+9001+abc123\n", string(output))
+
+	rc, err := os.ReadFile(result.RCFile)
+	require.NoError(t, err)
+	assert.Equal(t, "# Generated by ggo use --wsl\n[ -f ~/.bashrc ] && . ~/.bashrc\n. '"+result.EnvFile+"'\n", string(rc))
+
+	// Libraries of an earlier run are replaced
+	require.NoError(t, os.Remove(filepath.Join(libsDir, "libteleport.so")))
+	_, err = target.Setup(ctx, opts)
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "libs", "libteleport.so"))
+
+	_, err = target.Setup(ctx, &WSLEnvOptions{LibsDir: t.TempDir(), Name: "empty"})
+	assert.ErrorContains(t, err, "no GPU client libraries")
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 )
 
 // OutputFormat represents the output format type
@@ -81,7 +83,7 @@ func (o *Output) PrintJSON(data interface{}) error {
 // PrintTable outputs data as a styled table
 func (o *Output) PrintTable(headers []string, rows [][]string) {
 	if len(rows) == 0 {
-		_, _ = fmt.Fprintln(o.config.Writer, o.config.Styles.Muted.Render("No items found"))
+		_, _ = fmt.Fprintln(o.config.Writer, o.config.Styles.Muted.Render(i18n.T("No items found")))
 		return
 	}
 	_, _ = fmt.Fprintln(o.config.Writer, SimpleTable(headers, rows))
@@ -140,17 +142,43 @@ func (o *Output) Warning(message string) {
 	}
 }
 
-// Println prints a line (only in table format)
+// Successf prints a formatted success message (only in table format)
+func (o *Output) Successf(format string, a ...any) {
+	o.Success(i18n.Tf(format, a...))
+}
+
+// Errorf prints a formatted error message (only in table format)
+func (o *Output) Errorf(format string, a ...any) {
+	o.Error(i18n.Tf(format, a...))
+}
+
+// Infof prints a formatted info message (only in table format)
+func (o *Output) Infof(format string, a ...any) {
+	o.Info(i18n.Tf(format, a...))
+}
+
+// Warningf prints a formatted warning message (only in table format)
+func (o *Output) Warningf(format string, a ...any) {
+	o.Warning(i18n.Tf(format, a...))
+}
+
+// Println prints a line (only in table format). A single string argument is
+// translated.
 func (o *Output) Println(a ...interface{}) {
 	if !o.IsJSON() {
+		if len(a) == 1 {
+			if s, ok := a[0].(string); ok {
+				a = []any{i18n.T(s)}
+			}
+		}
 		_, _ = fmt.Fprintln(o.config.Writer, a...)
 	}
 }
 
-// Printf prints formatted output (only in table format)
+// Printf prints formatted output with a translated format (only in table format)
 func (o *Output) Printf(format string, a ...any) {
 	if !o.IsJSON() {
-		_, _ = fmt.Fprintf(o.config.Writer, format, a...)
+		_, _ = fmt.Fprintf(o.config.Writer, i18n.T(format), a...)
 	}
 }
 
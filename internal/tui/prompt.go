@@ -6,6 +6,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 )
 
 // SelectOption represents an option in a selection prompt
@@ -27,7 +29,7 @@ func SelectPromptWithDefault(title string, options []SelectOption, defaultIdx in
 	styles := DefaultStyles()
 
 	fmt.Println()
-	fmt.Println(styles.Subtitle.Render(title))
+	fmt.Println(styles.Subtitle.Render(i18n.T(title)))
 	fmt.Println()
 
 	for i, opt := range options {
@@ -44,24 +46,23 @@ func SelectPromptWithDefault(title string, options []SelectOption, defaultIdx in
 	if allowCustom {
 		fmt.Printf("    %s %s\n",
 			styles.Info.Render("[0]"),
-			styles.Muted.Render("Enter custom value"))
+			styles.Muted.Render(i18n.T("Enter custom value")))
 	}
 
 	fmt.Println()
 
 	defaultHint := ""
 	if defaultIdx >= 0 && defaultIdx < len(options) {
-		defaultHint = fmt.Sprintf(" (default: %d)", defaultIdx+1)
+		defaultHint = " " + i18n.Tf("(default: %d)", defaultIdx+1)
 	}
 
 	minChoice := 0
 	if !allowCustom {
 		minChoice = 1
 	}
-	fmt.Printf("%s Enter your choice (%d-%d)%s: ",
+	fmt.Printf("%s %s%s: ",
 		styles.Info.Render("→"),
-		minChoice,
-		len(options),
+		i18n.Tf("Enter your choice (%d-%d)", minChoice, len(options)),
 		defaultHint)
 
 	reader := bufio.NewReader(os.Stdin)
@@ -95,8 +96,8 @@ func MultiSelectPrompt(title string, options []SelectOption) ([]string, error) {
 	styles := DefaultStyles()
 
 	fmt.Println()
-	fmt.Println(styles.Subtitle.Render(title))
-	fmt.Println(styles.Muted.Render("  Enter numbers separated by comma (e.g., 1,2,3) or 'all' for all"))
+	fmt.Println(styles.Subtitle.Render(i18n.T(title)))
+	fmt.Println(styles.Muted.Render("  " + i18n.T("Enter numbers separated by comma (e.g., 1,2,3) or 'all' for all")))
 	fmt.Println()
 
 	for i, opt := range options {
@@ -106,7 +107,7 @@ func MultiSelectPrompt(title string, options []SelectOption) ([]string, error) {
 	}
 
 	fmt.Println()
-	fmt.Printf("%s Enter your choices: ", styles.Info.Render("→"))
+	fmt.Printf("%s %s: ", styles.Info.Render("→"), i18n.T("Enter your choices"))
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -170,12 +171,12 @@ func InputPromptWithDefault(prompt string, defaultValue string) (string, error)
 
 	defaultHint := ""
 	if defaultValue != "" {
-		defaultHint = styles.Muted.Render(fmt.Sprintf(" (default: %s)", defaultValue))
+		defaultHint = styles.Muted.Render(" " + i18n.Tf("(default: %s)", defaultValue))
 	}
 
 	fmt.Printf("%s %s%s: ",
 		styles.Info.Render("→"),
-		styles.Text.Render(prompt),
+		styles.Text.Render(i18n.T(prompt)),
 		defaultHint)
 
 	reader := bufio.NewReader(os.Stdin)
@@ -201,12 +202,12 @@ func InputPromptOptional(prompt string, defaultValue string) (string, error) {
 
 	defaultHint := ""
 	if defaultValue != "" {
-		defaultHint = styles.Muted.Render(fmt.Sprintf(" (default: %s)", defaultValue))
+		defaultHint = styles.Muted.Render(" " + i18n.Tf("(default: %s)", defaultValue))
 	}
 
 	fmt.Printf("%s %s%s: ",
 		styles.Info.Render("→"),
-		styles.Text.Render(prompt),
+		styles.Text.Render(i18n.T(prompt)),
 		defaultHint)
 
 	reader := bufio.NewReader(os.Stdin)
@@ -229,7 +230,7 @@ func ConfirmPrompt(message string) (bool, error) {
 
 	fmt.Printf("%s %s [y/N]: ",
 		styles.Warning.Render("!"),
-		styles.Text.Render(message))
+		styles.Text.Render(i18n.T(message)))
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -246,8 +247,8 @@ func StepHeader(stepNum int, totalSteps int, title string) {
 	styles := DefaultStyles()
 	fmt.Println()
 	fmt.Printf("%s %s\n",
-		styles.Info.Render(fmt.Sprintf("[Step %d/%d]", stepNum, totalSteps)),
-		styles.Subtitle.Render(title))
+		styles.Info.Render("["+i18n.Tf("Step %d/%d", stepNum, totalSteps)+"]"),
+		styles.Subtitle.Render(i18n.T(title)))
 }
 
 // WorkerSelectOption creates select options from worker list with name and ID
@@ -271,6 +272,20 @@ type GPUSelectItem struct {
 	Vendor string
 	Model  string
 	VRAMMb int64
+	// Utilization and VRAMFreeMb are only known with HasMetrics
+	HasMetrics  bool
+	Utilization float64
+	VRAMFreeMb  int64
+	// Workers names the workers the GPU is assigned to
+	Workers []string
+	// MIGInstances of a GPU in MIG mode, MIGInUse of them serving workers
+	MIGEnabled   bool
+	MIGInstances int
+	MIGInUse     int
+	// Health lists the flags the agent reported for the GPU
+	Health []string
+	// FullyAllocated marks a GPU another worker already uses whole
+	FullyAllocated bool
 }
 
 // FormatWorkerOptions formats workers into select options
@@ -310,11 +325,11 @@ func FormatAgentOptions(agents []AgentSelectItem) []SelectOption {
 		if len(agentIDDisplay) > 12 {
 			agentIDDisplay = agentIDDisplay[:12] + "..."
 		}
-		label := fmt.Sprintf("%s %s (%s) - %d GPUs",
+		label := fmt.Sprintf("%s %s (%s) - %s",
 			statusStyled,
 			styles.Bold.Render(a.Hostname),
 			styles.Muted.Render(agentIDDisplay),
-			a.GPUCount)
+			i18n.Tf("%d GPUs", a.GPUCount))
 		options = append(options, SelectOption{
 			Label: label,
 			Value: a.AgentID,
@@ -335,6 +350,9 @@ func FormatGPUOptions(gpus []GPUSelectItem) []SelectOption {
 			g.Model,
 			g.Vendor,
 			g.VRAMMb)
+		if details := gpuDetails(&g, styles); details != "" {
+			label += "\n        " + details
+		}
 		options = append(options, SelectOption{
 			Label: label,
 			Value: g.GPUID,
@@ -344,6 +362,30 @@ func FormatGPUOptions(gpus []GPUSelectItem) []SelectOption {
 	return options
 }
 
+// gpuDetails summarizes the live state of a GPU for its select option
+func gpuDetails(g *GPUSelectItem, styles *Styles) string {
+	var parts []string
+	if g.HasMetrics {
+		parts = append(parts, i18n.Tf("%.0f%% util", g.Utilization), i18n.Tf("%dMB free", g.VRAMFreeMb))
+	}
+	if g.MIGEnabled {
+		parts = append(parts, i18n.Tf("MIG: %d/%d instances in use", g.MIGInUse, g.MIGInstances))
+	}
+	if len(g.Workers) > 0 {
+		used := i18n.Tf("used by %s", strings.Join(g.Workers, ", "))
+		if g.FullyAllocated {
+			used = styles.Warning.Render(used)
+		}
+		parts = append(parts, used)
+	} else {
+		parts = append(parts, styles.Success.Render(i18n.T("free")))
+	}
+	if len(g.Health) > 0 {
+		parts = append(parts, styles.Error.Render("⚠ "+strings.Join(g.Health, ", ")))
+	}
+	return strings.Join(parts, " · ")
+}
+
 // FormatIPOptions formats IP addresses into select options
 func FormatIPOptions(ips []string) []SelectOption {
 	var options []SelectOption
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"github.com/NexusGPU/gpu-go/internal/i18n"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 )
@@ -27,7 +28,7 @@ func NewTableWithStyles(styles *Styles) *TableBuilder {
 	}
 }
 
-// Headers sets the table headers
+// Headers sets the table headers; they are translated when rendered
 func (tb *TableBuilder) Headers(headers ...string) *TableBuilder {
 	tb.headers = headers
 	return tb
@@ -58,6 +59,10 @@ func (tb *TableBuilder) String() string {
 	}
 
 	theme := tb.styles.Theme
+	headers := make([]string, len(tb.headers))
+	for i, h := range tb.headers {
+		headers[i] = i18n.T(h)
+	}
 
 	// Create table with custom styling
 	t := table.New().
@@ -81,7 +86,7 @@ func (tb *TableBuilder) String() string {
 					Padding(0, 1)
 			}
 		}).
-		Headers(tb.headers...).
+		Headers(headers...).
 		Rows(tb.rows...)
 
 	// Apply column widths if specified
@@ -146,7 +151,7 @@ func (st *StatusTable) String() string {
 
 	var output string
 	for _, item := range st.items {
-		key := st.styles.Key.Render(item.Key + ":")
+		key := st.styles.Key.Render(i18n.T(item.Key) + ":")
 		var value string
 		if item.Status != "" {
 			value = st.styles.StatusStyle(item.Status).Render(item.Value)
@@ -162,7 +167,7 @@ func (st *StatusTable) String() string {
 func DetailBox(title string, content string) string {
 	styles := DefaultStyles()
 
-	titleRendered := styles.Title.Render(title)
+	titleRendered := styles.Title.Render(i18n.T(title))
 	box := styles.Box.Render(content)
 
 	return titleRendered + "\n" + box
@@ -172,7 +177,7 @@ func DetailBox(title string, content string) string {
 func SuccessMessage(message string) string {
 	styles := DefaultStyles()
 	icon := styles.Success.Render("✓")
-	text := styles.Success.Render(message)
+	text := styles.Success.Render(i18n.T(message))
 	return icon + " " + text
 }
 
@@ -180,7 +185,7 @@ func SuccessMessage(message string) string {
 func ErrorMessage(message string) string {
 	styles := DefaultStyles()
 	icon := styles.Error.Render("✕")
-	text := styles.Error.Render(message)
+	text := styles.Error.Render(i18n.T(message))
 	return icon + " " + text
 }
 
@@ -188,7 +193,7 @@ func ErrorMessage(message string) string {
 func WarningMessage(message string) string {
 	styles := DefaultStyles()
 	icon := styles.Warning.Render("!")
-	text := styles.Warning.Render(message)
+	text := styles.Warning.Render(i18n.T(message))
 	return icon + " " + text
 }
 
@@ -196,7 +201,7 @@ func WarningMessage(message string) string {
 func InfoMessage(message string) string {
 	styles := DefaultStyles()
 	icon := styles.Info.Render("ℹ")
-	text := styles.Info.Render(message)
+	text := styles.Info.Render(i18n.T(message))
 	return icon + " " + text
 }
 
@@ -209,7 +214,7 @@ func Divider() string {
 // KeyValue renders a styled key-value pair
 func KeyValue(key, value string) string {
 	styles := DefaultStyles()
-	return styles.Key.Render(key+":") + " " + styles.Value.Render(value)
+	return styles.Key.Render(i18n.T(key)+":") + " " + styles.Value.Render(value)
 }
 
 // URL renders a styled URL
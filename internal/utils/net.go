@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand/v2"
 	"net"
+	"net/url"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -60,3 +64,90 @@ func GetRandomAvailablePort() (int, error) {
 	}
 	return 0, fmt.Errorf("failed to find an available port after 100 attempts")
 }
+
+// ConnectionAddr returns the host:port a worker connection URL points at,
+// with IPv6 literals bracketed for dialing. It accepts the worker's native
+// form, native+<host>+<port>[+<share code>], in which IPv6 hosts need no
+// brackets, as well as URLs such as tcp://[2001:db8::1]:9001.
+func ConnectionAddr(connectionURL string) (string, error) {
+	if rest, ok := strings.CutPrefix(connectionURL, "native+"); ok && !strings.Contains(rest, "://") {
+		parts := strings.Split(rest, "+")
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return "", fmt.Errorf("unsupported connection URL %q", connectionURL)
+		}
+		return net.JoinHostPort(strings.Trim(parts[0], "[]"), parts[1]), nil
+	}
+
+	u, err := url.Parse(connectionURL)
+	if err != nil || u.Host == "" || u.Port() == "" {
+		return "", fmt.Errorf("unsupported connection URL %q", connectionURL)
+	}
+	// Hostname strips the brackets, and splits an unbracketed IPv6 literal
+	// at its last colon
+	return net.JoinHostPort(u.Hostname(), u.Port()), nil
+}
+
+// ParseIPLiteral parses an IP address, accepting IPv6 addresses in the
+// bracketed form used in URLs, e.g. [2001:db8::1]
+func ParseIPLiteral(s string) (net.IP, error) {
+	ip := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", s)
+	}
+	return ip, nil
+}
+
+// DialFirst connects to the addresses in order of preference and returns the
+// index of the first that accepts a TCP connection. Following happy eyeballs
+// (RFC 8305), each attempt gets delay before the next one starts alongside
+// it, and a failed attempt starts the next one at once, so an unreachable
+// address costs a fraction of a second instead of a full connect timeout.
+func DialFirst(ctx context.Context, addrs []string, delay time.Duration) (int, error) {
+	if len(addrs) == 0 {
+		return -1, errors.New("no addresses to connect to")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		idx int
+		err error
+	}
+	// Buffered, so attempts still running on return don't block
+	results := make(chan result, len(addrs))
+	started := 0
+	start := func() {
+		idx := started
+		started++
+		go func() {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", addrs[idx])
+			if err == nil {
+				_ = conn.Close()
+			}
+			results <- result{idx: idx, err: err}
+		}()
+	}
+
+	start()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	var errs []error
+	for pending := 1; pending > 0; {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return r.idx, nil
+			}
+			errs = append(errs, r.err)
+		case <-timer.C:
+		}
+		if started < len(addrs) {
+			start()
+			pending++
+			timer.Reset(delay)
+		}
+	}
+	return -1, errors.Join(errs...)
+}
@@ -1,9 +1,14 @@
 package utils
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	tfv1 "github.com/NexusGPU/tensor-fusion/api/v1"
 	"github.com/stretchr/testify/assert"
@@ -105,3 +110,86 @@ func TestIsolationModeConversion(t *testing.T) {
 		assert.Equal(t, tt.expected, result, "FromTFIsolationMode(%s)", tt.input)
 	}
 }
+
+func TestNormalizeFingerprint(t *testing.T) {
+	assert.Equal(t, "ab01cd", NormalizeFingerprint("AB:01:CD"))
+	assert.Equal(t, "ab01cd", NormalizeFingerprint(" sha256:ab01cd "))
+	assert.Equal(t, "ab01cd", NormalizeFingerprint("SHA256:AB:01:CD"))
+}
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	certPEM, keyPEM, err := GenerateSelfSignedCert("worker-1", []string{"gpu-host", "10.0.0.5"}, time.Hour)
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, "worker-1", leaf.Subject.CommonName)
+	assert.Equal(t, []string{"gpu-host"}, leaf.DNSNames)
+	assert.Len(t, CertFingerprint(leaf.Raw), 64)
+}
+
+func TestConnectionAddr(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"native+10.0.0.1+9001", "10.0.0.1:9001"},
+		{"native+10.0.0.1+9001+abc", "10.0.0.1:9001"},
+		{"native+2001:db8::1+9001", "[2001:db8::1]:9001"},
+		{"native+[2001:db8::1]+9001+abc", "[2001:db8::1]:9001"},
+		{"tcp://192.168.1.50:9001", "192.168.1.50:9001"},
+		{"tcp://[2001:db8::1]:9001", "[2001:db8::1]:9001"},
+		{"native+tcp://[fd00::5]:9000/?x=1", "[fd00::5]:9000"},
+		{"tcp://relay.example.com:443", "relay.example.com:443"},
+	}
+	for _, tt := range tests {
+		got, err := ConnectionAddr(tt.url)
+		require.NoError(t, err, tt.url)
+		assert.Equal(t, tt.want, got, tt.url)
+	}
+
+	for _, bad := range []string{"native+10.0.0.1", "tcp://10.0.0.1", "not a url"} {
+		_, err := ConnectionAddr(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestParseIPLiteral(t *testing.T) {
+	ip, err := ParseIPLiteral("[2001:db8::1]")
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", ip.String())
+
+	ip, err = ParseIPLiteral("10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", ip.String())
+
+	_, err = ParseIPLiteral("gpu.example.com")
+	assert.Error(t, err)
+}
+
+func TestDialFirst(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := closed.Addr().String()
+	_ = closed.Close()
+
+	ctx := context.Background()
+	idx, err := DialFirst(ctx, []string{ln.Addr().String(), unreachable}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 0, idx)
+
+	// A refused primary hands over to the fallback without waiting out the delay
+	start := time.Now()
+	idx, err = DialFirst(ctx, []string{unreachable, ln.Addr().String()}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, idx)
+	assert.Less(t, time.Since(start), 30*time.Second)
+
+	_, err = DialFirst(ctx, []string{unreachable}, time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"k8s.io/klog/v2"
+)
+
+// RotatingLog is a log file that is rotated once it reaches a size limit.
+// The current file is path; rotated files are path.1 (newest) to
+// path.<maxFiles>, and older ones are deleted.
+type RotatingLog struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// OpenRotatingLog opens path for appending. A maxBytes of 0 never rotates.
+func OpenRotatingLog(path string, maxBytes int64, maxFiles int) (*RotatingLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	l := &RotatingLog{path: path, maxBytes: maxBytes, maxFiles: max(maxFiles, 0)}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *RotatingLog) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	l.f, l.size = f, info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when p would take the file over the limit
+func (l *RotatingLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		// Reopening failed after the last rotation
+		if err := l.open(); err != nil {
+			return 0, err
+		}
+	}
+	if l.maxBytes > 0 && l.size > 0 && l.size+int64(len(p)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the rotated files up by one and starts a new current file
+func (l *RotatingLog) rotate() error {
+	if err := l.f.Close(); err != nil {
+		klog.V(4).Infof("Failed to close rotated log: path=%s error=%v", l.path, err)
+	}
+	l.f = nil
+	_ = os.Remove(fmt.Sprintf("%s.%d", l.path, l.maxFiles))
+	for i := l.maxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if l.maxFiles == 0 {
+		_ = os.Remove(l.path)
+	} else if err := os.Rename(l.path, l.path+".1"); err != nil {
+		// A reader holding the file open on Windows blocks the rename;
+		// start over in place rather than grow without bound
+		klog.Warningf("Failed to rotate log, truncating it: path=%s error=%v", l.path, err)
+		_ = os.Truncate(l.path, 0)
+	}
+	return l.open()
+}
+
+// Close closes the current file
+func (l *RotatingLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// RunCaptured runs exe with its stdout and stderr appended to log, which may
+// be nil to leave them as they are, and returns its exit code. Termination
+// signals are passed on to the process. A process killed by a signal exits
+// with 128 plus the signal number, like in a shell, so whoever started
+// RunCaptured sees how it ended.
+func RunCaptured(log *RotatingLog, exe string, args []string) int {
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin = os.Stdin
+	if log != nil {
+		cmd.Stdout = log
+		cmd.Stderr = log
+	} else {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	if err := cmd.Start(); err != nil {
+		klog.Errorf("Failed to start worker process: executable=%s error=%v", exe, err)
+		return 127
+	}
+	// Outlive neither ggo nor a kill of this process (Windows Job Object)
+	postStart(cmd)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				if err := cmd.Process.Signal(sig); err != nil {
+					_ = cmd.Process.Kill()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	err := cmd.Wait()
+	close(done)
+
+	if cmd.ProcessState == nil {
+		klog.Errorf("Worker process failed: executable=%s error=%v", exe, err)
+		return 1
+	}
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return cmd.ProcessState.ExitCode()
+}
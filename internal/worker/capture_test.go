@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "output-w1.log")
+	l, err := OpenRotatingLog(path, 10, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := l.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Close())
+
+	read := func(p string) string {
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "fourth\n", read(path))
+	assert.Equal(t, "third\n", read(path+".1"))
+	assert.Equal(t, "second\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3", "only maxFiles rotated files are kept")
+
+	// Reopening appends to the current file
+	l, err = OpenRotatingLog(path, 100, 2)
+	require.NoError(t, err)
+	_, err = l.Write([]byte("fifth\n"))
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	assert.Equal(t, "fourth\nfifth\n", read(path))
+}
+
+func TestRunCaptured(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	path := filepath.Join(t.TempDir(), "output.log")
+	l, err := OpenRotatingLog(path, 0, 0)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	code := RunCaptured(l, "sh", []string{"-c", "echo out; echo err >&2; exit 3"})
+	assert.Equal(t, 3, code)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "out\nerr\n", string(data))
+
+	assert.Equal(t, 128+9, RunCaptured(l, "sh", []string{"-c", "kill -9 $$"}), "killed by SIGKILL")
+	assert.Equal(t, 127, RunCaptured(l, filepath.Join(t.TempDir(), "missing"), nil))
+}
@@ -371,7 +371,11 @@ restart_agent_service_if_running() {
             if command -v systemctl >/dev/null 2>&1 && [ -d /run/systemd/system ]; then
                 if ${SUDO:+${SUDO}} systemctl is-active --quiet "${SYSTEMD_SERVICE_NAME}" 2>/dev/null; then
                     info "Detected running ${SYSTEMD_SERVICE_NAME} service, restarting..."
-                    ${SUDO:+${SUDO}} systemctl restart "${SYSTEMD_SERVICE_NAME}"
+                    # Keep GPU workers and their clients alive; older agents
+                    # cannot hand over and are restarted by systemd
+                    if ! ${SUDO:+${SUDO} -H} "${DEST_PATH}" agent restart --preserve-workers >/dev/null 2>&1; then
+                        ${SUDO:+${SUDO}} systemctl restart "${SYSTEMD_SERVICE_NAME}"
+                    fi
                     if ${SUDO:+${SUDO}} systemctl is-active --quiet "${SYSTEMD_SERVICE_NAME}" 2>/dev/null; then
                         info "Service ${SYSTEMD_SERVICE_NAME} restarted successfully"
                     else